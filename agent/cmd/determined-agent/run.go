@@ -87,6 +87,8 @@ func newRunCmd() *cobra.Command {
 	// Labels flags.
 	cmd.Flags().StringVar(&opts.Label, "label", "",
 		"Label attached to the agent for scheduling constraints")
+	cmd.Flags().StringToStringVar(&opts.Labels, "labels", nil,
+		"Key-value labels (e.g. gpu=a100,rack=r1) that tasks can constrain their scheduling to")
 
 	// ResourcePool flags.
 	cmd.Flags().StringVar(&opts.ResourcePool, "resource-pool", "",
@@ -157,5 +159,20 @@ func newRunCmd() *cobra.Command {
 	cmd.Flags().IntVar(&opts.AgentReconnectBackoff, "agent-reconnect-backoff",
 		int(aproto.AgentReconnectBackoff/time.Second), "Time between agent reconnect attempts")
 
+	cmd.Flags().StringVar(&opts.ContainerRuntimeWorkDir, "container-runtime-work-dir",
+		"/var/lib/determined/containers",
+		"Directory for the images and containers of the apptainer, enroot and podman runtimes")
+	cmd.Flags().StringVar(&opts.Podman.UserNS, "podman-userns", "",
+		"User namespace mode of podman task containers; rootless podman defaults to keep-id")
+
+	// GPU health flags.
+	cmd.Flags().IntVar(&opts.GPUHealth.CheckPeriod, "gpu-health-check-period", 30,
+		"Seconds between GPU health checks; 0 disables GPU health monitoring")
+	cmd.Flags().IntVar(&opts.GPUHealth.MaxTemperature, "gpu-health-max-temperature", 0,
+		"Temperature in Celsius above which a GPU is considered unhealthy; 0 disables the check")
+	cmd.Flags().IntSliceVar(&opts.GPUHealth.CriticalXids, "gpu-health-critical-xids",
+		[]int{48, 62, 64, 74, 79, 92, 94, 95},
+		"Xid error codes that mark a GPU as unhealthy until the agent restarts")
+
 	return cmd
 }
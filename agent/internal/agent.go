@@ -14,6 +14,7 @@ import (
 	"os/exec"
 	"runtime"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -30,6 +31,7 @@ import (
 	"github.com/determined-ai/determined/master/pkg/actor/actors"
 	"github.com/determined-ai/determined/master/pkg/actor/api"
 	"github.com/determined-ai/determined/master/pkg/aproto"
+	"github.com/determined-ai/determined/master/pkg/cproto"
 	"github.com/determined-ai/determined/master/pkg/device"
 	"github.com/determined-ai/determined/master/pkg/logger"
 	"github.com/determined-ai/determined/master/pkg/model"
@@ -49,18 +51,30 @@ type agent struct {
 	MasterSetAgentOptions *aproto.MasterSetAgentOptions
 	Devices               []device.Device `json:"devices"`
 
-	socket *actor.Ref
-	cm     *actor.Ref
-	fluent *actor.Ref
+	socket     *actor.Ref
+	cm         *actor.Ref
+	fluent     *actor.Ref
+	gpuHealth  *actor.Ref
+	imageCache *actor.Ref
 
 	masterProto  string
 	masterClient *http.Client
 
 	reconnecting bool
+	// masterRestartDeadline is how long to keep trying to reconnect after the master said that it
+	// is restarting.
+	masterRestartDeadline time.Time
+	// lastStateChanges holds the last state change of each container that hasn't terminated, to
+	// resend those that may have been lost while reconnecting to the master.
+	lastStateChanges map[cproto.ID]aproto.ContainerStateChanged
 }
 
 func newAgent(version string, options Options) *agent {
-	return &agent{Version: version, Options: options}
+	return &agent{
+		Version:          version,
+		Options:          options,
+		lastStateChanges: make(map[cproto.ID]aproto.ContainerStateChanged),
+	}
 }
 
 func (a *agent) Receive(ctx *actor.Context) error {
@@ -106,11 +120,24 @@ func (a *agent) Receive(ctx *actor.Context) error {
 		case msg.AgentShutdown != nil:
 			ctx.Log().Infof("shutting down agent due to master message: %s", msg.AgentShutdown.ErrMsg)
 			ctx.Self().Stop()
+		case msg.MasterRestarting != nil:
+			wait := time.Duration(msg.MasterRestarting.ReconnectWait)
+			ctx.Log().Infof("master is restarting, will try to reconnect for up to %s", wait)
+			a.masterRestartDeadline = time.Now().Add(wait)
+		case msg.EvictImages != nil:
+			a.tellImageCache(ctx, *msg.EvictImages)
+		case msg.SetImageCacheLimit != nil:
+			a.tellImageCache(ctx, *msg.SetImageCacheLimit)
 		default:
 			panic(fmt.Sprintf("unknown message received: %+v", msg))
 		}
 
 	case aproto.ContainerStateChanged:
+		if msg.Container.State == cproto.Terminated {
+			delete(a.lastStateChanges, msg.Container.ID)
+		} else {
+			a.lastStateChanges[msg.Container.ID] = msg
+		}
 		if a.socket != nil {
 			ctx.Ask(a.socket, api.WriteMessage{Message: aproto.MasterMessage{ContainerStateChanged: &msg}})
 		} else {
@@ -126,6 +153,21 @@ func (a *agent) Receive(ctx *actor.Context) error {
 			ctx.Ask(a.socket, api.WriteMessage{Message: aproto.MasterMessage{ContainerStatsRecord: &msg}})
 		}
 
+	case aproto.ContainerMetrics:
+		if a.socket != nil {
+			ctx.Ask(a.socket, api.WriteMessage{Message: aproto.MasterMessage{ContainerMetrics: &msg}})
+		}
+
+	case aproto.DeviceHealthReport:
+		if a.socket != nil {
+			ctx.Ask(a.socket, api.WriteMessage{Message: aproto.MasterMessage{DeviceHealthReport: &msg}})
+		}
+
+	case aproto.ImageCacheReport:
+		if a.socket != nil {
+			ctx.Ask(a.socket, api.WriteMessage{Message: aproto.MasterMessage{ImageCacheReport: &msg}})
+		}
+
 	case model.TaskLog:
 		return a.postTaskLog(msg)
 
@@ -149,6 +191,15 @@ func (a *agent) Receive(ctx *actor.Context) error {
 				}
 			}()
 			return nil
+		case a.gpuHealth:
+			// Health monitoring is best effort; keep running without it.
+			ctx.Log().WithError(msg.Error).Warn("GPU health monitor failed, GPU health will not be reported")
+			return nil
+		case a.imageCache:
+			// Like health monitoring, managing the image cache is best effort.
+			ctx.Log().WithError(msg.Error).Warn("image cache manager failed, images will not be evicted")
+			a.imageCache = nil
+			return nil
 		}
 		return errors.Wrapf(msg.Error, "unexpected child failure: %s", msg.Child.Address())
 
@@ -314,25 +365,45 @@ func (a *agent) tlsConfig() (*tls.Config, error) {
 		}
 	}
 
-	var certs []tls.Certificate
-	switch cert, err := a.Options.Security.TLS.ReadClientCertificate(); {
-	case err != nil:
+	cert, err := a.Options.Security.TLS.ReadClientCertificate()
+	if err != nil {
 		msg := fmt.Sprintf("failed to read agent certificate file %q or certificate key %q",
 			a.Options.Security.TLS.ClientCert, a.Options.Security.TLS.ClientKey)
 		return nil, errors.Wrapf(err, msg)
-	case cert != nil:
-		certs = append(certs, *cert)
 	}
 
 	return &tls.Config{
-		InsecureSkipVerify: a.Options.Security.TLS.SkipVerify, //nolint:gosec
-		MinVersion:         tls.VersionTLS12,
-		RootCAs:            pool,
-		ServerName:         a.Options.Security.TLS.MasterCertName,
-		Certificates:       certs,
+		InsecureSkipVerify:   a.Options.Security.TLS.SkipVerify, //nolint:gosec
+		MinVersion:           tls.VersionTLS12,
+		RootCAs:              pool,
+		ServerName:           a.Options.Security.TLS.MasterCertName,
+		GetClientCertificate: a.clientCertificate(cert),
 	}, nil
 }
 
+// clientCertificate returns a callback that reads the agent certificate again for every
+// connection, so that a rotated certificate is used as soon as it is written, falling back to the
+// last certificate read while the files are being replaced.
+func (a *agent) clientCertificate(
+	cert *tls.Certificate,
+) func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	var mu sync.Mutex
+	return func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if newCert, err := a.Options.Security.TLS.ReadClientCertificate(); err != nil {
+			logrus.WithError(err).Warn("failed to reread agent certificate, using the previous one")
+		} else {
+			cert = newCert
+		}
+		if cert == nil {
+			// An empty certificate tells the master that the agent has none.
+			return &tls.Certificate{}, nil
+		}
+		return cert, nil
+	}
+}
+
 func (a *agent) makeMasterClient() error {
 	tlsConfig, err := a.tlsConfig()
 	if err != nil {
@@ -400,8 +471,20 @@ func (a *agent) attemptReconnect(ctx *actor.Context) bool {
 	a.reconnecting = true
 	defer func() {
 		a.reconnecting = false
+		a.masterRestartDeadline = time.Time{}
 	}()
-	for i := 0; i < a.Options.AgentReconnectAttempts; i++ {
+	// Keep trying for as long as the master waits for the agent before failing its containers,
+	// so that they survive brief network failures.
+	deadline := a.masterRestartDeadline
+	if a.MasterSetAgentOptions != nil {
+		wait := time.Duration(a.MasterSetAgentOptions.AgentReconnectWait) -
+			time.Duration(a.Options.AgentReconnectBackoff)*time.Second
+		if d := time.Now().Add(wait); d.After(deadline) {
+			deadline = d
+		}
+	}
+	inWindow := func() bool { return time.Now().Before(deadline) }
+	for i := 0; i < a.Options.AgentReconnectAttempts || inWindow(); i++ {
 		switch err := a.connect(ctx); {
 		case err == nil:
 			return true
@@ -439,13 +522,24 @@ func (a *agent) connect(ctx *actor.Context) error {
 }
 
 func (a *agent) setup(ctx *actor.Context) error {
-	fluentActor, err := newFluentActor(a.Options, *a.MasterSetAgentOptions)
-	if err != nil {
-		return errors.Wrap(err, "failed to start Fluent daemon")
+	// Fluent Bit runs under Docker and collects logs through Docker's logging driver; other
+	// runtimes ship their containers' logs themselves.
+	var fluentPort int
+	runtime := a.MasterSetAgentOptions.ContainerRuntime
+	if runtime == "" || runtime == aproto.ContainerRuntimeDocker {
+		fluentActor, err := newFluentActor(a.Options, *a.MasterSetAgentOptions)
+		if err != nil {
+			return errors.Wrap(err, "failed to start Fluent daemon")
+		}
+		a.fluent, _ = ctx.ActorOf("fluent", fluentActor)
+		fluentPort = fluentActor.port
+		a.imageCache, _ = ctx.ActorOf(
+			"image-cache", newImageCache(a.MasterSetAgentOptions.ImageCacheLimit))
+	} else {
+		ctx.Log().Infof("running task containers with %s", runtime)
 	}
-	a.fluent, _ = ctx.ActorOf("fluent", fluentActor)
 
-	if err = a.detect(); err != nil {
+	if err := a.detect(); err != nil {
 		return err
 	}
 	ctx.Log().Info("detected compute devices:")
@@ -453,6 +547,12 @@ func (a *agent) setup(ctx *actor.Context) error {
 		ctx.Log().Infof("\t%s", d.String())
 	}
 
+	if a.GPUHealth.CheckPeriod > 0 {
+		if monitor := newGPUHealthMonitor(a.GPUHealth, a.Devices); len(monitor.devices) > 0 {
+			a.gpuHealth, _ = ctx.ActorOf("gpu-health", monitor)
+		}
+	}
+
 	v, err := getNvidiaVersion()
 	if err != nil {
 		return err
@@ -475,7 +575,7 @@ func (a *agent) setup(ctx *actor.Context) error {
 		}
 	}
 
-	cm, err := newContainerManager(a, fluentActor.port)
+	cm, err := newContainerManager(a, fluentPort)
 	if err != nil {
 		return errors.Wrap(err, "error initializing container manager")
 	}
@@ -489,6 +589,7 @@ func (a *agent) setup(ctx *actor.Context) error {
 			Version:              a.Version,
 			Devices:              a.Devices,
 			Label:                a.Label,
+			Labels:               a.Labels,
 			ContainersReattached: res.ContainersReattached,
 		},
 	}})
@@ -499,6 +600,8 @@ func (a *agent) setupAfterMasterRestart(ctx *actor.Context) error {
 	// TODO(ilia): reinitialize fluent logging settings per the new master config,
 	// if possible.
 
+	a.resendLostStateChanges(ctx)
+
 	res := ctx.Ask(a.cm, requestRevalidateContainers{
 		a.MasterSetAgentOptions.ContainersToReattach,
 	}).Get().(responseReattachContainers)
@@ -508,15 +611,46 @@ func (a *agent) setupAfterMasterRestart(ctx *actor.Context) error {
 			Version:              a.Version,
 			Devices:              a.Devices,
 			Label:                a.Label,
+			Labels:               a.Labels,
 			ContainersReattached: res.ContainersReattached,
 		},
 	}})
 
+	a.tellImageCache(ctx, aproto.SetImageCacheLimit{
+		LimitBytes: a.MasterSetAgentOptions.ImageCacheLimit,
+	})
+
 	// TODO(ilia): buffer and resend pending network messages.
 
 	return nil
 }
 
+// resendLostStateChanges resends the state changes of containers that the master missed while the
+// agent was disconnected, before the containers are revalidated, so that the master doesn't kill
+// them for having changed state.
+func (a *agent) resendLostStateChanges(ctx *actor.Context) {
+	for _, expected := range a.MasterSetAgentOptions.ContainersToReattach {
+		sc, ok := a.lastStateChanges[expected.Container.ID]
+		if !ok || sc.Container.State == expected.Container.State {
+			continue
+		}
+		ctx.Log().Infof("resending state change of container %s from %s to %s",
+			sc.Container.ID, expected.Container.State, sc.Container.State)
+		ctx.Ask(a.socket, api.WriteMessage{
+			Message: aproto.MasterMessage{ContainerStateChanged: &sc},
+		})
+	}
+}
+
+// tellImageCache passes a message on to the image cache manager, which only runs with Docker.
+func (a *agent) tellImageCache(ctx *actor.Context, msg interface{}) {
+	if a.imageCache == nil {
+		ctx.Log().Warnf("ignoring %T: images are only cached with the Docker runtime", msg)
+		return
+	}
+	ctx.Tell(a.imageCache, msg)
+}
+
 func (a *agent) connectToMaster(ctx *actor.Context) error {
 	if err := a.makeMasterClient(); err != nil {
 		return errors.Wrap(err, "error creating master client")
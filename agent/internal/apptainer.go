@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/cproto"
+)
+
+// apptainerRuntime runs task containers with Apptainer, or Singularity on hosts that predate the
+// rename.
+type apptainerRuntime struct {
+	binary string
+	// envPrefix prefixes the environment variables the binary reads its options from.
+	envPrefix string
+}
+
+func newApptainerRuntime() (*apptainerRuntime, error) {
+	if path, err := exec.LookPath("apptainer"); err == nil {
+		return &apptainerRuntime{binary: path, envPrefix: "APPTAINER"}, nil
+	}
+	if path, err := exec.LookPath("singularity"); err == nil {
+		return &apptainerRuntime{binary: path, envPrefix: "SINGULARITY"}, nil
+	}
+	return nil, errors.New("neither apptainer nor singularity was found on the PATH")
+}
+
+func (a *apptainerRuntime) imageExtension() string {
+	return ".sif"
+}
+
+func (a *apptainerRuntime) pullCommand(
+	image reference.Named, dst string, auth *types.AuthConfig,
+) *exec.Cmd {
+	// #nosec G204
+	cmd := exec.Command(a.binary, "pull", "--force", dst, "docker://"+image.String())
+	cmd.Env = os.Environ()
+	if auth != nil && auth.Username != "" {
+		cmd.Env = append(cmd.Env,
+			a.envPrefix+"_DOCKER_USERNAME="+auth.Username,
+			a.envPrefix+"_DOCKER_PASSWORD="+auth.Password,
+		)
+	}
+	return cmd
+}
+
+func (a *apptainerRuntime) createCommand(image string, rootfs string) *exec.Cmd {
+	// #nosec G204
+	return exec.Command(a.binary, "build", "--force", "--sandbox", rootfs, image)
+}
+
+func (a *apptainerRuntime) runCommand(rootfs string, spec cproto.RunSpec) (*exec.Cmd, error) {
+	argv, err := containerCommand(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"exec", "--writable", "--no-home", "--cleanenv"}
+	if wd := spec.ContainerConfig.WorkingDir; wd != "" {
+		args = append(args, "--pwd", wd)
+	}
+	for _, bind := range containerBinds(spec) {
+		args = append(args, "--bind", bind)
+	}
+
+	// With --cleanenv, only variables with the prefix make it into the container.
+	env := os.Environ()
+	for _, kv := range spec.ContainerConfig.Env {
+		env = append(env, a.envPrefix+"ENV_"+kv)
+	}
+	if uuids := cudaDeviceUUIDs(spec); len(uuids) > 0 {
+		args = append(args, "--nv")
+		env = append(env, a.envPrefix+"ENV_CUDA_VISIBLE_DEVICES="+strings.Join(uuids, ","))
+	}
+
+	args = append(args, rootfs)
+	args = append(args, argv...)
+	// #nosec G204
+	cmd := exec.Command(a.binary, args...)
+	cmd.Env = env
+	return cmd, nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 
@@ -33,6 +34,14 @@ type containerActor struct {
 	docker        *actor.Ref
 	containerInfo *types.ContainerJSON
 
+	// runtime is the daemonless runtime to run the container with, or nil to use Docker. Either
+	// way, the actor running the container is docker.
+	runtime        processRuntime
+	runtimeWorkDir string
+	// systemMetricsPeriod is how often the resource usage of the container is reported to the
+	// master; 0 means never.
+	systemMetricsPeriod time.Duration
+
 	// Keeps track of why we exited. Always valid with a terminated state.
 	stop *aproto.ContainerStopped
 
@@ -45,17 +54,31 @@ type (
 	containerReady      struct{}
 )
 
-func newContainerActor(msg aproto.StartContainer, client *client.Client) actor.Actor {
+func newContainerActor(
+	msg aproto.StartContainer,
+	client *client.Client,
+	runtime processRuntime,
+	runtimeWorkDir string,
+	systemMetricsPeriod time.Duration,
+) actor.Actor {
 	return &containerActor{
-		Container: msg.Container, spec: &msg.Spec, client: client,
+		Container:           msg.Container,
+		spec:                &msg.Spec,
+		client:              client,
+		runtime:             runtime,
+		runtimeWorkDir:      runtimeWorkDir,
+		systemMetricsPeriod: systemMetricsPeriod,
 	}
 }
 
 func reattachContainerActor(
-	container cproto.Container, client *client.Client,
+	container cproto.Container, client *client.Client, systemMetricsPeriod time.Duration,
 ) actor.Actor {
 	return &containerActor{
-		Container: container, client: client, reattached: true,
+		Container:           container,
+		client:              client,
+		reattached:          true,
+		systemMetricsPeriod: systemMetricsPeriod,
 	}
 }
 
@@ -96,7 +119,12 @@ func (c *containerActor) Receive(ctx *actor.Context) error {
 	switch msg := ctx.Message().(type) {
 	case actor.PreStart:
 		if !c.reattached {
-			c.docker, _ = ctx.ActorOf("docker", &dockerActor{Client: c.client})
+			if c.runtime != nil {
+				c.docker, _ = ctx.ActorOf("runtime",
+					newProcessRuntimeActor(c.runtime, c.runtimeWorkDir, c.Container.ID))
+			} else {
+				c.docker, _ = ctx.ActorOf("docker", &dockerActor{Client: c.client})
+			}
 			taskLog := getBaseTaskLog(c.spec)
 			c.transition(ctx, cproto.Pulling)
 			pull := pullImage{
@@ -150,16 +178,18 @@ func (c *containerActor) Receive(ctx *actor.Context) error {
 	case containerReattached:
 		c.containerInfo = &msg.containerInfo
 		// TODO(ilia): When do we need to start a checker for these containers?
+		c.startSystemMetricsMonitor(ctx)
 
 	case containerReady:
 		c.containerStarted(ctx, aproto.ContainerStarted{ContainerInfo: *c.containerInfo})
+		c.startSystemMetricsMonitor(ctx)
 
 	case containerTerminated:
 		ctx.Log().Debug("containerTerminated")
 		c.containerStopped(ctx, aproto.ContainerExited(msg.ExitCode))
 		ctx.Self().Stop()
 
-	case aproto.ContainerStatsRecord:
+	case aproto.ContainerStatsRecord, aproto.ContainerMetrics:
 		ctx.Tell(ctx.Self().Parent(), msg)
 
 	case aproto.SignalContainer:
@@ -237,7 +267,15 @@ func (c *containerActor) makeTaskLog(log aproto.ContainerLog) model.TaskLog {
 	case log.PullMessage != nil:
 		msg = *log.PullMessage
 	case log.RunMessage != nil:
-		panic(fmt.Sprintf("unexpected run message from container on Fluent logging: %v", log.RunMessage))
+		// Only process runtimes, which have no Fluent logging driver, send run messages.
+		if c.runtime == nil {
+			panic(fmt.Sprintf(
+				"unexpected run message from container on Fluent logging: %v", log.RunMessage))
+		}
+		msg = log.RunMessage.Value
+		if log.RunMessage.StdType == stdcopy.Stderr {
+			l.StdType = ptrs.Ptr("stderr")
+		}
 	default:
 		panic("unknown log message received")
 	}
@@ -273,6 +311,16 @@ func (c *containerActor) containerStarted(ctx *actor.Context, started aproto.Con
 	})
 }
 
+// startSystemMetricsMonitor starts reporting the resource usage of the running container, if
+// enabled. Daemonless runtimes have no stats API, so their containers are not measured.
+func (c *containerActor) startSystemMetricsMonitor(ctx *actor.Context) {
+	if c.systemMetricsPeriod <= 0 || c.runtime != nil || c.containerInfo == nil {
+		return
+	}
+	ctx.ActorOf("system-metrics", newSystemMetricsMonitor(
+		c.client, c.Container, c.containerInfo.ID, c.systemMetricsPeriod))
+}
+
 // containerStopped transitions the container and sets the reason for stop. If called multiple
 // times, it just respects and resends the first reason.
 func (c *containerActor) containerStopped(ctx *actor.Context, msg aproto.ContainerStopped) {
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	dcontainer "github.com/docker/docker/api/types/container"
@@ -50,6 +51,12 @@ type containerManager struct {
 	fluentPort int
 
 	docker *client.Client
+	// runtime is the daemonless runtime task containers are run with, or nil to use Docker.
+	runtime processRuntime
+	// systemMetricsPeriod is how often containers report their resource usage; 0 means never.
+	systemMetricsPeriod time.Duration
+	// cpuSlots sets the CPU shares and limits of containers on CPU slots.
+	cpuSlots model.CPUSlotsConfig
 
 	recentExits *ring.Ring
 }
@@ -67,23 +74,33 @@ type (
 )
 
 func newContainerManager(a *agent, fluentPort int) (*containerManager, error) {
+	runtime, err := newProcessRuntime(a.MasterSetAgentOptions.ContainerRuntime, a.Options)
+	if err != nil {
+		return nil, err
+	}
 	return &containerManager{
 		MasterInfo:  a.MasterSetAgentOptions.MasterInfo,
 		Options:     a.Options,
 		Devices:     a.Devices,
 		fluentPort:  fluentPort,
+		runtime:     runtime,
 		recentExits: ring.New(recentExitsKept),
+
+		systemMetricsPeriod: time.Duration(a.MasterSetAgentOptions.SystemMetricsPeriod),
+		cpuSlots:            a.MasterSetAgentOptions.CPUSlots,
 	}, nil
 }
 
 func (c *containerManager) Receive(ctx *actor.Context) error {
 	switch msg := ctx.Message().(type) {
 	case actor.PreStart:
-		d, err := client.NewClientWithOpts(client.WithAPIVersionNegotiation(), client.FromEnv)
-		if err != nil {
-			return err
+		if c.runtime == nil {
+			d, err := client.NewClientWithOpts(client.WithAPIVersionNegotiation(), client.FromEnv)
+			if err != nil {
+				return err
+			}
+			c.docker = d
 		}
-		c.docker = d
 
 		masterScheme := httpInsecureScheme
 		if c.Options.Security.TLS.Enabled {
@@ -145,7 +162,7 @@ func (c *containerManager) Receive(ctx *actor.Context) error {
 
 		ctx.Tell(ctx.Self().Parent(), msg)
 
-	case aproto.ContainerLog, model.TaskLog, aproto.ContainerStatsRecord:
+	case aproto.ContainerLog, model.TaskLog, aproto.ContainerStatsRecord, aproto.ContainerMetrics:
 		ctx.Tell(ctx.Self().Parent(), msg)
 
 	case aproto.StartContainer:
@@ -159,8 +176,9 @@ func (c *containerManager) Receive(ctx *actor.Context) error {
 		}
 		// actually overwrite the spec.
 		msg.Spec = enrichedSpec
-		if ref, ok := ctx.ActorOf(
-			msg.Container.ID, newContainerActor(msg, c.docker)); !ok {
+		if ref, ok := ctx.ActorOf(msg.Container.ID, newContainerActor(
+			msg, c.docker, c.runtime, c.Options.ContainerRuntimeWorkDir, c.systemMetricsPeriod,
+		)); !ok {
 			ctx.Log().Warnf("container already created: %s", msg.Container.ID)
 			if ctx.ExpectingResponse() {
 				ctx.Respond(errors.Errorf("container already created: %s", msg.Container.ID))
@@ -237,7 +255,29 @@ func (c *containerManager) overwriteSpec(cont cproto.Container, spec cproto.Spec
 	cproto.Spec, error,
 ) {
 	autoRemove := !c.Options.ContainerAutoRemoveDisabled
-	return overwriteSpec(cont, spec, c.GlobalEnvVars, c.Labels, c.fluentPort, autoRemove)
+	spec, err := overwriteSpec(cont, spec, c.GlobalEnvVars, c.Labels, c.fluentPort, autoRemove)
+	if err != nil {
+		return cproto.Spec{}, err
+	}
+	limitCPUSlots(cont, &spec.RunSpec.HostConfig.Resources, c.cpuSlots)
+	return spec, nil
+}
+
+// limitCPUSlots sets the CPU shares and limit of a container on CPU slots in proportion to its
+// number of slots.
+func limitCPUSlots(
+	cont cproto.Container, resources *dcontainer.Resources, cpuSlots model.CPUSlotsConfig,
+) {
+	slots := len(cont.DeviceUUIDsByType(device.CPU))
+	if slots == 0 {
+		return
+	}
+	if cpuSlots.CPUSharesPerSlot > 0 {
+		resources.CPUShares = cpuSlots.CPUSharesPerSlot * int64(slots)
+	}
+	if cpuSlots.CPULimitPerSlot > 0 {
+		resources.NanoCPUs = int64(cpuSlots.CPULimitPerSlot * float64(slots) * 1e9)
+	}
 }
 
 func overwriteSpec(
@@ -366,6 +406,20 @@ func (c *containerManager) reattachContainers(
 ) {
 	result := make([]aproto.ContainerReattachAck, 0, len(expectedSurvivors))
 
+	if c.runtime != nil {
+		// Containers run by process runtimes are children of the agent and die with it.
+		for _, expectedSurvivor := range expectedSurvivors {
+			result = append(result, aproto.ContainerReattachAck{
+				Container: cproto.Container{ID: expectedSurvivor.Container.ID},
+				Failure: &aproto.ContainerFailure{
+					FailureType: aproto.RestoreError,
+					ErrMsg:      "container reattachment is only supported with the docker runtime",
+				},
+			})
+		}
+		return result, nil
+	}
+
 	runningContainers, err := c.listRunningContainers(ctx)
 	if err != nil {
 		return nil, err
@@ -438,7 +492,8 @@ func (c *containerManager) reattachContainer(
 	}
 
 	cid := containerPrevState.ID
-	containerRef, ok := ctx.ActorOf(cid, reattachContainerActor(*containerCurrState, c.docker))
+	containerRef, ok := ctx.ActorOf(cid, reattachContainerActor(
+		*containerCurrState, c.docker, c.systemMetricsPeriod))
 	if !ok {
 		errorMsg := fmt.Sprintf("failed to reattach container %s: actor already exists", cid)
 		ctx.Log().Warnf(errorMsg)
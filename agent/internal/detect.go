@@ -22,6 +22,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/determined-ai/determined/master/pkg/device"
+	"github.com/determined-ai/determined/master/pkg/model"
 )
 
 const (
@@ -83,7 +84,7 @@ func (a *agent) detect() error {
 		if err != nil {
 			return err
 		}
-		a.Devices = devices
+		a.Devices = splitCPUSlots(devices, a.MasterSetAgentOptions.CPUSlots, runtime.NumCPU())
 	case a.SlotType == "auto":
 		devices, err := detectCudaGPUs(a.Options.VisibleGPUs)
 		if err != nil {
@@ -100,6 +101,7 @@ func (a *agent) detect() error {
 			if err != nil {
 				return err
 			}
+			devices = splitCPUSlots(devices, a.MasterSetAgentOptions.CPUSlots, runtime.NumCPU())
 		}
 		a.Devices = devices
 	default:
@@ -149,6 +151,27 @@ func detectCPUs() ([]device.Device, error) {
 	}
 }
 
+// splitCPUSlots splits the single slot that holds all the CPUs of the agent into as many slots as
+// its resource pool configures.
+func splitCPUSlots(
+	devices []device.Device, cpuSlots model.CPUSlotsConfig, cpus int,
+) []device.Device {
+	n := cpuSlots.Slots(cpus)
+	if len(devices) != 1 || n == 1 {
+		return devices
+	}
+	slots := make([]device.Device, 0, n)
+	for i := 0; i < n; i++ {
+		slots = append(slots, device.Device{
+			ID:    device.ID(i),
+			Brand: devices[0].Brand,
+			UUID:  fmt.Sprintf("%s-%d", devices[0].UUID, i),
+			Type:  device.CPU,
+		})
+	}
+	return slots
+}
+
 var (
 	detectMIGEnabled = []string{
 		"nvidia-smi", "--query-gpu=mig.mode.current", "--format=csv,noheader",
@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"testing"
+
+	dcontainer "github.com/docker/docker/api/types/container"
+	"gotest.tools/assert"
+
+	"github.com/determined-ai/determined/master/pkg/cproto"
+	"github.com/determined-ai/determined/master/pkg/device"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestSplitCPUSlots(t *testing.T) {
+	cpus := []device.Device{{ID: 0, Brand: "Xeon x 8 cores", UUID: "GenuineIntel", Type: device.CPU}}
+
+	assert.DeepEqual(t, splitCPUSlots(cpus, model.CPUSlotsConfig{}, 8), cpus)
+	assert.DeepEqual(t, splitCPUSlots(cpus, model.CPUSlotsConfig{SlotsPerCPU: 0.1}, 8), cpus)
+
+	slots := splitCPUSlots(cpus, model.CPUSlotsConfig{SlotsPerCPU: 2}, 8)
+	assert.Equal(t, len(slots), 16)
+	for i, slot := range slots {
+		assert.Equal(t, slot.ID, device.ID(i))
+		assert.Equal(t, slot.Type, device.CPU)
+	}
+	assert.Equal(t, slots[3].UUID, "GenuineIntel-3")
+
+	assert.Equal(t, len(splitCPUSlots(cpus, model.CPUSlotsConfig{SlotsPerCPU: 0.5}, 8)), 4)
+}
+
+func TestLimitCPUSlots(t *testing.T) {
+	cpuSlots := model.CPUSlotsConfig{CPUSharesPerSlot: 256, CPULimitPerSlot: 0.5}
+	cont := cproto.Container{Devices: []device.Device{
+		{ID: 0, UUID: "GenuineIntel-0", Type: device.CPU},
+		{ID: 1, UUID: "GenuineIntel-1", Type: device.CPU},
+		{ID: 2, UUID: "GenuineIntel-2", Type: device.CPU},
+	}}
+
+	var resources dcontainer.Resources
+	limitCPUSlots(cont, &resources, cpuSlots)
+	assert.Equal(t, resources.CPUShares, int64(768))
+	assert.Equal(t, resources.NanoCPUs, int64(1.5e9))
+
+	// Containers without CPU slots are left alone.
+	resources = dcontainer.Resources{}
+	limitCPUSlots(cproto.Container{}, &resources, cpuSlots)
+	assert.DeepEqual(t, resources, dcontainer.Resources{})
+}
@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/determined-ai/determined/master/pkg/cproto"
+)
+
+// enrootRuntime runs task containers with enroot.
+type enrootRuntime struct {
+	binary string
+}
+
+func newEnrootRuntime() (*enrootRuntime, error) {
+	path, err := exec.LookPath("enroot")
+	if err != nil {
+		return nil, errors.Wrap(err, "enroot was not found on the PATH")
+	}
+	return &enrootRuntime{binary: path}, nil
+}
+
+func (e *enrootRuntime) imageExtension() string {
+	return ".sqsh"
+}
+
+func (e *enrootRuntime) pullCommand(
+	image reference.Named, dst string, auth *types.AuthConfig,
+) *exec.Cmd {
+	if auth != nil && auth.Username != "" {
+		log.Warn("registry_auth is not supported by enroot, " +
+			"configure registry credentials in enroot's credentials file instead")
+	}
+	// #nosec G204
+	return exec.Command(e.binary, "import", "--output", dst, enrootImageURI(image))
+}
+
+func (e *enrootRuntime) createCommand(image string, rootfs string) *exec.Cmd {
+	// #nosec G204
+	cmd := exec.Command(e.binary, "create", "--force", "--name", filepath.Base(rootfs), image)
+	cmd.Env = enrootEnv(rootfs)
+	return cmd
+}
+
+func (e *enrootRuntime) runCommand(rootfs string, spec cproto.RunSpec) (*exec.Cmd, error) {
+	argv, err := containerCommand(spec)
+	if err != nil {
+		return nil, err
+	}
+	// enroot has no option for the working directory, so change into it from a shell.
+	if wd := spec.ContainerConfig.WorkingDir; wd != "" {
+		argv = append([]string{"/bin/sh", "-c", `cd "$0" && exec "$@"`, wd}, argv...)
+	}
+
+	args := []string{"start", "--rw"}
+	for _, bind := range containerBinds(spec) {
+		args = append(args, "--mount", bind)
+	}
+	for _, kv := range spec.ContainerConfig.Env {
+		args = append(args, "--env", kv)
+	}
+	// enroot's Nvidia hook only exposes the GPUs listed here.
+	visible := "void"
+	if uuids := cudaDeviceUUIDs(spec); len(uuids) > 0 {
+		visible = strings.Join(uuids, ",")
+	}
+	args = append(args, "--env", "NVIDIA_VISIBLE_DEVICES="+visible)
+
+	args = append(args, filepath.Base(rootfs))
+	args = append(args, argv...)
+	// #nosec G204
+	cmd := exec.Command(e.binary, args...)
+	cmd.Env = enrootEnv(rootfs)
+	return cmd, nil
+}
+
+// enrootEnv points enroot at the directory holding the container's root filesystem, so that
+// the container named after it is unpacked exactly there.
+func enrootEnv(rootfs string) []string {
+	return append(os.Environ(), "ENROOT_DATA_PATH="+filepath.Dir(rootfs))
+}
+
+// enrootImageURI converts an image reference to enroot's URI format, which separates the
+// registry from the repository with a '#'.
+func enrootImageURI(image reference.Named) string {
+	path := reference.Path(image)
+	if tagged, ok := image.(reference.Tagged); ok {
+		path += ":" + tagged.Tag()
+	}
+	if digested, ok := image.(reference.Digested); ok {
+		path += "@" + digested.Digest().String()
+	}
+	if domain := reference.Domain(image); domain != "docker.io" {
+		return "docker://" + domain + "#" + path
+	}
+	return "docker://" + path
+}
@@ -87,6 +87,31 @@ func pullImageByName(docker *client.Client, imageName string) error {
 	return nil
 }
 
+// fluentTLSConfig returns the TLS configuration Fluent Bit uses to connect to the logging backend:
+// the master, for backends that are reached through it, or Elasticsearch.
+func fluentTLSConfig(
+	opts Options, loggingConfig model.LoggingConfig,
+) (model.TLSClientConfig, error) {
+	switch l := loggingConfig; {
+	case l.DefaultLoggingConfig != nil, l.CloudWatchLoggingConfig != nil:
+		t := opts.Security.TLS
+		tlsConfig := model.TLSClientConfig{
+			Enabled:         t.Enabled,
+			SkipVerify:      t.SkipVerify,
+			CertificatePath: t.MasterCert,
+			CertificateName: t.MasterCertName,
+		}
+		if err := tlsConfig.Resolve(); err != nil {
+			return model.TLSClientConfig{}, err
+		}
+		return tlsConfig, nil
+	case l.ElasticLoggingConfig != nil:
+		return l.ElasticLoggingConfig.Security.TLS, nil
+	default:
+		return model.TLSClientConfig{}, nil
+	}
+}
+
 // startLoggingContainer starts a Fluent Bit container running in host mode. It returns the port
 // that Fluent Bit is listening on and the ID of the container.
 func startLoggingContainer(
@@ -113,21 +138,9 @@ func startLoggingContainer(
 		masterPort = opts.ContainerMasterPort
 	}
 
-	var tlsConfig model.TLSClientConfig
-	switch l := masterSetOpts.LoggingOptions; {
-	case l.DefaultLoggingConfig != nil:
-		t := opts.Security.TLS
-		tlsConfig = model.TLSClientConfig{
-			Enabled:         t.Enabled,
-			SkipVerify:      t.SkipVerify,
-			CertificatePath: t.MasterCert,
-			CertificateName: t.MasterCertName,
-		}
-		if err := tlsConfig.Resolve(); err != nil {
-			return 0, "", err
-		}
-	case l.ElasticLoggingConfig != nil:
-		tlsConfig = l.ElasticLoggingConfig.Security.TLS
+	tlsConfig, err := fluentTLSConfig(opts, masterSetOpts.LoggingOptions)
+	if err != nil {
+		return 0, "", err
 	}
 
 	const fluentBaseDir = "/run/determined/fluent"
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestFluentTLSConfig(t *testing.T) {
+	opts := Options{Security: SecurityOptions{TLS: TLSOptions{
+		Enabled:        true,
+		SkipVerify:     true,
+		MasterCertName: "master.example.com",
+	}}}
+	masterTLS := model.TLSClientConfig{
+		Enabled:         true,
+		SkipVerify:      true,
+		CertificateName: "master.example.com",
+	}
+	elasticTLS := model.TLSClientConfig{Enabled: true, CertificateName: "elastic.example.com"}
+
+	for _, tc := range []struct {
+		name          string
+		loggingConfig model.LoggingConfig
+		expected      model.TLSClientConfig
+	}{
+		{
+			name:          "default",
+			loggingConfig: model.LoggingConfig{DefaultLoggingConfig: &model.DefaultLoggingConfig{}},
+			expected:      masterTLS,
+		},
+		{
+			name: "cloudwatch",
+			loggingConfig: model.LoggingConfig{
+				CloudWatchLoggingConfig: &model.CloudWatchLoggingConfig{},
+			},
+			expected: masterTLS,
+		},
+		{
+			name: "elastic",
+			loggingConfig: model.LoggingConfig{ElasticLoggingConfig: &model.ElasticLoggingConfig{
+				Security: model.ElasticSecurityConfig{TLS: elasticTLS},
+			}},
+			expected: elasticTLS,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tlsConfig, err := fluentTLSConfig(opts, tc.loggingConfig)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, tlsConfig, tc.expected)
+		})
+	}
+}
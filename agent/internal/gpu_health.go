@@ -0,0 +1,267 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/actor/actors"
+	"github.com/determined-ai/determined/master/pkg/aproto"
+	"github.com/determined-ai/determined/master/pkg/device"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+var (
+	gpuHealthQueryArgs = []string{
+		"nvidia-smi",
+		"--query-gpu=uuid,pci.bus_id,temperature.gpu,ecc.errors.uncorrected.volatile.total",
+		"--format=csv,noheader,nounits",
+	}
+	kernelLogArgs       = []string{"dmesg"}
+	kernelLogTimeRegExp = regexp.MustCompile(`^\[\s*(\d+\.\d+)\]`)
+	xidRegExp           = regexp.MustCompile(`NVRM: Xid \(PCI:([0-9a-fA-F:.]+)\): (\d+)`)
+)
+
+type checkGPUHealth struct{}
+
+// gpuHealthRecord is a single row of the nvidia-smi health query.
+type gpuHealthRecord struct {
+	uuid        string
+	busID       string
+	temperature int
+	eccErrors   int
+}
+
+// gpuHealthMonitor periodically checks the health of the agent's Nvidia GPUs and reports it to
+// its parent, which forwards it to the master.
+type gpuHealthMonitor struct {
+	options GPUHealthOptions
+	devices map[string]device.ID
+	// xids are the critical Xid errors seen so far for each device. They are sticky: a GPU that
+	// raised one stays unhealthy even once the message rotates out of the kernel log.
+	xids map[device.ID]map[int]bool
+
+	// kernelLogEnd is the timestamp, in seconds since boot, of the last kernel log entry that has
+	// been scanned. The log as it was when the agent started is skipped, since its Xid errors were
+	// raised before the agent last started too, and would mark GPUs unhealthy after every restart.
+	kernelLogEnd         float64
+	kernelLogStarted     bool
+	kernelLogUnavailable bool
+}
+
+func newGPUHealthMonitor(options GPUHealthOptions, devices []device.Device) *gpuHealthMonitor {
+	m := &gpuHealthMonitor{
+		options: options,
+		devices: make(map[string]device.ID),
+		xids:    make(map[device.ID]map[int]bool),
+	}
+	for _, d := range devices {
+		if d.Type == device.CUDA && !strings.HasPrefix(d.UUID, "MIG") {
+			m.devices[d.UUID] = d.ID
+		}
+	}
+	return m
+}
+
+func (m *gpuHealthMonitor) Receive(ctx *actor.Context) error {
+	switch ctx.Message().(type) {
+	case actor.PreStart:
+		ctx.Tell(ctx.Self(), checkGPUHealth{})
+	case checkGPUHealth:
+		report, err := m.check(ctx)
+		if err != nil {
+			ctx.Log().WithError(err).Warn("error checking GPU health")
+		} else if len(report.Devices) > 0 {
+			ctx.Tell(ctx.Self().Parent(), report)
+		}
+		actors.NotifyAfter(ctx, time.Duration(m.options.CheckPeriod)*time.Second, checkGPUHealth{})
+	case actor.PostStop:
+	default:
+		return actor.ErrUnexpectedMessage(ctx)
+	}
+	return nil
+}
+
+func (m *gpuHealthMonitor) check(ctx *actor.Context) (aproto.DeviceHealthReport, error) {
+	// #nosec G204
+	out, err := exec.Command(gpuHealthQueryArgs[0], gpuHealthQueryArgs[1:]...).Output()
+	if err != nil {
+		return aproto.DeviceHealthReport{}, errors.Wrapf(
+			err, "error while executing nvidia-smi: %s", string(out))
+	}
+	records, err := parseGPUHealthQuery(out)
+	if err != nil {
+		return aproto.DeviceHealthReport{}, err
+	}
+
+	xidsByBusID := map[string][]int{}
+	if !m.kernelLogUnavailable {
+		// #nosec G204
+		out, err = exec.Command(kernelLogArgs[0], kernelLogArgs[1:]...).Output()
+		if err != nil {
+			// Reading the kernel log usually requires root; don't keep trying if we can't.
+			ctx.Log().WithError(err).Warn("unable to read kernel log, Xid errors will not be monitored")
+			m.kernelLogUnavailable = true
+		} else if !m.kernelLogStarted {
+			_, m.kernelLogEnd = parseXidErrors(out, 0)
+			m.kernelLogStarted = true
+			if m.kernelLogEnd == 0 && len(out) > 0 {
+				// Without timestamps, new entries can't be told apart from those before we started.
+				ctx.Log().Warn("kernel log has no timestamps, Xid errors will not be monitored")
+				m.kernelLogUnavailable = true
+			}
+		} else {
+			xidsByBusID, m.kernelLogEnd = parseXidErrors(out, m.kernelLogEnd)
+		}
+	}
+
+	report := aproto.DeviceHealthReport{Devices: map[device.ID]model.DeviceHealth{}}
+	now := time.Now().UTC()
+	for _, r := range records {
+		id, ok := m.devices[r.uuid]
+		if !ok {
+			continue
+		}
+		if m.xids[id] == nil {
+			m.xids[id] = map[int]bool{}
+		}
+		for _, xid := range xidsByBusID[normalizeBusID(r.busID)] {
+			if m.options.isCriticalXid(xid) {
+				m.xids[id][xid] = true
+			}
+		}
+
+		health := model.DeviceHealth{
+			EccErrors:   r.eccErrors,
+			Temperature: r.temperature,
+			ReportTime:  now,
+		}
+		for xid := range m.xids[id] {
+			health.XidErrors = append(health.XidErrors, xid)
+		}
+		sort.Ints(health.XidErrors)
+		m.options.evaluate(&health)
+		report.Devices[id] = health
+	}
+	return report, nil
+}
+
+// evaluate decides whether a device is healthy based on its reported errors and temperature.
+func (o GPUHealthOptions) evaluate(health *model.DeviceHealth) {
+	var reasons []string
+	if len(health.XidErrors) > 0 {
+		reasons = append(reasons, fmt.Sprintf("critical Xid errors %v", health.XidErrors))
+	}
+	if health.EccErrors > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d uncorrected ECC errors", health.EccErrors))
+	}
+	if o.MaxTemperature > 0 && health.Temperature > o.MaxTemperature {
+		reasons = append(reasons, fmt.Sprintf(
+			"temperature %dC exceeds %dC", health.Temperature, o.MaxTemperature))
+	}
+	health.Healthy = len(reasons) == 0
+	health.Reason = strings.Join(reasons, "; ")
+}
+
+func (o GPUHealthOptions) isCriticalXid(xid int) bool {
+	for _, critical := range o.CriticalXids {
+		if xid == critical {
+			return true
+		}
+	}
+	return false
+}
+
+func parseGPUHealthQuery(out []byte) ([]gpuHealthRecord, error) {
+	var records []gpuHealthRecord
+	r := csv.NewReader(bytes.NewReader(out))
+	for {
+		record, err := r.Read()
+		switch {
+		case err == io.EOF:
+			return records, nil
+		case err != nil:
+			return nil, errors.Wrap(err, "error parsing output of nvidia-smi as CSV")
+		case len(record) != 4:
+			return nil, errors.New(
+				"error parsing output of nvidia-smi; GPU health record should have exactly 4 fields")
+		}
+
+		records = append(records, gpuHealthRecord{
+			uuid:        strings.TrimSpace(record[0]),
+			busID:       strings.TrimSpace(record[1]),
+			temperature: parseGPUHealthValue(record[2]),
+			eccErrors:   parseGPUHealthValue(record[3]),
+		})
+	}
+}
+
+// parseGPUHealthValue parses a numeric nvidia-smi field, treating unsupported values (e.g. ECC
+// counts on GPUs without ECC, reported as "[N/A]") as zero.
+func parseGPUHealthValue(s string) int {
+	v, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// parseXidErrors returns the Xid error codes found in the kernel log after the given timestamp,
+// by normalized PCI bus ID, along with the timestamp of the last entry in the log. Timestamps are
+// the seconds since boot that dmesg prefixes entries with; entries without one are continuations
+// of the previous entry.
+func parseXidErrors(out []byte, after float64) (map[string][]int, float64) {
+	xids := map[string][]int{}
+	end := after
+	var ts float64
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if matches := kernelLogTimeRegExp.FindStringSubmatch(line); len(matches) == 2 {
+			if v, err := strconv.ParseFloat(matches[1], 64); err == nil {
+				ts = v
+			}
+		}
+		if ts <= after {
+			continue
+		}
+		end = ts
+
+		matches := xidRegExp.FindStringSubmatch(line)
+		if len(matches) != 3 {
+			continue
+		}
+		xid, err := strconv.Atoi(matches[2])
+		if err != nil {
+			continue
+		}
+		busID := normalizeBusID(matches[1])
+		xids[busID] = append(xids[busID], xid)
+	}
+	return xids, end
+}
+
+// normalizeBusID reduces a PCI bus ID to its bus and device numbers, since nvidia-smi
+// ("00000000:3B:00.0") and the kernel log ("0000:3b:00") format it differently.
+func normalizeBusID(busID string) string {
+	parts := strings.Split(strings.ToLower(busID), ":")
+	if len(parts) < 2 {
+		return strings.ToLower(busID)
+	}
+	bus, dev := parts[len(parts)-2], parts[len(parts)-1]
+	if i := strings.Index(dev, "."); i >= 0 {
+		dev = dev[:i]
+	}
+	return bus + ":" + dev
+}
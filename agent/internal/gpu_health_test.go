@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+const testGPUHealthQueryData = `GPU-0b0ff6b6-5e31-43b4-9f4f-9fd5c1e2ff6c, 00000000:3B:00.0, 45, 0
+GPU-6a1c0e5a-a8e8-4e1a-8e1c-7c2c4c1d8a2b, 00000000:5E:00.0, 91, [N/A]
+`
+
+const testKernelLogData = `[    4.123456] nvidia: loading out-of-tree module taints kernel.
+[ 1234.567890] NVRM: Xid (PCI:0000:3b:00): 79, pid=0, GPU has fallen off the bus.
+[ 1240.000000] NVRM: Xid (PCI:0000:5e:00): 13, pid=1234, Graphics Exception
+`
+
+func TestParseGPUHealthQuery(t *testing.T) {
+	records, err := parseGPUHealthQuery([]byte(testGPUHealthQueryData))
+	assert.NilError(t, err)
+	assert.Equal(t, len(records), 2)
+	assert.Equal(t, records[0].uuid, "GPU-0b0ff6b6-5e31-43b4-9f4f-9fd5c1e2ff6c")
+	assert.Equal(t, normalizeBusID(records[0].busID), "3b:00")
+	assert.Equal(t, records[1].temperature, 91)
+	assert.Equal(t, records[1].eccErrors, 0)
+}
+
+func TestParseXidErrors(t *testing.T) {
+	xids, end := parseXidErrors([]byte(testKernelLogData), 0)
+	assert.DeepEqual(t, xids, map[string][]int{"3b:00": {79}, "5e:00": {13}})
+	assert.Equal(t, end, 1240.0)
+
+	// Only entries after the given timestamp are scanned.
+	xids, end = parseXidErrors([]byte(testKernelLogData), 1234.56789)
+	assert.DeepEqual(t, xids, map[string][]int{"5e:00": {13}})
+	assert.Equal(t, end, 1240.0)
+
+	xids, end = parseXidErrors([]byte(testKernelLogData), end)
+	assert.DeepEqual(t, xids, map[string][]int{})
+	assert.Equal(t, end, 1240.0)
+}
+
+func TestEvaluateGPUHealth(t *testing.T) {
+	options := GPUHealthOptions{MaxTemperature: 90, CriticalXids: []int{79}}
+	assert.Assert(t, !options.isCriticalXid(13))
+
+	healthy := model.DeviceHealth{Temperature: 45}
+	options.evaluate(&healthy)
+	assert.Assert(t, healthy.Healthy)
+	assert.Equal(t, healthy.Reason, "")
+
+	unhealthy := model.DeviceHealth{XidErrors: []int{79}, Temperature: 91}
+	options.evaluate(&unhealthy)
+	assert.Assert(t, !unhealthy.Healthy)
+	assert.Equal(t, unhealthy.Reason, "critical Xid errors [79]; temperature 91C exceeds 90C")
+}
@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/actor/actors"
+	"github.com/determined-ai/determined/master/pkg/aproto"
+)
+
+const imageCacheCheckPeriod = time.Minute
+
+type checkImageCache struct{}
+
+// imageCache periodically reports the images cached by Docker to its parent, which forwards them
+// to the master, and keeps them under the limit set by the master by evicting the least recently
+// used images that no container uses.
+type imageCache struct {
+	docker     *client.Client
+	limitBytes int64
+	// lastUsed is when each image was last seen in use by a container. Docker doesn't track when
+	// images are used, so images that weren't seen in use fall back to when they were created.
+	lastUsed map[string]time.Time
+}
+
+func newImageCache(limitBytes int64) *imageCache {
+	return &imageCache{limitBytes: limitBytes, lastUsed: make(map[string]time.Time)}
+}
+
+func (c *imageCache) Receive(ctx *actor.Context) error {
+	switch msg := ctx.Message().(type) {
+	case actor.PreStart:
+		d, err := client.NewClientWithOpts(client.WithAPIVersionNegotiation(), client.FromEnv)
+		if err != nil {
+			return err
+		}
+		c.docker = d
+		ctx.Tell(ctx.Self(), checkImageCache{})
+
+	case checkImageCache:
+		c.check(ctx)
+		actors.NotifyAfter(ctx, imageCacheCheckPeriod, checkImageCache{})
+
+	case aproto.SetImageCacheLimit:
+		ctx.Log().Infof("setting image cache limit to %d bytes", msg.LimitBytes)
+		c.limitBytes = msg.LimitBytes
+		c.check(ctx)
+
+	case aproto.EvictImages:
+		images, err := c.list()
+		if err != nil {
+			ctx.Log().WithError(err).Warn("error listing cached images")
+			return nil
+		}
+		for _, ref := range msg.Images {
+			image := findCachedImage(images, ref)
+			switch {
+			case image == nil:
+				ctx.Log().Warnf("not evicting image %s: it is not cached", ref)
+			case image.InUse:
+				ctx.Log().Warnf("not evicting image %s: it is in use by a container", ref)
+			default:
+				c.remove(ctx, *image)
+			}
+		}
+		c.check(ctx)
+
+	case actor.PostStop:
+		if c.docker != nil {
+			if err := c.docker.Close(); err != nil {
+				ctx.Log().WithError(err).Warn("error closing Docker client")
+			}
+		}
+
+	default:
+		return actor.ErrUnexpectedMessage(ctx)
+	}
+	return nil
+}
+
+// check evicts images over the limit and reports the images that are left.
+func (c *imageCache) check(ctx *actor.Context) {
+	images, err := c.list()
+	if err != nil {
+		ctx.Log().WithError(err).Warn("error listing cached images")
+		return
+	}
+
+	for _, image := range imagesToEvict(images, c.limitBytes) {
+		if c.remove(ctx, image) {
+			ctx.Log().Infof("evicted image %s to keep the image cache under %d bytes",
+				image.ID, c.limitBytes)
+		}
+	}
+
+	if images, err = c.list(); err != nil {
+		ctx.Log().WithError(err).Warn("error listing cached images")
+		return
+	}
+	ctx.Tell(ctx.Self().Parent(), aproto.ImageCacheReport{Images: images, LimitBytes: c.limitBytes})
+}
+
+func (c *imageCache) list() ([]aproto.CachedImage, error) {
+	containers, err := c.docker.ContainerList(
+		context.Background(), types.ContainerListOptions{All: true})
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing containers")
+	}
+	inUse := make(map[string]bool)
+	now := time.Now().UTC()
+	for _, cont := range containers {
+		inUse[cont.ImageID] = true
+		c.lastUsed[cont.ImageID] = now
+	}
+
+	summaries, err := c.docker.ImageList(context.Background(), types.ImageListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing images")
+	}
+	images := make([]aproto.CachedImage, 0, len(summaries))
+	cached := make(map[string]bool)
+	for _, s := range summaries {
+		image := aproto.CachedImage{
+			ID:      s.ID,
+			Tags:    s.RepoTags,
+			Size:    s.Size,
+			Created: time.Unix(s.Created, 0).UTC(),
+			InUse:   inUse[s.ID],
+		}
+		image.LastUsed = image.Created
+		if lastUsed, ok := c.lastUsed[s.ID]; ok {
+			image.LastUsed = lastUsed
+		}
+		images = append(images, image)
+		cached[s.ID] = true
+	}
+
+	for id := range c.lastUsed {
+		if !cached[id] {
+			delete(c.lastUsed, id)
+		}
+	}
+	return images, nil
+}
+
+// remove removes an image along with all of its tags, returning whether it succeeded.
+func (c *imageCache) remove(ctx *actor.Context, image aproto.CachedImage) bool {
+	if _, err := c.docker.ImageRemove(context.Background(), image.ID, types.ImageRemoveOptions{
+		Force:         true,
+		PruneChildren: true,
+	}); err != nil {
+		ctx.Log().WithError(err).Warnf("error removing image %s", image.ID)
+		return false
+	}
+	delete(c.lastUsed, image.ID)
+	return true
+}
+
+// imagesToEvict returns the least recently used images that aren't in use which must be removed
+// for the images to fit in limitBytes. Since images can share layers, this may evict more than
+// needed.
+func imagesToEvict(images []aproto.CachedImage, limitBytes int64) []aproto.CachedImage {
+	if limitBytes <= 0 {
+		return nil
+	}
+
+	var total int64
+	var candidates []aproto.CachedImage
+	for _, image := range images {
+		total += image.Size
+		if !image.InUse {
+			candidates = append(candidates, image)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].LastUsed.Before(candidates[j].LastUsed)
+	})
+
+	var evict []aproto.CachedImage
+	for _, image := range candidates {
+		if total <= limitBytes {
+			break
+		}
+		evict = append(evict, image)
+		total -= image.Size
+	}
+	return evict
+}
+
+// findCachedImage finds an image by its ID, a prefix of its ID or one of its tags.
+func findCachedImage(images []aproto.CachedImage, ref string) *aproto.CachedImage {
+	idPrefix := strings.TrimPrefix(ref, "sha256:")
+	for i, image := range images {
+		id := strings.TrimPrefix(image.ID, "sha256:")
+		if idPrefix != "" && strings.HasPrefix(id, idPrefix) {
+			return &images[i]
+		}
+		for _, tag := range image.Tags {
+			if ref == tag || ref+":latest" == tag {
+				return &images[i]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/determined-ai/determined/master/pkg/aproto"
+)
+
+func testCachedImages() []aproto.CachedImage {
+	now := time.Now()
+	return []aproto.CachedImage{
+		{ID: "sha256:aaaa1111", Tags: []string{"busybox:latest"}, Size: 100, LastUsed: now},
+		{ID: "sha256:bbbb2222", Tags: []string{"ubuntu:20.04"}, Size: 300, LastUsed: now.Add(-time.Hour)},
+		{ID: "sha256:cccc3333", Size: 200, LastUsed: now.Add(-2 * time.Hour)},
+		{ID: "sha256:dddd4444", Size: 400, LastUsed: now.Add(-3 * time.Hour), InUse: true},
+	}
+}
+
+func imageIDs(images []aproto.CachedImage) []string {
+	var ids []string
+	for _, image := range images {
+		ids = append(ids, image.ID)
+	}
+	return ids
+}
+
+func TestImagesToEvict(t *testing.T) {
+	images := testCachedImages()
+
+	assert.Equal(t, len(imagesToEvict(images, 0)), 0)
+	assert.Equal(t, len(imagesToEvict(images, 1000)), 0)
+	// The least recently used images go first, and images in use are never evicted.
+	assert.DeepEqual(t, imageIDs(imagesToEvict(images, 800)), []string{"sha256:cccc3333"})
+	assert.DeepEqual(t, imageIDs(imagesToEvict(images, 500)),
+		[]string{"sha256:cccc3333", "sha256:bbbb2222"})
+	assert.DeepEqual(t, imageIDs(imagesToEvict(images, 100)),
+		[]string{"sha256:cccc3333", "sha256:bbbb2222", "sha256:aaaa1111"})
+}
+
+func TestFindCachedImage(t *testing.T) {
+	images := testCachedImages()
+
+	for ref, id := range map[string]string{
+		"sha256:bbbb2222": "sha256:bbbb2222",
+		"bbbb":            "sha256:bbbb2222",
+		"busybox":         "sha256:aaaa1111",
+		"ubuntu:20.04":    "sha256:bbbb2222",
+	} {
+		image := findCachedImage(images, ref)
+		assert.Assert(t, image != nil, ref)
+		assert.Equal(t, image.ID, id)
+	}
+	assert.Assert(t, findCachedImage(images, "ubuntu") == nil)
+	assert.Assert(t, findCachedImage(images, "") == nil)
+}
@@ -22,8 +22,9 @@ type Options struct {
 	ContainerMasterHost string `json:"container_master_host"`
 	ContainerMasterPort int    `json:"container_master_port"`
 
-	Label        string `json:"label"`
-	ResourcePool string `json:"resource_pool"`
+	Label        string            `json:"label"`
+	Labels       map[string]string `json:"labels"`
+	ResourcePool string            `json:"resource_pool"`
 
 	APIEnabled bool   `json:"api_enabled"`
 	BindIP     string `json:"bind_ip"`
@@ -52,6 +53,15 @@ type Options struct {
 	AgentReconnectBackoff int `json:"agent_reconnect_backoff"`
 
 	Hooks HooksOptions `json:"hooks"`
+
+	GPUHealth GPUHealthOptions `json:"gpu_health"`
+
+	// ContainerRuntimeWorkDir holds the images and container root filesystems of the Apptainer
+	// and enroot runtimes, and the files copied into containers by Podman. It is unused with
+	// Docker.
+	ContainerRuntimeWorkDir string `json:"container_runtime_work_dir"`
+
+	Podman PodmanOptions `json:"podman"`
 }
 
 // Validate validates the state of the Options struct.
@@ -125,6 +135,25 @@ type FluentOptions struct {
 	ContainerName string `json:"container_name"`
 }
 
+// GPUHealthOptions configures monitoring of the health of the agent's Nvidia GPUs. Unhealthy GPUs
+// are reported to the master, which stops scheduling on them.
+type GPUHealthOptions struct {
+	// CheckPeriod is the time between health checks in seconds; 0 disables monitoring.
+	CheckPeriod int `json:"check_period"`
+	// MaxTemperature is the temperature in Celsius above which a GPU is unhealthy; 0 disables
+	// the temperature check.
+	MaxTemperature int `json:"max_temperature"`
+	// CriticalXids are the Xid error codes that make a GPU unhealthy until the agent restarts.
+	CriticalXids []int `json:"critical_xids"`
+}
+
+// PodmanOptions configures the Podman container runtime.
+type PodmanOptions struct {
+	// UserNS is the user namespace mode of task containers, such as "keep-id" or "auto". Rootless
+	// Podman defaults to "keep-id", which maps the agent's user to itself in containers.
+	UserNS string `json:"userns"`
+}
+
 // HooksOptions contains external commands to be run when specific things happen.
 type HooksOptions struct {
 	OnConnectionLost []string `json:"on_connection_lost"`
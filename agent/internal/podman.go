@@ -0,0 +1,226 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+
+	"github.com/determined-ai/determined/master/pkg/cproto"
+)
+
+// cgroupV2Controllers only exists on hosts that use the unified cgroup v2 hierarchy.
+const cgroupV2Controllers = "/sys/fs/cgroup/cgroup.controllers"
+
+// podmanScript creates the container, copies the files of the task into it and attaches to it.
+// The files are copied rather than unpacked into a root filesystem, since the container runs from
+// Podman's image store.
+const podmanScript = `"$0" "$@" >/dev/null || exit 1
+if [ -d "$DET_CONTAINER_FILES" ] && ! "$0" cp "$DET_CONTAINER_FILES/." "$DET_CONTAINER_NAME:/"; then
+	"$0" rm --force "$DET_CONTAINER_NAME" >/dev/null
+	exit 1
+fi
+exec "$0" start --attach "$DET_CONTAINER_NAME"`
+
+// podmanRuntime runs task containers with Podman, which needs no daemon and, unlike Docker, can
+// run containers without root privileges.
+type podmanRuntime struct {
+	binary string
+	// rootless is whether the agent, and so Podman, runs without root privileges.
+	rootless bool
+	// cgroupV2 is whether the host uses cgroup v2, which rootless Podman needs to limit the
+	// resources of containers.
+	cgroupV2 bool
+	userNS   string
+}
+
+func newPodmanRuntime(options PodmanOptions) (*podmanRuntime, error) {
+	path, err := exec.LookPath("podman")
+	if err != nil {
+		return nil, errors.Wrap(err, "podman was not found on the PATH")
+	}
+	_, err = os.Stat(cgroupV2Controllers)
+	p := &podmanRuntime{
+		binary:   path,
+		rootless: os.Geteuid() != 0,
+		cgroupV2: err == nil,
+		userNS:   options.UserNS,
+	}
+	if p.rootless && p.userNS == "" {
+		// Map the agent's user to the same ID in the container, so that the files of bind mounts
+		// that the agent's user owns are owned by the task's user too if it's the same.
+		p.userNS = "keep-id"
+	}
+	if p.rootless && !p.cgroupV2 {
+		log.Warn("podman is running rootless on a host without cgroup v2, " +
+			"so the resources of task containers cannot be limited")
+	}
+	return p, nil
+}
+
+func (p *podmanRuntime) imageExtension() string {
+	return ""
+}
+
+func (p *podmanRuntime) imageExistsCommand(image reference.Named) *exec.Cmd {
+	// #nosec G204
+	return exec.Command(p.binary, "image", "exists", image.String())
+}
+
+func (p *podmanRuntime) pullCommand(
+	image reference.Named, _ string, auth *types.AuthConfig,
+) *exec.Cmd {
+	if auth != nil && auth.Username != "" {
+		log.Warn("registry_auth is not supported by podman, " +
+			"log in to the registry with `podman login` as the agent's user instead")
+	}
+	// #nosec G204
+	return exec.Command(p.binary, "pull", image.String())
+}
+
+func (p *podmanRuntime) createCommand(string, string) *exec.Cmd {
+	return nil
+}
+
+func (p *podmanRuntime) runCommand(rootfs string, spec cproto.RunSpec) (*exec.Cmd, error) {
+	argv, err := containerCommand(spec)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := reference.ParseNormalizedNamed(spec.ContainerConfig.Image)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing image name: %s", spec.ContainerConfig.Image)
+	}
+	entrypoint, err := json.Marshal(argv[:1])
+	if err != nil {
+		return nil, err
+	}
+
+	name := podmanContainerName(rootfs)
+	args, env := p.createArgs(name, spec)
+	args = append(args, "--entrypoint", string(entrypoint), reference.TagNameOnly(ref).String())
+	args = append(args, argv[1:]...)
+
+	// #nosec G204
+	cmd := exec.Command("/bin/sh", append([]string{"-c", podmanScript, p.binary}, args...)...)
+	cmd.Env = append(env, "DET_CONTAINER_FILES="+rootfs, "DET_CONTAINER_NAME="+name)
+	return cmd, nil
+}
+
+// createArgs returns the arguments of `podman create`, except for the entrypoint and image, and
+// the environment to run it with.
+func (p *podmanRuntime) createArgs(name string, spec cproto.RunSpec) ([]string, []string) {
+	args := []string{"create", "--name", name, "--network", "host"}
+	if spec.HostConfig.AutoRemove {
+		args = append(args, "--rm")
+	}
+	if p.userNS != "" {
+		args = append(args, "--userns", p.userNS)
+	}
+	if user := spec.ContainerConfig.User; user != "" {
+		args = append(args, "--user", user)
+	}
+	if wd := spec.ContainerConfig.WorkingDir; wd != "" {
+		args = append(args, "--workdir", wd)
+	}
+	labels := maps.Keys(spec.ContainerConfig.Labels)
+	sort.Strings(labels)
+	for _, k := range labels {
+		args = append(args, "--label", k+"="+spec.ContainerConfig.Labels[k])
+	}
+
+	// Pass the variables by name only, so that their values don't show up in the process list.
+	env := os.Environ()
+	for _, kv := range spec.ContainerConfig.Env {
+		if i := strings.Index(kv, "="); i > 0 {
+			args = append(args, "--env", kv[:i])
+			env = append(env, kv)
+		}
+	}
+
+	for _, bind := range spec.HostConfig.Binds {
+		args = append(args, "--volume", bind)
+	}
+	for _, m := range spec.HostConfig.Mounts {
+		if m.Type != mount.TypeBind {
+			continue
+		}
+		bind := m.Source + ":" + m.Target
+		if m.ReadOnly {
+			bind += ":ro"
+		}
+		args = append(args, "--volume", bind)
+	}
+	for _, d := range spec.HostConfig.Devices {
+		device := d.PathOnHost + ":" + d.PathInContainer
+		if d.CgroupPermissions != "" {
+			device += ":" + d.CgroupPermissions
+		}
+		args = append(args, "--device", device)
+	}
+	// GPUs are exposed through the Container Device Interface specification that the Nvidia
+	// Container Toolkit generates.
+	if uuids := cudaDeviceUUIDs(spec); len(uuids) > 0 {
+		for _, uuid := range uuids {
+			args = append(args, "--device", "nvidia.com/gpu="+uuid)
+		}
+		args = append(args, "--security-opt", "label=disable")
+	}
+
+	for _, c := range spec.HostConfig.CapAdd {
+		args = append(args, "--cap-add", c)
+	}
+	for _, c := range spec.HostConfig.CapDrop {
+		args = append(args, "--cap-drop", c)
+	}
+	if shm := spec.HostConfig.ShmSize; shm > 0 {
+		args = append(args, "--shm-size", fmt.Sprintf("%db", shm))
+	}
+	return append(args, p.resourceArgs(spec)...), env
+}
+
+// resourceArgs returns the arguments that limit the resources of the container. Rootless Podman
+// can only limit them through cgroup v2.
+func (p *podmanRuntime) resourceArgs(spec cproto.RunSpec) []string {
+	var args []string
+	r := spec.HostConfig.Resources
+	if r.Memory > 0 {
+		args = append(args, "--memory", fmt.Sprintf("%db", r.Memory))
+	}
+	if r.NanoCPUs > 0 {
+		args = append(args, "--cpus", fmt.Sprintf("%g", float64(r.NanoCPUs)/1e9))
+	}
+	if r.CPUShares > 0 {
+		args = append(args, "--cpu-shares", fmt.Sprint(r.CPUShares))
+	}
+	if r.PidsLimit != nil && *r.PidsLimit > 0 {
+		args = append(args, "--pids-limit", fmt.Sprint(*r.PidsLimit))
+	}
+	if len(args) > 0 && p.rootless && !p.cgroupV2 {
+		log.Warnf("not limiting the resources of container: %s", strings.Join(args, " "))
+		return nil
+	}
+	return args
+}
+
+func (p *podmanRuntime) signalCommand(rootfs string, signal syscall.Signal) *exec.Cmd {
+	// #nosec G204
+	return exec.Command(p.binary, "kill", "--signal", fmt.Sprint(int(signal)),
+		podmanContainerName(rootfs))
+}
+
+// podmanContainerName names the Podman container of a task container after its ID.
+func podmanContainerName(rootfs string) string {
+	return "determined-" + filepath.Base(rootfs)
+}
@@ -0,0 +1,518 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/aproto"
+	"github.com/determined-ai/determined/master/pkg/cproto"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+)
+
+// processRuntime is a daemonless container runtime, such as Apptainer or enroot, that runs each
+// task container as a child process of the agent from a root filesystem unpacked from its image.
+type processRuntime interface {
+	// imageExtension is the file extension of the runtime's cached images.
+	imageExtension() string
+	// pullCommand returns the command that fetches the image into the file dst.
+	pullCommand(image reference.Named, dst string, auth *types.AuthConfig) *exec.Cmd
+	// createCommand returns the command that unpacks the cached image into the directory rootfs,
+	// or nil if there is nothing to unpack.
+	createCommand(image string, rootfs string) *exec.Cmd
+	// runCommand returns the command that runs the container from its root filesystem.
+	runCommand(rootfs string, spec cproto.RunSpec) (*exec.Cmd, error)
+}
+
+// imageStoreRuntime is a process runtime that keeps images in a store of its own, like Podman,
+// rather than in files of the agent. It pulls images into its store, with an empty dst, and runs
+// containers from there; their root filesystem only holds the files to copy into them.
+type imageStoreRuntime interface {
+	// imageExistsCommand returns the command that succeeds if the image is in the store.
+	imageExistsCommand(image reference.Named) *exec.Cmd
+}
+
+// signalRuntime is a process runtime whose containers don't run in the process group of the
+// command that runs them, so they are signaled through the runtime.
+type signalRuntime interface {
+	// signalCommand returns the command that sends the signal to the container.
+	signalCommand(rootfs string, signal syscall.Signal) *exec.Cmd
+}
+
+// newProcessRuntime returns the process runtime with the given name, or nil for Docker.
+func newProcessRuntime(name string, options Options) (processRuntime, error) {
+	switch name {
+	case "", aproto.ContainerRuntimeDocker:
+		return nil, nil
+	case aproto.ContainerRuntimeApptainer:
+		return newApptainerRuntime()
+	case aproto.ContainerRuntimeEnroot:
+		return newEnrootRuntime()
+	case aproto.ContainerRuntimePodman:
+		return newPodmanRuntime(options.Podman)
+	default:
+		return nil, errors.Errorf("unsupported container runtime: %s", name)
+	}
+}
+
+var imageFileNameRegExp = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// processRuntimeActor plays the role of the dockerActor for process runtimes: it pulls the
+// image, runs the container and reports back to the container actor with the same messages.
+type processRuntimeActor struct {
+	runtime     processRuntime
+	workDir     string
+	containerID cproto.ID
+
+	mu      sync.Mutex
+	process *os.Process
+}
+
+func newProcessRuntimeActor(
+	runtime processRuntime, workDir string, containerID cproto.ID,
+) *processRuntimeActor {
+	return &processRuntimeActor{runtime: runtime, workDir: workDir, containerID: containerID}
+}
+
+func (p *processRuntimeActor) Receive(ctx *actor.Context) error {
+	switch msg := ctx.Message().(type) {
+	case actor.PreStart:
+	case pullImage:
+		go p.pullImage(ctx, msg)
+
+	case runContainer:
+		go p.runContainer(ctx, msg.RunSpec)
+
+	case signalContainer:
+		go p.signalContainer(ctx, msg)
+
+	case actor.PostStop:
+	}
+	return nil
+}
+
+func (p *processRuntimeActor) imagePath(image reference.Named) string {
+	name := imageFileNameRegExp.ReplaceAllString(image.String(), "_")
+	return filepath.Join(p.workDir, "images", name+p.runtime.imageExtension())
+}
+
+func (p *processRuntimeActor) rootfs() string {
+	return filepath.Join(p.workDir, "containers", p.containerID.String())
+}
+
+func (p *processRuntimeActor) pullImage(ctx *actor.Context, msg pullImage) {
+	ref, err := reference.ParseNormalizedNamed(msg.Name)
+	if err != nil {
+		sendErr(ctx, errors.Wrapf(err, "error parsing image name: %s", msg.Name))
+		return
+	}
+	ref = reference.TagNameOnly(ref)
+
+	exists, err := p.imageExists(ref)
+	switch {
+	case err != nil:
+		sendErr(ctx, errors.Wrapf(err, "error checking if image exists: %s", ref.String()))
+		return
+	case exists && !msg.ForcePull:
+		p.sendAuxLog(ctx, ptrs.Ptr(model.LogLevelInfo),
+			fmt.Sprintf("image already found, skipping pull phase: %s", ref.String()))
+		ctx.Tell(ctx.Sender(), imagePulled{})
+		return
+	case exists:
+		p.sendAuxLog(ctx, ptrs.Ptr(model.LogLevelInfo), fmt.Sprintf(
+			"image present, but force_pull_image is set; pulling it again: %s", ref.String()))
+	default:
+		p.sendAuxLog(ctx, ptrs.Ptr(model.LogLevelInfo),
+			fmt.Sprintf("image not found, pulling image: %s", ref.String()))
+	}
+
+	now := time.Now().UTC()
+	ctx.Tell(ctx.Self().Parent(), aproto.ContainerStatsRecord{
+		EndStats: false,
+		TaskType: model.TaskType(msg.TaskType),
+		Stats: &model.TaskStats{
+			AllocationID: msg.AllocationID,
+			EventType:    "IMAGEPULL",
+			StartTime:    &now,
+		},
+	})
+	defer func() {
+		now := time.Now().UTC()
+		ctx.Tell(ctx.Self().Parent(), aproto.ContainerStatsRecord{
+			EndStats: true,
+			TaskType: model.TaskType(msg.TaskType),
+			Stats: &model.TaskStats{
+				AllocationID: msg.AllocationID,
+				EventType:    "IMAGEPULL",
+				EndTime:      &now,
+			},
+		})
+	}()
+
+	if _, ok := p.runtime.(imageStoreRuntime); ok {
+		if err = p.runLogged(ctx, p.runtime.pullCommand(ref, "", msg.Registry)); err != nil {
+			sendErr(ctx, errors.Wrapf(err, "error pulling image: %s", ref.String()))
+			return
+		}
+		ctx.Tell(ctx.Sender(), imagePulled{})
+		return
+	}
+
+	dst := p.imagePath(ref)
+	if err = os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		sendErr(ctx, errors.Wrap(err, "error creating image cache directory"))
+		return
+	}
+	// Pull to a file of our own and move it into place, so concurrent pulls of the same image
+	// never see a partial file.
+	tmp := fmt.Sprintf("%s.%s.tmp", dst, p.containerID)
+	defer func() {
+		_ = os.Remove(tmp)
+	}()
+	if err = p.runLogged(ctx, p.runtime.pullCommand(ref, tmp, msg.Registry)); err != nil {
+		sendErr(ctx, errors.Wrapf(err, "error pulling image: %s", ref.String()))
+		return
+	}
+	if err = os.Rename(tmp, dst); err != nil {
+		sendErr(ctx, errors.Wrap(err, "error moving pulled image into the image cache"))
+		return
+	}
+
+	ctx.Tell(ctx.Sender(), imagePulled{})
+}
+
+func (p *processRuntimeActor) runContainer(ctx *actor.Context, msg cproto.RunSpec) {
+	ref, err := reference.ParseNormalizedNamed(msg.ContainerConfig.Image)
+	if err != nil {
+		sendErr(ctx, errors.Wrapf(err, "error parsing image name: %s", msg.ContainerConfig.Image))
+		return
+	}
+	rootfs := p.rootfs()
+	if err = os.MkdirAll(filepath.Dir(rootfs), 0o700); err != nil {
+		sendErr(ctx, errors.Wrap(err, "error creating container directory"))
+		return
+	}
+	image := p.imagePath(reference.TagNameOnly(ref))
+	if create := p.runtime.createCommand(image, rootfs); create != nil {
+		if err = p.runLogged(ctx, create); err != nil {
+			sendErr(ctx, errors.Wrap(err, "error creating container"))
+			return
+		}
+	}
+	if msg.HostConfig.AutoRemove {
+		defer func() {
+			if err := os.RemoveAll(rootfs); err != nil {
+				p.sendAuxLog(ctx, ptrs.Ptr(model.LogLevelWarning),
+					fmt.Sprintf("error removing container root filesystem: %s", err))
+			}
+		}()
+	}
+
+	for _, copyArx := range msg.Archives {
+		p.sendAuxLog(ctx, ptrs.Ptr(model.LogLevelInfo),
+			fmt.Sprintf("copying files to container: %s", copyArx.Path))
+		if err = writeArchive(rootfs, copyArx); err != nil {
+			sendErr(ctx, errors.Wrap(err, "error copying files to container"))
+			return
+		}
+	}
+
+	cmd, err := p.runtime.runCommand(rootfs, msg)
+	if err != nil {
+		sendErr(ctx, errors.Wrap(err, "error creating container command"))
+		return
+	}
+	// Run the container in its own process group so signals reach everything it started.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		sendErr(ctx, errors.Wrap(err, "error attaching to container stdout"))
+		return
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		sendErr(ctx, errors.Wrap(err, "error attaching to container stderr"))
+		return
+	}
+	if err = cmd.Start(); err != nil {
+		sendErr(ctx, errors.Wrap(err, "error starting container"))
+		return
+	}
+	p.mu.Lock()
+	p.process = cmd.Process
+	p.mu.Unlock()
+
+	ctx.Tell(ctx.Sender(), containerStarted{
+		dockerID:      p.containerID.String(),
+		containerInfo: processContainerInfo(p.containerID, msg, cmd.Process.Pid),
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go p.sendRunLogs(ctx, &wg, stdout, stdcopy.Stdout)
+	go p.sendRunLogs(ctx, &wg, stderr, stdcopy.Stderr)
+	wg.Wait()
+
+	err = cmd.Wait()
+	exitErr, ok := err.(*exec.ExitError)
+	switch {
+	case err == nil:
+		ctx.Tell(ctx.Sender(), containerTerminated{ExitCode: 0})
+	case ok:
+		// Report signals the way Docker does, as 128 + the signal number.
+		exitCode := exitErr.ExitCode()
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			exitCode = 128 + int(status.Signal())
+		}
+		ctx.Tell(ctx.Sender(), containerTerminated{ExitCode: aproto.ExitCode(exitCode)})
+	default:
+		sendErr(ctx, errors.Wrap(err, "error while waiting for container to exit"))
+	}
+}
+
+func (p *processRuntimeActor) signalContainer(ctx *actor.Context, msg signalContainer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.process == nil {
+		sendErr(ctx, errors.New("error while killing container: container is not running"))
+		return
+	}
+	if r, ok := p.runtime.(signalRuntime); ok {
+		if out, err := r.signalCommand(p.rootfs(), msg.signal).CombinedOutput(); err != nil {
+			sendErr(ctx, errors.Wrapf(err, "error while killing container: %s", out))
+		}
+		return
+	}
+	if err := syscall.Kill(-p.process.Pid, msg.signal); err != nil {
+		sendErr(ctx, errors.Wrap(err, "error while killing container"))
+	}
+}
+
+// imageExists returns whether the image was already pulled.
+func (p *processRuntimeActor) imageExists(image reference.Named) (bool, error) {
+	if r, ok := p.runtime.(imageStoreRuntime); ok {
+		err := r.imageExistsCommand(image).Run()
+		if _, ok := err.(*exec.ExitError); ok {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	_, err := os.Stat(p.imagePath(image))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// runLogged runs a command to completion, forwarding its output as pull logs.
+func (p *processRuntimeActor) runLogged(ctx *actor.Context, cmd *exec.Cmd) error {
+	out, err := cmd.CombinedOutput()
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		ctx.Tell(ctx.Sender(), aproto.ContainerLog{
+			Timestamp:   time.Now().UTC(),
+			PullMessage: &line,
+		})
+	}
+	return err
+}
+
+func (p *processRuntimeActor) sendRunLogs(
+	ctx *actor.Context, wg *sync.WaitGroup, r io.Reader, stdType stdcopy.StdType,
+) {
+	defer wg.Done()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		ctx.Tell(ctx.Sender(), aproto.ContainerLog{
+			Timestamp:  time.Now().UTC(),
+			RunMessage: &aproto.RunMessage{Value: scanner.Text(), StdType: stdType},
+		})
+	}
+}
+
+func (p *processRuntimeActor) sendAuxLog(ctx *actor.Context, level *string, msg string) {
+	ctx.Tell(ctx.Sender(), aproto.ContainerLog{
+		Timestamp:  time.Now().UTC(),
+		Level:      level,
+		AuxMessage: &msg,
+	})
+}
+
+// processContainerInfo fakes the Docker inspection of a container run by a process runtime, which
+// always shares the host's network.
+func processContainerInfo(id cproto.ID, spec cproto.RunSpec, pid int) types.ContainerJSON {
+	hostConfig := spec.HostConfig
+	hostConfig.NetworkMode = "host"
+	config := spec.ContainerConfig
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:         id.String(),
+			State:      &types.ContainerState{Status: "running", Running: true, Pid: pid},
+			HostConfig: &hostConfig,
+		},
+		Config: &config,
+		NetworkSettings: &types.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{"host": {}},
+		},
+	}
+}
+
+// containerBinds returns the host paths to bind into the container, as "src:dst[:ro]".
+func containerBinds(spec cproto.RunSpec) []string {
+	binds := append([]string{}, spec.HostConfig.Binds...)
+	for _, m := range spec.HostConfig.Mounts {
+		if m.Type != mount.TypeBind {
+			continue
+		}
+		bind := m.Source + ":" + m.Target
+		if m.ReadOnly {
+			bind += ":ro"
+		}
+		binds = append(binds, bind)
+	}
+	for _, d := range spec.HostConfig.Devices {
+		binds = append(binds, d.PathOnHost+":"+d.PathInContainer)
+	}
+	return binds
+}
+
+// containerCommand returns the command line of the container.
+func containerCommand(spec cproto.RunSpec) ([]string, error) {
+	argv := append([]string{}, spec.ContainerConfig.Entrypoint...)
+	argv = append(argv, spec.ContainerConfig.Cmd...)
+	if len(argv) == 0 {
+		return nil, errors.New("container has no entrypoint or command")
+	}
+	return argv, nil
+}
+
+// cudaDeviceUUIDs returns the UUIDs of the Nvidia GPUs assigned to the container.
+func cudaDeviceUUIDs(spec cproto.RunSpec) []string {
+	var uuids []string
+	for _, r := range spec.HostConfig.DeviceRequests {
+		if r.Driver == "nvidia" {
+			uuids = append(uuids, r.DeviceIDs...)
+		}
+	}
+	return uuids
+}
+
+// writeArchive writes the files of an archive into a container root filesystem. The items of an
+// archive are partly controlled by users, so no item is written through a symlink, and symlink
+// items may only point to relative targets inside the container.
+func writeArchive(rootfs string, arx cproto.RunArchive) error {
+	root := filepath.Clean(rootfs)
+	for _, item := range arx.Archive {
+		path := filepath.Join(root, arx.Path, item.Path)
+		if !withinRoot(root, path) {
+			return errors.Errorf("archive item escapes the container: %s", item.Path)
+		}
+		if err := mkdirNoFollow(root, filepath.Dir(path)); err != nil {
+			return errors.Wrapf(err, "archive item %s", item.Path)
+		}
+
+		switch {
+		case item.IsDir():
+			if err := mkdirNoFollow(root, path); err != nil {
+				return errors.Wrapf(err, "archive item %s", item.Path)
+			}
+		case item.IsSymLink():
+			target := string(item.Content)
+			if filepath.IsAbs(target) || !withinRoot(root, filepath.Join(filepath.Dir(path), target)) {
+				return errors.Errorf("archive symlink %s points outside the container: %s",
+					item.Path, target)
+			}
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			if err := os.Symlink(target, path); err != nil {
+				return err
+			}
+			if os.Geteuid() == 0 {
+				if err := os.Lchown(path, item.UserID, item.GroupID); err != nil {
+					return err
+				}
+			}
+			continue
+		default:
+			// O_NOFOLLOW refuses to open the file if it is a symlink.
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|syscall.O_NOFOLLOW,
+				item.FileMode.Perm())
+			if err != nil {
+				return errors.Wrapf(err, "archive item %s", item.Path)
+			}
+			_, err = f.Write(item.Content)
+			if cErr := f.Close(); err == nil {
+				err = cErr
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := os.Chmod(path, item.FileMode.Perm()); err != nil {
+			return err
+		}
+		if os.Geteuid() == 0 {
+			if err := os.Lchown(path, item.UserID, item.GroupID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// withinRoot returns whether the cleaned path is root or below it.
+func withinRoot(root, path string) bool {
+	return path == root || strings.HasPrefix(path, root+string(os.PathSeparator))
+}
+
+// mkdirNoFollow creates the directory dir below root, along with any missing parents. It fails if
+// any component of dir below root is a symlink or not a directory.
+func mkdirNoFollow(root, dir string) error {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return err
+	}
+	cur := root
+	for _, name := range strings.Split(rel, string(os.PathSeparator)) {
+		if name == "." || name == "" {
+			continue
+		}
+		cur = filepath.Join(cur, name)
+		info, err := os.Lstat(cur)
+		if os.IsNotExist(err) {
+			if err = os.Mkdir(cur, 0o755); err != nil && !os.IsExist(err) {
+				return err
+			}
+			info, err = os.Lstat(cur)
+		}
+		switch {
+		case err != nil:
+			return err
+		case info.Mode()&os.ModeSymlink != 0:
+			return errors.Errorf("%s is a symlink", strings.TrimPrefix(cur, root))
+		case !info.IsDir():
+			return errors.Errorf("%s is not a directory", strings.TrimPrefix(cur, root))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/distribution/reference"
+	dcontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"gotest.tools/assert"
+
+	"github.com/determined-ai/determined/master/pkg/archive"
+	"github.com/determined-ai/determined/master/pkg/cproto"
+)
+
+func TestEnrootImageURI(t *testing.T) {
+	for image, expected := range map[string]string{
+		"ubuntu":                          "docker://library/ubuntu:latest",
+		"determinedai/environments:py-3":  "docker://determinedai/environments:py-3",
+		"nvcr.io/nvidia/pytorch:22.01-py": "docker://nvcr.io#nvidia/pytorch:22.01-py",
+	} {
+		ref, err := reference.ParseNormalizedNamed(image)
+		assert.NilError(t, err)
+		assert.Equal(t, enrootImageURI(reference.TagNameOnly(ref)), expected)
+	}
+}
+
+func TestContainerBinds(t *testing.T) {
+	spec := cproto.RunSpec{HostConfig: dcontainer.HostConfig{
+		Binds: []string{"/data:/data"},
+		Mounts: []mount.Mount{
+			{Type: mount.TypeBind, Source: "/ckpt", Target: "/checkpoints", ReadOnly: true},
+			{Type: mount.TypeVolume, Source: "vol", Target: "/vol"},
+		},
+		Devices: []dcontainer.DeviceMapping{{PathOnHost: "/dev/kfd", PathInContainer: "/dev/kfd"}},
+	}}
+	assert.DeepEqual(t, containerBinds(spec),
+		[]string{"/data:/data", "/ckpt:/checkpoints:ro", "/dev/kfd:/dev/kfd"})
+}
+
+func TestWriteArchive(t *testing.T) {
+	rootfs := t.TempDir()
+	err := writeArchive(rootfs, cproto.RunArchive{
+		Path: "/run/determined",
+		Archive: archive.Archive{
+			archive.RootItem("train", nil, 0o755, tar.TypeDir),
+			archive.RootItem("train/entrypoint.sh", []byte("#!/bin/sh\n"), 0o700, tar.TypeReg),
+			archive.RootItem("train/link.sh", []byte("entrypoint.sh"), 0o777, tar.TypeSymlink),
+		},
+	})
+	assert.NilError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(rootfs, "run/determined/train/link.sh"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "#!/bin/sh\n")
+	info, err := os.Stat(filepath.Join(rootfs, "run/determined/train/entrypoint.sh"))
+	assert.NilError(t, err)
+	assert.Equal(t, info.Mode().Perm(), os.FileMode(0o700))
+
+	err = writeArchive(rootfs, cproto.RunArchive{
+		Path:    "/",
+		Archive: archive.Archive{archive.RootItem("../escape", nil, 0o644, tar.TypeReg)},
+	})
+	assert.ErrorContains(t, err, "escapes the container")
+}
+
+func TestWriteArchiveSymlinks(t *testing.T) {
+	rootfs := t.TempDir()
+	outside := t.TempDir()
+
+	// Files are never written through a symlink, even one that points inside the container.
+	err := writeArchive(rootfs, cproto.RunArchive{
+		Path: "/run/determined",
+		Archive: archive.Archive{
+			archive.RootItem("link", []byte("."), 0o777, tar.TypeSymlink),
+			archive.RootItem("link/file", []byte("data"), 0o644, tar.TypeReg),
+		},
+	})
+	assert.ErrorContains(t, err, "is a symlink")
+
+	// Symlinks may not point outside the container.
+	for _, target := range []string{outside, "../../.." + outside} {
+		err = writeArchive(rootfs, cproto.RunArchive{
+			Path:    "/run/determined",
+			Archive: archive.Archive{archive.RootItem("out", []byte(target), 0o777, tar.TypeSymlink)},
+		})
+		assert.ErrorContains(t, err, "points outside the container")
+	}
+
+	// Symlinks already in the root filesystem are not followed either.
+	assert.NilError(t, os.Symlink(outside, filepath.Join(rootfs, "run/determined/out")))
+	err = writeArchive(rootfs, cproto.RunArchive{
+		Path:    "/run/determined",
+		Archive: archive.Archive{archive.RootItem("out/file", []byte("data"), 0o644, tar.TypeReg)},
+	})
+	assert.ErrorContains(t, err, "is a symlink")
+	err = writeArchive(rootfs, cproto.RunArchive{
+		Path:    "/run/determined",
+		Archive: archive.Archive{archive.RootItem("out", []byte("data"), 0o644, tar.TypeReg)},
+	})
+	assert.Assert(t, err != nil)
+
+	entries, err := os.ReadDir(outside)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 0)
+}
+
+func TestPodmanCreateArgs(t *testing.T) {
+	pidsLimit := int64(100)
+	spec := cproto.RunSpec{
+		ContainerConfig: dcontainer.Config{
+			User:       "1000:1000",
+			WorkingDir: "/run/determined/workdir",
+			Env:        []string{"DET_SESSION_TOKEN=secret", "EMPTY="},
+			Labels:     map[string]string{"b": "2", "a": "1"},
+		},
+		HostConfig: dcontainer.HostConfig{
+			AutoRemove: true,
+			Mounts: []mount.Mount{
+				{Type: mount.TypeBind, Source: "/ckpt", Target: "/checkpoints", ReadOnly: true},
+			},
+			Resources: dcontainer.Resources{
+				Devices:  []dcontainer.DeviceMapping{{PathOnHost: "/dev/kfd", PathInContainer: "/dev/kfd"}},
+				NanoCPUs: 1500000000,
+				DeviceRequests: []dcontainer.DeviceRequest{
+					{Driver: "nvidia", DeviceIDs: []string{"GPU-1234"}},
+				},
+				PidsLimit: &pidsLimit,
+			},
+			ShmSize: 1024,
+		},
+	}
+
+	p := &podmanRuntime{binary: "podman", rootless: true, cgroupV2: true, userNS: "keep-id"}
+	args, env := p.createArgs("determined-abc", spec)
+	assert.DeepEqual(t, args, []string{
+		"create", "--name", "determined-abc", "--network", "host", "--rm",
+		"--userns", "keep-id", "--user", "1000:1000", "--workdir", "/run/determined/workdir",
+		"--label", "a=1", "--label", "b=2",
+		"--env", "DET_SESSION_TOKEN", "--env", "EMPTY",
+		"--volume", "/ckpt:/checkpoints:ro", "--device", "/dev/kfd:/dev/kfd",
+		"--device", "nvidia.com/gpu=GPU-1234", "--security-opt", "label=disable",
+		"--shm-size", "1024b", "--cpus", "1.5", "--pids-limit", "100",
+	})
+	// The values of variables are only passed through the environment.
+	assert.Equal(t, env[len(env)-2], "DET_SESSION_TOKEN=secret")
+
+	// Rootless Podman can't limit resources without cgroup v2.
+	p.cgroupV2 = false
+	assert.Equal(t, len(p.resourceArgs(spec)), 0)
+}
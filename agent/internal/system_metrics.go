@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/actor/actors"
+	"github.com/determined-ai/determined/master/pkg/aproto"
+	"github.com/determined-ai/determined/master/pkg/cproto"
+	"github.com/determined-ai/determined/master/pkg/device"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+)
+
+var gpuUsageQueryArgs = []string{
+	"nvidia-smi",
+	"--query-gpu=uuid,utilization.gpu,memory.used,power.draw",
+	"--format=csv,noheader,nounits",
+}
+
+// systemMetricsTimeout bounds how long reading the stats of a container from Docker may take.
+const systemMetricsTimeout = 10 * time.Second
+
+type measureSystemMetrics struct{}
+
+// gpuUsageRecord is a single row of the nvidia-smi usage query.
+type gpuUsageRecord struct {
+	uuid        string
+	utilization float64
+	memoryMiB   int64
+	powerWatts  float64
+}
+
+// systemMetricsMonitor periodically measures the resource usage of a running Docker container and
+// reports it to its parent, which forwards it to the master.
+type systemMetricsMonitor struct {
+	client      *client.Client
+	containerID cproto.ID
+	dockerID    string
+	gpuUUIDs    map[string]bool
+	period      time.Duration
+
+	// last is the previous stats sample, which rates are computed against.
+	last *types.StatsJSON
+	// gpuUnavailable is set once nvidia-smi fails, so a broken install is not retried forever.
+	gpuUnavailable bool
+}
+
+func newSystemMetricsMonitor(
+	client *client.Client, container cproto.Container, dockerID string, period time.Duration,
+) *systemMetricsMonitor {
+	m := &systemMetricsMonitor{
+		client:      client,
+		containerID: container.ID,
+		dockerID:    dockerID,
+		gpuUUIDs:    make(map[string]bool),
+		period:      period,
+	}
+	for _, uuid := range container.DeviceUUIDsByType(device.CUDA) {
+		m.gpuUUIDs[uuid] = true
+	}
+	return m
+}
+
+func (m *systemMetricsMonitor) Receive(ctx *actor.Context) error {
+	switch ctx.Message().(type) {
+	case actor.PreStart:
+		ctx.Tell(ctx.Self(), measureSystemMetrics{})
+	case measureSystemMetrics:
+		metrics, err := m.measure(ctx)
+		if err != nil {
+			// The container may be exiting; errors must not fail the container actor.
+			ctx.Log().WithError(err).Debug("unable to measure the resource usage of the container")
+		} else {
+			ctx.Tell(ctx.Self().Parent(), aproto.ContainerMetrics{
+				ContainerID: m.containerID, Metrics: metrics,
+			})
+		}
+		actors.NotifyAfter(ctx, m.period, measureSystemMetrics{})
+	case actor.PostStop:
+	default:
+		return actor.ErrUnexpectedMessage(ctx)
+	}
+	return nil
+}
+
+func (m *systemMetricsMonitor) measure(ctx *actor.Context) (model.SystemMetrics, error) {
+	stats, err := m.containerStats()
+	if err != nil {
+		return model.SystemMetrics{}, err
+	}
+	metrics := computeSystemMetrics(m.last, stats)
+	m.last = stats
+
+	if len(m.gpuUUIDs) > 0 && !m.gpuUnavailable {
+		// #nosec G204
+		out, err := exec.Command(gpuUsageQueryArgs[0], gpuUsageQueryArgs[1:]...).Output()
+		if err != nil {
+			ctx.Log().WithError(err).Warn("unable to run nvidia-smi, GPU usage will not be measured")
+			m.gpuUnavailable = true
+			return metrics, nil
+		}
+		records, err := parseGPUUsageQuery(out)
+		if err != nil {
+			return metrics, err
+		}
+		addGPUUsage(&metrics, records, m.gpuUUIDs)
+	}
+	return metrics, nil
+}
+
+func (m *systemMetricsMonitor) containerStats() (*types.StatsJSON, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), systemMetricsTimeout)
+	defer cancel()
+	resp, err := m.client.ContainerStatsOneShot(ctx, m.dockerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading container stats")
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	var stats types.StatsJSON
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, errors.Wrap(err, "error decoding container stats")
+	}
+	return &stats, nil
+}
+
+// computeSystemMetrics converts a stats sample into metrics. CPU and network usage are cumulative
+// counters, so they are only reported as rates once there is a previous sample to compare with.
+func computeSystemMetrics(prev, cur *types.StatsJSON) model.SystemMetrics {
+	metrics := model.SystemMetrics{Time: cur.Read.UTC()}
+	if metrics.Time.IsZero() {
+		metrics.Time = time.Now().UTC()
+	}
+
+	// Page cache that can be reclaimed is not counted, like `docker stats`; cgroup v1 and v2 name
+	// it differently.
+	memory := cur.MemoryStats.Usage
+	inactive, ok := cur.MemoryStats.Stats["total_inactive_file"]
+	if !ok {
+		inactive = cur.MemoryStats.Stats["inactive_file"]
+	}
+	if inactive < memory {
+		memory -= inactive
+	}
+	metrics.MemoryBytes = ptrs.Ptr(int64(memory))
+
+	if prev == nil {
+		return metrics
+	}
+	elapsed := cur.Read.Sub(prev.Read)
+	if elapsed <= 0 {
+		return metrics
+	}
+	if cur.CPUStats.CPUUsage.TotalUsage >= prev.CPUStats.CPUUsage.TotalUsage {
+		used := cur.CPUStats.CPUUsage.TotalUsage - prev.CPUStats.CPUUsage.TotalUsage
+		metrics.CPUCores = ptrs.Ptr(float64(used) / float64(elapsed.Nanoseconds()))
+	}
+	prevRx, prevTx := networkBytes(prev)
+	curRx, curTx := networkBytes(cur)
+	if curRx >= prevRx && curTx >= prevTx {
+		metrics.NetworkRxBytesPerSecond = ptrs.Ptr(float64(curRx-prevRx) / elapsed.Seconds())
+		metrics.NetworkTxBytesPerSecond = ptrs.Ptr(float64(curTx-prevTx) / elapsed.Seconds())
+	}
+	return metrics
+}
+
+func networkBytes(stats *types.StatsJSON) (rx, tx uint64) {
+	for _, n := range stats.Networks {
+		rx += n.RxBytes
+		tx += n.TxBytes
+	}
+	return rx, tx
+}
+
+// addGPUUsage sets the mean utilization and the total memory used and power drawn of the given
+// GPUs.
+func addGPUUsage(metrics *model.SystemMetrics, records []gpuUsageRecord, uuids map[string]bool) {
+	var utilization, powerWatts float64
+	var memoryMiB int64
+	var count int
+	for _, r := range records {
+		if !uuids[r.uuid] {
+			continue
+		}
+		utilization += r.utilization
+		memoryMiB += r.memoryMiB
+		powerWatts += r.powerWatts
+		count++
+	}
+	if count == 0 {
+		return
+	}
+	metrics.GPUUtilization = ptrs.Ptr(utilization / float64(count))
+	metrics.GPUMemoryBytes = ptrs.Ptr(memoryMiB * 1024 * 1024)
+	metrics.GPUPowerWatts = ptrs.Ptr(powerWatts)
+}
+
+func parseGPUUsageQuery(out []byte) ([]gpuUsageRecord, error) {
+	var records []gpuUsageRecord
+	r := csv.NewReader(bytes.NewReader(out))
+	for {
+		record, err := r.Read()
+		switch {
+		case err == io.EOF:
+			return records, nil
+		case err != nil:
+			return nil, errors.Wrap(err, "error parsing output of nvidia-smi as CSV")
+		case len(record) != 4:
+			return nil, errors.New(
+				"error parsing output of nvidia-smi; GPU usage record should have exactly 4 fields")
+		}
+
+		// Unsupported values are reported as "[N/A]" and treated as zero.
+		utilization, _ := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		memory, _ := strconv.ParseInt(strings.TrimSpace(record[2]), 10, 64)
+		power, _ := strconv.ParseFloat(strings.TrimSpace(record[3]), 64)
+		records = append(records, gpuUsageRecord{
+			uuid:        strings.TrimSpace(record[0]),
+			utilization: utilization,
+			memoryMiB:   memory,
+			powerWatts:  power,
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"gotest.tools/assert"
+)
+
+const testGPUUsageQueryData = `GPU-0b0ff6b6-5e31-43b4-9f4f-9fd5c1e2ff6c, 80, 1024, 250.5
+GPU-6a1c0e5a-a8e8-4e1a-8e1c-7c2c4c1d8a2b, 40, 512, 99.5
+GPU-1d4b3c2a-0f9e-4d8c-b7a6-958473625140, [N/A], [N/A], [N/A]
+`
+
+func TestComputeSystemMetrics(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	prev := &types.StatsJSON{Networks: map[string]types.NetworkStats{
+		"eth0": {RxBytes: 1000, TxBytes: 2000},
+	}}
+	prev.Read = start
+	prev.CPUStats.CPUUsage.TotalUsage = uint64(time.Second)
+	prev.MemoryStats.Usage = 4096
+
+	cur := &types.StatsJSON{Networks: map[string]types.NetworkStats{
+		"eth0": {RxBytes: 3000, TxBytes: 2000},
+		"eth1": {RxBytes: 1000, TxBytes: 4000},
+	}}
+	cur.Read = start.Add(2 * time.Second)
+	cur.CPUStats.CPUUsage.TotalUsage = uint64(4 * time.Second)
+	cur.MemoryStats.Usage = 8192
+	cur.MemoryStats.Stats = map[string]uint64{"inactive_file": 1024}
+
+	first := computeSystemMetrics(nil, prev)
+	assert.Equal(t, first.Time, start)
+	assert.Equal(t, *first.MemoryBytes, int64(4096))
+	assert.Assert(t, first.CPUCores == nil)
+	assert.Assert(t, first.NetworkRxBytesPerSecond == nil)
+
+	metrics := computeSystemMetrics(prev, cur)
+	assert.Equal(t, *metrics.MemoryBytes, int64(7168))
+	assert.Equal(t, *metrics.CPUCores, 1.5)
+	assert.Equal(t, *metrics.NetworkRxBytesPerSecond, 1500.0)
+	assert.Equal(t, *metrics.NetworkTxBytesPerSecond, 2000.0)
+}
+
+func TestAddGPUUsage(t *testing.T) {
+	records, err := parseGPUUsageQuery([]byte(testGPUUsageQueryData))
+	assert.NilError(t, err)
+	assert.Equal(t, len(records), 3)
+	assert.Equal(t, records[2].utilization, 0.0)
+
+	first, second := records[0].uuid, records[1].uuid
+	metrics := computeSystemMetrics(nil, &types.StatsJSON{})
+	addGPUUsage(&metrics, records, map[string]bool{first: true, second: true})
+	assert.Equal(t, *metrics.GPUUtilization, 60.0)
+	assert.Equal(t, *metrics.GPUMemoryBytes, int64(1536*1024*1024))
+	assert.Equal(t, *metrics.GPUPowerWatts, 350.0)
+
+	none := computeSystemMetrics(nil, &types.StatsJSON{})
+	addGPUUsage(&none, records, map[string]bool{"GPU-missing": true})
+	assert.Assert(t, none.GPUUtilization == nil)
+}
@@ -24,5 +24,6 @@ from determined.cli import (
     tensorboard,
     trial,
     user,
+    vscode,
     workspace,
 )
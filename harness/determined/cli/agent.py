@@ -9,7 +9,7 @@ from typing import Any, Callable, Dict, List
 from determined import cli
 from determined.cli import render
 from determined.cli import task as cli_task
-from determined.common import api
+from determined.common import api, util
 from determined.common.api import authentication, bindings
 from determined.common.check import check_false
 from determined.common.declarative_argparse import Arg, Cmd, Group
@@ -207,6 +207,70 @@ def patch_slot(enabled: bool) -> Callable[[argparse.Namespace], None]:
     return patch
 
 
+@authentication.required
+def list_images(args: argparse.Namespace) -> None:
+    resp = api.get(args.master, f"api/v1/agents/{args.agent_id}/images").json()
+
+    images = [
+        OrderedDict(
+            [
+                ("id", i["id"]),
+                ("tags", ", ".join(i.get("tags") or [])),
+                ("size", util.sizeof_fmt(int(i["size"]))),
+                ("created", render.format_time(i.get("created"))),
+                ("last_used", render.format_time(i.get("lastUsed"))),
+                ("in_use", i.get("inUse", False)),
+            ]
+        )
+        for i in sorted(resp.get("images") or [], key=lambda i: i.get("lastUsed", ""))
+    ]
+
+    if args.json:
+        print(json.dumps(images, indent=4))
+        return
+
+    headers = ["Image ID", "Tags", "Size", "Created", "Last Used", "In Use"]
+    values = [i.values() for i in images]
+    render.tabulate_or_csv(headers, values, args.csv)
+
+    if not args.csv:
+        limit = int(resp.get("limitBytes", 0))
+        total = sum(int(i["size"]) for i in resp.get("images") or [])
+        print(
+            "\nTotal size: {}, limit: {}".format(
+                util.sizeof_fmt(total), util.sizeof_fmt(limit) if limit else "none"
+            )
+        )
+
+
+@authentication.required
+def evict_images(args: argparse.Namespace) -> None:
+    path = f"api/v1/agents/{args.agent_id}/images/evict"
+    api.post(args.master, path, {"images": args.images})
+    print(f"Requested eviction of {len(args.images)} image(s) from agent {args.agent_id}.")
+
+
+def parse_size(size: str) -> int:
+    units = {"K": 1 << 10, "M": 1 << 20, "G": 1 << 30, "T": 1 << 40}
+    s = size.strip().upper().rstrip("B").rstrip("I")
+    try:
+        if s and s[-1] in units:
+            return int(float(s[:-1]) * units[s[-1]])
+        return int(s)
+    except ValueError:
+        raise argparse.ArgumentTypeError(f"invalid size: {size}") from None
+
+
+@authentication.required
+def set_image_cache_limit(args: argparse.Namespace) -> None:
+    path = f"api/v1/agents/{args.agent_id}/images/limit"
+    api.post(args.master, path, {"limitBytes": str(args.limit)})
+    if args.limit:
+        print(f"Set image cache limit of agent {args.agent_id} to {util.sizeof_fmt(args.limit)}.")
+    else:
+        print(f"Removed image cache limit of agent {args.agent_id}.")
+
+
 def agent_id_completer(_1: str, parsed_args: argparse.Namespace, _2: Any) -> List[str]:
     r = api.get(parsed_args.master, "agents")
     return list(r.json().keys())
@@ -241,6 +305,26 @@ args_description = [
                 Arg("--json", action="store_true", help="print as JSON"),
             ),
         ]),
+        Cmd("image", None, "manage images cached on an agent (admin only)", [
+            Cmd("list ls", list_images, "list images cached on an agent", [
+                Arg("agent_id", help="agent ID", completer=agent_id_completer),
+                Group(
+                    Arg("--csv", action="store_true", help="print as CSV"),
+                    Arg("--json", action="store_true", help="print as JSON"),
+                ),
+            ], is_default=True),
+            Cmd("evict", evict_images, "remove images from the cache of an agent", [
+                Arg("agent_id", help="agent ID", completer=agent_id_completer),
+                Arg("images", nargs="+", help="IDs or tags of the images to evict"),
+            ]),
+            Cmd("set-limit", set_image_cache_limit,
+                "keep the images cached on an agent under a size, evicting the least "
+                "recently used images", [
+                    Arg("agent_id", help="agent ID", completer=agent_id_completer),
+                    Arg("limit", type=parse_size,
+                        help="size limit, e.g. 100G; 0 removes the limit"),
+                ]),
+        ]),
     ]),
     Cmd("s|lot", None, "manage slots", [
         Cmd("list ls", list_slots, "list slots in cluster", [
@@ -0,0 +1,80 @@
+import json
+import os
+import pathlib
+from argparse import Namespace
+from typing import Any, List
+
+from termcolor import colored
+
+from determined.common import api, storage
+from determined.common.api import authentication
+from determined.common.declarative_argparse import Arg, Cmd
+
+from . import render
+
+# The storage types that the master can download artifacts from itself.
+MASTER_DOWNLOADABLE_STORAGE = {"s3", "shared_fs"}
+
+
+@authentication.required
+def list_artifacts(args: Namespace) -> None:
+    path = f"api/v1/tasks/{args.task_id}/artifacts"
+    artifacts = api.get(args.master, path).json()["artifacts"]
+    if args.json:
+        print(json.dumps(artifacts, indent=4))
+        return
+
+    headers = ["UUID", "Name", "Type", "Report Time", "Size"]
+    values = [
+        [
+            a["uuid"],
+            a["name"],
+            a.get("type", ""),
+            render.format_time(a.get("reportTime")),
+            render.format_resource_sizes(a.get("resources")),
+        ]
+        for a in artifacts
+    ]
+    render.tabulate_or_csv(headers, values, args.csv)
+
+
+@authentication.required
+def download_artifact(args: Namespace) -> None:
+    artifact = api.get(args.master, f"api/v1/artifacts/{args.artifact_uuid}").json()["artifact"]
+    output_dir = pathlib.Path(args.output_dir)
+    output_dir.mkdir(parents=True, exist_ok=True)
+
+    config = artifact["storage"]
+    if config["type"] in MASTER_DOWNLOADABLE_STORAGE:
+        r = api.get(args.master, f"artifacts/{args.artifact_uuid}", stream=True)
+        with output_dir.joinpath(artifact["name"]).open("wb") as f:
+            for chunk in r.iter_content(chunk_size=4096):
+                f.write(chunk)
+    else:
+        # The master can't reach this storage, so download the artifact from it directly with the
+        # credentials of the environment.
+        manager = storage.build(config, container_path=None)
+        manager.download(src=f"artifacts/{artifact['uuid']}", dst=os.fspath(output_dir))
+
+    path = output_dir.joinpath(artifact["name"])
+    print(colored(f"Downloaded artifact {artifact['uuid']} to {path}", "green"))
+
+
+# fmt: off
+
+args_description = [
+    Cmd("artifact", None, "manage task artifacts", [
+        Cmd("list ls", list_artifacts, "list the artifacts of a task", [
+            Arg("task_id", help="task ID"),
+            Arg("--csv", action="store_true", help="print as CSV"),
+            Arg("--json", action="store_true", help="print as JSON"),
+        ]),
+        Cmd("download", download_artifact, "download an artifact", [
+            Arg("artifact_uuid", help="artifact UUID"),
+            Arg("-o", "--output-dir", default=".",
+                help="directory to download the artifact into"),
+        ]),
+    ])
+]  # type: List[Any]
+
+# fmt: on
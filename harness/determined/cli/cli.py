@@ -17,6 +17,7 @@ import determined
 import determined.cli
 from determined.cli import render
 from determined.cli.agent import args_description as agent_args_description
+from determined.cli.artifact import args_description as artifact_args_description
 from determined.cli.checkpoint import args_description as checkpoint_args_description
 from determined.cli.experiment import args_description as experiment_args_description
 from determined.cli.job import args_description as job_args_description
@@ -24,6 +25,7 @@ from determined.cli.master import args_description as master_args_description
 from determined.cli.model import args_description as model_args_description
 from determined.cli.notebook import args_description as notebook_args_description
 from determined.cli.oauth import args_description as oauth_args_description
+from determined.cli.pod_template import args_description as pod_template_args_description
 from determined.cli.project import args_description as project_args_description
 from determined.cli.rbac import args_description as rbac_args_description
 from determined.cli.remote import args_description as remote_args_description
@@ -38,6 +40,7 @@ from determined.cli.trial import args_description as trial_args_description
 from determined.cli.user import args_description as user_args_description
 from determined.cli.user_groups import args_description as user_groups_args_description
 from determined.cli.version import args_description as version_args_description
+from determined.cli.vscode import args_description as vscode_args_description
 from determined.cli.version import check_version
 from determined.cli.workspace import args_description as workspace_args_description
 from determined.common import api, yaml
@@ -138,6 +141,7 @@ all_args_description = (
     args_description
     + experiment_args_description
     + checkpoint_args_description
+    + artifact_args_description
     + master_args_description
     + model_args_description
     + agent_args_description
@@ -148,6 +152,7 @@ all_args_description = (
     + shell_args_description
     + task_args_description
     + template_args_description
+    + pod_template_args_description
     + tensorboard_args_description
     + trial_args_description
     + remote_args_description
@@ -155,6 +160,7 @@ all_args_description = (
     + user_groups_args_description
     + rbac_args_description
     + version_args_description
+    + vscode_args_description
     + workspace_args_description
     + auth_args_description
     + oauth_args_description
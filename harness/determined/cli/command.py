@@ -81,12 +81,14 @@ TaskTypeNotebook = "notebook"
 TaskTypeCommand = "command cmd"
 TaskTypeShell = "shell"
 TaskTypeTensorBoard = "tensorboard"
+TaskTypeVSCode = "vscode"
 
 RemoteTaskName = {
     TaskTypeNotebook: "notebook",
     TaskTypeCommand: "command",
     TaskTypeShell: "shell",
     TaskTypeTensorBoard: "tensorboard",
+    TaskTypeVSCode: "VS Code server",
 }
 
 RemoteTaskLogName = {
@@ -94,6 +96,7 @@ RemoteTaskLogName = {
     TaskTypeCommand: "Command",
     TaskTypeShell: "Shell",
     TaskTypeTensorBoard: "TensorBoard",
+    TaskTypeVSCode: "VS Code",
 }
 
 RemoteTaskNewAPIs = {
@@ -101,6 +104,7 @@ RemoteTaskNewAPIs = {
     TaskTypeCommand: "commands",
     TaskTypeShell: "shells",
     TaskTypeTensorBoard: "tensorboards",
+    TaskTypeVSCode: "vscodes",
 }
 
 RemoteTaskOldAPIs = {
@@ -108,6 +112,7 @@ RemoteTaskOldAPIs = {
     TaskTypeCommand: "commands",
     TaskTypeShell: "shells",
     TaskTypeTensorBoard: "tensorboard",
+    TaskTypeVSCode: "vscodes",
 }
 
 RemoteTaskListTableHeaders = {
@@ -115,6 +120,7 @@ RemoteTaskListTableHeaders = {
     "command cmd": CommandTableHeader,
     "shell": CommandTableHeader,
     "tensorboard": TensorboardTableHeader,
+    "vscode": CommandTableHeader,
 }
 
 RemoteTaskGetIDsFunc = {
@@ -122,6 +128,7 @@ RemoteTaskGetIDsFunc = {
     "command cmd": lambda args: args.command_id,
     "shell": lambda args: args.shell_id,
     "tensorboard": lambda args: args.tensorboard_id,
+    "vscode": lambda args: args.vscode_id,
 }
 
 
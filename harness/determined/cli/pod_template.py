@@ -0,0 +1,61 @@
+from argparse import FileType, Namespace
+from typing import Any, List
+
+from termcolor import colored
+
+from determined.common import api, util, yaml
+from determined.common.api import authentication
+from determined.common.declarative_argparse import Arg, Cmd
+
+from . import render
+
+
+@authentication.required
+def list_pod_templates(args: Namespace) -> None:
+    templates = api.get(args.master, path="api/v1/pod-templates").json()["templates"]
+    headers = ["Name", "Description", "Updated At"]
+    values = [[t["name"], t.get("description", ""), t["updatedAt"]] for t in templates]
+    render.tabulate_or_csv(headers, values, False)
+
+
+@authentication.required
+def describe_pod_template(args: Namespace) -> None:
+    resp = api.get(args.master, path="api/v1/pod-templates/" + args.template_name).json()
+    print(yaml.safe_dump(resp["template"]["spec"], default_flow_style=False), end="")
+
+
+@authentication.required
+def set_pod_template(args: Namespace) -> None:
+    with args.template_file:
+        spec = util.safe_load_yaml_with_exceptions(args.template_file)
+    body = {"name": args.template_name, "spec": spec, "description": args.description}
+    api.put(args.master, path="api/v1/pod-templates/" + args.template_name, json=body)
+    print(colored("Set pod template {}".format(args.template_name), "green"))
+
+
+@authentication.required
+def remove_pod_template(args: Namespace) -> None:
+    api.delete(args.master, path="api/v1/pod-templates/" + args.template_name)
+    print(colored("Removed pod template {}".format(args.template_name), "green"))
+
+
+# fmt: off
+
+args_description = [
+    Cmd("pod-template", None, "manage pod templates for Kubernetes tasks", [
+        Cmd("list ls", list_pod_templates, "list pod templates", [], is_default=True),
+        Cmd("describe", describe_pod_template, "print the pod spec of a pod template", [
+            Arg("template_name", help="pod template name"),
+        ]),
+        Cmd("set", set_pod_template, "create or replace a pod template (admin only)", [
+            Arg("template_name", help="pod template name"),
+            Arg("template_file", type=FileType("r"), help="pod spec file (.yaml)"),
+            Arg("--description", default="", help="what the pod template is for"),
+        ]),
+        Cmd("remove rm", remove_pod_template, "remove a pod template (admin only)", [
+            Arg("template_name", help="pod template name"),
+        ]),
+    ])
+]  # type: List[Any]
+
+# fmt: on
@@ -9,7 +9,7 @@ import tempfile
 from argparse import ONE_OR_MORE, FileType, Namespace
 from functools import partial
 from pathlib import Path
-from typing import IO, Any, ContextManager, Dict, Iterator, List, Tuple, Union
+from typing import IO, Any, ContextManager, Dict, Iterator, List, Optional, Tuple, Union
 
 import appdirs
 from termcolor import colored
@@ -27,6 +27,8 @@ def start_shell(args: Namespace) -> None:
     data = {}
     if args.passphrase:
         data["passphrase"] = getpass.getpass("Enter new passphrase: ")
+    if args.registered_keys:
+        data["registered_keys"] = True
     config = command.parse_config(args.config_file, None, args.config, args.volume)
     resp = command.launch_command(
         args.master,
@@ -135,10 +137,14 @@ def _open_shell(
         if not cache_dir.exists():
             cache_dir.mkdir(parents=True)
 
-    f, keypath = _prepare_key(cache_dir)
-    with f as keyfile:
-        keyfile.write(shell["privateKey"])
-        keyfile.flush()
+    keypath: Optional[str] = None
+    with contextlib.ExitStack() as stack:
+        # Shells started with --registered-keys have no generated key; ssh uses the user's keys.
+        if shell.get("privateKey"):
+            f, keypath = _prepare_key(cache_dir)
+            keyfile = stack.enter_context(f)
+            keyfile.write(shell["privateKey"])
+            keyfile.flush()
 
         # Use determined.cli.tunnel as a portable script for using the HTTP CONNECT mechanism,
         # similar to `nc -X CONNECT -x ...` but without any dependency on external binaries.
@@ -156,13 +162,17 @@ def _open_shell(
 
         username = shell["agentUserGroup"]["user"] or "root"
 
-        unixy_keypath = str(keypath)
-        if sys.platform == "win32":
-            # Convert the backslashes of the -i argument to ssh to forwardslashes.  This is
-            # important because when passing the output of ssh_show_command to VSCode, VSCode would
-            # put backslashes in .ssh/config, which would not be handled correctly by ssh.  When
-            # invoking ssh directly, it behaves the same whether -i has backslashes or not.
-            unixy_keypath = unixy_keypath.replace("\\", "/")
+        identity_opts: List[str] = []
+        if keypath is not None:
+            unixy_keypath = str(keypath)
+            if sys.platform == "win32":
+                # Convert the backslashes of the -i argument to ssh to forwardslashes.  This is
+                # important because when passing the output of ssh_show_command to VSCode, VSCode
+                # would put backslashes in .ssh/config, which would not be handled correctly by
+                # ssh.  When invoking ssh directly, it behaves the same whether -i has backslashes
+                # or not.
+                unixy_keypath = unixy_keypath.replace("\\", "/")
+            identity_opts = ["-o", "IdentitiesOnly=yes", "-i", unixy_keypath]
 
         cmd = [
             "ssh",
@@ -171,10 +181,7 @@ def _open_shell(
             "-o",
             "StrictHostKeyChecking=no",
             "-tt",
-            "-o",
-            "IdentitiesOnly=yes",
-            "-i",
-            unixy_keypath,
+            *identity_opts,
             f"{username}@{shell['id']}",
             *additional_opts,
         ]
@@ -222,6 +229,9 @@ args_description = [
             Arg("--config", action="append", default=[], help=command.CONFIG_DESC),
             Arg("-p", "--passphrase", action="store_true",
                 help="passphrase to encrypt the shell private key"),
+            Arg("--registered-keys", action="store_true",
+                help="do not generate a key; connect with the SSH keys registered with "
+                "`det user ssh-key add`"),
             Arg("--template", type=str,
                 help="name of template to apply to the shell configuration"),
             Arg("-d", "--detach", action="store_true",
@@ -183,6 +183,16 @@ def kill_trial(args: Namespace) -> None:
     print("Killed trial {}".format(args.trial_id))
 
 
+@authentication.required
+def preempt_trial(args: Namespace) -> None:
+    api.post(
+        args.master,
+        "/api/v1/trials/{}/preempt".format(args.trial_id),
+        json={"reason": args.reason or ""},
+    )
+    print("Requested preemption of trial {}".format(args.trial_id))
+
+
 @authentication.required
 def trial_logs(args: Namespace) -> None:
     logs = api.trial_logs(
@@ -455,6 +465,15 @@ args_description = [
             Cmd(
                 "kill", kill_trial, "forcibly terminate a trial", [Arg("trial_id", help="trial ID")]
             ),
+            Cmd(
+                "preempt",
+                preempt_trial,
+                "checkpoint and requeue a trial, freeing its resources (admin only)",
+                [
+                    Arg("trial_id", help="trial ID"),
+                    Arg("--reason", type=str, default=None, help="reason for the preemption"),
+                ],
+            ),
         ],
     ),
 ]  # type: List[Any]
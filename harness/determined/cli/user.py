@@ -1,7 +1,7 @@
 import getpass
-from argparse import Namespace
+from argparse import FileType, Namespace
 from collections import namedtuple
-from typing import Any, List
+from typing import Any, Dict, List, Optional
 
 from termcolor import colored
 
@@ -46,7 +46,7 @@ def log_in_user(parsed_args: Namespace) -> None:
     password = getpass.getpass(message)
 
     token_store = authentication.TokenStore(parsed_args.master)
-    token = authentication.do_login(parsed_args.master, username, password)
+    token = authentication.do_interactive_login(parsed_args.master, username, password)
     token_store.set_token(username, token)
     token_store.set_active(username)
 
@@ -103,7 +103,7 @@ def change_password(parsed_args: Namespace) -> None:
     # password change so that the user doesn't have to do so manually.
     if parsed_args.target_user is None:
         token_store = authentication.TokenStore(parsed_args.master)
-        token = authentication.do_login(parsed_args.master, username, password)
+        token = authentication.do_interactive_login(parsed_args.master, username, password)
         token_store.set_token(username, token)
         token_store.set_active(username)
 
@@ -135,12 +135,142 @@ def create_user(parsed_args: Namespace) -> None:
     client.create_user(username=username, admin=admin)
 
 
+@authentication.required
+def create_service_account(parsed_args: Namespace) -> None:
+    user: Dict[str, Any] = {
+        "username": parsed_args.username,
+        "active": True,
+        "serviceAccount": True,
+    }
+    if parsed_args.owner is not None:
+        user["ownerId"] = _user_id(parsed_args.master, parsed_args.owner)
+    api.post(parsed_args.master, "api/v1/users", json={"user": user})
+    print("Created service account '{}'.".format(parsed_args.username))
+
+
+@authentication.required
+def transfer_service_account(parsed_args: Namespace) -> None:
+    user_id = _user_id(parsed_args.master, parsed_args.username)
+    owner_id = _user_id(parsed_args.master, parsed_args.new_owner)
+    api.patch(parsed_args.master, "api/v1/users/{}".format(user_id), json={"ownerId": owner_id})
+    print(
+        "Transferred service account '{}' to '{}'.".format(
+            parsed_args.username, parsed_args.new_owner
+        )
+    )
+
+
 @login_sdk_client
 def whoami(parsed_args: Namespace) -> None:
     user = client.whoami()
     print("You are logged in as user '{}'".format(user.username))
 
 
+def _user_id(master: str, username: str) -> int:
+    resp = api.get(master, "api/v1/users/{}/by-username".format(username))
+    return int(resp.json()["user"]["id"])
+
+
+def _user_params(master: str, username: Optional[str]) -> Dict[str, Any]:
+    if username is None:
+        return {}
+    return {"user_id": _user_id(master, username)}
+
+
+@authentication.required
+def mfa_status(parsed_args: Namespace) -> None:
+    params = _user_params(parsed_args.master, parsed_args.username)
+    resp = api.get(parsed_args.master, "api/v1/mfa", params=params)
+    mfa = resp.json()["mfa"]
+    print("Enrolled: {}".format(mfa["enrolled"]))
+    if mfa.get("enrolledAt"):
+        print("Enrolled at: {}".format(mfa["enrolledAt"]))
+    print("Required: {}".format(mfa["required"]))
+    print("Recovery codes left: {}".format(mfa["recoveryCodesLeft"]))
+
+
+@authentication.required
+def mfa_enroll(parsed_args: Namespace) -> None:
+    enrollment = api.post(parsed_args.master, "api/v1/mfa/enrollment", json={}).json()
+    print("Add this secret to your authenticator app: {}".format(enrollment["secret"]))
+    print("or scan a QR code of this URI: {}".format(enrollment["uri"]))
+    code = input("Code shown by the authenticator app: ")
+    resp = api.post(parsed_args.master, "api/v1/mfa/enrollment/confirm", json={"code": code})
+    print(colored("Enrolled in MFA.", "green"))
+    _print_recovery_codes(resp.json()["recoveryCodes"])
+
+
+@authentication.required
+def mfa_recovery_codes(parsed_args: Namespace) -> None:
+    code = input("Code shown by the authenticator app: ")
+    resp = api.post(parsed_args.master, "api/v1/mfa/recovery-codes", json={"code": code})
+    _print_recovery_codes(resp.json()["recoveryCodes"])
+
+
+def _print_recovery_codes(codes: List[str]) -> None:
+    print("Each of these recovery codes can be used once instead of a code from the app.")
+    print("Keep them somewhere safe; they will not be shown again.")
+    for code in codes:
+        print(code)
+
+
+@authentication.required
+def mfa_disable(parsed_args: Namespace) -> None:
+    code = input("Code shown by the authenticator app, or a recovery code: ")
+    api.delete(parsed_args.master, "api/v1/mfa", params={"code": code})
+    print("Removed MFA.")
+
+
+@authentication.required
+def mfa_reset(parsed_args: Namespace) -> None:
+    params = _user_params(parsed_args.master, parsed_args.username)
+    api.delete(parsed_args.master, "api/v1/mfa", params=params)
+    print("Reset MFA for user '{}'.".format(parsed_args.username))
+
+
+@authentication.required
+def mfa_require(parsed_args: Namespace) -> None:
+    user_id = _user_id(parsed_args.master, parsed_args.username)
+    api.put(
+        parsed_args.master,
+        "api/v1/users/{}/mfa-required".format(user_id),
+        json={"required": not parsed_args.off},
+    )
+    verb = "no longer requires" if parsed_args.off else "requires"
+    print("The cluster {} MFA of user '{}'.".format(verb, parsed_args.username))
+
+
+@authentication.required
+def ssh_key_add(parsed_args: Namespace) -> None:
+    body = {
+        "publicKey": parsed_args.public_key_file.read(),
+        "name": parsed_args.name or "",
+        "injectIntoTrials": parsed_args.trials,
+    }
+    if parsed_args.username is not None:
+        body["userId"] = _user_id(parsed_args.master, parsed_args.username)
+    key = api.post(parsed_args.master, "api/v1/ssh-keys", json=body).json()["sshKey"]
+    print("Registered SSH key {} ({}).".format(key["id"], key["fingerprint"]))
+
+
+@authentication.required
+def ssh_key_list(parsed_args: Namespace) -> None:
+    params = _user_params(parsed_args.master, parsed_args.username)
+    keys = api.get(parsed_args.master, "api/v1/ssh-keys", params=params).json()["sshKeys"]
+    headers = ["ID", "Name", "Fingerprint", "Trials", "Registered"]
+    values = [
+        [k["id"], k["name"], k["fingerprint"], k["injectIntoTrials"], k["createdAt"]]
+        for k in keys
+    ]
+    render.tabulate_or_csv(headers, values, False)
+
+
+@authentication.required
+def ssh_key_remove(parsed_args: Namespace) -> None:
+    api.delete(parsed_args.master, "api/v1/ssh-keys/{}".format(parsed_args.key_id))
+    print("Removed SSH key {}.".format(parsed_args.key_id))
+
+
 AGENT_USER_GROUP_ARGS = [
     Arg("--agent-uid", type=int, help="UID on the agent to run tasks as"),
     Arg("--agent-user", help="user on the agent to run tasks as"),
@@ -174,11 +304,56 @@ args_description = [
             Arg("username", help="name of new user"),
             Arg("--admin", action="store_true", help="give new user admin rights"),
         ]),
+        Cmd("create-service-account", create_service_account,
+            "create a service account, which authenticates only with access tokens", [
+                Arg("username", help="name of new service account"),
+                Arg("--owner", help="name of user to own the service account "
+                    "(defaults to the active user)"),
+            ]),
+        Cmd("transfer", transfer_service_account, "transfer a service account to a new owner", [
+            Arg("username", help="name of service account to transfer"),
+            Arg("new_owner", help="name of user to become its owner"),
+        ]),
         Cmd("link-with-agent-user", link_with_agent_user, "link a user with UID/GID on agent", [
             Arg("det_username", help="name of Determined user to link"),
             *AGENT_USER_GROUP_ARGS,
         ]),
-        Cmd("whoami", whoami, "print the active user", [])
+        Cmd("whoami", whoami, "print the active user", []),
+        Cmd("mfa", None, "manage multi-factor authentication", [
+            Cmd("status", mfa_status, "show the MFA settings of a user", [
+                Arg("username", nargs="?", default=None,
+                    help="name of user to show, if not the active user"),
+            ], is_default=True),
+            Cmd("enroll", mfa_enroll, "enroll the active user in MFA", []),
+            Cmd("recovery-codes", mfa_recovery_codes,
+                "replace the recovery codes of the active user", []),
+            Cmd("disable", mfa_disable, "remove MFA from the active user", []),
+            Cmd("reset", mfa_reset, "remove MFA from a user who lost their codes", [
+                Arg("username", help="name of user to reset"),
+            ]),
+            Cmd("require", mfa_require, "require a user to enroll in MFA", [
+                Arg("username", help="name of user to require MFA of"),
+                Arg("--off", action="store_true", help="stop requiring MFA of the user"),
+            ]),
+        ]),
+        Cmd("ssh-key", None, "manage the SSH public keys authorized in shells", [
+            Cmd("list ls", ssh_key_list, "list registered SSH keys", [
+                Arg("username", nargs="?", default=None,
+                    help="name of user to list keys of, if not the active user"),
+            ], is_default=True),
+            Cmd("add", ssh_key_add, "register an SSH public key", [
+                Arg("public_key_file", type=FileType("r"),
+                    help="public key file, e.g. ~/.ssh/id_ed25519.pub"),
+                Arg("--name", help="name of the key (defaults to its comment)"),
+                Arg("--trials", action="store_true",
+                    help="also authorize the key in the containers of trials"),
+                Arg("--username", default=None,
+                    help="name of user to register the key for, if not the active user"),
+            ]),
+            Cmd("remove rm", ssh_key_remove, "remove a registered SSH key", [
+                Arg("key_id", type=int, help="ID of the key"),
+            ]),
+        ]),
     ])
 ]  # type: List[Any]
 
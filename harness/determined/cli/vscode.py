@@ -0,0 +1,127 @@
+from argparse import ONE_OR_MORE, FileType, Namespace
+from functools import partial
+from pathlib import Path
+from typing import Any, List
+
+from termcolor import colored
+
+from determined import cli
+from determined.cli import command, render, task
+from determined.common import api, context
+from determined.common.api import authentication, request
+from determined.common.check import check_eq
+from determined.common.declarative_argparse import Arg, Cmd, Group
+
+
+def _open_vscode(master: str, vscode: Any) -> str:
+    return api.browser_open(
+        master,
+        request.make_interactive_task_url(
+            task_id=vscode["id"],
+            service_address=vscode["serviceAddress"],
+            description=vscode["description"],
+            resource_pool=vscode["resourcePool"],
+            task_type="vscode",
+        ),
+    )
+
+
+@authentication.required
+def start_vscode(args: Namespace) -> None:
+    config = command.parse_config(args.config_file, None, args.config, args.volume)
+
+    files = context.read_v1_context(args.context, args.include)
+    body = {
+        "config": config,
+        "files": [f.to_json() for f in files],
+        "preview": args.preview,
+        "templateName": args.template,
+    }
+    resp = api.post(args.master, "api/v1/vscodes", json=body).json()
+
+    if args.preview:
+        print(render.format_object_as_yaml(resp["config"]))
+        return
+
+    vscode = resp["vscode"]
+
+    if args.detach:
+        print(vscode["id"])
+        return
+
+    with api.ws(args.master, "vscodes/{}/events".format(vscode["id"])) as ws:
+        for msg in ws:
+            if msg["service_ready_event"] and vscode["serviceAddress"] and not args.no_browser:
+                url = _open_vscode(args.master, vscode)
+                print(colored("VS Code is running at: {}".format(url), "green"))
+            command.render_event_stream(msg)
+
+
+@authentication.required
+def open_vscode(args: Namespace) -> None:
+    vscode_id = command.expand_uuid_prefixes(args)
+    resp = api.get(args.master, "api/v1/vscodes/{}".format(vscode_id)).json()["vscode"]
+    check_eq(resp["state"], "STATE_RUNNING", "VS Code server must be in a running state")
+    _open_vscode(args.master, resp)
+
+
+# fmt: off
+
+args_description = [
+    Cmd("vscode", None, "manage VS Code servers", [
+        Cmd("list ls", partial(command.list_tasks), "list VS Code servers", [
+            Arg("-q", "--quiet", action="store_true",
+                help="only display the IDs"),
+            Arg("--all", "-a", action="store_true",
+                help="show all VS Code servers (including other users')"),
+            Group(cli.output_format_args["json"], cli.output_format_args["csv"]),
+        ], is_default=True),
+        Cmd("config", partial(command.config),
+            "display VS Code server config", [
+                Arg("vscode_id", type=str, help="VS Code server ID"),
+        ]),
+        Cmd("start", start_vscode, "start a new VS Code server", [
+            Arg("--config-file", default=None, type=FileType("r"),
+                help="command config file (.yaml)"),
+            Arg("-v", "--volume", action="append", default=[],
+                help=command.VOLUME_DESC),
+            Arg("-c", "--context", default=None, type=Path, help=command.CONTEXT_DESC),
+            Arg(
+                "-i",
+                "--include",
+                default=[],
+                action="append",
+                type=Path,
+                help=command.INCLUDE_DESC
+            ),
+            Arg("--config", action="append", default=[], help=command.CONFIG_DESC),
+            Arg("--template", type=str,
+                help="name of template to apply to the VS Code server configuration"),
+            Arg("--no-browser", action="store_true",
+                help="don't open VS Code in a browser after startup"),
+            Arg("-d", "--detach", action="store_true",
+                help="run in the background and print the ID"),
+            Arg("--preview", action="store_true",
+                help="preview the VS Code server configuration"),
+        ]),
+        Cmd("open", open_vscode, "open an existing VS Code server", [
+            Arg("vscode_id", help="VS Code server ID")
+        ]),
+        Cmd("logs", partial(task.logs), "fetch VS Code server logs", [
+            Arg("task_id", help="VS Code server ID", metavar="vscode_id"),
+            *task.common_log_options
+        ]),
+        Cmd("kill", partial(command.kill), "kill a VS Code server", [
+            Arg("vscode_id", help="VS Code server ID", nargs=ONE_OR_MORE),
+            Arg("-f", "--force", action="store_true", help="ignore errors"),
+        ]),
+        Cmd("set", None, "set VS Code server attributes", [
+            Cmd("priority", partial(command.set_priority), "set VS Code server priority", [
+                Arg("vscode_id", help="VS Code server ID"),
+                Arg("priority", type=int, help="priority"),
+            ]),
+        ]),
+    ])
+]  # type: List[Any]
+
+# fmt: on
@@ -102,7 +102,7 @@ class Authentication:
             password = getpass.getpass("Password for user '{}': ".format(session_user))
 
         try:
-            token = do_login(self.master_address, session_user, password, cert)
+            token = do_interactive_login(self.master_address, session_user, password, cert)
         except api.errors.ForbiddenException:
             if fallback_to_default:
                 raise api.errors.UnauthenticatedException(username=session_user)
@@ -141,16 +141,78 @@ def do_login(
     username: str,
     password: str,
     cert: Optional[certs.Cert] = None,
+    mfa_code: Optional[str] = None,
+    new_password: Optional[str] = None,
 ) -> str:
-    password = api.salt_and_hash(password)
+    # Masters that check passwords against an LDAP directory need them unhashed, as do masters
+    # that check new passwords against a password policy; they hash the passwords of local users
+    # themselves.
+    info = api.get(master_address, "api/v1/master", authenticated=False, cert=cert).json()
+    hashed = not info.get("ldapEnabled", False)
+    if new_password is not None and info.get("passwordComplexityRequired", False):
+        hashed = False
+    if hashed:
+        password = api.salt_and_hash(password)
+        if new_password is not None:
+            new_password = api.salt_and_hash(new_password)
     unauth_session = api.Session(user=username, master=master_address, auth=None, cert=cert)
-    login = bindings.v1LoginRequest(username=username, password=password, isHashed=True)
-    r = bindings.post_Login(session=unauth_session, body=login)
+    login = bindings.v1LoginRequest(
+        username=username,
+        password=password,
+        isHashed=hashed,
+        mfaCode=mfa_code,
+        newPassword=new_password,
+    )
+    try:
+        r = bindings.post_Login(session=unauth_session, body=login)
+    except api.errors.APIException as e:
+        if "MFA code required" in str(e):
+            raise api.errors.MFARequiredException(username=username)
+        if "password expired" in str(e):
+            raise api.errors.PasswordExpiredException(username=username)
+        raise
     token = r.token
 
     return token
 
 
+def do_interactive_login(
+    master_address: str,
+    username: str,
+    password: str,
+    cert: Optional[certs.Cert] = None,
+) -> str:
+    """
+    Log in like do_login, prompting for a new password if the password of the user has expired
+    and for a code from the authenticator app of the user if they are enrolled in multi-factor
+    authentication.
+    """
+    mfa_code = None
+    new_password = None
+    while True:
+        try:
+            return do_login(
+                master_address,
+                username,
+                password,
+                cert,
+                mfa_code=mfa_code,
+                new_password=new_password,
+            )
+        except api.errors.PasswordExpiredException:
+            if new_password is not None:
+                raise
+            new_password = getpass.getpass(
+                "Password for user '{}' has expired; new password: ".format(username)
+            )
+            if getpass.getpass("Confirm password: ") != new_password:
+                raise api.errors.BadRequestException("passwords do not match")
+        except api.errors.MFARequiredException:
+            if mfa_code is not None:
+                raise
+            mfa_code = input("MFA code for user '{}': ".format(username))
+
+
 def _is_token_valid(master_address: str, token: str, cert: Optional[certs.Cert]) -> bool:
     """
     Find out whether the given token is valid by attempting to use it
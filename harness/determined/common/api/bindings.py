@@ -5658,6 +5658,8 @@ class v1LogLevel(enum.Enum):
 
 class v1LoginRequest:
     isHashed: "typing.Optional[bool]" = None
+    mfaCode: "typing.Optional[str]" = None
+    newPassword: "typing.Optional[str]" = None
 
     def __init__(
         self,
@@ -5665,11 +5667,17 @@ class v1LoginRequest:
         password: str,
         username: str,
         isHashed: "typing.Union[bool, None, Unset]" = _unset,
+        mfaCode: "typing.Union[str, None, Unset]" = _unset,
+        newPassword: "typing.Union[str, None, Unset]" = _unset,
     ):
         self.password = password
         self.username = username
         if not isinstance(isHashed, Unset):
             self.isHashed = isHashed
+        if not isinstance(mfaCode, Unset):
+            self.mfaCode = mfaCode
+        if not isinstance(newPassword, Unset):
+            self.newPassword = newPassword
 
     @classmethod
     def from_json(cls, obj: Json) -> "v1LoginRequest":
@@ -5679,6 +5687,10 @@ class v1LoginRequest:
         }
         if "isHashed" in obj:
             kwargs["isHashed"] = obj["isHashed"]
+        if "mfaCode" in obj:
+            kwargs["mfaCode"] = obj["mfaCode"]
+        if "newPassword" in obj:
+            kwargs["newPassword"] = obj["newPassword"]
         return cls(**kwargs)
 
     def to_json(self, omit_unset: bool = False) -> typing.Any:
@@ -5688,18 +5700,26 @@ class v1LoginRequest:
         }
         if not omit_unset or "isHashed" in vars(self):
             out["isHashed"] = self.isHashed
+        if not omit_unset or "mfaCode" in vars(self):
+            out["mfaCode"] = self.mfaCode
+        if not omit_unset or "newPassword" in vars(self):
+            out["newPassword"] = self.newPassword
         return out
 
 class v1LoginResponse:
+    mfaEnrollmentRequired: "typing.Optional[bool]" = None
 
     def __init__(
         self,
         *,
         token: str,
         user: "v1User",
+        mfaEnrollmentRequired: "typing.Union[bool, None, Unset]" = _unset,
     ):
         self.token = token
         self.user = user
+        if not isinstance(mfaEnrollmentRequired, Unset):
+            self.mfaEnrollmentRequired = mfaEnrollmentRequired
 
     @classmethod
     def from_json(cls, obj: Json) -> "v1LoginResponse":
@@ -5707,6 +5727,8 @@ class v1LoginResponse:
             "token": obj["token"],
             "user": v1User.from_json(obj["user"]),
         }
+        if "mfaEnrollmentRequired" in obj:
+            kwargs["mfaEnrollmentRequired"] = obj["mfaEnrollmentRequired"]
         return cls(**kwargs)
 
     def to_json(self, omit_unset: bool = False) -> typing.Any:
@@ -5714,6 +5736,8 @@ class v1LoginResponse:
             "token": self.token,
             "user": self.user.to_json(omit_unset),
         }
+        if not omit_unset or "mfaEnrollmentRequired" in vars(self):
+            out["mfaEnrollmentRequired"] = self.mfaEnrollmentRequired
         return out
 
 class v1MarkAllocationResourcesDaemonRequest:
@@ -6782,6 +6806,7 @@ class v1PatchUser:
     agentUserGroup: "typing.Optional[v1AgentUserGroup]" = None
     displayName: "typing.Optional[str]" = None
     isHashed: "typing.Optional[bool]" = None
+    ownerId: "typing.Optional[int]" = None
     password: "typing.Optional[str]" = None
     username: "typing.Optional[str]" = None
 
@@ -6793,6 +6818,7 @@ class v1PatchUser:
         agentUserGroup: "typing.Union[v1AgentUserGroup, None, Unset]" = _unset,
         displayName: "typing.Union[str, None, Unset]" = _unset,
         isHashed: "typing.Union[bool, None, Unset]" = _unset,
+        ownerId: "typing.Union[int, None, Unset]" = _unset,
         password: "typing.Union[str, None, Unset]" = _unset,
         username: "typing.Union[str, None, Unset]" = _unset,
     ):
@@ -6806,6 +6832,8 @@ class v1PatchUser:
             self.displayName = displayName
         if not isinstance(isHashed, Unset):
             self.isHashed = isHashed
+        if not isinstance(ownerId, Unset):
+            self.ownerId = ownerId
         if not isinstance(password, Unset):
             self.password = password
         if not isinstance(username, Unset):
@@ -6825,6 +6853,8 @@ class v1PatchUser:
             kwargs["displayName"] = obj["displayName"]
         if "isHashed" in obj:
             kwargs["isHashed"] = obj["isHashed"]
+        if "ownerId" in obj:
+            kwargs["ownerId"] = obj["ownerId"]
         if "password" in obj:
             kwargs["password"] = obj["password"]
         if "username" in obj:
@@ -6844,6 +6874,8 @@ class v1PatchUser:
             out["displayName"] = self.displayName
         if not omit_unset or "isHashed" in vars(self):
             out["isHashed"] = self.isHashed
+        if not omit_unset or "ownerId" in vars(self):
+            out["ownerId"] = self.ownerId
         if not omit_unset or "password" in vars(self):
             out["password"] = self.password
         if not omit_unset or "username" in vars(self):
@@ -11065,6 +11097,8 @@ class v1User:
     displayName: "typing.Optional[str]" = None
     id: "typing.Optional[int]" = None
     modifiedAt: "typing.Optional[str]" = None
+    ownerId: "typing.Optional[int]" = None
+    serviceAccount: "typing.Optional[bool]" = None
 
     def __init__(
         self,
@@ -11076,6 +11110,8 @@ class v1User:
         displayName: "typing.Union[str, None, Unset]" = _unset,
         id: "typing.Union[int, None, Unset]" = _unset,
         modifiedAt: "typing.Union[str, None, Unset]" = _unset,
+        ownerId: "typing.Union[int, None, Unset]" = _unset,
+        serviceAccount: "typing.Union[bool, None, Unset]" = _unset,
     ):
         self.active = active
         self.admin = admin
@@ -11088,6 +11124,10 @@ class v1User:
             self.id = id
         if not isinstance(modifiedAt, Unset):
             self.modifiedAt = modifiedAt
+        if not isinstance(ownerId, Unset):
+            self.ownerId = ownerId
+        if not isinstance(serviceAccount, Unset):
+            self.serviceAccount = serviceAccount
 
     @classmethod
     def from_json(cls, obj: Json) -> "v1User":
@@ -11104,6 +11144,10 @@ class v1User:
             kwargs["id"] = obj["id"]
         if "modifiedAt" in obj:
             kwargs["modifiedAt"] = obj["modifiedAt"]
+        if "ownerId" in obj:
+            kwargs["ownerId"] = obj["ownerId"]
+        if "serviceAccount" in obj:
+            kwargs["serviceAccount"] = obj["serviceAccount"]
         return cls(**kwargs)
 
     def to_json(self, omit_unset: bool = False) -> typing.Any:
@@ -11120,6 +11164,10 @@ class v1User:
             out["id"] = self.id
         if not omit_unset or "modifiedAt" in vars(self):
             out["modifiedAt"] = self.modifiedAt
+        if not omit_unset or "ownerId" in vars(self):
+            out["ownerId"] = self.ownerId
+        if not omit_unset or "serviceAccount" in vars(self):
+            out["serviceAccount"] = self.serviceAccount
         return out
 
 class v1UserRoleAssignment:
@@ -51,6 +51,26 @@ class ForbiddenException(BadRequestException):
         self.username = username
 
 
+class MFARequiredException(BadRequestException):
+    """
+    Raised when logging in requires a code from the authenticator app of the user.
+    """
+
+    def __init__(self, username: str):
+        super().__init__(message=f"MFA code required for user '{username}'")
+        self.username = username
+
+
+class PasswordExpiredException(BadRequestException):
+    """
+    Raised when logging in requires a new password because the password of the user has expired.
+    """
+
+    def __init__(self, username: str):
+        super().__init__(message=f"password expired for user '{username}'")
+        self.username = username
+
+
 class UnauthenticatedException(BadRequestException):
     def __init__(self, username: str):
         super().__init__(
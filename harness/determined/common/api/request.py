@@ -46,11 +46,12 @@ def maybe_upgrade_ws_scheme(master_address: str) -> str:
 def make_interactive_task_url(
     task_id: str, service_address: str, description: str, resource_pool: str, task_type: str
 ) -> str:
-    wait_path = (
-        "/notebooks/{}/events".format(task_id)
-        if task_type == "notebook"
-        else "/tensorboard/{}/events?tail=1".format(task_id)
-    )
+    if task_type == "notebook":
+        wait_path = "/notebooks/{}/events".format(task_id)
+    elif task_type == "vscode":
+        wait_path = "/vscodes/{}/events".format(task_id)
+    else:
+        wait_path = "/tensorboard/{}/events?tail=1".format(task_id)
     wait_path_url = service_address + wait_path
     public_url = os.environ.get("PUBLIC_URL", "/det")
     wait_page_url = "{}/wait/{}/{}?eventUrl={}&serviceAddr={}".format(
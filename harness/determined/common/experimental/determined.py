@@ -90,10 +90,12 @@ class Determined:
 
     def create_user(self, username: str, admin: bool, password: Optional[str]) -> user.User:
         create_user = bindings.v1User(username=username, admin=admin, active=True)
-        hashedPassword = None
-        if password is not None:
-            hashedPassword = api.salt_and_hash(password)
-        req = bindings.v1PostUserRequest(password=hashedPassword, user=create_user, isHashed=True)
+        # Masters with a password policy check new passwords, which must then be sent unhashed.
+        info = self._session.get("api/v1/master").json()
+        hashed = not info.get("passwordComplexityRequired", False)
+        if password is not None and hashed:
+            password = api.salt_and_hash(password)
+        req = bindings.v1PostUserRequest(password=password, user=create_user, isHashed=hashed)
         resp = bindings.post_PostUser(self._session, body=req)
         assert resp.user is not None
         return self._from_bindings(resp.user)
@@ -65,8 +65,12 @@ class User:
         self._reload(resp.user)
 
     def change_password(self, new_password: str) -> None:
-        new_password = api.salt_and_hash(new_password)
-        patch_user = bindings.v1PatchUser(password=new_password, isHashed=True)
+        # Masters with a password policy check new passwords, which must then be sent unhashed.
+        info = self._session.get("api/v1/master").json()
+        hashed = not info.get("passwordComplexityRequired", False)
+        if hashed:
+            new_password = api.salt_and_hash(new_password)
+        patch_user = bindings.v1PatchUser(password=new_password, isHashed=hashed)
         resp = bindings.patch_PatchUser(self._session, body=patch_user, userId=self.user_id)
         self._reload(resp.user)
 
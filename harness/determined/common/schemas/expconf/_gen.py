@@ -3,6 +3,97 @@
 import json
 
 schemas = {
+    "http://determined.ai/schemas/expconf/v0/alert.json": json.loads(
+        r"""
+{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "$id": "http://determined.ai/schemas/expconf/v0/alert.json",
+    "title": "Alert",
+    "additionalProperties": false,
+    "required": [
+        "name",
+        "metric"
+    ],
+    "type": "object",
+    "properties": {
+        "name": {
+            "type": "string",
+            "checks": {
+                "name must not be empty": {
+                    "minLength": 1
+                }
+            }
+        },
+        "metric": {
+            "type": "string",
+            "checks": {
+                "metric must not be empty": {
+                    "minLength": 1
+                }
+            }
+        },
+        "group": {
+            "type": [
+                "string",
+                "null"
+            ],
+            "default": "validation"
+        },
+        "above": {
+            "type": [
+                "number",
+                "null"
+            ],
+            "default": null
+        },
+        "below": {
+            "type": [
+                "number",
+                "null"
+            ],
+            "default": null
+        },
+        "nan": {
+            "type": [
+                "boolean",
+                "null"
+            ],
+            "default": false
+        },
+        "after_batches": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": 0,
+            "minimum": 0
+        },
+        "pause_experiment": {
+            "type": [
+                "boolean",
+                "null"
+            ],
+            "default": false
+        }
+    }
+}
+
+"""
+    ),
+    "http://determined.ai/schemas/expconf/v0/alerts.json": json.loads(
+        r"""
+{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "$id": "http://determined.ai/schemas/expconf/v0/alerts.json",
+    "title": "AlertsConfig",
+    "type": "array",
+    "items": {
+        "$ref": "http://determined.ai/schemas/expconf/v0/alert.json"
+    }
+}
+
+"""
+    ),
     "http://determined.ai/schemas/expconf/v0/azure.json": json.loads(
         r"""
 {
@@ -112,6 +203,51 @@ schemas = {
     }
 }
 
+"""
+    ),
+    "http://determined.ai/schemas/expconf/v0/best-checkpoint.json": json.loads(
+        r"""
+{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "$id": "http://determined.ai/schemas/expconf/v0/best-checkpoint.json",
+    "title": "BestCheckpointConfig",
+    "type": "object",
+    "additionalProperties": false,
+    "required": [],
+    "properties": {
+        "metric": {
+            "type": [
+                "string",
+                "null"
+            ],
+            "default": null
+        },
+        "smaller_is_better": {
+            "type": [
+                "boolean",
+                "null"
+            ],
+            "default": null
+        },
+        "smoothing_window": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": 1,
+            "minimum": 1
+        },
+        "min_length": {
+            "type": [
+                "object",
+                "null"
+            ],
+            "default": null,
+            "optionalRef": "http://determined.ai/schemas/expconf/v0/length.json"
+        }
+    }
+}
+
 """
     ),
     "http://determined.ai/schemas/expconf/v0/bind-mount.json": json.loads(
@@ -720,6 +856,89 @@ schemas = {
     }
 }
 
+"""
+    ),
+    "http://determined.ai/schemas/expconf/v0/early-stopping.json": json.loads(
+        r"""
+{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "$id": "http://determined.ai/schemas/expconf/v0/early-stopping.json",
+    "title": "EarlyStoppingConfig",
+    "type": "object",
+    "additionalProperties": false,
+    "required": [],
+    "properties": {
+        "metric": {
+            "type": [
+                "string",
+                "null"
+            ],
+            "default": null
+        },
+        "smaller_is_better": {
+            "type": [
+                "boolean",
+                "null"
+            ],
+            "default": null
+        },
+        "patience": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
+        "min_delta": {
+            "type": [
+                "number",
+                "null"
+            ],
+            "default": 0,
+            "minimum": 0
+        },
+        "stop_on_nan": {
+            "type": [
+                "boolean",
+                "null"
+            ],
+            "default": false
+        }
+    }
+}
+
+"""
+    ),
+    "http://determined.ai/schemas/expconf/v0/elastic.json": json.loads(
+        r"""
+{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "$id": "http://determined.ai/schemas/expconf/v0/elastic.json",
+    "title": "ElasticConfig",
+    "type": "object",
+    "additionalProperties": false,
+    "required": [
+        "min_slots_per_trial",
+        "max_slots_per_trial"
+    ],
+    "properties": {
+        "min_slots_per_trial": {
+            "type": "integer",
+            "minimum": 1
+        },
+        "max_slots_per_trial": {
+            "type": "integer",
+            "minimum": 1
+        }
+    },
+    "compareProperties": {
+        "type": "a<=b",
+        "a": "min_slots_per_trial",
+        "b": "max_slots_per_trial"
+    }
+}
+
 """
     ),
     "http://determined.ai/schemas/expconf/v0/environment-image-map.json": json.loads(
@@ -914,6 +1133,21 @@ schemas = {
                 "type": "integer"
             }
         },
+        "proxy_ports": {
+            "type": [
+                "object",
+                "null"
+            ],
+            "default": {},
+            "additionalProperties": {
+                "type": "integer",
+                "minimum": 1,
+                "maximum": 65535
+            },
+            "propertyNames": {
+                "pattern": "^[a-z0-9]([a-z0-9-]*[a-z0-9])?$"
+            }
+        },
         "force_pull_image": {
             "type": [
                 "boolean",
@@ -995,6 +1229,72 @@ schemas = {
                     }
                 }
             }
+        },
+        "pod_template_name": {
+            "type": [
+                "string",
+                "null"
+            ],
+            "default": null,
+            "minLength": 1
+        },
+        "init_containers": {
+            "type": [
+                "array",
+                "null"
+            ],
+            "default": null,
+            "items": {
+                "type": "object",
+                "required": [
+                    "name",
+                    "image"
+                ],
+                "properties": {
+                    "name": {
+                        "type": "string",
+                        "not": {
+                            "enum": [
+                                "determined-container",
+                                "determined-fluent-container",
+                                "determined-init-container"
+                            ]
+                        }
+                    },
+                    "image": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "sidecars": {
+            "type": [
+                "array",
+                "null"
+            ],
+            "default": null,
+            "items": {
+                "type": "object",
+                "required": [
+                    "name",
+                    "image"
+                ],
+                "properties": {
+                    "name": {
+                        "type": "string",
+                        "not": {
+                            "enum": [
+                                "determined-container",
+                                "determined-fluent-container",
+                                "determined-init-container"
+                            ]
+                        }
+                    },
+                    "image": {
+                        "type": "string"
+                    }
+                }
+            }
         }
     }
 }
@@ -1018,6 +1318,14 @@ schemas = {
         "searcher"
     ],
     "properties": {
+        "alerts": {
+            "type": [
+                "array",
+                "null"
+            ],
+            "default": null,
+            "optionalRef": "http://determined.ai/schemas/expconf/v0/alerts.json"
+        },
         "bind_mounts": {
             "type": [
                 "array",
@@ -1026,6 +1334,14 @@ schemas = {
             "default": [],
             "optionalRef": "http://determined.ai/schemas/expconf/v0/bind-mounts.json"
         },
+        "best_checkpoint": {
+            "type": [
+                "object",
+                "null"
+            ],
+            "default": null,
+            "optionalRef": "http://determined.ai/schemas/expconf/v0/best-checkpoint.json"
+        },
         "checkpoint_policy": {
             "enum": [
                 null,
@@ -1074,6 +1390,14 @@ schemas = {
             ],
             "default": null
         },
+        "early_stopping": {
+            "type": [
+                "object",
+                "null"
+            ],
+            "default": null,
+            "optionalRef": "http://determined.ai/schemas/expconf/v0/early-stopping.json"
+        },
         "entrypoint": {
             "type": [
                 "string",
@@ -1295,6 +1619,9 @@ schemas = {
             },
             "then": {
                 "properties": {
+                    "best_checkpoint": {
+                        "$ref": "http://determined.ai/schemas/expconf/v0/check-epoch-not-used.json"
+                    },
                     "min_validation_period": {
                         "$ref": "http://determined.ai/schemas/expconf/v0/check-epoch-not-used.json"
                     },
@@ -2087,6 +2414,15 @@ schemas = {
             ],
             "default": null,
             "minimum": 0
+        },
+        "trial_seed": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 0,
+            "maximum": 2147483647
         }
     }
 }
@@ -2118,6 +2454,32 @@ schemas = {
             "default": [],
             "optionalRef": "http://determined.ai/schemas/expconf/v0/devices.json"
         },
+        "elastic": {
+            "type": [
+                "object",
+                "null"
+            ],
+            "default": null,
+            "optionalRef": "http://determined.ai/schemas/expconf/v0/elastic.json"
+        },
+        "expected_runtime_seconds": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "minimum": 1,
+            "default": null
+        },
+        "label_constraints": {
+            "type": [
+                "object",
+                "null"
+            ],
+            "default": null,
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
         "max_slots": {
             "type": [
                 "integer",
@@ -2314,6 +2676,14 @@ schemas = {
         "name": {
             "const": "adaptive_asha"
         },
+        "brackets": {
+            "type": [
+                "array",
+                "null"
+            ],
+            "default": null,
+            "optionalRef": "http://determined.ai/schemas/expconf/v0/searcher-adaptive-brackets.json"
+        },
         "bracket_rungs": {
             "type": [
                 "array",
@@ -2395,6 +2765,21 @@ schemas = {
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -2412,6 +2797,65 @@ schemas = {
     }
 }
 
+"""
+    ),
+    "http://determined.ai/schemas/expconf/v0/searcher-adaptive-bracket.json": json.loads(
+        r"""
+{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "$id": "http://determined.ai/schemas/expconf/v0/searcher-adaptive-bracket.json",
+    "title": "AdaptiveBracket",
+    "additionalProperties": false,
+    "required": [
+        "rung_lengths"
+    ],
+    "type": "object",
+    "properties": {
+        "rung_lengths": {
+            "type": "array",
+            "items": {
+                "type": "integer",
+                "minimum": 1
+            },
+            "checks": {
+                "rung_lengths must not be empty": {
+                    "minItems": 1
+                }
+            }
+        },
+        "max_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
+        "max_concurrent_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        }
+    }
+}
+
+"""
+    ),
+    "http://determined.ai/schemas/expconf/v0/searcher-adaptive-brackets.json": json.loads(
+        r"""
+{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "$id": "http://determined.ai/schemas/expconf/v0/searcher-adaptive-brackets.json",
+    "title": "AdaptiveBracketsConfig",
+    "type": "array",
+    "items": {
+        "$ref": "http://determined.ai/schemas/expconf/v0/searcher-adaptive-bracket.json"
+    }
+}
+
 """
     ),
     "http://determined.ai/schemas/expconf/v0/searcher-adaptive-simple.json": json.loads(
@@ -2491,6 +2935,21 @@ schemas = {
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -2603,6 +3062,21 @@ schemas = {
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -2704,6 +3178,21 @@ schemas = {
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -2819,6 +3308,21 @@ schemas = {
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -2921,6 +3425,21 @@ schemas = {
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -2982,6 +3501,21 @@ schemas = {
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -3076,6 +3610,21 @@ schemas = {
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -3160,6 +3709,7 @@ schemas = {
     ],
     "properties": {
         "bracket_rungs": true,
+        "brackets": true,
         "divisor": true,
         "max_concurrent_trials": true,
         "max_length": true,
@@ -3183,6 +3733,21 @@ schemas = {
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
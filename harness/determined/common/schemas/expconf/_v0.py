@@ -36,10 +36,27 @@ class DeviceV0(schemas.SchemaBase):
         return super().from_dict(d, prevalidated)
 
 
+class ElasticConfigV0(schemas.SchemaBase):
+    _id = "http://determined.ai/schemas/expconf/v0/elastic.json"
+    min_slots_per_trial: int
+    max_slots_per_trial: int
+
+    @schemas.auto_init
+    def __init__(
+        self,
+        min_slots_per_trial: int,
+        max_slots_per_trial: int,
+    ) -> None:
+        pass
+
+
 class ResourcesConfigV0(schemas.SchemaBase):
     _id = "http://determined.ai/schemas/expconf/v0/resources.json"
     agent_label: Optional[str] = None
     devices: Optional[List[DeviceV0]] = None
+    elastic: Optional[ElasticConfigV0] = None
+    expected_runtime_seconds: Optional[int] = None
+    label_constraints: Optional[Dict[str, str]] = None
     max_slots: Optional[int] = None
     native_parallel: Optional[bool] = None
     priority: Optional[int] = None
@@ -53,6 +70,9 @@ class ResourcesConfigV0(schemas.SchemaBase):
         self,
         agent_label: Optional[str] = None,
         devices: Optional[List[DeviceV0]] = None,
+        elastic: Optional[ElasticConfigV0] = None,
+        expected_runtime_seconds: Optional[int] = None,
+        label_constraints: Optional[Dict[str, str]] = None,
         max_slots: Optional[int] = None,
         native_parallel: Optional[bool] = None,
         priority: Optional[int] = None,
@@ -130,14 +150,42 @@ class BindMountV0(schemas.SchemaBase):
         pass
 
 
+class AlertV0(schemas.SchemaBase):
+    _id = "http://determined.ai/schemas/expconf/v0/alert.json"
+    metric: str
+    name: str
+    above: Optional[float] = None
+    after_batches: Optional[int] = None
+    below: Optional[float] = None
+    group: Optional[str] = None
+    nan: Optional[bool] = None
+    pause_experiment: Optional[bool] = None
+
+    @schemas.auto_init
+    def __init__(
+        self,
+        metric: str,
+        name: str,
+        above: Optional[float] = None,
+        after_batches: Optional[int] = None,
+        below: Optional[float] = None,
+        group: Optional[str] = None,
+        nan: Optional[bool] = None,
+        pause_experiment: Optional[bool] = None,
+    ) -> None:
+        pass
+
+
 class ReproducibilityConfigV0(schemas.SchemaBase):
     _id = "http://determined.ai/schemas/expconf/v0/reproducibility.json"
     experiment_seed: Optional[int] = None
+    trial_seed: Optional[int] = None
 
     @schemas.auto_init
     def __init__(
         self,
         experiment_seed: Optional[int] = None,
+        trial_seed: Optional[int] = None,
     ) -> None:
         pass
 
@@ -188,6 +236,44 @@ class LengthV0(schemas.SchemaBase):
         return {"batches": None}
 
 
+class BestCheckpointConfigV0(schemas.SchemaBase):
+    _id = "http://determined.ai/schemas/expconf/v0/best-checkpoint.json"
+    metric: Optional[str] = None
+    smaller_is_better: Optional[bool] = None
+    smoothing_window: Optional[int] = None
+    min_length: Optional[LengthV0] = None
+
+    @schemas.auto_init
+    def __init__(
+        self,
+        metric: Optional[str] = None,
+        smaller_is_better: Optional[bool] = None,
+        smoothing_window: Optional[int] = None,
+        min_length: Optional[LengthV0] = None,
+    ) -> None:
+        pass
+
+
+class EarlyStoppingConfigV0(schemas.SchemaBase):
+    _id = "http://determined.ai/schemas/expconf/v0/early-stopping.json"
+    metric: Optional[str] = None
+    smaller_is_better: Optional[bool] = None
+    patience: Optional[int] = None
+    min_delta: Optional[float] = None
+    stop_on_nan: Optional[bool] = None
+
+    @schemas.auto_init
+    def __init__(
+        self,
+        metric: Optional[str] = None,
+        smaller_is_better: Optional[bool] = None,
+        patience: Optional[int] = None,
+        min_delta: Optional[float] = None,
+        stop_on_nan: Optional[bool] = None,
+    ) -> None:
+        pass
+
+
 def _parse_length_or_int(value: Any, prevalidated: bool) -> Any:
     if isinstance(value, int):
         return value
@@ -365,9 +451,13 @@ class EnvironmentConfigV0(schemas.SchemaBase):
     environment_variables: Optional[EnvironmentVariablesV0] = None
     force_pull_image: Optional[bool] = None
     image: Optional[EnvironmentImageV0] = None
+    init_containers: Optional[List[Dict[str, Any]]] = None
     pod_spec: Optional[Dict[str, Any]] = None
+    pod_template_name: Optional[str] = None
     ports: Optional[Dict[str, int]] = None
+    proxy_ports: Optional[Dict[str, int]] = None
     registry_auth: Optional[RegistryAuthConfigV0] = None
+    sidecars: Optional[List[Dict[str, Any]]] = None
 
     @schemas.auto_init
     def __init__(
@@ -377,9 +467,13 @@ class EnvironmentConfigV0(schemas.SchemaBase):
         environment_variables: Optional[EnvironmentVariablesV0] = None,
         force_pull_image: Optional[bool] = None,
         image: Optional[EnvironmentImageV0] = None,
+        init_containers: Optional[List[Dict[str, Any]]] = None,
         pod_spec: Optional[Dict[str, Any]] = None,
+        pod_template_name: Optional[str] = None,
         ports: Optional[Dict[str, int]] = None,
+        proxy_ports: Optional[Dict[str, int]] = None,
         registry_auth: Optional[RegistryAuthConfigV0] = None,
+        sidecars: Optional[List[Dict[str, Any]]] = None,
     ) -> None:
         pass
 
@@ -516,6 +610,8 @@ class SingleConfigV0(schemas.SchemaBase):
     smaller_is_better: Optional[bool] = None
     source_checkpoint_uuid: Optional[str] = None
     source_trial_id: Optional[int] = None
+    warm_start_experiment_id: Optional[int] = None
+    warm_start_trials: Optional[int] = None
 
     @schemas.auto_init
     def __init__(
@@ -525,6 +621,8 @@ class SingleConfigV0(schemas.SchemaBase):
         smaller_is_better: Optional[bool] = None,
         source_checkpoint_uuid: Optional[str] = None,
         source_trial_id: Optional[int] = None,
+        warm_start_experiment_id: Optional[int] = None,
+        warm_start_trials: Optional[int] = None,
     ) -> None:
         pass
 
@@ -556,6 +654,8 @@ class RandomConfigV0(schemas.SchemaBase):
     smaller_is_better: Optional[bool] = None
     source_checkpoint_uuid: Optional[str] = None
     source_trial_id: Optional[int] = None
+    warm_start_experiment_id: Optional[int] = None
+    warm_start_trials: Optional[int] = None
 
     @schemas.auto_init
     def __init__(
@@ -567,6 +667,8 @@ class RandomConfigV0(schemas.SchemaBase):
         smaller_is_better: Optional[bool] = None,
         source_checkpoint_uuid: Optional[str] = None,
         source_trial_id: Optional[int] = None,
+        warm_start_experiment_id: Optional[int] = None,
+        warm_start_trials: Optional[int] = None,
     ) -> None:
         pass
 
@@ -580,6 +682,8 @@ class GridConfigV0(schemas.SchemaBase):
     smaller_is_better: Optional[bool] = None
     source_checkpoint_uuid: Optional[str] = None
     source_trial_id: Optional[int] = None
+    warm_start_experiment_id: Optional[int] = None
+    warm_start_trials: Optional[int] = None
 
     @schemas.auto_init
     def __init__(
@@ -590,6 +694,8 @@ class GridConfigV0(schemas.SchemaBase):
         smaller_is_better: Optional[bool] = None,
         source_checkpoint_uuid: Optional[str] = None,
         source_trial_id: Optional[int] = None,
+        warm_start_experiment_id: Optional[int] = None,
+        warm_start_trials: Optional[int] = None,
     ) -> None:
         pass
 
@@ -607,6 +713,8 @@ class AsyncHalvingConfigV0(schemas.SchemaBase):
     source_checkpoint_uuid: Optional[str] = None
     source_trial_id: Optional[int] = None
     stop_once: Optional[int] = None
+    warm_start_experiment_id: Optional[int] = None
+    warm_start_trials: Optional[int] = None
 
     @schemas.auto_init
     def __init__(
@@ -621,6 +729,24 @@ class AsyncHalvingConfigV0(schemas.SchemaBase):
         source_checkpoint_uuid: Optional[str] = None,
         source_trial_id: Optional[int] = None,
         stop_once: Optional[int] = None,
+        warm_start_experiment_id: Optional[int] = None,
+        warm_start_trials: Optional[int] = None,
+    ) -> None:
+        pass
+
+
+class AdaptiveBracketV0(schemas.SchemaBase):
+    _id = "http://determined.ai/schemas/expconf/v0/searcher-adaptive-bracket.json"
+    rung_lengths: List[int]
+    max_concurrent_trials: Optional[int] = None
+    max_trials: Optional[int] = None
+
+    @schemas.auto_init
+    def __init__(
+        self,
+        rung_lengths: List[int],
+        max_concurrent_trials: Optional[int] = None,
+        max_trials: Optional[int] = None,
     ) -> None:
         pass
 
@@ -632,6 +758,7 @@ class AdaptiveASHAConfigV0(schemas.SchemaBase):
     max_trials: int
     metric: str
     bracket_rungs: Optional[List[int]] = None
+    brackets: Optional[List[AdaptiveBracketV0]] = None
     divisor: Optional[float] = None
     max_concurrent_trials: Optional[int] = None
     max_rungs: Optional[int] = None
@@ -640,6 +767,8 @@ class AdaptiveASHAConfigV0(schemas.SchemaBase):
     source_checkpoint_uuid: Optional[str] = None
     source_trial_id: Optional[int] = None
     stop_once: Optional[int] = None
+    warm_start_experiment_id: Optional[int] = None
+    warm_start_trials: Optional[int] = None
 
     @schemas.auto_init
     def __init__(
@@ -648,6 +777,7 @@ class AdaptiveASHAConfigV0(schemas.SchemaBase):
         max_trials: int,
         metric: str,
         bracket_rungs: Optional[List[int]] = None,
+        brackets: Optional[List[AdaptiveBracketV0]] = None,
         divisor: Optional[float] = None,
         max_concurrent_trials: Optional[int] = None,
         max_rungs: Optional[int] = None,
@@ -656,6 +786,8 @@ class AdaptiveASHAConfigV0(schemas.SchemaBase):
         source_checkpoint_uuid: Optional[str] = None,
         source_trial_id: Optional[int] = None,
         stop_once: Optional[int] = None,
+        warm_start_experiment_id: Optional[int] = None,
+        warm_start_trials: Optional[int] = None,
     ) -> None:
         pass
 
@@ -673,6 +805,8 @@ class SyncHalvingConfigV0(schemas.SchemaBase):
     source_checkpoint_uuid: Optional[str] = None
     source_trial_id: Optional[int] = None
     train_stragglers: Optional[bool] = None
+    warm_start_experiment_id: Optional[int] = None
+    warm_start_trials: Optional[int] = None
 
     @schemas.auto_init
     def __init__(
@@ -686,6 +820,8 @@ class SyncHalvingConfigV0(schemas.SchemaBase):
         source_checkpoint_uuid: Optional[str] = None,
         source_trial_id: Optional[int] = None,
         train_stragglers: Optional[bool] = None,
+        warm_start_experiment_id: Optional[int] = None,
+        warm_start_trials: Optional[int] = None,
     ) -> None:
         pass
 
@@ -705,6 +841,8 @@ class AdaptiveConfigV0(schemas.SchemaBase):
     source_checkpoint_uuid: Optional[str] = None
     source_trial_id: Optional[int] = None
     train_stragglers: Optional[bool] = None
+    warm_start_experiment_id: Optional[int] = None
+    warm_start_trials: Optional[int] = None
 
     @schemas.auto_init
     def __init__(
@@ -720,6 +858,8 @@ class AdaptiveConfigV0(schemas.SchemaBase):
         source_checkpoint_uuid: Optional[str] = None,
         source_trial_id: Optional[int] = None,
         train_stragglers: Optional[bool] = None,
+        warm_start_experiment_id: Optional[int] = None,
+        warm_start_trials: Optional[int] = None,
     ) -> None:
         pass
 
@@ -737,6 +877,8 @@ class AdaptiveSimpleConfigV0(schemas.SchemaBase):
     smaller_is_better: Optional[bool] = None
     source_checkpoint_uuid: Optional[str] = None
     source_trial_id: Optional[int] = None
+    warm_start_experiment_id: Optional[int] = None
+    warm_start_trials: Optional[int] = None
 
     @schemas.auto_init
     def __init__(
@@ -750,6 +892,8 @@ class AdaptiveSimpleConfigV0(schemas.SchemaBase):
         smaller_is_better: Optional[bool] = None,
         source_checkpoint_uuid: Optional[str] = None,
         source_trial_id: Optional[int] = None,
+        warm_start_experiment_id: Optional[int] = None,
+        warm_start_trials: Optional[int] = None,
     ) -> None:
         pass
 
@@ -923,6 +1067,8 @@ class ExperimentConfigV0(schemas.SchemaBase):
 
     # Fields which can be omitted or defined at the cluster level.
     hyperparameters: Optional[Dict[str, HyperparameterV0_Type]] = None
+    alerts: Optional[List[AlertV0]] = None
+    best_checkpoint: Optional[BestCheckpointConfigV0] = None
     bind_mounts: Optional[List[BindMountV0]] = None
     checkpoint_policy: Optional[str] = None
     checkpoint_storage: Optional[CheckpointStorageConfigV0_Type] = None
@@ -930,6 +1076,7 @@ class ExperimentConfigV0(schemas.SchemaBase):
     data: Optional[Dict[str, Any]] = None
     debug: Optional[bool] = None
     description: Optional[str] = None
+    early_stopping: Optional[EarlyStoppingConfigV0] = None
     entrypoint: Optional[Union[str, List[str]]] = None
     environment: Optional[EnvironmentConfigV0] = None
     labels: Optional[str] = None
@@ -956,6 +1103,8 @@ class ExperimentConfigV0(schemas.SchemaBase):
         self,
         searcher: SearcherConfigV0,
         hyperparameters: Optional[Dict[str, HyperparameterV0_Type]] = None,
+        alerts: Optional[List[AlertV0]] = None,
+        best_checkpoint: Optional[BestCheckpointConfigV0] = None,
         bind_mounts: Optional[List[BindMountV0]] = None,
         checkpoint_policy: Optional[str] = None,
         checkpoint_storage: Optional[CheckpointStorageConfigV0_Type] = None,
@@ -963,6 +1112,7 @@ class ExperimentConfigV0(schemas.SchemaBase):
         data: Optional[Dict[str, Any]] = None,
         debug: Optional[bool] = None,
         description: Optional[str] = None,
+        early_stopping: Optional[EarlyStoppingConfigV0] = None,
         entrypoint: Optional[Union[str, List[str]]] = None,
         environment: Optional[EnvironmentConfigV0] = None,
         labels: Optional[str] = None,
@@ -1,7 +1,8 @@
+import datetime
 import logging
 import os
 import tempfile
-from typing import Optional, Union
+from typing import Dict, Optional, Union
 
 from determined import errors
 from determined.common import storage, util
@@ -54,13 +55,21 @@ class AzureStorageManager(storage.CloudStorageManager):
             self.client.put(blob_dir, blob_base, abs_path)
 
     @util.preserve_random_state
-    def download(self, src: str, dst: Union[str, os.PathLike]) -> None:
+    def download(
+        self,
+        src: str,
+        dst: Union[str, os.PathLike],
+        selector: Optional[storage.Selector] = None,
+    ) -> None:
         dst = os.fspath(dst)
         logging.info(f"Downloading {src} from Azure Blob Storage")
         found = False
         for blob in self.client.list_files(self.container, file_prefix=src):
             found = True
-            _dst = os.path.join(dst, os.path.relpath(blob, src))
+            relpath = os.path.relpath(blob, src)
+            if selector is not None and not selector(relpath):
+                continue
+            _dst = os.path.join(dst, relpath)
             dst_dir = os.path.dirname(_dst)
             os.makedirs(dst_dir, exist_ok=True)
 
@@ -77,6 +86,16 @@ class AzureStorageManager(storage.CloudStorageManager):
         if not found:
             raise errors.CheckpointNotFound(f"Did not find checkpoint {src} in Azure Blob Storage")
 
+    @util.preserve_random_state
+    def _list_files(self, src: str) -> Dict[str, datetime.datetime]:
+        prefix = src.rstrip("/") + "/"
+        files = self.client.list_file_times(self.container, file_prefix=prefix)
+        return {
+            os.path.relpath(blob, prefix): mtime
+            for blob, mtime in files.items()
+            if not blob.endswith("/")
+        }
+
     @util.preserve_random_state
     def delete(self, tgt: str) -> None:
         storage_prefix = tgt
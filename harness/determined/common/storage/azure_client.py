@@ -1,6 +1,7 @@
+import datetime
 import logging
 from pathlib import Path
-from typing import List, Optional, Union
+from typing import Dict, List, Optional, Union
 
 from determined.common import util
 
@@ -77,3 +78,14 @@ class AzureStorageClient(object):
         container = self.client.get_container_client(container_name)
         files = [blob["name"] for blob in container.list_blobs(name_starts_with=file_prefix)]
         return files
+
+    @util.preserve_random_state
+    def list_file_times(
+        self, container_name: str, file_prefix: Optional[Union[str, Path]] = None
+    ) -> Dict[str, datetime.datetime]:
+        """Like list_files(), but maps each file to the time it was last modified."""
+        container = self.client.get_container_client(container_name)
+        return {
+            blob["name"]: blob["last_modified"]
+            for blob in container.list_blobs(name_starts_with=file_prefix)
+        }
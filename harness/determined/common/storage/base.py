@@ -1,8 +1,13 @@
 import abc
 import contextlib
+import datetime
 import os
 import pathlib
-from typing import Any, Dict, Iterator, Optional, Union
+from typing import Any, Callable, Dict, Iterator, Optional, Union
+
+# A Selector is passed the path of a file relative to the root of a download and returns whether
+# to download it.
+Selector = Callable[[str], bool]
 
 
 class StorageManager(metaclass=abc.ABCMeta):
@@ -87,7 +92,26 @@ class StorageManager(metaclass=abc.ABCMeta):
         pass
 
     @abc.abstractmethod
-    def download(self, src: str, dst: Union[str, os.PathLike]) -> None:
+    def download(
+        self,
+        src: str,
+        dst: Union[str, os.PathLike],
+        selector: Optional[Selector] = None,
+    ) -> None:
+        """
+        Download the files under ``src`` in storage into ``dst``.  If ``selector`` is set, only the
+        files it selects are downloaded.
+        """
+        pass
+
+    @abc.abstractmethod
+    def _list_files(self, src: str) -> Dict[str, datetime.datetime]:
+        """
+        Returns a dict mapping the paths of all files under ``src`` in storage, relative to
+        ``src``, to the times they were last modified.  Directories are not included, and a
+        missing ``src`` has no files.  This lets callers such as the TensorBoard fetcher download
+        only the files that changed since they last looked.
+        """
         pass
 
     @abc.abstractmethod
@@ -1,7 +1,8 @@
+import datetime
 import logging
 import os
 import tempfile
-from typing import Optional, Union, no_type_check
+from typing import Dict, Optional, Union, no_type_check
 
 import requests.exceptions
 import urllib3.exceptions
@@ -79,7 +80,12 @@ class GCSStorageManager(storage.CloudStorageManager):
                 retry_network_errors(blob.upload_from_filename)(abs_path)
 
     @util.preserve_random_state
-    def download(self, src: str, dst: Union[str, os.PathLike]) -> None:
+    def download(
+        self,
+        src: str,
+        dst: Union[str, os.PathLike],
+        selector: Optional[storage.Selector] = None,
+    ) -> None:
         dst = os.fspath(dst)
         path = self.get_storage_prefix(src)
         logging.info(f"Downloading {path} from GCS")
@@ -89,7 +95,10 @@ class GCSStorageManager(storage.CloudStorageManager):
         # directory-like blob.
         for blob in self.bucket.list_blobs(prefix=path):
             found = True
-            _dst = os.path.join(dst, os.path.relpath(blob.name, path))
+            relpath = os.path.relpath(blob.name, path)
+            if selector is not None and not selector(relpath):
+                continue
+            _dst = os.path.join(dst, relpath)
             dst_dir = os.path.dirname(_dst)
             if not os.path.exists(dst_dir):
                 os.makedirs(dst_dir, exist_ok=True)
@@ -107,6 +116,15 @@ class GCSStorageManager(storage.CloudStorageManager):
         if not found:
             raise errors.CheckpointNotFound(f"Did not find checkpoint {path} in GCS")
 
+    @util.preserve_random_state
+    def _list_files(self, src: str) -> Dict[str, datetime.datetime]:
+        prefix = self.get_storage_prefix(src).rstrip("/") + "/"
+        return {
+            os.path.relpath(blob.name, prefix): blob.updated
+            for blob in self.bucket.list_blobs(prefix=prefix)
+            if not blob.name.endswith("/")
+        }
+
     @util.preserve_random_state
     def delete(self, storage_id: str) -> None:
         prefix = self.get_storage_prefix(storage_id)
@@ -1,7 +1,9 @@
+import datetime
 import logging
 import os
+import posixpath
 import tempfile
-from typing import Optional, Union
+from typing import Dict, Optional, Union
 
 from hdfs.client import InsecureClient
 
@@ -35,10 +37,38 @@ class HDFSStorageManager(storage.CloudStorageManager):
         self.client.upload(dst, src)
 
     @util.preserve_random_state
-    def download(self, src: str, dst: Union[str, os.PathLike]) -> None:
+    def download(
+        self,
+        src: str,
+        dst: Union[str, os.PathLike],
+        selector: Optional[storage.Selector] = None,
+    ) -> None:
         dst = os.fspath(dst)
         logging.info(f"Downloading {src} from HDFS")
-        self.client.download(src, dst, overwrite=True)
+        if selector is None:
+            self.client.download(src, dst, overwrite=True)
+            return
+
+        for relpath in self._list_files(src):
+            if not selector(relpath):
+                continue
+            _dst = os.path.join(dst, relpath)
+            os.makedirs(os.path.dirname(_dst), exist_ok=True)
+            self.client.download(posixpath.join(src, relpath), _dst, overwrite=True)
+
+    @util.preserve_random_state
+    def _list_files(self, src: str) -> Dict[str, datetime.datetime]:
+        if self.client.status(src, strict=False) is None:
+            return {}
+        root = self.client.resolve(src)
+        files = {}
+        for path, _, filenames in self.client.walk(src, status=True):
+            for filename, status in filenames:
+                relpath = posixpath.relpath(posixpath.join(path[0], filename), root)
+                # HDFS reports modification times in milliseconds since the epoch.
+                mtime = datetime.datetime.fromtimestamp(status["modificationTime"] / 1000)
+                files[relpath] = mtime
+        return files
 
     @util.preserve_random_state
     def delete(self, tgt: str) -> None:
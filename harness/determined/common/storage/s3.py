@@ -1,8 +1,9 @@
+import datetime
 import logging
 import os
 import re
 import tempfile
-from typing import Optional, Union
+from typing import Dict, Optional, Union
 
 import requests
 
@@ -96,7 +97,12 @@ class S3StorageManager(storage.CloudStorageManager):
                 self.bucket.upload_file(abs_path, key_name)
 
     @util.preserve_random_state
-    def download(self, src: str, dst: Union[str, os.PathLike]) -> None:
+    def download(
+        self,
+        src: str,
+        dst: Union[str, os.PathLike],
+        selector: Optional[storage.Selector] = None,
+    ) -> None:
         import botocore
 
         dst = os.fspath(dst)
@@ -107,7 +113,10 @@ class S3StorageManager(storage.CloudStorageManager):
         try:
             for obj in self.bucket.objects.filter(Prefix=prefix):
                 found = True
-                _dst = os.path.join(dst, os.path.relpath(obj.key, prefix))
+                relpath = os.path.relpath(obj.key, prefix)
+                if selector is not None and not selector(relpath):
+                    continue
+                _dst = os.path.join(dst, relpath)
                 dst_dir = os.path.dirname(_dst)
                 os.makedirs(dst_dir, exist_ok=True)
 
@@ -134,6 +143,15 @@ class S3StorageManager(storage.CloudStorageManager):
         if not found:
             raise errors.CheckpointNotFound(f"Did not find {prefix} in S3")
 
+    @util.preserve_random_state
+    def _list_files(self, src: str) -> Dict[str, datetime.datetime]:
+        prefix = self.get_storage_prefix(src).rstrip("/") + "/"
+        return {
+            os.path.relpath(obj.key, prefix): obj.last_modified
+            for obj in self.bucket.objects.filter(Prefix=prefix)
+            if not obj.key.endswith("/")
+        }
+
     @util.preserve_random_state
     def delete(self, tgt: str) -> None:
         prefix = self.get_storage_prefix(tgt)
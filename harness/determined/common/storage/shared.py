@@ -1,4 +1,5 @@
 import contextlib
+import datetime
 import os
 import pathlib
 import shutil
@@ -6,7 +7,7 @@ from typing import Any, Dict, Iterator, Optional, Union
 
 from determined import errors
 from determined.common import check
-from determined.common.storage.base import StorageManager
+from determined.common.storage.base import Selector, StorageManager
 
 
 def _full_storage_path(
@@ -89,11 +90,39 @@ class SharedFSStorageManager(StorageManager):
         src = os.fspath(src)
         shutil.copytree(src, os.path.join(self._base_path, dst))
 
-    def download(self, src: str, dst: Union[str, os.PathLike]) -> None:
+    def download(
+        self,
+        src: str,
+        dst: Union[str, os.PathLike],
+        selector: Optional[Selector] = None,
+    ) -> None:
         dst = os.fspath(dst)
-        try:
-            shutil.copytree(os.path.join(self._base_path, src), dst)
-        except FileNotFoundError:
-            raise errors.CheckpointNotFound(
-                f"Did not find checkpoint {src} in shared_fs storage"
-            ) from None
+        storage_dir = os.path.join(self._base_path, src)
+        if selector is None:
+            try:
+                shutil.copytree(storage_dir, dst)
+            except FileNotFoundError:
+                raise errors.CheckpointNotFound(
+                    f"Did not find checkpoint {src} in shared_fs storage"
+                ) from None
+            return
+
+        if not os.path.isdir(storage_dir):
+            raise errors.CheckpointNotFound(f"Did not find checkpoint {src} in shared_fs storage")
+        # Unlike copytree(), copying a selection of files must work into an existing directory.
+        for relpath in self._list_files(src):
+            if not selector(relpath):
+                continue
+            _dst = os.path.join(dst, relpath)
+            os.makedirs(os.path.dirname(_dst), exist_ok=True)
+            shutil.copy2(os.path.join(storage_dir, relpath), _dst)
+
+    def _list_files(self, src: str) -> Dict[str, datetime.datetime]:
+        storage_dir = os.path.join(self._base_path, src)
+        files = {}
+        for root, _, filenames in os.walk(storage_dir):
+            for filename in filenames:
+                path = os.path.join(root, filename)
+                mtime = datetime.datetime.fromtimestamp(os.path.getmtime(path))
+                files[os.path.relpath(path, storage_dir)] = mtime
+        return files
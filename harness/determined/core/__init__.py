@@ -1,5 +1,6 @@
 from determined.core._tensorboard_mode import TensorboardMode
 from determined.core._distributed import DistributedContext, DummyDistributedContext
+from determined.core._artifacts import ArtifactContext, DummyArtifactContext
 from determined.core._checkpoint import (
     CheckpointContext,
     DownloadMode,
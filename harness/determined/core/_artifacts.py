@@ -0,0 +1,123 @@
+import logging
+import os
+import pathlib
+import shutil
+import tempfile
+import uuid
+from datetime import datetime, timezone
+from typing import Any, Dict, Optional, Union
+
+from determined import core
+from determined.common import api, storage
+
+logger = logging.getLogger("determined.core")
+
+
+class ArtifactContext:
+    """
+    ``ArtifactContext`` uploads output files of a task that are not checkpoints, such as plots,
+    reports, or exported models, to checkpoint storage and registers them with the master.
+
+    Artifacts are listed with ``det artifact list`` and downloaded with ``det artifact download``.
+    Unlike checkpoints, they are never garbage collected.
+    """
+
+    def __init__(
+        self,
+        dist: core.DistributedContext,
+        storage_manager: storage.StorageManager,
+        session: api.Session,
+        task_id: str,
+        allocation_id: str,
+    ) -> None:
+        self._dist = dist
+        self._storage_manager = storage_manager
+        self._session = session
+        self._task_id = task_id
+        self._allocation_id = allocation_id
+
+    def upload(
+        self,
+        path: Union[str, os.PathLike],
+        name: Optional[str] = None,
+        artifact_type: str = "",
+        metadata: Optional[Dict[str, Any]] = None,
+    ) -> str:
+        """
+        ``upload()`` uploads the file at ``path`` to checkpoint storage as a new artifact and
+        registers it with the master.
+
+        Note that with multiple workers, only the chief worker (``distributed.rank==0``) is allowed
+        to call ``upload()``.
+
+        Arguments:
+            path: The file to upload.
+            name (optional): The file name of the artifact.  Defaults to the name of ``path``.
+            artifact_type (optional): A free-form kind of the artifact, such as ``"plot"`` or
+                ``"onnx"``.
+            metadata (optional): User-defined metadata to store with the artifact.
+
+        Returns:  The UUID of the artifact.
+        """
+
+        if self._dist.rank != 0:
+            raise RuntimeError(
+                "cannot call ArtifactContext.upload() from non-chief worker "
+                f"(rank={self._dist.rank})"
+            )
+
+        path = pathlib.Path(path)
+        if not path.is_file():
+            raise ValueError(f"artifacts must be files, but {path} is not a file")
+        name = name or path.name
+
+        artifact_uuid = str(uuid.uuid4())
+        # Storage managers upload directories, so stage the file in one under its artifact name.
+        with tempfile.TemporaryDirectory() as tmp:
+            shutil.copy(path, os.path.join(tmp, name))
+            resources = self._storage_manager._list_directory(tmp)
+            self._storage_manager.upload(src=tmp, dst=f"artifacts/{artifact_uuid}")
+        self._report_artifact(artifact_uuid, name, artifact_type, resources, metadata)
+        return artifact_uuid
+
+    def _report_artifact(
+        self,
+        artifact_uuid: str,
+        name: str,
+        artifact_type: str,
+        resources: Dict[str, int],
+        metadata: Optional[Dict[str, Any]],
+    ) -> None:
+        body = {
+            "uuid": artifact_uuid,
+            "taskId": self._task_id,
+            "allocationId": self._allocation_id,
+            "name": name,
+            "type": artifact_type,
+            "reportTime": datetime.now(timezone.utc).isoformat(),
+            "resources": {k: str(v) for k, v in resources.items()},
+            "metadata": metadata or {},
+        }
+        self._session.post("/api/v1/artifacts", json=body)
+        logger.info(f"Reported artifact {name} to master as {artifact_uuid}")
+
+
+class DummyArtifactContext(ArtifactContext):
+    def __init__(
+        self,
+        dist: core.DistributedContext,
+        storage_manager: storage.StorageManager,
+    ) -> None:
+        self._dist = dist
+        self._storage_manager = storage_manager
+
+    def _report_artifact(
+        self,
+        artifact_uuid: str,
+        name: str,
+        artifact_type: str,
+        resources: Dict[str, int],
+        metadata: Optional[Dict[str, Any]],
+    ) -> None:
+        # No master to report to; just log the event.
+        logger.info(f"saved artifact {name} as {artifact_uuid}")
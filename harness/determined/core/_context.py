@@ -1,4 +1,6 @@
+import json
 import logging
+import os
 import signal
 import sys
 import traceback
@@ -19,6 +21,7 @@ class Context:
     ``core.Context`` is a simple composition of several component APIs, with the following public
     members:
 
+    -  ``.artifacts``, an :class:`~ArtifactContext`
     -  ``.checkpoint``, a :class:`~CheckpointContext`
     -  ``.distributed``, a :class:`~DistributedContext`
     -  ``.preempt``, a :class:`~PreemptContext`
@@ -39,12 +42,16 @@ class Context:
         preempt: Optional[core.PreemptContext] = None,
         train: Optional[core.TrainContext] = None,
         searcher: Optional[core.SearcherContext] = None,
+        artifacts: Optional[core.ArtifactContext] = None,
     ) -> None:
         self.checkpoint = checkpoint
         self.distributed = distributed or core.DummyDistributedContext()
         self.preempt = preempt or core.DummyPreemptContext(self.distributed)
         self.train = train or core.DummyTrainContext()
         self.searcher = searcher or core.DummySearcherContext(self.distributed)
+        self.artifacts = artifacts or core.DummyArtifactContext(
+            self.distributed, checkpoint._storage_manager
+        )
 
     def __enter__(self) -> "Context":
         self.preempt.start()
@@ -97,6 +104,7 @@ def _dummy_init(
         logger.info(f"no storage_manager provided; storing checkpoints in {base_path}")
         storage_manager = storage.SharedFSStorageManager(base_path)
     checkpoint = core.DummyCheckpointContext(distributed, storage_manager)
+    artifacts = core.DummyArtifactContext(distributed, storage_manager)
 
     train = core.DummyTrainContext()
     searcher = core.DummySearcherContext(distributed)
@@ -109,6 +117,7 @@ def _dummy_init(
         preempt=preempt,
         train=train,
         searcher=searcher,
+        artifacts=artifacts,
     )
 
 
@@ -218,6 +227,9 @@ def init(
             tensorboard_mode,
             tensorboard_manager,
         )
+        artifacts = core.ArtifactContext(
+            distributed, storage_manager, session, info.task_id, info.allocation_id
+        )
 
         preempt = core.PreemptContext(session, info.allocation_id, distributed, preempt_mode)
 
@@ -230,6 +242,22 @@ def init(
         checkpoint = core.DummyCheckpointContext(distributed, storage_manager)
         preempt = core.DummyPreemptContext(distributed, preempt_mode)
 
+        # Commands are told where to upload their artifacts by the master.
+        artifact_storage = os.environ.get("DET_ARTIFACT_STORAGE")
+        if artifact_storage:
+            artifacts = core.ArtifactContext(
+                distributed,
+                storage.build(
+                    json.loads(artifact_storage),
+                    container_path=constants.SHARED_FS_CONTAINER_PATH,
+                ),
+                session,
+                info.task_id,
+                info.allocation_id,
+            )
+        else:
+            artifacts = core.DummyArtifactContext(distributed, storage_manager)
+
     _install_stacktrace_on_sigusr1()
 
     return Context(
@@ -238,4 +266,5 @@ def init(
         preempt=preempt,
         train=train,
         searcher=searcher,
+        artifacts=artifacts,
     )
@@ -167,6 +167,8 @@ class PreemptContext:
         if self._dist.get_rank() == 0 or self._preempt_mode == PreemptMode.WorkersAskMaster:
             self._watcher = _PreemptionWatcher(session, allocation_id)
         self._ack_sent = False
+        self._resize_checked = False
+        self._resize_slots = None  # type: Optional[int]
 
     def start(self) -> "PreemptContext":
         if self._started:
@@ -224,6 +226,12 @@ class PreemptContext:
                 # Tell the master that user code has received the preemption signal.
                 self.acknowledge_preemption_signal()
                 self._ack_sent = True
+            if out and not self._resize_checked:
+                # A resize of an elastic trial is delivered as a preemption; check for one once.
+                self._resize_slots = self._get_resize()
+                self._resize_checked = True
+                if self._resize_slots is not None:
+                    logger.info(f"master requested a resize to {self._resize_slots} slots")
             if self._preempt_mode == PreemptMode.WorkersAskChief:
                 _ = self._dist.broadcast(out)
         else:
@@ -240,6 +248,27 @@ class PreemptContext:
         logger.debug(f"should_preempt() -> {out}")
         return out
 
+    def get_resize(self) -> Optional[int]:
+        """
+        ``get_resize()`` returns the number of slots the Determined master has asked this trial to
+        be resized to, or ``None`` if no resize is pending.
+
+        Elastic trials are resized by preempting them and restarting them at the new size, so a
+        resize is only known after ``should_preempt()`` has returned ``True``, and only on workers
+        which communicate with the master: the chief, or every worker in ``WorkersAskMaster`` mode.
+        """
+        return self._resize_slots
+
+    def _get_resize(self) -> Optional[int]:
+        try:
+            slots = self._session.get(
+                f"/api/v1/allocations/{self._allocation_id}/signals/resize"
+            ).json().get("slots")
+        except Exception:
+            logger.warning("failure checking for a pending resize (continuing):", exc_info=True)
+            return None
+        return None if slots is None else int(slots)
+
     def acknowledge_preemption_signal(self) -> None:
         """
         ``acknowledge_preemption_signal()`` tells the Determined master that you are shutting down,
@@ -308,5 +337,8 @@ class DummyPreemptContext(PreemptContext):
                 _ = self._dist.broadcast(None)
         return False
 
+    def get_resize(self) -> Optional[int]:
+        return None
+
     def acknowledge_preemption_signal(self) -> None:
         pass
@@ -187,6 +187,41 @@ class TrainContext:
                 self._tbd_writer.on_validation_step_end(steps_completed, metrics)
             self._tensorboard_manager.sync()
 
+    def report_metrics(
+        self,
+        group: str,
+        steps_completed: int,
+        metrics: Dict[str, Any],
+    ) -> None:
+        """
+        Report metrics to the master in a named group, such as ``"inference"`` or
+        ``"custom_eval"``, other than training and validation.
+
+        Metrics in a group can be plotted and queried like training and validation metrics, but are
+        not used by the searcher.
+        """
+
+        serializable_metrics = self._get_serializable_metrics(metrics)
+        reportable_metrics = {k: metrics[k] for k in serializable_metrics}
+
+        body = {
+            "group": group,
+            "metrics": {
+                "trial_run_id": self._run_id,
+                "steps_completed": steps_completed,
+                "metrics": {
+                    "avg_metrics": reportable_metrics,
+                },
+            },
+        }
+        logger.info(
+            f"report_metrics(group={group}, steps_completed={steps_completed}, metrics={metrics})"
+        )
+        self._session.post(
+            f"/api/v1/trials/{self._trial_id}/metrics",
+            data=det.util.json_encode(body),
+        )
+
     def report_early_exit(self, reason: EarlyExitReason) -> None:
         """
         Report an early exit reason to the Determined master.
@@ -256,6 +291,13 @@ class DummyTrainContext(TrainContext):
             f"report_validation_metrics(steps_completed={steps_completed} metrics={metrics})"
         )
 
+    def report_metrics(self, group: str, steps_completed: int, metrics: Dict[str, Any]) -> None:
+        serializable_metrics = self._get_serializable_metrics(metrics)
+        metrics = {k: metrics[k] for k in serializable_metrics}
+        logger.info(
+            f"report_metrics(group={group}, steps_completed={steps_completed}, metrics={metrics})"
+        )
+
     def upload_tensorboard_files(
         self,
         selector: Callable[[pathlib.Path], bool] = lambda _: True,
@@ -173,6 +173,9 @@ class WorkloadSequencer(workload.Source):
 
     def check_for_preemption(self) -> None:
         if self.core_context.preempt.should_preempt():
+            slots = self.core_context.preempt.get_resize()
+            if slots is not None:
+                logging.info(f"checkpointing before restarting with {slots} slots")
             raise ShouldExit()
 
     def train(self, num_batches: int, op: core.SearcherOperation) -> WorkloadGenerator:
@@ -98,7 +98,9 @@ def build(
         )
 
     elif type_name == "azure":
-        if not checkpoint_config.get("connection_string") and checkpoint_config.get("access_url"):
+        if not checkpoint_config.get("connection_string") and not checkpoint_config.get(
+            "account_url"
+        ):
             raise ValueError(
                 """At least one of [connection_string, account_url] must be specified for Azure
                  Tensorboard Manager, but none were."""
@@ -106,7 +108,7 @@ def build(
         return azure.AzureTensorboardManager(
             checkpoint_config["container"],
             checkpoint_config.get("connection_string", None),
-            checkpoint_config.get("access_url", None),
+            checkpoint_config.get("account_url", None),
             checkpoint_config.get("credential", None),
             base_path,
             sync_path,
@@ -1,24 +1,63 @@
-import abc
-from typing import Any, Dict, List
+import datetime
+import logging
+import os
+import posixpath
+from typing import Dict, List
 
+from determined.common import storage
 
-class Fetcher(metaclass=abc.ABCMeta):
-    """Abstract base class for Tensorflow fetchers.
+logger = logging.getLogger(__name__)
 
-    Responsible for syncing tensorflow files from a list of storage paths to a local directory.
-    """
 
-    storage_paths: List[str]
+class Fetcher:
+    """Syncs tensorboard files from a list of storage paths to a local directory.
+
+    Files are fetched through the same storage manager that checkpoints are stored with, so any
+    checkpoint_storage type works. Storage paths are relative to the root of the checkpoint storage
+    and each is synced to the same relative path under the local directory.
+    """
 
-    @abc.abstractmethod
-    def __init__(self, storage_config: Dict[str, Any], storage_paths: List[str], local_dir: str):
-        pass
+    def __init__(
+        self,
+        storage_manager: storage.StorageManager,
+        storage_paths: List[str],
+        local_dir: str,
+    ) -> None:
+        self.storage_manager = storage_manager
+        self.storage_paths = storage_paths
+        self.local_dir = local_dir
+        self._file_records = {}  # type: Dict[str, datetime.datetime]
 
-    @abc.abstractmethod
     def fetch_new(self) -> int:
         """Fetches changed files found in storage paths to local disk.
 
         Returns: count of new files fetched.
 
         """
-        pass
+        new_files = 0
+
+        for storage_path in self.storage_paths:
+            logger.debug(f"Looking at path: {storage_path}")
+
+            # Look at all files in our storage location.
+            changed = set()
+            for relpath, mtime in self.storage_manager._list_files(storage_path).items():
+                filepath = posixpath.join(storage_path, relpath)
+                prev_mtime = self._file_records.get(filepath)
+
+                if prev_mtime is not None and prev_mtime >= mtime:
+                    continue
+
+                changed.add(relpath)
+                self._file_records[filepath] = mtime
+
+            if not changed:
+                continue
+
+            # Download the new or updated files.
+            local_path = os.path.join(self.local_dir, storage_path.strip("/"))
+            self.storage_manager.download(storage_path, local_path, selector=changed.__contains__)
+            logger.debug(f"Downloaded {len(changed)} files to local: {local_path}")
+            new_files += len(changed)
+
+        return new_files
@@ -0,0 +1,52 @@
+import pathlib
+from unittest import mock
+
+import pytest
+
+from determined import core
+from tests import parallel
+from tests.core.test_checkpoint import make_mock_storage_manager
+
+
+def test_artifact_context(tmp_path: pathlib.Path) -> None:
+    plot = tmp_path.joinpath("loss.png")
+    plot.write_bytes(b"png")
+    with parallel.Execution(2) as pex:
+
+        @pex.run
+        def do_test() -> None:
+            storage_manager = make_mock_storage_manager(tmp_path)
+            session = mock.MagicMock()
+            artifacts = core.ArtifactContext(
+                pex.distributed,
+                storage_manager,
+                session=session,
+                task_id="task-id",
+                allocation_id="allocation-id",
+            )
+
+            with parallel.raises_when(
+                pex.distributed.rank == 1,
+                RuntimeError,
+                match="upload.*non-chief",
+            ):
+                artifact_uuid = artifacts.upload(plot, artifact_type="plot", metadata={"epoch": 3})
+            if pex.rank == 0:
+                storage_manager.upload.assert_called_once_with(
+                    src=mock.ANY, dst=f"artifacts/{artifact_uuid}"
+                )
+                session.post.assert_called_once()
+                body = session.post.call_args[1]["json"]
+                assert body["uuid"] == artifact_uuid
+                assert body["taskId"] == "task-id"
+                assert body["name"] == "loss.png"
+                assert body["type"] == "plot"
+                assert body["metadata"] == {"epoch": 3}
+
+
+def test_artifact_context_rejects_directories(tmp_path: pathlib.Path) -> None:
+    storage_manager = make_mock_storage_manager(tmp_path)
+    artifacts = core.DummyArtifactContext(core.DummyDistributedContext(), storage_manager)
+    with pytest.raises(ValueError, match="must be files"):
+        artifacts.upload(tmp_path)
+    storage_manager.upload.assert_not_called()
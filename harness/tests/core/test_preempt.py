@@ -1,6 +1,6 @@
 import threading
 import time
-from typing import Any, Dict, Tuple
+from typing import Any, Dict, Optional, Tuple
 from unittest import mock
 
 import pytest
@@ -20,6 +20,7 @@ class MockPreemptState:
         self.mock_session = mock.MagicMock()
         self.mock_session.get.side_effect = self.session_get
         self._state = False
+        self._resize_slots = None  # type: Optional[int]
         self._cond = threading.Condition()
 
     def preempt(self) -> None:
@@ -28,10 +29,25 @@ class MockPreemptState:
             self._state = True
             self._cond.notify()
 
-    def session_get(self, path: str, params: Dict[str, Any], timeout: float) -> mock.MagicMock:
-        """session_get() is called from a background thread."""
-        # We only mock one GET endpoint.
+    def resize(self, slots: int) -> None:
+        """resize() is called from the test code, and preempts like the master does."""
+        self._resize_slots = slots
+        self.preempt()
+
+    def session_get(
+        self, path: str, params: Optional[Dict[str, Any]] = None, timeout: Optional[float] = None
+    ) -> mock.MagicMock:
+        """session_get() is called from a background thread, or for resizes, the main thread."""
+        if path.endswith("signals/resize"):
+            response = mock.MagicMock()
+            response.json.return_value = (
+                {} if self._resize_slots is None else {"slots": self._resize_slots}
+            )
+            return response
+
+        # Otherwise we only mock the preemption GET endpoint.
         assert path.endswith("signals/preemption"), path
+        assert params is not None
         assert "timeout_seconds" in params, params
 
         timeout_seconds = float(params["timeout_seconds"])
@@ -225,3 +241,26 @@ def test_check_started(dummy: bool) -> None:
                 context.should_preempt()
             with context:
                 assert context.should_preempt() is False
+
+
+def test_preempt_resize() -> None:
+    with parallel.Execution(1) as pex:
+
+        @pex.run
+        def do_test() -> None:
+            state, context = make_test_preempt_context(
+                pex.distributed, core.PreemptMode.WorkersAskChief
+            )
+            with context:
+                assert context.should_preempt() is False
+                assert context.get_resize() is None
+                # A resize arrives as a preemption, and is known once it has been observed.
+                state.resize(4)
+                wait_on_watcher(context)
+                assert context.should_preempt() is True
+                assert context.get_resize() == 4
+
+            dummy = core.DummyPreemptContext(pex.distributed, core.PreemptMode.WorkersAskChief)
+            with dummy:
+                assert dummy.should_preempt() is False
+                assert dummy.get_resize() is None
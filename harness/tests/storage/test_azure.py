@@ -1,4 +1,3 @@
-import io
 import os
 import tempfile
 import uuid
@@ -8,7 +7,7 @@ from typing import Dict, List
 import pytest
 
 from determined.common import storage
-from determined.tensorboard.fetchers.azure import AzureFetcher
+from determined.tensorboard import fetchers
 from tests.storage import util
 
 CONTAINER_NAME = "storage-unit-tests"
@@ -72,56 +71,26 @@ def test_live_azure_lifecycle(require_secrets: bool, tmp_path: Path) -> None:
     util.run_storage_lifecycle_test(live_manager, post_delete_cb)
 
 
-def get_tensorboard_fetcher_azure(
-    require_secrets: bool, local_sync_dir: str, paths_to_sync: List[str]
-) -> AzureFetcher:
-
-    connection_string = os.environ.get("DET_AZURE_TEST_CREDS")
-    storage_config = {"connection_string": connection_string, "container": CONTAINER_NAME}
-
-    import azure.core.exceptions
-
-    try:
-        fetcher = AzureFetcher(storage_config, paths_to_sync, local_sync_dir)
-        data = io.BytesIO()
-
-        blob_client = fetcher.client.get_blob_client(CONTAINER_NAME, CHECK_ACCESS_KEY)
-        blob_client.download_blob().readinto(data)
-
-        data.seek(0)
-        assert data.read() == CHECK_KEY_CONTENT
-
-        return fetcher
-
-    except (
-        ValueError,
-        azure.core.exceptions.ClientAuthenticationError,
-        azure.core.exceptions.ResourceNotFoundError,
-    ):
-        if require_secrets:
-            raise
-        pytest.skip("No Azure access")
-
-
 @pytest.mark.cloud
 def test_tensorboard_fetcher_azure(require_secrets: bool, tmp_path: Path) -> None:
 
     local_sync_dir = os.path.join(tmp_path, "sync_dir")
-    storage_relpath = os.path.join(local_sync_dir, CONTAINER_NAME)
+    storage_relpath = local_sync_dir
 
     # Create two paths as multi-trial sync could happen.
     paths_to_sync = [os.path.join("test_dir", str(uuid.uuid4()), "subdir") for _ in range(2)]
 
-    fetcher = get_tensorboard_fetcher_azure(require_secrets, local_sync_dir, paths_to_sync)
+    manager = get_live_azure_manager(require_secrets, tmp_path)
+    fetcher = fetchers.Fetcher(manager, paths_to_sync, local_sync_dir)
 
     def put_files(filepath_content: Dict[str, bytes]) -> None:
         for filepath, content in filepath_content.items():
-            blob_client = fetcher.client.get_blob_client(CONTAINER_NAME, filepath)
+            blob_client = manager.client.client.get_blob_client(CONTAINER_NAME, filepath)
             blob_client.upload_blob(content, overwrite=True)
 
     def rm_files(filepaths: List[str]) -> None:
         for filepath in filepaths:
-            blob_client = fetcher.client.get_blob_client(CONTAINER_NAME, filepath)
+            blob_client = manager.client.client.get_blob_client(CONTAINER_NAME, filepath)
             blob_client.delete_blob()
 
     util.run_tensorboard_fetcher_test(local_sync_dir, fetcher, storage_relpath, put_files, rm_files)
@@ -8,7 +8,7 @@ import google.cloud.storage
 import pytest
 
 from determined.common import storage
-from determined.tensorboard.fetchers.gcs import GCSFetcher
+from determined.tensorboard import fetchers
 from tests.storage import util
 
 BUCKET_NAME = "storage-unit-tests"
@@ -95,46 +95,26 @@ def test_gcs_lifecycle(
     util.run_storage_lifecycle_test(live_gcs_manager, post_delete_cb)
 
 
-def get_tensorboard_fetcher_gcs(
-    require_secrets: bool, local_sync_dir: str, paths_to_sync: List[str]
-) -> GCSFetcher:
-
-    storage_config = {"bucket": BUCKET_NAME}
-
-    try:
-        fetcher = GCSFetcher(storage_config, paths_to_sync, local_sync_dir)
-
-        blob = fetcher.client.bucket(BUCKET_NAME).blob("check-access")
-        assert blob.download_as_string() == CHECK_KEY_CONTENT
-
-        return fetcher
-
-    except google.auth.exceptions.DefaultCredentialsError:
-        # No access detected.
-        if require_secrets:
-            raise
-        pytest.skip("No GCS access")
-
-
 @pytest.mark.cloud
 def test_tensorboard_fetcher_gcs(
     require_secrets: bool, tmp_path: Path, prep_gcs_test_creds: None
 ) -> None:
 
     local_sync_dir = os.path.join(tmp_path, "sync_dir")
-    storage_relpath = os.path.join(local_sync_dir, BUCKET_NAME)
+    storage_relpath = local_sync_dir
 
     # Create two paths as multi-trial sync could happen.
     paths_to_sync = [os.path.join("test_dir", str(uuid.uuid4()), "subdir") for _ in range(2)]
 
-    fetcher = get_tensorboard_fetcher_gcs(require_secrets, local_sync_dir, paths_to_sync)
+    manager = get_live_gcs_manager(tmp_path, None, require_secrets)
+    fetcher = fetchers.Fetcher(manager, paths_to_sync, local_sync_dir)
 
     def put_files(filepath_content: Dict[str, bytes]) -> None:
         for filepath, content in filepath_content.items():
-            fetcher.client.bucket(BUCKET_NAME).blob(filepath).upload_from_string(content)
+            manager.bucket.blob(filepath).upload_from_string(content)
 
     def rm_files(filepaths: List[str]) -> None:
         for filepath in filepaths:
-            fetcher.client.bucket(BUCKET_NAME).blob(filepath).delete()
+            manager.bucket.blob(filepath).delete()
 
     util.run_tensorboard_fetcher_test(local_sync_dir, fetcher, storage_relpath, put_files, rm_files)
@@ -9,7 +9,7 @@ import pytest
 
 from determined.common import storage
 from determined.common.storage.s3 import normalize_prefix
-from determined.tensorboard.fetchers.s3 import S3Fetcher
+from determined.tensorboard import fetchers
 from tests.storage import util
 
 BUCKET_NAME = "storage-unit-tests"
@@ -105,44 +105,23 @@ def test_live_s3_lifecycle(require_secrets: bool, tmp_path: Path, prefix: Option
     util.run_storage_lifecycle_test(live_manager, post_delete_cb)
 
 
-def get_tensorboard_fetcher_s3(
-    require_secrets: bool, local_sync_dir: str, paths_to_sync: List[str]
-) -> S3Fetcher:
-
-    storage_config = {"bucket": BUCKET_NAME}
-
-    try:
-        fetcher = S3Fetcher(storage_config, paths_to_sync, local_sync_dir)
-
-        out = io.BytesIO()
-        fetcher.client.download_fileobj(BUCKET_NAME, CHECK_ACCESS_KEY, out)
-        assert out.getvalue() == CHECK_KEY_CONTENT
-
-        return fetcher
-
-    except (botocore.exceptions.NoCredentialsError, botocore.exceptions.PartialCredentialsError):
-        # No access detected.
-        if require_secrets:
-            raise
-        pytest.skip("No S3 access")
-
-
 @pytest.mark.cloud
 def test_tensorboard_fetcher_s3(require_secrets: bool, tmp_path: Path) -> None:
     local_sync_dir = os.path.join(tmp_path, "sync_dir")
-    storage_relpath = os.path.join(local_sync_dir, BUCKET_NAME)
+    storage_relpath = local_sync_dir
 
     # Create two paths as multi-trial sync could happen.
     paths_to_sync = [os.path.join("test_dir", str(uuid.uuid4()), "subdir") for _ in range(2)]
 
-    fetcher = get_tensorboard_fetcher_s3(require_secrets, local_sync_dir, paths_to_sync)
+    manager = get_live_manager(require_secrets, tmp_path, None)
+    fetcher = fetchers.Fetcher(manager, paths_to_sync, local_sync_dir)
 
     def put_files(files: Dict[str, bytes]) -> None:
         for path, filebytes in files.items():
-            fetcher.client.put_object(Bucket=BUCKET_NAME, Key=path, Body=filebytes)
+            manager.bucket.put_object(Key=path, Body=filebytes)
 
     def rm_files(files: List[str]) -> None:
         for path in files:
-            fetcher.client.delete_object(Bucket=BUCKET_NAME, Key=path)
+            manager.bucket.Object(path).delete()
 
     util.run_tensorboard_fetcher_test(local_sync_dir, fetcher, storage_relpath, put_files, rm_files)
@@ -8,7 +8,7 @@ import pytest
 
 from determined.common import check, storage
 from determined.common.storage import shared
-from determined.tensorboard.fetchers.shared import SharedFSFetcher
+from determined.tensorboard import fetchers
 from tests.storage import util
 
 
@@ -74,6 +74,20 @@ def test_validate_read_only_dir(manager: storage.SharedFSStorageManager) -> None
             assert len(os.listdir(manager._base_path)) == 1
 
 
+def test_download_selector(manager: storage.SharedFSStorageManager, tmp_path: Path) -> None:
+    src = tmp_path.joinpath("src")
+    util.create_checkpoint(src)
+    manager.upload(src, "ckpt")
+
+    assert set(manager._list_files("ckpt")) == {"root.txt", os.path.join("subdir", "file.txt")}
+    assert manager._list_files("missing") == {}
+
+    dst = tmp_path.joinpath("dst")
+    dst.mkdir()
+    manager.download("ckpt", dst, selector=lambda path: path.startswith("subdir"))
+    assert set(manager._list_directory(dst)) == {"subdir/", "subdir/file.txt"}
+
+
 @pytest.mark.cloud
 def test_tensorboard_fetcher_shared(require_secrets: bool, tmp_path: Path) -> None:
 
@@ -82,11 +96,10 @@ def test_tensorboard_fetcher_shared(require_secrets: bool, tmp_path: Path) -> No
     storage_relpath = local_sync_dir
 
     # Create two paths as multi-trial sync could happen.
-    paths_to_sync = [
-        os.path.join(storage_dir, "test_dir", str(uuid.uuid4()), "subdir") for _ in range(2)
-    ]
+    paths_to_sync = [os.path.join("test_dir", str(uuid.uuid4()), "subdir") for _ in range(2)]
 
-    fetcher = SharedFSFetcher({}, paths_to_sync, local_sync_dir)
+    manager = storage.SharedFSStorageManager(storage_dir)
+    fetcher = fetchers.Fetcher(manager, paths_to_sync, local_sync_dir)
 
     def put_files(filepath_content: Dict[str, bytes]) -> None:
         for filepath, content in filepath_content.items():
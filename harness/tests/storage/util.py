@@ -9,7 +9,7 @@ import pytest
 
 from determined import errors
 from determined.common import storage
-from determined.tensorboard.fetchers import base
+from determined.tensorboard import fetchers
 
 EXPECTED_FILES = {
     "root.txt": "root file",
@@ -99,7 +99,7 @@ def run_storage_lifecycle_test(
 
 def run_tensorboard_fetcher_test(
     local_sync_dir: str,
-    fetcher: base.Fetcher,
+    fetcher: fetchers.Fetcher,
     storage_relpath: str,
     put_files: Callable,
     rm_files: Callable,
@@ -115,8 +115,7 @@ def run_tensorboard_fetcher_test(
         }
 
     def verify_files(expected_files: Dict[str, bytes]) -> None:
-        # SharedFS has absolute paths
-        expected_files = {k.lstrip("/"): v for k, v in expected_files.items()}
+        expected_files = dict(expected_files)
 
         full_paths = list_files(local_sync_dir)
         local_files = [os.path.relpath(fp, storage_relpath) for fp in full_paths]
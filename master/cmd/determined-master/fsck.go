@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/fsck"
+)
+
+func newFsckCmd() *cobra.Command {
+	var opts fsck.Options
+	cmd := &cobra.Command{
+		Use: "fsck",
+		Short: "check the db for inconsistent state, such as trials and tasks left open by a " +
+			"crash; run it only while the master is stopped",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runFsck(cmd.OutOrStdout(), opts); err != nil {
+				log.Error(fmt.Sprintf("%+v", err))
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&opts.Repair, "repair", false, "repair the inconsistencies that are found")
+	cmd.Flags().BoolVar(&opts.CheckStorage, "check-storage", false,
+		"look for the files of every completed checkpoint in checkpoint storage")
+	return cmd
+}
+
+func runFsck(out io.Writer, opts fsck.Options) error {
+	if err := initializeConfig(); err != nil {
+		return err
+	}
+
+	config := config.GetMasterConfig()
+	database, err := db.Connect(&config.DB)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if errd := database.Close(); errd != nil {
+			log.Errorf("error closing pg connection: %s", errd)
+		}
+	}()
+
+	problems, err := fsck.Run(context.Background(), opts)
+	if err != nil {
+		return errors.Wrap(err, "checking the database")
+	}
+	printFsckProblems(out, problems)
+	return nil
+}
+
+func printFsckProblems(out io.Writer, problems []fsck.Problem) {
+	if len(problems) == 0 {
+		fmt.Fprintln(out, "no inconsistencies found")
+		return
+	}
+	repaired := 0
+	for _, p := range problems {
+		fmt.Fprintf(out, "%s: %s %s\n", p.Kind, p.Object, p.Detail)
+		if p.Repaired {
+			repaired++
+			fmt.Fprintf(out, "  repaired: %s\n", p.Repair)
+		} else {
+			fmt.Fprintf(out, "  to repair: %s\n", p.Repair)
+		}
+	}
+	fmt.Fprintf(out, "%d inconsistencies found, %d repaired\n", len(problems), repaired)
+}
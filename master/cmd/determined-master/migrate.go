@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -14,7 +17,7 @@ import (
 )
 
 func newMigrateCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "migrate",
 		Short: "migrate the db",
 		Run: func(cmd *cobra.Command, args []string) {
@@ -24,6 +27,17 @@ func newMigrateCmd() *cobra.Command {
 			}
 		},
 	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "check",
+		Short: "report pending migrations and changes made to the schema by hand, changing nothing",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runMigrateCheck(cmd.OutOrStdout()); err != nil {
+				log.Error(fmt.Sprintf("%+v", err))
+				os.Exit(1)
+			}
+		},
+	})
+	return cmd
 }
 
 func runMigrate(cmd *cobra.Command, args []string) error {
@@ -52,3 +66,77 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func runMigrateCheck(out io.Writer) error {
+	if err := initializeConfig(); err != nil {
+		return err
+	}
+
+	config := config.GetMasterConfig()
+	database, err := db.Connect(&config.DB)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if errd := database.Close(); errd != nil {
+			log.Errorf("error closing pg connection: %s", errd)
+		}
+	}()
+
+	report, err := db.CheckMigrations(context.Background(), config.DB.Migrations)
+	if err != nil {
+		return errors.Wrap(err, "checking migrations")
+	}
+	printMigrationReport(out, report)
+	return nil
+}
+
+func printMigrationReport(out io.Writer, r *db.MigrationReport) {
+	fmt.Fprintf(out, "current version: %d\n", r.CurrentVersion)
+	if len(r.Pending) == 0 {
+		fmt.Fprintln(out, "no pending migrations")
+	}
+	for _, m := range r.Pending {
+		kind := "not in a transaction"
+		if m.Transactional {
+			kind = "in a transaction, holding its locks until it ends"
+		}
+		fmt.Fprintf(out, "pending: %d %s (%s)\n", m.Version, m.Name, kind)
+		for _, t := range m.Tables {
+			var blocks string
+			switch {
+			case t.BlocksReads:
+				blocks = ", blocks reads and writes"
+			case t.BlocksWrites:
+				blocks = ", blocks writes"
+			}
+			var warning string
+			if t.Large() && t.BlocksWrites {
+				warning = " [large table]"
+			}
+			fmt.Fprintf(out, "  %s: %s lock%s, ~%d rows, %d bytes%s\n",
+				t.Table, t.Lock, blocks, t.EstimatedRows, t.SizeBytes, warning)
+		}
+	}
+
+	if r.SnapshotTime.IsZero() {
+		fmt.Fprintln(out, "no schema snapshot to check for drift against")
+		return
+	}
+	fmt.Fprintf(out, "schema snapshot: version %d, taken %s\n",
+		r.SnapshotVersion, r.SnapshotTime.Format(time.RFC3339))
+	if len(r.Drift) == 0 {
+		fmt.Fprintln(out, "no schema drift")
+	}
+	for _, d := range r.Drift {
+		switch {
+		case d.Expected == "":
+			fmt.Fprintf(out, "drift: %s was added: %s\n", d.Object, d.Actual)
+		case d.Actual == "":
+			fmt.Fprintf(out, "drift: %s was removed, expected: %s\n", d.Object, d.Expected)
+		default:
+			fmt.Fprintf(out, "drift: %s was changed from: %s\n  to: %s\n",
+				d.Object, d.Expected, d.Actual)
+		}
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/ghodss/yaml"
 	"github.com/pkg/errors"
@@ -34,6 +37,7 @@ func newRootCmd() *cobra.Command {
 		},
 	}
 	cmd.AddCommand(newMigrateCmd())
+	cmd.AddCommand(newFsckCmd())
 	return cmd
 }
 
@@ -61,41 +65,76 @@ func runRoot() error {
 		return err
 	}
 
-	m := internal.New(logStore, config)
-	return m.Run(context.TODO())
+	// The first SIGTERM or SIGINT shuts the master down gracefully; stop catching them after it, so
+	// that a second one kills the master right away.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	m := internal.New(logStore, config, reloadConfig)
+	return m.Run(ctx)
 }
 
 // initializeConfig initializes master config with the validated configuration populated from config
 // file, environment variables, and command line flags) and also initializes
 // global logging state based on those options.
 func initializeConfig() error {
+	conf, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	config.SetMasterConfig(conf)
+	return nil
+}
+
+// loadConfig reads the configuration file, replacing any settings read from it before, and
+// returns the validated configuration with the environment variables and command line flags
+// applied.
+func loadConfig() (*config.Config, error) {
 	// Fetch an initial config to get the config file path and read its settings into Viper.
 	initialConfig, err := getConfig(v.AllSettings())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	bs, err := readConfigFile(initialConfig.ConfigFile)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	v.SetConfigType("json")
+	if err = v.ReadConfig(strings.NewReader("{}")); err != nil {
+		return nil, errors.Wrap(err, "error clearing previous configuration")
 	}
 	if err = mergeConfigBytesIntoViper(bs); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Now call viper.AllSettings() again to get the full config, containing all values from CLI flags,
 	// environment variables, and the configuration file.
 	conf, err := getConfig(v.AllSettings())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := check.Validate(conf); err != nil {
-		return err
+		return nil, err
 	}
+	return conf, nil
+}
 
-	config.SetMasterConfig(conf)
-	return nil
+// reloadConfig loads the configuration again for the running master. A webhook signing key is
+// generated whenever none is configured, so the one in use is kept in that case.
+func reloadConfig() (*config.Config, error) {
+	conf, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !v.IsSet("webhooks" + viperKeyDelimiter + "signing_key") {
+		conf.Webhooks.SigningKey = config.GetMasterConfig().Webhooks.SigningKey
+	}
+	return conf, nil
 }
 
 func readConfigFile(configPath string) ([]byte, error) {
@@ -73,6 +73,10 @@ webhooks:
 			Provider:                 providerConf,
 			MaxAuxContainersPerAgent: 100,
 			AgentReconnectWait:       model.Duration(aproto.AgentReconnectWait),
+			ContainerRuntime:         aproto.ContainerRuntimeDocker,
+
+			AgentHeartbeatInterval:      model.Duration(aproto.AgentHeartbeatInterval),
+			AgentHeartbeatMissTolerance: aproto.AgentHeartbeatMissTolerance,
 		},
 	}
 	expected.TaskContainerDefaults.CPUPodSpec = &k8sV1.Pod{
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Cursor marks the last record of a page of a list, so that the next page can start right after
+// it. Unlike an offset, it stays correct when records are added or removed between requests, and
+// lets the database seek to the page rather than count through every record before it. Lists
+// keyed by integer IDs set ID, lists of checkpoints set UUID, and logs set ID and Timestamp.
+type Cursor struct {
+	ID        int        `json:"id,omitempty"`
+	UUID      string     `json:"uuid,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// Encode returns the opaque form of the cursor that is handed to clients.
+func (c Cursor) Encode() string {
+	b, err := json.Marshal(c)
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses the cursor of a request, returning nil if there is none. Since a cursor
+// continues after a record rather than skipping a number of them, it cannot be combined with an
+// offset.
+func DecodeCursor(s string, offset int32) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if offset != 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset and cursor cannot both be set")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid cursor: %s", s)
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid cursor: %s", s)
+	}
+	return &c, nil
+}
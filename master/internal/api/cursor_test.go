@@ -0,0 +1,34 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCursor(t *testing.T) {
+	c, err := DecodeCursor("", 0)
+	require.NoError(t, err)
+	require.Nil(t, c)
+
+	ts := time.Date(2022, 12, 25, 10, 30, 0, 123456000, time.UTC)
+	for _, expected := range []Cursor{
+		{ID: 42},
+		{UUID: "7d3c6b0e-5f0a-4a39-9a1e-0b9f3f1d2c4e"},
+		{ID: 42, Timestamp: &ts},
+	} {
+		c, err := DecodeCursor(expected.Encode(), 0)
+		require.NoError(t, err)
+		require.Equal(t, expected, *c)
+	}
+
+	_, err = DecodeCursor(Cursor{ID: 42}.Encode(), 10)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	for _, invalid := range []string{"not a cursor!", "bm90IGpzb24"} {
+		_, err = DecodeCursor(invalid, 0)
+		require.Equal(t, codes.InvalidArgument, status.Code(err), invalid)
+	}
+}
@@ -12,6 +12,9 @@ const (
 	FilterOperationLessThanEqual
 	// FilterOperationStringContainment checks if the field contains a value as a substring.
 	FilterOperationStringContainment
+	// FilterOperationRegexMatch checks if the field contains a match of a case-insensitive
+	// regular expression.
+	FilterOperationRegexMatch
 )
 
 // Filter is a general representation for a filter provided to an API.
@@ -3,6 +3,9 @@ package internal
 import (
 	"context"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	"github.com/determined-ai/determined/master/internal/sproto"
 	"github.com/determined-ai/determined/master/pkg/actor"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
@@ -84,3 +87,36 @@ func (a *apiServer) DisableSlot(
 	}
 	return resp, a.ask(slotAddr(req.AgentId, req.SlotId), req, &resp)
 }
+
+func (a *apiServer) GetAgentImages(
+	ctx context.Context, req *apiv1.GetAgentImagesRequest,
+) (resp *apiv1.GetAgentImagesResponse, err error) {
+	if err := userShouldBeAdmin(ctx, a); err != nil {
+		return nil, err
+	}
+	return resp, a.ask(agentAddr(req.AgentId), req, &resp)
+}
+
+func (a *apiServer) EvictAgentImages(
+	ctx context.Context, req *apiv1.EvictAgentImagesRequest,
+) (resp *apiv1.EvictAgentImagesResponse, err error) {
+	if err := userShouldBeAdmin(ctx, a); err != nil {
+		return nil, err
+	}
+	if len(req.Images) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no images to evict")
+	}
+	return resp, a.ask(agentAddr(req.AgentId), req, &resp)
+}
+
+func (a *apiServer) SetAgentImageCacheLimit(
+	ctx context.Context, req *apiv1.SetAgentImageCacheLimitRequest,
+) (resp *apiv1.SetAgentImageCacheLimitResponse, err error) {
+	if err := userShouldBeAdmin(ctx, a); err != nil {
+		return nil, err
+	}
+	if req.LimitBytes < 0 {
+		return nil, status.Error(codes.InvalidArgument, "image cache limit must not be negative")
+	}
+	return resp, a.ask(agentAddr(req.AgentId), req, &resp)
+}
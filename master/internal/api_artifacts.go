@@ -0,0 +1,192 @@
+package internal
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	expauth "github.com/determined-ai/determined/master/internal/experiment"
+	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/artifactv1"
+)
+
+func errArtifactNotFound(id string) error {
+	return status.Errorf(codes.NotFound, "artifact not found: %s", id)
+}
+
+// artifactStorage returns the checkpoint storage that the artifacts of a task are uploaded to:
+// that of its experiment for trials, and that of the master for every other task.
+func (m *Master) artifactStorage(
+	ctx context.Context, t *model.Task,
+) (expconf.CheckpointStorageConfig, error) {
+	if t.TaskType != model.TaskTypeTrial {
+		return m.config.CheckpointStorage, nil
+	}
+	exp, err := db.ExperimentWithoutConfigByTaskID(ctx, t.TaskID)
+	if err != nil {
+		return expconf.CheckpointStorageConfig{}, err
+	}
+	config, err := m.db.LegacyExperimentConfigByID(exp.ID)
+	if err != nil {
+		return expconf.CheckpointStorageConfig{}, err
+	}
+	return config.CheckpointStorage(), nil
+}
+
+// canDoActionOnArtifact returns the artifact with the given UUID if the user may see the task
+// that reported it and do the action on it.
+func (m *Master) canDoActionOnArtifact(
+	ctx context.Context,
+	curUser model.User,
+	id string,
+	action func(context.Context, model.User, *model.Experiment) error,
+) (*model.Artifact, error) {
+	artifactUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid artifact uuid %s: %s", id, err)
+	}
+	artifact, err := db.ArtifactByUUID(ctx, artifactUUID)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, errArtifactNotFound(id)
+	} else if err != nil {
+		return nil, err
+	}
+	t, err := m.db.TaskByID(artifact.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.TaskType != model.TaskTypeTrial {
+		if ok, err := canAccessNTSCTask(ctx, curUser, t.TaskID); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, errArtifactNotFound(id)
+		}
+		return artifact, nil
+	}
+	exp, err := db.ExperimentWithoutConfigByTaskID(ctx, t.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := expauth.AuthZProvider.Get().CanGetExperiment(ctx, curUser, exp); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errArtifactNotFound(id)
+	}
+	if err := action(ctx, curUser, exp); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	return artifact, nil
+}
+
+func (a *apiServer) ReportArtifact(
+	ctx context.Context, req *apiv1.ReportArtifactRequest,
+) (*apiv1.ReportArtifactResponse, error) {
+	if req.Artifact == nil {
+		return nil, status.Error(codes.InvalidArgument, "artifact is required")
+	}
+	taskID := model.TaskID(req.Artifact.TaskId)
+	if err := a.canDoActionsOnTask(ctx, taskID,
+		expauth.AuthZProvider.Get().CanEditExperiment); err != nil {
+		return nil, err
+	}
+
+	artifactUUID, err := uuid.Parse(req.Artifact.Uuid)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"invalid artifact uuid %s: %s", req.Artifact.Uuid, err)
+	}
+	name := req.Artifact.Name
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"artifact name must be a file name: %q", name)
+	}
+	t, err := a.m.db.TaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := a.m.artifactStorage(ctx, t)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error resolving the artifact storage of task %s", taskID)
+	}
+
+	artifact := model.Artifact{
+		UUID:       artifactUUID,
+		TaskID:     taskID,
+		Name:       name,
+		Type:       req.Artifact.Type,
+		ReportTime: time.Now().UTC(),
+		Resources:  req.Artifact.Resources,
+		Metadata:   req.Artifact.Metadata.AsMap(),
+		Storage:    storage,
+	}
+	if req.Artifact.AllocationId != "" {
+		allocationID := model.AllocationID(req.Artifact.AllocationId)
+		artifact.AllocationID = &allocationID
+	}
+	if req.Artifact.ReportTime != nil && !req.Artifact.ReportTime.AsTime().IsZero() {
+		artifact.ReportTime = req.Artifact.ReportTime.AsTime().UTC()
+	}
+	if artifact.Resources == nil {
+		artifact.Resources = map[string]int64{}
+	}
+	if err := db.AddArtifact(ctx, &artifact); err != nil {
+		return nil, err
+	}
+	return &apiv1.ReportArtifactResponse{}, nil
+}
+
+func (a *apiServer) GetTaskArtifacts(
+	ctx context.Context, req *apiv1.GetTaskArtifactsRequest,
+) (*apiv1.GetTaskArtifactsResponse, error) {
+	taskID := model.TaskID(req.TaskId)
+	if err := a.canDoActionsOnTask(ctx, taskID,
+		expauth.AuthZProvider.Get().CanGetExperimentArtifacts); err != nil {
+		return nil, err
+	}
+
+	artifacts, err := db.TaskArtifacts(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.GetTaskArtifactsResponse{
+		Artifacts: make([]*artifactv1.Artifact, 0, len(artifacts)),
+	}
+	for _, artifact := range artifacts {
+		p, err := artifact.ToProto()
+		if err != nil {
+			return nil, err
+		}
+		resp.Artifacts = append(resp.Artifacts, p)
+	}
+	return resp, nil
+}
+
+func (a *apiServer) GetArtifact(
+	ctx context.Context, req *apiv1.GetArtifactRequest,
+) (*apiv1.GetArtifactResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	artifact, err := a.m.canDoActionOnArtifact(ctx, *curUser, req.ArtifactUuid,
+		expauth.AuthZProvider.Get().CanGetExperimentArtifacts)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := artifact.ToProto()
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.GetArtifactResponse{Artifact: p}, nil
+}
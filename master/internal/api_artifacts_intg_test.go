@@ -0,0 +1,147 @@
+//go:build integration
+// +build integration
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	detContext "github.com/determined-ai/determined/master/internal/context"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/artifactv1"
+)
+
+// addTestArtifact adds an artifact of a task that is stored in a shared_fs directory, and writes
+// its file there.
+func addTestArtifact(
+	t *testing.T, taskID model.TaskID, dir, content string,
+) *model.Artifact {
+	artifact := &model.Artifact{
+		UUID:       uuid.New(),
+		TaskID:     taskID,
+		Name:       "plot.png",
+		ReportTime: time.Now().UTC(),
+		Resources:  map[string]int64{"plot.png": int64(len(content))},
+		Metadata:   model.JSONObj{},
+		Storage: expconf.CheckpointStorageConfig{
+			RawSharedFSConfig: &expconf.SharedFSConfig{RawHostPath: ptrs.Ptr(dir)},
+		},
+	}
+	require.NoError(t, db.AddArtifact(context.Background(), artifact))
+
+	path := filepath.Join(dir, artifact.StorageKey())
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return artifact
+}
+
+func TestReportArtifact(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t)
+	trial := createTestTrial(t, api, curUser)
+
+	report := func(id, name string) error {
+		_, err := api.ReportArtifact(ctx, &apiv1.ReportArtifactRequest{
+			Artifact: &artifactv1.Artifact{
+				Uuid:   id,
+				TaskId: string(trial.TaskID),
+				Name:   name,
+				Type:   "plot",
+			},
+		})
+		return err
+	}
+
+	_, err := api.ReportArtifact(ctx, &apiv1.ReportArtifactRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Equal(t, codes.InvalidArgument, status.Code(report("not-a-uuid", "plot.png")))
+	for _, name := range []string{"", ".", "..", "plots/loss.png", `plots\loss.png`, "../x"} {
+		require.Equal(t, codes.InvalidArgument, status.Code(report(uuid.NewString(), name)),
+			"name %q", name)
+	}
+
+	id := uuid.NewString()
+	require.NoError(t, report(id, "loss.png"))
+	resp, err := api.GetTaskArtifacts(ctx, &apiv1.GetTaskArtifactsRequest{
+		TaskId: string(trial.TaskID),
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Artifacts, 1)
+	require.Equal(t, id, resp.Artifacts[0].Uuid)
+	require.Equal(t, "loss.png", resp.Artifacts[0].Name)
+	require.Equal(t, "plot", resp.Artifacts[0].Type)
+
+	artifact, err := api.GetArtifact(ctx, &apiv1.GetArtifactRequest{ArtifactUuid: id})
+	require.NoError(t, err)
+	require.Equal(t, string(trial.TaskID), artifact.Artifact.TaskId)
+}
+
+func TestAuthZArtifactsEcho(t *testing.T) {
+	api, authZExp, _, curUser, ctx := setupExpAuthTestEcho(t)
+	trial := createTestTrial(t, api, curUser)
+	ctx.SetRequest(httptest.NewRequest(http.MethodGet, "/", nil))
+	ctx.SetParamNames("artifact_uuid")
+
+	ctx.SetParamValues("not-a-uuid")
+	err := api.m.getArtifactFile(ctx)
+	require.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+
+	// Not found same as permission denied.
+	missing := uuid.NewString()
+	ctx.SetParamValues(missing)
+	require.Equal(t, echo.NewHTTPError(http.StatusNotFound,
+		fmt.Sprintf("artifact not found: %s", missing)), api.m.getArtifactFile(ctx))
+
+	artifact := addTestArtifact(t, trial.TaskID, t.TempDir(), "pixels")
+	ctx.SetParamValues(artifact.UUID.String())
+	authZExp.On("CanGetExperiment", mock.Anything, curUser, mock.Anything).Return(false, nil).Once()
+	require.Equal(t, echo.NewHTTPError(http.StatusNotFound,
+		fmt.Sprintf("artifact not found: %s", artifact.UUID)), api.m.getArtifactFile(ctx))
+
+	expectedErr := fmt.Errorf("canGetExperimentError")
+	authZExp.On("CanGetExperiment", mock.Anything, curUser, mock.Anything).
+		Return(false, expectedErr).Once()
+	require.Equal(t, expectedErr, api.m.getArtifactFile(ctx))
+
+	authZExp.On("CanGetExperiment", mock.Anything, curUser, mock.Anything).Return(true, nil).Once()
+	authZExp.On("CanGetExperimentArtifacts", mock.Anything, curUser, mock.Anything).
+		Return(fmt.Errorf("canGetArtifactsError")).Once()
+	require.Equal(t, echo.NewHTTPError(http.StatusForbidden, "canGetArtifactsError"),
+		api.m.getArtifactFile(ctx))
+}
+
+func TestGetArtifactFileEcho(t *testing.T) {
+	api, curUser, _ := setupAPITest(t)
+	trial := createTestTrial(t, api, curUser)
+	artifact := addTestArtifact(t, trial.TaskID, t.TempDir(), "pixels")
+
+	rec := httptest.NewRecorder()
+	c := &detContext.DetContext{
+		Context: echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec),
+	}
+	c.SetUser(curUser)
+	c.SetParamNames("artifact_uuid")
+	c.SetParamValues(artifact.UUID.String())
+
+	require.NoError(t, api.m.getArtifactFile(c))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "pixels", rec.Body.String())
+	require.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), `filename="plot.png"`)
+}
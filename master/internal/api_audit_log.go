@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/master/internal/auditlog"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/auditv1"
+)
+
+func (a *apiServer) GetAuditLog(
+	ctx context.Context, req *apiv1.GetAuditLogRequest,
+) (*apiv1.GetAuditLogResponse, error) {
+	if err := userShouldBeAdmin(ctx, a); err != nil {
+		return nil, err
+	}
+	if req.Offset < 0 || req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset and limit must not be negative")
+	}
+
+	f := auditlog.Filter{Action: req.Action, TargetType: req.TargetType}
+	if req.UserId != nil {
+		userID := model.UserID(*req.UserId)
+		f.UserID = &userID
+	}
+	for _, t := range []struct {
+		ts   *timestamppb.Timestamp
+		dest **time.Time
+	}{{req.Since, &f.Since}, {req.Until, &f.Until}} {
+		if t.ts == nil {
+			continue
+		}
+		if err := t.ts.CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		asTime := t.ts.AsTime()
+		*t.dest = &asTime
+	}
+
+	entries, total, err := auditlog.Query(ctx, f, int(req.Offset), int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.GetAuditLogResponse{
+		Entries: make([]*auditv1.AuditLogEntry, 0, len(entries)),
+		Pagination: &apiv1.Pagination{
+			Offset:     req.Offset,
+			Limit:      req.Limit,
+			StartIndex: req.Offset,
+			EndIndex:   req.Offset + int32(len(entries)),
+			Total:      int32(total),
+		},
+	}
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, e.Proto())
+	}
+	return resp, nil
+}
@@ -0,0 +1,75 @@
+//go:build integration
+// +build integration
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/master/internal/auditlog"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/userv1"
+)
+
+func TestAuditLog(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t)
+	since := timestamppb.New(time.Now().Add(-time.Second))
+
+	// Failed logins are recorded under the username that was tried.
+	badUsername := uuid.New().String()
+	_, err := api.Login(context.TODO(), &apiv1.LoginRequest{Username: badUsername})
+	require.Error(t, err)
+	resp, err := api.GetAuditLog(ctx, &apiv1.GetAuditLogRequest{
+		Action: string(auditlog.UserLogin), Since: since,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Entries)
+	require.Equal(t, badUsername, resp.Entries[0].Username)
+	require.Nil(t, resp.Entries[0].UserId)
+	require.False(t, resp.Entries[0].Success)
+
+	// Creating a user is recorded under the admin who did it.
+	username := uuid.New().String()
+	userResp, err := api.PostUser(ctx, &apiv1.PostUserRequest{
+		User: &userv1.User{Username: username, Active: true},
+	})
+	require.NoError(t, err)
+	userID := int32(curUser.ID)
+	resp, err = api.GetAuditLog(ctx, &apiv1.GetAuditLogRequest{
+		UserId: &userID, Action: string(auditlog.UserCreate), Since: since, Limit: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Entries, 1)
+	require.Equal(t, fmt.Sprint(userResp.User.Id), resp.Entries[0].TargetId)
+	require.Equal(t, curUser.Username, resp.Entries[0].Username)
+	require.True(t, resp.Entries[0].Success)
+
+	_, err = api.GetAuditLog(ctx, &apiv1.GetAuditLogRequest{Limit: -1})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Only admins can read the log.
+	loginResp, err := api.Login(context.TODO(), &apiv1.LoginRequest{Username: username})
+	require.NoError(t, err)
+	userCtx := metadata.NewIncomingContext(context.TODO(),
+		metadata.Pairs("x-user-token", fmt.Sprintf("Bearer %s", loginResp.Token)))
+	_, err = api.GetAuditLog(userCtx, &apiv1.GetAuditLogRequest{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Entries cannot be changed once recorded.
+	_, err = db.Bun().NewUpdate().Model((*auditlog.Entry)(nil)).
+		Set("success = true").
+		Where("id = ?", resp.Entries[0].Id).
+		Exec(context.TODO())
+	require.Error(t, err)
+}
@@ -4,13 +4,19 @@ import (
 	"context"
 	"crypto/sha512"
 	"fmt"
+	"time"
 
+	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/determined-ai/determined/master/internal/auditlog"
+	"github.com/determined-ai/determined/master/internal/config"
 	"github.com/determined-ai/determined/master/internal/db"
 	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/master/internal/plugin/sso"
 	"github.com/determined-ai/determined/master/internal/user"
+	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
 )
 
@@ -28,46 +34,193 @@ func replicateClientSideSaltAndHash(password string) string {
 	return fmt.Sprintf("%x", sum)
 }
 
-func (a *apiServer) Login(
-	ctx context.Context, req *apiv1.LoginRequest,
-) (*apiv1.LoginResponse, error) {
-	if a.m.config.InternalConfig.ExternalSessions.JwtKey != "" {
-		return nil, status.Error(codes.FailedPrecondition, "authentication is configured to be external")
+// checkNewPassword checks a new password against the password policy of the cluster and returns
+// it hashed the way clients hash it. Passwords can only be checked if they are sent unhashed.
+func checkNewPassword(password string, isHashed bool) (string, error) {
+	if isHashed {
+		if config.GetMasterConfig().Security.PasswordPolicy.ComplexityRequired() {
+			return "", status.Error(codes.InvalidArgument,
+				"the password policy requires new passwords to be sent unhashed")
+		}
+		return password, nil
+	}
+	if err := user.CheckPasswordPolicy(password); err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	return replicateClientSideSaltAndHash(password), nil
+}
+
+// loginRequest holds the credentials that users log in with through either the gRPC or the
+// legacy REST login endpoint.
+type loginRequest struct {
+	Username    string
+	Password    string
+	IsHashed    bool
+	NewPassword string
+	MfaCode     string
+}
+
+// loginResult is the session that a successful login starts.
+type loginResult struct {
+	Token                 string
+	MfaEnrollmentRequired bool
+}
+
+// login checks the credentials of a user and starts a session for them. Every login endpoint
+// must go through it so that lockouts, password expiry, LDAP, and MFA apply to all of them. The
+// user is returned even on failure, when known, for the audit log.
+func (m *Master) login(
+	ctx context.Context, req loginRequest,
+) (userModel *model.User, _ *loginResult, err error) {
+	if m.config.InternalConfig.ExternalSessions.JwtKey != "" {
+		return nil, nil, status.Error(codes.FailedPrecondition,
+			"authentication is configured to be external")
 	}
 
 	if req.Username == "" {
-		return nil, status.Error(codes.InvalidArgument, "missing argument: username")
+		return nil, nil, status.Error(codes.InvalidArgument, "missing argument: username")
 	}
 
-	userModel, err := user.UserByUsername(req.Username)
-	switch err {
-	case nil:
-	case db.ErrNotFound:
-		return nil, grpcutil.ErrInvalidCredentials
+	var newPasswordHash string
+	userModel, err = user.UserByUsername(req.Username)
+	switch {
+	case err == nil && userModel.ServiceAccount:
+		return userModel, nil, grpcutil.ErrServiceAccountLogin
+	case err == nil && !user.IsSSOUser(*userModel):
+		passwordState, err := user.PasswordState(ctx, userModel.ID)
+		if err != nil {
+			return userModel, nil, err
+		}
+		if passwordState.Locked(time.Now()) {
+			return userModel, nil, grpcutil.ErrUserLocked
+		}
+		var hashedPassword string
+		if req.IsHashed {
+			hashedPassword = req.Password
+		} else {
+			hashedPassword = replicateClientSideSaltAndHash(req.Password)
+		}
+		if !userModel.ValidatePassword(hashedPassword) {
+			if err := user.RecordFailedLogin(ctx, userModel.ID); err != nil {
+				return userModel, nil, err
+			}
+			return userModel, nil, grpcutil.ErrInvalidCredentials
+		}
+		if user.PasswordExpired(passwordState, time.Now()) {
+			// Users change an expired password as they log in, since they cannot log in to
+			// change it otherwise.
+			if req.NewPassword == "" {
+				return userModel, nil, grpcutil.ErrPasswordExpired
+			}
+			if newPasswordHash, err = checkNewPassword(req.NewPassword, req.IsHashed); err != nil {
+				return userModel, nil, err
+			}
+			if newPasswordHash == hashedPassword {
+				return userModel, nil, status.Error(codes.InvalidArgument,
+					"the new password must differ from the expired one")
+			}
+		}
+	case (err == nil || err == db.ErrNotFound) && sso.LDAPEnabled():
+		// Users without a password in the cluster log in with their password in the directory,
+		// which clients send unhashed when LDAP is enabled.
+		if req.IsHashed {
+			return userModel, nil, status.Error(codes.InvalidArgument,
+				"LDAP logins require the password to be sent unhashed")
+		}
+		ldapUser, ldapErr := sso.LDAPLogin(ctx, req.Username, req.Password)
+		switch {
+		case errors.Is(ldapErr, sso.ErrLDAPInvalidCredentials):
+			return userModel, nil, grpcutil.ErrInvalidCredentials
+		case ldapErr != nil:
+			return userModel, nil, ldapErr
+		}
+		userModel = ldapUser
+	case err == nil, err == db.ErrNotFound:
+		// Users that log in through single sign-on have no password.
+		return userModel, nil, grpcutil.ErrInvalidCredentials
 	default:
-		return nil, err
+		return nil, nil, err
 	}
 
-	var hashedPassword string
-	if req.IsHashed {
-		hashedPassword = req.Password
-	} else {
-		hashedPassword = replicateClientSideSaltAndHash(req.Password)
+	if !userModel.Active {
+		return userModel, nil, grpcutil.ErrNotActive
 	}
 
-	if !userModel.ValidatePassword(hashedPassword) {
-		return nil, grpcutil.ErrInvalidCredentials
+	startSession := m.db.StartUserSession
+	enrollmentRequired := false
+	if !user.IsSSOUser(*userModel) {
+		mfa, err := user.UserMFA(ctx, userModel.ID)
+		if err != nil {
+			return userModel, nil, err
+		}
+		switch {
+		case mfa.Enrolled() && req.MfaCode == "":
+			return userModel, nil, grpcutil.ErrMFARequired
+		case mfa.Enrolled():
+			switch err := user.VerifyMFACode(ctx, mfa, req.MfaCode); {
+			case errors.Is(err, user.ErrInvalidMFACode):
+				if err := user.RecordFailedLogin(ctx, userModel.ID); err != nil {
+					return userModel, nil, err
+				}
+				return userModel, nil, grpcutil.ErrInvalidCredentials
+			case err != nil:
+				return userModel, nil, err
+			}
+		case user.MFARequired(mfa):
+			// Users who must enroll can do nothing else until they have.
+			startSession = m.db.StartMFAEnrollmentSession
+			enrollmentRequired = true
+		}
+		if err := user.RecordSuccessfulLogin(ctx, userModel.ID); err != nil {
+			return userModel, nil, err
+		}
+	}
+	if newPasswordHash != "" {
+		if err := userModel.UpdatePasswordHash(newPasswordHash); err != nil {
+			return userModel, nil, err
+		}
+		if err := m.db.UpdateUser(userModel, []string{"password_hash"}, nil); err != nil {
+			return userModel, nil, err
+		}
 	}
 
-	if !userModel.Active {
-		return nil, grpcutil.ErrNotActive
+	token, err := startSession(userModel)
+	if err != nil {
+		return userModel, nil, err
 	}
-	token, err := a.m.db.StartUserSession(userModel)
+	return userModel, &loginResult{Token: token, MfaEnrollmentRequired: enrollmentRequired}, nil
+}
+
+func (a *apiServer) Login(
+	ctx context.Context, req *apiv1.LoginRequest,
+) (_ *apiv1.LoginResponse, err error) {
+	var userModel *model.User
+	defer func() {
+		// Failed logins for unknown users are recorded under the name they tried.
+		auditlog.Record(ctx, userModel, auditlog.Entry{
+			Username: req.Username,
+			Action:   auditlog.UserLogin,
+			Success:  err == nil,
+		})
+	}()
+
+	var res *loginResult
+	userModel, res, err = a.m.login(ctx, loginRequest{
+		Username:    req.Username,
+		Password:    req.Password,
+		IsHashed:    req.IsHashed,
+		NewPassword: req.NewPassword,
+		MfaCode:     req.MfaCode,
+	})
 	if err != nil {
 		return nil, err
 	}
 	fullUser, err := getUser(a.m.db, userModel.ID)
-	return &apiv1.LoginResponse{Token: token, User: fullUser}, err
+	return &apiv1.LoginResponse{
+		Token:                 res.Token,
+		User:                  fullUser,
+		MfaEnrollmentRequired: res.MfaEnrollmentRequired,
+	}, err
 }
 
 func (a *apiServer) CurrentUser(
@@ -92,6 +245,10 @@ func (a *apiServer) Logout(
 		return nil, status.Error(codes.InvalidArgument,
 			"cannot manually logout of an allocation session")
 	}
+	if userSession.AccessTokenScope != "" {
+		return nil, status.Error(codes.InvalidArgument,
+			"cannot logout of an access token; revoke it instead")
+	}
 
 	err = a.m.db.DeleteUserSessionByID(userSession.ID)
 	return &apiv1.LogoutResponse{}, err
@@ -10,10 +10,12 @@ import (
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 
+	"github.com/determined-ai/determined/master/internal/api"
 	expauth "github.com/determined-ai/determined/master/internal/experiment"
 	"github.com/determined-ai/determined/master/internal/grpcutil"
 	"github.com/determined-ai/determined/master/internal/user"
@@ -37,6 +39,39 @@ func errCheckpointsNotFound(ids []string) error {
 	return status.Errorf(codes.NotFound, "checkpoints not found: %s", strings.Join(tmp, ", "))
 }
 
+// paginateCheckpoints pages a sorted list of checkpoints by offset or by the cursor of the request,
+// if it has one. Checkpoints are listed in memory, so a cursor names the last checkpoint of the
+// previous page, and the next page starts after it wherever it now falls in the list.
+func (a *apiServer) paginateCheckpoints(
+	p **apiv1.Pagination, checkpoints *[]*checkpointv1.Checkpoint, offset, limit int32,
+	reqCursor string,
+) error {
+	cursor, err := api.DecodeCursor(reqCursor, offset)
+	if err != nil {
+		return err
+	}
+	total := int32(len(*checkpoints))
+	if cursor != nil {
+		i := slices.IndexFunc(*checkpoints, func(c *checkpointv1.Checkpoint) bool {
+			return c.Uuid == cursor.UUID
+		})
+		if i < 0 {
+			return status.Errorf(codes.InvalidArgument,
+				"the checkpoint of the cursor is no longer listed: %s", cursor.UUID)
+		}
+		*checkpoints = (*checkpoints)[i+1:]
+	}
+
+	if err := a.paginate(p, checkpoints, offset, limit); err != nil {
+		return err
+	}
+	if n := len(*checkpoints); n > 0 && (*p).EndIndex < (*p).Total {
+		(*p).NextCursor = api.Cursor{UUID: (*checkpoints)[n-1].Uuid}.Encode()
+	}
+	(*p).Total = total
+	return nil
+}
+
 func (m *Master) canDoActionOnCheckpoint(
 	ctx context.Context,
 	curUser model.User,
@@ -19,11 +19,13 @@ import (
 
 	"github.com/determined-ai/determined/master/internal/api"
 	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/master/internal/podtemplates"
 	"github.com/determined-ai/determined/master/internal/user"
 	"github.com/determined-ai/determined/master/pkg/actor"
 	"github.com/determined-ai/determined/master/pkg/archive"
 	"github.com/determined-ai/determined/master/pkg/check"
 	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/master/pkg/logger"
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/master/pkg/protoutils"
 	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
@@ -88,6 +90,13 @@ func (a *apiServer) getCommandLaunchParams(ctx context.Context, req *protoComman
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, err.Error())
 	}
+	switch err := checkResourcePoolBound(ctx, poolName, userModel.ID, nil); err.(type) {
+	case nil:
+	case ErrResourcePoolNotBound:
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return nil, err
+	}
 
 	// Get the base TaskSpec.
 	taskContainerDefaults := a.m.getTaskContainerDefaults(poolName)
@@ -127,6 +136,19 @@ func (a *apiServer) getCommandLaunchParams(ctx context.Context, req *protoComman
 	if req.MustZeroSlot {
 		config.Resources.Slots = 0
 	}
+	if name := config.Environment.PodTemplateName; name != nil {
+		podSpec, err := podtemplates.Resolve(ctx, *name, config.Environment.PodSpec)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		config.Environment.PodSpec = podSpec
+	}
+	// Like for experiments, the containers of the task container defaults are added to those of
+	// the task, rather than replaced by them.
+	config.Environment.InitContainers = expconf.Containers(config.Environment.InitContainers).
+		Merge(expconf.Containers(taskSpec.TaskContainerDefaults.InitContainers)).(expconf.Containers)
+	config.Environment.Sidecars = expconf.Containers(config.Environment.Sidecars).
+		Merge(expconf.Containers(taskSpec.TaskContainerDefaults.Sidecars)).(expconf.Containers)
 	if config.Environment.PodSpec == nil {
 		if config.Resources.Slots == 0 {
 			config.Environment.PodSpec = taskSpec.TaskContainerDefaults.CPUPodSpec
@@ -157,9 +179,10 @@ func (a *apiServer) getCommandLaunchParams(ctx context.Context, req *protoComman
 	taskSpec.UserSessionToken = token
 
 	return &tasks.GenericCommandSpec{
-		Base:      taskSpec,
-		Config:    config,
-		UserFiles: userFiles,
+		Base:       taskSpec,
+		Config:     config,
+		UserFiles:  userFiles,
+		LogContext: logger.RequestFields(ctx),
 	}, nil
 }
 
@@ -274,6 +297,7 @@ func (a *apiServer) LaunchCommand(
 		)
 	}
 	spec.Base.ExtraEnvVars = map[string]string{"DET_TASK_TYPE": string(model.TaskTypeCommand)}
+	spec.ArtifactStorage = &a.m.config.CheckpointStorage
 
 	// Launch a command actor.
 	var cmdID model.TaskID
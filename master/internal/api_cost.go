@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+var slotHoursGroupings = map[apiv1.GetResourceCostReportRequest_GroupBy]db.SlotHoursGroupBy{
+	apiv1.GetResourceCostReportRequest_GROUP_BY_UNSPECIFIED: db.SlotHoursByExperiment,
+	apiv1.GetResourceCostReportRequest_GROUP_BY_EXPERIMENT:  db.SlotHoursByExperiment,
+	apiv1.GetResourceCostReportRequest_GROUP_BY_USER:        db.SlotHoursByUser,
+	apiv1.GetResourceCostReportRequest_GROUP_BY_WORKSPACE:   db.SlotHoursByWorkspace,
+}
+
+// costModel prices slot hours at the hourly rates of a slot in their resource pools.
+type costModel struct {
+	rates    map[string]float64
+	currency string
+}
+
+func (a *apiServer) costModel() costModel {
+	return costModel{
+		rates:    a.m.config.Cost.SlotHourRates(a.m.config.ResourcePools),
+		currency: a.m.config.Cost.Currency,
+	}
+}
+
+func (c costModel) price(hours []model.SlotHours) *apiv1.ResourceCost {
+	cost := &apiv1.ResourceCost{Currency: c.currency}
+	for _, h := range hours {
+		cost.SlotHours += h.SlotHours
+		if rate, ok := c.rates[h.ResourcePool]; ok {
+			cost.Cost += rate * h.SlotHours
+		} else {
+			cost.UnpricedSlotHours += h.SlotHours
+		}
+	}
+	return cost
+}
+
+// experimentCost prices the slot hours that each trial of an experiment used.
+func (a *apiServer) experimentCost(
+	ctx context.Context, experimentID int,
+) (*apiv1.GetExperimentCostResponse, error) {
+	hours, err := db.ExperimentSlotHours(ctx, experimentID)
+	if err != nil {
+		return nil, err
+	}
+
+	costs := a.costModel()
+	resp := &apiv1.GetExperimentCostResponse{Trials: []*apiv1.TrialCost{}}
+	var all, trial []model.SlotHours
+	for i, h := range hours {
+		all = append(all, h.SlotHours)
+		trial = append(trial, h.SlotHours)
+		// Rows are ordered by trial, so a trial ends where the next row is of another one.
+		if i == len(hours)-1 || hours[i+1].TrialID != h.TrialID {
+			resp.Trials = append(resp.Trials, &apiv1.TrialCost{
+				TrialId: int32(h.TrialID), Cost: costs.price(trial),
+			})
+			trial = nil
+		}
+	}
+	resp.Cost = costs.price(all)
+	return resp, nil
+}
+
+func (a *apiServer) GetExperimentCost(
+	ctx context.Context, req *apiv1.GetExperimentCostRequest,
+) (*apiv1.GetExperimentCostResponse, error) {
+	user, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get the user: %s", err)
+	}
+	if _, err := a.getExperiment(ctx, *user, int(req.ExperimentId)); err != nil {
+		return nil, err
+	}
+	return a.experimentCost(ctx, int(req.ExperimentId))
+}
+
+func (a *apiServer) GetResourceCostReport(
+	ctx context.Context, req *apiv1.GetResourceCostReportRequest,
+) (*apiv1.GetResourceCostReportResponse, error) {
+	if req.StartTime == nil || req.EndTime == nil {
+		return nil, status.Error(codes.InvalidArgument, "start_time and end_time are required")
+	}
+	start, end := req.StartTime.AsTime().UTC(), req.EndTime.AsTime().UTC()
+	if !start.Before(end) {
+		return nil, status.Error(codes.InvalidArgument, "start_time must be before end_time")
+	}
+	groupBy, ok := slotHoursGroupings[req.GroupBy]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown group_by: %s", req.GroupBy)
+	}
+
+	hours, err := db.SlotHoursReport(ctx, groupBy, start, end)
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching slot hours")
+	}
+
+	costs := a.costModel()
+	resp := &apiv1.GetResourceCostReportResponse{Entries: []*apiv1.ResourceCostReportEntry{}}
+	var all, group []model.SlotHours
+	for i, h := range hours {
+		all = append(all, h.SlotHours)
+		group = append(group, h.SlotHours)
+		if i == len(hours)-1 || hours[i+1].ID != h.ID {
+			resp.Entries = append(resp.Entries, &apiv1.ResourceCostReportEntry{
+				Id: int32(h.ID), Name: h.Name, Cost: costs.price(group),
+			})
+			group = nil
+		}
+	}
+	resp.Total = costs.price(all)
+	return resp, nil
+}
@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/auditlog"
+	"github.com/determined-ai/determined/master/internal/backup"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/backupv1"
+)
+
+func (a *apiServer) PostDatabaseBackup(
+	ctx context.Context, _ *apiv1.PostDatabaseBackupRequest,
+) (*apiv1.PostDatabaseBackupResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !curUser.Admin {
+		return nil, grpcutil.ErrPermissionDenied
+	}
+
+	// Backups hold credentials, so they are never written to the checkpoint storage, which
+	// tasks can write to.
+	if a.m.config.Backup.Storage == nil {
+		return nil, status.Error(codes.FailedPrecondition,
+			"backup.storage must be set in the master config to take backups")
+	}
+	storage := *a.m.config.Backup.Storage
+	if reflect.DeepEqual(storage, a.m.config.CheckpointStorage) {
+		return nil, status.Error(codes.FailedPrecondition,
+			"backup.storage must not be the checkpoint storage, which tasks can write to")
+	}
+	config, err := a.m.config.Printable()
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing master config")
+	}
+	b, err := backup.Start(ctx, storage, config)
+	entry := auditlog.Entry{Action: auditlog.DatabaseBackup, TargetType: "database_backup"}
+	if err == nil {
+		entry.TargetID = strconv.Itoa(b.ID)
+	}
+	entry.Success = err == nil
+	auditlog.Record(ctx, curUser, entry)
+	if err != nil {
+		return nil, errors.Wrap(err, "error starting database backup")
+	}
+	return &apiv1.PostDatabaseBackupResponse{Backup: b.Proto()}, nil
+}
+
+func (a *apiServer) GetDatabaseBackups(
+	ctx context.Context, _ *apiv1.GetDatabaseBackupsRequest,
+) (*apiv1.GetDatabaseBackupsResponse, error) {
+	if err := userShouldBeAdmin(ctx, a); err != nil {
+		return nil, err
+	}
+
+	backups, err := backup.ListBackups(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching database backups")
+	}
+	resp := &apiv1.GetDatabaseBackupsResponse{Backups: []*backupv1.DatabaseBackup{}}
+	for _, b := range backups {
+		resp.Backups = append(resp.Backups, b.Proto())
+	}
+	return resp, nil
+}
+
+func (a *apiServer) GetDatabaseBackup(
+	ctx context.Context, req *apiv1.GetDatabaseBackupRequest,
+) (*apiv1.GetDatabaseBackupResponse, error) {
+	if err := userShouldBeAdmin(ctx, a); err != nil {
+		return nil, err
+	}
+
+	switch b, err := backup.GetBackup(ctx, int(req.BackupId)); {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "database backup %d not found", req.BackupId)
+	case err != nil:
+		return nil, errors.Wrapf(err, "error fetching database backup %d", req.BackupId)
+	default:
+		return &apiv1.GetDatabaseBackupResponse{Backup: b.Proto()}, nil
+	}
+}
+
+func (a *apiServer) PostDatabaseRestore(
+	ctx context.Context, req *apiv1.PostDatabaseRestoreRequest,
+) (*apiv1.PostDatabaseRestoreResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !curUser.Admin {
+		return nil, grpcutil.ErrPermissionDenied
+	}
+
+	r, err := backup.StartRestore(ctx, int(req.BackupId), req.ValidateOnly, a.m.exitAfterRestore)
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.DatabaseRestore,
+		TargetType: "database_backup",
+		TargetID:   strconv.Itoa(int(req.BackupId)),
+		Success:    err == nil,
+		Details:    map[string]interface{}{"validate_only": req.ValidateOnly},
+	})
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "database backup %d not found", req.BackupId)
+	case errors.Is(err, backup.ErrActive), errors.Is(err, backup.ErrNotCompleted),
+		errors.Is(err, backup.ErrNotMaintenance), errors.Is(err, backup.ErrRestoring):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, errors.Wrapf(err, "error restoring database backup %d", req.BackupId)
+	}
+	return &apiv1.PostDatabaseRestoreResponse{Restore: r.Proto()}, nil
+}
+
+func (a *apiServer) GetDatabaseRestore(
+	ctx context.Context, req *apiv1.GetDatabaseRestoreRequest,
+) (*apiv1.GetDatabaseRestoreResponse, error) {
+	if err := userShouldBeAdmin(ctx, a); err != nil {
+		return nil, err
+	}
+
+	switch r, err := backup.GetRestore(ctx, int(req.RestoreId)); {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "database restore %d not found", req.RestoreId)
+	case err != nil:
+		return nil, errors.Wrapf(err, "error fetching database restore %d", req.RestoreId)
+	default:
+		return &apiv1.GetDatabaseRestoreResponse{Restore: r.Proto()}, nil
+	}
+}
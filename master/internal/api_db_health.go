@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+func (a *apiServer) GetDatabaseHealth(
+	ctx context.Context, req *apiv1.GetDatabaseHealthRequest,
+) (*apiv1.GetDatabaseHealthResponse, error) {
+	if err := userShouldBeAdmin(ctx, a); err != nil {
+		return nil, err
+	}
+
+	health, err := db.CheckHealth(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error checking the health of the database")
+	}
+	migrations, err := db.CheckMigrations(ctx, a.m.config.DB.Migrations)
+	if err != nil {
+		return nil, errors.Wrap(err, "error checking migrations")
+	}
+
+	resp := &apiv1.GetDatabaseHealthResponse{
+		SizeBytes:         health.SizeBytes,
+		Tables:            []*apiv1.DatabaseTable{},
+		RunningQueries:    []*apiv1.DatabaseQuery{},
+		SlowQueries:       []*apiv1.DatabaseQuery{},
+		MigrationVersion:  migrations.CurrentVersion,
+		PendingMigrations: int32(len(migrations.Pending)),
+		SchemaDrift:       int32(len(migrations.Drift)),
+	}
+	for _, t := range health.Tables {
+		table := &apiv1.DatabaseTable{
+			Table:      t.Table,
+			TotalBytes: t.TotalBytes,
+			IndexBytes: t.IndexBytes,
+			LiveRows:   t.LiveRows,
+			DeadRows:   t.DeadRows,
+			Bloat:      t.Bloat(),
+		}
+		if t.LastVacuum != nil {
+			table.LastVacuum = timestamppb.New(*t.LastVacuum)
+		}
+		resp.Tables = append(resp.Tables, table)
+	}
+	for _, q := range health.Running {
+		resp.RunningQueries = append(resp.RunningQueries, &apiv1.DatabaseQuery{
+			Query:           q.Query,
+			StartTime:       timestamppb.New(q.Started),
+			DurationSeconds: q.Duration().Seconds(),
+			Pid:             int32(q.PID),
+			State:           q.State,
+			WaitEvent:       q.WaitEvent,
+		})
+	}
+	for _, q := range health.Slow {
+		resp.SlowQueries = append(resp.SlowQueries, &apiv1.DatabaseQuery{
+			Query:           q.Query,
+			StartTime:       timestamppb.New(q.Time),
+			DurationSeconds: q.Duration.Seconds(),
+			Failed:          q.Failed,
+		})
+	}
+	return resp, nil
+}
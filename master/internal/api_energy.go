@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+const joulesPerKWh = 3.6e6
+
+func (a *apiServer) GetExperimentEnergy(
+	ctx context.Context, req *apiv1.GetExperimentEnergyRequest,
+) (*apiv1.GetExperimentEnergyResponse, error) {
+	user, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get the user: %s", err)
+	}
+	if _, err := a.getExperiment(ctx, *user, int(req.ExperimentId)); err != nil {
+		return nil, err
+	}
+	energy, err := db.ExperimentGPUEnergy(ctx, int(req.ExperimentId))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &apiv1.GetExperimentEnergyResponse{
+		Energy: &apiv1.EnergyUsage{},
+		Trials: []*apiv1.TrialEnergy{},
+	}
+	var trial *apiv1.TrialEnergy
+	for _, e := range energy {
+		// Rows are ordered by trial, with one for each resource pool the trial ran in.
+		if trial == nil || trial.TrialId != int32(e.TrialID) {
+			trial = &apiv1.TrialEnergy{TrialId: int32(e.TrialID), Energy: &apiv1.EnergyUsage{}}
+			resp.Trials = append(resp.Trials, trial)
+		}
+		kWh := e.Joules / joulesPerKWh
+		co2 := kWh * a.m.config.Energy.CarbonIntensityOf(e.ResourcePool)
+		for _, usage := range []*apiv1.EnergyUsage{trial.Energy, resp.Energy} {
+			usage.GpuEnergyKwh += kWh
+			usage.Co2Grams += co2
+		}
+	}
+	return resp, nil
+}
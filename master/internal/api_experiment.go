@@ -6,19 +6,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"reflect"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 
 	"github.com/uptrace/bun"
 
+	"github.com/determined-ai/determined/master/internal/api"
 	"github.com/determined-ai/determined/master/internal/project"
 	"github.com/determined-ai/determined/master/internal/prom"
 	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/internal/stream"
 	"github.com/determined-ai/determined/master/internal/user"
 
 	"github.com/google/uuid"
@@ -27,15 +31,22 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/pkg/errors"
 
+	"github.com/determined-ai/determined/master/internal/auditlog"
+	"github.com/determined-ai/determined/master/internal/coldarchive"
 	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/dependencies"
 	expauth "github.com/determined-ai/determined/master/internal/experiment"
 	"github.com/determined-ai/determined/master/internal/grpcutil"
 	"github.com/determined-ai/determined/master/internal/hpimportance"
 	"github.com/determined-ai/determined/master/internal/lttb"
+	"github.com/determined-ai/determined/master/internal/querycache"
 	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/check"
+	"github.com/determined-ai/determined/master/pkg/logger"
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/master/pkg/protoutils"
 	"github.com/determined-ai/determined/master/pkg/protoutils/protoless"
@@ -49,7 +60,7 @@ import (
 	"github.com/determined-ai/determined/proto/pkg/jobv1"
 	"github.com/determined-ai/determined/proto/pkg/projectv1"
 
-	structpb "github.com/golang/protobuf/ptypes/struct"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 var experimentsAddr = actor.Addr("experiments")
@@ -277,8 +288,20 @@ func (a *apiServer) GetExperiment(
 		return nil, err
 	}
 
+	cost, err := a.experimentCost(ctx, int(req.ExperimentId))
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := a.experimentProgress(ctx, exp)
+	if err != nil {
+		return nil, err
+	}
+
 	resp := apiv1.GetExperimentResponse{
-		Experiment: exp,
+		Experiment:       exp,
+		Cost:             cost.Cost,
+		ProgressEstimate: progress,
 	}
 
 	// Only continue to add a job summary if it's an active experiment.
@@ -323,24 +346,52 @@ func (a *apiServer) DeleteExperiment(
 		return nil, err
 	}
 
-	switch exists, eErr := a.m.db.ExperimentHasCheckpointsInRegistry(int(req.ExperimentId)); {
+	if err := a.checkExperimentDeletable(e); err != nil {
+		return nil, err
+	}
+
+	e.State = model.DeletingState
+	if err := a.m.db.TrySaveExperimentState(e); err != nil {
+		return nil, errors.Wrapf(err, "transitioning to %s", e.State)
+	}
+	recordExperimentDelete(ctx, &curUser, e.ID)
+	a.deleteExperimentInBackground(e, &curUser)
+
+	return &apiv1.DeleteExperimentResponse{}, nil
+}
+
+// recordExperimentDelete records the deletion of an experiment in the audit log.
+func recordExperimentDelete(ctx context.Context, curUser *model.User, id int) {
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.ExperimentDelete,
+		TargetType: "experiment",
+		TargetID:   strconv.Itoa(id),
+		Success:    true,
+	})
+}
+
+// checkExperimentDeletable returns an error if the experiment can't be deleted in its current
+// state.
+func (a *apiServer) checkExperimentDeletable(e *model.Experiment) error {
+	switch exists, eErr := a.m.db.ExperimentHasCheckpointsInRegistry(e.ID); {
 	case eErr != nil:
-		return nil, errors.New("failed to check model registry for references")
+		return errors.New("failed to check model registry for references")
 	case exists:
-		return nil, status.Errorf(
+		return status.Errorf(
 			codes.InvalidArgument, "checkpoints are registered as model versions")
 	}
 
 	if !model.ExperimentTransitions[e.State][model.DeletingState] {
-		return nil, fmt.Errorf("cannot delete experiment in %s state", e.State)
+		return fmt.Errorf("cannot delete experiment in %s state", e.State)
 	}
+	return nil
+}
 
-	e.State = model.DeletingState
-	if err := a.m.db.TrySaveExperimentState(e); err != nil {
-		return nil, errors.Wrapf(err, "transitioning to %s", e.State)
-	}
+// deleteExperimentInBackground deletes an experiment that was moved to the DELETING state,
+// moving it to DELETE_FAILED if that fails.
+func (a *apiServer) deleteExperimentInBackground(e *model.Experiment, curUser *model.User) {
 	go func() {
-		if err := a.deleteExperiment(e, &curUser); err != nil {
+		if err := a.deleteExperiment(e, curUser); err != nil {
 			logrus.WithError(err).Errorf("deleting experiment %d", e.ID)
 			e.State = model.DeleteFailedState
 			if err := a.m.db.SaveExperimentState(e); err != nil {
@@ -350,8 +401,6 @@ func (a *apiServer) DeleteExperiment(
 			logrus.Infof("experiment %d deleted successfully", e.ID)
 		}
 	}()
-
-	return &apiv1.DeleteExperimentResponse{}, nil
 }
 
 func (a *apiServer) deleteExperiment(exp *model.Experiment, userModel *model.User) error {
@@ -427,14 +476,35 @@ func protoStateDBCaseString(
 
 func (a *apiServer) GetExperiments(
 	ctx context.Context, req *apiv1.GetExperimentsRequest,
+) (*apiv1.GetExperimentsResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get the user: %s", err)
+	}
+	key, err := querycache.Key("GetExperiments", curUser.ID, req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.m.queryCache.Get(key, func() (proto.Message, error) {
+		return a.getExperiments(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*apiv1.GetExperimentsResponse), nil
+}
+
+func (a *apiServer) getExperiments(
+	ctx context.Context, req *apiv1.GetExperimentsRequest,
 ) (*apiv1.GetExperimentsResponse, error) {
 	resp := &apiv1.GetExperimentsResponse{Experiments: []*experimentv1.Experiment{}}
-	query := db.Bun().NewSelect().
+	query := db.ReadBun().NewSelect().
 		Model(&resp.Experiments).
 		ModelTableExpr("experiments as e").
 		Column("e.id").
 		ColumnExpr("e.config->>'description' AS description").
 		ColumnExpr("e.config->>'labels' AS labels").
+		Column("e.key_value_labels").
 		ColumnExpr("proto_time(e.start_time) AS start_time").
 		ColumnExpr("proto_time(e.end_time) AS end_time").
 		ColumnExpr(protoStateDBCaseString(experimentv1.State_value, "e.state", "state", "STATE_")).
@@ -489,6 +559,14 @@ func (a *apiServer) GetExperiments(
 	}
 	orderExpr := ""
 	switch _, ok := orderColMap[req.SortBy]; {
+	case req.SortByKeyValueLabel != "":
+		// Experiments without the label sort last in either order.
+		direction := "ASC"
+		if req.OrderBy == apiv1.OrderBy_ORDER_BY_DESC {
+			direction = "DESC"
+		}
+		orderExpr = fmt.Sprintf("e.key_value_labels->? %s NULLS LAST, id %s",
+			direction, sortByMap[req.OrderBy])
 	case !ok:
 		return nil, fmt.Errorf("unsupported sort by %s", req.SortBy)
 	case orderColMap[req.SortBy] != "id": //nolint:goconst // Not actually the same constant.
@@ -499,7 +577,20 @@ func (a *apiServer) GetExperiments(
 	default:
 		orderExpr = fmt.Sprintf("id %s", sortByMap[req.OrderBy])
 	}
-	query = query.OrderExpr(orderExpr)
+	if req.SortByKeyValueLabel != "" {
+		query = query.OrderExpr(orderExpr, req.SortByKeyValueLabel)
+	} else {
+		query = query.OrderExpr(orderExpr)
+	}
+	sortByID := req.SortByKeyValueLabel == "" && orderColMap[req.SortBy] == "id"
+	cursor, err := api.DecodeCursor(req.Cursor, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+	if cursor != nil && !sortByID {
+		return nil, status.Error(codes.InvalidArgument,
+			"cursors are only supported when sorting experiments by id")
+	}
 
 	// Filtering
 	if req.Description != "" {
@@ -517,6 +608,15 @@ func (a *apiServer) GetExperiments(
 				ELSE e.config->'labels' END
 			))`, strings.Join(req.Labels, ",")) // Trying bun.In doesn't work.
 	}
+	for _, filter := range req.KeyValueLabels {
+		query = applyKeyValueLabelFilter(query, filter)
+	}
+	for _, filter := range req.Config {
+		var err error
+		if query, err = applyConfigFilter(query, filter); err != nil {
+			return nil, err
+		}
+	}
 	if req.Archived != nil {
 		query = query.Where("e.archived = ?", req.Archived.Value)
 	}
@@ -560,10 +660,20 @@ func (a *apiServer) GetExperiments(
 		return nil, err
 	}
 
-	resp.Pagination, err = runPagedBunExperimentsQuery(ctx, query, int(req.Offset), int(req.Limit))
+	resp.Pagination, err = runPagedBunExperimentsQuery(ctx, query, int(req.Offset), int(req.Limit),
+		cursor, req.OrderBy == apiv1.OrderBy_ORDER_BY_DESC)
 	if err != nil {
 		return nil, err
 	}
+	pageSize := resp.Pagination.EndIndex - resp.Pagination.StartIndex
+	if cursor != nil {
+		resp.Pagination.EndIndex = int32(len(resp.Experiments))
+	}
+	pageFull := len(resp.Experiments) > 0 && int32(len(resp.Experiments)) == pageSize
+	if sortByID && pageFull && (cursor != nil || resp.Pagination.EndIndex < resp.Pagination.Total) {
+		last := resp.Experiments[len(resp.Experiments)-1]
+		resp.Pagination.NextCursor = api.Cursor{ID: int(last.Id)}.Encode()
+	}
 
 	if err = a.enrichExperimentState(resp.Experiments...); err != nil {
 		return nil, err
@@ -573,7 +683,7 @@ func (a *apiServer) GetExperiments(
 }
 
 func runPagedBunExperimentsQuery(
-	ctx context.Context, query *bun.SelectQuery, offset, limit int,
+	ctx context.Context, query *bun.SelectQuery, offset, limit int, cursor *api.Cursor, desc bool,
 ) (*apiv1.Pagination, error) {
 	// Count number of items without any limits or offsets.
 	total, err := query.Count(ctx)
@@ -581,6 +691,16 @@ func runPagedBunExperimentsQuery(
 		return nil, err
 	}
 
+	// With a cursor, the page starts after the experiment it points to, and its indexes are
+	// relative to it.
+	if cursor != nil {
+		if desc {
+			query = query.Where("e.id < ?", cursor.ID)
+		} else {
+			query = query.Where("e.id > ?", cursor.ID)
+		}
+	}
+
 	// Calculate end and start indexes.
 	startIndex := offset
 	if offset > total || offset < -total {
@@ -632,11 +752,27 @@ func (a *apiServer) GetExperimentLabels(ctx context.Context,
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get the user: %s", err)
 	}
+	key, err := querycache.Key("GetExperimentLabels", curUser.ID, req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.m.queryCache.Get(key, func() (proto.Message, error) {
+		return a.getExperimentLabels(ctx, curUser, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*apiv1.GetExperimentLabelsResponse), nil
+}
 
+func (a *apiServer) getExperimentLabels(ctx context.Context, curUser *model.User,
+	req *apiv1.GetExperimentLabelsRequest,
+) (*apiv1.GetExperimentLabelsResponse, error) {
 	resp := &apiv1.GetExperimentLabelsResponse{}
 	var labels [][]string
+	var err error
 	query := db.Bun().NewSelect().
-		Table("experiments").
+		TableExpr("experiments AS e").
 		Model(&labels).
 		ColumnExpr("config->'labels' AS labels").
 		Distinct()
@@ -648,7 +784,7 @@ func (a *apiServer) GetExperimentLabels(ctx context.Context,
 			return nil, err
 		}
 
-		query = query.Where("project_id = ?", req.ProjectId)
+		query = query.Where("e.project_id = ?", req.ProjectId)
 	}
 
 	if query, err = expauth.AuthZProvider.Get().
@@ -687,6 +823,9 @@ func (a *apiServer) GetExperimentValidationHistory(
 		expauth.AuthZProvider.Get().CanGetExperimentArtifacts); err != nil {
 		return nil, err
 	}
+	if err := coldarchive.Rehydrate(ctx, int(req.ExperimentId)); err != nil {
+		return nil, err
+	}
 
 	var resp apiv1.GetExperimentValidationHistoryResponse
 	switch err := a.m.db.QueryProto("proto_experiment_validation_history", &resp, req.ExperimentId); {
@@ -750,6 +889,9 @@ func (a *apiServer) PreviewHPSearch(
 	if err = sc.AssertCurrent(); err != nil {
 		return nil, errors.Wrap(err, "invalid experiment configuration")
 	}
+	if err = check.Validate(sc); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid searcher configuration: %s", err)
+	}
 
 	sm := searcher.NewSearchMethod(sc)
 	s := searcher.NewSearcher(req.Seed, sm, hc)
@@ -798,6 +940,13 @@ func (a *apiServer) ActivateExperiment(
 		expauth.AuthZProvider.Get().CanEditExperiment); err != nil {
 		return nil, err
 	}
+	switch waiting, err := dependencies.Waiting(ctx, int(req.Id)); {
+	case err != nil:
+		return nil, err
+	case waiting:
+		return nil, status.Error(codes.FailedPrecondition,
+			"experiment is waiting for the experiments it depends on to complete")
+	}
 
 	addr := experimentsAddr.Child(req.Id)
 	switch err = a.ask(addr, req, &resp); {
@@ -975,6 +1124,23 @@ func (a *apiServer) PatchExperiment(
 		}
 	}
 
+	if req.Experiment.KeyValueLabels != nil {
+		if err = validateKeyValueLabels(req.Experiment.KeyValueLabels); err != nil {
+			return nil, err
+		}
+		labels, err := req.Experiment.KeyValueLabels.MarshalJSON()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to marshal key/value labels")
+		}
+		if _, err = db.Bun().NewUpdate().Table("experiments").
+			Set("key_value_labels = ?::jsonb", string(labels)).
+			Where("id = ?", exp.Id).
+			Exec(ctx); err != nil {
+			return nil, errors.Wrapf(err, "error updating experiment in database: %d", req.Experiment.Id)
+		}
+		exp.KeyValueLabels = req.Experiment.KeyValueLabels
+	}
+
 	if madeChanges {
 		type experimentPatch struct {
 			Labels      []string `json:"labels"`
@@ -1006,6 +1172,69 @@ func (a *apiServer) PatchExperiment(
 	return &apiv1.PatchExperimentResponse{Experiment: exp}, nil
 }
 
+// validateKeyValueLabels checks that key/value labels can be filtered on: keys must be non-empty
+// and not contain "=", and values must be strings, numbers, or booleans.
+func validateKeyValueLabels(labels *structpb.Struct) error {
+	for key, value := range labels.Fields {
+		if strings.TrimSpace(key) == "" || strings.Contains(key, "=") {
+			return status.Errorf(codes.InvalidArgument,
+				"label key %q must not be empty or contain '='", key)
+		}
+		switch value.Kind.(type) {
+		case *structpb.Value_StringValue, *structpb.Value_NumberValue, *structpb.Value_BoolValue:
+		default:
+			return status.Errorf(codes.InvalidArgument,
+				"value of label %q must be a string, number, or boolean", key)
+		}
+	}
+	return nil
+}
+
+// applyKeyValueLabelFilter limits a query over experiments to those with a key/value label. A
+// filter of the form "key" matches experiments with the label, and one of the form "key=value"
+// matches those where the label has that value. Both are answered by the GIN index on
+// key_value_labels.
+func applyKeyValueLabelFilter(query *bun.SelectQuery, filter string) *bun.SelectQuery {
+	key, value, ok := strings.Cut(filter, "=")
+	if !ok {
+		return query.Where("e.key_value_labels \\? ?", key)
+	}
+	var typed interface{} = value
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err == nil {
+		switch parsed.(type) {
+		case string, float64, bool:
+			typed = parsed
+		}
+	}
+	contains, _ := json.Marshal(map[string]interface{}{key: typed})
+	return query.Where("e.key_value_labels @> ?::jsonb", string(contains))
+}
+
+// applyConfigFilter limits a query to experiments whose configuration has a value, which the GIN
+// index on the configuration can answer.
+func applyConfigFilter(query *bun.SelectQuery, filter string) (*bun.SelectQuery, error) {
+	path, value, ok := strings.Cut(filter, "=")
+	keys := strings.Split(path, ".")
+	if !ok || slices.Contains(keys, "") {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"config filter %q must be of the form path=value", filter)
+	}
+	var contains interface{} = value
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err == nil {
+		switch parsed.(type) {
+		case string, float64, bool:
+			contains = parsed
+		}
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		contains = map[string]interface{}{keys[i]: contains}
+	}
+	b, _ := json.Marshal(contains)
+	return query.Where("e.config @> ?::jsonb", string(b)), nil
+}
+
 func (a *apiServer) GetExperimentCheckpoints(
 	ctx context.Context, req *apiv1.GetExperimentCheckpointsRequest,
 ) (*apiv1.GetExperimentCheckpointsResponse, error) {
@@ -1056,7 +1285,8 @@ func (a *apiServer) GetExperimentCheckpoints(
 		return true
 	})
 
-	sort.Slice(resp.Checkpoints, func(i, j int) bool {
+	// Sort stably, so that checkpoints that tie keep their order across pages.
+	sort.SliceStable(resp.Checkpoints, func(i, j int) bool {
 		ai, aj := resp.Checkpoints[i], resp.Checkpoints[j]
 		if useSearcherSortBy {
 			if order, done := protoless.CheckpointSearcherMetricNullsLast(ai, aj); done {
@@ -1087,7 +1317,8 @@ func (a *apiServer) GetExperimentCheckpoints(
 			return protoless.CheckpointTrialIDLess(ai, aj)
 		}
 	})
-	return resp, a.paginate(&resp.Pagination, &resp.Checkpoints, req.Offset, req.Limit)
+	return resp, a.paginateCheckpoints(
+		&resp.Pagination, &resp.Checkpoints, req.Offset, req.Limit, req.Cursor)
 }
 
 func (a *apiServer) CreateExperiment(
@@ -1133,14 +1364,27 @@ func (a *apiServer) CreateExperiment(
 
 	dbExp, p, validateOnly, taskSpec, err := a.m.parseCreateExperiment(&detParams, user)
 	if err != nil {
-		if _, ok := err.(ErrProjectNotFound); ok {
+		switch err.(type) {
+		case ErrProjectNotFound:
 			return nil, status.Errorf(codes.NotFound, err.Error())
+		case ErrResourcePoolNotBound:
+			return nil, status.Error(codes.PermissionDenied, err.Error())
 		}
 		return nil, status.Errorf(codes.InvalidArgument, "invalid experiment: %s", err)
 	}
 	if err = expauth.AuthZProvider.Get().CanCreateExperiment(ctx, *user, p, dbExp); err != nil {
 		return nil, status.Errorf(codes.PermissionDenied, err.Error())
 	}
+	// Warm starting reads the hyperparameters and metrics of the source experiment.
+	if id := dbExp.Config.Searcher().WarmStartExperimentID(); id != nil {
+		if _, err = a.getExperiment(ctx, *user, *id); err != nil {
+			return nil, err
+		}
+	}
+	deps, err := a.parseExperimentDependencies(ctx, req, dbExp)
+	if err != nil {
+		return nil, err
+	}
 
 	if validateOnly {
 		return &apiv1.CreateExperimentResponse{}, nil
@@ -1157,9 +1401,17 @@ func (a *apiServer) CreateExperiment(
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create experiment: %s", err)
 	}
+	e.logCtx = logger.MergeContexts(e.logCtx, logger.RequestFields(ctx))
+	// The experiment activates itself once its dependencies complete.
+	for i := range deps {
+		deps[i].ExperimentID = e.ID
+	}
+	if err = dependencies.Add(ctx, deps); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to add experiment dependencies: %s", err)
+	}
 	a.m.system.ActorOf(experimentsAddr.Child(e.ID), e)
 
-	if req.Activate {
+	if req.Activate && len(deps) == 0 {
 		_, err = a.ActivateExperiment(ctx, &apiv1.ActivateExperimentRequest{Id: int32(e.ID)})
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "failed to activate experiment: %s", err)
@@ -1203,6 +1455,9 @@ func (a *apiServer) MetricNames(req *apiv1.MetricNamesRequest,
 				false, expauth.AuthZProvider.Get().CanGetExperimentArtifacts); err != nil {
 				return err
 			}
+			if err := coldarchive.Rehydrate(resp.Context(), experimentID); err != nil {
+				return err
+			}
 
 			if timeSinceLastAuth == (time.Time{}) { // Initialzation.
 				var err error
@@ -1263,6 +1518,48 @@ func (a *apiServer) MetricNames(req *apiv1.MetricNamesRequest,
 	}
 }
 
+func (a *apiServer) StreamExperimentEvents(req *apiv1.StreamExperimentEventsRequest,
+	resp apiv1.Determined_StreamExperimentEventsServer,
+) error {
+	if len(req.ExperimentIds) == 0 {
+		return status.Error(codes.InvalidArgument, "at least one experiment ID is required")
+	}
+	checkAuth := func() error {
+		for _, id := range req.ExperimentIds {
+			if _, _, err := a.getExperimentAndCheckCanDoActions(resp.Context(), int(id),
+				false, expauth.AuthZProvider.Get().CanGetExperimentArtifacts); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := checkAuth(); err != nil {
+		return err
+	}
+
+	sub := stream.Subscribe(req.ExperimentIds)
+	defer sub.Close()
+	recheckAuth := time.NewTicker(recheckAuthPeriod)
+	defer recheckAuth.Stop()
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return status.Error(codes.ResourceExhausted, stream.ErrLagged.Error())
+			}
+			if err := resp.Send(event); err != nil {
+				return err
+			}
+		case <-recheckAuth.C:
+			if err := checkAuth(); err != nil {
+				return err
+			}
+		case <-resp.Context().Done():
+			return nil
+		}
+	}
+}
+
 // DEPRECATED -- do not use.
 func (a *apiServer) ExpCompareMetricNames(req *apiv1.ExpCompareMetricNamesRequest,
 	resp apiv1.Determined_ExpCompareMetricNamesServer,
@@ -1357,6 +1654,9 @@ func (a *apiServer) MetricBatches(req *apiv1.MetricBatchesRequest,
 				expauth.AuthZProvider.Get().CanGetExperimentArtifacts); err != nil {
 				return err
 			}
+			if err := coldarchive.Rehydrate(resp.Context(), experimentID); err != nil {
+				return err
+			}
 			timeSinceLastAuth = time.Now()
 		}
 
@@ -1448,6 +1748,9 @@ func (a *apiServer) TrialsSnapshot(req *apiv1.TrialsSnapshotRequest,
 				expauth.AuthZProvider.Get().CanGetExperimentArtifacts); err != nil {
 				return err
 			}
+			if err := coldarchive.Rehydrate(resp.Context(), experimentID); err != nil {
+				return err
+			}
 			timeSinceLastAuth = time.Now()
 		}
 
@@ -1694,6 +1997,9 @@ func (a *apiServer) TrialsSample(req *apiv1.TrialsSampleRequest,
 				expauth.AuthZProvider.Get().CanGetExperimentArtifacts); err != nil {
 				return err
 			}
+			if err := coldarchive.Rehydrate(resp.Context(), experimentID); err != nil {
+				return err
+			}
 
 			if timeSinceLastAuth == (time.Time{}) { // Initialzation.
 				var err error
@@ -1922,6 +2228,7 @@ func protoMetricHPI(metricHpi model.MetricHPImportance,
 		InProgress:         metricHpi.InProgress,
 		ExperimentProgress: metricHpi.ExperimentProgress,
 		HpImportance:       metricHpi.HpImportance,
+		Trials:             int32(metricHpi.Trials),
 	}
 }
 
@@ -2018,6 +2325,138 @@ func (a *apiServer) GetBestSearcherValidationMetric(
 	}, nil
 }
 
+// maxComparedExperiments bounds the number of experiments compared by one request.
+const maxComparedExperiments = 100
+
+func (a *apiServer) CompareExperiments(
+	ctx context.Context, req *apiv1.CompareExperimentsRequest,
+) (*apiv1.CompareExperimentsResponse, error) {
+	switch {
+	case len(req.Ids) == 0:
+		return nil, status.Error(codes.InvalidArgument, "at least one experiment id is required")
+	case len(req.Ids) > maxComparedExperiments:
+		return nil, status.Errorf(codes.InvalidArgument,
+			"at most %d experiments can be compared at once", maxComparedExperiments)
+	}
+	user, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get the user: %s", err)
+	}
+
+	resp := &apiv1.CompareExperimentsResponse{}
+	ids := make([]int, 0, len(req.Ids))
+	configs := make([]map[string]interface{}, 0, len(req.Ids))
+	for _, id := range req.Ids {
+		exp, err := a.getExperiment(ctx, *user, int(id))
+		if err != nil {
+			return nil, err
+		}
+
+		end := time.Now()
+		if exp.EndTime != nil {
+			end = exp.EndTime.AsTime()
+		}
+		config := exp.Config.AsMap()
+		searcherConfig, _ := config["searcher"].(map[string]interface{})
+		metric, _ := searcherConfig["metric"].(string)
+		smallerIsBetter, ok := searcherConfig["smaller_is_better"].(bool)
+		resp.Experiments = append(resp.Experiments, &apiv1.ComparedExperiment{
+			Experiment:      exp,
+			DurationSeconds: end.Sub(exp.StartTime.AsTime()).Seconds(),
+			SearcherMetric:  metric,
+			SmallerIsBetter: smallerIsBetter || !ok,
+		})
+		ids = append(ids, int(id))
+		configs = append(configs, config)
+	}
+
+	best, err := db.BestValidations(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	bestByExperiment := make(map[int]db.BestValidation, len(best))
+	for _, v := range best {
+		bestByExperiment[v.ExperimentID] = v
+	}
+	hparams := map[string]bool{}
+	for i, ce := range resp.Experiments {
+		v, ok := bestByExperiment[ids[i]]
+		if !ok {
+			continue
+		}
+		ce.BestTrialId = ptrs.Ptr(int32(v.TrialID))
+		ce.BestSearcherMetric = ptrs.Ptr(v.SearcherMetric)
+		ce.BestValidationMetrics = protoutils.ToStruct(v.Metrics)
+		flat := flattenSettings(v.HParams)
+		ce.Hyperparameters = protoutils.ToStruct(flat)
+		for name := range flat {
+			hparams[name] = true
+		}
+	}
+	resp.Hyperparameters = maps.Keys(hparams)
+	sort.Strings(resp.Hyperparameters)
+
+	resp.ConfigDifferences, err = configDifferences(configs)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// configDifferences returns the settings that are not the same in every configuration, with
+// their value in each.
+func configDifferences(configs []map[string]interface{}) ([]*apiv1.ConfigDifference, error) {
+	flat := make([]map[string]interface{}, 0, len(configs))
+	paths := map[string]bool{}
+	for _, config := range configs {
+		f := flattenSettings(config)
+		for path := range f {
+			paths[path] = true
+		}
+		flat = append(flat, f)
+	}
+	sortedPaths := maps.Keys(paths)
+	sort.Strings(sortedPaths)
+
+	diffs := []*apiv1.ConfigDifference{}
+	for _, path := range sortedPaths {
+		values := make([]*structpb.Value, 0, len(flat))
+		same := true
+		for i, f := range flat {
+			v, err := structpb.NewValue(f[path])
+			if err != nil {
+				return nil, errors.Wrapf(err, "converting config setting %s", path)
+			}
+			values = append(values, v)
+			if i > 0 && !reflect.DeepEqual(f[path], flat[0][path]) {
+				same = false
+			}
+		}
+		if !same {
+			diffs = append(diffs, &apiv1.ConfigDifference{Path: path, Values: values})
+		}
+	}
+	return diffs, nil
+}
+
+// flattenSettings flattens nested settings into a map keyed by their dotted paths. Lists are
+// kept as they are, since their elements do not line up between configurations.
+func flattenSettings(settings map[string]interface{}) map[string]interface{} {
+	flat := map[string]interface{}{}
+	var flatten func(prefix string, settings map[string]interface{})
+	flatten = func(prefix string, settings map[string]interface{}) {
+		for k, v := range settings {
+			if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+				flatten(prefix+k+".", nested)
+				continue
+			}
+			flat[prefix+k] = v
+		}
+	}
+	flatten("", settings)
+	return flat
+}
+
 func (a *apiServer) GetModelDef(
 	ctx context.Context, req *apiv1.GetModelDefRequest,
 ) (*apiv1.GetModelDefResponse, error) {
@@ -2045,46 +2484,82 @@ func (a *apiServer) MoveExperiment(
 	if err != nil {
 		return nil, err
 	}
-	if exp.Archived {
-		return nil, errors.Errorf("experiment (%v) is archived and cannot be moved.", exp.ID)
-	}
 
 	// check that user can view destination project
-	destProject, err := a.GetProjectByID(ctx, req.DestinationProjectId, curUser)
+	destProject, err := a.getMoveDestinationProject(ctx, req.DestinationProjectId, curUser)
+	if err != nil {
+		return nil, err
+	}
+	if err = a.checkExperimentMovable(ctx, exp, curUser, destProject); err != nil {
+		return nil, err
+	}
+
+	holder := &experimentv1.Experiment{}
+	err = a.m.db.QueryProto("move_experiment", holder, req.ExperimentId,
+		req.DestinationProjectId)
+
+	if holder.Id == 0 {
+		return nil, errors.Wrapf(err, "experiment (%d) does not exist or not moveable by this user",
+			req.ExperimentId)
+	}
+
+	return &apiv1.MoveExperimentResponse{},
+		errors.Wrapf(err, "error moving experiment (%d)", req.ExperimentId)
+}
+
+// getMoveDestinationProject returns the project experiments are being moved to, if the user can
+// view it and it can take new experiments.
+func (a *apiServer) getMoveDestinationProject(
+	ctx context.Context, projectID int32, curUser model.User,
+) (*projectv1.Project, error) {
+	destProject, err := a.GetProjectByID(ctx, projectID, curUser)
 	if err != nil {
 		return nil, err
 	}
 	if destProject.Archived {
 		return nil, errors.Errorf("project (%v) is archived and cannot add new experiments.",
-			req.DestinationProjectId)
+			projectID)
+	}
+	return destProject, nil
+}
+
+// checkExperimentMovable returns an error if the user can't move the experiment out of its
+// project and into the destination project.
+func (a *apiServer) checkExperimentMovable(
+	ctx context.Context, exp *model.Experiment, curUser model.User, destProject *projectv1.Project,
+) error {
+	if exp.Archived {
+		return errors.Errorf("experiment (%v) is archived and cannot be moved.", exp.ID)
 	}
 
 	// check that user can view source project
 	srcProject, err := a.GetProjectByID(ctx, int32(exp.ProjectID), curUser)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if srcProject.Archived {
-		return nil, errors.Errorf("project (%v) is archived and cannot have experiments moved from it.",
+		return errors.Errorf("project (%v) is archived and cannot have experiments moved from it.",
 			srcProject.Id)
 	}
 
 	if err = project.AuthZProvider.Get().CanMoveProjectExperiments(ctx, curUser, exp, srcProject,
 		destProject); err != nil {
-		return nil, status.Error(codes.PermissionDenied, err.Error())
+		return status.Error(codes.PermissionDenied, err.Error())
 	}
 
-	holder := &experimentv1.Experiment{}
-	err = a.m.db.QueryProto("move_experiment", holder, req.ExperimentId,
-		req.DestinationProjectId)
-
-	if holder.Id == 0 {
-		return nil, errors.Wrapf(err, "experiment (%d) does not exist or not moveable by this user",
-			req.ExperimentId)
+	if srcProject.WorkspaceId == destProject.WorkspaceId {
+		return nil
+	}
+	switch err := checkExperimentResourcePoolBound(
+		ctx, exp, int(destProject.WorkspaceId),
+	); err.(type) {
+	case nil:
+		return nil
+	case ErrResourcePoolNotBound:
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return err
 	}
-
-	return &apiv1.MoveExperimentResponse{},
-		errors.Wrapf(err, "error moving experiment (%d)", req.ExperimentId)
 }
 
 func (a *apiServer) GetModelDefTree(
@@ -0,0 +1,365 @@
+package internal
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	expauth "github.com/determined-ai/determined/master/internal/experiment"
+	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/projectv1"
+)
+
+// errExperimentChanged is reported for experiments that changed between being checked and being
+// updated by a bulk action.
+var errExperimentChanged = errors.New("experiment changed while the action was in progress")
+
+// bulkExperimentIDs returns the IDs of the experiments a bulk action applies to, which are either
+// given explicitly or selected by filters.
+func (a *apiServer) bulkExperimentIDs(
+	ctx context.Context, experimentIDs []int32, filters *apiv1.BulkExperimentFilters,
+) ([]int32, error) {
+	switch {
+	case len(experimentIDs) > 0 && filters != nil:
+		return nil, status.Error(codes.InvalidArgument,
+			"only one of experiment_ids and filters may be set")
+	case len(experimentIDs) > 0:
+		return experimentIDs, nil
+	case filters == nil:
+		return nil, status.Error(codes.InvalidArgument,
+			"one of experiment_ids and filters must be set")
+	}
+
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int32
+	query := db.Bun().NewSelect().
+		ModelTableExpr("experiments as e").
+		Column("e.id").
+		Join("JOIN users u ON e.owner_id = u.id").
+		Join("JOIN projects p ON e.project_id = p.id").
+		Join("JOIN workspaces w ON p.workspace_id = w.id").
+		Order("e.id")
+
+	if filters.Description != "" {
+		query = query.Where("e.config->>'description' ILIKE ('%%' || ? || '%%')",
+			filters.Description)
+	}
+	if filters.Name != "" {
+		query = query.Where("e.config->>'name' ILIKE ('%%' || ? || '%%')", filters.Name)
+	}
+	if len(filters.Labels) > 0 {
+		query = query.Where(`string_to_array(?, ',') <@ ARRAY(SELECT jsonb_array_elements_text(
+				CASE WHEN e.config->'labels'::text = 'null'
+				THEN NULL
+				ELSE e.config->'labels' END
+			))`, strings.Join(filters.Labels, ","))
+	}
+	if filters.Archived != nil {
+		query = query.Where("e.archived = ?", filters.Archived.Value)
+	}
+	if len(filters.States) > 0 {
+		var states []string
+		for _, state := range filters.States {
+			states = append(states, strings.TrimPrefix(state.String(), "STATE_"))
+		}
+		query = query.Where("e.state IN (?)", bun.In(states))
+	}
+	if len(filters.UserIds) > 0 {
+		query = query.Where("e.owner_id IN (?)", bun.In(filters.UserIds))
+	}
+	if len(filters.ExcludedExperimentIds) > 0 {
+		query = query.Where("e.id NOT IN (?)", bun.In(filters.ExcludedExperimentIds))
+	}
+
+	var proj *projectv1.Project
+	if filters.ProjectId != 0 {
+		if proj, err = a.GetProjectByID(ctx, filters.ProjectId, *curUser); err != nil {
+			return nil, err
+		}
+		query = query.Where("e.project_id = ?", filters.ProjectId)
+	}
+	if query, err = expauth.AuthZProvider.Get().
+		FilterExperimentsQuery(ctx, *curUser, proj, query); err != nil {
+		return nil, err
+	}
+
+	if err := query.Scan(ctx, &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// bulkExperimentResults collects the outcome of a bulk action on each experiment, in the order
+// the experiments were given.
+type bulkExperimentResults struct {
+	ids  []int32
+	errs map[int32]error
+}
+
+func newBulkExperimentResults(ids []int32) *bulkExperimentResults {
+	return &bulkExperimentResults{ids: ids, errs: make(map[int32]error)}
+}
+
+func (r *bulkExperimentResults) fail(id int32, err error) {
+	r.errs[id] = err
+}
+
+// checked returns the experiments that the action can be applied to, after running check on
+// each experiment that hasn't failed yet.
+func (r *bulkExperimentResults) checked(
+	check func(id int32) (*model.Experiment, error),
+) map[int32]*model.Experiment {
+	exps := make(map[int32]*model.Experiment)
+	for _, id := range r.ids {
+		if r.errs[id] != nil {
+			continue
+		}
+		exp, err := check(id)
+		if err != nil {
+			r.fail(id, err)
+			continue
+		}
+		exps[id] = exp
+	}
+	return exps
+}
+
+// updated marks the experiments that an update was attempted on but that aren't in updatedIDs
+// as having changed concurrently.
+func (r *bulkExperimentResults) updated(attempted map[int32]*model.Experiment, updatedIDs []int32) {
+	done := make(map[int32]bool, len(updatedIDs))
+	for _, id := range updatedIDs {
+		done[id] = true
+	}
+	for id := range attempted {
+		if !done[id] {
+			r.fail(id, errExperimentChanged)
+		}
+	}
+}
+
+func (r *bulkExperimentResults) proto() []*apiv1.ExperimentActionResult {
+	results := make([]*apiv1.ExperimentActionResult, 0, len(r.ids))
+	for _, id := range r.ids {
+		result := &apiv1.ExperimentActionResult{Id: id}
+		if err := r.errs[id]; err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func experimentIDsOf(exps map[int32]*model.Experiment) []int32 {
+	ids := make([]int32, 0, len(exps))
+	for id := range exps {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (a *apiServer) KillExperiments(
+	ctx context.Context, req *apiv1.KillExperimentsRequest,
+) (*apiv1.KillExperimentsResponse, error) {
+	ids, err := a.bulkExperimentIDs(ctx, req.ExperimentIds, req.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	results := newBulkExperimentResults(ids)
+	for _, id := range ids {
+		if _, err := a.KillExperiment(ctx, &apiv1.KillExperimentRequest{Id: id}); err != nil {
+			results.fail(id, err)
+		}
+	}
+	return &apiv1.KillExperimentsResponse{Results: results.proto()}, nil
+}
+
+func (a *apiServer) PauseExperiments(
+	ctx context.Context, req *apiv1.PauseExperimentsRequest,
+) (*apiv1.PauseExperimentsResponse, error) {
+	ids, err := a.bulkExperimentIDs(ctx, req.ExperimentIds, req.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	results := newBulkExperimentResults(ids)
+	for _, id := range ids {
+		if _, err := a.PauseExperiment(ctx, &apiv1.PauseExperimentRequest{Id: id}); err != nil {
+			results.fail(id, err)
+		}
+	}
+	return &apiv1.PauseExperimentsResponse{Results: results.proto()}, nil
+}
+
+func (a *apiServer) ArchiveExperiments(
+	ctx context.Context, req *apiv1.ArchiveExperimentsRequest,
+) (*apiv1.ArchiveExperimentsResponse, error) {
+	results, err := a.setExperimentsArchived(ctx, req.ExperimentIds, req.Filters, true)
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.ArchiveExperimentsResponse{Results: results.proto()}, nil
+}
+
+func (a *apiServer) UnarchiveExperiments(
+	ctx context.Context, req *apiv1.UnarchiveExperimentsRequest,
+) (*apiv1.UnarchiveExperimentsResponse, error) {
+	results, err := a.setExperimentsArchived(ctx, req.ExperimentIds, req.Filters, false)
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.UnarchiveExperimentsResponse{Results: results.proto()}, nil
+}
+
+func (a *apiServer) setExperimentsArchived(
+	ctx context.Context,
+	experimentIDs []int32,
+	filters *apiv1.BulkExperimentFilters,
+	archived bool,
+) (*bulkExperimentResults, error) {
+	ids, err := a.bulkExperimentIDs(ctx, experimentIDs, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	results := newBulkExperimentResults(ids)
+	exps := results.checked(func(id int32) (*model.Experiment, error) {
+		exp, _, err := a.getExperimentAndCheckCanDoActions(ctx, int(id), false,
+			expauth.AuthZProvider.Get().CanEditExperimentsMetadata)
+		if err != nil {
+			return nil, err
+		}
+		if !model.TerminalStates[exp.State] {
+			return nil, errors.Errorf("cannot change the archived status of experiment %v "+
+				"in non terminal state %v", id, exp.State)
+		}
+		return exp, nil
+	})
+	if len(exps) == 0 {
+		return results, nil
+	}
+
+	var terminalStates []model.State
+	for state := range model.TerminalStates {
+		terminalStates = append(terminalStates, state)
+	}
+	var updatedIDs []int32
+	if _, err := db.Bun().NewUpdate().Table("experiments").
+		Set("archived = ?", archived).
+		Where("id IN (?)", bun.In(experimentIDsOf(exps))).
+		Where("state IN (?)", bun.In(terminalStates)).
+		Returning("id").
+		Exec(ctx, &updatedIDs); err != nil {
+		return nil, errors.Wrap(err, "failed to update experiments")
+	}
+	results.updated(exps, updatedIDs)
+	return results, nil
+}
+
+func (a *apiServer) MoveExperiments(
+	ctx context.Context, req *apiv1.MoveExperimentsRequest,
+) (*apiv1.MoveExperimentsResponse, error) {
+	ids, err := a.bulkExperimentIDs(ctx, req.ExperimentIds, req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	destProject, err := a.getMoveDestinationProject(ctx, req.DestinationProjectId, *curUser)
+	if err != nil {
+		return nil, err
+	}
+
+	results := newBulkExperimentResults(ids)
+	exps := results.checked(func(id int32) (*model.Experiment, error) {
+		exp, _, err := a.getExperimentAndCheckCanDoActions(ctx, int(id), false)
+		if err != nil {
+			return nil, err
+		}
+		if err := a.checkExperimentMovable(ctx, exp, *curUser, destProject); err != nil {
+			return nil, err
+		}
+		return exp, nil
+	})
+	if len(exps) == 0 {
+		return &apiv1.MoveExperimentsResponse{Results: results.proto()}, nil
+	}
+
+	var updatedIDs []int32
+	if _, err := db.Bun().NewUpdate().Table("experiments").
+		Set("project_id = ?", req.DestinationProjectId).
+		Where("id IN (?)", bun.In(experimentIDsOf(exps))).
+		Where("NOT archived").
+		Returning("id").
+		Exec(ctx, &updatedIDs); err != nil {
+		return nil, errors.Wrap(err, "failed to move experiments")
+	}
+	results.updated(exps, updatedIDs)
+	return &apiv1.MoveExperimentsResponse{Results: results.proto()}, nil
+}
+
+func (a *apiServer) DeleteExperiments(
+	ctx context.Context, req *apiv1.DeleteExperimentsRequest,
+) (*apiv1.DeleteExperimentsResponse, error) {
+	ids, err := a.bulkExperimentIDs(ctx, req.ExperimentIds, req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := newBulkExperimentResults(ids)
+	exps := results.checked(func(id int32) (*model.Experiment, error) {
+		exp, _, err := a.getExperimentAndCheckCanDoActions(ctx, int(id), false,
+			expauth.AuthZProvider.Get().CanDeleteExperiment)
+		if err != nil {
+			return nil, err
+		}
+		if err := a.checkExperimentDeletable(exp); err != nil {
+			return nil, err
+		}
+		return exp, nil
+	})
+	if len(exps) == 0 {
+		return &apiv1.DeleteExperimentsResponse{Results: results.proto()}, nil
+	}
+
+	var deletableStates []model.State
+	for state := range model.ExperimentReverseTransitions[model.DeletingState] {
+		deletableStates = append(deletableStates, state)
+	}
+	var updatedIDs []int32
+	if _, err := db.Bun().NewUpdate().Table("experiments").
+		Set("state = ?", model.DeletingState).
+		Where("id IN (?)", bun.In(experimentIDsOf(exps))).
+		Where("state IN (?)", bun.In(deletableStates)).
+		Returning("id").
+		Exec(ctx, &updatedIDs); err != nil {
+		return nil, errors.Wrapf(err, "transitioning to %s", model.DeletingState)
+	}
+	results.updated(exps, updatedIDs)
+
+	for _, id := range updatedIDs {
+		exp := exps[id]
+		exp.State = model.DeletingState
+		recordExperimentDelete(ctx, curUser, exp.ID)
+		a.deleteExperimentInBackground(exp, curUser)
+	}
+	return &apiv1.DeleteExperimentsResponse{Results: results.proto()}, nil
+}
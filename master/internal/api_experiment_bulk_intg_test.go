@@ -0,0 +1,49 @@
+//go:build integration
+// +build integration
+
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+func TestArchiveExperiments(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t)
+	_, projectID := createProjectAndWorkspace(ctx, t, api)
+
+	paused := createTestExpWithProjectID(t, api, curUser, projectID)
+	completed := createTestExpWithProjectID(t, api, curUser, projectID)
+	completed.State = model.CompletedState
+	require.NoError(t, api.m.db.SaveExperimentState(completed))
+
+	_, err := api.ArchiveExperiments(ctx, &apiv1.ArchiveExperimentsRequest{})
+	require.Error(t, err)
+
+	resp, err := api.ArchiveExperiments(ctx, &apiv1.ArchiveExperimentsRequest{
+		Filters: &apiv1.BulkExperimentFilters{ProjectId: int32(projectID)},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+	for _, result := range resp.Results {
+		switch int(result.Id) {
+		case paused.ID:
+			require.NotEmpty(t, result.Error)
+		case completed.ID:
+			require.Empty(t, result.Error)
+		default:
+			t.Fatalf("unexpected experiment %d", result.Id)
+		}
+	}
+
+	exp, err := api.m.db.ExperimentWithoutConfigByID(completed.ID)
+	require.NoError(t, err)
+	require.True(t, exp.Archived)
+	exp, err = api.m.db.ExperimentWithoutConfigByID(paused.ID)
+	require.NoError(t, err)
+	require.False(t, exp.Archived)
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/dependencies"
+	expauth "github.com/determined-ai/determined/master/internal/experiment"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/experimentv1"
+)
+
+// parseExperimentDependencies checks that the experiment being created can depend on the
+// experiments of the request, and returns its dependencies without an experiment ID.
+func (a *apiServer) parseExperimentDependencies(
+	ctx context.Context, req *apiv1.CreateExperimentRequest, e *model.Experiment,
+) ([]dependencies.Dependency, error) {
+	if req.InheritCheckpointFrom != 0 && (e.Config.Searcher().SourceTrialID() != nil ||
+		e.Config.Searcher().SourceCheckpointUUID() != nil) {
+		return nil, status.Error(codes.InvalidArgument,
+			"an experiment cannot both inherit a checkpoint and set a source checkpoint")
+	}
+
+	deps := make([]dependencies.Dependency, 0, len(req.DependsOn))
+	seen := map[int32]bool{}
+	inheritFound := false
+	for _, id := range req.DependsOn {
+		if seen[id] {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"experiment %d is listed as a dependency more than once", id)
+		}
+		seen[id] = true
+
+		d := dependencies.Dependency{
+			DependsOnID:       int(id),
+			InheritCheckpoint: id == req.InheritCheckpointFrom,
+		}
+		actions := []func(context.Context, model.User, *model.Experiment) error{}
+		if d.InheritCheckpoint {
+			inheritFound = true
+			actions = append(actions, expauth.AuthZProvider.Get().CanGetExperimentArtifacts)
+		}
+		dep, _, err := a.getExperimentAndCheckCanDoActions(ctx, d.DependsOnID, false, actions...)
+		if err != nil {
+			return nil, err
+		}
+		if d.DependsOnState = dep.State; d.Failed() {
+			return nil, status.Errorf(codes.FailedPrecondition,
+				"cannot depend on experiment %d in state %s", dep.ID, dep.State)
+		}
+		deps = append(deps, d)
+	}
+	if req.InheritCheckpointFrom != 0 && !inheritFound {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"experiment %d to inherit a checkpoint from is not a dependency",
+			req.InheritCheckpointFrom)
+	}
+	return deps, nil
+}
+
+func (a *apiServer) GetExperimentDependencies(
+	ctx context.Context, req *apiv1.GetExperimentDependenciesRequest,
+) (*apiv1.GetExperimentDependenciesResponse, error) {
+	if _, _, err := a.getExperimentAndCheckCanDoActions(ctx, int(req.ExperimentId),
+		false); err != nil {
+		return nil, err
+	}
+	deps, err := dependencies.Of(ctx, int(req.ExperimentId))
+	if err != nil {
+		return nil, err
+	}
+	dependents, err := dependencies.Dependents(ctx, int(req.ExperimentId))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &apiv1.GetExperimentDependenciesResponse{
+		Dependencies: make([]*experimentv1.ExperimentDependency, 0, len(deps)),
+		Dependents:   make([]*experimentv1.ExperimentDependency, 0, len(dependents)),
+	}
+	for _, d := range deps {
+		resp.Dependencies = append(resp.Dependencies, d.Proto())
+	}
+	for _, d := range dependents {
+		resp.Dependents = append(resp.Dependents, d.Proto())
+	}
+	return resp, nil
+}
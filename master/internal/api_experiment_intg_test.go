@@ -36,7 +36,9 @@ import (
 	"github.com/determined-ai/determined/master/pkg/schemas"
 	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/commonv1"
 	"github.com/determined-ai/determined/proto/pkg/experimentv1"
+	"github.com/determined-ai/determined/proto/pkg/trialv1"
 	"github.com/determined-ai/determined/proto/pkg/userv1"
 	"github.com/determined-ai/determined/proto/pkg/utilv1"
 	"github.com/determined-ai/determined/proto/pkg/workspacev1"
@@ -376,6 +378,33 @@ func TestGetExperiments(t *testing.T) {
 		ProjectOwnerId: projResp.Project.UserId,
 	}
 
+	for _, e := range []struct {
+		expected *experimentv1.Experiment
+		labels   map[string]interface{}
+	}{
+		{exp0Expected, map[string]interface{}{"team": "vision", "epochs": 10}},
+		{exp1Expected, map[string]interface{}{"team": "nlp", "epochs": "10", "stage": "prod"}},
+	} {
+		e.expected.KeyValueLabels, err = structpb.NewStruct(e.labels)
+		require.NoError(t, err)
+		_, err = api.PatchExperiment(ctx, &apiv1.PatchExperimentRequest{
+			Experiment: &experimentv1.PatchExperiment{
+				Id:             e.expected.Id,
+				KeyValueLabels: e.expected.KeyValueLabels,
+			},
+		})
+		require.NoError(t, err)
+	}
+	_, err = api.PatchExperiment(ctx, &apiv1.PatchExperimentRequest{
+		Experiment: &experimentv1.PatchExperiment{
+			Id: exp0Expected.Id,
+			KeyValueLabels: &structpb.Struct{Fields: map[string]*structpb.Value{
+				"tags": structpb.NewListValue(&structpb.ListValue{}),
+			}},
+		},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
 	// Filtering tests.
 	getExperimentsTest(ctx, t, api, pid, &apiv1.GetExperimentsRequest{}, exp0Expected, exp1Expected)
 
@@ -400,6 +429,36 @@ func TestGetExperiments(t *testing.T) {
 	getExperimentsTest(ctx, t, api, pid,
 		&apiv1.GetExperimentsRequest{Labels: []string{"l0", "l1", "l3"}})
 
+	getExperimentsTest(ctx, t, api, pid,
+		&apiv1.GetExperimentsRequest{KeyValueLabels: []string{"team"}}, exp0Expected, exp1Expected)
+	getExperimentsTest(ctx, t, api, pid,
+		&apiv1.GetExperimentsRequest{KeyValueLabels: []string{"team=vision"}}, exp0Expected)
+	getExperimentsTest(ctx, t, api, pid,
+		&apiv1.GetExperimentsRequest{KeyValueLabels: []string{"epochs=10"}}, exp0Expected)
+	getExperimentsTest(ctx, t, api, pid,
+		&apiv1.GetExperimentsRequest{KeyValueLabels: []string{`epochs="10"`}}, exp1Expected)
+	getExperimentsTest(ctx, t, api, pid,
+		&apiv1.GetExperimentsRequest{KeyValueLabels: []string{"team=nlp", "epochs=10"}})
+	getExperimentsTest(ctx, t, api, pid,
+		&apiv1.GetExperimentsRequest{KeyValueLabels: []string{"owner"}})
+
+	getExperimentsTest(ctx, t, api, pid,
+		&apiv1.GetExperimentsRequest{Config: []string{"resources.resource_pool=kubernetes"}},
+		exp0Expected, exp1Expected)
+	getExperimentsTest(ctx, t, api, pid,
+		&apiv1.GetExperimentsRequest{Config: []string{"searcher.name=single", "name=longername"}},
+		exp1Expected)
+	getExperimentsTest(ctx, t, api, pid,
+		&apiv1.GetExperimentsRequest{Config: []string{`description="12345"`}}, exp0Expected)
+	getExperimentsTest(ctx, t, api, pid,
+		&apiv1.GetExperimentsRequest{Config: []string{"description=12345"}})
+	getExperimentsTest(ctx, t, api, pid,
+		&apiv1.GetExperimentsRequest{Config: []string{"resources.resource_pool=gpu"}})
+	for _, filter := range []string{"resources", "resources..resource_pool=gpu", "=gpu"} {
+		_, err = api.GetExperiments(ctx, &apiv1.GetExperimentsRequest{Config: []string{filter}})
+		require.Equal(t, codes.InvalidArgument, status.Code(err), filter)
+	}
+
 	getExperimentsTest(ctx, t, api, pid,
 		&apiv1.GetExperimentsRequest{Archived: wrapperspb.Bool(false)}, exp0Expected)
 	getExperimentsTest(ctx, t, api, pid,
@@ -460,13 +519,32 @@ func TestGetExperiments(t *testing.T) {
 			OrderBy: apiv1.OrderBy_ORDER_BY_DESC,
 		}, exp0Expected, exp1Expected)
 
+	getExperimentsTest(ctx, t, api, pid,
+		&apiv1.GetExperimentsRequest{SortByKeyValueLabel: "team"}, exp1Expected, exp0Expected)
+	getExperimentsTest(ctx, t, api, pid,
+		&apiv1.GetExperimentsRequest{
+			SortByKeyValueLabel: "team",
+			OrderBy:             apiv1.OrderBy_ORDER_BY_DESC,
+		}, exp0Expected, exp1Expected)
+	// Experiments without the label come last in either order.
+	getExperimentsTest(ctx, t, api, pid,
+		&apiv1.GetExperimentsRequest{SortByKeyValueLabel: "stage"}, exp1Expected, exp0Expected)
+	getExperimentsTest(ctx, t, api, pid,
+		&apiv1.GetExperimentsRequest{
+			SortByKeyValueLabel: "stage",
+			OrderBy:             apiv1.OrderBy_ORDER_BY_DESC,
+		}, exp1Expected, exp0Expected)
+
 	// Pagination tests.
 	// No experiments should be returned for Limit -2.
 	getExperimentsTest(ctx, t, api, pid, &apiv1.GetExperimentsRequest{Limit: -2})
 	getExperimentsPageTest(ctx, t, api, pid, &apiv1.GetExperimentsRequest{Offset: 1},
 		&apiv1.Pagination{Offset: 1, Limit: 0, StartIndex: 1, EndIndex: 2, Total: 2})
 	getExperimentsPageTest(ctx, t, api, pid, &apiv1.GetExperimentsRequest{Limit: 1},
-		&apiv1.Pagination{Offset: 0, Limit: 1, StartIndex: 0, EndIndex: 1, Total: 2})
+		&apiv1.Pagination{
+			Offset: 0, Limit: 1, StartIndex: 0, EndIndex: 1, Total: 2,
+			NextCursor: cursorForID(exp0Expected.Id),
+		})
 	getExperimentsPageTest(ctx, t, api, pid, &apiv1.GetExperimentsRequest{Limit: 1, Offset: 1},
 		&apiv1.Pagination{Offset: 1, Limit: 1, StartIndex: 1, EndIndex: 2, Total: 2})
 	getExperimentsPageTest(ctx, t, api, pid, &apiv1.GetExperimentsRequest{Offset: 2},
@@ -502,6 +580,11 @@ func getExperimentsTest(ctx context.Context, t *testing.T, api *apiServer, pid i
 		// Don't compare config.
 		res.Experiments[i].Config = nil
 
+		// Compare key/value labels as maps, since experiments without any have an empty struct.
+		require.Equal(t,
+			expected[i].KeyValueLabels.AsMap(), res.Experiments[i].KeyValueLabels.AsMap())
+		res.Experiments[i].KeyValueLabels = expected[i].KeyValueLabels
+
 		// Compare time seperatly due to millisecond precision in postgres.
 		require.WithinDuration(t,
 			expected[i].StartTime.AsTime(), res.Experiments[i].StartTime.AsTime(), time.Millisecond)
@@ -1034,3 +1117,51 @@ func TestAuthZGetExperimentAndCanDoActions(t *testing.T) {
 		require.Equal(t, expectedErr.Error(), curCase.IDToReqCall(exp.ID).Error())
 	}
 }
+
+func TestCompareExperiments(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t)
+	exp0 := createTestExp(t, api, curUser, "a")
+	exp1 := createTestExp(t, api, curUser, "b")
+
+	tr := db.RequireMockTrial(t, api.m.db, exp1)
+	for i, loss := range []float64{0.5, 0.2, 0.3} {
+		require.NoError(t, api.m.db.AddValidationMetrics(ctx, &trialv1.TrialMetrics{
+			TrialId:        int32(tr.ID),
+			StepsCompleted: int32(i + 1),
+			Metrics: &commonv1.Metrics{
+				AvgMetrics: &structpb.Struct{Fields: map[string]*structpb.Value{
+					"loss": structpb.NewNumberValue(loss),
+				}},
+			},
+		}))
+	}
+
+	resp, err := api.CompareExperiments(ctx, &apiv1.CompareExperimentsRequest{
+		Ids: []int32{int32(exp1.ID), int32(exp0.ID)},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Experiments, 2)
+
+	best := resp.Experiments[0]
+	require.Equal(t, int32(exp1.ID), best.Experiment.Id)
+	require.Equal(t, "loss", best.SearcherMetric)
+	require.True(t, best.SmallerIsBetter)
+	require.Equal(t, int32(tr.ID), *best.BestTrialId)
+	require.Equal(t, 0.2, *best.BestSearcherMetric)
+	require.Equal(t, float64(1), best.Hyperparameters.AsMap()["global_batch_size"])
+	require.Greater(t, best.DurationSeconds, float64(0))
+
+	require.Equal(t, int32(exp0.ID), resp.Experiments[1].Experiment.Id)
+	require.Nil(t, resp.Experiments[1].BestTrialId)
+	require.Equal(t, []string{"global_batch_size"}, resp.Hyperparameters)
+
+	require.Len(t, resp.ConfigDifferences, 1)
+	require.Equal(t, "labels", resp.ConfigDifferences[0].Path)
+	require.Equal(t, []interface{}{"b"}, resp.ConfigDifferences[0].Values[0].AsInterface())
+	require.Equal(t, []interface{}{"a"}, resp.ConfigDifferences[0].Values[1].AsInterface())
+
+	_, err = api.CompareExperiments(ctx, &apiv1.CompareExperimentsRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = api.CompareExperiments(ctx, &apiv1.CompareExperimentsRequest{Ids: []int32{-999}})
+	require.Equal(t, expNotFoundErr(-999).Error(), err.Error())
+}
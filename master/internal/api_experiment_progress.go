@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/experimentv1"
+)
+
+// experimentProgress returns how far along an experiment is and, while it is active, when it is
+// estimated to finish.
+func (a *apiServer) experimentProgress(
+	ctx context.Context, exp *experimentv1.Experiment,
+) (*apiv1.ExperimentProgress, error) {
+	now := time.Now().UTC()
+	window := progressWindow
+	if running := now.Sub(exp.StartTime.AsTime()); running < window {
+		window = running
+	}
+	total, recent, err := db.ExperimentBatches(ctx, int(exp.Id), now.Add(-window))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case exp.State == experimentv1.State_STATE_COMPLETED:
+		return &apiv1.ExperimentProgress{
+			Progress: 1, BatchesTrained: total, EstimatedEndTime: exp.EndTime,
+		}, nil
+	case !isActiveExperimentState(exp.State):
+		// Paused and stopped experiments make no progress, so they have no rate or estimate.
+		return &apiv1.ExperimentProgress{
+			Progress: exp.Progress.GetValue(), BatchesTrained: total,
+		}, nil
+	default:
+		return estimateExperimentProgress(exp.Progress.GetValue(), total, recent, window, now), nil
+	}
+}
+
+func (a *apiServer) GetExperimentProgress(
+	ctx context.Context, req *apiv1.GetExperimentProgressRequest,
+) (*apiv1.GetExperimentProgressResponse, error) {
+	user, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get the user: %s", err)
+	}
+	exp, err := a.getExperiment(ctx, *user, int(req.ExperimentId))
+	if err != nil {
+		return nil, err
+	}
+	progress, err := a.experimentProgress(ctx, exp)
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.GetExperimentProgressResponse{Progress: progress}, nil
+}
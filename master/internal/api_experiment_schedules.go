@@ -0,0 +1,315 @@
+package internal
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	expauth "github.com/determined-ai/determined/master/internal/experiment"
+	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/master/internal/schedules"
+	"github.com/determined-ai/determined/master/internal/user"
+	"github.com/determined-ai/determined/master/pkg/archive"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/master/pkg/tasks"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/projectv1"
+	"github.com/determined-ai/determined/proto/pkg/schedulev1"
+)
+
+// parseScheduledExperiment parses the experiment that a run of the schedule submits as its owner.
+func (m *Master) parseScheduledExperiment(s *schedules.Schedule, owner *model.User) (
+	*model.Experiment, *projectv1.Project, *tasks.TaskSpec, error,
+) {
+	modelDef, err := archive.FromTarGz(s.ModelDefinition)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "invalid model definition")
+	}
+	dbExp, p, _, taskSpec, err := m.parseCreateExperiment(&CreateExperimentParams{
+		ConfigBytes: s.Config,
+		Template:    s.Template,
+		ModelDef:    modelDef,
+		ProjectID:   &s.ProjectID,
+	}, owner)
+	return dbExp, p, taskSpec, err
+}
+
+// submitScheduledExperiment creates and activates the experiment of a run of the schedule as its
+// owner, who must still be allowed to.
+func (m *Master) submitScheduledExperiment(
+	ctx context.Context, s *schedules.Schedule,
+) (int, error) {
+	fullOwner, err := user.UserByID(s.OwnerID)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to find owner %d", s.OwnerID)
+	}
+	modelDef, err := archive.FromTarGz(s.ModelDefinition)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid model definition")
+	}
+	return m.submitExperimentAs(ctx, fullOwner, &CreateExperimentParams{
+		ConfigBytes: s.Config,
+		Template:    s.Template,
+		ModelDef:    modelDef,
+		ProjectID:   &s.ProjectID,
+	})
+}
+
+// submitExperimentAs creates and activates an experiment on behalf of a user, who must be active
+// and allowed to create it, for experiments that the master submits without a request.
+func (m *Master) submitExperimentAs(
+	ctx context.Context, fullOwner *model.FullUser, params *CreateExperimentParams,
+) (int, error) {
+	if !fullOwner.Active {
+		return 0, errors.Errorf("owner %s is not active", fullOwner.Username)
+	}
+	owner := fullOwner.ToUser()
+
+	dbExp, p, _, taskSpec, err := m.parseCreateExperiment(params, &owner)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid experiment")
+	}
+	if err = expauth.AuthZProvider.Get().CanCreateExperiment(ctx, owner, p, dbExp); err != nil {
+		return 0, err
+	}
+	if err = expauth.AuthZProvider.Get().CanEditExperiment(ctx, owner, dbExp); err != nil {
+		return 0, err
+	}
+
+	e, err := newExperiment(m, dbExp, taskSpec)
+	if err != nil {
+		return 0, errors.Wrap(err, "starting experiment")
+	}
+	addr := experimentsAddr.Child(e.ID)
+	m.system.ActorOf(addr, e)
+	resp := m.system.AskAt(addr, &apiv1.ActivateExperimentRequest{Id: int32(e.ID)})
+	if resp.Source() == nil {
+		return e.ID, errors.Errorf("experiment not found: %d", e.ID)
+	}
+	if _, notTimedOut := resp.GetOrTimeout(defaultAskTimeout); !notTimedOut {
+		return e.ID, errors.Errorf("attempt to activate experiment %d timed out", e.ID)
+	}
+	return e.ID, nil
+}
+
+// getSchedule returns a schedule that the user owns, or any schedule for admins.
+func (a *apiServer) getSchedule(ctx context.Context, id int32) (*schedules.Schedule, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s, err := schedules.Get(ctx, int(id))
+	if errors.Is(err, db.ErrNotFound) || (err == nil && !curUser.Admin && s.OwnerID != curUser.ID) {
+		return nil, status.Errorf(codes.NotFound, "experiment schedule %d not found", id)
+	} else if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (a *apiServer) GetExperimentSchedules(
+	ctx context.Context, req *apiv1.GetExperimentSchedulesRequest,
+) (*apiv1.GetExperimentSchedulesResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ownerID *model.UserID
+	if !curUser.Admin {
+		ownerID = &curUser.ID
+	}
+	ss, err := schedules.List(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.GetExperimentSchedulesResponse{
+		Schedules: make([]*schedulev1.ExperimentSchedule, 0, len(ss)),
+	}
+	for _, s := range ss {
+		resp.Schedules = append(resp.Schedules, s.Proto())
+	}
+	return resp, nil
+}
+
+func (a *apiServer) GetExperimentSchedule(
+	ctx context.Context, req *apiv1.GetExperimentScheduleRequest,
+) (*apiv1.GetExperimentScheduleResponse, error) {
+	s, err := a.getSchedule(ctx, req.ScheduleId)
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.GetExperimentScheduleResponse{Schedule: s.Proto()}, nil
+}
+
+func (a *apiServer) PostExperimentSchedule(
+	ctx context.Context, req *apiv1.PostExperimentScheduleRequest,
+) (*apiv1.PostExperimentScheduleResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Schedule == nil {
+		return nil, status.Error(codes.InvalidArgument, "schedule is required")
+	}
+	s := &schedules.Schedule{
+		Name:     req.Schedule.Name,
+		Cron:     req.Schedule.Cron,
+		TimeZone: req.Schedule.TimeZone,
+		Config:   req.Schedule.Config,
+		Template: req.Schedule.Template,
+		OwnerID:  curUser.ID,
+		Paused:   req.Schedule.Paused,
+	}
+	if err = s.Validate(time.Now()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid schedule: %s", err)
+	}
+
+	// Check that the experiment could be created now, which also reads the model definition from
+	// the parent experiment, if there is one.
+	params := CreateExperimentParams{
+		ConfigBytes:  s.Config,
+		Template:     s.Template,
+		ModelDef:     filesToArchive(req.ModelDefinition),
+		ValidateOnly: true,
+	}
+	if req.ParentId != 0 {
+		if _, _, err = a.getExperimentAndCheckCanDoActions(ctx, int(req.ParentId), false,
+			expauth.AuthZProvider.Get().CanForkFromExperiment); err != nil {
+			return nil, err
+		}
+		params.ParentID = ptrs.Ptr(int(req.ParentId))
+	}
+	if req.Schedule.ProjectId != 0 {
+		params.ProjectID = ptrs.Ptr(int(req.Schedule.ProjectId))
+	}
+	dbExp, p, _, _, err := a.m.parseCreateExperiment(&params, curUser)
+	if err != nil {
+		if _, ok := err.(ErrProjectNotFound); ok {
+			return nil, status.Errorf(codes.NotFound, err.Error())
+		}
+		return nil, status.Errorf(codes.InvalidArgument, "invalid experiment: %s", err)
+	}
+	if err = expauth.AuthZProvider.Get().CanCreateExperiment(ctx, *curUser, p, dbExp); err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, err.Error())
+	}
+	s.ProjectID = int(p.Id)
+	s.ModelDefinition = dbExp.ModelDefinitionBytes
+
+	switch err = schedules.Add(ctx, s); {
+	case errors.Is(err, db.ErrDuplicateRecord):
+		return nil, status.Errorf(codes.AlreadyExists,
+			"experiment schedule %s already exists", s.Name)
+	case err != nil:
+		return nil, err
+	}
+	return &apiv1.PostExperimentScheduleResponse{Schedule: s.Proto()}, nil
+}
+
+func (a *apiServer) PatchExperimentSchedule(
+	ctx context.Context, req *apiv1.PatchExperimentScheduleRequest,
+) (*apiv1.PatchExperimentScheduleResponse, error) {
+	s, err := a.getSchedule(ctx, req.ScheduleId)
+	if err != nil {
+		return nil, err
+	}
+	patch := req.Schedule
+	if patch == nil {
+		return &apiv1.PatchExperimentScheduleResponse{Schedule: s.Proto()}, nil
+	}
+
+	if patch.Name != nil {
+		s.Name = *patch.Name
+	}
+	if patch.Cron != nil {
+		s.Cron = *patch.Cron
+	}
+	if patch.TimeZone != nil {
+		s.TimeZone = *patch.TimeZone
+	}
+	if patch.Config != nil {
+		s.Config = *patch.Config
+	}
+	if patch.Template != nil {
+		s.Template = patch.Template
+		if *patch.Template == "" {
+			s.Template = nil
+		}
+	}
+	if patch.Paused != nil {
+		s.Paused = *patch.Paused
+	}
+	if err = s.Validate(time.Now()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid schedule: %s", err)
+	}
+	if patch.Config != nil || patch.Template != nil {
+		owner, err := user.UserByID(s.OwnerID)
+		if err != nil {
+			return nil, err
+		}
+		if _, _, _, err = a.m.parseScheduledExperiment(s, ptrs.Ptr(owner.ToUser())); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid experiment: %s", err)
+		}
+	}
+
+	switch err = schedules.Update(ctx, s); {
+	case errors.Is(err, db.ErrDuplicateRecord):
+		return nil, status.Errorf(codes.AlreadyExists,
+			"experiment schedule %s already exists", s.Name)
+	case errors.Is(err, db.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "experiment schedule %d not found",
+			req.ScheduleId)
+	case err != nil:
+		return nil, err
+	}
+	return &apiv1.PatchExperimentScheduleResponse{Schedule: s.Proto()}, nil
+}
+
+func (a *apiServer) DeleteExperimentSchedule(
+	ctx context.Context, req *apiv1.DeleteExperimentScheduleRequest,
+) (*apiv1.DeleteExperimentScheduleResponse, error) {
+	if _, err := a.getSchedule(ctx, req.ScheduleId); err != nil {
+		return nil, err
+	}
+	switch err := schedules.Delete(ctx, int(req.ScheduleId)); {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "experiment schedule %d not found",
+			req.ScheduleId)
+	case err != nil:
+		return nil, err
+	}
+	return &apiv1.DeleteExperimentScheduleResponse{}, nil
+}
+
+func (a *apiServer) GetExperimentScheduleRuns(
+	ctx context.Context, req *apiv1.GetExperimentScheduleRunsRequest,
+) (*apiv1.GetExperimentScheduleRunsResponse, error) {
+	if req.Offset < 0 || req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset and limit must not be negative")
+	}
+	if _, err := a.getSchedule(ctx, req.ScheduleId); err != nil {
+		return nil, err
+	}
+	runs, total, err := schedules.Runs(ctx, int(req.ScheduleId), int(req.Offset), int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.GetExperimentScheduleRunsResponse{
+		Runs: make([]*schedulev1.ExperimentScheduleRun, 0, len(runs)),
+		Pagination: &apiv1.Pagination{
+			Offset:     req.Offset,
+			Limit:      req.Limit,
+			StartIndex: req.Offset,
+			EndIndex:   req.Offset + int32(len(runs)),
+			Total:      int32(total),
+		},
+	}
+	for _, r := range runs {
+		resp.Runs = append(resp.Runs, r.Proto())
+	}
+	return resp, nil
+}
@@ -7,6 +7,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
 	"github.com/determined-ai/determined/proto/pkg/jobv1"
 )
@@ -45,10 +46,26 @@ func (a *apiServer) GetJobQueueStats(
 
 // UpdateJobQueue forwards the job queue message to the relevant resource pool.
 func (a *apiServer) UpdateJobQueue(
-	_ context.Context, req *apiv1.UpdateJobQueueRequest,
+	ctx context.Context, req *apiv1.UpdateJobQueueRequest,
 ) (resp *apiv1.UpdateJobQueueResponse, err error) {
 	resp = &apiv1.UpdateJobQueueResponse{}
 
+	for _, update := range req.Updates {
+		action, ok := update.GetAction().(*jobv1.QueueControl_ResourcePool)
+		if !ok || action.ResourcePool == "" {
+			continue
+		}
+		switch err := checkJobResourcePoolBound(
+			ctx, model.JobID(update.JobId), action.ResourcePool,
+		); err.(type) {
+		case nil:
+		case ErrResourcePoolNotBound:
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		default:
+			return nil, err
+		}
+	}
+
 	actorResp := a.m.system.AskAt(sproto.JobsActorAddr, req)
 	if err := actorResp.Error(); err != nil {
 		return nil, err
@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/internal/logretention"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/logretentionv1"
+)
+
+const defaultLogRetentionRunsLimit = 100
+
+func (a *apiServer) GetLogRetentionRuns(
+	ctx context.Context, req *apiv1.GetLogRetentionRunsRequest,
+) (*apiv1.GetLogRetentionRunsResponse, error) {
+	if err := userShouldBeAdmin(ctx, a); err != nil {
+		return nil, err
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultLogRetentionRunsLimit
+	}
+	runs, err := logretention.ListRuns(ctx, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching log retention runs")
+	}
+	resp := &apiv1.GetLogRetentionRunsResponse{Runs: []*logretentionv1.LogRetentionRun{}}
+	for _, r := range runs {
+		resp.Runs = append(resp.Runs, r.Proto())
+	}
+	return resp, nil
+}
@@ -0,0 +1,205 @@
+package internal
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/auditlog"
+	"github.com/determined-ai/determined/master/internal/backup"
+	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/master/internal/maintenance"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+func (a *apiServer) GetClusterMessage(
+	_ context.Context, _ *apiv1.GetClusterMessageRequest,
+) (*apiv1.GetClusterMessageResponse, error) {
+	resp := &apiv1.GetClusterMessageResponse{}
+	if c := maintenance.CurrentMessage(); c != nil {
+		resp.ClusterMessage = c.Proto()
+	}
+	return resp, nil
+}
+
+func (a *apiServer) SetClusterMessage(
+	ctx context.Context, req *apiv1.SetClusterMessageRequest,
+) (*apiv1.SetClusterMessageResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !curUser.Admin {
+		return nil, grpcutil.ErrPermissionDenied
+	}
+	if req.Message == "" {
+		return nil, status.Error(codes.InvalidArgument, "message must not be empty")
+	}
+
+	c := &maintenance.ClusterMessage{
+		Message:   req.Message,
+		StartTime: time.Now(),
+		CreatedBy: &curUser.ID,
+	}
+	if req.StartTime != nil {
+		if err := req.StartTime.CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		c.StartTime = req.StartTime.AsTime()
+	}
+	if req.EndTime != nil {
+		if err := req.EndTime.CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		endTime := req.EndTime.AsTime()
+		if !endTime.After(c.StartTime) {
+			return nil, status.Error(codes.InvalidArgument, "end_time must be after start_time")
+		}
+		c.EndTime = &endTime
+	}
+
+	err = maintenance.SetMessage(ctx, c)
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.ClusterMessageSet,
+		TargetType: "cluster_message",
+		Success:    err == nil,
+		Details:    auditlog.DetailsFromProto(req),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.SetClusterMessageResponse{}, nil
+}
+
+func (a *apiServer) DeleteClusterMessage(
+	ctx context.Context, _ *apiv1.DeleteClusterMessageRequest,
+) (*apiv1.DeleteClusterMessageResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !curUser.Admin {
+		return nil, grpcutil.ErrPermissionDenied
+	}
+
+	err = maintenance.ClearMessage(ctx)
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.ClusterMessageDelete,
+		TargetType: "cluster_message",
+		Success:    err == nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.DeleteClusterMessageResponse{}, nil
+}
+
+func (a *apiServer) GetMaintenanceMode(
+	ctx context.Context, _ *apiv1.GetMaintenanceModeRequest,
+) (*apiv1.GetMaintenanceModeResponse, error) {
+	m, err := maintenance.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.GetMaintenanceModeResponse{MaintenanceMode: m.Proto()}, nil
+}
+
+func (a *apiServer) SetMaintenanceMode(
+	ctx context.Context, req *apiv1.SetMaintenanceModeRequest,
+) (*apiv1.SetMaintenanceModeResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !curUser.Admin {
+		return nil, grpcutil.ErrPermissionDenied
+	}
+	if !req.Enabled && (req.Message != "" || req.DrainAgents) {
+		return nil, status.Error(codes.InvalidArgument,
+			"message and drain_agents only apply when enabling maintenance mode")
+	}
+	if !req.Enabled && backup.Restoring() {
+		return nil, status.Error(codes.FailedPrecondition,
+			"maintenance mode cannot be disabled while the database is being restored")
+	}
+	if req.DrainAgents && a.m.config.ResourceManager.AgentRM == nil {
+		return nil, status.Error(codes.InvalidArgument,
+			"drain_agents requires the agent resource manager")
+	}
+
+	m, err := maintenance.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = a.setMaintenanceMode(ctx, m, req, curUser.ID)
+	if err == nil {
+		m.Enabled = req.Enabled
+		m.UpdatedTime = time.Now()
+		m.UpdatedBy = &curUser.ID
+		err = maintenance.Set(ctx, m)
+	}
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.MaintenanceModeSet,
+		TargetType: "maintenance_mode",
+		Success:    err == nil,
+		Details:    auditlog.DetailsFromProto(req),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.SetMaintenanceModeResponse{MaintenanceMode: m.Proto()}, nil
+}
+
+// setMaintenanceMode drains or enables agents and sets or clears the cluster message as
+// maintenance mode is enabled or disabled, recording the drained agents in m.
+func (a *apiServer) setMaintenanceMode(
+	ctx context.Context, m *maintenance.Mode, req *apiv1.SetMaintenanceModeRequest,
+	userID model.UserID,
+) error {
+	if !req.Enabled {
+		// Agents that were removed in the meantime are no longer drained anyway.
+		for _, agentID := range m.DrainedAgents {
+			var resp *apiv1.EnableAgentResponse
+			if err := a.ask(agentAddr(agentID), &apiv1.EnableAgentRequest{AgentId: agentID},
+				&resp); err != nil {
+				log.WithError(err).Warnf("failed to enable agent %s after maintenance", agentID)
+			}
+		}
+		m.DrainedAgents = nil
+		return maintenance.ClearMessage(ctx)
+	}
+
+	if req.DrainAgents {
+		agents, err := a.m.rm.GetAgents(a.m.system, &apiv1.GetAgentsRequest{})
+		if err != nil {
+			return errors.Wrap(err, "error listing agents")
+		}
+		for _, agent := range agents.Agents {
+			// Agents that admins disabled or drained themselves are left for them to enable.
+			if !agent.Enabled || agent.Draining {
+				continue
+			}
+			// Agents that are reconnecting cannot be drained, but take no new work in the meantime.
+			var resp *apiv1.DisableAgentResponse
+			if err := a.ask(agentAddr(agent.Id),
+				&apiv1.DisableAgentRequest{AgentId: agent.Id, Drain: true}, &resp); err != nil {
+				log.WithError(err).Warnf("failed to drain agent %s for maintenance", agent.Id)
+				continue
+			}
+			m.DrainedAgents = append(m.DrainedAgents, agent.Id)
+		}
+	}
+	if req.Message != "" {
+		return maintenance.SetMessage(ctx, &maintenance.ClusterMessage{
+			Message:   req.Message,
+			StartTime: time.Now(),
+			CreatedBy: &userID,
+		})
+	}
+	return nil
+}
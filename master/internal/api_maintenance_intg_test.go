@@ -0,0 +1,86 @@
+//go:build integration
+// +build integration
+
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/master/internal/maintenance"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	api, _, ctx := setupAPITest(t)
+
+	resp, err := api.SetMaintenanceMode(ctx, &apiv1.SetMaintenanceModeRequest{
+		Enabled: true, Message: "Upgrading at 18:00 UTC",
+	})
+	require.NoError(t, err)
+	require.True(t, resp.MaintenanceMode.Enabled)
+	require.True(t, maintenance.Enabled())
+
+	master, err := api.GetMaster(ctx, &apiv1.GetMasterRequest{})
+	require.NoError(t, err)
+	require.True(t, master.MaintenanceMode)
+	require.Equal(t, "Upgrading at 18:00 UTC", master.ClusterMessage.Message)
+
+	// Maintenance mode and the message last across restarts of the master.
+	require.NoError(t, maintenance.Load(ctx))
+	require.True(t, maintenance.Enabled())
+	require.NotNil(t, maintenance.CurrentMessage())
+
+	_, err = api.SetMaintenanceMode(ctx, &apiv1.SetMaintenanceModeRequest{
+		Enabled: false, DrainAgents: true,
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	resp, err = api.SetMaintenanceMode(ctx, &apiv1.SetMaintenanceModeRequest{})
+	require.NoError(t, err)
+	require.False(t, resp.MaintenanceMode.Enabled)
+	require.False(t, maintenance.Enabled())
+	msg, err := api.GetClusterMessage(ctx, &apiv1.GetClusterMessageRequest{})
+	require.NoError(t, err)
+	require.Nil(t, msg.ClusterMessage)
+}
+
+func TestClusterMessage(t *testing.T) {
+	api, _, ctx := setupAPITest(t)
+
+	// A message is only shown from its start time.
+	_, err := api.SetClusterMessage(ctx, &apiv1.SetClusterMessageRequest{
+		Message:   "Maintenance tomorrow",
+		StartTime: timestamppb.New(time.Now().Add(time.Hour)),
+	})
+	require.NoError(t, err)
+	resp, err := api.GetClusterMessage(ctx, &apiv1.GetClusterMessageRequest{})
+	require.NoError(t, err)
+	require.Nil(t, resp.ClusterMessage)
+
+	_, err = api.SetClusterMessage(ctx, &apiv1.SetClusterMessageRequest{
+		Message: "Maintenance today",
+		EndTime: timestamppb.New(time.Now().Add(time.Hour)),
+	})
+	require.NoError(t, err)
+	resp, err = api.GetClusterMessage(ctx, &apiv1.GetClusterMessageRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "Maintenance today", resp.ClusterMessage.Message)
+
+	_, err = api.DeleteClusterMessage(ctx, &apiv1.DeleteClusterMessageRequest{})
+	require.NoError(t, err)
+	resp, err = api.GetClusterMessage(ctx, &apiv1.GetClusterMessageRequest{})
+	require.NoError(t, err)
+	require.Nil(t, resp.ClusterMessage)
+
+	_, err = api.SetClusterMessage(ctx, &apiv1.SetClusterMessageRequest{
+		Message: "Backwards",
+		EndTime: timestamppb.New(time.Now().Add(-time.Hour)),
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -6,13 +6,19 @@ import (
 
 	structpb "github.com/golang/protobuf/ptypes/struct"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/determined-ai/determined/master/internal/api"
 	"github.com/determined-ai/determined/master/internal/config"
 	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/master/internal/health"
+	"github.com/determined-ai/determined/master/internal/maintenance"
 	"github.com/determined-ai/determined/master/internal/plugin/sso"
+	"github.com/determined-ai/determined/master/internal/querycache"
 	"github.com/determined-ai/determined/master/pkg/logger"
 	"github.com/determined-ai/determined/master/version"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
@@ -40,8 +46,13 @@ func (a *apiServer) GetMaster(
 		RbacEnabled:       config.GetAuthZConfig().IsRBACUIEnabled(),
 		Product:           product,
 		FeatureSwitches:   a.m.config.FeatureSwitches,
+		MaintenanceMode:   maintenance.Enabled(),
+	}
+	if c := maintenance.CurrentMessage(); c != nil {
+		masterResp.ClusterMessage = c.Proto()
 	}
 	sso.AddProviderInfoToMasterResponse(a.m.config, masterResp)
+	masterResp.PasswordComplexityRequired = a.m.config.Security.PasswordPolicy.ComplexityRequired()
 
 	return masterResp, nil
 }
@@ -75,6 +86,44 @@ func (a *apiServer) GetMasterConfig(
 	}, err
 }
 
+func (a *apiServer) ReloadMasterConfig(
+	ctx context.Context, _ *apiv1.ReloadMasterConfigRequest,
+) (*apiv1.ReloadMasterConfigResponse, error) {
+	if err := userShouldBeAdmin(ctx, a); err != nil {
+		return nil, err
+	}
+
+	result, err := a.m.reloadConfig()
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &apiv1.ReloadMasterConfigResponse{
+		Reloaded:       append([]string{}, result.Reloaded...),
+		RequireRestart: append([]string{}, result.RequireRestart...),
+	}, nil
+}
+
+func (a *apiServer) RunPreflightChecks(
+	ctx context.Context, _ *apiv1.RunPreflightChecksRequest,
+) (*apiv1.RunPreflightChecksResponse, error) {
+	if err := userShouldBeAdmin(ctx, a); err != nil {
+		return nil, err
+	}
+
+	report := health.Run(ctx, time.Duration(a.m.config.Preflight.Timeout), a.m.preflightChecks())
+	resp := &apiv1.RunPreflightChecksResponse{Passed: report.Status == health.StatusUp}
+	for _, r := range report.Checks {
+		resp.Checks = append(resp.Checks, &apiv1.PreflightCheck{
+			Name:      r.Name,
+			Kind:      r.Kind,
+			Status:    string(r.Status),
+			Error:     r.Error,
+			LatencyMs: r.LatencyMs,
+		})
+	}
+	return resp, nil
+}
+
 func (a *apiServer) MasterLogs(
 	req *apiv1.MasterLogsRequest, resp apiv1.Determined_MasterLogsServer,
 ) error {
@@ -155,5 +204,16 @@ func (a *apiServer) ResourceAllocationAggregated(
 	_ context.Context,
 	req *apiv1.ResourceAllocationAggregatedRequest,
 ) (*apiv1.ResourceAllocationAggregatedResponse, error) {
-	return a.m.fetchAggregatedResourceAllocation(req)
+	// Usage is the same for every user, so all of them share the cached result.
+	key, err := querycache.Key("ResourceAllocationAggregated", 0, req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.m.queryCache.Get(key, func() (proto.Message, error) {
+		return a.m.fetchAggregatedResourceAllocation(req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*apiv1.ResourceAllocationAggregatedResponse), nil
 }
@@ -0,0 +1,46 @@
+//go:build integration
+// +build integration
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/health"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+func TestRunPreflightChecks(t *testing.T) {
+	api, _, ctx := setupAPITest(t)
+	dir := t.TempDir()
+	api.m.config.Preflight = config.DefaultPreflightConfig()
+	api.m.logBackendCheck = health.Check{Kind: "postgres", Run: pingTaskLogs}
+	api.m.config.CheckpointStorage = expconf.CheckpointStorageConfig{
+		RawSharedFSConfig: &expconf.SharedFSConfig{RawHostPath: ptrs.Ptr(dir)},
+	}
+
+	resp, err := api.RunPreflightChecks(ctx, &apiv1.RunPreflightChecksRequest{})
+	require.NoError(t, err)
+	require.True(t, resp.Passed)
+	require.Len(t, resp.Checks, 2)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries, "the test file should be deleted")
+
+	// Storage that the master uses itself must be writable.
+	api.m.config.Backup.Storage = &expconf.CheckpointStorageConfig{
+		RawSharedFSConfig: &expconf.SharedFSConfig{RawHostPath: ptrs.Ptr(filepath.Join(dir, "x"))},
+	}
+	resp, err = api.RunPreflightChecks(ctx, &apiv1.RunPreflightChecksRequest{})
+	require.NoError(t, err)
+	require.False(t, resp.Passed)
+	require.Equal(t, "backup.storage", resp.Checks[2].Name)
+	require.Equal(t, string(health.StatusDown), resp.Checks[2].Status)
+}
@@ -0,0 +1,201 @@
+package internal
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/auditlog"
+	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/master/internal/user"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+// mfaError converts errors about the enrollment of users into statuses.
+func mfaError(err error) error {
+	switch {
+	case errors.Is(err, user.ErrInvalidMFACode):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, user.ErrMFAEnrolled), errors.Is(err, user.ErrMFANotEnrolled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return err
+	}
+}
+
+// checkMFAUser returns an error if a user cannot enroll in multi-factor authentication, which is
+// only for users who log in with a password in the cluster.
+func checkMFAUser(u *model.User) error {
+	if user.IsSSOUser(*u) {
+		return status.Error(codes.FailedPrecondition,
+			"MFA is only for users who log in with a password in the cluster")
+	}
+	return nil
+}
+
+func (a *apiServer) GetUserMFA(
+	ctx context.Context, req *apiv1.GetUserMFARequest,
+) (*apiv1.GetUserMFAResponse, error) {
+	_, userID, err := targetUser(ctx, req.UserId, "the MFA settings")
+	if err != nil {
+		return nil, err
+	}
+	m, err := user.UserMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.GetUserMFAResponse{
+		Mfa: m.Proto(a.m.config.Security.MFA.Required),
+	}, nil
+}
+
+func (a *apiServer) PostMFAEnrollment(
+	ctx context.Context, _ *apiv1.PostMFAEnrollmentRequest,
+) (*apiv1.PostMFAEnrollmentResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMFAUser(curUser); err != nil {
+		return nil, err
+	}
+	secret, uri, err := user.BeginMFAEnrollment(ctx, curUser)
+	if err != nil {
+		return nil, mfaError(err)
+	}
+	return &apiv1.PostMFAEnrollmentResponse{Secret: secret, Uri: uri}, nil
+}
+
+func (a *apiServer) ConfirmMFAEnrollment(
+	ctx context.Context, req *apiv1.ConfirmMFAEnrollmentRequest,
+) (_ *apiv1.ConfirmMFAEnrollmentResponse, err error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		auditlog.Record(ctx, curUser, auditlog.Entry{
+			Action:     auditlog.MFAEnroll,
+			TargetType: "user",
+			TargetID:   strconv.Itoa(int(curUser.ID)),
+			Success:    err == nil,
+		})
+	}()
+
+	recoveryCodes, err := user.ConfirmMFAEnrollment(ctx, curUser.ID, req.Code)
+	if err != nil {
+		return nil, mfaError(err)
+	}
+	return &apiv1.ConfirmMFAEnrollmentResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (a *apiServer) PostMFARecoveryCodes(
+	ctx context.Context, req *apiv1.PostMFARecoveryCodesRequest,
+) (_ *apiv1.PostMFARecoveryCodesResponse, err error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		auditlog.Record(ctx, curUser, auditlog.Entry{
+			Action:     auditlog.MFARecoveryCodesSet,
+			TargetType: "user",
+			TargetID:   strconv.Itoa(int(curUser.ID)),
+			Success:    err == nil,
+		})
+	}()
+
+	m, err := user.UserMFA(ctx, curUser.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := user.VerifyMFACode(ctx, m, req.Code); err != nil {
+		return nil, mfaError(err)
+	}
+	recoveryCodes, err := user.ReplaceRecoveryCodes(ctx, curUser.ID)
+	if err != nil {
+		return nil, mfaError(err)
+	}
+	return &apiv1.PostMFARecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (a *apiServer) DeleteUserMFA(
+	ctx context.Context, req *apiv1.DeleteUserMFARequest,
+) (_ *apiv1.DeleteUserMFAResponse, err error) {
+	curUser, userID, err := targetUser(ctx, req.UserId, "the MFA settings")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		auditlog.Record(ctx, curUser, auditlog.Entry{
+			Action:     auditlog.MFARemove,
+			TargetType: "user",
+			TargetID:   strconv.Itoa(int(userID)),
+			Success:    err == nil,
+		})
+	}()
+
+	m, err := user.UserMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if userID == curUser.ID {
+		// Admins reset the enrollment of users who lost their authenticator app and recovery
+		// codes; users who still have them can only remove an enrollment they do not need.
+		if user.MFARequired(m) {
+			return nil, status.Error(codes.FailedPrecondition,
+				"MFA is required and cannot be removed; ask an admin to reset it")
+		}
+		if err := user.VerifyMFACode(ctx, m, req.Code); err != nil {
+			return nil, mfaError(err)
+		}
+	}
+	if err := user.DeleteMFA(ctx, userID); err != nil {
+		return nil, err
+	}
+	return &apiv1.DeleteUserMFAResponse{}, nil
+}
+
+func (a *apiServer) PutUserMFARequired(
+	ctx context.Context, req *apiv1.PutUserMFARequiredRequest,
+) (_ *apiv1.PutUserMFARequiredResponse, err error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !curUser.Admin {
+		return nil, grpcutil.ErrPermissionDenied
+	}
+	fullUser, err := getFullModelUser(model.UserID(req.UserId))
+	if err != nil {
+		return nil, err
+	}
+	u := fullUser.ToUser()
+	if err := checkMFAUser(&u); err != nil {
+		return nil, err
+	}
+	defer func() {
+		auditlog.Record(ctx, curUser, auditlog.Entry{
+			Action:     auditlog.MFARequiredSet,
+			TargetType: "user",
+			TargetID:   strconv.Itoa(int(u.ID)),
+			Success:    err == nil,
+			Details:    map[string]interface{}{"required": req.Required},
+		})
+	}()
+
+	if err := user.SetMFARequired(ctx, u.ID, req.Required); err != nil {
+		return nil, err
+	}
+	m, err := user.UserMFA(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.PutUserMFARequiredResponse{
+		Mfa: m.Proto(a.m.config.Security.MFA.Required),
+	}, nil
+}
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+func (a *apiServer) GetMigrationReport(
+	ctx context.Context, req *apiv1.GetMigrationReportRequest,
+) (*apiv1.GetMigrationReportResponse, error) {
+	if err := userShouldBeAdmin(ctx, a); err != nil {
+		return nil, err
+	}
+
+	report, err := db.CheckMigrations(ctx, a.m.config.DB.Migrations)
+	if err != nil {
+		return nil, errors.Wrap(err, "error checking migrations")
+	}
+
+	resp := &apiv1.GetMigrationReportResponse{
+		CurrentVersion:  report.CurrentVersion,
+		Pending:         []*apiv1.PendingMigration{},
+		SnapshotVersion: report.SnapshotVersion,
+		Drift:           []*apiv1.SchemaDrift{},
+	}
+	if !report.SnapshotTime.IsZero() {
+		resp.SnapshotTime = timestamppb.New(report.SnapshotTime)
+	}
+	for _, m := range report.Pending {
+		pm := &apiv1.PendingMigration{
+			Version:       m.Version,
+			Name:          m.Name,
+			Transactional: m.Transactional,
+			Tables:        []*apiv1.MigrationTableLock{},
+		}
+		for _, t := range m.Tables {
+			pm.Tables = append(pm.Tables, &apiv1.MigrationTableLock{
+				Table:         t.Table,
+				Lock:          t.Lock,
+				BlocksReads:   t.BlocksReads,
+				BlocksWrites:  t.BlocksWrites,
+				EstimatedRows: t.EstimatedRows,
+				SizeBytes:     t.SizeBytes,
+				Large:         t.Large(),
+			})
+		}
+		resp.Pending = append(resp.Pending, pm)
+	}
+	for _, d := range report.Drift {
+		resp.Drift = append(resp.Drift, &apiv1.SchemaDrift{
+			Object:   d.Object,
+			Expected: d.Expected,
+			Actual:   d.Actual,
+		})
+	}
+	return resp, nil
+}
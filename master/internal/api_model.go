@@ -7,17 +7,27 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/determined-ai/determined/master/internal/db"
+	expauth "github.com/determined-ai/determined/master/internal/experiment"
+	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/master/internal/workspace"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/protoutils"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
 	"github.com/determined-ai/determined/proto/pkg/checkpointv1"
+	"github.com/determined-ai/determined/proto/pkg/experimentv1"
 	"github.com/determined-ai/determined/proto/pkg/modelv1"
+	"github.com/determined-ai/determined/proto/pkg/userv1"
 
 	structpb "github.com/golang/protobuf/ptypes/struct"
 )
@@ -62,6 +72,96 @@ func (a *apiServer) ModelVersionFromID(modelIdentifier string,
 	}
 }
 
+// modelVersionWithAuthZ returns a model version after checking that the user can do an action on
+// the experiment that it was registered from.
+func (a *apiServer) modelVersionWithAuthZ(
+	ctx context.Context, modelIdentifier string, versionID int32,
+	action func(context.Context, model.User, *model.Experiment) error,
+) (*modelv1.ModelVersion, error) {
+	mv, err := a.ModelVersionFromID(modelIdentifier, versionID)
+	if err != nil {
+		return nil, err
+	}
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = a.canDoActionOnModelVersion(ctx, *curUser, mv, action); err != nil {
+		return nil, err
+	}
+	return mv, nil
+}
+
+// canDoActionOnModelVersion returns an error unless the user can do an action on the experiment
+// that a model version was registered from. Models belong to no workspace, so access to their
+// versions follows that of the experiments they come from, and versions of experiments the user
+// cannot view are not found.
+func (a *apiServer) canDoActionOnModelVersion(
+	ctx context.Context, curUser model.User, mv *modelv1.ModelVersion,
+	action func(context.Context, model.User, *model.Experiment) error,
+) error {
+	expID := mv.Checkpoint.GetTraining().GetExperimentId()
+	if expID == nil {
+		return nil // The checkpoint was not reported by an experiment.
+	}
+	e, err := a.m.db.ExperimentWithoutConfigByID(int(expID.Value))
+	if err != nil {
+		return err
+	}
+	if ok, err := expauth.AuthZProvider.Get().CanGetExperiment(ctx, curUser, e); err != nil {
+		return err
+	} else if !ok {
+		return status.Errorf(codes.NotFound,
+			"version %v for model %q not found", mv.Id, mv.Model.Name)
+	}
+	if err := action(ctx, curUser, e); err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return nil
+}
+
+// canDoActionOnModel returns an error unless the user can do an action on the experiments that
+// every version of a model was registered from. Models belong to no workspace, so a model with
+// no versions from experiments has nothing to be checked against, and only its owner and admins
+// may act on it.
+func (a *apiServer) canDoActionOnModel(
+	ctx context.Context, curUser model.User, m *modelv1.Model,
+	action func(context.Context, model.User, *model.Experiment) error,
+) error {
+	var expIDs []int
+	if err := db.Bun().NewSelect().
+		ColumnExpr("DISTINCT c.experiment_id").
+		TableExpr("model_versions AS mv").
+		Join("JOIN checkpoints_view AS c ON c.uuid = mv.checkpoint_uuid").
+		Where("mv.model_id = ?", m.Id).
+		Where("c.experiment_id IS NOT NULL").
+		Scan(ctx, &expIDs); err != nil {
+		return errors.Wrapf(err, "error finding the experiments of model %q", m.Name)
+	}
+	if len(expIDs) == 0 && !curUser.Admin && model.UserID(m.UserId) != curUser.ID {
+		return status.Errorf(codes.PermissionDenied,
+			"only the owner of model %q or an admin may change it while it has no versions", m.Name)
+	}
+	for _, id := range expIDs {
+		e, err := a.m.db.ExperimentWithoutConfigByID(id)
+		if err != nil {
+			return err
+		}
+		ok, err := expauth.AuthZProvider.Get().CanGetExperiment(ctx, curUser, e)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return status.Errorf(codes.PermissionDenied,
+				"model %q has versions from experiments you cannot view", m.Name)
+		}
+		if err = action(ctx, curUser, e); err != nil {
+			return status.Error(codes.PermissionDenied, err.Error())
+		}
+	}
+	return nil
+}
+
 func (a *apiServer) GetModel(
 	_ context.Context, req *apiv1.GetModelRequest,
 ) (*apiv1.GetModelResponse, error) {
@@ -208,6 +308,14 @@ func (a *apiServer) PatchModel(
 	if err != nil {
 		return nil, err
 	}
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = a.canDoActionOnModel(ctx, *curUser, currModel,
+		expauth.AuthZProvider.Get().CanEditExperiment); err != nil {
+		return nil, err
+	}
 
 	if currModel.Archived {
 		return nil, errors.Errorf("model %q is archived and cannot have attributes updated.",
@@ -274,6 +382,34 @@ func (a *apiServer) PatchModel(
 		currLabels = reqLabels
 	}
 
+	currApprovers := strings.Join(currModel.StageApprovers, ",")
+	if req.Model.StageApprovers != nil {
+		var reqApproverList []string
+		for _, el := range req.Model.StageApprovers.Values {
+			if _, ok := el.GetKind().(*structpb.Value_StringValue); ok {
+				reqApproverList = append(reqApproverList, el.GetStringValue())
+			}
+		}
+		unknown, err2 := db.UnknownUsernames(ctx, reqApproverList)
+		if err2 != nil {
+			return nil, errors.Wrap(err2, "error checking stage approvers")
+		}
+		if len(unknown) > 0 {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"stage approvers %v are not users", unknown)
+		}
+		reqApprovers := strings.Join(reqApproverList, ",")
+		if currApprovers != reqApprovers {
+			if err2 = canSetStageApprovers(ctx, *curUser, currModel); err2 != nil {
+				return nil, err2
+			}
+			log.Infof("model %q stage approvers changing from %q to %q",
+				currModel.Name, currModel.StageApprovers, reqApprovers)
+			madeChanges = true
+		}
+		currApprovers = reqApprovers
+	}
+
 	if !madeChanges {
 		return &apiv1.PatchModelResponse{Model: currModel}, nil
 	}
@@ -281,7 +417,7 @@ func (a *apiServer) PatchModel(
 	finalModel := &modelv1.Model{}
 	err = a.m.db.QueryProto(
 		"update_model", finalModel, currModel.Id, currModel.Name, currModel.Description,
-		currModel.Notes, currMeta, currLabels)
+		currModel.Notes, currMeta, currLabels, currApprovers)
 
 	return &apiv1.PatchModelResponse{Model: finalModel},
 		errors.Wrapf(err, "error updating model %q in database", currModel.Name)
@@ -294,6 +430,14 @@ func (a *apiServer) ArchiveModel(
 	if err != nil {
 		return nil, err
 	}
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = a.canDoActionOnModel(ctx, *curUser, currModel,
+		expauth.AuthZProvider.Get().CanEditExperiment); err != nil {
+		return nil, err
+	}
 
 	holder := &modelv1.Model{}
 	err = a.m.db.QueryProto("archive_model", holder, currModel.Name)
@@ -314,6 +458,14 @@ func (a *apiServer) UnarchiveModel(
 	if err != nil {
 		return nil, err
 	}
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = a.canDoActionOnModel(ctx, *curUser, currModel,
+		expauth.AuthZProvider.Get().CanEditExperiment); err != nil {
+		return nil, err
+	}
 
 	holder := &modelv1.Model{}
 	err = a.m.db.QueryProto("unarchive_model", holder, currModel.Name)
@@ -331,19 +483,21 @@ func (a *apiServer) DeleteModel(
 	ctx context.Context, req *apiv1.DeleteModelRequest) (*apiv1.DeleteModelResponse,
 	error,
 ) {
-	user, err := a.CurrentUser(ctx, &apiv1.CurrentUserRequest{})
+	currModel, err := a.ModelFromIdentifier(req.ModelName)
 	if err != nil {
 		return nil, err
 	}
-
-	currModel, err := a.ModelFromIdentifier(req.ModelName)
+	curUser, _, err := grpcutil.GetUser(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if err = a.canDoActionOnModel(ctx, *curUser, currModel,
+		expauth.AuthZProvider.Get().CanEditExperiment); err != nil {
+		return nil, err
+	}
 
 	holder := &modelv1.Model{}
-	err = a.m.db.QueryProto("delete_model", holder, currModel.Name, user.User.Id,
-		user.User.Admin)
+	err = a.m.db.QueryProto("delete_model", holder, currModel.Name, curUser.ID, curUser.Admin)
 
 	if holder.Id == 0 {
 		return nil, errors.Wrapf(err, "model %q does not exist or not deletable by this user",
@@ -357,7 +511,8 @@ func (a *apiServer) DeleteModel(
 func (a *apiServer) GetModelVersion(
 	ctx context.Context, req *apiv1.GetModelVersionRequest,
 ) (*apiv1.GetModelVersionResponse, error) {
-	mv, err := a.ModelVersionFromID(req.ModelName, req.ModelVersion)
+	mv, err := a.modelVersionWithAuthZ(ctx, req.ModelName, req.ModelVersion,
+		expauth.AuthZProvider.Get().CanGetExperimentArtifacts)
 	if err != nil {
 		return nil, err
 	}
@@ -375,10 +530,26 @@ func (a *apiServer) GetModelVersions(
 	}
 
 	resp := &apiv1.GetModelVersionsResponse{Model: parentModel}
-	err = a.m.db.QueryProto("get_model_versions", &resp.ModelVersions, parentModel.Id)
+	var versions []*modelv1.ModelVersion
+	err = a.m.db.QueryProto("get_model_versions", &versions, parentModel.Id)
 	if err != nil {
 		return nil, err
 	}
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, mv := range versions {
+		switch err := a.canDoActionOnModelVersion(ctx, *curUser, mv,
+			expauth.AuthZProvider.Get().CanGetExperimentArtifacts); status.Code(err) {
+		case codes.OK:
+			resp.ModelVersions = append(resp.ModelVersions, mv)
+		case codes.NotFound, codes.PermissionDenied:
+			// The version is left out, as if it did not exist.
+		default:
+			return nil, err
+		}
+	}
 
 	a.sort(resp.ModelVersions, req.OrderBy, req.SortBy, apiv1.GetModelVersionsRequest_SORT_BY_VERSION)
 	return resp, a.paginate(&resp.Pagination, &resp.ModelVersions, req.Offset, req.Limit)
@@ -416,6 +587,15 @@ func (a *apiServer) PostModelVersion(
 		)
 	}
 
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = a.m.canDoActionOnCheckpoint(ctx, *curUser, req.CheckpointUuid,
+		expauth.AuthZProvider.Get().CanGetExperimentArtifacts); err != nil {
+		return nil, err
+	}
+
 	user, err := a.CurrentUser(ctx, &apiv1.CurrentUserRequest{})
 	if err != nil {
 		return nil, err
@@ -452,7 +632,8 @@ func (a *apiServer) PatchModelVersion(
 	ctx context.Context, req *apiv1.PatchModelVersionRequest) (*apiv1.PatchModelVersionResponse,
 	error,
 ) {
-	currModelVersion, err := a.ModelVersionFromID(req.ModelName, req.ModelVersionId)
+	currModelVersion, err := a.modelVersionWithAuthZ(ctx, req.ModelName, req.ModelVersionId,
+		expauth.AuthZProvider.Get().CanEditExperiment)
 	if err != nil {
 		return nil, err
 	}
@@ -533,6 +714,10 @@ func (a *apiServer) DeleteModelVersion(
 	ctx context.Context, req *apiv1.DeleteModelVersionRequest) (*apiv1.DeleteModelVersionResponse,
 	error,
 ) {
+	if _, err := a.modelVersionWithAuthZ(ctx, req.ModelName, req.ModelVersionId,
+		expauth.AuthZProvider.Get().CanEditExperiment); err != nil {
+		return nil, err
+	}
 	user, err := a.CurrentUser(ctx, &apiv1.CurrentUserRequest{})
 	if err != nil {
 		return nil, err
@@ -550,3 +735,294 @@ func (a *apiServer) DeleteModelVersion(
 	return &apiv1.DeleteModelVersionResponse{},
 		errors.Wrapf(err, "error deleting model version %v", req.ModelVersionId)
 }
+
+func (a *apiServer) PostModelVersionTransition(
+	ctx context.Context, req *apiv1.PostModelVersionTransitionRequest,
+) (*apiv1.PostModelVersionTransitionResponse, error) {
+	mv, err := a.modelVersionWithAuthZ(ctx, req.ModelName, req.ModelVersionId,
+		expauth.AuthZProvider.Get().CanEditExperiment)
+	if err != nil {
+		return nil, err
+	}
+	if mv.Model.Archived {
+		return nil, errors.Errorf("model %q is archived and cannot have versions moved.",
+			mv.Model.Name)
+	}
+
+	toStage := model.ModelVersionStageFromProto(req.Stage)
+	switch {
+	case toStage == "":
+		return nil, status.Error(codes.InvalidArgument, "a stage is required")
+	case toStage == model.ModelVersionStageFromProto(mv.Stage):
+		return nil, status.Errorf(codes.InvalidArgument,
+			"model version %v is already in stage %s", mv.Id, toStage)
+	case req.ArchiveExisting && toStage != model.ModelVersionStageStaging &&
+		toStage != model.ModelVersionStageProduction:
+		return nil, status.Errorf(codes.InvalidArgument,
+			"existing versions can only be archived when moving to %s or %s",
+			model.ModelVersionStageStaging, model.ModelVersionStageProduction)
+	}
+
+	user, err := a.CurrentUser(ctx, &apiv1.CurrentUserRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Models without stage approvers let anyone move their versions. Otherwise, transitions
+	// requested by anyone but an approver wait for one to review them.
+	t := &model.ModelVersionTransition{
+		ModelVersionID:  int(mv.Id),
+		FromStage:       model.ModelVersionStageFromProto(mv.Stage),
+		ToStage:         toStage,
+		ArchiveExisting: req.ArchiveExisting,
+		State:           model.ModelVersionTransitionApplied,
+		Comment:         req.Comment,
+		RequestedBy:     model.UserID(user.User.Id),
+	}
+	if len(mv.Model.StageApprovers) > 0 && !isStageApprover(mv.Model, user.User) {
+		t.State = model.ModelVersionTransitionPending
+	}
+	switch err = db.AddModelVersionTransition(ctx, t); {
+	case errors.Is(err, db.ErrModelVersionStageChanged):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, errors.Wrapf(err, "error moving model version %v", req.ModelVersionId)
+	}
+	log.Infof("model version (%v) transition from %s to %s by %q is %s",
+		req.ModelVersionId, t.FromStage, t.ToStage, user.User.Username, t.State)
+
+	return a.modelVersionTransitionResponse(ctx, req.ModelName, req.ModelVersionId, t.ID)
+}
+
+func (a *apiServer) GetModelVersionTransitions(
+	ctx context.Context, req *apiv1.GetModelVersionTransitionsRequest,
+) (*apiv1.GetModelVersionTransitionsResponse, error) {
+	if _, err := a.modelVersionWithAuthZ(ctx, req.ModelName, req.ModelVersionId,
+		expauth.AuthZProvider.Get().CanGetExperimentArtifacts); err != nil {
+		return nil, err
+	}
+
+	ts, err := db.ModelVersionTransitions(ctx, int(req.ModelVersionId))
+	if err != nil {
+		return nil, errors.Wrapf(err,
+			"error fetching transitions of model version %v", req.ModelVersionId)
+	}
+	resp := &apiv1.GetModelVersionTransitionsResponse{}
+	for _, t := range ts {
+		resp.Transitions = append(resp.Transitions, t.Proto())
+	}
+	return resp, nil
+}
+
+func (a *apiServer) ReviewModelVersionTransition(
+	ctx context.Context, req *apiv1.ReviewModelVersionTransitionRequest,
+) (*apiv1.ReviewModelVersionTransitionResponse, error) {
+	mv, err := a.modelVersionWithAuthZ(ctx, req.ModelName, req.ModelVersionId,
+		expauth.AuthZProvider.Get().CanGetExperimentArtifacts)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.CurrentUser(ctx, &apiv1.CurrentUserRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if !user.User.Admin && !isStageApprover(mv.Model, user.User) {
+		return nil, status.Errorf(codes.PermissionDenied,
+			"only stage approvers of model %q can review transitions", mv.Model.Name)
+	}
+
+	switch err = db.ReviewModelVersionTransition(ctx, int(req.ModelVersionId),
+		int(req.TransitionId), model.UserID(user.User.Id), req.Approve); {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound,
+			"pending transition %v of model version %v not found",
+			req.TransitionId, req.ModelVersionId)
+	case errors.Is(err, db.ErrModelVersionStageChanged):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, errors.Wrapf(err, "error reviewing transition %v", req.TransitionId)
+	}
+	log.Infof("model version (%v) transition %v approved by %q: %v",
+		req.ModelVersionId, req.TransitionId, user.User.Username, req.Approve)
+
+	resp, err := a.modelVersionTransitionResponse(
+		ctx, req.ModelName, req.ModelVersionId, int(req.TransitionId))
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.ReviewModelVersionTransitionResponse{
+		Transition:   resp.Transition,
+		ModelVersion: resp.ModelVersion,
+	}, nil
+}
+
+func (a *apiServer) modelVersionTransitionResponse(
+	ctx context.Context, modelName string, modelVersionID int32, transitionID int,
+) (*apiv1.PostModelVersionTransitionResponse, error) {
+	t, err := db.ModelVersionTransitionByID(ctx, int(modelVersionID), transitionID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error fetching transition %v", transitionID)
+	}
+	mv, err := a.ModelVersionFromID(modelName, modelVersionID)
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.PostModelVersionTransitionResponse{
+		Transition:   t.Proto(),
+		ModelVersion: mv,
+	}, nil
+}
+
+// canSetStageApprovers returns an error unless the user can choose who approves the stage
+// transitions of a model. Cluster admins can, as can users who are admins of every workspace
+// that the versions of the model come from.
+func canSetStageApprovers(ctx context.Context, curUser model.User, m *modelv1.Model) error {
+	if curUser.Admin {
+		return nil
+	}
+	var workspaceIDs []int
+	if err := db.Bun().NewSelect().
+		ColumnExpr("DISTINCT p.workspace_id").
+		TableExpr("model_versions AS mv").
+		Join("JOIN checkpoints_view AS c ON c.uuid = mv.checkpoint_uuid").
+		Join("JOIN experiments AS e ON e.id = c.experiment_id").
+		Join("JOIN projects AS p ON p.id = e.project_id").
+		Where("mv.model_id = ?", m.Id).
+		Scan(ctx, &workspaceIDs); err != nil {
+		return errors.Wrapf(err, "error finding the workspaces of model %q", m.Name)
+	}
+	denied := status.Errorf(codes.PermissionDenied,
+		"only admins of the workspaces of model %q can change its stage approvers", m.Name)
+	if len(workspaceIDs) == 0 {
+		return denied
+	}
+	for _, id := range workspaceIDs {
+		role, err := workspace.WorkspaceRole(ctx, curUser, id)
+		if err != nil {
+			return err
+		}
+		if !role.AtLeast(model.MemberRoleAdmin) {
+			return denied
+		}
+	}
+	return nil
+}
+
+func isStageApprover(m *modelv1.Model, user *userv1.User) bool {
+	for _, username := range m.StageApprovers {
+		if username == user.Username {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *apiServer) GetModelVersionLineage(
+	ctx context.Context, req *apiv1.GetModelVersionLineageRequest,
+) (*apiv1.GetModelVersionLineageResponse, error) {
+	mv, err := a.modelVersionWithAuthZ(ctx, req.ModelName, req.ModelVersionId,
+		expauth.AuthZProvider.Get().CanGetExperimentArtifacts)
+	if err != nil {
+		return nil, err
+	}
+
+	lineage := &modelv1.ModelVersionLineage{ModelVersion: mv}
+	training := mv.Checkpoint.GetTraining()
+	if training.GetTrialId() == nil || training.GetExperimentId() == nil {
+		// The checkpoint was not reported by a trial, so its lineage ends here.
+		return &apiv1.GetModelVersionLineageResponse{Lineage: lineage}, nil
+	}
+
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := db.TrialLineageByID(ctx, int(training.TrialId.Value))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error fetching lineage of model version %v",
+			req.ModelVersionId)
+	}
+	lineage.Trial = &modelv1.LineageTrial{
+		Id:                      int32(t.ID),
+		TaskId:                  string(t.TaskID),
+		Hparams:                 protoutils.ToStruct(t.HParams),
+		Seed:                    t.Seed,
+		State:                   experimentStateToProto(t.State),
+		StartTime:               timestamppb.New(t.StartTime),
+		EndTime:                 optionalTimestamp(t.EndTime),
+		WarmStartCheckpointUuid: t.WarmStartCheckpointUUID,
+	}
+
+	es, err := db.ExperimentLineages(ctx, int(training.ExperimentId.Value))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error fetching lineage of model version %v",
+			req.ModelVersionId)
+	}
+	lineage.Experiment = experimentLineageToProto(es[0])
+	for _, e := range es[1:] {
+		ok, err := a.canGetExperimentLineage(ctx, *curUser, e.ID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			lineage.ForkedFrom = append(lineage.ForkedFrom, experimentLineageToProto(e))
+		}
+	}
+	return &apiv1.GetModelVersionLineageResponse{Lineage: lineage}, nil
+}
+
+// canGetExperimentLineage returns whether the user can see an experiment in the lineage of a
+// model version, which includes its configuration and where its code came from.
+func (a *apiServer) canGetExperimentLineage(
+	ctx context.Context, curUser model.User, id int,
+) (bool, error) {
+	e, err := a.m.db.ExperimentWithoutConfigByID(id)
+	if err != nil {
+		return false, err
+	}
+	authZ := expauth.AuthZProvider.Get()
+	if ok, err := authZ.CanGetExperiment(ctx, curUser, e); err != nil || !ok {
+		return false, err
+	}
+	return authZ.CanGetExperimentArtifacts(ctx, curUser, e) == nil, nil
+}
+
+func experimentLineageToProto(e db.ExperimentLineage) *modelv1.LineageExperiment {
+	pe := &modelv1.LineageExperiment{
+		Id:                  int32(e.ID),
+		Name:                e.Name,
+		State:               experimentStateToProto(e.State),
+		Config:              protoutils.ToStruct(e.Config),
+		ConfigHash:          e.ConfigHash,
+		ModelDefinitionHash: e.ModelDefinitionHash,
+		GitRemote:           e.GitRemote,
+		GitCommit:           e.GitCommit,
+		GitCommitter:        e.GitCommitter,
+		GitCommitDate:       optionalTimestamp(e.GitCommitDate),
+		Username:            e.Username,
+		ProjectId:           int32(e.ProjectID),
+		WorkspaceId:         int32(e.WorkspaceID),
+		StartTime:           timestamppb.New(e.StartTime),
+		EndTime:             optionalTimestamp(e.EndTime),
+	}
+	if data, ok := e.Config["data"].(map[string]interface{}); ok {
+		pe.Data = protoutils.ToStruct(data)
+	}
+	if e.ParentID != nil {
+		pe.ForkedFrom = ptrs.Ptr(int32(*e.ParentID))
+	}
+	return pe
+}
+
+func experimentStateToProto(s model.State) experimentv1.State {
+	return experimentv1.State(experimentv1.State_value["STATE_"+string(s)])
+}
+
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
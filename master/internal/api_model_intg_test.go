@@ -0,0 +1,342 @@
+//go:build integration
+// +build integration
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+	"github.com/determined-ai/determined/proto/pkg/userv1"
+	"github.com/determined-ai/determined/proto/pkg/workspacev1"
+)
+
+// createCompletedCheckpoint creates a completed checkpoint of a new experiment in a project.
+func createCompletedCheckpoint(
+	ctx context.Context, t *testing.T, api *apiServer, curUser model.User, projectID int,
+) string {
+	exp := createTestExpWithProjectID(t, api, curUser, projectID)
+	task := &model.Task{TaskType: model.TaskTypeTrial, TaskID: model.NewTaskID()}
+	require.NoError(t, api.m.db.AddTask(task))
+	trial := &model.Trial{
+		StartTime:    time.Now(),
+		State:        model.PausedState,
+		ExperimentID: exp.ID,
+		TaskID:       task.TaskID,
+	}
+	require.NoError(t, api.m.db.AddTrial(trial))
+
+	a := &model.Allocation{
+		AllocationID: model.AllocationID(string(task.TaskID) + "-1"),
+		TaskID:       task.TaskID,
+		Slots:        1,
+		ResourcePool: "kubernetes",
+		StartTime:    ptrs.Ptr(time.Now().UTC()),
+	}
+	require.NoError(t, api.m.db.AddAllocation(a))
+	checkpoint := &model.CheckpointV2{
+		UUID:         uuid.New(),
+		TaskID:       task.TaskID,
+		AllocationID: a.AllocationID,
+		ReportTime:   time.Now(),
+		State:        model.CompletedState,
+		Resources:    map[string]int64{"ok": 1},
+		Metadata:     map[string]interface{}{"steps_completed": 1},
+	}
+	require.NoError(t, api.m.db.AddCheckpointMetadata(ctx, checkpoint))
+	return checkpoint.UUID.String()
+}
+
+// createModelVersion creates a model with a version registered from a checkpoint of an
+// experiment in a project.
+func createModelVersion(
+	ctx context.Context, t *testing.T, api *apiServer, curUser model.User, projectID int,
+) *modelv1.ModelVersion {
+	mResp, err := api.PostModel(ctx, &apiv1.PostModelRequest{Name: uuid.New().String()})
+	require.NoError(t, err)
+	mvResp, err := api.PostModelVersion(ctx, &apiv1.PostModelVersionRequest{
+		ModelName:      mResp.Model.Name,
+		CheckpointUuid: createCompletedCheckpoint(ctx, t, api, curUser, projectID),
+	})
+	require.NoError(t, err)
+	return mvResp.ModelVersion
+}
+
+// userContext creates a user who is not an admin and returns them with a context they are
+// logged in with.
+func userContext(ctx context.Context, t *testing.T, api *apiServer) (int32, context.Context) {
+	userResp, err := api.PostUser(ctx, &apiv1.PostUserRequest{
+		User: &userv1.User{Username: uuid.New().String(), Active: true},
+	})
+	require.NoError(t, err)
+	loginResp, err := api.Login(context.TODO(), &apiv1.LoginRequest{
+		Username: userResp.User.Username,
+	})
+	require.NoError(t, err)
+	return userResp.User.Id, metadata.NewIncomingContext(context.TODO(),
+		metadata.Pairs("x-user-token", fmt.Sprintf("Bearer %s", loginResp.Token)))
+}
+
+func TestPatchModelStageApprovers(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t)
+	workspaceID, projectID := createProjectAndWorkspace(ctx, t, api)
+	mv := createModelVersion(ctx, t, api, curUser, projectID)
+	userID, userCtx := userContext(ctx, t, api)
+
+	setApprovers := func(ctx context.Context, usernames ...interface{}) error {
+		approvers, err := structpb.NewList(usernames)
+		require.NoError(t, err)
+		_, err = api.PatchModel(ctx, &apiv1.PatchModelRequest{
+			ModelName: mv.Model.Name,
+			Model:     &modelv1.PatchModel{StageApprovers: approvers},
+		})
+		return err
+	}
+
+	// Editors of the workspace cannot choose who approves transitions.
+	_, err := api.PutWorkspaceMember(ctx, &apiv1.PutWorkspaceMemberRequest{
+		Id: int32(workspaceID), UserId: userID, Role: workspacev1.MemberRole_MEMBER_ROLE_EDITOR,
+	})
+	require.NoError(t, err)
+	require.Equal(t, codes.PermissionDenied, status.Code(setApprovers(userCtx, "admin")))
+
+	// Admins of the workspace and of the cluster can.
+	_, err = api.PutWorkspaceMember(ctx, &apiv1.PutWorkspaceMemberRequest{
+		Id: int32(workspaceID), UserId: userID, Role: workspacev1.MemberRole_MEMBER_ROLE_ADMIN,
+	})
+	require.NoError(t, err)
+	require.NoError(t, setApprovers(userCtx, "admin"))
+	require.NoError(t, setApprovers(ctx))
+
+	// Patches that leave the approvers as they are need no more than before.
+	_, err = api.PutWorkspaceMember(ctx, &apiv1.PutWorkspaceMemberRequest{
+		Id: int32(workspaceID), UserId: userID, Role: workspacev1.MemberRole_MEMBER_ROLE_EDITOR,
+	})
+	require.NoError(t, err)
+	require.NoError(t, setApprovers(userCtx))
+}
+
+func TestGetModelVersionLineageAuthZ(t *testing.T) {
+	api, authZExp, _, curUser, ctx := setupExpAuthTest(t)
+	authZExp.On("CanGetExperiment", mock.Anything, curUser, mock.Anything).
+		Return(true, nil).Once()
+	authZExp.On("CanGetExperimentArtifacts", mock.Anything, curUser, mock.Anything).
+		Return(nil).Once()
+	mv := createModelVersion(ctx, t, api, curUser, 1)
+	parent := createTestExp(t, api, curUser)
+	_, err := db.Bun().NewUpdate().Table("experiments").
+		Set("parent_id = ?", parent.ID).
+		Where("id = ?", mv.Checkpoint.Training.ExperimentId.Value).
+		Exec(ctx)
+	require.NoError(t, err)
+
+	getLineage := func() *modelv1.ModelVersionLineage {
+		resp, err := api.GetModelVersionLineage(ctx, &apiv1.GetModelVersionLineageRequest{
+			ModelName: mv.Model.Name, ModelVersionId: mv.Id,
+		})
+		require.NoError(t, err)
+		return resp.Lineage
+	}
+
+	// Users who can see every experiment see the whole lineage.
+	authZExp.On("CanGetExperiment", mock.Anything, curUser, mock.Anything).
+		Return(true, nil).Twice()
+	authZExp.On("CanGetExperimentArtifacts", mock.Anything, curUser, mock.Anything).
+		Return(nil).Twice()
+	lineage := getLineage()
+	require.NotNil(t, lineage.Trial)
+	require.NotNil(t, lineage.Experiment)
+	require.Len(t, lineage.ForkedFrom, 1)
+
+	// Experiments that were forked from are left out if the user cannot see them.
+	authZExp.On("CanGetExperiment", mock.Anything, curUser, mock.Anything).
+		Return(true, nil).Once()
+	authZExp.On("CanGetExperimentArtifacts", mock.Anything, curUser, mock.Anything).
+		Return(nil).Once()
+	authZExp.On("CanGetExperiment", mock.Anything, curUser, mock.Anything).
+		Return(false, nil).Once()
+	lineage = getLineage()
+	require.NotNil(t, lineage.Experiment)
+	require.Empty(t, lineage.ForkedFrom)
+
+	// Lineages are denied to users who cannot see the artifacts of the version's experiment.
+	authZExp.On("CanGetExperiment", mock.Anything, curUser, mock.Anything).
+		Return(true, nil).Once()
+	authZExp.On("CanGetExperimentArtifacts", mock.Anything, curUser, mock.Anything).
+		Return(fmt.Errorf("denied")).Once()
+	_, err = api.GetModelVersionLineage(ctx, &apiv1.GetModelVersionLineageRequest{
+		ModelName: mv.Model.Name, ModelVersionId: mv.Id,
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Errors checking access are returned.
+	authZExp.On("CanGetExperiment", mock.Anything, curUser, mock.Anything).
+		Return(false, fmt.Errorf("canGetExperimentError")).Once()
+	_, err = api.GetModelVersionLineage(ctx, &apiv1.GetModelVersionLineageRequest{
+		ModelName: mv.Model.Name, ModelVersionId: mv.Id,
+	})
+	require.EqualError(t, err, "canGetExperimentError")
+}
+
+func TestModelRegistryAuthZ(t *testing.T) {
+	api, authZExp, _, curUser, ctx := setupExpAuthTest(t)
+	authZExp.On("CanGetExperiment", mock.Anything, curUser, mock.Anything).
+		Return(true, nil).Once()
+	authZExp.On("CanGetExperimentArtifacts", mock.Anything, curUser, mock.Anything).
+		Return(nil).Once()
+	mv := createModelVersion(ctx, t, api, curUser, 1)
+	modelName, versionID := mv.Model.Name, mv.Id
+
+	cases := []struct {
+		DenyFuncName string
+		// IsModel is set for actions on whole models, which are denied rather than not found
+		// when the user cannot view the experiment of one of their versions.
+		IsModel bool
+		Call    func() error
+	}{
+		{"CanGetExperimentArtifacts", false, func() error {
+			_, err := api.GetModelVersion(ctx, &apiv1.GetModelVersionRequest{
+				ModelName: modelName, ModelVersion: versionID,
+			})
+			return err
+		}},
+		{"CanEditExperiment", false, func() error {
+			_, err := api.PatchModelVersion(ctx, &apiv1.PatchModelVersionRequest{
+				ModelName: modelName, ModelVersionId: versionID,
+				ModelVersion: &modelv1.PatchModelVersion{},
+			})
+			return err
+		}},
+		{"CanEditExperiment", false, func() error {
+			_, err := api.DeleteModelVersion(ctx, &apiv1.DeleteModelVersionRequest{
+				ModelName: modelName, ModelVersionId: versionID,
+			})
+			return err
+		}},
+		{"CanEditExperiment", false, func() error {
+			_, err := api.PostModelVersionTransition(ctx,
+				&apiv1.PostModelVersionTransitionRequest{
+					ModelName: modelName, ModelVersionId: versionID,
+					Stage: modelv1.ModelVersionStage_MODEL_VERSION_STAGE_STAGING,
+				})
+			return err
+		}},
+		{"CanGetExperimentArtifacts", false, func() error {
+			_, err := api.GetModelVersionTransitions(ctx,
+				&apiv1.GetModelVersionTransitionsRequest{
+					ModelName: modelName, ModelVersionId: versionID,
+				})
+			return err
+		}},
+		{"CanGetExperimentArtifacts", false, func() error {
+			_, err := api.ReviewModelVersionTransition(ctx,
+				&apiv1.ReviewModelVersionTransitionRequest{
+					ModelName: modelName, ModelVersionId: versionID, TransitionId: 1,
+				})
+			return err
+		}},
+		{"CanGetExperimentArtifacts", false, func() error {
+			_, err := api.GetModelVersionLineage(ctx, &apiv1.GetModelVersionLineageRequest{
+				ModelName: modelName, ModelVersionId: versionID,
+			})
+			return err
+		}},
+		{"CanEditExperiment", true, func() error {
+			_, err := api.PatchModel(ctx, &apiv1.PatchModelRequest{
+				ModelName: modelName, Model: &modelv1.PatchModel{},
+			})
+			return err
+		}},
+		{"CanEditExperiment", true, func() error {
+			_, err := api.ArchiveModel(ctx, &apiv1.ArchiveModelRequest{ModelName: modelName})
+			return err
+		}},
+		{"CanEditExperiment", true, func() error {
+			_, err := api.UnarchiveModel(ctx, &apiv1.UnarchiveModelRequest{ModelName: modelName})
+			return err
+		}},
+		{"CanEditExperiment", true, func() error {
+			_, err := api.DeleteModel(ctx, &apiv1.DeleteModelRequest{ModelName: modelName})
+			return err
+		}},
+	}
+
+	for _, curCase := range cases {
+		// Versions of experiments the user cannot view are not found.
+		authZExp.On("CanGetExperiment", mock.Anything, curUser, mock.Anything).
+			Return(false, nil).Once()
+		expected := codes.NotFound
+		if curCase.IsModel {
+			expected = codes.PermissionDenied
+		}
+		require.Equal(t, expected, status.Code(curCase.Call()))
+
+		// An error returned by CanGetExperiment is returned unmodified.
+		expErr := fmt.Errorf("canGetExperimentError")
+		authZExp.On("CanGetExperiment", mock.Anything, curUser, mock.Anything).
+			Return(false, expErr).Once()
+		require.Equal(t, expErr, curCase.Call())
+
+		// Deny with permission to view returns error wrapped in forbidden.
+		deniedErr := status.Error(codes.PermissionDenied, curCase.DenyFuncName+"Deny")
+		authZExp.On("CanGetExperiment", mock.Anything, curUser, mock.Anything).
+			Return(true, nil).Once()
+		authZExp.On(curCase.DenyFuncName, mock.Anything, curUser, mock.Anything).
+			Return(fmt.Errorf("%sDeny", curCase.DenyFuncName)).Once()
+		require.Equal(t, deniedErr.Error(), curCase.Call().Error())
+	}
+
+	// Listing the versions of a model leaves out those the user cannot view.
+	authZExp.On("CanGetExperiment", mock.Anything, curUser, mock.Anything).
+		Return(false, nil).Once()
+	resp, err := api.GetModelVersions(ctx, &apiv1.GetModelVersionsRequest{ModelName: modelName})
+	require.NoError(t, err)
+	require.Empty(t, resp.ModelVersions)
+	authZExp.On("CanGetExperiment", mock.Anything, curUser, mock.Anything).
+		Return(true, nil).Once()
+	authZExp.On("CanGetExperimentArtifacts", mock.Anything, curUser, mock.Anything).
+		Return(nil).Once()
+	resp, err = api.GetModelVersions(ctx, &apiv1.GetModelVersionsRequest{ModelName: modelName})
+	require.NoError(t, err)
+	require.Len(t, resp.ModelVersions, 1)
+}
+
+func TestModelWithoutVersionsAuthZ(t *testing.T) {
+	api, _, ctx := setupAPITest(t)
+	mResp, err := api.PostModel(ctx, &apiv1.PostModelRequest{Name: uuid.New().String()})
+	require.NoError(t, err)
+	modelName := mResp.Model.Name
+	_, userCtx := userContext(ctx, t, api)
+
+	// Only the owner of a model without versions and admins can act on it.
+	_, err = api.PatchModel(userCtx, &apiv1.PatchModelRequest{
+		ModelName: modelName, Model: &modelv1.PatchModel{},
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = api.ArchiveModel(userCtx, &apiv1.ArchiveModelRequest{ModelName: modelName})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = api.PatchModel(ctx, &apiv1.PatchModelRequest{
+		ModelName: modelName, Model: &modelv1.PatchModel{},
+	})
+	require.NoError(t, err)
+
+	mResp, err = api.PostModel(userCtx, &apiv1.PostModelRequest{Name: uuid.New().String()})
+	require.NoError(t, err)
+	_, err = api.ArchiveModel(userCtx, &apiv1.ArchiveModelRequest{ModelName: mResp.Model.Name})
+	require.NoError(t, err)
+}
@@ -34,7 +34,7 @@ const (
 	jupyterRuntimeDir = "/run/determined/jupyter/runtime"
 	jupyterEntrypoint = "/run/determined/jupyter/notebook-entrypoint.sh"
 	jupyterIdleCheck  = "/run/determined/jupyter/check_idle.py"
-	// Agent ports 2600 - 3500 are split between TensorBoards, Notebooks, and Shells.
+	// Agent ports 2600 - 3800 are split between TensorBoards, Notebooks, Shells, and VS Code.
 	minNotebookPort     = 2900
 	maxNotebookPort     = minNotebookPort + 299
 	notebookDefaultPage = "/run/determined/workdir/README.ipynb"
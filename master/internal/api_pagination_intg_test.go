@@ -0,0 +1,141 @@
+//go:build integration
+// +build integration
+
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/api"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/checkpointv1"
+)
+
+func cursorForID(id int32) string {
+	return api.Cursor{ID: int(id)}.Encode()
+}
+
+func TestExperimentsCursor(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t)
+	_, projectID := createProjectAndWorkspace(ctx, t, api)
+	var ids []int32
+	for i := 0; i < 3; i++ {
+		ids = append(ids, int32(createTestExpWithProjectID(t, api, curUser, projectID).ID))
+	}
+
+	page := func(req *apiv1.GetExperimentsRequest) ([]int32, *apiv1.Pagination) {
+		req.ProjectId = int32(projectID)
+		resp, err := api.GetExperiments(ctx, req)
+		require.NoError(t, err)
+		var pageIDs []int32
+		for _, e := range resp.Experiments {
+			pageIDs = append(pageIDs, e.Id)
+		}
+		return pageIDs, resp.Pagination
+	}
+
+	pageIDs, p := page(&apiv1.GetExperimentsRequest{Limit: 2})
+	require.Equal(t, ids[:2], pageIDs)
+	require.Equal(t, cursorForID(ids[1]), p.NextCursor)
+
+	// An experiment deleted from the first page does not shift the second.
+	_, err := db.Bun().NewDelete().Table("experiments").Where("id = ?", ids[0]).Exec(ctx)
+	require.NoError(t, err)
+	pageIDs, p = page(&apiv1.GetExperimentsRequest{Limit: 2, Cursor: p.NextCursor})
+	require.Equal(t, ids[2:], pageIDs)
+	require.Empty(t, p.NextCursor)
+	require.Equal(t, int32(2), p.Total)
+	require.Equal(t, int32(1), p.EndIndex)
+
+	pageIDs, p = page(&apiv1.GetExperimentsRequest{
+		Limit: 1, OrderBy: apiv1.OrderBy_ORDER_BY_DESC, Cursor: cursorForID(ids[2]),
+	})
+	require.Equal(t, []int32{ids[1]}, pageIDs)
+	require.Equal(t, cursorForID(ids[1]), p.NextCursor)
+
+	for _, req := range []*apiv1.GetExperimentsRequest{
+		{Offset: 1, Cursor: cursorForID(ids[1])},
+		{SortBy: apiv1.GetExperimentsRequest_SORT_BY_NAME, Cursor: cursorForID(ids[1])},
+		{Cursor: "not a cursor"},
+	} {
+		_, err := api.GetExperiments(ctx, req)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func TestExperimentTrialsCursor(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t)
+	exp := createTestExpWithProjectID(t, api, curUser, 1)
+	var ids []int32
+	for i := 0; i < 3; i++ {
+		task := &model.Task{TaskType: model.TaskTypeTrial, TaskID: model.NewTaskID()}
+		require.NoError(t, api.m.db.AddTask(task))
+		trial := &model.Trial{
+			StartTime:    time.Now(),
+			State:        model.PausedState,
+			ExperimentID: exp.ID,
+			TaskID:       task.TaskID,
+		}
+		require.NoError(t, api.m.db.AddTrial(trial))
+		ids = append(ids, int32(trial.ID))
+	}
+
+	resp, err := api.GetExperimentTrials(ctx, &apiv1.GetExperimentTrialsRequest{
+		ExperimentId: int32(exp.ID), Limit: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Trials, 2)
+	require.Equal(t, cursorForID(ids[1]), resp.Pagination.NextCursor)
+
+	resp, err = api.GetExperimentTrials(ctx, &apiv1.GetExperimentTrialsRequest{
+		ExperimentId: int32(exp.ID), Limit: 2, Cursor: resp.Pagination.NextCursor,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Trials, 1)
+	require.Equal(t, ids[2], resp.Trials[0].Id)
+	require.Empty(t, resp.Pagination.NextCursor)
+	require.Equal(t, int32(3), resp.Pagination.Total)
+
+	_, err = api.GetExperimentTrials(ctx, &apiv1.GetExperimentTrialsRequest{
+		ExperimentId: int32(exp.ID),
+		SortBy:       apiv1.GetExperimentTrialsRequest_SORT_BY_START_TIME,
+		Cursor:       cursorForID(ids[0]),
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestCheckpointsCursor(t *testing.T) {
+	api, _, _ := setupAPITest(t)
+	list := func() []*checkpointv1.Checkpoint {
+		return []*checkpointv1.Checkpoint{{Uuid: "a"}, {Uuid: "b"}, {Uuid: "c"}}
+	}
+
+	var p *apiv1.Pagination
+	checkpoints := list()
+	require.NoError(t, api.paginateCheckpoints(&p, &checkpoints, 0, 2, ""))
+	require.Equal(t, []*checkpointv1.Checkpoint{{Uuid: "a"}, {Uuid: "b"}}, checkpoints)
+	cursor := p.NextCursor
+	require.NotEmpty(t, cursor)
+
+	// A checkpoint added before the cursor does not shift the next page.
+	checkpoints = append([]*checkpointv1.Checkpoint{{Uuid: uuid.New().String()}}, list()...)
+	require.NoError(t, api.paginateCheckpoints(&p, &checkpoints, 0, 2, cursor))
+	require.Equal(t, []*checkpointv1.Checkpoint{{Uuid: "c"}}, checkpoints)
+	require.Empty(t, p.NextCursor)
+	require.Equal(t, int32(4), p.Total)
+
+	checkpoints = list()[:1]
+	err := api.paginateCheckpoints(&p, &checkpoints, 0, 2, cursor)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	checkpoints = list()
+	err = api.paginateCheckpoints(&p, &checkpoints, 1, 2, cursor)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	k8sClient "k8s.io/client-go/kubernetes"
+
+	"github.com/determined-ai/determined/master/internal/auditlog"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/master/internal/podtemplates"
+	"github.com/determined-ai/determined/master/internal/rm/kubernetes"
+	"github.com/determined-ai/determined/master/pkg/check"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/podtemplatev1"
+)
+
+func (a *apiServer) GetPodTemplates(
+	ctx context.Context, _ *apiv1.GetPodTemplatesRequest,
+) (*apiv1.GetPodTemplatesResponse, error) {
+	if _, _, err := grpcutil.GetUser(ctx); err != nil {
+		return nil, err
+	}
+	ts, err := podtemplates.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.GetPodTemplatesResponse{
+		Templates: make([]*podtemplatev1.PodTemplate, 0, len(ts)),
+	}
+	for _, t := range ts {
+		pt, err := t.Proto()
+		if err != nil {
+			return nil, err
+		}
+		resp.Templates = append(resp.Templates, pt)
+	}
+	return resp, nil
+}
+
+func (a *apiServer) GetPodTemplate(
+	ctx context.Context, req *apiv1.GetPodTemplateRequest,
+) (*apiv1.GetPodTemplateResponse, error) {
+	if _, _, err := grpcutil.GetUser(ctx); err != nil {
+		return nil, err
+	}
+	t, err := podtemplates.Get(ctx, req.Name)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "pod template %q not found", req.Name)
+	} else if err != nil {
+		return nil, err
+	}
+	pt, err := t.Proto()
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.GetPodTemplateResponse{Template: pt}, nil
+}
+
+func (a *apiServer) PutPodTemplate(
+	ctx context.Context, req *apiv1.PutPodTemplateRequest,
+) (*apiv1.PutPodTemplateResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !curUser.Admin {
+		return nil, grpcutil.ErrPermissionDenied
+	}
+	if req.Template == nil {
+		return nil, status.Error(codes.InvalidArgument, "template is required")
+	}
+	t, err := podtemplates.FromProto(req.Template)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = check.Validate(t); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = a.validatePodTemplateInCluster(ctx, t); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err = podtemplates.Put(ctx, t)
+	recordPodTemplateChange(ctx, curUser, auditlog.PodTemplatePut, t.Name, err)
+	if err != nil {
+		return nil, err
+	}
+	pt, err := t.Proto()
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.PutPodTemplateResponse{Template: pt}, nil
+}
+
+func (a *apiServer) DeletePodTemplate(
+	ctx context.Context, req *apiv1.DeletePodTemplateRequest,
+) (*apiv1.DeletePodTemplateResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !curUser.Admin {
+		return nil, grpcutil.ErrPermissionDenied
+	}
+	err = podtemplates.Delete(ctx, req.Name)
+	recordPodTemplateChange(ctx, curUser, auditlog.PodTemplateDelete, req.Name, err)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "pod template %q not found", req.Name)
+	} else if err != nil {
+		return nil, err
+	}
+	return &apiv1.DeletePodTemplateResponse{}, nil
+}
+
+// validatePodTemplateInCluster checks a pod template against the cluster of the Kubernetes
+// resource manager. Templates are only checked locally with other resource managers.
+func (a *apiServer) validatePodTemplateInCluster(
+	ctx context.Context, t *podtemplates.PodTemplate,
+) error {
+	k8sConfig := a.m.config.ResourceManager.KubernetesRM
+	if k8sConfig == nil {
+		return nil
+	}
+	restConfig, err := kubernetes.ReadClientConfig(k8sConfig.CredsDir)
+	if err != nil {
+		return errors.Wrap(err, "failed to read Kubernetes client config")
+	}
+	clientSet, err := k8sClient.NewForConfig(restConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to create Kubernetes client")
+	}
+	return t.ValidateInCluster(ctx, clientSet.CoreV1().Pods(k8sConfig.Namespace))
+}
+
+func recordPodTemplateChange(
+	ctx context.Context, curUser *model.User, action auditlog.Action, name string, err error,
+) {
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     action,
+		TargetType: "pod_template",
+		TargetID:   name,
+		Success:    err == nil,
+	})
+}
@@ -8,9 +8,12 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/determined-ai/determined/master/internal/auditlog"
 	"github.com/determined-ai/determined/master/internal/db"
 	"github.com/determined-ai/determined/master/internal/grpcutil"
 	"github.com/determined-ai/determined/master/internal/project"
+	"github.com/determined-ai/determined/master/internal/usergroup"
+	"github.com/determined-ai/determined/master/internal/workspace"
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
 	"github.com/determined-ai/determined/proto/pkg/projectv1"
@@ -345,3 +348,121 @@ func (a *apiServer) UnarchiveProject(
 	}
 	return &apiv1.UnarchiveProjectResponse{}, nil
 }
+
+// canManageProjectMembers requires the user to be an admin of the project.
+func canManageProjectMembers(
+	ctx context.Context, curUser model.User, p *projectv1.Project,
+) error {
+	return workspace.RequireProjectRole(ctx, curUser, int(p.Id), model.MemberRoleAdmin)
+}
+
+func (a *apiServer) GetProjectMembers(
+	ctx context.Context, req *apiv1.GetProjectMembersRequest,
+) (*apiv1.GetProjectMembersResponse, error) {
+	if _, _, err := a.getProjectAndCheckCanDoActions(ctx, req.Id); err != nil {
+		return nil, err
+	}
+	members, err := workspace.ProjectMembers(ctx, int(req.Id))
+	if err != nil {
+		return nil, err
+	}
+	groupMembers, err := workspace.ProjectGroupMembers(ctx, int(req.Id))
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.GetProjectMembersResponse{
+		Members:      []*workspacev1.Member{},
+		GroupMembers: []*workspacev1.GroupMember{},
+	}
+	for _, m := range members {
+		resp.Members = append(resp.Members, m.Proto())
+	}
+	for _, m := range groupMembers {
+		resp.GroupMembers = append(resp.GroupMembers, m.Proto())
+	}
+	return resp, nil
+}
+
+func (a *apiServer) PutProjectMember(
+	ctx context.Context, req *apiv1.PutProjectMemberRequest,
+) (*apiv1.PutProjectMemberResponse, error) {
+	_, curUser, err := a.getProjectAndCheckCanDoActions(ctx, req.Id, canManageProjectMembers)
+	if err != nil {
+		return nil, err
+	}
+	m, err := newMember(req.UserId, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if err = workspace.SetProjectMember(ctx, int(req.Id), m.UserID, m.Role); err != nil {
+		return nil, err
+	}
+	recordMemberChange(ctx, curUser, auditlog.ProjectMemberSet, "project", req.Id,
+		map[string]interface{}{"user_id": m.UserID, "role": m.Role})
+	return &apiv1.PutProjectMemberResponse{Member: m.Proto()}, nil
+}
+
+func (a *apiServer) DeleteProjectMember(
+	ctx context.Context, req *apiv1.DeleteProjectMemberRequest,
+) (*apiv1.DeleteProjectMemberResponse, error) {
+	_, curUser, err := a.getProjectAndCheckCanDoActions(ctx, req.Id, canManageProjectMembers)
+	if err != nil {
+		return nil, err
+	}
+	switch err := workspace.RemoveProjectMember(ctx, int(req.Id), model.UserID(req.UserId)); {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound,
+			"user (%d) is not a member of project (%d)", req.UserId, req.Id)
+	case err != nil:
+		return nil, err
+	}
+	recordMemberChange(ctx, curUser, auditlog.ProjectMemberRemove, "project", req.Id,
+		map[string]interface{}{"user_id": req.UserId})
+	return &apiv1.DeleteProjectMemberResponse{}, nil
+}
+
+func (a *apiServer) PutProjectGroupMember(
+	ctx context.Context, req *apiv1.PutProjectGroupMemberRequest,
+) (*apiv1.PutProjectGroupMemberResponse, error) {
+	_, curUser, err := a.getProjectAndCheckCanDoActions(ctx, req.Id, canManageProjectMembers)
+	if err != nil {
+		return nil, err
+	}
+	role := model.MemberRoleFromProto(req.Role)
+	if role == "" {
+		return nil, status.Error(codes.InvalidArgument, "a role is required")
+	}
+	g, err := usergroup.GroupByIDTx(ctx, nil, int(req.GroupId))
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "group (%d) not found", req.GroupId)
+	case err != nil:
+		return nil, err
+	}
+	if err = workspace.SetProjectGroupMember(ctx, int(req.Id), g.ID, role); err != nil {
+		return nil, err
+	}
+	recordMemberChange(ctx, curUser, auditlog.ProjectMemberSet, "project", req.Id,
+		map[string]interface{}{"group_id": g.ID, "role": role})
+	m := model.GroupMember{GroupID: g.ID, GroupName: g.Name, Role: role}
+	return &apiv1.PutProjectGroupMemberResponse{Member: m.Proto()}, nil
+}
+
+func (a *apiServer) DeleteProjectGroupMember(
+	ctx context.Context, req *apiv1.DeleteProjectGroupMemberRequest,
+) (*apiv1.DeleteProjectGroupMemberResponse, error) {
+	_, curUser, err := a.getProjectAndCheckCanDoActions(ctx, req.Id, canManageProjectMembers)
+	if err != nil {
+		return nil, err
+	}
+	switch err := workspace.RemoveProjectGroupMember(ctx, int(req.Id), int(req.GroupId)); {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound,
+			"group (%d) is not a member of project (%d)", req.GroupId, req.Id)
+	case err != nil:
+		return nil, err
+	}
+	recordMemberChange(ctx, curUser, auditlog.ProjectMemberRemove, "project", req.Id,
+		map[string]interface{}{"group_id": req.GroupId})
+	return &apiv1.DeleteProjectGroupMemberResponse{}, nil
+}
@@ -2,7 +2,20 @@ package internal
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/master/internal/auditlog"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
 )
 
@@ -15,3 +28,319 @@ func (a *apiServer) GetResourcePools(
 	}
 	return resp, a.paginate(&resp.Pagination, &resp.ResourcePools, req.Offset, req.Limit)
 }
+
+func (a *apiServer) ScheduleDryRun(
+	_ context.Context, req *apiv1.ScheduleDryRunRequest,
+) (*apiv1.ScheduleDryRunResponse, error) {
+	if req.Slots < 0 {
+		return nil, status.Error(codes.InvalidArgument, "slots must be non-negative")
+	}
+	if err := a.m.rm.ValidateResourcePool(a.m.system, req.ResourcePool); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	msg := sproto.ScheduleDryRunRequest{
+		ResourcePool:        req.ResourcePool,
+		SlotsNeeded:         int(req.Slots),
+		AgentLabel:          req.AgentLabel,
+		LabelConstraints:    req.LabelConstraints,
+		FittingRequirements: sproto.FittingRequirements{SingleAgent: req.SingleAgent},
+	}
+	if req.Priority != nil {
+		priority := int(*req.Priority)
+		msg.Priority = &priority
+	}
+	resp, err := a.m.rm.ScheduleDryRun(a.m.system, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	placement := make(map[string]int32, len(resp.Placement))
+	for agentID, slots := range resp.Placement {
+		placement[agentID] = int32(slots)
+	}
+	return &apiv1.ScheduleDryRunResponse{
+		Schedulable: resp.Schedulable,
+		Placement:   placement,
+		QueuedAhead: int32(resp.QueuedAhead),
+		SlotsAhead:  int32(resp.SlotsAhead),
+		Reason:      resp.Reason,
+	}, nil
+}
+
+func (a *apiServer) GetResourcePoolStats(
+	ctx context.Context, req *apiv1.GetResourcePoolStatsRequest,
+) (*apiv1.GetResourcePoolStatsResponse, error) {
+	if req.BucketSeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "bucket_seconds must be non-negative")
+	}
+	if err := a.m.rm.ValidateResourcePool(a.m.system, req.ResourcePool); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	end := time.Now()
+	if req.EndTime != nil {
+		end = req.EndTime.AsTime()
+	}
+	start := end.Add(-24 * time.Hour)
+	if req.StartTime != nil {
+		start = req.StartTime.AsTime()
+	}
+	if !start.Before(end) {
+		return nil, status.Error(codes.InvalidArgument, "start_time must be before end_time")
+	}
+
+	stats, err := db.ResourcePoolStatsHistory(
+		ctx, req.ResourcePool, start, end, time.Duration(req.BucketSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &apiv1.GetResourcePoolStatsResponse{
+		Samples: make([]*apiv1.ResourcePoolStatsSample, 0, len(stats)),
+	}
+	for _, s := range stats {
+		resp.Samples = append(resp.Samples, &apiv1.ResourcePoolStatsSample{
+			Time:              timestamppb.New(s.Time),
+			QueueLength:       int32(s.QueueLength),
+			QueuedSlots:       int32(s.QueuedSlots),
+			MedianWaitSeconds: s.MedianWaitSeconds,
+			SlotsTotal:        int32(s.SlotsTotal),
+			SlotsUsed:         int32(s.SlotsUsed),
+		})
+	}
+	return resp, nil
+}
+
+func (a *apiServer) CreateSlotReservation(
+	ctx context.Context, req *apiv1.CreateSlotReservationRequest,
+) (*apiv1.CreateSlotReservationResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !curUser.Admin {
+		return nil, grpcutil.ErrPermissionDenied
+	}
+	if a.m.config.ResourceManager.AgentRM == nil {
+		return nil, status.Error(codes.FailedPrecondition,
+			"slot reservations are only supported by the agent resource manager")
+	}
+	if err := a.m.rm.ValidateResourcePool(a.m.system, req.ResourcePool); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	switch {
+	case req.Slots <= 0:
+		return nil, status.Error(codes.InvalidArgument, "slots must be positive")
+	case req.StartTime == nil || req.EndTime == nil:
+		return nil, status.Error(codes.InvalidArgument, "start_time and end_time are required")
+	case !req.StartTime.AsTime().Before(req.EndTime.AsTime()):
+		return nil, status.Error(codes.InvalidArgument, "start_time must be before end_time")
+	case !req.EndTime.AsTime().After(time.Now()):
+		return nil, status.Error(codes.InvalidArgument, "end_time must be in the future")
+	case len(req.UserIds) == 0 && len(req.WorkspaceIds) == 0:
+		return nil, status.Error(codes.InvalidArgument,
+			"at least one of user_ids and workspace_ids must be set")
+	}
+
+	reservation := model.SlotReservation{
+		ResourcePool: req.ResourcePool,
+		Slots:        int(req.Slots),
+		StartTime:    req.StartTime.AsTime(),
+		EndTime:      req.EndTime.AsTime(),
+		UserIDs:      req.UserIds,
+		WorkspaceIDs: req.WorkspaceIds,
+		Description:  req.Description,
+		CreatedBy:    curUser.ID,
+	}
+	if err := db.AddSlotReservation(ctx, &reservation); err != nil {
+		return nil, err
+	}
+	return &apiv1.CreateSlotReservationResponse{
+		Reservation: toProtoSlotReservation(reservation),
+	}, nil
+}
+
+func (a *apiServer) GetSlotReservations(
+	ctx context.Context, req *apiv1.GetSlotReservationsRequest,
+) (*apiv1.GetSlotReservationsResponse, error) {
+	if err := a.m.rm.ValidateResourcePool(a.m.system, req.ResourcePool); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	reservations, err := db.SlotReservations(ctx, req.ResourcePool, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.GetSlotReservationsResponse{
+		Reservations: make([]*apiv1.SlotReservation, 0, len(reservations)),
+	}
+	for _, r := range reservations {
+		resp.Reservations = append(resp.Reservations, toProtoSlotReservation(r))
+	}
+	return resp, nil
+}
+
+func (a *apiServer) DeleteSlotReservation(
+	ctx context.Context, req *apiv1.DeleteSlotReservationRequest,
+) (*apiv1.DeleteSlotReservationResponse, error) {
+	if err := userShouldBeAdmin(ctx, a); err != nil {
+		return nil, err
+	}
+
+	err := db.DeleteSlotReservation(ctx, req.ResourcePool, int(req.Id))
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "slot reservation not found: %d", req.Id)
+	} else if err != nil {
+		return nil, err
+	}
+	return &apiv1.DeleteSlotReservationResponse{}, nil
+}
+
+func toProtoSlotReservation(r model.SlotReservation) *apiv1.SlotReservation {
+	return &apiv1.SlotReservation{
+		Id:           int32(r.ID),
+		ResourcePool: r.ResourcePool,
+		Slots:        int32(r.Slots),
+		StartTime:    timestamppb.New(r.StartTime),
+		EndTime:      timestamppb.New(r.EndTime),
+		UserIds:      r.UserIDs,
+		WorkspaceIds: r.WorkspaceIDs,
+		Description:  r.Description,
+		CreatedBy:    int32(r.CreatedBy),
+	}
+}
+
+func (a *apiServer) GetResourcePoolBindings(
+	ctx context.Context, _ *apiv1.GetResourcePoolBindingsRequest,
+) (*apiv1.GetResourcePoolBindingsResponse, error) {
+	bindings, err := db.ResourcePoolBindings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.GetResourcePoolBindingsResponse{
+		Bindings: make([]*apiv1.ResourcePoolBinding, 0, len(bindings)),
+	}
+	for _, b := range bindings {
+		resp.Bindings = append(resp.Bindings, toProtoResourcePoolBinding(b))
+	}
+	return resp, nil
+}
+
+func (a *apiServer) SetResourcePoolBinding(
+	ctx context.Context, req *apiv1.SetResourcePoolBindingRequest,
+) (*apiv1.SetResourcePoolBindingResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !curUser.Admin {
+		return nil, grpcutil.ErrPermissionDenied
+	}
+	if err := a.m.rm.ValidateResourcePool(a.m.system, req.ResourcePool); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	binding := model.ResourcePoolBinding{
+		ResourcePool: req.ResourcePool,
+		UserIDs:      req.UserIds,
+		GroupIDs:     req.GroupIds,
+		WorkspaceIDs: req.WorkspaceIds,
+	}
+	err = db.SetResourcePoolBinding(ctx, &binding)
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.ResourcePoolBindingSet,
+		TargetType: "resource_pool",
+		TargetID:   req.ResourcePool,
+		Success:    err == nil,
+		Details:    auditlog.DetailsFromProto(req),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.SetResourcePoolBindingResponse{
+		Binding: toProtoResourcePoolBinding(binding),
+	}, nil
+}
+
+func toProtoResourcePoolBinding(b model.ResourcePoolBinding) *apiv1.ResourcePoolBinding {
+	return &apiv1.ResourcePoolBinding{
+		ResourcePool: b.ResourcePool,
+		UserIds:      b.UserIDs,
+		GroupIds:     b.GroupIDs,
+		WorkspaceIds: b.WorkspaceIDs,
+	}
+}
+
+// ErrResourcePoolNotBound is returned when work is submitted to a resource pool whose binding
+// does not include the user, their groups, or the workspace of the work.
+type ErrResourcePoolNotBound string
+
+// Error implements the error interface.
+func (e ErrResourcePoolNotBound) Error() string {
+	return string(e)
+}
+
+// checkResourcePoolBound returns ErrResourcePoolNotBound if the tasks of a user may not use a
+// resource pool. workspaceID is nil for tasks outside of workspaces.
+func checkResourcePoolBound(
+	ctx context.Context, resourcePool string, userID model.UserID, workspaceID *int,
+) error {
+	bound, err := db.ResourcePoolBound(ctx, resourcePool, userID, workspaceID)
+	if err != nil {
+		return err
+	}
+	if !bound {
+		return ErrResourcePoolNotBound(fmt.Sprintf(
+			"resource pool %s is restricted to other users, groups, and workspaces; "+
+				"ask an admin to bind it to you", resourcePool))
+	}
+	return nil
+}
+
+// checkJobResourcePoolBound returns ErrResourcePoolNotBound if a job may not be moved to a
+// resource pool. Only experiments are restricted, the same way as when they are created.
+func checkJobResourcePoolBound(
+	ctx context.Context, jobID model.JobID, resourcePool string,
+) error {
+	var exp struct {
+		OwnerID     *model.UserID `bun:"owner_id"`
+		WorkspaceID int           `bun:"workspace_id"`
+	}
+	err := db.Bun().NewSelect().TableExpr("experiments AS e").
+		Join("JOIN projects AS p ON p.id = e.project_id").
+		ColumnExpr("e.owner_id, p.workspace_id").
+		Where("e.job_id = ?", jobID).
+		Scan(ctx, &exp)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return err
+	case exp.OwnerID == nil:
+		return nil
+	}
+	return checkResourcePoolBound(ctx, resourcePool, *exp.OwnerID, &exp.WorkspaceID)
+}
+
+// checkExperimentResourcePoolBound returns ErrResourcePoolNotBound if an experiment may not be
+// moved to a workspace because the resource pool it uses is not bound to the workspace, the
+// same way as when it is created there.
+func checkExperimentResourcePoolBound(
+	ctx context.Context, exp *model.Experiment, workspaceID int,
+) error {
+	if exp.OwnerID == nil {
+		return nil
+	}
+	var resourcePool string
+	if err := db.Bun().NewSelect().TableExpr("experiments").
+		ColumnExpr("coalesce(config->'resources'->>'resource_pool', '')").
+		Where("id = ?", exp.ID).
+		Scan(ctx, &resourcePool); err != nil {
+		return err
+	}
+	if resourcePool == "" {
+		return nil
+	}
+	return checkResourcePoolBound(ctx, resourcePool, *exp.OwnerID, &workspaceID)
+}
@@ -0,0 +1,76 @@
+//go:build integration
+// +build integration
+
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/master/pkg/schemas"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+)
+
+func TestCheckJobResourcePoolBound(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t)
+	exp := createTestExp(t, api, curUser)
+	pool := "pool-" + uuid.NewString()
+
+	// Pools without a binding are open to every job.
+	require.NoError(t, checkJobResourcePoolBound(ctx, exp.JobID, pool))
+
+	other := db.RequireMockUser(t, api.m.db)
+	require.NoError(t, db.SetResourcePoolBinding(ctx, &model.ResourcePoolBinding{
+		ResourcePool: pool,
+		UserIDs:      []int32{int32(other.ID)},
+	}))
+	require.IsType(t, ErrResourcePoolNotBound(""),
+		checkJobResourcePoolBound(ctx, exp.JobID, pool))
+
+	require.NoError(t, db.SetResourcePoolBinding(ctx, &model.ResourcePoolBinding{
+		ResourcePool: pool,
+		UserIDs:      []int32{int32(other.ID), int32(curUser.ID)},
+	}))
+	require.NoError(t, checkJobResourcePoolBound(ctx, exp.JobID, pool))
+
+	// Only experiments are restricted.
+	require.NoError(t, checkJobResourcePoolBound(ctx, model.NewJobID(), pool))
+}
+
+func TestCheckExperimentResourcePoolBound(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t)
+	boundWorkspaceID, projectID := createProjectAndWorkspace(ctx, t, api)
+	otherWorkspaceID, _ := createProjectAndWorkspace(ctx, t, api)
+	pool := "pool-" + uuid.NewString()
+
+	exp := &model.Experiment{
+		JobID:                model.JobID(uuid.New().String()),
+		State:                model.PausedState,
+		OwnerID:              &curUser.ID,
+		ProjectID:            projectID,
+		StartTime:            time.Now(),
+		ModelDefinitionBytes: []byte{10, 11, 12},
+		Config: schemas.Merge(expconf.ExperimentConfig{
+			RawResources: &expconf.ResourcesConfig{RawResourcePool: ptrs.Ptr(pool)},
+		}, minExpConfig).(expconf.ExperimentConfig),
+	}
+	require.NoError(t, api.m.db.AddExperiment(exp))
+
+	// Pools without a binding can be used from every workspace.
+	require.NoError(t, checkExperimentResourcePoolBound(ctx, exp, otherWorkspaceID))
+
+	// Experiments can only be moved to workspaces their pool is bound to.
+	require.NoError(t, db.SetResourcePoolBinding(ctx, &model.ResourcePoolBinding{
+		ResourcePool: pool,
+		WorkspaceIDs: []int32{int32(boundWorkspaceID)},
+	}))
+	require.NoError(t, checkExperimentResourcePoolBound(ctx, exp, boundWorkspaceID))
+	require.IsType(t, ErrResourcePoolNotBound(""),
+		checkExperimentResourcePoolBound(ctx, exp, otherWorkspaceID))
+}
@@ -0,0 +1,36 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/protoutils"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+func (a *apiServer) GetExperimentSearcherObservations(
+	ctx context.Context, req *apiv1.GetExperimentSearcherObservationsRequest,
+) (*apiv1.GetExperimentSearcherObservationsResponse, error) {
+	exp, _, err := a.getExperimentAndCheckCanDoActions(ctx, int(req.ExperimentId), true)
+	if err != nil {
+		return nil, err
+	}
+	observations, err := db.ExperimentSearcherObservations(ctx, exp.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &apiv1.GetExperimentSearcherObservationsResponse{
+		SearcherMetric:  exp.Config.Searcher().Metric(),
+		SmallerIsBetter: exp.Config.Searcher().SmallerIsBetter(),
+		Observations:    make([]*apiv1.SearcherObservation, 0, len(observations)),
+	}
+	for _, o := range observations {
+		resp.Observations = append(resp.Observations, &apiv1.SearcherObservation{
+			TrialId: int32(o.TrialID),
+			Hparams: protoutils.ToStruct(o.HParams),
+			Metric:  o.Metric,
+		})
+	}
+	return resp, nil
+}
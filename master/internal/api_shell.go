@@ -32,7 +32,7 @@ import (
 const (
 	shellSSHDConfigFile   = "/run/determined/ssh/sshd_config"
 	shellEntrypointScript = "/run/determined/ssh/shell-entrypoint.sh"
-	// Agent ports 2600 - 3500 are split between TensorBoards, Notebooks, and Shells.
+	// Agent ports 2600 - 3800 are split between TensorBoards, Notebooks, Shells, and VS Code.
 	minSshdPort = 3200
 	maxSshdPort = minSshdPort + 299
 )
@@ -164,6 +164,7 @@ func (a *apiServer) LaunchShell(
 	spec.Base.ExtraEnvVars = map[string]string{"DET_TASK_TYPE": string(model.TaskTypeShell)}
 
 	var passphrase *string
+	var registeredKeysOnly bool
 	if len(req.Data) > 0 {
 		var data map[string]interface{}
 		if err = json.Unmarshal(req.Data, &data); err != nil {
@@ -174,15 +175,30 @@ func (a *apiServer) LaunchShell(
 				passphrase = &typed
 			}
 		}
+		if only, ok := data["registered_keys"].(bool); ok {
+			registeredKeysOnly = only
+		}
 	}
 
-	keys, err := ssh.GenerateKey(spec.Base.SSHRsaSize, passphrase)
+	// The SSH keys the owner registered are always authorized, so they can connect without the
+	// generated key; with registered_keys set, no key is generated at all.
+	spec.Base.AuthorizedKeys, err = user.AuthorizedSSHKeys(ctx, spec.Base.Owner.ID, false)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, err
+	}
+	switch {
+	case registeredKeysOnly && len(spec.Base.AuthorizedKeys) == 0:
+		return nil, status.Error(codes.FailedPrecondition,
+			"no SSH keys are registered; register one with `det user ssh-key add`")
+	case !registeredKeysOnly:
+		keys, err := ssh.GenerateKey(spec.Base.SSHRsaSize, passphrase)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		spec.Metadata.PrivateKey = ptrs.Ptr(string(keys.PrivateKey))
+		spec.Metadata.PublicKey = ptrs.Ptr(string(keys.PublicKey))
+		spec.Keys = &keys
 	}
-	spec.Metadata.PrivateKey = ptrs.Ptr(string(keys.PrivateKey))
-	spec.Metadata.PublicKey = ptrs.Ptr(string(keys.PublicKey))
-	spec.Keys = &keys
 
 	spec.ProxyTCP = true
 	// Shell authentication happens through SSH keys, instead.
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"context"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -33,3 +34,32 @@ func (a *apiServer) GetTask(
 			errors.Wrapf(err, "error fetching task %s from database", req.TaskId)
 	}
 }
+
+func (a *apiServer) GetTaskSystemMetrics(
+	ctx context.Context, req *apiv1.GetTaskSystemMetricsRequest,
+) (*apiv1.GetTaskSystemMetricsResponse, error) {
+	taskID := model.TaskID(req.TaskId)
+	if err := a.canDoActionsOnTask(ctx, taskID,
+		expauth.AuthZProvider.Get().CanGetExperimentArtifacts); err != nil {
+		return nil, err
+	}
+
+	var start, end time.Time
+	if req.StartTime != nil {
+		start = req.StartTime.AsTime()
+	}
+	if req.EndTime != nil {
+		end = req.EndTime.AsTime()
+	}
+	metrics, err := db.TaskSystemMetrics(
+		ctx, taskID, model.AllocationID(req.AllocationId), start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &apiv1.GetTaskSystemMetricsResponse{}
+	for _, m := range metrics {
+		resp.Metrics = append(resp.Metrics, m.Proto())
+	}
+	return resp, nil
+}
@@ -3,6 +3,7 @@ package internal
 import (
 	"context"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/go-multierror"
@@ -23,6 +25,7 @@ import (
 	"github.com/determined-ai/determined/master/internal/user"
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/logv1"
 	"github.com/determined-ai/determined/proto/pkg/taskv1"
 )
 
@@ -31,6 +34,10 @@ const (
 	taskLogsBatchSize  = 1000
 )
 
+// defaultLogsHeartbeatPeriod is how often followers of logs are sent a heartbeat while no logs are
+// written, unless they ask for another period.
+const defaultLogsHeartbeatPeriod = 15 * time.Second
+
 var (
 	taskReadyCheckLogs = "/run/determined/check_ready_logs.py"
 
@@ -202,6 +209,38 @@ func (a *apiServer) AllocationWaiting(
 	return &apiv1.AllocationWaitingResponse{}, nil
 }
 
+func (a *apiServer) PreemptAllocation(
+	ctx context.Context, req *apiv1.PreemptAllocationRequest,
+) (*apiv1.PreemptAllocationResponse, error) {
+	if err := userShouldBeAdmin(ctx, a); err != nil {
+		return nil, err
+	}
+
+	resp, err := a.m.rm.GetAllocationHandler(
+		a.m.system,
+		sproto.GetAllocationHandler{ID: model.AllocationID(req.AllocationId)},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.ask(resp.Address(), sproto.AllocationSignalWithReason{
+		AllocationSignal:    sproto.PreemptAllocation,
+		InformationalReason: adminPreemptionReason(req.Reason),
+	}, nil); err != nil {
+		return nil, err
+	}
+	return &apiv1.PreemptAllocationResponse{}, nil
+}
+
+// adminPreemptionReason describes a preemption requested through the API.
+func adminPreemptionReason(reason string) string {
+	if reason == "" {
+		return "preempted by an administrator"
+	}
+	return fmt.Sprintf("preempted by an administrator: %s", reason)
+}
+
 func (a *apiServer) AllocationAllGather(
 	ctx context.Context, req *apiv1.AllocationAllGatherRequest,
 ) (*apiv1.AllocationAllGatherResponse, error) {
@@ -290,15 +329,157 @@ func (a *apiServer) TaskLogs(
 
 	return processBatches(res, func(b api.Batch) error {
 		return b.ForEach(func(i interface{}) error {
-			pl, pErr := i.(*model.TaskLog).Proto()
+			l := i.(*model.TaskLog)
+			pl, pErr := l.Proto()
 			if pErr != nil {
 				return pErr
 			}
+			pl.Cursor = taskLogCursor(l)
 			return resp.Send(pl)
 		})
 	})
 }
 
+// taskLogCursor returns the cursor that marks a log, or nothing if the log backend does not
+// support cursors.
+func taskLogCursor(l *model.TaskLog) string {
+	if l.ID == nil {
+		return ""
+	}
+	return api.Cursor{ID: *l.ID, Timestamp: l.Timestamp}.Encode()
+}
+
+func (a *apiServer) FollowTaskLogs(
+	req *apiv1.FollowTaskLogsRequest, resp apiv1.Determined_FollowTaskLogsServer,
+) error {
+	taskID := model.TaskID(req.TaskId)
+	checkAuth := func() error {
+		return a.canDoActionsOnTask(resp.Context(), taskID,
+			expauth.AuthZProvider.Get().CanGetExperimentArtifacts)
+	}
+	if err := checkAuth(); err != nil {
+		return err
+	}
+	return a.followTaskLogs(resp.Context(), taskID, followLogsFilters(req.RankIds, req.Levels),
+		req.HeartbeatPeriodSeconds, checkAuth, resp.Send)
+}
+
+// followTaskLogs sends the logs of a task that match the filters and then waits to be told of new
+// logs by the log notifier, rather than polling the backend for them, until the task ends. The
+// backend is still read once per heartbeat, since logs that are shipped to it directly are not
+// seen by the notifier, and that is also when the task is checked for having ended.
+func (a *apiServer) followTaskLogs(
+	ctx context.Context, taskID model.TaskID, filters []api.Filter, heartbeatPeriodSeconds int32,
+	checkAuth func() error, send func(*apiv1.FollowLogsResponse) error,
+) error {
+	if heartbeatPeriodSeconds < 0 {
+		return status.Error(codes.InvalidArgument, "heartbeat period must not be negative")
+	}
+	heartbeatPeriod := defaultLogsHeartbeatPeriod
+	if heartbeatPeriodSeconds > 0 {
+		heartbeatPeriod = time.Duration(heartbeatPeriodSeconds) * time.Second
+	}
+
+	// Subscribe before the first read so that no log written in between is missed.
+	notify, unsubscribe := a.m.taskLogs.Subscribe(taskID)
+	defer unsubscribe()
+	heartbeat := time.NewTicker(heartbeatPeriod)
+	defer heartbeat.Stop()
+	recheckAuth := time.NewTicker(recheckAuthPeriod)
+	defer recheckAuth.Stop()
+	isTerminal := a.isTaskTerminalFunc(taskID, a.m.taskLogBackend.MaxTerminationDelay())
+
+	// Logs are read in the order they were written, rather than by timestamp, so that logs that
+	// are shipped late are not skipped.
+	var followState interface{}
+	sendNew := func() error {
+		for {
+			logs, state, err := a.m.taskLogBackend.TaskLogs(
+				taskID, taskLogsBatchSize, filters, apiv1.OrderBy_ORDER_BY_UNSPECIFIED, followState)
+			if err != nil {
+				return err
+			}
+			followState = state
+			for _, l := range logs {
+				pl, err := l.Proto()
+				if err != nil {
+					return err
+				}
+				if err := send(&apiv1.FollowLogsResponse{
+					Event: &apiv1.FollowLogsResponse_Log{Log: pl},
+				}); err != nil {
+					return err
+				}
+			}
+			if len(logs) < taskLogsBatchSize {
+				return nil
+			}
+		}
+	}
+
+	if err := sendNew(); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-notify:
+			if err := sendNew(); err != nil {
+				return err
+			}
+		case <-heartbeat.C:
+			// Check whether the task ended before reading, so that logs written right before it
+			// ended are still sent.
+			terminal, err := isTerminal()
+			if err != nil {
+				return err
+			}
+			if err := sendNew(); err != nil {
+				return err
+			}
+			if terminal {
+				return nil
+			}
+			if err := send(&apiv1.FollowLogsResponse{
+				Event: &apiv1.FollowLogsResponse_Heartbeat{
+					Heartbeat: timestamppb.New(time.Now().UTC()),
+				},
+			}); err != nil {
+				return err
+			}
+		case <-recheckAuth.C:
+			if err := checkAuth(); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// followLogsFilters returns the filters for following logs of only some ranks and levels.
+func followLogsFilters(rankIDs []int32, levels []logv1.LogLevel) []api.Filter {
+	var filters []api.Filter
+	if len(rankIDs) > 0 {
+		filters = append(filters, api.Filter{
+			Field:     "rank_id",
+			Operation: api.FilterOperationIn,
+			Values:    rankIDs,
+		})
+	}
+	if len(levels) > 0 {
+		var values []string
+		for _, l := range levels {
+			values = append(values, model.TaskLogLevelFromProto(l))
+		}
+		filters = append(filters, api.Filter{
+			Field:     "level",
+			Operation: api.FilterOperationIn,
+			Values:    values,
+		})
+	}
+	return filters
+}
+
 func (a *apiServer) GetActiveTasksCount(
 	ctx context.Context, req *apiv1.GetActiveTasksCountRequest,
 ) (resp *apiv1.GetActiveTasksCountResponse, err error) {
@@ -355,6 +536,17 @@ func (a *apiServer) GetActiveTasksCount(
 		}
 	}
 
+	req5 := &apiv1.GetVSCodesRequest{}
+	resp5 := &apiv1.GetVSCodesResponse{}
+	if err = a.ask(vscodesAddr, req5, &resp5); err != nil {
+		return nil, err
+	}
+	for _, v := range resp5.Vscodes {
+		if v.State == taskv1.State_STATE_RUNNING {
+			finalResp.Vscodes++
+		}
+	}
+
 	return finalResp, err
 }
 
@@ -369,8 +561,36 @@ func (a *apiServer) taskLogs(
 		)
 		return
 	}
+	if req.SearchRegex && req.SearchText != "" {
+		if err = a.m.taskLogBackend.ValidateTaskLogsRegex(req.SearchText); err != nil {
+			res <- api.ErrBatchResult(api.APIErrToGRPC(
+				errors.Wrap(err, "invalid regular expression")))
+			return
+		}
+	}
+
+	cursor, err := api.DecodeCursor(req.Cursor, 0)
+	if err != nil {
+		res <- api.ErrBatchResult(err)
+		return
+	}
+	if cursor != nil && req.Follow {
+		res <- api.ErrBatchResult(
+			status.Error(codes.InvalidArgument, "cursor and follow cannot both be set"))
+		return
+	}
+
+	// Followed logs are read in the order they were written, rather than by timestamp, so that
+	// logs that are shipped late are not skipped.
+	order := req.OrderBy
+	if req.Follow {
+		order = apiv1.OrderBy_ORDER_BY_UNSPECIFIED
+	}
 
 	var followState interface{}
+	if cursor != nil {
+		followState = cursor
+	}
 	var timeSinceLastAuth time.Time
 	fetch := func(r api.BatchRequest) (api.Batch, error) {
 		if time.Now().Sub(timeSinceLastAuth) >= recheckAuthPeriod {
@@ -390,7 +610,7 @@ func (a *apiServer) taskLogs(
 		}
 
 		b, state, fErr := a.m.taskLogBackend.TaskLogs(
-			taskID, r.Limit, filters, req.OrderBy, followState)
+			taskID, r.Limit, filters, order, followState)
 		if fErr != nil {
 			return nil, fErr
 		}
@@ -399,15 +619,15 @@ func (a *apiServer) taskLogs(
 		return model.TaskLogBatch(b), nil
 	}
 
-	total, err := a.m.taskLogBackend.TaskLogsCount(taskID, filters)
-	if err != nil {
-		res <- api.ErrBatchResult(fmt.Errorf("getting log count from backend: %w", err))
-		return
+	// Without a limit, logs are streamed until a batch comes back empty, rather than counted first,
+	// which would mean reading every log twice.
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = math.MaxInt
 	}
-	effectiveLimit := api.EffectiveLimit(int(req.Limit), 0, total)
 
 	api.NewBatchStreamProcessor(
-		api.BatchRequest{Limit: effectiveLimit, Follow: req.Follow},
+		api.BatchRequest{Limit: limit, Follow: req.Follow},
 		fetch,
 		a.isTaskTerminalFunc(taskID, a.m.taskLogBackend.MaxTerminationDelay()),
 		false,
@@ -465,7 +685,15 @@ func constructTaskLogsFilters(req *apiv1.TaskLogsRequest) ([]api.Filter, error)
 		})
 	}
 
-	if req.SearchText != "" {
+	switch {
+	case req.SearchText == "":
+	case req.SearchRegex:
+		filters = append(filters, api.Filter{
+			Field:     "log",
+			Operation: api.FilterOperationRegexMatch,
+			Values:    req.SearchText,
+		})
+	default:
 		filters = append(filters, api.Filter{
 			Field:     "log",
 			Operation: api.FilterOperationStringContainment,
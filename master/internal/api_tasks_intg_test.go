@@ -6,12 +6,15 @@ package internal
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
 	"github.com/determined-ai/determined/proto/pkg/checkpointv1"
 )
@@ -59,6 +62,11 @@ func TestTaskAuthZ(t *testing.T) {
 				TaskId: id,
 			}, mockStream[*apiv1.TaskLogsFieldsResponse]{ctx})
 		}},
+		{"CanGetExperimentArtifacts", func(id string) error {
+			return api.FollowTaskLogs(&apiv1.FollowTaskLogsRequest{
+				TaskId: id,
+			}, mockStream[*apiv1.FollowLogsResponse]{ctx})
+		}},
 	}
 
 	for _, curCase := range cases {
@@ -84,3 +92,64 @@ func TestTaskAuthZ(t *testing.T) {
 		require.ErrorIs(t, curCase.IDToReqCall(taskID), expectedErr)
 	}
 }
+
+func TestFollowTaskLogs(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t)
+	trial := createTestTrial(t, api, curUser)
+
+	addLog := func(msg string, rank int) {
+		require.NoError(t, api.m.taskLogBackend.AddTaskLogs([]*model.TaskLog{{
+			TaskID:    string(trial.TaskID),
+			Log:       msg + "\n",
+			RankID:    ptrs.Ptr(rank),
+			Level:     ptrs.Ptr("INFO"),
+			Timestamp: ptrs.Ptr(time.Now()),
+		}}))
+	}
+	addLog("before", 0)
+	addLog("other rank", 1)
+
+	events := make(chan *apiv1.FollowLogsResponse, 16)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- api.followTaskLogs(ctx, trial.TaskID,
+			followLogsFilters([]int32{0}, nil), 1, func() error { return nil },
+			func(resp *apiv1.FollowLogsResponse) error {
+				events <- resp
+				return nil
+			})
+	}()
+	nextLog := func() string {
+		for {
+			select {
+			case e := <-events:
+				if l := e.GetLog(); l != nil {
+					return l.Message
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("timed out waiting for a log")
+			}
+		}
+	}
+	require.Contains(t, nextLog(), "before")
+
+	// New logs are sent as they are added, and heartbeats are sent while there are none.
+	addLog("other rank again", 1)
+	addLog("after", 0)
+	require.Contains(t, nextLog(), "after")
+	select {
+	case e := <-events:
+		require.NotNil(t, e.GetHeartbeat())
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for a heartbeat")
+	}
+
+	// The stream ends once the task has ended.
+	require.NoError(t, api.m.db.CompleteTask(trial.TaskID, time.Now().Add(-time.Minute)))
+	select {
+	case err := <-errs:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the stream to end")
+	}
+}
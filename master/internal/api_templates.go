@@ -9,7 +9,10 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 
+	"github.com/determined-ai/determined/master/internal/auditlog"
 	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
 	"github.com/determined-ai/determined/proto/pkg/templatev1"
 )
@@ -43,21 +46,33 @@ func (a *apiServer) GetTemplate(
 }
 
 func (a *apiServer) PutTemplate(
-	_ context.Context, req *apiv1.PutTemplateRequest,
+	ctx context.Context, req *apiv1.PutTemplateRequest,
 ) (*apiv1.PutTemplateResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
 	config, err := protojson.Marshal(req.Template.Config)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid config provided: %s", err.Error())
 	}
 	err = a.m.db.QueryProto("put_template", req.Template, req.Template.Name, config)
+	recordTemplateChange(ctx, curUser, auditlog.TemplatePut, req.Template.Name, err,
+		auditlog.DetailsFromProto(req.Template))
 	return &apiv1.PutTemplateResponse{Template: req.Template},
 		errors.Wrapf(err, "error putting template")
 }
 
 func (a *apiServer) DeleteTemplate(
-	_ context.Context, req *apiv1.DeleteTemplateRequest,
+	ctx context.Context, req *apiv1.DeleteTemplateRequest,
 ) (*apiv1.DeleteTemplateResponse, error) {
-	switch err := a.m.db.DeleteTemplate(req.TemplateName); err {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = a.m.db.DeleteTemplate(req.TemplateName)
+	recordTemplateChange(ctx, curUser, auditlog.TemplateDelete, req.TemplateName, err, nil)
+	switch err {
 	case nil:
 		return &apiv1.DeleteTemplateResponse{}, nil
 	case db.ErrNotFound:
@@ -67,3 +82,18 @@ func (a *apiServer) DeleteTemplate(
 		return nil, err
 	}
 }
+
+// recordTemplateChange records a change to a template, which sets configuration defaults for the
+// experiments that use it, in the audit log.
+func recordTemplateChange(
+	ctx context.Context, curUser *model.User, action auditlog.Action, name string, err error,
+	details map[string]interface{},
+) {
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     action,
+		TargetType: "template",
+		TargetID:   name,
+		Success:    err == nil,
+		Details:    details,
+	})
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"net/url"
 	"os"
-	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -41,7 +40,7 @@ import (
 )
 
 const (
-	// Agent ports 2600 - 3500 are split between TensorBoards, Notebooks, and Shells.
+	// Agent ports 2600 - 3800 are split between TensorBoards, Notebooks, Shells, and VS Code.
 	minTensorBoardPort        = 2600
 	maxTensorBoardPort        = minTensorBoardPort + 299
 	tensorboardEntrypointFile = "/run/determined/tensorboard/tensorboard-entrypoint.sh"
@@ -211,8 +210,6 @@ func (a *apiServer) LaunchTensorboard(
 	}
 
 	for _, exp := range exps {
-		var logBasePath string
-
 		switch c := exp.Config.CheckpointStorage().GetUnionMember().(type) {
 		case expconf.SharedFSConfig:
 			// Mount the checkpoint location into the TensorBoard container to
@@ -224,7 +221,6 @@ func (a *apiServer) LaunchTensorboard(
 				RawPropagation:   ptrs.Ptr(expconf.DefaultSharedFSPropagation),
 			}).(expconf.BindMount)
 			uniqMounts[sharedFSMount.ContainerPath()] = model.ToModelBindMount(sharedFSMount)
-			logBasePath = c.PathInContainer()
 
 		case expconf.S3Config:
 			if c.AccessKey() != nil {
@@ -253,27 +249,9 @@ func (a *apiServer) LaunchTensorboard(
 
 			uniqEnvVars["AWS_BUCKET"] = c.Bucket()
 
-			prefix := c.Prefix()
-			if prefix != nil {
-				logBasePath = fmt.Sprintf("s3://%s", filepath.Join(c.Bucket(), *prefix))
-			} else {
-				logBasePath = fmt.Sprintf("s3://%s", c.Bucket())
-			}
-
-		case expconf.AzureConfig:
-			logBasePath = "azure://" + c.Container()
-
-		case expconf.GCSConfig:
-			prefix := c.Prefix()
-			if prefix != nil {
-				logBasePath = fmt.Sprintf("gs://%s", filepath.Join(c.Bucket(), *prefix))
-			} else {
-				logBasePath = fmt.Sprintf("gs://%s", c.Bucket())
-			}
+		case expconf.AzureConfig, expconf.GCSConfig:
 
 		case expconf.HDFSConfig:
-			logBasePath = "hdfs://" + c.Path()
-
 			// The credentials files for HDFS exist on agent machines and are
 			// bind mounted into the container.
 			for _, mount := range exp.Config.BindMounts() {
@@ -285,16 +263,18 @@ func (a *apiServer) LaunchTensorboard(
 				"unknown storage backend for experiment: %T", c)
 		}
 
+		// Log directories are relative to the root of the checkpoint storage, which the
+		// TensorBoard fetches them from through the same storage managers as checkpoints.
 		if len(exp.TrialIDs) == 0 {
-			expDir := fmt.Sprintf("%s/%s/tensorboard/experiment/%d/",
-				logBasePath, spec.Base.ClusterID, exp.ExperimentID)
+			expDir := fmt.Sprintf("%s/tensorboard/experiment/%d/",
+				spec.Base.ClusterID, exp.ExperimentID)
 			logDirs = append(logDirs, expDir)
 			continue
 		}
 
 		for _, id := range exp.TrialIDs {
-			trialDir := fmt.Sprintf("%s/%s/tensorboard/experiment/%d/trial/%d/",
-				logBasePath, spec.Base.ClusterID, exp.ExperimentID, id)
+			trialDir := fmt.Sprintf("%s/tensorboard/experiment/%d/trial/%d/",
+				spec.Base.ClusterID, exp.ExperimentID, id)
 
 			logDirs = append(logDirs, trialDir)
 		}
@@ -3,33 +3,43 @@ package internal
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"math"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/ghodss/yaml"
 	"github.com/google/uuid"
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/determined-ai/determined/master/internal/api"
+	"github.com/determined-ai/determined/master/internal/coldarchive"
 	"github.com/determined-ai/determined/master/internal/db"
 	expauth "github.com/determined-ai/determined/master/internal/experiment"
 	"github.com/determined-ai/determined/master/internal/grpcutil"
 	"github.com/determined-ai/determined/master/internal/lttb"
 	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/internal/stream"
 	"github.com/determined-ai/determined/master/internal/task"
+	"github.com/determined-ai/determined/master/internal/webhooks"
 	"github.com/determined-ai/determined/master/pkg/actor"
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/master/pkg/protoutils"
 	"github.com/determined-ai/determined/master/pkg/protoutils/protoconverter"
 	"github.com/determined-ai/determined/master/pkg/protoutils/protoless"
 	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/master/pkg/schemas"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
 	"github.com/determined-ai/determined/master/pkg/searcher"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
 	"github.com/determined-ai/determined/proto/pkg/checkpointv1"
@@ -40,6 +50,9 @@ import (
 const (
 	trialLogsBatchSize            = 1000
 	trialProfilerMetricsBatchSize = 100
+	// defaultProfilerTimelinePoints is the number of points that profiler timelines are
+	// downsampled to when no number is requested.
+	defaultProfilerTimelinePoints = 500
 )
 
 var (
@@ -78,7 +91,8 @@ func (a *apiServer) canGetTrialsExperimentAndCheckCanDoAction(ctx context.Contex
 	if err = actionFunc(ctx, *curUser, exp); err != nil {
 		return status.Error(codes.PermissionDenied, err.Error())
 	}
-	return nil
+	// Load the metrics and logs of the trial back into the database if they were archived.
+	return coldarchive.Rehydrate(ctx, exp.ID)
 }
 
 // TrialLogBackend is an interface trial log backends, such as elastic or postgres,
@@ -171,19 +185,22 @@ func (a *apiServer) TrialLogs(
 		// This indicates the trial is existed before the task logs table, and has version 0.
 		fallthrough
 	case t.LogVersion == model.TaskLogVersion0:
-		// First stream the legacy logs.
-		res := make(chan api.BatchResult, taskLogsChanBuffer)
-		go a.legacyTrialLogs(ctx, req, res)
-		if err := processBatches(res, func(b api.Batch) error {
-			return b.ForEach(func(i interface{}) error {
-				l, err := i.(*model.TrialLog).Proto()
-				if err != nil {
-					return err
-				}
-				return resp.Send(l)
-			})
-		}); err != nil {
-			return err
+		// First stream the legacy logs. They have no cursors, so a request with a cursor only
+		// continues through the logs stored by task.
+		if req.Cursor == "" {
+			res := make(chan api.BatchResult, taskLogsChanBuffer)
+			go a.legacyTrialLogs(ctx, req, res)
+			if err := processBatches(res, func(b api.Batch) error {
+				return b.ForEach(func(i interface{}) error {
+					l, err := i.(*model.TrialLog).Proto()
+					if err != nil {
+						return err
+					}
+					return resp.Send(l)
+				})
+			}); err != nil {
+				return err
+			}
 		}
 		// Then fallthrough and stream the remaining logs, in the event the trial spanned an
 		// upgrade. In the event it did not, this should return quickly anyway.
@@ -205,10 +222,12 @@ func (a *apiServer) TrialLogs(
 			TimestampAfter:  req.TimestampAfter,
 			OrderBy:         req.OrderBy,
 			SearchText:      req.SearchText,
+			Cursor:          req.Cursor,
 		}, res)
 		return processBatches(res, func(b api.Batch) error {
 			return b.ForEach(func(i interface{}) error {
-				l, err := i.(*model.TaskLog).Proto()
+				tl := i.(*model.TaskLog)
+				l, err := tl.Proto()
 				if err != nil {
 					return err
 				}
@@ -217,6 +236,7 @@ func (a *apiServer) TrialLogs(
 					Timestamp: l.Timestamp,
 					Message:   l.Message,
 					Level:     l.Level,
+					Cursor:    taskLogCursor(tl),
 				})
 			})
 		})
@@ -225,6 +245,70 @@ func (a *apiServer) TrialLogs(
 	}
 }
 
+func (a *apiServer) SearchTrialLogs(
+	req *apiv1.SearchTrialLogsRequest, resp apiv1.Determined_SearchTrialLogsServer,
+) error {
+	if err := grpcutil.ValidateRequest(grpcutil.ValidateLimit(req.Limit)); err != nil {
+		return err
+	}
+	if req.Query == "" {
+		return status.Error(codes.InvalidArgument, "query must be set")
+	}
+	if err := a.canGetTrialsExperimentAndCheckCanDoAction(resp.Context(), int(req.TrialId),
+		expauth.AuthZProvider.Get().CanGetExperimentArtifacts); err != nil {
+		return err
+	}
+
+	t, err := a.m.db.TrialByID(int(req.TrialId))
+	if err != nil {
+		return err
+	}
+	switch task, err := a.m.db.TaskByID(t.TaskID); {
+	case errors.Is(err, sql.ErrNoRows):
+		fallthrough
+	case err == nil && task.LogVersion == model.TaskLogVersion0:
+		return status.Errorf(codes.FailedPrecondition,
+			"trial %d was created before its logs could be searched", req.TrialId)
+	case err != nil:
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(resp.Context())
+	defer cancel()
+
+	res := make(chan api.BatchResult, taskLogsChanBuffer)
+	go a.taskLogs(ctx, &apiv1.TaskLogsRequest{
+		TaskId:          string(t.TaskID),
+		Limit:           req.Limit,
+		RankIds:         req.RankIds,
+		TimestampBefore: req.TimestampBefore,
+		TimestampAfter:  req.TimestampAfter,
+		OrderBy:         req.OrderBy,
+		SearchText:      req.Query,
+		SearchRegex:     req.Regex,
+	}, res)
+	return processBatches(res, func(b api.Batch) error {
+		return b.ForEach(func(i interface{}) error {
+			tl := i.(*model.TaskLog)
+			l, err := tl.Proto()
+			if err != nil {
+				return err
+			}
+			var rankID *int32
+			if tl.RankID != nil {
+				rankID = ptrs.Ptr(int32(*tl.RankID))
+			}
+			return resp.Send(&apiv1.SearchTrialLogsResponse{
+				Id:        l.Id,
+				Timestamp: l.Timestamp,
+				Message:   l.Message,
+				Level:     l.Level,
+				RankId:    rankID,
+			})
+		})
+	})
+}
+
 func (a *apiServer) legacyTrialLogs(
 	ctx context.Context, req *apiv1.TrialLogsRequest, res chan api.BatchResult,
 ) {
@@ -337,6 +421,26 @@ func constructTrialLogsFilters(req *apiv1.TrialLogsRequest) ([]api.Filter, error
 	return filters, nil
 }
 
+func (a *apiServer) FollowTrialLogs(
+	req *apiv1.FollowTrialLogsRequest, resp apiv1.Determined_FollowTrialLogsServer,
+) error {
+	checkAuth := func() error {
+		return a.canGetTrialsExperimentAndCheckCanDoAction(resp.Context(), int(req.TrialId),
+			expauth.AuthZProvider.Get().CanGetExperimentArtifacts)
+	}
+	if err := checkAuth(); err != nil {
+		return err
+	}
+
+	trial, err := a.m.db.TrialByID(int(req.TrialId))
+	if err != nil {
+		return errors.Wrap(err, "retrieving trial")
+	}
+	return a.followTaskLogs(resp.Context(), trial.TaskID,
+		followLogsFilters(req.RankIds, req.Levels), req.HeartbeatPeriodSeconds, checkAuth,
+		resp.Send)
+}
+
 func (a *apiServer) TrialLogsFields(
 	req *apiv1.TrialLogsFieldsRequest, resp apiv1.Determined_TrialLogsFieldsServer,
 ) error {
@@ -459,7 +563,8 @@ func (a *apiServer) GetTrialCheckpoints(
 		return true
 	})
 
-	sort.Slice(resp.Checkpoints, func(i, j int) bool {
+	// Sort stably, so that checkpoints that tie keep their order across pages.
+	sort.SliceStable(resp.Checkpoints, func(i, j int) bool {
 		ai, aj := resp.Checkpoints[i], resp.Checkpoints[j]
 		if req.OrderBy == apiv1.OrderBy_ORDER_BY_DESC {
 			aj, ai = ai, aj
@@ -481,7 +586,8 @@ func (a *apiServer) GetTrialCheckpoints(
 		}
 	})
 
-	return resp, a.paginate(&resp.Pagination, &resp.Checkpoints, req.Offset, req.Limit)
+	return resp, a.paginateCheckpoints(
+		&resp.Pagination, &resp.Checkpoints, req.Offset, req.Limit, req.Cursor)
 }
 
 func (a *apiServer) KillTrial(
@@ -507,6 +613,308 @@ func (a *apiServer) KillTrial(
 	return &apiv1.KillTrialResponse{}, nil
 }
 
+func (a *apiServer) PreemptTrial(
+	ctx context.Context, req *apiv1.PreemptTrialRequest,
+) (*apiv1.PreemptTrialResponse, error) {
+	if err := userShouldBeAdmin(ctx, a); err != nil {
+		return nil, err
+	}
+	t, err := a.m.db.TrialByID(int(req.Id))
+	if err != nil {
+		return nil, err
+	}
+
+	tr := actor.Addr("experiments", t.ExperimentID, t.RequestID)
+	if err = a.ask(tr, sproto.AllocationSignalWithReason{
+		AllocationSignal:    sproto.PreemptAllocation,
+		InformationalReason: adminPreemptionReason(req.Reason),
+	}, nil); err != nil {
+		return nil, err
+	}
+	return &apiv1.PreemptTrialResponse{}, nil
+}
+
+// ForkTrial creates an experiment that continues training a single trial from one of its
+// checkpoints, with its hyperparameters fixed to those of the trial and any given overrides.
+func (a *apiServer) ForkTrial(
+	ctx context.Context, req *apiv1.ForkTrialRequest,
+) (*apiv1.ForkTrialResponse, error) {
+	trialID := int(req.TrialId)
+	if err := a.canGetTrialsExperimentAndCheckCanDoAction(ctx, trialID,
+		expauth.AuthZProvider.Get().CanForkFromExperiment); err != nil {
+		return nil, err
+	}
+	t, err := a.m.db.TrialByID(trialID)
+	if err != nil {
+		return nil, err
+	}
+	exp, err := a.m.db.ExperimentByID(t.ExperimentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ckpt *model.Checkpoint
+	if req.CheckpointUuid == "" {
+		if ckpt, err = a.m.db.LatestCheckpointForTrial(trialID); err != nil {
+			return nil, err
+		} else if ckpt == nil {
+			return nil, status.Errorf(codes.FailedPrecondition,
+				"trial %d has no completed checkpoint to fork from", trialID)
+		}
+	} else {
+		var ckptUUID uuid.UUID
+		if ckptUUID, err = uuid.Parse(req.CheckpointUuid); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"invalid checkpoint uuid %s: %s", req.CheckpointUuid, err)
+		}
+		if ckpt, err = a.m.db.CheckpointByUUID(ckptUUID); err != nil {
+			return nil, err
+		} else if ckpt == nil {
+			return nil, status.Errorf(codes.NotFound,
+				"checkpoint %s not found", req.CheckpointUuid)
+		} else if ckpt.TrialID != trialID {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"checkpoint %s does not belong to trial %d", req.CheckpointUuid, trialID)
+		}
+	}
+
+	config, err := forkTrialConfig(exp.Config, t, ckpt, req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+	}
+	resp, err := a.createTrialExperiment(ctx, exp, config, req.ProjectId, req.Activate)
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.ForkTrialResponse{Experiment: resp.Experiment, Config: resp.Config}, nil
+}
+
+// GetTrialReplayState returns the seeds of a trial and the recorded data sharding of its runs.
+func (a *apiServer) GetTrialReplayState(
+	ctx context.Context, req *apiv1.GetTrialReplayStateRequest,
+) (*apiv1.GetTrialReplayStateResponse, error) {
+	trialID := int(req.TrialId)
+	if err := a.canGetTrialsExperimentAndCheckCanDoAction(ctx, trialID,
+		expauth.AuthZProvider.Get().CanGetExperimentArtifacts); err != nil {
+		return nil, err
+	}
+	t, err := a.m.db.TrialByID(trialID)
+	if err != nil {
+		return nil, err
+	}
+	exp, err := a.m.db.ExperimentByID(t.ExperimentID)
+	if err != nil {
+		return nil, err
+	}
+	states, err := db.TrialRunStates(ctx, trialID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &apiv1.GetTrialReplayStateResponse{TrialSeed: uint32(t.Seed)}
+	if r := exp.Config.RawReproducibility; r != nil && r.RawExperimentSeed != nil {
+		resp.ExperimentSeed = *r.RawExperimentSeed
+	}
+	for _, s := range states {
+		resp.Runs = append(resp.Runs, s.Proto())
+	}
+	return resp, nil
+}
+
+// ReplayTrial creates an experiment that trains a single trial the way a trial was trained from
+// its start, so that nondeterministic failures of the trial can be reproduced.
+func (a *apiServer) ReplayTrial(
+	ctx context.Context, req *apiv1.ReplayTrialRequest,
+) (*apiv1.ReplayTrialResponse, error) {
+	trialID := int(req.TrialId)
+	if err := a.canGetTrialsExperimentAndCheckCanDoAction(ctx, trialID,
+		expauth.AuthZProvider.Get().CanForkFromExperiment); err != nil {
+		return nil, err
+	}
+	t, err := a.m.db.TrialByID(trialID)
+	if err != nil {
+		return nil, err
+	}
+	exp, err := a.m.db.ExperimentByID(t.ExperimentID)
+	if err != nil {
+		return nil, err
+	}
+	states, err := db.TrialRunStates(ctx, trialID)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := replayTrialConfig(exp.Config, t, states, req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+	}
+	resp, err := a.createTrialExperiment(ctx, exp, config, req.ProjectId, req.Activate)
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.ReplayTrialResponse{Experiment: resp.Experiment, Config: resp.Config}, nil
+}
+
+// createTrialExperiment creates an experiment with the config, derived from a trial of the parent
+// experiment, in the given project or else that of the parent.
+func (a *apiServer) createTrialExperiment(
+	ctx context.Context, parent *model.Experiment, config expconf.ExperimentConfig,
+	projectID int32, activate bool,
+) (*apiv1.CreateExperimentResponse, error) {
+	configBytes, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	if projectID == 0 {
+		projectID = int32(parent.ProjectID)
+	}
+	return a.CreateExperiment(ctx, &apiv1.CreateExperimentRequest{
+		Config:    string(configBytes),
+		ParentId:  int32(parent.ID),
+		Activate:  activate,
+		ProjectId: projectID,
+	})
+}
+
+// forkTrialConfig returns the config of an experiment that continues training a trial of the
+// experiment with the given config from a checkpoint. The trial is trained by a single searcher
+// with constant hyperparameters, which the YAML config of the request is merged over.
+func forkTrialConfig(
+	parent expconf.ExperimentConfig, t *model.Trial, ckpt *model.Checkpoint,
+	req *apiv1.ForkTrialRequest,
+) (expconf.ExperimentConfig, error) {
+	hparams := map[string]interface{}(t.HParams)
+	if req.Hparams != nil {
+		hparams = mergeHParams(hparams, req.Hparams.AsMap())
+	}
+	config, err := singleTrialConfig(parent, hparams, req.MaxLength)
+	if err != nil {
+		return expconf.ExperimentConfig{}, err
+	}
+	config.RawName = expconf.Name{RawString: ptrs.Ptr(fmt.Sprintf(
+		"Fork of trial %d of experiment %d", t.ID, t.ExperimentID))}
+	config.RawDescription = ptrs.Ptr(fmt.Sprintf(
+		"Continuation of trial %d from checkpoint %s", t.ID, ckpt.UUID))
+
+	if config, err = mergeConfigYAML(config, req.Config); err != nil {
+		return expconf.ExperimentConfig{}, err
+	}
+	// The lineage of the new trial is always the checkpoint it was forked from.
+	config.RawSearcher.RawSourceTrialID = nil
+	config.RawSearcher.RawSourceCheckpointUUID = ptrs.Ptr(ckpt.UUID.String())
+	return config, nil
+}
+
+// replayTrialConfig returns the config of an experiment that replays a trial from its start. The
+// order of the data a trial trains on is determined by its seed, the number of slots it shards the
+// data across, and the checkpoint it starts from, so the replay uses those of the trial's first
+// run, along with the seed of its experiment; trials whose first run was not recorded are replayed
+// with the slots and source checkpoint of the experiment's config. The YAML config of the request
+// is merged over the generated one.
+func replayTrialConfig(
+	parent expconf.ExperimentConfig, t *model.Trial, states []model.TrialRunState,
+	req *apiv1.ReplayTrialRequest,
+) (expconf.ExperimentConfig, error) {
+	config, err := singleTrialConfig(parent, t.HParams, req.MaxLength)
+	if err != nil {
+		return expconf.ExperimentConfig{}, err
+	}
+	config.RawName = expconf.Name{RawString: ptrs.Ptr(fmt.Sprintf(
+		"Replay of trial %d of experiment %d", t.ID, t.ExperimentID))}
+	config.RawDescription = ptrs.Ptr(fmt.Sprintf(
+		"Replay of trial %d with seed %d", t.ID, t.Seed))
+
+	reproducibility := expconf.ReproducibilityConfig{RawTrialSeed: ptrs.Ptr(uint32(t.Seed))}
+	if parent.RawReproducibility != nil {
+		reproducibility.RawExperimentSeed = parent.RawReproducibility.RawExperimentSeed
+	}
+	config.RawReproducibility = &reproducibility
+
+	config.RawSearcher.RawSourceTrialID = parent.RawSearcher.RawSourceTrialID
+	config.RawSearcher.RawSourceCheckpointUUID = parent.RawSearcher.RawSourceCheckpointUUID
+	if len(states) > 0 && states[0].RunID == 1 {
+		first := states[0]
+		config.RawSearcher.RawSourceTrialID = nil
+		config.RawSearcher.RawSourceCheckpointUUID = nil
+		if first.CheckpointUUID != nil {
+			config.RawSearcher.RawSourceCheckpointUUID = ptrs.Ptr(first.CheckpointUUID.String())
+		}
+		// Resizing changes how the data is sharded, so the replay keeps the size it started at.
+		resources := expconf.ResourcesConfig{}
+		if config.RawResources != nil {
+			resources = *config.RawResources
+		}
+		resources.RawSlotsPerTrial = ptrs.Ptr(first.Slots)
+		resources.RawElastic = nil
+		config.RawResources = &resources
+	}
+
+	return mergeConfigYAML(config, req.Config)
+}
+
+// singleTrialConfig returns a copy of the config of an experiment that trains a single trial with
+// constant hyperparameters for the given length, in the units of the experiment's searcher, or
+// for that searcher's max length if it is zero.
+func singleTrialConfig(
+	parent expconf.ExperimentConfig, hparams map[string]interface{}, length uint64,
+) (expconf.ExperimentConfig, error) {
+	if parent.RawSearcher == nil {
+		return expconf.ExperimentConfig{}, errors.New("experiment has no searcher configured")
+	}
+	maxLength := parent.RawSearcher.MaxLength()
+	if length != 0 {
+		unit := expconf.Batches
+		if maxLength != nil {
+			unit = maxLength.Unit
+		}
+		maxLength = &expconf.LengthV0{Unit: unit, Units: length}
+	} else if maxLength == nil {
+		return expconf.ExperimentConfig{}, errors.New(
+			"max_length is required for a trial of a searcher without one")
+	}
+
+	config := schemas.Copy(parent).(expconf.ExperimentConfig)
+	config.RawHyperparameters = expconf.ConstHPs(parent.RawHyperparameters, hparams)
+	config.RawSearcher = &expconf.SearcherConfigV0{
+		RawSingleConfig:    &expconf.SingleConfigV0{RawMaxLength: maxLength},
+		RawMetric:          parent.RawSearcher.RawMetric,
+		RawSmallerIsBetter: parent.RawSearcher.RawSmallerIsBetter,
+	}
+	return config, nil
+}
+
+// mergeConfigYAML merges an experiment config in YAML, if any, over the config.
+func mergeConfigYAML(
+	config expconf.ExperimentConfig, overlayYAML string,
+) (expconf.ExperimentConfig, error) {
+	if overlayYAML == "" {
+		return config, nil
+	}
+	overlay, err := expconf.ParseAnyExperimentConfigYAML([]byte(overlayYAML))
+	if err != nil {
+		return expconf.ExperimentConfig{}, errors.Wrap(err, "invalid config")
+	}
+	return schemas.Merge(overlay, config).(expconf.ExperimentConfig), nil
+}
+
+// mergeHParams returns the hyperparameters with the overrides merged over them, recursing into
+// nested hyperparameters.
+func mergeHParams(hparams, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(hparams))
+	for key, val := range hparams {
+		merged[key] = val
+	}
+	for key, val := range overrides {
+		nested, ok := val.(map[string]interface{})
+		if current, isMap := merged[key].(map[string]interface{}); ok && isMap {
+			merged[key] = mergeHParams(current, nested)
+		} else {
+			merged[key] = val
+		}
+	}
+	return merged
+}
+
 func (a *apiServer) GetExperimentTrials(
 	ctx context.Context, req *apiv1.GetExperimentTrialsRequest,
 ) (resp *apiv1.GetExperimentTrialsResponse, err error) {
@@ -514,6 +922,9 @@ func (a *apiServer) GetExperimentTrials(
 		false, expauth.AuthZProvider.Get().CanGetExperimentArtifacts); err != nil {
 		return nil, err
 	}
+	if err = coldarchive.Rehydrate(ctx, int(req.ExperimentId)); err != nil {
+		return nil, err
+	}
 
 	// Construct the trial filtering expression.
 	var allStates []string
@@ -552,6 +963,29 @@ func (a *apiServer) GetExperimentTrials(
 	default:
 		orderExpr = fmt.Sprintf("id %s", sortByMap[req.OrderBy])
 	}
+	sortByID := orderColMap[req.SortBy] == "id"
+	cursor, err := api.DecodeCursor(req.Cursor, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+	var cursorID *int
+	if cursor != nil {
+		if !sortByID {
+			return nil, status.Error(codes.InvalidArgument,
+				"cursors are only supported when sorting trials by id")
+		}
+		cursorID = &cursor.ID
+	}
+
+	var metadataFilter *string
+	if req.Metadata != "" {
+		var metadata map[string]interface{}
+		if err = json.Unmarshal([]byte(req.Metadata), &metadata); err != nil || metadata == nil {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"metadata filter must be a JSON object: %s", req.Metadata)
+		}
+		metadataFilter = &req.Metadata
+	}
 
 	resp = &apiv1.GetExperimentTrialsResponse{}
 	if err = a.m.db.QueryProtof(
@@ -562,9 +996,21 @@ func (a *apiServer) GetExperimentTrials(
 		stateFilterExpr,
 		req.Offset,
 		req.Limit,
+		cursorID,
+		req.OrderBy == apiv1.OrderBy_ORDER_BY_DESC,
+		metadataFilter,
 	); err != nil {
 		return nil, errors.Wrapf(err, "failed to get trials for experiment %d", req.ExperimentId)
-	} else if len(resp.Trials) == 0 {
+	}
+	pageSize := resp.Pagination.EndIndex - resp.Pagination.StartIndex
+	if cursor != nil {
+		resp.Pagination.EndIndex = int32(len(resp.Trials))
+	}
+	pageFull := len(resp.Trials) > 0 && int32(len(resp.Trials)) == pageSize
+	if sortByID && pageFull && (cursor != nil || resp.Pagination.EndIndex < resp.Pagination.Total) {
+		resp.Pagination.NextCursor = api.Cursor{ID: int(resp.Trials[len(resp.Trials)-1].Id)}.Encode()
+	}
+	if len(resp.Trials) == 0 {
 		return resp, nil
 	}
 
@@ -625,6 +1071,24 @@ func (a *apiServer) GetTrial(ctx context.Context, req *apiv1.GetTrialRequest) (
 	return resp, nil
 }
 
+func (a *apiServer) PostTrialMetadata(
+	ctx context.Context, req *apiv1.PostTrialMetadataRequest,
+) (*apiv1.PostTrialMetadataResponse, error) {
+	if err := a.canGetTrialsExperimentAndCheckCanDoAction(ctx, int(req.TrialId),
+		expauth.AuthZProvider.Get().CanEditExperiment); err != nil {
+		return nil, err
+	}
+
+	metadata, err := a.m.db.UpdateTrialMetadata(ctx, int(req.TrialId), req.Metadata.AsMap())
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "trial %d not found", req.TrialId)
+	case err != nil:
+		return nil, errors.Wrapf(err, "failed to update metadata of trial %d", req.TrialId)
+	}
+	return &apiv1.PostTrialMetadataResponse{Metadata: protoutils.ToStruct(metadata)}, nil
+}
+
 func (a *apiServer) appendToMetrics(metrics []*apiv1.SummarizedMetric, m *apiv1.SummarizedMetric,
 	metricSeries []lttb.Point,
 ) []*apiv1.SummarizedMetric {
@@ -709,6 +1173,89 @@ func (a *apiServer) SummarizeTrial(ctx context.Context,
 	return resp, nil
 }
 
+func (a *apiServer) GetTrialMetricSeries(ctx context.Context,
+	req *apiv1.GetTrialMetricSeriesRequest,
+) (*apiv1.GetTrialMetricSeriesResponse, error) {
+	if err := a.canGetTrialsExperimentAndCheckCanDoAction(ctx, int(req.TrialId),
+		expauth.AuthZProvider.Get().CanGetExperimentArtifacts); err != nil {
+		return nil, err
+	}
+
+	q := db.MetricSeriesQuery{
+		TrialID:      int(req.TrialId),
+		StartBatches: int(req.StartBatches),
+		EndBatches:   int(req.EndBatches),
+		TargetPoints: int(req.TargetPoints),
+		LogScale:     req.Scale == apiv1.Scale_SCALE_LOG,
+	}
+	if q.TargetPoints == 0 {
+		q.TargetPoints = 1000
+	}
+	switch req.Method {
+	case apiv1.DownsampleMethod_DOWNSAMPLE_METHOD_UNSPECIFIED,
+		apiv1.DownsampleMethod_DOWNSAMPLE_METHOD_LTTB:
+		q.Method = db.DownsampleLTTB
+		if q.TargetPoints < 3 {
+			return nil, status.Error(codes.InvalidArgument,
+				"target_points must be at least 3 to downsample with LTTB")
+		}
+	case apiv1.DownsampleMethod_DOWNSAMPLE_METHOD_BUCKETS:
+		q.Method = db.DownsampleBuckets
+		if q.TargetPoints < 1 {
+			return nil, status.Error(codes.InvalidArgument, "target_points must be positive")
+		}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown method %s", req.Method)
+	}
+
+	var metricTypes []apiv1.MetricType
+	switch {
+	case req.MetricGroup == model.TrainingMetricGroup:
+		metricTypes = []apiv1.MetricType{apiv1.MetricType_METRIC_TYPE_TRAINING}
+	case req.MetricGroup == model.ValidationMetricGroup:
+		metricTypes = []apiv1.MetricType{apiv1.MetricType_METRIC_TYPE_VALIDATION}
+	case req.MetricGroup != "":
+		q.MetricGroup = req.MetricGroup
+		metricTypes = []apiv1.MetricType{apiv1.MetricType_METRIC_TYPE_UNSPECIFIED}
+	case req.MetricType == apiv1.MetricType_METRIC_TYPE_UNSPECIFIED:
+		metricTypes = []apiv1.MetricType{
+			apiv1.MetricType_METRIC_TYPE_TRAINING, apiv1.MetricType_METRIC_TYPE_VALIDATION,
+		}
+	default:
+		metricTypes = []apiv1.MetricType{req.MetricType}
+	}
+
+	resp := &apiv1.GetTrialMetricSeriesResponse{}
+	for _, name := range req.MetricNames {
+		for _, metricType := range metricTypes {
+			q.MetricName = name
+			q.MetricType = model.TrainingMetric
+			if metricType == apiv1.MetricType_METRIC_TYPE_VALIDATION {
+				q.MetricType = model.ValidationMetric
+			}
+			points, err := db.DownsampledMetricSeries(ctx, q)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error downsampling metric %s", name)
+			}
+			if len(points) == 0 {
+				continue
+			}
+
+			series := &apiv1.MetricSeries{Name: name, Type: metricType, Group: q.MetricGroup}
+			for _, p := range points {
+				series.Points = append(series.Points, &apiv1.MetricSeriesPoint{
+					Batches: int32(p.Batches),
+					Value:   p.Value,
+					Min:     p.Min,
+					Max:     p.Max,
+				})
+			}
+			resp.Series = append(resp.Series, series)
+		}
+	}
+	return resp, nil
+}
+
 func (a *apiServer) CompareTrials(ctx context.Context,
 	req *apiv1.CompareTrialsRequest,
 ) (*apiv1.CompareTrialsResponse, error) {
@@ -877,6 +1424,46 @@ func (a *apiServer) GetTrialProfilerAvailableSeries(
 	})
 }
 
+func (a *apiServer) GetTrialProfilerTimeline(
+	ctx context.Context, req *apiv1.GetTrialProfilerTimelineRequest,
+) (*apiv1.GetTrialProfilerTimelineResponse, error) {
+	if err := a.canGetTrialsExperimentAndCheckCanDoAction(ctx, int(req.TrialId),
+		expauth.AuthZProvider.Get().CanGetExperimentArtifacts); err != nil {
+		return nil, err
+	}
+
+	var start, end time.Time
+	if req.StartTime != nil {
+		start = req.StartTime.AsTime()
+	}
+	if req.EndTime != nil {
+		end = req.EndTime.AsTime()
+	}
+	maxPoints := int(req.MaxDatapoints)
+	switch {
+	case maxPoints < 0:
+		return nil, status.Error(codes.InvalidArgument, "max_datapoints must not be negative")
+	case maxPoints == 0:
+		maxPoints = defaultProfilerTimelinePoints
+	}
+
+	points, width, err := db.TrialProfilerTimeline(
+		ctx, int(req.TrialId), req.Names, start, end, maxPoints)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.GetTrialProfilerTimelineResponse{ResolutionSeconds: int32(width / time.Second)}
+	for _, p := range points {
+		labels := p.Labels()
+		if n := len(resp.Series); n == 0 || !proto.Equal(resp.Series[n-1].Labels, labels) {
+			resp.Series = append(resp.Series, &apiv1.ProfilerTimelineSeries{Labels: labels})
+		}
+		series := resp.Series[len(resp.Series)-1]
+		series.Points = append(series.Points, p.Proto())
+	}
+	return resp, nil
+}
+
 func (a *apiServer) PostTrialProfilerMetricsBatch(
 	ctx context.Context,
 	req *apiv1.PostTrialProfilerMetricsBatchRequest,
@@ -917,6 +1504,12 @@ func (a *apiServer) PostTrialProfilerMetricsBatch(
 			errs = multierror.Append(errs, fmt.Errorf("failed to insert batch: %w", err))
 			continue
 		}
+		if err := db.AddTrialProfilerTimeline(
+			ctx, batch.Labels, batch.Values, timestamps,
+		); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("failed to add batch to timeline: %w", err))
+			continue
+		}
 	}
 	return &apiv1.PostTrialProfilerMetricsBatchResponse{}, errs.ErrorOrNil()
 }
@@ -981,6 +1574,32 @@ func (a *apiServer) AckAllocationPreemptionSignal(
 	return &apiv1.AckAllocationPreemptionSignalResponse{}, nil
 }
 
+func (a *apiServer) AllocationResizeSignal(
+	ctx context.Context, req *apiv1.AllocationResizeSignalRequest,
+) (*apiv1.AllocationResizeSignalResponse, error) {
+	if err := a.canEditAllocation(ctx, req.AllocationId); err != nil {
+		return nil, err
+	}
+
+	allocationID := model.AllocationID(req.AllocationId)
+	handler, err := a.m.rm.GetAllocationHandler(
+		a.m.system,
+		sproto.GetAllocationHandler{ID: allocationID},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var slots *int
+	if err := a.ask(handler.Address(), task.GetResize{AllocationID: allocationID}, &slots); err != nil {
+		return nil, err
+	}
+	if slots == nil {
+		return &apiv1.AllocationResizeSignalResponse{}, nil
+	}
+	return &apiv1.AllocationResizeSignalResponse{Slots: ptrs.Ptr(int32(*slots))}, nil
+}
+
 func (a *apiServer) AllocationPendingPreemptionSignal(
 	ctx context.Context,
 	req *apiv1.AllocationPendingPreemptionSignalRequest,
@@ -1154,6 +1773,10 @@ func (a *apiServer) ReportTrialTrainingMetrics(
 	if err := a.m.db.AddTrainingMetrics(ctx, req.TrainingMetrics); err != nil {
 		return nil, err
 	}
+	if err := a.m.checkAlerts(ctx, model.TrainingMetricGroup, req.TrainingMetrics); err != nil {
+		log.WithError(err).Errorf("failed to check alerts of trial %d",
+			req.TrainingMetrics.TrialId)
+	}
 	return &apiv1.ReportTrialTrainingMetricsResponse{}, nil
 }
 
@@ -1167,9 +1790,66 @@ func (a *apiServer) ReportTrialValidationMetrics(
 	if err := a.m.db.AddValidationMetrics(ctx, req.ValidationMetrics); err != nil {
 		return nil, err
 	}
+	// The metrics are saved, so failing to look up their experiment only skips updating it.
+	if experimentID, err := a.m.db.ExperimentIDByTrialID(
+		int(req.ValidationMetrics.TrialId),
+	); err != nil {
+		log.WithError(err).Errorf("failed to get experiment of trial %d",
+			req.ValidationMetrics.TrialId)
+	} else {
+		stream.ValidationMetricsReported(experimentID, req.ValidationMetrics)
+		if err := a.m.db.UpdateExperimentBestCheckpoint(ctx, experimentID); err != nil {
+			log.WithError(err).Errorf("failed to update best checkpoint of experiment %d",
+				experimentID)
+		}
+	}
+	if err := a.m.checkEarlyStopping(ctx, int(req.ValidationMetrics.TrialId)); err != nil {
+		log.WithError(err).Errorf("failed to check early stopping rules of trial %d",
+			req.ValidationMetrics.TrialId)
+	}
+	if err := a.m.checkAlerts(
+		ctx, model.ValidationMetricGroup, req.ValidationMetrics,
+	); err != nil {
+		log.WithError(err).Errorf("failed to check alerts of trial %d",
+			req.ValidationMetrics.TrialId)
+	}
 	return &apiv1.ReportTrialValidationMetricsResponse{}, nil
 }
 
+// metricGroupPattern is what the name of a group of generic metrics must look like.
+var metricGroupPattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,64}$`)
+
+func (a *apiServer) ReportTrialMetrics(
+	ctx context.Context, req *apiv1.ReportTrialMetricsRequest,
+) (*apiv1.ReportTrialMetricsResponse, error) {
+	switch req.Group {
+	case model.TrainingMetricGroup:
+		_, err := a.ReportTrialTrainingMetrics(ctx,
+			&apiv1.ReportTrialTrainingMetricsRequest{TrainingMetrics: req.Metrics})
+		return &apiv1.ReportTrialMetricsResponse{}, err
+	case model.ValidationMetricGroup:
+		_, err := a.ReportTrialValidationMetrics(ctx,
+			&apiv1.ReportTrialValidationMetricsRequest{ValidationMetrics: req.Metrics})
+		return &apiv1.ReportTrialMetricsResponse{}, err
+	}
+
+	if !metricGroupPattern.MatchString(req.Group) {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"metric group %q must be 1 to 64 letters, digits, '_', '.', or '-'", req.Group)
+	}
+	if err := a.canGetTrialsExperimentAndCheckCanDoAction(ctx, int(req.Metrics.TrialId),
+		expauth.AuthZProvider.Get().CanEditExperiment); err != nil {
+		return nil, err
+	}
+	if err := a.m.db.AddGenericMetrics(ctx, req.Group, req.Metrics); err != nil {
+		return nil, err
+	}
+	if err := a.m.checkAlerts(ctx, req.Group, req.Metrics); err != nil {
+		log.WithError(err).Errorf("failed to check alerts of trial %d", req.Metrics.TrialId)
+	}
+	return &apiv1.ReportTrialMetricsResponse{}, nil
+}
+
 func (a *apiServer) ReportCheckpoint(
 	ctx context.Context, req *apiv1.ReportCheckpointRequest,
 ) (*apiv1.ReportCheckpointResponse, error) {
@@ -1194,6 +1874,19 @@ func (a *apiServer) ReportCheckpoint(
 	if err := a.m.db.AddCheckpointMetadata(ctx, c); err != nil {
 		return nil, err
 	}
+	// The checkpoint is saved, so failing to look up its experiment only skips updating it.
+	exp, err := db.ExperimentWithoutConfigByTaskID(ctx, model.TaskID(req.Checkpoint.TaskId))
+	if err != nil {
+		log.WithError(err).Errorf("failed to get experiment of task %s", req.Checkpoint.TaskId)
+		return &apiv1.ReportCheckpointResponse{}, nil
+	}
+	stream.CheckpointReported(exp.ID, req.Checkpoint)
+	if err := a.m.db.UpdateExperimentBestCheckpoint(ctx, exp.ID); err != nil {
+		log.WithError(err).Errorf("failed to update best checkpoint of experiment %d", exp.ID)
+	}
+	if err := webhooks.ReportCheckpointCreated(ctx, *exp, *c); err != nil {
+		log.WithError(err).Error("failed to send checkpoint created webhook")
+	}
 	return &apiv1.ReportCheckpointResponse{}, nil
 }
 
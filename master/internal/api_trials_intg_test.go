@@ -8,12 +8,17 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/master/pkg/schemas"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
 	"github.com/determined-ai/determined/proto/pkg/trialv1"
 )
@@ -63,6 +68,11 @@ func TestTrialAuthZ(t *testing.T) {
 				TrialId: int32(id),
 			}, mockStream[*apiv1.TrialLogsFieldsResponse]{ctx})
 		}, false},
+		{"CanGetExperimentArtifacts", func(id int) error {
+			return api.FollowTrialLogs(&apiv1.FollowTrialLogsRequest{
+				TrialId: int32(id),
+			}, mockStream[*apiv1.FollowLogsResponse]{ctx})
+		}, false},
 		{"CanGetExperimentArtifacts", func(id int) error {
 			_, err := api.GetTrialCheckpoints(ctx, &apiv1.GetTrialCheckpointsRequest{
 				Id: int32(id),
@@ -110,6 +120,12 @@ func TestTrialAuthZ(t *testing.T) {
 					TrialId: int32(id),
 				}, mockStream[*apiv1.GetTrialProfilerAvailableSeriesResponse]{ctx})
 		}, false},
+		{"CanGetExperimentArtifacts", func(id int) error {
+			_, err := api.GetTrialProfilerTimeline(ctx, &apiv1.GetTrialProfilerTimelineRequest{
+				TrialId: int32(id),
+			})
+			return err
+		}, false},
 		{"CanEditExperiment", func(id int) error {
 			_, err := api.PostTrialProfilerMetricsBatch(ctx,
 				&apiv1.PostTrialProfilerMetricsBatchRequest{
@@ -174,6 +190,24 @@ func TestTrialAuthZ(t *testing.T) {
 				TrialId: []int32{int32(id)},
 			}, mockStream[*apiv1.ExpCompareMetricNamesResponse]{ctx})
 		}, false},
+		{"CanForkFromExperiment", func(id int) error {
+			_, err := api.ForkTrial(ctx, &apiv1.ForkTrialRequest{
+				TrialId: int32(id),
+			})
+			return err
+		}, false},
+		{"CanGetExperimentArtifacts", func(id int) error {
+			_, err := api.GetTrialReplayState(ctx, &apiv1.GetTrialReplayStateRequest{
+				TrialId: int32(id),
+			})
+			return err
+		}, false},
+		{"CanForkFromExperiment", func(id int) error {
+			_, err := api.ReplayTrial(ctx, &apiv1.ReplayTrialRequest{
+				TrialId: int32(id),
+			})
+			return err
+		}, false},
 		{"CanGetExperimentArtifacts", func(id int) error {
 			_, err := api.LaunchTensorboard(ctx, &apiv1.LaunchTensorboardRequest{
 				TrialIds: []int32{int32(id)},
@@ -205,3 +239,127 @@ func TestTrialAuthZ(t *testing.T) {
 		require.ErrorIs(t, curCase.IDToReqCall(trial.ID), expectedErr)
 	}
 }
+
+func TestForkTrialConfig(t *testing.T) {
+	optimizer := map[string]expconf.Hyperparameter{
+		"momentum": {RawConstHyperparameter: &expconf.ConstHyperparameter{RawVal: 0.9}},
+		"nesterov": {RawConstHyperparameter: &expconf.ConstHyperparameter{RawVal: false}},
+	}
+	parent := schemas.Merge(minExpConfig, expconf.ExperimentConfig{
+		RawHyperparameters: expconf.Hyperparameters{
+			"lr": expconf.Hyperparameter{RawDoubleHyperparameter: &expconf.DoubleHyperparameter{
+				RawMinval: 0.001, RawMaxval: 0.1,
+			}},
+			"optimizer": expconf.Hyperparameter{RawNestedHyperparameter: &optimizer},
+		},
+		RawSearcher: &expconf.SearcherConfig{
+			RawSmallerIsBetter: ptrs.Ptr(false),
+			RawSourceTrialID:   ptrs.Ptr(1),
+		},
+	}).(expconf.ExperimentConfig)
+	trial := &model.Trial{
+		ID:           3,
+		ExperimentID: 2,
+		HParams: model.JSONObj{
+			"lr":        0.01,
+			"optimizer": map[string]interface{}{"momentum": 0.9, "nesterov": false},
+		},
+	}
+	ckptUUID := uuid.New()
+	ckpt := &model.Checkpoint{UUID: &ckptUUID}
+
+	overrides, err := structpb.NewStruct(map[string]interface{}{
+		"optimizer": map[string]interface{}{"nesterov": true},
+	})
+	require.NoError(t, err)
+	config, err := forkTrialConfig(parent, trial, ckpt, &apiv1.ForkTrialRequest{
+		TrialId: 3,
+		Hparams: overrides,
+		Config:  "description: tuned",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]interface{}{
+		"lr":                 0.01,
+		"optimizer.momentum": 0.9,
+		"optimizer.nesterov": true,
+	}, flattenConstHPs(config.RawHyperparameters))
+	require.Equal(t, "Fork of trial 3 of experiment 2", *config.RawName.RawString)
+	require.Equal(t, "tuned", *config.RawDescription)
+	require.Equal(t, parent.RawSearcher.RawSingleConfig.RawMaxLength,
+		config.RawSearcher.RawSingleConfig.RawMaxLength)
+	require.Equal(t, "loss", *config.RawSearcher.RawMetric)
+	require.False(t, *config.RawSearcher.RawSmallerIsBetter)
+	require.Nil(t, config.RawSearcher.RawSourceTrialID)
+	require.Equal(t, ckptUUID.String(), *config.RawSearcher.RawSourceCheckpointUUID)
+
+	config, err = forkTrialConfig(parent, trial, ckpt, &apiv1.ForkTrialRequest{
+		TrialId:   3,
+		MaxLength: 50,
+	})
+	require.NoError(t, err)
+	require.Equal(t, expconf.Length{Unit: expconf.Batches, Units: 50},
+		*config.RawSearcher.RawSingleConfig.RawMaxLength)
+
+	custom := schemas.Copy(parent).(expconf.ExperimentConfig)
+	custom.RawSearcher = &expconf.SearcherConfig{
+		RawMetric:       ptrs.Ptr("loss"),
+		RawCustomConfig: &expconf.CustomConfig{},
+	}
+	_, err = forkTrialConfig(custom, trial, ckpt, &apiv1.ForkTrialRequest{TrialId: 3})
+	require.ErrorContains(t, err, "max_length is required")
+}
+
+func TestReplayTrialConfig(t *testing.T) {
+	parent := schemas.Merge(minExpConfig, expconf.ExperimentConfig{
+		RawResources: &expconf.ResourcesConfig{
+			RawSlotsPerTrial: ptrs.Ptr(8),
+			RawElastic:       &expconf.ElasticConfig{RawMinSlotsPerTrial: 2, RawMaxSlotsPerTrial: 8},
+		},
+		RawSearcher: &expconf.SearcherConfig{RawSourceTrialID: ptrs.Ptr(1)},
+	}).(expconf.ExperimentConfig)
+	trial := &model.Trial{
+		ID:           3,
+		ExperimentID: 2,
+		Seed:         1234,
+		HParams:      model.JSONObj{"lr": 0.01},
+	}
+
+	// Without a recorded first run, the trial starts the way its experiment is configured.
+	config, err := replayTrialConfig(parent, trial, nil, &apiv1.ReplayTrialRequest{TrialId: 3})
+	require.NoError(t, err)
+	require.Equal(t, "Replay of trial 3 of experiment 2", *config.RawName.RawString)
+	require.Equal(t, uint32(42), *config.RawReproducibility.RawExperimentSeed)
+	require.Equal(t, uint32(1234), *config.RawReproducibility.RawTrialSeed)
+	require.Equal(t, map[string]interface{}{"lr": 0.01}, flattenConstHPs(config.RawHyperparameters))
+	require.Equal(t, 1, *config.RawSearcher.RawSourceTrialID)
+	require.Equal(t, 8, *config.RawResources.RawSlotsPerTrial)
+
+	ckptUUID := uuid.New()
+	states := []model.TrialRunState{
+		{TrialID: 3, RunID: 1, TrialSeed: 1234, Slots: 4, CheckpointUUID: &ckptUUID},
+		{TrialID: 3, RunID: 2, TrialSeed: 1234, Slots: 2, StepsCompleted: 100},
+	}
+	config, err = replayTrialConfig(parent, trial, states, &apiv1.ReplayTrialRequest{
+		TrialId:   3,
+		MaxLength: 50,
+		Config:    "description: debugging",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "debugging", *config.RawDescription)
+	require.Equal(t, expconf.Length{Unit: expconf.Batches, Units: 50},
+		*config.RawSearcher.RawSingleConfig.RawMaxLength)
+	require.Nil(t, config.RawSearcher.RawSourceTrialID)
+	require.Equal(t, ckptUUID.String(), *config.RawSearcher.RawSourceCheckpointUUID)
+	require.Equal(t, 4, *config.RawResources.RawSlotsPerTrial)
+	require.Nil(t, config.RawResources.RawElastic)
+	require.Equal(t, "kubernetes", *config.RawResources.RawResourcePool)
+}
+
+func flattenConstHPs(h expconf.Hyperparameters) map[string]interface{} {
+	values := make(map[string]interface{})
+	for name, hp := range expconf.FlattenHPs(h) {
+		values[name] = hp.RawConstHyperparameter.RawVal
+	}
+	return values
+}
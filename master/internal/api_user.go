@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"gopkg.in/guregu/null.v3"
@@ -13,9 +15,11 @@ import (
 	"google.golang.org/grpc/status"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/determined-ai/determined/master/internal/auditlog"
 	"github.com/determined-ai/determined/master/internal/db"
 	"github.com/determined-ai/determined/master/internal/grpcutil"
 	"github.com/determined-ai/determined/master/internal/user"
+	"github.com/determined-ai/determined/master/internal/usergroup"
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/master/pkg/ptrs"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
@@ -87,6 +91,10 @@ func toProtoUserFromFullUser(user model.FullUser) *userv1.User {
 		}
 	}
 	displayNameString := user.DisplayName.ValueOrZero()
+	var ownerID *int32
+	if user.OwnerID != nil {
+		ownerID = ptrs.Ptr(int32(*user.OwnerID))
+	}
 	return &userv1.User{
 		Id:             int32(user.ID),
 		Username:       user.Username,
@@ -95,6 +103,9 @@ func toProtoUserFromFullUser(user model.FullUser) *userv1.User {
 		AgentUserGroup: agentUserGroup,
 		DisplayName:    displayNameString,
 		ModifiedAt:     timestamppb.New(user.ModifiedAt),
+		Remote:         user.Remote,
+		ServiceAccount: user.ServiceAccount,
+		OwnerId:        ownerID,
 	}
 }
 
@@ -255,9 +266,10 @@ func (a *apiServer) PostUser(
 		return nil, status.Error(codes.InvalidArgument, "must specify user to create")
 	}
 	userToAdd := &model.User{
-		Username: req.User.Username,
-		Admin:    req.User.Admin,
-		Active:   req.User.Active,
+		Username:       req.User.Username,
+		Admin:          req.User.Admin,
+		Active:         req.User.Active,
+		ServiceAccount: req.User.ServiceAccount,
 	}
 	clearedUsername, err := clearUsername(*userToAdd, userToAdd.Username, 2)
 	if err != nil {
@@ -302,6 +314,27 @@ func (a *apiServer) PostUser(
 		CanCreateUser(ctx, *curUser, *userToAdd, agentUserGroup); err != nil {
 		return nil, status.Error(codes.PermissionDenied, err.Error())
 	}
+	var groupIDs []int
+	if len(req.GroupIds) > 0 {
+		if err = usergroup.AuthZProvider.Get().CanUpdateGroups(ctx, *curUser); err != nil {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		seen := map[int]bool{}
+		for _, gid := range req.GroupIds {
+			if seen[int(gid)] {
+				continue
+			}
+			seen[int(gid)] = true
+			_, err = usergroup.GroupByIDTx(ctx, nil, int(gid))
+			switch {
+			case errors.Is(err, db.ErrNotFound):
+				return nil, status.Errorf(codes.NotFound, "group (%d) not found", gid)
+			case err != nil:
+				return nil, err
+			}
+			groupIDs = append(groupIDs, int(gid))
+		}
+	}
 
 	if err = grpcutil.ValidateRequest(
 		func() (bool, string) { return req.User != nil, "no user specified" },
@@ -310,24 +343,50 @@ func (a *apiServer) PostUser(
 		return nil, err
 	}
 
-	var hashedPassword string
-	if req.IsHashed {
-		hashedPassword = req.Password
+	if userToAdd.ServiceAccount {
+		if req.Password != "" {
+			return nil, status.Error(codes.InvalidArgument, user.ErrServiceAccountPassword.Error())
+		}
+		// Service accounts are owned by whoever creates them unless an owner is named.
+		ownerID := curUser.ID
+		if req.User.OwnerId != nil {
+			if ownerID, err = serviceAccountOwner(*req.User.OwnerId); err != nil {
+				return nil, err
+			}
+		}
+		userToAdd.OwnerID = &ownerID
 	} else {
-		hashedPassword = replicateClientSideSaltAndHash(req.Password)
-	}
-
-	if err = userToAdd.UpdatePasswordHash(hashedPassword); err != nil {
-		return nil, err
+		if req.User.OwnerId != nil {
+			return nil, status.Error(codes.InvalidArgument, "only service accounts have owners")
+		}
+		hashedPassword, err := checkNewPassword(req.Password, req.IsHashed)
+		if err != nil {
+			return nil, err
+		}
+		if err = userToAdd.UpdatePasswordHash(hashedPassword); err != nil {
+			return nil, err
+		}
 	}
 
-	userID, err := a.m.db.AddUser(userToAdd, agentUserGroup)
+	userID, err := a.m.db.AddUserWithGroups(userToAdd, agentUserGroup, groupIDs)
 	switch {
 	case err == db.ErrDuplicateRecord:
 		return nil, status.Error(codes.InvalidArgument, "user already exists")
+	case errors.Is(err, db.ErrNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
 	case err != nil:
 		return nil, err
 	}
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.UserCreate,
+		TargetType: "user",
+		TargetID:   strconv.Itoa(int(userID)),
+		Success:    true,
+		Details: map[string]interface{}{
+			"username": userToAdd.Username, "admin": userToAdd.Admin, "group_ids": groupIDs,
+			"service_account": userToAdd.ServiceAccount,
+		},
+	})
 	fullUser, err := getUser(a.m.db, userID)
 	return &apiv1.PostUserResponse{User: fullUser}, err
 }
@@ -355,8 +414,19 @@ func (a *apiServer) SetUserPassword(
 		}
 		return nil, status.Error(codes.PermissionDenied, err.Error())
 	}
+	if user.IsSSOUser(targetUser) {
+		return nil, status.Error(codes.InvalidArgument,
+			"Cannot set the password of SSO/SCIM user through this API.")
+	}
+	if targetUser.ServiceAccount {
+		return nil, status.Error(codes.InvalidArgument, user.ErrServiceAccountPassword.Error())
+	}
 
-	if err = targetUser.UpdatePasswordHash(replicateClientSideSaltAndHash(req.Password)); err != nil {
+	hashedPassword, err := checkNewPassword(req.Password, false)
+	if err != nil {
+		return nil, err
+	}
+	if err = targetUser.UpdatePasswordHash(hashedPassword); err != nil {
 		return nil, err
 	}
 	switch err = a.m.db.UpdateUser(&targetUser, []string{"password_hash"}, nil); {
@@ -365,6 +435,9 @@ func (a *apiServer) SetUserPassword(
 	case err != nil:
 		return nil, err
 	}
+	recordUserUpdate(ctx, curUser, targetUser.ID, map[string]interface{}{
+		"fields": []string{"password"},
+	})
 	fullUser, err := getUser(a.m.db, model.UserID(req.UserId))
 	return &apiv1.SetUserPasswordResponse{User: fullUser}, err
 }
@@ -466,10 +539,13 @@ func (a *apiServer) PatchUser(
 			CanSetUsersPassword(ctx, *curUser, targetUser); err != nil {
 			return nil, status.Error(codes.PermissionDenied, err.Error())
 		}
+		if targetUser.ServiceAccount {
+			return nil, status.Error(codes.InvalidArgument, user.ErrServiceAccountPassword.Error())
+		}
 
-		hashedPassword := *req.User.Password
-		if !req.User.IsHashed {
-			hashedPassword = replicateClientSideSaltAndHash(hashedPassword)
+		hashedPassword, err := checkNewPassword(*req.User.Password, req.User.IsHashed)
+		if err != nil {
+			return nil, err
 		}
 		if err := updatedUser.UpdatePasswordHash(hashedPassword); err != nil {
 			return nil, errors.Wrap(err, "error hashing password")
@@ -477,6 +553,22 @@ func (a *apiServer) PatchUser(
 		insertColumns = append(insertColumns, "password_hash")
 	}
 
+	if req.User.OwnerId != nil {
+		if !targetUser.ServiceAccount {
+			return nil, status.Error(codes.InvalidArgument, "only service accounts have owners")
+		}
+		if !curUser.Admin && !user.OwnsServiceAccount(*curUser, targetUser) {
+			return nil, status.Error(codes.PermissionDenied,
+				"only admins and the owner of a service account can transfer it")
+		}
+		ownerID, err := serviceAccountOwner(*req.User.OwnerId)
+		if err != nil {
+			return nil, err
+		}
+		updatedUser.OwnerID = &ownerID
+		insertColumns = append(insertColumns, "owner_id")
+	}
+
 	var ug *model.AgentUserGroup
 	if aug := req.User.AgentUserGroup; aug != nil {
 		if err = validateProtoAgentUserGroup(aug); err != nil {
@@ -498,6 +590,26 @@ func (a *apiServer) PatchUser(
 	if err := a.m.db.UpdateUser(updatedUser, insertColumns, ug); err != nil {
 		return nil, err
 	}
+	details := map[string]interface{}{}
+	var fields []string
+	for _, c := range insertColumns {
+		switch c {
+		case "admin":
+			details["admin"] = updatedUser.Admin
+		case "active":
+			details["active"] = updatedUser.Active
+		case "owner_id":
+			details["owner_id"] = *updatedUser.OwnerID
+		case "password_hash":
+			c = "password"
+		}
+		fields = append(fields, c)
+	}
+	if ug != nil {
+		fields = append(fields, "agent_user_group")
+	}
+	details["fields"] = fields
+	recordUserUpdate(ctx, curUser, targetUser.ID, details)
 
 	fullUser, err := getUser(a.m.db, model.UserID(req.UserId))
 	return &apiv1.PatchUserResponse{User: fullUser}, err
@@ -558,3 +670,344 @@ func (a *apiServer) ResetUserSetting(
 	err = db.ResetUserSetting(curUser.ID)
 	return &apiv1.ResetUserSettingResponse{}, err
 }
+
+// recordUserUpdate records a change to a user in the audit log.
+func recordUserUpdate(
+	ctx context.Context, curUser *model.User, target model.UserID, details map[string]interface{},
+) {
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.UserUpdate,
+		TargetType: "user",
+		TargetID:   strconv.Itoa(int(target)),
+		Success:    true,
+		Details:    details,
+	})
+}
+
+// accessTokenUser returns the current user and the user whose access tokens or sessions a request
+// is about, which is the current user unless they may manage those of another user they name.
+func accessTokenUser(ctx context.Context, userID *int32) (*model.User, model.UserID, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	targetUser := *curUser
+	if userID != nil && model.UserID(*userID) != curUser.ID {
+		fullUser, err := getFullModelUser(model.UserID(*userID))
+		if err != nil {
+			return nil, 0, err
+		}
+		targetUser = fullUser.ToUser()
+	}
+	if err := user.AuthZProvider.Get().
+		CanManageUsersAccessTokens(ctx, *curUser, targetUser); err != nil {
+		return nil, 0, status.Error(codes.PermissionDenied, err.Error())
+	}
+	return curUser, targetUser.ID, nil
+}
+
+// targetUser returns the current user and the user a request is about, which is the current user
+// unless an admin, or the owner of a service account, names another. what describes what is being
+// managed in errors.
+func targetUser(
+	ctx context.Context, userID *int32, what string,
+) (*model.User, model.UserID, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if userID == nil || model.UserID(*userID) == curUser.ID {
+		return curUser, curUser.ID, nil
+	}
+	denied := status.Errorf(codes.PermissionDenied,
+		"only admins can manage %s of other users than their service accounts", what)
+	targetUser, err := getFullModelUser(model.UserID(*userID))
+	switch {
+	case err != nil && !curUser.Admin:
+		return nil, 0, denied
+	case err != nil:
+		return nil, 0, err
+	case !curUser.Admin && !user.OwnsServiceAccount(*curUser, targetUser.ToUser()):
+		return nil, 0, denied
+	}
+	return curUser, targetUser.ID, nil
+}
+
+// serviceAccountOwner checks that a user can own service accounts and returns their ID.
+func serviceAccountOwner(ownerID int32) (model.UserID, error) {
+	owner, err := getFullModelUser(model.UserID(ownerID))
+	if err != nil {
+		return 0, err
+	}
+	if owner.ServiceAccount || !owner.Active {
+		return 0, status.Errorf(codes.InvalidArgument,
+			"user (%d) must be an active user who is not a service account to own one", ownerID)
+	}
+	return owner.ID, nil
+}
+
+func (a *apiServer) PostAccessToken(
+	ctx context.Context, req *apiv1.PostAccessTokenRequest,
+) (*apiv1.PostAccessTokenResponse, error) {
+	curUser, userID, err := accessTokenUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	scope := model.AccessTokenScopeFromProto(req.Scope)
+	if scope == "" {
+		return nil, status.Error(codes.InvalidArgument, "must provide a scope")
+	}
+
+	token := &model.AccessToken{
+		UserID:      userID,
+		Description: req.Description,
+		Scope:       scope,
+	}
+	if req.ExpiresAt != nil {
+		if err := req.ExpiresAt.CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		expiresAt := req.ExpiresAt.AsTime()
+		if !expiresAt.After(time.Now()) {
+			return nil, status.Error(codes.InvalidArgument, "expiry must be in the future")
+		}
+		token.ExpiresAt = &expiresAt
+	}
+
+	secret, err := user.AddAccessToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.AccessTokenCreate,
+		TargetType: "access_token",
+		TargetID:   strconv.Itoa(token.ID),
+		Success:    true,
+		Details:    map[string]interface{}{"user_id": userID, "scope": scope},
+	})
+	return &apiv1.PostAccessTokenResponse{Token: secret, AccessToken: token.Proto()}, nil
+}
+
+func (a *apiServer) GetAccessTokens(
+	ctx context.Context, req *apiv1.GetAccessTokensRequest,
+) (*apiv1.GetAccessTokensResponse, error) {
+	_, userID, err := accessTokenUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := user.AccessTokens(ctx, &userID, req.IncludeInactive)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.GetAccessTokensResponse{}
+	for _, t := range tokens {
+		resp.AccessTokens = append(resp.AccessTokens, t.Proto())
+	}
+	return resp, nil
+}
+
+func (a *apiServer) DeleteAccessToken(
+	ctx context.Context, req *apiv1.DeleteAccessTokenRequest,
+) (*apiv1.DeleteAccessTokenResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	errTokenNotFound := status.Errorf(codes.NotFound, "access token %d not found", req.Id)
+	token, err := user.AccessTokenByID(ctx, int(req.Id))
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, errTokenNotFound
+	case err != nil:
+		return nil, err
+	}
+	tokenUser, err := getFullModelUser(token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// Tokens that the user may not manage are reported as not found, to not reveal them.
+	if err := user.AuthZProvider.Get().
+		CanManageUsersAccessTokens(ctx, *curUser, tokenUser.ToUser()); err != nil {
+		return nil, errTokenNotFound
+	}
+
+	if err := user.RevokeAccessToken(ctx, token.ID); err != nil {
+		return nil, err
+	}
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.AccessTokenRevoke,
+		TargetType: "access_token",
+		TargetID:   strconv.Itoa(token.ID),
+		Success:    true,
+		Details:    map[string]interface{}{"user_id": token.UserID},
+	})
+	return &apiv1.DeleteAccessTokenResponse{}, nil
+}
+
+func (a *apiServer) PostSSHKey(
+	ctx context.Context, req *apiv1.PostSSHKeyRequest,
+) (*apiv1.PostSSHKeyResponse, error) {
+	curUser, userID, err := targetUser(ctx, req.UserId, "the SSH keys")
+	if err != nil {
+		return nil, err
+	}
+	key, err := user.NewSSHKey(userID, req.PublicKey, req.Name, req.InjectIntoTrials)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	switch err := user.AddSSHKey(ctx, key); {
+	case errors.Is(err, db.ErrDuplicateRecord):
+		return nil, status.Errorf(codes.AlreadyExists,
+			"SSH key %s is already registered", key.Fingerprint)
+	case err != nil:
+		return nil, err
+	}
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.SSHKeyCreate,
+		TargetType: "ssh_key",
+		TargetID:   strconv.Itoa(key.ID),
+		Success:    true,
+		Details: map[string]interface{}{
+			"user_id":            userID,
+			"fingerprint":        key.Fingerprint,
+			"inject_into_trials": key.InjectIntoTrials,
+		},
+	})
+	return &apiv1.PostSSHKeyResponse{SshKey: key.Proto()}, nil
+}
+
+func (a *apiServer) GetSSHKeys(
+	ctx context.Context, req *apiv1.GetSSHKeysRequest,
+) (*apiv1.GetSSHKeysResponse, error) {
+	_, userID, err := targetUser(ctx, req.UserId, "the SSH keys")
+	if err != nil {
+		return nil, err
+	}
+	keys, err := user.SSHKeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.GetSSHKeysResponse{SshKeys: []*userv1.SSHKey{}}
+	for _, k := range keys {
+		resp.SshKeys = append(resp.SshKeys, k.Proto())
+	}
+	return resp, nil
+}
+
+func (a *apiServer) DeleteSSHKey(
+	ctx context.Context, req *apiv1.DeleteSSHKeyRequest,
+) (*apiv1.DeleteSSHKeyResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	errKeyNotFound := status.Errorf(codes.NotFound, "SSH key %d not found", req.Id)
+	key, err := user.SSHKeyByID(ctx, int(req.Id))
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, errKeyNotFound
+	case err != nil:
+		return nil, err
+	case key.UserID != curUser.ID && !curUser.Admin:
+		return nil, errKeyNotFound
+	}
+
+	if err := user.DeleteSSHKey(ctx, key.ID); err != nil {
+		return nil, err
+	}
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.SSHKeyDelete,
+		TargetType: "ssh_key",
+		TargetID:   strconv.Itoa(key.ID),
+		Success:    true,
+		Details:    map[string]interface{}{"user_id": key.UserID, "fingerprint": key.Fingerprint},
+	})
+	return &apiv1.DeleteSSHKeyResponse{}, nil
+}
+
+func (a *apiServer) GetSessions(
+	ctx context.Context, req *apiv1.GetSessionsRequest,
+) (*apiv1.GetSessionsResponse, error) {
+	_, userID, err := accessTokenUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	_, curSession, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := user.Sessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.GetSessionsResponse{}
+	for _, s := range sessions {
+		current := curSession != nil && curSession.AccessTokenScope == "" &&
+			curSession.ID == s.ID
+		resp.Sessions = append(resp.Sessions, s.Proto(current))
+	}
+	return resp, nil
+}
+
+func (a *apiServer) DeleteSession(
+	ctx context.Context, req *apiv1.DeleteSessionRequest,
+) (*apiv1.DeleteSessionResponse, error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	errSessionNotFound := status.Errorf(codes.NotFound, "session %d not found", req.Id)
+	session, err := user.SessionByID(ctx, model.SessionID(req.Id))
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, errSessionNotFound
+	case err != nil:
+		return nil, err
+	case session.UserID != curUser.ID && !curUser.Admin:
+		return nil, errSessionNotFound
+	}
+
+	if err := a.m.db.DeleteUserSessionByID(session.ID); err != nil {
+		return nil, err
+	}
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.SessionRevoke,
+		TargetType: "session",
+		TargetID:   strconv.Itoa(int(session.ID)),
+		Success:    true,
+		Details:    map[string]interface{}{"user_id": session.UserID},
+	})
+	return &apiv1.DeleteSessionResponse{}, nil
+}
+
+func (a *apiServer) DeleteSessions(
+	ctx context.Context, req *apiv1.DeleteSessionsRequest,
+) (*apiv1.DeleteSessionsResponse, error) {
+	curUser, userID, err := accessTokenUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	_, curSession, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Users ending their own sessions stay logged in where they ask from.
+	var keep *model.SessionID
+	if userID == curUser.ID && curSession != nil && curSession.AccessTokenScope == "" {
+		keep = &curSession.ID
+	}
+
+	deleted, err := user.DeleteSessions(ctx, userID, keep)
+	if err != nil {
+		return nil, err
+	}
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.SessionRevoke,
+		TargetType: "user",
+		TargetID:   strconv.Itoa(int(userID)),
+		Success:    true,
+		Details:    map[string]interface{}{"deleted": deleted},
+	})
+	return &apiv1.DeleteSessionsResponse{Deleted: int32(deleted)}, nil
+}
@@ -6,7 +6,9 @@ package internal
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -16,18 +18,22 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/determined-ai/determined/master/internal/config"
 	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/grpcutil"
 	"github.com/determined-ai/determined/master/internal/mocks"
 	"github.com/determined-ai/determined/master/internal/rm"
 	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/internal/task"
 	"github.com/determined-ai/determined/master/internal/user"
 	"github.com/determined-ai/determined/master/pkg/actor"
 	"github.com/determined-ai/determined/master/pkg/etc"
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/master/pkg/ssh"
 	"github.com/determined-ai/determined/master/pkg/tasks"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
 	"github.com/determined-ai/determined/proto/pkg/userv1"
@@ -54,11 +60,13 @@ func setupAPITest(t *testing.T) (*apiServer, model.User, context.Context) {
 		mockRM = rm.WrapRMActor(ref)
 	}
 
+	taskLogs := task.NewLogNotifier(pgDB)
 	api := &apiServer{
 		m: &Master{
 			system:         system,
 			db:             pgDB,
-			taskLogBackend: pgDB,
+			taskLogBackend: taskLogs,
+			taskLogs:       taskLogs,
 			rm:             mockRM,
 			config: &config.Config{
 				InternalConfig:        config.InternalConfig{},
@@ -175,6 +183,144 @@ func TestPatchUser(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestAccessTokens(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t)
+
+	_, err := api.PostAccessToken(ctx, &apiv1.PostAccessTokenRequest{Description: "no scope"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = api.PostAccessToken(ctx, &apiv1.PostAccessTokenRequest{
+		Description: "expired",
+		Scope:       userv1.AccessTokenScope_ACCESS_TOKEN_SCOPE_READ_ONLY,
+		ExpiresAt:   timestamppb.New(time.Now().Add(-time.Hour)),
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	resp, err := api.PostAccessToken(ctx, &apiv1.PostAccessTokenRequest{
+		Description: "ci",
+		Scope:       userv1.AccessTokenScope_ACCESS_TOKEN_SCOPE_READ_ONLY,
+		ExpiresAt:   timestamppb.New(time.Now().Add(time.Hour)),
+	})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(resp.Token, model.AccessTokenPrefix))
+	require.Equal(t, int32(curUser.ID), resp.AccessToken.UserId)
+
+	// The token authenticates as its user, with its scope.
+	tokenCtx := metadata.NewIncomingContext(context.TODO(),
+		metadata.Pairs("x-user-token", fmt.Sprintf("Bearer %s", resp.Token)))
+	tokenUser, session, err := grpcutil.GetUser(tokenCtx)
+	require.NoError(t, err)
+	require.Equal(t, curUser.ID, tokenUser.ID)
+	require.Equal(t, model.AccessTokenScopeReadOnly, session.AccessTokenScope)
+	_, err = api.Logout(tokenCtx, &apiv1.LogoutRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	tokens, err := api.GetAccessTokens(ctx, &apiv1.GetAccessTokensRequest{})
+	require.NoError(t, err)
+	require.Equal(t, resp.AccessToken.Id, tokens.AccessTokens[0].Id)
+	require.NotNil(t, tokens.AccessTokens[0].LastUsedAt)
+
+	// Other users cannot see or revoke the token.
+	otherID, err := api.m.db.AddUser(&model.User{Username: uuid.New().String(), Active: true}, nil)
+	require.NoError(t, err)
+	otherResp, err := api.PostAccessToken(ctx, &apiv1.PostAccessTokenRequest{
+		Description: "other",
+		Scope:       userv1.AccessTokenScope_ACCESS_TOKEN_SCOPE_FULL,
+		UserId:      ptrs.Ptr(int32(otherID)),
+	})
+	require.NoError(t, err)
+	otherCtx := metadata.NewIncomingContext(context.TODO(),
+		metadata.Pairs("x-user-token", fmt.Sprintf("Bearer %s", otherResp.Token)))
+	_, err = api.GetAccessTokens(otherCtx, &apiv1.GetAccessTokensRequest{
+		UserId: ptrs.Ptr(int32(curUser.ID)),
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = api.DeleteAccessToken(otherCtx, &apiv1.DeleteAccessTokenRequest{
+		Id: resp.AccessToken.Id,
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// Revoked tokens no longer authenticate.
+	_, err = api.DeleteAccessToken(ctx, &apiv1.DeleteAccessTokenRequest{Id: resp.AccessToken.Id})
+	require.NoError(t, err)
+	_, _, err = grpcutil.GetUser(tokenCtx)
+	require.Equal(t, grpcutil.ErrInvalidCredentials, err)
+
+	tokens, err = api.GetAccessTokens(ctx, &apiv1.GetAccessTokensRequest{})
+	require.NoError(t, err)
+	for _, token := range tokens.AccessTokens {
+		require.NotEqual(t, resp.AccessToken.Id, token.Id)
+	}
+	tokens, err = api.GetAccessTokens(ctx, &apiv1.GetAccessTokensRequest{IncludeInactive: true})
+	require.NoError(t, err)
+	require.Equal(t, resp.AccessToken.Id, tokens.AccessTokens[0].Id)
+	require.NotNil(t, tokens.AccessTokens[0].RevokedAt)
+}
+
+func TestSSHKeys(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t)
+
+	_, err := api.PostSSHKey(ctx, &apiv1.PostSSHKeyRequest{PublicKey: "not a key"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	keys, err := ssh.GenerateKey(1024, nil)
+	require.NoError(t, err)
+	resp, err := api.PostSSHKey(ctx, &apiv1.PostSSHKeyRequest{
+		PublicKey: strings.TrimSpace(string(keys.PublicKey)) + " me@laptop",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "me@laptop", resp.SshKey.Name)
+	require.Equal(t, int32(curUser.ID), resp.SshKey.UserId)
+	_, err = api.PostSSHKey(ctx, &apiv1.PostSSHKeyRequest{PublicKey: string(keys.PublicKey)})
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	trialKeys, err := ssh.GenerateKey(1024, nil)
+	require.NoError(t, err)
+	trialResp, err := api.PostSSHKey(ctx, &apiv1.PostSSHKeyRequest{
+		PublicKey:        string(trialKeys.PublicKey),
+		Name:             "cluster",
+		InjectIntoTrials: true,
+	})
+	require.NoError(t, err)
+
+	listResp, err := api.GetSSHKeys(ctx, &apiv1.GetSSHKeysRequest{})
+	require.NoError(t, err)
+	require.Len(t, listResp.SshKeys, 2)
+	require.Equal(t, trialResp.SshKey.Id, listResp.SshKeys[0].Id)
+
+	// Shells authorize every key, trials only the keys injected into them.
+	authorized, err := user.AuthorizedSSHKeys(ctx, curUser.ID, false)
+	require.NoError(t, err)
+	require.Equal(t, resp.SshKey.PublicKey+"\n"+trialResp.SshKey.PublicKey+"\n",
+		string(authorized))
+	authorized, err = user.AuthorizedSSHKeys(ctx, curUser.ID, true)
+	require.NoError(t, err)
+	require.Equal(t, trialResp.SshKey.PublicKey+"\n", string(authorized))
+
+	// Other users cannot see or remove the keys.
+	otherID, err := api.m.db.AddUser(&model.User{Username: uuid.New().String(), Active: true}, nil)
+	require.NoError(t, err)
+	tokenResp, err := api.PostAccessToken(ctx, &apiv1.PostAccessTokenRequest{
+		Description: "other",
+		Scope:       userv1.AccessTokenScope_ACCESS_TOKEN_SCOPE_FULL,
+		UserId:      ptrs.Ptr(int32(otherID)),
+	})
+	require.NoError(t, err)
+	otherCtx := metadata.NewIncomingContext(context.TODO(),
+		metadata.Pairs("x-user-token", fmt.Sprintf("Bearer %s", tokenResp.Token)))
+	_, err = api.GetSSHKeys(otherCtx, &apiv1.GetSSHKeysRequest{
+		UserId: ptrs.Ptr(int32(curUser.ID)),
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = api.DeleteSSHKey(otherCtx, &apiv1.DeleteSSHKeyRequest{Id: resp.SshKey.Id})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = api.DeleteSSHKey(ctx, &apiv1.DeleteSSHKeyRequest{Id: resp.SshKey.Id})
+	require.NoError(t, err)
+	listResp, err = api.GetSSHKeys(ctx, &apiv1.GetSSHKeysRequest{})
+	require.NoError(t, err)
+	require.Len(t, listResp.SshKeys, 1)
+}
+
 func setupUserAuthzTest(t *testing.T) (*apiServer, *mocks.UserAuthZ, model.User, context.Context) {
 	api, curUser, ctx := setupAPITest(t)
 
@@ -301,6 +447,23 @@ func TestAuthzSetUserPassword(t *testing.T) {
 	require.Equal(t, err, cantViewUserError)
 }
 
+func TestAuthzAccessTokens(t *testing.T) {
+	api, authzUsers, curUser, ctx := setupUserAuthzTest(t)
+
+	expectedErr := status.Error(codes.PermissionDenied, "canManageUsersAccessTokens")
+	authzUsers.On("CanManageUsersAccessTokens", mock.Anything, curUser, mock.Anything).
+		Return(fmt.Errorf("canManageUsersAccessTokens")).Once()
+	_, err := api.GetAccessTokens(ctx, &apiv1.GetAccessTokensRequest{})
+	require.Equal(t, expectedErr.Error(), err.Error())
+
+	authzUsers.On("CanManageUsersAccessTokens", mock.Anything, curUser, mock.Anything).
+		Return(fmt.Errorf("canManageUsersAccessTokens")).Once()
+	_, err = api.PostAccessToken(ctx, &apiv1.PostAccessTokenRequest{
+		Scope: userv1.AccessTokenScope_ACCESS_TOKEN_SCOPE_READ_ONLY,
+	})
+	require.Equal(t, expectedErr.Error(), err.Error())
+}
+
 func TestAuthzPatchUser(t *testing.T) {
 	api, authzUsers, curUser, ctx := setupUserAuthzTest(t)
 
@@ -375,3 +538,130 @@ func TestAuthzResetUserSetting(t *testing.T) {
 	_, err := api.ResetUserSetting(ctx, &apiv1.ResetUserSettingRequest{})
 	require.Equal(t, expectedErr.Error(), err.Error())
 }
+
+func TestLoginPasswordPolicy(t *testing.T) {
+	api, _, ctx := setupAPITest(t)
+	security := &config.GetMasterConfig().Security
+	defer func(policy config.PasswordPolicyConfig, lockout config.LockoutConfig) {
+		security.PasswordPolicy, security.Lockout = policy, lockout
+	}(security.PasswordPolicy, security.Lockout)
+	security.PasswordPolicy = config.PasswordPolicyConfig{
+		MinLength:    10,
+		RequireDigit: true,
+		MaxAge:       model.Duration(time.Hour),
+	}
+	security.Lockout = config.LockoutConfig{MaxFailedAttempts: 2, Duration: model.Duration(time.Hour)}
+
+	// New passwords must meet the policy, which requires them to be sent unhashed.
+	username := uuid.New().String()
+	for _, req := range []*apiv1.PostUserRequest{
+		{Password: "short1"},
+		{Password: replicateClientSideSaltAndHash("long-enough-1"), IsHashed: true},
+	} {
+		req.User = &userv1.User{Username: username, Active: true}
+		_, err := api.PostUser(ctx, req)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+	resp, err := api.PostUser(ctx, &apiv1.PostUserRequest{
+		User:     &userv1.User{Username: username, Active: true},
+		Password: "long-enough-1",
+	})
+	require.NoError(t, err)
+
+	// Expired passwords are changed as users log in.
+	_, err = db.Bun().NewUpdate().Table("user_password_states").
+		Set("password_changed_at = now() - interval '2 hours'").
+		Where("user_id = ?", resp.User.Id).
+		Exec(ctx)
+	require.NoError(t, err)
+	_, err = api.Login(ctx, &apiv1.LoginRequest{Username: username, Password: "long-enough-1"})
+	require.Equal(t, grpcutil.ErrPasswordExpired, err)
+	_, err = api.Login(ctx, &apiv1.LoginRequest{
+		Username: username, Password: "long-enough-1", NewPassword: "long-enough-1",
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = api.Login(ctx, &apiv1.LoginRequest{
+		Username: username, Password: "long-enough-1", NewPassword: "long-enough-2",
+	})
+	require.NoError(t, err)
+	_, err = api.Login(ctx, &apiv1.LoginRequest{Username: username, Password: "long-enough-2"})
+	require.NoError(t, err)
+
+	// Too many failed logins in a row lock the user out, even with the right password.
+	for i := 0; i < 2; i++ {
+		_, err = api.Login(ctx, &apiv1.LoginRequest{Username: username, Password: "wrong"})
+		require.Equal(t, grpcutil.ErrInvalidCredentials, err)
+	}
+	_, err = api.Login(ctx, &apiv1.LoginRequest{Username: username, Password: "long-enough-2"})
+	require.Equal(t, grpcutil.ErrUserLocked, err)
+}
+
+func TestServiceAccounts(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t)
+
+	ownerID, err := api.m.db.AddUser(&model.User{Username: uuid.New().String(), Active: true}, nil)
+	require.NoError(t, err)
+	ownerUser, err := user.UserByID(ownerID)
+	require.NoError(t, err)
+	loginResp, err := api.Login(context.TODO(), &apiv1.LoginRequest{Username: ownerUser.Username})
+	require.NoError(t, err)
+	ownerCtx := metadata.NewIncomingContext(context.TODO(),
+		metadata.Pairs("x-user-token", fmt.Sprintf("Bearer %s", loginResp.Token)))
+
+	// Service accounts have no password and belong to an owner.
+	username := uuid.New().String()
+	_, err = api.PostUser(ctx, &apiv1.PostUserRequest{
+		User:     &userv1.User{Username: username, Active: true, ServiceAccount: true},
+		Password: "secret",
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	resp, err := api.PostUser(ctx, &apiv1.PostUserRequest{User: &userv1.User{
+		Username: username, Active: true, ServiceAccount: true,
+		OwnerId: ptrs.Ptr(int32(ownerID)),
+	}})
+	require.NoError(t, err)
+	require.True(t, resp.User.ServiceAccount)
+	require.Equal(t, int32(ownerID), *resp.User.OwnerId)
+	serviceAccountID := resp.User.Id
+
+	_, err = api.Login(context.TODO(), &apiv1.LoginRequest{Username: username})
+	require.Equal(t, grpcutil.ErrServiceAccountLogin, err)
+	_, err = api.SetUserPassword(ctx, &apiv1.SetUserPasswordRequest{
+		UserId: serviceAccountID, Password: "secret",
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// The owner manages the access tokens of the service account, which authenticate as it.
+	tokenResp, err := api.PostAccessToken(ownerCtx, &apiv1.PostAccessTokenRequest{
+		Description: "ci",
+		Scope:       userv1.AccessTokenScope_ACCESS_TOKEN_SCOPE_FULL,
+		UserId:      &serviceAccountID,
+	})
+	require.NoError(t, err)
+	tokenCtx := metadata.NewIncomingContext(context.TODO(),
+		metadata.Pairs("x-user-token", fmt.Sprintf("Bearer %s", tokenResp.Token)))
+	tokenUser, _, err := grpcutil.GetUser(tokenCtx)
+	require.NoError(t, err)
+	require.Equal(t, model.UserID(serviceAccountID), tokenUser.ID)
+
+	// The owner can transfer the service account, after which it is no longer theirs to manage.
+	_, err = api.PatchUser(ownerCtx, &apiv1.PatchUserRequest{
+		UserId: serviceAccountID,
+		User:   &userv1.PatchUser{OwnerId: &serviceAccountID},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = api.PatchUser(ownerCtx, &apiv1.PatchUserRequest{
+		UserId: serviceAccountID,
+		User:   &userv1.PatchUser{OwnerId: ptrs.Ptr(int32(curUser.ID))},
+	})
+	require.NoError(t, err)
+	_, err = api.GetAccessTokens(ownerCtx, &apiv1.GetAccessTokensRequest{
+		UserId: &serviceAccountID,
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = api.PatchUser(ctx, &apiv1.PatchUserRequest{
+		UserId: int32(ownerID),
+		User:   &userv1.PatchUser{OwnerId: ptrs.Ptr(int32(curUser.ID))},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,192 @@
+package internal
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	petname "github.com/dustinkirkland/golang-petname"
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/internal/api"
+	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/master/internal/user"
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/archive"
+	"github.com/determined-ai/determined/master/pkg/check"
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/protoutils"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/vscodev1"
+)
+
+const (
+	vscodeDir        = "/run/determined/vscode/"
+	vscodeEntrypoint = "/run/determined/vscode/vscode-entrypoint.sh"
+	// Agent ports 2600 - 3800 are split between TensorBoards, Notebooks, Shells, and VS Code.
+	minVSCodePort = 3500
+	maxVSCodePort = minVSCodePort + 299
+)
+
+var vscodesAddr = actor.Addr("vscodes")
+
+func (a *apiServer) GetVSCodes(
+	ctx context.Context, req *apiv1.GetVSCodesRequest,
+) (resp *apiv1.GetVSCodesResponse, err error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.ask(vscodesAddr, req, &resp); err != nil {
+		return nil, err
+	}
+
+	a.filter(&resp.Vscodes, func(i int) bool {
+		if err != nil {
+			return false
+		}
+		ok, serverError := user.AuthZProvider.Get().CanAccessNTSCTask(
+			ctx, *curUser, model.UserID(resp.Vscodes[i].UserId))
+		if serverError != nil {
+			err = serverError
+		}
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.sort(resp.Vscodes, req.OrderBy, req.SortBy, apiv1.GetVSCodesRequest_SORT_BY_ID)
+	return resp, a.paginate(&resp.Pagination, &resp.Vscodes, req.Offset, req.Limit)
+}
+
+func (a *apiServer) GetVSCode(
+	ctx context.Context, req *apiv1.GetVSCodeRequest,
+) (resp *apiv1.GetVSCodeResponse, err error) {
+	curUser, _, err := grpcutil.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := vscodesAddr.Child(req.VscodeId)
+	if err = a.ask(addr, req, &resp); err != nil {
+		return nil, err
+	}
+
+	if ok, err := user.AuthZProvider.Get().CanAccessNTSCTask(
+		ctx, *curUser, model.UserID(resp.Vscode.UserId)); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errActorNotFound(addr)
+	}
+	return resp, nil
+}
+
+func (a *apiServer) KillVSCode(
+	ctx context.Context, req *apiv1.KillVSCodeRequest,
+) (resp *apiv1.KillVSCodeResponse, err error) {
+	if _, err := a.GetVSCode(ctx,
+		&apiv1.GetVSCodeRequest{VscodeId: req.VscodeId}); err != nil {
+		return nil, err
+	}
+
+	return resp, a.ask(vscodesAddr.Child(req.VscodeId), req, &resp)
+}
+
+func (a *apiServer) SetVSCodePriority(
+	ctx context.Context, req *apiv1.SetVSCodePriorityRequest,
+) (resp *apiv1.SetVSCodePriorityResponse, err error) {
+	if _, err := a.GetVSCode(ctx,
+		&apiv1.GetVSCodeRequest{VscodeId: req.VscodeId}); err != nil {
+		return nil, err
+	}
+
+	return resp, a.ask(vscodesAddr.Child(req.VscodeId), req, &resp)
+}
+
+func (a *apiServer) LaunchVSCode(
+	ctx context.Context, req *apiv1.LaunchVSCodeRequest,
+) (*apiv1.LaunchVSCodeResponse, error) {
+	spec, err := a.getCommandLaunchParams(ctx, &protoCommandParams{
+		TemplateName: req.TemplateName,
+		Config:       req.Config,
+		Files:        req.Files,
+	})
+	if err != nil {
+		return nil, api.APIErrToGRPC(errors.Wrapf(err, "failed to prepare launch params"))
+	}
+
+	// VS Code servers do not report whether they are idle, so only traffic through the proxy
+	// counts as activity.
+	spec.WatchProxyIdleTimeout = true
+	// code-server cannot be told the path it is served from, so it is served from the root.
+	spec.StripPrefix = true
+
+	// Postprocess the spec.
+	if spec.Config.Description == "" {
+		petName := petname.Generate(expconf.TaskNameGeneratorWords, expconf.TaskNameGeneratorSep)
+		spec.Config.Description = fmt.Sprintf("VS Code (%s)", petName)
+	}
+
+	if req.Preview {
+		return &apiv1.LaunchVSCodeResponse{
+			Vscode: &vscodev1.VSCode{},
+			Config: protoutils.ToStruct(spec.Config),
+		}, nil
+	}
+
+	// Selecting a random port mitigates the risk of multiple processes binding
+	// the same port on an agent in host mode.
+	port := getRandomPort(minVSCodePort, maxVSCodePort)
+	spec.Base.ExtraEnvVars = map[string]string{
+		"VSCODE_PORT":   strconv.Itoa(port),
+		"DET_TASK_TYPE": string(model.TaskTypeVSCode),
+	}
+	spec.Port = &port
+	spec.Config.Environment.Ports = map[string]int{"vscode": port}
+
+	spec.Config.Entrypoint = []string{vscodeEntrypoint}
+
+	if err = check.Validate(spec.Config); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid VS Code config: %s", err.Error())
+	}
+
+	spec.AdditionalFiles = archive.Archive{
+		spec.Base.AgentUserGroup.OwnedArchiveItem(vscodeDir, nil, 0o700, tar.TypeDir),
+		spec.Base.AgentUserGroup.OwnedArchiveItem(
+			vscodeEntrypoint,
+			etc.MustStaticFile(etc.VSCodeEntrypointResource),
+			0o700,
+			tar.TypeReg,
+		),
+		spec.Base.AgentUserGroup.OwnedArchiveItem(
+			taskReadyCheckLogs,
+			etc.MustStaticFile(etc.TaskCheckReadyLogsResource),
+			0o700,
+			tar.TypeReg,
+		),
+	}
+
+	// Launch a VS Code actor.
+	var vscodeID model.TaskID
+	if err = a.ask(vscodesAddr, *spec, &vscodeID); err != nil {
+		return nil, err
+	}
+
+	var vscode *vscodev1.VSCode
+	if err = a.ask(vscodesAddr.Child(vscodeID), &vscodev1.VSCode{}, &vscode); err != nil {
+		return nil, err
+	}
+
+	return &apiv1.LaunchVSCodeResponse{
+		Vscode: vscode,
+		Config: protoutils.ToStruct(spec.Config),
+	}, nil
+}
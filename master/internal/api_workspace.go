@@ -12,8 +12,11 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/determined-ai/determined/master/internal/auditlog"
 	"github.com/determined-ai/determined/master/internal/db"
 	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/master/internal/user"
+	"github.com/determined-ai/determined/master/internal/usergroup"
 	"github.com/determined-ai/determined/master/internal/workspace"
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/master/pkg/schemas"
@@ -409,6 +412,18 @@ func (a *apiServer) PatchWorkspace(
 		insertColumns = append(insertColumns, "checkpoint_storage_config")
 	}
 
+	if req.Workspace.LogRetentionDays != nil {
+		if err = workspace.AuthZProvider.Get().
+			CanSetWorkspacesLogRetention(ctx, currUser, currWorkspace); err != nil {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+
+		if *req.Workspace.LogRetentionDays >= 0 {
+			updatedWorkspace.LogRetentionDays = req.Workspace.LogRetentionDays
+		}
+		insertColumns = append(insertColumns, "log_retention_days")
+	}
+
 	if len(insertColumns) == 0 {
 		return &apiv1.PatchWorkspaceResponse{Workspace: currWorkspace}, nil
 	}
@@ -570,3 +585,155 @@ func (a *apiServer) UnpinWorkspace(
 	return &apiv1.UnpinWorkspaceResponse{},
 		errors.Wrapf(err, "error un-pinning workspace (%d)", req.Id)
 }
+
+// canManageWorkspaceMembers requires the user to be an admin of the workspace.
+func canManageWorkspaceMembers(
+	ctx context.Context, curUser model.User, w *workspacev1.Workspace,
+) error {
+	return workspace.RequireWorkspaceRole(ctx, curUser, int(w.Id), model.MemberRoleAdmin)
+}
+
+// newMember returns the member a request to give a user a role describes.
+func newMember(userID int32, role workspacev1.MemberRole) (*model.Member, error) {
+	r := model.MemberRoleFromProto(role)
+	if r == "" {
+		return nil, status.Error(codes.InvalidArgument, "a role is required")
+	}
+	u, err := user.UserByID(model.UserID(userID))
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "user (%d) not found", userID)
+	case err != nil:
+		return nil, err
+	}
+	return &model.Member{UserID: u.ID, Username: u.Username, Role: r}, nil
+}
+
+func (a *apiServer) GetWorkspaceMembers(
+	ctx context.Context, req *apiv1.GetWorkspaceMembersRequest,
+) (*apiv1.GetWorkspaceMembersResponse, error) {
+	if _, _, err := a.getWorkspaceAndCheckCanDoActions(ctx, req.Id, false); err != nil {
+		return nil, err
+	}
+	members, err := workspace.WorkspaceMembers(ctx, int(req.Id))
+	if err != nil {
+		return nil, err
+	}
+	groupMembers, err := workspace.WorkspaceGroupMembers(ctx, int(req.Id))
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.GetWorkspaceMembersResponse{
+		Members:      []*workspacev1.Member{},
+		GroupMembers: []*workspacev1.GroupMember{},
+	}
+	for _, m := range members {
+		resp.Members = append(resp.Members, m.Proto())
+	}
+	for _, m := range groupMembers {
+		resp.GroupMembers = append(resp.GroupMembers, m.Proto())
+	}
+	return resp, nil
+}
+
+func (a *apiServer) PutWorkspaceMember(
+	ctx context.Context, req *apiv1.PutWorkspaceMemberRequest,
+) (*apiv1.PutWorkspaceMemberResponse, error) {
+	_, curUser, err := a.getWorkspaceAndCheckCanDoActions(ctx, req.Id, false,
+		canManageWorkspaceMembers)
+	if err != nil {
+		return nil, err
+	}
+	m, err := newMember(req.UserId, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if err = workspace.SetWorkspaceMember(ctx, int(req.Id), m.UserID, m.Role); err != nil {
+		return nil, err
+	}
+	recordMemberChange(ctx, curUser, auditlog.WorkspaceMemberSet, "workspace", req.Id,
+		map[string]interface{}{"user_id": m.UserID, "role": m.Role})
+	return &apiv1.PutWorkspaceMemberResponse{Member: m.Proto()}, nil
+}
+
+func (a *apiServer) DeleteWorkspaceMember(
+	ctx context.Context, req *apiv1.DeleteWorkspaceMemberRequest,
+) (*apiv1.DeleteWorkspaceMemberResponse, error) {
+	_, curUser, err := a.getWorkspaceAndCheckCanDoActions(ctx, req.Id, false,
+		canManageWorkspaceMembers)
+	if err != nil {
+		return nil, err
+	}
+	switch err := workspace.RemoveWorkspaceMember(ctx, int(req.Id), model.UserID(req.UserId)); {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound,
+			"user (%d) is not a member of workspace (%d)", req.UserId, req.Id)
+	case err != nil:
+		return nil, err
+	}
+	recordMemberChange(ctx, curUser, auditlog.WorkspaceMemberRemove, "workspace", req.Id,
+		map[string]interface{}{"user_id": req.UserId})
+	return &apiv1.DeleteWorkspaceMemberResponse{}, nil
+}
+
+func (a *apiServer) PutWorkspaceGroupMember(
+	ctx context.Context, req *apiv1.PutWorkspaceGroupMemberRequest,
+) (*apiv1.PutWorkspaceGroupMemberResponse, error) {
+	_, curUser, err := a.getWorkspaceAndCheckCanDoActions(ctx, req.Id, false,
+		canManageWorkspaceMembers)
+	if err != nil {
+		return nil, err
+	}
+	role := model.MemberRoleFromProto(req.Role)
+	if role == "" {
+		return nil, status.Error(codes.InvalidArgument, "a role is required")
+	}
+	g, err := usergroup.GroupByIDTx(ctx, nil, int(req.GroupId))
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "group (%d) not found", req.GroupId)
+	case err != nil:
+		return nil, err
+	}
+	if err = workspace.SetWorkspaceGroupMember(ctx, int(req.Id), g.ID, role); err != nil {
+		return nil, err
+	}
+	recordMemberChange(ctx, curUser, auditlog.WorkspaceMemberSet, "workspace", req.Id,
+		map[string]interface{}{"group_id": g.ID, "role": role})
+	m := model.GroupMember{GroupID: g.ID, GroupName: g.Name, Role: role}
+	return &apiv1.PutWorkspaceGroupMemberResponse{Member: m.Proto()}, nil
+}
+
+func (a *apiServer) DeleteWorkspaceGroupMember(
+	ctx context.Context, req *apiv1.DeleteWorkspaceGroupMemberRequest,
+) (*apiv1.DeleteWorkspaceGroupMemberResponse, error) {
+	_, curUser, err := a.getWorkspaceAndCheckCanDoActions(ctx, req.Id, false,
+		canManageWorkspaceMembers)
+	if err != nil {
+		return nil, err
+	}
+	switch err := workspace.RemoveWorkspaceGroupMember(ctx, int(req.Id), int(req.GroupId)); {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound,
+			"group (%d) is not a member of workspace (%d)", req.GroupId, req.Id)
+	case err != nil:
+		return nil, err
+	}
+	recordMemberChange(ctx, curUser, auditlog.WorkspaceMemberRemove, "workspace", req.Id,
+		map[string]interface{}{"group_id": req.GroupId})
+	return &apiv1.DeleteWorkspaceGroupMemberResponse{}, nil
+}
+
+// recordMemberChange records a change to the members of a workspace or project in the audit log.
+func recordMemberChange(
+	ctx context.Context, curUser model.User, action auditlog.Action, targetType string,
+	targetID int32, details map[string]interface{},
+) {
+	auditlog.Record(ctx, &curUser, auditlog.Entry{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   strconv.Itoa(int(targetID)),
+		Success:    true,
+		Details:    details,
+	})
+}
@@ -18,11 +18,16 @@ import (
 	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
+	"github.com/determined-ai/determined/master/internal/db"
+	expauth "github.com/determined-ai/determined/master/internal/experiment"
 	"github.com/determined-ai/determined/master/internal/mocks"
+	"github.com/determined-ai/determined/master/internal/user"
+	"github.com/determined-ai/determined/master/internal/usergroup"
 	"github.com/determined-ai/determined/master/internal/workspace"
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
 	"github.com/determined-ai/determined/proto/pkg/projectv1"
+	"github.com/determined-ai/determined/proto/pkg/userv1"
 	"github.com/determined-ai/determined/proto/pkg/workspacev1"
 )
 
@@ -383,3 +388,206 @@ func TestAuthzWorkspaceGetThenActionRoutes(t *testing.T) {
 		require.Equal(t, expectedErr.Error(), curCase.IDToReqCall(id).Error())
 	}
 }
+
+func TestWorkspaceAndProjectMembers(t *testing.T) {
+	api, _, ctx := setupAPITest(t)
+	workspaceID, projectID := createProjectAndWorkspace(ctx, t, api)
+	userResp, err := api.PostUser(ctx, &apiv1.PostUserRequest{
+		User: &userv1.User{Username: uuid.New().String(), Active: true},
+	})
+	require.NoError(t, err)
+	member := model.User{ID: model.UserID(userResp.User.Id)}
+
+	// Without a role the user has none in the workspace or its projects.
+	role, err := workspace.ProjectRole(ctx, member, projectID)
+	require.NoError(t, err)
+	require.False(t, role.AtLeast(model.MemberRoleViewer))
+
+	// A role is required, and the user must exist.
+	_, err = api.PutWorkspaceMember(ctx, &apiv1.PutWorkspaceMemberRequest{
+		Id: int32(workspaceID), UserId: userResp.User.Id,
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = api.PutWorkspaceMember(ctx, &apiv1.PutWorkspaceMemberRequest{
+		Id: int32(workspaceID), UserId: -999, Role: workspacev1.MemberRole_MEMBER_ROLE_VIEWER,
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// A role in the workspace is a role in its projects.
+	_, err = api.PutWorkspaceMember(ctx, &apiv1.PutWorkspaceMemberRequest{
+		Id: int32(workspaceID), UserId: userResp.User.Id,
+		Role: workspacev1.MemberRole_MEMBER_ROLE_VIEWER,
+	})
+	require.NoError(t, err)
+	wResp, err := api.GetWorkspaceMembers(ctx, &apiv1.GetWorkspaceMembersRequest{
+		Id: int32(workspaceID),
+	})
+	require.NoError(t, err)
+	require.Len(t, wResp.Members, 1)
+	require.Equal(t, userResp.User.Id, wResp.Members[0].UserId)
+	require.Equal(t, workspacev1.MemberRole_MEMBER_ROLE_VIEWER, wResp.Members[0].Role)
+	role, err = workspace.ProjectRole(ctx, member, projectID)
+	require.NoError(t, err)
+	require.Equal(t, model.MemberRoleViewer, role)
+
+	// A greater role in the project wins over the role in the workspace.
+	_, err = api.PutProjectMember(ctx, &apiv1.PutProjectMemberRequest{
+		Id: int32(projectID), UserId: userResp.User.Id,
+		Role: workspacev1.MemberRole_MEMBER_ROLE_EDITOR,
+	})
+	require.NoError(t, err)
+	pResp, err := api.GetProjectMembers(ctx, &apiv1.GetProjectMembersRequest{
+		Id: int32(projectID),
+	})
+	require.NoError(t, err)
+	require.Len(t, pResp.Members, 1)
+	role, err = workspace.ProjectRole(ctx, member, projectID)
+	require.NoError(t, err)
+	require.Equal(t, model.MemberRoleEditor, role)
+	role, err = workspace.WorkspaceRole(ctx, member, workspaceID)
+	require.NoError(t, err)
+	require.Equal(t, model.MemberRoleViewer, role)
+
+	// Removing the roles removes access.
+	_, err = api.DeleteProjectMember(ctx, &apiv1.DeleteProjectMemberRequest{
+		Id: int32(projectID), UserId: userResp.User.Id,
+	})
+	require.NoError(t, err)
+	_, err = api.DeleteWorkspaceMember(ctx, &apiv1.DeleteWorkspaceMemberRequest{
+		Id: int32(workspaceID), UserId: userResp.User.Id,
+	})
+	require.NoError(t, err)
+	_, err = api.DeleteWorkspaceMember(ctx, &apiv1.DeleteWorkspaceMemberRequest{
+		Id: int32(workspaceID), UserId: userResp.User.Id,
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	role, err = workspace.ProjectRole(ctx, member, projectID)
+	require.NoError(t, err)
+	require.False(t, role.AtLeast(model.MemberRoleViewer))
+}
+
+func TestRolesCanDeleteExperiment(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t)
+	workspaceID, projectID := createProjectAndWorkspace(ctx, t, api)
+	userID, _ := userContext(ctx, t, api)
+	member := model.User{ID: model.UserID(userID)}
+	_, err := api.PutWorkspaceMember(ctx, &apiv1.PutWorkspaceMemberRequest{
+		Id: int32(workspaceID), UserId: userID, Role: workspacev1.MemberRole_MEMBER_ROLE_EDITOR,
+	})
+	require.NoError(t, err)
+
+	// Editors can only delete the experiments they own; experiments without an owner are not
+	// theirs.
+	authz := &expauth.ExperimentAuthZRoles{}
+	e := &model.Experiment{ProjectID: projectID}
+	require.Error(t, authz.CanDeleteExperiment(ctx, member, e))
+	e.OwnerID = &curUser.ID
+	require.Error(t, authz.CanDeleteExperiment(ctx, member, e))
+	e.OwnerID = &member.ID
+	require.NoError(t, authz.CanDeleteExperiment(ctx, member, e))
+}
+
+func TestWorkspaceGroupMembers(t *testing.T) {
+	api, _, ctx := setupAPITest(t)
+	workspaceID, projectID := createProjectAndWorkspace(ctx, t, api)
+	userResp, err := api.PostUser(ctx, &apiv1.PostUserRequest{
+		User: &userv1.User{Username: uuid.New().String(), Active: true},
+	})
+	require.NoError(t, err)
+	member := model.User{ID: model.UserID(userResp.User.Id)}
+	group, _, err := usergroup.AddGroupWithMembers(ctx,
+		usergroup.Group{Name: uuid.New().String()}, member.ID)
+	require.NoError(t, err)
+
+	_, err = api.PutWorkspaceGroupMember(ctx, &apiv1.PutWorkspaceGroupMemberRequest{
+		Id: int32(workspaceID), GroupId: -999, Role: workspacev1.MemberRole_MEMBER_ROLE_EDITOR,
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// The users in a group get its role in the workspace and its projects.
+	_, err = api.PutWorkspaceGroupMember(ctx, &apiv1.PutWorkspaceGroupMemberRequest{
+		Id: int32(workspaceID), GroupId: int32(group.ID),
+		Role: workspacev1.MemberRole_MEMBER_ROLE_EDITOR,
+	})
+	require.NoError(t, err)
+	resp, err := api.GetWorkspaceMembers(ctx, &apiv1.GetWorkspaceMembersRequest{
+		Id: int32(workspaceID),
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.GroupMembers, 1)
+	require.Equal(t, group.Name, resp.GroupMembers[0].GroupName)
+	role, err := workspace.ProjectRole(ctx, member, projectID)
+	require.NoError(t, err)
+	require.Equal(t, model.MemberRoleEditor, role)
+
+	_, err = api.DeleteWorkspaceGroupMember(ctx, &apiv1.DeleteWorkspaceGroupMemberRequest{
+		Id: int32(workspaceID), GroupId: int32(group.ID),
+	})
+	require.NoError(t, err)
+	role, err = workspace.WorkspaceRole(ctx, member, workspaceID)
+	require.NoError(t, err)
+	require.False(t, role.AtLeast(model.MemberRoleViewer))
+}
+
+func TestProjectGroupMembers(t *testing.T) {
+	api, _, ctx := setupAPITest(t)
+	workspaceID, projectID := createProjectAndWorkspace(ctx, t, api)
+	group, _, err := usergroup.AddGroupWithMembers(ctx, usergroup.Group{Name: uuid.New().String()})
+	require.NoError(t, err)
+
+	// Users can be added to groups when they are created.
+	_, err = api.PostUser(ctx, &apiv1.PostUserRequest{
+		User:     &userv1.User{Username: uuid.New().String(), Active: true},
+		GroupIds: []int32{-999},
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	userResp, err := api.PostUser(ctx, &apiv1.PostUserRequest{
+		User:     &userv1.User{Username: uuid.New().String(), Active: true},
+		GroupIds: []int32{int32(group.ID), int32(group.ID)},
+	})
+	require.NoError(t, err)
+	member := model.User{ID: model.UserID(userResp.User.Id)}
+
+	// Users are not created when they cannot be added to all of their groups.
+	username := uuid.New().String()
+	_, err = api.m.db.AddUserWithGroups(
+		&model.User{Username: username, Active: true}, nil, []int{group.ID, -999})
+	require.ErrorIs(t, err, db.ErrNotFound)
+	_, err = user.UserByUsername(username)
+	require.ErrorIs(t, err, db.ErrNotFound)
+
+	// The users in a group get its role in the project but not in its workspace.
+	_, err = api.PutProjectGroupMember(ctx, &apiv1.PutProjectGroupMemberRequest{
+		Id: int32(projectID), GroupId: int32(group.ID),
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = api.PutProjectGroupMember(ctx, &apiv1.PutProjectGroupMemberRequest{
+		Id: int32(projectID), GroupId: int32(group.ID),
+		Role: workspacev1.MemberRole_MEMBER_ROLE_EDITOR,
+	})
+	require.NoError(t, err)
+	resp, err := api.GetProjectMembers(ctx, &apiv1.GetProjectMembersRequest{
+		Id: int32(projectID),
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.GroupMembers, 1)
+	require.Equal(t, group.Name, resp.GroupMembers[0].GroupName)
+	role, err := workspace.ProjectRole(ctx, member, projectID)
+	require.NoError(t, err)
+	require.Equal(t, model.MemberRoleEditor, role)
+	role, err = workspace.WorkspaceRole(ctx, member, workspaceID)
+	require.NoError(t, err)
+	require.False(t, role.AtLeast(model.MemberRoleViewer))
+
+	_, err = api.DeleteProjectGroupMember(ctx, &apiv1.DeleteProjectGroupMemberRequest{
+		Id: int32(projectID), GroupId: int32(group.ID),
+	})
+	require.NoError(t, err)
+	_, err = api.DeleteProjectGroupMember(ctx, &apiv1.DeleteProjectGroupMemberRequest{
+		Id: int32(projectID), GroupId: int32(group.ID),
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	role, err = workspace.ProjectRole(ctx, member, projectID)
+	require.NoError(t, err)
+	require.False(t, role.AtLeast(model.MemberRoleViewer))
+}
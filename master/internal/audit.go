@@ -5,9 +5,9 @@ import (
 	"strings"
 
 	"github.com/labstack/echo/v4"
-	log "github.com/sirupsen/logrus"
 
 	detContext "github.com/determined-ai/determined/master/internal/context"
+	"github.com/determined-ai/determined/master/pkg/logger"
 )
 
 // LogrusLogFn is an interface for all the logrus Levelf log functions.
@@ -70,9 +70,9 @@ func auditLogMiddleware() echo.MiddlewareFunc {
 			var logFn LogrusLogFn
 			switch method := c.Request().Method; {
 			case infoMethods[method] || unauthorized:
-				logFn = log.WithFields(fields).Infof
+				logFn = logger.FromContext(c.Request().Context()).WithFields(fields).Infof
 			case debugMethods[method]:
-				logFn = log.WithFields(fields).Debugf
+				logFn = logger.FromContext(c.Request().Context()).WithFields(fields).Debugf
 			default:
 				return
 			}
@@ -0,0 +1,240 @@
+// Package auditlog records security-relevant actions, such as logins and permission changes, to
+// an append-only table that admins can query and export.
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/protoutils"
+	"github.com/determined-ai/determined/proto/pkg/auditv1"
+)
+
+// Action is a kind of action recorded in the audit log.
+type Action string
+
+// The actions recorded in the audit log.
+const (
+	UserLogin              Action = "user.login"
+	UserCreate             Action = "user.create"
+	UserUpdate             Action = "user.update"
+	AccessTokenCreate      Action = "access_token.create"
+	AccessTokenRevoke      Action = "access_token.revoke"
+	SessionRevoke          Action = "session.revoke"
+	SSHKeyCreate           Action = "ssh_key.create"
+	SSHKeyDelete           Action = "ssh_key.delete"
+	GroupCreate            Action = "group.create"
+	GroupUpdate            Action = "group.update"
+	GroupDelete            Action = "group.delete"
+	RoleAssign             Action = "role.assign"
+	RoleRemove             Action = "role.remove"
+	WorkspaceMemberSet     Action = "workspace.member.set"
+	WorkspaceMemberRemove  Action = "workspace.member.remove"
+	ProjectMemberSet       Action = "project.member.set"
+	ProjectMemberRemove    Action = "project.member.remove"
+	ExperimentDelete       Action = "experiment.delete"
+	TemplatePut            Action = "template.put"
+	TemplateDelete         Action = "template.delete"
+	PodTemplatePut         Action = "pod_template.put"
+	PodTemplateDelete      Action = "pod_template.delete"
+	CheckpointDownload     Action = "checkpoint.download"
+	ArtifactDownload       Action = "artifact.download"
+	DatabaseBackup         Action = "database.backup"
+	DatabaseRestore        Action = "database.restore"
+	MaintenanceModeSet     Action = "maintenance_mode.set"
+	ClusterMessageSet      Action = "cluster_message.set"
+	ClusterMessageDelete   Action = "cluster_message.delete"
+	ResourcePoolBindingSet Action = "resource_pool_binding.set"
+	MFAEnroll              Action = "mfa.enroll"
+	MFARemove              Action = "mfa.remove"
+	MFARequiredSet         Action = "mfa.required.set"
+	MFARecoveryCodesSet    Action = "mfa.recovery_codes.set"
+)
+
+// Entry is an entry in the audit log.
+type Entry struct {
+	bun.BaseModel `bun:"table:audit_log"`
+
+	ID         int64                  `bun:"id,pk,autoincrement"`
+	Time       time.Time              `bun:"time,nullzero,notnull,default:current_timestamp"`
+	UserID     *model.UserID          `bun:"user_id"`
+	Username   string                 `bun:"username"`
+	Action     Action                 `bun:"action"`
+	TargetType string                 `bun:"target_type"`
+	TargetID   string                 `bun:"target_id"`
+	Success    bool                   `bun:"success"`
+	RemoteIP   string                 `bun:"remote_ip"`
+	Details    map[string]interface{} `bun:"details,type:jsonb,nullzero,notnull,default:'{}'"`
+}
+
+// Proto returns the protobuf representation of the entry.
+func (e Entry) Proto() *auditv1.AuditLogEntry {
+	pe := &auditv1.AuditLogEntry{
+		Id:         e.ID,
+		Time:       timestamppb.New(e.Time),
+		Username:   e.Username,
+		Action:     string(e.Action),
+		TargetType: e.TargetType,
+		TargetId:   e.TargetID,
+		Success:    e.Success,
+		RemoteIp:   e.RemoteIP,
+		Details:    protoutils.ToStruct(e.Details),
+	}
+	if e.UserID != nil {
+		userID := int32(*e.UserID)
+		pe.UserId = &userID
+	}
+	return pe
+}
+
+// Record appends an entry to the audit log for an action by a user, who may be nil if they are
+// unknown, such as after a failed login. The remote address is taken from the context of gRPC
+// requests if the entry does not have one. Failing to record an entry is logged rather than
+// returned, so that auditing never fails the action itself.
+func Record(ctx context.Context, user *model.User, e Entry) {
+	if user != nil {
+		e.UserID = &user.ID
+		e.Username = user.Username
+	}
+	if e.RemoteIP == "" {
+		e.RemoteIP = RemoteIP(ctx)
+	}
+	if _, err := db.Bun().NewInsert().Model(&e).Exec(context.Background()); err != nil {
+		log.WithError(err).WithField("action", e.Action).Error("failed to record audit log entry")
+	}
+}
+
+// DetailsFromProto returns the details of an action from the request that asked for it.
+func DetailsFromProto(m proto.Message) map[string]interface{} {
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	var details map[string]interface{}
+	if err := json.Unmarshal(b, &details); err != nil {
+		return nil
+	}
+	return details
+}
+
+// RemoteIP returns the address a gRPC request came from, which is its peer unless it came through
+// the gateway. The gateway runs in the master and connects over loopback, and the last address
+// it adds to x-forwarded-for is the one the HTTP request came from. Clients can send whatever
+// x-forwarded-for they like, so nothing else in it is trusted.
+func RemoteIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	tcp, ok := p.Addr.(*net.TCPAddr)
+	if !ok {
+		return p.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok && tcp.IP.IsLoopback() {
+		if forwarded := md.Get("x-forwarded-for"); len(forwarded) > 0 {
+			hops := strings.Split(forwarded[len(forwarded)-1], ",")
+			return strings.TrimSpace(hops[len(hops)-1])
+		}
+	}
+	return tcp.IP.String()
+}
+
+// RemoteHTTPIP returns the address an HTTP request came from, which is its peer. Unlike for gRPC
+// requests, nothing in the master forwards HTTP requests, so X-Forwarded-For is never trusted.
+func RemoteHTTPIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Filter narrows down a query of the audit log. Empty fields match everything.
+type Filter struct {
+	UserID     *model.UserID
+	Action     string
+	TargetType string
+	Since      *time.Time
+	Until      *time.Time
+}
+
+func (f Filter) apply(q *bun.SelectQuery) *bun.SelectQuery {
+	if f.UserID != nil {
+		q = q.Where("user_id = ?", *f.UserID)
+	}
+	if f.Action != "" {
+		q = q.Where("action = ?", f.Action)
+	}
+	if f.TargetType != "" {
+		q = q.Where("target_type = ?", f.TargetType)
+	}
+	if f.Since != nil {
+		q = q.Where("time >= ?", *f.Since)
+	}
+	if f.Until != nil {
+		q = q.Where("time < ?", *f.Until)
+	}
+	return q
+}
+
+// Query returns a page of the entries that match a filter, most recent first, along with the
+// total number of matching entries. A limit of 0 returns every entry after the offset.
+func Query(ctx context.Context, f Filter, offset, limit int) ([]Entry, int, error) {
+	entries := []Entry{}
+	q := f.apply(db.Bun().NewSelect().Model(&entries)).Order("id DESC").Offset(offset)
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	total, err := q.ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// Prune removes the entries older than the retention period.
+func Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	res, err := db.Bun().NewDelete().Model((*Entry)(nil)).
+		Where("time < ?", time.Now().Add(-retention)).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// PruneLoop prunes the audit log once a day until the context is canceled. A retention period of
+// zero keeps entries forever.
+func PruneLoop(ctx context.Context, retention time.Duration) {
+	if retention <= 0 {
+		return
+	}
+	t := time.NewTicker(24 * time.Hour)
+	defer t.Stop()
+	for {
+		if n, err := Prune(ctx, retention); err != nil {
+			log.WithError(err).Error("failed to prune audit log")
+		} else if n > 0 {
+			log.Infof("pruned %d audit log entries older than %s", n, retention)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
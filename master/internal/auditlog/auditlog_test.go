@@ -0,0 +1,53 @@
+package auditlog
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestRemoteIP(t *testing.T) {
+	remote := &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 51234}
+	gateway := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8080}
+	for _, c := range []struct {
+		name      string
+		addr      net.Addr
+		forwarded string
+		expected  string
+	}{
+		{"no peer", nil, "", ""},
+		{"direct", remote, "", "203.0.113.7"},
+		{"direct with a forged header", remote, "198.51.100.1", "203.0.113.7"},
+		{"gateway", gateway, "198.51.100.1", "198.51.100.1"},
+		{"gateway with a forged header", gateway, "10.0.0.1, 198.51.100.1",
+			"198.51.100.1"},
+		{"gateway without the header", gateway, "", "127.0.0.1"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			if c.addr != nil {
+				ctx = peer.NewContext(ctx, &peer.Peer{Addr: c.addr})
+			}
+			if c.forwarded != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-forwarded-for", c.forwarded))
+			}
+			require.Equal(t, c.expected, RemoteIP(ctx))
+		})
+	}
+}
+
+func TestRemoteHTTPIP(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "203.0.113.7:51234"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	require.Equal(t, "203.0.113.7", RemoteHTTPIP(r))
+
+	r.RemoteAddr = "[2001:db8::1]:51234"
+	require.Equal(t, "2001:db8::1", RemoteHTTPIP(r))
+}
@@ -0,0 +1,119 @@
+package auditlog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	detContext "github.com/determined-ai/determined/master/internal/context"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// ExportPath is where admins export the audit log.
+const ExportPath = "/audit-log/export"
+
+const exportBatchSize = 1000
+
+var exportHeader = []string{
+	"id", "time", "user_id", "username", "action", "target_type", "target_id", "success",
+	"remote_ip", "details",
+}
+
+// Export writes the entries that match the filter in the query parameters as CSV, most recent
+// first. Only admins can export the audit log. Entries are read in batches so that exporting a
+// large log does not hold it in memory.
+func Export(c echo.Context) error {
+	if !c.(*detContext.DetContext).MustGetUser().Admin {
+		return echo.NewHTTPError(http.StatusForbidden, "user not admin")
+	}
+	f, err := parseFilter(c)
+	if err != nil {
+		return err
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/csv")
+	res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="audit-log.csv"`)
+	res.WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(res)
+	if err := w.Write(exportHeader); err != nil {
+		return err
+	}
+	var before *int64
+	for {
+		var entries []Entry
+		q := f.apply(db.Bun().NewSelect().Model(&entries)).Order("id DESC").Limit(exportBatchSize)
+		if before != nil {
+			q = q.Where("id < ?", *before)
+		}
+		if err := q.Scan(c.Request().Context()); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := w.Write(e.csvRecord()); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+		if len(entries) < exportBatchSize {
+			return nil
+		}
+		before = &entries[len(entries)-1].ID
+	}
+}
+
+func (e Entry) csvRecord() []string {
+	var userID string
+	if e.UserID != nil {
+		userID = strconv.Itoa(int(*e.UserID))
+	}
+	details, _ := json.Marshal(e.Details)
+	return []string{
+		strconv.FormatInt(e.ID, 10),
+		e.Time.UTC().Format(time.RFC3339Nano),
+		userID,
+		e.Username,
+		string(e.Action),
+		e.TargetType,
+		e.TargetID,
+		strconv.FormatBool(e.Success),
+		e.RemoteIP,
+		string(details),
+	}
+}
+
+// parseFilter parses a filter from the query parameters user_id, action, target_type, and since
+// and until, which are RFC 3339 times.
+func parseFilter(c echo.Context) (Filter, error) {
+	f := Filter{Action: c.QueryParam("action"), TargetType: c.QueryParam("target_type")}
+	if v := c.QueryParam("user_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return Filter{}, echo.NewHTTPError(http.StatusBadRequest, "invalid user_id: "+v)
+		}
+		userID := model.UserID(id)
+		f.UserID = &userID
+	}
+	for param, dest := range map[string]**time.Time{"since": &f.Since, "until": &f.Until} {
+		v := c.QueryParam(param)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return Filter{}, echo.NewHTTPError(http.StatusBadRequest,
+				"invalid "+param+", expected an RFC 3339 time: "+v)
+		}
+		*dest = &t
+	}
+	return f, nil
+}
@@ -0,0 +1,62 @@
+package auditlog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestParseFilter(t *testing.T) {
+	parse := func(query string) (Filter, error) {
+		req := httptest.NewRequest(http.MethodGet, ExportPath+"?"+query, nil)
+		return parseFilter(echo.New().NewContext(req, httptest.NewRecorder()))
+	}
+
+	f, err := parse("")
+	require.NoError(t, err)
+	require.Equal(t, Filter{}, f)
+
+	f, err = parse("user_id=3&action=user.login&target_type=user&since=2022-12-01T00:00:00Z")
+	require.NoError(t, err)
+	require.Equal(t, model.UserID(3), *f.UserID)
+	require.Equal(t, "user.login", f.Action)
+	require.Equal(t, "user", f.TargetType)
+	require.Equal(t, time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC), *f.Since)
+	require.Nil(t, f.Until)
+
+	for _, query := range []string{"user_id=admin", "until=yesterday"} {
+		_, err := parse(query)
+		require.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code, query)
+	}
+}
+
+func TestCSVRecord(t *testing.T) {
+	userID := model.UserID(2)
+	e := Entry{
+		ID:         7,
+		Time:       time.Date(2022, 12, 14, 10, 0, 0, 0, time.UTC),
+		UserID:     &userID,
+		Username:   "alice",
+		Action:     RoleAssign,
+		TargetType: "role",
+		TargetID:   "5",
+		Success:    true,
+		RemoteIP:   "10.0.0.1",
+		Details:    map[string]interface{}{"scope": "global"},
+	}
+	require.Equal(t, []string{
+		"7", "2022-12-14T10:00:00Z", "2", "alice", "role.assign", "role", "5", "true", "10.0.0.1",
+		`{"scope":"global"}`,
+	}, e.csvRecord())
+
+	// Entries for unknown users have an empty user ID.
+	e.UserID = nil
+	require.Equal(t, "", e.csvRecord()[2])
+	require.Len(t, e.csvRecord(), len(exportHeader))
+}
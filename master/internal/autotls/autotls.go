@@ -0,0 +1,385 @@
+// Package autotls obtains the serving certificate of the master from an ACME certificate
+// authority, such as Let's Encrypt, and renews it before it expires. Control of the domains is
+// proven with HTTP-01 challenges, served by the master on a plain HTTP port, or with DNS-01
+// challenges, whose records are published by a hook command.
+package autotls
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/acme"
+
+	"github.com/determined-ai/determined/master/internal/config"
+)
+
+const (
+	accountKeyFile = "account.key"
+	certFile       = "cert.pem"
+	keyFile        = "key.pem"
+
+	// obtainTimeout bounds how long getting a certificate may take, including waiting for the
+	// certificate authority to validate every challenge.
+	obtainTimeout = 10 * time.Minute
+	// renewCheckInterval is how often the certificate is checked for renewal, and so how soon a
+	// failed renewal is retried.
+	renewCheckInterval = time.Hour
+)
+
+// Manager holds the current certificate of the master and keeps it renewed.
+type Manager struct {
+	conf   config.ACMEConfig
+	client *acme.Client
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+	// responses are the key authorizations of pending HTTP-01 challenges, by request path.
+	responses map[string]string
+}
+
+// New returns a manager that obtains certificates as configured.
+func New(conf config.ACMEConfig) *Manager {
+	return &Manager{conf: conf, responses: map[string]string{}}
+}
+
+// Start returns a certificate for the configured domains, from the cache directory if it has one
+// that is not yet due for renewal or else from the certificate authority, and keeps renewing it
+// until ctx is canceled.
+func (m *Manager) Start(ctx context.Context) (*tls.Certificate, error) {
+	if err := os.MkdirAll(m.conf.CacheDir, 0o700); err != nil {
+		return nil, errors.Wrap(err, "creating the ACME cache directory")
+	}
+	key, err := m.loadOrCreateAccountKey()
+	if err != nil {
+		return nil, err
+	}
+	m.client = &acme.Client{Key: key, DirectoryURL: m.conf.DirectoryURL}
+
+	if m.conf.Challenge == config.ACMEChallengeHTTP01 {
+		if err := m.serveHTTPChallenges(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	cert, err := m.loadCert()
+	if err != nil || m.needsRenewal(cert) {
+		log.Infof("obtaining a TLS certificate for %s through ACME",
+			strings.Join(m.conf.Domains, ", "))
+		if cert, err = m.obtainCert(ctx); err != nil {
+			return nil, err
+		}
+	}
+	m.setCert(cert)
+
+	go m.renew(ctx)
+	return cert, nil
+}
+
+// GetCertificate returns the current certificate; it is meant to be used as the GetCertificate
+// callback of a tls.Config, so that renewed certificates are served without a restart.
+func (m *Manager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cert, nil
+}
+
+func (m *Manager) setCert(cert *tls.Certificate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cert = cert
+}
+
+func (m *Manager) renew(ctx context.Context) {
+	t := time.NewTicker(renewCheckInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+
+		if current, _ := m.GetCertificate(nil); !m.needsRenewal(current) {
+			continue
+		}
+		cert, err := m.obtainCert(ctx)
+		if err != nil {
+			log.WithError(err).Error("failed to renew the TLS certificate, retrying later")
+			continue
+		}
+		m.setCert(cert)
+		log.Infof("renewed the TLS certificate, which now expires at %s", cert.Leaf.NotAfter)
+	}
+}
+
+// needsRenewal returns whether the certificate is about to expire or does not cover the
+// configured domains, which may have changed since it was obtained.
+func (m *Manager) needsRenewal(cert *tls.Certificate) bool {
+	if time.Until(cert.Leaf.NotAfter) < time.Duration(m.conf.RenewBefore) {
+		return true
+	}
+	for _, d := range m.conf.Domains {
+		// A wildcard domain is covered if any name it matches is.
+		if cert.Leaf.VerifyHostname(strings.Replace(d, "*", "wildcard", 1)) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// obtainCert orders a certificate for the configured domains, completes a challenge for each of
+// them, and saves the certificate to the cache directory.
+func (m *Manager) obtainCert(ctx context.Context) (*tls.Certificate, error) {
+	ctx, cancel := context.WithTimeout(ctx, obtainTimeout)
+	defer cancel()
+
+	var contact []string
+	if m.conf.Email != "" {
+		contact = []string{"mailto:" + m.conf.Email}
+	}
+	_, err := m.client.Register(ctx, &acme.Account{Contact: contact}, acme.AcceptTOS)
+	if err != nil && err != acme.ErrAccountAlreadyExists {
+		return nil, errors.Wrap(err, "registering the ACME account")
+	}
+
+	order, err := m.client.AuthorizeOrder(ctx, acme.DomainIDs(m.conf.Domains...))
+	if err != nil {
+		return nil, errors.Wrap(err, "ordering the certificate")
+	}
+	for _, url := range order.AuthzURLs {
+		if err := m.authorize(ctx, url); err != nil {
+			return nil, err
+		}
+	}
+	if order, err = m.client.WaitOrder(ctx, order.URI); err != nil {
+		return nil, errors.Wrap(err, "waiting for the order to be ready")
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating the certificate key")
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: m.conf.Domains[0]},
+		DNSNames: m.conf.Domains,
+	}, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the certificate request")
+	}
+	chain, _, err := m.client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "finalizing the order")
+	}
+
+	var certPEM []byte
+	for _, der := range chain {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding the certificate key")
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := m.writeFile(keyFile, keyPEM); err != nil {
+		return nil, err
+	}
+	if err := m.writeFile(certFile, certPEM); err != nil {
+		return nil, err
+	}
+	return parseCert(certPEM, keyPEM)
+}
+
+// authorize completes the configured challenge of one authorization of an order, unless the
+// certificate authority already considers it valid.
+func (m *Manager) authorize(ctx context.Context, url string) error {
+	authz, err := m.client.GetAuthorization(ctx, url)
+	if err != nil {
+		return errors.Wrap(err, "getting the authorization")
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+	domain := authz.Identifier.Value
+
+	var chal *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == m.conf.Challenge {
+			chal = c
+		}
+	}
+	if chal == nil {
+		return errors.Errorf("the certificate authority offered no %s challenge for %s",
+			m.conf.Challenge, domain)
+	}
+
+	switch m.conf.Challenge {
+	case config.ACMEChallengeHTTP01:
+		response, err := m.client.HTTP01ChallengeResponse(chal.Token)
+		if err != nil {
+			return err
+		}
+		path := m.client.HTTP01ChallengePath(chal.Token)
+		m.mu.Lock()
+		m.responses[path] = response
+		m.mu.Unlock()
+		defer func() {
+			m.mu.Lock()
+			delete(m.responses, path)
+			m.mu.Unlock()
+		}()
+	case config.ACMEChallengeDNS01:
+		value, err := m.client.DNS01ChallengeRecord(chal.Token)
+		if err != nil {
+			return err
+		}
+		name := "_acme-challenge." + domain
+		if err := m.runDNSHook(ctx, "present", name, value); err != nil {
+			return err
+		}
+		defer func() {
+			if err := m.runDNSHook(context.Background(), "cleanup", name, value); err != nil {
+				log.WithError(err).Warnf("failed to remove the DNS record %s", name)
+			}
+		}()
+	}
+
+	if _, err := m.client.Accept(ctx, chal); err != nil {
+		return errors.Wrapf(err, "accepting the %s challenge for %s", m.conf.Challenge, domain)
+	}
+	if _, err := m.client.WaitAuthorization(ctx, url); err != nil {
+		return errors.Wrapf(err, "validating %s", domain)
+	}
+	return nil
+}
+
+// runDNSHook runs the DNS hook to publish or remove the TXT record of a DNS-01 challenge. The hook
+// should only return once the record is visible to the certificate authority.
+func (m *Manager) runDNSHook(ctx context.Context, action, name, value string) error {
+	out, err := exec.CommandContext(ctx, m.conf.DNSHook, action, name, value).CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "running the DNS hook to %s %s: %s", action, name, out)
+	}
+	return nil
+}
+
+// serveHTTPChallenges answers HTTP-01 challenges on the configured plain HTTP port until ctx is
+// canceled.
+func (m *Manager) serveHTTPChallenges(ctx context.Context) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", m.conf.HTTPPort))
+	if err != nil {
+		return errors.Wrap(err, "listening for ACME HTTP-01 challenges")
+	}
+	server := &http.Server{
+		Handler:           http.HandlerFunc(m.serveHTTPChallenge),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		if err := server.Close(); err != nil {
+			log.WithError(err).Error("error closing the ACME HTTP-01 server")
+		}
+	}()
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Error("ACME HTTP-01 server failed")
+		}
+	}()
+	return nil
+}
+
+func (m *Manager) serveHTTPChallenge(w http.ResponseWriter, r *http.Request) {
+	m.mu.RLock()
+	response, ok := m.responses[r.URL.Path]
+	m.mu.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	if _, err := w.Write([]byte(response)); err != nil {
+		log.WithError(err).Debug("failed to answer an ACME HTTP-01 challenge")
+	}
+}
+
+func (m *Manager) loadOrCreateAccountKey() (crypto.Signer, error) {
+	path := filepath.Join(m.conf.CacheDir, accountKeyFile)
+	b, err := os.ReadFile(path) //nolint:gosec
+	switch {
+	case err == nil:
+		block, _ := pem.Decode(b)
+		if block == nil {
+			return nil, errors.Errorf("no key found in %s", path)
+		}
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing the ACME account key in %s", path)
+		}
+		return key, nil
+	case !os.IsNotExist(err):
+		return nil, errors.Wrap(err, "reading the ACME account key")
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating the ACME account key")
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding the ACME account key")
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	if err := m.writeFile(accountKeyFile, pemBytes); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (m *Manager) loadCert() (*tls.Certificate, error) {
+	certPEM, err := os.ReadFile(filepath.Join(m.conf.CacheDir, certFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "no cached certificate")
+	}
+	keyPEM, err := os.ReadFile(filepath.Join(m.conf.CacheDir, keyFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "no cached certificate key")
+	}
+	return parseCert(certPEM, keyPEM)
+}
+
+// writeFile replaces a file of the cache directory, so that a crash never leaves it half written.
+func (m *Manager) writeFile(name string, b []byte) error {
+	path := filepath.Join(m.conf.CacheDir, name)
+	if err := os.WriteFile(path+".tmp", b, 0o600); err != nil {
+		return errors.Wrapf(err, "writing %s", path)
+	}
+	return errors.Wrapf(os.Rename(path+".tmp", path), "writing %s", path)
+}
+
+func parseCert(certPEM, keyPEM []byte) (*tls.Certificate, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing the certificate")
+	}
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return nil, errors.Wrap(err, "parsing the certificate")
+	}
+	return &cert, nil
+}
@@ -0,0 +1,524 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/objectstore"
+	"github.com/determined-ai/determined/master/version"
+)
+
+// A backup is a gzipped tarball with a CSV file of the rows of each table, in the order they are
+// restored in, followed by a manifest.
+const manifestName = "manifest.json"
+
+// excludedTables are neither backed up nor restored: they track the migrations and the partitions
+// of the database itself, and backups and restores. The audit log is append-only, so restores
+// must not erase or rewrite it. Restores require maintenance mode and keep it, so that the master
+// comes back from a restore without scheduling anything until an admin checks the restored state.
+var excludedTables = map[string]bool{
+	"gopg_migrations":    true,
+	"schema_migrations":  true,
+	"metrics_partitions": true,
+	"db_backups":         true,
+	"db_restores":        true,
+	"audit_log":          true,
+	"maintenance_mode":   true,
+}
+
+// ErrActive is returned when restoring the database while tasks are running, which would lose
+// track of them.
+var ErrActive = errors.New("the database cannot be restored while tasks are running")
+
+// ErrNotMaintenance is returned when restoring the database outside of maintenance mode, in which
+// new work would be scheduled from the database as it is replaced.
+var ErrNotMaintenance = errors.New("the database can only be restored in maintenance mode")
+
+// ErrRestoring is returned when restoring the database while it is already being restored.
+var ErrRestoring = errors.New("the database is already being restored")
+
+// ErrNotCompleted is returned when restoring the database from a backup that did not complete.
+var ErrNotCompleted = errors.New("only completed backups can be restored")
+
+// ErrTampered is returned when restoring the database from an archive that does not match the
+// digest recorded when the backup was taken.
+var ErrTampered = errors.New("the backup in storage does not match the one that was taken")
+
+type manifest struct {
+	MigrationVersion int64           `json:"migration_version"`
+	MasterVersion    string          `json:"master_version"`
+	CreatedAt        time.Time       `json:"created_at"`
+	Config           json.RawMessage `json:"config"`
+	// Tables are ordered so that each comes after the tables it references.
+	Tables []tableManifest `json:"tables"`
+}
+
+type tableManifest struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Rows    int64    `json:"rows"`
+	SHA256  string   `json:"sha256"`
+}
+
+func (t tableManifest) fileName() string {
+	return "tables/" + t.Name + ".csv"
+}
+
+func (t tableManifest) sanitizedName() string {
+	return pgx.Identifier{t.Name}.Sanitize()
+}
+
+func (t tableManifest) sanitizedColumns() string {
+	columns := make([]string, 0, len(t.Columns))
+	for _, c := range t.Columns {
+		columns = append(columns, pgx.Identifier{c}.Sanitize())
+	}
+	return strings.Join(columns, ", ")
+}
+
+// take writes a backup to storage. Every table is read within one transaction, so the backup is
+// a consistent snapshot of the database even as the master keeps writing to it.
+func take(ctx context.Context, b *Backup, s objectstore.Store, config []byte) error {
+	f, err := os.CreateTemp("", "db-backup-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	digest := sha256.New()
+	gz := gzip.NewWriter(io.MultiWriter(f, digest))
+	tw := tar.NewWriter(gz)
+
+	m := manifest{
+		MigrationVersion: b.MigrationVersion,
+		MasterVersion:    version.Version,
+		CreatedAt:        time.Now(),
+		Config:           config,
+	}
+	if err := withConn(ctx, func(conn *pgconn.PgConn) error {
+		if _, err := exec(ctx, conn, `BEGIN ISOLATION LEVEL REPEATABLE READ READ ONLY;
+SET LOCAL statement_timeout = 0`); err != nil {
+			return err
+		}
+		tables, err := listTables(ctx, conn)
+		if err != nil {
+			return err
+		}
+		b.TablesTotal = len(tables)
+		updateProgress(ctx, b)
+		for i := range tables {
+			t := &tables[i]
+			if err := dump(ctx, conn, tw, t); err != nil {
+				return errors.Wrapf(err, "backing up %s", t.Name)
+			}
+			m.Tables = append(m.Tables, *t)
+			b.TablesDone++
+			b.RowsDone += t.Rows
+			updateProgress(ctx, b)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name: manifestName, Mode: 0o600, Size: int64(len(data)), ModTime: m.CreatedAt,
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	b.SizeBytes = info.Size()
+	b.SHA256 = hex.EncodeToString(digest.Sum(nil))
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return s.Upload(ctx, b.storageKey(), f)
+}
+
+// dump copies the rows of a table into the backup, through a temporary file since the size of
+// each file in a tarball comes before its contents.
+func dump(ctx context.Context, conn *pgconn.PgConn, tw *tar.Writer, t *tableManifest) error {
+	tmp, err := os.CreateTemp("", "db-backup-*.csv")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	h := sha256.New()
+	tag, err := conn.CopyTo(ctx, io.MultiWriter(tmp, h), fmt.Sprintf(
+		"COPY (SELECT %s FROM %s) TO STDOUT WITH (FORMAT csv)",
+		t.sanitizedColumns(), t.sanitizedName()))
+	if err != nil {
+		return err
+	}
+	t.Rows = tag.RowsAffected()
+	t.SHA256 = hex.EncodeToString(h.Sum(nil))
+
+	info, err := tmp.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name: t.fileName(), Mode: 0o600, Size: info.Size(), ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, tmp)
+	return err
+}
+
+// restore downloads a backup and checks that it is complete and matches the schema of the
+// database and then, unless the restore only validates it, replaces the contents of the database
+// with it in one transaction.
+func restore(ctx context.Context, r *Restore, b Backup, s objectstore.Store) error {
+	f, err := os.CreateTemp("", "db-backup-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	if err := s.Download(ctx, b.storageKey(), f); err != nil {
+		return errors.Wrap(err, "downloading backup")
+	}
+	if err := verifyDigest(f, b); err != nil {
+		return err
+	}
+
+	m, err := validate(f)
+	if err != nil {
+		return err
+	}
+	current, err := migrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if m.MigrationVersion != current {
+		return fmt.Errorf("the backup has schema version %d but the database has version %d; "+
+			"restore it with the version of the master that took it", m.MigrationVersion, current)
+	}
+	r.TablesTotal = len(m.Tables)
+	if r.ValidateOnly {
+		r.TablesDone = len(m.Tables)
+		for _, t := range m.Tables {
+			r.RowsDone += t.Rows
+		}
+		return nil
+	}
+	updateProgress(ctx, r)
+
+	return withConn(ctx, func(conn *pgconn.PgConn) error {
+		if _, err := exec(ctx, conn, "BEGIN; SET LOCAL statement_timeout = 0"); err != nil {
+			return err
+		}
+		tables, err := listTables(ctx, conn)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(tables))
+		for _, t := range tables {
+			names = append(names, t.sanitizedName())
+		}
+		if _, err := exec(ctx, conn, "TRUNCATE "+strings.Join(names, ", ")); err != nil {
+			return errors.Wrap(err, "emptying the database")
+		}
+
+		byFile := map[string]tableManifest{}
+		for _, t := range m.Tables {
+			byFile[t.fileName()] = t
+		}
+		if err := readArchive(f, func(name string, contents io.Reader) error {
+			t, ok := byFile[name]
+			if !ok {
+				return nil
+			}
+			tag, err := conn.CopyFrom(ctx, contents, fmt.Sprintf(
+				"COPY %s (%s) FROM STDIN WITH (FORMAT csv)", t.sanitizedName(), t.sanitizedColumns()))
+			if err != nil {
+				return errors.Wrapf(err, "restoring %s", t.Name)
+			}
+			r.TablesDone++
+			r.RowsDone += tag.RowsAffected()
+			updateProgress(ctx, r)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if _, err := exec(ctx, conn, resetSequences); err != nil {
+			return errors.Wrap(err, "resetting sequences")
+		}
+		_, err = exec(ctx, conn, "COMMIT")
+		return err
+	})
+}
+
+// resetSequences sets the sequence of every serial and identity column to continue after the
+// largest restored value.
+const resetSequences = `
+DO $$
+DECLARE
+  r record;
+BEGIN
+  FOR r IN
+    SELECT table_name, column_name,
+      pg_get_serial_sequence(format('%I', table_name), column_name) AS seq
+    FROM information_schema.columns
+    WHERE table_schema = 'public'
+  LOOP
+    IF r.seq IS NOT NULL THEN
+      EXECUTE format('SELECT setval(%L, coalesce(max(%I), 0) + 1, false) FROM %I',
+        r.seq, r.column_name, r.table_name);
+    END IF;
+  END LOOP;
+END $$`
+
+// verifyDigest checks that an archive is the one that was written when the backup was taken. The
+// checksums in the manifest only catch corruption, since whoever changes an archive can change
+// them too.
+func verifyDigest(f *os.File, b Backup) error {
+	if b.SHA256 == "" {
+		return errors.Wrapf(ErrTampered, "backup %d has no recorded digest to verify it with", b.ID)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if hex.EncodeToString(h.Sum(nil)) != b.SHA256 {
+		return errors.Wrapf(ErrTampered, "backup %d", b.ID)
+	}
+	return nil
+}
+
+// validate checks that a backup has a manifest and that every table in it is intact, and returns
+// the manifest.
+func validate(f *os.File) (*manifest, error) {
+	var m *manifest
+	sums := map[string]string{}
+	if err := readArchive(f, func(name string, contents io.Reader) error {
+		if name == manifestName {
+			m = &manifest{}
+			return json.NewDecoder(contents).Decode(m)
+		}
+		h := sha256.New()
+		if _, err := io.Copy(h, contents); err != nil {
+			return err
+		}
+		sums[name] = hex.EncodeToString(h.Sum(nil))
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "reading backup")
+	}
+	if m == nil {
+		return nil, errors.New("the backup has no manifest")
+	}
+	for _, t := range m.Tables {
+		sum, ok := sums[t.fileName()]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("the backup is missing table %s", t.Name)
+		case sum != t.SHA256:
+			return nil, fmt.Errorf("the backup of table %s is corrupt", t.Name)
+		}
+		delete(sums, t.fileName())
+	}
+	if len(sums) > 0 {
+		var extra []string
+		for name := range sums {
+			extra = append(extra, name)
+		}
+		sort.Strings(extra)
+		return nil, fmt.Errorf("the backup has files that are not in its manifest: %s",
+			strings.Join(extra, ", "))
+	}
+	return m, nil
+}
+
+// readArchive calls fn with the name and contents of each file of a backup in order.
+func readArchive(f *os.File, fn func(name string, contents io.Reader) error) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = gz.Close() }()
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(h.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// listTables returns the tables of the database to back up or restore, with their columns, in
+// the order they are restored in.
+func listTables(ctx context.Context, conn *pgconn.PgConn) ([]tableManifest, error) {
+	rows, err := exec(ctx, conn, `
+SELECT c.relname, a.attname
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+JOIN pg_attribute a ON a.attrelid = c.oid
+WHERE n.nspname = 'public'
+  AND c.relkind IN ('r', 'p')
+  AND NOT c.relispartition
+  AND a.attnum > 0
+  AND NOT a.attisdropped
+ORDER BY c.relname, a.attnum`)
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string][]string{}
+	var names []string
+	for _, row := range rows {
+		name := string(row[0])
+		if excludedTables[name] {
+			continue
+		}
+		if _, ok := columns[name]; !ok {
+			names = append(names, name)
+		}
+		columns[name] = append(columns[name], string(row[1]))
+	}
+
+	rows, err = exec(ctx, conn, `
+SELECT conrelid::regclass::text, confrelid::regclass::text
+FROM pg_constraint
+WHERE contype = 'f'`)
+	if err != nil {
+		return nil, err
+	}
+	references := map[string][]string{}
+	for _, row := range rows {
+		references[string(row[0])] = append(references[string(row[0])], string(row[1]))
+	}
+
+	var tables []tableManifest
+	for _, name := range sortByReferences(names, references) {
+		tables = append(tables, tableManifest{Name: name, Columns: columns[name]})
+	}
+	return tables, nil
+}
+
+// sortByReferences orders tables so that each comes after the tables it references, and
+// otherwise by name. Tables in a cycle of references come last, by name.
+func sortByReferences(names []string, references map[string][]string) []string {
+	remaining := map[string]bool{}
+	for _, name := range names {
+		remaining[name] = true
+	}
+	var sorted []string
+	for len(remaining) > 0 {
+		var ready []string
+		for name := range remaining {
+			blocked := false
+			for _, ref := range references[name] {
+				if ref != name && remaining[ref] {
+					blocked = true
+					break
+				}
+			}
+			if !blocked {
+				ready = append(ready, name)
+			}
+		}
+		if len(ready) == 0 {
+			for name := range remaining {
+				ready = append(ready, name)
+			}
+		}
+		sort.Strings(ready)
+		for _, name := range ready {
+			delete(remaining, name)
+		}
+		sorted = append(sorted, ready...)
+	}
+	return sorted
+}
+
+// withConn runs f on a connection to the database of its own, and rolls back any transaction
+// that f leaves open before returning the connection to the pool.
+func withConn(ctx context.Context, f func(*pgconn.PgConn) error) error {
+	conn, err := db.Bun().Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	return conn.Raw(func(driverConn interface{}) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected database connection %T", driverConn)
+		}
+		pgConn := c.Conn().PgConn()
+		defer func() { _, _ = exec(context.Background(), pgConn, "ROLLBACK") }()
+		return f(pgConn)
+	})
+}
+
+// exec runs SQL without parameters and returns the rows of its results as text.
+func exec(ctx context.Context, conn *pgconn.PgConn, sql string) ([][][]byte, error) {
+	results, err := conn.Exec(ctx, sql).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var rows [][][]byte
+	for _, r := range results {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		rows = append(rows, r.Rows...)
+	}
+	return rows, nil
+}
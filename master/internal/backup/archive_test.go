@@ -0,0 +1,21 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortByReferences(t *testing.T) {
+	names := []string{"checkpoints", "experiments", "trials", "users", "a", "b"}
+	references := map[string][]string{
+		"checkpoints": {"trials"},
+		"experiments": {"users", "experiments"},
+		"trials":      {"experiments"},
+		// a and b reference each other, so neither can come first.
+		"a": {"b"},
+		"b": {"a"},
+	}
+	require.Equal(t, []string{"users", "experiments", "trials", "checkpoints", "a", "b"},
+		sortByReferences(names, references))
+}
@@ -0,0 +1,313 @@
+// Package backup takes consistent logical backups of the database of the master, along with its
+// configuration, to the backup storage configured for the master, and validates backups and
+// restores the database from them, so that recovering from a disaster does not depend on ad-hoc
+// pg_dump scripts.
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/maintenance"
+	"github.com/determined-ai/determined/master/internal/objectstore"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+	"github.com/determined-ai/determined/proto/pkg/backupv1"
+)
+
+// State is the state of a backup or restore.
+type State string
+
+// The states of a backup or restore.
+const (
+	StateRunning   State = "RUNNING"
+	StateCompleted State = "COMPLETED"
+	StateFailed    State = "FAILED"
+)
+
+func (s State) proto() backupv1.State {
+	return backupv1.State(backupv1.State_value["STATE_"+string(s)])
+}
+
+// Progress is how far a backup or restore has gotten.
+type Progress struct {
+	TablesDone  int   `bun:"tables_done"`
+	TablesTotal int   `bun:"tables_total"`
+	RowsDone    int64 `bun:"rows_done"`
+}
+
+// Backup corresponds to a row in the "db_backups" DB table.
+type Backup struct {
+	bun.BaseModel `bun:"table:db_backups,alias:b"`
+
+	ID      int                             `bun:"id,pk,autoincrement"`
+	State   State                           `bun:"state"`
+	Storage expconf.CheckpointStorageConfig `bun:"storage,type:jsonb"`
+	// MigrationVersion is the version of the schema of the database that was backed up, which
+	// is the only version it can be restored to.
+	MigrationVersion int64 `bun:"migration_version"`
+	Progress
+	SizeBytes int64 `bun:"size_bytes"`
+	// SHA256 is the digest of the archive. It is kept in the database rather than in storage, so
+	// that restores can tell whether the archive was changed by anyone who can write to storage.
+	SHA256    string     `bun:"sha256,nullzero"`
+	StartedAt time.Time  `bun:"started_at,nullzero,notnull,default:current_timestamp"`
+	EndedAt   *time.Time `bun:"ended_at"`
+	Error     string     `bun:"error,nullzero"`
+}
+
+// storageKey returns where the backup is stored.
+func (b Backup) storageKey() string {
+	return fmt.Sprintf("db-backups/backup-%d.tar.gz", b.ID)
+}
+
+// Proto returns the protobuf representation of the backup.
+func (b Backup) Proto() *backupv1.DatabaseBackup {
+	pb := &backupv1.DatabaseBackup{
+		Id:               int32(b.ID),
+		State:            b.State.proto(),
+		MigrationVersion: b.MigrationVersion,
+		TablesDone:       int32(b.TablesDone),
+		TablesTotal:      int32(b.TablesTotal),
+		RowsDone:         b.RowsDone,
+		SizeBytes:        b.SizeBytes,
+		StartedAt:        timestamppb.New(b.StartedAt),
+		Error:            b.Error,
+	}
+	if b.EndedAt != nil {
+		pb.EndedAt = timestamppb.New(*b.EndedAt)
+	}
+	return pb
+}
+
+// Restore corresponds to a row in the "db_restores" DB table.
+type Restore struct {
+	bun.BaseModel `bun:"table:db_restores,alias:r"`
+
+	ID       int `bun:"id,pk,autoincrement"`
+	BackupID int `bun:"backup_id"`
+	// ValidateOnly is whether the backup is only checked to be complete and restorable, without
+	// touching the database.
+	ValidateOnly bool  `bun:"validate_only"`
+	State        State `bun:"state"`
+	Progress
+	StartedAt time.Time  `bun:"started_at,nullzero,notnull,default:current_timestamp"`
+	EndedAt   *time.Time `bun:"ended_at"`
+	Error     string     `bun:"error,nullzero"`
+}
+
+// Proto returns the protobuf representation of the restore.
+func (r Restore) Proto() *backupv1.DatabaseRestore {
+	pr := &backupv1.DatabaseRestore{
+		Id:           int32(r.ID),
+		BackupId:     int32(r.BackupID),
+		ValidateOnly: r.ValidateOnly,
+		State:        r.State.proto(),
+		TablesDone:   int32(r.TablesDone),
+		TablesTotal:  int32(r.TablesTotal),
+		RowsDone:     r.RowsDone,
+		StartedAt:    timestamppb.New(r.StartedAt),
+		Error:        r.Error,
+	}
+	if r.EndedAt != nil {
+		pr.EndedAt = timestamppb.New(*r.EndedAt)
+	}
+	return pr
+}
+
+// migrationVersion returns the version of the schema of the database.
+func migrationVersion(ctx context.Context) (int64, error) {
+	var version int64
+	err := db.Bun().NewRaw("SELECT version FROM gopg_migrations ORDER BY id DESC LIMIT 1").
+		Scan(ctx, &version)
+	return version, err
+}
+
+// Start starts a backup of the database and the configuration of the master to storage in the
+// background, and returns it as it starts. config is the printable configuration of the master.
+func Start(
+	ctx context.Context, storage expconf.CheckpointStorageConfig, config []byte,
+) (*Backup, error) {
+	s, err := objectstore.New(ctx, storage)
+	if err != nil {
+		return nil, err
+	}
+	version, err := migrationVersion(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the migration version")
+	}
+	b := &Backup{State: StateRunning, Storage: storage, MigrationVersion: version}
+	if _, err := db.Bun().NewInsert().Model(b).Returning("*").Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	started := *b
+	go func() {
+		ctx := context.Background()
+		err := take(ctx, b, s, config)
+		b.State, b.EndedAt = StateCompleted, ptrs.Ptr(time.Now())
+		if err != nil {
+			log.WithError(err).Errorf("database backup %d failed", b.ID)
+			b.State, b.Error = StateFailed, err.Error()
+		} else {
+			log.Infof("database backup %d completed", b.ID)
+		}
+		if _, err := db.Bun().NewUpdate().Model(b).WherePK().Exec(ctx); err != nil {
+			log.WithError(err).Errorf("failed to record the end of database backup %d", b.ID)
+		}
+	}()
+	return &started, nil
+}
+
+// restoring is 1 while the database is being restored from a backup.
+var restoring int32
+
+// Restoring returns whether the database is being restored from a backup. Maintenance mode stays
+// enabled while it is.
+func Restoring() bool {
+	return atomic.LoadInt32(&restoring) == 1
+}
+
+// StartRestore starts validating a completed backup and, unless validateOnly is set, restoring
+// the database from it in the background, and returns the restore as it starts. Restoring the
+// database requires maintenance mode, which is kept until the master stops. What the master holds
+// in memory is out of date once the database is restored, so exit is then called to stop it.
+func StartRestore(
+	ctx context.Context, backupID int, validateOnly bool, exit func(),
+) (_ *Restore, err error) {
+	b, err := GetBackup(ctx, backupID)
+	if err != nil {
+		return nil, err
+	}
+	if b.State != StateCompleted {
+		return nil, errors.Wrapf(ErrNotCompleted, "backup %d is %s", b.ID, b.State)
+	}
+	if !validateOnly {
+		if !atomic.CompareAndSwapInt32(&restoring, 0, 1) {
+			return nil, ErrRestoring
+		}
+		defer func() {
+			if err != nil {
+				atomic.StoreInt32(&restoring, 0)
+			}
+		}()
+		if err := checkRestorable(ctx); err != nil {
+			return nil, err
+		}
+	}
+	s, err := objectstore.New(ctx, b.Storage)
+	if err != nil {
+		return nil, err
+	}
+	r := &Restore{BackupID: b.ID, ValidateOnly: validateOnly, State: StateRunning}
+	if _, err := db.Bun().NewInsert().Model(r).Returning("*").Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	started := *r
+	go func() {
+		ctx := context.Background()
+		err := restore(ctx, r, *b, s)
+		r.State, r.EndedAt = StateCompleted, ptrs.Ptr(time.Now())
+		switch {
+		case err != nil:
+			log.WithError(err).Errorf("database restore %d from backup %d failed", r.ID, b.ID)
+			r.State, r.Error = StateFailed, err.Error()
+		case validateOnly:
+			log.Infof("validated database backup %d", b.ID)
+		default:
+			log.Warnf("restored the database from backup %d", b.ID)
+		}
+		if _, err := db.Bun().NewUpdate().Model(r).WherePK().Exec(ctx); err != nil {
+			log.WithError(err).Errorf("failed to record the end of database restore %d", r.ID)
+		}
+		if validateOnly {
+			return
+		}
+		if err == nil {
+			exit()
+		}
+		atomic.StoreInt32(&restoring, 0)
+	}()
+	return &started, nil
+}
+
+// checkRestorable checks that the database can be restored: the cluster must be in maintenance
+// mode, so that nothing new is scheduled, and no tasks may be running, since the master would lose
+// track of them.
+func checkRestorable(ctx context.Context) error {
+	if !maintenance.Enabled() {
+		return ErrNotMaintenance
+	}
+	active, err := db.Bun().NewSelect().Table("allocations").
+		Where("end_time IS NULL").
+		Exists(ctx)
+	if err != nil {
+		return err
+	}
+	if active {
+		return ErrActive
+	}
+	return nil
+}
+
+// GetBackup returns a backup by ID.
+func GetBackup(ctx context.Context, id int) (*Backup, error) {
+	var b Backup
+	if err := db.Bun().NewSelect().Model(&b).Where("id = ?", id).Scan(ctx); err != nil {
+		return nil, db.MatchSentinelError(err)
+	}
+	return &b, nil
+}
+
+// ListBackups returns every backup, most recent first.
+func ListBackups(ctx context.Context) ([]Backup, error) {
+	backups := []Backup{}
+	if err := db.Bun().NewSelect().Model(&backups).Order("id DESC").Scan(ctx); err != nil {
+		return nil, err
+	}
+	return backups, nil
+}
+
+// GetRestore returns a restore by ID.
+func GetRestore(ctx context.Context, id int) (*Restore, error) {
+	var r Restore
+	if err := db.Bun().NewSelect().Model(&r).Where("id = ?", id).Scan(ctx); err != nil {
+		return nil, db.MatchSentinelError(err)
+	}
+	return &r, nil
+}
+
+// FailInterrupted marks the backups and restores that were running when the master last stopped
+// as failed, since nothing will finish them.
+func FailInterrupted(ctx context.Context) error {
+	for _, m := range []interface{}{(*Backup)(nil), (*Restore)(nil)} {
+		if _, err := db.Bun().NewUpdate().Model(m).
+			Set("state = ?", StateFailed).
+			Set("ended_at = now()").
+			Set("error = ?", "interrupted by a restart of the master").
+			Where("state = ?", StateRunning).
+			Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateProgress records how far a backup or restore has gotten.
+func updateProgress(ctx context.Context, model interface{}) {
+	if _, err := db.Bun().NewUpdate().Model(model).
+		Column("tables_done", "tables_total", "rows_done").
+		WherePK().
+		Exec(ctx); err != nil {
+		log.WithError(err).Warn("failed to record the progress of a database backup or restore")
+	}
+}
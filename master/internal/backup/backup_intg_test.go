@@ -0,0 +1,156 @@
+//go:build integration
+// +build integration
+
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/maintenance"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+)
+
+// setupIsolatedPostgres points the database of the package at a new, migrated database that is
+// dropped after the test, since restores replace the contents of the whole database.
+func setupIsolatedPostgres(t *testing.T) {
+	u, err := url.Parse(os.Getenv("DET_INTEGRATION_POSTGRES_URL"))
+	require.NoError(t, err)
+	admin, err := sql.Open("pgx", u.String())
+	require.NoError(t, err)
+	name := "backup_" + uuid.New().String()[:8]
+	_, err = admin.Exec(fmt.Sprintf("CREATE DATABASE %s", name))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := admin.Exec(fmt.Sprintf("DROP DATABASE %s", name))
+		require.NoError(t, err)
+		require.NoError(t, admin.Close())
+	})
+
+	u.Path = "/" + name
+	pgDB, err := db.ConnectPostgres(u.String())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, pgDB.Close()) })
+	db.MustMigrateTestPostgres(t, pgDB, "file://../../static/migrations")
+}
+
+func waitForEnd(t *testing.T, get func() (State, error)) {
+	require.Eventually(t, func() bool {
+		state, err := get()
+		require.NoError(t, err)
+		return state != StateRunning
+	}, 30*time.Second, 100*time.Millisecond)
+}
+
+func takeBackup(
+	ctx context.Context, t *testing.T, storage expconf.CheckpointStorageConfig,
+) *Backup {
+	b, err := Start(ctx, storage, []byte("{}"))
+	require.NoError(t, err)
+	waitForEnd(t, func() (State, error) {
+		b, err = GetBackup(ctx, b.ID)
+		if err != nil {
+			return "", err
+		}
+		return b.State, nil
+	})
+	require.Equal(t, StateCompleted, b.State, b.Error)
+	require.NotEmpty(t, b.SHA256)
+	return b
+}
+
+// restoreBackup restores a backup and returns the restore once it ends, and whether the master was
+// told to exit after it.
+func restoreBackup(
+	ctx context.Context, t *testing.T, b *Backup, validateOnly bool,
+) (*Restore, bool) {
+	exited := make(chan struct{}, 1)
+	r, err := StartRestore(ctx, b.ID, validateOnly, func() { exited <- struct{}{} })
+	require.NoError(t, err)
+	waitForEnd(t, func() (State, error) {
+		r, err = GetRestore(ctx, r.ID)
+		if err != nil {
+			return "", err
+		}
+		return r.State, nil
+	})
+	// The restore is recorded as ended just before the master is told to exit.
+	require.Eventually(t, func() bool { return !Restoring() }, 5*time.Second, 10*time.Millisecond)
+	select {
+	case <-exited:
+		return r, true
+	default:
+		return r, false
+	}
+}
+
+func TestBackupRestore(t *testing.T) {
+	setupIsolatedPostgres(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	storage := expconf.CheckpointStorageConfig{
+		RawSharedFSConfig: &expconf.SharedFSConfig{RawHostPath: ptrs.Ptr(dir)},
+	}
+
+	_, err := db.Bun().ExecContext(ctx,
+		`INSERT INTO templates (name, config) VALUES ('backed-up', '{}')`)
+	require.NoError(t, err)
+	b := takeBackup(ctx, t, storage)
+
+	// Restoring brings back what was backed up but leaves the audit log alone.
+	_, err = db.Bun().ExecContext(ctx, `DELETE FROM templates WHERE name = 'backed-up'`)
+	require.NoError(t, err)
+	_, err = db.Bun().ExecContext(ctx,
+		`INSERT INTO audit_log (action, success) VALUES ('after backup', true)`)
+	require.NoError(t, err)
+
+	// Restores require maintenance mode, which they keep, and stop the master once they complete.
+	_, err = StartRestore(ctx, b.ID, false, func() {})
+	require.ErrorIs(t, err, ErrNotMaintenance)
+	require.NoError(t, maintenance.Set(ctx, &maintenance.Mode{Enabled: true}))
+	t.Cleanup(func() { require.NoError(t, maintenance.Set(ctx, &maintenance.Mode{})) })
+
+	r, exited := restoreBackup(ctx, t, b, false)
+	require.Equal(t, StateCompleted, r.State, r.Error)
+	require.True(t, exited)
+	m, err := maintenance.Get(ctx)
+	require.NoError(t, err)
+	require.True(t, m.Enabled)
+	exists, err := db.Bun().NewSelect().Table("templates").Where("name = 'backed-up'").Exists(ctx)
+	require.NoError(t, err)
+	require.True(t, exists)
+	exists, err = db.Bun().NewSelect().Table("audit_log").Where("action = 'after backup'").
+		Exists(ctx)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	// An archive that was swapped in storage is rejected, even when it is an intact backup.
+	other := takeBackup(ctx, t, storage)
+	contents, err := os.ReadFile(filepath.Join(dir, other.storageKey()))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, b.storageKey()), contents, 0o600))
+	for _, validateOnly := range []bool{true, false} {
+		r, exited = restoreBackup(ctx, t, b, validateOnly)
+		require.Equal(t, StateFailed, r.State)
+		require.False(t, exited)
+		require.Contains(t, r.Error, ErrTampered.Error())
+	}
+
+	// Backups without a recorded digest cannot be verified, so they are rejected too.
+	_, err = db.Bun().NewUpdate().Model(other).Set("sha256 = NULL").WherePK().Exec(ctx)
+	require.NoError(t, err)
+	r, _ = restoreBackup(ctx, t, other, true)
+	require.Equal(t, StateFailed, r.State)
+	require.Contains(t, r.Error, "no recorded digest")
+}
@@ -0,0 +1,95 @@
+// Package certwatch reloads the TLS certificate of the master, and the CA certificates it
+// verifies agents with, when their files change, so that short-lived certificates, such as those
+// issued by cert-manager or Vault, are rotated without restarting the master. Files are polled
+// rather than watched for events, since Kubernetes updates mounted secrets by swapping symlinks.
+package certwatch
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// pollInterval is how often the files are checked for changes.
+const pollInterval = 10 * time.Second
+
+// Watcher holds the certificate read from a pair of certificate and key files.
+type Watcher struct {
+	certFile, keyFile string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+	// certPEM and keyPEM are the contents of the files as last read, to tell when they change.
+	certPEM, keyPEM []byte
+}
+
+// New returns a watcher for the given files, serving the given certificate until they change.
+func New(certFile, keyFile string, cert *tls.Certificate) *Watcher {
+	w := &Watcher{certFile: certFile, keyFile: keyFile, cert: cert}
+	if certPEM, keyPEM, err := w.read(); err == nil {
+		w.certPEM, w.keyPEM = certPEM, keyPEM
+	}
+	return w
+}
+
+// Run reloads the certificate whenever the files change, until ctx is canceled.
+func (w *Watcher) Run(ctx context.Context) {
+	t := time.NewTicker(pollInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+		w.reload()
+	}
+}
+
+// GetCertificate returns the current certificate; it is meant to be used as the GetCertificate
+// callback of a tls.Config.
+func (w *Watcher) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.cert, nil
+}
+
+func (w *Watcher) reload() {
+	certPEM, keyPEM, err := w.read()
+	if err != nil {
+		log.WithError(err).Warn("failed to read the TLS certificate, serving the previous one")
+		return
+	}
+	if bytes.Equal(certPEM, w.certPEM) && bytes.Equal(keyPEM, w.keyPEM) {
+		return
+	}
+	// Writers may update the files one at a time, so a certificate that does not match its key is
+	// tried again at the next poll.
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		log.WithError(err).Warn("failed to load the new TLS certificate, serving the previous one")
+		return
+	}
+	w.certPEM, w.keyPEM = certPEM, keyPEM
+
+	w.mu.Lock()
+	w.cert = &cert
+	w.mu.Unlock()
+	log.Infof("reloaded the TLS certificate from %s", w.certFile)
+}
+
+func (w *Watcher) read() (certPEM, keyPEM []byte, err error) {
+	if certPEM, err = os.ReadFile(w.certFile); err != nil {
+		return nil, nil, errors.Wrap(err, "reading the certificate file")
+	}
+	if keyPEM, err = os.ReadFile(w.keyFile); err != nil {
+		return nil, nil, errors.Wrap(err, "reading the key file")
+	}
+	return certPEM, keyPEM, nil
+}
@@ -0,0 +1,99 @@
+package certwatch
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func selfSigned(t *testing.T, name string) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}, &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+	}, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	write := func(certPEM, keyPEM []byte) {
+		require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+		require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+	}
+	served := func(w *Watcher) string {
+		cert, err := w.GetCertificate(nil)
+		require.NoError(t, err)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		return leaf.Subject.CommonName
+	}
+
+	oldCert, oldKey := selfSigned(t, "old")
+	write(oldCert, oldKey)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+	w := New(certFile, keyFile, &cert)
+	w.reload()
+	require.Equal(t, "old", served(w))
+
+	// A certificate whose key has yet to be written is not served.
+	newCert, newKey := selfSigned(t, "new")
+	write(newCert, oldKey)
+	w.reload()
+	require.Equal(t, "old", served(w))
+
+	write(newCert, newKey)
+	w.reload()
+	require.Equal(t, "new", served(w))
+
+	require.NoError(t, os.Remove(keyFile))
+	w.reload()
+	require.Equal(t, "new", served(w))
+}
+
+func TestPoolReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	oldCA, _ := selfSigned(t, "old")
+	require.NoError(t, os.WriteFile(file, oldCA, 0o600))
+	w, err := NewPool(file)
+	require.NoError(t, err)
+	subjects := func() int {
+		//nolint:staticcheck // Subjects is only deprecated for system pools.
+		return len(w.Pool().Subjects())
+	}
+	require.Equal(t, 1, subjects())
+
+	// A file that is being rewritten is not loaded.
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	w.reload()
+	require.Equal(t, 1, subjects())
+
+	newCA, _ := selfSigned(t, "new")
+	require.NoError(t, os.WriteFile(file, append(oldCA, newCA...), 0o600))
+	w.reload()
+	require.Equal(t, 2, subjects())
+}
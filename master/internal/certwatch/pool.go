@@ -0,0 +1,89 @@
+package certwatch
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// PoolWatcher holds the pool of CA certificates read from a file, such as the CA that agent
+// certificates are issued by.
+type PoolWatcher struct {
+	file string
+
+	mu   sync.RWMutex
+	pool *x509.CertPool
+	// pem is the contents of the file as last read, to tell when it changes.
+	pem []byte
+}
+
+// NewPool reads the CA certificates in the given file and returns a watcher for it.
+func NewPool(file string) (*PoolWatcher, error) {
+	w := &PoolWatcher{file: file}
+	pemCerts, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the CA file")
+	}
+	if w.pool, err = parsePool(pemCerts); err != nil {
+		return nil, err
+	}
+	w.pem = pemCerts
+	return w, nil
+}
+
+// Run reloads the CA certificates whenever the file changes, until ctx is canceled.
+func (w *PoolWatcher) Run(ctx context.Context) {
+	t := time.NewTicker(pollInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+		w.reload()
+	}
+}
+
+// Pool returns the current pool of CA certificates.
+func (w *PoolWatcher) Pool() *x509.CertPool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.pool
+}
+
+func (w *PoolWatcher) reload() {
+	pemCerts, err := os.ReadFile(w.file)
+	if err != nil {
+		log.WithError(err).Warn("failed to read the CA file, using the previous CA certificates")
+		return
+	}
+	if bytes.Equal(pemCerts, w.pem) {
+		return
+	}
+	pool, err := parsePool(pemCerts)
+	if err != nil {
+		log.WithError(err).Warn("failed to load the new CA certificates, using the previous ones")
+		return
+	}
+	w.pem = pemCerts
+
+	w.mu.Lock()
+	w.pool = pool
+	w.mu.Unlock()
+	log.Infof("reloaded the CA certificates from %s", w.file)
+}
+
+func parsePool(pemCerts []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemCerts) {
+		return nil, errors.New("no certificates found in the CA file")
+	}
+	return pool, nil
+}
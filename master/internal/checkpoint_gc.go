@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -133,6 +134,10 @@ func (t *checkpointGCTask) Receive(ctx *actor.Context) error {
 			ctx.Log().WithError(err).Error("updating checkpoints to delete state in checkpoint GC Task")
 			return err
 		}
+		if err := t.db.UpdateExperimentBestCheckpoint(
+			context.TODO(), t.ExperimentID); err != nil {
+			ctx.Log().WithError(err).Error("updating best checkpoint after checkpoint GC")
+		}
 
 		t.completeTask(ctx)
 	case actor.ChildStopped:
@@ -0,0 +1,95 @@
+// Package cloudwatch stores task logs in AWS CloudWatch Logs, with a log stream for each task in
+// a single log group.
+package cloudwatch
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// CloudWatch is a task log backend around a CloudWatch Logs log group.
+type CloudWatch struct {
+	client   cloudwatchlogsiface.CloudWatchLogsAPI
+	logGroup string
+
+	mu sync.Mutex
+	// streams holds the streams known to exist, with the sequence token to write to them next,
+	// which is nil for a new stream.
+	streams map[string]*string
+}
+
+// Setup creates a CloudWatch log backend with the given configuration, creating its log group if
+// it does not exist.
+func Setup(conf model.CloudWatchLoggingConfig) (*CloudWatch, error) {
+	awsConfig := aws.Config{}
+	if conf.Region != "" {
+		awsConfig.Region = aws.String(conf.Region)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            awsConfig,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create AWS session")
+	}
+	if conf.RoleARN != nil {
+		awsConfig.Credentials = stscreds.NewCredentials(sess, *conf.RoleARN)
+	}
+
+	cw := newCloudWatch(cloudwatchlogs.New(sess, &awsConfig), conf.LogGroup)
+	if err := cw.ensureLogGroup(); err != nil {
+		return nil, err
+	}
+	log.Infof("writing task logs to CloudWatch log group %s", conf.LogGroup)
+	return cw, nil
+}
+
+func newCloudWatch(client cloudwatchlogsiface.CloudWatchLogsAPI, logGroup string) *CloudWatch {
+	return &CloudWatch{
+		client:   client,
+		logGroup: logGroup,
+		streams:  map[string]*string{},
+	}
+}
+
+// Ping returns an error if the log group cannot be reached.
+func (c *CloudWatch) Ping(ctx context.Context) error {
+	_, err := c.client.DescribeLogGroupsWithContext(ctx, &cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(c.logGroup),
+		Limit:              aws.Int64(1),
+	})
+	return err
+}
+
+func (c *CloudWatch) ensureLogGroup() error {
+	out, err := c.client.DescribeLogGroups(&cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(c.logGroup),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to describe log group %s", c.logGroup)
+	}
+	for _, g := range out.LogGroups {
+		if aws.StringValue(g.LogGroupName) == c.logGroup {
+			return nil
+		}
+	}
+
+	_, err = c.client.CreateLogGroup(&cloudwatchlogs.CreateLogGroupInput{
+		LogGroupName: aws.String(c.logGroup),
+	})
+	var exists *cloudwatchlogs.ResourceAlreadyExistsException
+	if err != nil && !errors.As(err, &exists) {
+		return errors.Wrapf(err, "failed to create log group %s", c.logGroup)
+	}
+	return nil
+}
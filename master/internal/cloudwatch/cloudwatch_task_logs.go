@@ -0,0 +1,446 @@
+package cloudwatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/determined-ai/determined/master/internal/api"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+const (
+	// ingestionDelay is the time buffer to allow logs to become readable before we try to serve
+	// them up, so that following a stream does not skip over logs that show up late.
+	ingestionDelay = 5 * time.Second
+
+	// The limits on a single PutLogEvents call.
+	maxBatchEvents = 10000
+	maxBatchBytes  = 1048576
+	maxBatchSpan   = 24 * time.Hour
+	// eventOverheadBytes is what CloudWatch adds to the size of each message to size a batch.
+	eventOverheadBytes = 26
+	// maxLogBytes leaves room in the 256KiB CloudWatch allows for an event for the other fields
+	// of the log.
+	maxLogBytes = 200 * 1024
+)
+
+// cloudWatchFollowState is the position in a stream to continue reading from. CloudWatch orders
+// events by their timestamp only, so it holds the IDs of the logs already read at that time.
+type cloudWatchFollowState struct {
+	timestamp int64
+	seen      map[string]bool
+}
+
+func (s *cloudWatchFollowState) advance(timestamp int64, id string) {
+	if timestamp != s.timestamp || s.seen == nil {
+		s.timestamp = timestamp
+		s.seen = map[string]bool{}
+	}
+	s.seen[id] = true
+}
+
+// AddTaskLogs writes a batch of task logs to the streams of their tasks.
+func (c *CloudWatch) AddTaskLogs(logs []*model.TaskLog) error {
+	taskToEvents := map[string][]*cloudwatchlogs.InputLogEvent{}
+	for _, l := range logs {
+		l := *l
+		if l.Timestamp == nil {
+			now := time.Now().UTC()
+			l.Timestamp = &now
+		}
+		if len(l.Log) > maxLogBytes {
+			l.Log = l.Log[:maxLogBytes]
+		}
+		message, err := json.Marshal(l)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode task log")
+		}
+		taskToEvents[l.TaskID] = append(taskToEvents[l.TaskID], &cloudwatchlogs.InputLogEvent{
+			Message:   aws.String(string(message)),
+			Timestamp: aws.Int64(l.Timestamp.UnixMilli()),
+		})
+	}
+
+	for taskID, events := range taskToEvents {
+		// CloudWatch requires the events of a batch to be in order.
+		sort.SliceStable(events, func(i, j int) bool {
+			return *events[i].Timestamp < *events[j].Timestamp
+		})
+		for len(events) > 0 {
+			n := batchSize(events)
+			if err := c.putLogEvents(taskID, events[:n]); err != nil {
+				return err
+			}
+			events = events[n:]
+		}
+	}
+	return nil
+}
+
+// batchSize returns how many of the events fit in a single PutLogEvents call.
+func batchSize(events []*cloudwatchlogs.InputLogEvent) int {
+	var n, bytes int
+	for ; n < len(events) && n < maxBatchEvents; n++ {
+		bytes += len(*events[n].Message) + eventOverheadBytes
+		span := time.Duration(*events[n].Timestamp-*events[0].Timestamp) * time.Millisecond
+		if n > 0 && (bytes > maxBatchBytes || span > maxBatchSpan) {
+			break
+		}
+	}
+	return n
+}
+
+func (c *CloudWatch) putLogEvents(stream string, events []*cloudwatchlogs.InputLogEvent) error {
+	// Writes to a stream must be serialized, since each one needs the sequence token returned by
+	// the last.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	token, ok := c.streams[stream]
+	if !ok {
+		_, err := c.client.CreateLogStream(&cloudwatchlogs.CreateLogStreamInput{
+			LogGroupName:  aws.String(c.logGroup),
+			LogStreamName: aws.String(stream),
+		})
+		var exists *cloudwatchlogs.ResourceAlreadyExistsException
+		if err != nil && !errors.As(err, &exists) {
+			return errors.Wrapf(err, "failed to create log stream for task %s", stream)
+		}
+	}
+
+	for retried := false; ; retried = true {
+		out, err := c.client.PutLogEvents(&cloudwatchlogs.PutLogEventsInput{
+			LogGroupName:  aws.String(c.logGroup),
+			LogStreamName: aws.String(stream),
+			LogEvents:     events,
+			SequenceToken: token,
+		})
+		var invalidToken *cloudwatchlogs.InvalidSequenceTokenException
+		var alreadyAccepted *cloudwatchlogs.DataAlreadyAcceptedException
+		switch {
+		case errors.As(err, &invalidToken) && !retried:
+			// Another writer, such as a previous master, wrote to the stream since we last did.
+			token = invalidToken.ExpectedSequenceToken
+			continue
+		case errors.As(err, &alreadyAccepted):
+			c.streams[stream] = alreadyAccepted.ExpectedSequenceToken
+			return nil
+		case err != nil:
+			return errors.Wrapf(err, "failed to write logs for task %s", stream)
+		}
+
+		if out.RejectedLogEventsInfo != nil {
+			log.Warnf("CloudWatch rejected logs of task %s for being too old or new: %s",
+				stream, out.RejectedLogEventsInfo)
+		}
+		c.streams[stream] = out.NextSequenceToken
+		return nil
+	}
+}
+
+// TaskLogsCount returns an upper bound on the number of logs for the given task. CloudWatch
+// cannot count events without reading them all, so readers stop when a read comes back empty.
+func (c *CloudWatch) TaskLogsCount(taskID model.TaskID, fs []api.Filter) (int, error) {
+	return math.MaxInt32, nil
+}
+
+// TaskLogs returns a set of logs matching the provided criteria from the stream of the task.
+// Filters are applied as the stream is read, since CloudWatch cannot search a single stream.
+func (c *CloudWatch) TaskLogs(
+	taskID model.TaskID, limit int, fs []api.Filter, order apiv1.OrderBy, followState interface{},
+) ([]*model.TaskLog, interface{}, error) {
+	if _, ok := followState.(*api.Cursor); ok {
+		return nil, nil, errors.Wrap(api.ErrNotImplemented,
+			"cursors are not supported for logs stored in CloudWatch")
+	}
+	matches, err := filtersToMatcher(fs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	desc := order == apiv1.OrderBy_ORDER_BY_DESC
+	in := &cloudwatchlogs.GetLogEventsInput{
+		LogGroupName:  aws.String(c.logGroup),
+		LogStreamName: aws.String(string(taskID)),
+		StartFromHead: aws.Bool(!desc),
+		EndTime:       aws.Int64(time.Now().Add(-ingestionDelay).UnixMilli()),
+	}
+	// Reading resumes at the time of the last log read, skipping the logs already read then.
+	state := &cloudWatchFollowState{}
+	var skip map[string]bool
+	if followState != nil {
+		prev := followState.(*cloudWatchFollowState)
+		skip = prev.seen
+		for id := range prev.seen {
+			state.advance(prev.timestamp, id)
+		}
+		if desc {
+			in.EndTime = aws.Int64(prev.timestamp + 1)
+		} else {
+			in.StartTime = aws.Int64(prev.timestamp)
+		}
+	}
+
+	var logs []*model.TaskLog
+	for len(logs) < limit {
+		out, err := c.client.GetLogEvents(in)
+		var notFound *cloudwatchlogs.ResourceNotFoundException
+		switch {
+		case errors.As(err, &notFound):
+			// The task has not written any logs yet.
+			return nil, followState, nil
+		case err != nil:
+			return nil, nil, errors.Wrapf(err, "failed to read logs of task %s", taskID)
+		}
+
+		events := out.Events
+		if desc {
+			for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+				events[i], events[j] = events[j], events[i]
+			}
+		}
+		for _, e := range events {
+			l := eventToTaskLog(taskID, e)
+			if skip[*l.StringID] {
+				continue
+			}
+			state.advance(aws.Int64Value(e.Timestamp), *l.StringID)
+			if matches(l) {
+				logs = append(logs, l)
+				if len(logs) == limit {
+					break
+				}
+			}
+		}
+
+		next := out.NextForwardToken
+		if desc {
+			next = out.NextBackwardToken
+		}
+		// CloudWatch returns the token it was given once the end of the stream is reached.
+		if next == nil || aws.StringValue(next) == aws.StringValue(in.NextToken) {
+			break
+		}
+		in.NextToken = next
+	}
+
+	if state.seen == nil {
+		return logs, followState, nil
+	}
+	return logs, state, nil
+}
+
+// eventToTaskLog decodes a log written by AddTaskLogs, falling back to treating the message as
+// the log text for events written to the stream by anything else. CloudWatch does not return IDs
+// for events read from a stream, so one is derived from the contents of the event.
+func eventToTaskLog(taskID model.TaskID, e *cloudwatchlogs.OutputLogEvent) *model.TaskLog {
+	message := aws.StringValue(e.Message)
+	var l model.TaskLog
+	if err := json.Unmarshal([]byte(message), &l); err != nil || l.TaskID == "" {
+		l = model.TaskLog{TaskID: string(taskID), Log: message}
+	}
+	if l.Timestamp == nil {
+		ts := time.UnixMilli(aws.Int64Value(e.Timestamp)).UTC()
+		l.Timestamp = &ts
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(message))
+	id := fmt.Sprintf("%d-%d-%x",
+		aws.Int64Value(e.Timestamp), aws.Int64Value(e.IngestionTime), h.Sum64())
+	l.StringID = &id
+	return &l
+}
+
+// DeleteTaskLogs deletes the logs for the given tasks.
+func (c *CloudWatch) DeleteTaskLogs(taskIDs []model.TaskID) error {
+	for _, taskID := range taskIDs {
+		_, err := c.client.DeleteLogStream(&cloudwatchlogs.DeleteLogStreamInput{
+			LogGroupName:  aws.String(c.logGroup),
+			LogStreamName: aws.String(string(taskID)),
+		})
+		var notFound *cloudwatchlogs.ResourceNotFoundException
+		if err != nil && !errors.As(err, &notFound) {
+			return errors.Wrapf(err, "failed to delete logs of task %s", taskID)
+		}
+
+		c.mu.Lock()
+		delete(c.streams, string(taskID))
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// TaskLogsFields returns the unique fields that can be filtered on for the given task, which
+// requires reading the whole stream.
+func (c *CloudWatch) TaskLogsFields(taskID model.TaskID) (*apiv1.TaskLogsFieldsResponse, error) {
+	logs, _, err := c.TaskLogs(taskID, math.MaxInt32, nil, apiv1.OrderBy_ORDER_BY_ASC, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	allocationIDs, agentIDs, containerIDs := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	sources, stdtypes, rankIDs := map[string]bool{}, map[string]bool{}, map[int32]bool{}
+	add := func(set map[string]bool, v *string) {
+		if v != nil {
+			set[*v] = true
+		}
+	}
+	for _, l := range logs {
+		add(allocationIDs, l.AllocationID)
+		add(agentIDs, l.AgentID)
+		add(containerIDs, l.ContainerID)
+		add(sources, l.Source)
+		add(stdtypes, l.StdType)
+		if l.RankID != nil {
+			rankIDs[int32(*l.RankID)] = true
+		}
+	}
+
+	resp := &apiv1.TaskLogsFieldsResponse{
+		AllocationIds: sortedKeys(allocationIDs),
+		AgentIds:      sortedKeys(agentIDs),
+		ContainerIds:  sortedKeys(containerIDs),
+		Sources:       sortedKeys(sources),
+		Stdtypes:      sortedKeys(stdtypes),
+	}
+	for r := range rankIDs {
+		resp.RankIds = append(resp.RankIds, r)
+	}
+	sort.Slice(resp.RankIds, func(i, j int) bool { return resp.RankIds[i] < resp.RankIds[j] })
+	return resp, nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	var keys []string
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MaxTerminationDelay is the max delay before a consumer can be sure all logs have been received.
+func (c *CloudWatch) MaxTerminationDelay() time.Duration {
+	return ingestionDelay + time.Second
+}
+
+// ValidateTaskLogsRegex implements task.LogBackend. The master filters the logs it reads from
+// CloudWatch itself, so patterns use the syntax of Go.
+func (c *CloudWatch) ValidateTaskLogsRegex(pattern string) error {
+	if _, err := regexp.Compile("(?i)" + pattern); err != nil {
+		return api.AsValidationError("%s", err)
+	}
+	return nil
+}
+
+// filtersToMatcher returns a function reporting whether a log passes all the filters.
+func filtersToMatcher(fs []api.Filter) (func(*model.TaskLog) bool, error) {
+	var preds []func(*model.TaskLog) bool
+	for _, f := range fs {
+		field := f.Field
+		switch f.Operation {
+		case api.FilterOperationIn:
+			values := map[string]bool{}
+			switch vs := f.Values.(type) {
+			case []string:
+				for _, v := range vs {
+					values[v] = true
+				}
+			case []int32:
+				for _, v := range vs {
+					values[strconv.Itoa(int(v))] = true
+				}
+			case []int64:
+				for _, v := range vs {
+					values[strconv.FormatInt(v, 10)] = true
+				}
+			default:
+				return nil, fmt.Errorf("unsupported values for filter on %s: %T", field, f.Values)
+			}
+			preds = append(preds, func(l *model.TaskLog) bool {
+				v, ok := taskLogField(l, field)
+				return ok && values[v]
+			})
+		case api.FilterOperationGreaterThan, api.FilterOperationLessThanEqual:
+			t, ok := f.Values.(time.Time)
+			if field != "timestamp" || !ok {
+				return nil, fmt.Errorf("unsupported comparison on %s", field)
+			}
+			after := f.Operation == api.FilterOperationGreaterThan
+			preds = append(preds, func(l *model.TaskLog) bool {
+				return l.Timestamp != nil && l.Timestamp.After(t) == after
+			})
+		case api.FilterOperationStringContainment:
+			substr := strings.ToLower(fmt.Sprint(f.Values))
+			preds = append(preds, func(l *model.TaskLog) bool {
+				v, ok := taskLogField(l, field)
+				return ok && strings.Contains(strings.ToLower(v), substr)
+			})
+		case api.FilterOperationRegexMatch:
+			re, err := regexp.Compile("(?i)" + fmt.Sprint(f.Values))
+			if err != nil {
+				return nil, err
+			}
+			preds = append(preds, func(l *model.TaskLog) bool {
+				v, ok := taskLogField(l, field)
+				return ok && re.MatchString(v)
+			})
+		default:
+			return nil, fmt.Errorf("unsupported filter operation: %d", f.Operation)
+		}
+	}
+
+	return func(l *model.TaskLog) bool {
+		for _, p := range preds {
+			if !p(l) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func taskLogField(l *model.TaskLog, field string) (string, bool) {
+	var v *string
+	switch field {
+	case "task_id":
+		return l.TaskID, true
+	case "log":
+		return l.Log, true
+	case "rank_id":
+		if l.RankID == nil {
+			return "", false
+		}
+		return strconv.Itoa(*l.RankID), true
+	case "allocation_id":
+		v = l.AllocationID
+	case "agent_id":
+		v = l.AgentID
+	case "container_id":
+		v = l.ContainerID
+	case "level":
+		v = l.Level
+	case "stdtype":
+		v = l.StdType
+	case "source":
+		v = l.Source
+	}
+	if v == nil {
+		return "", false
+	}
+	return *v, true
+}
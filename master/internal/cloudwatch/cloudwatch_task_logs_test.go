@@ -0,0 +1,174 @@
+package cloudwatch
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/api"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+// fakeCloudWatchLogs keeps streams in memory and pages through them a few events at a time.
+type fakeCloudWatchLogs struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+	pageSize int
+	streams  map[string][]*cloudwatchlogs.OutputLogEvent
+}
+
+func (f *fakeCloudWatchLogs) CreateLogStream(
+	in *cloudwatchlogs.CreateLogStreamInput,
+) (*cloudwatchlogs.CreateLogStreamOutput, error) {
+	if _, ok := f.streams[*in.LogStreamName]; ok {
+		return nil, &cloudwatchlogs.ResourceAlreadyExistsException{}
+	}
+	f.streams[*in.LogStreamName] = nil
+	return &cloudwatchlogs.CreateLogStreamOutput{}, nil
+}
+
+func (f *fakeCloudWatchLogs) PutLogEvents(
+	in *cloudwatchlogs.PutLogEventsInput,
+) (*cloudwatchlogs.PutLogEventsOutput, error) {
+	events, ok := f.streams[*in.LogStreamName]
+	if !ok {
+		return nil, &cloudwatchlogs.ResourceNotFoundException{}
+	}
+	for _, e := range in.LogEvents {
+		events = append(events, &cloudwatchlogs.OutputLogEvent{
+			Message:       e.Message,
+			Timestamp:     e.Timestamp,
+			IngestionTime: e.Timestamp,
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return *events[i].Timestamp < *events[j].Timestamp
+	})
+	f.streams[*in.LogStreamName] = events
+	return &cloudwatchlogs.PutLogEventsOutput{
+		NextSequenceToken: aws.String(fmt.Sprint(len(events))),
+	}, nil
+}
+
+func (f *fakeCloudWatchLogs) GetLogEvents(
+	in *cloudwatchlogs.GetLogEventsInput,
+) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	events, ok := f.streams[*in.LogStreamName]
+	if !ok {
+		return nil, &cloudwatchlogs.ResourceNotFoundException{}
+	}
+	var inRange []*cloudwatchlogs.OutputLogEvent
+	for _, e := range events {
+		if (in.StartTime == nil || *e.Timestamp >= *in.StartTime) &&
+			(in.EndTime == nil || *e.Timestamp < *in.EndTime) {
+			inRange = append(inRange, e)
+		}
+	}
+
+	// Tokens are the number of events already read from the chosen end of the range.
+	var read int
+	if in.NextToken != nil {
+		if _, err := fmt.Sscan(*in.NextToken, &read); err != nil {
+			return nil, err
+		}
+	}
+	n := f.pageSize
+	if read+n > len(inRange) {
+		n = len(inRange) - read
+	}
+	var page []*cloudwatchlogs.OutputLogEvent
+	if aws.BoolValue(in.StartFromHead) {
+		page = inRange[read : read+n]
+	} else {
+		page = inRange[len(inRange)-read-n : len(inRange)-read]
+	}
+	next := aws.String(fmt.Sprint(read + n))
+	return &cloudwatchlogs.GetLogEventsOutput{
+		Events:            page,
+		NextForwardToken:  next,
+		NextBackwardToken: next,
+	}, nil
+}
+
+func (f *fakeCloudWatchLogs) DeleteLogStream(
+	in *cloudwatchlogs.DeleteLogStreamInput,
+) (*cloudwatchlogs.DeleteLogStreamOutput, error) {
+	if _, ok := f.streams[*in.LogStreamName]; !ok {
+		return nil, &cloudwatchlogs.ResourceNotFoundException{}
+	}
+	delete(f.streams, *in.LogStreamName)
+	return &cloudwatchlogs.DeleteLogStreamOutput{}, nil
+}
+
+func logTexts(logs []*model.TaskLog) []string {
+	var texts []string
+	for _, l := range logs {
+		texts = append(texts, l.Log)
+	}
+	return texts
+}
+
+func TestCloudWatchTaskLogs(t *testing.T) {
+	fake := &fakeCloudWatchLogs{
+		pageSize: 2,
+		streams:  map[string][]*cloudwatchlogs.OutputLogEvent{},
+	}
+	cw := newCloudWatch(fake, "determined")
+	taskID := model.TaskID("task-1")
+
+	// All the logs share a timestamp, so reads must pick up partway through it.
+	ts := time.Now().Add(-time.Minute).UTC().Truncate(time.Millisecond)
+	var logs []*model.TaskLog
+	for i := 0; i < 5; i++ {
+		logs = append(logs, &model.TaskLog{
+			TaskID:    string(taskID),
+			Timestamp: &ts,
+			RankID:    ptrs.Ptr(i % 2),
+			Log:       fmt.Sprintf("line %d\n", i),
+		})
+	}
+	logs = append(logs, &model.TaskLog{TaskID: "task-2", Timestamp: &ts, Log: "other\n"})
+	require.NoError(t, cw.AddTaskLogs(logs))
+
+	var all []*model.TaskLog
+	var state interface{}
+	for {
+		batch, s, err := cw.TaskLogs(taskID, 2, nil, apiv1.OrderBy_ORDER_BY_ASC, state)
+		require.NoError(t, err)
+		if len(batch) == 0 {
+			break
+		}
+		all, state = append(all, batch...), s
+	}
+	require.Equal(t,
+		[]string{"line 0\n", "line 1\n", "line 2\n", "line 3\n", "line 4\n"}, logTexts(all))
+
+	desc, _, err := cw.TaskLogs(taskID, 2, nil, apiv1.OrderBy_ORDER_BY_DESC, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"line 4\n", "line 3\n"}, logTexts(desc))
+
+	filtered, _, err := cw.TaskLogs(taskID, 10, []api.Filter{
+		{Field: "rank_id", Operation: api.FilterOperationIn, Values: []int32{1}},
+		{Field: "log", Operation: api.FilterOperationRegexMatch, Values: "LINE [0-3]"},
+	}, apiv1.OrderBy_ORDER_BY_ASC, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"line 1\n", "line 3\n"}, logTexts(filtered))
+	require.NoError(t, cw.ValidateTaskLogsRegex(`line \d`))
+	require.ErrorIs(t, cw.ValidateTaskLogsRegex(`line (`), api.ErrInvalid)
+
+	fields, err := cw.TaskLogsFields(taskID)
+	require.NoError(t, err)
+	require.Equal(t, []int32{0, 1}, fields.RankIds)
+
+	require.NoError(t, cw.DeleteTaskLogs([]model.TaskID{taskID, "task-3"}))
+	none, _, err := cw.TaskLogs(taskID, 10, nil, apiv1.OrderBy_ORDER_BY_ASC, nil)
+	require.NoError(t, err)
+	require.Empty(t, none)
+}
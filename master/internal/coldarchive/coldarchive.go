@@ -0,0 +1,293 @@
+// Package coldarchive moves the metrics and logs of experiments that ended long ago out of the
+// database and into gzipped JSON Lines files in checkpoint storage, and loads them back into the
+// database the next time they are accessed.
+package coldarchive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/objectstore"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+)
+
+// archive records that the metrics and logs of an experiment were archived.
+type archive struct {
+	bun.BaseModel `bun:"table:experiment_cold_archives"`
+
+	ExperimentID int                             `bun:"experiment_id,pk"`
+	ArchivedAt   time.Time                       `bun:"archived_at"`
+	RehydratedAt *time.Time                      `bun:"rehydrated_at"`
+	Storage      expconf.CheckpointStorageConfig `bun:"storage,type:jsonb"`
+}
+
+// table is a table with rows that belong to the trials of an experiment. Each is archived to its
+// own file, with one line per row holding the whole row as JSON, which is what is loaded back into
+// the table.
+type table struct {
+	name string
+	key  string
+	keys string
+}
+
+const (
+	trialIDs = "SELECT id FROM trials WHERE experiment_id = ?"
+	taskIDs  = "SELECT task_id FROM trials WHERE experiment_id = ?"
+)
+
+var tables = []table{
+	{name: "raw_steps", key: "trial_id", keys: trialIDs},
+	{name: "raw_validations", key: "trial_id", keys: trialIDs},
+	{name: "raw_generic_metrics", key: "trial_id", keys: trialIDs},
+	{name: "trial_logs", key: "trial_id", keys: trialIDs},
+	{name: "task_logs", key: "task_id", keys: taskIDs},
+}
+
+const (
+	// rehydrateBatchSize is the number of rows inserted at once when loading an archive.
+	rehydrateBatchSize = 1000
+	// maxRowSize is the largest row, as JSON, that can be loaded from an archive.
+	maxRowSize = 64 << 20
+)
+
+// storageKey returns where the rows of the table are stored for an experiment.
+func (t table) storageKey(experimentID int) string {
+	return fmt.Sprintf("cold-archive/experiment-%d/%s.jsonl.gz", experimentID, t.name)
+}
+
+// Archive writes the metrics and logs of an experiment to storage and then deletes them from the
+// database.
+func Archive(ctx context.Context, experimentID int, storage expconf.CheckpointStorageConfig) error {
+	s, err := objectstore.New(ctx, storage)
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		if err := t.export(ctx, s, experimentID); err != nil {
+			return errors.Wrapf(err, "archiving %s of experiment %d", t.name, experimentID)
+		}
+	}
+
+	return db.Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		a := archive{ExperimentID: experimentID, ArchivedAt: time.Now(), Storage: storage}
+		if _, err := tx.NewInsert().Model(&a).
+			On("CONFLICT (experiment_id) DO UPDATE").
+			Set("archived_at = EXCLUDED.archived_at").
+			Set("rehydrated_at = NULL").
+			Set("storage = EXCLUDED.storage").
+			Exec(ctx); err != nil {
+			return errors.Wrapf(err, "recording archive of experiment %d", experimentID)
+		}
+		for _, t := range tables {
+			if _, err := tx.NewDelete().TableExpr(t.name).
+				Where("? IN ("+t.keys+")", bun.Ident(t.key), experimentID).
+				Exec(ctx); err != nil {
+				return errors.Wrapf(err, "deleting archived %s of experiment %d",
+					t.name, experimentID)
+			}
+		}
+		return nil
+	})
+}
+
+// export writes the rows of the table for an experiment to a temporary file and uploads it, so
+// that rows are streamed from the database rather than held in memory.
+func (t table) export(ctx context.Context, s objectstore.Store, experimentID int) (err error) {
+	f, err := os.CreateTemp("", "cold-archive-*.jsonl.gz")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	w := gzip.NewWriter(f)
+	rows, err := db.Bun().QueryContext(ctx, "SELECT to_jsonb(t)::text FROM ? t WHERE ? IN ("+
+		t.keys+")", bun.Ident(t.name), bun.Ident(t.key), experimentID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cErr := rows.Close(); err == nil {
+			err = cErr
+		}
+	}()
+	for rows.Next() {
+		var row string
+		if err := rows.Scan(&row); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, row+"\n"); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return s.Upload(ctx, t.storageKey(experimentID), f)
+}
+
+// Rehydrate loads the metrics and logs of an experiment back into the database if they were
+// archived. It is cheap to call for experiments that are not archived.
+func Rehydrate(ctx context.Context, experimentID int) error {
+	archived, err := db.Bun().NewSelect().Model((*archive)(nil)).
+		Where("experiment_id = ?", experimentID).
+		Where("rehydrated_at IS NULL").
+		Exists(ctx)
+	if err != nil || !archived {
+		return err
+	}
+
+	return db.Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		// Lock the archive so that concurrent requests load it only once.
+		var a archive
+		switch err := tx.NewSelect().Model(&a).
+			Where("experiment_id = ?", experimentID).
+			Where("rehydrated_at IS NULL").
+			For("UPDATE").
+			Scan(ctx); {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil
+		case err != nil:
+			return err
+		}
+
+		s, err := objectstore.New(ctx, a.Storage)
+		if err != nil {
+			return err
+		}
+		start := time.Now()
+		for _, t := range tables {
+			if err := t.load(ctx, tx, s, experimentID); err != nil {
+				return errors.Wrapf(err, "rehydrating %s of experiment %d", t.name, experimentID)
+			}
+		}
+		if _, err := tx.NewUpdate().Model(&a).
+			Set("rehydrated_at = now()").
+			WherePK().
+			Exec(ctx); err != nil {
+			return err
+		}
+		log.Infof("rehydrated experiment %d from cold archive in %s",
+			experimentID, time.Since(start))
+		return nil
+	})
+}
+
+// load downloads the archived rows of the table for an experiment and inserts them back.
+func (t table) load(ctx context.Context, tx bun.Tx, s objectstore.Store, experimentID int) error {
+	f, err := os.CreateTemp("", "cold-archive-*.jsonl.gz")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	if err = s.Download(ctx, t.storageKey(experimentID), f); err != nil {
+		return err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var batch []json.RawMessage
+	insert := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		rows, err := json.Marshal(batch)
+		if err != nil {
+			return err
+		}
+		batch = batch[:0]
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO ? SELECT * FROM jsonb_populate_recordset(NULL::?, ?)",
+			bun.Ident(t.name), bun.Ident(t.name), string(rows))
+		return err
+	}
+	// Rows hold whole log lines, so they can be longer than the default limit of a scanner.
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxRowSize)
+	for scanner.Scan() {
+		row := append(json.RawMessage(nil), scanner.Bytes()...)
+		if batch = append(batch, row); len(batch) >= rehydrateBatchSize {
+			if err := insert(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return insert()
+}
+
+// candidates returns experiments that ended before the cutoff and whose metrics and logs are in
+// the database, leaving out those that were rehydrated after the cutoff.
+func candidates(ctx context.Context, cutoff time.Time) ([]int, error) {
+	var ids []int
+	err := db.Bun().NewSelect().
+		TableExpr("experiments AS e").
+		Column("e.id").
+		Join("LEFT JOIN experiment_cold_archives AS a ON a.experiment_id = e.id").
+		Where("e.state IN ('COMPLETED', 'CANCELED', 'ERROR')").
+		Where("e.end_time < ?", cutoff).
+		Where("a.experiment_id IS NULL OR a.rehydrated_at < ?", cutoff).
+		Order("e.id").
+		Scan(ctx, &ids)
+	return ids, err
+}
+
+// ArchiveLoop archives experiments that ended more than the given time ago once an hour until
+// the context is canceled. A duration of 0 disables archival.
+func ArchiveLoop(ctx context.Context, after time.Duration, storage expconf.CheckpointStorageConfig) {
+	if after <= 0 {
+		return
+	}
+	t := time.NewTicker(time.Hour)
+	defer t.Stop()
+	for {
+		ids, err := candidates(ctx, time.Now().Add(-after))
+		if err != nil {
+			log.WithError(err).Error("failed to find experiments to archive")
+		}
+		for _, id := range ids {
+			if err := Archive(ctx, id, storage); err != nil {
+				log.WithError(err).Errorf("failed to archive experiment %d", id)
+				continue
+			}
+			log.Infof("archived metrics and logs of experiment %d", id)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,102 @@
+//go:build integration
+// +build integration
+
+package coldarchive
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+	"github.com/determined-ai/determined/proto/pkg/commonv1"
+	"github.com/determined-ai/determined/proto/pkg/trialv1"
+)
+
+// experimentRows returns the rows of every archived table for an experiment as JSON.
+func experimentRows(ctx context.Context, t *testing.T, experimentID int) map[string][]string {
+	rows := map[string][]string{}
+	for _, tbl := range tables {
+		var r []string
+		require.NoError(t, db.Bun().NewRaw("SELECT to_jsonb(t)::text FROM ? t WHERE ? IN ("+
+			tbl.keys+") ORDER BY 1", bun.Ident(tbl.name), bun.Ident(tbl.key), experimentID,
+		).Scan(ctx, &r))
+		rows[tbl.name] = r
+	}
+	return rows
+}
+
+func TestArchiveAndRehydrate(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(db.RootFromDB))
+	pgDB := db.MustResolveTestPostgres(t)
+	db.MustMigrateTestPostgres(t, pgDB, db.MigrationsFromDB)
+	ctx := context.Background()
+
+	user := db.RequireMockUser(t, pgDB)
+	exp := db.RequireMockExperiment(t, pgDB, user)
+	tr := db.RequireMockTrial(t, pgDB, exp)
+	other := db.RequireMockTrial(t, pgDB, db.RequireMockExperiment(t, pgDB, user))
+
+	for _, trial := range []*model.Trial{tr, other} {
+		metrics := &trialv1.TrialMetrics{
+			TrialId:        int32(trial.ID),
+			StepsCompleted: 10,
+			Metrics: &commonv1.Metrics{
+				AvgMetrics: &structpb.Struct{Fields: map[string]*structpb.Value{
+					"loss": structpb.NewNumberValue(0.5),
+				}},
+				BatchMetrics: []*structpb.Struct{},
+			},
+		}
+		require.NoError(t, pgDB.AddTrainingMetrics(ctx, metrics))
+		require.NoError(t, pgDB.AddValidationMetrics(ctx, metrics))
+		require.NoError(t, pgDB.AddGenericMetrics(ctx, "inference", metrics))
+		require.NoError(t, pgDB.AddTaskLogs([]*model.TaskLog{
+			{TaskID: string(trial.TaskID), Log: "hello\n", Timestamp: ptrs.Ptr(time.Now())},
+			{TaskID: string(trial.TaskID), Log: "\x00binary\xff", RankID: ptrs.Ptr(1)},
+		}))
+	}
+	before := experimentRows(ctx, t, exp.ID)
+	otherBefore := experimentRows(ctx, t, other.ExperimentID)
+	for _, tbl := range []string{"raw_steps", "raw_validations", "raw_generic_metrics", "task_logs"} {
+		require.NotEmpty(t, before[tbl], tbl)
+	}
+
+	storage := expconf.CheckpointStorageConfig{RawSharedFSConfig: &expconf.SharedFSConfig{
+		RawHostPath: ptrs.Ptr(t.TempDir()),
+	}}
+	require.NoError(t, Archive(ctx, exp.ID, storage))
+	for tbl, r := range experimentRows(ctx, t, exp.ID) {
+		require.Empty(t, r, tbl)
+	}
+	require.Equal(t, otherBefore, experimentRows(ctx, t, other.ExperimentID))
+
+	// Rehydrating restores the rows exactly, including their IDs, and only once.
+	for i := 0; i < 2; i++ {
+		require.NoError(t, Rehydrate(ctx, exp.ID))
+		require.Equal(t, before, experimentRows(ctx, t, exp.ID))
+	}
+	require.NoError(t, Rehydrate(ctx, other.ExperimentID))
+
+	// Rehydrated experiments are archived again once they have not been accessed for long enough.
+	_, err := db.Bun().NewUpdate().Table("experiments").
+		Set("state = 'COMPLETED'").
+		Set("end_time = now() - interval '1 day'").
+		Where("id = ?", exp.ID).
+		Exec(ctx)
+	require.NoError(t, err)
+	ids, err := candidates(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Contains(t, ids, exp.ID)
+	ids, err = candidates(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.NotContains(t, ids, exp.ID)
+}
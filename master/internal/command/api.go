@@ -40,13 +40,21 @@ func RegisterAPIHandler(
 		actor.Addr("tensorboard"),
 		&tensorboardManager{db: db, rm: rm, taskLogger: taskLogger},
 	)
+	vscodeManagerRef, _ := system.ActorOf(
+		actor.Addr("vscodes"),
+		&vscodeManager{db: db, rm: rm, taskLogger: taskLogger},
+	)
 
 	// Wait for all managers to initialize.
-	refs := []*actor.Ref{commandManagerRef, notebookManagerRef, shellManagerRef, tensorboardManagerRef}
+	refs := []*actor.Ref{
+		commandManagerRef, notebookManagerRef, shellManagerRef, tensorboardManagerRef,
+		vscodeManagerRef,
+	}
 	system.AskAll(actor.Ping{}, refs...).GetAll()
 
 	echo.Any("/commands*", api.Route(system, nil), middleware...)
 	echo.Any("/notebooks*", api.Route(system, nil), middleware...)
 	echo.Any("/shells*", api.Route(system, nil), middleware...)
 	echo.Any("/tensorboard*", api.Route(system, nil), middleware...)
+	echo.Any("/vscodes*", api.Route(system, nil), middleware...)
 }
@@ -32,6 +32,7 @@ import (
 	"github.com/determined-ai/determined/proto/pkg/shellv1"
 	"github.com/determined-ai/determined/proto/pkg/taskv1"
 	"github.com/determined-ai/determined/proto/pkg/tensorboardv1"
+	"github.com/determined-ai/determined/proto/pkg/vscodev1"
 )
 
 // terminatedDuration defines the amount of time the command stays in a
@@ -79,11 +80,11 @@ func createGenericCommandActor(
 		jobType:  jobType,
 		jobID:    jobID,
 
-		logCtx: logger.Context{
+		logCtx: logger.MergeContexts(spec.LogContext, logger.Context{
 			"job-id":    jobID,
 			"task-id":   taskID,
 			"task-type": taskType,
-		},
+		}),
 	}
 
 	a, _ := ctx.ActorOf(cmd.taskID, cmd)
@@ -259,15 +260,18 @@ func (c *command) Receive(ctx *actor.Context) error {
 			}
 		}
 
-		var proxyPortConf *sproto.ProxyPortConfig
+		var proxyPorts []*sproto.ProxyPortConfig
 		if c.GenericCommandSpec.Port != nil {
-			proxyPortConf = &sproto.ProxyPortConfig{
+			proxyPorts = append(proxyPorts, &sproto.ProxyPortConfig{
 				ServiceID:       string(c.taskID),
 				Port:            *c.GenericCommandSpec.Port,
 				ProxyTCP:        c.ProxyTCP,
 				Unauthenticated: c.Unauthenticated,
-			}
+				StripPrefix:     c.StripPrefix,
+			})
 		}
+		proxyPorts = append(proxyPorts, sproto.ServiceProxyPorts(
+			c.taskID, c.Config.Environment.ProxyPorts)...)
 
 		c.eventStream, _ = ctx.ActorOf("events", newEventManager(c.Config.Description))
 
@@ -298,16 +302,21 @@ func (c *command) Receive(ctx *actor.Context) error {
 			Name:              c.Config.Description,
 			AllocationRef:     ctx.Self(),
 			Group:             ctx.Self(),
+			UserID:            c.Base.OwnerID(),
+			WorkspaceID:       c.Base.WorkspaceID,
 
-			SlotsNeeded:  c.Config.Resources.Slots,
-			AgentLabel:   c.Config.Resources.AgentLabel,
-			ResourcePool: c.Config.Resources.ResourcePool,
+			SlotsNeeded:      c.Config.Resources.Slots,
+			AgentLabel:       c.Config.Resources.AgentLabel,
+			LabelConstraints: c.Config.Resources.LabelConstraints,
+			ResourcePool:     c.Config.Resources.ResourcePool,
 			FittingRequirements: sproto.FittingRequirements{
 				SingleAgent: true,
 			},
+			ExpectedRuntime: sproto.ExpectedRuntimeFromSeconds(
+				c.Config.Resources.ExpectedRuntimeSeconds),
 
 			StreamEvents: eventStreamConfig,
-			ProxyPort:    proxyPortConf,
+			ProxyPorts:   proxyPorts,
 			IdleTimeout:  idleWatcherConfig,
 			Restore:      c.restored,
 		}, c.db, c.rm, c.taskLogger)
@@ -473,6 +482,30 @@ func (c *command) Receive(ctx *actor.Context) error {
 		}
 		ctx.Respond(&apiv1.SetTensorboardPriorityResponse{Tensorboard: c.toTensorboard(ctx)})
 
+	case *vscodev1.VSCode:
+		ctx.Respond(c.toVSCode(ctx))
+
+	case *apiv1.GetVSCodeRequest:
+		ctx.Respond(&apiv1.GetVSCodeResponse{
+			Vscode: c.toVSCode(ctx),
+			Config: protoutils.ToStruct(c.Config),
+		})
+
+	case *apiv1.KillVSCodeRequest:
+		ctx.Tell(c.allocation, sproto.AllocationSignalWithReason{
+			AllocationSignal:    sproto.KillAllocation,
+			InformationalReason: "user requested kill",
+		})
+		ctx.Respond(&apiv1.KillVSCodeResponse{Vscode: c.toVSCode(ctx)})
+
+	case *apiv1.SetVSCodePriorityRequest:
+		err := c.setPriority(ctx, int(msg.Priority), true)
+		if err != nil {
+			ctx.Respond(err)
+			return nil
+		}
+		ctx.Respond(&apiv1.SetVSCodePriorityResponse{Vscode: c.toVSCode(ctx)})
+
 	case sproto.NotifyRMPriorityChange:
 		ctx.Respond(c.setPriority(ctx, msg.Priority, false))
 
@@ -598,14 +631,19 @@ func (c *command) toCommand(ctx *actor.Context) *commandv1.Command {
 func (c *command) toShell(ctx *actor.Context) *shellv1.Shell {
 	allo := c.refreshAllocationState(ctx)
 	state := enrichState(allo.State)
+	// Shells that only authorize registered keys have no generated keys.
+	var privateKey, publicKey string
+	if c.Metadata.PrivateKey != nil {
+		privateKey, publicKey = *c.Metadata.PrivateKey, *c.Metadata.PublicKey
+	}
 	return &shellv1.Shell{
 		Id:             c.stringID(),
 		State:          state,
 		Description:    c.Config.Description,
 		StartTime:      protoutils.ToTimestamp(ctx.Self().RegisteredTime()),
 		Container:      allo.FirstContainer().ToProto(),
-		PrivateKey:     *c.Metadata.PrivateKey,
-		PublicKey:      *c.Metadata.PublicKey,
+		PrivateKey:     privateKey,
+		PublicKey:      publicKey,
 		Username:       c.Base.Owner.Username,
 		UserId:         int32(c.Base.Owner.ID),
 		DisplayName:    c.Base.Owner.DisplayName.ValueOrZero(),
@@ -638,6 +676,25 @@ func (c *command) toTensorboard(ctx *actor.Context) *tensorboardv1.Tensorboard {
 	}
 }
 
+func (c *command) toVSCode(ctx *actor.Context) *vscodev1.VSCode {
+	allo := c.refreshAllocationState(ctx)
+	state := enrichState(allo.State)
+	return &vscodev1.VSCode{
+		Id:             c.stringID(),
+		State:          state,
+		Description:    c.Config.Description,
+		StartTime:      protoutils.ToTimestamp(ctx.Self().RegisteredTime()),
+		Container:      allo.FirstContainer().ToProto(),
+		ServiceAddress: c.serviceAddress(),
+		Username:       c.Base.Owner.Username,
+		UserId:         int32(c.Base.Owner.ID),
+		DisplayName:    c.Base.Owner.DisplayName.ValueOrZero(),
+		ResourcePool:   c.Config.Resources.ResourcePool,
+		ExitStatus:     c.exitStatus.String(),
+		JobId:          c.jobID.String(),
+	}
+}
+
 // Refresh our view of the allocation state. If the allocation has sent us an exit status,
 // we don't ask for a refresh because it won't respond. Otherwise, ask with a timeout
 // since there is another ask in the opposite direction, and even though it's probably
@@ -0,0 +1,70 @@
+//nolint:dupl
+package command
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/rm"
+	"github.com/determined-ai/determined/master/internal/task"
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/tasks"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/vscodev1"
+)
+
+type vscodeManager struct {
+	db         *db.PgDB
+	rm         rm.ResourceManager
+	taskLogger *task.Logger
+}
+
+func (v *vscodeManager) Receive(ctx *actor.Context) error {
+	switch msg := ctx.Message().(type) {
+	case actor.PreStart:
+		tryRestoreCommandsByType(ctx, v.db, v.rm, v.taskLogger, model.TaskTypeVSCode)
+
+	case actor.PostStop, actor.ChildFailed, actor.ChildStopped:
+
+	case *apiv1.GetVSCodesRequest:
+		resp := &apiv1.GetVSCodesResponse{}
+		users := make(map[string]bool, len(msg.Users))
+		for _, user := range msg.Users {
+			users[user] = true
+		}
+		userIds := make(map[int32]bool, len(msg.UserIds))
+		for _, user := range msg.UserIds {
+			userIds[user] = true
+		}
+		for _, vscode := range ctx.AskAll(&vscodev1.VSCode{}, ctx.Children()...).GetAll() {
+			typed := vscode.(*vscodev1.VSCode)
+			if len(users) == 0 || users[typed.Username] || userIds[typed.UserId] {
+				resp.Vscodes = append(resp.Vscodes, typed)
+			}
+		}
+		ctx.Respond(resp)
+
+	case tasks.GenericCommandSpec:
+		taskID := model.NewTaskID()
+		jobID := model.NewJobID()
+		if err := createGenericCommandActor(
+			ctx, v.db, v.rm, v.taskLogger, taskID, model.TaskTypeVSCode, jobID,
+			model.JobTypeVSCode, msg,
+		); err != nil {
+			ctx.Log().WithError(err).Error("failed to launch VS Code server")
+			ctx.Respond(err)
+		} else {
+			ctx.Respond(taskID)
+		}
+
+	case echo.Context:
+		ctx.Respond(echo.NewHTTPError(http.StatusNotFound))
+
+	default:
+		return actor.ErrUnexpectedMessage(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/determined-ai/determined/master/pkg/check"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+const (
+	// ACMEChallengeHTTP01 proves control of a domain by serving a token over plain HTTP.
+	ACMEChallengeHTTP01 = "http-01"
+	// ACMEChallengeDNS01 proves control of a domain by publishing a token in a DNS TXT record.
+	ACMEChallengeDNS01 = "dns-01"
+
+	defaultACMEDirectoryURL = "https://acme-v02.api.letsencrypt.org/directory"
+	defaultACMEHTTPPort     = 80
+	defaultACMECacheDir     = "/var/lib/determined/acme"
+	defaultACMERenewBefore  = model.Duration(30 * 24 * time.Hour)
+)
+
+// ACMEConfig configures obtaining and renewing the serving certificate of the master from an ACME
+// certificate authority, such as Let's Encrypt, rather than reading it from files.
+type ACMEConfig struct {
+	// Domains are the names the certificate is issued for.
+	Domains []string `json:"domains"`
+	// Email is given to the certificate authority to be told about problems with the certificate.
+	Email        string `json:"email"`
+	DirectoryURL string `json:"directory_url"`
+	Challenge    string `json:"challenge"`
+	// HTTPPort is where HTTP-01 challenges are served; certificate authorities only connect to
+	// port 80, so another port only makes sense behind a port forward.
+	HTTPPort int `json:"http_port"`
+	// DNSHook is a command run as `<hook> present|cleanup <record name> <record value>` to publish
+	// and remove the TXT records of DNS-01 challenges.
+	DNSHook string `json:"dns_hook"`
+	// CacheDir holds the account key, the certificate, and its key across restarts.
+	CacheDir    string         `json:"cache_dir"`
+	RenewBefore model.Duration `json:"renew_before"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *ACMEConfig) UnmarshalJSON(data []byte) error {
+	c.DirectoryURL = defaultACMEDirectoryURL
+	c.Challenge = ACMEChallengeHTTP01
+	c.HTTPPort = defaultACMEHTTPPort
+	c.CacheDir = defaultACMECacheDir
+	c.RenewBefore = defaultACMERenewBefore
+
+	type DefaultParser *ACMEConfig
+	return json.Unmarshal(data, DefaultParser(c))
+}
+
+// Validate implements the check.Validatable interface.
+func (c ACMEConfig) Validate() []error {
+	var wildcard bool
+	for _, d := range c.Domains {
+		wildcard = wildcard || strings.HasPrefix(d, "*.")
+	}
+	return []error{
+		check.GreaterThan(len(c.Domains), 0, "security.tls.acme.domains must not be empty"),
+		check.In(c.Challenge, []string{ACMEChallengeHTTP01, ACMEChallengeDNS01},
+			"security.tls.acme.challenge must be one of http-01 or dns-01"),
+		check.True(c.Challenge != ACMEChallengeHTTP01 || !wildcard,
+			"security.tls.acme.domains may only have wildcards with the dns-01 challenge"),
+		check.True(c.Challenge != ACMEChallengeDNS01 || c.DNSHook != "",
+			"security.tls.acme.dns_hook must be set for the dns-01 challenge"),
+		check.True(c.HTTPPort > 0 && c.HTTPPort < 65536,
+			"security.tls.acme.http_port must be a valid port"),
+		check.NotEmpty(c.CacheDir, "security.tls.acme.cache_dir must be set"),
+		check.GreaterThan(int64(c.RenewBefore), int64(0),
+			"security.tls.acme.renew_before must be positive"),
+	}
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AuditLogConfig configures the audit log of security-relevant actions.
+type AuditLogConfig struct {
+	// RetentionDays is how long entries are kept; 0 keeps them forever.
+	RetentionDays int `json:"retention_days"`
+}
+
+// Retention returns how long entries are kept, or 0 if they are kept forever.
+func (c AuditLogConfig) Retention() time.Duration {
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+// Validate implements the check.Validatable interface.
+func (c AuditLogConfig) Validate() []error {
+	if c.RetentionDays < 0 {
+		return []error{errors.New("audit_log.retention_days must not be negative")}
+	}
+	return nil
+}
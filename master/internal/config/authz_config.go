@@ -18,6 +18,10 @@ var (
 // BasicAuthZType is the default authz string id.
 const BasicAuthZType = "basic"
 
+// RolesAuthZType is the authz string id for access by the roles of users in workspaces and
+// projects.
+const RolesAuthZType = "roles"
+
 // AuthZConfig is a authz-related section of master config.
 type AuthZConfig struct {
 	Type              string  `json:"type"`
@@ -59,7 +63,7 @@ func (c AuthZConfig) IsRBACUIEnabled() bool {
 	if c.RBACUIEnabled != nil {
 		return *c.RBACUIEnabled
 	}
-	return c.Type != BasicAuthZType
+	return c.Type != BasicAuthZType && c.Type != RolesAuthZType
 }
 
 func initAuthZTypes() {
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+)
+
+// BackupConfig configures backups of the database that admins take through the API.
+type BackupConfig struct {
+	// Storage is where backups are written, instead of the checkpoint storage of the master.
+	Storage *expconf.CheckpointStorageConfig `json:"storage"`
+}
+
+// Validate implements the check.Validatable interface.
+func (c BackupConfig) Validate() []error {
+	if c.Storage != nil {
+		switch c.Storage.GetUnionMember().(type) {
+		case expconf.S3Config, expconf.SharedFSConfig:
+		default:
+			return []error{errors.New("backup.storage must be s3 or shared_fs storage")}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+)
+
+// ColdArchiveConfig configures moving the metrics and logs of old experiments out of the database
+// and into checkpoint storage.
+type ColdArchiveConfig struct {
+	// AfterDays is how long after an experiment ends it is archived; 0 disables archival.
+	AfterDays int `json:"after_days"`
+	// Storage is where archives are written, instead of the checkpoint storage of the master.
+	Storage *expconf.CheckpointStorageConfig `json:"storage"`
+}
+
+// After returns how long after an experiment ends it is archived, or 0 if archival is disabled.
+func (c ColdArchiveConfig) After() time.Duration {
+	return time.Duration(c.AfterDays) * 24 * time.Hour
+}
+
+// Validate implements the check.Validatable interface.
+func (c ColdArchiveConfig) Validate() []error {
+	if c.AfterDays < 0 {
+		return []error{errors.New("cold_archive.after_days must not be negative")}
+	}
+	if c.Storage != nil {
+		switch c.Storage.GetUnionMember().(type) {
+		case expconf.S3Config, expconf.SharedFSConfig:
+		default:
+			return []error{errors.New("cold_archive.storage must be s3 or shared_fs storage")}
+		}
+	}
+	return nil
+}
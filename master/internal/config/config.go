@@ -7,7 +7,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -34,6 +36,15 @@ var (
 const KubernetesDefaultPriority = 50
 const sslModeDisable = "disable"
 
+// The ways the master can store the metrics of trials.
+const (
+	// MetricsStoragePostgres stores metrics in tables partitioned by ranges of trial IDs.
+	MetricsStoragePostgres = "postgres"
+	// MetricsStorageTimescaleDB stores training and validation metrics in compressed TimescaleDB
+	// hypertables, and keeps thinned series of them in continuous aggregates.
+	MetricsStorageTimescaleDB = "timescaledb"
+)
+
 type (
 	// ExperimentConfigPatch is the updatedble fields for patching an experiment.
 	ExperimentConfigPatch struct {
@@ -44,8 +55,13 @@ type (
 // DefaultDBConfig returns the default configuration of the database.
 func DefaultDBConfig() *DBConfig {
 	return &DBConfig{
-		Migrations: "file://static/migrations",
-		SSLMode:    sslModeDisable,
+		Migrations:                "file://static/migrations",
+		SSLMode:                   sslModeDisable,
+		TrialsPerMetricsPartition: 10000,
+		MetricsStorage:            MetricsStoragePostgres,
+		MaxOpenConns:              48,
+		MaxIdleConns:              2,
+		SlowQueryThreshold:        model.Duration(time.Second),
 	}
 }
 
@@ -72,6 +88,55 @@ type DBConfig struct {
 	Name        string `json:"name"`
 	SSLMode     string `json:"ssl_mode"`
 	SSLRootCert string `json:"ssl_root_cert"`
+	// TrialsPerMetricsPartition is how many trials the metrics of each new partition of the
+	// tables of metrics hold.
+	TrialsPerMetricsPartition int `json:"trials_per_metrics_partition"`
+	// MetricsStorage is how training and validation metrics are stored: MetricsStoragePostgres or
+	// MetricsStorageTimescaleDB. Switching to TimescaleDB converts the existing tables of metrics
+	// when the master starts, and cannot be undone.
+	MetricsStorage string `json:"metrics_storage"`
+	// Replica is a read-only replica of the database to route expensive reads to.
+	Replica *DBReplicaConfig `json:"replica"`
+
+	// MaxOpenConns, MaxIdleConns, ConnMaxIdleTime, and ConnMaxLifetime size the connection pool
+	// of the database; durations of 0 keep connections open forever.
+	MaxOpenConns    int            `json:"max_open_conns"`
+	MaxIdleConns    int            `json:"max_idle_conns"`
+	ConnMaxIdleTime model.Duration `json:"conn_max_idle_time"`
+	ConnMaxLifetime model.Duration `json:"conn_max_lifetime"`
+	// StatementTimeout is how long a statement may run before the database cancels it; 0 lets
+	// statements run forever. Migrations are never canceled.
+	StatementTimeout model.Duration `json:"statement_timeout"`
+	// SlowQueryThreshold is how long a query must run to be logged and reported as slow; 0
+	// disables reporting slow queries.
+	SlowQueryThreshold model.Duration `json:"slow_query_threshold"`
+}
+
+// Validate implements the check.Validatable interface.
+func (c DBConfig) Validate() []error {
+	var errs []error
+	if c.TrialsPerMetricsPartition <= 0 {
+		errs = append(errs, errors.New("db.trials_per_metrics_partition must be positive"))
+	}
+	switch c.MetricsStorage {
+	case MetricsStoragePostgres, MetricsStorageTimescaleDB:
+	default:
+		errs = append(errs, fmt.Errorf("db.metrics_storage must be %q or %q",
+			MetricsStoragePostgres, MetricsStorageTimescaleDB))
+	}
+	if c.MaxOpenConns <= 0 {
+		errs = append(errs, errors.New("db.max_open_conns must be positive"))
+	}
+	if c.MaxIdleConns < 0 || c.MaxIdleConns > c.MaxOpenConns {
+		errs = append(errs, errors.New("db.max_idle_conns must be between 0 and db.max_open_conns"))
+	}
+	if c.ConnMaxIdleTime < 0 || c.ConnMaxLifetime < 0 || c.StatementTimeout < 0 ||
+		c.SlowQueryThreshold < 0 {
+		errs = append(errs, errors.New(
+			"db.conn_max_idle_time, db.conn_max_lifetime, db.statement_timeout, and "+
+				"db.slow_query_threshold must not be negative"))
+	}
+	return errs
 }
 
 // WebhooksConfig hosts configuration fields for webhook functionality.
@@ -99,6 +164,15 @@ func DefaultConfig() *Config {
 				RsaKeySize: 1024,
 			},
 			AuthZ: *DefaultAuthZConfig(),
+			MFA: MFAConfig{
+				Issuer: "Determined",
+			},
+			Lockout: LockoutConfig{
+				Duration: model.Duration(15 * time.Minute),
+			},
+			Session: SessionConfig{
+				MaxDuration: model.Duration(DefaultSessionDuration),
+			},
 		},
 		// If left unspecified, the port is later filled in with 8080 (no TLS) or 8443 (TLS).
 		Port:        0,
@@ -127,6 +201,16 @@ func DefaultConfig() *Config {
 			CoresPerWorker: 1,
 			MaxTrees:       100,
 		},
+		OIDC:           DefaultOIDCConfig(),
+		LDAP:           DefaultLDAPConfig(),
+		RateLimit:      DefaultRateLimitConfig(),
+		QueryCache:     DefaultQueryCacheConfig(),
+		Shutdown:       DefaultShutdownConfig(),
+		ServiceProxy:   DefaultServiceProxyConfig(),
+		Preflight:      DefaultPreflightConfig(),
+		SystemMetrics:  DefaultSystemMetricsConfig(),
+		Cost:           DefaultCostConfig(),
+		Energy:         DefaultEnergyConfig(),
 		ResourceConfig: DefaultResourceConfig(),
 	}
 }
@@ -154,7 +238,24 @@ type Config struct {
 	Observability         ObservabilityConfig               `json:"observability"`
 	Cache                 CacheConfig                       `json:"cache"`
 	Webhooks              WebhooksConfig                    `json:"webhooks"`
+	LogForwarders         []LogForwarderConfig              `json:"log_forwarders"`
 	FeatureSwitches       []string                          `json:"feature_switches"`
+	OIDC                  OIDCConfig                        `json:"oidc"`
+	LDAP                  LDAPConfig                        `json:"ldap"`
+	Scim                  ScimConfig                        `json:"scim"`
+	RateLimit             RateLimitConfig                   `json:"rate_limit"`
+	QueryCache            QueryCacheConfig                  `json:"query_cache"`
+	Fsck                  FsckConfig                        `json:"fsck"`
+	Shutdown              ShutdownConfig                    `json:"shutdown"`
+	AuditLog              AuditLogConfig                    `json:"audit_log"`
+	ColdArchive           ColdArchiveConfig                 `json:"cold_archive"`
+	Backup                BackupConfig                      `json:"backup"`
+	LogRetention          LogRetentionConfig                `json:"log_retention"`
+	ServiceProxy          ServiceProxyConfig                `json:"service_proxy"`
+	Preflight             PreflightConfig                   `json:"preflight"`
+	SystemMetrics         SystemMetricsConfig               `json:"system_metrics"`
+	Cost                  CostConfig                        `json:"cost"`
+	Energy                EnergyConfig                      `json:"energy"`
 	*ResourceConfig
 
 	// Internal contains "hidden" useful debugging configurations.
@@ -187,6 +288,12 @@ func (c Config) Printable() ([]byte, error) {
 	if c.DB.Password != "" {
 		c.DB.Password = hiddenValue
 	}
+	if c.DB.Replica != nil {
+		// The DSN of the replica may contain a password.
+		printable := *c.DB.Replica
+		printable.DSN = hiddenValue
+		c.DB.Replica = &printable
+	}
 	if c.Telemetry.SegmentMasterKey != "" {
 		c.Telemetry.SegmentMasterKey = hiddenValue
 	}
@@ -204,6 +311,44 @@ func (c Config) Printable() ([]byte, error) {
 	}
 
 	c.CheckpointStorage = c.CheckpointStorage.Printable()
+	for _, storage := range []**expconf.CheckpointStorageConfig{
+		&c.ColdArchive.Storage, &c.Backup.Storage, &c.LogRetention.Storage,
+	} {
+		if *storage != nil {
+			printable := (*storage).Printable()
+			*storage = &printable
+		}
+	}
+
+	if c.OIDC.ClientSecret != "" {
+		c.OIDC.ClientSecret = hiddenValue
+	}
+	if c.LDAP.BindPassword != "" {
+		c.LDAP.BindPassword = hiddenValue
+	}
+	if c.Scim.Auth.Password != "" {
+		c.Scim.Auth.Password = hiddenValue
+	}
+	if c.Scim.Auth.Token != "" {
+		c.Scim.Auth.Token = hiddenValue
+	}
+
+	forwarders := make([]LogForwarderConfig, 0, len(c.LogForwarders))
+	for _, f := range c.LogForwarders {
+		if f.Token != "" {
+			f.Token = hiddenValue
+		}
+		// Headers may carry credentials too, so all of their values are hidden.
+		if len(f.Headers) > 0 {
+			headers := make(map[string]string, len(f.Headers))
+			for name := range f.Headers {
+				headers[name] = hiddenValue
+			}
+			f.Headers = headers
+		}
+		forwarders = append(forwarders, f)
+	}
+	c.LogForwarders = forwarders
 
 	optJSON, err := json.Marshal(c)
 	if err != nil {
@@ -259,6 +404,21 @@ type SecurityConfig struct {
 	TLS         TLSConfig            `json:"tls"`
 	SSH         SSHConfig            `json:"ssh"`
 	AuthZ       AuthZConfig          `json:"authz"`
+	MFA         MFAConfig            `json:"mfa"`
+	// PasswordPolicy, Lockout and Session apply to users who log in with a password in the
+	// cluster; Session also applies to the sessions of other users.
+	PasswordPolicy PasswordPolicyConfig `json:"password_policy"`
+	Lockout        LockoutConfig        `json:"lockout"`
+	Session        SessionConfig        `json:"session"`
+}
+
+// MFAConfig is the configuration for multi-factor authentication of users who log in with a
+// password in the cluster.
+type MFAConfig struct {
+	// Required makes every such user enroll before they can do anything else.
+	Required bool `json:"required"`
+	// Issuer names the cluster in authenticator apps.
+	Issuer string `json:"issuer"`
 }
 
 // SSHConfig is the configuration setting for SSH.
@@ -270,6 +430,8 @@ type SSHConfig struct {
 type TLSConfig struct {
 	Cert string `json:"cert"`
 	Key  string `json:"key"`
+	// ACME, if set, obtains the certificate from an ACME certificate authority instead.
+	ACME *ACMEConfig `json:"acme"`
 }
 
 // Validate implements the check.Validatable interface.
@@ -280,6 +442,9 @@ func (t *TLSConfig) Validate() []error {
 	} else if t.Key == "" && t.Cert != "" {
 		errs = append(errs, errors.New("TLS cert file provided without a key file"))
 	}
+	if t.ACME != nil && (t.Cert != "" || t.Key != "") {
+		errs = append(errs, errors.New("TLS cert and key files cannot be provided along with ACME"))
+	}
 	return errs
 }
 
@@ -294,15 +459,24 @@ func (t *SSHConfig) Validate() []error {
 	return errs
 }
 
+// Validate implements the check.Validatable interface.
+func (c MFAConfig) Validate() []error {
+	// Authenticator apps split the issuer from the username at the first colon.
+	if c.Issuer == "" || strings.Contains(c.Issuer, ":") {
+		return []error{errors.New("mfa issuer must be non-empty and cannot contain a colon")}
+	}
+	return nil
+}
+
 // Enabled returns whether this configuration makes it possible to enable TLS.
 func (t *TLSConfig) Enabled() bool {
-	return t.Cert != "" && t.Key != ""
+	return (t.Cert != "" && t.Key != "") || t.ACME != nil
 }
 
 // ReadCertificate returns the certificate described by this configuration (nil if it does not allow
-// TLS to be enabled).
+// TLS to be enabled or if the certificate is obtained through ACME).
 func (t *TLSConfig) ReadCertificate() (*tls.Certificate, error) {
-	if !t.Enabled() {
+	if t.Cert == "" || t.Key == "" {
 		return nil, nil
 	}
 	cert, err := tls.LoadX509KeyPair(t.Cert, t.Key)
@@ -359,6 +533,38 @@ func readRMPreemptionStatus(config *Config, rpName string) bool {
 	}
 }
 
+// ReadGangStartConfig resolves the gang start configuration for a resource pool, or nil if
+// multi-container allocations in the pool should wait indefinitely for all of their resources.
+func ReadGangStartConfig(rpName string) *GangStartConfig {
+	config := GetMasterConfig()
+	return readGangStartConfig(config, rpName)
+}
+
+func readGangStartConfig(config *Config, rpName string) *GangStartConfig {
+	for _, rpConfig := range config.ResourcePools {
+		if rpConfig.PoolName != rpName {
+			continue
+		}
+		if rpConfig.Scheduler != nil && rpConfig.Scheduler.GangStart != nil {
+			return rpConfig.Scheduler.GangStart
+		}
+		break
+	}
+
+	// if not found, fall back to resource manager config
+	switch {
+	case config.ResourceManager.AgentRM != nil:
+		if config.ResourceManager.AgentRM.Scheduler == nil {
+			return nil
+		}
+		return config.ResourceManager.AgentRM.Scheduler.GangStart
+	case config.ResourceManager.KubernetesRM != nil:
+		return config.ResourceManager.KubernetesRM.GangStart
+	default:
+		return nil
+	}
+}
+
 // ReadPriority resolves the priority value for a job.
 func ReadPriority(rpName string, jobConf interface{}) int {
 	config := GetMasterConfig()
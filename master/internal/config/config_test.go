@@ -4,6 +4,7 @@ package config
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 
 	"github.com/determined-ai/determined/master/internal/config/provconfig"
 	"github.com/determined-ai/determined/master/pkg/aproto"
+	"github.com/determined-ai/determined/master/pkg/check"
 	"github.com/determined-ai/determined/master/pkg/config"
 	"github.com/determined-ai/determined/master/pkg/logger"
 	"github.com/determined-ai/determined/master/pkg/model"
@@ -65,6 +67,7 @@ resource_pools:
 					},
 					DefaultComputeResourcePool: "default",
 					DefaultAuxResourcePool:     "default",
+					PoolStatsRetention:         DefaultPoolStatsRetention,
 				},
 			},
 			ResourcePools: []ResourcePoolConfig{
@@ -88,6 +91,10 @@ resource_pools:
 						DtrainNetworkInterface: "if0",
 					},
 					AgentReconnectWait: model.Duration(aproto.AgentReconnectWait),
+					ContainerRuntime:   aproto.ContainerRuntimeDocker,
+
+					AgentHeartbeatInterval:      model.Duration(aproto.AgentHeartbeatInterval),
+					AgentHeartbeatMissTolerance: aproto.AgentHeartbeatMissTolerance,
 				},
 			},
 		},
@@ -129,6 +136,7 @@ resource_pools:
 					},
 					DefaultComputeResourcePool: "gpu-pool",
 					DefaultAuxResourcePool:     "cpu-pool",
+					PoolStatsRetention:         DefaultPoolStatsRetention,
 				},
 			},
 			ResourcePools: []ResourcePoolConfig{
@@ -148,6 +156,10 @@ resource_pools:
 					MaxAuxContainersPerAgent: 10,
 					MaxCPUContainersPerAgent: 0,
 					AgentReconnectWait:       model.Duration(aproto.AgentReconnectWait),
+					ContainerRuntime:         aproto.ContainerRuntimeDocker,
+
+					AgentHeartbeatInterval:      model.Duration(aproto.AgentHeartbeatInterval),
+					AgentHeartbeatMissTolerance: aproto.AgentHeartbeatMissTolerance,
 				},
 				{
 					PoolName: "gpu-pool",
@@ -165,6 +177,10 @@ resource_pools:
 					MaxAuxContainersPerAgent: 0,
 					MaxCPUContainersPerAgent: 0,
 					AgentReconnectWait:       model.Duration(aproto.AgentReconnectWait),
+					ContainerRuntime:         aproto.ContainerRuntimeDocker,
+
+					AgentHeartbeatInterval:      model.Duration(aproto.AgentHeartbeatInterval),
+					AgentHeartbeatMissTolerance: aproto.AgentHeartbeatMissTolerance,
 				},
 			},
 		},
@@ -256,6 +272,7 @@ func TestPrintableConfig(t *testing.T) {
 	masterSecret := "my_master_secret"
 	webuiSecret := "my_webui_secret"
 	registryAuthSecret := "i_love_cellos"
+	replicaSecret := "my_replica_secret"
 
 	raw := fmt.Sprintf(`
 db:
@@ -263,6 +280,8 @@ db:
   password: password
   host: hostname
   port: "3000"
+  replica:
+    dsn: postgres://user:%v@replica:5432/determined
 
 checkpoint_storage:
   type: s3
@@ -281,7 +300,7 @@ task_container_defaults:
     password: %v
     shm_size_bytes: 4294967296
     network_mode: bridge
-`, s3Key, s3Secret, masterSecret, webuiSecret, registryAuthSecret)
+`, replicaSecret, s3Key, s3Secret, masterSecret, webuiSecret, registryAuthSecret)
 
 	expected := Config{
 		Logging: model.LoggingConfig{
@@ -292,6 +311,9 @@ task_container_defaults:
 			Password: "password",
 			Host:     "hostname",
 			Port:     "3000",
+			Replica: &DBReplicaConfig{
+				DSN: fmt.Sprintf("postgres://user:%v@replica:5432/determined", replicaSecret),
+			},
 		},
 		CheckpointStorage: expconf.CheckpointStorageConfig{
 			RawS3Config: &expconf.S3Config{
@@ -333,6 +355,7 @@ task_container_defaults:
 	assert.Assert(t, !bytes.Contains(printable, []byte(masterSecret)))
 	assert.Assert(t, !bytes.Contains(printable, []byte(webuiSecret)))
 	assert.Assert(t, !bytes.Contains(printable, []byte(registryAuthSecret)))
+	assert.Assert(t, !bytes.Contains(printable, []byte(replicaSecret)))
 
 	// Ensure that the original was unmodified.
 	assert.DeepEqual(t, unmarshaled, expected)
@@ -490,3 +513,318 @@ resource_manager:
 		})
 	}
 }
+
+func TestGangStartConfig(t *testing.T) {
+	test := func(t *testing.T, configRaw string, rpName string, expected *GangStartConfig) {
+		unmarshaled := DefaultConfig()
+		err := yaml.Unmarshal([]byte(configRaw), unmarshaled, yaml.DisallowUnknownFields)
+		assert.NilError(t, unmarshaled.Resolve())
+		assert.NilError(t, err)
+		assert.DeepEqual(t, readGangStartConfig(unmarshaled, rpName), expected)
+	}
+
+	testCases := []struct {
+		name      string
+		configRaw string
+		rpName    string
+		expected  *GangStartConfig
+	}{
+		{
+			name: "agent without gang start",
+			configRaw: `
+resource_manager:
+  type: agent
+`,
+			rpName:   "default",
+			expected: nil,
+		},
+		{
+			name: "agent with default policy",
+			configRaw: `
+resource_manager:
+  type: agent
+  scheduler:
+    gang_start:
+      timeout: 10m
+`,
+			rpName: "default",
+			expected: &GangStartConfig{
+				Timeout: model.Duration(10 * time.Minute),
+				Policy:  GangStartPolicyRequeue,
+			},
+		},
+		{
+			name: "agent with gang start overridden by RP",
+			configRaw: `
+resource_manager:
+  type: agent
+  scheduler:
+    gang_start:
+      timeout: 10m
+
+resource_pools:
+  - pool_name: default
+    scheduler:
+      gang_start:
+        timeout: 1m
+        policy: degraded
+  - pool_name: other
+`,
+			rpName: "default",
+			expected: &GangStartConfig{
+				Timeout: model.Duration(time.Minute),
+				Policy:  GangStartPolicyDegraded,
+			},
+		},
+		{
+			name: "agent with gang start falling back to RM",
+			configRaw: `
+resource_manager:
+  type: agent
+  scheduler:
+    gang_start:
+      timeout: 10m
+
+resource_pools:
+  - pool_name: default
+    scheduler:
+      gang_start:
+        timeout: 1m
+        policy: degraded
+  - pool_name: other
+`,
+			rpName: "other",
+			expected: &GangStartConfig{
+				Timeout: model.Duration(10 * time.Minute),
+				Policy:  GangStartPolicyRequeue,
+			},
+		},
+		{
+			name: "k8s with gang start",
+			configRaw: `
+resource_manager:
+  type: kubernetes
+  gang_start:
+    timeout: 5m
+    policy: degraded
+`,
+			rpName: "default",
+			expected: &GangStartConfig{
+				Timeout: model.Duration(5 * time.Minute),
+				Policy:  GangStartPolicyDegraded,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			test(t, tc.configRaw, tc.rpName, tc.expected)
+		})
+	}
+}
+
+func TestLogRetentionOffPeak(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2022, 12, 23, hour, 30, 0, 0, time.UTC) }
+	var allDay []int
+	for h := 0; h < 24; h++ {
+		allDay = append(allDay, h)
+	}
+	testCases := []struct {
+		start, end int
+		offPeak    []int
+	}{
+		{start: 3, end: 3, offPeak: allDay},
+		{start: 1, end: 5, offPeak: []int{1, 2, 3, 4}},
+		{start: 22, end: 3, offPeak: []int{22, 23, 0, 1, 2}},
+	}
+	for _, tc := range testCases {
+		c := LogRetentionConfig{OffPeakStartHour: tc.start, OffPeakEndHour: tc.end}
+		offPeak := map[int]bool{}
+		for _, h := range tc.offPeak {
+			offPeak[h] = true
+		}
+		for h := 0; h < 24; h++ {
+			assert.Equal(t, c.OffPeak(at(h)), offPeak[h], "hours %d-%d at %d", tc.start, tc.end, h)
+		}
+	}
+}
+
+func TestACMEConfig(t *testing.T) {
+	var c Config
+	err := yaml.Unmarshal([]byte(`
+security:
+  tls:
+    acme:
+      domains: [det.example.com]
+`), &c, yaml.DisallowUnknownFields)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, *c.Security.TLS.ACME, ACMEConfig{
+		Domains:      []string{"det.example.com"},
+		DirectoryURL: defaultACMEDirectoryURL,
+		Challenge:    ACMEChallengeHTTP01,
+		HTTPPort:     defaultACMEHTTPPort,
+		CacheDir:     defaultACMECacheDir,
+		RenewBefore:  defaultACMERenewBefore,
+	})
+	assert.NilError(t, check.Validate(c.Security.TLS))
+	assert.Assert(t, c.Security.TLS.Enabled())
+
+	for _, invalid := range []ACMEConfig{
+		{Domains: []string{"*.example.com"}},
+		{Domains: []string{"*.example.com"}, Challenge: ACMEChallengeDNS01},
+		{Challenge: ACMEChallengeDNS01, DNSHook: "/usr/local/bin/dns-hook"},
+	} {
+		conf := *c.Security.TLS.ACME
+		conf.Domains = invalid.Domains
+		if invalid.Challenge != "" {
+			conf.Challenge, conf.DNSHook = invalid.Challenge, invalid.DNSHook
+		}
+		assert.ErrorContains(t, check.Validate(conf), "security.tls.acme", "%+v", invalid)
+	}
+}
+
+func TestCostSlotHourRates(t *testing.T) {
+	pools := []ResourcePoolConfig{
+		{
+			PoolName: "aws",
+			Provider: &provconfig.Config{AWS: &provconfig.AWSClusterConfig{InstanceType: "p3.8xlarge"}},
+		},
+		{
+			PoolName: "overridden",
+			Provider: &provconfig.Config{AWS: &provconfig.AWSClusterConfig{InstanceType: "p3.8xlarge"}},
+		},
+		{PoolName: "static"},
+		{PoolName: "unpriced"},
+	}
+	c := CostConfig{
+		Currency:          "USD",
+		InstanceTypeRates: map[string]float64{"p3.8xlarge": 12},
+		ResourcePoolRates: map[string]float64{"overridden": 2.5, "static": 1},
+	}
+	assert.DeepEqual(t, c.SlotHourRates(pools), map[string]float64{
+		"aws": 3, "overridden": 2.5, "static": 1,
+	})
+
+	assert.NilError(t, check.Validate(c))
+	c.ResourcePoolRates["static"] = -1
+	assert.ErrorContains(t, check.Validate(c), "cost.resource_pool_rates.static")
+}
+
+func TestEnergyCarbonIntensity(t *testing.T) {
+	c := DefaultEnergyConfig()
+	c.ResourcePoolCarbonIntensities = map[string]float64{"hydro": 20}
+	assert.Equal(t, c.CarbonIntensityOf("default"), float64(defaultCarbonIntensity))
+	assert.Equal(t, c.CarbonIntensityOf("hydro"), 20.0)
+	assert.NilError(t, check.Validate(c))
+
+	c.CarbonIntensity = -1
+	assert.ErrorContains(t, check.Validate(c), "energy.carbon_intensity")
+}
+
+func TestLDAPConfig(t *testing.T) {
+	c := DefaultConfig()
+	err := yaml.Unmarshal([]byte(`
+ldap:
+  enabled: true
+  url: ldaps://ad.example.com
+  bind_dn: cn=determined,ou=services,dc=example,dc=com
+  bind_password: hunter2
+  user_search_base_dn: ou=people,dc=example,dc=com
+  username_attribute: sAMAccountName
+`), c, yaml.DisallowUnknownFields)
+	assert.NilError(t, err)
+	assert.Equal(t, c.LDAP.UserObjectClass, "person")
+	assert.Equal(t, c.LDAP.UsernameAttribute, "sAMAccountName")
+	assert.NilError(t, check.Validate(c.LDAP))
+
+	printable, err := c.Printable()
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(string(printable), "hunter2"))
+
+	for _, invalid := range []func(*LDAPConfig){
+		func(l *LDAPConfig) { l.URL = "https://ad.example.com" },
+		func(l *LDAPConfig) { l.StartTLS = true },
+		func(l *LDAPConfig) { l.BindPassword = "" },
+		func(l *LDAPConfig) { l.UserSearchBaseDN = "" },
+		func(l *LDAPConfig) { l.GroupSearchBaseDN, l.GroupNameAttribute = "dc=example,dc=com", "" },
+	} {
+		conf := c.LDAP
+		invalid(&conf)
+		assert.ErrorContains(t, check.Validate(conf), "ldap")
+	}
+}
+
+func TestLogForwarderConfig(t *testing.T) {
+	c := DefaultConfig()
+	err := yaml.Unmarshal([]byte(`
+log_forwarders:
+  - type: http
+    url: https://logs.example.com/ingest
+    token: hunter2
+    headers:
+      X-Api-Key: correct-horse
+`), c, yaml.DisallowUnknownFields)
+	assert.NilError(t, err)
+	assert.Equal(t, c.LogForwarders[0].BatchSize, defaultLogForwarderBatchSize)
+	assert.NilError(t, check.Validate(c.LogForwarders[0]))
+
+	printable, err := c.Printable()
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(string(printable), "hunter2"))
+	assert.Assert(t, !strings.Contains(string(printable), "correct-horse"))
+	assert.Assert(t, strings.Contains(string(printable), "X-Api-Key"))
+
+	// Ensure that the original was unmodified.
+	assert.Equal(t, c.LogForwarders[0].Headers["X-Api-Key"], "correct-horse")
+}
+
+func TestPasswordPolicyConfig(t *testing.T) {
+	c := DefaultConfig()
+	assert.Assert(t, !c.Security.PasswordPolicy.ComplexityRequired())
+	assert.Equal(t, c.Security.Session.MaxDuration, model.Duration(DefaultSessionDuration))
+
+	err := yaml.Unmarshal([]byte(`
+security:
+  password_policy:
+    min_length: 12
+    require_digit: true
+    max_age: 2160h
+  lockout:
+    max_failed_attempts: 5
+  session:
+    max_duration: 12h
+`), c, yaml.DisallowUnknownFields)
+	assert.NilError(t, err)
+	assert.Assert(t, c.Security.PasswordPolicy.ComplexityRequired())
+	assert.Equal(t, c.Security.Lockout.Duration, model.Duration(15*time.Minute))
+	assert.Equal(t, c.Security.Session.MaxDuration, model.Duration(12*time.Hour))
+	assert.NilError(t, check.Validate(c.Security))
+
+	c.Security.Lockout.Duration = 0
+	assert.ErrorContains(t, check.Validate(c.Security.Lockout), "lockout.duration")
+	c.Security.Session.MaxDuration = 0
+	assert.ErrorContains(t, check.Validate(c.Security.Session), "session.max_duration")
+}
+
+func TestAgentHeartbeatConfig(t *testing.T) {
+	var c Config
+	err := yaml.Unmarshal([]byte(`
+resource_pools:
+  - pool_name: flaky
+    agent_heartbeat_interval: 10s
+    agent_heartbeat_miss_tolerance: 3
+    agent_reconnect_wait: 5m
+`), &c, yaml.DisallowUnknownFields)
+	assert.NilError(t, err)
+	pool := c.ResourcePools[0]
+	assert.Equal(t, pool.AgentHeartbeatInterval, model.Duration(10*time.Second))
+	assert.Equal(t, pool.AgentHeartbeatMissTolerance, 3)
+	assert.Equal(t, pool.AgentReconnectWait, model.Duration(5*time.Minute))
+	assert.NilError(t, check.Validate(pool))
+
+	pool.AgentHeartbeatMissTolerance = 0
+	assert.ErrorContains(t, check.Validate(pool), "miss tolerance")
+	pool.AgentHeartbeatMissTolerance = 3
+	pool.AgentHeartbeatInterval = 0
+	assert.ErrorContains(t, check.Validate(pool), "heartbeat interval")
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/internal/config/provconfig"
+)
+
+// CostConfig configures the rates that the slot hours used by experiments are priced at.
+type CostConfig struct {
+	// Currency is the currency the rates are in.
+	Currency string `json:"currency"`
+	// InstanceTypeRates are the hourly rates of the instance types that resource pools with a
+	// provider launch, which are split evenly between the slots of each instance.
+	InstanceTypeRates map[string]float64 `json:"instance_type_rates"`
+	// ResourcePoolRates are the hourly rates of a slot in a resource pool. They take precedence
+	// over the rate of the instance type of the pool.
+	ResourcePoolRates map[string]float64 `json:"resource_pool_rates"`
+}
+
+// DefaultCostConfig returns the default cost configuration, which prices nothing.
+func DefaultCostConfig() CostConfig {
+	return CostConfig{Currency: "USD"}
+}
+
+// Validate implements the check.Validatable interface.
+func (c CostConfig) Validate() []error {
+	var errs []error
+	if c.Currency == "" {
+		errs = append(errs, errors.New("cost.currency must be set"))
+	}
+	for name, rate := range c.InstanceTypeRates {
+		if rate < 0 {
+			errs = append(errs, errors.Errorf("cost.instance_type_rates.%s must not be negative", name))
+		}
+	}
+	for name, rate := range c.ResourcePoolRates {
+		if rate < 0 {
+			errs = append(errs, errors.Errorf("cost.resource_pool_rates.%s must not be negative", name))
+		}
+	}
+	return errs
+}
+
+// SlotHourRates returns the hourly rate of a slot in each resource pool that has one.
+func (c CostConfig) SlotHourRates(pools []ResourcePoolConfig) map[string]float64 {
+	rates := map[string]float64{}
+	for _, pool := range pools {
+		name, slots := instanceType(pool.Provider)
+		if rate, ok := c.InstanceTypeRates[name]; ok && slots > 0 {
+			rates[pool.PoolName] = rate / float64(slots)
+		}
+	}
+	for pool, rate := range c.ResourcePoolRates {
+		rates[pool] = rate
+	}
+	return rates
+}
+
+// instanceType returns the name of the instance type that a provider launches and the number of
+// slots each instance has, or an empty name if the pool has no provider.
+func instanceType(p *provconfig.Config) (string, int) {
+	switch {
+	case p == nil:
+		return "", 0
+	case p.AWS != nil:
+		return p.AWS.InstanceType.Name(), p.AWS.SlotsPerInstance()
+	case p.GCP != nil:
+		return p.GCP.InstanceType.Name(), p.GCP.SlotsPerInstance()
+	case p.Azure != nil:
+		return p.Azure.InstanceType.Name(), p.Azure.SlotsPerInstance()
+	default:
+		return "", 0
+	}
+}
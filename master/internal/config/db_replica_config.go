@@ -0,0 +1,39 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const defaultReplicaMaxLagSeconds = 10
+
+// DBReplicaConfig configures a read-only replica of the database that expensive reads, such as
+// listing experiments or querying metrics, are routed to while it is fresh enough.
+type DBReplicaConfig struct {
+	// DSN is the connection string of the replica.
+	DSN string `json:"dsn"`
+	// MaxLagSeconds is how far the replica may lag behind the primary before reads are routed back
+	// to the primary; 0 uses the default.
+	MaxLagSeconds int `json:"max_lag_seconds"`
+}
+
+// MaxLag returns how far the replica may lag behind the primary.
+func (c DBReplicaConfig) MaxLag() time.Duration {
+	if c.MaxLagSeconds == 0 {
+		return defaultReplicaMaxLagSeconds * time.Second
+	}
+	return time.Duration(c.MaxLagSeconds) * time.Second
+}
+
+// Validate implements the check.Validatable interface.
+func (c DBReplicaConfig) Validate() []error {
+	var errs []error
+	if c.DSN == "" {
+		errs = append(errs, errors.New("db.replica.dsn must be set"))
+	}
+	if c.MaxLagSeconds < 0 {
+		errs = append(errs, errors.New("db.replica.max_lag_seconds must not be negative"))
+	}
+	return errs
+}
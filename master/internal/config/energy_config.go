@@ -0,0 +1,45 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+// defaultCarbonIntensity is the world average carbon intensity of electricity, in grams of CO2
+// equivalent per kWh.
+const defaultCarbonIntensity = 475
+
+// EnergyConfig configures estimating the emissions of the energy that GPUs use.
+type EnergyConfig struct {
+	// CarbonIntensity is the grams of CO2 equivalent emitted per kWh of electricity.
+	CarbonIntensity float64 `json:"carbon_intensity"`
+	// ResourcePoolCarbonIntensities override CarbonIntensity for resource pools on other grids.
+	ResourcePoolCarbonIntensities map[string]float64 `json:"resource_pool_carbon_intensities"`
+}
+
+// DefaultEnergyConfig returns the default energy configuration.
+func DefaultEnergyConfig() EnergyConfig {
+	return EnergyConfig{CarbonIntensity: defaultCarbonIntensity}
+}
+
+// CarbonIntensityOf returns the carbon intensity of the electricity of a resource pool.
+func (c EnergyConfig) CarbonIntensityOf(resourcePool string) float64 {
+	if intensity, ok := c.ResourcePoolCarbonIntensities[resourcePool]; ok {
+		return intensity
+	}
+	return c.CarbonIntensity
+}
+
+// Validate implements the check.Validatable interface.
+func (c EnergyConfig) Validate() []error {
+	var errs []error
+	if c.CarbonIntensity < 0 {
+		errs = append(errs, errors.New("energy.carbon_intensity must not be negative"))
+	}
+	for name, intensity := range c.ResourcePoolCarbonIntensities {
+		if intensity < 0 {
+			errs = append(errs, errors.Errorf(
+				"energy.resource_pool_carbon_intensities.%s must not be negative", name))
+		}
+	}
+	return errs
+}
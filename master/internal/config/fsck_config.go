@@ -0,0 +1,33 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	// FsckReport logs inconsistent state found at startup without changing it.
+	FsckReport = "report"
+	// FsckRepair repairs inconsistent state found at startup.
+	FsckRepair = "repair"
+)
+
+// FsckConfig configures checking the database for inconsistent state, such as trials and tasks
+// left open by a crash, when the master starts.
+type FsckConfig struct {
+	// OnStartup is FsckReport or FsckRepair to check the database when the master starts, before it
+	// restores experiments; empty skips the check.
+	OnStartup string `json:"on_startup"`
+	// CheckStorage is whether to look for the files of every completed checkpoint in storage.
+	CheckStorage bool `json:"check_storage"`
+}
+
+// Validate implements the check.Validatable interface.
+func (c FsckConfig) Validate() []error {
+	switch c.OnStartup {
+	case "", FsckReport, FsckRepair:
+		return nil
+	default:
+		return []error{errors.Errorf(
+			"fsck.on_startup must be empty, %q, or %q", FsckReport, FsckRepair)}
+	}
+}
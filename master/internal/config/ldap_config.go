@@ -0,0 +1,101 @@
+package config
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// LDAPConfig configures logging in with the password of an account in an LDAP directory, such as
+// Active Directory.
+type LDAPConfig struct {
+	Enabled bool `json:"enabled"`
+	// URL is the ldap:// or ldaps:// URL of the directory server.
+	URL string `json:"url"`
+	// StartTLS upgrades ldap:// connections to TLS before sending credentials.
+	StartTLS bool `json:"start_tls"`
+	// TLSRootCert is the path to the PEM bundle of the certificate authorities that are trusted
+	// for the server certificate, instead of those of the system.
+	TLSRootCert string `json:"tls_root_cert"`
+	// BindDN and BindPassword are the credentials of the account that searches the directory.
+	// Searches are anonymous if BindDN is empty.
+	BindDN       string `json:"bind_dn"`
+	BindPassword string `json:"bind_password"`
+	// Users are looked up under UserSearchBaseDN by their UsernameAttribute.
+	UserSearchBaseDN     string `json:"user_search_base_dn"`
+	UserObjectClass      string `json:"user_object_class"`
+	UsernameAttribute    string `json:"username_attribute"`
+	DisplayNameAttribute string `json:"display_name_attribute"`
+	// Groups that list the user in GroupMemberAttribute are looked up under GroupSearchBaseDN and
+	// named by GroupNameAttribute. Group memberships are not synchronized if it is empty.
+	GroupSearchBaseDN    string `json:"group_search_base_dn"`
+	GroupObjectClass     string `json:"group_object_class"`
+	GroupMemberAttribute string `json:"group_member_attribute"`
+	GroupNameAttribute   string `json:"group_name_attribute"`
+	// GroupMappings maps the groups of the directory to the names of groups in the cluster. When
+	// it is empty, groups are matched by name.
+	GroupMappings map[string]string `json:"group_mappings"`
+	// AutoProvisionUsers creates users that log in for the first time.
+	AutoProvisionUsers bool           `json:"auto_provision_users"`
+	Timeout            model.Duration `json:"timeout"`
+}
+
+// DefaultLDAPConfig returns the default LDAP config, which suits OpenLDAP directories.
+func DefaultLDAPConfig() LDAPConfig {
+	return LDAPConfig{
+		UserObjectClass:      "person",
+		UsernameAttribute:    "uid",
+		DisplayNameAttribute: "displayName",
+		GroupObjectClass:     "groupOfNames",
+		GroupMemberAttribute: "member",
+		GroupNameAttribute:   "cn",
+		Timeout:              model.Duration(10 * time.Second),
+	}
+}
+
+// Validate implements the check.Validatable interface.
+func (c LDAPConfig) Validate() []error {
+	if !c.Enabled {
+		return nil
+	}
+	var errs []error
+	u, err := url.Parse(c.URL)
+	switch {
+	case err != nil || u.Host == "":
+		errs = append(errs, errors.New("ldap url must be an absolute URL"))
+	case u.Scheme != "ldap" && u.Scheme != "ldaps":
+		errs = append(errs, errors.New("ldap url must use the ldap or ldaps scheme"))
+	case u.Scheme == "ldaps" && c.StartTLS:
+		errs = append(errs, errors.New("ldap start_tls cannot be used with an ldaps url"))
+	}
+	if c.BindDN != "" && c.BindPassword == "" {
+		errs = append(errs, errors.New("ldap bind_password must be provided with bind_dn"))
+	}
+	for name, value := range map[string]string{
+		"user_search_base_dn": c.UserSearchBaseDN,
+		"user_object_class":   c.UserObjectClass,
+		"username_attribute":  c.UsernameAttribute,
+	} {
+		if value == "" {
+			errs = append(errs, errors.Errorf("ldap %s must be provided", name))
+		}
+	}
+	if c.GroupSearchBaseDN != "" {
+		for name, value := range map[string]string{
+			"group_object_class":     c.GroupObjectClass,
+			"group_member_attribute": c.GroupMemberAttribute,
+			"group_name_attribute":   c.GroupNameAttribute,
+		} {
+			if value == "" {
+				errs = append(errs, errors.Errorf("ldap %s must be provided", name))
+			}
+		}
+	}
+	if time.Duration(c.Timeout) <= 0 {
+		errs = append(errs, errors.New("ldap timeout must be positive"))
+	}
+	return errs
+}
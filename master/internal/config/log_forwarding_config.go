@@ -0,0 +1,77 @@
+package config
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/determined-ai/determined/master/pkg/check"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+const (
+	// LogForwarderSplunkHEC forwards task logs to a Splunk HTTP Event Collector.
+	LogForwarderSplunkHEC = "splunk_hec"
+	// LogForwarderDatadog forwards task logs to the Datadog logs intake API.
+	LogForwarderDatadog = "datadog"
+	// LogForwarderHTTP forwards task logs as a JSON array of task logs to any HTTP endpoint.
+	LogForwarderHTTP = "http"
+
+	defaultLogForwarderBatchSize     = 500
+	defaultLogForwarderFlushInterval = model.Duration(time.Second)
+	defaultLogForwarderMaxRetries    = 5
+	defaultLogForwarderBufferSize    = 10000
+)
+
+// LogForwarderConfig configures an HTTP endpoint that task logs are forwarded to, in addition to
+// being stored by the logging backend.
+type LogForwarderConfig struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+	// Token authenticates to the endpoint, as a Splunk HEC token, a Datadog API key, or a bearer
+	// token for plain HTTP endpoints.
+	Token   string            `json:"token"`
+	Headers map[string]string `json:"headers"`
+	// Index is the Splunk index to write to; it defaults to the default index of the token.
+	Index string `json:"index"`
+	// Tags are Datadog tags added to every log.
+	Tags []string `json:"tags"`
+
+	BatchSize     int            `json:"batch_size"`
+	FlushInterval model.Duration `json:"flush_interval"`
+	MaxRetries    int            `json:"max_retries"`
+	// BufferSize is how many logs may wait to be forwarded before new logs are dropped, so that a
+	// slow or unavailable endpoint cannot hold up storing logs.
+	BufferSize int `json:"buffer_size"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *LogForwarderConfig) UnmarshalJSON(data []byte) error {
+	c.BatchSize = defaultLogForwarderBatchSize
+	c.FlushInterval = defaultLogForwarderFlushInterval
+	c.MaxRetries = defaultLogForwarderMaxRetries
+	c.BufferSize = defaultLogForwarderBufferSize
+
+	type DefaultParser *LogForwarderConfig
+	return json.Unmarshal(data, DefaultParser(c))
+}
+
+// Validate implements the check.Validatable interface.
+func (c LogForwarderConfig) Validate() []error {
+	u, err := url.Parse(c.URL)
+	return []error{
+		check.In(c.Type, []string{LogForwarderSplunkHEC, LogForwarderDatadog, LogForwarderHTTP},
+			"log_forwarders.type must be one of splunk_hec, datadog, or http"),
+		check.True(c.URL != "" && err == nil && (u.Scheme == "http" || u.Scheme == "https"),
+			"log_forwarders.url must be an http or https URL"),
+		check.True(c.Type == LogForwarderHTTP || c.Token != "",
+			"log_forwarders.token must be set for splunk_hec and datadog"),
+		check.GreaterThan(c.BatchSize, 0, "log_forwarders.batch_size must be positive"),
+		check.GreaterThan(int64(c.FlushInterval), int64(0),
+			"log_forwarders.flush_interval must be positive"),
+		check.GreaterThanOrEqualTo(c.MaxRetries, 0,
+			"log_forwarders.max_retries must not be negative"),
+		check.GreaterThanOrEqualTo(c.BufferSize, c.BatchSize,
+			"log_forwarders.buffer_size must be at least batch_size"),
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+)
+
+// LogRetentionConfig configures deleting the logs of trials some time after they end.
+type LogRetentionConfig struct {
+	// Days is how long after a trial ends its logs are kept, in workspaces that do not set their
+	// own retention; 0 keeps them forever.
+	Days int `json:"days"`
+	// Archive is whether logs are written to storage before they are deleted.
+	Archive bool `json:"archive"`
+	// Storage is where logs are archived, instead of the checkpoint storage of the master.
+	Storage *expconf.CheckpointStorageConfig `json:"storage"`
+	// OffPeakStartHour and OffPeakEndHour bound the hours of the day, in UTC, during which logs
+	// are deleted; the window wraps around midnight if it ends before it starts, and spans the
+	// whole day if they are equal.
+	OffPeakStartHour int `json:"off_peak_start_hour"`
+	OffPeakEndHour   int `json:"off_peak_end_hour"`
+}
+
+// OffPeak returns whether logs may be deleted at the time.
+func (c LogRetentionConfig) OffPeak(t time.Time) bool {
+	h := t.UTC().Hour()
+	switch start, end := c.OffPeakStartHour, c.OffPeakEndHour; {
+	case start == end:
+		return true
+	case start < end:
+		return start <= h && h < end
+	default:
+		return h >= start || h < end
+	}
+}
+
+// Validate implements the check.Validatable interface.
+func (c LogRetentionConfig) Validate() []error {
+	var errs []error
+	if c.Days < 0 {
+		errs = append(errs, errors.New("log_retention.days must not be negative"))
+	}
+	if c.OffPeakStartHour < 0 || c.OffPeakStartHour > 23 ||
+		c.OffPeakEndHour < 0 || c.OffPeakEndHour > 23 {
+		errs = append(errs, errors.New(
+			"log_retention.off_peak_start_hour and log_retention.off_peak_end_hour must be "+
+				"between 0 and 23"))
+	}
+	if c.Storage != nil {
+		switch c.Storage.GetUnionMember().(type) {
+		case expconf.S3Config, expconf.SharedFSConfig:
+		default:
+			errs = append(errs, errors.New("log_retention.storage must be s3 or shared_fs storage"))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// OIDCConfig configures single sign-on through an OpenID Connect provider.
+type OIDCConfig struct {
+	Enabled bool `json:"enabled"`
+	// Provider is the name of the provider shown to users.
+	Provider string `json:"provider"`
+	// IDPSSOURL is the issuer URL of the provider, where its discovery document is found.
+	IDPSSOURL string `json:"idp_sso_url"`
+	// IDPRecipientURL is the URL of the master that the provider redirects users back to.
+	IDPRecipientURL string `json:"idp_recipient_url"`
+	ClientID        string `json:"client_id"`
+	ClientSecret    string `json:"client_secret"`
+	// AuthenticationClaim is the claim of the ID token that holds the username.
+	AuthenticationClaim string `json:"authentication_claim"`
+	// Scopes are requested in addition to openid.
+	Scopes []string `json:"scopes"`
+	// AutoProvisionUsers creates users that log in for the first time.
+	AutoProvisionUsers bool `json:"auto_provision_users"`
+	// GroupsClaimName is the claim of the ID token that lists the groups of the user, if any.
+	GroupsClaimName string `json:"groups_claim_name"`
+	// GroupMappings maps the groups of the provider to the names of groups in the cluster. When
+	// it is empty, groups are matched by name.
+	GroupMappings map[string]string `json:"group_mappings"`
+}
+
+// DefaultOIDCConfig returns the default OIDC config.
+func DefaultOIDCConfig() OIDCConfig {
+	return OIDCConfig{
+		Provider:            "OIDC",
+		AuthenticationClaim: "email",
+		Scopes:              []string{"profile", "email"},
+	}
+}
+
+// Validate implements the check.Validatable interface.
+func (c OIDCConfig) Validate() []error {
+	if !c.Enabled {
+		return nil
+	}
+	var errs []error
+	for name, value := range map[string]string{
+		"idp_sso_url":       c.IDPSSOURL,
+		"idp_recipient_url": c.IDPRecipientURL,
+	} {
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, errors.Errorf("oidc %s must be an absolute URL", name))
+		}
+	}
+	if c.ClientID == "" {
+		errs = append(errs, errors.New("oidc client_id must be provided"))
+	}
+	if c.AuthenticationClaim == "" {
+		errs = append(errs, errors.New("oidc authentication_claim must be provided"))
+	}
+	return errs
+}
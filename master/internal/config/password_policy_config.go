@@ -0,0 +1,81 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// DefaultSessionDuration is how long sessions are valid by default.
+const DefaultSessionDuration = 7 * 24 * time.Hour
+
+// PasswordPolicyConfig configures the rules for the passwords of users who log in with a password
+// in the cluster.
+type PasswordPolicyConfig struct {
+	// MinLength is the minimum number of characters in a password.
+	MinLength        int  `json:"min_length"`
+	RequireUppercase bool `json:"require_uppercase"`
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireDigit     bool `json:"require_digit"`
+	RequireSymbol    bool `json:"require_symbol"`
+	// MaxAge is how long a password can be used before it must be changed. Passwords do not
+	// expire if it is zero.
+	MaxAge model.Duration `json:"max_age"`
+}
+
+// ComplexityRequired returns whether new passwords are checked against complexity rules, which
+// requires clients to send them unhashed.
+func (c PasswordPolicyConfig) ComplexityRequired() bool {
+	return c.MinLength > 0 || c.RequireUppercase || c.RequireLowercase || c.RequireDigit ||
+		c.RequireSymbol
+}
+
+// Validate implements the check.Validatable interface.
+func (c PasswordPolicyConfig) Validate() []error {
+	var errs []error
+	if c.MinLength < 0 {
+		errs = append(errs, errors.New("password_policy.min_length cannot be negative"))
+	}
+	if c.MaxAge < 0 {
+		errs = append(errs, errors.New("password_policy.max_age cannot be negative"))
+	}
+	return errs
+}
+
+// LockoutConfig configures locking users who log in with a password in the cluster out after
+// failed logins, which throttles guessing their password.
+type LockoutConfig struct {
+	// MaxFailedAttempts is how many failed logins in a row lock a user out. Users are never
+	// locked out if it is zero.
+	MaxFailedAttempts int `json:"max_failed_attempts"`
+	// Duration is how long a user is locked out for.
+	Duration model.Duration `json:"duration"`
+}
+
+// Validate implements the check.Validatable interface.
+func (c LockoutConfig) Validate() []error {
+	var errs []error
+	if c.MaxFailedAttempts < 0 {
+		errs = append(errs, errors.New("lockout.max_failed_attempts cannot be negative"))
+	}
+	if c.MaxFailedAttempts > 0 && c.Duration <= 0 {
+		errs = append(errs, errors.New("lockout.duration must be positive"))
+	}
+	return errs
+}
+
+// SessionConfig configures the sessions users get when they log in.
+type SessionConfig struct {
+	// MaxDuration is how long a session is valid for, after which the user must log in again.
+	MaxDuration model.Duration `json:"max_duration"`
+}
+
+// Validate implements the check.Validatable interface.
+func (c SessionConfig) Validate() []error {
+	if c.MaxDuration <= 0 {
+		return []error{errors.New("session.max_duration must be positive")}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// PreflightConfig configures the checks of the external services that the master and its tasks
+// depend on, such as checkpoint storage and container registries, which the master runs when it
+// starts and admins can run through the API.
+type PreflightConfig struct {
+	// Enabled runs the checks when the master starts.
+	Enabled bool `json:"enabled"`
+	// FailOnError stops the master from starting if any check fails, rather than only logging
+	// the failures.
+	FailOnError bool `json:"fail_on_error"`
+	// Timeout bounds how long each check may take.
+	Timeout model.Duration `json:"timeout"`
+}
+
+// DefaultPreflightConfig returns the default preflight configuration.
+func DefaultPreflightConfig() PreflightConfig {
+	return PreflightConfig{
+		Enabled:     true,
+		FailOnError: true,
+		Timeout:     model.Duration(30 * time.Second),
+	}
+}
+
+// Validate implements the check.Validatable interface.
+func (c PreflightConfig) Validate() []error {
+	if c.Timeout <= 0 {
+		return []error{errors.New("preflight.timeout must be positive")}
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package provconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg"
+	"github.com/determined-ai/determined/master/pkg/check"
+	"github.com/determined-ai/determined/master/pkg/device"
+)
+
+// SpotMaxPriceOnDemand is the Azure spot max price that caps the price at the on-demand price
+// rather than a fixed amount.
+const SpotMaxPriceOnDemand = -1
+
+const azureMetadataURL = "http://169.254.169.254/metadata/instance?api-version=2021-02-01"
+
+// AzureClusterConfig describes the configuration for an Azure cluster managed by Determined. Agents
+// run as instances of a virtual machine scale set that is created on demand.
+type AzureClusterConfig struct {
+	SubscriptionID string `json:"subscription_id"`
+	ResourceGroup  string `json:"resource_group"`
+	Location       string `json:"location"`
+
+	// The credentials of a service principal. If ClientSecret is not set, the managed identity of
+	// the master's VM is used, and ClientID optionally selects a user-assigned identity.
+	TenantID     string `json:"tenant_id"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+
+	ScaleSetName string `json:"scale_set_name"`
+	TagKey       string `json:"tag_key"`
+	TagValue     string `json:"tag_value"`
+
+	Image      azureImage `json:"image"`
+	OSDiskSize int        `json:"os_disk_size"`
+
+	AdminUsername string `json:"admin_username"`
+	SSHPublicKey  string `json:"ssh_public_key"`
+
+	SubnetID string `json:"subnet_id"`
+	PublicIP bool   `json:"public_ip"`
+
+	InstanceType azureInstanceType `json:"instance_type"`
+
+	SpotEnabled  bool    `json:"spot"`
+	SpotMaxPrice float64 `json:"spot_max_price"`
+
+	CPUSlotsAllowed bool `json:"cpu_slots_allowed"`
+}
+
+// DefaultAzureClusterConfig returns the default configuration of the Azure cluster.
+func DefaultAzureClusterConfig() *AzureClusterConfig {
+	return &AzureClusterConfig{
+		TagKey: "managed-by",
+		Image: azureImage{
+			Publisher: "microsoft-dsvm",
+			Offer:     "ubuntu-hpc",
+			SKU:       "2004",
+			Version:   "latest",
+		},
+		OSDiskSize:    200,
+		AdminUsername: "determined",
+		InstanceType: azureInstanceType{
+			VMSize: "Standard_NC24s_v3",
+			GPUNum: 4,
+		},
+		SpotMaxPrice:    SpotMaxPriceOnDemand,
+		CPUSlotsAllowed: false,
+	}
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *AzureClusterConfig) UnmarshalJSON(data []byte) error {
+	*c = *DefaultAzureClusterConfig()
+	type DefaultParser *AzureClusterConfig
+	return json.Unmarshal(data, DefaultParser(c))
+}
+
+// Validate implements the check.Validatable interface.
+func (c AzureClusterConfig) Validate() []error {
+	return []error{
+		check.GreaterThanOrEqualTo(c.OSDiskSize, 100, "azure VM os disk size must be >= 100"),
+		check.NotEmpty(c.SSHPublicKey, "azure ssh public key must be non-empty"),
+		check.NotEmpty(c.SubnetID, "azure subnet id must be non-empty"),
+		check.NotEmpty(c.AdminUsername, "azure admin username must be non-empty"),
+		check.True(c.SpotMaxPrice == SpotMaxPriceOnDemand || c.SpotMaxPrice > 0,
+			"azure spot max price must be -1 or greater than 0"),
+		check.True(c.ClientSecret == "" || (c.TenantID != "" && c.ClientID != ""),
+			"azure tenant id and client id must be set to use a client secret"),
+	}
+}
+
+// InitDefaultValues init default values.
+func (c *AzureClusterConfig) InitDefaultValues() error {
+	// One common reason that fetching the instance metadata fails is that the master is not
+	// running in Azure, in which case the location of the resources must be configured.
+	metadata, err := getAzureMetadata()
+	if err != nil && (c.SubscriptionID == "" || c.ResourceGroup == "" || c.Location == "") {
+		return errors.Wrap(err,
+			"subscription_id, resource_group, and location must be set outside of Azure")
+	}
+
+	identifier := pkg.DeterminedIdentifier
+	if metadata != nil {
+		if c.SubscriptionID == "" {
+			c.SubscriptionID = metadata.Compute.SubscriptionID
+		}
+		if c.ResourceGroup == "" {
+			c.ResourceGroup = metadata.Compute.ResourceGroupName
+		}
+		if c.Location == "" {
+			c.Location = metadata.Compute.Location
+		}
+		identifier = metadata.Compute.Name
+	}
+
+	if len(c.TagValue) == 0 {
+		c.TagValue = identifier
+	}
+	return nil
+}
+
+// SlotsPerInstance returns the number of slots per instance.
+func (c AzureClusterConfig) SlotsPerInstance() int {
+	slots := c.InstanceType.Slots()
+	if slots == 0 && c.CPUSlotsAllowed {
+		slots = 1
+	}
+
+	return slots
+}
+
+// SlotType returns the type of the slot.
+func (c AzureClusterConfig) SlotType() device.Type {
+	slots := c.InstanceType.Slots()
+	if slots > 0 {
+		return device.CUDA
+	}
+	if c.CPUSlotsAllowed {
+		return device.CPU
+	}
+	return device.ZeroSlot
+}
+
+// Accelerator returns the GPU accelerator for the instance.
+func (c AzureClusterConfig) Accelerator() string {
+	return fmt.Sprintf("%d x GPU (%s)", c.InstanceType.GPUNum, c.InstanceType.VMSize)
+}
+
+// ImageID returns a description of the image the agent instances boot from.
+func (c AzureClusterConfig) ImageID() string {
+	if c.Image.ID != "" {
+		return c.Image.ID
+	}
+	return fmt.Sprintf("%s:%s:%s:%s", c.Image.Publisher, c.Image.Offer, c.Image.SKU, c.Image.Version)
+}
+
+// azureImage is either the resource ID of a custom image or a marketplace image reference.
+type azureImage struct {
+	ID        string `json:"id,omitempty"`
+	Publisher string `json:"publisher,omitempty"`
+	Offer     string `json:"offer,omitempty"`
+	SKU       string `json:"sku,omitempty"`
+	Version   string `json:"version,omitempty"`
+}
+
+func (i azureImage) Validate() []error {
+	return []error{
+		check.True(i.ID != "" || (i.Publisher != "" && i.Offer != "" && i.SKU != ""),
+			"azure image must set either id or publisher, offer, and sku"),
+	}
+}
+
+type azureInstanceType struct {
+	VMSize string `json:"vm_size"`
+	GPUNum int    `json:"gpu_num"`
+}
+
+func (t azureInstanceType) Name() string {
+	return t.VMSize
+}
+
+func (t azureInstanceType) Slots() int {
+	return t.GPUNum
+}
+
+func (t azureInstanceType) Validate() []error {
+	return []error{
+		check.NotEmpty(t.VMSize, "azure vm size must be non-empty"),
+		check.GreaterThanOrEqualTo(t.GPUNum, 0, "azure gpu num must be >= 0"),
+	}
+}
+
+// azureMetadata is the subset of the Azure instance metadata that Determined uses.
+type azureMetadata struct {
+	Compute struct {
+		Name              string `json:"name"`
+		Location          string `json:"location"`
+		ResourceGroupName string `json:"resourceGroupName"`
+		SubscriptionID    string `json:"subscriptionId"`
+	} `json:"compute"`
+	Network struct {
+		Interface []struct {
+			IPv4 struct {
+				IPAddress []struct {
+					PrivateIPAddress string `json:"privateIpAddress"`
+					PublicIPAddress  string `json:"publicIpAddress"`
+				} `json:"ipAddress"`
+			} `json:"ipv4"`
+		} `json:"interface"`
+	} `json:"network"`
+}
+
+func getAzureMetadata() (*azureMetadata, error) {
+	req, err := http.NewRequest(http.MethodGet, azureMetadataURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata", "true")
+
+	client := http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot reach Azure instance metadata service")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("Azure instance metadata service returned %s", resp.Status)
+	}
+
+	var metadata azureMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, errors.Wrap(err, "cannot parse Azure instance metadata")
+	}
+	return &metadata, nil
+}
+
+func getAzureIP(public bool) (string, error) {
+	metadata, err := getAzureMetadata()
+	if err != nil {
+		return "", err
+	}
+	for _, iface := range metadata.Network.Interface {
+		for _, addr := range iface.IPv4.IPAddress {
+			if public && addr.PublicIPAddress != "" {
+				return addr.PublicIPAddress, nil
+			}
+			if !public && addr.PrivateIPAddress != "" {
+				return addr.PrivateIPAddress, nil
+			}
+		}
+	}
+	return "", errors.New("no IP address found in Azure instance metadata")
+}
+
+func onAzure() bool {
+	_, err := getAzureMetadata()
+	return err == nil
+}
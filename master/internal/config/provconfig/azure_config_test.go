@@ -0,0 +1,55 @@
+package provconfig
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/determined-ai/determined/master/pkg/check"
+)
+
+func TestUnmarshalAzureClusterConfig(t *testing.T) {
+	raw := `
+{
+	"subscription_id": "test-subscription",
+	"resource_group": "test-group",
+	"location": "eastus",
+	"ssh_public_key": "ssh-rsa AAAA",
+	"subnet_id": "test-subnet",
+	"instance_type": {
+		"vm_size": "Standard_NC6s_v3",
+		"gpu_num": 1
+	},
+	"spot": true,
+	"spot_max_price": 0.5
+}`
+	expected := *DefaultAzureClusterConfig()
+	expected.SubscriptionID = "test-subscription"
+	expected.ResourceGroup = "test-group"
+	expected.Location = "eastus"
+	expected.SSHPublicKey = "ssh-rsa AAAA"
+	expected.SubnetID = "test-subnet"
+	expected.InstanceType = azureInstanceType{VMSize: "Standard_NC6s_v3", GPUNum: 1}
+	expected.SpotEnabled = true
+	expected.SpotMaxPrice = 0.5
+
+	var config AzureClusterConfig
+	err := json.Unmarshal([]byte(raw), &config)
+	assert.NilError(t, err)
+	err = check.Validate(&config)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, config, expected)
+	assert.Equal(t, config.SlotsPerInstance(), 1)
+	assert.Equal(t, config.ImageID(), "microsoft-dsvm:ubuntu-hpc:2004:latest")
+}
+
+func TestAzureClusterConfigMissingFields(t *testing.T) {
+	var config AzureClusterConfig
+	err := json.Unmarshal([]byte(`{"spot_max_price": 0}`), &config)
+	assert.NilError(t, err)
+	err = check.Validate(&config)
+	assert.ErrorContains(t, err, "azure ssh public key must be non-empty")
+	assert.ErrorContains(t, err, "azure subnet id must be non-empty")
+	assert.ErrorContains(t, err, "azure spot max price must be -1 or greater than 0")
+}
@@ -20,23 +20,24 @@ const defaultMasterPort = "8080"
 
 // Config describes config for provisioner.
 type Config struct {
-	MasterURL               string            `json:"master_url"`
-	MasterCertName          string            `json:"master_cert_name"`
-	StartupScript           string            `json:"startup_script"`
-	ContainerStartupScript  string            `json:"container_startup_script"`
-	AgentDockerNetwork      string            `json:"agent_docker_network"`
-	AgentDockerRuntime      string            `json:"agent_docker_runtime"`
-	AgentDockerImage        string            `json:"agent_docker_image"`
-	AgentFluentImage        string            `json:"agent_fluent_image"`
-	AgentReconnectAttempts  int               `json:"agent_reconnect_attempts"`
-	AgentReconnectBackoff   int               `json:"agent_reconnect_backoff"`
-	AgentConfigFileContents json.RawMessage   `json:"agent_config_file_contents"`
-	AWS                     *AWSClusterConfig `union:"type,aws" json:"-"`
-	GCP                     *GCPClusterConfig `union:"type,gcp" json:"-"`
-	MaxIdleAgentPeriod      model.Duration    `json:"max_idle_agent_period"`
-	MaxAgentStartingPeriod  model.Duration    `json:"max_agent_starting_period"`
-	MinInstances            int               `json:"min_instances"`
-	MaxInstances            int               `json:"max_instances"`
+	MasterURL               string              `json:"master_url"`
+	MasterCertName          string              `json:"master_cert_name"`
+	StartupScript           string              `json:"startup_script"`
+	ContainerStartupScript  string              `json:"container_startup_script"`
+	AgentDockerNetwork      string              `json:"agent_docker_network"`
+	AgentDockerRuntime      string              `json:"agent_docker_runtime"`
+	AgentDockerImage        string              `json:"agent_docker_image"`
+	AgentFluentImage        string              `json:"agent_fluent_image"`
+	AgentReconnectAttempts  int                 `json:"agent_reconnect_attempts"`
+	AgentReconnectBackoff   int                 `json:"agent_reconnect_backoff"`
+	AgentConfigFileContents json.RawMessage     `json:"agent_config_file_contents"`
+	AWS                     *AWSClusterConfig   `union:"type,aws" json:"-"`
+	GCP                     *GCPClusterConfig   `union:"type,gcp" json:"-"`
+	Azure                   *AzureClusterConfig `union:"type,azure" json:"-"`
+	MaxIdleAgentPeriod      model.Duration      `json:"max_idle_agent_period"`
+	MaxAgentStartingPeriod  model.Duration      `json:"max_agent_starting_period"`
+	MinInstances            int                 `json:"min_instances"`
+	MaxInstances            int                 `json:"max_instances"`
 }
 
 // DefaultConfig returns the default configuration of the provisioner.
@@ -84,11 +85,17 @@ func (c Config) Validate() []error {
 		errs = append(errs, check.In(masterURL.Scheme, []string{"http", "https"},
 			"master url scheme must be within [http, https]"))
 	}
+	numClusters := 0
+	for _, configured := range []bool{c.AWS != nil, c.GCP != nil, c.Azure != nil} {
+		if configured {
+			numClusters++
+		}
+	}
 	errs = append(errs, []error{
 		masterURLErr,
 		check.NotEmpty(c.AgentDockerImage, "must configure an agent docker image"),
-		check.False(c.AWS != nil && c.GCP != nil, "must configure only one cluster"),
-		check.False(c.AWS == nil && c.GCP == nil, "must configure aws or gcp cluster"),
+		check.LessThanOrEqualTo(numClusters, 1, "must configure only one cluster"),
+		check.GreaterThan(numClusters, 0, "must configure aws, gcp, or azure cluster"),
 		check.GreaterThan(
 			int64(c.MaxIdleAgentPeriod), int64(0), "max idle agent period must be greater than 0"),
 		check.GreaterThan(
@@ -133,6 +140,10 @@ func (c *Config) InitMasterAddress() error {
 		host, err = getEC2Metadata("local-hostname")
 	case host == "public-hostname" && onEC2():
 		host, err = getEC2Metadata("public-hostname")
+	case (host == "internal-ip" || host == "") && c.Azure != nil && onAzure():
+		host, err = getAzureIP(false)
+	case host == "external-ip" && c.Azure != nil && onAzure():
+		host, err = getAzureIP(true)
 	}
 	if err != nil {
 		return errors.Wrap(err, "cannot get metadata")
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// QueryCacheConfig configures caching the results of the expensive queries behind the pages that
+// the WebUI polls, such as the experiment list, so that many open browser tabs do not each run
+// them against the database.
+type QueryCacheConfig struct {
+	// TTL is how long a result is reused; 0 disables the cache.
+	TTL model.Duration `json:"ttl"`
+}
+
+// DefaultQueryCacheConfig returns the default query cache configuration.
+func DefaultQueryCacheConfig() QueryCacheConfig {
+	return QueryCacheConfig{TTL: model.Duration(5 * time.Second)}
+}
+
+// Validate implements the check.Validatable interface.
+func (c QueryCacheConfig) Validate() []error {
+	if c.TTL < 0 {
+		return []error{errors.New("query_cache.ttl must not be negative")}
+	}
+	return nil
+}
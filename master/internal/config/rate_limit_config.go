@@ -0,0 +1,51 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+// RateLimitConfig configures how many API requests each user can make. Requests are limited
+// separately for each class of endpoint, so that a script polling for results does not keep the
+// same user from making changes.
+type RateLimitConfig struct {
+	Enabled bool `json:"enabled"`
+	// Read limits requests that only read, such as GET requests.
+	Read RateLimitRule `json:"read"`
+	// Write limits all other requests.
+	Write RateLimitRule `json:"write"`
+}
+
+// RateLimitRule is a token bucket: it refills at RequestsPerSecond and holds up to Burst requests.
+type RateLimitRule struct {
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	Burst             int     `json:"burst"`
+}
+
+// DefaultRateLimitConfig returns the default rate limits, which are off.
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Read:  RateLimitRule{RequestsPerSecond: 50, Burst: 100},
+		Write: RateLimitRule{RequestsPerSecond: 10, Burst: 20},
+	}
+}
+
+// Validate implements the check.Validatable interface.
+func (c RateLimitConfig) Validate() []error {
+	if !c.Enabled {
+		return nil
+	}
+	var errs []error
+	for _, r := range []struct {
+		class string
+		rule  RateLimitRule
+	}{{"read", c.Read}, {"write", c.Write}} {
+		if r.rule.RequestsPerSecond <= 0 {
+			errs = append(errs, errors.Errorf(
+				"rate_limit.%s.requests_per_second must be positive", r.class))
+		}
+		if r.rule.Burst < 1 {
+			errs = append(errs, errors.Errorf("rate_limit.%s.burst must be at least 1", r.class))
+		}
+	}
+	return errs
+}
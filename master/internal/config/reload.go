@@ -0,0 +1,138 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ReloadResult describes the settings that changed when the master configuration was reloaded.
+type ReloadResult struct {
+	// Reloaded are the settings that changed and took effect.
+	Reloaded []string
+	// RequireRestart are the settings that changed but only take effect once the master restarts.
+	RequireRestart []string
+}
+
+var reloadMu sync.Mutex
+
+// Reload applies the settings of next that can change while the master runs to the master config:
+// logging, webhooks, OIDC, LDAP, and some of the settings of each resource pool. The master is
+// responsible for acting on them. Other changes are reported and left for the next restart.
+func Reload(next *Config) (*ReloadResult, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	cur := GetMasterConfig()
+
+	changed, err := changedKeys(cur, next)
+	if err != nil {
+		return nil, err
+	}
+	var result ReloadResult
+	for _, key := range changed {
+		switch key {
+		case "log":
+			cur.Log = next.Log
+		case "webhooks":
+			cur.Webhooks = next.Webhooks
+		case "oidc":
+			cur.OIDC = next.OIDC
+		case "ldap":
+			cur.LDAP = next.LDAP
+		case "resource_pools":
+			if err := reloadResourcePools(cur.ResourcePools, next.ResourcePools, &result); err != nil {
+				return nil, err
+			}
+			continue
+		default:
+			result.RequireRestart = append(result.RequireRestart, key)
+			continue
+		}
+		result.Reloaded = append(result.Reloaded, key)
+	}
+	return &result, nil
+}
+
+// reloadResourcePools applies the settings of pools that can change while they run. Adding,
+// removing, or reordering pools requires a restart.
+func reloadResourcePools(cur, next []ResourcePoolConfig, result *ReloadResult) error {
+	if len(cur) != len(next) {
+		result.RequireRestart = append(result.RequireRestart, "resource_pools")
+		return nil
+	}
+	for i := range cur {
+		if cur[i].PoolName != next[i].PoolName {
+			result.RequireRestart = append(result.RequireRestart, "resource_pools")
+			return nil
+		}
+	}
+
+	for i := range cur {
+		changed, err := changedKeys(cur[i], next[i])
+		if err != nil {
+			return err
+		}
+		for _, key := range changed {
+			name := fmt.Sprintf("resource_pools.%s.%s", cur[i].PoolName, key)
+			switch key {
+			case "description":
+				cur[i].Description = next[i].Description
+			case "max_aux_containers_per_agent":
+				cur[i].MaxAuxContainersPerAgent = next[i].MaxAuxContainersPerAgent
+			case "task_container_defaults":
+				cur[i].TaskContainerDefaults = next[i].TaskContainerDefaults
+			case "agent_reconnect_wait":
+				cur[i].AgentReconnectWait = next[i].AgentReconnectWait
+			case "scheduler":
+				// The jobs in the pool are queued for the type of scheduler it has, so only its
+				// settings can change.
+				if cur[i].Scheduler == nil || next[i].Scheduler == nil ||
+					cur[i].Scheduler.GetType() != next[i].Scheduler.GetType() {
+					result.RequireRestart = append(result.RequireRestart, name)
+					continue
+				}
+				cur[i].Scheduler = next[i].Scheduler
+			default:
+				result.RequireRestart = append(result.RequireRestart, name)
+				continue
+			}
+			result.Reloaded = append(result.Reloaded, name)
+		}
+	}
+	return nil
+}
+
+// changedKeys returns the top-level keys whose values differ between the JSON forms of a and b.
+func changedKeys(a, b interface{}) ([]string, error) {
+	var aKeys, bKeys map[string]json.RawMessage
+	for _, v := range []struct {
+		in  interface{}
+		out *map[string]json.RawMessage
+	}{{a, &aKeys}, {b, &bKeys}} {
+		bs, err := json.Marshal(v.in)
+		if err != nil {
+			return nil, errors.Wrap(err, "comparing configurations")
+		}
+		if err := json.Unmarshal(bs, v.out); err != nil {
+			return nil, errors.Wrap(err, "comparing configurations")
+		}
+	}
+
+	var changed []string
+	for key, value := range aKeys {
+		if other, ok := bKeys[key]; !ok || !bytes.Equal(value, other) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range bKeys {
+		if _, ok := aKeys[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
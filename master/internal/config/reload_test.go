@@ -0,0 +1,90 @@
+//nolint:exhaustivestruct
+package config
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"gotest.tools/assert"
+)
+
+func TestReload(t *testing.T) {
+	load := func(raw string) *Config {
+		c := DefaultConfig()
+		assert.NilError(t, yaml.Unmarshal([]byte(raw), c, yaml.DisallowUnknownFields))
+		assert.NilError(t, c.Resolve())
+		return c
+	}
+	const base = `
+log:
+  level: info
+port: 8080
+webhooks:
+  signing_key: key
+resource_pools:
+  - pool_name: default
+    description: old
+    scheduler:
+      type: priority
+      default_priority: 42
+  - pool_name: aux
+    scheduler:
+      type: fair_share
+`
+
+	cur := GetMasterConfig()
+	defer func(saved Config) { *cur = saved }(*cur)
+	*cur = *load(base)
+
+	result, err := Reload(load(base))
+	assert.NilError(t, err)
+	assert.Equal(t, len(result.Reloaded), 0)
+	assert.Equal(t, len(result.RequireRestart), 0)
+
+	result, err = Reload(load(`
+log:
+  level: debug
+port: 9090
+webhooks:
+  signing_key: key
+  base_url: http://example.com
+resource_pools:
+  - pool_name: default
+    description: new
+    scheduler:
+      type: priority
+      default_priority: 10
+  - pool_name: aux
+    agent_reattach_enabled: true
+    scheduler:
+      type: round_robin
+`))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, result.Reloaded, []string{
+		"log",
+		"resource_pools.default.description",
+		"resource_pools.default.scheduler",
+		"webhooks",
+	})
+	assert.DeepEqual(t, result.RequireRestart, []string{
+		"port",
+		"resource_pools.aux.agent_reattach_enabled",
+		"resource_pools.aux.scheduler",
+	})
+
+	assert.Equal(t, cur.Log.Level, "debug")
+	assert.Equal(t, cur.Port, 8080)
+	assert.Equal(t, cur.Webhooks.BaseURL, "http://example.com")
+	assert.Equal(t, cur.ResourcePools[0].Description, "new")
+	assert.Equal(t, *cur.ResourcePools[0].Scheduler.Priority.DefaultPriority, 10)
+	assert.Equal(t, cur.ResourcePools[1].AgentReattachEnabled, false)
+	assert.Equal(t, cur.ResourcePools[1].Scheduler.GetType(), FairShareScheduling)
+
+	result, err = Reload(load(`
+resource_pools:
+  - pool_name: default
+`))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, result.RequireRestart, []string{"resource_pools"})
+	assert.Equal(t, len(cur.ResourcePools), 2)
+}
@@ -19,11 +19,15 @@ type ResourceConfig struct {
 func (r *ResourceConfig) ResolveResource() error {
 	if r.ResourceManager == nil {
 		r.ResourceManager = &ResourceManagerConfig{
-			AgentRM: &AgentResourceManagerConfig{},
+			AgentRM: &AgentResourceManagerConfig{
+				PoolStatsRetention: DefaultPoolStatsRetention,
+			},
 		}
 	}
 	if r.ResourceManager.AgentRM == nil && r.ResourceManager.KubernetesRM == nil {
-		r.ResourceManager.AgentRM = &AgentResourceManagerConfig{}
+		r.ResourceManager.AgentRM = &AgentResourceManagerConfig{
+			PoolStatsRetention: DefaultPoolStatsRetention,
+		}
 	}
 	if r.ResourceManager.AgentRM != nil && r.ResourcePools == nil {
 		defaultPool := defaultRPConfig()
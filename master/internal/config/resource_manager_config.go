@@ -2,17 +2,23 @@ package config
 
 import (
 	"encoding/json"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/determined-ai/determined/master/internal/rm/kubernetes"
 	"github.com/determined-ai/determined/master/pkg/check"
 	"github.com/determined-ai/determined/master/pkg/device"
+	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/master/pkg/union"
 )
 
 const defaultResourcePoolName = "default"
 
+// DefaultPoolStatsRetention is how long the recorded stats of resource pools are kept by default.
+const DefaultPoolStatsRetention = model.Duration(30 * 24 * time.Hour)
+
 // ResourceManagerConfig hosts configuration fields for the resource manager.
 type ResourceManagerConfig struct {
 	AgentRM      *AgentResourceManagerConfig      `union:"type,agent" json:"-"`
@@ -43,6 +49,7 @@ func (r *ResourceManagerConfig) UnmarshalJSON(data []byte) error {
 			},
 			DefaultComputeResourcePool: defaultResourcePoolName,
 			DefaultAuxResourcePool:     defaultResourcePoolName,
+			PoolStatsRetention:         DefaultPoolStatsRetention,
 		}
 	}
 	return nil
@@ -60,6 +67,12 @@ type AgentResourceManagerConfig struct {
 
 	RequireAuthentication bool   `json:"require_authentication"`
 	ClientCA              string `json:"client_ca"`
+	// VerifyAgentID requires the certificate of each agent to be issued to its ID, as its common
+	// name or one of its DNS names, so that agents cannot connect as each other.
+	VerifyAgentID bool `json:"verify_agent_id"`
+	// PoolStatsRetention is how long the recorded queue and utilization stats of resource pools
+	// are kept.
+	PoolStatsRetention model.Duration `json:"pool_stats_retention"`
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
@@ -82,6 +95,9 @@ func (a *AgentResourceManagerConfig) UnmarshalJSON(data []byte) error {
 	if a.DefaultAuxResourcePool == "" {
 		a.DefaultAuxResourcePool = defaultResourcePoolName
 	}
+	if a.PoolStatsRetention == 0 {
+		a.PoolStatsRetention = DefaultPoolStatsRetention
+	}
 	a.DefaultCPUResourcePool = ""
 	a.DefaultGPUResourcePool = ""
 
@@ -93,6 +109,9 @@ func (a AgentResourceManagerConfig) Validate() []error {
 	return []error{
 		check.NotEmpty(a.DefaultAuxResourcePool, "default_aux_resource_pool should be non-empty"),
 		check.NotEmpty(a.DefaultComputeResourcePool, "default_compute_resource_pool should be non-empty"),
+		check.True(!a.VerifyAgentID || a.RequireAuthentication,
+			"verify_agent_id requires require_authentication"),
+		check.True(a.PoolStatsRetention > 0, "pool_stats_retention must be positive"),
 	}
 }
 
@@ -105,7 +124,12 @@ type KubernetesResourceManagerConfig struct {
 	DefaultScheduler         string                             `json:"default_scheduler"`
 	SlotType                 device.Type                        `json:"slot_type"`
 	SlotResourceRequests     kubernetes.PodSlotResourceRequests `json:"slot_resource_requests"`
+	SlotResourceName         string                             `json:"slot_resource_name,omitempty"`
 	Fluent                   kubernetes.FluentConfig            `json:"fluent"`
+	GangStart                *GangStartConfig                   `json:"gang_start,omitempty"`
+	QueueIntegration         *kubernetes.QueueIntegrationConfig `json:"queue_integration,omitempty"`
+	ExperimentCRDs           *ExperimentCRDConfig               `json:"experiment_crds,omitempty"`
+	WorkspaceQuotas          map[string]int                     `json:"workspace_quotas,omitempty"`
 	CredsDir                 string                             `json:"_creds_dir,omitempty"`
 	MasterIP                 string                             `json:"_master_ip,omitempty"`
 	MasterPort               int32                              `json:"_master_port,omitempty"`
@@ -149,9 +173,42 @@ func (k KubernetesResourceManagerConfig) Validate() []error {
 		checkCPUResource = check.GreaterThan(
 			k.SlotResourceRequests.CPU, float32(0), "slot_resource_requests.cpu must be > 0")
 	}
-	return []error{
+	var checkSlotResourceName error
+	if k.SlotResourceName != "" {
+		checkSlotResourceName = check.True(
+			k.SlotType == device.CUDA && strings.Contains(k.SlotResourceName, "/"),
+			"slot_resource_name must be an extended resource, like amd.com/gpu, with slot_type cuda")
+	}
+	var checkQueueIntegration error
+	if k.QueueIntegration != nil {
+		checkQueueIntegration = check.Equal(k.DefaultScheduler, "",
+			"default_scheduler can't be set when pods are submitted through queue_integration")
+	}
+	errs := []error{
 		check.GreaterThanOrEqualTo(k.MaxSlotsPerPod, 0, "max_slots_per_pod must be >= 0"),
 		checkSlotType,
 		checkCPUResource,
+		checkSlotResourceName,
+		checkQueueIntegration,
+	}
+	for workspace, slots := range k.WorkspaceQuotas {
+		errs = append(errs, check.GreaterThanOrEqualTo(slots, 0,
+			"workspace_quotas of workspace %s must be >= 0", workspace))
+	}
+	return errs
+}
+
+// ExperimentCRDConfig configures the submission of experiments from Experiment custom resources.
+type ExperimentCRDConfig struct {
+	// Username is the user that experiments are submitted as.
+	Username string `json:"username"`
+	// Namespace is watched for resources; defaults to the namespace of the resource manager.
+	Namespace string `json:"namespace"`
+}
+
+// Validate implements the check.Validatable interface.
+func (e ExperimentCRDConfig) Validate() []error {
+	return []error{
+		check.NotEmpty(e.Username, "experiment_crds.username must be set"),
 	}
 }
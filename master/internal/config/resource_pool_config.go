@@ -16,6 +16,10 @@ func defaultRPConfig() ResourcePoolConfig {
 		MaxCPUContainersPerAgent: -1,
 		AgentReconnectWait:       model.Duration(aproto.AgentReconnectWait),
 		AgentReattachEnabled:     false,
+		AgentHeartbeatInterval:   model.Duration(aproto.AgentHeartbeatInterval),
+		ContainerRuntime:         aproto.ContainerRuntimeDocker,
+
+		AgentHeartbeatMissTolerance: aproto.AgentHeartbeatMissTolerance,
 	}
 }
 
@@ -33,6 +37,16 @@ type ResourcePoolConfig struct {
 	// AgentReconnectWait define the time master will wait for agent
 	// before abandoning it.
 	AgentReconnectWait model.Duration `json:"agent_reconnect_wait"`
+	// AgentHeartbeatInterval is how often the master pings agents to check that they are still
+	// connected.
+	AgentHeartbeatInterval model.Duration `json:"agent_heartbeat_interval"`
+	// AgentHeartbeatMissTolerance is how many heartbeat intervals an agent may go without
+	// answering a ping before its connection is considered lost.
+	AgentHeartbeatMissTolerance int `json:"agent_heartbeat_miss_tolerance"`
+	// ContainerRuntime is the runtime agents in the pool run task containers with.
+	ContainerRuntime string `json:"container_runtime"`
+	// CPUSlots configures the slots of agents in the pool that have no GPUs.
+	CPUSlots model.CPUSlotsConfig `json:"cpu_slots"`
 
 	// Deprecated: Use MaxAuxContainersPerAgent instead.
 	MaxCPUContainersPerAgent int `json:"max_cpu_containers_per_agent,omitempty"`
@@ -61,5 +75,16 @@ func (r ResourcePoolConfig) Validate() []error {
 		check.True(len(r.PoolName) != 0, "resource pool name cannot be empty"),
 		check.True(r.MaxAuxContainersPerAgent >= 0,
 			"resource pool max cpu containers per agent should be >= 0"),
+		check.True(r.AgentReconnectWait >= 0, "resource pool agent reconnect wait should be >= 0"),
+		check.True(r.AgentHeartbeatInterval > 0,
+			"resource pool agent heartbeat interval should be > 0"),
+		check.True(r.AgentHeartbeatMissTolerance >= 1,
+			"resource pool agent heartbeat miss tolerance should be >= 1"),
+		check.In(r.ContainerRuntime, []string{
+			aproto.ContainerRuntimeDocker,
+			aproto.ContainerRuntimeApptainer,
+			aproto.ContainerRuntimeEnroot,
+			aproto.ContainerRuntimePodman,
+		}, "resource pool container runtime"),
 	}
 }
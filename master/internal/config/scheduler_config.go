@@ -22,6 +22,13 @@ const (
 	best             = "best"
 	worst            = "worst"
 	defaultFitPolicy = best
+
+	// GangStartPolicyRequeue releases all of an allocation's resources and requeues it when not
+	// all of its resources start within the gang start timeout.
+	GangStartPolicyRequeue = "requeue"
+	// GangStartPolicyDegraded starts an allocation with only the resources that started within
+	// the gang start timeout, releasing the rest.
+	GangStartPolicyDegraded = "degraded"
 )
 
 // DefaultSchedulerConfig returns the default fair share configuration for the scheduler.
@@ -38,6 +45,7 @@ type SchedulerConfig struct {
 	Priority      *PrioritySchedulerConfig   `union:"type,priority" json:"-"`
 	RoundRobin    *RoundRobinSchedulerConfig `union:"type,round_robin" json:"-"`
 	FittingPolicy string                     `json:"fitting_policy"`
+	GangStart     *GangStartConfig           `json:"gang_start,omitempty"`
 }
 
 // MarshalJSON implements the json.Marshaler interface.
@@ -113,8 +121,59 @@ type FairShareSchedulerConfig struct{}
 
 // PrioritySchedulerConfig holds the configurations for the priority scheduler.
 type PrioritySchedulerConfig struct {
-	Preemption      bool `json:"preemption"`
-	DefaultPriority *int `json:"default_priority"`
+	Preemption      bool                 `json:"preemption"`
+	DefaultPriority *int                 `json:"default_priority"`
+	Aging           *PriorityAgingConfig `json:"aging,omitempty"`
+	// Backfill lets tasks that declared an expected runtime start ahead of a blocked
+	// higher-priority task, as long as they will be done before it could start anyway.
+	Backfill bool `json:"backfill"`
+}
+
+// PriorityAgingConfig configures raising the priority of pending tasks the longer they wait, so
+// that a steady stream of higher-priority work can't starve them.
+type PriorityAgingConfig struct {
+	// Interval is how long a task must wait for its priority to be raised by one.
+	Interval model.Duration `json:"interval"`
+	// MaxBoost caps how far a task's priority can be raised; 0 means no cap.
+	MaxBoost int `json:"max_boost"`
+}
+
+// Validate implements the check.Validatable interface.
+func (a PriorityAgingConfig) Validate() []error {
+	return []error{
+		check.True(a.Interval > 0, "aging.interval must be > 0"),
+		check.GreaterThanOrEqualTo(a.MaxBoost, 0, "aging.max_boost must be >= 0"),
+	}
+}
+
+// GangStartConfig configures how long a multi-container allocation may hold a partial set of
+// started resources while waiting for the rest, and what to do once that time runs out.
+type GangStartConfig struct {
+	Timeout model.Duration `json:"timeout"`
+	Policy  string         `json:"policy"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (g *GangStartConfig) UnmarshalJSON(data []byte) error {
+	type DefaultParser *GangStartConfig
+	if err := json.Unmarshal(data, DefaultParser(g)); err != nil {
+		return err
+	}
+	if g.Policy == "" {
+		g.Policy = GangStartPolicyRequeue
+	}
+	return nil
+}
+
+// Validate implements the check.Validatable interface.
+func (g GangStartConfig) Validate() []error {
+	return []error{
+		check.GreaterThan(int64(g.Timeout), int64(0), "gang_start.timeout must be > 0"),
+		check.Contains(
+			g.Policy, []interface{}{GangStartPolicyRequeue, GangStartPolicyDegraded},
+			"invalid gang start policy",
+		),
+	}
 }
 
 // RoundRobinSchedulerConfig holds the configurations for the round robing scheduler.
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	// ScimAuthBasic authenticates identity providers with a username and password.
+	ScimAuthBasic = "basic"
+	// ScimAuthToken authenticates identity providers with a bearer token.
+	ScimAuthToken = "token"
+)
+
+// ScimConfig configures the SCIM endpoints that identity providers provision users and groups
+// through.
+type ScimConfig struct {
+	Enabled bool           `json:"enabled"`
+	Auth    ScimAuthConfig `json:"auth"`
+}
+
+// ScimAuthConfig is how identity providers authenticate to the SCIM endpoints.
+type ScimAuthConfig struct {
+	Type     string `json:"type"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
+// Validate implements the check.Validatable interface.
+func (c ScimConfig) Validate() []error {
+	if !c.Enabled {
+		return nil
+	}
+	switch c.Auth.Type {
+	case ScimAuthBasic:
+		if c.Auth.Username == "" || c.Auth.Password == "" {
+			return []error{errors.New("scim basic auth requires a username and password")}
+		}
+	case ScimAuthToken:
+		if c.Auth.Token == "" {
+			return []error{errors.New("scim token auth requires a token")}
+		}
+	default:
+		return []error{errors.Errorf("scim auth type must be %q or %q, got %q",
+			ScimAuthBasic, ScimAuthToken, c.Auth.Type)}
+	}
+	return nil
+}
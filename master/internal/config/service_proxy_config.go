@@ -0,0 +1,37 @@
+package config
+
+import (
+	"time"
+
+	"github.com/determined-ai/determined/master/pkg/check"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// ServiceProxyConfig configures how the master proxies connections to notebooks, TensorBoards,
+// shells, and other services running in the cluster.
+type ServiceProxyConfig struct {
+	// KeepaliveInterval is how often proxied websocket connections are pinged on both ends; a
+	// connection that goes two intervals without an answer is closed.
+	KeepaliveInterval model.Duration `json:"keepalive_interval"`
+	// ReconnectTimeout is how long a request keeps trying to reach its service, covering brief
+	// network problems and services that are still being restored after the master restarts.
+	ReconnectTimeout model.Duration `json:"reconnect_timeout"`
+}
+
+// DefaultServiceProxyConfig returns the default service proxy configuration.
+func DefaultServiceProxyConfig() ServiceProxyConfig {
+	return ServiceProxyConfig{
+		KeepaliveInterval: model.Duration(30 * time.Second),
+		ReconnectTimeout:  model.Duration(30 * time.Second),
+	}
+}
+
+// Validate implements the check.Validatable interface.
+func (c ServiceProxyConfig) Validate() []error {
+	return []error{
+		check.GreaterThanOrEqualTo(int64(c.KeepaliveInterval), int64(0),
+			"service_proxy.keepalive_interval must not be negative"),
+		check.GreaterThanOrEqualTo(int64(c.ReconnectTimeout), int64(0),
+			"service_proxy.reconnect_timeout must not be negative"),
+	}
+}
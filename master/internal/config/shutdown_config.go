@@ -0,0 +1,39 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// ShutdownConfig configures how the master shuts down when it receives SIGTERM or SIGINT.
+type ShutdownConfig struct {
+	// Timeout bounds how long each step of shutting down waits, such as for experiments to persist
+	// their state or for agents to be told of the restart.
+	Timeout model.Duration `json:"timeout"`
+	// AgentReconnectWait is how long agents keep trying to reconnect to the master after it tells
+	// them that it is restarting, rather than giving up after their usual reconnect attempts.
+	AgentReconnectWait model.Duration `json:"agent_reconnect_wait"`
+}
+
+// DefaultShutdownConfig returns the default shutdown configuration.
+func DefaultShutdownConfig() ShutdownConfig {
+	return ShutdownConfig{
+		Timeout:            model.Duration(30 * time.Second),
+		AgentReconnectWait: model.Duration(5 * time.Minute),
+	}
+}
+
+// Validate implements the check.Validatable interface.
+func (c ShutdownConfig) Validate() []error {
+	var errs []error
+	if c.Timeout <= 0 {
+		errs = append(errs, errors.New("shutdown.timeout must be positive"))
+	}
+	if c.AgentReconnectWait < 0 {
+		errs = append(errs, errors.New("shutdown.agent_reconnect_wait must not be negative"))
+	}
+	return errs
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// SystemMetricsConfig configures collecting the CPU, memory, GPU, and network usage of the
+// containers of every task.
+type SystemMetricsConfig struct {
+	// ReportPeriod is how often the usage of each container is measured; 0 turns collection off.
+	ReportPeriod model.Duration `json:"report_period"`
+	// RetentionDays is how long measurements are kept; 0 keeps them forever.
+	RetentionDays int `json:"retention_days"`
+}
+
+// DefaultSystemMetricsConfig returns the default system metrics configuration.
+func DefaultSystemMetricsConfig() SystemMetricsConfig {
+	return SystemMetricsConfig{
+		ReportPeriod:  model.Duration(10 * time.Second),
+		RetentionDays: 30,
+	}
+}
+
+// Retention returns how long measurements are kept, or 0 if they are kept forever.
+func (c SystemMetricsConfig) Retention() time.Duration {
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+// Validate implements the check.Validatable interface.
+func (c SystemMetricsConfig) Validate() []error {
+	var errs []error
+	if c.ReportPeriod < 0 {
+		errs = append(errs, errors.New("system_metrics.report_period must not be negative"))
+	} else if c.ReportPeriod > 0 && time.Duration(c.ReportPeriod) < time.Second {
+		errs = append(errs, errors.New("system_metrics.report_period must be at least 1s"))
+	}
+	if c.RetentionDays < 0 {
+		errs = append(errs, errors.New("system_metrics.retention_days must not be negative"))
+	}
+	return errs
+}
@@ -32,6 +32,12 @@ func (c *DetContext) GetUsername() string {
 	return "unknown"
 }
 
+// GetUser returns the user for the relevant echo request context, if one has been set.
+func (c *DetContext) GetUser() (model.User, bool) {
+	user, ok := c.Get("user").(model.User)
+	return user, ok
+}
+
 // MustGetUser returns the user for the relevant echo request context. Panics if the user has not
 // been set, so this method should only be used inside handlers that _require_ authentication.
 func (c *DetContext) MustGetUser() model.User {
@@ -4,12 +4,10 @@ import (
 	"bufio"
 	"context"
 	"crypto/tls"
-	"crypto/x509"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/pprof"
@@ -36,21 +34,36 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/determined-ai/determined/master/internal/api"
+	"github.com/determined-ai/determined/master/internal/auditlog"
+	"github.com/determined-ai/determined/master/internal/autotls"
+	"github.com/determined-ai/determined/master/internal/backup"
+	"github.com/determined-ai/determined/master/internal/certwatch"
+	"github.com/determined-ai/determined/master/internal/cloudwatch"
 	"github.com/determined-ai/determined/master/internal/cluster"
+	"github.com/determined-ai/determined/master/internal/coldarchive"
 	"github.com/determined-ai/determined/master/internal/command"
 	"github.com/determined-ai/determined/master/internal/config"
 	"github.com/determined-ai/determined/master/internal/connsave"
 	detContext "github.com/determined-ai/determined/master/internal/context"
 	"github.com/determined-ai/determined/master/internal/db"
 	"github.com/determined-ai/determined/master/internal/elastic"
+	"github.com/determined-ai/determined/master/internal/fsck"
 	"github.com/determined-ai/determined/master/internal/grpcutil"
+	"github.com/determined-ai/determined/master/internal/health"
 	"github.com/determined-ai/determined/master/internal/hpimportance"
 	"github.com/determined-ai/determined/master/internal/job"
+	"github.com/determined-ai/determined/master/internal/logforward"
+	"github.com/determined-ai/determined/master/internal/logretention"
+	"github.com/determined-ai/determined/master/internal/maintenance"
+	"github.com/determined-ai/determined/master/internal/plugin/scim"
 	"github.com/determined-ai/determined/master/internal/plugin/sso"
 	"github.com/determined-ai/determined/master/internal/prom"
 	"github.com/determined-ai/determined/master/internal/proxy"
+	"github.com/determined-ai/determined/master/internal/querycache"
+	"github.com/determined-ai/determined/master/internal/ratelimit"
 	"github.com/determined-ai/determined/master/internal/rm"
 	"github.com/determined-ai/determined/master/internal/rm/allocationmap"
+	"github.com/determined-ai/determined/master/internal/schedules"
 	"github.com/determined-ai/determined/master/internal/sproto"
 	"github.com/determined-ai/determined/master/internal/task"
 	"github.com/determined-ai/determined/master/internal/task/taskmodel"
@@ -96,6 +109,8 @@ type Master struct {
 
 	config   *config.Config
 	taskSpec *tasks.TaskSpec
+	// loadConfig reads the configuration again for reloading it, if that is supported.
+	loadConfig func() (*config.Config, error)
 
 	logs          *logger.LogBuffer
 	system        *actor.System
@@ -105,19 +120,30 @@ type Master struct {
 	rm            rm.ResourceManager
 	proxy         *actor.Ref
 	taskLogger    *task.Logger
+	taskLogs      *task.LogNotifier
 	hpImportance  *actor.Ref
+	rateLimiter   *ratelimit.Limiter
+	queryCache    *querycache.Cache
+	// getCertificate provides the serving certificate as it is renewed or rotated.
+	getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)
 
 	trialLogBackend TrialLogBackend
 	taskLogBackend  task.LogBackend
+	// logBackendCheck checks that the backend task logs are written to can be reached.
+	logBackendCheck health.Check
 }
 
-// New creates an instance of the Determined master.
-func New(logStore *logger.LogBuffer, config *config.Config) *Master {
+// New creates an instance of the Determined master. loadConfig, if not nil, reads the
+// configuration again when it is reloaded.
+func New(
+	logStore *logger.LogBuffer, config *config.Config, loadConfig func() (*config.Config, error),
+) *Master {
 	logger.SetLogrus(config.Log)
 	return &Master{
-		MasterID: uuid.New().String(),
-		logs:     logStore,
-		config:   config,
+		MasterID:   uuid.New().String(),
+		logs:       logStore,
+		config:     config,
+		loadConfig: loadConfig,
 	}
 }
 
@@ -130,8 +156,8 @@ func (m *Master) getTaskContainerDefaults(poolName string) model.TaskContainerDe
 	// Always fall back to the top-level TaskContainerDefaults
 	taskContainerDefaults := m.config.TaskContainerDefaults
 
-	// Only look for pool settings with Agent resource managers.
-	if m.config.ResourceManager.AgentRM != nil {
+	// Only look for pool settings with Agent and Kubernetes resource managers.
+	if m.config.ResourceManager.AgentRM != nil || m.config.ResourceManager.KubernetesRM != nil {
 		// Iterate through configured pools looking for a TaskContainerDefaults setting.
 		for _, pool := range m.config.ResourcePools {
 			if poolName == pool.PoolName {
@@ -498,39 +524,45 @@ func (m *Master) startServers(ctx context.Context, cert *tls.Certificate) error
 
 	// If configured, set up TLS wrapping.
 	if cert != nil {
-		var clientCAs *x509.CertPool
-		clientAuthMode := tls.NoClientCert
+		tlsConf := &tls.Config{
+			// The HTTP and gRPC servers share this listener, so both serve the certificate as it
+			// changes.
+			GetCertificate:           m.getCertificate,
+			MinVersion:               tls.VersionTLS12,
+			PreferServerCipherSuites: true,
+			ClientAuth:               tls.NoClientCert,
+		}
 
 		if agentRM := m.config.ResourceManager.AgentRM; agentRM != nil && agentRM.RequireAuthentication {
 			// Most connections don't require client certificates, but we do want to make sure that any that
 			// are provided are valid, so individual handlers that care can just check for the presence of
 			// certificates.
-			clientAuthMode = tls.VerifyClientCertIfGiven
+			tlsConf.ClientAuth = tls.VerifyClientCertIfGiven
 
 			if agentRM.ClientCA != "" {
-				clientCAs = x509.NewCertPool()
-				clientRootCA, iErr := ioutil.ReadFile(agentRM.ClientCA)
-				if iErr != nil {
-					return errors.Wrap(err, "failed to read agent CA file")
+				clientCAs, cErr := certwatch.NewPool(agentRM.ClientCA)
+				if cErr != nil {
+					return errors.Wrap(cErr, "failed to read agent CA file")
+				}
+				go clientCAs.Run(ctx)
+				// Verify agents against the CA as it is rotated.
+				tlsConf.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+					conf := tlsConf.Clone()
+					conf.GetConfigForClient = nil
+					conf.ClientCAs = clientCAs.Pool()
+					return conf, nil
 				}
-				clientCAs.AppendCertsFromPEM(clientRootCA)
 			}
 		}
 
-		baseListener = tls.NewListener(baseListener, &tls.Config{
-			Certificates:             []tls.Certificate{*cert},
-			MinVersion:               tls.VersionTLS12,
-			PreferServerCipherSuites: true,
-			ClientCAs:                clientCAs,
-			ClientAuth:               clientAuthMode,
-		})
+		baseListener = tls.NewListener(baseListener, tlsConf)
 	}
 
 	// This must be before grpcutil.RegisterHTTPProxy is called since it may use stuff set up by the
 	// gRPC server (logger initialization, maybe more). Found by --race.
 	gRPCServer := grpcutil.NewGRPCServer(m.db, &apiServer{m: m},
 		m.config.Observability.EnablePrometheus,
-		&m.config.InternalConfig.ExternalSessions)
+		&m.config.InternalConfig.ExternalSessions, m.rateLimiter, m.queryCache)
 
 	err = grpcutil.RegisterHTTPProxy(ctx, m.echo, m.config.Port, cert)
 	if err != nil {
@@ -735,6 +767,7 @@ func (m *Master) postTaskLogs(c echo.Context) (interface{}, error) {
 }
 
 // Run causes the Determined master to connect the database and begin listening for HTTP requests.
+// Canceling ctx shuts the master down gracefully, after which Run returns nil.
 func (m *Master) Run(ctx context.Context) error {
 	log.Infof("Determined master %s (built with %s)", version.Version, runtime.Version())
 
@@ -754,15 +787,45 @@ func (m *Master) Run(ctx context.Context) error {
 	if err != nil {
 		return errors.Wrap(err, "could not fetch cluster id from database")
 	}
+	if m.config.Fsck.OnStartup != "" {
+		problems, fErr := fsck.Run(ctx, fsck.Options{
+			Repair:       m.config.Fsck.OnStartup == config.FsckRepair,
+			CheckStorage: m.config.Fsck.CheckStorage,
+		})
+		if fErr != nil {
+			return errors.Wrap(fErr, "could not check the database for inconsistencies")
+		}
+		log.Infof("found %d inconsistencies in the database", len(problems))
+		fsck.Log(problems)
+	}
 	cert, err := m.config.Security.TLS.ReadCertificate()
 	if err != nil {
 		return errors.Wrap(err, "failed to read TLS certificate")
 	}
+	switch tlsConfig := m.config.Security.TLS; {
+	case tlsConfig.ACME != nil:
+		autoTLS := autotls.New(*tlsConfig.ACME)
+		if cert, err = autoTLS.Start(ctx); err != nil {
+			return errors.Wrap(err, "failed to obtain TLS certificate through ACME")
+		}
+		m.getCertificate = autoTLS.GetCertificate
+	case cert != nil:
+		watcher := certwatch.New(tlsConfig.Cert, tlsConfig.Key, cert)
+		go watcher.Run(ctx)
+		m.getCertificate = watcher.GetCertificate
+	}
+	var masterCert func() *tls.Certificate
+	if m.getCertificate != nil {
+		masterCert = func() *tls.Certificate {
+			cert, _ := m.getCertificate(nil)
+			return cert
+		}
+	}
 	m.taskSpec = &tasks.TaskSpec{
 		ClusterID:             m.ClusterID,
 		HarnessPath:           filepath.Join(m.config.Root, "wheels"),
 		TaskContainerDefaults: m.config.TaskContainerDefaults,
-		MasterCert:            cert,
+		MasterCert:            masterCert,
 		SSHRsaSize:            m.config.Security.SSH.RsaKeySize,
 		SegmentEnabled:        m.config.Telemetry.Enabled && m.config.Telemetry.SegmentMasterKey != "",
 		SegmentAPIKey:         m.config.Telemetry.SegmentMasterKey,
@@ -791,7 +854,10 @@ func (m *Master) Run(ctx context.Context) error {
 	//             +- Websocket (actors.WebSocket: <remote-address>)
 	m.system = actor.NewSystemWithRoot("master", actor.ActorFunc(root))
 
-	ctx, cancel := context.WithCancel(ctx)
+	// Shutting down gracefully needs the servers and the background work to keep running until it
+	// is done, so they stop with their own context rather than the one that signals a shutdown.
+	shutdown := ctx.Done()
+	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
 		sErr := m.system.Ref.AwaitTermination()
 		log.WithError(sErr).Error("actor system exited")
@@ -802,6 +868,7 @@ func (m *Master) Run(ctx context.Context) error {
 	case m.config.Logging.DefaultLoggingConfig != nil:
 		m.trialLogBackend = m.db
 		m.taskLogBackend = m.db
+		m.logBackendCheck = health.Check{Kind: "postgres", Run: pingTaskLogs}
 	case m.config.Logging.ElasticLoggingConfig != nil:
 		es, eErr := elastic.Setup(*m.config.Logging.ElasticLoggingConfig)
 		if eErr != nil {
@@ -809,16 +876,41 @@ func (m *Master) Run(ctx context.Context) error {
 		}
 		m.trialLogBackend = es
 		m.taskLogBackend = es
+		m.logBackendCheck = health.Check{Kind: "elastic", Run: es.Ping}
+	case m.config.Logging.CloudWatchLoggingConfig != nil:
+		cw, cErr := cloudwatch.Setup(*m.config.Logging.CloudWatchLoggingConfig)
+		if cErr != nil {
+			return cErr
+		}
+		// Only task logs are written to CloudWatch; the logs of trials from before task logs
+		// existed stay in Postgres.
+		m.trialLogBackend = m.db
+		m.taskLogBackend = cw
+		m.logBackendCheck = health.Check{Kind: "cloudwatch", Run: cw.Ping}
 	default:
 		panic("unsupported logging backend")
 	}
+	if m.config.Preflight.Enabled {
+		if err = m.runPreflightChecks(ctx); err != nil {
+			return err
+		}
+	}
+	if len(m.config.LogForwarders) > 0 {
+		fwd := logforward.New(m.taskLogBackend, m.config.LogForwarders)
+		defer closeWithErrCheck("log-forwarding", fwd)
+		m.taskLogBackend = fwd
+	}
+	m.taskLogs = task.NewLogNotifier(m.taskLogBackend)
+	m.taskLogBackend = task.NewMeteredLogBackend(m.taskLogs)
 	m.taskLogger = task.NewLogger(m.system, m.taskLogBackend)
 
 	user.InitService(m.db, m.system, &m.config.InternalConfig.ExternalSessions)
 	userService := user.GetService()
 
 	m.proxy, _ = m.system.ActorOf(actor.Addr("proxy"), &proxy.Proxy{
-		HTTPAuth: userService.ProcessProxyAuthentication,
+		HTTPAuth:          userService.ProcessProxyAuthentication,
+		KeepaliveInterval: time.Duration(m.config.ServiceProxy.KeepaliveInterval),
+		ReconnectTimeout:  time.Duration(m.config.ServiceProxy.ReconnectTimeout),
 	})
 
 	allocationmap.InitAllocationMap()
@@ -832,6 +924,9 @@ func (m *Master) Run(ctx context.Context) error {
 
 	// Initialize the HTTP server and listen for incoming requests.
 	m.echo = echo.New()
+	// Requests are attributed to the address they came from, since clients can send whatever
+	// X-Forwarded-For and X-Real-IP headers they like.
+	m.echo.IPExtractor = echo.ExtractIPDirect()
 	m.echo.Use(middleware.Recover())
 
 	gzipConfig := middleware.GzipConfig{
@@ -862,6 +957,9 @@ func (m *Master) Run(ctx context.Context) error {
 	}
 	m.echo.Use(middleware.SecureWithConfig(secureConfig))
 
+	// Give each request an ID with which to correlate its logs.
+	m.echo.Use(logger.RequestIDMiddleware)
+
 	// Register middleware that extends default context.
 	m.echo.Use(func(h echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -882,6 +980,9 @@ func (m *Master) Run(ctx context.Context) error {
 	}
 
 	m.echo.Use(userService.ProcessAuthentication)
+	m.rateLimiter = ratelimit.New(m.config.RateLimit)
+	m.echo.Use(ratelimit.Middleware(m.rateLimiter))
+	m.queryCache = querycache.New(m.config.QueryCache)
 
 	m.echo.Logger = logger.New()
 	m.echo.HideBanner = true
@@ -895,6 +996,12 @@ func (m *Master) Run(ctx context.Context) error {
 		return errors.Wrap(err, "could not update end stats for instances")
 	}
 
+	// Before RM start, so that work is held back from the first scheduling pass if the cluster was
+	// left in maintenance mode.
+	if err = maintenance.Load(ctx); err != nil {
+		return errors.Wrap(err, "could not load maintenance mode")
+	}
+
 	// Resource Manager.
 	m.rm = rm.New(
 		m.system,
@@ -902,8 +1009,9 @@ func (m *Master) Run(ctx context.Context) error {
 		m.echo,
 		m.config.ResourceConfig,
 		&aproto.MasterSetAgentOptions{
-			MasterInfo:     m.Info(),
-			LoggingOptions: m.config.Logging,
+			MasterInfo:          m.Info(),
+			LoggingOptions:      m.config.Logging,
+			SystemMetricsPeriod: m.config.SystemMetrics.ReportPeriod,
 		},
 		cert,
 	)
@@ -945,11 +1053,36 @@ func (m *Master) Run(ctx context.Context) error {
 		return err
 	}
 
+	if err = backup.FailInterrupted(ctx); err != nil {
+		return errors.Wrap(err, "failed to mark interrupted database backups as failed")
+	}
+
 	// The below function call is intentionally made after the call to CloseOpenAllocations.
 	// This ensures that in the scenario where a cluster fails all open allocations are
 	// set to the last cluster heartbeat when the cluster was running.
 	cluster.InitTheLastBootClusterHeartbeat()
 	go updateClusterHeartbeat(ctx, m.db)
+	go auditlog.PruneLoop(ctx, m.config.AuditLog.Retention())
+	go db.PruneSystemMetricsLoop(ctx, m.config.SystemMetrics.Retention())
+	coldArchiveStorage := m.config.CheckpointStorage
+	if m.config.ColdArchive.Storage != nil {
+		coldArchiveStorage = *m.config.ColdArchive.Storage
+	}
+	go coldarchive.ArchiveLoop(ctx, m.config.ColdArchive.After(), coldArchiveStorage)
+	logRetentionStorage := m.config.CheckpointStorage
+	if m.config.LogRetention.Storage != nil {
+		logRetentionStorage = *m.config.LogRetention.Storage
+	}
+	go logretention.Loop(ctx, m.config.LogRetention, logRetentionStorage)
+	go schedules.Loop(ctx, m.submitScheduledExperiment)
+	if err = m.startExperimentCRDController(
+		ctx, m.config.ResourceManager.KubernetesRM); err != nil {
+		return err
+	}
+	go db.MetricsPartitionsLoop(ctx, m.config.DB.TrialsPerMetricsPartition)
+	go db.MonitorReplicaLag(ctx)
+	go db.MonitorPool(ctx)
+	go m.reloadConfigOnSIGHUP(ctx)
 
 	// Docs and WebUI.
 	webuiRoot := filepath.Join(m.config.Root, "webui")
@@ -1016,18 +1149,23 @@ func (m *Master) Run(ctx context.Context) error {
 
 	m.echo.GET("/config", api.Route(m.getConfig))
 	m.echo.GET("/info", api.Route(m.getInfo))
+	m.echo.GET("/health", m.getHealth)
 	m.echo.GET("/logs", api.Route(m.getMasterLogs))
 
 	experimentsGroup := m.echo.Group("/experiments")
 	experimentsGroup.GET("/:experiment_id/model_def", m.getExperimentModelDefinition)
 	experimentsGroup.GET("/:experiment_id/file/download", m.getExperimentModelFile)
+	experimentsGroup.GET("/:experiment_id/bundle", m.getExperimentBundle)
 	experimentsGroup.GET("/:experiment_id/preview_gc", api.Route(m.getExperimentCheckpointsToGC))
+	experimentsGroup.GET("/:experiment_id/metrics/export", m.getExperimentMetricsExport)
 	experimentsGroup.PATCH("/:experiment_id", api.Route(m.patchExperiment))
 	experimentsGroup.POST("", api.Route(m.postExperiment))
 
 	checkpointsGroup := m.echo.Group("/checkpoints")
 	checkpointsGroup.GET("/:checkpoint_uuid", m.getCheckpoint)
 
+	m.echo.GET("/artifacts/:artifact_uuid", m.getArtifactFile)
+
 	searcherGroup := m.echo.Group("/searcher")
 	searcherGroup.POST("/preview", api.Route(m.getSearcherPreview))
 
@@ -1075,6 +1213,8 @@ func (m *Master) Run(ctx context.Context) error {
 			return c.Path()
 		}
 		p.Use(m.echo)
+		prom.RegisterInternals(m.system, db.Bun().Stats)
+		m.echo.Any("/metrics", echo.WrapHandler(promhttp.Handler()))
 		m.echo.Any("/debug/prom/metrics", echo.WrapHandler(promhttp.Handler()))
 		m.echo.Any("/prom/det-state-metrics",
 			echo.WrapHandler(promhttp.HandlerFor(prom.DetStateMetrics, promhttp.HandlerOpts{})))
@@ -1092,6 +1232,7 @@ func (m *Master) Run(ctx context.Context) error {
 	})
 
 	user.RegisterAPIHandler(m.echo, userService)
+	m.echo.POST("/login", api.Route(m.postLogin))
 	template.RegisterAPIHandler(m.echo, m.db)
 
 	telemetry.Setup(
@@ -1105,9 +1246,25 @@ func (m *Master) Run(ctx context.Context) error {
 	if err := sso.RegisterAPIHandlers(m.config, m.db, m.echo); err != nil {
 		return err
 	}
+	scim.RegisterAPIHandlers(m.config, m.echo)
+	m.echo.GET(auditlog.ExportPath, auditlog.Export)
 
 	webhooks.Init()
 	defer webhooks.Deinit()
 
-	return m.startServers(ctx, cert)
+	go func() {
+		select {
+		case <-shutdown:
+			m.shutDown()
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	err = m.startServers(ctx, cert)
+	select {
+	case <-shutdown:
+		return nil
+	default:
+		return err
+	}
 }
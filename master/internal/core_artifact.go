@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/api"
+	"github.com/determined-ai/determined/master/internal/auditlog"
+	detContext "github.com/determined-ai/determined/master/internal/context"
+	expauth "github.com/determined-ai/determined/master/internal/experiment"
+	"github.com/determined-ai/determined/master/internal/objectstore"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// @Summary Get the file of an artifact.
+// @Tags Artifacts
+// @ID get-artifact-file
+// @Produce  application/octet-stream
+// @Param   artifact_uuid path string  true  "Artifact UUID"
+// @Success 200 {} string ""
+//nolint:godot
+// @Router /artifacts/{artifact_uuid} [get]
+func (m *Master) getArtifactFile(c echo.Context) error {
+	args := struct {
+		ArtifactUUID string `path:"artifact_uuid"`
+	}{}
+	if err := api.BindArgs(&args, c); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid artifact_uuid: "+err.Error())
+	}
+
+	ctx := c.Request().Context()
+	curUser := c.(*detContext.DetContext).MustGetUser()
+	artifact, err := m.canDoActionOnArtifact(ctx, curUser, args.ArtifactUUID,
+		expauth.AuthZProvider.Get().CanGetExperimentArtifacts)
+	if err != nil {
+		s, ok := status.FromError(err)
+		if !ok {
+			return err
+		}
+		switch s.Code() {
+		case codes.InvalidArgument:
+			return echo.NewHTTPError(http.StatusBadRequest, s.Message())
+		case codes.NotFound:
+			return echo.NewHTTPError(http.StatusNotFound, s.Message())
+		case codes.PermissionDenied:
+			return echo.NewHTTPError(http.StatusForbidden, s.Message())
+		default:
+			return errors.New(s.Message())
+		}
+	}
+
+	err = serveArtifactFile(c, artifact)
+	auditlog.Record(ctx, &curUser, auditlog.Entry{
+		Action:     auditlog.ArtifactDownload,
+		TargetType: "artifact",
+		TargetID:   artifact.UUID.String(),
+		Success:    err == nil,
+		RemoteIP:   c.RealIP(),
+	})
+	return err
+}
+
+func serveArtifactFile(c echo.Context, artifact *model.Artifact) error {
+	ctx := c.Request().Context()
+	s, err := objectstore.New(ctx, artifact.Storage)
+	if errors.Is(err, objectstore.ErrUnsupported) {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"artifacts can only be downloaded through the master from s3 and shared_fs storage; "+
+				"download it from checkpoint storage directly instead")
+	} else if err != nil {
+		return err
+	}
+
+	// The artifact is staged in a temporary file so that a failed download from storage is
+	// reported as an error rather than as a truncated file.
+	f, err := os.CreateTemp("", "artifact")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	if err := s.Download(ctx, artifact.StorageKey(), f); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError,
+			fmt.Sprintf("unable to download artifact %s: %s", artifact.UUID, err))
+	}
+	return c.Attachment(f.Name(), artifact.Name)
+}
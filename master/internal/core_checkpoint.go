@@ -17,6 +17,7 @@ import (
 	"github.com/determined-ai/determined/master/pkg/checkpoints/archive"
 
 	"github.com/determined-ai/determined/master/internal/api"
+	"github.com/determined-ai/determined/master/internal/auditlog"
 	detContext "github.com/determined-ai/determined/master/internal/context"
 	expauth "github.com/determined-ai/determined/master/internal/experiment"
 	"github.com/determined-ai/determined/master/pkg/ptrs"
@@ -176,5 +177,13 @@ func (m *Master) getCheckpoint(c echo.Context) error {
 	}
 
 	c.Response().Header().Set(echo.HeaderContentType, mimeType)
-	return m.getCheckpointImpl(c.Request().Context(), id, mimeType, c.Response())
+	err = m.getCheckpointImpl(c.Request().Context(), id, mimeType, c.Response())
+	auditlog.Record(c.Request().Context(), &curUser, auditlog.Entry{
+		Action:     auditlog.CheckpointDownload,
+		TargetType: "checkpoint",
+		TargetID:   id.String(),
+		Success:    err == nil,
+		RemoteIP:   c.RealIP(),
+	})
+	return err
 }
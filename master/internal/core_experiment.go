@@ -1,12 +1,19 @@
 package internal
 
 import (
+	"archive/tar"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
 	"net/http"
+	"path"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
@@ -18,18 +25,22 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/determined-ai/determined/master/internal/api"
+	"github.com/determined-ai/determined/master/internal/coldarchive"
 	detContext "github.com/determined-ai/determined/master/internal/context"
 	"github.com/determined-ai/determined/master/internal/db"
 	expauth "github.com/determined-ai/determined/master/internal/experiment"
+	"github.com/determined-ai/determined/master/internal/podtemplates"
 	"github.com/determined-ai/determined/master/internal/project"
 	"github.com/determined-ai/determined/master/internal/sproto"
 	"github.com/determined-ai/determined/master/internal/user"
 	"github.com/determined-ai/determined/master/pkg/actor"
 	"github.com/determined-ai/determined/master/pkg/archive"
+	"github.com/determined-ai/determined/master/pkg/check"
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/master/pkg/schemas"
 	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
 	"github.com/determined-ai/determined/master/pkg/tasks"
+	"github.com/determined-ai/determined/master/version"
 )
 
 // ExperimentRequestQuery contains values for the experiments request queries with defaults already
@@ -190,6 +201,149 @@ func (m *Master) getExperimentModelFile(c echo.Context) error {
 	return c.Blob(http.StatusOK, http.DetectContentType(file), file)
 }
 
+// metricsExportWriter writes the metrics of an experiment to a file in one of the export formats.
+type metricsExportWriter interface {
+	write(m db.ExperimentMetric) error
+	close() error
+}
+
+type csvMetricsExportWriter struct {
+	w *csv.Writer
+}
+
+func (w *csvMetricsExportWriter) write(m db.ExperimentMetric) error {
+	var endTime, value string
+	if m.EndTime != nil {
+		endTime = m.EndTime.UTC().Format(time.RFC3339Nano)
+	}
+	if m.Value != nil {
+		value = strconv.FormatFloat(*m.Value, 'g', -1, 64)
+	}
+	return w.w.Write([]string{
+		strconv.Itoa(m.TrialID), m.Group, strconv.Itoa(m.TotalBatches), endTime, m.Name, value,
+	})
+}
+
+func (w *csvMetricsExportWriter) close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type jsonMetricsExportWriter struct {
+	enc *json.Encoder
+}
+
+// jsonMetricsExportRow is a line of the JSON Lines export. Values that JSON numbers cannot hold
+// are written as the strings "NaN", "Infinity" and "-Infinity", as in the database.
+type jsonMetricsExportRow struct {
+	TrialID      int         `json:"trial_id"`
+	MetricGroup  string      `json:"metric_group"`
+	TotalBatches int         `json:"total_batches"`
+	EndTime      *time.Time  `json:"end_time"`
+	Metric       string      `json:"metric"`
+	Value        interface{} `json:"value"`
+}
+
+func (w *jsonMetricsExportWriter) write(m db.ExperimentMetric) error {
+	row := jsonMetricsExportRow{
+		TrialID:      m.TrialID,
+		MetricGroup:  m.Group,
+		TotalBatches: m.TotalBatches,
+		EndTime:      m.EndTime,
+		Metric:       m.Name,
+	}
+	switch {
+	case m.Value == nil:
+	case math.IsNaN(*m.Value):
+		row.Value = "NaN"
+	case math.IsInf(*m.Value, 1):
+		row.Value = "Infinity"
+	case math.IsInf(*m.Value, -1):
+		row.Value = "-Infinity"
+	default:
+		row.Value = *m.Value
+	}
+	return w.enc.Encode(row)
+}
+
+func (w *jsonMetricsExportWriter) close() error {
+	return nil
+}
+
+var metricsExportColumns = []string{
+	"trial_id", "metric_group", "total_batches", "end_time", "metric", "value",
+}
+
+func newMetricsExportWriter(format string, w io.Writer) (metricsExportWriter, error) {
+	if format == "json" {
+		return &jsonMetricsExportWriter{enc: json.NewEncoder(w)}, nil
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(metricsExportColumns); err != nil {
+		return nil, err
+	}
+	return &csvMetricsExportWriter{w: cw}, nil
+}
+
+// @Summary Export the metrics of every trial of an experiment, one row per metric value.
+// @Tags Experiments
+// @ID get-experiment-metrics-export
+// @Produce  text/csv,application/x-ndjson
+// @Param   experiment_id path int  true  "Experiment ID"
+// @Param   format query string false "Format of the file, csv (the default) or json (JSON Lines)"
+// @Success 200 {} string "trial_id,metric_group,total_batches,end_time,metric,value"
+// @Router /experiments/{experiment_id}/metrics/export [get]
+//
+//nolint:godot
+func (m *Master) getExperimentMetricsExport(c echo.Context) error {
+	args := struct {
+		ExperimentID int    `path:"experiment_id"`
+		Format       string `query:"format"`
+	}{}
+	if err := api.BindArgs(&args, c); err != nil {
+		return err
+	}
+	var contentType, extension string
+	switch args.Format {
+	case "":
+		args.Format = "csv"
+		contentType, extension = "text/csv", "csv"
+	case "csv":
+		contentType, extension = "text/csv", "csv"
+	case "json":
+		contentType, extension = "application/x-ndjson", "jsonl"
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Sprintf("unsupported format %q, expected csv or json", args.Format))
+	}
+	if _, _, err := echoGetExperimentAndCheckCanDoActions(
+		c.Request().Context(), c, m, args.ExperimentID, false,
+		expauth.AuthZProvider.Get().CanGetExperimentArtifacts,
+	); err != nil {
+		return err
+	}
+	if err := coldarchive.Rehydrate(c.Request().Context(), args.ExperimentID); err != nil {
+		return err
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, contentType)
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(
+		`attachment; filename="experiment-%d-metrics.%s"`, args.ExperimentID, extension))
+	res.WriteHeader(http.StatusOK)
+
+	w, err := newMetricsExportWriter(args.Format, res)
+	if err != nil {
+		return err
+	}
+	if err := db.ForEachExperimentMetric(
+		c.Request().Context(), args.ExperimentID, w.write,
+	); err != nil {
+		return err
+	}
+	return w.close()
+}
+
 func (m *Master) getExperimentModelDefinition(c echo.Context) error {
 	args := struct {
 		ExperimentID int `path:"experiment_id"`
@@ -235,6 +389,164 @@ func (m *Master) getExperimentModelDefinition(c echo.Context) error {
 	return c.Blob(http.StatusOK, "application/x-gtar", modelDef)
 }
 
+// experimentBundleImage is an environment image of an experiment. The digest is only known when
+// the image is pinned to one, since tags can be moved to other images after the experiment runs.
+type experimentBundleImage struct {
+	Image  string `json:"image"`
+	Digest string `json:"digest,omitempty"`
+}
+
+type experimentBundleGit struct {
+	Remote     *string    `json:"remote"`
+	Commit     *string    `json:"commit"`
+	Committer  *string    `json:"committer"`
+	CommitDate *time.Time `json:"commit_date"`
+}
+
+type experimentBundleCheckpoint struct {
+	UUID           string        `json:"uuid"`
+	TrialID        int           `json:"trial_id"`
+	StepsCompleted int           `json:"steps_completed"`
+	SearcherMetric *float64      `json:"searcher_metric"`
+	HParams        model.JSONObj `json:"hparams"`
+	Resources      model.JSONObj `json:"resources"`
+}
+
+// experimentBundleMetadata is what a reproducibility bundle records about an experiment besides
+// its configuration and model definition.
+type experimentBundleMetadata struct {
+	ExperimentID      int                              `json:"experiment_id"`
+	Name              string                           `json:"name"`
+	State             model.State                      `json:"state"`
+	StartTime         time.Time                        `json:"start_time"`
+	EndTime           *time.Time                       `json:"end_time"`
+	ParentID          *int                             `json:"parent_id"`
+	Username          string                           `json:"username"`
+	DeterminedVersion string                           `json:"determined_version"`
+	Images            map[string]experimentBundleImage `json:"environment_images"`
+	Git               *experimentBundleGit             `json:"git"`
+	BestCheckpoint    *experimentBundleCheckpoint      `json:"best_checkpoint"`
+}
+
+func newExperimentBundleImage(image string) experimentBundleImage {
+	i := experimentBundleImage{Image: image}
+	if at := strings.LastIndex(image, "@"); at >= 0 {
+		i.Digest = image[at+1:]
+	}
+	return i
+}
+
+// experimentBundle returns a gzipped tarball with everything needed to rerun or audit an
+// experiment: its configuration as submitted and with defaults filled in, its model definition,
+// and metadata about where its code and environment came from and where its best checkpoint is.
+func (m *Master) experimentBundle(e *model.Experiment) ([]byte, error) {
+	expConfig := schemas.WithDefaults(e.Config).(expconf.ExperimentConfig)
+	config, err := json.Marshal(expConfig)
+	if err != nil {
+		return nil, err
+	}
+	if config, err = yaml.JSONToYAML(config); err != nil {
+		return nil, err
+	}
+
+	images := expConfig.Environment().Image()
+	meta := experimentBundleMetadata{
+		ExperimentID:      e.ID,
+		Name:              expConfig.Name().String(),
+		State:             e.State,
+		StartTime:         e.StartTime,
+		EndTime:           e.EndTime,
+		ParentID:          e.ParentID,
+		Username:          e.Username,
+		DeterminedVersion: version.Version,
+		Images: map[string]experimentBundleImage{
+			"cpu":  newExperimentBundleImage(images.CPU()),
+			"cuda": newExperimentBundleImage(images.CUDA()),
+			"rocm": newExperimentBundleImage(images.ROCM()),
+		},
+	}
+	if e.GitCommit != nil {
+		meta.Git = &experimentBundleGit{
+			Remote:     e.GitRemote,
+			Commit:     e.GitCommit,
+			Committer:  e.GitCommitter,
+			CommitDate: e.GitCommitDate,
+		}
+	}
+	best, err := m.db.ExperimentBestCheckpoint(e.ID, expConfig.Searcher().SmallerIsBetter())
+	if err != nil {
+		return nil, err
+	}
+	if best != nil {
+		meta.BestCheckpoint = &experimentBundleCheckpoint{
+			UUID:           best.UUID.String(),
+			TrialID:        best.TrialID,
+			StepsCompleted: best.StepsCompleted,
+			SearcherMetric: best.SearcherMetric,
+			HParams:        best.HParams,
+			Resources:      best.Resources,
+		}
+	}
+	metadata, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	modelDefBytes, err := m.db.ExperimentModelDefinitionRaw(e.ID)
+	if err != nil {
+		return nil, err
+	}
+	modelDef, err := archive.FromTarGz(modelDefBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading model definition")
+	}
+
+	bundle := archive.Archive{
+		archive.RootItem("metadata.json", metadata, 0o644, tar.TypeReg),
+		archive.RootItem("config.yaml", config, 0o644, tar.TypeReg),
+		archive.RootItem("original_config.yaml", []byte(e.OriginalConfig), 0o644, tar.TypeReg),
+		archive.RootItem("model_def", nil, 0o755, tar.TypeDir),
+	}
+	for _, item := range modelDef {
+		item.Path = path.Join("model_def", item.Path)
+		bundle = append(bundle, item)
+	}
+	return archive.ToRelocatedTarGz(fmt.Sprintf("exp%d_bundle/", e.ID), bundle)
+}
+
+// @Summary Get a reproducibility bundle of an experiment.
+// @Tags Experiments
+// @ID get-experiment-bundle
+// @Produce  application/x-gtar
+// @Param   experiment_id path int  true  "Experiment ID"
+// @Success 200 {} string "A gzipped tarball of the experiment's config, code, and metadata"
+// @Router /experiments/{experiment_id}/bundle [get]
+//
+//nolint:godot
+func (m *Master) getExperimentBundle(c echo.Context) error {
+	args := struct {
+		ExperimentID int `path:"experiment_id"`
+	}{}
+	if err := api.BindArgs(&args, c); err != nil {
+		return err
+	}
+	e, _, err := echoGetExperimentAndCheckCanDoActions(
+		c.Request().Context(), c, m, args.ExperimentID, true,
+		expauth.AuthZProvider.Get().CanGetExperimentArtifacts,
+	)
+	if err != nil {
+		return err
+	}
+
+	bundle, err := m.experimentBundle(e)
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="exp%d_bundle.tar.gz"`, args.ExperimentID))
+	return c.Blob(http.StatusOK, "application/x-gtar", bundle)
+}
+
 func (m *Master) patchExperiment(c echo.Context) (interface{}, error) {
 	// Allow clients to apply partial updates to an experiment via the JSON Merge Patch format
 	// (RFC 7386). Clients can only update certain fields of the experiment.
@@ -469,6 +781,11 @@ func (m *Master) parseCreateExperiment(params *CreateExperimentParams, user *mod
 		config = schemas.Merge(config, tc).(expconf.ExperimentConfig)
 	}
 
+	// Merge the pod spec over the pod template that the user specified.
+	if err = podtemplates.ResolveEnvironment(context.TODO(), config.RawEnvironment); err != nil {
+		return nil, nil, false, nil, errors.Wrap(err, "invalid experiment configuration")
+	}
+
 	defaulted := schemas.WithDefaults(config).(expconf.ExperimentConfig)
 	resources := defaulted.Resources()
 	poolName, err := m.rm.ResolveResourcePool(
@@ -486,6 +803,10 @@ func (m *Master) parseCreateExperiment(params *CreateExperimentParams, user *mod
 	if err != nil {
 		return nil, nil, false, nil, err
 	}
+	workspaceID := int(project.WorkspaceId)
+	if err = checkResourcePoolBound(context.TODO(), poolName, user.ID, &workspaceID); err != nil {
+		return nil, nil, false, nil, err
+	}
 
 	// Merge in workspace's checkpoint storage into the conifg.
 	w := &model.Workspace{}
@@ -515,6 +836,9 @@ func (m *Master) parseCreateExperiment(params *CreateExperimentParams, user *mod
 	if err = config.Searcher().AssertCurrent(); err != nil {
 		return nil, nil, false, nil, errors.Wrap(err, "invalid experiment configuration")
 	}
+	if err = check.Validate(config.Searcher()); err != nil {
+		return nil, nil, false, nil, errors.Wrap(err, "invalid experiment configuration")
+	}
 
 	var modelBytes []byte
 	if params.ParentID != nil {
@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/dynamic"
+	k8sClient "k8s.io/client-go/kubernetes"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/experimentcrd"
+	"github.com/determined-ai/determined/master/internal/rm/kubernetes"
+	"github.com/determined-ai/determined/master/internal/user"
+	"github.com/determined-ai/determined/master/pkg/archive"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+// crdExperiments manages the experiments of Experiment custom resources as the configured user.
+type crdExperiments struct {
+	m        *Master
+	username string
+}
+
+func (c *crdExperiments) Submit(
+	ctx context.Context, spec experimentcrd.Spec, modelDef archive.Archive,
+) (int, error) {
+	owner, err := user.UserByUsername(c.username)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to find user %s", c.username)
+	}
+	fullOwner, err := user.UserByID(owner.ID)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to find user %s", c.username)
+	}
+	configBytes, err := json.Marshal(spec.Config)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid config")
+	}
+	return c.m.submitExperimentAs(ctx, fullOwner, &CreateExperimentParams{
+		ConfigBytes: string(configBytes),
+		Template:    spec.Template,
+		ModelDef:    modelDef,
+		ProjectID:   spec.ProjectID,
+	})
+}
+
+func (c *crdExperiments) State(ctx context.Context, id int) (model.State, error) {
+	e, err := c.m.db.ExperimentByID(id)
+	if err != nil {
+		return "", err
+	}
+	return e.State, nil
+}
+
+func (c *crdExperiments) Kill(ctx context.Context, id int) error {
+	resp := c.m.system.AskAt(experimentsAddr.Child(id), &apiv1.KillExperimentRequest{
+		Id: int32(id),
+	})
+	if resp.Source() == nil {
+		// The experiment is not running, so there is nothing to kill.
+		return nil
+	}
+	if _, notTimedOut := resp.GetOrTimeout(defaultAskTimeout); !notTimedOut {
+		return errors.Errorf("attempt to kill experiment %d timed out", id)
+	}
+	return resp.Error()
+}
+
+// startExperimentCRDController submits the experiments of Experiment custom resources until the
+// context is canceled, if the Kubernetes resource manager is configured to.
+func (m *Master) startExperimentCRDController(
+	ctx context.Context, k8sConfig *config.KubernetesResourceManagerConfig,
+) error {
+	if k8sConfig == nil || k8sConfig.ExperimentCRDs == nil {
+		return nil
+	}
+	restConfig, err := kubernetes.ReadClientConfig(k8sConfig.CredsDir)
+	if err != nil {
+		return errors.Wrap(err, "failed to read Kubernetes client config")
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to create Kubernetes dynamic client")
+	}
+	clientSet, err := k8sClient.NewForConfig(restConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to create Kubernetes client")
+	}
+
+	namespace := k8sConfig.ExperimentCRDs.Namespace
+	if namespace == "" {
+		namespace = k8sConfig.Namespace
+	}
+	c := experimentcrd.New(dynamicClient, clientSet, namespace, &crdExperiments{
+		m:        m,
+		username: k8sConfig.ExperimentCRDs.Username,
+	})
+	go c.Loop(ctx)
+	return nil
+}
@@ -4,13 +4,16 @@
 package internal
 
 import (
+	"archive/tar"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
 	"github.com/stretchr/testify/mock"
@@ -18,7 +21,9 @@ import (
 
 	"github.com/determined-ai/determined/master/internal/context"
 	"github.com/determined-ai/determined/master/internal/mocks"
+	"github.com/determined-ai/determined/master/pkg/archive"
 	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
 )
 
 func expNotFoundErrEcho(id int) error {
@@ -128,6 +133,20 @@ func TestAuthZGetExperimentAndCanDoActionsEcho(t *testing.T) {
 			ctx.SetRequest(httptest.NewRequest(http.MethodPost, "/?path=rootPath", nil))
 			return api.m.getExperimentModelFile(ctx)
 		}, []any{mock.Anything, mock.Anything, mock.Anything}},
+		{"CanGetExperimentArtifacts", func(id int) error {
+			_, _, _, _, ctx := setupExpAuthTestEcho(t)
+			ctx.SetParamNames("experiment_id")
+			ctx.SetParamValues(fmt.Sprintf("%d", id))
+			ctx.SetRequest(httptest.NewRequest(http.MethodGet, "/", nil))
+			return api.m.getExperimentBundle(ctx)
+		}, []any{mock.Anything, mock.Anything, mock.Anything}},
+		{"CanGetExperimentArtifacts", func(id int) error {
+			_, _, _, _, ctx := setupExpAuthTestEcho(t)
+			ctx.SetParamNames("experiment_id")
+			ctx.SetParamValues(fmt.Sprintf("%d", id))
+			ctx.SetRequest(httptest.NewRequest(http.MethodGet, "/?format=json", nil))
+			return api.m.getExperimentMetricsExport(ctx)
+		}, []any{mock.Anything, mock.Anything, mock.Anything}},
 		{"CanGetExperimentArtifacts", func(id int) error {
 			_, _, _, _, ctx := setupExpAuthTestEcho(t)
 			ctx.SetParamNames("experiment_id")
@@ -215,3 +234,55 @@ func TestAuthZGetExperimentAndCanDoActionsEcho(t *testing.T) {
 		require.Equal(t, expectedErr.Error(), curCase.IDToReqCall(exp.ID).Error())
 	}
 }
+
+func TestExperimentBundle(t *testing.T) {
+	api, curUser, _ := setupAPITest(t)
+	modelDef, err := archive.ToTarGz(archive.Archive{
+		archive.RootItem("train.py", []byte("print('hi')"), 0o644, tar.TypeReg),
+	})
+	require.NoError(t, err)
+	exp := &model.Experiment{
+		JobID:                model.JobID(uuid.New().String()),
+		State:                model.CompletedState,
+		OwnerID:              &curUser.ID,
+		ProjectID:            1,
+		StartTime:            time.Now(),
+		ModelDefinitionBytes: modelDef,
+		Config:               minExpConfig,
+		OriginalConfig:       minExpConfToYaml(t),
+		GitCommit:            ptrs.Ptr("0123abc"),
+		GitRemote:            ptrs.Ptr("https://example.com/repo.git"),
+	}
+	require.NoError(t, api.m.db.AddExperiment(exp))
+	exp, err = api.m.db.ExperimentByID(exp.ID)
+	require.NoError(t, err)
+
+	bundle, err := api.m.experimentBundle(exp)
+	require.NoError(t, err)
+	files, err := archive.FromTarGz(bundle)
+	require.NoError(t, err)
+	contents := map[string][]byte{}
+	for _, f := range files {
+		contents[f.Path] = f.Content
+	}
+	prefix := fmt.Sprintf("exp%d_bundle/", exp.ID)
+	require.Equal(t, []byte("print('hi')"), contents[prefix+"model_def/train.py"])
+	require.Equal(t, exp.OriginalConfig, string(contents[prefix+"original_config.yaml"]))
+	require.Contains(t, string(contents[prefix+"config.yaml"]), "metric: loss")
+
+	var meta experimentBundleMetadata
+	require.NoError(t, json.Unmarshal(contents[prefix+"metadata.json"], &meta))
+	require.Equal(t, exp.ID, meta.ExperimentID)
+	require.Equal(t, "0123abc", *meta.Git.Commit)
+	require.NotEmpty(t, meta.Images["cpu"].Image)
+	require.Nil(t, meta.BestCheckpoint)
+}
+
+func TestExperimentBundleImage(t *testing.T) {
+	require.Equal(t, experimentBundleImage{Image: "determinedai/environments:py-3.8"},
+		newExperimentBundleImage("determinedai/environments:py-3.8"))
+	require.Equal(t, experimentBundleImage{
+		Image:  "registry:5000/env@sha256:abcd",
+		Digest: "sha256:abcd",
+	}, newExperimentBundleImage("registry:5000/env@sha256:abcd"))
+}
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/health"
+	"github.com/determined-ai/determined/master/internal/objectstore"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+// healthCheckTimeout is how long each subsystem has to answer a health check.
+const healthCheckTimeout = 5 * time.Second
+
+// getHealth reports the status of each subsystem of the master, answering with 503 Service
+// Unavailable only if the master cannot work, so that load balancers keep sending requests to a
+// master whose optional subsystems are down.
+func (m *Master) getHealth(c echo.Context) error {
+	report := health.Run(c.Request().Context(), healthCheckTimeout, m.healthChecks())
+	code := http.StatusOK
+	if report.Status == health.StatusDown {
+		code = http.StatusServiceUnavailable
+	}
+	return c.JSON(code, report)
+}
+
+func (m *Master) healthChecks() []health.Check {
+	rmKind := "agent"
+	if m.config.ResourceManager.KubernetesRM != nil {
+		rmKind = "kubernetes"
+	}
+	logBackend := m.logBackendCheck
+	logBackend.Name = "log_backend"
+
+	return []health.Check{
+		{
+			Name:     "database",
+			Kind:     "postgres",
+			Critical: true,
+			Run:      db.Bun().PingContext,
+		},
+		{
+			Name:     "resource_manager",
+			Kind:     rmKind,
+			Critical: true,
+			Run: func(context.Context) error {
+				_, err := m.rm.GetResourcePools(m.system, &apiv1.GetResourcePoolsRequest{})
+				return err
+			},
+		},
+		logBackend,
+		{
+			Name: "checkpoint_storage",
+			Kind: storageKind(m.config.CheckpointStorage),
+			Run: func(ctx context.Context) error {
+				s, err := objectstore.New(ctx, m.config.CheckpointStorage)
+				switch {
+				case errors.Is(err, objectstore.ErrUnsupported):
+					return health.ErrUnsupported
+				case err != nil:
+					return err
+				}
+				return s.Check(ctx)
+			},
+		},
+	}
+}
+
+// pingTaskLogs checks that task logs can be read from the database.
+func pingTaskLogs(ctx context.Context) error {
+	_, err := db.Bun().NewSelect().Table("task_logs").Column("id").Limit(1).Exists(ctx)
+	return err
+}
+
+func storageKind(storage expconf.CheckpointStorageConfig) string {
+	switch storage.GetUnionMember().(type) {
+	case expconf.SharedFSConfig:
+		return "shared_fs"
+	case expconf.S3Config:
+		return "s3"
+	case expconf.GCSConfig:
+		return "gcs"
+	case expconf.AzureConfig:
+		return "azure"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/auditlog"
+	"github.com/determined-ai/determined/master/internal/user"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// postLogin is the legacy REST login endpoint. It checks credentials the same way as the gRPC
+// Login endpoint. Clients send the password hashed, as they always have, unless they set
+// isHashed to false, which LDAP logins require.
+func (m *Master) postLogin(c echo.Context) (_ interface{}, err error) {
+	if m.config.InternalConfig.ExternalSessions.JwtKey != "" {
+		return nil, echo.NewHTTPError(http.StatusMisdirectedRequest,
+			"authentication is configured to be external")
+	}
+
+	type (
+		request struct {
+			Username    string `json:"username"`
+			Password    string `json:"password"`
+			IsHashed    *bool  `json:"isHashed"`
+			NewPassword string `json:"newPassword"`
+			MfaCode     string `json:"mfaCode"`
+		}
+		response struct {
+			Token string `json:"token"`
+		}
+	)
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var params request
+	if err = json.Unmarshal(body, &params); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest)
+	}
+
+	var userModel *model.User
+	defer func() {
+		auditlog.Record(c.Request().Context(), userModel, auditlog.Entry{
+			Username: params.Username,
+			Action:   auditlog.UserLogin,
+			Success:  err == nil,
+			RemoteIP: c.RealIP(),
+		})
+	}()
+
+	isHashed := params.IsHashed == nil || *params.IsHashed
+	var res *loginResult
+	userModel, res, err = m.login(c.Request().Context(), loginRequest{
+		Username:    params.Username,
+		Password:    params.Password,
+		IsHashed:    isHashed,
+		NewPassword: params.NewPassword,
+		MfaCode:     params.MfaCode,
+	})
+	if err != nil {
+		return nil, loginHTTPError(err)
+	}
+
+	// The caller of this REST endpoint can request that the master set a cookie.
+	// This is used by the WebUI for persistence of sessions.
+	if c.QueryParam("cookie") == "true" {
+		c.SetCookie(user.NewCookieFromToken(res.Token))
+	}
+
+	return response{
+		Token: res.Token,
+	}, nil
+}
+
+// loginHTTPError converts the gRPC status errors of a failed login to the HTTP errors that the
+// legacy login endpoint has always returned.
+func loginHTTPError(err error) error {
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch s.Code() {
+	case codes.InvalidArgument:
+		return echo.NewHTTPError(http.StatusBadRequest, s.Message())
+	case codes.Unauthenticated, codes.PermissionDenied, codes.FailedPrecondition:
+		return echo.NewHTTPError(http.StatusForbidden, s.Message())
+	default:
+		return err
+	}
+}
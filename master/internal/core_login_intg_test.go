@@ -0,0 +1,134 @@
+//go:build integration
+// +build integration
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/plugin/sso"
+	"github.com/determined-ai/determined/master/internal/user"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/totp"
+)
+
+func legacyLogin(api *apiServer, params map[string]interface{}) (interface{}, error) {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(string(body)))
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+	return api.m.postLogin(c)
+}
+
+func requireLoginStatus(t *testing.T, err error, code int, message string) {
+	var httpErr *echo.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, code, httpErr.Code)
+	require.Contains(t, httpErr.Message, message)
+}
+
+func TestLegacyLoginMFA(t *testing.T) {
+	api, _, _ := setupAPITest(t)
+	ctx := context.TODO()
+
+	u := &model.User{Username: uuid.New().String(), Active: true}
+	_, err := api.m.db.AddUser(u, nil)
+	require.NoError(t, err)
+	_, err = legacyLogin(api, map[string]interface{}{"username": u.Username})
+	require.NoError(t, err)
+
+	secret, _, err := user.BeginMFAEnrollment(ctx, u)
+	require.NoError(t, err)
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+	recoveryCodes, err := user.ConfirmMFAEnrollment(ctx, u.ID, code)
+	require.NoError(t, err)
+
+	// A password alone no longer logs in users who enrolled in MFA.
+	_, err = legacyLogin(api, map[string]interface{}{"username": u.Username})
+	requireLoginStatus(t, err, http.StatusForbidden, "MFA code required")
+	_, err = legacyLogin(api, map[string]interface{}{"username": u.Username, "mfaCode": "000000"})
+	requireLoginStatus(t, err, http.StatusForbidden, "invalid credentials")
+	_, err = legacyLogin(api, map[string]interface{}{
+		"username": u.Username, "mfaCode": recoveryCodes[0],
+	})
+	require.NoError(t, err)
+}
+
+func TestLegacyLoginPasswordPolicy(t *testing.T) {
+	api, _, ctx := setupAPITest(t)
+	security := &config.GetMasterConfig().Security
+	defer func(policy config.PasswordPolicyConfig, lockout config.LockoutConfig) {
+		security.PasswordPolicy, security.Lockout = policy, lockout
+	}(security.PasswordPolicy, security.Lockout)
+	security.PasswordPolicy = config.PasswordPolicyConfig{MaxAge: model.Duration(time.Hour)}
+	security.Lockout = config.LockoutConfig{MaxFailedAttempts: 2, Duration: model.Duration(time.Hour)}
+
+	password := replicateClientSideSaltAndHash("password-1")
+	u := &model.User{Username: uuid.New().String(), Active: true}
+	require.NoError(t, u.UpdatePasswordHash(password))
+	_, err := api.m.db.AddUser(u, nil)
+	require.NoError(t, err)
+
+	// Expired passwords are changed as users log in.
+	_, err = db.Bun().NewUpdate().Table("user_password_states").
+		Set("password_changed_at = now() - interval '2 hours'").
+		Where("user_id = ?", u.ID).
+		Exec(ctx)
+	require.NoError(t, err)
+	_, err = legacyLogin(api, map[string]interface{}{"username": u.Username, "password": password})
+	requireLoginStatus(t, err, http.StatusForbidden, "password expired")
+	newPassword := replicateClientSideSaltAndHash("password-2")
+	_, err = legacyLogin(api, map[string]interface{}{
+		"username": u.Username, "password": password, "newPassword": newPassword,
+	})
+	require.NoError(t, err)
+	_, err = legacyLogin(api, map[string]interface{}{"username": u.Username, "password": newPassword})
+	require.NoError(t, err)
+
+	// Too many failed logins in a row lock the user out, even with the right password.
+	for i := 0; i < 2; i++ {
+		_, err = legacyLogin(api, map[string]interface{}{"username": u.Username, "password": "wrong"})
+		requireLoginStatus(t, err, http.StatusForbidden, "invalid credentials")
+	}
+	_, err = legacyLogin(api, map[string]interface{}{"username": u.Username, "password": newPassword})
+	requireLoginStatus(t, err, http.StatusForbidden, "locked")
+}
+
+func TestLegacyLoginLDAP(t *testing.T) {
+	api, _, _ := setupAPITest(t)
+	c := &config.GetMasterConfig().LDAP
+	defer func(ldap config.LDAPConfig) { *c = ldap }(*c)
+	*c = config.LDAPConfig{
+		Enabled: true,
+		URL:     "ldap://127.0.0.1:1",
+		Timeout: model.Duration(time.Second),
+	}
+	require.True(t, sso.LDAPEnabled())
+
+	// LDAP users log in through the legacy endpoint with their password sent unhashed.
+	username := uuid.New().String()
+	_, err := legacyLogin(api, map[string]interface{}{"username": username, "password": "pw"})
+	requireLoginStatus(t, err, http.StatusBadRequest, "unhashed")
+	_, err = legacyLogin(api, map[string]interface{}{
+		"username": username, "password": "pw", "isHashed": false,
+	})
+	require.Error(t, err)
+	var httpErr *echo.HTTPError
+	require.False(t, errors.As(err, &httpErr), "the directory should have been dialed: %v", err)
+}
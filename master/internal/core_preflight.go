@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"context"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/determined-ai/determined/master/internal/health"
+	"github.com/determined-ai/determined/master/internal/objectstore"
+	"github.com/determined-ai/determined/master/internal/plugin/sso"
+	"github.com/determined-ai/determined/master/internal/preflight"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+)
+
+// runPreflightChecks checks the external services that the master and its tasks depend on when
+// the master starts, failing if any cannot be reached and the master is configured to fail.
+func (m *Master) runPreflightChecks(ctx context.Context) error {
+	report := health.Run(ctx, time.Duration(m.config.Preflight.Timeout), m.preflightChecks())
+	var failed []string
+	for _, r := range report.Checks {
+		l := log.WithField("check", r.Name).WithField("kind", r.Kind)
+		switch r.Status {
+		case health.StatusDown:
+			l.Errorf("preflight check failed: %s", r.Error)
+			failed = append(failed, r.Name+": "+r.Error)
+		case health.StatusUnknown:
+			l.Infof("preflight check skipped: %s", r.Error)
+		default:
+			l.Debug("preflight check passed")
+		}
+	}
+	if len(failed) > 0 && m.config.Preflight.FailOnError {
+		return errors.Errorf(
+			"preflight checks failed (set preflight.fail_on_error to false to start anyway):\n%s",
+			strings.Join(failed, "\n"))
+	}
+	return nil
+}
+
+func (m *Master) preflightChecks() []health.Check {
+	logBackend := m.logBackendCheck
+	logBackend.Name = "log_backend"
+	logBackend.Critical = true
+	checks := []health.Check{logBackend, storagePreflightCheck(
+		"checkpoint_storage", m.config.CheckpointStorage, true,
+	)}
+
+	for _, storage := range []struct {
+		name   string
+		config *expconf.CheckpointStorageConfig
+	}{
+		{"cold_archive.storage", m.config.ColdArchive.Storage},
+		{"backup.storage", m.config.Backup.Storage},
+		{"log_retention.storage", m.config.LogRetention.Storage},
+	} {
+		if storage.config != nil {
+			checks = append(checks, storagePreflightCheck(storage.name, *storage.config, false))
+		}
+	}
+
+	if m.config.OIDC.Enabled {
+		oidc := m.config.OIDC
+		checks = append(checks, health.Check{
+			Name:     "oidc",
+			Kind:     oidc.IDPSSOURL,
+			Critical: true,
+			Run:      func(context.Context) error { return sso.CheckOIDC(oidc) },
+		})
+	}
+
+	// Tasks use the registry_auth of their resource pool, if it has one.
+	auths := []*types.AuthConfig{m.config.TaskContainerDefaults.RegistryAuth}
+	for _, pool := range m.config.ResourcePools {
+		if pool.TaskContainerDefaults != nil {
+			auths = append(auths, pool.TaskContainerDefaults.RegistryAuth)
+		}
+	}
+	checked := map[types.AuthConfig]bool{}
+	for _, auth := range auths {
+		if auth == nil || checked[*auth] {
+			continue
+		}
+		checked[*auth] = true
+		auth := *auth
+		checks = append(checks, health.Check{
+			Name:     "registry_auth",
+			Kind:     auth.ServerAddress,
+			Critical: true,
+			Run:      func(ctx context.Context) error { return preflight.Registry(ctx, auth) },
+		})
+	}
+	return checks
+}
+
+// storagePreflightCheck checks that the master can write to checkpoint storage. Only tasks write
+// to the checkpoint storage of the cluster, which the master may not have mounted, so if it is a
+// shared_fs storage that the master does not have, it is skipped rather than failed.
+func storagePreflightCheck(
+	name string, storage expconf.CheckpointStorageConfig, usedByTasks bool,
+) health.Check {
+	return health.Check{
+		Name:     name,
+		Kind:     storageKind(storage),
+		Critical: true,
+		Run: func(ctx context.Context) error {
+			s, err := objectstore.New(ctx, storage)
+			switch {
+			case errors.Is(err, objectstore.ErrUnsupported):
+				return health.ErrUnsupported
+			case err != nil:
+				return err
+			}
+			if _, ok := storage.GetUnionMember().(expconf.SharedFSConfig); ok && usedByTasks {
+				if err := s.Check(ctx); errors.Is(err, fs.ErrNotExist) {
+					return errors.Wrap(health.ErrUnsupported, "not mounted on the master")
+				}
+			}
+			return preflight.Storage(ctx, s)
+		},
+	}
+}
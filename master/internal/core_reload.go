@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/plugin/sso"
+	"github.com/determined-ai/determined/master/internal/rm"
+	"github.com/determined-ai/determined/master/pkg/logger"
+)
+
+// reloadConfig reads the configuration file again and applies the settings that can change while
+// the master runs, without disturbing running allocations.
+func (m *Master) reloadConfig() (*config.ReloadResult, error) {
+	if m.loadConfig == nil {
+		return nil, errors.New("this master cannot reload its configuration")
+	}
+	next, err := m.loadConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading the configuration")
+	}
+
+	// Set up OIDC first, since reaching the provider with the new settings may fail.
+	if !reflect.DeepEqual(m.config.OIDC, next.OIDC) {
+		if err := sso.ReloadOIDC(next.OIDC, m.db); err != nil {
+			return nil, errors.Wrap(err, "error reloading OIDC")
+		}
+	}
+
+	result, err := config.Reload(next)
+	if err != nil {
+		return nil, err
+	}
+	logger.SetLogrus(m.config.Log)
+	if m.config.ResourceManager.AgentRM != nil {
+		for _, pool := range m.config.ResourcePools {
+			ref, err := m.rm.GetResourcePoolRef(m.system, pool.PoolName)
+			if err != nil {
+				return nil, err
+			}
+			m.system.Ask(ref, rm.UpdateResourcePoolConfig{Config: pool}).Get()
+		}
+	}
+
+	log.Infof("reloaded configuration, changed settings: %v", result.Reloaded)
+	if len(result.RequireRestart) > 0 {
+		log.Warnf("settings that only change once the master restarts: %v", result.RequireRestart)
+	}
+	return result, nil
+}
+
+// reloadConfigOnSIGHUP reloads the configuration each time the master receives SIGHUP.
+func (m *Master) reloadConfigOnSIGHUP(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-hup:
+			if _, err := m.reloadConfig(); err != nil {
+				log.WithError(err).Error("failed to reload configuration")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/determined-ai/determined/master/pkg/check"
 	"github.com/determined-ai/determined/master/pkg/schemas"
 	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
 	"github.com/determined-ai/determined/master/pkg/searcher"
@@ -47,6 +48,9 @@ func (m *Master) getSearcherPreview(c echo.Context) (interface{}, error) {
 	if err = sc.AssertCurrent(); err != nil {
 		return nil, errors.Wrap(err, "invalid experiment configuration")
 	}
+	if err = check.Validate(sc); err != nil {
+		return nil, errors.Wrap(err, "invalid searcher configuration")
+	}
 
 	sm := searcher.NewSearchMethod(sc)
 	s := searcher.NewSearcher(0, sm, hc)
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/determined-ai/determined/master/internal/drain"
+	"github.com/determined-ai/determined/master/internal/rm"
+	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/actor"
+)
+
+// shutDown prepares the master to exit without disturbing the work in flight, which is restored
+// when it starts again. It stops accepting new work, persists the state of experiments, tells
+// agents that the master is restarting so that they wait for it rather than exit, and flushes the
+// task logs it holds. The servers and the actor system must still be running.
+func (m *Master) shutDown() {
+	start := time.Now()
+	timeout := time.Duration(m.config.Shutdown.Timeout)
+	log.Info("shutting down, new experiments and tasks are refused")
+	drain.Start()
+
+	if experiments := m.system.Get(actor.Addr("experiments")); experiments != nil {
+		refs := experiments.Children()
+		m.system.AskAllTimeout(persistSnapshot{}, timeout, refs...).GetAll()
+		log.Infof("persisted the state of %d experiments", len(refs))
+	}
+
+	if agents := m.system.Get(sproto.AgentsAddr); agents != nil {
+		refs := agents.Children()
+		m.system.AskAllTimeout(rm.NotifyMasterRestart{
+			ReconnectWait: time.Duration(m.config.Shutdown.AgentReconnectWait),
+		}, timeout, refs...).GetAll()
+		log.Infof("told %d agents that the master is restarting", len(refs))
+	}
+
+	m.taskLogger.Flush(timeout)
+	log.Infof("shut down in %s", time.Since(start).Round(time.Millisecond))
+}
+
+// exitAfterRestore stops the master once the database has been restored from a backup. Everything
+// the master holds in memory is then out of date, so it exits right away rather than shutting down
+// gracefully, which would persist that state over the restored database, and exits with an error
+// so that whatever supervises the master starts it again on the restored database.
+func (m *Master) exitAfterRestore() {
+	log.Error("the database was restored from a backup; exiting so that the master restarts on it")
+	os.Exit(1)
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	maxHealthTables         = 50
+	maxHealthRunningQueries = 20
+)
+
+// TableHealth is how much space a table takes up and how much of it is dead rows that vacuum has
+// yet to reclaim.
+type TableHealth struct {
+	Table      string     `bun:"table_name"`
+	TotalBytes int64      `bun:"total_bytes"`
+	IndexBytes int64      `bun:"index_bytes"`
+	LiveRows   int64      `bun:"live_rows"`
+	DeadRows   int64      `bun:"dead_rows"`
+	LastVacuum *time.Time `bun:"last_vacuum"`
+}
+
+// Bloat estimates the fraction of the table taken up by dead rows.
+func (t TableHealth) Bloat() float64 {
+	if t.LiveRows+t.DeadRows == 0 {
+		return 0
+	}
+	return float64(t.DeadRows) / float64(t.LiveRows+t.DeadRows)
+}
+
+// RunningQuery is a query that a connection of the master is running.
+type RunningQuery struct {
+	PID int `bun:"pid"`
+	// State is the state of the connection, such as "active" or "idle in transaction".
+	State string `bun:"state"`
+	// WaitEvent is what the query is waiting on, such as "Lock: relation", if anything.
+	WaitEvent string    `bun:"wait_event"`
+	Query     string    `bun:"query"`
+	Started   time.Time `bun:"started"`
+	Seconds   float64   `bun:"seconds"`
+}
+
+// Duration is how long the query has been running.
+func (q RunningQuery) Duration() time.Duration {
+	return time.Duration(q.Seconds * float64(time.Second))
+}
+
+// HealthReport describes how much space the database takes up and what the master is doing with
+// it.
+type HealthReport struct {
+	SizeBytes int64
+	// Tables are the largest tables, largest first.
+	Tables []TableHealth
+	// Running are the queries the master is running, longest-running first.
+	Running []RunningQuery
+	// Slow are the recent slow queries, most recent first.
+	Slow []SlowQuery
+}
+
+// CheckHealth reports the size of the database and its largest tables, and the queries of the
+// master that are running or were recently slow.
+func CheckHealth(ctx context.Context) (*HealthReport, error) {
+	report := &HealthReport{Slow: SlowQueries()}
+	if err := Bun().NewRaw("SELECT pg_database_size(current_database())").
+		Scan(ctx, &report.SizeBytes); err != nil {
+		return nil, errors.Wrap(err, "error reading the size of the database")
+	}
+
+	if err := Bun().NewRaw(`
+SELECT
+    CASE WHEN s.schemaname = 'public' THEN s.relname ELSE s.schemaname || '.' || s.relname END
+        AS table_name,
+    pg_total_relation_size(s.relid) AS total_bytes,
+    pg_indexes_size(s.relid) AS index_bytes,
+    s.n_live_tup AS live_rows,
+    s.n_dead_tup AS dead_rows,
+    greatest(s.last_vacuum, s.last_autovacuum) AS last_vacuum
+FROM pg_stat_user_tables s
+ORDER BY total_bytes DESC
+LIMIT ?`, maxHealthTables).Scan(ctx, &report.Tables); err != nil {
+		return nil, errors.Wrap(err, "error reading the sizes of tables")
+	}
+
+	if err := Bun().NewRaw(`
+SELECT
+    pid,
+    state,
+    coalesce(wait_event_type || ': ' || wait_event, '') AS wait_event,
+    query,
+    query_start AS started,
+    extract(epoch FROM clock_timestamp() - query_start) AS seconds
+FROM pg_stat_activity
+WHERE application_name = ? AND pid <> pg_backend_pid() AND state <> 'idle'
+    AND query_start IS NOT NULL
+ORDER BY query_start
+LIMIT ?`, applicationName, maxHealthRunningQueries).Scan(ctx, &report.Running); err != nil {
+		return nil, errors.Wrap(err, "error reading running queries")
+	}
+	return report, nil
+}
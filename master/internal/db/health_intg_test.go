@@ -0,0 +1,46 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/etc"
+)
+
+func TestCheckHealth(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	ctx := context.Background()
+
+	SetSlowQueryThreshold(time.Nanosecond)
+	_, err := Bun().ExecContext(ctx, "SELECT pg_sleep(?)", 0.01)
+	require.NoError(t, err)
+	SetSlowQueryThreshold(0)
+
+	report, err := CheckHealth(ctx)
+	require.NoError(t, err)
+	require.Positive(t, report.SizeBytes)
+	require.NotEmpty(t, report.Tables)
+	for i, table := range report.Tables {
+		require.Positive(t, table.TotalBytes)
+		require.GreaterOrEqual(t, table.TotalBytes, table.IndexBytes)
+		require.GreaterOrEqual(t, table.Bloat(), 0.0)
+		require.LessOrEqual(t, table.Bloat(), 1.0)
+		if i > 0 {
+			require.LessOrEqual(t, table.TotalBytes, report.Tables[i-1].TotalBytes)
+		}
+	}
+
+	require.NotEmpty(t, report.Slow)
+	slow := report.Slow[0]
+	require.Equal(t, "SELECT pg_sleep(?)", slow.Query)
+	require.GreaterOrEqual(t, slow.Duration, 10*time.Millisecond)
+	require.False(t, slow.Failed)
+}
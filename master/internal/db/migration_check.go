@@ -0,0 +1,411 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+)
+
+// largeTableRows is how many rows a table must have for a migration that locks it to be worth
+// planning downtime for.
+const largeTableRows = 1000000
+
+// Lock modes that statements in migrations take on the tables they touch, weakest first. See
+// https://www.postgresql.org/docs/current/explicit-locking.html.
+const (
+	lockRowExclusive         = "ROW EXCLUSIVE"
+	lockShareUpdateExclusive = "SHARE UPDATE EXCLUSIVE"
+	lockShare                = "SHARE"
+	lockShareRowExclusive    = "SHARE ROW EXCLUSIVE"
+	lockAccessExclusive      = "ACCESS EXCLUSIVE"
+)
+
+var lockStrength = map[string]int{
+	lockRowExclusive:         1,
+	lockShareUpdateExclusive: 2,
+	lockShare:                3,
+	lockShareRowExclusive:    4,
+	lockAccessExclusive:      5,
+}
+
+// lockPatterns match the statements of migrations that lock a table, which is the last group of
+// each pattern, in a way worth knowing about before running them.
+var lockPatterns = []struct {
+	re   *regexp.Regexp
+	lock string
+}{
+	{
+		regexp.MustCompile(`(?i)\bCREATE\s+(?:UNIQUE\s+)?INDEX\s+CONCURRENTLY\s+` +
+			`(?:IF\s+NOT\s+EXISTS\s+)?(?:\w+\s+)?ON\s+(?:ONLY\s+)?(?:public\.)?(\w+)`),
+		lockShareUpdateExclusive,
+	},
+	{
+		regexp.MustCompile(`(?i)\bCREATE\s+(?:UNIQUE\s+)?INDEX\s+(?:IF\s+NOT\s+EXISTS\s+)?` +
+			`(?:\w+\s+)?ON\s+(?:ONLY\s+)?(?:public\.)?(\w+)`),
+		lockShare,
+	},
+	{
+		regexp.MustCompile(`(?i)\bALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?(?:public\.)?(\w+)`),
+		lockAccessExclusive,
+	},
+	{
+		regexp.MustCompile(`(?i)\bDROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:public\.)?(\w+)`),
+		lockAccessExclusive,
+	},
+	{
+		regexp.MustCompile(`(?i)\bTRUNCATE\s+(?:TABLE\s+)?(?:ONLY\s+)?(?:public\.)?(\w+)`),
+		lockAccessExclusive,
+	},
+	{
+		regexp.MustCompile(`(?i)\bCREATE\s+(?:OR\s+REPLACE\s+)?(?:CONSTRAINT\s+)?TRIGGER\s+` +
+			`[\s\S]*?\bON\s+(?:public\.)?(\w+)`),
+		lockShareRowExclusive,
+	},
+	{
+		regexp.MustCompile(`(?i)\bUPDATE\s+(?:ONLY\s+)?(?:public\.)?(\w+)\s+(?:AS\s+\w+\s+)?SET\b`),
+		lockRowExclusive,
+	},
+	{
+		regexp.MustCompile(`(?i)\bDELETE\s+FROM\s+(?:ONLY\s+)?(?:public\.)?(\w+)`),
+		lockRowExclusive,
+	},
+}
+
+var (
+	migrationsURLPattern    = regexp.MustCompile(`file://(.+)`)
+	migrationFilePattern    = regexp.MustCompile(`^(\d+)_(.+?)(\.tx)?\.up\.sql$`)
+	migrationCommentPattern = regexp.MustCompile(`--[^\n]*`)
+)
+
+// TableLock is the lock that a pending migration takes on a table that already exists, and how
+// big the table is.
+type TableLock struct {
+	Table string
+	Lock  string
+	// BlocksReads and BlocksWrites are whether the lock keeps the table from being read or
+	// written while it is held.
+	BlocksReads  bool
+	BlocksWrites bool
+	// EstimatedRows is the estimate of the planner, which is only as fresh as the table's last
+	// vacuum or analyze.
+	EstimatedRows int64
+	SizeBytes     int64
+}
+
+// Large returns whether the migration may hold the lock long enough to be noticed.
+func (l TableLock) Large() bool {
+	return l.EstimatedRows >= largeTableRows
+}
+
+// PendingMigration is a migration that has yet to be applied.
+type PendingMigration struct {
+	Version int64
+	Name    string
+	// Transactional is whether the migration runs in a transaction, which holds every lock it
+	// takes until the whole migration is done.
+	Transactional bool
+	Tables        []TableLock
+}
+
+// SchemaDrift is an object of the schema that differs from the last snapshot the master took.
+// Expected is empty for objects that were added, and Actual for objects that were removed.
+type SchemaDrift struct {
+	Object   string
+	Expected string
+	Actual   string
+}
+
+// MigrationReport describes what upgrading the database would do, and how its schema has drifted
+// from what the master expects.
+type MigrationReport struct {
+	CurrentVersion int64
+	Pending        []PendingMigration
+	// SnapshotVersion and SnapshotTime describe the snapshot that the schema is compared against,
+	// and are zero if the master has never taken one.
+	SnapshotVersion int64
+	SnapshotTime    time.Time
+	Drift           []SchemaDrift
+}
+
+// schemaSnapshot corresponds to a row in the "schema_snapshots" DB table.
+type schemaSnapshot struct {
+	bun.BaseModel `bun:"table:schema_snapshots,alias:s"`
+
+	ID      int               `bun:"id,pk,autoincrement"`
+	Version int64             `bun:"version"`
+	TakenAt time.Time         `bun:"taken_at,nullzero,notnull,default:current_timestamp"`
+	Objects map[string]string `bun:"objects,type:jsonb"`
+}
+
+// migrationsDir returns the directory of a URL of migrations.
+func migrationsDir(migrationURL string) (string, error) {
+	match := migrationsURLPattern.FindStringSubmatch(migrationURL)
+	if len(match) != 2 {
+		return "", errors.Errorf("failed to parse migrationsURL: %s", migrationURL)
+	}
+	return match[1], nil
+}
+
+// tableExists returns whether the table exists, for tables that migrations may not have created
+// yet.
+func tableExists(ctx context.Context, table string) (bool, error) {
+	var exists bool
+	err := Bun().NewRaw("SELECT to_regclass(?) IS NOT NULL", table).Scan(ctx, &exists)
+	return exists, err
+}
+
+// migrationVersion returns the version of the last migration applied to the database, or 0 if
+// none have been.
+func migrationVersion(ctx context.Context) (int64, error) {
+	if exists, err := tableExists(ctx, "gopg_migrations"); err != nil || !exists {
+		return 0, err
+	}
+	var version int64
+	err := Bun().NewRaw("SELECT version FROM gopg_migrations ORDER BY id DESC LIMIT 1").
+		Scan(ctx, &version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return version, err
+}
+
+// schemaObjects describes each object of the public schema that migrations create, keyed by its
+// kind and name. Partitions of tables and the objects of extensions are left out, since they are
+// not created by migrations.
+func schemaObjects(ctx context.Context) (map[string]string, error) {
+	var rows []struct {
+		Object     string `bun:"object"`
+		Definition string `bun:"definition"`
+	}
+	if err := Bun().NewRaw(`
+SELECT format('column %s.%s', c.relname, a.attname) AS object,
+  format_type(a.atttypid, a.atttypmod)
+    || CASE WHEN a.attnotnull THEN ' NOT NULL' ELSE '' END
+    || coalesce(' DEFAULT ' || pg_get_expr(d.adbin, d.adrelid), '') AS definition
+FROM pg_attribute a
+JOIN pg_class c ON c.oid = a.attrelid
+LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE c.relnamespace = 'public'::regnamespace AND c.relkind IN ('r', 'p', 'v', 'm')
+  AND NOT c.relispartition AND a.attnum > 0 AND NOT a.attisdropped
+UNION ALL
+SELECT format('index %s', i.relname), pg_get_indexdef(i.oid)
+FROM pg_index x
+JOIN pg_class i ON i.oid = x.indexrelid
+JOIN pg_class t ON t.oid = x.indrelid
+WHERE t.relnamespace = 'public'::regnamespace AND NOT t.relispartition
+UNION ALL
+SELECT format('constraint %s.%s', t.relname, con.conname), pg_get_constraintdef(con.oid)
+FROM pg_constraint con
+JOIN pg_class t ON t.oid = con.conrelid
+WHERE t.relnamespace = 'public'::regnamespace AND NOT t.relispartition
+UNION ALL
+SELECT format('view %s', c.relname), pg_get_viewdef(c.oid)
+FROM pg_class c
+WHERE c.relnamespace = 'public'::regnamespace AND c.relkind IN ('v', 'm')
+UNION ALL
+SELECT format('function %s', p.oid::regprocedure), md5(pg_get_functiondef(p.oid))
+FROM pg_proc p
+WHERE p.pronamespace = 'public'::regnamespace AND p.prokind IN ('f', 'p')
+  AND NOT EXISTS (
+    SELECT 1 FROM pg_depend dep WHERE dep.objid = p.oid AND dep.deptype = 'e'
+  )`).Scan(ctx, &rows); err != nil {
+		return nil, errors.Wrap(err, "error describing the schema")
+	}
+	objects := make(map[string]string, len(rows))
+	for _, r := range rows {
+		objects[r.Object] = r.Definition
+	}
+	return objects, nil
+}
+
+// RecordSchemaSnapshot takes a snapshot of the schema to later find changes made to it by hand,
+// unless one was already taken at the current version of the migrations. force takes one anyway,
+// for changes to the schema that the master makes outside of migrations.
+func RecordSchemaSnapshot(ctx context.Context, force bool) error {
+	version, err := migrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if !force {
+		exists, err := Bun().NewSelect().Model((*schemaSnapshot)(nil)).
+			Where("version = ?", version).
+			Exists(ctx)
+		if err != nil || exists {
+			return err
+		}
+	}
+	objects, err := schemaObjects(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = Bun().NewInsert().Model(&schemaSnapshot{Version: version, Objects: objects}).
+		Exec(ctx)
+	return errors.Wrap(err, "error recording schema snapshot")
+}
+
+// pendingMigrations returns the migrations in the directory after the given version, in order.
+func pendingMigrations(dir string, version int64) ([]PendingMigration, map[int64]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error reading migrations")
+	}
+	var pending []PendingMigration
+	contents := map[int64]string{}
+	for _, e := range entries {
+		match := migrationFilePattern.FindStringSubmatch(e.Name())
+		if match == nil {
+			continue
+		}
+		v, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || v <= version {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, e.Name())) // #nosec
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "error reading migration %s", e.Name())
+		}
+		pending = append(pending, PendingMigration{
+			Version:       v,
+			Name:          match[2],
+			Transactional: match[3] != "",
+		})
+		contents[v] = string(b)
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Version < pending[j].Version })
+	return pending, contents, nil
+}
+
+// migrationLocks returns the strongest lock that the statements of a migration take on each
+// table, by the name of the table.
+func migrationLocks(contents string) map[string]string {
+	contents = migrationCommentPattern.ReplaceAllString(contents, "")
+	locks := map[string]string{}
+	for _, stmt := range strings.Split(contents, ";") {
+		for _, p := range lockPatterns {
+			for _, match := range p.re.FindAllStringSubmatch(stmt, -1) {
+				table := strings.ToLower(match[len(match)-1])
+				if lockStrength[p.lock] > lockStrength[locks[table]] {
+					locks[table] = p.lock
+				}
+			}
+			// Statements that create indexes concurrently also match the pattern for indexes
+			// that are not, so only the first pattern that matches a statement counts.
+			if p.re.MatchString(stmt) {
+				break
+			}
+		}
+	}
+	return locks
+}
+
+// tableSizes returns the estimated number of rows and the size of each of the tables that exist.
+func tableSizes(ctx context.Context, tables []string) (map[string]TableLock, error) {
+	var rows []struct {
+		Table string `bun:"relname"`
+		Rows  int64  `bun:"rows"`
+		Size  int64  `bun:"size"`
+	}
+	if len(tables) > 0 {
+		if err := Bun().NewRaw(`
+SELECT c.relname, greatest(c.reltuples, 0)::bigint AS rows, pg_total_relation_size(c.oid) AS size
+FROM pg_class c
+WHERE c.relnamespace = 'public'::regnamespace AND c.relkind IN ('r', 'p') AND c.relname IN (?)`,
+			bun.In(tables)).Scan(ctx, &rows); err != nil {
+			return nil, err
+		}
+	}
+	sizes := make(map[string]TableLock, len(rows))
+	for _, r := range rows {
+		sizes[r.Table] = TableLock{Table: r.Table, EstimatedRows: r.Rows, SizeBytes: r.Size}
+	}
+	return sizes, nil
+}
+
+// CheckMigrations reports, without changing anything, the migrations from the directory URL that
+// have yet to be applied and the locks they would take on existing tables, and how the schema has
+// drifted from the last snapshot the master took of it.
+func CheckMigrations(ctx context.Context, migrationURL string) (*MigrationReport, error) {
+	dir, err := migrationsDir(migrationURL)
+	if err != nil {
+		return nil, err
+	}
+	version, err := migrationVersion(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading the version of the database")
+	}
+	report := &MigrationReport{CurrentVersion: version}
+
+	pending, contents, err := pendingMigrations(dir, version)
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range pending {
+		locks := migrationLocks(contents[m.Version])
+		tables := make([]string, 0, len(locks))
+		for t := range locks {
+			tables = append(tables, t)
+		}
+		sizes, err := tableSizes(ctx, tables)
+		if err != nil {
+			return nil, errors.Wrap(err, "error estimating the size of tables")
+		}
+		for t, lock := range locks {
+			// Tables that do not exist yet are created by the migrations, so are empty.
+			tl, ok := sizes[t]
+			if !ok {
+				continue
+			}
+			tl.Lock = lock
+			tl.BlocksReads = lock == lockAccessExclusive
+			tl.BlocksWrites = lockStrength[lock] >= lockStrength[lockShare]
+			pending[i].Tables = append(pending[i].Tables, tl)
+		}
+		sort.Slice(pending[i].Tables, func(a, b int) bool {
+			return pending[i].Tables[a].Table < pending[i].Tables[b].Table
+		})
+	}
+	report.Pending = pending
+
+	if exists, err := tableExists(ctx, "schema_snapshots"); err != nil || !exists {
+		return report, err
+	}
+	var snapshot schemaSnapshot
+	switch err := Bun().NewSelect().Model(&snapshot).Order("id DESC").Limit(1).Scan(ctx); {
+	case errors.Is(err, sql.ErrNoRows):
+		return report, nil
+	case err != nil:
+		return nil, errors.Wrap(err, "error reading the schema snapshot")
+	}
+	report.SnapshotVersion = snapshot.Version
+	report.SnapshotTime = snapshot.TakenAt
+
+	actual, err := schemaObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for object, expected := range snapshot.Objects {
+		if actual[object] != expected {
+			report.Drift = append(report.Drift, SchemaDrift{
+				Object: object, Expected: expected, Actual: actual[object],
+			})
+		}
+	}
+	for object, def := range actual {
+		if _, ok := snapshot.Objects[object]; !ok {
+			report.Drift = append(report.Drift, SchemaDrift{Object: object, Actual: def})
+		}
+	}
+	sort.Slice(report.Drift, func(i, j int) bool {
+		return report.Drift[i].Object < report.Drift[j].Object
+	})
+	return report, nil
+}
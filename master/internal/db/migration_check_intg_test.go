@@ -0,0 +1,71 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/etc"
+)
+
+func TestCheckMigrations(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	ctx := context.Background()
+
+	require.NoError(t, RecordSchemaSnapshot(ctx, true))
+	report, err := CheckMigrations(ctx, MigrationsFromDB)
+	require.NoError(t, err)
+	require.NotZero(t, report.CurrentVersion)
+	require.Empty(t, report.Pending)
+	require.Equal(t, report.CurrentVersion, report.SnapshotVersion)
+	require.Empty(t, report.Drift)
+
+	// Changes made by hand are found.
+	_, err = Bun().ExecContext(ctx, "ALTER TABLE log_retention_runs ADD COLUMN drift_test int")
+	require.NoError(t, err)
+	defer func() {
+		_, err := Bun().ExecContext(ctx, "ALTER TABLE log_retention_runs DROP COLUMN drift_test")
+		require.NoError(t, err)
+	}()
+	report, err = CheckMigrations(ctx, MigrationsFromDB)
+	require.NoError(t, err)
+	require.Equal(t, []SchemaDrift{
+		{Object: "column log_retention_runs.drift_test", Actual: "integer"},
+	}, report.Drift)
+
+	// Pending migrations report the locks they take on existing tables.
+	dir := t.TempDir()
+	version := report.CurrentVersion + 1
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, fmt.Sprintf("%d_pending.tx.up.sql", version)),
+		[]byte(`
+ALTER TABLE trials ADD COLUMN pending int;
+CREATE INDEX CONCURRENTLY ix_pending ON experiments (id);
+CREATE TABLE pending (id int);
+CREATE INDEX ix_pending_id ON pending (id);`),
+		0o600))
+	report, err = CheckMigrations(ctx, "file://"+dir)
+	require.NoError(t, err)
+	require.Len(t, report.Pending, 1)
+	pending := report.Pending[0]
+	require.Equal(t, version, pending.Version)
+	require.Equal(t, "pending", pending.Name)
+	require.True(t, pending.Transactional)
+	require.Len(t, pending.Tables, 2)
+	require.Equal(t, "experiments", pending.Tables[0].Table)
+	require.Equal(t, lockShareUpdateExclusive, pending.Tables[0].Lock)
+	require.False(t, pending.Tables[0].BlocksWrites)
+	require.Equal(t, "trials", pending.Tables[1].Table)
+	require.Equal(t, lockAccessExclusive, pending.Tables[1].Lock)
+	require.True(t, pending.Tables[1].BlocksReads)
+	require.True(t, pending.Tables[1].BlocksWrites)
+}
@@ -149,15 +149,14 @@ func (db *PgDB) Migrate(migrationURL string, actions []string) error {
 
 	log.Infof("running DB migrations from %s; this might take a while...", migrationURL)
 
-	re := regexp.MustCompile(`file://(.+)`)
-	match := re.FindStringSubmatch(migrationURL)
-	if len(match) != 2 {
-		return errors.New(fmt.Sprintf("failed to parse migrationsURL: %s", migrationURL))
+	dir, err := migrationsDir(migrationURL)
+	if err != nil {
+		return err
 	}
 
 	collection := migrations.NewCollection()
 	collection.DisableSQLAutodiscover(true)
-	if err = collection.DiscoverSQLMigrations(match[1]); err != nil {
+	if err = collection.DiscoverSQLMigrations(dir); err != nil {
 		return err
 	}
 	if len(collection.Migrations()) == 0 {
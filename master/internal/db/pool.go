@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/determined-ai/determined/master/internal/config"
+)
+
+const poolCheckPeriod = 30 * time.Second
+
+// withStatementTimeout returns a connection string for connections on which statements that run
+// longer than the timeout are canceled, or the URL itself if the timeout is 0.
+func withStatementTimeout(url string, timeout time.Duration) (string, error) {
+	if timeout <= 0 {
+		return url, nil
+	}
+	cfg, err := pgx.ParseConfig(url)
+	if err != nil {
+		return "", err
+	}
+	cfg.RuntimeParams["statement_timeout"] = strconv.FormatInt(timeout.Milliseconds(), 10)
+	return stdlib.RegisterConnConfig(cfg), nil
+}
+
+// configurePool sizes a connection pool of the database as configured.
+func configurePool(sqlDB *sql.DB, opts *config.DBConfig) {
+	sqlDB.SetMaxOpenConns(opts.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(opts.MaxIdleConns)
+	sqlDB.SetConnMaxIdleTime(time.Duration(opts.ConnMaxIdleTime))
+	sqlDB.SetConnMaxLifetime(time.Duration(opts.ConnMaxLifetime))
+}
+
+// MonitorPool warns once per check, until the context is canceled, whenever queries had to wait
+// for a free connection because every connection of the pool of the primary was in use.
+func MonitorPool(ctx context.Context) {
+	t := time.NewTicker(poolCheckPeriod)
+	defer t.Stop()
+	last := Bun().Stats()
+	for {
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+		s := Bun().Stats()
+		if waits := s.WaitCount - last.WaitCount; waits > 0 {
+			log.Warnf("database connection pool exhausted: %d queries waited %s in total for one of "+
+				"%d connections in the last %s; consider raising db.max_open_conns",
+				waits, s.WaitDuration-last.WaitDuration, s.MaxOpenConnections, poolCheckPeriod)
+		}
+		last = s
+	}
+}
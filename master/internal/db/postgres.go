@@ -62,6 +62,7 @@ func initTheOneBun(db *sql.DB) {
 
 	// This will print only the failed queries.
 	theOneBun.AddQueryHook(bundebug.NewQueryHook())
+	theOneBun.AddQueryHook(slowQueryHook{})
 }
 
 func setTokenKeys(tk *model.AuthTokenKeypair) {
@@ -163,6 +164,11 @@ type PgDB struct {
 	sql       *sqlx.DB
 	queries   *staticQueryMap
 	url       string
+
+	// trainingMetrics queues reports of training metrics for the workers that insert them in
+	// batches, which are started along with it.
+	trainingMetrics     chan trainingMetricsReport
+	trainingMetricsOnce sync.Once
 }
 
 // ConnectPostgres connects to a Postgres database.
@@ -193,6 +199,9 @@ const (
 	// insert/update violates a foreign key constraint.  Obtained from:
 	// https://www.postgresql.org/docs/10/errcodes-appendix.html
 	CodeForeignKeyViolation = "23503"
+	// CodeInvalidRegularExpression is the error code that Postgres uses to indicate that a
+	// regular expression could not be compiled.
+	CodeInvalidRegularExpression = "2201B"
 )
 
 // Close closes the underlying pq connection.
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// AddArtifact persists a new artifact.
+func AddArtifact(ctx context.Context, a *model.Artifact) error {
+	_, err := Bun().NewInsert().Model(a).Exec(ctx)
+	return err
+}
+
+// ArtifactByUUID returns the artifact with the given UUID, or ErrNotFound if there is none.
+func ArtifactByUUID(ctx context.Context, id uuid.UUID) (*model.Artifact, error) {
+	var a model.Artifact
+	if err := Bun().NewSelect().Model(&a).Where("uuid = ?", id).Scan(ctx); err != nil {
+		return nil, MatchSentinelError(err)
+	}
+	return &a, nil
+}
+
+// TaskArtifacts returns the artifacts of a task, oldest first.
+func TaskArtifacts(ctx context.Context, taskID model.TaskID) ([]model.Artifact, error) {
+	artifacts := []model.Artifact{}
+	if err := Bun().NewSelect().Model(&artifacts).
+		Where("task_id = ?", taskID).
+		Order("report_time ASC", "uuid ASC").
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	return artifacts, nil
+}
@@ -1,12 +1,14 @@
 package db
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
 	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
 )
 
 // CheckpointByUUID looks up a checkpoint by UUID, returning nil if none exists.
@@ -22,6 +24,102 @@ func (db *PgDB) CheckpointByUUID(id uuid.UUID) (*model.Checkpoint, error) {
 	return &checkpoint, nil
 }
 
+// ExperimentBestCheckpoint looks up the best checkpoint of an experiment by its best checkpoint
+// policy, falling back to the completed checkpoint with the best searcher metric for experiments
+// that have not had one chosen, and returning nil if no checkpoint has one.
+func (db *PgDB) ExperimentBestCheckpoint(
+	experimentID int, smallerIsBetter bool,
+) (*model.Checkpoint, error) {
+	sign := 1
+	if !smallerIsBetter {
+		sign = -1
+	}
+	var checkpoint model.Checkpoint
+	if err := db.query(`
+	SELECT c.* FROM checkpoints_view c
+	JOIN experiments e ON e.id = c.experiment_id
+	WHERE c.experiment_id = $1 AND c.state = 'COMPLETED'
+	  AND (c.searcher_metric IS NOT NULL OR c.uuid = e.best_checkpoint_uuid)
+	ORDER BY (c.uuid = e.best_checkpoint_uuid) IS TRUE DESC, c.searcher_metric * $2 ASC,
+	  c.report_time DESC
+	LIMIT 1`, &checkpoint, experimentID, sign); errors.Cause(err) == ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "error querying for best checkpoint of experiment %d",
+			experimentID)
+	}
+	return &checkpoint, nil
+}
+
+// UpdateExperimentBestCheckpoint chooses the best checkpoint of an experiment by its best
+// checkpoint policy and records it on the experiment, or clears it if no checkpoint qualifies.
+// It should be called whenever a validation or checkpoint that could change the choice is
+// reported or deleted.
+func (db *PgDB) UpdateExperimentBestCheckpoint(ctx context.Context, experimentID int) error {
+	config, err := db.ExperimentConfig(experimentID)
+	if err != nil {
+		return errors.Wrapf(err, "getting config of experiment %d", experimentID)
+	}
+	policy := config.BestCheckpointPolicy()
+	sign := 1
+	if !policy.SmallerIsBetter {
+		sign = -1
+	}
+
+	// Lengths in records or epochs depend on the batch size, which may differ between trials.
+	var minBatches, minRecords uint64
+	if l := policy.MinLength; l != nil {
+		switch l.Unit {
+		case expconf.Batches:
+			minBatches = l.Units
+		case expconf.Records:
+			minRecords = l.Units
+		case expconf.Epochs:
+			if config.RawRecordsPerEpoch == nil {
+				return fmt.Errorf("experiment %d has a best checkpoint min_length in epochs "+
+					"but no records_per_epoch", experimentID)
+			}
+			minRecords = l.Units * uint64(*config.RawRecordsPerEpoch)
+		}
+	}
+	minRecordsFilter := ""
+	if minRecords > 0 {
+		minRecordsFilter = fmt.Sprintf(
+			"AND c.steps_completed * (c.hparams->>'global_batch_size')::float8 >= %d", minRecords)
+	}
+
+	if _, err := Bun().ExecContext(ctx, `
+WITH smoothed AS (
+  SELECT v.trial_id, v.total_batches,
+    avg((v.metrics->'validation_metrics'->>?0)::float8) OVER (
+      PARTITION BY v.trial_id ORDER BY v.total_batches
+      ROWS BETWEEN ?1 PRECEDING AND CURRENT ROW
+    ) AS metric
+  FROM validations v
+  JOIN trials t ON t.id = v.trial_id
+  WHERE t.experiment_id = ?2
+    AND v.state = 'COMPLETED'
+    AND jsonb_typeof(v.metrics->'validation_metrics'->?0) = 'number'
+)
+UPDATE experiments SET best_checkpoint_uuid = (
+  SELECT c.uuid
+  FROM checkpoints_view c
+  JOIN smoothed s ON s.trial_id = c.trial_id AND s.total_batches = c.steps_completed
+  WHERE c.experiment_id = ?2
+    AND c.state = 'COMPLETED'
+    AND c.steps_completed >= ?3
+    `+minRecordsFilter+`
+  ORDER BY s.metric * ?4 ASC, c.report_time DESC
+  LIMIT 1
+)
+WHERE id = ?2`,
+		policy.Metric, policy.SmoothingWindow-1, experimentID, minBatches, sign,
+	); err != nil {
+		return errors.Wrapf(err, "updating best checkpoint of experiment %d", experimentID)
+	}
+	return nil
+}
+
 // CheckpointByUUIDs looks up a checkpoint by list of UUIDS, returning nil if error.
 func (db *PgDB) CheckpointByUUIDs(ckptUUIDs []uuid.UUID) ([]model.Checkpoint, error) {
 	var checkpoints []model.Checkpoint
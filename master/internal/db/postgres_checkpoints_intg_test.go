@@ -12,11 +12,14 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/determined-ai/determined/master/pkg/etc"
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/proto/pkg/checkpointv1"
+	"github.com/determined-ai/determined/proto/pkg/commonv1"
 	"github.com/determined-ai/determined/proto/pkg/modelv1"
+	"github.com/determined-ai/determined/proto/pkg/trialv1"
 )
 
 func sortUUIDSlice(uuids []uuid.UUID) {
@@ -133,3 +136,59 @@ func TestDeleteCheckpoints(t *testing.T) {
 	require.Equal(t, numValidDCheckpoints, numDStateCheckpoints,
 		"didn't correctly delete the valid checkpoints")
 }
+
+func TestUpdateExperimentBestCheckpoint(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	ctx := context.Background()
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+
+	// Each trial checkpoints after its validation, and the lower the metric the better.
+	var ckpts []uuid.UUID
+	for _, metric := range []float64{0.5, 0.2, 0.8} {
+		tr := RequireMockTrial(t, db, exp)
+		allocation := RequireMockAllocation(t, db, tr.TaskID)
+		require.NoError(t, db.AddValidationMetrics(ctx, &trialv1.TrialMetrics{
+			TrialId:        int32(tr.ID),
+			StepsCompleted: 10,
+			Metrics: &commonv1.Metrics{
+				AvgMetrics: &structpb.Struct{Fields: map[string]*structpb.Value{
+					defaultSearcherMetric: structpb.NewNumberValue(metric),
+				}},
+				BatchMetrics: []*structpb.Struct{},
+			},
+		}))
+		ckpt := MockModelCheckpoint(uuid.New(), tr, allocation)
+		require.NoError(t, db.AddCheckpointMetadata(ctx, &ckpt))
+		ckpts = append(ckpts, ckpt.UUID)
+	}
+
+	bestCheckpoint := func() *uuid.UUID {
+		var best *uuid.UUID
+		require.NoError(t, Bun().NewSelect().Table("experiments").
+			Column("best_checkpoint_uuid").
+			Where("id = ?", exp.ID).
+			Scan(ctx, &best))
+		return best
+	}
+	require.Nil(t, bestCheckpoint())
+	require.NoError(t, db.UpdateExperimentBestCheckpoint(ctx, exp.ID))
+	require.Equal(t, ckpts[1], *bestCheckpoint())
+
+	// The tagged checkpoint is the one reported as the best.
+	best, err := db.ExperimentBestCheckpoint(exp.ID, true)
+	require.NoError(t, err)
+	require.Equal(t, ckpts[1], *best.UUID)
+
+	// No checkpoint is best if none was taken after the minimum length of training.
+	_, err = Bun().NewUpdate().Table("experiments").
+		Set("config = jsonb_set(config, '{best_checkpoint}', ?)",
+			`{"min_length": {"batches": 100}}`).
+		Where("id = ?", exp.ID).
+		Exec(ctx)
+	require.NoError(t, err)
+	require.NoError(t, db.UpdateExperimentBestCheckpoint(ctx, exp.ID))
+	require.Nil(t, bestCheckpoint())
+}
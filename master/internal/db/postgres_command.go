@@ -30,3 +30,23 @@ func GetCommandOwnerID(ctx context.Context, taskID model.TaskID) (model.UserID,
 
 	return ownerIDBun.OwnerID, nil
 }
+
+// GetTaskOwnerID gets the ownerID of the job a task belongs to. Unlike GetCommandOwnerID, this
+// works for any task, such as trials. Returns db.ErrNotFound if the task does not exist or its job
+// has no owner.
+func GetTaskOwnerID(ctx context.Context, taskID model.TaskID) (model.UserID, error) {
+	var ownerID model.UserID
+	if err := Bun().NewSelect().
+		ColumnExpr("j.owner_id").
+		TableExpr("tasks AS t").
+		Join("JOIN jobs AS j ON j.job_id = t.job_id").
+		Where("t.task_id = ?", taskID).
+		Where("j.owner_id IS NOT NULL").
+		Scan(ctx, &ownerID); err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	return ownerID, nil
+}
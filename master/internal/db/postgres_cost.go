@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// SlotHoursGroupBy is what a slot hours report is grouped by.
+type SlotHoursGroupBy string
+
+const (
+	// SlotHoursByExperiment groups slot hours by experiment.
+	SlotHoursByExperiment SlotHoursGroupBy = "experiment"
+	// SlotHoursByUser groups slot hours by the owner of the experiment.
+	SlotHoursByUser SlotHoursGroupBy = "user"
+	// SlotHoursByWorkspace groups slot hours by the workspace of the experiment.
+	SlotHoursByWorkspace SlotHoursGroupBy = "workspace"
+)
+
+// TrialSlotHours is the slot hours that the allocations of a trial used in a resource pool.
+type TrialSlotHours struct {
+	TrialID int `bun:"trial_id"`
+	model.SlotHours
+}
+
+// GroupSlotHours is the slot hours that the trials of an experiment, user, or workspace used in a
+// resource pool.
+type GroupSlotHours struct {
+	ID   int    `bun:"id"`
+	Name string `bun:"name"`
+	model.SlotHours
+}
+
+// allocationEnd is the end of an allocation, which is now for allocations still running.
+const allocationEnd = "COALESCE(a.end_time, now() AT TIME ZONE 'UTC')"
+
+// ExperimentSlotHours returns the slot hours that the trials of an experiment used, by trial and
+// resource pool.
+func ExperimentSlotHours(ctx context.Context, experimentID int) ([]TrialSlotHours, error) {
+	hours := []TrialSlotHours{}
+	err := Bun().NewSelect().TableExpr("trials AS t").
+		Join("JOIN allocations AS a ON a.task_id = t.task_id").
+		ColumnExpr("t.id AS trial_id").
+		ColumnExpr("a.resource_pool").
+		ColumnExpr("sum(a.slots * extract(epoch FROM "+allocationEnd+
+			" - a.start_time)) / 3600 AS slot_hours").
+		Where("t.experiment_id = ?", experimentID).
+		Where("a.start_time IS NOT NULL").
+		GroupExpr("t.id, a.resource_pool").
+		OrderExpr("t.id, a.resource_pool").
+		Scan(ctx, &hours)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting slot hours of experiment %d", experimentID)
+	}
+	return hours, nil
+}
+
+// SlotHoursReport returns the slot hours that trials used in [start, end), by resource pool and
+// the experiment, user, or workspace they belong to. Allocations that overlap the period are only
+// counted for the part of them within it.
+func SlotHoursReport(
+	ctx context.Context, groupBy SlotHoursGroupBy, start, end time.Time,
+) ([]GroupSlotHours, error) {
+	q := Bun().NewSelect().TableExpr("trials AS t").
+		Join("JOIN allocations AS a ON a.task_id = t.task_id").
+		Join("JOIN experiments AS e ON e.id = t.experiment_id")
+	switch groupBy {
+	case SlotHoursByExperiment:
+		q = q.ColumnExpr("e.id").ColumnExpr("e.config->>'name' AS name")
+	case SlotHoursByUser:
+		q = q.Join("JOIN users AS u ON u.id = e.owner_id").
+			ColumnExpr("u.id").ColumnExpr("u.username AS name")
+	case SlotHoursByWorkspace:
+		q = q.Join("JOIN projects AS p ON p.id = e.project_id").
+			Join("JOIN workspaces AS w ON w.id = p.workspace_id").
+			ColumnExpr("w.id").ColumnExpr("w.name")
+	default:
+		return nil, errors.Errorf("unknown grouping of slot hours: %s", groupBy)
+	}
+
+	hours := []GroupSlotHours{}
+	err := q.ColumnExpr("a.resource_pool").
+		ColumnExpr("sum(a.slots * extract(epoch FROM least("+allocationEnd+", ?) - "+
+			"greatest(a.start_time, ?))) / 3600 AS slot_hours", end, start).
+		Where("a.start_time < ?", end).
+		Where(allocationEnd+" > ?", start).
+		GroupExpr("1, 2, 3").
+		OrderExpr("1, 3").
+		Scan(ctx, &hours)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting slot hours by %s", groupBy)
+	}
+	return hours, nil
+}
@@ -0,0 +1,79 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+)
+
+func TestSlotHours(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	ctx := context.Background()
+
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+	first, second := RequireMockTrial(t, db, exp), RequireMockTrial(t, db, exp)
+
+	// Pick a period that no other test runs allocations in.
+	start := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+	addAllocation := func(tr *model.Trial, pool string, slots int, from, to time.Duration) {
+		a := model.Allocation{
+			AllocationID: model.AllocationID(fmt.Sprintf("%s.%s.%d", tr.TaskID, pool, from)),
+			TaskID:       tr.TaskID,
+			Slots:        slots,
+			ResourcePool: pool,
+			StartTime:    ptrs.Ptr(start.Add(from)),
+		}
+		require.NoError(t, db.AddAllocation(&a))
+		a.EndTime = ptrs.Ptr(start.Add(to))
+		require.NoError(t, db.CompleteAllocation(&a))
+	}
+	addAllocation(first, "gpu", 4, 0, 2*time.Hour)
+	addAllocation(first, "gpu", 4, 3*time.Hour, 4*time.Hour)
+	addAllocation(first, "cpu", 1, 0, 30*time.Minute)
+	addAllocation(second, "gpu", 2, time.Hour, 5*time.Hour)
+
+	hours, err := ExperimentSlotHours(ctx, exp.ID)
+	require.NoError(t, err)
+	require.Len(t, hours, 3)
+	require.Equal(t, first.ID, hours[0].TrialID)
+	require.Equal(t, "cpu", hours[0].ResourcePool)
+	require.InDelta(t, 0.5, hours[0].SlotHours, 1e-9)
+	require.Equal(t, "gpu", hours[1].ResourcePool)
+	require.InDelta(t, 12, hours[1].SlotHours, 1e-9)
+	require.Equal(t, second.ID, hours[2].TrialID)
+	require.InDelta(t, 8, hours[2].SlotHours, 1e-9)
+
+	// Only the first three hours count, including part of the allocation of the second trial.
+	report, err := SlotHoursReport(ctx, SlotHoursByUser, start, start.Add(3*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, report, 2)
+	require.Equal(t, int(user.ID), report[0].ID)
+	require.Equal(t, user.Username, report[0].Name)
+	require.Equal(t, "cpu", report[0].ResourcePool)
+	require.InDelta(t, 0.5, report[0].SlotHours, 1e-9)
+	require.Equal(t, "gpu", report[1].ResourcePool)
+	require.InDelta(t, 12, report[1].SlotHours, 1e-9)
+
+	report, err = SlotHoursReport(
+		ctx, SlotHoursByExperiment, start.Add(4*time.Hour), start.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, report, 1)
+	require.Equal(t, exp.ID, report[0].ID)
+	require.InDelta(t, 2, report[0].SlotHours, 1e-9)
+
+	_, err = SlotHoursReport(ctx, "project", start, start.Add(time.Hour))
+	require.Error(t, err)
+}
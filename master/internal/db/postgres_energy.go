@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// TrialGPUEnergy is the energy that the GPUs of the allocations of a trial used in a resource
+// pool.
+type TrialGPUEnergy struct {
+	TrialID      int     `bun:"trial_id"`
+	ResourcePool string  `bun:"resource_pool"`
+	Joules       float64 `bun:"joules"`
+}
+
+// AddAllocationGPUEnergy adds to the energy that the GPUs of an allocation used.
+func AddAllocationGPUEnergy(
+	ctx context.Context, allocationID model.AllocationID, joules float64,
+) error {
+	_, err := Bun().ExecContext(ctx, `
+INSERT INTO allocation_gpu_energy (allocation_id, joules) VALUES (?, ?)
+ON CONFLICT (allocation_id) DO UPDATE SET joules = allocation_gpu_energy.joules + EXCLUDED.joules
+`, allocationID, joules)
+	if err != nil {
+		return errors.Wrapf(err, "adding GPU energy of allocation %s", allocationID)
+	}
+	return nil
+}
+
+// ExperimentGPUEnergy returns the energy that the GPUs of the trials of an experiment used, by
+// trial and resource pool.
+func ExperimentGPUEnergy(ctx context.Context, experimentID int) ([]TrialGPUEnergy, error) {
+	energy := []TrialGPUEnergy{}
+	err := Bun().NewSelect().TableExpr("trials AS t").
+		Join("JOIN allocations AS a ON a.task_id = t.task_id").
+		Join("JOIN allocation_gpu_energy AS g ON g.allocation_id = a.allocation_id").
+		ColumnExpr("t.id AS trial_id").
+		ColumnExpr("a.resource_pool").
+		ColumnExpr("sum(g.joules) AS joules").
+		Where("t.experiment_id = ?", experimentID).
+		GroupExpr("t.id, a.resource_pool").
+		OrderExpr("t.id, a.resource_pool").
+		Scan(ctx, &energy)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting GPU energy of experiment %d", experimentID)
+	}
+	return energy, nil
+}
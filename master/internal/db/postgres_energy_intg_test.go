@@ -0,0 +1,41 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/etc"
+)
+
+func TestExperimentGPUEnergy(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	ctx := context.Background()
+
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+	first, second := RequireMockTrial(t, db, exp), RequireMockTrial(t, db, exp)
+	firstAlloc := RequireMockAllocation(t, db, first.TaskID)
+	RequireMockAllocation(t, db, second.TaskID)
+
+	energy, err := ExperimentGPUEnergy(ctx, exp.ID)
+	require.NoError(t, err)
+	require.Empty(t, energy)
+
+	// Energy accumulates across measurements.
+	require.NoError(t, AddAllocationGPUEnergy(ctx, firstAlloc.AllocationID, 1000))
+	require.NoError(t, AddAllocationGPUEnergy(ctx, firstAlloc.AllocationID, 500))
+
+	energy, err = ExperimentGPUEnergy(ctx, exp.ID)
+	require.NoError(t, err)
+	require.Len(t, energy, 1)
+	require.Equal(t, first.ID, energy[0].TrialID)
+	require.Equal(t, firstAlloc.ResourcePool, energy[0].ResourcePool)
+	require.InDelta(t, 1500, energy[0].Joules, 1e-9)
+}
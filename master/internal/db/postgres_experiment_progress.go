@@ -0,0 +1,29 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ExperimentBatches returns the batches that the trials of an experiment have trained in total,
+// and how many of them were trained after since.
+func ExperimentBatches(
+	ctx context.Context, experimentID int, since time.Time,
+) (total, recent int64, err error) {
+	err = Bun().NewRaw(`
+SELECT coalesce(sum(latest), 0), coalesce(sum(latest - earlier), 0)
+FROM (
+  SELECT max(s.total_batches) AS latest,
+    coalesce(max(s.total_batches) FILTER (WHERE s.end_time <= ?), 0) AS earlier
+  FROM steps s
+  JOIN trials t ON t.id = s.trial_id
+  WHERE t.experiment_id = ?
+  GROUP BY s.trial_id
+) per_trial`, since, experimentID).Scan(ctx, &total, &recent)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "getting batches trained by experiment %d", experimentID)
+	}
+	return total, recent, nil
+}
@@ -0,0 +1,56 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/proto/pkg/commonv1"
+	"github.com/determined-ai/determined/proto/pkg/trialv1"
+)
+
+func TestExperimentBatches(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	ctx := context.Background()
+
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+	first, second := RequireMockTrial(t, db, exp), RequireMockTrial(t, db, exp)
+	addStep := func(trialID, batches int) {
+		require.NoError(t, db.AddTrainingMetrics(ctx, &trialv1.TrialMetrics{
+			TrialId:        int32(trialID),
+			StepsCompleted: int32(batches),
+			Metrics: &commonv1.Metrics{
+				AvgMetrics:   &structpb.Struct{Fields: map[string]*structpb.Value{}},
+				BatchMetrics: []*structpb.Struct{},
+			},
+		}))
+	}
+
+	total, recent, err := ExperimentBatches(ctx, exp.ID, time.Now())
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Zero(t, recent)
+
+	addStep(first.ID, 100)
+	addStep(first.ID, 200)
+	// Steps are timestamped by the database, so take the time from its clock.
+	var since time.Time
+	require.NoError(t, Bun().NewRaw("SELECT now()").Scan(ctx, &since))
+	addStep(first.ID, 300)
+	addStep(second.ID, 50)
+
+	total, recent, err = ExperimentBatches(ctx, exp.ID, since)
+	require.NoError(t, err)
+	require.Equal(t, int64(350), total)
+	require.Equal(t, int64(150), recent)
+}
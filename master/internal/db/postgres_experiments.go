@@ -13,6 +13,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
 
 	"github.com/determined-ai/determined/master/internal/lttb"
 	"github.com/determined-ai/determined/master/pkg/model"
@@ -52,6 +53,15 @@ func (db *PgDB) ProjectByName(workspaceName string, projectName string) (int, er
 	return int(p.Id), nil
 }
 
+// ProjectWorkspaceID returns the ID of the workspace a project belongs to.
+func ProjectWorkspaceID(ctx context.Context, projectID int) (int, error) {
+	var workspaceID int
+	err := Bun().NewSelect().Table("projects").Column("workspace_id").
+		Where("id = ?", projectID).
+		Scan(ctx, &workspaceID)
+	return workspaceID, err
+}
+
 // ProjectExperiments returns a list of experiments within a project.
 func (db *PgDB) ProjectExperiments(id int) (experiments []*model.Experiment, err error) {
 	rows, err := db.sql.Queryx(`
@@ -950,6 +960,102 @@ WHERE t.task_id = ?`, taskID).Scan(ctx, &experiment); err != nil {
 	return &experiment, nil
 }
 
+// BestValidation is the best validation of an experiment by its searcher metric.
+type BestValidation struct {
+	ExperimentID   int
+	TrialID        int
+	HParams        map[string]interface{} `bun:"hparams,type:jsonb"`
+	Metrics        map[string]interface{} `bun:"metrics,type:jsonb"`
+	SearcherMetric float64
+}
+
+// BestValidations returns the best validation of each of the experiments that has one.
+func BestValidations(ctx context.Context, experimentIDs []int) ([]BestValidation, error) {
+	vs := []BestValidation{}
+	if len(experimentIDs) == 0 {
+		return vs, nil
+	}
+	if err := Bun().NewRaw(`
+WITH searcher_info AS (
+  SELECT id, config->'searcher'->>'metric' AS metric_name,
+    CASE WHEN coalesce((config->'searcher'->>'smaller_is_better')::boolean, true)
+      THEN 1 ELSE -1 END AS sign
+  FROM experiments
+  WHERE id IN (?)
+)
+SELECT DISTINCT ON (s.id)
+  s.id AS experiment_id, t.id AS trial_id, t.hparams,
+  v.metrics->'validation_metrics' AS metrics,
+  (v.metrics->'validation_metrics'->>s.metric_name)::float8 AS searcher_metric
+FROM searcher_info s
+  JOIN trials t ON t.experiment_id = s.id
+  JOIN validations v ON v.trial_id = t.id
+WHERE v.state = 'COMPLETED'
+  AND jsonb_typeof(v.metrics->'validation_metrics'->s.metric_name) = 'number'
+ORDER BY s.id, (v.metrics->'validation_metrics'->>s.metric_name)::float8 * s.sign ASC`,
+		bun.In(experimentIDs)).Scan(ctx, &vs); err != nil {
+		return nil, errors.Wrap(err, "querying best validations")
+	}
+	return vs, nil
+}
+
+// ExperimentMetric is the value of a metric that a trial reported at a point in its training.
+// Value is nil for metrics that are not numbers.
+type ExperimentMetric struct {
+	TrialID      int
+	Group        string
+	TotalBatches int
+	EndTime      *time.Time
+	Name         string
+	Value        *float64
+}
+
+// ForEachExperimentMetric calls f with every metric reported by the trials of an experiment,
+// ordered by trial, metric group, batch, and name. Rows are streamed from the database rather than
+// loaded at once, so that experiments with many metrics can be exported in bounded memory.
+func ForEachExperimentMetric(
+	ctx context.Context, experimentID int, f func(ExperimentMetric) error,
+) error {
+	rows, err := Bun().QueryContext(ctx, `
+WITH trial_ids AS (
+  SELECT id FROM trials WHERE experiment_id = ?
+), reports AS (
+  SELECT trial_id, 'training' AS metric_group, total_batches, end_time,
+    metrics->'avg_metrics' AS metrics
+  FROM steps WHERE trial_id IN (SELECT id FROM trial_ids)
+  UNION ALL
+  SELECT trial_id, 'validation', total_batches, end_time, metrics->'validation_metrics'
+  FROM validations WHERE trial_id IN (SELECT id FROM trial_ids)
+  UNION ALL
+  SELECT trial_id, metric_group, total_batches, end_time, metrics->'avg_metrics'
+  FROM generic_metrics WHERE trial_id IN (SELECT id FROM trial_ids)
+)
+SELECT r.trial_id, r.metric_group, r.total_batches, r.end_time, m.key,
+  CASE
+    WHEN jsonb_typeof(m.value) = 'number' THEN (m.value #>> '{}')::float8
+    WHEN m.value #>> '{}' IN ('NaN', 'Infinity', '-Infinity') THEN (m.value #>> '{}')::float8
+  END
+FROM reports r, jsonb_each(CASE WHEN jsonb_typeof(r.metrics) = 'object' THEN r.metrics END) m
+ORDER BY r.trial_id, r.metric_group, r.total_batches, m.key`, experimentID)
+	if err != nil {
+		return errors.Wrapf(err, "querying metrics of experiment %d", experimentID)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m ExperimentMetric
+		if err := rows.Scan(
+			&m.TrialID, &m.Group, &m.TotalBatches, &m.EndTime, &m.Name, &m.Value,
+		); err != nil {
+			return errors.Wrapf(err, "scanning metrics of experiment %d", experimentID)
+		}
+		if err := f(m); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 // ExperimentIDByTrialID looks up an experiment ID by a trial ID.
 func (db *PgDB) ExperimentIDByTrialID(trialID int) (int, error) {
 	var experimentID int
@@ -1150,6 +1256,13 @@ WHERE trial_id IN (SELECT id FROM trials WHERE experiment_id = $1)
 			return errors.Wrapf(err, "error deleting validations for experiment %v", id)
 		}
 
+		if _, err := tx.Exec(`
+DELETE FROM raw_generic_metrics
+WHERE trial_id IN (SELECT id FROM trials WHERE experiment_id = $1)
+`, id); err != nil {
+			return errors.Wrapf(err, "error deleting generic metrics for experiment %v", id)
+		}
+
 		if _, err := tx.Exec(`
 DELETE FROM raw_checkpoints
 WHERE trial_id IN (SELECT id FROM trials WHERE experiment_id = $1)
@@ -1350,7 +1463,8 @@ WITH const AS (
                 WHEN coalesce((config->'searcher'->>'smaller_is_better')::boolean, true)
                 THEN 1
                 ELSE -1
-            END) AS sign
+            END) AS sign,
+           best_checkpoint_uuid
     FROM experiments WHERE id = $1
 ), selected_checkpoints AS (
     SELECT *
@@ -1360,15 +1474,18 @@ WITH const AS (
                -- rank, which could cause more than the desired number of checkpoints to be
                -- left out of the result set. Also, any rows with null validation values
                -- will sort to the end, thereby not affecting the ranks of rows with
-               -- non-null validations, and will be filtered out later.
+               -- non-null validations, and will be filtered out later. The best checkpoint by
+               -- the experiment's best checkpoint policy ranks ahead of all others.
                rank() OVER (
                    ORDER BY
+                       (uuid = const.best_checkpoint_uuid) IS NOT TRUE,
                        const.sign * (step->'validation'->'metrics'->'validation_metrics'
                                      ->>const.metric_name)::float8 ASC NULLS LAST, id ASC
                ) AS experiment_rank,
                rank() OVER (
                    PARTITION BY trial_id
                    ORDER BY
+                       (uuid = const.best_checkpoint_uuid) IS NOT TRUE,
                        const.sign * (step->'validation'->'metrics'->'validation_metrics'
                                      ->>const.metric_name)::float8 ASC NULLS LAST, id ASC
                ) AS trial_rank,
@@ -1409,7 +1526,8 @@ WITH const AS (
           AND ((c.experiment_rank > $2
                 AND c.trial_rank > $3)
                OR (c.step->'validation'->'metrics'->'validation_metrics'->>const.metric_name
-                   IS NULL))
+                   IS NULL
+                   AND c.uuid IS DISTINCT FROM const.best_checkpoint_uuid))
 )
 SELECT selected_checkpoints.uuid AS ID from selected_checkpoints;`
 
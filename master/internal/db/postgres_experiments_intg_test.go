@@ -215,3 +215,55 @@ func TestCheckpointMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestForEachExperimentMetric(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+	tr := RequireMockTrial(t, db, exp)
+
+	metrics := func(fields map[string]*structpb.Value) *trialv1.TrialMetrics {
+		return &trialv1.TrialMetrics{
+			TrialId:        int32(tr.ID),
+			StepsCompleted: 10,
+			Metrics: &commonv1.Metrics{
+				AvgMetrics:   &structpb.Struct{Fields: fields},
+				BatchMetrics: []*structpb.Struct{},
+			},
+		}
+	}
+	ctx := context.TODO()
+	require.NoError(t, db.AddTrainingMetrics(ctx, metrics(map[string]*structpb.Value{
+		"loss": structpb.NewNumberValue(0.5),
+		"note": structpb.NewStringValue("warmup"),
+	})))
+	require.NoError(t, db.AddValidationMetrics(ctx, metrics(map[string]*structpb.Value{
+		"val_loss": structpb.NewNumberValue(0.25),
+	})))
+	require.NoError(t, db.AddGenericMetrics(ctx, "inference", metrics(map[string]*structpb.Value{
+		"latency": structpb.NewNumberValue(3),
+	})))
+
+	var actual []ExperimentMetric
+	require.NoError(t, ForEachExperimentMetric(ctx, exp.ID, func(m ExperimentMetric) error {
+		require.NotNil(t, m.EndTime)
+		m.EndTime = nil
+		actual = append(actual, m)
+		return nil
+	}))
+	value := func(v float64) *float64 { return &v }
+	require.Equal(t, []ExperimentMetric{
+		{TrialID: tr.ID, Group: "inference", TotalBatches: 10, Name: "latency", Value: value(3)},
+		{TrialID: tr.ID, Group: "training", TotalBatches: 10, Name: "loss", Value: value(0.5)},
+		{TrialID: tr.ID, Group: "training", TotalBatches: 10, Name: "note"},
+		{TrialID: tr.ID, Group: "validation", TotalBatches: 10, Name: "val_loss", Value: value(0.25)},
+	}, actual)
+
+	stop := fmt.Errorf("stop")
+	require.Equal(t, stop, ForEachExperimentMetric(ctx, exp.ID, func(ExperimentMetric) error {
+		return stop
+	}))
+}
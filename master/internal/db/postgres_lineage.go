@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// TrialLineage is what the lineage of a model version records about the trial that produced its
+// checkpoint.
+type TrialLineage struct {
+	ID                      int
+	TaskID                  model.TaskID
+	HParams                 map[string]interface{} `bun:"hparams,type:jsonb"`
+	Seed                    int64
+	State                   model.State
+	StartTime               time.Time
+	EndTime                 *time.Time
+	WarmStartCheckpointUUID *string
+}
+
+// ExperimentLineage is what the lineage of a model version records about an experiment: how it
+// was configured, the code it ran, and where that code came from.
+type ExperimentLineage struct {
+	ID                  int
+	Name                string
+	State               model.State
+	Config              map[string]interface{} `bun:"config,type:jsonb"`
+	ConfigHash          string
+	ModelDefinitionHash string
+	ParentID            *int
+	GitRemote           *string
+	GitCommit           *string
+	GitCommitter        *string
+	GitCommitDate       *time.Time
+	Username            string
+	ProjectID           int
+	WorkspaceID         int
+	StartTime           time.Time
+	EndTime             *time.Time
+}
+
+// TrialLineageByID returns the lineage of a trial.
+func TrialLineageByID(ctx context.Context, id int) (*TrialLineage, error) {
+	var t TrialLineage
+	switch err := Bun().NewRaw(`
+SELECT
+  t.id, t.task_id, t.hparams, t.seed, t.state, t.start_time, t.end_time,
+  coalesce(new_ckpt.uuid::text, old_ckpt.uuid::text) AS warm_start_checkpoint_uuid
+FROM trials t
+  LEFT JOIN raw_checkpoints old_ckpt ON old_ckpt.id = t.warm_start_checkpoint_id
+  LEFT JOIN checkpoints_v2 new_ckpt ON new_ckpt.id = t.warm_start_checkpoint_id
+WHERE t.id = ?`, id).Scan(ctx, &t); {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrNotFound
+	case err != nil:
+		return nil, errors.Wrapf(err, "querying lineage of trial %d", id)
+	}
+	return &t, nil
+}
+
+// ExperimentLineages returns the lineage of an experiment followed by those of the experiments
+// it was forked from, nearest first. The hashes of configurations and model definitions are
+// computed by the database, so that model definitions are not loaded to hash them.
+func ExperimentLineages(ctx context.Context, id int) ([]ExperimentLineage, error) {
+	es := []ExperimentLineage{}
+	if err := Bun().NewRaw(`
+WITH RECURSIVE ancestry AS (
+  SELECT id, parent_id, 0 AS depth FROM experiments WHERE id = ?
+  UNION ALL
+  SELECT e.id, e.parent_id, a.depth + 1
+  FROM experiments e JOIN ancestry a ON e.id = a.parent_id
+)
+SELECT
+  e.id, e.config->>'name' AS name, e.state, e.config,
+  encode(sha256(convert_to(e.config::text, 'UTF8')), 'hex') AS config_hash,
+  encode(sha256(e.model_definition), 'hex') AS model_definition_hash,
+  e.parent_id, e.git_remote, e.git_commit, e.git_committer, e.git_commit_date,
+  u.username, e.project_id, p.workspace_id, e.start_time, e.end_time
+FROM ancestry a
+  JOIN experiments e ON e.id = a.id
+  JOIN users u ON u.id = e.owner_id
+  JOIN projects p ON p.id = e.project_id
+ORDER BY a.depth`, id).Scan(ctx, &es); err != nil {
+		return nil, errors.Wrapf(err, "querying lineage of experiment %d", id)
+	}
+	if len(es) == 0 {
+		return nil, ErrNotFound
+	}
+	return es, nil
+}
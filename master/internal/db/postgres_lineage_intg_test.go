@@ -0,0 +1,60 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/etc"
+)
+
+func TestLineage(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	ctx := context.Background()
+
+	user := RequireMockUser(t, db)
+	parent := RequireMockExperiment(t, db, user)
+	exp := RequireMockExperiment(t, db, user)
+	_, err := Bun().NewUpdate().Table("experiments").
+		Set("parent_id = ?", parent.ID).
+		Where("id = ?", exp.ID).
+		Exec(ctx)
+	require.NoError(t, err)
+	tr := RequireMockTrial(t, db, exp)
+
+	trial, err := TrialLineageByID(ctx, tr.ID)
+	require.NoError(t, err)
+	require.Equal(t, tr.TaskID, trial.TaskID)
+	require.Equal(t, float64(1), trial.HParams["global_batch_size"])
+	require.Nil(t, trial.WarmStartCheckpointUUID)
+
+	es, err := ExperimentLineages(ctx, exp.ID)
+	require.NoError(t, err)
+	require.Len(t, es, 2)
+	require.Equal(t, exp.ID, es[0].ID)
+	require.Equal(t, &parent.ID, es[0].ParentID)
+	require.Equal(t, parent.ID, es[1].ID)
+	require.Nil(t, es[1].ParentID)
+	require.Equal(t, user.Username, es[0].Username)
+	require.Equal(t, exp.Config.Name().String(), es[0].Name)
+
+	// Both experiments ran the same code, but their configurations differ in their names.
+	sum := sha256.Sum256(exp.ModelDefinitionBytes)
+	require.Equal(t, hex.EncodeToString(sum[:]), es[0].ModelDefinitionHash)
+	require.Equal(t, es[0].ModelDefinitionHash, es[1].ModelDefinitionHash)
+	require.Len(t, es[0].ConfigHash, sha256.Size*2)
+	require.NotEqual(t, es[0].ConfigHash, es[1].ConfigHash)
+
+	_, err = ExperimentLineages(ctx, -1)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = TrialLineageByID(ctx, -1)
+	require.ErrorIs(t, err, ErrNotFound)
+}
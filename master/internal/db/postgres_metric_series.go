@@ -0,0 +1,201 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// DownsampleMethod is how a metric series is reduced to a number of points that can be plotted.
+type DownsampleMethod int
+
+const (
+	// DownsampleLTTB keeps the points of the series that are most visually significant, as chosen
+	// by the Largest-Triangle-Three-Buckets algorithm.
+	DownsampleLTTB DownsampleMethod = iota
+	// DownsampleBuckets splits the series into ranges of batches of equal width and summarizes
+	// each range by the mean, minimum, and maximum of the values in it.
+	DownsampleBuckets
+)
+
+// MetricSeriesQuery selects the metric series of a trial to downsample.
+type MetricSeriesQuery struct {
+	TrialID    int
+	MetricName string
+	MetricType model.MetricType
+	// MetricGroup, if set, selects a group of generic metrics instead of MetricType.
+	MetricGroup string
+	// StartBatches and EndBatches bound the series, inclusively; an EndBatches of zero leaves the
+	// series unbounded above.
+	StartBatches int
+	EndBatches   int
+	// TargetPoints is the most points to return. Series with no more points than this are
+	// returned as they are.
+	TargetPoints int
+	// LogScale selects the points that matter on a logarithmic axis instead of a linear one,
+	// skipping the values that cannot be plotted on it.
+	LogScale bool
+	Method   DownsampleMethod
+}
+
+// MetricSeriesPoint is a point of a downsampled metric series. Min and Max are only set by
+// DownsampleBuckets, to the extremes of the values the point summarizes.
+type MetricSeriesPoint struct {
+	Batches int      `bun:"batches"`
+	Value   float64  `bun:"value"`
+	Min     *float64 `bun:"min"`
+	Max     *float64 `bun:"max"`
+}
+
+// metricSeriesSource returns the CTEs `series`, holding the points of the series as (x, y, sy)
+// where sy is y on the requested scale, and `numbered`, which adds the index rn of each point
+// and the length n of the series. If thinned is set, the points come from the continuous
+// aggregates that keep the last point of every 1000 batches. Arguments 0 through 6 must be those
+// of metricSeriesArgs.
+func metricSeriesSource(q MetricSeriesQuery, thinned bool) string {
+	table, field, filter := "steps", "avg_metrics", "m.state = 'COMPLETED'"
+	switch {
+	case q.MetricGroup != "":
+		table, filter = "generic_metrics", "m.metric_group = ?6"
+	case q.MetricType == model.ValidationMetric:
+		table, field = "validations", "validation_metrics"
+	}
+	if thinned {
+		// The continuous aggregates only hold points that the views would have returned.
+		table, filter = table+"_downsampled", "true"
+	}
+	return fmt.Sprintf(`
+series AS (
+  SELECT x, y, CASE WHEN ?5 THEN ln(y) ELSE y END AS sy
+  FROM (
+    SELECT m.total_batches AS x, (m.metrics->'%[2]s'->>?1)::float8 AS y
+    FROM %[1]s m
+    WHERE m.trial_id = ?0
+      AND %[3]s
+      AND m.total_batches >= ?2
+      AND (?3 <= 0 OR m.total_batches <= ?3)
+      AND jsonb_typeof(m.metrics->'%[2]s'->?1) = 'number'
+  ) raw
+  WHERE NOT ?5 OR y > 0
+),
+numbered AS (
+  SELECT x, y, sy, row_number() OVER (ORDER BY x) AS rn, count(*) OVER () AS n
+  FROM series
+)`, table, field, filter)
+}
+
+func metricSeriesArgs(q MetricSeriesQuery) []interface{} {
+	return []interface{}{
+		q.TrialID, q.MetricName, q.StartBatches, q.EndBatches, q.TargetPoints, q.LogScale,
+		q.MetricGroup,
+	}
+}
+
+// DownsampledMetricSeries returns a series of metrics of a trial, downsampled in the database so
+// that only the points that will be plotted are read out of it.
+func DownsampledMetricSeries(
+	ctx context.Context, q MetricSeriesQuery,
+) ([]MetricSeriesPoint, error) {
+	var query string
+	switch q.Method {
+	case DownsampleLTTB:
+		query = downsampleLTTBQuery
+	case DownsampleBuckets:
+		query = downsampleBucketsQuery
+	default:
+		return nil, fmt.Errorf("unknown downsample method %d", q.Method)
+	}
+
+	thinned, err := useThinnedMetricSeries(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	points := []MetricSeriesPoint{}
+	err = ReadBun().NewRaw(
+		"WITH RECURSIVE "+metricSeriesSource(q, thinned)+query, metricSeriesArgs(q)...,
+	).Scan(ctx, &points)
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// useThinnedMetricSeries returns whether to downsample a series of training or validation metrics
+// stored in TimescaleDB from its continuous aggregate rather than from every point of it. That is
+// only done when the thinned series alone has more points than requested, so that what is plotted
+// barely changes while long series are read much faster.
+func useThinnedMetricSeries(ctx context.Context, q MetricSeriesQuery) (bool, error) {
+	if !timescaleMetricsEnabled() || q.MetricGroup != "" {
+		return false, nil
+	}
+	table := "steps_downsampled"
+	if q.MetricType == model.ValidationMetric {
+		table = "validations_downsampled"
+	}
+	var n int
+	if err := ReadBun().NewSelect().TableExpr(table).
+		ColumnExpr("count(*)").
+		Where("trial_id = ?", q.TrialID).
+		Where("total_batches >= ?", q.StartBatches).
+		Where("? <= 0 OR total_batches <= ?", q.EndBatches, q.EndBatches).
+		Scan(ctx, &n); err != nil {
+		return false, err
+	}
+	return n > q.TargetPoints, nil
+}
+
+// downsampleLTTBQuery keeps the first and last points of the series and splits the rest into
+// ?4 - 2 buckets, from each of which it picks the point that forms the largest triangle with the
+// point picked from the previous bucket and the mean of the next bucket. Because each pick
+// depends on the one before it, the buckets are walked in order by a recursive CTE.
+const downsampleLTTBQuery = `,
+bucketed AS (
+  SELECT x, y, sy, 0 AS b FROM numbered WHERE n > ?4 AND rn = 1
+  UNION ALL
+  SELECT x, y, sy, ntile(?4::int - 2) OVER (ORDER BY x) AS b
+  FROM numbered WHERE n > ?4 AND rn > 1 AND rn < n
+  UNION ALL
+  SELECT x, y, sy, ?4::int - 1 AS b FROM numbered WHERE n > ?4 AND rn = n
+),
+means AS (
+  SELECT b, avg(x) AS mx, avg(sy) AS my FROM bucketed GROUP BY b
+),
+picked AS (
+  SELECT b, x, y, sy FROM bucketed WHERE b = 0
+  UNION ALL
+  SELECT nxt.b, nxt.x, nxt.y, nxt.sy
+  FROM picked p
+  CROSS JOIN LATERAL (
+    SELECT c.b, c.x, c.y, c.sy
+    FROM bucketed c
+    JOIN means m ON m.b = c.b + 1
+    WHERE c.b = p.b + 1
+    ORDER BY abs((p.x - m.mx) * (c.sy - p.sy) - (p.x - c.x) * (m.my - p.sy)) DESC, c.x
+    LIMIT 1
+  ) nxt
+)
+SELECT x AS batches, y AS value FROM numbered WHERE n <= ?4
+UNION ALL
+SELECT x AS batches, y AS value FROM picked
+UNION ALL
+SELECT x AS batches, y AS value FROM bucketed WHERE b = ?4::int - 1
+ORDER BY batches`
+
+// downsampleBucketsQuery splits the batches the series spans into ?4 ranges of equal width and
+// reports each range at the last batch in it. Logarithmic values are averaged geometrically.
+const downsampleBucketsQuery = `,
+bounds AS (
+  SELECT min(x) AS lo, max(x) AS hi FROM series
+)
+SELECT x AS batches, y AS value, y AS min, y AS max FROM numbered WHERE n <= ?4
+UNION ALL
+SELECT
+  max(s.x) AS batches,
+  CASE WHEN ?5 THEN exp(avg(s.sy)) ELSE avg(s.y) END AS value,
+  min(s.y) AS min,
+  max(s.y) AS max
+FROM series s, bounds
+WHERE (SELECT count(*) FROM series) > ?4
+GROUP BY width_bucket(s.x::float8, bounds.lo::float8, bounds.hi::float8 + 1, ?4)
+ORDER BY batches`
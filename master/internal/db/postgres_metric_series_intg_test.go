@@ -0,0 +1,134 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/commonv1"
+	"github.com/determined-ai/determined/proto/pkg/trialv1"
+)
+
+func TestDownsampledMetricSeries(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+	tr := RequireMockTrial(t, db, exp)
+
+	const numSteps = 100
+	const spikeAt = 37
+	for i := 1; i <= numSteps; i++ {
+		loss := float64(i)
+		if i == spikeAt {
+			loss = 1000
+		}
+		require.NoError(t, db.AddTrainingMetrics(context.TODO(), &trialv1.TrialMetrics{
+			TrialId:        int32(tr.ID),
+			StepsCompleted: int32(i),
+			Metrics: &commonv1.Metrics{
+				AvgMetrics: &structpb.Struct{Fields: map[string]*structpb.Value{
+					"loss": structpb.NewNumberValue(loss),
+				}},
+				BatchMetrics: []*structpb.Struct{},
+			},
+		}))
+	}
+
+	q := MetricSeriesQuery{
+		TrialID:      tr.ID,
+		MetricName:   "loss",
+		MetricType:   model.TrainingMetric,
+		TargetPoints: 10,
+		Method:       DownsampleLTTB,
+	}
+	points, err := DownsampledMetricSeries(context.TODO(), q)
+	require.NoError(t, err)
+	require.Len(t, points, 10)
+	require.Equal(t, 1, points[0].Batches)
+	require.Equal(t, numSteps, points[len(points)-1].Batches)
+	var sawSpike bool
+	for _, p := range points {
+		sawSpike = sawSpike || p.Batches == spikeAt
+	}
+	require.True(t, sawSpike, "LTTB should keep the spike")
+
+	q.Method = DownsampleBuckets
+	points, err = DownsampledMetricSeries(context.TODO(), q)
+	require.NoError(t, err)
+	require.Len(t, points, 10)
+	for i, p := range points {
+		require.NotNil(t, p.Min)
+		require.NotNil(t, p.Max)
+		require.LessOrEqual(t, *p.Min, p.Value)
+		require.GreaterOrEqual(t, *p.Max, p.Value)
+		if i > 0 {
+			require.Greater(t, p.Batches, points[i-1].Batches)
+		}
+	}
+	require.Equal(t, numSteps, points[len(points)-1].Batches)
+
+	q.TargetPoints = numSteps
+	points, err = DownsampledMetricSeries(context.TODO(), q)
+	require.NoError(t, err)
+	require.Len(t, points, numSteps, "short series should not be downsampled")
+
+	q.MetricName = "missing"
+	points, err = DownsampledMetricSeries(context.TODO(), q)
+	require.NoError(t, err)
+	require.Empty(t, points)
+}
+
+func TestGenericMetricSeries(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+	tr := RequireMockTrial(t, db, exp)
+
+	report := func(group string, stepsCompleted int32, value float64) {
+		require.NoError(t, db.AddGenericMetrics(context.TODO(), group, &trialv1.TrialMetrics{
+			TrialId:        int32(tr.ID),
+			StepsCompleted: stepsCompleted,
+			Metrics: &commonv1.Metrics{
+				AvgMetrics: &structpb.Struct{Fields: map[string]*structpb.Value{
+					"latency": structpb.NewNumberValue(value),
+				}},
+			},
+		}))
+	}
+	report("inference", 10, 1)
+	report("inference", 20, 2)
+	report("system", 10, 100)
+
+	points, err := DownsampledMetricSeries(context.TODO(), MetricSeriesQuery{
+		TrialID:      tr.ID,
+		MetricName:   "latency",
+		MetricGroup:  "inference",
+		TargetPoints: 10,
+		Method:       DownsampleLTTB,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []MetricSeriesPoint{{Batches: 10, Value: 1}, {Batches: 20, Value: 2}}, points)
+
+	points, err = DownsampledMetricSeries(context.TODO(), MetricSeriesQuery{
+		TrialID:      tr.ID,
+		MetricName:   "latency",
+		MetricType:   model.TrainingMetric,
+		TargetPoints: 10,
+		Method:       DownsampleLTTB,
+	})
+	require.NoError(t, err)
+	require.Empty(t, points, "generic metrics should not be mixed into training metrics")
+}
@@ -0,0 +1,193 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/trialv1"
+)
+
+const (
+	// trainingMetricsWorkers is how many batches of training metrics are inserted at once.
+	trainingMetricsWorkers = 4
+	// maxTrainingMetricsBatch is the most reports of training metrics inserted in one batch.
+	maxTrainingMetricsBatch = 500
+	// trainingMetricsQueueSize is how many reports of training metrics may wait to be inserted
+	// before trials that report more have to wait for room.
+	trainingMetricsQueueSize = 10000
+)
+
+// trainingMetricsReport is a report of training metrics waiting to be inserted, and where to
+// send the outcome.
+type trainingMetricsReport struct {
+	metrics *trialv1.TrialMetrics
+	done    chan error
+}
+
+// stepRow is a step to insert, as read by jsonb_to_recordset.
+type stepRow struct {
+	TrialID      int32         `json:"trial_id"`
+	TrialRunID   int32         `json:"trial_run_id"`
+	TotalBatches int32         `json:"total_batches"`
+	Metrics      model.JSONObj `json:"metrics"`
+}
+
+// AddTrainingMetrics adds a completed step to the database with the given training metrics.
+// If these training metrics occur before any others, a rollback is assumed and later
+// training and validation metrics are cleaned up.
+//
+// Reports are queued and inserted in batches along with those of other trials, so that each
+// report does not cost a transaction, and it returns once the step has been inserted. When the
+// database falls behind and the queue fills up, it waits for room, which slows down the trials
+// that report metrics instead of letting the queue grow without bound.
+func (db *PgDB) AddTrainingMetrics(ctx context.Context, m *trialv1.TrialMetrics) error {
+	db.trainingMetricsOnce.Do(func() {
+		db.trainingMetrics = make(chan trainingMetricsReport, trainingMetricsQueueSize)
+		for i := 0; i < trainingMetricsWorkers; i++ {
+			go db.insertTrainingMetrics()
+		}
+	})
+
+	r := trainingMetricsReport{metrics: m, done: make(chan error, 1)}
+	select {
+	case db.trainingMetrics <- r:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-r.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// insertTrainingMetrics inserts the reports of training metrics that are waiting whenever there
+// are any, forever. It never waits for more reports to arrive, so reports are only batched when
+// they arrive faster than they can be inserted.
+func (db *PgDB) insertTrainingMetrics() {
+	for r := range db.trainingMetrics {
+		batch := []trainingMetricsReport{r}
+	drain:
+		for len(batch) < maxTrainingMetricsBatch {
+			select {
+			case r := <-db.trainingMetrics:
+				batch = append(batch, r)
+			default:
+				break drain
+			}
+		}
+		db.addTrainingMetricsBatch(context.Background(), batch)
+	}
+}
+
+// addTrainingMetricsBatch adds the steps of a batch of reports in one transaction, and sends each
+// report its outcome. If the batch fails as a whole, its reports are retried one at a time, so
+// that a bad report does not fail the others.
+func (db *PgDB) addTrainingMetricsBatch(ctx context.Context, batch []trainingMetricsReport) {
+	if len(batch) == 1 {
+		batch[0].done <- db.addTrainingMetrics(ctx, batch[0].metrics)
+		return
+	}
+
+	steps := make([]stepRow, 0, len(batch))
+	for _, r := range batch {
+		m := r.metrics
+		steps = append(steps, stepRow{
+			TrialID:      m.TrialId,
+			TrialRunID:   m.TrialRunId,
+			TotalBatches: int32(m.StepsCompleted),
+			Metrics: model.JSONObj{
+				"avg_metrics":   m.Metrics.AvgMetrics,
+				"batch_metrics": m.Metrics.BatchMetrics,
+			},
+		})
+	}
+
+	var valid map[int]bool
+	err := db.withTransaction("add training metrics batch", func(tx *sqlx.Tx) error {
+		allJSON, err := json.Marshal(steps)
+		if err != nil {
+			return err
+		}
+		var runIDs []struct {
+			TrialID int32 `db:"id"`
+			RunID   int32 `db:"run_id"`
+		}
+		if err := tx.SelectContext(ctx, &runIDs, `
+SELECT DISTINCT t.id, t.run_id
+FROM jsonb_to_recordset($1) AS r(trial_id int)
+JOIN trials t ON t.id = r.trial_id`, allJSON); err != nil {
+			return errors.Wrap(err, "querying current runs")
+		}
+		current := make(map[int32]int32, len(runIDs))
+		for _, r := range runIDs {
+			current[r.TrialID] = r.RunID
+		}
+
+		// Reports from runs other than the current one are left out, to be rejected on their own.
+		valid = make(map[int]bool, len(steps))
+		var validSteps []stepRow
+		for i, s := range steps {
+			if runID, ok := current[s.TrialID]; ok && runID == s.TrialRunID {
+				valid[i] = true
+				validSteps = append(validSteps, s)
+			}
+		}
+		if len(validSteps) == 0 {
+			return nil
+		}
+		stepsJSON, err := json.Marshal(validSteps)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+UPDATE raw_steps s SET archived = true
+FROM jsonb_to_recordset($1) AS r(trial_id int, trial_run_id int, total_batches int)
+WHERE s.trial_id = r.trial_id
+  AND s.trial_run_id < r.trial_run_id
+  AND s.total_batches >= r.total_batches`, stepsJSON); err != nil {
+			return errors.Wrap(err, "archiving training metrics")
+		}
+		if _, err := tx.ExecContext(ctx, `
+UPDATE raw_validations v SET archived = true
+FROM jsonb_to_recordset($1) AS r(trial_id int, trial_run_id int, total_batches int)
+WHERE v.trial_id = r.trial_id
+  AND v.trial_run_id < r.trial_run_id
+  AND v.total_batches > r.total_batches`, stepsJSON); err != nil {
+			return errors.Wrap(err, "archiving validations")
+		}
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO raw_steps (trial_id, trial_run_id, state, end_time, metrics, total_batches)
+SELECT r.trial_id, r.trial_run_id, 'COMPLETED', now(), r.metrics, r.total_batches
+FROM jsonb_to_recordset($1)
+  AS r(trial_id int, trial_run_id int, total_batches int, metrics jsonb)`,
+			stepsJSON); err != nil {
+			return errors.Wrap(err, "inserting training metrics")
+		}
+		return nil
+	})
+	if err != nil {
+		log.WithError(err).Warnf(
+			"failed to add a batch of %d training metrics, adding them one at a time", len(batch))
+		for _, r := range batch {
+			r.done <- db.addTrainingMetrics(ctx, r.metrics)
+		}
+		return
+	}
+
+	for i, r := range batch {
+		if valid[i] {
+			r.done <- nil
+			continue
+		}
+		// Let the report fail with the same error it would have on its own.
+		r.done <- db.addTrainingMetrics(ctx, r.metrics)
+	}
+}
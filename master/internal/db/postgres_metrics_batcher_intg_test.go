@@ -0,0 +1,92 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/determined-ai/determined/master/internal/api"
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/proto/pkg/commonv1"
+	"github.com/determined-ai/determined/proto/pkg/trialv1"
+)
+
+func trainingMetrics(trialID, runID, steps int) *trialv1.TrialMetrics {
+	return &trialv1.TrialMetrics{
+		TrialId:        int32(trialID),
+		TrialRunId:     int32(runID),
+		StepsCompleted: int32(steps),
+		Metrics: &commonv1.Metrics{
+			AvgMetrics: &structpb.Struct{Fields: map[string]*structpb.Value{
+				"loss": structpb.NewNumberValue(float64(steps)),
+			}},
+			BatchMetrics: []*structpb.Struct{},
+		},
+	}
+}
+
+func TestAddTrainingMetricsBatch(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	ctx := context.Background()
+
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+	tr := RequireMockTrial(t, db, exp)
+	other := RequireMockTrial(t, db, exp)
+
+	// Steps from the first run are archived by steps from the second that roll back past them.
+	require.NoError(t, db.AddTrainingMetrics(ctx, trainingMetrics(tr.ID, 0, 10)))
+	require.NoError(t, db.UpdateTrialRunID(tr.ID, 1))
+
+	var batch []trainingMetricsReport
+	for _, m := range []*trialv1.TrialMetrics{
+		trainingMetrics(tr.ID, 1, 5),
+		trainingMetrics(other.ID, 0, 5),
+		trainingMetrics(other.ID, 3, 10),
+		trainingMetrics(tr.ID, 1, 10),
+	} {
+		batch = append(batch, trainingMetricsReport{metrics: m, done: make(chan error, 1)})
+	}
+	db.addTrainingMetricsBatch(ctx, batch)
+	for i, r := range batch {
+		err := <-r.done
+		if i == 2 {
+			// Reports from stale runs fail just as they would on their own.
+			require.ErrorIs(t, err, api.ErrInvalid)
+			continue
+		}
+		require.NoError(t, err)
+	}
+
+	steps := func(trialID int, archived bool) int {
+		n, err := Bun().NewSelect().Table("raw_steps").
+			Where("trial_id = ?", trialID).
+			Where("archived = ?", archived).
+			Count(ctx)
+		require.NoError(t, err)
+		return n
+	}
+	require.Equal(t, 2, steps(tr.ID, false))
+	require.Equal(t, 1, steps(tr.ID, true))
+	require.Equal(t, 1, steps(other.ID, false))
+
+	// Concurrent reports are all inserted.
+	var wg sync.WaitGroup
+	for i := 1; i <= 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			require.NoError(t, db.AddTrainingMetrics(ctx, trainingMetrics(other.ID, 0, 10+i)))
+		}(i)
+	}
+	wg.Wait()
+	require.Equal(t, 51, steps(other.ID, false))
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
+)
+
+// metricsPartition corresponds to a row in the "metrics_partitions" DB table, which records the
+// partitions of the tables of metrics and the ranges of trial IDs they hold.
+type metricsPartition struct {
+	bun.BaseModel `bun:"table:metrics_partitions,alias:p"`
+
+	TableName  string `bun:"table_name,pk"`
+	LowerBound int    `bun:"lower_bound,pk"`
+	UpperBound int    `bun:"upper_bound"`
+}
+
+// AddMetricsPartitions creates the partitions of the tables of metrics for the next
+// trialsPerPartition trials, so that the metrics of new trials do not land in the default
+// partitions.
+func AddMetricsPartitions(ctx context.Context, trialsPerPartition int) error {
+	_, err := Bun().ExecContext(ctx, `
+SELECT add_metrics_partitions((SELECT coalesce(max(id), 0) FROM trials) + ?0, ?0)`,
+		trialsPerPartition)
+	return err
+}
+
+// DropEmptyMetricsPartitions drops the partitions of the tables of metrics whose trials have all
+// been deleted, and returns how many it dropped. Trial IDs are never reused, so no metrics can be
+// written to them again.
+func DropEmptyMetricsPartitions(ctx context.Context) (int, error) {
+	var dropped int
+	err := Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var parts []metricsPartition
+		if err := tx.NewSelect().Model(&parts).
+			Where("p.upper_bound <= (SELECT coalesce(max(id), 0) FROM trials)").
+			Where(`NOT EXISTS (SELECT 1 FROM trials t
+WHERE t.id >= p.lower_bound AND t.id < p.upper_bound)`).
+			Scan(ctx); err != nil {
+			return err
+		}
+		for _, p := range parts {
+			name := fmt.Sprintf("%s_%d", p.TableName, p.LowerBound)
+			if _, err := tx.NewDropTable().Table(name).IfExists().Exec(ctx); err != nil {
+				return errors.Wrapf(err, "dropping partition %s", name)
+			}
+			if _, err := tx.NewDelete().Model((*metricsPartition)(nil)).
+				Where("table_name = ?", p.TableName).
+				Where("lower_bound = ?", p.LowerBound).
+				Exec(ctx); err != nil {
+				return err
+			}
+		}
+		dropped = len(parts)
+		return nil
+	})
+	return dropped, err
+}
+
+// MetricsPartitionsLoop creates upcoming partitions of the tables of metrics and drops empty
+// ones, and compresses and drops chunks of those stored in TimescaleDB, once an hour until the
+// context is canceled.
+func MetricsPartitionsLoop(ctx context.Context, trialsPerPartition int) {
+	t := time.NewTicker(time.Hour)
+	defer t.Stop()
+	for {
+		if err := AddMetricsPartitions(ctx, trialsPerPartition); err != nil {
+			log.WithError(err).Error("failed to add metrics partitions")
+		}
+		if n, err := DropEmptyMetricsPartitions(ctx); err != nil {
+			log.WithError(err).Error("failed to drop empty metrics partitions")
+		} else if n > 0 {
+			log.Infof("dropped %d empty metrics partitions", n)
+		}
+		maintainMetricsChunks(ctx)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
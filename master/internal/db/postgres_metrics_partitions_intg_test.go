@@ -0,0 +1,61 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/proto/pkg/commonv1"
+	"github.com/determined-ai/determined/proto/pkg/trialv1"
+)
+
+func TestMetricsPartitions(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	ctx := context.Background()
+
+	require.NoError(t, AddMetricsPartitions(ctx, 10))
+
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+	tr := RequireMockTrial(t, db, exp)
+	require.NoError(t, db.AddTrainingMetrics(ctx, &trialv1.TrialMetrics{
+		TrialId:        int32(tr.ID),
+		StepsCompleted: 1,
+		Metrics: &commonv1.Metrics{
+			AvgMetrics: &structpb.Struct{Fields: map[string]*structpb.Value{
+				"loss": structpb.NewNumberValue(1),
+			}},
+			BatchMetrics: []*structpb.Struct{},
+		},
+	}))
+
+	partition := func() string {
+		var name string
+		require.NoError(t, Bun().NewRaw(
+			"SELECT tableoid::regclass::text FROM raw_steps WHERE trial_id = ?", tr.ID,
+		).Scan(ctx, &name))
+		return name
+	}
+	require.NotEqual(t, "raw_steps_default", partition())
+
+	// The partition of a trial that still exists is never dropped.
+	_, err := DropEmptyMetricsPartitions(ctx)
+	require.NoError(t, err)
+	require.NotEqual(t, "raw_steps_default", partition())
+
+	var covered bool
+	require.NoError(t, Bun().NewSelect().Model((*metricsPartition)(nil)).
+		ColumnExpr("count(*) > 0").
+		Where("table_name = 'raw_steps'").
+		Where("lower_bound <= ? AND ? < upper_bound", tr.ID, tr.ID).
+		Scan(ctx, &covered))
+	require.True(t, covered)
+}
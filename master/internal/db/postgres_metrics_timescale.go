@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
+)
+
+// timescaleMetrics is 1 once the tables of training and validation metrics are TimescaleDB
+// hypertables.
+var timescaleMetrics int32
+
+func timescaleMetricsEnabled() bool {
+	return atomic.LoadInt32(&timescaleMetrics) == 1
+}
+
+// minTimescaleVersion is the oldest major and minor version of TimescaleDB that metrics can be
+// stored with. Earlier versions cannot update or delete the rows of compressed chunks, which
+// archiving the metrics of trials that restart from a checkpoint and deleting trials both do.
+var minTimescaleVersion = [2]int{2, 11}
+
+// timescaleVersionAtLeast returns whether a version of TimescaleDB, like "2.11.1", is at least the
+// given major and minor version.
+func timescaleVersionAtLeast(version string, min [2]int) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	minor, err := strconv.Atoi(strings.SplitN(parts[1], "-", 2)[0])
+	if err != nil {
+		return false
+	}
+	return major > min[0] || (major == min[0] && minor >= min[1])
+}
+
+// checkTimescaleVersion returns an error unless the version of TimescaleDB that the database has
+// installed, or would install, is at least minTimescaleVersion.
+func checkTimescaleVersion(ctx context.Context) error {
+	var version string
+	err := Bun().NewRaw(`
+SELECT coalesce(installed_version, default_version)
+FROM pg_available_extensions
+WHERE name = 'timescaledb'`).Scan(ctx, &version)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return errors.New("the timescaledb extension is not available to the database")
+	case err != nil:
+		return errors.Wrap(err, "error checking the version of TimescaleDB")
+	case !timescaleVersionAtLeast(version, minTimescaleVersion):
+		return fmt.Errorf("storing metrics in TimescaleDB requires version %d.%d or later, not %s",
+			minTimescaleVersion[0], minTimescaleVersion[1], version)
+	}
+	return nil
+}
+
+// EnableTimescaleMetrics converts the tables of training and validation metrics into TimescaleDB
+// hypertables chunked by ranges of trialsPerChunk trials, unless they already are, and routes
+// downsampled reads of long series to their continuous aggregates. The timescaledb extension must
+// be available to the database, at minTimescaleVersion or later.
+func EnableTimescaleMetrics(ctx context.Context, trialsPerChunk int) error {
+	if err := checkTimescaleVersion(ctx); err != nil {
+		return err
+	}
+	// The tables are partitioned until they are converted.
+	var converted bool
+	if err := Bun().NewRaw("SELECT relkind <> 'p' FROM pg_class WHERE oid = 'raw_steps'::regclass").
+		Scan(ctx, &converted); err != nil {
+		return errors.Wrap(err, "error checking whether metrics are stored in TimescaleDB")
+	}
+	err := Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		// The conversion copies every metric, which can take longer than statements may usually run.
+		if _, err := tx.ExecContext(ctx, "SET LOCAL statement_timeout = 0"); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "SELECT enable_timescale_metrics(?)", trialsPerChunk)
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "error storing metrics in TimescaleDB hypertables")
+	}
+	atomic.StoreInt32(&timescaleMetrics, 1)
+	if !converted {
+		// The conversion changes the schema without a migration.
+		return RecordSchemaSnapshot(ctx, true)
+	}
+	return nil
+}
+
+// CompressMetricsChunks compresses the chunks of the hypertables of metrics whose trials have all
+// ended, and returns how many it compressed. Chunks of trials that may still report metrics stay
+// uncompressed so that writing to them stays cheap.
+func CompressMetricsChunks(ctx context.Context) (int, error) {
+	var compressed int
+	err := Bun().NewRaw(`
+SELECT count(compress_chunk(format('%I.%I', c.chunk_schema, c.chunk_name)::regclass, true))
+FROM timescaledb_information.chunks c
+WHERE c.hypertable_schema = 'public'
+  AND c.hypertable_name IN ('raw_steps', 'raw_validations')
+  AND NOT c.is_compressed
+  AND c.range_end_integer <= (SELECT coalesce(max(id), 0) + 1 FROM trials)
+  AND NOT EXISTS (SELECT 1 FROM trials t
+    WHERE t.id >= c.range_start_integer AND t.id < c.range_end_integer AND t.end_time IS NULL)`).
+		Scan(ctx, &compressed)
+	return compressed, err
+}
+
+// DropEmptyMetricsChunks drops the chunks of the hypertables of metrics that only hold trials
+// older than every trial that still exists, and returns how many it dropped.
+func DropEmptyMetricsChunks(ctx context.Context) (int, error) {
+	var dropped int
+	err := Bun().NewRaw(`
+SELECT count(*)
+FROM (SELECT min(id) AS id FROM trials) oldest,
+  LATERAL (
+    SELECT drop_chunks('raw_steps', older_than => oldest.id)
+    UNION ALL
+    SELECT drop_chunks('raw_validations', older_than => oldest.id)
+  ) chunks
+WHERE oldest.id IS NOT NULL`).Scan(ctx, &dropped)
+	return dropped, err
+}
+
+// maintainMetricsChunks compresses and drops chunks of the hypertables of metrics, if metrics are
+// stored in TimescaleDB.
+func maintainMetricsChunks(ctx context.Context) {
+	if !timescaleMetricsEnabled() {
+		return
+	}
+	if n, err := CompressMetricsChunks(ctx); err != nil {
+		log.WithError(err).Error("failed to compress metrics chunks")
+	} else if n > 0 {
+		log.Infof("compressed %d metrics chunks", n)
+	}
+	if n, err := DropEmptyMetricsChunks(ctx); err != nil {
+		log.WithError(err).Error("failed to drop empty metrics chunks")
+	} else if n > 0 {
+		log.Infof("dropped %d empty metrics chunks", n)
+	}
+}
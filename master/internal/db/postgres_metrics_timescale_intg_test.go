@@ -0,0 +1,155 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/commonv1"
+	"github.com/determined-ai/determined/proto/pkg/trialv1"
+)
+
+// setupTimescalePostgres points the database of the package at a new, migrated database that is
+// dropped after the test, since storing metrics in TimescaleDB changes the schema for good. It
+// skips the test unless a recent enough TimescaleDB can be used.
+func setupTimescalePostgres(t *testing.T) *PgDB {
+	ctx := context.Background()
+	shared := MustResolveTestPostgres(t)
+	if err := checkTimescaleVersion(ctx); err != nil {
+		t.Skip(err)
+	}
+	var preloaded bool
+	require.NoError(t, Bun().NewRaw(
+		"SELECT current_setting('shared_preload_libraries') LIKE '%timescaledb%'",
+	).Scan(ctx, &preloaded))
+	if !preloaded {
+		t.Skip("timescaledb is not preloaded by the database")
+	}
+
+	name := "timescale_" + uuid.New().String()[:8]
+	_, err := shared.sql.Exec(fmt.Sprintf("CREATE DATABASE %s", name))
+	require.NoError(t, err)
+	u, err := url.Parse(os.Getenv("DET_INTEGRATION_POSTGRES_URL"))
+	require.NoError(t, err)
+	u.Path = "/" + name
+	pgDB, err := ConnectPostgres(u.String())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		atomic.StoreInt32(&timescaleMetrics, 0)
+		require.NoError(t, pgDB.Close())
+		initTheOneBun(shared.sql.DB)
+		_, err := shared.sql.Exec(fmt.Sprintf("DROP DATABASE %s", name))
+		require.NoError(t, err)
+	})
+	MustMigrateTestPostgres(t, pgDB, MigrationsFromDB)
+	return pgDB
+}
+
+func TestTimescaleVersionAtLeast(t *testing.T) {
+	for version, ok := range map[string]bool{
+		"2.11.0":     true,
+		"2.11.2-dev": true,
+		"2.13.1":     true,
+		"3.0.0":      true,
+		"2.10.3":     false,
+		"1.7.5":      false,
+		"2":          false,
+		"":           false,
+	} {
+		require.Equal(t, ok, timescaleVersionAtLeast(version, minTimescaleVersion), version)
+	}
+}
+
+func TestTimescaleMetrics(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := setupTimescalePostgres(t)
+	ctx := context.Background()
+
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+	tr := RequireMockTrial(t, db, exp)
+	// The continuous aggregates keep one point for every 1000 batches.
+	const numBuckets = 5
+	for i := 1; i <= numBuckets; i++ {
+		require.NoError(t, db.AddTrainingMetrics(ctx, &trialv1.TrialMetrics{
+			TrialId:        int32(tr.ID),
+			StepsCompleted: int32(i * 1000),
+			Metrics: &commonv1.Metrics{
+				AvgMetrics: &structpb.Struct{Fields: map[string]*structpb.Value{
+					"loss": structpb.NewNumberValue(float64(i)),
+				}},
+				BatchMetrics: []*structpb.Struct{},
+			},
+		}))
+	}
+	q := MetricSeriesQuery{
+		TrialID:      tr.ID,
+		MetricName:   "loss",
+		MetricType:   model.TrainingMetric,
+		TargetPoints: numBuckets - 2,
+		Method:       DownsampleLTTB,
+	}
+
+	// Series are never thinned before metrics are stored in TimescaleDB.
+	thinned, err := useThinnedMetricSeries(ctx, q)
+	require.NoError(t, err)
+	require.False(t, thinned)
+
+	// Converting the tables keeps their metrics, and converting them again changes nothing.
+	for i := 0; i < 2; i++ {
+		require.NoError(t, EnableTimescaleMetrics(ctx, 10))
+	}
+	var hypertables int
+	require.NoError(t, Bun().NewRaw(`
+SELECT count(*) FROM timescaledb_information.hypertables
+WHERE hypertable_schema = 'public' AND hypertable_name IN ('raw_steps', 'raw_validations')`).
+		Scan(ctx, &hypertables))
+	require.Equal(t, 2, hypertables)
+	var steps int
+	require.NoError(t, Bun().NewSelect().Table("raw_steps").ColumnExpr("count(*)").
+		Where("trial_id = ?", tr.ID).Scan(ctx, &steps))
+	require.Equal(t, numBuckets, steps)
+
+	// Series are only thinned when their continuous aggregate has more points than requested.
+	thinned, err = useThinnedMetricSeries(ctx, q)
+	require.NoError(t, err)
+	require.True(t, thinned)
+	points, err := DownsampledMetricSeries(ctx, q)
+	require.NoError(t, err)
+	require.Len(t, points, q.TargetPoints)
+
+	q.TargetPoints = numBuckets
+	thinned, err = useThinnedMetricSeries(ctx, q)
+	require.NoError(t, err)
+	require.False(t, thinned)
+
+	q.TargetPoints = 1
+	q.EndBatches = 2000
+	thinned, err = useThinnedMetricSeries(ctx, q)
+	require.NoError(t, err)
+	require.True(t, thinned)
+	q.StartBatches = 2000
+	thinned, err = useThinnedMetricSeries(ctx, q)
+	require.NoError(t, err)
+	require.False(t, thinned)
+
+	// Generic metrics have no continuous aggregates.
+	q = MetricSeriesQuery{
+		TrialID: tr.ID, MetricName: "loss", MetricGroup: "inference", TargetPoints: 1,
+	}
+	thinned, err = useThinnedMetricSeries(ctx, q)
+	require.NoError(t, err)
+	require.False(t, thinned)
+}
@@ -162,3 +162,112 @@ func TestModels(t *testing.T) {
 		})
 	}
 }
+
+func TestModelVersionTransitions(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	ctx := context.Background()
+
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+	tr := RequireMockTrial(t, db, exp)
+	a := RequireMockAllocation(t, db, tr.TaskID)
+
+	var pmdl modelv1.Model
+	require.NoError(t, db.QueryProto(
+		"insert_model", &pmdl, uuid.NewString(), "", emptyMetadata, "", "", user.ID,
+	))
+	addVersion := func() *modelv1.ModelVersion {
+		ckpt := &model.CheckpointV2{
+			UUID:         uuid.New(),
+			TaskID:       tr.TaskID,
+			AllocationID: a.AllocationID,
+			ReportTime:   time.Now().UTC(),
+			State:        model.CompletedState,
+			Resources:    map[string]int64{"ok": 1},
+			Metadata:     map[string]interface{}{"steps_completed": 1},
+		}
+		require.NoError(t, db.AddCheckpointMetadata(ctx, ckpt))
+		var mv modelv1.ModelVersion
+		require.NoError(t, db.QueryProto(
+			"insert_model_version", &mv, pmdl.Id, ckpt.UUID, "", "", emptyMetadata, "", "",
+			user.ID,
+		))
+		require.Equal(t, modelv1.ModelVersionStage_MODEL_VERSION_STAGE_NONE, mv.Stage)
+		return &mv
+	}
+	requireStage := func(mv *modelv1.ModelVersion, expected model.ModelVersionStage) {
+		var retMv modelv1.ModelVersion
+		require.NoError(t, db.QueryProto("get_model_version", &retMv, pmdl.Id, mv.Id))
+		require.Equal(t, expected.Proto(), retMv.Stage)
+	}
+	v1, v2 := addVersion(), addVersion()
+
+	// Applied transitions move the version right away.
+	require.NoError(t, AddModelVersionTransition(ctx, &model.ModelVersionTransition{
+		ModelVersionID: int(v1.Id),
+		FromStage:      model.ModelVersionStageNone,
+		ToStage:        model.ModelVersionStageProduction,
+		State:          model.ModelVersionTransitionApplied,
+		RequestedBy:    user.ID,
+	}))
+	requireStage(v1, model.ModelVersionStageProduction)
+
+	// Moving another version to production can archive the one there.
+	require.NoError(t, AddModelVersionTransition(ctx, &model.ModelVersionTransition{
+		ModelVersionID:  int(v2.Id),
+		FromStage:       model.ModelVersionStageNone,
+		ToStage:         model.ModelVersionStageProduction,
+		ArchiveExisting: true,
+		State:           model.ModelVersionTransitionApplied,
+		RequestedBy:     user.ID,
+	}))
+	requireStage(v1, model.ModelVersionStageArchived)
+	requireStage(v2, model.ModelVersionStageProduction)
+
+	ts, err := ModelVersionTransitions(ctx, int(v1.Id))
+	require.NoError(t, err)
+	require.Len(t, ts, 2)
+	require.Equal(t, model.ModelVersionStageArchived, ts[1].ToStage)
+	require.Equal(t, user.Username, ts[1].RequestedByUsername)
+
+	// Pending transitions only move the version once approved.
+	pending := &model.ModelVersionTransition{
+		ModelVersionID: int(v1.Id),
+		FromStage:      model.ModelVersionStageArchived,
+		ToStage:        model.ModelVersionStageStaging,
+		State:          model.ModelVersionTransitionPending,
+		RequestedBy:    user.ID,
+	}
+	require.NoError(t, AddModelVersionTransition(ctx, pending))
+	requireStage(v1, model.ModelVersionStageArchived)
+
+	require.NoError(t, ReviewModelVersionTransition(ctx, int(v1.Id), pending.ID, user.ID, true))
+	requireStage(v1, model.ModelVersionStageStaging)
+	reviewed, err := ModelVersionTransitionByID(ctx, int(v1.Id), pending.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.ModelVersionTransitionApplied, reviewed.State)
+	require.Equal(t, &user.Username, reviewed.ReviewedByUsername)
+	require.NotNil(t, reviewed.ReviewedTime)
+
+	require.ErrorIs(t,
+		ReviewModelVersionTransition(ctx, int(v1.Id), pending.ID, user.ID, true), ErrNotFound)
+
+	// Transitions from a stage the version has since left are not applied.
+	stale := &model.ModelVersionTransition{
+		ModelVersionID: int(v1.Id),
+		FromStage:      model.ModelVersionStageArchived,
+		ToStage:        model.ModelVersionStageProduction,
+		State:          model.ModelVersionTransitionPending,
+		RequestedBy:    user.ID,
+	}
+	require.NoError(t, AddModelVersionTransition(ctx, stale))
+	require.ErrorIs(t, ReviewModelVersionTransition(ctx, int(v1.Id), stale.ID, user.ID, true),
+		ErrModelVersionStageChanged)
+	requireStage(v1, model.ModelVersionStageStaging)
+
+	unknown, err := UnknownUsernames(ctx, []string{user.Username, uuid.NewString()})
+	require.NoError(t, err)
+	require.Len(t, unknown, 1)
+}
@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// ErrModelVersionStageChanged is returned when a transition is applied to a model version that
+// is no longer in the stage it was requested from.
+var ErrModelVersionStageChanged = errors.New("model version has moved to another stage")
+
+// AddModelVersionTransition persists a transition, filling in its ID and the time it was
+// requested. A transition that is added as applied moves its model version at the same time.
+func AddModelVersionTransition(ctx context.Context, t *model.ModelVersionTransition) error {
+	return Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(t).Returning("id, requested_time").Exec(ctx); err != nil {
+			return err
+		}
+		if t.State == model.ModelVersionTransitionApplied {
+			return applyModelVersionTransition(ctx, tx, t)
+		}
+		return nil
+	})
+}
+
+// ReviewModelVersionTransition approves or rejects a pending transition, moving its model
+// version if it is approved. It returns ErrNotFound if there is no such pending transition.
+func ReviewModelVersionTransition(
+	ctx context.Context, modelVersionID, id int, reviewer model.UserID, approve bool,
+) error {
+	return Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var t model.ModelVersionTransition
+		switch err := tx.NewSelect().Model(&t).
+			Where("id = ?", id).
+			Where("model_version_id = ?", modelVersionID).
+			Where("state = ?", model.ModelVersionTransitionPending).
+			For("UPDATE").
+			Scan(ctx); {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrNotFound
+		case err != nil:
+			return err
+		}
+
+		t.State = model.ModelVersionTransitionRejected
+		if approve {
+			if err := applyModelVersionTransition(ctx, tx, &t); err != nil {
+				return err
+			}
+			t.State = model.ModelVersionTransitionApplied
+		}
+		_, err := tx.NewUpdate().Table("model_version_transitions").
+			Set("state = ?", t.State).
+			Set("reviewed_by = ?", reviewer).
+			Set("reviewed_time = ?", time.Now().UTC()).
+			Where("id = ?", t.ID).
+			Exec(ctx)
+		return err
+	})
+}
+
+// applyModelVersionTransition moves the model version of a transition to its new stage, and
+// archives the other versions of the model in that stage if the transition asks for it.
+func applyModelVersionTransition(
+	ctx context.Context, tx bun.Tx, t *model.ModelVersionTransition,
+) error {
+	var mv struct {
+		ModelID int
+		Stage   model.ModelVersionStage
+	}
+	switch err := tx.NewSelect().Table("model_versions").
+		Column("model_id", "stage").
+		Where("id = ?", t.ModelVersionID).
+		For("UPDATE").
+		Scan(ctx, &mv); {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotFound
+	case err != nil:
+		return err
+	}
+	if mv.Stage != t.FromStage {
+		return ErrModelVersionStageChanged
+	}
+
+	if t.ArchiveExisting {
+		var archived []model.ModelVersionTransition
+		if err := tx.NewRaw(`
+UPDATE model_versions SET stage = ?, last_updated_time = current_timestamp
+WHERE model_id = ? AND stage = ? AND id != ?
+RETURNING id AS model_version_id`,
+			model.ModelVersionStageArchived, mv.ModelID, t.ToStage, t.ModelVersionID,
+		).Scan(ctx, &archived); err != nil {
+			return errors.Wrap(err, "archiving existing model versions")
+		}
+		for i := range archived {
+			archived[i].FromStage = t.ToStage
+			archived[i].ToStage = model.ModelVersionStageArchived
+			archived[i].State = model.ModelVersionTransitionApplied
+			archived[i].Comment = fmt.Sprintf(
+				"archived when model version %d moved to %s", t.ModelVersionID, t.ToStage)
+			archived[i].RequestedBy = t.RequestedBy
+		}
+		if len(archived) > 0 {
+			if _, err := tx.NewInsert().Model(&archived).Exec(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := tx.NewUpdate().Table("model_versions").
+		Set("stage = ?", t.ToStage).
+		Set("last_updated_time = current_timestamp").
+		Where("id = ?", t.ModelVersionID).
+		Exec(ctx)
+	return err
+}
+
+// ModelVersionTransitions returns the transitions of a model version, oldest first.
+func ModelVersionTransitions(
+	ctx context.Context, modelVersionID int,
+) ([]model.ModelVersionTransition, error) {
+	ts := []model.ModelVersionTransition{}
+	if err := modelVersionTransitionsQuery(&ts).
+		Where("t.model_version_id = ?", modelVersionID).
+		Order("t.id ASC").
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+// ModelVersionTransitionByID returns a transition of a model version.
+func ModelVersionTransitionByID(
+	ctx context.Context, modelVersionID, id int,
+) (*model.ModelVersionTransition, error) {
+	var t model.ModelVersionTransition
+	switch err := modelVersionTransitionsQuery(&t).
+		Where("t.model_version_id = ?", modelVersionID).
+		Where("t.id = ?", id).
+		Scan(ctx); {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrNotFound
+	case err != nil:
+		return nil, err
+	}
+	return &t, nil
+}
+
+func modelVersionTransitionsQuery(dest interface{}) *bun.SelectQuery {
+	return Bun().NewSelect().Model(dest).
+		ModelTableExpr("model_version_transitions AS t").
+		ColumnExpr("t.*").
+		ColumnExpr("r.username AS requested_by_username").
+		ColumnExpr("v.username AS reviewed_by_username").
+		Join("JOIN users r ON r.id = t.requested_by").
+		Join("LEFT JOIN users v ON v.id = t.reviewed_by")
+}
+
+// UnknownUsernames returns those of the given usernames that do not belong to any user.
+func UnknownUsernames(ctx context.Context, usernames []string) ([]string, error) {
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+	var known []string
+	if err := Bun().NewSelect().Table("users").Column("username").
+		Where("username IN (?)", bun.In(usernames)).
+		Scan(ctx, &known); err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool, len(known))
+	for _, u := range known {
+		exists[u] = true
+	}
+	var unknown []string
+	for _, u := range usernames {
+		if !exists[u] {
+			unknown = append(unknown, u)
+		}
+	}
+	return unknown, nil
+}
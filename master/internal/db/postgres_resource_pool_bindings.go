@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// ResourcePoolBindings returns the bindings of every resource pool that has one, ordered by pool.
+func ResourcePoolBindings(ctx context.Context) ([]model.ResourcePoolBinding, error) {
+	bindings := []model.ResourcePoolBinding{}
+	if err := Bun().NewSelect().Model(&bindings).Order("resource_pool ASC").Scan(ctx); err != nil {
+		return nil, err
+	}
+	return bindings, nil
+}
+
+// SetResourcePoolBinding replaces the binding of a resource pool. An empty binding is deleted,
+// opening the pool to everyone.
+func SetResourcePoolBinding(ctx context.Context, b *model.ResourcePoolBinding) error {
+	if b.Empty() {
+		_, err := Bun().NewDelete().Table("resource_pool_bindings").
+			Where("resource_pool = ?", b.ResourcePool).
+			Exec(ctx)
+		return err
+	}
+	_, err := Bun().NewInsert().Model(b).
+		On("CONFLICT (resource_pool) DO UPDATE").
+		Set("user_ids = EXCLUDED.user_ids").
+		Set("group_ids = EXCLUDED.group_ids").
+		Set("workspace_ids = EXCLUDED.workspace_ids").
+		Exec(ctx)
+	return err
+}
+
+// ResourcePoolBound returns whether the tasks of a user may use a resource pool: it has no
+// binding, or its binding includes the user, one of their groups, or the workspace of the task.
+// workspaceID is nil for tasks outside of workspaces.
+func ResourcePoolBound(
+	ctx context.Context, resourcePool string, userID model.UserID, workspaceID *int,
+) (bool, error) {
+	var bound bool
+	err := Bun().NewRaw(`
+SELECT NOT EXISTS (SELECT 1 FROM resource_pool_bindings WHERE resource_pool = ?)
+    OR EXISTS (
+        SELECT 1 FROM resource_pool_bindings
+        WHERE resource_pool = ? AND (
+            ? = ANY(user_ids)
+            OR ?::integer = ANY(workspace_ids)
+            OR group_ids && ARRAY(SELECT group_id FROM user_group_membership WHERE user_id = ?)
+        )
+    )`, resourcePool, resourcePool, userID, workspaceID, userID).Scan(ctx, &bound)
+	return bound, err
+}
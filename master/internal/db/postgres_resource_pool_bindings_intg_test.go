@@ -0,0 +1,67 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestResourcePoolBound(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	ctx := context.Background()
+
+	pool := "pool-" + uuid.NewString()
+	bound, member, outsider := RequireMockUser(t, db), RequireMockUser(t, db), RequireMockUser(t, db)
+	var groupID int32
+	require.NoError(t, Bun().NewRaw("INSERT INTO groups (group_name) VALUES (?) RETURNING id",
+		"group-"+uuid.NewString()).Scan(ctx, &groupID))
+	_, err := Bun().ExecContext(ctx,
+		"INSERT INTO user_group_membership (user_id, group_id) VALUES (?, ?)", member.ID, groupID)
+	require.NoError(t, err)
+	workspaceID := 1
+
+	check := func(userID model.UserID, workspaceID *int) bool {
+		ok, err := ResourcePoolBound(ctx, pool, userID, workspaceID)
+		require.NoError(t, err)
+		return ok
+	}
+
+	// Pools without a binding are open to everyone.
+	require.True(t, check(outsider.ID, nil))
+
+	require.NoError(t, SetResourcePoolBinding(ctx, &model.ResourcePoolBinding{
+		ResourcePool: pool,
+		UserIDs:      []int32{int32(bound.ID)},
+		GroupIDs:     []int32{groupID},
+		WorkspaceIDs: []int32{int32(workspaceID)},
+	}))
+	require.True(t, check(bound.ID, nil))
+	require.True(t, check(member.ID, nil))
+	require.True(t, check(outsider.ID, &workspaceID))
+	require.False(t, check(outsider.ID, nil))
+	otherWorkspaceID := workspaceID + 1
+	require.False(t, check(outsider.ID, &otherWorkspaceID))
+
+	bindings, err := ResourcePoolBindings(ctx)
+	require.NoError(t, err)
+	require.Contains(t, bindings, model.ResourcePoolBinding{
+		ResourcePool: pool,
+		UserIDs:      []int32{int32(bound.ID)},
+		GroupIDs:     []int32{groupID},
+		WorkspaceIDs: []int32{int32(workspaceID)},
+	})
+
+	// Binding nothing opens the pool again.
+	require.NoError(t, SetResourcePoolBinding(ctx, &model.ResourcePoolBinding{ResourcePool: pool}))
+	require.True(t, check(outsider.ID, nil))
+}
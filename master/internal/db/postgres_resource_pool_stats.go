@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// RecordResourcePoolStats inserts a snapshot of a resource pool, filling in the median time spent
+// queued by the allocations in the pool that started within the window before the snapshot.
+func RecordResourcePoolStats(
+	ctx context.Context, stats *model.ResourcePoolStats, window time.Duration,
+) error {
+	err := Bun().NewSelect().TableExpr("task_stats").
+		ColumnExpr("percentile_cont(0.5) WITHIN GROUP "+
+			"(ORDER BY extract(epoch FROM task_stats.end_time - task_stats.start_time))").
+		Join("JOIN allocations ON allocations.allocation_id = task_stats.allocation_id").
+		Where("task_stats.event_type = ?", "QUEUED").
+		Where("allocations.resource_pool = ?", stats.ResourcePool).
+		Where("task_stats.end_time > ?", stats.Time.Add(-window)).
+		Scan(ctx, &stats.MedianWaitSeconds)
+	if err != nil {
+		return err
+	}
+
+	_, err = Bun().NewInsert().Model(stats).Exec(ctx)
+	return err
+}
+
+// PruneResourcePoolStats removes the snapshots of all resource pools older than the retention
+// period.
+func PruneResourcePoolStats(ctx context.Context, retention time.Duration) (int64, error) {
+	res, err := Bun().NewDelete().Model((*model.ResourcePoolStats)(nil)).
+		Where("time < ?", time.Now().Add(-retention)).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ResourcePoolStatsHistory returns the snapshots of a resource pool taken in [start, end). If
+// bucket is non-zero, the snapshots are averaged over consecutive periods of that length.
+func ResourcePoolStatsHistory(
+	ctx context.Context, resourcePool string, start, end time.Time, bucket time.Duration,
+) ([]model.ResourcePoolStats, error) {
+	var stats []model.ResourcePoolStats
+	q := Bun().NewSelect().Model(&stats).
+		Where("resource_pool = ?", resourcePool).
+		Where("time >= ?", start).
+		Where("time < ?", end)
+
+	if bucket > 0 {
+		seconds := bucket.Seconds()
+		q = q.ColumnExpr("resource_pool").
+			ColumnExpr("to_timestamp(floor(extract(epoch FROM time) / ?) * ?) AS time",
+				seconds, seconds).
+			ColumnExpr("avg(queue_length)::int AS queue_length").
+			ColumnExpr("avg(queued_slots)::int AS queued_slots").
+			ColumnExpr("avg(median_wait_seconds) AS median_wait_seconds").
+			ColumnExpr("avg(slots_total)::int AS slots_total").
+			ColumnExpr("avg(slots_used)::int AS slots_used").
+			GroupExpr("resource_pool, 2")
+	}
+
+	if err := q.Order("time ASC").Scan(ctx); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
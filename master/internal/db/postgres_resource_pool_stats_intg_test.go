@@ -0,0 +1,49 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestPruneResourcePoolStats(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	ctx := context.Background()
+
+	pool, other := "pool-"+uuid.NewString(), "pool-"+uuid.NewString()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	for _, s := range []model.ResourcePoolStats{
+		{ResourcePool: pool, Time: now.Add(-48 * time.Hour)},
+		{ResourcePool: pool, Time: now.Add(-time.Hour)},
+		{ResourcePool: other, Time: now.Add(-48 * time.Hour)},
+	} {
+		s := s
+		require.NoError(t, RecordResourcePoolStats(ctx, &s, time.Minute))
+	}
+
+	// The snapshots of every pool older than the retention period are removed.
+	n, err := PruneResourcePoolStats(ctx, 24*time.Hour)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, n, int64(2))
+
+	start := now.Add(-72 * time.Hour)
+	stats, err := ResourcePoolStatsHistory(ctx, pool, start, now, 0)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	require.Equal(t, now.Add(-time.Hour), stats[0].Time.UTC())
+
+	stats, err = ResourcePoolStatsHistory(ctx, other, start, now, 0)
+	require.NoError(t, err)
+	require.Empty(t, stats)
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// SearcherObservation is the best value of the searcher metric that a trial reached with its
+// hyperparameters.
+type SearcherObservation struct {
+	TrialID int           `bun:"trial_id"`
+	HParams model.JSONObj `bun:"hparams"`
+	Metric  float64       `bun:"metric"`
+}
+
+// ExperimentSearcherObservations returns the observations of the trials of an experiment that
+// reported the searcher metric, ordered from the best to the worst according to the experiment's
+// searcher configuration.
+func ExperimentSearcherObservations(
+	ctx context.Context, experimentID int,
+) ([]SearcherObservation, error) {
+	var observations []SearcherObservation
+	err := Bun().NewRaw(`
+SELECT trial_id, hparams, metric
+FROM (
+  SELECT t.id AS trial_id, t.hparams,
+    (v.metrics->'validation_metrics'->>(e.config->'searcher'->>'metric'))::float8 AS metric,
+    CASE WHEN coalesce((e.config->'searcher'->>'smaller_is_better')::boolean, true)
+      THEN 1 ELSE -1 END AS sign
+  FROM trials t
+  JOIN experiments e ON e.id = t.experiment_id
+  JOIN validations v ON v.trial_id = t.id AND v.id = t.best_validation_id
+  WHERE t.experiment_id = ?
+) observed
+WHERE metric IS NOT NULL
+ORDER BY sign * metric ASC, trial_id ASC`, experimentID).Scan(ctx, &observations)
+	if err != nil {
+		return nil, errors.Wrapf(err,
+			"getting searcher observations of experiment %d", experimentID)
+	}
+	return observations, nil
+}
@@ -0,0 +1,58 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/proto/pkg/commonv1"
+	"github.com/determined-ai/determined/proto/pkg/trialv1"
+)
+
+func TestExperimentSearcherObservations(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	ctx := context.Background()
+
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+	first, second := RequireMockTrial(t, db, exp), RequireMockTrial(t, db, exp)
+	// A trial that never validated has nothing to contribute.
+	RequireMockTrial(t, db, exp)
+	addValidation := func(trialID, batches int, metric float64) {
+		require.NoError(t, db.AddValidationMetrics(ctx, &trialv1.TrialMetrics{
+			TrialId:        int32(trialID),
+			StepsCompleted: int32(batches),
+			Metrics: &commonv1.Metrics{
+				AvgMetrics: &structpb.Struct{Fields: map[string]*structpb.Value{
+					defaultSearcherMetric: structpb.NewNumberValue(metric),
+				}},
+				BatchMetrics: []*structpb.Struct{},
+			},
+		}))
+	}
+
+	observations, err := ExperimentSearcherObservations(ctx, exp.ID)
+	require.NoError(t, err)
+	require.Empty(t, observations)
+
+	addValidation(first.ID, 10, 0.5)
+	addValidation(first.ID, 20, 0.3)
+	addValidation(second.ID, 10, 0.2)
+
+	observations, err = ExperimentSearcherObservations(ctx, exp.ID)
+	require.NoError(t, err)
+	require.Len(t, observations, 2)
+	require.Equal(t, second.ID, observations[0].TrialID)
+	require.Equal(t, 0.2, observations[0].Metric)
+	require.Equal(t, first.ID, observations[1].TrialID)
+	require.Equal(t, 0.3, observations[1].Metric)
+	require.EqualValues(t, 1, observations[1].HParams["global_batch_size"])
+}
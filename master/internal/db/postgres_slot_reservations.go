@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// AddSlotReservation persists a new slot reservation, filling in its ID.
+func AddSlotReservation(ctx context.Context, r *model.SlotReservation) error {
+	_, err := Bun().NewInsert().Model(r).Returning("id").Exec(ctx)
+	return err
+}
+
+// SlotReservations returns the reservations of a resource pool that have not ended by the given
+// time, ordered by when they start. If resourcePool is empty, those of every pool are returned.
+func SlotReservations(
+	ctx context.Context, resourcePool string, endingAfter time.Time,
+) ([]model.SlotReservation, error) {
+	reservations := []model.SlotReservation{}
+	q := Bun().NewSelect().Model(&reservations).Where("end_time > ?", endingAfter)
+	if resourcePool != "" {
+		q = q.Where("resource_pool = ?", resourcePool)
+	}
+	if err := q.Order("start_time ASC", "id ASC").Scan(ctx); err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// DeleteSlotReservation deletes a reservation of the given resource pool, returning ErrNotFound
+// if there is no such reservation.
+func DeleteSlotReservation(ctx context.Context, resourcePool string, id int) error {
+	res, err := Bun().NewDelete().Table("slot_reservations").
+		Where("id = ?", id).
+		Where("resource_pool = ?", resourcePool).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// AddAllocationSystemMetrics records the resource usage of a container of an allocation.
+func AddAllocationSystemMetrics(ctx context.Context, m *model.AllocationSystemMetrics) error {
+	if _, err := Bun().NewInsert().Model(m).Exec(ctx); err != nil {
+		return errors.Wrapf(err, "adding system metrics of allocation %s", m.AllocationID)
+	}
+	return nil
+}
+
+// TaskSystemMetrics returns the resource usage of the containers of a task, or of one of its
+// allocations if allocationID is not empty, measured from start until end, which are unbounded if
+// they are zero. The measurements are ordered by time.
+func TaskSystemMetrics(
+	ctx context.Context, taskID model.TaskID, allocationID model.AllocationID,
+	start, end time.Time,
+) ([]model.AllocationSystemMetrics, error) {
+	metrics := []model.AllocationSystemMetrics{}
+	q := Bun().NewSelect().Model(&metrics).
+		Where("allocation_id IN (SELECT allocation_id FROM allocations WHERE task_id = ?)", taskID).
+		Order("time ASC", "container_id ASC")
+	if allocationID != "" {
+		q = q.Where("allocation_id = ?", allocationID)
+	}
+	if !start.IsZero() {
+		q = q.Where("time >= ?", start)
+	}
+	if !end.IsZero() {
+		q = q.Where("time < ?", end)
+	}
+	if err := q.Scan(ctx); err != nil {
+		return nil, errors.Wrapf(err, "getting system metrics of task %s", taskID)
+	}
+	return metrics, nil
+}
+
+// PruneSystemMetrics removes the measurements of resource usage older than the retention period.
+func PruneSystemMetrics(ctx context.Context, retention time.Duration) (int64, error) {
+	res, err := Bun().NewDelete().Model((*model.AllocationSystemMetrics)(nil)).
+		Where("time < ?", time.Now().Add(-retention)).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// PruneSystemMetricsLoop prunes the measurements of resource usage once an hour until the context
+// is canceled. A retention period of zero keeps them forever.
+func PruneSystemMetricsLoop(ctx context.Context, retention time.Duration) {
+	if retention <= 0 {
+		return
+	}
+	t := time.NewTicker(time.Hour)
+	defer t.Stop()
+	for {
+		if n, err := PruneSystemMetrics(ctx, retention); err != nil {
+			log.WithError(err).Error("failed to prune system metrics")
+		} else if n > 0 {
+			log.Infof("pruned %d system metrics older than %s", n, retention)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
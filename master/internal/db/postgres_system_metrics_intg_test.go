@@ -0,0 +1,63 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+)
+
+func TestTaskSystemMetrics(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	ctx := context.Background()
+
+	task := RequireMockTask(t, db, nil)
+	alloc := RequireMockAllocation(t, db, task.TaskID)
+	other := RequireMockAllocation(t, db, RequireMockTask(t, db, nil).TaskID)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	add := func(a model.AllocationID, container string, at time.Time, cpu float64) {
+		require.NoError(t, AddAllocationSystemMetrics(ctx, &model.AllocationSystemMetrics{
+			AllocationID: a,
+			ContainerID:  container,
+			SystemMetrics: model.SystemMetrics{
+				Time:        at,
+				CPUCores:    ptrs.Ptr(cpu),
+				MemoryBytes: ptrs.Ptr(int64(1 << 30)),
+			},
+		}))
+	}
+	add(alloc.AllocationID, "b", now.Add(-time.Hour), 2)
+	add(alloc.AllocationID, "a", now.Add(-time.Hour), 1)
+	add(alloc.AllocationID, "a", now.Add(-48*time.Hour), 4)
+	add(other.AllocationID, "c", now.Add(-time.Hour), 8)
+
+	metrics, err := TaskSystemMetrics(ctx, task.TaskID, "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, metrics, 3)
+	require.Equal(t, 4.0, *metrics[0].CPUCores)
+	require.Equal(t, "a", metrics[1].ContainerID)
+	require.Equal(t, "b", metrics[2].ContainerID)
+	require.Nil(t, metrics[2].GPUUtilization)
+
+	metrics, err = TaskSystemMetrics(
+		ctx, task.TaskID, alloc.AllocationID, now.Add(-2*time.Hour), now)
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	_, err = PruneSystemMetrics(ctx, 24*time.Hour)
+	require.NoError(t, err)
+	metrics, err = TaskSystemMetrics(ctx, task.TaskID, "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/determined-ai/determined/master/internal/api"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
 
+	"github.com/jackc/pgconn"
 	"github.com/jmoiron/sqlx"
 
 	"github.com/o1egl/paseto"
@@ -248,22 +249,55 @@ type taskLogsFollowState struct {
 	// index scan), we at least pass Postgres the ID and let it begin after a certain ID rather
 	// than offset N into the query.
 	id int64
+	// The timestamp of the last log returned by the query, for logs ordered by timestamp.
+	timestamp time.Time
 }
 
+// taskLogsTimestamp is the expression that logs are ordered by when they are ordered by
+// timestamp. Logs without a timestamp come first, as if they were written at the epoch.
+const taskLogsTimestamp = "coalesce(l.timestamp, 'epoch')"
+
 // TaskLogs takes a task ID and log offset, limit and filters and returns matching logs.
+//
+// Logs are ordered by timestamp, and then by ID, if the order is set, and only by ID otherwise,
+// which is the order they were written in. Either way, each batch continues from where the last
+// one ended, given its follow state, rather than reading past the logs before it, so reading all
+// the logs of a task costs about the same for every batch. The follow state may also be an
+// *api.Cursor, to continue after a log from an earlier request.
 func (db *PgDB) TaskLogs(
 	taskID model.TaskID, limit int, fs []api.Filter, order apiv1.OrderBy, followState interface{},
 ) ([]*model.TaskLog, interface{}, error) {
-	if followState != nil {
-		fs = append(fs, api.Filter{
-			Field:     "id",
-			Operation: api.FilterOperationGreaterThan,
-			Values:    []int64{followState.(*taskLogsFollowState).id},
-		})
+	byTimestamp := order != apiv1.OrderBy_ORDER_BY_UNSPECIFIED
+	if c, ok := followState.(*api.Cursor); ok {
+		state := &taskLogsFollowState{id: int64(c.ID), timestamp: time.Unix(0, 0).UTC()}
+		if c.Timestamp != nil {
+			state.timestamp = *c.Timestamp
+		}
+		followState = state
 	}
 
 	params := []interface{}{taskID, limit}
 	fragment, params := filtersToSQL(fs, params, taskLogsFieldMap)
+	orderBy := fmt.Sprintf("l.id %s", OrderByToSQL(order))
+	if byTimestamp {
+		orderBy = fmt.Sprintf("%[1]s %[2]s, l.id %[2]s", taskLogsTimestamp, OrderByToSQL(order))
+	}
+	if followState != nil {
+		state := followState.(*taskLogsFollowState)
+		cmp := ">"
+		if order == apiv1.OrderBy_ORDER_BY_DESC {
+			cmp = "<"
+		}
+		if byTimestamp {
+			fragment += fmt.Sprintf("\nAND (%s, l.id) %s ($%d, $%d)",
+				taskLogsTimestamp, cmp, len(params)+1, len(params)+2)
+			params = append(params, state.timestamp, state.id)
+		} else {
+			fragment += fmt.Sprintf("\nAND l.id %s $%d", cmp, len(params)+1)
+			params = append(params, state.id)
+		}
+	}
+
 	query := fmt.Sprintf(`
 SELECT
     l.id,
@@ -280,8 +314,8 @@ SELECT
 FROM task_logs l
 WHERE l.task_id = $1
 %s
-ORDER BY l.id %s LIMIT $2
-`, fragment, OrderByToSQL(order))
+ORDER BY %s LIMIT $2
+`, fragment, orderBy)
 
 	var b []*model.TaskLog
 	if err := db.queryRows(query, &b, params...); err != nil {
@@ -290,7 +324,11 @@ ORDER BY l.id %s LIMIT $2
 
 	if len(b) > 0 {
 		lastLog := b[len(b)-1]
-		followState = &taskLogsFollowState{id: int64(*lastLog.ID)}
+		state := &taskLogsFollowState{id: int64(*lastLog.ID), timestamp: time.Unix(0, 0).UTC()}
+		if lastLog.Timestamp != nil {
+			state.timestamp = *lastLog.Timestamp
+		}
+		followState = state
 	}
 
 	return b, followState, nil
@@ -358,6 +396,17 @@ WHERE task_id = $1
 	return count, nil
 }
 
+// ValidateTaskLogsRegex implements task.LogBackend by having Postgres compile the pattern the
+// same way log searches do.
+func (db *PgDB) ValidateTaskLogsRegex(pattern string) error {
+	var matched bool
+	err := db.sql.QueryRow(`SELECT '' ~* $1`, pattern).Scan(&matched)
+	if pgErrCode(err) == CodeInvalidRegularExpression {
+		return api.AsValidationError("%s", err.(*pgconn.PgError).Message)
+	}
+	return err
+}
+
 // RecordTaskStats record stats for tasks.
 func (db *PgDB) RecordTaskStats(stats *model.TaskStats) error {
 	return RecordTaskStatsBun(stats)
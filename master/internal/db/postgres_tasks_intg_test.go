@@ -19,9 +19,11 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/determined-ai/determined/master/internal/api"
 	"github.com/determined-ai/determined/master/pkg/etc"
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
 	"github.com/determined-ai/determined/proto/pkg/taskv1"
 )
 
@@ -262,3 +264,114 @@ func TestExhaustiveEnums(t *testing.T) {
 		require.JSONEq(t, string(pb), string(gb))
 	}
 }
+
+func TestTaskLogsSearch(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+
+	user := RequireMockUser(t, db)
+	task := RequireMockTask(t, db, &user.ID)
+
+	var logs []*model.TaskLog
+	for i, msg := range []string{
+		"Epoch 1 loss=0.5",
+		"RuntimeError: CUDA out of memory",
+		"epoch 2 loss=0.3",
+	} {
+		logs = append(logs, &model.TaskLog{
+			TaskID: string(task.TaskID),
+			RankID: ptrs.Ptr(i % 2),
+			Log:    msg,
+		})
+	}
+	require.NoError(t, db.AddTaskLogs(logs))
+
+	search := func(fs ...api.Filter) []string {
+		found, _, err := db.TaskLogs(task.TaskID, 10, fs, apiv1.OrderBy_ORDER_BY_ASC, nil)
+		require.NoError(t, err)
+		var msgs []string
+		for _, l := range found {
+			msgs = append(msgs, l.Log)
+		}
+		return msgs
+	}
+
+	require.Equal(t, []string{"RuntimeError: CUDA out of memory"}, search(api.Filter{
+		Field:     "log",
+		Operation: api.FilterOperationStringContainment,
+		Values:    "cuda",
+	}))
+	require.Equal(t, []string{"Epoch 1 loss=0.5", "epoch 2 loss=0.3"}, search(api.Filter{
+		Field:     "log",
+		Operation: api.FilterOperationRegexMatch,
+		Values:    `epoch \d+ loss=`,
+	}))
+	require.Equal(t, []string{"Epoch 1 loss=0.5"}, search(api.Filter{
+		Field:     "log",
+		Operation: api.FilterOperationRegexMatch,
+		Values:    `^epoch`,
+	}, api.Filter{
+		Field:     "rank_id",
+		Operation: api.FilterOperationIn,
+		Values:    []int32{0},
+	}))
+
+	// Patterns are checked with the syntax of Postgres, which allows back-references.
+	require.NoError(t, db.ValidateTaskLogsRegex(`(a)\1`))
+	require.ErrorIs(t, db.ValidateTaskLogsRegex(`epoch (`), api.ErrInvalid)
+}
+
+func TestTaskLogsKeyset(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+
+	user := RequireMockUser(t, db)
+	task := RequireMockTask(t, db, &user.ID)
+
+	// Logs are written out of order, and some share a timestamp.
+	start := time.Date(2022, 12, 25, 0, 0, 0, 0, time.UTC)
+	var logs []*model.TaskLog
+	for i, offset := range []int{3, 1, 1, 0, 2, 1, 4} {
+		logs = append(logs, &model.TaskLog{
+			TaskID:    string(task.TaskID),
+			Timestamp: ptrs.Ptr(start.Add(time.Duration(offset) * time.Second)),
+			Log:       fmt.Sprintf("%d", i),
+		})
+	}
+	logs = append(logs, &model.TaskLog{TaskID: string(task.TaskID), Log: "no timestamp"})
+	require.NoError(t, db.AddTaskLogs(logs))
+
+	readAll := func(order apiv1.OrderBy, state interface{}) []string {
+		var msgs []string
+		for {
+			batch, next, err := db.TaskLogs(task.TaskID, 2, nil, order, state)
+			require.NoError(t, err)
+			if len(batch) == 0 {
+				return msgs
+			}
+			for _, l := range batch {
+				msgs = append(msgs, l.Log)
+			}
+			state = next
+		}
+	}
+
+	asc := []string{"no timestamp", "3", "1", "2", "5", "4", "0", "6"}
+	require.Equal(t, asc, readAll(apiv1.OrderBy_ORDER_BY_ASC, nil))
+	desc := make([]string, len(asc))
+	for i, msg := range asc {
+		desc[len(asc)-1-i] = msg
+	}
+	require.Equal(t, desc, readAll(apiv1.OrderBy_ORDER_BY_DESC, nil))
+	require.Equal(t, []string{"0", "1", "2", "3", "4", "5", "6", "no timestamp"},
+		readAll(apiv1.OrderBy_ORDER_BY_UNSPECIFIED, nil))
+
+	// A cursor continues after the log it marks.
+	first, _, err := db.TaskLogs(task.TaskID, 3, nil, apiv1.OrderBy_ORDER_BY_ASC, nil)
+	require.NoError(t, err)
+	last := first[len(first)-1]
+	cursor := &api.Cursor{ID: *last.ID, Timestamp: last.Timestamp}
+	require.Equal(t, asc[3:], readAll(apiv1.OrderBy_ORDER_BY_ASC, cursor))
+}
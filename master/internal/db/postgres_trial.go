@@ -8,6 +8,7 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
+	"github.com/uptrace/bun/dialect/pgdialect"
 
 	"github.com/determined-ai/determined/master/internal/api"
 	"github.com/determined-ai/determined/master/pkg/model"
@@ -141,6 +142,32 @@ WHERE id = $1`, id, runID); err != nil {
 	return nil
 }
 
+// AddTrialRunState records the state that determines the data order of a run of a trial.
+func (db *PgDB) AddTrialRunState(s *model.TrialRunState) error {
+	if _, err := Bun().NewInsert().Model(s).
+		On("CONFLICT (trial_id, run_id) DO UPDATE").
+		Set("trial_seed = EXCLUDED.trial_seed").
+		Set("slots = EXCLUDED.slots").
+		Set("steps_completed = EXCLUDED.steps_completed").
+		Set("checkpoint_uuid = EXCLUDED.checkpoint_uuid").
+		Exec(context.TODO()); err != nil {
+		return errors.Wrapf(err, "recording state of run %d of trial %d", s.RunID, s.TrialID)
+	}
+	return nil
+}
+
+// TrialRunStates returns the recorded states of the runs of a trial, in the order they ran.
+func TrialRunStates(ctx context.Context, trialID int) ([]model.TrialRunState, error) {
+	states := []model.TrialRunState{}
+	if err := Bun().NewSelect().Model(&states).
+		Where("trial_id = ?", trialID).
+		Order("run_id ASC").
+		Scan(ctx); err != nil {
+		return nil, errors.Wrapf(err, "getting run states of trial %d", trialID)
+	}
+	return states, nil
+}
+
 // UpdateTrialRestarts sets the trial's restart count.
 func (db *PgDB) UpdateTrialRestarts(id, restartCount int) error {
 	if _, err := db.sql.Exec(`
@@ -152,10 +179,10 @@ WHERE id = $1`, id, restartCount); err != nil {
 	return nil
 }
 
-// AddTrainingMetrics adds a completed step to the database with the given training metrics.
-// If these training metrics occur before any others, a rollback is assumed and later
-// training and validation metrics are cleaned up.
-func (db *PgDB) AddTrainingMetrics(ctx context.Context, m *trialv1.TrialMetrics) error {
+// addTrainingMetrics adds a completed step to the database with the given training metrics in a
+// transaction of its own. If these training metrics occur before any others, a rollback is
+// assumed and later training and validation metrics are cleaned up.
+func (db *PgDB) addTrainingMetrics(ctx context.Context, m *trialv1.TrialMetrics) error {
 	return db.withTransaction("add training metrics", func(tx *sqlx.Tx) error {
 		if err := checkTrialRunID(ctx, tx, m.TrialId, m.TrialRunId); err != nil {
 			return err
@@ -255,6 +282,42 @@ VALUES
 	})
 }
 
+// AddGenericMetrics adds metrics to the database in a group other than training or validation.
+// As with training metrics, if these metrics occur before others in their group, a rollback is
+// assumed and the later metrics of the group are cleaned up.
+func (db *PgDB) AddGenericMetrics(
+	ctx context.Context, group string, m *trialv1.TrialMetrics,
+) error {
+	return db.withTransaction("add generic metrics", func(tx *sqlx.Tx) error {
+		if err := checkTrialRunID(ctx, tx, m.TrialId, m.TrialRunId); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+UPDATE raw_generic_metrics SET archived = true
+WHERE trial_id = $1
+  AND trial_run_id < $2
+  AND metric_group = $3
+  AND total_batches >= $4;
+`, m.TrialId, m.TrialRunId, group, m.StepsCompleted); err != nil {
+			return errors.Wrapf(err, "archiving %s metrics", group)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO raw_generic_metrics
+	(trial_id, trial_run_id, metric_group, end_time, metrics, total_batches)
+VALUES
+	($1, $2, $3, now(), $4, $5)
+`, m.TrialId, m.TrialRunId, group, model.JSONObj{
+			"avg_metrics":   m.Metrics.AvgMetrics,
+			"batch_metrics": m.Metrics.BatchMetrics,
+		}, m.StepsCompleted); err != nil {
+			return errors.Wrapf(err, "inserting %s metrics", group)
+		}
+		return nil
+	})
+}
+
 // ensureStep inserts a noop step if no step exists at the batch index of the validation.
 // This is used to make sure there is at least a dummy step for each validation or checkpoint,
 // in the event one comes without (e.g. perform_initial_validation).
@@ -346,6 +409,71 @@ AND total_batches = $2`, &validation, trialID, totalBatches); errors.Cause(err)
 	return &validation, nil
 }
 
+// ValidationMetricSeries returns the values of a validation metric of a trial in the order they
+// were reported, leaving out validations that do not have a number for it. NaN and infinite values
+// are included.
+func (db *PgDB) ValidationMetricSeries(
+	ctx context.Context, trialID int, metric string,
+) ([]float64, error) {
+	var values []float64
+	if err := Bun().NewRaw(`
+SELECT value FROM (
+  SELECT v.total_batches, CASE
+    WHEN jsonb_typeof(m.value) = 'number' THEN (m.value #>> '{}')::float8
+    WHEN m.value #>> '{}' IN ('NaN', 'Infinity', '-Infinity') THEN (m.value #>> '{}')::float8
+  END AS value
+  FROM validations v, jsonb_extract_path(v.metrics, 'validation_metrics', ?) m(value)
+  WHERE v.trial_id = ? AND v.state = 'COMPLETED'
+) s
+WHERE value IS NOT NULL
+ORDER BY total_batches`, metric, trialID).Scan(ctx, &values); err != nil {
+		return nil, errors.Wrapf(err, "querying %s validations of trial %d", metric, trialID)
+	}
+	return values, nil
+}
+
+// UpdateTrialMetadata merges the given keys into the metadata of a trial, removing those whose
+// value is nil, and returns the resulting metadata.
+func (db *PgDB) UpdateTrialMetadata(
+	ctx context.Context, trialID int, metadata model.JSONObj,
+) (model.JSONObj, error) {
+	set := map[string]interface{}{}
+	remove := []string{}
+	for k, v := range metadata {
+		if v == nil {
+			remove = append(remove, k)
+		} else {
+			set[k] = v
+		}
+	}
+	var updated model.JSONObj
+	res, err := Bun().NewUpdate().Table("trials").
+		Set("metadata = (metadata || ?) - ?::text[]", set, pgdialect.Array(remove)).
+		Where("id = ?", trialID).
+		Returning("metadata").
+		Exec(ctx, &updated)
+	if err = MustHaveAffectedRows(res, err); err != nil {
+		return nil, MatchSentinelError(err)
+	}
+	return updated, nil
+}
+
+// AddTrialAlert records that an alert fired for a trial, unless it already fired for the trial,
+// and returns whether it was recorded.
+func (db *PgDB) AddTrialAlert(ctx context.Context, a *model.TrialAlert) (bool, error) {
+	res, err := Bun().NewInsert().Model(a).
+		On("CONFLICT (trial_id, name) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return false, errors.Wrapf(err, "recording alert %s of trial %d", a.Name, a.TrialID)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
 // CheckpointByTotalBatches looks up a checkpoint by trial and total batch,
 // returning nil if none exists.
 func (db *PgDB) CheckpointByTotalBatches(trialID, totalBatches int) (*model.Checkpoint, error) {
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/determined-ai/determined/master/pkg/etc"
@@ -177,3 +178,58 @@ func TestAddValidationMetricsDupeCheckpoints(t *testing.T) {
 	require.Equal(t, nil, checkpoints[1].Training.TrainingMetrics.AvgMetrics.AsMap()["loss"])
 	require.Equal(t, 1.5, checkpoints[1].Training.ValidationMetrics.AvgMetrics.AsMap()["loss"])
 }
+
+func TestUpdateTrialMetadata(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	ctx := context.Background()
+
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+	tr := RequireMockTrial(t, db, exp)
+	other := RequireMockTrial(t, db, exp)
+
+	metadata, err := db.UpdateTrialMetadata(ctx, tr.ID, model.JSONObj{
+		"dataset": map[string]interface{}{"hash": "abc", "rows": 10},
+		"commit":  "0123abc",
+		"notes":   "baseline",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "0123abc", metadata["commit"])
+
+	// Keys are merged in and removed when null.
+	metadata, err = db.UpdateTrialMetadata(ctx, tr.ID, model.JSONObj{
+		"notes":  nil,
+		"commit": "4567def",
+	})
+	require.NoError(t, err)
+	require.Equal(t, model.JSONObj{
+		"dataset": map[string]interface{}{"hash": "abc", "rows": float64(10)},
+		"commit":  "4567def",
+	}, metadata)
+
+	_, err = db.UpdateTrialMetadata(ctx, -1, model.JSONObj{"commit": "4567def"})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	filter, err := structpb.NewStruct(map[string]interface{}{
+		"dataset": map[string]interface{}{"hash": "abc"},
+	})
+	require.NoError(t, err)
+	filterJSON, err := protojson.Marshal(filter)
+	require.NoError(t, err)
+	var ids []int
+	require.NoError(t, Bun().NewSelect().Table("trials").Column("id").
+		Where("experiment_id = ?", exp.ID).
+		Where("metadata @> ?::jsonb", string(filterJSON)).
+		Scan(ctx, &ids))
+	require.Equal(t, []int{tr.ID}, ids)
+
+	var trResp trialv1.Trial
+	require.NoError(t, db.QueryProtof(
+		"proto_get_trials_plus", []any{"($1::int, $2::int)"}, &trResp, other.ID, 1))
+	require.Empty(t, trResp.Metadata.AsMap())
+	require.NoError(t, db.QueryProtof(
+		"proto_get_trials_plus", []any{"($1::int, $2::int)"}, &trResp, tr.ID, 1))
+	require.Equal(t, "4567def", trResp.Metadata.AsMap()["commit"])
+}
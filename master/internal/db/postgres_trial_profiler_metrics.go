@@ -1,14 +1,21 @@
 package db
 
 import (
+	"context"
+	"sort"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
 
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/proto/pkg/trialv1"
 )
 
+// profilerTimelineResolution is the span of time that the readings of profiler series are
+// aggregated over when they are stored for their timeline.
+const profilerTimelineResolution = time.Second
+
 // InsertTrialProfilerMetricsBatch inserts a batch of metrics into the database.
 func (db *PgDB) InsertTrialProfilerMetricsBatch(
 	values []float32, batches []int32, timestamps []time.Time, labels []byte,
@@ -55,3 +62,132 @@ OFFSET $2 LIMIT $3`, labelsJSON, offset, limit)
 	}
 	return pBatches, nil
 }
+
+// AddTrialProfilerTimeline adds a batch of readings of a profiler series to the timeline of its
+// trial.
+func AddTrialProfilerTimeline(
+	ctx context.Context, labels *trialv1.TrialProfilerMetricLabels,
+	values []float32, timestamps []time.Time,
+) error {
+	buckets := profilerTimelineBuckets(labels, values, timestamps)
+	if len(buckets) == 0 {
+		return nil
+	}
+	if _, err := Bun().NewInsert().Model(&buckets).
+		On("CONFLICT (trial_id, name, agent_id, gpu_uuid, metric_type, bucket) DO UPDATE").
+		Set("count = ?TableAlias.count + EXCLUDED.count").
+		Set("sum = ?TableAlias.sum + EXCLUDED.sum").
+		Set("min = LEAST(?TableAlias.min, EXCLUDED.min)").
+		Set("max = GREATEST(?TableAlias.max, EXCLUDED.max)").
+		Exec(ctx); err != nil {
+		return errors.Wrapf(err, "adding to the profiler timeline of trial %d", labels.TrialId)
+	}
+	return nil
+}
+
+// profilerTimelineBuckets aggregates readings of a profiler series into buckets of
+// profilerTimelineResolution, ordered by time.
+func profilerTimelineBuckets(
+	labels *trialv1.TrialProfilerMetricLabels, values []float32, timestamps []time.Time,
+) []model.TrialProfilerTimelineBucket {
+	byTime := map[time.Time]*model.TrialProfilerTimelineBucket{}
+	for i, v := range values {
+		t := timestamps[i].UTC().Truncate(profilerTimelineResolution)
+		b, ok := byTime[t]
+		if !ok {
+			b = &model.TrialProfilerTimelineBucket{
+				TrialID:    int(labels.TrialId),
+				Name:       labels.Name,
+				AgentID:    labels.AgentId,
+				GPUUUID:    labels.GpuUuid,
+				MetricType: labels.MetricType.String(),
+				Bucket:     t,
+				Min:        v,
+				Max:        v,
+			}
+			byTime[t] = b
+		}
+		b.Count++
+		b.Sum += float64(v)
+		if v < b.Min {
+			b.Min = v
+		}
+		if v > b.Max {
+			b.Max = v
+		}
+	}
+
+	buckets := make([]model.TrialProfilerTimelineBucket, 0, len(byTime))
+	for _, b := range byTime {
+		buckets = append(buckets, *b)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Bucket.Before(buckets[j].Bucket)
+	})
+	return buckets
+}
+
+// TrialProfilerTimeline returns the timeline of the profiler series of a trial, or of those with
+// the given names, from start until end, which default to the first and last readings if they are
+// zero. The readings are aggregated over spans of time, in whole multiples of the stored
+// resolution, that are wide enough that each series has about maxPoints points at most. The points
+// are ordered by series and then by time, and are returned with the span they aggregate over.
+func TrialProfilerTimeline(
+	ctx context.Context, trialID int, names []string, start, end time.Time, maxPoints int,
+) ([]model.TrialProfilerTimelinePoint, time.Duration, error) {
+	query := func() *bun.SelectQuery {
+		q := Bun().NewSelect().Table("trial_profiler_timeline").Where("trial_id = ?", trialID)
+		if len(names) > 0 {
+			q = q.Where("name IN (?)", bun.In(names))
+		}
+		return q
+	}
+
+	if start.IsZero() || end.IsZero() {
+		var first, last bun.NullTime
+		if err := query().ColumnExpr("min(bucket), max(bucket)").Scan(ctx, &first, &last); err != nil {
+			return nil, 0, errors.Wrapf(err, "getting the profiler timeline range of trial %d", trialID)
+		}
+		if start.IsZero() {
+			start = first.Time
+		}
+		if end.IsZero() && !last.IsZero() {
+			end = last.Add(profilerTimelineResolution)
+		}
+	}
+	width := profilerTimelineWidth(end.Sub(start), maxPoints)
+	if !end.After(start) {
+		return nil, width, nil
+	}
+
+	points := []model.TrialProfilerTimelinePoint{}
+	seconds := int64(width / time.Second)
+	if err := query().
+		Column("trial_id", "name", "agent_id", "gpu_uuid", "metric_type").
+		ColumnExpr("to_timestamp(floor(extract(epoch FROM bucket) / ?) * ?) AS time", seconds, seconds).
+		ColumnExpr("sum(sum) / sum(count) AS mean").
+		ColumnExpr("min(min) AS min").
+		ColumnExpr("max(max) AS max").
+		Where("bucket >= ?", start).
+		Where("bucket < ?", end).
+		GroupExpr("trial_id, name, agent_id, gpu_uuid, metric_type, time").
+		OrderExpr("name, agent_id, gpu_uuid, metric_type, time").
+		Scan(ctx, &points); err != nil {
+		return nil, 0, errors.Wrapf(err, "getting the profiler timeline of trial %d", trialID)
+	}
+	return points, width, nil
+}
+
+// profilerTimelineWidth returns the smallest whole multiple of the stored resolution that splits
+// the span into no more than maxPoints parts.
+func profilerTimelineWidth(span time.Duration, maxPoints int) time.Duration {
+	if maxPoints <= 0 || span <= 0 {
+		return profilerTimelineResolution
+	}
+	parts := (span + time.Duration(maxPoints) - 1) / time.Duration(maxPoints)
+	width := (parts + profilerTimelineResolution - 1) / profilerTimelineResolution
+	if width < 1 {
+		width = 1
+	}
+	return width * profilerTimelineResolution
+}
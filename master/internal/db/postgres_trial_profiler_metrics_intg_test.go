@@ -0,0 +1,86 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/proto/pkg/trialv1"
+)
+
+func TestTrialProfilerTimeline(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	ctx := context.Background()
+
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+	tr := RequireMockTrial(t, db, exp)
+
+	gpuUtil := &trialv1.TrialProfilerMetricLabels{
+		TrialId:    int32(tr.ID),
+		Name:       "gpu_util",
+		AgentId:    "agent",
+		GpuUuid:    "GPU-0",
+		MetricType: trialv1.TrialProfilerMetricLabels_PROFILER_METRIC_TYPE_SYSTEM,
+	}
+	dataloader := &trialv1.TrialProfilerMetricLabels{
+		TrialId:    int32(tr.ID),
+		Name:       "dataloader_next",
+		MetricType: trialv1.TrialProfilerMetricLabels_PROFILER_METRIC_TYPE_TIMING,
+	}
+	start := time.Date(2022, 12, 30, 12, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	// Readings in the same second are aggregated, including across batches.
+	require.NoError(t, AddTrialProfilerTimeline(ctx, gpuUtil,
+		[]float32{10, 30, 50}, []time.Time{at(0), at(500), at(1500)}))
+	require.NoError(t, AddTrialProfilerTimeline(ctx, gpuUtil,
+		[]float32{20, 90}, []time.Time{at(900), at(3000)}))
+	require.NoError(t, AddTrialProfilerTimeline(ctx, dataloader,
+		[]float32{0.5}, []time.Time{at(100)}))
+
+	points, width, err := TrialProfilerTimeline(ctx, tr.ID, []string{"gpu_util"},
+		time.Time{}, time.Time{}, 10)
+	require.NoError(t, err)
+	require.Equal(t, time.Second, width)
+	require.Len(t, points, 3)
+	require.True(t, start.Equal(points[0].Time))
+	require.InDelta(t, 20, points[0].Mean, 1e-6)
+	require.Equal(t, float32(10), points[0].Min)
+	require.Equal(t, float32(30), points[0].Max)
+	require.True(t, proto.Equal(gpuUtil, points[0].Labels()))
+
+	// Spans wide enough to fit the range in the points requested are aggregated again.
+	points, width, err = TrialProfilerTimeline(ctx, tr.ID, []string{"gpu_util"},
+		start, at(4000), 2)
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Second, width)
+	require.Len(t, points, 2)
+	require.InDelta(t, 27.5, points[0].Mean, 1e-6)
+	require.InDelta(t, 90, points[1].Mean, 1e-6)
+
+	points, _, err = TrialProfilerTimeline(ctx, tr.ID, nil, at(1000), time.Time{}, 10)
+	require.NoError(t, err)
+	require.Len(t, points, 2, "the range should leave out the first second")
+
+	points, _, err = TrialProfilerTimeline(ctx, tr.ID, nil, time.Time{}, time.Time{}, 10)
+	require.NoError(t, err)
+	require.Len(t, points, 4)
+	require.Equal(t, "dataloader_next", points[0].Name)
+}
+
+func TestProfilerTimelineWidth(t *testing.T) {
+	require.Equal(t, time.Second, profilerTimelineWidth(0, 500))
+	require.Equal(t, time.Second, profilerTimelineWidth(10*time.Second, 500))
+	require.Equal(t, 2*time.Second, profilerTimelineWidth(1000*time.Second, 500))
+	require.Equal(t, 3*time.Second, profilerTimelineWidth(1001*time.Second, 500))
+}
@@ -12,21 +12,36 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/determined-ai/determined/master/internal/config"
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/proto/pkg/userv1"
 )
 
-// SessionDuration is how long a newly created session is valid.
-const SessionDuration = 7 * 24 * time.Hour
+// SessionDuration returns how long a newly created session is valid.
+func SessionDuration() time.Duration {
+	return time.Duration(config.GetMasterConfig().Security.Session.MaxDuration)
+}
 
 // StartUserSession creates a row in the user_sessions table.
 func (db *PgDB) StartUserSession(user *model.User) (string, error) {
+	return db.startUserSession(user, false)
+}
+
+// StartMFAEnrollmentSession creates a session for a user who must enroll in multi-factor
+// authentication, which can only be used to enroll, and returns its token.
+func (db *PgDB) StartMFAEnrollmentSession(user *model.User) (string, error) {
+	return db.startUserSession(user, true)
+}
+
+func (db *PgDB) startUserSession(user *model.User, mfaEnrollmentOnly bool) (string, error) {
 	userSession := &model.UserSession{
-		UserID: user.ID,
-		Expiry: time.Now().Add(SessionDuration),
+		UserID:            user.ID,
+		Expiry:            time.Now().Add(SessionDuration()),
+		MFAEnrollmentOnly: mfaEnrollmentOnly,
 	}
 
-	query := "INSERT INTO user_sessions (user_id, expiry) VALUES (:user_id, :expiry) RETURNING id"
+	query := `INSERT INTO user_sessions (user_id, expiry, mfa_enrollment_only)
+VALUES (:user_id, :expiry, :mfa_enrollment_only) RETURNING id`
 	if err := db.namedGet(&userSession.ID, query, *userSession); err != nil {
 		return "", err
 	}
@@ -60,8 +75,8 @@ func (db *PgDB) DeleteUserSessionByID(sessionID model.SessionID) error {
 func addUser(tx *sqlx.Tx, user *model.User) (model.UserID, error) {
 	stmt, err := tx.PrepareNamed(`
 INSERT INTO users
-(username, admin, active, password_hash, display_name)
-VALUES (:username, :admin, :active, :password_hash, :display_name)
+(username, admin, active, password_hash, display_name, service_account, owner_id)
+VALUES (:username, :admin, :active, :password_hash, :display_name, :service_account, :owner_id)
 RETURNING id`)
 	if err != nil {
 		return 0, errors.WithStack(err)
@@ -80,10 +95,24 @@ RETURNING id`)
 	if err := addUserPersonalGroup(tx, user.ID); err != nil {
 		return 0, errors.Wrap(err, "error adding users personal group")
 	}
+	if err := recordPasswordChange(tx, user.ID); err != nil {
+		return 0, err
+	}
 
 	return user.ID, nil
 }
 
+// recordPasswordChange restarts the expiry of the password of a user.
+func recordPasswordChange(tx *sqlx.Tx, userID model.UserID) error {
+	query := `
+INSERT INTO user_password_states (user_id) VALUES ($1)
+ON CONFLICT (user_id) DO UPDATE SET password_changed_at = now()`
+	if _, err := tx.Exec(query, userID); err != nil {
+		return errors.Wrap(err, "error recording password change")
+	}
+	return nil
+}
+
 // PersonalGroupPostfix is the system postfix appended to the username of all personal groups.
 const PersonalGroupPostfix = "DeterminedPersonalGroup"
 
@@ -137,6 +166,14 @@ func deleteAgentUserGroup(tx *sqlx.Tx, userID model.UserID) error {
 
 // AddUser creates a new user.
 func (db *PgDB) AddUser(user *model.User, ug *model.AgentUserGroup) (model.UserID, error) {
+	return db.AddUserWithGroups(user, ug, nil)
+}
+
+// AddUserWithGroups creates a new user and adds them to the groups, all in one transaction. It
+// returns ErrNotFound if one of the groups does not exist.
+func (db *PgDB) AddUserWithGroups(
+	user *model.User, ug *model.AgentUserGroup, groupIDs []int,
+) (model.UserID, error) {
 	tx, err := db.sql.Beginx()
 	if err != nil {
 		return 0, errors.WithStack(err)
@@ -163,6 +200,16 @@ func (db *PgDB) AddUser(user *model.User, ug *model.AgentUserGroup) (model.UserI
 		}
 	}
 
+	for _, gid := range groupIDs {
+		if _, err := tx.Exec(`
+INSERT INTO user_group_membership (user_id, group_id) VALUES ($1, $2)`, userID, gid); err != nil {
+			if pgErrCode(err) == CodeForeignKeyViolation {
+				return 0, errors.Wrapf(ErrNotFound, "group (%d) not found", gid)
+			}
+			return 0, errors.Wrapf(err, "error adding user (%d) to group (%d)", userID, gid)
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		return 0, errors.WithStack(err)
 	}
@@ -210,6 +257,9 @@ func (db *PgDB) UpdateUser(updated *model.User, toUpdate []string, ug *model.Age
 		if _, err = tx.Exec(query, updated.ID); err != nil {
 			return errors.Wrap(err, "error deleting user sessions")
 		}
+		if err = recordPasswordChange(tx, updated.ID); err != nil {
+			return err
+		}
 	}
 
 	if ug != nil {
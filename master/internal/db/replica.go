@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+
+	"github.com/determined-ai/determined/master/internal/config"
+)
+
+const replicaLagCheckPeriod = 5 * time.Second
+
+var (
+	replicaMutex  sync.Mutex
+	theReplicaBun *bun.DB
+	replicaMaxLag time.Duration
+	// replicaFresh is 1 while the replica is known to lag behind the primary by at most
+	// replicaMaxLag.
+	replicaFresh int32
+)
+
+// ConnectReplica connects to the read-only replica of the database, to which ReadBun routes
+// expensive reads once MonitorReplicaLag finds that it has caught up with the primary. Its
+// connection pool is sized like that of the primary.
+func ConnectReplica(opts *config.DBConfig) error {
+	dsn, err := withStatementTimeout(opts.Replica.DSN, time.Duration(opts.StatementTimeout))
+	if err != nil {
+		return errors.Wrap(err, "error parsing database replica DSN")
+	}
+	sqlDB, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return errors.Wrap(err, "error opening database replica")
+	}
+	if err := sqlDB.Ping(); err != nil {
+		return errors.Wrap(err, "error connecting to database replica")
+	}
+	configurePool(sqlDB, opts)
+
+	replicaMutex.Lock()
+	defer replicaMutex.Unlock()
+	theReplicaBun = bun.NewDB(sqlDB, pgdialect.New())
+	theReplicaBun.AddQueryHook(slowQueryHook{})
+	replicaMaxLag = opts.Replica.MaxLag()
+	atomic.StoreInt32(&replicaFresh, 0)
+	return nil
+}
+
+// ReadBun returns the database connection to use for reads that are expensive and can tolerate
+// being slightly out of date, such as listing experiments or querying metrics: the replica if one
+// is configured and it is fresh enough, or else the primary. Reads that must observe a write just
+// made by the caller should use Bun instead.
+func ReadBun() *bun.DB {
+	if atomic.LoadInt32(&replicaFresh) == 1 {
+		replicaMutex.Lock()
+		defer replicaMutex.Unlock()
+		if theReplicaBun != nil {
+			return theReplicaBun
+		}
+	}
+	return Bun()
+}
+
+// replicaLag returns how far the replica lags behind the primary. A replica that has replayed
+// everything it received is not behind, however long ago the last transaction was.
+func replicaLag(ctx context.Context, replica *bun.DB) (time.Duration, error) {
+	var seconds float64
+	if err := replica.NewRaw(`
+SELECT coalesce(CASE
+  WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+  ELSE extract(epoch FROM now() - pg_last_xact_replay_timestamp())
+END, 0)`).Scan(ctx, &seconds); err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// MonitorReplicaLag checks how far the replica lags behind the primary every few seconds until
+// the context is canceled, and routes reads back to the primary while it lags too far behind or
+// cannot be reached.
+func MonitorReplicaLag(ctx context.Context) {
+	replicaMutex.Lock()
+	replica, maxLag := theReplicaBun, replicaMaxLag
+	replicaMutex.Unlock()
+	if replica == nil {
+		return
+	}
+
+	t := time.NewTicker(replicaLagCheckPeriod)
+	defer t.Stop()
+	for {
+		lag, err := replicaLag(ctx, replica)
+		fresh := int32(0)
+		switch {
+		case err != nil:
+			log.WithError(err).Warn("failed to check database replica lag")
+		case lag <= maxLag:
+			fresh = 1
+		}
+		if old := atomic.SwapInt32(&replicaFresh, fresh); old != fresh {
+			if fresh == 1 {
+				log.Infof("database replica caught up, routing reads to it")
+			} else {
+				log.Warnf("database replica is unavailable or lags by %s, routing reads to "+
+					"the primary", lag)
+			}
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
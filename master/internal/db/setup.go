@@ -1,7 +1,9 @@
 package db
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -9,24 +11,31 @@ import (
 	"github.com/determined-ai/determined/master/internal/config"
 )
 
-const maxOpenConns = 48
-
 const (
-	cnxTpl = "postgres://%s:%s@%s:%s/%s?application_name=determined-master"
-	sslTpl = "&sslmode=%s&sslrootcert=%s"
+	applicationName = "determined-master"
+	cnxTpl          = "postgres://%s:%s@%s:%s/%s?application_name=" + applicationName
+	sslTpl          = "&sslmode=%s&sslrootcert=%s"
 )
 
 // Connect connects to the database, but doesn't run migrations & inits.
 func Connect(opts *config.DBConfig) (*PgDB, error) {
 	dbURL := fmt.Sprintf(cnxTpl, opts.User, opts.Password, opts.Host, opts.Port, opts.Name)
 	dbURL += fmt.Sprintf(sslTpl, opts.SSLMode, opts.SSLRootCert)
+	poolURL, err := withStatementTimeout(dbURL, time.Duration(opts.StatementTimeout))
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing database URL")
+	}
 	log.Infof("connecting to database %s:%s", opts.Host, opts.Port)
-	db, err := ConnectPostgres(dbURL)
+	db, err := ConnectPostgres(poolURL)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error connecting to database: %s:%s", opts.Host, opts.Port)
 	}
+	// Migrations connect with the URL itself, so that they are never canceled by the statement
+	// timeout.
+	db.url = dbURL
 
-	db.sql.SetMaxOpenConns(maxOpenConns)
+	configurePool(db.sql.DB, opts)
+	SetSlowQueryThreshold(time.Duration(opts.SlowQueryThreshold))
 
 	return db, nil
 }
@@ -47,5 +56,22 @@ func Setup(opts *config.DBConfig) (*PgDB, error) {
 	if err = db.initAllocationSessions(); err != nil {
 		return nil, err
 	}
+	if opts.MetricsStorage == config.MetricsStorageTimescaleDB {
+		log.Info("storing metrics in TimescaleDB hypertables")
+		if err = EnableTimescaleMetrics(
+			context.Background(), opts.TrialsPerMetricsPartition,
+		); err != nil {
+			return nil, err
+		}
+	}
+	if err = RecordSchemaSnapshot(context.Background(), false); err != nil {
+		return nil, err
+	}
+	if opts.Replica != nil {
+		log.Info("connecting to database replica")
+		if err = ConnectReplica(opts); err != nil {
+			return nil, err
+		}
+	}
 	return db, nil
 }
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
+)
+
+const (
+	maxSlowQueries     = 50
+	maxSlowQueryLength = 4096
+)
+
+// SlowQuery is a query made through Bun that ran for at least the slow query threshold.
+type SlowQuery struct {
+	// Query is the query with placeholders in place of its arguments.
+	Query    string
+	Duration time.Duration
+	Time     time.Time
+	Failed   bool
+}
+
+var slowQueries = struct {
+	sync.Mutex
+	threshold time.Duration
+	// recent holds the last maxSlowQueries slow queries, oldest first.
+	recent []SlowQuery
+}{}
+
+// SetSlowQueryThreshold sets how long a query must run to be logged and reported as slow; 0
+// disables reporting slow queries.
+func SetSlowQueryThreshold(threshold time.Duration) {
+	slowQueries.Lock()
+	defer slowQueries.Unlock()
+	slowQueries.threshold = threshold
+}
+
+// SlowQueries returns the most recent slow queries, most recent first.
+func SlowQueries() []SlowQuery {
+	slowQueries.Lock()
+	defer slowQueries.Unlock()
+	queries := make([]SlowQuery, 0, len(slowQueries.recent))
+	for i := len(slowQueries.recent) - 1; i >= 0; i-- {
+		queries = append(queries, slowQueries.recent[i])
+	}
+	return queries
+}
+
+// slowQueryHook records the queries that take longer than the slow query threshold.
+type slowQueryHook struct{}
+
+func (slowQueryHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (slowQueryHook) AfterQuery(_ context.Context, event *bun.QueryEvent) {
+	duration := time.Since(event.StartTime)
+
+	slowQueries.Lock()
+	defer slowQueries.Unlock()
+	if slowQueries.threshold == 0 || duration < slowQueries.threshold {
+		return
+	}
+
+	query := event.QueryTemplate
+	if query == "" {
+		query = event.Query
+	}
+	if len(query) > maxSlowQueryLength {
+		query = query[:maxSlowQueryLength]
+	}
+	log.WithField("duration", duration).Warnf("slow query: %s", query)
+
+	slowQueries.recent = append(slowQueries.recent, SlowQuery{
+		Query:    query,
+		Duration: duration,
+		Time:     event.StartTime,
+		Failed:   event.Err != nil,
+	})
+	if len(slowQueries.recent) > maxSlowQueries {
+		slowQueries.recent = slowQueries.recent[1:]
+	}
+}
@@ -0,0 +1,130 @@
+// Package dependencies stores the experiments that an experiment waits for before it starts, so
+// that experiments can be chained into a pipeline in which each one starts once the experiments it
+// depends on have completed, optionally warm starting from the best checkpoint of one of them.
+package dependencies
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/experimentv1"
+)
+
+// Dependency corresponds to a row in the "experiment_dependencies" DB table.
+type Dependency struct {
+	bun.BaseModel `bun:"table:experiment_dependencies,alias:d"`
+
+	ExperimentID int `bun:"experiment_id,pk"`
+	DependsOnID  int `bun:"depends_on_id,pk"`
+	// InheritCheckpoint is whether the experiment warm starts from the best checkpoint of the
+	// experiment it depends on. At most one dependency of an experiment inherits its checkpoint.
+	InheritCheckpoint bool `bun:"inherit_checkpoint,notnull"`
+	// Met is set once every dependency of the experiment has completed and it has been started.
+	Met bool `bun:"met,notnull"`
+
+	// ExperimentState and DependsOnState are the current states of the two experiments.
+	ExperimentState model.State `bun:"experiment_state,scanonly"`
+	DependsOnState  model.State `bun:"depends_on_state,scanonly"`
+}
+
+// Proto returns the protobuf representation of the dependency.
+func (d Dependency) Proto() *experimentv1.ExperimentDependency {
+	return &experimentv1.ExperimentDependency{
+		ExperimentId:      int32(d.ExperimentID),
+		DependsOnId:       int32(d.DependsOnID),
+		InheritCheckpoint: d.InheritCheckpoint,
+		Met:               d.Met,
+		ExperimentState: experimentv1.State(
+			experimentv1.State_value["STATE_"+string(d.ExperimentState)]),
+		DependsOnState: experimentv1.State(
+			experimentv1.State_value["STATE_"+string(d.DependsOnState)]),
+	}
+}
+
+// Failed returns whether the experiment depended on has stopped or will stop without completing,
+// in which case the dependency can no longer be met.
+func (d Dependency) Failed() bool {
+	switch d.DependsOnState {
+	case model.CompletedState, model.StoppingCompletedState:
+		return false
+	case model.DeletingState, model.DeleteFailedState, model.DeletedState:
+		return true
+	default:
+		return model.TerminalStates[d.DependsOnState] || model.StoppingStates[d.DependsOnState]
+	}
+}
+
+// Add adds the dependencies of an experiment to the DB.
+func Add(ctx context.Context, deps []Dependency) error {
+	if len(deps) == 0 {
+		return nil
+	}
+	_, err := db.Bun().NewInsert().Model(&deps).Exec(ctx)
+	return db.MatchSentinelError(err)
+}
+
+// Of returns the dependencies of an experiment, in the order of the experiments it depends on.
+func Of(ctx context.Context, experimentID int) ([]Dependency, error) {
+	return list(ctx, "d.experiment_id = ?", experimentID)
+}
+
+// Dependents returns the dependencies on an experiment, in the order of the experiments that
+// depend on it.
+func Dependents(ctx context.Context, experimentID int) ([]Dependency, error) {
+	return list(ctx, "d.depends_on_id = ?", experimentID)
+}
+
+func list(ctx context.Context, where string, experimentID int) ([]Dependency, error) {
+	deps := []Dependency{}
+	if err := db.Bun().NewSelect().Model(&deps).
+		ColumnExpr("d.*").
+		ColumnExpr("e.state AS experiment_state").
+		ColumnExpr("o.state AS depends_on_state").
+		Join("JOIN experiments e ON e.id = d.experiment_id").
+		Join("JOIN experiments o ON o.id = d.depends_on_id").
+		Where(where, experimentID).
+		Order("d.experiment_id", "d.depends_on_id").
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+// Waiting returns whether the experiment has dependencies that have not been met yet.
+func Waiting(ctx context.Context, experimentID int) (bool, error) {
+	return db.Bun().NewSelect().Model((*Dependency)(nil)).
+		Where("experiment_id = ?", experimentID).
+		Where("NOT met").
+		Exists(ctx)
+}
+
+// MarkMet records that every dependency of the experiment has been met.
+func MarkMet(ctx context.Context, experimentID int) error {
+	_, err := db.Bun().NewUpdate().Model((*Dependency)(nil)).
+		Set("met = true").
+		Where("experiment_id = ?", experimentID).
+		Exec(ctx)
+	return err
+}
+
+// Check returns whether an experiment with the given dependencies is ready to start, because
+// every dependency that has not been met has completed, or otherwise the first dependency that
+// has failed, if any. An experiment whose dependencies have all been met is neither.
+func Check(deps []Dependency) (ready bool, failed *Dependency) {
+	waiting := false
+	for i, d := range deps {
+		switch {
+		case d.Met:
+		case d.Failed():
+			return false, &deps[i]
+		case d.DependsOnState == model.CompletedState:
+			ready = true
+		default:
+			waiting = true
+		}
+	}
+	return ready && !waiting, nil
+}
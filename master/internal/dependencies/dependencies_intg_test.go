@@ -0,0 +1,80 @@
+//go:build integration
+// +build integration
+
+package dependencies
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestDependencies(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(db.RootFromDB))
+	pgDB := db.MustResolveTestPostgres(t)
+	db.MustMigrateTestPostgres(t, pgDB, db.MigrationsFromDB)
+	ctx := context.Background()
+
+	user := db.RequireMockUser(t, pgDB)
+	first := db.RequireMockExperiment(t, pgDB, user)
+	second := db.RequireMockExperiment(t, pgDB, user)
+	dependent := db.RequireMockExperiment(t, pgDB, user)
+
+	require.NoError(t, Add(ctx, nil))
+	require.NoError(t, Add(ctx, []Dependency{
+		{ExperimentID: dependent.ID, DependsOnID: first.ID, InheritCheckpoint: true},
+		{ExperimentID: dependent.ID, DependsOnID: second.ID},
+	}))
+	require.ErrorIs(t, Add(ctx, []Dependency{
+		{ExperimentID: dependent.ID, DependsOnID: first.ID},
+	}), db.ErrDuplicateRecord)
+
+	deps, err := Of(ctx, dependent.ID)
+	require.NoError(t, err)
+	require.Len(t, deps, 2)
+	require.Equal(t, first.ID, deps[0].DependsOnID)
+	require.True(t, deps[0].InheritCheckpoint)
+	require.Equal(t, model.ActiveState, deps[0].DependsOnState)
+	require.Equal(t, second.ID, deps[1].DependsOnID)
+	require.False(t, deps[1].InheritCheckpoint)
+
+	dependents, err := Dependents(ctx, second.ID)
+	require.NoError(t, err)
+	require.Len(t, dependents, 1)
+	require.Equal(t, dependent.ID, dependents[0].ExperimentID)
+	require.Equal(t, dependent.State, dependents[0].ExperimentState)
+
+	waiting, err := Waiting(ctx, dependent.ID)
+	require.NoError(t, err)
+	require.True(t, waiting)
+	waiting, err = Waiting(ctx, first.ID)
+	require.NoError(t, err)
+	require.False(t, waiting)
+
+	ready, failed := Check(deps)
+	require.False(t, ready)
+	require.Nil(t, failed)
+	for _, e := range []*model.Experiment{first, second} {
+		e.State = model.CompletedState
+		require.NoError(t, pgDB.SaveExperimentState(e))
+	}
+	deps, err = Of(ctx, dependent.ID)
+	require.NoError(t, err)
+	ready, failed = Check(deps)
+	require.True(t, ready)
+	require.Nil(t, failed)
+
+	require.NoError(t, MarkMet(ctx, dependent.ID))
+	waiting, err = Waiting(ctx, dependent.ID)
+	require.NoError(t, err)
+	require.False(t, waiting)
+	deps, err = Of(ctx, dependent.ID)
+	require.NoError(t, err)
+	ready, _ = Check(deps)
+	require.False(t, ready)
+}
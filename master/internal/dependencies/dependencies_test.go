@@ -0,0 +1,62 @@
+package dependencies
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestCheck(t *testing.T) {
+	dep := func(id int, state model.State, met bool) Dependency {
+		return Dependency{ExperimentID: 10, DependsOnID: id, DependsOnState: state, Met: met}
+	}
+	cases := []struct {
+		name   string
+		deps   []Dependency
+		ready  bool
+		failed int
+	}{
+		{"no dependencies", nil, false, 0},
+		{"all completed", []Dependency{
+			dep(1, model.CompletedState, false),
+			dep(2, model.CompletedState, false),
+		}, true, 0},
+		{"one still active", []Dependency{
+			dep(1, model.CompletedState, false),
+			dep(2, model.ActiveState, false),
+		}, false, 0},
+		{"one completing", []Dependency{
+			dep(1, model.StoppingCompletedState, false),
+		}, false, 0},
+		{"already met", []Dependency{
+			dep(1, model.CompletedState, true),
+			dep(2, model.CompletedState, true),
+		}, false, 0},
+		{"canceled", []Dependency{
+			dep(1, model.PausedState, false),
+			dep(2, model.CanceledState, false),
+		}, false, 2},
+		{"erroring", []Dependency{
+			dep(1, model.StoppingErrorState, false),
+		}, false, 1},
+		{"deleted", []Dependency{
+			dep(1, model.DeletingState, false),
+		}, false, 1},
+		{"failed after met", []Dependency{
+			dep(1, model.DeletingState, true),
+		}, false, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ready, failed := Check(tc.deps)
+			require.Equal(t, tc.ready, ready)
+			if tc.failed == 0 {
+				require.Nil(t, failed)
+			} else {
+				require.Equal(t, tc.failed, failed.DependsOnID)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// Package drain tracks whether the master is shutting down, so that it can keep answering requests
+// while refusing the ones that would start new work.
+package drain
+
+import (
+	"context"
+	"regexp"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// starting matches the methods that start new experiments, trials, and tasks.
+var starting = regexp.MustCompile(`^/determined\.api\.v1\.Determined/(` +
+	`CreateExperiment|ActivateExperiments?|ForkTrial|CreateSlotReservation|` +
+	`Launch(Command|Notebook|Shell|Tensorboard|VSCode))$`)
+
+var draining int32
+
+// Start marks the master as shutting down. It cannot be undone, since the master exits soon after.
+func Start() {
+	atomic.StoreInt32(&draining, 1)
+}
+
+// Draining returns whether the master is shutting down.
+func Draining() bool {
+	return atomic.LoadInt32(&draining) == 1
+}
+
+// UnaryServerInterceptor rejects calls that would start new work while the master is shutting
+// down, so that clients retry them against the restarted master.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if Draining() && starting.MatchString(info.FullMethod) {
+			return nil, status.Error(codes.Unavailable,
+				"the master is shutting down; try again once it has restarted")
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,40 @@
+package drain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	call := func(method string) error {
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{
+			FullMethod: "/determined.api.v1.Determined/" + method,
+		}, func(context.Context, interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		return err
+	}
+
+	require.False(t, Draining())
+	require.NoError(t, call("CreateExperiment"))
+
+	Start()
+	defer func() { draining = 0 }()
+	require.True(t, Draining())
+	for _, method := range []string{
+		"CreateExperiment", "ActivateExperiment", "ActivateExperiments", "LaunchNotebook",
+	} {
+		require.Equal(t, codes.Unavailable, status.Code(call(method)), method)
+	}
+	for _, method := range []string{
+		"GetExperiments", "PauseExperiment", "KillExperiment", "PostExperimentSchedule",
+	} {
+		require.NoError(t, call(method), method)
+	}
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+)
+
+// earlyStoppingReason returns why a trial should be stopped under the policy, given the values of
+// the policy's metric from each of its validations in order, or "" if it should keep going.
+func earlyStoppingReason(p expconf.EarlyStoppingPolicy, values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	last := values[len(values)-1]
+	if p.StopOnNaN && (math.IsNaN(last) || math.IsInf(last, 0)) {
+		return fmt.Sprintf("validation metric %s is %v", p.Metric, last)
+	}
+
+	if p.Patience == nil || len(values) <= *p.Patience {
+		return ""
+	}
+	// The trial is stopped if no validation in the last patience validations beats the best one
+	// before them by more than the delta. NaN values never count as the best or as improvements.
+	window := len(values) - *p.Patience
+	best := math.NaN()
+	for _, v := range values[:window] {
+		if math.IsNaN(best) || (p.SmallerIsBetter && v < best) || (!p.SmallerIsBetter && v > best) {
+			best = v
+		}
+	}
+	if math.IsNaN(best) {
+		return ""
+	}
+	for _, v := range values[window:] {
+		if (p.SmallerIsBetter && v < best-p.MinDelta) || (!p.SmallerIsBetter && v > best+p.MinDelta) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("validation metric %s has not improved on %v in %d validations",
+		p.Metric, best, *p.Patience)
+}
+
+// checkEarlyStopping evaluates the early stopping rules of the trial's experiment against its
+// validations and, if they say to, stops the trial as if it had been canceled, which the searcher
+// handles like any other trial that exited early.
+func (m *Master) checkEarlyStopping(ctx context.Context, trialID int) error {
+	experimentID, requestID, err := m.db.TrialExperimentAndRequestID(trialID)
+	if err != nil {
+		return err
+	}
+	config, err := m.db.ExperimentConfig(experimentID)
+	if err != nil {
+		return err
+	}
+	policy := config.EarlyStoppingPolicy()
+	if policy == nil {
+		return nil
+	}
+
+	values, err := m.db.ValidationMetricSeries(ctx, trialID, policy.Metric)
+	if err != nil {
+		return err
+	}
+	reason := earlyStoppingReason(*policy, values)
+	if reason == "" {
+		return nil
+	}
+	m.system.TellAt(actor.Addr("experiments", experimentID, requestID), model.StateWithReason{
+		State:               model.StoppingCanceledState,
+		InformationalReason: "early stopping: " + reason,
+	})
+	return nil
+}
@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+)
+
+func TestEarlyStoppingReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy expconf.EarlyStoppingPolicy
+		values []float64
+		stop   bool
+	}{
+		{
+			name:   "no validations",
+			policy: expconf.EarlyStoppingPolicy{Patience: ptrs.Ptr(1), StopOnNaN: true},
+		},
+		{
+			name:   "NaN",
+			policy: expconf.EarlyStoppingPolicy{StopOnNaN: true},
+			values: []float64{0.5, math.NaN()},
+			stop:   true,
+		},
+		{
+			name:   "infinite",
+			policy: expconf.EarlyStoppingPolicy{StopOnNaN: true},
+			values: []float64{math.Inf(1)},
+			stop:   true,
+		},
+		{
+			name:   "NaN allowed",
+			policy: expconf.EarlyStoppingPolicy{},
+			values: []float64{math.NaN()},
+		},
+		{
+			name:   "too few validations",
+			policy: expconf.EarlyStoppingPolicy{SmallerIsBetter: true, Patience: ptrs.Ptr(2)},
+			values: []float64{0.5, 0.6},
+		},
+		{
+			name:   "improved",
+			policy: expconf.EarlyStoppingPolicy{SmallerIsBetter: true, Patience: ptrs.Ptr(2)},
+			values: []float64{0.5, 0.6, 0.4},
+		},
+		{
+			name:   "not improved",
+			policy: expconf.EarlyStoppingPolicy{SmallerIsBetter: true, Patience: ptrs.Ptr(2)},
+			values: []float64{0.5, 0.4, 0.6, 0.4},
+			stop:   true,
+		},
+		{
+			name: "improved by less than the delta",
+			policy: expconf.EarlyStoppingPolicy{
+				SmallerIsBetter: true, Patience: ptrs.Ptr(1), MinDelta: 0.1,
+			},
+			values: []float64{0.5, 0.45},
+			stop:   true,
+		},
+		{
+			name:   "larger is better",
+			policy: expconf.EarlyStoppingPolicy{Patience: ptrs.Ptr(1)},
+			values: []float64{0.5, 0.4},
+			stop:   true,
+		},
+		{
+			name:   "NaN does not improve",
+			policy: expconf.EarlyStoppingPolicy{SmallerIsBetter: true, Patience: ptrs.Ptr(1)},
+			values: []float64{math.NaN(), 0.5, math.NaN()},
+			stop:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := earlyStoppingReason(tt.policy, tt.values)
+			require.Equal(t, tt.stop, reason != "", reason)
+		})
+	}
+}
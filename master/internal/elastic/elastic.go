@@ -1,6 +1,7 @@
 package elastic
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
@@ -73,6 +74,19 @@ func Setup(conf model.ElasticLoggingConfig) (*Elastic, error) {
 	}
 }
 
+// Ping returns an error if the elasticsearch cluster cannot be reached.
+func (e *Elastic) Ping(ctx context.Context) error {
+	res, err := e.client.Ping(e.client.Ping.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return errors.Errorf("elasticsearch returned %s", res.Status())
+	}
+	return nil
+}
+
 func elasticTLSConfig(conf model.TLSClientConfig) (*tls.Config, error) {
 	if !conf.Enabled {
 		return nil, nil
@@ -92,6 +92,10 @@ func (e *Elastic) TaskLogs(
 	taskID model.TaskID, limit int, fs []api.Filter, order apiv1.OrderBy,
 	searchAfter interface{},
 ) ([]*model.TaskLog, interface{}, error) {
+	if _, ok := searchAfter.(*api.Cursor); ok {
+		return nil, nil, errors.Wrap(api.ErrNotImplemented,
+			"cursors are not supported for logs stored in Elasticsearch")
+	}
 	if limit > elasticMaxQuerySize {
 		limit = elasticMaxQuerySize
 	}
@@ -298,6 +302,51 @@ func (e *Elastic) MaxTerminationDelay() time.Duration {
 	return ElasticTimeWindowDelay + time.Second
 }
 
+// ValidateTaskLogsRegex implements task.LogBackend by having Elasticsearch validate the query
+// that log searches use, since it has its own syntax for regular expressions.
+func (e *Elastic) ValidateTaskLogsRegex(pattern string) error {
+	query := jsonObj{"query": filtersToElastic([]api.Filter{{
+		Field:     "log",
+		Operation: api.FilterOperationRegexMatch,
+		Values:    pattern,
+	}})[0]}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(query); err != nil {
+		return errors.Wrap(err, "failed to encode query")
+	}
+
+	res, err := e.client.Indices.ValidateQuery(
+		e.client.Indices.ValidateQuery.WithBody(&buf),
+		e.client.Indices.ValidateQuery.WithExplain(true),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to validate query")
+	}
+	defer closeWithErrCheck(res.Body)
+	if err = checkResponse(res); err != nil {
+		return errors.Wrap(err, "failed to validate query")
+	}
+
+	resp := struct {
+		Valid        bool `json:"valid"`
+		Explanations []struct {
+			Error string `json:"error"`
+		} `json:"explanations"`
+	}{}
+	if err = json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return errors.New("failed to decode validate query api response")
+	}
+	if !resp.Valid {
+		for _, x := range resp.Explanations {
+			if x.Error != "" {
+				return api.AsValidationError("%s", x.Error)
+			}
+		}
+		return api.AsValidationError("invalid regular expression")
+	}
+	return nil
+}
+
 // search runs the search request with query as its body and populates the result into resp.
 func (e *Elastic) search(query jsonObj, resp interface{}) error {
 	var buf bytes.Buffer
@@ -421,6 +470,17 @@ func filtersToElastic(fs []api.Filter) []jsonObj {
 						},
 					},
 				})
+		case api.FilterOperationRegexMatch:
+			// Elasticsearch anchors regular expressions to the whole term.
+			terms = append(terms,
+				jsonObj{
+					"regexp": jsonObj{
+						f.Field: jsonObj{
+							"value":            fmt.Sprintf(".*(%s).*", f.Values),
+							"case_insensitive": true,
+						},
+					},
+				})
 
 		default:
 			panic(fmt.Sprintf("unsupported filter operation: %d", f.Operation))
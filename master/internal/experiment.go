@@ -21,8 +21,11 @@ import (
 	"github.com/determined-ai/determined/master/internal/user"
 
 	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/dependencies"
 	"github.com/determined-ai/determined/master/internal/hpimportance"
+	"github.com/determined-ai/determined/master/internal/schedules"
 	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/internal/stream"
 	"github.com/determined-ai/determined/master/internal/telemetry"
 	"github.com/determined-ai/determined/master/internal/webhooks"
 	"github.com/determined-ai/determined/master/pkg/actor"
@@ -79,6 +82,20 @@ type (
 	UnwatchEvents struct {
 		id uuid.UUID
 	}
+
+	// checkDependencies asks the experiment to start if the experiments it depends on have all
+	// completed, or to cancel if one of them will not. It is sent when one of them stops.
+	checkDependencies struct{}
+
+	// persistSnapshot asks the experiment to save a snapshot of its state, such as before the
+	// master shuts down.
+	persistSnapshot struct{}
+
+	// trialWarmStart replaces the checkpoint that a trial which has not started yet warm starts
+	// from.
+	trialWarmStart struct {
+		checkpoint *model.Checkpoint
+	}
 )
 
 type (
@@ -147,6 +164,11 @@ func newExperiment(m *Master, expModel *model.Experiment, taskSpec *tasks.TaskSp
 	}
 
 	if expModel.ID == 0 {
+		if id := conf.Searcher().WarmStartExperimentID(); id != nil {
+			if err = warmStartSearcher(search, *id, conf.Searcher().WarmStartTrials()); err != nil {
+				return nil, err
+			}
+		}
 		if err = m.db.AddExperiment(expModel); err != nil {
 			return nil, err
 		}
@@ -160,6 +182,12 @@ func newExperiment(m *Master, expModel *model.Experiment, taskSpec *tasks.TaskSp
 
 	taskSpec.AgentUserGroup = agentUserGroup
 
+	workspaceID, err := db.ProjectWorkspaceID(context.TODO(), expModel.ProjectID)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the experiment's workspace")
+	}
+	taskSpec.WorkspaceID = &workspaceID
+
 	return &experiment{
 		Experiment:          expModel,
 		taskLogger:          m.taskLogger,
@@ -189,6 +217,9 @@ func (e *experiment) Receive(ctx *actor.Context) error {
 	// Searcher-related messages.
 	case actor.PreStart:
 		ctx.AddLabels(e.logCtx)
+		// Handled once the experiment has started, so that it catches up on any dependencies that
+		// stopped while it was being created or the master was down.
+		ctx.Tell(ctx.Self(), checkDependencies{})
 		e.rm.SetGroupMaxSlots(ctx, sproto.SetGroupMaxSlots{
 			MaxSlots: e.Config.Resources().MaxSlots(),
 			Handler:  ctx.Self(),
@@ -377,11 +408,18 @@ func (e *experiment) Receive(ctx *actor.Context) error {
 		if err := webhooks.ReportExperimentStateChanged(context.TODO(), *e.Experiment); err != nil {
 			log.WithError(err).Error("failed to send experiment state change webhook")
 		}
+		if e.State == model.ErrorState {
+			if err := schedules.ReportExperimentFailed(context.TODO(), *e.Experiment); err != nil {
+				log.WithError(err).Error("failed to report failed scheduled run")
+			}
+		}
+		stream.ExperimentStateChanged(*e.Experiment)
 
 		if err := e.db.SaveExperimentState(e.Experiment); err != nil {
 			return err
 		}
 		ctx.Log().Infof("experiment state changed to %s", e.State)
+		e.notifyDependents(ctx)
 		addr := actor.Addr(fmt.Sprintf("experiment-%d-checkpoint-gc", e.ID))
 
 		checkpoints, err := e.db.ExperimentCheckpointsToGCRaw(
@@ -486,6 +524,12 @@ func (e *experiment) Receive(ctx *actor.Context) error {
 			}
 		}
 
+	case checkDependencies:
+		e.checkDependencies(ctx)
+
+	case persistSnapshot:
+		e.snapshotAndSave(ctx)
+
 	case UnwatchEvents:
 		if queue, err := e.searcher.GetCustomSearcherEventQueue(); err != nil {
 			ctx.Respond(status.Error(codes.Internal, err.Error()))
@@ -563,6 +607,7 @@ func (e *experiment) Receive(ctx *actor.Context) error {
 func (e *experiment) trialClosed(ctx *actor.Context, requestID model.RequestID) {
 	ops, err := e.searcher.TrialClosed(requestID)
 	e.processOperations(ctx, ops, err)
+	ctx.Tell(e.hpImportance, hpimportance.TrialClosed{ExperimentID: e.ID})
 	if e.canTerminate(ctx) {
 		ctx.Self().Stop()
 	}
@@ -607,6 +652,9 @@ func (e *experiment) processOperations(
 		ctx.Log().Debugf("handling searcher op: %v", operation)
 		switch op := operation.(type) {
 		case searcher.Create:
+			if seed := e.Config.Reproducibility().TrialSeed(); seed != nil {
+				op.TrialSeed = *seed
+			}
 			checkpoint, err := e.checkpointForCreate(op)
 			if err != nil {
 				e.updateState(ctx, model.StateWithReason{
@@ -690,6 +738,91 @@ func (e *experiment) checkpointForCreate(op searcher.Create) (*model.Checkpoint,
 	return checkpoint, nil
 }
 
+// checkDependencies activates the experiment once the experiments it is waiting for have all
+// completed, warm starting it from the best checkpoint of the one it inherits from, or cancels it
+// if one of them stopped without completing.
+func (e *experiment) checkDependencies(ctx *actor.Context) {
+	if e.State != model.PausedState {
+		return
+	}
+	deps, err := dependencies.Of(context.TODO(), e.ID)
+	if err != nil {
+		ctx.Log().WithError(err).Error("failed to check experiment dependencies")
+		return
+	}
+	switch ready, failed := dependencies.Check(deps); {
+	case failed != nil:
+		e.updateState(ctx, model.StateWithReason{
+			State: model.StoppingCanceledState,
+			InformationalReason: fmt.Sprintf("dependency experiment %d ended in state %s",
+				failed.DependsOnID, failed.DependsOnState),
+		})
+	case ready:
+		for _, d := range deps {
+			if !d.InheritCheckpoint {
+				continue
+			}
+			if err := e.inheritCheckpoint(ctx, d.DependsOnID); err != nil {
+				e.updateState(ctx, model.StateWithReason{
+					State:               model.StoppingErrorState,
+					InformationalReason: err.Error(),
+				})
+				return
+			}
+		}
+		if err := dependencies.MarkMet(context.TODO(), e.ID); err != nil {
+			ctx.Log().WithError(err).Error("failed to save met experiment dependencies")
+			return
+		}
+		e.updateState(ctx, model.StateWithReason{
+			State:               model.ActiveState,
+			InformationalReason: "dependencies completed",
+		})
+	}
+}
+
+// inheritCheckpoint makes the experiment and its trials that have not started yet warm start from
+// the best checkpoint of another experiment.
+func (e *experiment) inheritCheckpoint(ctx *actor.Context, experimentID int) error {
+	config, err := e.db.ExperimentConfig(experimentID)
+	if err != nil {
+		return errors.Wrapf(err, "getting the config of dependency experiment %d", experimentID)
+	}
+	checkpoint, err := e.db.ExperimentBestCheckpoint(
+		experimentID, config.Searcher().SmallerIsBetter())
+	if err != nil {
+		return err
+	} else if checkpoint == nil {
+		return errors.Errorf("dependency experiment %d has no checkpoint to inherit", experimentID)
+	}
+
+	searcherConfig := e.Config.Searcher()
+	searcherConfig.RawSourceTrialID = nil
+	searcherConfig.RawSourceCheckpointUUID = ptrs.Ptr(checkpoint.UUID.String())
+	e.Config.SetSearcher(searcherConfig)
+	if err := e.db.SaveExperimentConfig(e.Experiment); err != nil {
+		return errors.Wrap(err, "saving the inherited checkpoint")
+	}
+	e.warmStartCheckpoint = checkpoint
+	ctx.TellAll(trialWarmStart{checkpoint: checkpoint}, ctx.Children()...)
+	return nil
+}
+
+// notifyDependents asks the experiments waiting for the experiment to check their dependencies
+// now that it has stopped.
+func (e *experiment) notifyDependents(ctx *actor.Context) {
+	dependents, err := dependencies.Dependents(context.TODO(), e.ID)
+	if err != nil {
+		ctx.Log().WithError(err).Error("failed to get dependent experiments")
+		return
+	}
+	for _, d := range dependents {
+		if !d.Met {
+			ctx.Self().System().TellAt(experimentsAddr.Child(d.ExperimentID), checkDependencies{})
+		}
+	}
+}
+
 func (e *experiment) updateState(ctx *actor.Context, state model.StateWithReason) bool {
 	if wasPatched, err := e.Transition(state.State); err != nil {
 		ctx.Log().Errorf("error transitioning experiment state: %s", err)
@@ -701,6 +834,7 @@ func (e *experiment) updateState(ctx *actor.Context, state model.StateWithReason
 	if err := webhooks.ReportExperimentStateChanged(context.TODO(), *e.Experiment); err != nil {
 		log.WithError(err).Error("failed to send experiment state change webhook")
 	}
+	stream.ExperimentStateChanged(*e.Experiment)
 
 	ctx.Log().Infof("experiment state changed to %s", state.State)
 	ctx.TellAll(state, ctx.Children()...)
@@ -769,6 +903,23 @@ func checkpointFromTrialIDOrUUID(
 	return checkpoint, nil
 }
 
+// warmStartSearcher seeds a new searcher with the hyperparameters of the best trials of the
+// experiment it warm starts from; restored searchers keep the seeds left in their snapshot.
+func warmStartSearcher(s *searcher.Searcher, experimentID int, trials *int) error {
+	observations, err := db.ExperimentSearcherObservations(context.TODO(), experimentID)
+	if err != nil {
+		return err
+	}
+	if trials != nil && *trials < len(observations) {
+		observations = observations[:*trials]
+	}
+	hparams := make([]searcher.HParamSample, 0, len(observations))
+	for _, o := range observations {
+		hparams = append(hparams, searcher.HParamSample(o.HParams))
+	}
+	return errors.Wrapf(s.WarmStart(hparams), "warm starting from experiment %d", experimentID)
+}
+
 func (e *experiment) setPriority(ctx *actor.Context, priority *int, forward bool) (err error) {
 	if priority == nil {
 		return nil
@@ -0,0 +1,146 @@
+package experiment
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+
+	"github.com/determined-ai/determined/master/internal/workspace"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/projectv1"
+)
+
+// ExperimentAuthZRoles controls access to experiments by the roles of users in their projects:
+// viewers can see experiments, editors can change them, and admins can also delete other users'
+// experiments.
+type ExperimentAuthZRoles struct{}
+
+// CanGetExperiment returns whether the user is a viewer of the experiment's project.
+func (a *ExperimentAuthZRoles) CanGetExperiment(
+	ctx context.Context, curUser model.User, e *model.Experiment,
+) (canGetExp bool, serverError error) {
+	role, err := workspace.ProjectRole(ctx, curUser, e.ProjectID)
+	if err != nil {
+		return false, err
+	}
+	return role.AtLeast(model.MemberRoleViewer), nil
+}
+
+// CanGetExperimentArtifacts requires the user to be a viewer of the experiment's project.
+func (a *ExperimentAuthZRoles) CanGetExperimentArtifacts(
+	ctx context.Context, curUser model.User, e *model.Experiment,
+) error {
+	return workspace.RequireProjectRole(ctx, curUser, e.ProjectID, model.MemberRoleViewer)
+}
+
+// CanDeleteExperiment requires the user to be an admin of the experiment's project, or an editor
+// of it who owns the experiment.
+func (a *ExperimentAuthZRoles) CanDeleteExperiment(
+	ctx context.Context, curUser model.User, e *model.Experiment,
+) error {
+	role, err := workspace.ProjectRole(ctx, curUser, e.ProjectID)
+	if err != nil {
+		return err
+	}
+	curUserIsOwner := e.OwnerID != nil && *e.OwnerID == curUser.ID
+	if !role.AtLeast(model.MemberRoleAdmin) &&
+		!(curUserIsOwner && role.AtLeast(model.MemberRoleEditor)) {
+		return fmt.Errorf("only admins of a project may delete other user's experiments in it")
+	}
+	return nil
+}
+
+// FilterExperimentsQuery limits the query to experiments in projects the user has a role in.
+// A project that is given has already been checked. The query must select experiments as e.
+func (a *ExperimentAuthZRoles) FilterExperimentsQuery(
+	ctx context.Context, curUser model.User, proj *projectv1.Project, query *bun.SelectQuery,
+) (*bun.SelectQuery, error) {
+	if proj != nil || curUser.Admin {
+		return query, nil
+	}
+	return query.Where("e.project_id IN (?)", workspace.VisibleProjects(curUser)), nil
+}
+
+// FilterExperimentLabelsQuery limits the query to experiments in projects the user has a role
+// in. A project that is given has already been checked.
+func (a *ExperimentAuthZRoles) FilterExperimentLabelsQuery(
+	ctx context.Context, curUser model.User, proj *projectv1.Project, query *bun.SelectQuery,
+) (*bun.SelectQuery, error) {
+	return a.FilterExperimentsQuery(ctx, curUser, proj, query)
+}
+
+// CanPreviewHPSearch always returns a nil error.
+func (a *ExperimentAuthZRoles) CanPreviewHPSearch(
+	ctx context.Context, curUser model.User,
+) error {
+	return nil
+}
+
+// CanEditExperiment requires the user to be an editor of the experiment's project.
+func (a *ExperimentAuthZRoles) CanEditExperiment(
+	ctx context.Context, curUser model.User, e *model.Experiment,
+) error {
+	return workspace.RequireProjectRole(ctx, curUser, e.ProjectID, model.MemberRoleEditor)
+}
+
+// CanEditExperimentsMetadata requires the user to be an editor of the experiment's project.
+func (a *ExperimentAuthZRoles) CanEditExperimentsMetadata(
+	ctx context.Context, curUser model.User, e *model.Experiment,
+) error {
+	return workspace.RequireProjectRole(ctx, curUser, e.ProjectID, model.MemberRoleEditor)
+}
+
+// CanCreateExperiment requires the user to be an editor of the project.
+func (a *ExperimentAuthZRoles) CanCreateExperiment(
+	ctx context.Context, curUser model.User, proj *projectv1.Project, e *model.Experiment,
+) error {
+	return workspace.RequireProjectRole(ctx, curUser, int(proj.Id), model.MemberRoleEditor)
+}
+
+// CanForkFromExperiment requires the user to be a viewer of the experiment's project.
+func (a *ExperimentAuthZRoles) CanForkFromExperiment(
+	ctx context.Context, curUser model.User, e *model.Experiment,
+) error {
+	return workspace.RequireProjectRole(ctx, curUser, e.ProjectID, model.MemberRoleViewer)
+}
+
+// CanSetExperimentsMaxSlots requires the user to be an editor of the experiment's project.
+func (a *ExperimentAuthZRoles) CanSetExperimentsMaxSlots(
+	ctx context.Context, curUser model.User, e *model.Experiment, slots int,
+) error {
+	return workspace.RequireProjectRole(ctx, curUser, e.ProjectID, model.MemberRoleEditor)
+}
+
+// CanSetExperimentsWeight requires the user to be an editor of the experiment's project.
+func (a *ExperimentAuthZRoles) CanSetExperimentsWeight(
+	ctx context.Context, curUser model.User, e *model.Experiment, weight float64,
+) error {
+	return workspace.RequireProjectRole(ctx, curUser, e.ProjectID, model.MemberRoleEditor)
+}
+
+// CanSetExperimentsPriority requires the user to be an editor of the experiment's project.
+func (a *ExperimentAuthZRoles) CanSetExperimentsPriority(
+	ctx context.Context, curUser model.User, e *model.Experiment, priority int,
+) error {
+	return workspace.RequireProjectRole(ctx, curUser, e.ProjectID, model.MemberRoleEditor)
+}
+
+// CanSetExperimentsCheckpointGCPolicy requires the user to be an editor of the experiment's
+// project.
+func (a *ExperimentAuthZRoles) CanSetExperimentsCheckpointGCPolicy(
+	ctx context.Context, curUser model.User, e *model.Experiment,
+) error {
+	return workspace.RequireProjectRole(ctx, curUser, e.ProjectID, model.MemberRoleEditor)
+}
+
+// CanRunCustomSearch requires the user to be an editor of the experiment's project.
+func (a *ExperimentAuthZRoles) CanRunCustomSearch(
+	ctx context.Context, curUser model.User, e *model.Experiment,
+) error {
+	return workspace.RequireProjectRole(ctx, curUser, e.ProjectID, model.MemberRoleEditor)
+}
+
+func init() {
+	AuthZProvider.Register("roles", &ExperimentAuthZRoles{})
+}
@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+// progressWindow is how far back the rate at which an experiment trains is measured.
+const progressWindow = time.Hour
+
+// estimateExperimentProgress estimates when an experiment will finish from the fraction of the
+// budget of its searcher that it has used, the batches its trials have trained, and how many of
+// them were trained in the last window. The batches left are extrapolated from the batches
+// trained so far, assuming that the budget is used in proportion to them.
+func estimateExperimentProgress(
+	progress float64, total, recent int64, window time.Duration, now time.Time,
+) *apiv1.ExperimentProgress {
+	p := &apiv1.ExperimentProgress{Progress: progress, BatchesTrained: total}
+	if window > 0 {
+		p.BatchesPerSecond = float64(recent) / window.Seconds()
+	}
+	if progress <= 0 || progress >= 1 || p.BatchesPerSecond <= 0 {
+		return p
+	}
+
+	remaining := float64(total) * (1 - progress) / progress
+	seconds := remaining / p.BatchesPerSecond
+	p.EstimatedEndTime = timestamppb.New(now.Add(time.Duration(seconds * float64(time.Second))))
+	return p
+}
@@ -0,0 +1,23 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateExperimentProgress(t *testing.T) {
+	now := time.Date(2023, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	// A quarter of the budget took 3000 batches, so 9000 are left, at 1000 batches an hour.
+	p := estimateExperimentProgress(0.25, 3000, 1000, time.Hour, now)
+	require.Equal(t, int64(3000), p.BatchesTrained)
+	require.InDelta(t, 1000.0/3600, p.BatchesPerSecond, 1e-9)
+	require.Equal(t, now.Add(9*time.Hour), p.EstimatedEndTime.AsTime())
+
+	// Without progress or a recent rate, there is nothing to extrapolate from.
+	require.Nil(t, estimateExperimentProgress(0, 3000, 1000, time.Hour, now).EstimatedEndTime)
+	require.Nil(t, estimateExperimentProgress(0.25, 3000, 0, time.Hour, now).EstimatedEndTime)
+	require.Nil(t, estimateExperimentProgress(0.25, 3000, 1000, 0, now).EstimatedEndTime)
+}
@@ -0,0 +1,297 @@
+// Package experimentcrd submits the experiments described by Experiment custom resources in a
+// Kubernetes namespace and writes their progress back to the status of the resources, so that
+// experiments can be managed with kubectl and GitOps tools.
+package experimentcrd
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	k8sClient "k8s.io/client-go/kubernetes"
+	typedV1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/determined-ai/determined/master/pkg/archive"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+const (
+	// syncPeriod is how often the controller looks for new, changed and deleted resources.
+	syncPeriod = 15 * time.Second
+
+	// finalizer keeps a resource around until the controller has killed its experiment.
+	finalizer = "determined.ai/experiment"
+
+	modelDefinitionFileMode = 0o644
+)
+
+// Resource identifies Experiment custom resources.
+var Resource = schema.GroupVersionResource{
+	Group:    "determined.ai",
+	Version:  "v1",
+	Resource: "experiments",
+}
+
+// Spec is the spec of an Experiment resource.
+type Spec struct {
+	// Config is the experiment configuration.
+	Config map[string]interface{} `json:"config"`
+	// ProjectID is the project to submit the experiment to, if not the default one.
+	ProjectID *int `json:"projectId,omitempty"`
+	// Template is the name of a config template to apply, if any.
+	Template *string `json:"template,omitempty"`
+	// ModelDefinition is where the code of the experiment comes from, if not its image.
+	ModelDefinition *ModelDefinitionSource `json:"modelDefinition,omitempty"`
+}
+
+// ModelDefinitionSource is where the model definition of an experiment comes from.
+type ModelDefinitionSource struct {
+	// ConfigMapName names a ConfigMap in the namespace of the resource, each key of which is a
+	// file of the model definition.
+	ConfigMapName string `json:"configMapName"`
+}
+
+// Status is the status of an Experiment resource.
+type Status struct {
+	// ExperimentID is the experiment submitted for the resource, once it is.
+	ExperimentID int `json:"experimentId,omitempty"`
+	// State is the state of the experiment.
+	State string `json:"state,omitempty"`
+	// Message explains why the experiment could not be submitted or why changes are ignored.
+	Message string `json:"message,omitempty"`
+	// ObservedGeneration is the generation of the resource the controller last acted on.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// Experiments submits and manages the experiments of Experiment resources.
+type Experiments interface {
+	Submit(ctx context.Context, spec Spec, modelDef archive.Archive) (int, error)
+	State(ctx context.Context, id int) (model.State, error)
+	Kill(ctx context.Context, id int) error
+}
+
+// Controller reconciles the Experiment resources of a namespace with their experiments.
+type Controller struct {
+	resources   dynamic.ResourceInterface
+	configMaps  typedV1.ConfigMapInterface
+	experiments Experiments
+}
+
+// New returns a controller for the Experiment resources of a namespace.
+func New(
+	client dynamic.Interface, clientSet k8sClient.Interface, namespace string, e Experiments,
+) *Controller {
+	return &Controller{
+		resources:   client.Resource(Resource).Namespace(namespace),
+		configMaps:  clientSet.CoreV1().ConfigMaps(namespace),
+		experiments: e,
+	}
+}
+
+// Loop reconciles the resources periodically until the context is canceled.
+func (c *Controller) Loop(ctx context.Context) {
+	t := time.NewTicker(syncPeriod)
+	defer t.Stop()
+	for {
+		if err := c.Reconcile(ctx); err != nil {
+			log.WithError(err).Error("failed to reconcile Experiment resources")
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Reconcile submits the experiments of new resources, kills the experiments of deleted ones and
+// updates the status of the rest. A resource that fails is logged and retried on the next pass.
+func (c *Controller) Reconcile(ctx context.Context) error {
+	list, err := c.resources.List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "listing Experiment resources")
+	}
+	// Handle resources in the order they were created, so experiments are submitted in order too.
+	sort.SliceStable(list.Items, func(i, j int) bool {
+		return list.Items[i].GetCreationTimestamp().Time.Before(
+			list.Items[j].GetCreationTimestamp().Time)
+	})
+	for i := range list.Items {
+		obj := &list.Items[i]
+		if err := c.reconcile(ctx, obj); err != nil {
+			log.WithError(err).Errorf("failed to reconcile Experiment resource %s", obj.GetName())
+		}
+	}
+	return nil
+}
+
+func (c *Controller) reconcile(ctx context.Context, obj *unstructured.Unstructured) error {
+	status, err := statusOf(obj)
+	if err != nil {
+		return err
+	}
+
+	if obj.GetDeletionTimestamp() != nil {
+		return c.finalize(ctx, obj, status)
+	}
+	if !hasFinalizer(obj) {
+		obj.SetFinalizers(append(obj.GetFinalizers(), finalizer))
+		if obj, err = c.resources.Update(ctx, obj, metaV1.UpdateOptions{}); err != nil {
+			return errors.Wrap(err, "adding finalizer")
+		}
+	}
+
+	next := *status
+	switch {
+	case status.ExperimentID == 0 && status.ObservedGeneration == obj.GetGeneration():
+		// Submitting this generation failed already; wait for the spec to change.
+		return nil
+	case status.ExperimentID == 0:
+		next.ObservedGeneration = obj.GetGeneration()
+		id, err := c.submit(ctx, obj)
+		if err != nil {
+			next.Message = err.Error()
+			break
+		}
+		next.ExperimentID = id
+		next.Message = ""
+		log.Infof("submitted experiment %d for Experiment resource %s", id, obj.GetName())
+		fallthrough
+	default:
+		state, err := c.experiments.State(ctx, next.ExperimentID)
+		if err != nil {
+			return errors.Wrapf(err, "getting state of experiment %d", next.ExperimentID)
+		}
+		next.State = string(state)
+		if obj.GetGeneration() != next.ObservedGeneration {
+			next.Message = fmt.Sprintf("changes to the spec after experiment %d was submitted "+
+				"are ignored; create a new resource to submit another experiment",
+				next.ExperimentID)
+		}
+	}
+
+	if next == *status {
+		return nil
+	}
+	return c.updateStatus(ctx, obj, next)
+}
+
+// finalize kills the experiment of a deleted resource and lets the deletion go through.
+func (c *Controller) finalize(
+	ctx context.Context, obj *unstructured.Unstructured, status *Status,
+) error {
+	if !hasFinalizer(obj) {
+		return nil
+	}
+	if status.ExperimentID != 0 {
+		state, err := c.experiments.State(ctx, status.ExperimentID)
+		if err != nil {
+			return errors.Wrapf(err, "getting state of experiment %d", status.ExperimentID)
+		}
+		if _, ok := model.TerminalStates[state]; !ok {
+			if err := c.experiments.Kill(ctx, status.ExperimentID); err != nil {
+				return errors.Wrapf(err, "killing experiment %d", status.ExperimentID)
+			}
+			log.Infof("killed experiment %d of deleted Experiment resource %s",
+				status.ExperimentID, obj.GetName())
+		}
+	}
+
+	var finalizers []string
+	for _, f := range obj.GetFinalizers() {
+		if f != finalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	obj.SetFinalizers(finalizers)
+	_, err := c.resources.Update(ctx, obj, metaV1.UpdateOptions{})
+	if k8sErrors.IsNotFound(err) {
+		return nil
+	}
+	return errors.Wrap(err, "removing finalizer")
+}
+
+func (c *Controller) submit(ctx context.Context, obj *unstructured.Unstructured) (int, error) {
+	var spec Spec
+	rawSpec, _, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid spec")
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawSpec, &spec); err != nil {
+		return 0, errors.Wrap(err, "invalid spec")
+	}
+	if spec.Config == nil {
+		return 0, errors.New("spec.config is required")
+	}
+
+	var modelDef archive.Archive
+	if src := spec.ModelDefinition; src != nil && src.ConfigMapName != "" {
+		cm, err := c.configMaps.Get(ctx, src.ConfigMapName, metaV1.GetOptions{})
+		if err != nil {
+			return 0, errors.Wrapf(err, "getting model definition ConfigMap %s", src.ConfigMapName)
+		}
+		modelDef = configMapArchive(cm.Data, cm.BinaryData)
+	}
+
+	return c.experiments.Submit(ctx, spec, modelDef)
+}
+
+func (c *Controller) updateStatus(
+	ctx context.Context, obj *unstructured.Unstructured, status Status,
+) error {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(obj.Object, raw, "status"); err != nil {
+		return err
+	}
+	_, err = c.resources.UpdateStatus(ctx, obj, metaV1.UpdateOptions{})
+	return errors.Wrap(err, "updating status")
+}
+
+func statusOf(obj *unstructured.Unstructured) (*Status, error) {
+	var status Status
+	raw, found, err := unstructured.NestedMap(obj.Object, "status")
+	if err != nil || !found {
+		return &status, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &status); err != nil {
+		return nil, errors.Wrap(err, "invalid status")
+	}
+	return &status, nil
+}
+
+func hasFinalizer(obj *unstructured.Unstructured) bool {
+	for _, f := range obj.GetFinalizers() {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// configMapArchive turns the keys of a ConfigMap into the files of a model definition.
+func configMapArchive(data map[string]string, binaryData map[string][]byte) archive.Archive {
+	var files archive.Archive
+	for name, content := range data {
+		files = append(files, archive.UserItem(
+			name, []byte(content), modelDefinitionFileMode, tar.TypeReg, 0, 0))
+	}
+	for name, content := range binaryData {
+		files = append(files, archive.UserItem(
+			name, content, modelDefinitionFileMode, tar.TypeReg, 0, 0))
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
@@ -0,0 +1,175 @@
+package experimentcrd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	k8sV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/determined-ai/determined/master/pkg/archive"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+const namespace = "default"
+
+type fakeExperiments struct {
+	submitted []Spec
+	modelDefs []archive.Archive
+	states    map[int]model.State
+	killed    []int
+	submitErr error
+}
+
+func (f *fakeExperiments) Submit(
+	ctx context.Context, spec Spec, modelDef archive.Archive,
+) (int, error) {
+	if f.submitErr != nil {
+		return 0, f.submitErr
+	}
+	f.submitted = append(f.submitted, spec)
+	f.modelDefs = append(f.modelDefs, modelDef)
+	id := len(f.submitted)
+	f.states[id] = model.ActiveState
+	return id, nil
+}
+
+func (f *fakeExperiments) State(ctx context.Context, id int) (model.State, error) {
+	return f.states[id], nil
+}
+
+func (f *fakeExperiments) Kill(ctx context.Context, id int) error {
+	f.killed = append(f.killed, id)
+	f.states[id] = model.StoppingKilledState
+	return nil
+}
+
+func experimentResource(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "determined.ai/v1",
+		"kind":       "Experiment",
+		"metadata": map[string]interface{}{
+			"name":       name,
+			"namespace":  namespace,
+			"generation": int64(1),
+		},
+		"spec": spec,
+	}}
+}
+
+func setup(
+	t *testing.T, objs ...*unstructured.Unstructured,
+) (*Controller, *fakeExperiments, *dynamicFake.FakeDynamicClient) {
+	scheme := runtime.NewScheme()
+	client := dynamicFake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{Resource: "ExperimentList"})
+	for _, obj := range objs {
+		_, err := client.Resource(Resource).Namespace(namespace).
+			Create(context.Background(), obj, metaV1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	clientSet := k8sFake.NewSimpleClientset(&k8sV1.ConfigMap{
+		ObjectMeta: metaV1.ObjectMeta{Name: "model-def", Namespace: namespace},
+		Data:       map[string]string{"model_def.py": "print('hi')"},
+		BinaryData: map[string][]byte{"data.bin": {0, 1}},
+	})
+	e := &fakeExperiments{states: map[int]model.State{}}
+	return New(client, clientSet, namespace, e), e, client
+}
+
+func get(t *testing.T, client *dynamicFake.FakeDynamicClient, name string) (
+	*unstructured.Unstructured, *Status,
+) {
+	obj, err := client.Resource(Resource).Namespace(namespace).
+		Get(context.Background(), name, metaV1.GetOptions{})
+	require.NoError(t, err)
+	status, err := statusOf(obj)
+	require.NoError(t, err)
+	return obj, status
+}
+
+func TestReconcileSubmits(t *testing.T) {
+	ctx := context.Background()
+	c, e, client := setup(t, experimentResource("exp", map[string]interface{}{
+		"config":          map[string]interface{}{"name": "mnist"},
+		"projectId":       int64(3),
+		"modelDefinition": map[string]interface{}{"configMapName": "model-def"},
+	}))
+
+	require.NoError(t, c.Reconcile(ctx))
+	require.Len(t, e.submitted, 1)
+	require.Equal(t, "mnist", e.submitted[0].Config["name"])
+	require.Equal(t, 3, *e.submitted[0].ProjectID)
+	require.Len(t, e.modelDefs[0], 2)
+	require.Equal(t, "data.bin", e.modelDefs[0][0].Path)
+	require.Equal(t, "model_def.py", e.modelDefs[0][1].Path)
+
+	obj, status := get(t, client, "exp")
+	require.Equal(t, []string{finalizer}, obj.GetFinalizers())
+	require.Equal(t, Status{
+		ExperimentID:       1,
+		State:              string(model.ActiveState),
+		ObservedGeneration: 1,
+	}, *status)
+
+	// Later passes only follow the state of the experiment.
+	e.states[1] = model.CompletedState
+	require.NoError(t, c.Reconcile(ctx))
+	require.Len(t, e.submitted, 1)
+	_, status = get(t, client, "exp")
+	require.Equal(t, string(model.CompletedState), status.State)
+
+	// Changes to the spec are not submitted again.
+	obj.SetGeneration(2)
+	_, err := client.Resource(Resource).Namespace(namespace).
+		Update(ctx, obj, metaV1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, c.Reconcile(ctx))
+	require.Len(t, e.submitted, 1)
+	_, status = get(t, client, "exp")
+	require.Contains(t, status.Message, "are ignored")
+}
+
+func TestReconcileSubmitFailure(t *testing.T) {
+	ctx := context.Background()
+	c, e, client := setup(t, experimentResource("exp", map[string]interface{}{
+		"config": map[string]interface{}{"name": "mnist"},
+	}))
+	e.submitErr = errors.New("invalid experiment")
+
+	require.NoError(t, c.Reconcile(ctx))
+	_, status := get(t, client, "exp")
+	require.Equal(t, Status{Message: "invalid experiment", ObservedGeneration: 1}, *status)
+
+	// The same generation is not retried.
+	e.submitErr = nil
+	require.NoError(t, c.Reconcile(ctx))
+	require.Empty(t, e.submitted)
+}
+
+func TestReconcileDeletion(t *testing.T) {
+	ctx := context.Background()
+	c, e, client := setup(t, experimentResource("exp", map[string]interface{}{
+		"config": map[string]interface{}{"name": "mnist"},
+	}))
+	require.NoError(t, c.Reconcile(ctx))
+
+	obj, _ := get(t, client, "exp")
+	now := metaV1.Now()
+	obj.SetDeletionTimestamp(&now)
+	_, err := client.Resource(Resource).Namespace(namespace).
+		Update(ctx, obj, metaV1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, c.Reconcile(ctx))
+	require.Equal(t, []int{1}, e.killed)
+	obj, _ = get(t, client, "exp")
+	require.Empty(t, obj.GetFinalizers())
+}
@@ -0,0 +1,332 @@
+// Package fsck finds, and optionally repairs, inconsistent state in the database of the master,
+// as can be left behind by a crash or a botched upgrade: trials still running in experiments that
+// have ended, allocations and tasks that were never closed, and checkpoints whose files are gone.
+package fsck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/objectstore"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+)
+
+// Kind is a kind of inconsistency.
+type Kind string
+
+const (
+	// StuckTrial is a trial that is not in a terminal state although its experiment is.
+	StuckTrial Kind = "stuck trial"
+	// OrphanedAllocation is an allocation that is open although its task or trial has ended.
+	OrphanedAllocation Kind = "orphaned allocation"
+	// DanglingTask is a task that is open although nothing will ever run or end it.
+	DanglingTask Kind = "dangling task"
+	// MissingCheckpoint is a completed checkpoint whose files are not in storage.
+	MissingCheckpoint Kind = "missing checkpoint"
+)
+
+// Problem is an inconsistency that was found.
+type Problem struct {
+	Kind Kind
+	// Object identifies what is inconsistent, such as "trial 12".
+	Object string
+	// Detail describes what is wrong with it.
+	Detail string
+	// Repair describes what repairing the problem does.
+	Repair   string
+	Repaired bool
+}
+
+// Options configure a check.
+type Options struct {
+	// Repair is whether to repair the problems that are found.
+	Repair bool
+	// CheckStorage is whether to look for a file of every completed checkpoint in storage, which
+	// can take a while.
+	CheckStorage bool
+}
+
+type check func(ctx context.Context, tx bun.Tx, repair bool) ([]Problem, error)
+
+// Run checks the database for inconsistencies and, if asked to, repairs them all in one
+// transaction. It must not run while a master is running against the database, since state that a
+// running master is in the middle of changing can look inconsistent.
+func Run(ctx context.Context, opts Options) ([]Problem, error) {
+	// Trials are repaired first, since ending them can leave their allocations and tasks open.
+	checks := []check{stuckTrials, orphanedAllocations, danglingTasks}
+	if opts.CheckStorage {
+		checks = append(checks, missingCheckpoints)
+	}
+
+	var problems []Problem
+	if err := db.Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for _, c := range checks {
+			found, err := c(ctx, tx, opts.Repair)
+			if err != nil {
+				return err
+			}
+			problems = append(problems, found...)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+func states(m map[model.State]bool) []string {
+	var s []string
+	for state := range m {
+		s = append(s, string(state))
+	}
+	sort.Strings(s)
+	return s
+}
+
+func stuckTrials(ctx context.Context, tx bun.Tx, repair bool) ([]Problem, error) {
+	var trials []struct {
+		ID              int         `bun:"id"`
+		State           model.State `bun:"state"`
+		ExperimentID    int         `bun:"experiment_id"`
+		ExperimentState model.State `bun:"experiment_state"`
+	}
+	if err := tx.NewRaw(`
+SELECT t.id, t.state, e.id AS experiment_id, e.state AS experiment_state
+FROM trials t
+JOIN experiments e ON e.id = t.experiment_id
+WHERE t.state::text NOT IN (?) AND e.state::text IN (?)
+ORDER BY t.id`,
+		bun.In(states(model.TerminalStates)), bun.In(states(model.TerminalStates)),
+	).Scan(ctx, &trials); err != nil {
+		return nil, errors.Wrap(err, "error finding stuck trials")
+	}
+
+	var problems []Problem
+	for _, t := range trials {
+		state, ok := model.StoppingToTerminalStates[t.State]
+		if !ok {
+			state = model.ErrorState
+		}
+		p := Problem{
+			Kind:   StuckTrial,
+			Object: fmt.Sprintf("trial %d", t.ID),
+			Detail: fmt.Sprintf("is %s but experiment %d is %s",
+				t.State, t.ExperimentID, t.ExperimentState),
+			Repair: fmt.Sprintf("set its state to %s", state),
+		}
+		if repair {
+			if _, err := tx.ExecContext(ctx, `
+UPDATE trials t SET state = ?, end_time = coalesce(t.end_time, e.end_time, now())
+FROM experiments e
+WHERE e.id = t.experiment_id AND t.id = ?`, state, t.ID); err != nil {
+				return nil, errors.Wrapf(err, "error repairing trial %d", t.ID)
+			}
+			p.Repaired = true
+		}
+		problems = append(problems, p)
+	}
+	return problems, nil
+}
+
+func orphanedAllocations(ctx context.Context, tx bun.Tx, repair bool) ([]Problem, error) {
+	var allocations []struct {
+		AllocationID model.AllocationID `bun:"allocation_id"`
+		TaskID       model.TaskID       `bun:"task_id"`
+		TaskEnded    bool               `bun:"task_ended"`
+		TrialID      *int               `bun:"trial_id"`
+		TrialState   *model.State       `bun:"trial_state"`
+	}
+	if err := tx.NewRaw(`
+SELECT a.allocation_id, a.task_id, tk.end_time IS NOT NULL AS task_ended,
+    t.id AS trial_id, t.state AS trial_state
+FROM allocations a
+JOIN tasks tk ON tk.task_id = a.task_id
+LEFT JOIN trials t ON t.task_id = a.task_id
+WHERE a.end_time IS NULL AND (tk.end_time IS NOT NULL OR t.state::text IN (?))
+ORDER BY a.allocation_id`, bun.In(states(model.TerminalStates)),
+	).Scan(ctx, &allocations); err != nil {
+		return nil, errors.Wrap(err, "error finding orphaned allocations")
+	}
+
+	var problems []Problem
+	for _, a := range allocations {
+		detail := fmt.Sprintf("is open but task %s has ended", a.TaskID)
+		if !a.TaskEnded && a.TrialID != nil && a.TrialState != nil {
+			detail = fmt.Sprintf("is open but trial %d is %s", *a.TrialID, *a.TrialState)
+		}
+		p := Problem{
+			Kind:   OrphanedAllocation,
+			Object: fmt.Sprintf("allocation %s", a.AllocationID),
+			Detail: detail,
+			Repair: "end it",
+		}
+		if repair {
+			if _, err := tx.ExecContext(ctx, `
+UPDATE allocations a SET state = 'TERMINATED', end_time = greatest(a.start_time, coalesce(
+    (SELECT tk.end_time FROM tasks tk WHERE tk.task_id = a.task_id),
+    (SELECT max(t.end_time) FROM trials t WHERE t.task_id = a.task_id),
+    now()))
+WHERE a.allocation_id = ?`, a.AllocationID); err != nil {
+				return nil, errors.Wrapf(err, "error repairing allocation %s", a.AllocationID)
+			}
+			p.Repaired = true
+		}
+		problems = append(problems, p)
+	}
+	return problems, nil
+}
+
+func danglingTasks(ctx context.Context, tx bun.Tx, repair bool) ([]Problem, error) {
+	var tasks []struct {
+		TaskID     model.TaskID   `bun:"task_id"`
+		TaskType   model.TaskType `bun:"task_type"`
+		TrialID    *int           `bun:"trial_id"`
+		TrialState *model.State   `bun:"trial_state"`
+	}
+	// Trials that have not ended keep their tasks open between allocations, but nothing else
+	// starts a new allocation for a task.
+	if err := tx.NewRaw(`
+SELECT tk.task_id, tk.task_type, t.id AS trial_id, t.state AS trial_state
+FROM tasks tk
+LEFT JOIN trials t ON t.task_id = tk.task_id
+WHERE tk.end_time IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM allocations a WHERE a.task_id = tk.task_id AND a.end_time IS NULL)
+    AND (tk.task_type != ? OR t.id IS NULL OR t.state::text IN (?))
+ORDER BY tk.task_id`, model.TaskTypeTrial, bun.In(states(model.TerminalStates)),
+	).Scan(ctx, &tasks); err != nil {
+		return nil, errors.Wrap(err, "error finding dangling tasks")
+	}
+
+	var problems []Problem
+	for _, t := range tasks {
+		var detail string
+		switch {
+		case t.TaskType != model.TaskTypeTrial:
+			detail = fmt.Sprintf("is an open %s task with no open allocation", t.TaskType)
+		case t.TrialID == nil:
+			detail = "is an open trial task with no trial"
+		default:
+			detail = fmt.Sprintf("is open but trial %d is %s", *t.TrialID, *t.TrialState)
+		}
+		p := Problem{
+			Kind:   DanglingTask,
+			Object: fmt.Sprintf("task %s", t.TaskID),
+			Detail: detail,
+			Repair: "end it",
+		}
+		if repair {
+			if _, err := tx.ExecContext(ctx, `
+UPDATE tasks tk SET end_time = greatest(tk.start_time, coalesce(
+    (SELECT max(a.end_time) FROM allocations a WHERE a.task_id = tk.task_id), now()))
+WHERE tk.task_id = ?`, t.TaskID); err != nil {
+				return nil, errors.Wrapf(err, "error repairing task %s", t.TaskID)
+			}
+			p.Repaired = true
+		}
+		problems = append(problems, p)
+	}
+	return problems, nil
+}
+
+// checkpointFile returns a file of a checkpoint from its resources, which map paths to sizes, or
+// false if it has none; paths that end in a slash are directories.
+func checkpointFile(resources map[string]int64) (string, bool) {
+	var files []string
+	for path := range resources {
+		if !strings.HasSuffix(path, "/") {
+			files = append(files, path)
+		}
+	}
+	if len(files) == 0 {
+		return "", false
+	}
+	sort.Strings(files)
+	return files[0], true
+}
+
+func missingCheckpoints(ctx context.Context, tx bun.Tx, repair bool) ([]Problem, error) {
+	var checkpoints []struct {
+		UUID      string           `bun:"uuid"`
+		Resources map[string]int64 `bun:"resources"`
+		Storage   json.RawMessage  `bun:"storage"`
+	}
+	if err := tx.NewRaw(`
+SELECT c.uuid, c.resources, e.config->'checkpoint_storage' AS storage
+FROM checkpoints_v2 c
+JOIN trials t ON t.task_id = c.task_id
+JOIN experiments e ON e.id = t.experiment_id
+WHERE c.state = 'COMPLETED'
+ORDER BY c.id`).Scan(ctx, &checkpoints); err != nil {
+		return nil, errors.Wrap(err, "error finding completed checkpoints")
+	}
+
+	// Experiments mostly share a few storage configurations, so each is only connected to once.
+	stores := map[string]objectstore.Store{}
+	var problems []Problem
+	for _, c := range checkpoints {
+		file, ok := checkpointFile(c.Resources)
+		if !ok {
+			continue
+		}
+		s, ok := stores[string(c.Storage)]
+		if !ok {
+			var storage expconf.CheckpointStorageConfig
+			if err := json.Unmarshal(c.Storage, &storage); err != nil {
+				return nil, errors.Wrapf(err, "error parsing storage of checkpoint %s", c.UUID)
+			}
+			var err error
+			if s, err = objectstore.New(ctx, storage); err != nil {
+				log.WithError(err).Warn("not checking checkpoints in storage the master cannot reach")
+			}
+			stores[string(c.Storage)] = s
+		}
+		if s == nil {
+			continue
+		}
+
+		exists, err := s.Exists(ctx, c.UUID+"/"+file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error checking storage of checkpoint %s", c.UUID)
+		}
+		if exists {
+			continue
+		}
+		p := Problem{
+			Kind:   MissingCheckpoint,
+			Object: fmt.Sprintf("checkpoint %s", c.UUID),
+			Detail: fmt.Sprintf("is completed but %s is not in storage", file),
+			Repair: "mark it deleted",
+		}
+		if repair {
+			if _, err := tx.ExecContext(ctx, `
+UPDATE checkpoints_v2 SET state = 'DELETED' WHERE uuid = ?`, c.UUID); err != nil {
+				return nil, errors.Wrapf(err, "error repairing checkpoint %s", c.UUID)
+			}
+			p.Repaired = true
+		}
+		problems = append(problems, p)
+	}
+	return problems, nil
+}
+
+// Log logs the problems that were found.
+func Log(problems []Problem) {
+	for _, p := range problems {
+		entry := log.WithField("kind", p.Kind)
+		if p.Repaired {
+			entry.Warnf("fsck: %s %s; repaired: %s", p.Object, p.Detail, p.Repair)
+		} else {
+			entry.Warnf("fsck: %s %s; to repair: %s", p.Object, p.Detail, p.Repair)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+//go:build integration
+// +build integration
+
+package fsck
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func find(problems []Problem, kind Kind, object string) *Problem {
+	for i, p := range problems {
+		if p.Kind == kind && p.Object == object {
+			return &problems[i]
+		}
+	}
+	return nil
+}
+
+func TestChecks(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(db.RootFromDB))
+	pgDB := db.MustResolveTestPostgres(t)
+	db.MustMigrateTestPostgres(t, pgDB, db.MigrationsFromDB)
+	ctx := context.Background()
+
+	user := db.RequireMockUser(t, pgDB)
+	exp := db.RequireMockExperiment(t, pgDB, user)
+	stuck := db.RequireMockTrial(t, pgDB, exp)
+	stopping := db.RequireMockTrial(t, pgDB, exp)
+	allocation := db.RequireMockAllocation(t, pgDB, stuck.TaskID)
+	running := db.RequireMockTrial(t, pgDB, db.RequireMockExperiment(t, pgDB, user))
+	db.RequireMockAllocation(t, pgDB, running.TaskID)
+
+	_, err := db.Bun().ExecContext(ctx, `
+UPDATE experiments SET state = 'CANCELED', end_time = now() WHERE id = ?`, exp.ID)
+	require.NoError(t, err)
+	_, err = db.Bun().ExecContext(ctx, `
+UPDATE trials SET state = 'STOPPING_CANCELED' WHERE id = ?`, stopping.ID)
+	require.NoError(t, err)
+
+	// Other tests share the database, so the checks run in a transaction that is rolled back.
+	tx, err := db.Bun().BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, tx.Rollback()) }()
+
+	run := func(c check, repair bool) []Problem {
+		problems, err := c(ctx, tx, repair)
+		require.NoError(t, err)
+		return problems
+	}
+	trial := func(id int) string { return fmt.Sprintf("trial %d", id) }
+	task := func(id model.TaskID) string { return fmt.Sprintf("task %s", id) }
+
+	problems := run(stuckTrials, false)
+	p := find(problems, StuckTrial, trial(stuck.ID))
+	require.NotNil(t, p)
+	require.Equal(t, fmt.Sprintf("is ACTIVE but experiment %d is CANCELED", exp.ID), p.Detail)
+	require.False(t, p.Repaired)
+	require.NotNil(t, find(problems, StuckTrial, trial(stopping.ID)))
+	require.Nil(t, find(problems, StuckTrial, trial(running.ID)))
+
+	// Trials that are not repaired leave their allocations and tasks open.
+	require.Nil(t, find(run(orphanedAllocations, false), OrphanedAllocation,
+		fmt.Sprintf("allocation %s", allocation.AllocationID)))
+
+	require.NotNil(t, find(run(stuckTrials, true), StuckTrial, trial(stuck.ID)))
+	for id, state := range map[int]model.State{
+		stuck.ID:    model.ErrorState,
+		stopping.ID: model.CanceledState,
+		running.ID:  model.ActiveState,
+	} {
+		var actual model.State
+		require.NoError(t, tx.NewRaw("SELECT state FROM trials WHERE id = ?", id).Scan(ctx, &actual))
+		require.Equal(t, state, actual)
+	}
+
+	p = find(run(orphanedAllocations, true), OrphanedAllocation,
+		fmt.Sprintf("allocation %s", allocation.AllocationID))
+	require.NotNil(t, p)
+	require.Equal(t, fmt.Sprintf("is open but trial %d is ERROR", stuck.ID), p.Detail)
+	require.True(t, p.Repaired)
+
+	problems = run(danglingTasks, true)
+	require.NotNil(t, find(problems, DanglingTask, task(stuck.TaskID)))
+	require.NotNil(t, find(problems, DanglingTask, task(stopping.TaskID)))
+	require.Nil(t, find(problems, DanglingTask, task(running.TaskID)))
+
+	// Everything is consistent once repaired.
+	for _, c := range []check{stuckTrials, orphanedAllocations, danglingTasks} {
+		for _, p := range run(c, false) {
+			for _, object := range []string{
+				trial(stuck.ID), trial(stopping.ID), task(stuck.TaskID), task(stopping.TaskID),
+				fmt.Sprintf("allocation %s", allocation.AllocationID),
+			} {
+				require.NotEqual(t, object, p.Object)
+			}
+		}
+	}
+	var ended sql.NullTime
+	require.NoError(t, tx.NewRaw("SELECT end_time FROM tasks WHERE task_id = ?", stuck.TaskID).
+		Scan(ctx, &ended))
+	require.True(t, ended.Valid)
+}
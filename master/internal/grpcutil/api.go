@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net/http"
 	"runtime/debug"
 
 	grpcmiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
@@ -17,25 +18,32 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/drain"
+	"github.com/determined-ai/determined/master/internal/querycache"
+	"github.com/determined-ai/determined/master/internal/ratelimit"
+	"github.com/determined-ai/determined/master/pkg/logger"
 	"github.com/determined-ai/determined/master/pkg/model"
 	proto "github.com/determined-ai/determined/proto/pkg/apiv1"
 )
 
 const jsonPretty = "application/json+pretty"
 
-// NewGRPCServer creates a Determined gRPC service.
+// NewGRPCServer creates a Determined gRPC service. A nil limiter does not limit requests, and the
+// cache, if any, is invalidated by the calls that change what it holds.
 func NewGRPCServer(db *db.PgDB, srv proto.DeterminedServer, enablePrometheus bool,
-	extConfig *model.ExternalSessions,
+	extConfig *model.ExternalSessions, limiter *ratelimit.Limiter, cache *querycache.Cache,
 ) *grpc.Server {
 	// In go-grpc, the INFO log level is used primarily for debugging
 	// purposes, so omit INFO messages from the master log.
-	logger := logrus.New()
-	logger.SetLevel(logrus.WarnLevel)
+	grpcLogger := logrus.New()
+	grpcLogger.SetLevel(logrus.WarnLevel)
+	grpcLogger.SetFormatter(logrus.StandardLogger().Formatter)
 
-	logEntry := logrus.NewEntry(logger)
+	logEntry := logrus.NewEntry(grpcLogger)
 	grpclogrus.ReplaceGrpcLogger(logEntry)
 
 	opts := []grpclogrus.Option{
@@ -44,20 +52,24 @@ func NewGRPCServer(db *db.PgDB, srv proto.DeterminedServer, enablePrometheus boo
 
 	streamInterceptors := []grpc.StreamServerInterceptor{
 		grpclogrus.StreamServerInterceptor(logEntry, opts...),
+		streamRequestIDInterceptor(),
 		grpcrecovery.StreamServerInterceptor(),
-		streamAuthInterceptor(db, extConfig),
+		streamAuthInterceptor(db, extConfig, limiter),
 	}
 
 	unaryInterceptors := []grpc.UnaryServerInterceptor{
 		grpclogrus.UnaryServerInterceptor(logEntry, opts...),
+		unaryRequestIDInterceptor(),
 		grpcrecovery.UnaryServerInterceptor(grpcrecovery.WithRecoveryHandler(
 			func(p interface{}) (err error) {
 				logEntry.Error(string(debug.Stack()))
 				return status.Errorf(codes.Internal, "%s", p)
 			},
 		)),
-		unaryAuthInterceptor(db, extConfig),
+		drain.UnaryServerInterceptor(),
+		unaryAuthInterceptor(db, extConfig, limiter),
 		authZInterceptor(),
+		querycache.UnaryServerInterceptor(cache),
 	}
 
 	if enablePrometheus {
@@ -87,10 +99,19 @@ func newGRPCGatewayMux() *runtime.ServeMux {
 			&runtime.JSONPb{EmitDefaults: true}),
 		runtime.WithProtoErrorHandler(errorHandler),
 		runtime.WithForwardResponseOption(userTokenResponse),
+		runtime.WithMetadata(requestIDFromHeader),
 	}
 	return runtime.NewServeMux(serverOpts...)
 }
 
+// requestIDFromHeader forwards the ID that the echo server gave a request to the gRPC server.
+func requestIDFromHeader(_ context.Context, r *http.Request) metadata.MD {
+	if id := r.Header.Get(logger.RequestIDHeader); id != "" {
+		return metadata.Pairs(requestIDMetadata, id)
+	}
+	return nil
+}
+
 // RegisterHTTPProxy registers grpc-gateway with the master echo server.
 func RegisterHTTPProxy(ctx context.Context, e *echo.Echo, port int, cert *tls.Certificate) error {
 	addr := fmt.Sprintf(":%d", port)
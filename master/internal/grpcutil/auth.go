@@ -17,8 +17,10 @@ import (
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
+	"github.com/determined-ai/determined/master/internal/auditlog"
 	"github.com/determined-ai/determined/master/internal/config"
 	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/ratelimit"
 	"github.com/determined-ai/determined/master/internal/rbac/audit"
 	"github.com/determined-ai/determined/master/internal/user"
 	"github.com/determined-ai/determined/master/pkg/model"
@@ -45,6 +47,25 @@ var unauthenticatedMethods = map[string]bool{
 	"/determined.api.v1.Determined/GetTelemetry": true,
 }
 
+// mfaEnrollmentMethods are the methods that sessions of users who must enroll in multi-factor
+// authentication may call before they have.
+var mfaEnrollmentMethods = map[string]bool{
+	"/determined.api.v1.Determined/CurrentUser":          true,
+	"/determined.api.v1.Determined/GetMe":                true,
+	"/determined.api.v1.Determined/Logout":               true,
+	"/determined.api.v1.Determined/GetUserMFA":           true,
+	"/determined.api.v1.Determined/PostMFAEnrollment":    true,
+	"/determined.api.v1.Determined/ConfirmMFAEnrollment": true,
+}
+
+// checkpointDownloadMethods are the methods that access tokens scoped to downloading checkpoints
+// may call.
+var checkpointDownloadMethods = map[string]bool{
+	"/determined.api.v1.Determined/GetCheckpoint":            true,
+	"/determined.api.v1.Determined/GetExperimentCheckpoints": true,
+	"/determined.api.v1.Determined/GetTrialCheckpoints":      true,
+}
+
 var (
 	// ErrInvalidCredentials notifies that the provided credentials are invalid or missing.
 	ErrInvalidCredentials = status.Error(codes.Unauthenticated, "invalid credentials")
@@ -54,6 +75,24 @@ var (
 	ErrNotActive = status.Error(codes.PermissionDenied, "user is not active")
 	// ErrPermissionDenied notifies that the user does not have permission to access the method.
 	ErrPermissionDenied = status.Error(codes.PermissionDenied, "user does not have permission")
+	// ErrTokenScope notifies that the access token used does not allow calling the method.
+	ErrTokenScope = status.Error(codes.PermissionDenied, "access token scope does not allow this")
+	// ErrMFARequired notifies that logging in requires a code from the authenticator app of the
+	// user.
+	ErrMFARequired = status.Error(codes.FailedPrecondition, "MFA code required")
+	// ErrMFAEnrollmentRequired notifies that the user must enroll in multi-factor authentication
+	// before calling the method.
+	ErrMFAEnrollmentRequired = status.Error(codes.PermissionDenied,
+		"MFA enrollment required: enroll with 'det user mfa enroll' before continuing")
+	// ErrPasswordExpired notifies that the password of the user has expired and that logging in
+	// requires a new one.
+	ErrPasswordExpired = status.Error(codes.FailedPrecondition, "password expired")
+	// ErrUserLocked notifies that the user is locked out after too many failed logins.
+	ErrUserLocked = status.Error(codes.PermissionDenied,
+		"user is locked out after too many failed logins; try again later")
+	// ErrServiceAccountLogin notifies that service accounts cannot log in.
+	ErrServiceAccountLogin = status.Error(codes.PermissionDenied,
+		"service accounts cannot log in; authenticate with an access token instead")
 )
 
 func allocationSessionByTokenBun(token string) (*model.AllocationSession, error) {
@@ -165,17 +204,55 @@ func GetUser(ctx context.Context) (*model.User, *model.UserSession, error) {
 
 // Return error if user cannot be authenticated or lacks authorization.
 func auth(ctx context.Context, db *db.PgDB, fullMethod string,
-	extConfig *model.ExternalSessions,
+	extConfig *model.ExternalSessions, limiter *ratelimit.Limiter,
 ) (*model.User, *model.UserSession, error) {
 	if unauthenticatedMethods[fullMethod] {
 		return nil, nil, nil
 	}
 
-	return GetUser(ctx)
+	u, session, err := GetUser(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session != nil && !methodAllowed(session.AccessTokenScope, fullMethod) {
+		return nil, nil, ErrTokenScope
+	}
+	if session != nil && session.MFAEnrollmentOnly && !mfaEnrollmentMethods[fullMethod] {
+		return nil, nil, ErrMFAEnrollmentRequired
+	}
+	// Allocations calling back to the master have no session and are not limited.
+	if session != nil {
+		if ok, delay := limiter.Allow(u.ID, ratelimit.GRPCClass(fullMethod)); !ok {
+			return nil, nil, status.Errorf(codes.ResourceExhausted,
+				"rate limit exceeded, retry in %s", delay.Round(time.Millisecond))
+		}
+	}
+	// Only sessions started by logging in are kept in the database.
+	if session != nil && session.AccessTokenScope == "" && extConfig.JwtKey == "" {
+		if err := user.RecordSessionUse(ctx, session.ID, auditlog.RemoteIP(ctx)); err != nil {
+			log.WithError(err).Warnf("failed to record use of session %d", session.ID)
+		}
+	}
+	return u, session, nil
+}
+
+// methodAllowed returns whether an access token with the given scope may call a method.
+func methodAllowed(scope model.AccessTokenScope, fullMethod string) bool {
+	if scope.AllowsAll() {
+		return true
+	}
+	switch scope {
+	case model.AccessTokenScopeReadOnly:
+		return readOnlyMethods[fullMethod]
+	case model.AccessTokenScopeCheckpointDownload:
+		return checkpointDownloadMethods[fullMethod]
+	default:
+		return false
+	}
 }
 
 func streamAuthInterceptor(db *db.PgDB,
-	extConfig *model.ExternalSessions,
+	extConfig *model.ExternalSessions, limiter *ratelimit.Limiter,
 ) grpc.StreamServerInterceptor {
 	return func(
 		srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
@@ -183,14 +260,18 @@ func streamAuthInterceptor(db *db.PgDB,
 		// Don't cache the result of the stream auth interceptor because
 		// we can't easily modify ss's context and
 		// we would have to worry about the user session expiring in the context.
-		_, _, err := auth(ss.Context(), db, info.FullMethod, extConfig)
+		user, _, err := auth(ss.Context(), db, info.FullMethod, extConfig, limiter)
+		if err != nil {
+			return err
+		}
+		ctx := ss.Context()
+		if user != nil {
+			ctx = withUserField(ctx, user.Username)
+		}
 		fields := log.Fields{"endpoint": info.FullMethod}
 		wrappedSS := grpc_middleware.WrappedServerStream{
 			ServerStream:   ss,
-			WrappedContext: context.WithValue(ss.Context(), audit.LogKey{}, fields),
-		}
-		if err != nil {
-			return err
+			WrappedContext: context.WithValue(ctx, audit.LogKey{}, fields),
 		}
 
 		return handler(srv, &wrappedSS)
@@ -198,17 +279,18 @@ func streamAuthInterceptor(db *db.PgDB,
 }
 
 func unaryAuthInterceptor(db *db.PgDB,
-	extConfig *model.ExternalSessions,
+	extConfig *model.ExternalSessions, limiter *ratelimit.Limiter,
 ) grpc.UnaryServerInterceptor {
 	return func(
 		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
 	) (resp interface{}, err error) {
-		user, session, err := auth(ctx, db, info.FullMethod, extConfig)
+		user, session, err := auth(ctx, db, info.FullMethod, extConfig, limiter)
 		if err != nil {
 			return nil, err
 		}
 		if user != nil {
 			ctx = context.WithValue(ctx, userContextKey{}, user)
+			ctx = withUserField(ctx, user.Username)
 		}
 		if session != nil {
 			ctx = context.WithValue(ctx, userSessionContextKey{}, session)
@@ -235,7 +317,7 @@ func userTokenResponse(_ context.Context, w http.ResponseWriter, resp proto.Mess
 		http.SetCookie(w, &http.Cookie{
 			Name:    cookieName,
 			Value:   r.Token,
-			Expires: time.Now().Add(db.SessionDuration),
+			Expires: time.Now().Add(db.SessionDuration()),
 			Path:    "/",
 		})
 	case *apiv1.LogoutResponse:
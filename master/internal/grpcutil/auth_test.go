@@ -0,0 +1,41 @@
+package grpcutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+func TestMethodAllowed(t *testing.T) {
+	const prefix = "/determined.api.v1.Determined/"
+	service := apiv1.File_determined_api_v1_api_proto.Services().ByName("Determined")
+	for _, methods := range []map[string]bool{readOnlyMethods, checkpointDownloadMethods} {
+		for fullMethod := range methods {
+			name := protoreflect.Name(strings.TrimPrefix(fullMethod, prefix))
+			require.NotNil(t, service.Methods().ByName(name), "unknown method %s", fullMethod)
+		}
+	}
+
+	for _, c := range []struct {
+		scope   model.AccessTokenScope
+		method  string
+		allowed bool
+	}{
+		{"", "PostUser", true},
+		{model.AccessTokenScopeFull, "PostUser", true},
+		{model.AccessTokenScopeReadOnly, "GetExperiment", true},
+		{model.AccessTokenScopeReadOnly, "TrialLogs", true},
+		{model.AccessTokenScopeReadOnly, "PostUser", false},
+		{model.AccessTokenScopeReadOnly, "AllocationPreemptionSignal", false},
+		{model.AccessTokenScopeCheckpointDownload, "GetCheckpoint", true},
+		{model.AccessTokenScopeCheckpointDownload, "GetExperiment", false},
+	} {
+		require.Equal(t, c.allowed, methodAllowed(c.scope, prefix+c.method), "%s %s",
+			c.scope, c.method)
+	}
+}
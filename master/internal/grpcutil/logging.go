@@ -1,10 +1,63 @@
 package grpcutil
 
 import (
+	"context"
+	"strings"
+
+	grpcmiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus/ctxlogrus"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/determined-ai/determined/master/pkg/logger"
 )
 
+// requestIDMetadata is the metadata key that carries the ID of a request, which the gateway sets
+// from the header of the same name.
+var requestIDMetadata = strings.ToLower(logger.RequestIDHeader)
+
+// withRequestFields adds the ID and route of a request to the fields that its context carries and
+// to the log of its outcome.
+func withRequestFields(ctx context.Context, method string) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDMetadata); len(ids) > 0 {
+			id = ids[0]
+		}
+	}
+	fields := logger.Context{"request-id": logger.RequestID(id), "route": method}
+	ctxlogrus.AddFields(ctx, fields.Fields())
+	return logger.WithFields(ctx, fields)
+}
+
+// withUserField adds the user making a request to the fields that its context carries and to the
+// log of its outcome.
+func withUserField(ctx context.Context, username string) context.Context {
+	ctxlogrus.AddFields(ctx, logrus.Fields{"user": username})
+	return logger.WithFields(ctx, logger.Context{"user": username})
+}
+
+func unaryRequestIDInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		return handler(withRequestFields(ctx, info.FullMethod), req)
+	}
+}
+
+func streamRequestIDInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &grpcmiddleware.WrappedServerStream{
+			ServerStream:   ss,
+			WrappedContext: withRequestFields(ss.Context(), info.FullMethod),
+		})
+	}
+}
+
 func grpcCodeToLogrusLevel(code codes.Code) logrus.Level {
 	switch code {
 	// TraceLevel: Provides very little new info, mostly noise.
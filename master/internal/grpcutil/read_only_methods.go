@@ -0,0 +1,130 @@
+package grpcutil
+
+// readOnlyMethods are the methods that access tokens with the read-only scope may call. None of
+// them change anything; new methods are only allowed once they are added here.
+var readOnlyMethods = map[string]bool{
+	"/determined.api.v1.Determined/CompareExperiments":               true,
+	"/determined.api.v1.Determined/CompareTrials":                    true,
+	"/determined.api.v1.Determined/CurrentUser":                      true,
+	"/determined.api.v1.Determined/ExpCompareMetricNames":            true,
+	"/determined.api.v1.Determined/ExpCompareTrialsSample":           true,
+	"/determined.api.v1.Determined/FollowTaskLogs":                   true,
+	"/determined.api.v1.Determined/FollowTrialLogs":                  true,
+	"/determined.api.v1.Determined/GetAccessTokens":                  true,
+	"/determined.api.v1.Determined/GetActiveTasksCount":              true,
+	"/determined.api.v1.Determined/GetAgent":                         true,
+	"/determined.api.v1.Determined/GetAgentImages":                   true,
+	"/determined.api.v1.Determined/GetAgents":                        true,
+	"/determined.api.v1.Determined/GetArtifact":                      true,
+	"/determined.api.v1.Determined/GetAuditLog":                      true,
+	"/determined.api.v1.Determined/GetBestSearcherValidationMetric":  true,
+	"/determined.api.v1.Determined/GetCheckpoint":                    true,
+	"/determined.api.v1.Determined/GetClusterMessage":                true,
+	"/determined.api.v1.Determined/GetCommand":                       true,
+	"/determined.api.v1.Determined/GetCommands":                      true,
+	"/determined.api.v1.Determined/GetCurrentTrialSearcherOperation": true,
+	"/determined.api.v1.Determined/GetDatabaseBackup":                true,
+	"/determined.api.v1.Determined/GetDatabaseBackups":               true,
+	"/determined.api.v1.Determined/GetDatabaseHealth":                true,
+	"/determined.api.v1.Determined/GetDatabaseRestore":               true,
+	"/determined.api.v1.Determined/GetExperiment":                    true,
+	"/determined.api.v1.Determined/GetExperimentCheckpoints":         true,
+	"/determined.api.v1.Determined/GetExperimentCost":                true,
+	"/determined.api.v1.Determined/GetExperimentDependencies":        true,
+	"/determined.api.v1.Determined/GetExperimentEnergy":              true,
+	"/determined.api.v1.Determined/GetExperimentLabels":              true,
+	"/determined.api.v1.Determined/GetExperimentProgress":            true,
+	"/determined.api.v1.Determined/GetExperimentSchedule":            true,
+	"/determined.api.v1.Determined/GetExperimentScheduleRuns":        true,
+	"/determined.api.v1.Determined/GetExperimentSchedules":           true,
+	"/determined.api.v1.Determined/GetExperimentTrials":              true,
+	"/determined.api.v1.Determined/GetExperimentValidationHistory":   true,
+	"/determined.api.v1.Determined/GetExperiments":                   true,
+	"/determined.api.v1.Determined/GetGroup":                         true,
+	"/determined.api.v1.Determined/GetGroups":                        true,
+	"/determined.api.v1.Determined/GetHPImportance":                  true,
+	"/determined.api.v1.Determined/GetJobQueueStats":                 true,
+	"/determined.api.v1.Determined/GetJobs":                          true,
+	"/determined.api.v1.Determined/GetLogRetentionRuns":              true,
+	"/determined.api.v1.Determined/GetMaintenanceMode":               true,
+	"/determined.api.v1.Determined/GetMaster":                        true,
+	"/determined.api.v1.Determined/GetMasterConfig":                  true,
+	"/determined.api.v1.Determined/GetMe":                            true,
+	"/determined.api.v1.Determined/GetMigrationReport":               true,
+	"/determined.api.v1.Determined/GetModel":                         true,
+	"/determined.api.v1.Determined/GetModelDef":                      true,
+	"/determined.api.v1.Determined/GetModelDefFile":                  true,
+	"/determined.api.v1.Determined/GetModelDefTree":                  true,
+	"/determined.api.v1.Determined/GetModelLabels":                   true,
+	"/determined.api.v1.Determined/GetModelVersion":                  true,
+	"/determined.api.v1.Determined/GetModelVersionLineage":           true,
+	"/determined.api.v1.Determined/GetModelVersionTransitions":       true,
+	"/determined.api.v1.Determined/GetModelVersions":                 true,
+	"/determined.api.v1.Determined/GetModels":                        true,
+	"/determined.api.v1.Determined/GetNotebook":                      true,
+	"/determined.api.v1.Determined/GetNotebooks":                     true,
+	"/determined.api.v1.Determined/GetPermissionsSummary":            true,
+	"/determined.api.v1.Determined/GetPodTemplate":                   true,
+	"/determined.api.v1.Determined/GetPodTemplates":                  true,
+	"/determined.api.v1.Determined/GetProject":                       true,
+	"/determined.api.v1.Determined/GetProjectMembers":                true,
+	"/determined.api.v1.Determined/GetResourceCostReport":            true,
+	"/determined.api.v1.Determined/GetResourcePoolBindings":          true,
+	"/determined.api.v1.Determined/GetResourcePoolStats":             true,
+	"/determined.api.v1.Determined/GetResourcePools":                 true,
+	"/determined.api.v1.Determined/GetRolesAssignedToGroup":          true,
+	"/determined.api.v1.Determined/GetRolesAssignedToUser":           true,
+	"/determined.api.v1.Determined/GetRolesByID":                     true,
+	"/determined.api.v1.Determined/GetSSHKeys":                       true,
+	"/determined.api.v1.Determined/GetSearcherEvents":                true,
+	"/determined.api.v1.Determined/GetSessions":                      true,
+	"/determined.api.v1.Determined/GetShell":                         true,
+	"/determined.api.v1.Determined/GetShells":                        true,
+	"/determined.api.v1.Determined/GetSlot":                          true,
+	"/determined.api.v1.Determined/GetSlotReservations":              true,
+	"/determined.api.v1.Determined/GetSlots":                         true,
+	"/determined.api.v1.Determined/GetTask":                          true,
+	"/determined.api.v1.Determined/GetTaskArtifacts":                 true,
+	"/determined.api.v1.Determined/GetTaskSystemMetrics":             true,
+	"/determined.api.v1.Determined/GetTelemetry":                     true,
+	"/determined.api.v1.Determined/GetTemplate":                      true,
+	"/determined.api.v1.Determined/GetTemplates":                     true,
+	"/determined.api.v1.Determined/GetTensorboard":                   true,
+	"/determined.api.v1.Determined/GetTensorboards":                  true,
+	"/determined.api.v1.Determined/GetTrial":                         true,
+	"/determined.api.v1.Determined/GetTrialCheckpoints":              true,
+	"/determined.api.v1.Determined/GetTrialMetricSeries":             true,
+	"/determined.api.v1.Determined/GetTrialProfilerAvailableSeries":  true,
+	"/determined.api.v1.Determined/GetTrialProfilerMetrics":          true,
+	"/determined.api.v1.Determined/GetTrialProfilerTimeline":         true,
+	"/determined.api.v1.Determined/GetTrialReplayState":              true,
+	"/determined.api.v1.Determined/GetTrialWorkloads":                true,
+	"/determined.api.v1.Determined/GetTrialsCollections":             true,
+	"/determined.api.v1.Determined/GetUser":                          true,
+	"/determined.api.v1.Determined/GetUserByUsername":                true,
+	"/determined.api.v1.Determined/GetUserMFA":                       true,
+	"/determined.api.v1.Determined/GetUserSetting":                   true,
+	"/determined.api.v1.Determined/GetUsers":                         true,
+	"/determined.api.v1.Determined/GetVSCode":                        true,
+	"/determined.api.v1.Determined/GetVSCodes":                       true,
+	"/determined.api.v1.Determined/GetWebhook":                       true,
+	"/determined.api.v1.Determined/GetWebhooks":                      true,
+	"/determined.api.v1.Determined/GetWorkspace":                     true,
+	"/determined.api.v1.Determined/GetWorkspaceMembers":              true,
+	"/determined.api.v1.Determined/GetWorkspaceProjects":             true,
+	"/determined.api.v1.Determined/GetWorkspaces":                    true,
+	"/determined.api.v1.Determined/MasterLogs":                       true,
+	"/determined.api.v1.Determined/MetricBatches":                    true,
+	"/determined.api.v1.Determined/MetricNames":                      true,
+	"/determined.api.v1.Determined/ResourceAllocationAggregated":     true,
+	"/determined.api.v1.Determined/ResourceAllocationRaw":            true,
+	"/determined.api.v1.Determined/SearchTrialLogs":                  true,
+	"/determined.api.v1.Determined/StreamExperimentEvents":           true,
+	"/determined.api.v1.Determined/SummarizeTrial":                   true,
+	"/determined.api.v1.Determined/TaskLogs":                         true,
+	"/determined.api.v1.Determined/TaskLogsFields":                   true,
+	"/determined.api.v1.Determined/TrialLogs":                        true,
+	"/determined.api.v1.Determined/TrialLogsFields":                  true,
+	"/determined.api.v1.Determined/TrialsSample":                     true,
+	"/determined.api.v1.Determined/TrialsSnapshot":                   true,
+}
@@ -0,0 +1,112 @@
+// Package health checks the subsystems that the master depends on, such as the database and
+// checkpoint storage, for load balancers and alerting.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Status is the status of a subsystem, or of the master as a whole.
+type Status string
+
+const (
+	// StatusUp means that the subsystem, or every subsystem, is working.
+	StatusUp Status = "up"
+	// StatusDegraded means that the master is working, but a subsystem that only some features
+	// depend on is not.
+	StatusDegraded Status = "degraded"
+	// StatusDown means that the subsystem, or one that the master cannot work without, is not
+	// working.
+	StatusDown Status = "down"
+	// StatusUnknown means that the master cannot check the subsystem.
+	StatusUnknown Status = "unknown"
+)
+
+// ErrUnsupported is returned by checks of subsystems that the master cannot check.
+var ErrUnsupported = errors.New("cannot be checked by the master")
+
+// Check checks a subsystem.
+type Check struct {
+	Name string
+	// Kind describes the subsystem, such as the type of resource manager.
+	Kind string
+	// Critical checks are of subsystems that the master cannot work without.
+	Critical bool
+	Run      func(ctx context.Context) error
+}
+
+// Result is the result of a check.
+type Result struct {
+	Name      string  `json:"name"`
+	Kind      string  `json:"kind,omitempty"`
+	Status    Status  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the result of checking every subsystem.
+type Report struct {
+	Status Status   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Run runs the checks concurrently, failing any that take longer than timeout. The master is down
+// if any critical check fails, and degraded if any other check does.
+func Run(ctx context.Context, timeout time.Duration, checks []Check) Report {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	report := Report{Status: StatusUp, Checks: make([]Result, len(checks))}
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c Check) {
+			defer wg.Done()
+			report.Checks[i] = run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for i, r := range report.Checks {
+		switch {
+		case r.Status != StatusDown:
+		case checks[i].Critical:
+			report.Status = StatusDown
+		case report.Status == StatusUp:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+func run(ctx context.Context, c Check) Result {
+	start := time.Now()
+	errs := make(chan error, 1)
+	// Checks that ignore the context, such as those that ask actors, are abandoned on timeout.
+	go func() { errs <- c.Run(ctx) }()
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		err = errors.New("timed out")
+	}
+
+	r := Result{
+		Name:      c.Name,
+		Kind:      c.Kind,
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	switch {
+	case errors.Is(err, ErrUnsupported):
+		r.Status = StatusUnknown
+		r.Error = err.Error()
+	case err != nil:
+		r.Status = StatusDown
+		r.Error = err.Error()
+	}
+	return r
+}
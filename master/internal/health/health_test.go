@@ -0,0 +1,44 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	up := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+	hang := func(context.Context) error {
+		time.Sleep(time.Hour)
+		return nil
+	}
+	unsupported := func(context.Context) error { return ErrUnsupported }
+
+	report := Run(context.Background(), time.Second, []Check{
+		{Name: "database", Critical: true, Run: up},
+		{Name: "checkpoint_storage", Run: unsupported},
+	})
+	require.Equal(t, StatusUp, report.Status)
+	require.Equal(t, StatusUp, report.Checks[0].Status)
+	require.Equal(t, StatusUnknown, report.Checks[1].Status)
+
+	report = Run(context.Background(), time.Second, []Check{
+		{Name: "database", Critical: true, Run: up},
+		{Name: "log_backend", Run: down},
+	})
+	require.Equal(t, StatusDegraded, report.Status)
+	require.Equal(t, "connection refused", report.Checks[1].Error)
+
+	start := time.Now()
+	report = Run(context.Background(), 50*time.Millisecond, []Check{
+		{Name: "resource_manager", Critical: true, Run: hang},
+		{Name: "log_backend", Run: down},
+	})
+	require.Less(t, time.Since(start), time.Second)
+	require.Equal(t, StatusDown, report.Status)
+	require.Equal(t, "timed out", report.Checks[0].Error)
+}
@@ -260,3 +260,16 @@ func TestComputeHPImportance(t *testing.T) {
 	err = os.Remove("data.arff")
 	assert.Assert(t, err == nil)
 }
+
+func TestCountTrialData(t *testing.T) {
+	trials, dataPoints := countTrialData(map[int][]model.HPImportanceTrialData{
+		100: {{TrialID: 1}, {TrialID: 2}},
+		200: {{TrialID: 1}, {TrialID: 2}, {TrialID: 3}},
+	})
+	assert.Equal(t, trials, 3)
+	assert.Equal(t, dataPoints, 5)
+
+	trials, dataPoints = countTrialData(nil)
+	assert.Equal(t, trials, 0)
+	assert.Equal(t, dataPoints, 0)
+}
@@ -50,6 +50,11 @@ type (
 		Progress float64
 	}
 
+	// TrialClosed is the message an experiment sends when one of its trials closes.
+	TrialClosed struct {
+		ExperimentID int
+	}
+
 	// WorkRequest is an explicit request to compute HP importance on-demand.
 	WorkRequest struct {
 		ExperimentID int
@@ -131,6 +136,8 @@ func (m *manager) Receive(ctx *actor.Context) error {
 		m.experimentCreated(ctx, msg)
 	case ExperimentProgress:
 		m.experimentProgress(ctx, msg)
+	case TrialClosed:
+		m.trialClosed(ctx, msg)
 	case WorkRequest:
 		m.workRequest(ctx, msg)
 	case workStarted:
@@ -194,16 +201,24 @@ func (m *manager) experimentCreated(ctx *actor.Context, msg ExperimentCreated) {
 }
 
 func (m *manager) experimentProgress(ctx *actor.Context, msg ExperimentProgress) {
-	state, ok := m.state[msg.ID]
-	if !ok {
-		state = stateRecord{}
-	}
+	state := m.state[msg.ID]
 	if msg.Progress-state.lastProgress > minPercent &&
 		time.Since(state.lastResult) > minPause {
+		state.lastProgress = msg.Progress
+		m.state[msg.ID] = state
 		m.triggerDefaultWork(ctx, msg.ID)
 	}
 }
 
+// trialClosed recomputes importance once a trial's final metrics are in, so results keep up with
+// searchers that close trials faster than they report progress. Workers reuse the stored results
+// when no new metrics were reported, so this is cheap when nothing changed.
+func (m *manager) trialClosed(ctx *actor.Context, msg TrialClosed) {
+	if time.Since(m.state[msg.ExperimentID].lastResult) > minPause {
+		m.triggerDefaultWork(ctx, msg.ExperimentID)
+	}
+}
+
 func (m *manager) workStarted(ctx *actor.Context, msg workStarted) {
 	hpi, err := m.db.GetHPImportance(msg.experimentID)
 	if err != nil {
@@ -258,6 +273,8 @@ func (m *manager) workCompleted(ctx *actor.Context, msg workCompleted) {
 	metricData.Error = ""
 	metricData.ExperimentProgress = msg.progress
 	metricData.HpImportance = msg.results
+	metricData.Trials = msg.trials
+	metricData.DataPoints = msg.dataPoints
 	metricData.InProgress = false
 	hpi.SetMetricHPImportance(metricData, msg.metricName, msg.metricType)
 	err = m.db.SetHPImportance(msg.experimentID, hpi)
@@ -31,6 +31,8 @@ type (
 		metricName   string
 		metricType   model.MetricType
 		progress     float64
+		trials       int
+		dataPoints   int
 		results      map[string]float64
 	}
 
@@ -66,13 +68,15 @@ func taskHandlerFactory(db *db.PgDB, system *actor.System, growforest string, wo
 	}
 
 	sendWorkCompleted := func(system *actor.System, work startWork, progress float64,
-		results map[string]float64,
+		trials, dataPoints int, results map[string]float64,
 	) {
 		system.Tell(getManager(), workCompleted{
 			experimentID: work.experimentID,
 			metricType:   work.metricType,
 			metricName:   work.metricName,
 			progress:     progress,
+			trials:       trials,
+			dataPoints:   dataPoints,
 			results:      results,
 		})
 	}
@@ -119,20 +123,49 @@ func taskHandlerFactory(db *db.PgDB, system *actor.System, growforest string, wo
 			sendWorkFailed(system, work, "invalid metric type received in hyperparameter importance worker")
 			return nil
 		}
+
+		// Results only change when there are new metrics, so reuse the stored ones if there
+		// are not.
+		numTrials, dataPoints := countTrialData(trials)
+		hpi, err := db.GetHPImportance(work.experimentID)
+		if err != nil {
+			sendWorkFailed(system, work, err.Error())
+			return nil
+		}
+		cached := hpi.GetMetricHPImportance(work.metricName, work.metricType)
+		if cached.Error == "" && cached.HpImportance != nil && cached.DataPoints == dataPoints {
+			sendWorkCompleted(system, work, progress, numTrials, dataPoints, cached.HpImportance)
+			return nil
+		}
+
 		taskDir := path.Join(workingDir, fmt.Sprint(actorId))
 		err = os.Mkdir(taskDir, 0o700)
 		if err != nil {
 			sendWorkFailed(system, work, err.Error())
+			return nil
 		}
 		results, err := computeHPImportance(trials, experimentConfig, masterConfig, growforest, taskDir)
-		if err != nil {
-			sendWorkFailed(system, work, err.Error())
+		if rmErr := os.RemoveAll(taskDir); rmErr != nil {
+			ctx.Log().WithError(rmErr).Errorf("Failed to clean up temporary directory %s", taskDir)
 		}
-		err = os.RemoveAll(taskDir)
 		if err != nil {
-			ctx.Log().WithError(err).Errorf("Failed to clean up temporary directory %s", taskDir)
+			sendWorkFailed(system, work, err.Error())
+			return nil
 		}
-		sendWorkCompleted(system, work, progress, results)
+		sendWorkCompleted(system, work, progress, numTrials, dataPoints, results)
 		return nil
 	}
 }
+
+// countTrialData returns the number of distinct trials in the input to the computation and the
+// number of data points they reported.
+func countTrialData(data map[int][]model.HPImportanceTrialData) (trials, dataPoints int) {
+	seen := map[int]bool{}
+	for _, batch := range data {
+		for _, d := range batch {
+			seen[d.TrialID] = true
+		}
+		dataPoints += len(batch)
+	}
+	return len(seen), dataPoints
+}
@@ -0,0 +1,111 @@
+package logforward
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// logSource identifies logs from Determined to the services they are forwarded to.
+const logSource = "determined"
+
+// encoder encodes a batch of task logs as the body of a request to an endpoint.
+type encoder func(conf config.LogForwarderConfig, logs []*model.TaskLog) ([]byte, error)
+
+var encoders = map[string]encoder{
+	config.LogForwarderSplunkHEC: encodeSplunkHEC,
+	config.LogForwarderDatadog:   encodeDatadog,
+	config.LogForwarderHTTP:      encodeHTTP,
+}
+
+type splunkHECEvent struct {
+	// Time is in seconds since the epoch, which HEC takes with a fractional part.
+	Time       float64        `json:"time"`
+	Host       string         `json:"host,omitempty"`
+	Source     string         `json:"source"`
+	SourceType string         `json:"sourcetype"`
+	Index      string         `json:"index,omitempty"`
+	Event      *model.TaskLog `json:"event"`
+}
+
+// encodeSplunkHEC encodes logs as a batch of HEC events, which are concatenated JSON objects.
+func encodeSplunkHEC(conf config.LogForwarderConfig, logs []*model.TaskLog) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, l := range logs {
+		e := splunkHECEvent{
+			Time:       float64(logTime(l).UnixNano()) / float64(time.Second),
+			Source:     logSource,
+			SourceType: "_json",
+			Index:      conf.Index,
+			Event:      l,
+		}
+		if l.AgentID != nil {
+			e.Host = *l.AgentID
+		}
+		if err := enc.Encode(e); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+type datadogLog struct {
+	Source   string `json:"ddsource"`
+	Service  string `json:"service"`
+	Hostname string `json:"hostname,omitempty"`
+	Tags     string `json:"ddtags,omitempty"`
+	Status   string `json:"status,omitempty"`
+	// Timestamp is in milliseconds since the epoch.
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+
+	TaskID       string  `json:"task_id"`
+	AllocationID *string `json:"allocation_id,omitempty"`
+	ContainerID  *string `json:"container_id,omitempty"`
+	RankID       *int    `json:"rank_id,omitempty"`
+	StdType      *string `json:"stdtype,omitempty"`
+}
+
+// encodeDatadog encodes logs as an array for the Datadog logs intake API.
+func encodeDatadog(conf config.LogForwarderConfig, logs []*model.TaskLog) ([]byte, error) {
+	out := make([]datadogLog, 0, len(logs))
+	for _, l := range logs {
+		d := datadogLog{
+			Source:       logSource,
+			Service:      logSource,
+			Tags:         strings.Join(append([]string{"task_id:" + l.TaskID}, conf.Tags...), ","),
+			Timestamp:    logTime(l).UnixMilli(),
+			Message:      strings.TrimSuffix(l.Log, "\n"),
+			TaskID:       l.TaskID,
+			AllocationID: l.AllocationID,
+			ContainerID:  l.ContainerID,
+			RankID:       l.RankID,
+			StdType:      l.StdType,
+		}
+		if l.AgentID != nil {
+			d.Hostname = *l.AgentID
+		}
+		if l.Level != nil {
+			d.Status = strings.ToLower(*l.Level)
+		}
+		out = append(out, d)
+	}
+	return json.Marshal(out)
+}
+
+// encodeHTTP encodes logs as an array of task logs, as they are returned by the master.
+func encodeHTTP(_ config.LogForwarderConfig, logs []*model.TaskLog) ([]byte, error) {
+	return json.Marshal(logs)
+}
+
+func logTime(l *model.TaskLog) time.Time {
+	if l.Timestamp == nil {
+		return time.Now()
+	}
+	return *l.Timestamp
+}
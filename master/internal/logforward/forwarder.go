@@ -0,0 +1,183 @@
+package logforward
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	back "github.com/cenkalti/backoff/v4"
+	"github.com/hashicorp/go-cleanhttp"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+const (
+	backoffInterval = time.Second
+	backoffMax      = 30 * time.Second
+	// requestTimeout bounds each attempt to send a batch.
+	requestTimeout = 30 * time.Second
+	// closeTimeout bounds the last attempt to send queued logs when the master shuts down.
+	closeTimeout = 10 * time.Second
+)
+
+type forwarder struct {
+	conf   config.LogForwarderConfig
+	encode encoder
+	cl     *http.Client
+	log    *log.Entry
+
+	logs    chan *model.TaskLog
+	dropped int64
+}
+
+func newForwarder(id int, conf config.LogForwarderConfig) *forwarder {
+	cl := cleanhttp.DefaultPooledClient()
+	cl.Timeout = requestTimeout
+	return &forwarder{
+		conf:   conf,
+		encode: encoders[conf.Type],
+		cl:     cl,
+		log: log.WithFields(log.Fields{
+			"component": "log-forwarder", "id": id, "type": conf.Type,
+		}),
+		logs: make(chan *model.TaskLog, conf.BufferSize),
+	}
+}
+
+// enqueue queues logs to be forwarded without blocking. Once the queue is full, because the
+// endpoint cannot keep up or is down, further logs are dropped until it drains.
+func (f *forwarder) enqueue(logs []*model.TaskLog) {
+	for _, l := range logs {
+		select {
+		case f.logs <- l:
+		default:
+			atomic.AddInt64(&f.dropped, 1)
+		}
+	}
+}
+
+func (f *forwarder) run(ctx context.Context) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			f.log.Errorf("uncaught error, log forwarder crashed: %v", rec)
+		}
+	}()
+
+	t := time.NewTicker(time.Duration(f.conf.FlushInterval))
+	defer t.Stop()
+
+	batch := make([]*model.TaskLog, 0, f.conf.BatchSize)
+	for {
+		select {
+		case l := <-f.logs:
+			batch = append(batch, l)
+			if len(batch) < f.conf.BatchSize {
+				continue
+			}
+		case <-t.C:
+		case <-ctx.Done():
+			f.drain(batch)
+			return
+		}
+
+		if err := f.ship(ctx, batch); err != nil && ctx.Err() == nil {
+			f.log.WithError(err).Errorf("dropping %d logs that could not be forwarded", len(batch))
+		}
+		batch = batch[:0]
+		if n := atomic.SwapInt64(&f.dropped, 0); n > 0 {
+			f.log.Warnf("dropped %d logs because the forwarding queue was full", n)
+		}
+	}
+}
+
+// drain makes a single attempt to send the logs still queued on shutdown.
+func (f *forwarder) drain(batch []*model.TaskLog) {
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+	for {
+		select {
+		case l := <-f.logs:
+			batch = append(batch, l)
+			if len(batch) < f.conf.BatchSize {
+				continue
+			}
+		default:
+		}
+
+		if len(batch) == 0 {
+			return
+		}
+		if err := f.send(ctx, batch); err != nil {
+			f.log.WithError(err).Errorf("dropping logs that could not be forwarded on shutdown")
+			return
+		}
+		batch = batch[:0]
+	}
+}
+
+// ship sends a batch, retrying failures that may be temporary with an exponential backoff.
+func (f *forwarder) ship(ctx context.Context, batch []*model.TaskLog) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	bf := back.NewExponentialBackOff()
+	bf.InitialInterval = backoffInterval
+	bf.MaxInterval = backoffMax
+	bf.MaxElapsedTime = 0
+	return back.Retry(
+		func() error { return f.send(ctx, batch) },
+		back.WithContext(back.WithMaxRetries(bf, uint64(f.conf.MaxRetries)), ctx),
+	)
+}
+
+func (f *forwarder) send(ctx context.Context, batch []*model.TaskLog) error {
+	body, err := f.encode(f.conf, batch)
+	if err != nil {
+		return back.Permanent(fmt.Errorf("encoding logs: %w", err))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.conf.URL, bytes.NewReader(body))
+	if err != nil {
+		return back.Permanent(fmt.Errorf("creating request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	switch {
+	case f.conf.Token == "":
+	case f.conf.Type == config.LogForwarderSplunkHEC:
+		req.Header.Set("Authorization", "Splunk "+f.conf.Token)
+	case f.conf.Type == config.LogForwarderDatadog:
+		req.Header.Set("DD-API-KEY", f.conf.Token)
+	default:
+		req.Header.Set("Authorization", "Bearer "+f.conf.Token)
+	}
+	for k, v := range f.conf.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := f.cl.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending logs: %w", err)
+	}
+	defer func() {
+		// Read the body to the end so the connection can be reused.
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		if err := resp.Body.Close(); err != nil {
+			f.log.WithError(err).Warn("failed to close response body")
+		}
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return fmt.Errorf("request returned %v", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return back.Permanent(fmt.Errorf("request returned %v", resp.StatusCode))
+	default:
+		return nil
+	}
+}
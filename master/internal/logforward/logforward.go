@@ -0,0 +1,57 @@
+// Package logforward ships task logs to external HTTP endpoints, such as a Splunk HTTP Event
+// Collector or Datadog, alongside the logging backend that stores them.
+package logforward
+
+import (
+	"context"
+	"sync"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/task"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// Backend is a task log backend that stores logs in another backend and forwards them to each of
+// the configured endpoints as well. Logs are forwarded asynchronously, so that an endpoint that
+// is slow or down never holds up storing logs or fails the request that added them.
+type Backend struct {
+	task.LogBackend
+
+	forwarders []*forwarder
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// New wraps a task log backend with forwarding to the given endpoints.
+func New(backend task.LogBackend, confs []config.LogForwarderConfig) *Backend {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &Backend{LogBackend: backend, cancel: cancel}
+	for i, conf := range confs {
+		f := newForwarder(i, conf)
+		b.forwarders = append(b.forwarders, f)
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			f.run(ctx)
+		}()
+	}
+	return b
+}
+
+// AddTaskLogs stores the logs in the wrapped backend, and then queues them to be forwarded.
+func (b *Backend) AddTaskLogs(logs []*model.TaskLog) error {
+	if err := b.LogBackend.AddTaskLogs(logs); err != nil {
+		return err
+	}
+	for _, f := range b.forwarders {
+		f.enqueue(logs)
+	}
+	return nil
+}
+
+// Close stops forwarding logs, making a last attempt to send those that are queued.
+func (b *Backend) Close() error {
+	b.cancel()
+	b.wg.Wait()
+	return nil
+}
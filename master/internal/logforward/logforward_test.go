@@ -0,0 +1,143 @@
+package logforward
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/task"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+)
+
+type memoryBackend struct {
+	task.LogBackend
+	logs []*model.TaskLog
+}
+
+func (b *memoryBackend) AddTaskLogs(logs []*model.TaskLog) error {
+	b.logs = append(b.logs, logs...)
+	return nil
+}
+
+func testLogs(n int) []*model.TaskLog {
+	ts := time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+	var logs []*model.TaskLog
+	for i := 0; i < n; i++ {
+		logs = append(logs, &model.TaskLog{
+			TaskID:    "task-1",
+			AgentID:   ptrs.Ptr("agent-1"),
+			Timestamp: &ts,
+			Level:     ptrs.Ptr(model.LogLevelInfo),
+			Log:       "hello\n",
+		})
+	}
+	return logs
+}
+
+func TestForwardSplunkHEC(t *testing.T) {
+	var mu sync.Mutex
+	var events []splunkHECEvent
+	attempts := 0
+	// Failing a test from the goroutine of the handler is not allowed, so errors are sent here.
+	errs := make(chan error, 10)
+	report := func(err error) {
+		select {
+		case errs <- err:
+		default: // Enough errors were reported already.
+		}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if auth := r.Header.Get("Authorization"); auth != "Splunk secret" {
+			report(fmt.Errorf("unexpected Authorization header %q", auth))
+		}
+		if extra := r.Header.Get("X-Extra"); extra != "yes" {
+			report(fmt.Errorf("unexpected X-Extra header %q", extra))
+		}
+
+		// Fail the first attempt to check that it is retried.
+		if attempts++; attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		dec := json.NewDecoder(bufio.NewReader(r.Body))
+		for dec.More() {
+			var e splunkHECEvent
+			if err := dec.Decode(&e); err != nil {
+				report(err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			events = append(events, e)
+		}
+	}))
+	defer srv.Close()
+
+	primary := &memoryBackend{}
+	b := New(primary, []config.LogForwarderConfig{{
+		Type:          config.LogForwarderSplunkHEC,
+		URL:           srv.URL,
+		Token:         "secret",
+		Headers:       map[string]string{"X-Extra": "yes"},
+		Index:         "ml",
+		BatchSize:     2,
+		FlushInterval: model.Duration(10 * time.Millisecond),
+		MaxRetries:    3,
+		BufferSize:    10,
+	}})
+	require.NoError(t, b.AddTaskLogs(testLogs(3)))
+	require.Len(t, primary.logs, 3)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(events) == 3 || len(errs) > 0
+	}, 10*time.Second, 10*time.Millisecond)
+	require.NoError(t, b.Close())
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	require.Len(t, events, 3)
+
+	e := events[0]
+	require.Equal(t, "agent-1", e.Host)
+	require.Equal(t, "ml", e.Index)
+	require.Equal(t, "task-1", e.Event.TaskID)
+	require.Equal(t, float64(time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC).Unix()), e.Time)
+}
+
+func TestForwardDropsWhenFull(t *testing.T) {
+	f := newForwarder(0, config.LogForwarderConfig{
+		Type:       config.LogForwarderHTTP,
+		BatchSize:  2,
+		BufferSize: 2,
+	})
+	f.enqueue(testLogs(5))
+	require.Len(t, f.logs, 2)
+	require.EqualValues(t, 3, f.dropped)
+}
+
+func TestEncodeDatadog(t *testing.T) {
+	body, err := encodeDatadog(config.LogForwarderConfig{Tags: []string{"env:prod"}}, testLogs(1))
+	require.NoError(t, err)
+
+	var logs []map[string]interface{}
+	require.NoError(t, json.NewDecoder(bytes.NewReader(body)).Decode(&logs))
+	require.Len(t, logs, 1)
+	require.Equal(t, "hello", logs[0]["message"])
+	require.Equal(t, "info", logs[0]["status"])
+	require.Equal(t, "agent-1", logs[0]["hostname"])
+	require.Equal(t, "task_id:task-1,env:prod", logs[0]["ddtags"])
+}
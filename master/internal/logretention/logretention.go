@@ -0,0 +1,288 @@
+// Package logretention deletes the logs of trials some time after they end, as set for each
+// workspace, optionally archiving them to checkpoint storage first. Logs are deleted a little at a
+// time during the off-peak hours of the cluster, and each run records how much space it
+// reclaimed.
+package logretention
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/objectstore"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+	"github.com/determined-ai/determined/proto/pkg/logretentionv1"
+)
+
+const (
+	// trialsPerBatch is how many trials are looked up at a time.
+	trialsPerBatch = 100
+	// rowsPerDelete is how many logs are deleted by each statement, so that no statement holds
+	// locks or bloats the write-ahead log for long.
+	rowsPerDelete = 10000
+)
+
+// expiredTrial is a trial whose logs have outlived the retention of its workspace.
+type expiredTrial struct {
+	ID     int          `bun:"id"`
+	TaskID model.TaskID `bun:"task_id"`
+}
+
+// logTable is a table that holds the logs of trials.
+type logTable struct {
+	name string
+	// key is the column that matches logs to the trial that keyOf returns.
+	key   string
+	keyOf func(expiredTrial) interface{}
+}
+
+// logTables are the tables that hold the logs of trials: trial_logs for trials from before logs
+// were stored by task, and task_logs since.
+var logTables = []logTable{
+	{name: "trial_logs", key: "trial_id", keyOf: func(t expiredTrial) interface{} { return t.ID }},
+	{name: "task_logs", key: "task_id", keyOf: func(t expiredTrial) interface{} { return t.TaskID }},
+}
+
+// Run corresponds to a row in the "log_retention_runs" DB table.
+type Run struct {
+	bun.BaseModel `bun:"table:log_retention_runs,alias:r"`
+
+	ID             int        `bun:"id,pk,autoincrement"`
+	StartedAt      time.Time  `bun:"started_at,nullzero,notnull,default:current_timestamp"`
+	EndedAt        *time.Time `bun:"ended_at"`
+	Trials         int        `bun:"trials"`
+	RowsDeleted    int64      `bun:"rows_deleted"`
+	BytesReclaimed int64      `bun:"bytes_reclaimed"`
+	Error          string     `bun:"error,nullzero"`
+}
+
+// Proto returns the protobuf representation of the run.
+func (r Run) Proto() *logretentionv1.LogRetentionRun {
+	pr := &logretentionv1.LogRetentionRun{
+		Id:             int32(r.ID),
+		StartedAt:      timestamppb.New(r.StartedAt),
+		Trials:         int32(r.Trials),
+		RowsDeleted:    r.RowsDeleted,
+		BytesReclaimed: r.BytesReclaimed,
+		Error:          r.Error,
+	}
+	if r.EndedAt != nil {
+		pr.EndedAt = timestamppb.New(*r.EndedAt)
+	}
+	return pr
+}
+
+// ListRuns returns the most recent runs, most recent first.
+func ListRuns(ctx context.Context, limit int) ([]Run, error) {
+	runs := []Run{}
+	if err := db.Bun().NewSelect().Model(&runs).Order("id DESC").Limit(limit).
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// expired returns up to trialsPerBatch trials after the given one that ended longer ago than the
+// retention of their workspace and still have logs in the database.
+func expired(ctx context.Context, defaultDays, after int) ([]expiredTrial, error) {
+	var trials []expiredTrial
+	err := db.Bun().NewSelect().
+		TableExpr("trials AS t").
+		Column("t.id", "t.task_id").
+		Join("JOIN experiments AS e ON e.id = t.experiment_id").
+		Join("JOIN projects AS p ON p.id = e.project_id").
+		Join("JOIN workspaces AS w ON w.id = p.workspace_id").
+		Where("t.id > ?", after).
+		Where("t.end_time IS NOT NULL").
+		Where("coalesce(w.log_retention_days, ?) > 0", defaultDays).
+		Where("t.end_time < now() - make_interval(days => coalesce(w.log_retention_days, ?))",
+			defaultDays).
+		Where("EXISTS (SELECT 1 FROM trial_logs AS l WHERE l.trial_id = t.id) OR "+
+			"EXISTS (SELECT 1 FROM task_logs AS l WHERE l.task_id = t.task_id)").
+		Order("t.id").
+		Limit(trialsPerBatch).
+		Scan(ctx, &trials)
+	return trials, err
+}
+
+// archive writes the logs of a trial in the table to storage as gzipped JSON lines. The key
+// includes the first log, so that the rest of the logs of a trial whose deletion was interrupted
+// are archived next to the ones that were deleted rather than over them.
+func (lt logTable) archive(ctx context.Context, s objectstore.Store, t expiredTrial) (err error) {
+	f, err := os.CreateTemp("", "trial-logs-*.jsonl.gz")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	rows, err := db.Bun().QueryContext(ctx,
+		"SELECT l.id, to_jsonb(l)::text FROM ? AS l WHERE ? = ? ORDER BY l.id",
+		bun.Ident(lt.name), bun.Ident("l."+lt.key), lt.keyOf(t))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cErr := rows.Close(); err == nil {
+			err = cErr
+		}
+	}()
+	buf := bufio.NewWriter(f)
+	zw := gzip.NewWriter(buf)
+	var firstID int64
+	for rows.Next() {
+		var id int64
+		var line string
+		if err := rows.Scan(&id, &line); err != nil {
+			return err
+		}
+		if firstID == 0 {
+			firstID = id
+		}
+		if _, err := zw.Write([]byte(line + "\n")); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	if firstID == 0 {
+		return nil
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return err
+	}
+	key := fmt.Sprintf("trial-logs/trial-%d/%s-from-%d.jsonl.gz", t.ID, lt.name, firstID)
+	return s.Upload(ctx, key, f)
+}
+
+// deleteSome deletes up to rowsPerDelete logs of a trial in the table, and returns how many it
+// deleted and how many bytes they took up.
+func (lt logTable) deleteSome(ctx context.Context, t expiredTrial) (rows, bytes int64, err error) {
+	err = db.Bun().NewRaw(`
+WITH deleted AS (
+  DELETE FROM ?0 AS l
+  WHERE l.id IN (SELECT id FROM ?0 WHERE ?1 = ?2 LIMIT ?3)
+  RETURNING pg_column_size(l.*) AS size
+)
+SELECT count(*), coalesce(sum(size), 0) FROM deleted`,
+		bun.Ident(lt.name), bun.Ident(lt.key), lt.keyOf(t), rowsPerDelete,
+	).Scan(ctx, &rows, &bytes)
+	return rows, bytes, err
+}
+
+// Enforce deletes the logs of trials that ended longer ago than the retention of their workspace,
+// or c.Days for workspaces that do not set one, until there are none left or the off-peak window
+// closes, archiving them to s first if it is set. It records the run if it found any logs to
+// delete.
+func Enforce(ctx context.Context, c config.LogRetentionConfig, s objectstore.Store) (err error) {
+	var r *Run
+	defer func() {
+		if r == nil {
+			return
+		}
+		r.EndedAt = ptrs.Ptr(time.Now())
+		if err != nil {
+			r.Error = err.Error()
+		}
+		if _, err := db.Bun().NewUpdate().Model(r).WherePK().Exec(ctx); err != nil {
+			log.WithError(err).Errorf("failed to record log retention run %d", r.ID)
+		}
+	}()
+
+	after := 0
+	for {
+		trials, err := expired(ctx, c.Days, after)
+		if err != nil {
+			return err
+		}
+		if len(trials) == 0 {
+			return nil
+		}
+		if r == nil {
+			r = &Run{}
+			if _, err := db.Bun().NewInsert().Model(r).Returning("*").Exec(ctx); err != nil {
+				r = nil
+				return err
+			}
+		}
+
+		for _, t := range trials {
+			// The logs of a trial are deleted all at once even if the window closes meanwhile,
+			// so that its archive is not split.
+			if !c.OffPeak(time.Now()) {
+				return nil
+			}
+			after = t.ID
+			for _, lt := range logTables {
+				if s != nil {
+					if err := lt.archive(ctx, s, t); err != nil {
+						return errors.Wrapf(err, "archiving the %s of trial %d", lt.name, t.ID)
+					}
+				}
+				for {
+					rows, bytes, err := lt.deleteSome(ctx, t)
+					if err != nil {
+						return errors.Wrapf(err, "deleting the %s of trial %d", lt.name, t.ID)
+					}
+					r.RowsDeleted += rows
+					r.BytesReclaimed += bytes
+					if rows < rowsPerDelete {
+						break
+					}
+				}
+			}
+			r.Trials++
+		}
+	}
+}
+
+// Loop enforces the retention of logs once an hour, during off-peak hours, until the context is
+// canceled. storage is where logs are archived if c.Archive is set.
+func Loop(
+	ctx context.Context, c config.LogRetentionConfig, storage expconf.CheckpointStorageConfig,
+) {
+	var s objectstore.Store
+	if c.Archive {
+		var err error
+		if s, err = objectstore.New(ctx, storage); err != nil {
+			log.WithError(err).Error("failed to access storage to archive logs, logs will be kept")
+			return
+		}
+	}
+
+	t := time.NewTicker(time.Hour)
+	defer t.Stop()
+	for {
+		if c.OffPeak(time.Now()) {
+			if err := Enforce(ctx, c, s); err != nil {
+				log.WithError(err).Error("failed to enforce log retention")
+			}
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
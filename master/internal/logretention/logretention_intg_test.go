@@ -0,0 +1,94 @@
+//go:build integration
+// +build integration
+
+package logretention
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/objectstore"
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+)
+
+func TestEnforce(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(db.RootFromDB))
+	pgDB := db.MustResolveTestPostgres(t)
+	db.MustMigrateTestPostgres(t, pgDB, db.MigrationsFromDB)
+	ctx := context.Background()
+
+	user := db.RequireMockUser(t, pgDB)
+	old := db.RequireMockTrial(t, pgDB, db.RequireMockExperiment(t, pgDB, user))
+	running := db.RequireMockTrial(t, pgDB, db.RequireMockExperiment(t, pgDB, user))
+	for _, tr := range []*model.Trial{old, running} {
+		require.NoError(t, pgDB.AddTaskLogs([]*model.TaskLog{
+			{TaskID: string(tr.TaskID), Log: "hello\n", Timestamp: ptrs.Ptr(time.Now())},
+		}))
+	}
+	_, err := db.Bun().NewUpdate().Table("trials").
+		Set("end_time = now() - interval '40 days'").
+		Where("id = ?", old.ID).
+		Exec(ctx)
+	require.NoError(t, err)
+
+	var workspaceID int
+	require.NoError(t, db.Bun().NewRaw(`
+SELECT p.workspace_id FROM experiments e JOIN projects p ON p.id = e.project_id WHERE e.id = ?`,
+		old.ExperimentID).Scan(ctx, &workspaceID))
+	setRetention := func(days *int) {
+		_, err := db.Bun().NewUpdate().Table("workspaces").
+			Set("log_retention_days = ?", days).
+			Where("id = ?", workspaceID).
+			Exec(ctx)
+		require.NoError(t, err)
+	}
+	defer setRetention(nil)
+
+	logs := func(tr *model.Trial) int {
+		n, err := db.Bun().NewSelect().Table("task_logs").
+			Where("task_id = ?", tr.TaskID).
+			Count(ctx)
+		require.NoError(t, err)
+		return n
+	}
+
+	// Logs are kept forever unless a retention is set.
+	require.NoError(t, Enforce(ctx, config.LogRetentionConfig{}, nil))
+	require.Equal(t, 1, logs(old))
+
+	dir := t.TempDir()
+	s, err := objectstore.New(ctx, expconf.CheckpointStorageConfig{
+		RawSharedFSConfig: &expconf.SharedFSConfig{RawHostPath: ptrs.Ptr(dir)},
+	})
+	require.NoError(t, err)
+	setRetention(ptrs.Ptr(30))
+	require.NoError(t, Enforce(ctx, config.LogRetentionConfig{Days: 365}, s))
+	require.Equal(t, 0, logs(old))
+	require.Equal(t, 1, logs(running))
+
+	archived, err := filepath.Glob(filepath.Join(dir, "trial-logs", "trial-*", "task_logs-*"))
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+	info, err := os.Stat(archived[0])
+	require.NoError(t, err)
+	require.NotZero(t, info.Size())
+
+	runs, err := ListRuns(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.GreaterOrEqual(t, runs[0].Trials, 1)
+	require.GreaterOrEqual(t, runs[0].RowsDeleted, int64(1))
+	require.Positive(t, runs[0].BytesReclaimed)
+	require.NotNil(t, runs[0].EndedAt)
+	require.Empty(t, runs[0].Error)
+}
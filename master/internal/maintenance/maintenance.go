@@ -0,0 +1,186 @@
+// Package maintenance keeps the maintenance mode of the cluster, in which new work is accepted but
+// not scheduled so that admins can upgrade or repair the cluster without failing the submissions
+// of users, and the message shown to all users of the cluster, such as a notice of maintenance.
+package maintenance
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+var (
+	enabled int32
+
+	// message is the latest cluster message, which is kept in memory since every user of the
+	// WebUI asks for it.
+	messageMu sync.RWMutex
+	message   *ClusterMessage
+)
+
+// Enabled returns whether the cluster is in maintenance mode. Resource managers hold back new
+// allocations while it is.
+func Enabled() bool {
+	return atomic.LoadInt32(&enabled) == 1
+}
+
+func setEnabled(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&enabled, v)
+}
+
+// Mode is the maintenance mode of the cluster.
+type Mode struct {
+	bun.BaseModel `bun:"table:maintenance_mode"`
+
+	ID      bool `bun:"id,pk"`
+	Enabled bool `bun:"enabled"`
+	// DrainedAgents are the agents that were drained when maintenance mode was enabled, to enable
+	// again when it is disabled.
+	DrainedAgents []string      `bun:"drained_agents,array"`
+	UpdatedTime   time.Time     `bun:"updated_time"`
+	UpdatedBy     *model.UserID `bun:"updated_by"`
+}
+
+// Proto returns the protobuf representation of the maintenance mode.
+func (m Mode) Proto() *apiv1.MaintenanceMode {
+	return &apiv1.MaintenanceMode{
+		Enabled:       m.Enabled,
+		DrainedAgents: append([]string{}, m.DrainedAgents...),
+		UpdatedTime:   timestamppb.New(m.UpdatedTime),
+	}
+}
+
+// ClusterMessage is a message shown to all users of the cluster.
+type ClusterMessage struct {
+	bun.BaseModel `bun:"table:cluster_messages"`
+
+	ID          int           `bun:"id,pk,autoincrement"`
+	Message     string        `bun:"message"`
+	StartTime   time.Time     `bun:"start_time"`
+	EndTime     *time.Time    `bun:"end_time"`
+	CreatedTime time.Time     `bun:"created_time,nullzero,notnull,default:current_timestamp"`
+	CreatedBy   *model.UserID `bun:"created_by"`
+}
+
+// Proto returns the protobuf representation of the cluster message.
+func (c ClusterMessage) Proto() *apiv1.ClusterMessage {
+	pc := &apiv1.ClusterMessage{
+		Message:     c.Message,
+		StartTime:   timestamppb.New(c.StartTime),
+		CreatedTime: timestamppb.New(c.CreatedTime),
+	}
+	if c.EndTime != nil {
+		pc.EndTime = timestamppb.New(*c.EndTime)
+	}
+	return pc
+}
+
+func (c ClusterMessage) shownAt(t time.Time) bool {
+	return !t.Before(c.StartTime) && (c.EndTime == nil || t.Before(*c.EndTime))
+}
+
+// Load reads the maintenance mode and the cluster message from the database, so that both last
+// across restarts of the master.
+func Load(ctx context.Context) error {
+	m, err := Get(ctx)
+	if err != nil {
+		return err
+	}
+	setEnabled(m.Enabled)
+
+	var latest []ClusterMessage
+	if err := db.Bun().NewSelect().Model(&latest).
+		Order("id DESC").Limit(1).Scan(ctx); err != nil {
+		return errors.Wrap(err, "error reading the cluster message")
+	}
+	messageMu.Lock()
+	defer messageMu.Unlock()
+	message = nil
+	if len(latest) > 0 {
+		message = &latest[0]
+	}
+	return nil
+}
+
+// Get returns the maintenance mode of the cluster.
+func Get(ctx context.Context) (*Mode, error) {
+	var m Mode
+	if err := db.Bun().NewSelect().Model(&m).Scan(ctx); err != nil {
+		return nil, errors.Wrap(err, "error reading the maintenance mode")
+	}
+	return &m, nil
+}
+
+// Set stores the maintenance mode of the cluster and puts it into effect.
+func Set(ctx context.Context, m *Mode) error {
+	m.ID = true
+	if m.DrainedAgents == nil {
+		m.DrainedAgents = []string{}
+	}
+	if _, err := db.Bun().NewUpdate().Model(m).WherePK().Exec(ctx); err != nil {
+		return errors.Wrap(err, "error updating the maintenance mode")
+	}
+	setEnabled(m.Enabled)
+	return nil
+}
+
+// CurrentMessage returns the cluster message that is shown now, if any.
+func CurrentMessage() *ClusterMessage {
+	messageMu.RLock()
+	defer messageMu.RUnlock()
+	if message == nil || !message.shownAt(time.Now()) {
+		return nil
+	}
+	c := *message
+	return &c
+}
+
+// SetMessage replaces the cluster message. Past messages are kept, ended, for reference.
+func SetMessage(ctx context.Context, c *ClusterMessage) error {
+	messageMu.Lock()
+	defer messageMu.Unlock()
+	err := db.Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := endMessages(ctx, tx); err != nil {
+			return err
+		}
+		_, err := tx.NewInsert().Model(c).Returning("*").Exec(ctx)
+		return errors.Wrap(err, "error inserting the cluster message")
+	})
+	if err != nil {
+		return err
+	}
+	message = c
+	return nil
+}
+
+// ClearMessage stops showing the cluster message.
+func ClearMessage(ctx context.Context) error {
+	messageMu.Lock()
+	defer messageMu.Unlock()
+	if err := endMessages(ctx, db.Bun()); err != nil {
+		return err
+	}
+	message = nil
+	return nil
+}
+
+func endMessages(ctx context.Context, idb bun.IDB) error {
+	_, err := idb.NewUpdate().Table("cluster_messages").
+		Set("end_time = now()").
+		Where("end_time IS NULL OR end_time > now()").
+		Exec(ctx)
+	return errors.Wrap(err, "error ending the cluster message")
+}
@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/determined-ai/determined/master/internal/webhooks"
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+	"github.com/determined-ai/determined/proto/pkg/trialv1"
+)
+
+// metricValue returns the number a reported metric holds. Values that JSON has no numbers for are
+// reported as the strings "NaN", "Infinity" and "-Infinity".
+func metricValue(v *structpb.Value) (float64, bool) {
+	switch v := v.GetKind().(type) {
+	case *structpb.Value_NumberValue:
+		return v.NumberValue, true
+	case *structpb.Value_StringValue:
+		switch v.StringValue {
+		case "NaN", "Infinity", "-Infinity":
+			f, err := strconv.ParseFloat(v.StringValue, 64)
+			return f, err == nil
+		}
+	}
+	return 0, false
+}
+
+// firedAlerts returns the alerts that metrics reported by a trial in a group set off, with the
+// trial ID left for the caller to fill in.
+func firedAlerts(
+	alerts expconf.AlertsConfig, group string, m *trialv1.TrialMetrics,
+) []model.TrialAlert {
+	var fired []model.TrialAlert
+	for _, a := range alerts {
+		if a.Group() != group || int(m.StepsCompleted) < a.AfterBatches() {
+			continue
+		}
+		v, ok := metricValue(m.Metrics.GetAvgMetrics().GetFields()[a.Metric()])
+		if !ok {
+			continue
+		}
+		// NaN compares false against both thresholds, so it only fires alerts that ask for it.
+		if (a.NaN() && (math.IsNaN(v) || math.IsInf(v, 0))) ||
+			(a.Above() != nil && v > *a.Above()) ||
+			(a.Below() != nil && v < *a.Below()) {
+			fired = append(fired, model.TrialAlert{
+				Name:         a.Name(),
+				MetricGroup:  group,
+				Metric:       a.Metric(),
+				Value:        v,
+				TotalBatches: int(m.StepsCompleted),
+				FiredAt:      time.Now(),
+			})
+		}
+	}
+	return fired
+}
+
+// alertValue returns a metric value in a form that can be encoded as JSON.
+func alertValue(v float64) interface{} {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return v
+}
+
+// checkAlerts evaluates the alerts of the trial's experiment against metrics it reported in a
+// group. The first time an alert fires for the trial, it is sent to the webhooks that listen for
+// it and, if the alert says to, the experiment is paused.
+func (m *Master) checkAlerts(
+	ctx context.Context, group string, metrics *trialv1.TrialMetrics,
+) error {
+	experimentID, err := m.db.ExperimentIDByTrialID(int(metrics.TrialId))
+	if err != nil {
+		return err
+	}
+	config, err := m.db.ExperimentConfig(experimentID)
+	if err != nil {
+		return err
+	}
+	fired := firedAlerts(config.Alerts(), group, metrics)
+	if len(fired) == 0 {
+		return nil
+	}
+	e, err := m.db.ExperimentByID(experimentID)
+	if err != nil {
+		return err
+	}
+
+	pause := map[string]bool{}
+	for _, a := range config.Alerts() {
+		pause[a.Name()] = a.PauseExperiment()
+	}
+	for i := range fired {
+		a := &fired[i]
+		a.TrialID = int(metrics.TrialId)
+		if ok, err := m.db.AddTrialAlert(ctx, a); err != nil {
+			return err
+		} else if !ok {
+			continue
+		}
+
+		log.Warnf("alert %s fired for trial %d of experiment %d: %s metric %s was %v "+
+			"after %d batches", a.Name, a.TrialID, experimentID, a.MetricGroup, a.Metric, a.Value,
+			a.TotalBatches)
+		if err := webhooks.ReportMetricThresholdExceeded(ctx, *e, webhooks.AlertPayload{
+			Name:            a.Name,
+			ExperimentID:    experimentID,
+			TrialID:         a.TrialID,
+			MetricGroup:     a.MetricGroup,
+			Metric:          a.Metric,
+			Value:           alertValue(a.Value),
+			TotalBatches:    a.TotalBatches,
+			PauseExperiment: pause[a.Name],
+		}); err != nil {
+			log.WithError(err).Error("failed to send metric threshold exceeded webhook")
+		}
+		if pause[a.Name] {
+			m.system.TellAt(actor.Addr("experiments", experimentID), model.StateWithReason{
+				State:               model.PausedState,
+				InformationalReason: "alert " + a.Name + " fired",
+			})
+		}
+	}
+	return nil
+}
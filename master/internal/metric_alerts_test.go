@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/master/pkg/schemas"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+	"github.com/determined-ai/determined/proto/pkg/commonv1"
+	"github.com/determined-ai/determined/proto/pkg/trialv1"
+)
+
+func TestFiredAlerts(t *testing.T) {
+	alerts := schemas.WithDefaults(expconf.AlertsConfig{
+		{RawName: "diverged", RawMetric: "loss", RawAbove: ptrs.Ptr(10.0), RawNaN: ptrs.Ptr(true)},
+		{
+			RawName:         "grad norm",
+			RawMetric:       "grad_norm",
+			RawGroup:        ptrs.Ptr(model.TrainingMetricGroup),
+			RawNaN:          ptrs.Ptr(true),
+			RawAfterBatches: ptrs.Ptr(100),
+		},
+		{RawName: "inaccurate", RawMetric: "accuracy", RawBelow: ptrs.Ptr(0.5)},
+	}).(expconf.AlertsConfig)
+
+	metrics := func(steps int32, values map[string]*structpb.Value) *trialv1.TrialMetrics {
+		return &trialv1.TrialMetrics{
+			StepsCompleted: steps,
+			Metrics: &commonv1.Metrics{
+				AvgMetrics: &structpb.Struct{Fields: values},
+			},
+		}
+	}
+	names := func(fired []model.TrialAlert) []string {
+		var out []string
+		for _, a := range fired {
+			out = append(out, a.Name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		group   string
+		metrics *trialv1.TrialMetrics
+		fired   []string
+	}{
+		{
+			name:  "within thresholds",
+			group: model.ValidationMetricGroup,
+			metrics: metrics(10, map[string]*structpb.Value{
+				"loss":     structpb.NewNumberValue(1),
+				"accuracy": structpb.NewNumberValue(0.9),
+			}),
+		},
+		{
+			name:  "crossed thresholds",
+			group: model.ValidationMetricGroup,
+			metrics: metrics(10, map[string]*structpb.Value{
+				"loss":     structpb.NewNumberValue(11),
+				"accuracy": structpb.NewNumberValue(0.1),
+			}),
+			fired: []string{"diverged", "inaccurate"},
+		},
+		{
+			name:  "NaN as a string",
+			group: model.ValidationMetricGroup,
+			metrics: metrics(10, map[string]*structpb.Value{
+				"loss":     structpb.NewStringValue("NaN"),
+				"accuracy": structpb.NewStringValue("NaN"),
+			}),
+			fired: []string{"diverged"},
+		},
+		{
+			name:  "other group",
+			group: model.TrainingMetricGroup,
+			metrics: metrics(100, map[string]*structpb.Value{
+				"loss":      structpb.NewNumberValue(11),
+				"grad_norm": structpb.NewNumberValue(math.Inf(1)),
+			}),
+			fired: []string{"grad norm"},
+		},
+		{
+			name:  "before the alert applies",
+			group: model.TrainingMetricGroup,
+			metrics: metrics(99, map[string]*structpb.Value{
+				"grad_norm": structpb.NewNumberValue(math.NaN()),
+			}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.fired, names(firedAlerts(alerts, tt.group, tt.metrics)))
+		})
+	}
+
+	fired := firedAlerts(alerts, model.ValidationMetricGroup, metrics(10, map[string]*structpb.Value{
+		"loss": structpb.NewNumberValue(11),
+	}))
+	require.Len(t, fired, 1)
+	require.Equal(t, model.TrialAlert{
+		Name:         "diverged",
+		MetricGroup:  model.ValidationMetricGroup,
+		Metric:       "loss",
+		Value:        11,
+		TotalBatches: 10,
+		FiredAt:      fired[0].FiredAt,
+	}, fired[0])
+}
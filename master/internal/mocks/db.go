@@ -126,6 +126,20 @@ func (_m *DB) AddTrial(trial *model.Trial) error {
 	return r0
 }
 
+// AddTrialRunState provides a mock function with given fields: s
+func (_m *DB) AddTrialRunState(s *model.TrialRunState) error {
+	ret := _m.Called(s)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.TrialRunState) error); ok {
+		r0 = rf(s)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // AddUser provides a mock function with given fields: user, ug
 func (_m *DB) AddUser(user *model.User, ug *model.AgentUserGroup) (model.UserID, error) {
 	ret := _m.Called(user, ug)
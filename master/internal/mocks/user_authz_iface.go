@@ -127,6 +127,20 @@ func (_m *UserAuthZ) CanGetUsersOwnSettings(ctx context.Context, curUser model.U
 	return r0
 }
 
+// CanManageUsersAccessTokens provides a mock function with given fields: ctx, curUser, targetUser
+func (_m *UserAuthZ) CanManageUsersAccessTokens(ctx context.Context, curUser model.User, targetUser model.User) error {
+	ret := _m.Called(ctx, curUser, targetUser)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.User, model.User) error); ok {
+		r0 = rf(ctx, curUser, targetUser)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CanResetUsersOwnSettings provides a mock function with given fields: ctx, curUser
 func (_m *UserAuthZ) CanResetUsersOwnSettings(ctx context.Context, curUser model.User) error {
 	ret := _m.Called(ctx, curUser)
@@ -152,6 +152,20 @@ func (_m *WorkspaceAuthZ) CanSetWorkspacesCheckpointStorageConfig(ctx context.Co
 	return r0
 }
 
+// CanSetWorkspacesLogRetention provides a mock function with given fields: ctx, curUser, _a2
+func (_m *WorkspaceAuthZ) CanSetWorkspacesLogRetention(ctx context.Context, curUser model.User, _a2 *workspacev1.Workspace) error {
+	ret := _m.Called(ctx, curUser, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.User, *workspacev1.Workspace) error); ok {
+		r0 = rf(ctx, curUser, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CanSetWorkspacesName provides a mock function with given fields: ctx, curUser, _a2
 func (_m *WorkspaceAuthZ) CanSetWorkspacesName(ctx context.Context, curUser model.User, _a2 *workspacev1.Workspace) error {
 	ret := _m.Called(ctx, curUser, _a2)
@@ -0,0 +1,215 @@
+// Package objectstore reads and writes files that the master itself stores in checkpoint storage,
+// such as cold archives and backups of the database.
+package objectstore
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+
+	s3checkpoints "github.com/determined-ai/determined/master/pkg/checkpoints/s3"
+	"github.com/determined-ai/determined/master/pkg/schemas"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+)
+
+// Store is checkpoint storage that the master accesses directly.
+type Store interface {
+	// Upload writes the contents of the file to the key.
+	Upload(ctx context.Context, key string, f *os.File) error
+	// Download writes the contents of the key to the file.
+	Download(ctx context.Context, key string, f *os.File) error
+	// Exists returns whether there is a file at the key.
+	Exists(ctx context.Context, key string) (bool, error)
+	// Delete removes the file at the key, if there is one.
+	Delete(ctx context.Context, key string) error
+	// Check returns an error if the storage cannot be reached.
+	Check(ctx context.Context) error
+}
+
+// ErrUnsupported is returned for checkpoint storage that the master cannot access.
+var ErrUnsupported = errors.New("the master can only access s3 and shared_fs checkpoint storage")
+
+// New returns the store for a checkpoint storage config. Only S3 and shared_fs storage are
+// supported, since those are the ones the master can reach without running a task.
+func New(ctx context.Context, config expconf.CheckpointStorageConfig) (Store, error) {
+	config = schemas.WithDefaults(config).(expconf.CheckpointStorageConfig)
+	switch storage := config.GetUnionMember().(type) {
+	case expconf.S3Config:
+		return newS3Store(ctx, storage)
+	case expconf.SharedFSConfig:
+		root := storage.HostPath()
+		if storage.StoragePath() != nil {
+			if filepath.IsAbs(*storage.StoragePath()) {
+				root = *storage.StoragePath()
+			} else {
+				root = filepath.Join(root, *storage.StoragePath())
+			}
+		}
+		return &sharedFSStore{root: root}, nil
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+type sharedFSStore struct {
+	root string
+}
+
+func (s *sharedFSStore) Upload(_ context.Context, key string, f *os.File) error {
+	dst := filepath.Join(s.root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return err
+	}
+	// Write to a temporary file first so that an interrupted upload never replaces a file.
+	tmp, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := io.Copy(tmp, f); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+func (s *sharedFSStore) Download(_ context.Context, key string, f *os.File) error {
+	src, err := os.Open(filepath.Join(s.root, filepath.FromSlash(key)))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+	_, err = io.Copy(f, src)
+	return err
+}
+
+func (s *sharedFSStore) Exists(_ context.Context, key string) (bool, error) {
+	_, err := os.Stat(filepath.Join(s.root, filepath.FromSlash(key)))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	case err != nil:
+		return false, err
+	default:
+		return true, nil
+	}
+}
+
+func (s *sharedFSStore) Delete(_ context.Context, key string) error {
+	err := os.Remove(filepath.Join(s.root, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *sharedFSStore) Check(context.Context) error {
+	info, err := os.Stat(s.root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return errors.Errorf("%s is not a directory", s.root)
+	}
+	return nil
+}
+
+type s3Store struct {
+	sess   *session.Session
+	bucket string
+	prefix string
+}
+
+func newS3Store(ctx context.Context, storage expconf.S3Config) (*s3Store, error) {
+	config := &aws.Config{}
+	if storage.AccessKey() != nil && storage.SecretKey() != nil {
+		config.Credentials = credentials.NewStaticCredentials(
+			*storage.AccessKey(), *storage.SecretKey(), "")
+	}
+	if storage.EndpointURL() != nil {
+		config.Endpoint = storage.EndpointURL()
+		config.Region = aws.String("us-east-1")
+		config.S3ForcePathStyle = aws.Bool(true)
+	} else {
+		region, err := s3checkpoints.GetS3BucketRegion(ctx, storage.Bucket())
+		if err != nil {
+			return nil, err
+		}
+		config.Region = &region
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+	s := &s3Store{sess: sess, bucket: storage.Bucket()}
+	if storage.Prefix() != nil {
+		s.prefix = *storage.Prefix()
+	}
+	return s, nil
+}
+
+func (s *s3Store) key(key string) *string {
+	return aws.String(strings.TrimLeft(path.Join(s.prefix, key), "/"))
+}
+
+func (s *s3Store) Upload(ctx context.Context, key string, f *os.File) error {
+	_, err := s3manager.NewUploader(s.sess).UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: &s.bucket,
+		Key:    s.key(key),
+		Body:   f,
+	})
+	return err
+}
+
+func (s *s3Store) Download(ctx context.Context, key string, f *os.File) error {
+	_, err := s3manager.NewDownloader(s.sess).DownloadWithContext(ctx, f, &s3.GetObjectInput{
+		Bucket: &s.bucket,
+		Key:    s.key(key),
+	})
+	return err
+}
+
+func (s *s3Store) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s3.New(s.sess).HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: &s.bucket,
+		Key:    s.key(key),
+	})
+	var reqErr awserr.RequestFailure
+	switch {
+	case errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusNotFound:
+		return false, nil
+	case err != nil:
+		return false, err
+	default:
+		return true, nil
+	}
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	_, err := s3.New(s.sess).DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: &s.bucket,
+		Key:    s.key(key),
+	})
+	return err
+}
+
+func (s *s3Store) Check(ctx context.Context) error {
+	_, err := s3.New(s.sess).HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: &s.bucket})
+	return err
+}
@@ -0,0 +1,370 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/internal/auditlog"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/usergroup"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// scimGroup is the SCIM representation of a group.
+type scimGroup struct {
+	Schemas     []string      `json:"schemas"`
+	ID          string        `json:"id,omitempty"`
+	DisplayName string        `json:"displayName"`
+	Members     []groupMember `json:"members"`
+	Meta        *meta         `json:"meta,omitempty"`
+}
+
+// groupMember is a member of a group, identified by the ID of the user in its value.
+type groupMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+func toSCIMGroup(c echo.Context, g usergroup.Group, users []model.User) scimGroup {
+	members := make([]groupMember, 0, len(users))
+	for _, u := range users {
+		members = append(members, groupMember{Value: strconv.Itoa(int(u.ID)), Display: u.Username})
+	}
+	return scimGroup{
+		Schemas:     []string{groupSchema},
+		ID:          strconv.Itoa(g.ID),
+		DisplayName: g.Name,
+		Members:     members,
+		Meta:        newMeta(c, "Group", "Groups", g.ID),
+	}
+}
+
+func (s *service) listGroups(c echo.Context) (int, interface{}, error) {
+	name, err := parseFilter(c.QueryParam("filter"), "displayName")
+	if err != nil {
+		return 0, nil, err
+	}
+	offset, limit, err := page(c)
+	if err != nil {
+		return 0, nil, err
+	}
+	var groupName string
+	if name != nil {
+		groupName = *name
+	}
+
+	ctx := c.Request().Context()
+	groups, _, total, err := usergroup.SearchGroupsWithoutPersonalGroups(
+		ctx, groupName, 0, offset, limit)
+	if err != nil {
+		return 0, nil, err
+	}
+	resources := make([]scimGroup, 0, len(groups))
+	for _, g := range groups {
+		users, err := usergroup.UsersInGroupTx(ctx, nil, g.ID)
+		if err != nil {
+			return 0, nil, err
+		}
+		resources = append(resources, toSCIMGroup(c, g, users))
+	}
+	return http.StatusOK, listResponse{
+		Schemas:      []string{listSchema},
+		TotalResults: total,
+		StartIndex:   offset + 1,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, nil
+}
+
+func (s *service) getGroup(c echo.Context) (int, interface{}, error) {
+	g, users, err := groupByID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, toSCIMGroup(c, g, users), nil
+}
+
+func (s *service) createGroup(c echo.Context) (int, interface{}, error) {
+	var req scimGroup
+	if err := decode(c, &req); err != nil {
+		return 0, nil, err
+	}
+	if req.DisplayName == "" {
+		return 0, nil, newError(http.StatusBadRequest, "invalidValue", "displayName is required")
+	}
+	uids, err := memberIDs(req.Members)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	g, users, err := usergroup.AddGroupWithMembers(
+		c.Request().Context(), usergroup.Group{Name: req.DisplayName}, uids...)
+	switch {
+	case errors.Is(err, db.ErrDuplicateRecord):
+		return 0, nil, newError(http.StatusConflict, "uniqueness",
+			"group %s already exists", req.DisplayName)
+	case errors.Is(err, db.ErrNotFound):
+		return 0, nil, newError(http.StatusBadRequest, "invalidValue", "%s", err)
+	case err != nil:
+		return 0, nil, err
+	}
+	recordGroupChange(c, auditlog.GroupCreate, g.ID, map[string]interface{}{
+		"name": g.Name, "add_users": uids,
+	})
+	return http.StatusCreated, toSCIMGroup(c, g, users), nil
+}
+
+func (s *service) replaceGroup(c echo.Context) (int, interface{}, error) {
+	g, current, err := groupByID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+	var req scimGroup
+	if err := decode(c, &req); err != nil {
+		return 0, nil, err
+	}
+	if req.DisplayName == "" {
+		return 0, nil, newError(http.StatusBadRequest, "invalidValue", "displayName is required")
+	}
+	uids, err := memberIDs(req.Members)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	members := newMemberSet(current)
+	members.replace(uids)
+	return s.updateGroup(c, g, req.DisplayName, members)
+}
+
+func (s *service) patchGroup(c echo.Context) (int, interface{}, error) {
+	g, current, err := groupByID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+	var req patchRequest
+	if err := decode(c, &req); err != nil {
+		return 0, nil, err
+	}
+
+	name := g.Name
+	members := newMemberSet(current)
+	for _, op := range req.Operations {
+		if err := applyGroupOperation(&name, members, op); err != nil {
+			return 0, nil, err
+		}
+	}
+	return s.updateGroup(c, g, name, members)
+}
+
+var memberFilterPattern = regexp.MustCompile(`^(?i:members)\[(.*)\]$`)
+
+// applyGroupOperation applies a patch operation to the name and members of a group.
+func applyGroupOperation(name *string, members *memberSet, op patchOperation) error {
+	if strings.EqualFold(op.Op, "remove") {
+		if m := memberFilterPattern.FindStringSubmatch(op.Path); m != nil {
+			value, err := parseFilter(m[1], "value")
+			if err != nil {
+				return err
+			}
+			id, err := strconv.Atoi(*value)
+			if err != nil {
+				return newError(http.StatusBadRequest, "invalidValue", "invalid member %q", *value)
+			}
+			members.remove([]model.UserID{model.UserID(id)})
+			return nil
+		}
+		if !strings.EqualFold(op.Path, "members") {
+			return newError(http.StatusBadRequest, "noTarget", "cannot remove %q", op.Path)
+		}
+		if len(op.Value) == 0 {
+			members.replace(nil)
+			return nil
+		}
+		uids, err := parseMembers(op.Value)
+		if err != nil {
+			return err
+		}
+		members.remove(uids)
+		return nil
+	}
+
+	isAdd := strings.EqualFold(op.Op, "add")
+	if !isAdd && !strings.EqualFold(op.Op, "replace") {
+		return newError(http.StatusBadRequest, "invalidSyntax", "unknown operation %q", op.Op)
+	}
+	attrs, err := op.attributes()
+	if err != nil {
+		return err
+	}
+	for path, value := range attrs {
+		switch strings.ToLower(path) {
+		case "displayname":
+			if err := json.Unmarshal(value, name); err != nil || *name == "" {
+				return newError(http.StatusBadRequest, "invalidValue", "invalid displayName")
+			}
+		case "members":
+			uids, err := parseMembers(value)
+			if err != nil {
+				return err
+			}
+			if isAdd {
+				members.add(uids)
+			} else {
+				members.replace(uids)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *service) updateGroup(
+	c echo.Context, g usergroup.Group, name string, members *memberSet,
+) (int, interface{}, error) {
+	users, newName, err := usergroup.UpdateGroupAndMembers(
+		c.Request().Context(), g.ID, name, members.added(), members.removed())
+	switch {
+	case errors.Is(err, db.ErrDuplicateRecord):
+		return 0, nil, newError(http.StatusConflict, "uniqueness", "group %s already exists", name)
+	case errors.Is(err, db.ErrNotFound):
+		return 0, nil, newError(http.StatusBadRequest, "invalidValue", "%s", err)
+	case err != nil:
+		return 0, nil, err
+	}
+	recordGroupChange(c, auditlog.GroupUpdate, g.ID, map[string]interface{}{
+		"name": newName, "add_users": members.added(), "remove_users": members.removed(),
+	})
+	g.Name = newName
+	return http.StatusOK, toSCIMGroup(c, g, users), nil
+}
+
+func (s *service) deleteGroup(c echo.Context) (int, interface{}, error) {
+	id, err := parseID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+	if _, err := usergroup.GroupByIDTx(c.Request().Context(), nil, id); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return 0, nil, newError(http.StatusNotFound, "", "group %d not found", id)
+		}
+		return 0, nil, err
+	}
+	if err := usergroup.DeleteGroup(c.Request().Context(), id); err != nil {
+		return 0, nil, err
+	}
+	recordGroupChange(c, auditlog.GroupDelete, id, nil)
+	return http.StatusNoContent, nil, nil
+}
+
+// recordGroupChange records a change the identity provider made to a group in the audit log.
+func recordGroupChange(
+	c echo.Context, action auditlog.Action, id int, details map[string]interface{},
+) {
+	auditlog.Record(c.Request().Context(), nil, auditlog.Entry{
+		Username:   "scim",
+		Action:     action,
+		TargetType: "group",
+		TargetID:   strconv.Itoa(id),
+		Success:    true,
+		RemoteIP:   c.RealIP(),
+		Details:    details,
+	})
+}
+
+func groupByID(c echo.Context) (usergroup.Group, []model.User, error) {
+	id, err := parseID(c)
+	if err != nil {
+		return usergroup.Group{}, nil, err
+	}
+	ctx := c.Request().Context()
+	g, err := usergroup.GroupByIDTx(ctx, nil, id)
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return usergroup.Group{}, nil, newError(http.StatusNotFound, "", "group %d not found", id)
+	case err != nil:
+		return usergroup.Group{}, nil, err
+	}
+	users, err := usergroup.UsersInGroupTx(ctx, nil, id)
+	if err != nil {
+		return usergroup.Group{}, nil, err
+	}
+	return g, users, nil
+}
+
+func parseMembers(value json.RawMessage) ([]model.UserID, error) {
+	var members []groupMember
+	if err := json.Unmarshal(value, &members); err != nil {
+		return nil, newError(http.StatusBadRequest, "invalidValue", "invalid members")
+	}
+	return memberIDs(members)
+}
+
+func memberIDs(members []groupMember) ([]model.UserID, error) {
+	uids := make([]model.UserID, 0, len(members))
+	for _, m := range members {
+		id, err := strconv.Atoi(m.Value)
+		if err != nil {
+			return nil, newError(http.StatusBadRequest, "invalidValue", "invalid member %q", m.Value)
+		}
+		uids = append(uids, model.UserID(id))
+	}
+	return uids, nil
+}
+
+// memberSet tracks the members of a group as patch operations change them, so that only the
+// difference is written.
+type memberSet struct {
+	initial map[model.UserID]bool
+	current map[model.UserID]bool
+}
+
+func newMemberSet(users []model.User) *memberSet {
+	s := &memberSet{initial: map[model.UserID]bool{}, current: map[model.UserID]bool{}}
+	for _, u := range users {
+		s.initial[u.ID] = true
+		s.current[u.ID] = true
+	}
+	return s
+}
+
+func (s *memberSet) add(uids []model.UserID) {
+	for _, id := range uids {
+		s.current[id] = true
+	}
+}
+
+func (s *memberSet) remove(uids []model.UserID) {
+	for _, id := range uids {
+		delete(s.current, id)
+	}
+}
+
+func (s *memberSet) replace(uids []model.UserID) {
+	s.current = map[model.UserID]bool{}
+	s.add(uids)
+}
+
+func (s *memberSet) added() []model.UserID {
+	var uids []model.UserID
+	for id := range s.current {
+		if !s.initial[id] {
+			uids = append(uids, id)
+		}
+	}
+	return uids
+}
+
+func (s *memberSet) removed() []model.UserID {
+	var uids []model.UserID
+	for id := range s.initial {
+		if !s.current[id] {
+			uids = append(uids, id)
+		}
+	}
+	return uids
+}
@@ -0,0 +1,247 @@
+// Package scim implements the subset of SCIM 2.0 (RFC 7643 and 7644) that identity providers use
+// to provision users and groups.
+package scim
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/internal/config"
+)
+
+const (
+	// BasePath is where the SCIM endpoints are served.
+	BasePath = "/scim/v2"
+
+	userSchema  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	groupSchema = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	listSchema  = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	errorSchema = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	contentType     = "application/scim+json"
+	defaultPageSize = 100
+)
+
+// service serves the SCIM endpoints.
+type service struct {
+	config config.ScimConfig
+}
+
+// RegisterAPIHandlers registers the SCIM endpoints if they are enabled.
+func RegisterAPIHandlers(c *config.Config, e *echo.Echo) {
+	if !c.Scim.Enabled {
+		return
+	}
+	s := &service{config: c.Scim}
+	g := e.Group(BasePath, s.authenticate)
+
+	g.GET("/Users", handle(s.listUsers))
+	g.POST("/Users", handle(s.createUser))
+	g.GET("/Users/:id", handle(s.getUser))
+	g.PUT("/Users/:id", handle(s.replaceUser))
+	g.PATCH("/Users/:id", handle(s.patchUser))
+	g.DELETE("/Users/:id", handle(s.deleteUser))
+
+	g.GET("/Groups", handle(s.listGroups))
+	g.POST("/Groups", handle(s.createGroup))
+	g.GET("/Groups/:id", handle(s.getGroup))
+	g.PUT("/Groups/:id", handle(s.replaceGroup))
+	g.PATCH("/Groups/:id", handle(s.patchGroup))
+	g.DELETE("/Groups/:id", handle(s.deleteGroup))
+}
+
+// authenticate rejects requests that do not carry the credentials of the identity provider.
+func (s *service) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !s.authorized(c.Request()) {
+			return writeError(c, newError(http.StatusUnauthorized, "", "invalid credentials"))
+		}
+		return next(c)
+	}
+}
+
+func (s *service) authorized(r *http.Request) bool {
+	switch s.config.Auth.Type {
+	case config.ScimAuthBasic:
+		username, password, ok := r.BasicAuth()
+		usernameOK := equal(username, s.config.Auth.Username)
+		passwordOK := equal(password, s.config.Auth.Password)
+		return ok && usernameOK && passwordOK
+	case config.ScimAuthToken:
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		return equal(token, s.config.Auth.Token)
+	default:
+		return false
+	}
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// scimError is an error response, as described in section 3.12 of RFC 7644.
+type scimError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+func (e *scimError) Error() string {
+	return e.Detail
+}
+
+func newError(status int, scimType string, format string, args ...interface{}) *scimError {
+	return &scimError{
+		Schemas:  []string{errorSchema},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   fmt.Sprintf(format, args...),
+	}
+}
+
+func writeError(c echo.Context, err *scimError) error {
+	status, _ := strconv.Atoi(err.Status)
+	return writeJSON(c, status, err)
+}
+
+func writeJSON(c echo.Context, status int, v interface{}) error {
+	if v == nil {
+		return c.NoContent(status)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Blob(status, contentType, b)
+}
+
+// handle adapts a SCIM handler, which returns the status and body of its response, to echo,
+// writing any error as a SCIM error.
+func handle(f func(c echo.Context) (int, interface{}, error)) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		status, body, err := f(c)
+		var sErr *scimError
+		switch {
+		case errors.As(err, &sErr):
+			return writeError(c, sErr)
+		case err != nil:
+			return writeError(c, newError(http.StatusInternalServerError, "", "%s", err))
+		}
+		return writeJSON(c, status, body)
+	}
+}
+
+// meta is the metadata of a resource.
+type meta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location"`
+}
+
+func newMeta(c echo.Context, resourceType, endpoint string, id int) *meta {
+	return &meta{
+		ResourceType: resourceType,
+		Location:     fmt.Sprintf("%s://%s%s/%s/%d", c.Scheme(), c.Request().Host, BasePath, endpoint, id),
+	}
+}
+
+// listResponse is the response to a query, as described in section 3.4.2 of RFC 7644.
+type listResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// page returns the offset and limit of the page a query asks for. SCIM indexes results from 1.
+func page(c echo.Context) (offset, limit int, err error) {
+	startIndex, limit := 1, defaultPageSize
+	if v := c.QueryParam("startIndex"); v != "" {
+		if startIndex, err = strconv.Atoi(v); err != nil {
+			return 0, 0, newError(http.StatusBadRequest, "invalidValue", "invalid startIndex %q", v)
+		}
+		if startIndex < 1 {
+			startIndex = 1
+		}
+	}
+	if v := c.QueryParam("count"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			return 0, 0, newError(http.StatusBadRequest, "invalidValue", "invalid count %q", v)
+		}
+		if limit < 0 {
+			limit = 0
+		}
+	}
+	return startIndex - 1, limit, nil
+}
+
+var filterPattern = regexp.MustCompile(`^\s*([A-Za-z.]+)\s+(?i:eq)\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// parseFilter parses a filter of the form `attribute eq "value"`, which is all that identity
+// providers use to look up resources, and returns the value. The attribute must be the one given,
+// compared case-insensitively as attribute names are in SCIM. An empty filter returns nil.
+func parseFilter(filter string, attribute string) (*string, error) {
+	if filter == "" {
+		return nil, nil
+	}
+	m := filterPattern.FindStringSubmatch(filter)
+	if m == nil || !strings.EqualFold(m[1], attribute) {
+		return nil, newError(http.StatusBadRequest, "invalidFilter",
+			"only filters of the form %s eq \"value\" are supported", attribute)
+	}
+	value, err := strconv.Unquote(m[2])
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, "invalidFilter", "invalid filter value %s", m[2])
+	}
+	return &value, nil
+}
+
+// patchRequest is a request to modify a resource, as described in section 3.5.2 of RFC 7644.
+type patchRequest struct {
+	Operations []patchOperation `json:"Operations"`
+}
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// attributes returns the attributes an operation sets: the value itself when the operation has no
+// path, and otherwise the value at the path.
+func (o patchOperation) attributes() (map[string]json.RawMessage, error) {
+	if o.Path != "" {
+		return map[string]json.RawMessage{o.Path: o.Value}, nil
+	}
+	var attrs map[string]json.RawMessage
+	if err := json.Unmarshal(o.Value, &attrs); err != nil {
+		return nil, newError(http.StatusBadRequest, "invalidValue",
+			"an operation without a path must have an object value")
+	}
+	return attrs, nil
+}
+
+// parseID parses the ID of a resource, which is its ID in the database.
+func parseID(c echo.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, newError(http.StatusNotFound, "", "resource %s not found", c.Param("id"))
+	}
+	return id, nil
+}
+
+func decode(c echo.Context, v interface{}) error {
+	if err := json.NewDecoder(c.Request().Body).Decode(v); err != nil {
+		return newError(http.StatusBadRequest, "invalidSyntax", "invalid request body: %s", err)
+	}
+	return nil
+}
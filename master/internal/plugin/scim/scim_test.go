@@ -0,0 +1,132 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestParseFilter(t *testing.T) {
+	value, err := parseFilter(`userName eq "alice@example.com"`, "userName")
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", *value)
+
+	value, err = parseFilter(`USERNAME Eq "say \"hi\""`, "userName")
+	require.NoError(t, err)
+	require.Equal(t, `say "hi"`, *value)
+
+	value, err = parseFilter("", "userName")
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	for _, filter := range []string{
+		`displayName eq "alice"`,
+		`userName co "alice"`,
+		`userName eq alice`,
+		`userName eq "a" or userName eq "b"`,
+	} {
+		_, err = parseFilter(filter, "userName")
+		require.Error(t, err, filter)
+	}
+}
+
+func TestAuthorized(t *testing.T) {
+	basic := &service{config: config.ScimConfig{Auth: config.ScimAuthConfig{
+		Type: config.ScimAuthBasic, Username: "determined", Password: "password",
+	}}}
+	token := &service{config: config.ScimConfig{Auth: config.ScimAuthConfig{
+		Type: config.ScimAuthToken, Token: "secret",
+	}}}
+
+	r := httptest.NewRequest(http.MethodGet, BasePath+"/Users", nil)
+	require.False(t, basic.authorized(r))
+	require.False(t, token.authorized(r))
+
+	r.SetBasicAuth("determined", "password")
+	require.True(t, basic.authorized(r))
+	r.SetBasicAuth("determined", "wrong")
+	require.False(t, basic.authorized(r))
+
+	r.Header.Set("Authorization", "Bearer secret")
+	require.True(t, token.authorized(r))
+	require.False(t, basic.authorized(r))
+	r.Header.Set("Authorization", "Bearer wrong")
+	require.False(t, token.authorized(r))
+}
+
+func TestApplyUserOperation(t *testing.T) {
+	u := &model.User{Username: "alice", Active: true, DisplayName: null.StringFrom("Alice")}
+
+	// Okta deactivates users by replacing active without a path.
+	require.NoError(t, applyUserOperation(u, patchOperation{
+		Op: "replace", Value: json.RawMessage(`{"active": false}`),
+	}))
+	require.False(t, u.Active)
+
+	// Azure AD capitalizes operations and sends booleans as strings.
+	require.NoError(t, applyUserOperation(u, patchOperation{
+		Op: "Replace", Path: "active", Value: json.RawMessage(`"True"`),
+	}))
+	require.True(t, u.Active)
+
+	require.NoError(t, applyUserOperation(u, patchOperation{
+		Op: "replace", Value: json.RawMessage(`{"userName": "Bob", "emails": []}`),
+	}))
+	require.Equal(t, "bob", u.Username)
+
+	require.NoError(t, applyUserOperation(u, patchOperation{Op: "remove", Path: "displayName"}))
+	require.False(t, u.DisplayName.Valid)
+
+	require.Error(t, applyUserOperation(u, patchOperation{
+		Op: "replace", Path: "active", Value: json.RawMessage(`"maybe"`),
+	}))
+	require.Error(t, applyUserOperation(u, patchOperation{
+		Op: "move", Path: "active", Value: json.RawMessage(`true`),
+	}))
+}
+
+func TestApplyGroupOperation(t *testing.T) {
+	sorted := func(uids []model.UserID) []model.UserID {
+		sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+		return uids
+	}
+	name := "team"
+	members := newMemberSet([]model.User{{ID: 1}, {ID: 2}})
+
+	require.NoError(t, applyGroupOperation(&name, members, patchOperation{
+		Op: "add", Path: "members", Value: json.RawMessage(`[{"value": "3"}, {"value": "4"}]`),
+	}))
+	require.NoError(t, applyGroupOperation(&name, members, patchOperation{
+		Op: "remove", Path: `members[value eq "1"]`,
+	}))
+	require.NoError(t, applyGroupOperation(&name, members, patchOperation{
+		Op: "remove", Path: "members", Value: json.RawMessage(`[{"value": "4"}]`),
+	}))
+	require.NoError(t, applyGroupOperation(&name, members, patchOperation{
+		Op: "replace", Value: json.RawMessage(`{"id": "7", "displayName": "renamed"}`),
+	}))
+	require.Equal(t, "renamed", name)
+	require.Equal(t, []model.UserID{3}, sorted(members.added()))
+	require.Equal(t, []model.UserID{1}, sorted(members.removed()))
+
+	require.NoError(t, applyGroupOperation(&name, members, patchOperation{
+		Op: "replace", Path: "members", Value: json.RawMessage(`[{"value": "5"}]`),
+	}))
+	require.Equal(t, []model.UserID{5}, sorted(members.added()))
+	require.Equal(t, []model.UserID{1, 2}, sorted(members.removed()))
+
+	require.Error(t, applyGroupOperation(&name, members, patchOperation{
+		Op: "add", Path: "members", Value: json.RawMessage(`[{"value": "alice"}]`),
+	}))
+	require.Error(t, applyGroupOperation(&name, members, patchOperation{
+		Op: "remove", Path: "displayName",
+	}))
+}
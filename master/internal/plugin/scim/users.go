@@ -0,0 +1,283 @@
+package scim
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/user"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// scimUser is the SCIM representation of a user.
+type scimUser struct {
+	Schemas     []string  `json:"schemas"`
+	ID          string    `json:"id,omitempty"`
+	UserName    string    `json:"userName"`
+	Name        *userName `json:"name,omitempty"`
+	DisplayName string    `json:"displayName,omitempty"`
+	Active      *bool     `json:"active,omitempty"`
+	Meta        *meta     `json:"meta,omitempty"`
+}
+
+type userName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// displayName returns the name to display for the user: their display name, or else their full
+// name.
+func (u scimUser) displayName() string {
+	switch {
+	case u.DisplayName != "":
+		return u.DisplayName
+	case u.Name == nil:
+		return ""
+	case u.Name.Formatted != "":
+		return u.Name.Formatted
+	default:
+		return strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+	}
+}
+
+func toSCIMUser(c echo.Context, u *model.User) scimUser {
+	active := u.Active
+	return scimUser{
+		Schemas:     []string{userSchema},
+		ID:          strconv.Itoa(int(u.ID)),
+		UserName:    u.Username,
+		DisplayName: u.DisplayName.ValueOrZero(),
+		Active:      &active,
+		Meta:        newMeta(c, "User", "Users", int(u.ID)),
+	}
+}
+
+func (s *service) listUsers(c echo.Context) (int, interface{}, error) {
+	username, err := parseFilter(c.QueryParam("filter"), "userName")
+	if err != nil {
+		return 0, nil, err
+	}
+	offset, limit, err := page(c)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var users []model.User
+	q := db.Bun().NewSelect().Model(&users).Where("remote").Order("id").Offset(offset).Limit(limit)
+	if username != nil {
+		q = q.Where("username = ?", strings.ToLower(*username))
+	}
+	total, err := q.ScanAndCount(c.Request().Context())
+	if err != nil {
+		return 0, nil, err
+	}
+
+	resources := make([]scimUser, 0, len(users))
+	for i := range users {
+		resources = append(resources, toSCIMUser(c, &users[i]))
+	}
+	return http.StatusOK, listResponse{
+		Schemas:      []string{listSchema},
+		TotalResults: total,
+		StartIndex:   offset + 1,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, nil
+}
+
+func (s *service) getUser(c echo.Context) (int, interface{}, error) {
+	u, err := userByID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, toSCIMUser(c, u), nil
+}
+
+func (s *service) createUser(c echo.Context) (int, interface{}, error) {
+	var req scimUser
+	if err := decode(c, &req); err != nil {
+		return 0, nil, err
+	}
+	if req.UserName == "" {
+		return 0, nil, newError(http.StatusBadRequest, "invalidValue", "userName is required")
+	}
+
+	switch _, err := user.UserByUsername(req.UserName); {
+	case err == nil:
+		return 0, nil, newError(http.StatusConflict, "uniqueness",
+			"user %s already exists", req.UserName)
+	case !errors.Is(err, db.ErrNotFound):
+		return 0, nil, err
+	}
+
+	u := &model.User{
+		Username:     strings.ToLower(req.UserName),
+		PasswordHash: null.NewString("", false),
+		DisplayName:  null.NewString(req.displayName(), req.displayName() != ""),
+		Active:       req.Active == nil || *req.Active,
+		Remote:       true,
+	}
+	if err := user.AddUserExec(u); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusCreated, toSCIMUser(c, u), nil
+}
+
+func (s *service) replaceUser(c echo.Context) (int, interface{}, error) {
+	u, err := userByID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+	var req scimUser
+	if err := decode(c, &req); err != nil {
+		return 0, nil, err
+	}
+	if req.UserName == "" {
+		return 0, nil, newError(http.StatusBadRequest, "invalidValue", "userName is required")
+	}
+
+	u.Username = strings.ToLower(req.UserName)
+	u.DisplayName = null.NewString(req.displayName(), req.displayName() != "")
+	u.Active = req.Active == nil || *req.Active
+	if err := updateUser(c.Request().Context(), u); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, toSCIMUser(c, u), nil
+}
+
+func (s *service) patchUser(c echo.Context) (int, interface{}, error) {
+	u, err := userByID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+	var req patchRequest
+	if err := decode(c, &req); err != nil {
+		return 0, nil, err
+	}
+	for _, op := range req.Operations {
+		if err := applyUserOperation(u, op); err != nil {
+			return 0, nil, err
+		}
+	}
+	if err := updateUser(c.Request().Context(), u); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, toSCIMUser(c, u), nil
+}
+
+// applyUserOperation applies a patch operation to a user. Attributes that are not kept by the
+// cluster, such as emails, are ignored, since identity providers send them regardless.
+func applyUserOperation(u *model.User, op patchOperation) error {
+	attrs, err := op.attributes()
+	if err != nil {
+		return err
+	}
+	for path, value := range attrs {
+		switch {
+		case strings.EqualFold(op.Op, "remove"):
+			if strings.EqualFold(path, "displayName") {
+				u.DisplayName = null.String{}
+			}
+		case strings.EqualFold(op.Op, "add"), strings.EqualFold(op.Op, "replace"):
+			if err := setUserAttribute(u, path, value); err != nil {
+				return err
+			}
+		default:
+			return newError(http.StatusBadRequest, "invalidSyntax", "unknown operation %q", op.Op)
+		}
+	}
+	return nil
+}
+
+func setUserAttribute(u *model.User, path string, value json.RawMessage) error {
+	switch strings.ToLower(path) {
+	case "active":
+		active, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		u.Active = active
+	case "username":
+		var username string
+		if err := json.Unmarshal(value, &username); err != nil || username == "" {
+			return newError(http.StatusBadRequest, "invalidValue", "invalid userName")
+		}
+		u.Username = strings.ToLower(username)
+	case "displayname":
+		var displayName string
+		if err := json.Unmarshal(value, &displayName); err != nil {
+			return newError(http.StatusBadRequest, "invalidValue", "invalid displayName")
+		}
+		u.DisplayName = null.NewString(displayName, displayName != "")
+	}
+	return nil
+}
+
+// parseBool parses a boolean, which some identity providers send as a string.
+func parseBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+	return false, newError(http.StatusBadRequest, "invalidValue", "invalid boolean %s", value)
+}
+
+// deleteUser deactivates a user; users are never deleted, so that what they created is kept.
+func (s *service) deleteUser(c echo.Context) (int, interface{}, error) {
+	u, err := userByID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+	u.Active = false
+	if err := updateUser(c.Request().Context(), u); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+// userByID returns the user in the path. SCIM only manages remote users, whom identity providers
+// are the source of, so users who log in with a password are never found.
+func userByID(c echo.Context) (*model.User, error) {
+	id, err := parseID(c)
+	if err != nil {
+		return nil, err
+	}
+	var u model.User
+	switch err := db.Bun().NewSelect().Model(&u).Where("id = ?", id).Where("remote").
+		Scan(c.Request().Context()); {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, newError(http.StatusNotFound, "", "user %d not found", id)
+	case err != nil:
+		return nil, err
+	}
+	return &u, nil
+}
+
+// updateUser saves the attributes of a user that SCIM manages.
+func updateUser(ctx context.Context, u *model.User) error {
+	u.ModifiedAt = time.Now()
+	_, err := db.Bun().NewUpdate().Model(u).
+		Column("username", "display_name", "active", "modified_at").
+		WherePK().
+		Exec(ctx)
+	if errors.Is(db.MatchSentinelError(err), db.ErrDuplicateRecord) {
+		return newError(http.StatusConflict, "uniqueness", "user %s already exists", u.Username)
+	}
+	return err
+}
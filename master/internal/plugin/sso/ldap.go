@@ -0,0 +1,210 @@
+package sso
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/user"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// ErrLDAPInvalidCredentials is returned when the directory does not accept the username and
+// password of a login, or the user cannot log in to the cluster with them.
+var ErrLDAPInvalidCredentials = errors.New("invalid LDAP credentials")
+
+// LDAPEnabled returns whether users can log in with the password of their account in an LDAP
+// directory.
+func LDAPEnabled() bool {
+	return config.GetMasterConfig().LDAP.Enabled
+}
+
+// LDAPLogin checks a password against the LDAP directory and returns the user it belongs to,
+// creating them if they do not exist and users are provisioned automatically. Their group
+// memberships are synchronized with those in the directory.
+func LDAPLogin(ctx context.Context, username, password string) (*model.User, error) {
+	c := config.GetMasterConfig().LDAP
+	entry, groups, err := ldapAuthenticate(c, username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := user.UserByUsername(username)
+	switch {
+	case err == nil:
+		if !user.IsSSOUser(*u) {
+			// Users with a password in the cluster never log in with the directory.
+			return nil, ErrLDAPInvalidCredentials
+		}
+	case !errors.Is(err, db.ErrNotFound):
+		return nil, err
+	case !c.AutoProvisionUsers:
+		log.Infof("refusing LDAP login of %s, who has no user in the cluster", username)
+		return nil, ErrLDAPInvalidCredentials
+	default:
+		u = &model.User{
+			Username:     strings.ToLower(username),
+			PasswordHash: null.NewString("", false),
+			Active:       true,
+			Remote:       true,
+		}
+		if name := entry.GetEqualFoldAttributeValue(c.DisplayNameAttribute); name != "" {
+			u.DisplayName = null.StringFrom(name)
+		}
+		if err := user.AddUserExec(u); err != nil {
+			return nil, err
+		}
+		log.Infof("created user %s on their first login through LDAP", u.Username)
+	}
+
+	if c.GroupSearchBaseDN != "" && u.Active {
+		if err := syncGroups(ctx, u.ID, groups, c.GroupMappings, "LDAP"); err != nil {
+			return nil, err
+		}
+	}
+	return u, nil
+}
+
+// ldapAuthenticate binds as the user with the given name and returns their entry and the names
+// of their groups.
+func ldapAuthenticate(
+	c config.LDAPConfig, username, password string,
+) (*ldap.Entry, []string, error) {
+	// Servers treat a bind without a password as an anonymous bind that always succeeds.
+	if password == "" {
+		return nil, nil, ErrLDAPInvalidCredentials
+	}
+	conn, err := ldapDial(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.WithError(err).Debug("closing LDAP connection")
+		}
+	}()
+
+	bindServiceAccount := func() error {
+		if c.BindDN == "" {
+			return nil
+		}
+		return errors.Wrap(conn.Bind(c.BindDN, c.BindPassword), "binding as the LDAP service account")
+	}
+	if err := bindServiceAccount(); err != nil {
+		return nil, nil, err
+	}
+
+	users, err := conn.Search(ldap.NewSearchRequest(
+		c.UserSearchBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		ldapFilter(c.UserObjectClass, c.UsernameAttribute, username),
+		[]string{c.DisplayNameAttribute}, nil,
+	))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "looking up LDAP user %s", username)
+	}
+	switch len(users.Entries) {
+	case 0:
+		return nil, nil, ErrLDAPInvalidCredentials
+	case 1:
+	default:
+		return nil, nil, errors.Errorf("%d LDAP entries match user %s",
+			len(users.Entries), username)
+	}
+	entry := users.Entries[0]
+
+	switch err := conn.Bind(entry.DN, password); {
+	case ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials):
+		return nil, nil, ErrLDAPInvalidCredentials
+	case err != nil:
+		return nil, nil, errors.Wrapf(err, "binding as LDAP user %s", username)
+	}
+
+	if c.GroupSearchBaseDN == "" {
+		return entry, nil, nil
+	}
+	// Users may not be allowed to search the directory themselves.
+	if err := bindServiceAccount(); err != nil {
+		return nil, nil, err
+	}
+	groupEntries, err := conn.Search(ldap.NewSearchRequest(
+		c.GroupSearchBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		ldapFilter(c.GroupObjectClass, c.GroupMemberAttribute, entry.DN),
+		[]string{c.GroupNameAttribute}, nil,
+	))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "looking up LDAP groups of %s", username)
+	}
+	var groups []string
+	for _, g := range groupEntries.Entries {
+		if name := g.GetEqualFoldAttributeValue(c.GroupNameAttribute); name != "" {
+			groups = append(groups, name)
+		}
+	}
+	return entry, groups, nil
+}
+
+// ldapDial connects to the directory server, upgrading ldap:// connections with StartTLS if it is
+// configured. Every operation on the connection must complete within the configured timeout.
+func ldapDial(c config.LDAPConfig) (*ldap.Conn, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid LDAP URL")
+	}
+	tlsConfig, err := ldapTLSConfig(c, u.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	timeout := time.Duration(c.Timeout)
+	conn, err := ldap.DialURL(c.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to LDAP server %s", u.Host)
+	}
+	conn.SetTimeout(timeout)
+	if c.StartTLS && u.Scheme == "ldap" {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			_ = conn.Close()
+			return nil, errors.Wrap(err, "starting TLS")
+		}
+	}
+	return conn, nil
+}
+
+// ldapFilter returns a search filter for the entries of an object class with the given value of
+// an attribute.
+func ldapFilter(objectClass, attribute, value string) string {
+	return fmt.Sprintf("(&(objectClass=%s)(%s=%s))",
+		ldap.EscapeFilter(objectClass), attribute, ldap.EscapeFilter(value))
+}
+
+// ldapTLSConfig returns the TLS settings for the directory server with the given host name.
+func ldapTLSConfig(c config.LDAPConfig, host string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	if c.TLSRootCert == "" {
+		return tlsConfig, nil
+	}
+	pem, err := os.ReadFile(c.TLSRootCert)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading LDAP tls_root_cert")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no certificates found in LDAP tls_root_cert %s", c.TLSRootCert)
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
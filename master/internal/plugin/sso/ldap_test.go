@@ -0,0 +1,22 @@
+package sso
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/config"
+)
+
+func TestLDAPFilter(t *testing.T) {
+	require.Equal(t, "(&(objectClass=person)(uid=alice))", ldapFilter("person", "uid", "alice"))
+	// Values cannot widen the search.
+	require.Equal(t, `(&(objectClass=person)(uid=\2a\29\28uid=\2a))`,
+		ldapFilter("person", "uid", "*)(uid=*"))
+}
+
+func TestLDAPEmptyPassword(t *testing.T) {
+	// Empty passwords are refused before connecting, since servers accept them as anonymous binds.
+	_, _, err := ldapAuthenticate(config.LDAPConfig{URL: "ldap://ldap.invalid"}, "alice", "")
+	require.ErrorIs(t, err, ErrLDAPInvalidCredentials)
+}
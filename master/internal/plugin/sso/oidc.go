@@ -0,0 +1,536 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/determined-ai/determined/master/internal/auditlog"
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/user"
+	"github.com/determined-ai/determined/master/internal/usergroup"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+const (
+	// OIDCSSOPath starts a login through the OIDC provider.
+	OIDCSSOPath = "/oidc/sso"
+	// OIDCCallbackPath is where the OIDC provider redirects users back to after they log in.
+	OIDCCallbackPath = "/oidc/callback"
+
+	oidcStateCookie     = "oidc_state"
+	oidcStateExpiry     = 10 * time.Minute
+	oidcKeysRefreshWait = time.Minute
+	defaultRelayState   = "/det/"
+)
+
+// oidcDiscovery is the part of the discovery document of an OIDC provider that is used.
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcLoginState is what is kept in a cookie between redirecting a user to the provider and
+// their return: the state that protects against forged callbacks, the nonce that ties the ID
+// token to this login, the PKCE verifier, and where to send the user after they log in.
+type oidcLoginState struct {
+	State      string `json:"state"`
+	Nonce      string `json:"nonce"`
+	Verifier   string `json:"verifier"`
+	RelayState string `json:"relay_state"`
+}
+
+// oidcService logs users in through an OIDC provider.
+type oidcService struct {
+	config config.OIDCConfig
+	db     *db.PgDB
+	issuer string
+	oauth  *oauth2.Config
+	keys   *jwks
+}
+
+func newOIDCService(c config.OIDCConfig, pgDB *db.PgDB) (*oidcService, error) {
+	var discovery oidcDiscovery
+	discoveryURL := strings.TrimSuffix(c.IDPSSOURL, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(discoveryURL, &discovery); err != nil {
+		return nil, errors.Wrap(err, "fetching OIDC discovery document")
+	}
+	if discovery.Issuer != strings.TrimSuffix(c.IDPSSOURL, "/") &&
+		discovery.Issuer != c.IDPSSOURL {
+		return nil, errors.Errorf("OIDC issuer %q does not match idp_sso_url %q",
+			discovery.Issuer, c.IDPSSOURL)
+	}
+
+	scopes := append([]string{"openid"}, c.Scopes...)
+	return &oidcService{
+		config: c,
+		db:     pgDB,
+		issuer: discovery.Issuer,
+		oauth: &oauth2.Config{
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  discovery.AuthorizationEndpoint,
+				TokenURL: discovery.TokenEndpoint,
+			},
+			RedirectURL: strings.TrimSuffix(c.IDPRecipientURL, "/") + OIDCCallbackPath,
+			Scopes:      scopes,
+		},
+		keys: &jwks{url: discovery.JWKSURI},
+	}, nil
+}
+
+// CheckOIDC returns an error if the OIDC provider cannot be reached with the given settings or does
+// not publish the keys that its ID tokens are signed with.
+func CheckOIDC(c config.OIDCConfig) error {
+	s, err := newOIDCService(c, nil)
+	if err != nil {
+		return errors.Wrap(err, "check oidc.idp_sso_url and that the master can reach it")
+	}
+	s.keys.mu.Lock()
+	defer s.keys.mu.Unlock()
+	return s.keys.refresh()
+}
+
+// sso redirects the user to the provider to log in.
+func (s *oidcService) sso(c echo.Context) error {
+	state := oidcLoginState{
+		State:      randomString(),
+		Nonce:      randomString(),
+		Verifier:   randomString(),
+		RelayState: relayState(c.QueryParam("relayState")),
+	}
+	value, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    base64.RawURLEncoding.EncodeToString(value),
+		Path:     "/oidc",
+		Expires:  time.Now().Add(oidcStateExpiry),
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	challenge := sha256.Sum256([]byte(state.Verifier))
+	return c.Redirect(http.StatusSeeOther, s.oauth.AuthCodeURL(state.State,
+		oauth2.SetAuthURLParam("nonce", state.Nonce),
+		oauth2.SetAuthURLParam("code_challenge",
+			base64.RawURLEncoding.EncodeToString(challenge[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	))
+}
+
+// callback completes a login once the provider redirects the user back, starting a session for
+// them.
+func (s *oidcService) callback(c echo.Context) error {
+	state, err := loginStateFromCookie(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     "/oidc",
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+	})
+
+	if e := c.QueryParam("error"); e != "" {
+		return echo.NewHTTPError(http.StatusUnauthorized,
+			fmt.Sprintf("OIDC login failed: %s %s", e, c.QueryParam("error_description")))
+	}
+	if subtle.ConstantTimeCompare([]byte(c.QueryParam("state")), []byte(state.State)) != 1 {
+		return echo.NewHTTPError(http.StatusBadRequest, "OIDC login state does not match")
+	}
+
+	ctx := c.Request().Context()
+	token, err := s.oauth.Exchange(ctx, c.QueryParam("code"),
+		oauth2.SetAuthURLParam("code_verifier", state.Verifier))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized,
+			fmt.Sprintf("exchanging OIDC authorization code: %s", err))
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "OIDC provider returned no ID token")
+	}
+	claims, err := s.verify(rawIDToken, state.Nonce)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	}
+
+	u, err := s.userFromClaims(ctx, claims)
+	if err != nil {
+		return err
+	}
+	entry := auditlog.Entry{
+		Action:   auditlog.UserLogin,
+		RemoteIP: c.RealIP(),
+		Details:  map[string]interface{}{"provider": s.config.Provider},
+	}
+	startSession, err := s.checkLogin(ctx, u)
+	if err != nil {
+		auditlog.Record(ctx, u, entry)
+		return err
+	}
+	if s.config.GroupsClaimName != "" {
+		groups := claimedGroups(claims[s.config.GroupsClaimName])
+		if err := syncGroups(ctx, u.ID, groups, s.config.GroupMappings, "OIDC"); err != nil {
+			return err
+		}
+	}
+
+	sessionToken, err := startSession(u)
+	if err != nil {
+		return err
+	}
+	entry.Success = true
+	auditlog.Record(ctx, u, entry)
+	c.SetCookie(user.NewCookieFromToken(sessionToken))
+	return c.Redirect(http.StatusSeeOther, state.RelayState)
+}
+
+// checkLogin applies the checks that logging in with a password does to a user the provider has
+// authenticated, and returns how to start their session. Service accounts and inactive users
+// cannot log in. Local users linked through OIDC are also subject to lockouts and MFA: those
+// enrolled in MFA must log in with their password and a code, which OIDC cannot carry, and those
+// who must enroll get a session that only allows enrolling.
+func (s *oidcService) checkLogin(
+	ctx context.Context, u *model.User,
+) (func(*model.User) (string, error), error) {
+	switch {
+	case u.ServiceAccount:
+		return nil, echo.NewHTTPError(http.StatusForbidden,
+			"service accounts cannot log in; authenticate with an access token instead")
+	case !u.Active:
+		return nil, echo.NewHTTPError(http.StatusForbidden, "user not active")
+	case user.IsSSOUser(*u):
+		return s.db.StartUserSession, nil
+	}
+
+	passwordState, err := user.PasswordState(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	if passwordState.Locked(time.Now()) {
+		return nil, echo.NewHTTPError(http.StatusForbidden,
+			"user is locked out after too many failed logins; try again later")
+	}
+
+	startSession := s.db.StartUserSession
+	mfa, err := user.UserMFA(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case mfa.Enrolled():
+		return nil, echo.NewHTTPError(http.StatusForbidden,
+			"users enrolled in MFA must log in with their password and an MFA code")
+	case user.MFARequired(mfa):
+		startSession = s.db.StartMFAEnrollmentSession
+	}
+	if err := user.RecordSuccessfulLogin(ctx, u.ID); err != nil {
+		return nil, err
+	}
+	return startSession, nil
+}
+
+// verify checks the signature, issuer, audience, expiry and nonce of an ID token and returns its
+// claims.
+func (s *oidcService) verify(rawIDToken, nonce string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(rawIDToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, errors.Errorf("unsupported signing method %s", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return s.keys.key(kid)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid OIDC ID token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	switch {
+	case !ok:
+		return nil, errors.New("invalid OIDC ID token claims")
+	case !claims.VerifyIssuer(s.issuer, true):
+		return nil, errors.New("OIDC ID token was issued by another provider")
+	case !claims.VerifyAudience(s.config.ClientID, true):
+		return nil, errors.New("OIDC ID token was issued to another client")
+	case !claims.VerifyExpiresAt(time.Now().Unix(), true):
+		return nil, errors.New("OIDC ID token has expired")
+	}
+	if claimed, _ := claims["nonce"].(string); nonce == "" ||
+		subtle.ConstantTimeCompare([]byte(claimed), []byte(nonce)) != 1 {
+		return nil, errors.New("OIDC ID token was issued for another login")
+	}
+	return claims, nil
+}
+
+// userFromClaims returns the user an ID token identifies, creating them if they do not exist and
+// users are provisioned automatically.
+func (s *oidcService) userFromClaims(
+	ctx context.Context, claims jwt.MapClaims,
+) (*model.User, error) {
+	username, ok := claims[s.config.AuthenticationClaim].(string)
+	if !ok || username == "" {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized,
+			fmt.Sprintf("OIDC ID token has no %s claim", s.config.AuthenticationClaim))
+	}
+	// Anyone can claim an unverified address, so it must not link them to the user who owns it.
+	if s.config.AuthenticationClaim == "email" && !emailVerified(claims["email_verified"]) {
+		return nil, echo.NewHTTPError(http.StatusForbidden,
+			"OIDC provider has not verified the email address of the user")
+	}
+
+	u, err := user.UserByUsername(username)
+	switch {
+	case err == nil:
+		return u, nil
+	case !errors.Is(err, db.ErrNotFound):
+		return nil, err
+	case !s.config.AutoProvisionUsers:
+		return nil, echo.NewHTTPError(http.StatusForbidden, "user not found")
+	}
+
+	u = &model.User{
+		Username:     strings.ToLower(username),
+		PasswordHash: null.NewString("", false),
+		Active:       true,
+		Remote:       true,
+	}
+	if name, ok := claims["name"].(string); ok && name != "" {
+		u.DisplayName = null.StringFrom(name)
+	}
+	if err := user.AddUserExec(u); err != nil {
+		return nil, err
+	}
+	log.Infof("created user %s on their first login through OIDC", u.Username)
+	return u, nil
+}
+
+// emailVerified returns whether the email_verified claim of an ID token is true. Some providers
+// send it as a string.
+func emailVerified(claim interface{}) bool {
+	switch verified := claim.(type) {
+	case bool:
+		return verified
+	case string:
+		return verified == "true"
+	default:
+		return false
+	}
+}
+
+// claimedGroups returns the names of the groups listed in the groups claim of an ID token.
+func claimedGroups(claim interface{}) []string {
+	var names []string
+	claimed, _ := claim.([]interface{})
+	for _, g := range claimed {
+		if name, ok := g.(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// syncGroups makes the user a member of exactly the groups in the cluster that their groups in
+// an identity provider map to.
+func syncGroups(
+	ctx context.Context, uid model.UserID, provided []string, mappings map[string]string,
+	provider string,
+) error {
+	want := map[string]bool{}
+	for _, name := range provided {
+		if len(mappings) > 0 {
+			var ok bool
+			if name, ok = mappings[name]; !ok {
+				continue
+			}
+		}
+		want[name] = true
+	}
+
+	current, _, _, err := usergroup.SearchGroupsWithoutPersonalGroups(ctx, "", uid, 0, 0)
+	if err != nil {
+		return err
+	}
+	for _, g := range current {
+		if want[g.Name] {
+			delete(want, g.Name)
+			continue
+		}
+		if !managesGroup(g.Name, mappings) {
+			continue
+		}
+		if err := usergroup.RemoveUsersFromGroupTx(ctx, nil, g.ID, uid); err != nil {
+			return err
+		}
+	}
+	for name := range want {
+		groups, _, _, err := usergroup.SearchGroupsWithoutPersonalGroups(ctx, name, 0, 0, 0)
+		if err != nil {
+			return err
+		}
+		if len(groups) == 0 {
+			log.Debugf("skipping %s group %s with no matching group in the cluster", provider, name)
+			continue
+		}
+		if err := usergroup.AddUsersToGroupTx(ctx, nil, groups[0].ID, uid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// managesGroup returns whether membership of a group is controlled by an identity provider.
+// Without mappings every group is; otherwise only those that are mapped to.
+func managesGroup(name string, mappings map[string]string) bool {
+	if len(mappings) == 0 {
+		return true
+	}
+	for _, mapped := range mappings {
+		if mapped == name {
+			return true
+		}
+	}
+	return false
+}
+
+func loginStateFromCookie(c echo.Context) (*oidcLoginState, error) {
+	cookie, err := c.Cookie(oidcStateCookie)
+	if err != nil {
+		return nil, errors.New("missing OIDC login state, start the login again")
+	}
+	value, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return nil, errors.New("invalid OIDC login state")
+	}
+	var state oidcLoginState
+	if err := json.Unmarshal(value, &state); err != nil {
+		return nil, errors.New("invalid OIDC login state")
+	}
+	return &state, nil
+}
+
+// relayState returns where to send a user after they log in, which must be a path on the master.
+func relayState(requested string) string {
+	u, err := url.Parse(requested)
+	if requested == "" || err != nil || u.IsAbs() || u.Host != "" ||
+		!strings.HasPrefix(u.Path, "/") || strings.HasPrefix(requested, "//") {
+		return defaultRelayState
+	}
+	return requested
+}
+
+func randomString() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func getJSON(u string, v interface{}) error {
+	resp, err := http.Get(u) //nolint:gosec // The URL comes from the master config.
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.WithError(err).Warn("closing response body")
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("GET %s returned %s", u, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// jwks is the set of keys an OIDC provider signs ID tokens with. The keys are fetched again when
+// a token is signed with an unknown key, so that the provider can rotate them.
+type jwks struct {
+	url string
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	lastRefresh time.Time
+}
+
+func (k *jwks) key(kid string) (*rsa.PublicKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if key, ok := k.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(k.lastRefresh) > oidcKeysRefreshWait {
+		if err := k.refresh(); err != nil {
+			return nil, err
+		}
+		if key, ok := k.keys[kid]; ok {
+			return key, nil
+		}
+	}
+	return nil, errors.Errorf("OIDC ID token is signed with unknown key %q", kid)
+}
+
+func (k *jwks) refresh() error {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	k.lastRefresh = time.Now()
+	if err := getJSON(k.url, &set); err != nil {
+		return errors.Wrap(err, "fetching OIDC signing keys")
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return errors.Wrapf(err, "decoding modulus of OIDC key %q", key.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return errors.Wrapf(err, "decoding exponent of OIDC key %q", key.Kid)
+		}
+		keys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	k.keys = keys
+	return nil
+}
@@ -0,0 +1,126 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestRelayState(t *testing.T) {
+	for requested, expected := range map[string]string{
+		"":                            defaultRelayState,
+		"/det/experiments?sort=id":    "/det/experiments?sort=id",
+		"https://evil.example.com/":   defaultRelayState,
+		"//evil.example.com/det":      defaultRelayState,
+		"det/experiments":             defaultRelayState,
+		"javascript:alert(1)":         defaultRelayState,
+		"/det/projects/1#experiments": "/det/projects/1#experiments",
+	} {
+		require.Equal(t, expected, relayState(requested), requested)
+	}
+}
+
+func TestOIDCVerify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Failing a test from the goroutine of the handler is not allowed.
+		if err := json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key-1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e": base64.RawURLEncoding.EncodeToString(
+					big.NewInt(int64(key.E)).Bytes()),
+			}},
+		}); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	s := &oidcService{
+		config: config.OIDCConfig{ClientID: "determined"},
+		issuer: "https://idp.example.com",
+		keys:   &jwks{url: server.URL},
+	}
+	sign := func(kid string, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   "https://idp.example.com",
+			"aud":   "determined",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": "nonce-1",
+			"email": "alice@example.com",
+		}
+	}
+
+	claims, err := s.verify(sign("key-1", valid()), "nonce-1")
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", claims["email"])
+
+	for name, mutate := range map[string]func(jwt.MapClaims){
+		"issuer":   func(c jwt.MapClaims) { c["iss"] = "https://other.example.com" },
+		"audience": func(c jwt.MapClaims) { c["aud"] = "other" },
+		"expiry":   func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"nonce":    func(c jwt.MapClaims) { c["nonce"] = "nonce-2" },
+		"no nonce": func(c jwt.MapClaims) { delete(c, "nonce") },
+	} {
+		c := valid()
+		mutate(c)
+		_, err = s.verify(sign("key-1", c), "nonce-1")
+		require.Error(t, err, name)
+	}
+
+	_, err = s.verify(sign("key-2", valid()), "nonce-1")
+	require.ErrorContains(t, err, "unknown key")
+}
+
+func TestEmailVerified(t *testing.T) {
+	for claim, expected := range map[interface{}]bool{
+		true:    true,
+		"true":  true,
+		false:   false,
+		"false": false,
+		nil:     false,
+		1.0:     false,
+	} {
+		require.Equal(t, expected, emailVerified(claim), claim)
+	}
+}
+
+func TestOIDCCheckLogin(t *testing.T) {
+	s := &oidcService{}
+	ctx := context.Background()
+
+	_, err := s.checkLogin(ctx, &model.User{Active: true, ServiceAccount: true})
+	require.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	_, err = s.checkLogin(ctx, &model.User{Active: false, Remote: true})
+	require.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+
+	// Users who only log in through single sign-on get a regular session.
+	startSession, err := s.checkLogin(ctx, &model.User{Active: true, Remote: true})
+	require.NoError(t, err)
+	require.NotNil(t, startSession)
+}
@@ -1,6 +1,9 @@
 package sso
 
 import (
+	"strings"
+	"sync"
+
 	"github.com/labstack/echo/v4"
 
 	"github.com/determined-ai/determined/master/internal/config"
@@ -10,17 +13,65 @@ import (
 )
 
 // AddProviderInfoToMasterResponse modifies passed in master response adds sso
-// provider information. In OSS this is a no-op. While having two functions that
-// just set a field is somewhat awkward it avoids having to have OSS have any
-// requirements that masterResp/masterInfo has a field defined for provider info.
-func AddProviderInfoToMasterResponse(config *config.Config, masterResp *apiv1.GetMasterResponse) {}
+// provider information. While having two functions that just set a field is
+// somewhat awkward it avoids having to have masterResp/masterInfo have a field
+// defined for provider info.
+func AddProviderInfoToMasterResponse(config *config.Config, masterResp *apiv1.GetMasterResponse) {
+	if config.OIDC.Enabled {
+		masterResp.SsoProviders = append(masterResp.SsoProviders, &apiv1.SSOProvider{
+			Name:   config.OIDC.Provider,
+			SsoUrl: strings.TrimSuffix(config.OIDC.IDPRecipientURL, "/") + OIDCSSOPath,
+		})
+	}
+	masterResp.LdapEnabled = config.LDAP.Enabled
+}
 
 // AddProviderInfoToMasterInfo modifies passed in master info adds sso
-// provider information. In OSS this is a no-op.
+// provider information. This is a no-op since master info has no field for it.
 func AddProviderInfoToMasterInfo(config *config.Config, masterInfo *aproto.MasterInfo) {}
 
 // RegisterAPIHandlers registers needed API handlers
-// determined by master config. In OSS this is just a no-op.
+// determined by master config.
 func RegisterAPIHandlers(config *config.Config, db *db.PgDB, echo *echo.Echo) error {
+	if err := ReloadOIDC(config.OIDC, db); err != nil {
+		return err
+	}
+	// The routes are registered even when OIDC is disabled, so that reloading the configuration
+	// can enable it.
+	echo.GET(OIDCSSOPath, withOIDC((*oidcService).sso))
+	echo.GET(OIDCCallbackPath, withOIDC((*oidcService).callback))
 	return nil
 }
+
+var (
+	oidcMu sync.RWMutex
+	oidc   *oidcService
+)
+
+// ReloadOIDC replaces the OIDC settings used for new logins. If the provider cannot be reached
+// with the new settings, the old ones are kept.
+func ReloadOIDC(c config.OIDCConfig, db *db.PgDB) error {
+	var s *oidcService
+	if c.Enabled {
+		var err error
+		if s, err = newOIDCService(c, db); err != nil {
+			return err
+		}
+	}
+	oidcMu.Lock()
+	defer oidcMu.Unlock()
+	oidc = s
+	return nil
+}
+
+func withOIDC(handler func(*oidcService, echo.Context) error) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		oidcMu.RLock()
+		s := oidc
+		oidcMu.RUnlock()
+		if s == nil {
+			return echo.ErrNotFound
+		}
+		return handler(s, c)
+	}
+}
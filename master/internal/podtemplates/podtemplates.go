@@ -0,0 +1,178 @@
+// Package podtemplates stores named pod specs that Kubernetes tasks reference with
+// pod_template_name in their environment, instead of repeating the same pod_spec in every config.
+package podtemplates
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	k8sV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typedV1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/check"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+	"github.com/determined-ai/determined/proto/pkg/podtemplatev1"
+)
+
+// validationImage stands in for the image of the task container when a template is checked
+// against the cluster, since the image comes from the config of each task.
+const validationImage = "busybox"
+
+var namePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9._-]*[a-z0-9])?$`)
+
+// PodTemplate corresponds to a row in the "pod_templates" DB table.
+type PodTemplate struct {
+	bun.BaseModel `bun:"table:pod_templates"`
+
+	Name        string    `bun:"name,pk"`
+	Spec        k8sV1.Pod `bun:"spec,notnull"`
+	Description string    `bun:"description,notnull"`
+	CreatedAt   time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt   time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+}
+
+// FromProto parses a template from its protobuf representation. The spec must be a pod in the
+// format of pod_spec, without unknown fields.
+func FromProto(pt *podtemplatev1.PodTemplate) (*PodTemplate, error) {
+	t := &PodTemplate{Name: pt.Name, Description: pt.Description}
+	if pt.Spec == nil {
+		return nil, errors.New("spec is required")
+	}
+	spec, err := pt.Spec.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(spec))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&t.Spec); err != nil {
+		return nil, errors.Wrap(err, "invalid spec")
+	}
+	return t, nil
+}
+
+// Proto returns the protobuf representation of the template.
+func (t PodTemplate) Proto() (*podtemplatev1.PodTemplate, error) {
+	raw, err := json.Marshal(t.Spec)
+	if err != nil {
+		return nil, err
+	}
+	spec := &structpb.Struct{}
+	if err := spec.UnmarshalJSON(raw); err != nil {
+		return nil, err
+	}
+	return &podtemplatev1.PodTemplate{
+		Name:        t.Name,
+		Spec:        spec,
+		Description: t.Description,
+		CreatedAt:   timestamppb.New(t.CreatedAt),
+		UpdatedAt:   timestamppb.New(t.UpdatedAt),
+	}, nil
+}
+
+// Validate checks the name of the template, and that its spec only sets what a pod_spec may.
+func (t PodTemplate) Validate() []error {
+	errs := []error{
+		check.True(namePattern.MatchString(t.Name),
+			"name must consist of lower case alphanumeric characters, '-', '_' or '.'"),
+	}
+	return append(errs, model.Environment{PodSpec: &t.Spec}.Validate()...)
+}
+
+// ValidateInCluster checks that the cluster accepts pods built from the template by creating one
+// in dry run mode, which runs the validation and admission checks of the cluster without
+// persisting anything.
+func (t PodTemplate) ValidateInCluster(ctx context.Context, pods typedV1.PodInterface) error {
+	pod := t.Spec.DeepCopy()
+	pod.Name = "determined-pod-template-" + t.Name
+	found := false
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == model.DeterminedK8ContainerName {
+			pod.Spec.Containers[i].Image = validationImage
+			found = true
+		}
+	}
+	if !found {
+		pod.Spec.Containers = append(pod.Spec.Containers, k8sV1.Container{
+			Name:  model.DeterminedK8ContainerName,
+			Image: validationImage,
+		})
+	}
+	_, err := pods.Create(ctx, pod, metaV1.CreateOptions{DryRun: []string{metaV1.DryRunAll}})
+	return errors.Wrap(err, "pod template rejected by the cluster")
+}
+
+// Put adds a template to the DB, or replaces the template with the same name.
+func Put(ctx context.Context, t *PodTemplate) error {
+	_, err := db.Bun().NewInsert().Model(t).
+		On("CONFLICT (name) DO UPDATE").
+		Set("spec = EXCLUDED.spec").
+		Set("description = EXCLUDED.description").
+		Set("updated_at = now()").
+		Returning("created_at, updated_at").
+		Exec(ctx)
+	return err
+}
+
+// Get returns a template from the DB, or db.ErrNotFound.
+func Get(ctx context.Context, name string) (*PodTemplate, error) {
+	var t PodTemplate
+	if err := db.Bun().NewSelect().Model(&t).Where("name = ?", name).Scan(ctx); err != nil {
+		return nil, db.MatchSentinelError(err)
+	}
+	return &t, nil
+}
+
+// List returns the templates in the DB, by name.
+func List(ctx context.Context) ([]PodTemplate, error) {
+	ts := []PodTemplate{}
+	if err := db.Bun().NewSelect().Model(&ts).Order("name").Scan(ctx); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+// Delete deletes a template from the DB. Tasks that already started keep their pod spec.
+func Delete(ctx context.Context, name string) error {
+	res, err := db.Bun().NewDelete().Model((*PodTemplate)(nil)).Where("name = ?", name).Exec(ctx)
+	return db.MustHaveAffectedRows(res, err)
+}
+
+// Resolve returns the pod spec of a task that names a template: the pod spec of the task merged
+// over the template, so that the fields it sets take precedence.
+func Resolve(ctx context.Context, name string, podSpec *k8sV1.Pod) (*k8sV1.Pod, error) {
+	t, err := Get(ctx, name)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, errors.Errorf("pod template %q not found", name)
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "getting pod template %q", name)
+	}
+	if podSpec == nil {
+		return &t.Spec, nil
+	}
+	merged := expconf.PodSpec(*podSpec).Merge(expconf.PodSpec(t.Spec)).(expconf.PodSpec)
+	return (*k8sV1.Pod)(&merged), nil
+}
+
+// ResolveEnvironment replaces the pod spec of an environment that names a template with the
+// result of Resolve.
+func ResolveEnvironment(ctx context.Context, env *expconf.EnvironmentConfig) error {
+	if env == nil || env.RawPodTemplateName == nil {
+		return nil
+	}
+	podSpec, err := Resolve(ctx, *env.RawPodTemplateName, (*k8sV1.Pod)(env.RawPodSpec))
+	if err != nil {
+		return err
+	}
+	env.RawPodSpec = (*expconf.PodSpec)(podSpec)
+	return nil
+}
@@ -0,0 +1,74 @@
+package podtemplates
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+	k8sV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+	k8sTesting "k8s.io/client-go/testing"
+
+	"github.com/determined-ai/determined/master/pkg/check"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/podtemplatev1"
+)
+
+func template(t *testing.T, name string, spec map[string]interface{}) *PodTemplate {
+	s, err := structpb.NewStruct(spec)
+	require.NoError(t, err)
+	pt, err := FromProto(&podtemplatev1.PodTemplate{Name: name, Spec: s})
+	require.NoError(t, err)
+	return pt
+}
+
+func TestFromProto(t *testing.T) {
+	pt := template(t, "gpu-nodes", map[string]interface{}{
+		"spec": map[string]interface{}{
+			"nodeSelector": map[string]interface{}{"pool": "gpu"},
+		},
+	})
+	require.Equal(t, map[string]string{"pool": "gpu"}, pt.Spec.Spec.NodeSelector)
+	require.NoError(t, check.Validate(pt))
+
+	s, err := structpb.NewStruct(map[string]interface{}{"spce": map[string]interface{}{}})
+	require.NoError(t, err)
+	_, err = FromProto(&podtemplatev1.PodTemplate{Name: "typo", Spec: s})
+	require.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	require.Error(t, check.Validate(template(t, "Not A Name", map[string]interface{}{})))
+	require.Error(t, check.Validate(template(t, "host", map[string]interface{}{
+		"spec": map[string]interface{}{"hostNetwork": true},
+	})))
+}
+
+func TestValidateInCluster(t *testing.T) {
+	pt := template(t, "gpu-nodes", map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name":         model.DeterminedK8ContainerName,
+					"volumeMounts": []interface{}{map[string]interface{}{"name": "data"}},
+				},
+			},
+		},
+	})
+
+	clientSet := k8sFake.NewSimpleClientset()
+	var created *k8sV1.Pod
+	clientSet.PrependReactor("create", "pods",
+		func(action k8sTesting.Action) (bool, runtime.Object, error) {
+			created = action.(k8sTesting.CreateAction).GetObject().(*k8sV1.Pod)
+			return false, nil, nil
+		})
+	require.NoError(t, pt.ValidateInCluster(context.Background(), clientSet.CoreV1().Pods("det")))
+	require.Len(t, created.Spec.Containers, 1)
+	require.Equal(t, validationImage, created.Spec.Containers[0].Image)
+	require.Len(t, created.Spec.Containers[0].VolumeMounts, 1)
+	// The template itself is left alone.
+	require.Empty(t, pt.Spec.Spec.Containers[0].Image)
+}
@@ -0,0 +1,192 @@
+// Package preflight checks that the external services the master and its tasks depend on, such as
+// checkpoint storage and container registries, can be reached with the configured settings, so
+// that misconfigurations are found when the master starts rather than by the first task to run.
+package preflight
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/internal/objectstore"
+)
+
+// dockerHubRegistry is the registry that images without a registry are pulled from.
+const dockerHubRegistry = "https://registry-1.docker.io"
+
+// Storage checks that the master can write, read, and delete files in the store.
+func Storage(ctx context.Context, s objectstore.Store) error {
+	if err := s.Check(ctx); err != nil {
+		return errors.Wrap(err, "cannot reach the storage; check its location and credentials")
+	}
+
+	f, err := os.CreateTemp("", "determined-preflight")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	if _, err = f.WriteString("determined preflight check\n"); err != nil {
+		return err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf(".determined-preflight-%s", uuid.New())
+	if err := s.Upload(ctx, key, f); err != nil {
+		return errors.Wrap(err, "cannot write to the storage; check that the master may write to it")
+	}
+	exists, err := s.Exists(ctx, key)
+	switch {
+	case err != nil:
+		return errors.Wrap(err, "cannot read from the storage; check that the master may read it")
+	case !exists:
+		return errors.Errorf("cannot find %s after writing it to the storage", key)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		return errors.Wrapf(err,
+			"cannot delete from the storage; check that the master may delete %s", key)
+	}
+	return nil
+}
+
+// Registry checks that the container registry can be reached and accepts the credentials, if
+// any, by authenticating as a docker pull would.
+func Registry(ctx context.Context, auth types.AuthConfig) error {
+	base, err := registryURL(auth.ServerAddress)
+	if err != nil {
+		return err
+	}
+	username, password, err := credentials(auth)
+	if err != nil {
+		return err
+	}
+
+	resp, err := get(ctx, base+"/v2/", "", "")
+	if err != nil {
+		return errors.Wrapf(err, "cannot reach registry %s", base)
+	}
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode != http.StatusUnauthorized:
+		return errors.Errorf("registry %s returned %s", base, resp.Status)
+	case username == "":
+		// Registries that require logging in even to pull public images are still reachable.
+		return nil
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	scheme, params := parseChallenge(challenge)
+	var authURL string
+	switch strings.ToLower(scheme) {
+	case "basic":
+		authURL = base + "/v2/"
+	case "bearer":
+		realm, err := url.Parse(params["realm"])
+		if err != nil || realm.Host == "" {
+			return errors.Errorf("registry %s returned an invalid challenge %q", base, challenge)
+		}
+		if service, ok := params["service"]; ok {
+			q := realm.Query()
+			q.Set("service", service)
+			realm.RawQuery = q.Encode()
+		}
+		authURL = realm.String()
+	default:
+		return errors.Errorf("registry %s returned an unsupported challenge %q", base, challenge)
+	}
+
+	resp, err = get(ctx, authURL, username, password)
+	if err != nil {
+		return errors.Wrapf(err, "cannot reach the authentication service of registry %s", base)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errors.Errorf("registry %s rejected the credentials of %s; check registry_auth",
+			base, username)
+	default:
+		return errors.Errorf("the authentication service of registry %s returned %s",
+			base, resp.Status)
+	}
+}
+
+// registryURL returns the base URL of the registry at the address of a registry_auth, which, like
+// the address given to docker login, may leave out the scheme and may be the legacy address of
+// Docker Hub.
+func registryURL(address string) (string, error) {
+	switch address {
+	case "", "docker.io", "index.docker.io", "https://index.docker.io/v1/":
+		return dockerHubRegistry, nil
+	}
+	if !strings.Contains(address, "://") {
+		address = "https://" + address
+	}
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" {
+		return "", errors.Errorf("invalid registry address %q", address)
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
+
+// credentials returns the username and password of a registry_auth, which may instead hold them
+// encoded together.
+func credentials(auth types.AuthConfig) (string, string, error) {
+	if auth.Username != "" || auth.Auth == "" {
+		return auth.Username, auth.Password, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+	if err != nil {
+		return "", "", errors.Wrap(err, "invalid registry_auth.auth")
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", "", errors.New("invalid registry_auth.auth: expected username:password")
+	}
+	return username, password, nil
+}
+
+func get(ctx context.Context, u, username, password string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return resp, nil
+}
+
+// parseChallenge parses a WWW-Authenticate header such as
+// `Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`.
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+	for _, param := range strings.Split(rest, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if ok {
+			params[strings.ToLower(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return scheme, params
+}
@@ -0,0 +1,98 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+// memStore is an objectstore.Store that keeps files in memory.
+type memStore struct {
+	files    map[string]bool
+	readOnly bool
+}
+
+func (s *memStore) Upload(_ context.Context, key string, _ *os.File) error {
+	if s.readOnly {
+		return fmt.Errorf("access denied")
+	}
+	s.files[key] = true
+	return nil
+}
+
+func (s *memStore) Download(context.Context, string, *os.File) error { return nil }
+
+func (s *memStore) Exists(_ context.Context, key string) (bool, error) {
+	return s.files[key], nil
+}
+
+func (s *memStore) Delete(_ context.Context, key string) error {
+	delete(s.files, key)
+	return nil
+}
+
+func (s *memStore) Check(context.Context) error { return nil }
+
+func TestStorage(t *testing.T) {
+	s := &memStore{files: map[string]bool{}}
+	require.NoError(t, Storage(context.Background(), s))
+	require.Empty(t, s.files, "the test file should be deleted")
+
+	s.readOnly = true
+	require.ErrorContains(t, Storage(context.Background(), s), "cannot write to the storage")
+}
+
+func TestRegistry(t *testing.T) {
+	var tokenServer *httptest.Server
+	tokenServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "det" || pass != "secret" ||
+			r.URL.Query().Get("service") != "registry.example.com" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"token": "t"}`))
+	}))
+	defer tokenServer.Close()
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+			`Bearer realm="%s/token",service="registry.example.com"`, tokenServer.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer registry.Close()
+
+	ctx := context.Background()
+	require.NoError(t, Registry(ctx, types.AuthConfig{
+		ServerAddress: registry.URL, Username: "det", Password: "secret",
+	}))
+	// Credentials may also be given encoded together.
+	require.NoError(t, Registry(ctx, types.AuthConfig{
+		ServerAddress: registry.URL, Auth: "ZGV0OnNlY3JldA==",
+	}))
+	require.ErrorContains(t, Registry(ctx, types.AuthConfig{
+		ServerAddress: registry.URL, Username: "det", Password: "wrong",
+	}), "rejected the credentials")
+
+	registry.Close()
+	require.ErrorContains(t, Registry(ctx, types.AuthConfig{
+		ServerAddress: registry.URL, Username: "det", Password: "secret",
+	}), "cannot reach registry")
+}
+
+func TestRegistryURL(t *testing.T) {
+	for address, expected := range map[string]string{
+		"":                            dockerHubRegistry,
+		"https://index.docker.io/v1/": dockerHubRegistry,
+		"registry.example.com:5000":   "https://registry.example.com:5000",
+		"http://localhost:5000/v2/":   "http://localhost:5000",
+	} {
+		actual, err := registryURL(address)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual, address)
+	}
+}
@@ -0,0 +1,107 @@
+package project
+
+import (
+	"context"
+
+	"github.com/determined-ai/determined/master/internal/workspace"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/projectv1"
+	"github.com/determined-ai/determined/proto/pkg/workspacev1"
+)
+
+// ProjectAuthZRoles controls access to projects by the roles of users in them and their
+// workspaces. Editors of a workspace can create projects in it, editors of a project can write
+// its notes, and admins of a project can change the rest of it.
+type ProjectAuthZRoles struct{}
+
+// CanGetProject returns whether the user has a role in the project.
+func (a *ProjectAuthZRoles) CanGetProject(
+	ctx context.Context, curUser model.User, project *projectv1.Project,
+) (canGetProject bool, serverError error) {
+	role, err := workspace.ProjectRole(ctx, curUser, int(project.Id))
+	if err != nil {
+		return false, err
+	}
+	return role.AtLeast(model.MemberRoleViewer), nil
+}
+
+// CanCreateProject requires the user to be an editor of the workspace.
+func (a *ProjectAuthZRoles) CanCreateProject(
+	ctx context.Context, curUser model.User, willBeInWorkspace *workspacev1.Workspace,
+) error {
+	return workspace.RequireWorkspaceRole(
+		ctx, curUser, int(willBeInWorkspace.Id), model.MemberRoleEditor)
+}
+
+// CanSetProjectNotes requires the user to be an editor of the project.
+func (a *ProjectAuthZRoles) CanSetProjectNotes(
+	ctx context.Context, curUser model.User, project *projectv1.Project,
+) error {
+	return workspace.RequireProjectRole(ctx, curUser, int(project.Id), model.MemberRoleEditor)
+}
+
+// CanSetProjectName requires the user to be an admin of the project.
+func (a *ProjectAuthZRoles) CanSetProjectName(
+	ctx context.Context, curUser model.User, project *projectv1.Project,
+) error {
+	return workspace.RequireProjectRole(ctx, curUser, int(project.Id), model.MemberRoleAdmin)
+}
+
+// CanSetProjectDescription requires the user to be an admin of the project.
+func (a *ProjectAuthZRoles) CanSetProjectDescription(
+	ctx context.Context, curUser model.User, project *projectv1.Project,
+) error {
+	return workspace.RequireProjectRole(ctx, curUser, int(project.Id), model.MemberRoleAdmin)
+}
+
+// CanDeleteProject requires the user to be an admin of the project.
+func (a *ProjectAuthZRoles) CanDeleteProject(
+	ctx context.Context, curUser model.User, targetProject *projectv1.Project,
+) error {
+	return workspace.RequireProjectRole(
+		ctx, curUser, int(targetProject.Id), model.MemberRoleAdmin)
+}
+
+// CanMoveProject requires the user to be an admin of the project and an editor of the workspace
+// it moves to.
+func (a *ProjectAuthZRoles) CanMoveProject(
+	ctx context.Context,
+	curUser model.User,
+	project *projectv1.Project,
+	from, to *workspacev1.Workspace,
+) error {
+	if err := workspace.RequireProjectRole(
+		ctx, curUser, int(project.Id), model.MemberRoleAdmin); err != nil {
+		return err
+	}
+	return workspace.RequireWorkspaceRole(ctx, curUser, int(to.Id), model.MemberRoleEditor)
+}
+
+// CanMoveProjectExperiments requires the user to be an editor of both projects.
+func (a *ProjectAuthZRoles) CanMoveProjectExperiments(
+	ctx context.Context, curUser model.User, exp *model.Experiment, from, to *projectv1.Project,
+) error {
+	if err := workspace.RequireProjectRole(
+		ctx, curUser, int(from.Id), model.MemberRoleEditor); err != nil {
+		return err
+	}
+	return workspace.RequireProjectRole(ctx, curUser, int(to.Id), model.MemberRoleEditor)
+}
+
+// CanArchiveProject requires the user to be an admin of the project.
+func (a *ProjectAuthZRoles) CanArchiveProject(
+	ctx context.Context, curUser model.User, project *projectv1.Project,
+) error {
+	return workspace.RequireProjectRole(ctx, curUser, int(project.Id), model.MemberRoleAdmin)
+}
+
+// CanUnarchiveProject requires the user to be an admin of the project.
+func (a *ProjectAuthZRoles) CanUnarchiveProject(
+	ctx context.Context, curUser model.User, project *projectv1.Project,
+) error {
+	return workspace.RequireProjectRole(ctx, curUser, int(project.Id), model.MemberRoleAdmin)
+}
+
+func init() {
+	AuthZProvider.Register("roles", &ProjectAuthZRoles{})
+}
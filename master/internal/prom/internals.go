@@ -0,0 +1,160 @@
+package prom
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/determined-ai/determined/master/pkg/actor"
+)
+
+// Metrics about the internals of the master, as opposed to the tasks it runs. These are exported
+// with the default registry, alongside the Go runtime and API request metrics.
+var (
+	schedulerQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: "det",
+		Name:      "scheduler_queue_depth",
+		Help:      "the number of jobs in a resource pool by whether they are queued or scheduled",
+	}, []string{"resource_pool", "state"})
+
+	schedulerPassSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: "det",
+		Name:      "scheduler_pass_seconds",
+		Help:      "how long a pass of the scheduler over a resource pool takes",
+	}, []string{"resource_pool"})
+
+	allocationSchedulingSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: "det",
+		Name:      "allocation_scheduling_seconds",
+		Help:      "how long allocations wait between requesting resources and receiving them",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 4, 10),
+	}, []string{"resource_pool"})
+
+	allocationStartupSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: "det",
+		Name:      "allocation_startup_seconds",
+		Help:      "how long allocations take between receiving resources and running on them",
+		Buckets:   prometheus.ExponentialBuckets(0.5, 2, 12),
+	}, []string{"resource_pool"})
+
+	taskLogLinesIngested = promauto.NewCounter(prometheus.CounterOpts{
+		Subsystem: "det",
+		Name:      "task_log_lines_ingested_total",
+		Help:      "the number of task log lines stored by the logging backend",
+	})
+
+	taskLogIngestErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Subsystem: "det",
+		Name:      "task_log_ingest_errors_total",
+		Help:      "the number of batches of task logs the logging backend failed to store",
+	})
+)
+
+// SetSchedulerQueueDepth records how many jobs are queued and scheduled in a resource pool.
+func SetSchedulerQueueDepth(resourcePool string, queued, scheduled int) {
+	schedulerQueueDepth.WithLabelValues(resourcePool, "queued").Set(float64(queued))
+	schedulerQueueDepth.WithLabelValues(resourcePool, "scheduled").Set(float64(scheduled))
+}
+
+// ObserveSchedulerPass records how long a pass of the scheduler over a resource pool took.
+func ObserveSchedulerPass(resourcePool string, d time.Duration) {
+	schedulerPassSeconds.WithLabelValues(resourcePool).Observe(d.Seconds())
+}
+
+// ObserveAllocationScheduled records how long an allocation waited for its resources.
+func ObserveAllocationScheduled(resourcePool string, d time.Duration) {
+	allocationSchedulingSeconds.WithLabelValues(resourcePool).Observe(d.Seconds())
+}
+
+// ObserveAllocationStarted records how long an allocation took to start running once it had its
+// resources.
+func ObserveAllocationStarted(resourcePool string, d time.Duration) {
+	allocationStartupSeconds.WithLabelValues(resourcePool).Observe(d.Seconds())
+}
+
+// ObserveTaskLogsIngested records an attempt to store a batch of task logs.
+func ObserveTaskLogsIngested(lines int, err error) {
+	if err != nil {
+		taskLogIngestErrors.Inc()
+		return
+	}
+	taskLogLinesIngested.Add(float64(lines))
+}
+
+// RegisterInternals registers the metrics that are collected on demand, from the connection pool
+// of the database and the inboxes of the actor system, with the default registry.
+func RegisterInternals(system *actor.System, dbStats func() sql.DBStats) {
+	prometheus.MustRegister(&dbStatsCollector{stats: dbStats}, &actorCollector{system: system})
+}
+
+var (
+	dbMaxOpenDesc = prometheus.NewDesc("det_db_pool_max_open_connections",
+		"the maximum number of open connections to the database", nil, nil)
+	dbOpenDesc = prometheus.NewDesc("det_db_pool_open_connections",
+		"the number of open connections to the database by state",
+		[]string{"state"}, nil)
+	dbWaitCountDesc = prometheus.NewDesc("det_db_pool_wait_count_total",
+		"the number of times a query waited for a free connection", nil, nil)
+	dbWaitSecondsDesc = prometheus.NewDesc("det_db_pool_wait_seconds_total",
+		"the total time queries waited for a free connection", nil, nil)
+	dbClosedDesc = prometheus.NewDesc("det_db_pool_closed_connections_total",
+		"the number of database connections closed by reason",
+		[]string{"reason"}, nil)
+
+	actorsDesc = prometheus.NewDesc("det_actors",
+		"the number of actors by Go type", []string{"type"}, nil)
+	actorInboxMessagesDesc = prometheus.NewDesc("det_actor_inbox_messages",
+		"the number of messages waiting in the inboxes of actors by Go type", []string{"type"}, nil)
+)
+
+// dbStatsCollector exports how saturated the connection pool of the database is.
+type dbStatsCollector struct {
+	stats func() sql.DBStats
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dbMaxOpenDesc
+	ch <- dbOpenDesc
+	ch <- dbWaitCountDesc
+	ch <- dbWaitSecondsDesc
+	ch <- dbClosedDesc
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(
+		dbMaxOpenDesc, prometheus.GaugeValue, float64(s.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(dbOpenDesc, prometheus.GaugeValue, float64(s.InUse), "in_use")
+	ch <- prometheus.MustNewConstMetric(dbOpenDesc, prometheus.GaugeValue, float64(s.Idle), "idle")
+	ch <- prometheus.MustNewConstMetric(
+		dbWaitCountDesc, prometheus.CounterValue, float64(s.WaitCount))
+	ch <- prometheus.MustNewConstMetric(
+		dbWaitSecondsDesc, prometheus.CounterValue, s.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(
+		dbClosedDesc, prometheus.CounterValue, float64(s.MaxIdleClosed), "max_idle_conns")
+	ch <- prometheus.MustNewConstMetric(
+		dbClosedDesc, prometheus.CounterValue, float64(s.MaxIdleTimeClosed), "conn_max_idle_time")
+	ch <- prometheus.MustNewConstMetric(
+		dbClosedDesc, prometheus.CounterValue, float64(s.MaxLifetimeClosed), "conn_max_lifetime")
+}
+
+// actorCollector exports how many actors there are and how far behind they are on their messages.
+type actorCollector struct {
+	system *actor.System
+}
+
+func (c *actorCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- actorsDesc
+	ch <- actorInboxMessagesDesc
+}
+
+func (c *actorCollector) Collect(ch chan<- prometheus.Metric) {
+	for typeName, b := range c.system.Backlogs() {
+		ch <- prometheus.MustNewConstMetric(
+			actorsDesc, prometheus.GaugeValue, float64(b.Actors), typeName)
+		ch <- prometheus.MustNewConstMetric(
+			actorInboxMessagesDesc, prometheus.GaugeValue, float64(b.Messages), typeName)
+	}
+}
@@ -0,0 +1,54 @@
+package prom
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveTaskLogsIngested(t *testing.T) {
+	lines, errs := testutil.ToFloat64(taskLogLinesIngested), testutil.ToFloat64(taskLogIngestErrors)
+	ObserveTaskLogsIngested(3, nil)
+	ObserveTaskLogsIngested(5, errors.New("backend down"))
+	require.Equal(t, lines+3, testutil.ToFloat64(taskLogLinesIngested))
+	require.Equal(t, errs+1, testutil.ToFloat64(taskLogIngestErrors))
+}
+
+func TestDBStatsCollector(t *testing.T) {
+	c := &dbStatsCollector{stats: func() sql.DBStats {
+		return sql.DBStats{
+			MaxOpenConnections: 10,
+			InUse:              4,
+			Idle:               2,
+			WaitCount:          7,
+			WaitDuration:       1500 * time.Millisecond,
+			MaxIdleClosed:      3,
+			MaxLifetimeClosed:  5,
+		}
+	}}
+	expected := `
+# HELP det_db_pool_closed_connections_total the number of database connections closed by reason
+# TYPE det_db_pool_closed_connections_total counter
+det_db_pool_closed_connections_total{reason="conn_max_idle_time"} 0
+det_db_pool_closed_connections_total{reason="conn_max_lifetime"} 5
+det_db_pool_closed_connections_total{reason="max_idle_conns"} 3
+# HELP det_db_pool_max_open_connections the maximum number of open connections to the database
+# TYPE det_db_pool_max_open_connections gauge
+det_db_pool_max_open_connections 10
+# HELP det_db_pool_open_connections the number of open connections to the database by state
+# TYPE det_db_pool_open_connections gauge
+det_db_pool_open_connections{state="idle"} 2
+det_db_pool_open_connections{state="in_use"} 4
+# HELP det_db_pool_wait_seconds_total the total time queries waited for a free connection
+# TYPE det_db_pool_wait_seconds_total counter
+det_db_pool_wait_seconds_total 1.5
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"det_db_pool_closed_connections_total", "det_db_pool_max_open_connections",
+		"det_db_pool_open_connections", "det_db_pool_wait_seconds_total"))
+}
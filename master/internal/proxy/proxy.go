@@ -1,11 +1,14 @@
 package proxy
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,6 +17,15 @@ import (
 	"github.com/determined-ai/determined/master/pkg/actor"
 )
 
+const (
+	// serviceRetryInterval is how often a request checks whether its service has been registered.
+	serviceRetryInterval = 500 * time.Millisecond
+	// dialRetryInterval and maxDialRetryInterval bound the backoff between attempts to connect to
+	// a service.
+	dialRetryInterval    = 100 * time.Millisecond
+	maxDialRetryInterval = 2 * time.Second
+)
+
 // Proxy-specific actor messages.
 type (
 	// Register registers the service name with the associated target URL. All requests with the
@@ -23,6 +35,9 @@ type (
 		URL             *url.URL
 		ProxyTCP        bool
 		Unauthenticated bool
+		// StripPrefix forwards requests without their "/proxy/:service-name" prefix, for services
+		// that cannot be told the path they are served from.
+		StripPrefix bool
 	}
 	// Unregister removes the service from the proxy. All future requests until the service name is
 	// registered again will be responded with a 404 response. If the service is not registered with
@@ -43,6 +58,7 @@ type Service struct {
 	LastRequested        time.Time
 	ProxyTCP             bool
 	AllowUnauthenticated bool
+	StripPrefix          bool
 }
 
 // ProxyHTTPAuth processes a proxy request, returning true if the request should terminate
@@ -53,8 +69,16 @@ type ProxyHTTPAuth func(echo.Context) (done bool, err error)
 type Proxy struct {
 	lock     sync.RWMutex
 	services map[string]*Service
+	// started is when the proxy started; services may not be registered again until a while after
+	// the master restarts.
+	started   time.Time
+	transport *http.Transport
 
 	HTTPAuth ProxyHTTPAuth
+	// KeepaliveInterval is how often proxied websocket connections are pinged, if it is positive.
+	KeepaliveInterval time.Duration
+	// ReconnectTimeout is how long requests keep trying to reach their service.
+	ReconnectTimeout time.Duration
 }
 
 // Receive implements the actor.Actor interface.
@@ -62,6 +86,9 @@ func (p *Proxy) Receive(ctx *actor.Context) error {
 	switch msg := ctx.Message().(type) {
 	case actor.PreStart:
 		p.services = make(map[string]*Service)
+		p.started = time.Now()
+		p.transport = http.DefaultTransport.(*http.Transport).Clone()
+		p.transport.DialContext = p.dial
 	case Register:
 		if msg.ServiceID == "" {
 			return nil
@@ -74,6 +101,7 @@ func (p *Proxy) Receive(ctx *actor.Context) error {
 			LastRequested:        time.Now(),
 			ProxyTCP:             msg.ProxyTCP,
 			AllowUnauthenticated: msg.Unauthenticated,
+			StripPrefix:          msg.StripPrefix,
 		}
 
 		if ctx.ExpectingResponse() {
@@ -112,6 +140,7 @@ func (p *Proxy) getService(serviceName string) *Service {
 		LastRequested:        service.LastRequested,
 		ProxyTCP:             service.ProxyTCP,
 		AllowUnauthenticated: service.AllowUnauthenticated,
+		StripPrefix:          service.StripPrefix,
 	}
 }
 
@@ -120,7 +149,7 @@ func (p *Proxy) newProxyHandler(serviceID string) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		// Look up the service name in the url path.
 		serviceName := c.Param(serviceID)
-		service := p.getService(serviceName)
+		service := p.awaitService(c.Request().Context(), serviceName)
 
 		if service == nil {
 			return echo.NewHTTPError(http.StatusNotFound,
@@ -147,16 +176,24 @@ func (p *Proxy) newProxyHandler(serviceID string) echo.HandlerFunc {
 		if c.IsWebSocket() && req.Header.Get(echo.HeaderXForwardedFor) == "" {
 			req.Header.Set(echo.HeaderXForwardedFor, c.RealIP())
 		}
+		if service.StripPrefix {
+			prefix := fmt.Sprintf("/proxy/%s", serviceName)
+			req.Header.Set("X-Forwarded-Prefix", prefix)
+			req.URL.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, prefix), "/")
+			req.URL.RawPath = ""
+		}
 
 		// Proxy the request to the target host.
 		var proxy http.Handler
 		switch {
 		case service.ProxyTCP:
-			proxy = newSingleHostReverseTCPOverWebSocketProxy(c, service.URL)
+			proxy = p.newSingleHostReverseTCPOverWebSocketProxy(c, service.URL)
 		case c.IsWebSocket():
-			proxy = newSingleHostReverseWebSocketProxy(c, service.URL)
+			proxy = p.newSingleHostReverseWebSocketProxy(c, service.URL)
 		default:
-			proxy = httputil.NewSingleHostReverseProxy(service.URL)
+			httpProxy := httputil.NewSingleHostReverseProxy(service.URL)
+			httpProxy.Transport = p.transport
+			proxy = httpProxy
 		}
 		proxy.ServeHTTP(c.Response(), req)
 
@@ -164,6 +201,50 @@ func (p *Proxy) newProxyHandler(serviceID string) echo.HandlerFunc {
 	}
 }
 
+// awaitService returns the service with the given name. Until the proxy has run for
+// ReconnectTimeout, it waits for services that are not registered yet, since the allocations of
+// services that were running when the master restarted register them again as they are restored.
+func (p *Proxy) awaitService(ctx context.Context, serviceName string) *Service {
+	deadline := p.started.Add(p.ReconnectTimeout)
+	for {
+		if service := p.getService(serviceName); service != nil || time.Now().After(deadline) {
+			return service
+		}
+		select {
+		case <-time.After(serviceRetryInterval):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// dial connects to a service, retrying for up to ReconnectTimeout so that brief network problems
+// between the master and the service do not fail requests. Nothing has been sent to the service
+// until it connects, so retrying is safe for any request.
+func (p *Proxy) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	var dialer net.Dialer
+	if p.ReconnectTimeout <= 0 {
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.ReconnectTimeout)
+	defer cancel()
+	for backoff := dialRetryInterval; ; backoff *= 2 {
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err == nil {
+			return conn, nil
+		}
+		if backoff > maxDialRetryInterval {
+			backoff = maxDialRetryInterval
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, err
+		}
+	}
+}
+
 func (p *Proxy) getSummary() map[string]Service {
 	p.lock.RLock()
 	defer p.lock.RUnlock()
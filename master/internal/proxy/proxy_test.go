@@ -0,0 +1,167 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+// unusedAddr returns an address that nothing is listening on.
+func unusedAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	return addr
+}
+
+func TestDialRetries(t *testing.T) {
+	addr := unusedAddr(t)
+
+	// Without a timeout, a service that is not listening fails right away.
+	p := &Proxy{}
+	_, err := p.dial(context.Background(), "tcp", addr)
+	require.Error(t, err)
+
+	// With one, connecting is retried until the service listens.
+	listening := make(chan net.Listener, 1)
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			close(listening)
+			return
+		}
+		listening <- l
+	}()
+	p = &Proxy{ReconnectTimeout: 10 * time.Second}
+	conn, err := p.dial(context.Background(), "tcp", addr)
+	l, ok := <-listening
+	require.True(t, ok, "failed to listen on %s", addr)
+	defer func() { require.NoError(t, l.Close()) }()
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
+
+func TestAwaitService(t *testing.T) {
+	p := &Proxy{
+		services:         map[string]*Service{},
+		started:          time.Now(),
+		ReconnectTimeout: 10 * time.Second,
+	}
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		p.services["restored"] = &Service{URL: &url.URL{Host: "restored:8080"}}
+	}()
+	service := p.awaitService(context.Background(), "restored")
+	require.NotNil(t, service)
+	require.Equal(t, "restored:8080", service.URL.Host)
+
+	// Requests made once the master has run for the timeout do not wait.
+	p.started = time.Now().Add(-p.ReconnectTimeout)
+	start := time.Now()
+	require.Nil(t, p.awaitService(context.Background(), "missing"))
+	require.Less(t, time.Since(start), serviceRetryInterval)
+}
+
+func TestWebSocketProxy(t *testing.T) {
+	// The service echoes messages, and drops the connection without closing it when asked to.
+	service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			msgType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if string(data) == "drop" {
+				_ = conn.UnderlyingConn().Close()
+				return
+			}
+			if err := conn.WriteMessage(msgType, data); err != nil {
+				return
+			}
+		}
+	}))
+	defer service.Close()
+	serviceURL, err := url.Parse(service.URL)
+	require.NoError(t, err)
+
+	p := &Proxy{KeepaliveInterval: time.Second}
+	e := echo.New()
+	e.GET("/*", func(c echo.Context) error {
+		p.newSingleHostReverseWebSocketProxy(c, serviceURL).ServeHTTP(c.Response(), c.Request())
+		return nil
+	})
+	master := httptest.NewServer(e)
+	defer master.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial(
+		"ws"+strings.TrimPrefix(master.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte("hello")))
+	_, data, err := client.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(data))
+
+	// When the service goes away, the client is told to reconnect.
+	require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte("drop")))
+	_, _, err = client.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.CloseServiceRestart), err)
+}
+
+func TestStripPrefix(t *testing.T) {
+	// The service echoes the path and prefix of the requests it gets.
+	service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path + " " + r.Header.Get("X-Forwarded-Prefix")))
+	}))
+	defer service.Close()
+	serviceURL, err := url.Parse(service.URL)
+	require.NoError(t, err)
+
+	p := &Proxy{
+		services: map[string]*Service{
+			"vscode":   {URL: serviceURL, AllowUnauthenticated: true, StripPrefix: true},
+			"notebook": {URL: serviceURL, AllowUnauthenticated: true},
+		},
+		transport: http.DefaultTransport.(*http.Transport).Clone(),
+	}
+	e := echo.New()
+	e.Any("/proxy/:service/*", p.newProxyHandler("service"))
+	master := httptest.NewServer(e)
+	defer master.Close()
+
+	for _, tc := range []struct {
+		path     string
+		expected string
+	}{
+		{"/proxy/vscode/", "/ /proxy/vscode"},
+		{"/proxy/vscode/static/main.js", "/static/main.js /proxy/vscode"},
+		{"/proxy/notebook/static/main.js", "/proxy/notebook/static/main.js "},
+	} {
+		resp, err := http.Get(master.URL + tc.path)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, resp.Body.Close())
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode, tc.path)
+		require.Equal(t, tc.expected, string(body), tc.path)
+	}
+}
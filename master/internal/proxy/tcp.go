@@ -3,7 +3,6 @@ package proxy
 import (
 	"bytes"
 	"io"
-	"net"
 	"net/http"
 	"net/url"
 
@@ -45,10 +44,12 @@ func (w *websocketReadWriter) Write(buf []byte) (int, error) {
 	return len(buf), nil
 }
 
-func newSingleHostReverseTCPOverWebSocketProxy(c echo.Context, t *url.URL) http.Handler {
+func (p *Proxy) newSingleHostReverseTCPOverWebSocketProxy(
+	c echo.Context, t *url.URL,
+) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Make sure we can open the connection to the remote host.
-		out, err := net.Dial("tcp", t.Host)
+		out, err := p.dial(r.Context(), "tcp", t.Host)
 		if err != nil {
 			c.Error(echo.NewHTTPError(http.StatusBadGateway,
 				errors.Errorf("error dialing to %v: %v", t, err)))
@@ -66,6 +67,8 @@ func newSingleHostReverseTCPOverWebSocketProxy(c echo.Context, t *url.URL) http.
 			return
 		}
 
+		defer keepAlive(ws, p.KeepaliveInterval)()
+
 		rw := &websocketReadWriter{ws: ws, buf: new(bytes.Buffer)}
 		copyReqErr := asyncCopy(rw, out)
 		copyResErr := asyncCopy(out, rw)
@@ -1,53 +1,158 @@
 package proxy
 
 import (
-	"net"
 	"net/http"
 	"net/url"
+	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 )
 
-func newSingleHostReverseWebSocketProxy(c echo.Context, t *url.URL) http.Handler {
+// websocketHandshakeHeaders are set by the websocket dialer itself, so they are not copied from the
+// client's request to the service.
+var websocketHandshakeHeaders = []string{
+	"Upgrade", "Connection", "Sec-Websocket-Key", "Sec-Websocket-Version",
+	"Sec-Websocket-Extensions", "Sec-Websocket-Protocol",
+}
+
+// newSingleHostReverseWebSocketProxy proxies a websocket connection message by message, rather
+// than as a stream of bytes, so that the master can ping both ends to keep the connection from
+// being closed as idle along the way and to notice when either end has gone away. Connections are
+// not resumed once established: the state of a session lives in the connection to the service, so
+// when the service goes away the client is told to reconnect instead.
+func (p *Proxy) newSingleHostReverseWebSocketProxy(c echo.Context, t *url.URL) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		in, _, err := c.Response().Hijack()
-		if err != nil {
-			c.Error(errors.Errorf("error hijacking connection to %v: %v", t, err))
-			return
+		target := url.URL{Scheme: "ws", Host: t.Host, Path: r.URL.Path, RawQuery: r.URL.RawQuery}
+		if t.Scheme == "https" {
+			target.Scheme = "wss"
 		}
-		defer func() {
-			if cerr := in.Close(); cerr != nil {
-				c.Logger().Error(cerr)
-			}
-		}()
-
-		out, err := net.Dial("tcp", t.Host)
+		header := r.Header.Clone()
+		for _, h := range websocketHandshakeHeaders {
+			header.Del(h)
+		}
+		// Services such as Jupyter compare the Origin header with the Host header they are sent.
+		header.Set("Host", r.Host)
+		dialer := websocket.Dialer{
+			NetDialContext:   p.dial,
+			HandshakeTimeout: websocket.DefaultDialer.HandshakeTimeout,
+			Subprotocols:     websocket.Subprotocols(r),
+		}
+		out, resp, err := dialer.DialContext(r.Context(), target.String(), header)
 		if err != nil {
+			if resp != nil {
+				err = errors.Errorf("%v (%s)", err, resp.Status)
+			}
 			c.Error(echo.NewHTTPError(http.StatusBadGateway,
 				errors.Errorf("error dialing to %v: %v", t, err)))
 			return
 		}
-		defer func() {
+
+		var respHeader http.Header
+		if protocol := out.Subprotocol(); protocol != "" {
+			respHeader = http.Header{"Sec-Websocket-Protocol": {protocol}}
+		}
+		// The service checks the origin itself, since the Origin header is passed on to it.
+		upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+		in, err := upgrader.Upgrade(w, r, respHeader)
+		if err != nil {
 			if cerr := out.Close(); cerr != nil {
 				c.Logger().Error(cerr)
 			}
-		}()
-
-		err = r.Write(out)
-		if err != nil {
-			c.Error(echo.NewHTTPError(http.StatusBadGateway,
-				errors.Errorf("error copying headers for %v: %v", t, err)))
+			c.Error(echo.NewHTTPError(http.StatusBadGateway, errors.Wrap(err, "error upgrading")))
 			return
 		}
 
-		copyReqErr := asyncCopy(out, in)
-		copyResErr := asyncCopy(in, out)
-		if cerr := <-copyReqErr; cerr != nil {
-			c.Logger().Errorf("error copying request body for %v: %v", t, cerr)
+		stopIn := keepAlive(in, p.KeepaliveInterval)
+		stopOut := keepAlive(out, p.KeepaliveInterval)
+		copyReqErr := asyncCopyMessages(out, in, websocket.CloseGoingAway)
+		copyResErr := asyncCopyMessages(in, out, websocket.CloseServiceRestart)
+
+		// Once either side closes, close both so that the other copy stops too.
+		var cerr error
+		select {
+		case cerr = <-copyReqErr:
+		case cerr = <-copyResErr:
+		}
+		stopIn()
+		stopOut()
+		for _, conn := range []*websocket.Conn{in, out} {
+			if err := conn.Close(); err != nil {
+				c.Logger().Error(err)
+			}
 		}
-		if cerr := <-copyResErr; cerr != nil {
-			c.Logger().Errorf("error copying response body for %v: %v", t, cerr)
+		<-copyReqErr
+		<-copyResErr
+		if cerr != nil {
+			c.Logger().Errorf("error proxying websocket for %v: %v", t, cerr)
 		}
 	})
 }
+
+// asyncCopyMessages copies messages from src to dst until src is closed, passing its close message
+// on to dst. If src goes away without closing, dst is closed with lostCode.
+func asyncCopyMessages(dst, src *websocket.Conn, lostCode int) chan error {
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		for {
+			msgType, data, err := src.ReadMessage()
+			if err != nil {
+				code, text := lostCode, ""
+				if cerr, ok := err.(*websocket.CloseError); ok {
+					// These codes are only reported locally and may not be sent.
+					switch cerr.Code {
+					case websocket.CloseAbnormalClosure:
+					case websocket.CloseNoStatusReceived:
+						code = websocket.CloseNormalClosure
+					default:
+						code, text = cerr.Code, cerr.Text
+					}
+					err = nil
+				}
+				msg := websocket.FormatCloseMessage(code, text)
+				_ = dst.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+				errs <- err
+				return
+			}
+			if err := dst.WriteMessage(msgType, data); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+	return errs
+}
+
+// keepAlive pings conn every interval until the returned function is called, and makes reads from
+// conn fail once it has gone two intervals without a pong.
+func keepAlive(conn *websocket.Conn, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	extend := func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * interval))
+	}
+	_ = extend("")
+	conn.SetPongHandler(extend)
+
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+			case <-done:
+				return
+			}
+			if err := conn.WriteControl(
+				websocket.PingMessage, nil, time.Now().Add(interval),
+			); err != nil {
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
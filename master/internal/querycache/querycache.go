@@ -0,0 +1,167 @@
+// Package querycache reuses the results of expensive API queries for a short time, so that the
+// WebUI pages polling the same endpoints do not each run the same queries against Postgres.
+package querycache
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+var cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Subsystem: "det",
+	Name:      "api_query_cache_requests_total",
+	Help:      "the number of cacheable API queries, by whether they were answered from the cache",
+}, []string{"result"})
+
+// invalidating matches the methods that change what the cached queries return: the changes users
+// make to experiments, projects and workspaces, and to who may see them. Reports from running
+// trials are left out, since they arrive constantly and would keep the cache empty.
+var invalidating = regexp.MustCompile(`^/determined\.api\.v1\.Determined/(` +
+	`(Create|Activate|Pause|Cancel|Kill|Archive|Unarchive|Patch|Delete|Move|Post|Put|Pin|Unpin)` +
+	`(Experiment|Project|Workspace)\w*|` +
+	`PatchUser|CreateGroup|UpdateGroup|DeleteGroup|AssignRoles|RemoveAssignments)$`)
+
+var errIncomplete = errors.New("query did not complete")
+
+type entry struct {
+	// done is closed once resp and err are set.
+	done    chan struct{}
+	resp    proto.Message
+	err     error
+	expires time.Time
+}
+
+// Cache holds the results of queries for a fixed time, and until the next change that could
+// affect them.
+type Cache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]*entry
+	lastPrune time.Time
+}
+
+// New returns a Cache for the given configuration, or nil if caching is disabled. A nil Cache
+// runs every query.
+func New(c config.QueryCacheConfig) *Cache {
+	if c.TTL == 0 {
+		return nil
+	}
+	return &Cache{
+		ttl:       time.Duration(c.TTL),
+		entries:   map[string]*entry{},
+		lastPrune: time.Now(),
+	}
+}
+
+// Key returns the cache key for a request a user made to a method. Users do not share results,
+// since what each can see depends on their permissions.
+func Key(method string, userID model.UserID, req proto.Message) (string, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "error marshaling %s request", method)
+	}
+	return fmt.Sprintf("%s/%d/%s", method, userID, b), nil
+}
+
+// Get returns the cached result for the key or, if there is none, runs the query. Concurrent
+// calls with the same key share a single run. Errors are not cached. The result is a copy that the
+// caller may change.
+func (c *Cache) Get(key string, query func() (proto.Message, error)) (proto.Message, error) {
+	if c == nil {
+		return query()
+	}
+	now := time.Now()
+
+	c.mu.Lock()
+	c.prune(now)
+	if e, ok := c.entries[key]; ok && !e.expired(now) {
+		c.mu.Unlock()
+		<-e.done
+		if e.err != nil {
+			// The run we waited on failed, maybe only because its caller went away.
+			return query()
+		}
+		cacheRequests.WithLabelValues("hit").Inc()
+		return proto.Clone(e.resp), nil
+	}
+	e := &entry{done: make(chan struct{}), err: errIncomplete}
+	c.entries[key] = e
+	c.mu.Unlock()
+	cacheRequests.WithLabelValues("miss").Inc()
+
+	defer c.finish(key, e)
+	e.resp, e.err = query()
+	if e.err != nil {
+		return nil, e.err
+	}
+	return proto.Clone(e.resp), nil
+}
+
+// finish records when an entry expires, or drops it if its query failed, and wakes the calls
+// waiting on it.
+func (c *Cache) finish(key string, e *entry) {
+	c.mu.Lock()
+	e.expires = time.Now().Add(c.ttl)
+	if e.err != nil && c.entries[key] == e {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+	close(e.done)
+}
+
+// Invalidate forgets every result. Queries still running when it is called are not cached.
+func (c *Cache) Invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]*entry{}
+}
+
+// prune forgets the expired results, checking at most once a minute.
+func (c *Cache) prune(now time.Time) {
+	if now.Sub(c.lastPrune) < time.Minute {
+		return
+	}
+	c.lastPrune = now
+	for k, e := range c.entries {
+		if e.expired(now) {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// expired returns whether an entry is too old to use; entries whose query is still running are
+// not.
+func (e *entry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+// UnaryServerInterceptor invalidates the cache after each successful call to a method that
+// changes what the cached queries return.
+func UnaryServerInterceptor(c *Cache) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err == nil && invalidating.MatchString(info.FullMethod) {
+			c.Invalidate()
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,149 @@
+package querycache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func counter(n *int) func() (proto.Message, error) {
+	return func() (proto.Message, error) {
+		*n++
+		return wrapperspb.Int64(int64(*n)), nil
+	}
+}
+
+func TestGet(t *testing.T) {
+	var disabled *Cache
+	runs := 0
+	for i := 0; i < 2; i++ {
+		_, err := disabled.Get("k", counter(&runs))
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, runs)
+	require.Nil(t, New(config.QueryCacheConfig{TTL: 0}))
+
+	c := New(config.QueryCacheConfig{TTL: model.Duration(50 * time.Millisecond)})
+	runs = 0
+	for i := 0; i < 3; i++ {
+		resp, err := c.Get("k", counter(&runs))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), resp.(*wrapperspb.Int64Value).Value)
+		// Callers get their own copy.
+		resp.(*wrapperspb.Int64Value).Value = 100
+	}
+	_, err := c.Get("other", counter(&runs))
+	require.NoError(t, err)
+	require.Equal(t, 2, runs)
+
+	time.Sleep(50 * time.Millisecond)
+	resp, err := c.Get("k", counter(&runs))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), resp.(*wrapperspb.Int64Value).Value)
+
+	c.Invalidate()
+	resp, err = c.Get("k", counter(&runs))
+	require.NoError(t, err)
+	require.Equal(t, int64(4), resp.(*wrapperspb.Int64Value).Value)
+
+	// Errors are not cached.
+	failed := errors.New("failed")
+	_, err = c.Get("err", func() (proto.Message, error) { return nil, failed })
+	require.Equal(t, failed, err)
+	resp, err = c.Get("err", counter(&runs))
+	require.NoError(t, err)
+	require.Equal(t, int64(5), resp.(*wrapperspb.Int64Value).Value)
+}
+
+func TestGetShared(t *testing.T) {
+	c := New(config.QueryCacheConfig{TTL: model.Duration(time.Minute)})
+	release := make(chan struct{})
+	var mu sync.Mutex
+	runs := 0
+	query := func() (proto.Message, error) {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+		<-release
+		return wrapperspb.String("done"), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := c.Get("k", query)
+			require.NoError(t, err)
+			require.Equal(t, "done", resp.(*wrapperspb.StringValue).Value)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	require.Equal(t, 1, runs)
+}
+
+func TestKey(t *testing.T) {
+	a, err := Key("GetExperiments", 1, wrapperspb.String("x"))
+	require.NoError(t, err)
+	b, err := Key("GetExperiments", 1, wrapperspb.String("x"))
+	require.NoError(t, err)
+	require.Equal(t, a, b)
+
+	for _, other := range []func() (string, error){
+		func() (string, error) { return Key("GetExperiments", 2, wrapperspb.String("x")) },
+		func() (string, error) { return Key("GetExperiments", 1, wrapperspb.String("y")) },
+		func() (string, error) { return Key("GetExperimentLabels", 1, wrapperspb.String("x")) },
+	} {
+		k, err := other()
+		require.NoError(t, err)
+		require.NotEqual(t, a, k)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	c := New(config.QueryCacheConfig{TTL: model.Duration(time.Minute)})
+	interceptor := UnaryServerInterceptor(c)
+	call := func(method string, err error) {
+		_, _ = interceptor(context.Background(), nil,
+			&grpc.UnaryServerInfo{FullMethod: "/determined.api.v1.Determined/" + method},
+			func(context.Context, interface{}) (interface{}, error) { return nil, err })
+	}
+	cached := func() bool {
+		ran := false
+		_, err := c.Get("k", func() (proto.Message, error) {
+			ran = true
+			return wrapperspb.Bool(true), nil
+		})
+		require.NoError(t, err)
+		return !ran
+	}
+
+	require.False(t, cached())
+	for _, method := range []string{
+		"GetExperiments", "ReportTrialTrainingMetrics", "PostTrialProfilerMetricsBatch",
+	} {
+		call(method, nil)
+		require.True(t, cached(), method)
+	}
+	call("ArchiveExperiment", errors.New("failed"))
+	require.True(t, cached())
+
+	for _, method := range []string{
+		"ArchiveExperiment", "KillExperiments", "PostProject", "MoveExperiment", "AssignRoles",
+	} {
+		call(method, nil)
+		require.False(t, cached(), method)
+	}
+}
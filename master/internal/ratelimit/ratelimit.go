@@ -0,0 +1,158 @@
+// Package ratelimit limits how many API requests each user can make, so that a runaway script
+// cannot overwhelm the master.
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	detContext "github.com/determined-ai/determined/master/internal/context"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// Class is a class of endpoints that are limited together.
+type Class string
+
+const (
+	// Read is the class of endpoints that only read.
+	Read Class = "read"
+	// Write is the class of all other endpoints.
+	Write Class = "write"
+)
+
+var limitedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Subsystem: "det",
+	Name:      "api_rate_limited_requests_total",
+	Help:      "the number of API requests rejected for exceeding a rate limit",
+}, []string{"class"})
+
+// HTTPClass returns the class of an HTTP request by its method.
+func HTTPClass(method string) Class {
+	if method == http.MethodGet || method == http.MethodHead {
+		return Read
+	}
+	return Write
+}
+
+// GRPCClass returns the class of a gRPC method. Methods that only read are, by convention, the
+// ones named Get.
+func GRPCClass(fullMethod string) Class {
+	if strings.HasPrefix(fullMethod, "/determined.api.v1.Determined/Get") {
+		return Read
+	}
+	return Write
+}
+
+type key struct {
+	userID model.UserID
+	class  Class
+}
+
+type bucket struct {
+	limiter *rate.Limiter
+	// full is when the bucket will have refilled completely, after which forgetting it changes
+	// nothing.
+	full time.Time
+}
+
+// Limiter keeps a token bucket for each user and class of endpoint.
+type Limiter struct {
+	rules map[Class]config.RateLimitRule
+
+	mu        sync.Mutex
+	buckets   map[key]*bucket
+	lastPrune time.Time
+}
+
+// New returns a Limiter for the given configuration, or nil if rate limiting is disabled. A nil
+// Limiter allows every request.
+func New(c config.RateLimitConfig) *Limiter {
+	if !c.Enabled {
+		return nil
+	}
+	return &Limiter{
+		rules:     map[Class]config.RateLimitRule{Read: c.Read, Write: c.Write},
+		buckets:   map[key]*bucket{},
+		lastPrune: time.Now(),
+	}
+}
+
+// Allow returns whether a user may make a request of the given class now and, if not, how long
+// until they may.
+func (l *Limiter) Allow(userID model.UserID, class Class) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(now)
+
+	k := key{userID: userID, class: class}
+	b, ok := l.buckets[k]
+	if !ok {
+		rule := l.rules[class]
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(rule.RequestsPerSecond), rule.Burst)}
+		l.buckets[k] = b
+	}
+
+	r := b.limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		limitedRequests.WithLabelValues(string(class)).Inc()
+		return false, delay
+	}
+	refill := float64(b.limiter.Burst()) / float64(b.limiter.Limit())
+	b.full = now.Add(time.Duration(refill * float64(time.Second)))
+	return true, 0
+}
+
+// prune forgets the buckets that have refilled, checking at most once a minute.
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < time.Minute {
+		return
+	}
+	l.lastPrune = now
+	for k, b := range l.buckets {
+		if now.After(b.full) {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// RetryAfter formats a delay for the Retry-After header, which counts whole seconds.
+func RetryAfter(delay time.Duration) string {
+	return strconv.Itoa(int((delay + time.Second - 1) / time.Second))
+}
+
+// Middleware rejects HTTP requests from users who have exceeded their rate limit. It must run
+// after authentication; requests without a user are not limited.
+func Middleware(l *Limiter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			dc, ok := c.(*detContext.DetContext)
+			if !ok {
+				return next(c)
+			}
+			user, ok := dc.GetUser()
+			if !ok {
+				return next(c)
+			}
+			if ok, delay := l.Allow(user.ID, HTTPClass(c.Request().Method)); !ok {
+				c.Response().Header().Set("Retry-After", RetryAfter(delay))
+				return echo.NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	detContext "github.com/determined-ai/determined/master/internal/context"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestAllow(t *testing.T) {
+	var disabled *Limiter
+	ok, _ := disabled.Allow(1, Write)
+	require.True(t, ok)
+	require.Nil(t, New(config.RateLimitConfig{Enabled: false}))
+
+	l := New(config.RateLimitConfig{
+		Enabled: true,
+		Read:    config.RateLimitRule{RequestsPerSecond: 1, Burst: 3},
+		Write:   config.RateLimitRule{RequestsPerSecond: 0.5, Burst: 1},
+	})
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow(1, Read)
+		require.True(t, ok)
+	}
+	ok, delay := l.Allow(1, Read)
+	require.False(t, ok)
+	require.InDelta(t, time.Second, delay, float64(100*time.Millisecond))
+
+	// Classes and users have separate buckets.
+	ok, _ = l.Allow(1, Write)
+	require.True(t, ok)
+	ok, delay = l.Allow(1, Write)
+	require.False(t, ok)
+	require.InDelta(t, 2*time.Second, delay, float64(100*time.Millisecond))
+	ok, _ = l.Allow(2, Read)
+	require.True(t, ok)
+
+	// Rejected requests do not push back when the next one is allowed.
+	ok, delay = l.Allow(1, Read)
+	require.False(t, ok)
+	require.InDelta(t, time.Second, delay, float64(100*time.Millisecond))
+}
+
+func TestRetryAfter(t *testing.T) {
+	require.Equal(t, "1", RetryAfter(time.Millisecond))
+	require.Equal(t, "1", RetryAfter(time.Second))
+	require.Equal(t, "2", RetryAfter(1500*time.Millisecond))
+}
+
+func TestClasses(t *testing.T) {
+	require.Equal(t, Read, HTTPClass(http.MethodGet))
+	require.Equal(t, Read, HTTPClass(http.MethodHead))
+	require.Equal(t, Write, HTTPClass(http.MethodPost))
+	require.Equal(t, Read, GRPCClass("/determined.api.v1.Determined/GetExperiments"))
+	require.Equal(t, Write, GRPCClass("/determined.api.v1.Determined/PatchExperiment"))
+}
+
+func TestMiddleware(t *testing.T) {
+	l := New(config.RateLimitConfig{
+		Enabled: true,
+		Read:    config.RateLimitRule{RequestsPerSecond: 0.1, Burst: 1},
+		Write:   config.RateLimitRule{RequestsPerSecond: 0.1, Burst: 1},
+	})
+	handler := Middleware(l)(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	request := func(user *model.User) (*httptest.ResponseRecorder, error) {
+		rec := httptest.NewRecorder()
+		c := &detContext.DetContext{
+			Context: echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec),
+		}
+		if user != nil {
+			c.SetUser(*user)
+		}
+		return rec, handler(c)
+	}
+
+	_, err := request(&model.User{ID: 1})
+	require.NoError(t, err)
+	rec, err := request(&model.User{ID: 1})
+	require.Equal(t, http.StatusTooManyRequests, err.(*echo.HTTPError).Code)
+	require.Equal(t, "10", rec.Header().Get("Retry-After"))
+
+	// Unauthenticated requests are not limited.
+	for i := 0; i < 3; i++ {
+		_, err := request(nil)
+		require.NoError(t, err)
+	}
+}
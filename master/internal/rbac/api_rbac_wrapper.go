@@ -4,7 +4,10 @@ import (
 	"context"
 
 	"github.com/uptrace/bun"
+	"google.golang.org/protobuf/proto"
 
+	"github.com/determined-ai/determined/master/internal/auditlog"
+	"github.com/determined-ai/determined/master/internal/grpcutil"
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
 )
@@ -66,14 +69,35 @@ func (s *RBACAPIServerWrapper) ListRoles(ctx context.Context, req *apiv1.ListRol
 func (s *RBACAPIServerWrapper) AssignRoles(ctx context.Context, req *apiv1.AssignRolesRequest) (
 	*apiv1.AssignRolesResponse, error,
 ) {
-	return rbacAPIServer.AssignRoles(ctx, req)
+	resp, err := rbacAPIServer.AssignRoles(ctx, req)
+	recordRoleChange(ctx, auditlog.RoleAssign, req, err)
+	return resp, err
 }
 
 // RemoveAssignments is a wrapper the same function the RBACAPIServer interface.
 func (s *RBACAPIServerWrapper) RemoveAssignments(ctx context.Context,
 	req *apiv1.RemoveAssignmentsRequest,
 ) (*apiv1.RemoveAssignmentsResponse, error) {
-	return rbacAPIServer.RemoveAssignments(ctx, req)
+	resp, err := rbacAPIServer.RemoveAssignments(ctx, req)
+	recordRoleChange(ctx, auditlog.RoleRemove, req, err)
+	return resp, err
+}
+
+// recordRoleChange records an attempt to change role assignments in the audit log. Attempts that
+// were denied are recorded too, since they may be attempts to escalate privileges.
+func recordRoleChange(
+	ctx context.Context, action auditlog.Action, req proto.Message, err error,
+) {
+	curUser, _, userErr := grpcutil.GetUser(ctx)
+	if userErr != nil {
+		return
+	}
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     action,
+		TargetType: "role_assignment",
+		Success:    err == nil,
+		Details:    auditlog.DetailsFromProto(req),
+	})
 }
 
 // AssignWorkspaceAdminToUserTx is a wrapper the same function the RBACAPIServer interface.
@@ -91,6 +91,14 @@ func (r *ActorResourceManager) ValidateCommandResources(
 	return resp, r.ask(ctx, msg, &resp)
 }
 
+// ScheduleDryRun reports how a hypothetical allocation would be scheduled.
+func (r *ActorResourceManager) ScheduleDryRun(
+	ctx actor.Messenger,
+	msg sproto.ScheduleDryRunRequest,
+) (resp sproto.ScheduleDryRunResponse, err error) {
+	return resp, r.ask(ctx, msg, &resp)
+}
+
 // Allocate allocates some resources.
 func (r *ActorResourceManager) Allocate(ctx actor.Messenger, msg sproto.AllocateRequest) error {
 	return r.ask(ctx, msg, nil)
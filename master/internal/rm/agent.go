@@ -22,6 +22,7 @@ import (
 	"github.com/determined-ai/determined/master/pkg/cproto"
 	"github.com/determined-ai/determined/master/pkg/device"
 	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/version"
 	"github.com/determined-ai/determined/proto/pkg/agentv1"
 	proto "github.com/determined-ai/determined/proto/pkg/apiv1"
 )
@@ -42,6 +43,9 @@ type (
 		maxZeroSlotContainers int
 		agentReconnectWait    time.Duration
 		agentReattachEnabled  bool
+		agentHeartbeat        ws.PingOptions
+		containerRuntime      string
+		cpuSlots              model.CPUSlotsConfig
 		// awaitingReconnect et al contain reconnect related state. The pattern for
 		// reconnecting agents is
 		//  * They have a small window to reconnect.
@@ -63,13 +67,25 @@ type (
 		preDisconnectEnabled  bool
 		preDisconnectDraining bool
 
+		// certExpiryTimer fires when the certificate the agent connected with expires.
+		certExpiryTimer *actor.Ref
+
 		// opts are additional agent options the master sends to the agent.
 		opts *aproto.MasterSetAgentOptions
 
+		// imageCache is the last report of the images cached on the agent, and imageCacheLimit
+		// the size limit of its image cache set through the API.
+		imageCache      *aproto.ImageCacheReport
+		imageCacheLimit int64
+
 		agentState *AgentState
 	}
 
 	reconnectTimeout struct{}
+	// clientCertExpired is sent when the certificate that socket was opened with expires.
+	clientCertExpired struct {
+		socket *actor.Ref
+	}
 
 	// GetAgentState response is agent.agentState.
 	GetAgentState struct{}
@@ -97,6 +113,11 @@ type (
 	DeallocateContainer struct {
 		ContainerID cproto.ID
 	}
+	// NotifyMasterRestart tells the agent that the master is shutting down and will be back, so
+	// that it keeps trying to reconnect for ReconnectWait.
+	NotifyMasterRestart struct {
+		ReconnectWait time.Duration
+	}
 )
 
 var errRecovering = errors.New("agent disconnected, wait for recovery")
@@ -124,11 +145,22 @@ func (a *agent) receive(ctx *actor.Context, msg interface{}) error {
 		ctx.Respond(a.summarize(ctx))
 	case ws.WebSocketConnected:
 		check.Panic(check.True(a.socket == nil, "websocket already connected"))
-		socket, ok := msg.Accept(ctx, aproto.MasterMessage{}, true)
+		socket, ok := msg.AcceptWithPing(ctx, aproto.MasterMessage{}, a.agentHeartbeat)
 		check.Panic(check.True(ok, "failed to accept websocket connection"))
 		a.socket = socket
 		a.version = msg.Ctx.QueryParam("version")
 
+		// The certificate is only checked when the connection is made, so close the connection
+		// once it expires; the agent reconnects with its renewed certificate.
+		if a.certExpiryTimer != nil {
+			a.certExpiryTimer.Stop()
+			a.certExpiryTimer = nil
+		}
+		if cert := agentCertificate(msg.Ctx); cert != nil {
+			a.certExpiryTimer, _ = actors.NotifyAfter(
+				ctx, time.Until(cert.NotAfter), clientCertExpired{socket: socket})
+		}
+
 		lastColonIndex := strings.LastIndex(msg.Ctx.Request().RemoteAddr, ":")
 		if lastColonIndex == -1 {
 			a.address = msg.Ctx.Request().RemoteAddr
@@ -136,7 +168,16 @@ func (a *agent) receive(ctx *actor.Context, msg interface{}) error {
 			a.address = msg.Ctx.Request().RemoteAddr[0:lastColonIndex]
 		}
 
-		var masterSetAgentOptions aproto.AgentMessage
+		optsCopy := *a.opts
+		optsCopy.ContainerRuntime = a.containerRuntime
+		optsCopy.CPUSlots = a.cpuSlots
+		optsCopy.AgentReconnectWait = model.Duration(a.agentReconnectWait)
+		limit, err := imageCacheLimit(AgentID(ctx.Self().Address().Local()))
+		if err != nil {
+			ctx.Log().WithError(err).Error("failed to get image cache limit")
+		}
+		a.imageCacheLimit = limit
+		optsCopy.ImageCacheLimit = limit
 		// Do container revalidation:
 		// - when reattach is on or off, on all valid reconnects.
 		// - when reattach is on, also do it on initial connect.
@@ -144,12 +185,9 @@ func (a *agent) receive(ctx *actor.Context, msg interface{}) error {
 		// Flush them otherwise.
 		reconnect, _ := msg.IsReconnect()
 		if a.awaitingReconnect && (a.agentReattachEnabled || reconnect) {
-			optsCopy := *a.opts
 			optsCopy.ContainersToReattach = a.gatherContainersToReattach(ctx)
-			masterSetAgentOptions = aproto.AgentMessage{MasterSetAgentOptions: &optsCopy}
-		} else {
-			masterSetAgentOptions = aproto.AgentMessage{MasterSetAgentOptions: a.opts}
 		}
+		masterSetAgentOptions := aproto.AgentMessage{MasterSetAgentOptions: &optsCopy}
 
 		wsm := ws.WriteMessage{Message: masterSetAgentOptions}
 		if err := ctx.Ask(a.socket, wsm).Error(); err != nil {
@@ -299,6 +337,12 @@ func (a *agent) receive(ctx *actor.Context, msg interface{}) error {
 		}
 		ctx.Respond(&proto.DisableAgentResponse{Agent: a.summarize(ctx).ToProto()})
 		ctx.Tell(a.resourcePool, sproto.UpdateAgent{Agent: ctx.Self()})
+	case *proto.GetAgentImagesRequest:
+		a.getImages(ctx)
+	case *proto.EvictAgentImagesRequest:
+		a.evictImages(ctx, msg)
+	case *proto.SetAgentImageCacheLimitRequest:
+		a.setImageCacheLimit(ctx, msg)
 	case echo.Context:
 		a.handleAPIRequest(ctx, msg)
 	case actor.ChildFailed:
@@ -313,11 +357,28 @@ func (a *agent) receive(ctx *actor.Context, msg interface{}) error {
 
 		a.socketDisconnected(ctx)
 		ctx.Tell(a.resourcePool, sproto.UpdateAgent{Agent: ctx.Self()})
+	case clientCertExpired:
+		if a.socket != msg.socket {
+			return nil
+		}
+		ctx.Log().Info("agent certificate expired, closing its connection")
+		ctx.Tell(a.socket, errors.New("agent certificate expired"))
 	case reconnectTimeout:
 		// Re-enter from actor.ChildFailed.
 		if a.awaitingReconnect {
 			return errors.New("agent failed to reconnect by deadline")
 		}
+	case NotifyMasterRestart:
+		// Agents of other versions may not understand the message and would exit on it.
+		if a.socket == nil || a.version != version.Version {
+			return nil
+		}
+		wsm := ws.WriteMessage{Message: aproto.AgentMessage{
+			MasterRestarting: &aproto.MasterRestarting{ReconnectWait: model.Duration(msg.ReconnectWait)},
+		}}
+		if err := ctx.Ask(a.socket, wsm).Error(); err != nil {
+			ctx.Log().WithError(err).Warn("failed to tell agent that the master is restarting")
+		}
 	case GetAgentState:
 		if !a.started {
 			ctx.Respond(errors.New("agent state is not available: agent not started"))
@@ -471,6 +532,27 @@ func (a *agent) handleIncomingWSMessage(ctx *actor.Context, msg aproto.MasterMes
 			RunMessage:  msg.ContainerLog.RunMessage,
 			AuxMessage:  msg.ContainerLog.AuxMessage,
 		})
+	case msg.DeviceHealthReport != nil:
+		if !a.started {
+			log.Debugf("received DeviceHealthReport on non-started agent")
+			return
+		}
+		if a.agentState.updateDeviceHealth(ctx, *msg.DeviceHealthReport) {
+			ctx.Tell(a.resourcePool, sproto.UpdateAgent{Agent: ctx.Self()})
+		}
+	case msg.ContainerMetrics != nil:
+		ref, ok := a.agentState.containerAllocation[msg.ContainerMetrics.ContainerID]
+		if !ok {
+			log.WithField("container-id", msg.ContainerMetrics.ContainerID).Debug(
+				"received ContainerMetrics from container not allocated to agent")
+			return
+		}
+		ctx.Tell(ref, sproto.ContainerMetrics{
+			ContainerID: msg.ContainerMetrics.ContainerID,
+			Metrics:     msg.ContainerMetrics.Metrics,
+		})
+	case msg.ImageCacheReport != nil:
+		a.imageCache = msg.ImageCacheReport
 	case msg.ContainerStatsRecord != nil:
 		if a.taskNeedsRecording(msg.ContainerStatsRecord) {
 			var err error
@@ -498,6 +580,7 @@ func (a *agent) agentStarted(ctx *actor.Context, agentStarted *aproto.AgentStart
 		sproto.AddAgent{Agent: ctx.Self(), Label: agentStarted.Label},
 		a.maxZeroSlotContainers)
 	a.agentState.resourcePoolName = a.resourcePoolName
+	a.agentState.Labels = agentStarted.Labels
 	a.agentState.agentStarted(ctx, agentStarted)
 	ctx.Tell(a.resourcePool, sproto.AddAgent{
 		Agent: ctx.Self(),
@@ -0,0 +1,132 @@
+package rm
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/actor"
+	ws "github.com/determined-ai/determined/master/pkg/actor/api"
+	"github.com/determined-ai/determined/master/pkg/aproto"
+	"github.com/determined-ai/determined/master/pkg/protoutils"
+	"github.com/determined-ai/determined/proto/pkg/agentv1"
+	proto "github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+var errImagesNotCached = errors.New("images are only cached on agents that use Docker")
+
+// cachesImages returns whether the agent runs containers with Docker, which caches their images.
+func (a *agent) cachesImages() bool {
+	return a.containerRuntime == "" || a.containerRuntime == aproto.ContainerRuntimeDocker
+}
+
+func (a *agent) getImages(ctx *actor.Context) {
+	if !a.cachesImages() {
+		ctx.Respond(errImagesNotCached)
+		return
+	}
+
+	resp := &proto.GetAgentImagesResponse{LimitBytes: a.imageCacheLimit}
+	if a.imageCache != nil {
+		for _, image := range a.imageCache.Images {
+			resp.Images = append(resp.Images, &agentv1.CachedImage{
+				Id:       image.ID,
+				Tags:     image.Tags,
+				Size:     image.Size,
+				Created:  protoutils.ToTimestamp(image.Created),
+				LastUsed: protoutils.ToTimestamp(image.LastUsed),
+				InUse:    image.InUse,
+			})
+		}
+	}
+	ctx.Respond(resp)
+}
+
+func (a *agent) evictImages(ctx *actor.Context, msg *proto.EvictAgentImagesRequest) {
+	switch {
+	case a.awaitingReconnect:
+		ctx.Respond(errRecovering)
+		return
+	case !a.started:
+		ctx.Respond(errors.New("can't evict images: agent not started"))
+		return
+	case !a.cachesImages():
+		ctx.Respond(errImagesNotCached)
+		return
+	}
+
+	ctx.Log().Infof("evicting images %v", msg.Images)
+	wsm := ws.WriteMessage{Message: aproto.AgentMessage{
+		EvictImages: &aproto.EvictImages{Images: msg.Images},
+	}}
+	if err := ctx.Ask(a.socket, wsm).Error(); err != nil {
+		ctx.Respond(errors.Wrap(err, "failed to write evict images message"))
+		return
+	}
+	ctx.Respond(&proto.EvictAgentImagesResponse{})
+}
+
+func (a *agent) setImageCacheLimit(
+	ctx *actor.Context, msg *proto.SetAgentImageCacheLimitRequest,
+) {
+	switch {
+	case a.awaitingReconnect:
+		ctx.Respond(errRecovering)
+		return
+	case !a.cachesImages():
+		ctx.Respond(errImagesNotCached)
+		return
+	}
+
+	if err := setImageCacheLimit(AgentID(ctx.Self().Address().Local()), msg.LimitBytes); err != nil {
+		ctx.Respond(errors.Wrap(err, "failed to save image cache limit"))
+		return
+	}
+	a.imageCacheLimit = msg.LimitBytes
+	ctx.Log().Infof("set image cache limit to %d bytes", msg.LimitBytes)
+
+	// Agents that haven't started yet get the limit along with the other agent options.
+	if a.started {
+		wsm := ws.WriteMessage{Message: aproto.AgentMessage{
+			SetImageCacheLimit: &aproto.SetImageCacheLimit{LimitBytes: msg.LimitBytes},
+		}}
+		if err := ctx.Ask(a.socket, wsm).Error(); err != nil {
+			ctx.Respond(errors.Wrap(err, "failed to write image cache limit message"))
+			return
+		}
+	}
+	ctx.Respond(&proto.SetAgentImageCacheLimitResponse{})
+}
+
+// imageCacheLimit returns the image cache limit set for the agent, or 0 if there is none.
+func imageCacheLimit(agentID AgentID) (int64, error) {
+	var limit AgentImageCacheLimit
+	switch err := db.Bun().NewSelect().Model(&limit).
+		Where("agent_id = ?", agentID).
+		Scan(context.TODO()); {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, nil
+	case err != nil:
+		return 0, err
+	}
+	return limit.LimitBytes, nil
+}
+
+func setImageCacheLimit(agentID AgentID, limitBytes int64) error {
+	if limitBytes == 0 {
+		_, err := db.Bun().NewDelete().Model((*AgentImageCacheLimit)(nil)).
+			Where("agent_id = ?", agentID).
+			Exec(context.TODO())
+		return err
+	}
+
+	_, err := db.Bun().NewInsert().Model(&AgentImageCacheLimit{
+		AgentID:    agentID,
+		LimitBytes: limitBytes,
+		UpdatedAt:  time.Now().UTC(),
+	}).On("CONFLICT (agent_id) DO UPDATE").Exec(context.TODO())
+	return err
+}
@@ -1,6 +1,8 @@
 package rm
 
 import (
+	"time"
+
 	"github.com/uptrace/bun"
 
 	"github.com/determined-ai/determined/master/internal/sproto"
@@ -24,16 +26,26 @@ type AgentID string
 type AgentSnapshot struct {
 	bun.BaseModel `bun:"table:resourcemanagers_agent_agentstate,alias:rmas"`
 
-	ID                    int64       `bun:"id,pk,autoincrement"`
-	AgentID               AgentID     `bun:"agent_id,notnull,unique"`
-	UUID                  string      `bun:"uuid,notnull,unique"`
-	ResourcePoolName      string      `bun:"resource_pool_name,notnull"`
-	Label                 string      `bun:"label"`
-	UserEnabled           bool        `bun:"user_enabled"`
-	UserDraining          bool        `bun:"user_draining"`
-	MaxZeroSlotContainers int         `bun:"max_zero_slot_containers"`
-	Slots                 []SlotData  `bun:"slots"`
-	Containers            []cproto.ID `bun:"containers"`
+	ID                    int64             `bun:"id,pk,autoincrement"`
+	AgentID               AgentID           `bun:"agent_id,notnull,unique"`
+	UUID                  string            `bun:"uuid,notnull,unique"`
+	ResourcePoolName      string            `bun:"resource_pool_name,notnull"`
+	Label                 string            `bun:"label"`
+	Labels                map[string]string `bun:"labels"`
+	UserEnabled           bool              `bun:"user_enabled"`
+	UserDraining          bool              `bun:"user_draining"`
+	MaxZeroSlotContainers int               `bun:"max_zero_slot_containers"`
+	Slots                 []SlotData        `bun:"slots"`
+	Containers            []cproto.ID       `bun:"containers"`
+}
+
+// AgentImageCacheLimit is a database representation of the image cache limit set for an agent.
+type AgentImageCacheLimit struct {
+	bun.BaseModel `bun:"table:agent_image_cache_limits"`
+
+	AgentID    AgentID   `bun:"agent_id,pk"`
+	LimitBytes int64     `bun:"limit_bytes,notnull"`
+	UpdatedAt  time.Time `bun:"updated_at,notnull"`
 }
 
 // ContainerSnapshot is a database representation of `containerResources`.
@@ -17,6 +17,7 @@ import (
 	"github.com/determined-ai/determined/master/internal/db"
 	"github.com/determined-ai/determined/master/internal/sproto"
 	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/actor/actors"
 	"github.com/determined-ai/determined/master/pkg/device"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
 	"github.com/determined-ai/determined/proto/pkg/jobv1"
@@ -175,6 +176,11 @@ func (a *agentResourceManager) Receive(ctx *actor.Context) error {
 				a.pools[config.PoolName] = rpRef
 			}
 		}
+		actors.NotifyAfter(ctx, poolStatsPruneInterval, poolStatsPruneTick{})
+
+	case poolStatsPruneTick:
+		prunePoolStats(ctx, time.Duration(a.config.PoolStatsRetention))
+		actors.NotifyAfter(ctx, poolStatsPruneInterval, poolStatsPruneTick{})
 
 	case sproto.AllocateRequest:
 		// this code exists to handle the case where an experiment does not have
@@ -239,6 +245,9 @@ func (a *agentResourceManager) Receive(ctx *actor.Context) error {
 	case sproto.ValidateCommandResourcesRequest:
 		a.forwardToPool(ctx, msg.ResourcePool, msg)
 
+	case sproto.ScheduleDryRunRequest:
+		a.forwardToPool(ctx, msg.ResourcePool, msg)
+
 	case *apiv1.GetResourcePoolsRequest:
 		summaries := make([]*resourcepoolv1.ResourcePool, 0, len(a.poolsConfig))
 		for _, pool := range a.poolsConfig {
@@ -493,6 +502,18 @@ func (a *agentResourceManager) createResourcePoolSummary(
 				accelerator = pool.Provider.GCP.Accelerator()
 			}
 		}
+		if pool.Provider.Azure != nil {
+			poolType = resourcepoolv1.ResourcePoolType_RESOURCE_POOL_TYPE_AZURE
+			preemptible = pool.Provider.Azure.SpotEnabled
+			location = pool.Provider.Azure.Location
+			imageID = pool.Provider.Azure.ImageID()
+			slotsPerAgent = pool.Provider.Azure.SlotsPerInstance()
+			slotType = pool.Provider.Azure.SlotType()
+			instanceType = pool.Provider.Azure.InstanceType.VMSize
+			if pool.Provider.Azure.InstanceType.GPUNum > 0 {
+				accelerator = pool.Provider.Azure.Accelerator()
+			}
+		}
 	}
 
 	var schedulerType resourcepoolv1.SchedulerType
@@ -612,6 +633,26 @@ func (a *agentResourceManager) createResourcePoolSummary(
 			OperationTimeoutPeriod: float32(time.Duration(gcp.OperationTimeoutPeriod).Seconds()),
 		}
 	}
+	if poolType == resourcepoolv1.ResourcePoolType_RESOURCE_POOL_TYPE_AZURE {
+		azure := pool.Provider.Azure
+		resp.Details.Azure = &resourcepoolv1.ResourcePoolAzureDetail{
+			SubscriptionId: azure.SubscriptionID,
+			ResourceGroup:  azure.ResourceGroup,
+			Location:       azure.Location,
+			ScaleSetName:   azure.ScaleSetName,
+			TagKey:         azure.TagKey,
+			TagValue:       azure.TagValue,
+			Image:          azure.ImageID(),
+			OsDiskSize:     int32(azure.OSDiskSize),
+			AdminUsername:  azure.AdminUsername,
+			SubnetId:       azure.SubnetID,
+			PublicIp:       azure.PublicIP,
+			VmSize:         azure.InstanceType.VMSize,
+			GpuNum:         int32(azure.InstanceType.GPUNum),
+			SpotEnabled:    azure.SpotEnabled,
+			SpotMaxPrice:   azure.SpotMaxPrice,
+		}
+	}
 
 	if schedulerType == resourcepoolv1.SchedulerType_SCHEDULER_TYPE_PRIORITY {
 		resp.Details.PriorityScheduler = &resourcepoolv1.ResourcePoolPrioritySchedulerDetail{
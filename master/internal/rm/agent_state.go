@@ -27,16 +27,19 @@ type slotEnabled struct {
 	agentEnabled bool
 	userEnabled  bool
 	draining     bool
+	// unhealthy is set when the agent reports the slot's device as unhealthy.
+	unhealthy bool
 }
 
 func (s slotEnabled) enabled() bool {
-	return s.agentEnabled && s.userEnabled
+	return s.agentEnabled && s.userEnabled && !s.unhealthy
 }
 
 type slot struct {
 	device      device.Device
 	enabled     slotEnabled
 	containerID *cproto.ID
+	health      *model.DeviceHealth
 }
 
 // AgentState holds the scheduler state for an agent. The implementation of agent-related operations
@@ -46,6 +49,7 @@ type AgentState struct {
 	Handler          *actor.Ref
 	Devices          map[device.Device]*cproto.ID
 	Label            string
+	Labels           map[string]string
 	resourcePoolName string
 	enabled          bool
 	draining         bool
@@ -198,6 +202,7 @@ func (a *AgentState) DeepCopy() *AgentState {
 	copiedAgent := &AgentState{
 		Handler:               a.Handler,
 		Label:                 a.Label,
+		Labels:                a.Labels,
 		Devices:               maps.Clone(a.Devices),
 		maxZeroSlotContainers: a.maxZeroSlotContainers,
 		enabled:               a.enabled,
@@ -376,6 +381,7 @@ func (a *AgentState) getSlotSummary(deviceID device.ID) model.SlotSummary {
 		Enabled:   s.enabled.enabled(),
 		Container: container,
 		Draining:  s.enabled.draining,
+		Health:    s.health,
 	}
 }
 
@@ -398,16 +404,53 @@ func (a *AgentState) updateSlotDeviceView(ctx *actor.Context, deviceID device.ID
 		}
 
 		// On `PostStop`, draining will be already set to false, and we'll kill the container
-		// whether we have the device or not.
-		if !s.enabled.draining && s.containerID != nil {
+		// whether we have the device or not. Containers on unhealthy devices are killed even when
+		// draining, since they are unlikely to finish.
+		if (!s.enabled.draining || s.enabled.unhealthy) && s.containerID != nil {
+			reason := "slot disabled"
+			if s.enabled.unhealthy {
+				reason = "slot device unhealthy"
+			}
 			ctx.Tell(a.containerAllocation[*s.containerID], sproto.AllocationSignalWithReason{
 				AllocationSignal:    sproto.KillAllocation,
-				InformationalReason: "slot disabled",
+				InformationalReason: reason,
 			})
 		}
 	}
 }
 
+// updateDeviceHealth records the health the agent reported for its devices, cordoning slots whose
+// devices became unhealthy and uncordoning those whose devices recovered. It returns whether any
+// slot's schedulability changed.
+func (a *AgentState) updateDeviceHealth(
+	ctx *actor.Context, report aproto.DeviceHealthReport,
+) bool {
+	changed := false
+	for id, health := range report.Devices {
+		s, ok := a.slotStates[id]
+		if !ok {
+			ctx.Log().Warnf("received health for unknown device %d (%s)", id, a.string())
+			continue
+		}
+		health := health
+		s.health = &health
+		if s.enabled.unhealthy == !health.Healthy {
+			continue
+		}
+
+		s.enabled.unhealthy = !health.Healthy
+		if s.enabled.unhealthy {
+			ctx.Log().Warnf("device %s is unhealthy, cordoning slot %d: %s",
+				s.device.UUID, id, health.Reason)
+		} else {
+			ctx.Log().Infof("device %s is healthy again, uncordoning slot %d", s.device.UUID, id)
+		}
+		a.updateSlotDeviceView(ctx, id)
+		changed = true
+	}
+	return changed
+}
+
 func (a *AgentState) patchSlotStateInner(
 	ctx *actor.Context, msg PatchSlotState, slotState *slot,
 ) model.SlotSummary {
@@ -467,6 +510,7 @@ func (a *AgentState) snapshot() *AgentSnapshot {
 		UUID:             a.uuid.String(),
 		ResourcePoolName: a.resourcePoolName,
 		Label:            a.Label,
+		Labels:           a.Labels,
 		// TODO(ilia): we need to disambiguate user setting (which needs to be saved)
 		// vs current state.
 		UserEnabled:           a.enabled,
@@ -647,6 +691,7 @@ func newAgentStateFromSnapshot(as AgentSnapshot) (*AgentState, error) {
 		maxZeroSlotContainers: as.MaxZeroSlotContainers,
 		resourcePoolName:      as.ResourcePoolName,
 		Label:                 as.Label,
+		Labels:                as.Labels,
 		uuid:                  parsedUUID,
 		enabled:               as.UserEnabled,
 		draining:              as.UserDraining,
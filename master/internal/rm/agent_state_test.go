@@ -0,0 +1,107 @@
+package rm
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/actor/actors"
+	"github.com/determined-ai/determined/master/pkg/aproto"
+	"github.com/determined-ai/determined/master/pkg/cproto"
+	"github.com/determined-ai/determined/master/pkg/device"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestAgentStateDeviceHealth(t *testing.T) {
+	system := actor.NewSystem(t.Name())
+	allocationImpl := actors.MockActor{Responses: map[string]*actors.MockResponse{}}
+	allocation := system.MustActorOf(actor.Addr("allocation"), &allocationImpl)
+
+	// The agent state is only touched from within the agent actor, which runs the functions it
+	// is sent.
+	agent := system.MustActorOf(actor.Addr("agent-1"), actor.ActorFunc(
+		func(ctx *actor.Context) error {
+			if f, ok := ctx.Message().(func(*actor.Context)); ok {
+				f(ctx)
+			}
+			return nil
+		}))
+	inAgent := func(f func(*actor.Context)) {
+		system.Ask(agent, f).Get()
+	}
+
+	healthy := device.Device{ID: 0, UUID: "GPU-0", Type: device.CUDA}
+	broken := device.Device{ID: 1, UUID: "GPU-1", Type: device.CUDA}
+	state := NewAgentState(sproto.AddAgent{Agent: agent}, 0)
+	inAgent(func(ctx *actor.Context) {
+		for _, d := range []device.Device{healthy, broken} {
+			state.slotStates[d.ID] = &slot{
+				device:  d,
+				enabled: slotEnabled{agentEnabled: true, userEnabled: true},
+			}
+			state.updateSlotDeviceView(ctx, d.ID)
+		}
+	})
+	assert.Equal(t, state.NumEmptySlots(), 2)
+
+	// A container is running on the device that goes bad.
+	cid := cproto.NewID()
+	state.Devices[broken] = &cid
+	state.slotStates[broken.ID].containerID = &cid
+	state.containerAllocation[cid] = allocation
+
+	report := func(d device.Device, health model.DeviceHealth) (changed bool) {
+		inAgent(func(ctx *actor.Context) {
+			changed = state.updateDeviceHealth(ctx, aproto.DeviceHealthReport{
+				Devices: map[device.ID]model.DeviceHealth{d.ID: health},
+			})
+		})
+		return changed
+	}
+
+	// An unhealthy device is cordoned and the containers on it are killed.
+	assert.Assert(t, report(broken, model.DeviceHealth{Healthy: false, Reason: "Xid 79"}))
+	_, ok := state.Devices[broken]
+	assert.Assert(t, !ok)
+	summary := state.getSlotSummary(broken.ID)
+	assert.Assert(t, !summary.Enabled)
+	assert.Equal(t, summary.Health.Reason, "Xid 79")
+	system.Ask(allocation, actor.Ping{}).Get()
+	var signals []sproto.AllocationSignalWithReason
+	for _, msg := range allocationImpl.Messages {
+		if signal, ok := msg.(sproto.AllocationSignalWithReason); ok {
+			signals = append(signals, signal)
+		}
+	}
+	assert.DeepEqual(t, signals, []sproto.AllocationSignalWithReason{{
+		AllocationSignal:    sproto.KillAllocation,
+		InformationalReason: "slot device unhealthy",
+	}})
+
+	// Reporting it again changes nothing, and new containers are kept off it.
+	assert.Assert(t, !report(broken, model.DeviceHealth{Healthy: false, Reason: "Xid 79"}))
+	assert.Equal(t, state.NumSlots(), 1)
+	assert.Equal(t, state.NumEmptySlots(), 1)
+	_, err := state.AllocateFreeDevices(2, cproto.NewID())
+	assert.ErrorContains(t, err, "not enough devices")
+	next := cproto.NewID()
+	devices, err := state.AllocateFreeDevices(1, next)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, devices, []device.Device{healthy})
+	state.DeallocateContainer(next)
+
+	// Once the killed container is gone and the device recovers, it is uncordoned.
+	state.slotStates[broken.ID].containerID = nil
+	assert.Assert(t, report(broken, model.DeviceHealth{Healthy: true}))
+	assert.Assert(t, state.getSlotSummary(broken.ID).Enabled)
+	assert.Equal(t, state.NumEmptySlots(), 2)
+	devices, err = state.AllocateFreeDevices(2, cproto.NewID())
+	assert.NilError(t, err)
+	assert.Equal(t, len(devices), 2)
+
+	// Health is recorded for healthy devices without changing whether they are schedulable.
+	assert.Assert(t, !report(healthy, model.DeviceHealth{Healthy: true, Temperature: 60}))
+	assert.Equal(t, state.getSlotSummary(healthy.ID).Health.Temperature, 60)
+}
@@ -2,6 +2,7 @@ package rm
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
 	"time"
@@ -79,21 +80,28 @@ func (a *agents) Receive(ctx *actor.Context) error {
 			}
 		}
 	case api.WebSocketConnected:
+		id := msg.Ctx.QueryParam("id")
 		cmuxConn := connsave.GetConn(msg.Ctx.Request().Context()).(*cmux.MuxConn)
 		// Here, we just have to check that there are any certificates at all, since the top-level TLS
 		// config verifies that any certificates that are provided are valid.
 		if tlsConn, ok := cmuxConn.Conn.(*tls.Conn); ok {
-			requireAuth := config.GetMasterConfig().ResourceManager.AgentRM.RequireAuthentication
-			missingAuth := len(tlsConn.ConnectionState().PeerCertificates) == 0
-			if requireAuth && missingAuth {
+			agentRM := config.GetMasterConfig().ResourceManager.AgentRM
+			cert := agentCertificate(msg.Ctx)
+			if agentRM.RequireAuthentication && cert == nil {
 				ctx.Log().WithField("remote-addr", tlsConn.RemoteAddr()).
 					Warnf("rejecting agent WebSocket request with no certificates")
 				ctx.Respond(echo.ErrForbidden)
 				return nil
 			}
+			if agentRM.VerifyAgentID && !certificateIssuedTo(cert, id) {
+				ctx.Log().WithField("remote-addr", tlsConn.RemoteAddr()).
+					Warnf("rejecting agent WebSocket request for %s with a certificate issued to %s",
+						id, cert.Subject.CommonName)
+				ctx.Respond(echo.ErrForbidden)
+				return nil
+			}
 		}
 
-		id := msg.Ctx.QueryParam("id")
 		resourcePool := msg.Ctx.QueryParam("resource_pool")
 		reconnect, err := msg.IsReconnect()
 		if err != nil {
@@ -146,6 +154,33 @@ func (a *agents) Receive(ctx *actor.Context) error {
 	return nil
 }
 
+// agentCertificate returns the certificate that the agent making a request presented, if any.
+func agentCertificate(c echo.Context) *x509.Certificate {
+	cmuxConn, ok := connsave.GetConn(c.Request().Context()).(*cmux.MuxConn)
+	if !ok {
+		return nil
+	}
+	tlsConn, ok := cmuxConn.Conn.(*tls.Conn)
+	if !ok || len(tlsConn.ConnectionState().PeerCertificates) == 0 {
+		return nil
+	}
+	return tlsConn.ConnectionState().PeerCertificates[0]
+}
+
+// certificateIssuedTo returns whether the certificate names the agent as its common name or as one
+// of its DNS names.
+func certificateIssuedTo(cert *x509.Certificate, agentID string) bool {
+	if cert.Subject.CommonName == agentID {
+		return true
+	}
+	for _, name := range cert.DNSNames {
+		if name == agentID {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *agents) createAgentActor(
 	ctx *actor.Context, id AgentID, resourcePool string, opts *aproto.MasterSetAgentOptions,
 	restoredAgentState *AgentState,
@@ -174,8 +209,14 @@ func (a *agents) createAgentActor(
 		maxZeroSlotContainers: rpConfig.MaxZeroSlotContainers,
 		agentReconnectWait:    time.Duration(rpConfig.AgentReconnectWait),
 		agentReattachEnabled:  rpConfig.AgentReattachEnabled,
+		containerRuntime:      rpConfig.ContainerRuntime,
+		cpuSlots:              rpConfig.CPUSlots,
 		opts:                  opts,
 		agentState:            restoredAgentState,
+		agentHeartbeat: api.PingOptions{
+			Interval:      time.Duration(rpConfig.AgentHeartbeatInterval),
+			MissTolerance: rpConfig.AgentHeartbeatMissTolerance,
+		},
 	})
 	if !ok {
 		return nil, errors.Errorf("agent already connected: %s", id)
@@ -0,0 +1,26 @@
+package rm
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestCertificateIssuedTo(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "agent-1"},
+		DNSNames: []string{"agent-2", "agent-2.example.com"},
+	}
+	for agentID, issued := range map[string]bool{
+		"agent-1":             true,
+		"agent-2":             true,
+		"agent-2.example.com": true,
+		"agent-3":             false,
+		"agent":               false,
+		"":                    false,
+	} {
+		assert.Equal(t, certificateIssuedTo(cert, agentID), issued, agentID)
+	}
+}
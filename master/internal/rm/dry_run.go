@@ -0,0 +1,139 @@
+package rm
+
+import (
+	"fmt"
+
+	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/cproto"
+	"github.com/determined-ai/determined/master/pkg/mathx"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// dryRunAllocationID stands in for the allocation ID of the hypothetical request, which only
+// serves to break ties between equally good agents.
+const dryRunAllocationID = model.AllocationID("dry-run")
+
+func (rp *ResourcePool) scheduleDryRun(
+	ctx *actor.Context, msg sproto.ScheduleDryRunRequest,
+) sproto.ScheduleDryRunResponse {
+	req := &sproto.AllocateRequest{
+		AllocationID:        dryRunAllocationID,
+		SlotsNeeded:         msg.SlotsNeeded,
+		AgentLabel:          msg.AgentLabel,
+		LabelConstraints:    msg.LabelConstraints,
+		ResourcePool:        rp.config.PoolName,
+		FittingRequirements: msg.FittingRequirements,
+	}
+
+	var priority *int
+	if rp.config.Scheduler != nil && rp.config.Scheduler.Priority != nil {
+		priority = rp.config.Scheduler.Priority.DefaultPriority
+		if msg.Priority != nil {
+			priority = msg.Priority
+		}
+	}
+
+	return scheduleDryRun(req, priority, rp.taskList, rp.groups, rp.fetchAgentStates(ctx),
+		rp.fittingMethod, rp.slotsPerInstance)
+}
+
+// scheduleDryRun reports how the scheduler would treat req if it were submitted now. priority is
+// the priority req would have, or nil if the pool doesn't schedule by priority. slotsPerInstance
+// is the size of the agents the provisioner launches, or 0 if the pool has no provisioner.
+func scheduleDryRun(
+	req *sproto.AllocateRequest,
+	priority *int,
+	taskList *taskList,
+	groups map[*actor.Ref]*group,
+	agents map[*actor.Ref]*AgentState,
+	fittingMethod SoftConstraint,
+	slotsPerInstance int,
+) sproto.ScheduleDryRunResponse {
+	var resp sproto.ScheduleDryRunResponse
+	for it := taskList.iterator(); it.next(); {
+		pending := it.value()
+		switch {
+		case taskList.GetAllocations(pending.AllocationRef) != nil:
+			continue
+		case pending.AgentLabel != req.AgentLabel, (pending.SlotsNeeded == 0) != (req.SlotsNeeded == 0):
+			// Tasks for other labels, and zero-slot tasks, are scheduled independently.
+			continue
+		case priority != nil && groups[pending.Group] != nil && groups[pending.Group].priority != nil &&
+			*groups[pending.Group].priority > *priority:
+			continue
+		}
+		resp.QueuedAhead++
+		resp.SlotsAhead += pending.SlotsNeeded
+	}
+
+	if fits := findFits(req, agents, fittingMethod); len(fits) > 0 {
+		resp.Schedulable = true
+		resp.Placement = make(map[string]int, len(fits))
+		for _, fit := range fits {
+			resp.Placement[fit.Agent.string()] += fit.Slots
+		}
+		if resp.QueuedAhead > 0 {
+			resp.Reason = fmt.Sprintf(
+				"fits now, but %d pending allocations needing %d slots are considered first",
+				resp.QueuedAhead, resp.SlotsAhead)
+		}
+		return resp
+	}
+
+	idle := idleAgents(agents)
+	switch {
+	case len(findFits(req, idle, fittingMethod)) > 0:
+		resp.Schedulable = true
+		resp.Reason = fmt.Sprintf(
+			"waiting for slots to be released; %d pending allocations needing %d slots are ahead",
+			resp.QueuedAhead, resp.SlotsAhead)
+	case slotsPerInstance > 0 && (req.SlotsNeeded <= slotsPerInstance ||
+		!req.FittingRequirements.SingleAgent && req.SlotsNeeded%slotsPerInstance == 0):
+		resp.Schedulable = true
+		resp.Reason = "waiting for the provisioner to launch new agents"
+	default:
+		resp.Reason = unschedulableReason(req, idle)
+	}
+	return resp
+}
+
+// idleAgents returns copies of the agents with every slot and zero-slot unit freed, the best case
+// the scheduler could ever reach without new agents joining the pool.
+func idleAgents(agents map[*actor.Ref]*AgentState) map[*actor.Ref]*AgentState {
+	idle := make(map[*actor.Ref]*AgentState, len(agents))
+	for ref, agent := range agents {
+		copied := agent.DeepCopy()
+		for d := range copied.Devices {
+			copied.Devices[d] = nil
+		}
+		copied.containerState = make(map[cproto.ID]*cproto.Container)
+		idle[ref] = copied
+	}
+	return idle
+}
+
+func unschedulableReason(req *sproto.AllocateRequest, agents map[*actor.Ref]*AgentState) string {
+	matching, largest := 0, 0
+	for _, agent := range agents {
+		if !isViable(req, agent, labelSatisfied, labelConstraintsSatisfied) {
+			continue
+		}
+		matching++
+		largest = mathx.Max(largest, agent.NumSlots())
+	}
+
+	switch {
+	case matching == 0:
+		return "no agents in the pool match the requested labels"
+	case req.SlotsNeeded == 0:
+		return "no matching agent accepts zero-slot containers"
+	case req.FittingRequirements.SingleAgent || req.SlotsNeeded <= 1:
+		return fmt.Sprintf("%d slots are needed on a single agent, but the largest matching "+
+			"agent has %d", req.SlotsNeeded, largest)
+	default:
+		return fmt.Sprintf("%d slots can't be split evenly across the %d matching agents; "+
+			"when running on multiple agents, slots must be a multiple of the slots per agent",
+			req.SlotsNeeded, matching)
+	}
+}
@@ -0,0 +1,53 @@
+package rm
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/actor"
+)
+
+func TestScheduleDryRun(t *testing.T) {
+	system := actor.NewSystem(t.Name())
+	agents := []*mockAgent{
+		{id: "agent1", slots: 8},
+	}
+	tasks := []*mockTask{
+		{id: "running", slotsNeeded: 6, allocatedAgent: agents[0], containerStarted: true},
+		{id: "pending", slotsNeeded: 4},
+	}
+	taskList, groups, agentMap := setupSchedulerStates(t, system, tasks, nil, agents)
+	dryRun := func(req sproto.AllocateRequest, slotsPerInstance int) sproto.ScheduleDryRunResponse {
+		req.AllocationID = dryRunAllocationID
+		return scheduleDryRun(&req, nil, taskList, groups, agentMap, BestFit, slotsPerInstance)
+	}
+
+	resp := dryRun(sproto.AllocateRequest{SlotsNeeded: 2}, 0)
+	assert.Assert(t, resp.Schedulable)
+	assert.DeepEqual(t, resp.Placement, map[string]int{"agent1": 2})
+	assert.Equal(t, resp.QueuedAhead, 1)
+	assert.Equal(t, resp.SlotsAhead, 4)
+
+	resp = dryRun(sproto.AllocateRequest{SlotsNeeded: 4}, 0)
+	assert.Assert(t, resp.Schedulable)
+	assert.Equal(t, len(resp.Placement), 0)
+	assert.Assert(t, strings.HasPrefix(resp.Reason, "waiting for slots to be released"))
+
+	resp = dryRun(sproto.AllocateRequest{SlotsNeeded: 16}, 0)
+	assert.Assert(t, !resp.Schedulable)
+	assert.Assert(t, strings.Contains(resp.Reason, "can't be split evenly"), resp.Reason)
+
+	resp = dryRun(sproto.AllocateRequest{SlotsNeeded: 16}, 8)
+	assert.Assert(t, resp.Schedulable)
+	assert.Equal(t, resp.Reason, "waiting for the provisioner to launch new agents")
+
+	resp = dryRun(sproto.AllocateRequest{
+		SlotsNeeded:      1,
+		LabelConstraints: map[string]string{"gpu": "a100"},
+	}, 0)
+	assert.Assert(t, !resp.Schedulable)
+	assert.Equal(t, resp.Reason, "no agents in the pool match the requested labels")
+}
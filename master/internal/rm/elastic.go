@@ -0,0 +1,106 @@
+package rm
+
+import (
+	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/actor"
+)
+
+// elasticResize is a decision to resize a running elastic allocation.
+type elasticResize struct {
+	handler *actor.Ref
+	slots   int
+}
+
+// elasticResizes decides which running elastic allocations should be resized. When non-elastic
+// work is waiting on more slots than are free, the newest elastic allocations are shrunk (by
+// halving, down to their minimum) until enough slots would be released. When nothing is waiting,
+// at most one allocation, the oldest that can, is grown (by doubling, up to its maximum) into
+// the free slots. Allocations in skip, which are already being resized or released, are ignored.
+func elasticResizes(
+	taskList *taskList,
+	agents map[*actor.Ref]*AgentState,
+	fittingMethod SoftConstraint,
+	skip map[*actor.Ref]bool,
+) []elasticResize {
+	freeSlots := 0
+	for _, agent := range agents {
+		freeSlots += agent.NumEmptySlots()
+	}
+
+	var demand int
+	var candidates []*sproto.AllocateRequest
+	for it := taskList.iterator(); it.next(); {
+		req := it.value()
+		switch {
+		case taskList.GetAllocations(req.AllocationRef) == nil:
+			// Pending elastic allocations don't shrink others, or two elastic allocations could
+			// keep trading slots back and forth.
+			if req.Elastic == nil {
+				demand += req.SlotsNeeded
+			}
+		case req.Elastic != nil && req.Preemptible && !skip[req.AllocationRef]:
+			candidates = append(candidates, req)
+		}
+	}
+
+	var resizes []elasticResize
+	if demand > freeSlots {
+		needed := demand - freeSlots
+		for i := len(candidates) - 1; i >= 0 && needed > 0; i-- {
+			req := candidates[i]
+			target := req.SlotsNeeded / 2
+			if target < req.Elastic.MinSlots {
+				target = req.Elastic.MinSlots
+			}
+			if target >= req.SlotsNeeded {
+				continue
+			}
+			resizes = append(resizes, elasticResize{handler: req.AllocationRef, slots: target})
+			needed -= req.SlotsNeeded - target
+		}
+		return resizes
+	}
+
+	if demand > 0 {
+		return nil
+	}
+	for _, req := range candidates {
+		target := req.SlotsNeeded * 2
+		if target > req.Elastic.MaxSlots {
+			target = req.Elastic.MaxSlots
+		}
+		if target <= req.SlotsNeeded || target-req.SlotsNeeded > freeSlots {
+			continue
+		}
+		// Only grow if the larger request would fit without counting the slots it gives up, so a
+		// grown allocation never finds itself stuck in the queue.
+		grown := *req
+		grown.SlotsNeeded = target
+		if len(findFits(&grown, agents, fittingMethod)) == 0 {
+			continue
+		}
+		return append(resizes, elasticResize{handler: req.AllocationRef, slots: target})
+	}
+	return nil
+}
+
+func (rp *ResourcePool) resizeElasticAllocations(ctx *actor.Context, released []*actor.Ref) {
+	skip := make(map[*actor.Ref]bool, len(rp.resizing)+len(released))
+	for handler := range rp.resizing {
+		skip[handler] = true
+	}
+	for _, handler := range released {
+		skip[handler] = true
+	}
+
+	for _, r := range elasticResizes(rp.taskList, rp.agentStatesCache, rp.fittingMethod, skip) {
+		req, ok := rp.taskList.GetAllocationByHandler(r.handler)
+		if !ok {
+			continue
+		}
+		ctx.Log().Infof("resizing elastic allocation %s from %d to %d slots",
+			req.AllocationID, req.SlotsNeeded, r.slots)
+		rp.resizing[r.handler] = true
+		ctx.Tell(r.handler, sproto.ResizeAllocation{AllocationID: req.AllocationID, Slots: r.slots})
+	}
+}
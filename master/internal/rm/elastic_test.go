@@ -0,0 +1,110 @@
+package rm
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/actor"
+)
+
+func TestElasticResizesShrink(t *testing.T) {
+	system := actor.NewSystem(t.Name())
+	elastic := &sproto.ElasticConfig{MinSlots: 2, MaxSlots: 8}
+
+	agents := []*mockAgent{
+		{id: "agent", slots: 8},
+	}
+	tasks := []*mockTask{
+		{
+			id: "elastic1", slotsNeeded: 4, elastic: elastic,
+			allocatedAgent: agents[0], containerStarted: true,
+		},
+		{
+			id: "elastic2", slotsNeeded: 4, elastic: elastic,
+			allocatedAgent: agents[0], containerStarted: true,
+		},
+		{id: "pending", slotsNeeded: 2},
+	}
+
+	taskList, _, agentMap := setupSchedulerStates(t, system, tasks, nil, agents)
+	resizes := elasticResizes(taskList, agentMap, BestFit, nil)
+
+	// Only the newest elastic allocation needs to shrink to make room.
+	assert.Equal(t, len(resizes), 1)
+	assert.Equal(t, resizes[0].handler, system.Get(actor.Addr("elastic2")))
+	assert.Equal(t, resizes[0].slots, 2)
+
+	// Allocations already being resized are left alone.
+	skip := map[*actor.Ref]bool{system.Get(actor.Addr("elastic2")): true}
+	resizes = elasticResizes(taskList, agentMap, BestFit, skip)
+	assert.Equal(t, len(resizes), 1)
+	assert.Equal(t, resizes[0].handler, system.Get(actor.Addr("elastic1")))
+	assert.Equal(t, resizes[0].slots, 2)
+}
+
+func TestElasticResizesShrinkRespectsMinimum(t *testing.T) {
+	system := actor.NewSystem(t.Name())
+
+	agents := []*mockAgent{
+		{id: "agent", slots: 4},
+	}
+	tasks := []*mockTask{
+		{
+			id: "elastic", slotsNeeded: 4, elastic: &sproto.ElasticConfig{MinSlots: 4, MaxSlots: 8},
+			allocatedAgent: agents[0], containerStarted: true,
+		},
+		{id: "pending", slotsNeeded: 2},
+	}
+
+	taskList, _, agentMap := setupSchedulerStates(t, system, tasks, nil, agents)
+	assert.Equal(t, len(elasticResizes(taskList, agentMap, BestFit, nil)), 0)
+}
+
+func TestElasticResizesGrow(t *testing.T) {
+	system := actor.NewSystem(t.Name())
+	elastic := &sproto.ElasticConfig{MinSlots: 1, MaxSlots: 6}
+
+	agents := []*mockAgent{
+		{id: "agent", slots: 8},
+	}
+	tasks := []*mockTask{
+		{
+			id: "elastic1", slotsNeeded: 4, elastic: elastic,
+			allocatedAgent: agents[0], containerStarted: true,
+		},
+		{
+			id: "elastic2", slotsNeeded: 1, elastic: elastic,
+			allocatedAgent: agents[0], containerStarted: true,
+		},
+	}
+
+	taskList, _, agentMap := setupSchedulerStates(t, system, tasks, nil, agents)
+	resizes := elasticResizes(taskList, agentMap, BestFit, nil)
+
+	// The oldest allocation can't fit at its larger size without first giving up the slots it
+	// has, so only the second one grows.
+	assert.Equal(t, len(resizes), 1)
+	assert.Equal(t, resizes[0].handler, system.Get(actor.Addr("elastic2")))
+	assert.Equal(t, resizes[0].slots, 2)
+}
+
+func TestElasticResizesIgnorePendingElastic(t *testing.T) {
+	system := actor.NewSystem(t.Name())
+	elastic := &sproto.ElasticConfig{MinSlots: 1, MaxSlots: 8}
+
+	agents := []*mockAgent{
+		{id: "agent", slots: 4},
+	}
+	tasks := []*mockTask{
+		{
+			id: "elastic1", slotsNeeded: 4, elastic: elastic,
+			allocatedAgent: agents[0], containerStarted: true,
+		},
+		{id: "elastic2", slotsNeeded: 4, elastic: elastic},
+	}
+
+	taskList, _, agentMap := setupSchedulerStates(t, system, tasks, nil, agents)
+	assert.Equal(t, len(elasticResizes(taskList, agentMap, BestFit, nil)), 0)
+}
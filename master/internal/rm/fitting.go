@@ -106,7 +106,9 @@ func findDedicatedAgentFits(
 	// 2) Multi-agent tasks will receive all the slots on every agent they are scheduled on.
 	agentsByNumSlots := make(map[int][]*AgentState)
 	for _, agent := range agentStates {
-		constraints := []HardConstraint{labelSatisfied, agentSlotUnusedSatisfied}
+		constraints := []HardConstraint{
+			labelSatisfied, labelConstraintsSatisfied, agentSlotUnusedSatisfied,
+		}
 		if isViable(req, agent, constraints...) {
 			agentsByNumSlots[agent.NumEmptySlots()] = append(agentsByNumSlots[agent.NumEmptySlots()], agent)
 		}
@@ -173,7 +175,8 @@ func findSharedAgentFit(
 ) *fittingState {
 	var candidates candidateList
 	for _, agent := range agents {
-		if !isViable(req, agent, slotsSatisfied, maxZeroSlotContainersSatisfied, labelSatisfied) {
+		if !isViable(req, agent, slotsSatisfied, maxZeroSlotContainersSatisfied, labelSatisfied,
+			labelConstraintsSatisfied) {
 			continue
 		}
 
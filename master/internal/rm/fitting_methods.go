@@ -16,6 +16,15 @@ func labelSatisfied(req *sproto.AllocateRequest, agent *AgentState) bool {
 	return req.AgentLabel == agent.Label
 }
 
+func labelConstraintsSatisfied(req *sproto.AllocateRequest, agent *AgentState) bool {
+	for k, v := range req.LabelConstraints {
+		if agentValue, ok := agent.Labels[k]; !ok || agentValue != v {
+			return false
+		}
+	}
+	return true
+}
+
 func maxZeroSlotContainersSatisfied(req *sproto.AllocateRequest, agent *AgentState) bool {
 	if req.SlotsNeeded == 0 {
 		return agent.NumEmptyZeroSlots() > 0
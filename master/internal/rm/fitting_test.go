@@ -23,6 +23,25 @@ func TestIsViable(t *testing.T) {
 		newFakeAgentState(t, system, "agent4", "", 1, 0, 100, 0), slotsSatisfied))
 }
 
+func TestLabelConstraints(t *testing.T) {
+	system := actor.NewSystem(t.Name())
+	req := &sproto.AllocateRequest{
+		SlotsNeeded:      1,
+		LabelConstraints: map[string]string{"gpu": "a100"},
+	}
+
+	matching := newFakeAgentState(t, system, "agent1", "", 4, 0, 100, 0)
+	matching.Labels = map[string]string{"gpu": "a100", "rack": "r1"}
+	mismatched := newFakeAgentState(t, system, "agent2", "", 4, 0, 100, 0)
+	mismatched.Labels = map[string]string{"gpu": "v100"}
+	unlabeled := newFakeAgentState(t, system, "agent3", "", 4, 0, 100, 0)
+
+	assert.Assert(t, labelConstraintsSatisfied(req, matching))
+	assert.Assert(t, !labelConstraintsSatisfied(req, mismatched))
+	assert.Assert(t, !labelConstraintsSatisfied(req, unlabeled))
+	assert.Assert(t, labelConstraintsSatisfied(&sproto.AllocateRequest{}, unlabeled))
+}
+
 func TestFindFits(t *testing.T) {
 	type testCase struct {
 		Name          string
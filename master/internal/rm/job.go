@@ -6,6 +6,7 @@ import (
 
 	"github.com/shopspring/decimal"
 
+	"github.com/determined-ai/determined/master/internal/prom"
 	"github.com/determined-ai/determined/master/internal/sproto"
 	"github.com/determined-ai/determined/master/pkg/actor"
 	"github.com/determined-ai/determined/master/pkg/model"
@@ -55,6 +56,15 @@ func jobStats(taskList *taskList) *jobv1.QueueStats {
 	return stats
 }
 
+// observeSchedulerPass exports the queue of a resource pool and how long the scheduler took over
+// it, after a pass that started at the given time.
+func observeSchedulerPass(resourcePool string, taskList *taskList, start time.Time) {
+	prom.ObserveSchedulerPass(resourcePool, time.Since(start))
+	stats := jobStats(taskList)
+	prom.SetSchedulerQueueDepth(
+		resourcePool, int(stats.QueuedCount), int(stats.ScheduledCount))
+}
+
 //nolint:deadcode,nolintlint // Method used by Slurm support in determined-ee
 func jobStatsByPool(taskList *taskList, resourcePool string) *jobv1.QueueStats {
 	reqs := make(AllocReqs, 0)
@@ -0,0 +1,90 @@
+package kubernetes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/cproto"
+	"github.com/determined-ai/determined/master/pkg/model"
+
+	k8sV1 "k8s.io/api/core/v1"
+	policyV1beta1 "k8s.io/api/policy/v1beta1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// nodeCordoned tells pods that a node was marked unschedulable, as it is when it's drained.
+type nodeCordoned struct {
+	nodeName string
+}
+
+// disruptionBudgetName names the pod disruption budget of the pods of an allocation.
+func disruptionBudgetName(allocationID string) string {
+	return "det-" + allocationID
+}
+
+// protectedFromDisruptions returns whether the pods of the task are covered by a pod disruption
+// budget. Evicting a single pod of a distributed trial fails all of them, so its pods are
+// protected, and when their nodes are drained the trial checkpoints and is rescheduled instead.
+func (p *pod) protectedFromDisruptions() bool {
+	return p.numPods > 1 && p.taskSpec.TaskType == model.TaskTypeTrial
+}
+
+// disruptionBudgetSpec returns the pod disruption budget to create along with the pod, if any.
+// Every pod of the allocation asks for the same budget, which is created along with the first.
+func (p *pod) disruptionBudgetSpec() *policyV1beta1.PodDisruptionBudget {
+	if !p.protectedFromDisruptions() {
+		return nil
+	}
+
+	maxUnavailable := intstr.FromInt(0)
+	return &policyV1beta1.PodDisruptionBudget{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      disruptionBudgetName(p.taskSpec.AllocationID),
+			Namespace: p.namespace,
+			Labels:    map[string]string{determinedLabel: p.taskSpec.AllocationID},
+		},
+		Spec: policyV1beta1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector: &metaV1.LabelSelector{
+				MatchLabels: map[string]string{determinedLabel: p.taskSpec.AllocationID},
+			},
+		},
+	}
+}
+
+// notifyCordonedNode tells the pods when a node is newly marked unschedulable.
+func (p *pods) notifyCordonedNode(ctx *actor.Context, previous, node *k8sV1.Node) {
+	if !node.Spec.Unschedulable || (previous != nil && previous.Spec.Unschedulable) {
+		return
+	}
+
+	ctx.Log().Infof("node %s was marked unschedulable", node.Name)
+	for _, ref := range p.podNameToPodHandler {
+		ctx.Tell(ref, nodeCordoned{nodeName: node.Name})
+	}
+}
+
+// receiveNodeCordoned releases the resources of a task that is protected from disruptions when
+// the node of its pod is cordoned, since the node is likely being drained. Its disruption budget
+// holds off the eviction of its pods, while the task checkpoints and is rescheduled elsewhere.
+func (p *pod) receiveNodeCordoned(ctx *actor.Context, msg nodeCordoned) {
+	if !p.protectedFromDisruptions() || p.drainRequested {
+		return
+	}
+	if p.pod == nil || p.pod.Spec.NodeName != msg.nodeName {
+		return
+	}
+	if p.container.State == cproto.Terminated {
+		return
+	}
+	p.drainRequested = true
+
+	ctx.Log().Infof("node %s of pod is being drained, releasing resources", msg.nodeName)
+	p.insertLog(ctx, time.Now().UTC(), fmt.Sprintf(
+		"Pod %s: node %s is being drained, checkpointing to continue on other nodes",
+		p.podName, msg.nodeName))
+	p.taskActor.System().Tell(p.taskActor, sproto.ReleaseResources{ForcePreemption: true})
+}
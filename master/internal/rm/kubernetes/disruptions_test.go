@@ -0,0 +1,31 @@
+//nolint:exhaustivestruct
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/tasks"
+)
+
+func TestDisruptionBudgetSpec(t *testing.T) {
+	p := pod{
+		namespace: "default",
+		numPods:   1,
+		taskSpec:  tasks.TaskSpec{AllocationID: "alloc", TaskType: model.TaskTypeTrial},
+	}
+	require.Nil(t, p.disruptionBudgetSpec())
+
+	p.numPods = 2
+	spec := p.disruptionBudgetSpec()
+	require.NotNil(t, spec)
+	require.Equal(t, "det-alloc", spec.Name)
+	require.Equal(t, 0, spec.Spec.MaxUnavailable.IntValue())
+	require.Equal(t, map[string]string{determinedLabel: "alloc"}, spec.Spec.Selector.MatchLabels)
+
+	// Only trials can checkpoint and continue on other nodes.
+	p.taskSpec.TaskType = model.TaskTypeCommand
+	require.Nil(t, p.disruptionBudgetSpec())
+}
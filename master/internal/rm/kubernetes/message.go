@@ -15,6 +15,8 @@ type (
 		Spec      tasks.TaskSpec
 		Slots     int
 		Rank      int
+		// NumPods is the number of pods of the allocation, which are admitted as a group.
+		NumPods int
 
 		LogContext logger.Context
 	}
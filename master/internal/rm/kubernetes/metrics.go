@@ -0,0 +1,94 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/rest"
+
+	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/actor/actors"
+	"github.com/determined-ai/determined/master/pkg/cproto"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+)
+
+// podMetricsPath is the path of the resource usage of a pod in the Kubernetes metrics API, which
+// is served if the cluster runs the metrics server.
+const podMetricsPath = "/apis/metrics.k8s.io/v1beta1/namespaces/%s/pods/%s"
+
+// podMetricsTimeout bounds how long a measurement of the resource usage of a pod may take.
+const podMetricsTimeout = 10 * time.Second
+
+type measurePodMetrics struct{}
+
+// podMetrics is the resource usage of a pod as the Kubernetes metrics API reports it.
+type podMetrics struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Containers []struct {
+		Name  string                       `json:"name"`
+		Usage map[string]resource.Quantity `json:"usage"`
+	} `json:"containers"`
+}
+
+// receiveMeasurePodMetrics measures the CPU and memory usage of the task container of the pod and
+// reports it to the task actor until the pod stops running. The metrics API does not report the
+// usage of GPUs or the network.
+func (p *pod) receiveMeasurePodMetrics(ctx *actor.Context) {
+	if p.container.State != cproto.Running {
+		return
+	}
+	system, taskActor, containerID := ctx.Self().System(), p.taskActor, p.container.ID
+	client := p.clientSet.CoreV1().RESTClient()
+	path := fmt.Sprintf(podMetricsPath, p.namespace, p.podName)
+	log := ctx.Log()
+	go func() {
+		metrics, err := fetchPodMetrics(client, path)
+		if err != nil {
+			log.WithError(err).Debug("unable to measure the resource usage of the pod")
+			return
+		}
+		system.Tell(taskActor, sproto.ContainerMetrics{ContainerID: containerID, Metrics: metrics})
+	}()
+	actors.NotifyAfter(ctx, p.systemMetricsPeriod, measurePodMetrics{})
+}
+
+func fetchPodMetrics(client rest.Interface, path string) (model.SystemMetrics, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), podMetricsTimeout)
+	defer cancel()
+	body, err := client.Get().AbsPath(path).DoRaw(ctx)
+	if err != nil {
+		return model.SystemMetrics{}, errors.Wrap(err, "querying the metrics API")
+	}
+	return parsePodMetrics(body)
+}
+
+func parsePodMetrics(body []byte) (model.SystemMetrics, error) {
+	var usage podMetrics
+	if err := json.Unmarshal(body, &usage); err != nil {
+		return model.SystemMetrics{}, errors.Wrap(err, "parsing the response of the metrics API")
+	}
+	for _, c := range usage.Containers {
+		if c.Name != model.DeterminedK8ContainerName {
+			continue
+		}
+		metrics := model.SystemMetrics{Time: usage.Timestamp}
+		if metrics.Time.IsZero() {
+			metrics.Time = time.Now().UTC()
+		}
+		if cpu, ok := c.Usage["cpu"]; ok {
+			metrics.CPUCores = ptrs.Ptr(float64(cpu.MilliValue()) / 1000)
+		}
+		if memory, ok := c.Usage["memory"]; ok {
+			metrics.MemoryBytes = ptrs.Ptr(memory.Value())
+		}
+		return metrics, nil
+	}
+	return model.SystemMetrics{}, errors.Errorf(
+		"the metrics API did not report container %s", model.DeterminedK8ContainerName)
+}
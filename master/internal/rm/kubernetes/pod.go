@@ -27,6 +27,7 @@ const (
 	initContainerTarSrcPath   = "/run/determined/temp/tar/src"
 	initContainerTarDstPath   = "/run/determined/temp/tar/dst"
 	initContainerWorkDir      = "/run/determined/temp/"
+	lifecycleDir              = "/run/determined/lifecycle"
 	determinedLabel           = "determined"
 	determinedPreemptionLabel = "determined-preemption"
 	determinedSystemLabel     = "determined-system"
@@ -48,6 +49,8 @@ type pod struct {
 	loggingTLSConfig         model.TLSClientConfig
 	loggingConfig            model.LoggingConfig
 	slots                    int
+	rank                     int
+	numPods                  int
 	podInterface             typedV1.PodInterface
 	configMapInterface       typedV1.ConfigMapInterface
 	resourceRequestQueue     *actor.Ref
@@ -55,7 +58,11 @@ type pod struct {
 	scheduler                string
 	slotType                 device.Type
 	slotResourceRequests     PodSlotResourceRequests
+	slotResourceName         k8sV1.ResourceName
 	fluentConfig             FluentConfig
+	queueIntegration         *QueueIntegrationConfig
+	// systemMetricsPeriod is how often the resource usage of the pod is measured; 0 means never.
+	systemMetricsPeriod time.Duration
 
 	pod           *k8sV1.Pod
 	podName       string
@@ -67,6 +74,12 @@ type pod struct {
 	resourcesDeleted bool
 	testLogStreamer  bool
 	containerNames   map[string]bool
+	// waitingReasons holds the last reason each container was seen waiting for, so that each is
+	// only logged once; lastWarning is the last problem logged, to explain a pod that never ran.
+	waitingReasons map[string]string
+	lastWarning    string
+	// drainRequested is set once the resources of the task were released for a node drain.
+	drainRequested bool
 
 	logCtx logger.Context
 }
@@ -116,6 +129,8 @@ func newPod(
 	slotResourceRequests PodSlotResourceRequests,
 	scheduler string,
 	fluentConfig FluentConfig,
+	queueIntegration *QueueIntegrationConfig,
+	systemMetricsPeriod time.Duration,
 ) *pod {
 	podContainer := cproto.Container{
 		Parent: msg.TaskActor.Address(),
@@ -141,6 +156,8 @@ func newPod(
 		loggingTLSConfig:         loggingTLSConfig,
 		loggingConfig:            loggingConfig,
 		slots:                    msg.Slots,
+		rank:                     msg.Rank,
+		numPods:                  msg.NumPods,
 		podInterface:             podInterface,
 		configMapInterface:       configMapInterface,
 		resourceRequestQueue:     resourceRequestQueue,
@@ -153,6 +170,8 @@ func newPod(
 		slotType:                 slotType,
 		slotResourceRequests:     slotResourceRequests,
 		fluentConfig:             fluentConfig,
+		queueIntegration:         queueIntegration,
+		systemMetricsPeriod:      systemMetricsPeriod,
 		logCtx: logger.MergeContexts(msg.LogContext, logger.Context{
 			"pod": uniqueName,
 		}),
@@ -178,6 +197,9 @@ func (p *pod) Receive(ctx *actor.Context) error {
 	case podEventUpdate:
 		p.receivePodEventUpdate(ctx, msg)
 
+	case nodeCordoned:
+		p.receiveNodeCordoned(ctx, msg)
+
 	case PreemptTaskPod:
 		ctx.Log().Info("received preemption command")
 		p.taskActor.System().Tell(p.taskActor, sproto.ReleaseResources{})
@@ -193,6 +215,9 @@ func (p *pod) Receive(ctx *actor.Context) error {
 	case sproto.ContainerLog:
 		p.receiveContainerLog(ctx, msg)
 
+	case measurePodMetrics:
+		p.receiveMeasurePodMetrics(ctx)
+
 	case KillTaskPod:
 		ctx.Log().Info("received request to stop pod")
 		p.deleteKubernetesResources(ctx)
@@ -235,6 +260,9 @@ func (p *pod) createPodSpecAndSubmit(ctx *actor.Context) error {
 		handler:       ctx.Self(),
 		podSpec:       p.pod,
 		configMapSpec: p.configMap,
+		podGroupSpec:  p.volcanoPodGroupSpec(),
+
+		disruptionBudgetSpec: p.disruptionBudgetSpec(),
 	})
 	return nil
 }
@@ -256,6 +284,10 @@ func (p *pod) receivePodStatusUpdate(ctx *actor.Context, msg podStatusUpdate) er
 		return err
 	}
 
+	if containerState != cproto.Terminated {
+		p.logContainerWaitingReasons(ctx)
+	}
+
 	if containerState == p.container.State {
 		return nil
 	}
@@ -312,20 +344,35 @@ func (p *pod) receivePodStatusUpdate(ctx *actor.Context, msg podStatusUpdate) er
 			Addresses:         addresses,
 			NativeResourcesID: taskContainerID,
 		})
+		if p.systemMetricsPeriod > 0 {
+			ctx.Tell(ctx.Self(), measurePodMetrics{})
+		}
 
 	case cproto.Terminated:
 		exitCode, exitMessage, err := getExitCodeAndMessage(p.pod, p.containerNames)
-		if err != nil {
+		switch {
+		case err != nil && p.pod.Status.Reason != "":
+			// Pods that are evicted or otherwise stopped by the kubelet may not have an exit
+			// code, but the pod status says why they were stopped.
+			ctx.Log().Infof("pod stopped (%s), setting exit code to 137", p.pod.Status.Reason)
+			exitCode = 137
+			exitMessage = ""
+		case err != nil:
 			// When a pod is deleted, it is possible that it will exit before the
 			// determined containers generates an exit code. To check if this is
 			// the case we check if a deletion timestamp has been set.
-			if p.pod.ObjectMeta.DeletionTimestamp != nil {
-				ctx.Log().Info("unable to get exit code for pod setting exit code to 137")
-				exitCode = 137
-				exitMessage = ""
-			} else {
+			if p.pod.ObjectMeta.DeletionTimestamp == nil {
 				return err
 			}
+			ctx.Log().Info("unable to get exit code for pod setting exit code to 137")
+			exitCode = 137
+			exitMessage = ""
+		}
+		if exitMessage == "" && p.pod.Status.Reason != "" {
+			exitMessage = podStatusMessage(p.pod)
+		}
+		if exitMessage == "" {
+			exitMessage = p.lastWarning
 		}
 
 		ctx.Log().Infof("transitioning pod state from %s to %s", p.container.State, containerState)
@@ -490,19 +537,77 @@ func (p *pod) preparePodUpdateMessage(msgText string) string {
 }
 
 func (p *pod) receivePodEventUpdate(ctx *actor.Context, msg podEventUpdate) {
-	// We only forward messages while pods are starting up.
+	// We forward all messages while pods are starting up, and once they are running only those
+	// that explain why they may stop.
 	switch p.container.State {
-	case cproto.Running, cproto.Terminated:
+	case cproto.Terminated:
 		return
+	case cproto.Running:
+		if !isDisruptionEvent(msg.event) {
+			return
+		}
 	}
 
 	msgText := p.preparePodUpdateMessage(msg.event.Message)
 	msg.event.Message = msgText
 
 	message := fmt.Sprintf("Pod %s: %s", msg.event.InvolvedObject.Name, msgText)
+	if msg.event.Type == k8sV1.EventTypeWarning {
+		p.lastWarning = fmt.Sprintf("%s: %s", msg.event.Reason, msgText)
+	}
 	p.insertLog(ctx, msg.event.CreationTimestamp.Time, message)
 }
 
+// disruptionEventReasons are the reasons of normal events that Kubernetes sends when it stops the
+// containers of a running pod.
+var disruptionEventReasons = map[string]bool{
+	"Killing":    true,
+	"Preempting": true,
+	"Evicted":    true,
+}
+
+func isDisruptionEvent(event *k8sV1.Event) bool {
+	return event.Type == k8sV1.EventTypeWarning || disruptionEventReasons[event.Reason]
+}
+
+// waitingReasonsToLog are the reasons for a container to be waiting that won't resolve on their
+// own, which events only report as generic failures or not at all.
+var waitingReasonsToLog = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"CrashLoopBackOff":           true,
+}
+
+// logContainerWaitingReasons logs why containers of the pod are waiting to start, once for each
+// new reason.
+func (p *pod) logContainerWaitingReasons(ctx *actor.Context) {
+	if p.waitingReasons == nil {
+		p.waitingReasons = make(map[string]string)
+	}
+
+	statuses := append(append([]k8sV1.ContainerStatus{},
+		p.pod.Status.InitContainerStatuses...), p.pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		waiting := status.State.Waiting
+		if waiting == nil || !waitingReasonsToLog[waiting.Reason] {
+			continue
+		}
+		if p.waitingReasons[status.Name] == waiting.Reason {
+			continue
+		}
+		p.waitingReasons[status.Name] = waiting.Reason
+
+		p.lastWarning = fmt.Sprintf("container %s: %s", status.Name, waiting.Reason)
+		if waiting.Message != "" {
+			p.lastWarning += ": " + waiting.Message
+		}
+		p.insertLog(ctx, time.Now().UTC(), fmt.Sprintf("Pod %s: %s", p.podName, p.lastWarning))
+	}
+}
+
 func getPodState(
 	ctx *actor.Context,
 	pod *k8sV1.Pod,
@@ -574,7 +679,7 @@ func getExitCodeAndMessage(pod *k8sV1.Pod, containerNames map[string]bool) (int,
 		if exitCode != aproto.SuccessExitCode {
 			errMessage := fmt.Sprintf(
 				"container %s: %s", initContainerStatus.Name,
-				initContainerStatus.State.Terminated.Message,
+				terminationMessage(initContainerStatus.State.Terminated),
 			)
 			return int(exitCode), errMessage, nil
 		}
@@ -594,13 +699,34 @@ func getExitCodeAndMessage(pod *k8sV1.Pod, containerNames map[string]bool) (int,
 	for _, containerStatus := range containerStatuses {
 		terminationStatus := containerStatus.State.Terminated
 		if terminationStatus != nil {
-			return int(terminationStatus.ExitCode), terminationStatus.Message, nil
+			return int(terminationStatus.ExitCode), terminationMessage(terminationStatus), nil
 		}
 	}
 
 	return 0, "", errors.Errorf("unable to get exit code from pod %s", pod.Name)
 }
 
+// terminationMessage describes why a container terminated, e.g., "OOMKilled", along with the
+// message it left, if any.
+func terminationMessage(status *k8sV1.ContainerStateTerminated) string {
+	switch status.Reason {
+	case "", "Error", "Completed":
+		return status.Message
+	}
+	if status.Message == "" {
+		return status.Reason
+	}
+	return fmt.Sprintf("%s: %s", status.Reason, status.Message)
+}
+
+// podStatusMessage describes why a pod was stopped as a whole, e.g., when it is evicted.
+func podStatusMessage(pod *k8sV1.Pod) string {
+	if pod.Status.Message == "" {
+		return fmt.Sprintf("pod %s", pod.Status.Reason)
+	}
+	return fmt.Sprintf("pod %s: %s", pod.Status.Reason, pod.Status.Message)
+}
+
 func getDeterminedContainersStatus(
 	statuses []k8sV1.ContainerStatus,
 	containerNames map[string]bool,
@@ -82,7 +82,7 @@ func createPod(
 		model.TLSClientConfig{}, model.TLSClientConfig{},
 		model.LoggingConfig{DefaultLoggingConfig: &model.DefaultLoggingConfig{}},
 		podInterface, configMapInterface, resourceRequestQueue, leaveKubernetesResources,
-		slotType, slotResourceRequests, "default-scheduler", DefaultFluentConfig,
+		slotType, slotResourceRequests, "default-scheduler", DefaultFluentConfig, nil, 0,
 	)
 
 	return newPodHandler
@@ -640,13 +640,114 @@ func TestReceivePodEventUpdate(t *testing.T) {
 	time.Sleep(time.Second)
 	assert.Equal(t, podMap["task"].GetLength(), 0)
 
+	// When container is in Running state, pod actor should forward warnings.
+	warningEvent := k8sV1.Event{
+		InvolvedObject: object,
+		Type:           k8sV1.EventTypeWarning,
+		Reason:         "Evicted",
+		Message:        "The node was low on resource: memory.",
+	}
+	system.Ask(ref, podEventUpdate{event: &warningEvent})
+	time.Sleep(time.Second)
+	assert.Equal(t, podMap["task"].GetLength(), 1)
+	message, err = podMap["task"].Pop()
+	assert.NilError(t, err)
+	assert.Equal(t, *message.(sproto.ContainerLog).AuxMessage,
+		fmt.Sprintf("Pod %s: %s", object.Name, warningEvent.Message))
+	assert.Equal(t, newPod.lastWarning, "Evicted: The node was low on resource: memory.")
+
 	// When container is in Terminated state, pod actor should not forward message.
 	newPod.container.State = cproto.Terminated
 	system.Ask(ref, podEventUpdate{event: &newEvent})
+	system.Ask(ref, podEventUpdate{event: &warningEvent})
 	time.Sleep(time.Second)
 	assert.Equal(t, podMap["task"].GetLength(), 0)
 }
 
+func TestReceivePodStatusUpdateWaitingReason(t *testing.T) {
+	setupEntrypoint(t)
+	defer cleanup(t)
+
+	system, newPod, ref, podMap, _ := createPodWithMockQueue()
+	podMap["task"].Purge()
+
+	waiting := &k8sV1.ContainerStateWaiting{
+		Reason:  "ImagePullBackOff",
+		Message: `Back-off pulling image "missing:latest"`,
+	}
+	pod := k8sV1.Pod{
+		ObjectMeta: metaV1.ObjectMeta{Name: "test meta"},
+		Status: k8sV1.PodStatus{
+			Phase: k8sV1.PodPending,
+			Conditions: []k8sV1.PodCondition{
+				{Type: k8sV1.PodScheduled, Status: k8sV1.ConditionTrue},
+			},
+			ContainerStatuses: []k8sV1.ContainerStatus{
+				{
+					Name:  "determined-container",
+					State: k8sV1.ContainerState{Waiting: waiting},
+				},
+			},
+		},
+	}
+	statusUpdate := podStatusUpdate{updatedPod: &pod}
+
+	// The reason is logged along with the transition to Starting, but only once.
+	system.Ask(ref, statusUpdate)
+	system.Ask(ref, statusUpdate)
+	time.Sleep(time.Second)
+	assert.Equal(t, podMap["task"].GetLength(), 3)
+	message, err := podMap["task"].Pop()
+	assert.NilError(t, err)
+	assert.Equal(t, *message.(sproto.ContainerLog).AuxMessage, fmt.Sprintf(
+		`Pod %s: container determined-container: ImagePullBackOff: `+
+			`Back-off pulling image "missing:latest"`, newPod.podName))
+}
+
+func TestGetExitCodeAndMessage(t *testing.T) {
+	initContainerStatuses := []k8sV1.ContainerStatus{
+		{
+			Name: "determined-init-container",
+			State: k8sV1.ContainerState{
+				Terminated: &k8sV1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"},
+			},
+		},
+	}
+	containerNames := map[string]bool{"determined-container": true}
+
+	pod := &k8sV1.Pod{Status: k8sV1.PodStatus{
+		Phase:                 k8sV1.PodFailed,
+		InitContainerStatuses: initContainerStatuses,
+		ContainerStatuses: []k8sV1.ContainerStatus{
+			{
+				Name: "determined-container",
+				State: k8sV1.ContainerState{
+					Terminated: &k8sV1.ContainerStateTerminated{
+						ExitCode: 137,
+						Reason:   "OOMKilled",
+					},
+				},
+			},
+		},
+	}}
+	exitCode, exitMessage, err := getExitCodeAndMessage(pod, containerNames)
+	assert.NilError(t, err)
+	assert.Equal(t, exitCode, 137)
+	assert.Equal(t, exitMessage, "OOMKilled")
+
+	// Evicted pods need not have an exit code, but say why they stopped.
+	evicted := &k8sV1.Pod{Status: k8sV1.PodStatus{
+		Phase:                 k8sV1.PodFailed,
+		Reason:                "Evicted",
+		Message:               "The node was low on resource: ephemeral-storage.",
+		InitContainerStatuses: initContainerStatuses,
+	}}
+	_, _, err = getExitCodeAndMessage(evicted, containerNames)
+	assert.ErrorContains(t, err, "unexpected number of containers")
+	assert.Equal(t, podStatusMessage(evicted),
+		"pod Evicted: The node was low on resource: ephemeral-storage.")
+}
+
 func TestReceiveContainerLog(t *testing.T) {
 	setupEntrypoint(t)
 	defer cleanup(t)
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/determined-ai/determined/master/pkg/cproto"
 
@@ -28,8 +29,10 @@ import (
 	k8sV1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	k8sClient "k8s.io/client-go/kubernetes"
 	typedV1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	policyV1beta1Client "k8s.io/client-go/kubernetes/typed/policy/v1beta1"
 	"k8s.io/client-go/rest"
 
 	// Used to load all auth plugins.
@@ -48,6 +51,7 @@ type podMetadata struct {
 //        +- podLogStreamer: stream logs for a specific pod.
 //     +- informer: sends updates about pod states
 //     +- events: sends updates about kubernetes events.
+//     +- quotas: sends updates about the resource quotas of the namespace.
 //     +- requestQueue: queues requests to create / delete kubernetes resources.
 //        +- requestProcessingWorkers: processes request to create / delete kubernetes resources.
 type pods struct {
@@ -59,19 +63,28 @@ type pods struct {
 	slotType                 device.Type
 	slotResourceRequests     PodSlotResourceRequests
 	fluentConfig             FluentConfig
+	queueIntegration         *QueueIntegrationConfig
 	credsDir                 string
 
+	// configuredSlotResourceName is the extended resource that slots come from, if configured;
+	// otherwise slotResourceName is discovered from the nodes as they change.
+	configuredSlotResourceName k8sV1.ResourceName
+	slotResourceName           k8sV1.ResourceName
+
 	clientSet        *k8sClient.Clientset
 	masterIP         string
 	masterPort       int32
 	masterTLSConfig  model.TLSClientConfig
 	loggingTLSConfig model.TLSClientConfig
 	loggingConfig    model.LoggingConfig
+	// systemMetricsPeriod is how often the resource usage of pods is measured; 0 means never.
+	systemMetricsPeriod time.Duration
 
 	informer                     *actor.Ref
 	nodeInformer                 *actor.Ref
 	eventListener                *actor.Ref
 	preemptionListener           *actor.Ref
+	quotaListener                *actor.Ref
 	resourceRequestQueue         *actor.Ref
 	podNameToPodHandler          map[string]*actor.Ref
 	containerIDToPodName         map[string]string
@@ -81,9 +94,14 @@ type pods struct {
 	nodeToSystemResourceRequests map[string]int64
 
 	currentNodes map[string]*k8sV1.Node
+	// quotas are the resource quotas of the namespace, by name.
+	quotas map[string]*k8sV1.ResourceQuota
 
 	podInterface       typedV1.PodInterface
 	configMapInterface typedV1.ConfigMapInterface
+	podGroupInterface  dynamic.ResourceInterface
+	// disruptionBudgetInterface creates the pod disruption budgets of distributed trials.
+	disruptionBudgetInterface policyV1beta1Client.PodDisruptionBudgetInterface
 }
 
 // PodsInfo contains information for pods.
@@ -108,10 +126,13 @@ func Initialize(
 	scheduler string,
 	slotType device.Type,
 	slotResourceRequests PodSlotResourceRequests,
+	slotResourceName string,
 	fluentConfig FluentConfig,
+	queueIntegration *QueueIntegrationConfig,
 	credsDir string,
 	masterIP string,
 	masterPort int32,
+	systemMetricsPeriod time.Duration,
 ) *actor.Ref {
 	loggingTLSConfig := masterTLSConfig
 	if loggingConfig.ElasticLoggingConfig != nil {
@@ -134,12 +155,16 @@ func Initialize(
 		leaveKubernetesResources:     leaveKubernetesResources,
 		slotType:                     slotType,
 		slotResourceRequests:         slotResourceRequests,
+		configuredSlotResourceName:   k8sV1.ResourceName(slotResourceName),
 		fluentConfig:                 fluentConfig,
+		queueIntegration:             queueIntegration,
 		credsDir:                     credsDir,
 		masterIP:                     masterIP,
 		masterPort:                   masterPort,
+		systemMetricsPeriod:          systemMetricsPeriod,
 		currentNodes:                 make(map[string]*k8sV1.Node),
 		nodeToSystemResourceRequests: make(map[string]int64),
+		quotas:                       make(map[string]*k8sV1.ResourceQuota),
 	})
 	check.Panic(check.True(ok, "pods address already taken"))
 	s.Ask(podsActor, actor.Ping{}).Get()
@@ -161,6 +186,7 @@ func (p *pods) Receive(ctx *actor.Context) error {
 		if err := p.getSystemResourceRequests(ctx); err != nil {
 			return err
 		}
+		p.updateSlotResourceName()
 		p.startResourceRequestQueue(ctx)
 		if err := p.deleteExistingKubernetesResources(ctx); err != nil {
 			return err
@@ -169,6 +195,7 @@ func (p *pods) Receive(ctx *actor.Context) error {
 		p.startNodeInformer(ctx)
 		p.startEventListener(ctx)
 		p.startPreemptionListener(ctx)
+		p.startQuotaListener(ctx)
 
 	case actor.PostStop:
 
@@ -186,6 +213,9 @@ func (p *pods) Receive(ctx *actor.Context) error {
 	case podEventUpdate:
 		p.receivePodEventUpdate(ctx, msg)
 
+	case quotaUpdate:
+		p.receiveQuotaUpdate(ctx, msg)
+
 	case PreemptTaskPod:
 		p.receivePodPreemption(ctx, msg)
 
@@ -201,6 +231,12 @@ func (p *pods) Receive(ctx *actor.Context) error {
 	case SummarizeResources:
 		p.receiveResourceSummarize(ctx, msg)
 
+	case MaxSlotsPerNode:
+		p.receiveMaxSlotsPerNode(ctx)
+
+	case QuotaSlots:
+		ctx.Respond(p.tightestQuota())
+
 	case resourceDeletionFailed:
 		if msg.err != nil {
 			ctx.Log().WithError(msg.err).Error("error deleting leftover kubernetes resource")
@@ -221,6 +257,8 @@ func (p *pods) Receive(ctx *actor.Context) error {
 			return errors.Errorf("event listener failed")
 		case p.preemptionListener:
 			return errors.Errorf("preemption listener failed")
+		case p.quotaListener:
+			return errors.Errorf("resource quota listener failed")
 		case p.resourceRequestQueue:
 			return errors.Errorf("resource request actor failed")
 		}
@@ -242,7 +280,9 @@ func (p *pods) Receive(ctx *actor.Context) error {
 	return nil
 }
 
-func readClientConfig(credsDir string) (*rest.Config, error) {
+// ReadClientConfig returns the config for clients of the cluster, either from the credentials in
+// credsDir or, if unset, from the pod the master runs in.
+func ReadClientConfig(credsDir string) (*rest.Config, error) {
 	if credsDir == "" {
 		// The default in-cluster case.  Internally, k8s.io/client-go/rest is going to look for
 		// environment variables:
@@ -284,7 +324,7 @@ func readClientConfig(credsDir string) (*rest.Config, error) {
 }
 
 func (p *pods) startClientSet(ctx *actor.Context) error {
-	config, err := readClientConfig(p.credsDir)
+	config, err := ReadClientConfig(p.credsDir)
 	if err != nil {
 		return errors.Wrap(err, "error building kubernetes config")
 	}
@@ -296,6 +336,15 @@ func (p *pods) startClientSet(ctx *actor.Context) error {
 
 	p.podInterface = p.clientSet.CoreV1().Pods(p.namespace)
 	p.configMapInterface = p.clientSet.CoreV1().ConfigMaps(p.namespace)
+	p.disruptionBudgetInterface = p.clientSet.PolicyV1beta1().PodDisruptionBudgets(p.namespace)
+
+	if p.queueIntegration != nil && p.queueIntegration.Type == QueueIntegrationVolcano {
+		dynamicClient, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return errors.Wrap(err, "failed to initialize kubernetes dynamic client")
+		}
+		p.podGroupInterface = dynamicClient.Resource(volcanoPodGroupResource).Namespace(p.namespace)
+	}
 
 	ctx.Log().Infof("kubernetes clientSet initialized")
 	return nil
@@ -386,11 +435,16 @@ func (p *pods) startPreemptionListener(ctx *actor.Context) {
 		"preemption-listener", newPreemptionListener(p.clientSet, p.namespace, ctx.Self()))
 }
 
+func (p *pods) startQuotaListener(ctx *actor.Context) {
+	p.quotaListener, _ = ctx.ActorOf(
+		"quota-listener", newQuotaListener(p.clientSet, p.namespace, ctx.Self()))
+}
+
 func (p *pods) startResourceRequestQueue(ctx *actor.Context) {
-	p.resourceRequestQueue, _ = ctx.ActorOf(
-		"kubernetes-resource-request-queue",
-		newRequestQueue(p.podInterface, p.configMapInterface),
-	)
+	queue := newRequestQueue(p.podInterface, p.configMapInterface)
+	queue.podGroupInterface = p.podGroupInterface
+	queue.disruptionBudgetInterface = p.disruptionBudgetInterface
+	p.resourceRequestQueue, _ = ctx.ActorOf("kubernetes-resource-request-queue", queue)
 }
 
 func (p *pods) receiveStartTaskPod(ctx *actor.Context, msg StartTaskPod) error {
@@ -398,8 +452,10 @@ func (p *pods) receiveStartTaskPod(ctx *actor.Context, msg StartTaskPod) error {
 		msg, p.cluster, msg.Spec.ClusterID, p.clientSet, p.namespace, p.masterIP, p.masterPort,
 		p.masterTLSConfig, p.loggingTLSConfig, p.loggingConfig, p.podInterface, p.configMapInterface,
 		p.resourceRequestQueue, p.leaveKubernetesResources,
-		p.slotType, p.slotResourceRequests, p.scheduler, p.fluentConfig,
+		p.slotType, p.slotResourceRequests, p.scheduler, p.fluentConfig, p.queueIntegration,
+		p.systemMetricsPeriod,
 	)
+	newPodHandler.slotResourceName = p.slotResourceName
 	ref, ok := ctx.ActorOf(fmt.Sprintf("pod-%s", msg.Spec.ContainerID), newPodHandler)
 	if !ok {
 		return errors.Errorf("pod actor %s already exists", ref.Address().String())
@@ -453,12 +509,19 @@ func (p *pods) receivePodStatusUpdate(ctx *actor.Context, msg podStatusUpdate) {
 
 func (p *pods) receiveNodeStatusUpdate(ctx *actor.Context, msg nodeStatusUpdate) {
 	if msg.updatedNode != nil {
+		p.notifyCordonedNode(ctx, p.currentNodes[msg.updatedNode.Name], msg.updatedNode)
 		p.currentNodes[msg.updatedNode.Name] = msg.updatedNode
 	}
 
 	if msg.deletedNode != nil {
 		delete(p.currentNodes, msg.deletedNode.Name)
 	}
+
+	previous := p.slotResourceName
+	p.updateSlotResourceName()
+	if p.slotResourceName != previous {
+		ctx.Log().Infof("slots now come from the %s resource of nodes", p.slotResourceName)
+	}
 }
 
 func (p *pods) receivePodEventUpdate(ctx *actor.Context, msg podEventUpdate) {
@@ -480,6 +543,10 @@ func (p *pods) receiveResourceSummarize(ctx *actor.Context, msg SummarizeResourc
 	for _, node := range summary {
 		slots += len(node.Slots)
 	}
+	// Pods can't request more slots than the resource quotas of the namespace allow.
+	if quota := p.tightestQuota(); quota != nil && quota.Limit < slots {
+		slots = quota.Limit
+	}
 	ctx.Respond(&PodsInfo{NumAgents: len(summary), SlotsAvailable: slots})
 }
 
@@ -618,23 +685,7 @@ func (p *pods) summarize(ctx *actor.Context) map[string]model.AgentSummary {
 
 	summary := make(map[string]model.AgentSummary, len(p.currentNodes))
 	for _, node := range p.currentNodes {
-		var numSlots int64
-		var deviceType device.Type
-		switch p.slotType {
-		case device.CPU:
-			resources := node.Status.Allocatable["cpu"]
-			milliCPUs := resources.MilliValue() - p.nodeToSystemResourceRequests[node.Name]
-			numSlots = int64(float32(milliCPUs) / (1000. * p.slotResourceRequests.CPU))
-			deviceType = device.CPU
-		case device.ROCM:
-			panic("ROCm is not supported on k8s yet")
-		case device.CUDA:
-			fallthrough
-		default:
-			resources := node.Status.Allocatable["nvidia.com/gpu"]
-			numSlots = resources.Value()
-			deviceType = device.CUDA
-		}
+		numSlots, deviceType := p.nodeSlots(node)
 		if numSlots < 1 {
 			continue
 		}
@@ -743,7 +794,7 @@ func (p *pods) getNonDetSlots(deviceType device.Type) (map[string][]string, map[
 			if deviceType == device.CPU {
 				reqs += p.getCPUReqs(c)
 			} else if deviceType == device.CUDA {
-				reqs += c.Resources.Requests.Name("nvidia.com/gpu", resource.DecimalSI).Value()
+				reqs += c.Resources.Requests.Name(p.slotResourceName, resource.DecimalSI).Value()
 			}
 		}
 		if reqs > 0 {
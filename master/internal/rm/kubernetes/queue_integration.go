@@ -0,0 +1,116 @@
+package kubernetes
+
+import (
+	"strconv"
+
+	"github.com/determined-ai/determined/master/pkg/check"
+
+	k8sV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// QueueIntegrationKueue submits pods to a Kueue LocalQueue as a plain pod group.
+	QueueIntegrationKueue = "kueue"
+	// QueueIntegrationVolcano submits pods to the Volcano scheduler as members of a PodGroup.
+	QueueIntegrationVolcano = "volcano"
+
+	kueueQueueNameLabel               = "kueue.x-k8s.io/queue-name"
+	kueuePodGroupNameLabel            = "kueue.x-k8s.io/pod-group-name"
+	kueuePodGroupTotalCountAnnotation = "kueue.x-k8s.io/pod-group-total-count"
+
+	volcanoSchedulerName       = "volcano"
+	volcanoGroupNameAnnotation = "scheduling.k8s.io/group-name"
+	volcanoQueueNameAnnotation = "scheduling.volcano.sh/queue-name"
+)
+
+var volcanoPodGroupResource = schema.GroupVersionResource{
+	Group:    "scheduling.volcano.sh",
+	Version:  "v1beta1",
+	Resource: "podgroups",
+}
+
+// QueueIntegrationConfig configures handing the pods of each allocation to an external batch
+// scheduler, which then owns queueing, quota, and gang scheduling for them.
+type QueueIntegrationConfig struct {
+	Type string `json:"type"`
+	// Queue is the Kueue LocalQueue or the Volcano Queue to submit to. A task can override it
+	// with the scheduler's own queue label or annotation in its pod spec.
+	Queue string `json:"queue"`
+}
+
+// Validate implements the check.Validatable interface.
+func (c QueueIntegrationConfig) Validate() []error {
+	return []error{
+		check.True(c.Type == QueueIntegrationKueue || c.Type == QueueIntegrationVolcano,
+			"queue_integration.type must be either kueue or volcano"),
+		check.True(c.Type != QueueIntegrationKueue || c.Queue != "",
+			"queue_integration.queue must be set to use kueue"),
+	}
+}
+
+// podGroupName names the group of pods of an allocation, which are admitted all at once.
+func podGroupName(allocationID string) string {
+	return "det-" + allocationID
+}
+
+func (p *pod) configureQueueIntegration(newPod *k8sV1.Pod) {
+	if p.queueIntegration == nil {
+		return
+	}
+
+	if newPod.ObjectMeta.Annotations == nil {
+		newPod.ObjectMeta.Annotations = make(map[string]string)
+	}
+	groupName := podGroupName(p.taskSpec.AllocationID)
+
+	switch p.queueIntegration.Type {
+	case QueueIntegrationKueue:
+		if _, ok := newPod.ObjectMeta.Labels[kueueQueueNameLabel]; !ok {
+			newPod.ObjectMeta.Labels[kueueQueueNameLabel] = p.queueIntegration.Queue
+		}
+		newPod.ObjectMeta.Labels[kueuePodGroupNameLabel] = groupName
+		newPod.ObjectMeta.Annotations[kueuePodGroupTotalCountAnnotation] = strconv.Itoa(p.numPods)
+	case QueueIntegrationVolcano:
+		newPod.Spec.SchedulerName = volcanoSchedulerName
+		newPod.ObjectMeta.Annotations[volcanoGroupNameAnnotation] = groupName
+	}
+}
+
+// volcanoPodGroupSpec returns the PodGroup that the pods of the allocation join, or nil if there
+// is none to create. Only the first pod of an allocation creates it, so that it is deleted along
+// with that pod's resources.
+func (p *pod) volcanoPodGroupSpec() *unstructured.Unstructured {
+	if p.queueIntegration == nil || p.queueIntegration.Type != QueueIntegrationVolcano ||
+		p.rank != 0 {
+		return nil
+	}
+
+	spec := map[string]interface{}{
+		"minMember": int64(p.numPods),
+	}
+	queue := p.queueIntegration.Queue
+	if q, ok := p.pod.ObjectMeta.Annotations[volcanoQueueNameAnnotation]; ok {
+		queue = q
+	}
+	if queue != "" {
+		spec["queue"] = queue
+	}
+	if p.pod.Spec.PriorityClassName != "" {
+		spec["priorityClassName"] = p.pod.Spec.PriorityClassName
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": volcanoPodGroupResource.GroupVersion().String(),
+		"kind":       "PodGroup",
+		"metadata": map[string]interface{}{
+			"name":      podGroupName(p.taskSpec.AllocationID),
+			"namespace": p.namespace,
+			"labels": map[string]interface{}{
+				determinedLabel: p.taskSpec.AllocationID,
+			},
+		},
+		"spec": spec,
+	}}
+}
@@ -0,0 +1,178 @@
+package kubernetes
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/actor/actors"
+	"github.com/determined-ai/determined/master/pkg/device"
+
+	k8sV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sClient "k8s.io/client-go/kubernetes"
+)
+
+// Messages that are sent to the quota listener.
+type startQuotaListener struct{}
+
+// Messages that are sent by the quota listener.
+type quotaUpdate struct {
+	quota   *k8sV1.ResourceQuota
+	deleted bool
+}
+
+// QuotaSlots asks for the slots that the resource quotas of the namespace allow pods to request.
+// The response is a *QuotaSlotsInfo, which is nil if no quota limits slots.
+type QuotaSlots struct{}
+
+// QuotaSlotsInfo describes the resource quota of the namespace that leaves the fewest slots free.
+type QuotaSlotsInfo struct {
+	Name  string
+	Limit int
+	Used  int
+}
+
+type quotaListener struct {
+	clientSet   *k8sClient.Clientset
+	namespace   string
+	podsHandler *actor.Ref
+}
+
+func newQuotaListener(
+	clientSet *k8sClient.Clientset,
+	namespace string,
+	podsHandler *actor.Ref,
+) *quotaListener {
+	return &quotaListener{
+		clientSet:   clientSet,
+		namespace:   namespace,
+		podsHandler: podsHandler,
+	}
+}
+
+func (q *quotaListener) Receive(ctx *actor.Context) error {
+	switch msg := ctx.Message().(type) {
+	case actor.PreStart:
+		ctx.Tell(ctx.Self(), startQuotaListener{})
+
+	case startQuotaListener:
+		q.startQuotaListener(ctx)
+
+	case actor.PostStop:
+
+	default:
+		ctx.Log().Errorf("unexpected message %T", msg)
+		return actor.ErrUnexpectedMessage(ctx)
+	}
+
+	return nil
+}
+
+func (q *quotaListener) startQuotaListener(ctx *actor.Context) {
+	quotas, err := q.clientSet.CoreV1().ResourceQuotas(q.namespace).List(
+		context.TODO(), metaV1.ListOptions{})
+	if err != nil {
+		ctx.Log().WithError(err).Warnf("error retrieving resource quotas")
+		actors.NotifyAfter(ctx, defaultInformerBackoff, startQuotaListener{})
+		return
+	}
+
+	for i := range quotas.Items {
+		ctx.Tell(q.podsHandler, quotaUpdate{quota: &quotas.Items[i]})
+	}
+
+	rw, err := watchtools.NewRetryWatcher(quotas.ResourceVersion, &cache.ListWatch{
+		WatchFunc: func(options metaV1.ListOptions) (watch.Interface, error) {
+			return q.clientSet.CoreV1().ResourceQuotas(q.namespace).Watch(
+				context.TODO(), metaV1.ListOptions{})
+		},
+	})
+	if err != nil {
+		ctx.Log().WithError(err).Warnf("error initializing resource quota watch")
+		actors.NotifyAfter(ctx, defaultInformerBackoff, startQuotaListener{})
+		return
+	}
+
+	ctx.Log().Info("resource quota listener is starting")
+	for e := range rw.ResultChan() {
+		if e.Type == watch.Error {
+			ctx.Log().WithField("error", e.Object).Warnf("resource quota listener encountered error")
+			continue
+		}
+
+		quota, ok := e.Object.(*k8sV1.ResourceQuota)
+		if !ok {
+			ctx.Log().Warnf("error converting object type %T to *k8sV1.ResourceQuota: %+v", e, e)
+			continue
+		}
+		ctx.Tell(q.podsHandler, quotaUpdate{quota: quota, deleted: e.Type == watch.Deleted})
+	}
+
+	ctx.Log().Warn("resource quota listener stopped unexpectedly")
+	ctx.Tell(ctx.Self(), startQuotaListener{})
+}
+
+func (p *pods) receiveQuotaUpdate(ctx *actor.Context, msg quotaUpdate) {
+	if msg.deleted {
+		delete(p.quotas, msg.quota.Name)
+		return
+	}
+	if _, ok := p.quotas[msg.quota.Name]; !ok {
+		ctx.Log().Infof("found resource quota %s", msg.quota.Name)
+	}
+	p.quotas[msg.quota.Name] = msg.quota
+}
+
+// quotaSlots returns the slots that a single resource quota allows and has used, or false if it
+// doesn't limit slots.
+func (p *pods) quotaSlots(quota *k8sV1.ResourceQuota) (limit, used int, ok bool) {
+	switch p.slotType {
+	case device.CPU:
+		for _, name := range []k8sV1.ResourceName{k8sV1.ResourceRequestsCPU, k8sV1.ResourceCPU} {
+			hard, ok := quota.Status.Hard[name]
+			if !ok {
+				hard, ok = quota.Spec.Hard[name]
+			}
+			if !ok {
+				continue
+			}
+			usage := quota.Status.Used[name]
+			perSlot := 1000. * p.slotResourceRequests.CPU
+			return int(float32(hard.MilliValue()) / perSlot),
+				int(float32(usage.MilliValue()) / perSlot), true
+		}
+		return 0, 0, false
+	default:
+		// Quotas on extended resources are only allowed on their requests.
+		name := k8sV1.ResourceName(k8sV1.DefaultResourceRequestsPrefix + p.slotResourceName)
+		hard, ok := quota.Status.Hard[name]
+		if !ok {
+			hard, ok = quota.Spec.Hard[name]
+		}
+		if !ok {
+			return 0, 0, false
+		}
+		usage := quota.Status.Used[name]
+		return int(hard.Value()), int(usage.Value()), true
+	}
+}
+
+// tightestQuota returns the resource quota that leaves the fewest slots free, or nil if none
+// limits slots.
+func (p *pods) tightestQuota() *QuotaSlotsInfo {
+	var tightest *QuotaSlotsInfo
+	for name, quota := range p.quotas {
+		limit, used, ok := p.quotaSlots(quota)
+		if !ok {
+			continue
+		}
+		if tightest == nil || limit-used < tightest.Limit-tightest.Used {
+			tightest = &QuotaSlotsInfo{Name: name, Limit: limit, Used: used}
+		}
+	}
+	return tightest
+}
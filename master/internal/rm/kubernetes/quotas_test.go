@@ -0,0 +1,56 @@
+//nolint:exhaustivestruct
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	k8sV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/determined-ai/determined/master/pkg/device"
+)
+
+func quota(name string, hard, used map[k8sV1.ResourceName]string) *k8sV1.ResourceQuota {
+	q := &k8sV1.ResourceQuota{
+		ObjectMeta: metaV1.ObjectMeta{Name: name},
+		Status:     k8sV1.ResourceQuotaStatus{Hard: k8sV1.ResourceList{}, Used: k8sV1.ResourceList{}},
+	}
+	for resourceName, value := range hard {
+		q.Status.Hard[resourceName] = resource.MustParse(value)
+	}
+	for resourceName, value := range used {
+		q.Status.Used[resourceName] = resource.MustParse(value)
+	}
+	return q
+}
+
+func TestTightestQuota(t *testing.T) {
+	p := pods{
+		slotType:         device.CUDA,
+		slotResourceName: "nvidia.com/gpu",
+		quotas:           map[string]*k8sV1.ResourceQuota{},
+	}
+	require.Nil(t, p.tightestQuota())
+
+	p.quotas["memory"] = quota("memory",
+		map[k8sV1.ResourceName]string{"requests.memory": "1Ti"}, nil)
+	require.Nil(t, p.tightestQuota())
+
+	p.quotas["team"] = quota("team",
+		map[k8sV1.ResourceName]string{"requests.nvidia.com/gpu": "16"},
+		map[k8sV1.ResourceName]string{"requests.nvidia.com/gpu": "4"})
+	p.quotas["small"] = quota("small",
+		map[k8sV1.ResourceName]string{"requests.nvidia.com/gpu": "8"},
+		map[k8sV1.ResourceName]string{"requests.nvidia.com/gpu": "2"})
+	require.Equal(t, &QuotaSlotsInfo{Name: "small", Limit: 8, Used: 2}, p.tightestQuota())
+
+	// CPU slots are counted in units of the CPU each slot requests.
+	p.slotType = device.CPU
+	p.slotResourceRequests = PodSlotResourceRequests{CPU: 2}
+	p.quotas["cpu"] = quota("cpu",
+		map[k8sV1.ResourceName]string{"requests.cpu": "20"},
+		map[k8sV1.ResourceName]string{"requests.cpu": "3"})
+	require.Equal(t, &QuotaSlotsInfo{Name: "cpu", Limit: 10, Used: 1}, p.tightestQuota())
+}
@@ -8,7 +8,11 @@ import (
 	"github.com/determined-ai/determined/master/pkg/actor"
 
 	k8sV1 "k8s.io/api/core/v1"
+	policyV1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
 	typedV1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	policyV1beta1Client "k8s.io/client-go/kubernetes/typed/policy/v1beta1"
 )
 
 const (
@@ -22,6 +26,10 @@ type (
 		handler       *actor.Ref
 		podSpec       *k8sV1.Pod
 		configMapSpec *k8sV1.ConfigMap
+		// podGroupSpec is the Volcano PodGroup to create along with the pod, if any.
+		podGroupSpec *unstructured.Unstructured
+		// disruptionBudgetSpec is the pod disruption budget to create along with the pod, if any.
+		disruptionBudgetSpec *policyV1beta1.PodDisruptionBudget
 	}
 
 	deleteKubernetesResources struct {
@@ -96,6 +104,9 @@ type queuedResourceRequest struct {
 type requestQueue struct {
 	podInterface       typedV1.PodInterface
 	configMapInterface typedV1.ConfigMapInterface
+	// podGroupInterface is only set when pods are submitted to Volcano.
+	podGroupInterface         dynamic.ResourceInterface
+	disruptionBudgetInterface policyV1beta1Client.PodDisruptionBudgetInterface
 
 	queue                    []*queuedResourceRequest
 	pendingResourceCreations map[*actor.Ref]*queuedResourceRequest
@@ -131,6 +142,9 @@ func (r *requestQueue) Receive(ctx *actor.Context) error {
 				&requestProcessingWorker{
 					podInterface:       r.podInterface,
 					configMapInterface: r.configMapInterface,
+					podGroupInterface:  r.podGroupInterface,
+
+					disruptionBudgetInterface: r.disruptionBudgetInterface,
 				},
 			)
 			if !ok {
@@ -5,13 +5,19 @@ import (
 
 	"github.com/determined-ai/determined/master/pkg/actor"
 
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	typedV1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	policyV1beta1Client "k8s.io/client-go/kubernetes/typed/policy/v1beta1"
 )
 
 type requestProcessingWorker struct {
 	podInterface       typedV1.PodInterface
 	configMapInterface typedV1.ConfigMapInterface
+	podGroupInterface  dynamic.ResourceInterface
+
+	disruptionBudgetInterface policyV1beta1Client.PodDisruptionBudgetInterface
 }
 
 func (r *requestProcessingWorker) Receive(ctx *actor.Context) error {
@@ -52,6 +58,46 @@ func (r *requestProcessingWorker) receiveCreateKubernetesResources(
 	ctx.Log().WithField("handler", msg.handler.Address()).Infof(
 		"created configMap %s", configMap.Name)
 
+	if msg.podGroupSpec != nil {
+		// The pod group is owned by the configMap so that Kubernetes garbage collects it once the
+		// first pod of the allocation is cleaned up.
+		msg.podGroupSpec.SetOwnerReferences([]metaV1.OwnerReference{{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Name:       configMap.Name,
+			UID:        configMap.UID,
+		}})
+		_, err = r.podGroupInterface.Create(context.TODO(), msg.podGroupSpec, metaV1.CreateOptions{})
+		if err != nil && !k8sErrors.IsAlreadyExists(err) {
+			ctx.Log().WithField("handler", msg.handler.Address()).WithError(err).Errorf(
+				"error creating pod group %s", msg.podGroupSpec.GetName())
+			ctx.Tell(msg.handler, resourceCreationFailed{err: err})
+			return
+		}
+		ctx.Log().WithField("handler", msg.handler.Address()).Infof(
+			"created pod group %s", msg.podGroupSpec.GetName())
+	}
+
+	if msg.disruptionBudgetSpec != nil {
+		// Like the pod group, the budget is garbage collected along with the first pod. Tasks
+		// still run without it, only less gracefully when nodes are drained.
+		msg.disruptionBudgetSpec.SetOwnerReferences([]metaV1.OwnerReference{{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Name:       configMap.Name,
+			UID:        configMap.UID,
+		}})
+		_, err = r.disruptionBudgetInterface.Create(
+			context.TODO(), msg.disruptionBudgetSpec, metaV1.CreateOptions{})
+		if err != nil && !k8sErrors.IsAlreadyExists(err) {
+			ctx.Log().WithField("handler", msg.handler.Address()).WithError(err).Warnf(
+				"error creating pod disruption budget %s", msg.disruptionBudgetSpec.Name)
+		} else if err == nil {
+			ctx.Log().WithField("handler", msg.handler.Address()).Infof(
+				"created pod disruption budget %s", msg.disruptionBudgetSpec.Name)
+		}
+	}
+
 	ctx.Log().Debugf("launching pod with spec %v", msg.podSpec)
 	pod, err := r.podInterface.Create(context.TODO(), msg.podSpec, metaV1.CreateOptions{})
 	if err != nil {
@@ -0,0 +1,79 @@
+package kubernetes
+
+import (
+	k8sV1 "k8s.io/api/core/v1"
+
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/device"
+)
+
+// acceleratorResourceNames are the extended resources advertised by the device plugins of common
+// accelerators, in the order they are preferred in when nodes advertise as many of several.
+var acceleratorResourceNames = []k8sV1.ResourceName{
+	"nvidia.com/gpu",
+	"amd.com/gpu",
+	"gpu.intel.com/i915",
+	"habana.ai/gaudi",
+	"aws.amazon.com/neuroncore",
+}
+
+// MaxSlotsPerNode asks for the largest number of slots that a single node offers.
+type MaxSlotsPerNode struct{}
+
+// discoverSlotResourceName returns the accelerator resource that nodes have the most of, so that
+// slots come from whichever device plugin the cluster runs. It falls back to NVIDIA GPUs when no
+// node advertises any accelerator yet.
+func discoverSlotResourceName(nodes map[string]*k8sV1.Node) k8sV1.ResourceName {
+	totals := make(map[k8sV1.ResourceName]int64, len(acceleratorResourceNames))
+	for _, node := range nodes {
+		for _, name := range acceleratorResourceNames {
+			if quantity, ok := node.Status.Allocatable[name]; ok {
+				totals[name] += quantity.Value()
+			}
+		}
+	}
+
+	best := acceleratorResourceNames[0]
+	for _, name := range acceleratorResourceNames {
+		if totals[name] > totals[best] {
+			best = name
+		}
+	}
+	return best
+}
+
+// updateSlotResourceName rediscovers the resource that slots come from, unless it is configured.
+func (p *pods) updateSlotResourceName() {
+	if p.configuredSlotResourceName != "" {
+		p.slotResourceName = p.configuredSlotResourceName
+		return
+	}
+	p.slotResourceName = discoverSlotResourceName(p.currentNodes)
+}
+
+// nodeSlots returns the number of slots that a node offers, and their device type.
+func (p *pods) nodeSlots(node *k8sV1.Node) (int64, device.Type) {
+	switch p.slotType {
+	case device.CPU:
+		resources := node.Status.Allocatable["cpu"]
+		milliCPUs := resources.MilliValue() - p.nodeToSystemResourceRequests[node.Name]
+		return int64(float32(milliCPUs) / (1000. * p.slotResourceRequests.CPU)), device.CPU
+	case device.ROCM:
+		panic("ROCm is not supported on k8s yet")
+	case device.CUDA:
+		fallthrough
+	default:
+		resources := node.Status.Allocatable[p.slotResourceName]
+		return resources.Value(), device.CUDA
+	}
+}
+
+func (p *pods) receiveMaxSlotsPerNode(ctx *actor.Context) {
+	maxSlots := int64(0)
+	for _, node := range p.currentNodes {
+		if slots, _ := p.nodeSlots(node); slots > maxSlots {
+			maxSlots = slots
+		}
+	}
+	ctx.Respond(int(maxSlots))
+}
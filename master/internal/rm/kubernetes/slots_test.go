@@ -0,0 +1,57 @@
+//nolint:exhaustivestruct
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	k8sV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/determined-ai/determined/master/pkg/device"
+)
+
+func node(name string, allocatable map[k8sV1.ResourceName]int64) *k8sV1.Node {
+	n := &k8sV1.Node{
+		ObjectMeta: metaV1.ObjectMeta{Name: name},
+		Status:     k8sV1.NodeStatus{Allocatable: k8sV1.ResourceList{}},
+	}
+	for resourceName, value := range allocatable {
+		n.Status.Allocatable[resourceName] = *resource.NewQuantity(value, resource.DecimalSI)
+	}
+	return n
+}
+
+func TestDiscoverSlotResourceName(t *testing.T) {
+	require.Equal(t, k8sV1.ResourceName("nvidia.com/gpu"),
+		discoverSlotResourceName(map[string]*k8sV1.Node{}))
+
+	nodes := map[string]*k8sV1.Node{
+		"cpu":  node("cpu", map[k8sV1.ResourceName]int64{"cpu": 64}),
+		"nv":   node("nv", map[k8sV1.ResourceName]int64{"nvidia.com/gpu": 2}),
+		"amd1": node("amd1", map[k8sV1.ResourceName]int64{"amd.com/gpu": 4}),
+		"amd2": node("amd2", map[k8sV1.ResourceName]int64{"amd.com/gpu": 8}),
+	}
+	require.Equal(t, k8sV1.ResourceName("amd.com/gpu"), discoverSlotResourceName(nodes))
+}
+
+func TestNodeSlots(t *testing.T) {
+	p := pods{
+		slotType:     device.CUDA,
+		currentNodes: map[string]*k8sV1.Node{},
+	}
+	p.currentNodes["a"] = node("a", map[k8sV1.ResourceName]int64{"habana.ai/gaudi": 8})
+	p.currentNodes["b"] = node("b", map[k8sV1.ResourceName]int64{"habana.ai/gaudi": 4})
+	p.updateSlotResourceName()
+
+	slots, deviceType := p.nodeSlots(p.currentNodes["b"])
+	require.Equal(t, int64(4), slots)
+	require.Equal(t, device.CUDA, deviceType)
+
+	// A configured resource takes precedence over the discovered one.
+	p.configuredSlotResourceName = "example.com/fpga"
+	p.updateSlotResourceName()
+	slots, _ = p.nodeSlots(p.currentNodes["a"])
+	require.Equal(t, int64(0), slots)
+}
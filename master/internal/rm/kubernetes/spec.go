@@ -39,6 +39,21 @@ const (
 	cmdTask             = "cmd"
 
 	rootUserName = "root"
+
+	// lifecycleWrapperScript runs the task in the background so that it can forward termination
+	// signals to it, then records its exit code for the sidecars once it is done.
+	lifecycleWrapperScript = `"$@" &
+pid=$!
+trap 'kill -TERM "$pid" 2>/dev/null' TERM INT
+wait "$pid"
+code=$?
+while kill -0 "$pid" 2>/dev/null; do
+	wait "$pid"
+	code=$?
+done
+echo "$code" > "` + lifecycleDir + `/exit-code.tmp"
+mv "` + lifecycleDir + `/exit-code.tmp" "` + lifecycleDir + `/exit-code"
+exit "$code"`
 )
 
 func (p *pod) configureResourcesRequirements() k8sV1.ResourceRequirements {
@@ -58,12 +73,16 @@ func (p *pod) configureResourcesRequirements() k8sV1.ResourceRequirements {
 	case device.CUDA: // default to CUDA-backed slots.
 		fallthrough
 	default:
+		resourceName := p.slotResourceName
+		if resourceName == "" {
+			resourceName = acceleratorResourceNames[0]
+		}
 		return k8sV1.ResourceRequirements{
 			Limits: map[k8sV1.ResourceName]resource.Quantity{
-				"nvidia.com/gpu": *resource.NewQuantity(int64(p.slots), resource.DecimalSI),
+				resourceName: *resource.NewQuantity(int64(p.slots), resource.DecimalSI),
 			},
 			Requests: map[k8sV1.ResourceName]resource.Quantity{
-				"nvidia.com/gpu": *resource.NewQuantity(int64(p.slots), resource.DecimalSI),
+				resourceName: *resource.NewQuantity(int64(p.slots), resource.DecimalSI),
 			},
 		}
 	}
@@ -273,6 +292,21 @@ func (p *pod) configureCoscheduler(newPod *k8sV1.Pod, scheduler string) {
 	}
 }
 
+// configureNodeSelector restricts the pod to nodes matching the task's label constraints, on top
+// of any node selector from the pod spec. The constraints win on conflicting keys.
+func (p *pod) configureNodeSelector(newPod *k8sV1.Pod) {
+	constraints := p.taskSpec.ResourcesConfig.LabelConstraints()
+	if len(constraints) == 0 {
+		return
+	}
+	if newPod.Spec.NodeSelector == nil {
+		newPod.Spec.NodeSelector = make(map[string]string, len(constraints))
+	}
+	for k, v := range constraints {
+		newPod.Spec.NodeSelector[k] = v
+	}
+}
+
 func (p *pod) createPriorityClass(name string, priority int32) error {
 	preemptionPolicy := k8sV1.PreemptNever
 
@@ -295,7 +329,7 @@ func (p *pod) createPriorityClass(name string, priority int32) error {
 func (p *pod) configurePodSpec(
 	ctx *actor.Context,
 	volumes []k8sV1.Volume,
-	determinedInitContainers k8sV1.Container,
+	initContainers []k8sV1.Container,
 	determinedContainer k8sV1.Container,
 	sidecarContainers []k8sV1.Container,
 	podSpec *k8sV1.Pod,
@@ -315,6 +349,8 @@ func (p *pod) configurePodSpec(
 	podSpec.ObjectMeta.Labels[determinedLabel] = p.taskSpec.AllocationID
 
 	p.modifyPodSpec(podSpec, scheduler)
+	p.configureQueueIntegration(podSpec)
+	p.configureNodeSelector(podSpec)
 
 	nonDeterminedContainers := make([]k8sV1.Container, 0)
 	for idx, container := range podSpec.Spec.Containers {
@@ -350,7 +386,7 @@ func (p *pod) configurePodSpec(
 	podSpec.Spec.Containers = append(podSpec.Spec.Containers, determinedContainer)
 	podSpec.Spec.Volumes = append(podSpec.Spec.Volumes, volumes...)
 	podSpec.Spec.HostNetwork = p.taskSpec.TaskContainerDefaults.NetworkMode.IsHost()
-	podSpec.Spec.InitContainers = append(podSpec.Spec.InitContainers, determinedInitContainers)
+	podSpec.Spec.InitContainers = append(podSpec.Spec.InitContainers, initContainers...)
 	podSpec.Spec.RestartPolicy = k8sV1.RestartPolicyNever
 
 	return podSpec
@@ -377,6 +413,9 @@ func (p *pod) createPodSpec(ctx *actor.Context, scheduler string) error {
 	for _, port := range env.Ports() {
 		p.ports = append(p.ports, port)
 	}
+	for _, port := range env.ProxyPorts() {
+		p.ports = append(p.ports, port)
+	}
 
 	envVars, err := p.configureEnvVars(spec.EnvVars(), env, deviceType)
 	if err != nil {
@@ -501,11 +540,47 @@ func (p *pod) createPodSpec(ctx *actor.Context, scheduler string) error {
 	volumes = append(volumes, rootVolumes...)
 	container.VolumeMounts = append(container.VolumeMounts, rootVolumeMounts...)
 
+	userSidecars, lifecycleVolumes := configureSidecars(env.Sidecars(), &container)
+	sidecars = append(sidecars, userSidecars...)
+	volumes = append(volumes, lifecycleVolumes...)
+
+	// Configured init containers run before the one that unpacks the files of the task, which
+	// always runs last.
+	initContainers := append(env.InitContainers().Copy().(expconf.Containers), initContainer)
+
 	p.pod = p.configurePodSpec(
-		ctx, volumes, initContainer, container, sidecars, (*k8sV1.Pod)(env.PodSpec()), scheduler)
+		ctx, volumes, initContainers, container, sidecars, (*k8sV1.Pod)(env.PodSpec()), scheduler)
 	return nil
 }
 
+// configureSidecars returns the configured sidecars of a task, set up so that they can follow the
+// lifecycle of the task container: the task container is wrapped to write its exit code to a
+// volume shared with the sidecars, which find it through DET_LIFECYCLE_DIR and should exit once
+// the file exists. Sidecars never hold up the task either way, since only the Determined
+// containers decide when the pod is done.
+func configureSidecars(
+	sidecars expconf.Containers, container *k8sV1.Container,
+) ([]k8sV1.Container, []k8sV1.Volume) {
+	if len(sidecars) == 0 {
+		return nil, nil
+	}
+
+	lifecycleMount, lifecycleVolume := configureLifecycleVolume()
+	container.VolumeMounts = append(container.VolumeMounts, lifecycleMount)
+	container.Command = append([]string{
+		"/bin/sh", "-c", lifecycleWrapperScript, "det-task",
+	}, container.Command...)
+
+	out := make([]k8sV1.Container, 0, len(sidecars))
+	for i := range sidecars {
+		sidecar := sidecars[i].DeepCopy()
+		sidecar.VolumeMounts = append(sidecar.VolumeMounts, lifecycleMount)
+		sidecar.Env = append(sidecar.Env, k8sV1.EnvVar{Name: "DET_LIFECYCLE_DIR", Value: lifecycleDir})
+		out = append(out, *sidecar)
+	}
+	return out, []k8sV1.Volume{lifecycleVolume}
+}
+
 func configureUniqueName(t tasks.TaskSpec, rank int) string {
 	return fmt.Sprintf("%s-%d-%s-%s",
 		t.Description, rank, t.AllocationID, petName.Generate(2, "-"))
@@ -554,7 +629,7 @@ func configureInitContainer(
 	agentUserGroup *model.AgentUserGroup,
 ) k8sV1.Container {
 	return k8sV1.Container{
-		Name:    "determined-init-container",
+		Name:    model.DeterminedK8InitContainerName,
 		Command: []string{path.Join(initContainerWorkDir, etc.K8InitContainerEntryScriptResource)},
 		Args: []string{
 			fmt.Sprintf("%d", numArchives), initContainerTarSrcPath, initContainerTarDstPath,
@@ -30,3 +30,75 @@ func TestLaterEnvironmentVariablesGetSet(t *testing.T) {
 	require.NotContains(t, actual, dontBe, "earlier variable set")
 	require.Contains(t, actual, shouldBe, "later variable not set")
 }
+
+func TestLabelConstraintsSetNodeSelector(t *testing.T) {
+	p := pod{}
+	p.taskSpec.ResourcesConfig = expconf.ResourcesConfig{
+		RawLabelConstraints: map[string]string{"gpu": "a100", "rack": "r1"},
+	}
+	newPod := &k8sV1.Pod{Spec: k8sV1.PodSpec{
+		NodeSelector: map[string]string{"gpu": "v100", "zone": "us-west"},
+	}}
+
+	p.configureNodeSelector(newPod)
+	require.Equal(t, map[string]string{
+		"gpu":  "a100",
+		"rack": "r1",
+		"zone": "us-west",
+	}, newPod.Spec.NodeSelector)
+}
+
+func TestQueueIntegrationLabelsPodGroup(t *testing.T) {
+	p := pod{numPods: 2, namespace: "default"}
+	p.taskSpec.AllocationID = "1.abc.1"
+
+	p.queueIntegration = &QueueIntegrationConfig{Type: QueueIntegrationKueue, Queue: "team-a"}
+	newPod := &k8sV1.Pod{}
+	newPod.ObjectMeta.Labels = map[string]string{}
+	p.configureQueueIntegration(newPod)
+	require.Equal(t, "team-a", newPod.ObjectMeta.Labels[kueueQueueNameLabel])
+	require.Equal(t, "det-1.abc.1", newPod.ObjectMeta.Labels[kueuePodGroupNameLabel])
+	require.Equal(t, "2", newPod.ObjectMeta.Annotations[kueuePodGroupTotalCountAnnotation])
+
+	p.queueIntegration = &QueueIntegrationConfig{Type: QueueIntegrationVolcano, Queue: "default"}
+	newPod = &k8sV1.Pod{}
+	newPod.ObjectMeta.Labels = map[string]string{}
+	newPod.ObjectMeta.Annotations = map[string]string{volcanoQueueNameAnnotation: "team-b"}
+	p.configureQueueIntegration(newPod)
+	require.Equal(t, volcanoSchedulerName, newPod.Spec.SchedulerName)
+	require.Equal(t, "det-1.abc.1", newPod.ObjectMeta.Annotations[volcanoGroupNameAnnotation])
+
+	p.pod = newPod
+	podGroup := p.volcanoPodGroupSpec()
+	require.NotNil(t, podGroup)
+	require.Equal(t, "det-1.abc.1", podGroup.GetName())
+	require.Equal(t, map[string]interface{}{
+		"minMember": int64(2),
+		"queue":     "team-b",
+	}, podGroup.Object["spec"])
+
+	p.rank = 1
+	require.Nil(t, p.volcanoPodGroupSpec())
+}
+
+func TestConfigureSidecars(t *testing.T) {
+	container := k8sV1.Container{Command: []string{"/run/determined/train/entrypoint.sh"}}
+	sidecars, volumes := configureSidecars(nil, &container)
+	require.Empty(t, sidecars)
+	require.Empty(t, volumes)
+	require.Equal(t, []string{"/run/determined/train/entrypoint.sh"}, container.Command)
+
+	configured := expconf.Containers{{Name: "logs", Image: "logs:1"}}
+	sidecars, volumes = configureSidecars(configured, &container)
+	require.Len(t, volumes, 1)
+	require.Len(t, sidecars, 1)
+	require.Equal(t, "logs", sidecars[0].Name)
+	require.Equal(t, lifecycleDir, sidecars[0].VolumeMounts[0].MountPath)
+	require.Equal(t, k8sV1.EnvVar{Name: "DET_LIFECYCLE_DIR", Value: lifecycleDir}, sidecars[0].Env[0])
+	require.Empty(t, configured[0].VolumeMounts, "configured sidecar modified")
+
+	require.Equal(t, lifecycleDir, container.VolumeMounts[0].MountPath)
+	require.Equal(t, []string{
+		"/bin/sh", "-c", lifecycleWrapperScript, "det-task", "/run/determined/train/entrypoint.sh",
+	}, container.Command)
+}
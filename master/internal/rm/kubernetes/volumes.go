@@ -77,6 +77,19 @@ func configureShmVolume(_ int64) (k8sV1.VolumeMount, k8sV1.Volume) {
 	return volumeMount, volume
 }
 
+func configureLifecycleVolume() (k8sV1.VolumeMount, k8sV1.Volume) {
+	volumeName := "det-lifecycle"
+	volumeMount := k8sV1.VolumeMount{
+		Name:      volumeName,
+		MountPath: lifecycleDir,
+	}
+	volume := k8sV1.Volume{
+		Name:         volumeName,
+		VolumeSource: k8sV1.VolumeSource{EmptyDir: &k8sV1.EmptyDirVolumeSource{}},
+	}
+	return volumeMount, volume
+}
+
 func configureAdditionalFilesVolumes(
 	configMapName string,
 	runArchives []cproto.RunArchive,
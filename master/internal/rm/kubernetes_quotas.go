@@ -0,0 +1,161 @@
+package rm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/rm/kubernetes"
+	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+)
+
+// The reasons for tasks to wait for quotas.
+const (
+	namespaceQuotaExceeded = "namespace-exceeded"
+	namespaceQuotaFull     = "namespace-full"
+	workspaceQuotaExceeded = "workspace-exceeded"
+	workspaceQuotaFull     = "workspace-full"
+)
+
+// k8sQuotas tracks the slots that the resource quotas of the namespace and the workspace quotas
+// leave free during a scheduling pass.
+type k8sQuotas struct {
+	namespace     *kubernetes.QuotaSlotsInfo
+	namespaceFree int
+
+	workspaceNames map[int]string
+	workspaceLimit map[string]int
+	workspaceUsed  map[string]int
+}
+
+func (k *kubernetesResourceManager) quotas(ctx *actor.Context) *k8sQuotas {
+	q := &k8sQuotas{
+		workspaceNames: k.workspaceNames,
+		workspaceLimit: k.config.WorkspaceQuotas,
+		workspaceUsed:  make(map[string]int),
+	}
+
+	resp := ctx.Ask(k.podsActor, kubernetes.QuotaSlots{})
+	if err := resp.Error(); err != nil {
+		ctx.Log().WithError(err).Warn("failed to get the resource quotas of the namespace")
+	} else if info, ok := resp.Get().(*kubernetes.QuotaSlotsInfo); ok && info != nil {
+		q.namespace = info
+		// Pods that were assigned but are not created yet don't count towards the quota yet.
+		used := 0
+		for _, slots := range k.slotsUsedPerGroup {
+			used += slots
+		}
+		if info.Used > used {
+			used = info.Used
+		}
+		q.namespaceFree = info.Limit - used
+	}
+
+	for it := k.reqList.iterator(); it.next(); {
+		req := it.value()
+		if assignmentIsScheduled(k.reqList.GetAllocations(req.AllocationRef)) {
+			if name, ok := q.workspace(req); ok {
+				q.workspaceUsed[name] += req.SlotsNeeded
+			}
+		}
+	}
+	return q
+}
+
+func (q *k8sQuotas) workspace(req *sproto.AllocateRequest) (string, bool) {
+	if req.WorkspaceID == nil {
+		return "", false
+	}
+	name, ok := q.workspaceNames[*req.WorkspaceID]
+	if !ok {
+		return "", false
+	}
+	_, ok = q.workspaceLimit[name]
+	return name, ok
+}
+
+// hold returns why the request must wait for a quota, with a key that only changes when the
+// reason does, or an empty key if the request fits.
+func (q *k8sQuotas) hold(req *sproto.AllocateRequest) (key, reason string) {
+	if req.SlotsNeeded == 0 {
+		return "", ""
+	}
+
+	if q.namespace != nil {
+		switch {
+		case req.SlotsNeeded > q.namespace.Limit:
+			return namespaceQuotaExceeded, fmt.Sprintf(
+				"task needs %d slots, but resource quota %s of the namespace only allows %d; "+
+					"it will not start unless the quota is raised",
+				req.SlotsNeeded, q.namespace.Name, q.namespace.Limit)
+		case req.SlotsNeeded > q.namespaceFree:
+			return namespaceQuotaFull, fmt.Sprintf(
+				"waiting for resource quota %s of the namespace, which has %d of %d slots free",
+				q.namespace.Name, q.namespaceFree, q.namespace.Limit)
+		}
+	}
+
+	if name, ok := q.workspace(req); ok {
+		limit := q.workspaceLimit[name]
+		switch {
+		case req.SlotsNeeded > limit:
+			return workspaceQuotaExceeded, fmt.Sprintf(
+				"task needs %d slots, but the quota of workspace %s is %d slots; "+
+					"it will not start unless the quota is raised", req.SlotsNeeded, name, limit)
+		case q.workspaceUsed[name]+req.SlotsNeeded > limit:
+			return workspaceQuotaFull, fmt.Sprintf(
+				"waiting for the quota of workspace %s, which has %d of %d slots in use",
+				name, q.workspaceUsed[name], limit)
+		}
+	}
+
+	return "", ""
+}
+
+// take counts the slots of a request that was assigned resources against the quotas.
+func (q *k8sQuotas) take(req *sproto.AllocateRequest) {
+	q.namespaceFree -= req.SlotsNeeded
+	if name, ok := q.workspace(req); ok {
+		q.workspaceUsed[name] += req.SlotsNeeded
+	}
+}
+
+// warnQuotaHold tells a task why it is waiting for a quota, once for each reason it waits for.
+func (k *kubernetesResourceManager) warnQuotaHold(
+	ctx *actor.Context, req *sproto.AllocateRequest, key, reason string,
+) {
+	if k.quotaHolds[req.AllocationID] == key {
+		return
+	}
+	k.quotaHolds[req.AllocationID] = key
+
+	ctx.Log().WithFields(req.LogContext.Fields()).
+		WithField("allocation-id", req.AllocationID).Info(reason)
+	ctx.Tell(req.AllocationRef, sproto.ContainerLog{
+		Timestamp:  time.Now().UTC(),
+		AuxMessage: &reason,
+		Level:      ptrs.Ptr(model.LogLevelWarning),
+	})
+}
+
+// resolveWorkspaceName looks up the name of the workspace of a request, which workspace quotas
+// refer to. It is looked up again for every request, so that renamed workspaces are picked up.
+func (k *kubernetesResourceManager) resolveWorkspaceName(
+	ctx *actor.Context, req sproto.AllocateRequest,
+) {
+	if len(k.config.WorkspaceQuotas) == 0 || req.WorkspaceID == nil {
+		return
+	}
+
+	var name string
+	if err := db.Bun().NewSelect().Table("workspaces").Column("name").
+		Where("id = ?", *req.WorkspaceID).Scan(context.TODO(), &name); err != nil {
+		ctx.Log().WithError(err).Warnf("failed to look up workspace %d", *req.WorkspaceID)
+		return
+	}
+	k.workspaceNames[*req.WorkspaceID] = name
+}
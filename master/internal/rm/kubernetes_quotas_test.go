@@ -0,0 +1,43 @@
+package rm
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/determined-ai/determined/master/internal/rm/kubernetes"
+	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+)
+
+func TestK8sQuotasHold(t *testing.T) {
+	q := &k8sQuotas{
+		namespace:      &kubernetes.QuotaSlotsInfo{Name: "gpus", Limit: 8, Used: 2},
+		namespaceFree:  6,
+		workspaceNames: map[int]string{1: "vision", 2: "nlp"},
+		workspaceLimit: map[string]int{"vision": 4},
+		workspaceUsed:  map[string]int{"vision": 2},
+	}
+
+	key, _ := q.hold(&sproto.AllocateRequest{SlotsNeeded: 0})
+	assert.Equal(t, key, "")
+	key, _ = q.hold(&sproto.AllocateRequest{SlotsNeeded: 16})
+	assert.Equal(t, key, namespaceQuotaExceeded)
+	key, _ = q.hold(&sproto.AllocateRequest{SlotsNeeded: 7})
+	assert.Equal(t, key, namespaceQuotaFull)
+
+	vision := &sproto.AllocateRequest{SlotsNeeded: 2, WorkspaceID: ptrs.Ptr(1)}
+	key, _ = q.hold(vision)
+	assert.Equal(t, key, "")
+	q.take(vision)
+	assert.Equal(t, q.namespaceFree, 4)
+
+	key, _ = q.hold(vision)
+	assert.Equal(t, key, workspaceQuotaFull)
+	key, _ = q.hold(&sproto.AllocateRequest{SlotsNeeded: 5, WorkspaceID: ptrs.Ptr(1)})
+	assert.Equal(t, key, workspaceQuotaExceeded)
+
+	// Workspaces without a quota are only limited by the namespace.
+	key, _ = q.hold(&sproto.AllocateRequest{SlotsNeeded: 4, WorkspaceID: ptrs.Ptr(2)})
+	assert.Equal(t, key, "")
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/determined-ai/determined/master/internal/config"
 	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/maintenance"
 	"github.com/determined-ai/determined/master/internal/rm/kubernetes"
 	"github.com/determined-ai/determined/master/internal/sproto"
 	"github.com/determined-ai/determined/master/pkg/actor"
@@ -63,6 +65,7 @@ func NewKubernetesResourceManager(
 			echo,
 			tlsConfig,
 			opts.LoggingOptions,
+			time.Duration(opts.SystemMetricsPeriod),
 		),
 	)
 	system.Ask(ref, actor.Ping{}).Get()
@@ -138,6 +141,10 @@ type kubernetesResourceManager struct {
 	groupActorToID    map[*actor.Ref]model.JobID
 	IDToGroupActor    map[model.JobID]*actor.Ref
 	slotsUsedPerGroup map[*group]int
+	// quotaHolds holds the last reason each waiting allocation was told it waits for a quota, and
+	// workspaceNames the names of the workspaces of tasks, which workspace quotas refer to.
+	quotaHolds     map[model.AllocationID]string
+	workspaceNames map[int]string
 
 	podsActor *actor.Ref
 
@@ -147,6 +154,8 @@ type kubernetesResourceManager struct {
 	echoRef         *echo.Echo
 	masterTLSConfig model.TLSClientConfig
 	loggingConfig   model.LoggingConfig
+	// systemMetricsPeriod is how often the resource usage of pods is measured; 0 means never.
+	systemMetricsPeriod time.Duration
 }
 
 func newKubernetesResourceManager(
@@ -154,6 +163,7 @@ func newKubernetesResourceManager(
 	echoRef *echo.Echo,
 	masterTLSConfig model.TLSClientConfig,
 	loggingConfig model.LoggingConfig,
+	systemMetricsPeriod time.Duration,
 ) actor.Actor {
 	return &kubernetesResourceManager{
 		config: config,
@@ -167,11 +177,14 @@ func newKubernetesResourceManager(
 		groupActorToID:    make(map[*actor.Ref]model.JobID),
 		IDToGroupActor:    make(map[model.JobID]*actor.Ref),
 		slotsUsedPerGroup: make(map[*group]int),
+		quotaHolds:        make(map[model.AllocationID]string),
+		workspaceNames:    make(map[int]string),
 		queuePositions:    initalizeJobSortState(true),
 
-		echoRef:         echoRef,
-		masterTLSConfig: masterTLSConfig,
-		loggingConfig:   loggingConfig,
+		echoRef:             echoRef,
+		masterTLSConfig:     masterTLSConfig,
+		loggingConfig:       loggingConfig,
+		systemMetricsPeriod: systemMetricsPeriod,
 	}
 }
 
@@ -199,10 +212,13 @@ func (k *kubernetesResourceManager) Receive(ctx *actor.Context) error {
 			k.config.DefaultScheduler,
 			k.config.SlotType,
 			kubernetes.PodSlotResourceRequests{CPU: k.config.SlotResourceRequests.CPU},
+			k.config.SlotResourceName,
 			k.config.Fluent,
+			k.config.QueueIntegration,
 			k.config.CredsDir,
 			k.config.MasterIP,
 			k.config.MasterPort,
+			k.systemMetricsPeriod,
 		)
 
 	case
@@ -251,14 +267,22 @@ func (k *kubernetesResourceManager) Receive(ctx *actor.Context) error {
 		ctx.Respond(resp)
 
 	case sproto.ValidateCommandResourcesRequest:
-		fulfillable := k.config.MaxSlotsPerPod >= msg.Slots
+		fulfillable := k.maxSlotsPerPod(ctx) >= msg.Slots
 		ctx.Respond(sproto.ValidateCommandResourcesResponse{Fulfillable: fulfillable})
 
+	case sproto.ScheduleDryRunRequest:
+		reschedule = false
+		ctx.Respond(k.scheduleDryRun(ctx, msg))
+
 	case schedulerTick:
-		if k.reschedule {
+		// In maintenance mode, the pass is put off rather than skipped, so that work submitted in
+		// the meantime is scheduled once it ends.
+		if k.reschedule && !maintenance.Enabled() {
+			start := time.Now()
 			k.schedulePendingTasks(ctx)
+			observeSchedulerPass(KubernetesDummyResourcePool, k.reqList, start)
+			k.reschedule = false
 		}
-		k.reschedule = false
 		reschedule = false
 		actors.NotifyAfter(ctx, actionCoolDown, schedulerTick{})
 	case *apiv1.GetAgentsRequest:
@@ -304,7 +328,7 @@ func (k *kubernetesResourceManager) summarizeDummyResourcePool(
 		Preemptible:                  k.config.GetPreemption(),
 		MinAgents:                    0,
 		MaxAgents:                    0,
-		SlotsPerAgent:                int32(k.config.MaxSlotsPerPod),
+		SlotsPerAgent:                int32(k.maxSlotsPerPod(ctx)),
 		AuxContainerCapacityPerAgent: int32(1),
 		SchedulerType:                resourcepoolv1.SchedulerType_SCHEDULER_TYPE_KUBERNETES,
 		SchedulerFittingPolicy:       resourcepoolv1.FittingPolicy_FITTING_POLICY_KUBERNETES,
@@ -315,6 +339,21 @@ func (k *kubernetesResourceManager) summarizeDummyResourcePool(
 	}, nil
 }
 
+// maxSlotsPerPod returns max_slots_per_pod or, if it is not set, the largest number of slots that
+// a node of the cluster offers.
+func (k *kubernetesResourceManager) maxSlotsPerPod(ctx *actor.Context) int {
+	if k.config.MaxSlotsPerPod > 0 {
+		return k.config.MaxSlotsPerPod
+	}
+	resp := ctx.Ask(k.podsActor, kubernetes.MaxSlotsPerNode{})
+	if err := resp.Error(); err != nil {
+		ctx.Log().WithError(err).Warn("failed to get the number of slots per node")
+		return 0
+	}
+	maxSlots, _ := resp.Get().(int)
+	return maxSlots
+}
+
 func (k *kubernetesResourceManager) summarizePods(
 	ctx *actor.Context,
 ) (*kubernetes.PodsInfo, error) {
@@ -406,6 +445,7 @@ func (k *kubernetesResourceManager) addTask(ctx *actor.Context, msg sproto.Alloc
 		k.groupActorToID[msg.Group] = msg.JobID
 		k.IDToGroupActor[msg.JobID] = msg.Group
 	}
+	k.resolveWorkspaceName(ctx, msg)
 	k.reqList.AddTask(&msg)
 }
 
@@ -429,7 +469,14 @@ func (k *kubernetesResourceManager) receiveJobQueueMsg(ctx *actor.Context) error
 		ctx.Respond(jobStats(k.reqList))
 
 	case sproto.MoveJob:
-		err := k.moveJob(ctx, msg.ID, msg.Anchor, msg.Ahead)
+		var err error
+		if k.config.QueueIntegration != nil {
+			// Pods are ordered by the external scheduler, so positions here would have no effect.
+			err = ErrUnsupported(fmt.Sprintf(
+				"the job queue is managed by %s", k.config.QueueIntegration.Type))
+		} else {
+			err = k.moveJob(ctx, msg.ID, msg.Anchor, msg.Ahead)
+		}
 		if ctx.ExpectingResponse() {
 			ctx.Respond(err)
 		}
@@ -579,31 +626,74 @@ func (k *kubernetesResourceManager) receiveSetAllocationName(
 	}
 }
 
+// scheduleDryRun only checks that the request can be split into pods; where and when the pods
+// are placed is up to the Kubernetes scheduler.
+func (k *kubernetesResourceManager) scheduleDryRun(
+	ctx *actor.Context, msg sproto.ScheduleDryRunRequest,
+) sproto.ScheduleDryRunResponse {
+	var resp sproto.ScheduleDryRunResponse
+	for it := k.reqList.iterator(); it.next(); {
+		if k.reqList.GetAllocations(it.value().AllocationRef) == nil {
+			resp.QueuedAhead++
+			resp.SlotsAhead += it.value().SlotsNeeded
+		}
+	}
+
+	maxSlots := k.maxSlotsPerPod(ctx)
+	switch {
+	case msg.SlotsNeeded <= 1:
+		resp.Schedulable = true
+	case maxSlots == 0:
+		resp.Reason = "no node offers slots; set max_slots_per_pod > 0 to schedule tasks with slots"
+	case msg.SlotsNeeded > maxSlots && msg.FittingRequirements.SingleAgent:
+		resp.Reason = fmt.Sprintf("%d slots are needed in a single pod, but max_slots_per_pod is %d",
+			msg.SlotsNeeded, maxSlots)
+	case msg.SlotsNeeded > maxSlots && msg.SlotsNeeded%maxSlots != 0:
+		resp.Reason = fmt.Sprintf("%d slots are not a multiple of max_slots_per_pod (%d)",
+			msg.SlotsNeeded, maxSlots)
+	default:
+		resp.Schedulable = true
+	}
+	if resp.Schedulable {
+		req := &sproto.AllocateRequest{SlotsNeeded: msg.SlotsNeeded}
+		if key, reason := k.quotas(ctx).hold(req); key == namespaceQuotaExceeded {
+			resp.Schedulable = false
+			resp.Reason = reason
+		}
+	}
+	if resp.Schedulable {
+		resp.Reason = "pod placement is decided by the Kubernetes scheduler"
+	}
+	return resp
+}
+
 func (k *kubernetesResourceManager) assignResources(
 	ctx *actor.Context, req *sproto.AllocateRequest,
 ) {
+	log := ctx.Log().WithFields(req.LogContext.Fields())
 	numPods := 1
 	slotsPerPod := req.SlotsNeeded
 	if req.SlotsNeeded > 1 {
-		if k.config.MaxSlotsPerPod == 0 {
-			ctx.Log().WithField("allocation-id", req.AllocationID).Error(
-				"set max_slots_per_pod > 0 to schedule tasks with slots")
+		maxSlotsPerPod := k.maxSlotsPerPod(ctx)
+		if maxSlotsPerPod == 0 {
+			log.WithField("allocation-id", req.AllocationID).Error(
+				"no node offers slots; set max_slots_per_pod > 0 to schedule tasks with slots")
 			return
 		}
 
-		if req.SlotsNeeded <= k.config.MaxSlotsPerPod {
+		if req.SlotsNeeded <= maxSlotsPerPod {
 			numPods = 1
 			slotsPerPod = req.SlotsNeeded
 		} else {
-			if req.SlotsNeeded%k.config.MaxSlotsPerPod != 0 {
-				ctx.Log().WithField("allocation-id", req.AllocationID).Errorf(
-					"task number of slots (%d) is not schedulable on the configured "+
-						"max_slots_per_pod (%d)", req.SlotsNeeded, k.config.MaxSlotsPerPod)
+			if req.SlotsNeeded%maxSlotsPerPod != 0 {
+				log.WithField("allocation-id", req.AllocationID).Errorf(
+					"task number of slots (%d) is not schedulable on the "+
+						"max_slots_per_pod (%d)", req.SlotsNeeded, maxSlotsPerPod)
 				return
 			}
 
-			numPods = req.SlotsNeeded / k.config.MaxSlotsPerPod
-			slotsPerPod = k.config.MaxSlotsPerPod
+			numPods = req.SlotsNeeded / maxSlotsPerPod
+			slotsPerPod = maxSlotsPerPod
 		}
 	}
 
@@ -617,6 +707,7 @@ func (k *kubernetesResourceManager) assignResources(
 			podsActor:       k.podsActor,
 			containerID:     containerID,
 			slots:           slotsPerPod,
+			numPods:         numPods,
 			group:           k.groups[req.Group],
 			initialPosition: k.queuePositions[k.addrToJobID[req.AllocationRef]],
 		}
@@ -629,7 +720,7 @@ func (k *kubernetesResourceManager) assignResources(
 	k.reqList.SetAllocationsRaw(req.AllocationRef, &assigned)
 	req.AllocationRef.System().Tell(req.AllocationRef, assigned.Clone())
 
-	ctx.Log().
+	log.
 		WithField("allocation-id", req.AllocationID).
 		WithField("task-handler", req.AllocationRef.Address()).
 		Infof("resources assigned with %d pods", numPods)
@@ -645,7 +736,9 @@ func (k *kubernetesResourceManager) resourcesReleased(
 	}
 
 	ctx.Log().Infof("resources are released for %s", msg.AllocationRef.Address())
-	k.reqList.RemoveTaskByHandler(msg.AllocationRef)
+	if req := k.reqList.RemoveTaskByHandler(msg.AllocationRef); req != nil {
+		delete(k.quotaHolds, req.AllocationID)
+	}
 	delete(k.addrToContainerID, msg.AllocationRef)
 
 	deleteID := ""
@@ -686,6 +779,7 @@ func (k *kubernetesResourceManager) getOrCreateGroup(
 }
 
 func (k *kubernetesResourceManager) schedulePendingTasks(ctx *actor.Context) {
+	quotas := k.quotas(ctx)
 	for it := k.reqList.iterator(); it.next(); {
 		req := it.value()
 		group := k.groups[req.Group]
@@ -697,7 +791,18 @@ func (k *kubernetesResourceManager) schedulePendingTasks(ctx *actor.Context) {
 				}
 			}
 
+			// Pods beyond a resource quota would be rejected by Kubernetes, so tasks wait for
+			// quotas here, where users can see why.
+			if key, reason := quotas.hold(req); key != "" {
+				k.warnQuotaHold(ctx, req, key, reason)
+				continue
+			}
+
 			k.assignResources(ctx, req)
+			if assignmentIsScheduled(k.reqList.GetAllocations(req.AllocationRef)) {
+				quotas.take(req)
+				delete(k.quotaHolds, req.AllocationID)
+			}
 		}
 	}
 }
@@ -708,6 +813,7 @@ type k8sPodResources struct {
 	group           *group
 	containerID     cproto.ID
 	slots           int
+	numPods         int
 	initialPosition decimal.Decimal
 }
 
@@ -749,6 +855,7 @@ func (p k8sPodResources) Start(
 		Spec:       spec,
 		Slots:      p.slots,
 		Rank:       rri.AgentRank,
+		NumPods:    p.numPods,
 		LogContext: logCtx,
 	}).Error()
 }
@@ -0,0 +1,59 @@
+package rm
+
+import (
+	"context"
+	"time"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// poolStatsInterval is how often resource pools record their queue and utilization.
+const poolStatsInterval = time.Minute
+
+// poolStatsPruneInterval is how often stats past their retention are removed.
+const poolStatsPruneInterval = time.Hour
+
+// poolStatsTick periodically triggers a resource pool to record its stats.
+type poolStatsTick struct{}
+
+// poolStatsPruneTick periodically triggers the resource manager to prune the stats of all pools.
+type poolStatsPruneTick struct{}
+
+func (rp *ResourcePool) recordPoolStats(ctx *actor.Context) {
+	summary := getResourceSummary(rp.fetchAgentStates(ctx))
+	stats := model.ResourcePoolStats{
+		ResourcePool: rp.config.PoolName,
+		Time:         time.Now().UTC(),
+		SlotsTotal:   summary.numTotalSlots,
+		SlotsUsed:    summary.numActiveSlots,
+	}
+	stats.QueueLength, stats.QueuedSlots = queueLength(rp.taskList)
+
+	if err := db.RecordResourcePoolStats(context.TODO(), &stats, poolStatsInterval); err != nil {
+		ctx.Log().WithError(err).Warn("failed to record resource pool stats")
+	}
+}
+
+func prunePoolStats(ctx *actor.Context, retention time.Duration) {
+	if n, err := db.PruneResourcePoolStats(context.TODO(), retention); err != nil {
+		ctx.Log().WithError(err).Warn("failed to prune resource pool stats")
+	} else if n > 0 {
+		ctx.Log().Debugf("pruned %d resource pool stats older than %s", n, retention)
+	}
+}
+
+// queueLength returns the number of requests that are still waiting for resources, and the total
+// number of slots they need.
+func queueLength(taskList *taskList) (requests, slots int) {
+	for it := taskList.iterator(); it.next(); {
+		req := it.value()
+		if taskList.GetAllocations(req.AllocationRef) != nil {
+			continue
+		}
+		requests++
+		slots += req.SlotsNeeded
+	}
+	return requests, slots
+}
@@ -0,0 +1,26 @@
+package rm
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/determined-ai/determined/master/pkg/actor"
+)
+
+func TestQueueLength(t *testing.T) {
+	system := actor.NewSystem(t.Name())
+	agents := []*mockAgent{
+		{id: "agent1", slots: 8},
+	}
+	tasks := []*mockTask{
+		{id: "running", slotsNeeded: 6, allocatedAgent: agents[0], containerStarted: true},
+		{id: "pending1", slotsNeeded: 4},
+		{id: "pending2", slotsNeeded: 0},
+	}
+	taskList, _, _ := setupSchedulerStates(t, system, tasks, nil, agents)
+
+	requests, slots := queueLength(taskList)
+	assert.Equal(t, requests, 2)
+	assert.Equal(t, slots, 4)
+}
@@ -3,6 +3,7 @@ package rm
 import (
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
@@ -17,6 +18,8 @@ import (
 
 type priorityScheduler struct {
 	preemptionEnabled bool
+	aging             *config.PriorityAgingConfig
+	backfill          bool
 }
 
 // AllocReqs is an alias for a list of Allocate Requests.
@@ -26,6 +29,8 @@ type AllocReqs = []*sproto.AllocateRequest
 func NewPriorityScheduler(config *config.SchedulerConfig) Scheduler {
 	return &priorityScheduler{
 		preemptionEnabled: config.Priority.Preemption,
+		aging:             config.Priority.Aging,
+		backfill:          config.Priority.Backfill,
 	}
 }
 
@@ -76,6 +81,10 @@ func (p *priorityScheduler) prioritySchedule(
 // 1. Schedule pending tasks without preemption.
 // 2. Search if preempting any lower-priority tasks can make space.
 // 3. Back-fill lower-priority pending tasks if there are no tasks to preempt.
+//
+// Preemptible tasks can always be back-filled when preemption is enabled; other tasks only if
+// runtime-aware backfilling is enabled and they are expected to finish before the blocked tasks
+// could start.
 func (p *priorityScheduler) prioritySchedulerWithFilter(
 	taskList *taskList,
 	groups map[*actor.Ref]*group,
@@ -91,18 +100,32 @@ func (p *priorityScheduler) prioritySchedulerWithFilter(
 	// tasks are scheduled and preempted.
 	//nolint:lll // There isn't a great way to break this line that makes it more readable.
 	priorityToPendingTasksMap, priorityToScheduledTaskMap := sortTasksByPriorityAndPositionAndTimestamp(taskList, groups, jobPositions, filter)
+	now := time.Now()
+	if p.aging != nil {
+		priorityToPendingTasksMap = agePendingTasks(priorityToPendingTasksMap, p.aging, now)
+	}
 
 	localAgentsState := deepCopyAgents(agents)
 
 	// If there exist any tasks that cannot be scheduled, all the tasks of lower priorities
 	// can only be backfilled if they are preemptible.
 	backfilling := false
+	// The earliest time any of the blocked tasks is expected to be able to start, if known.
+	var reservedFrom *time.Time
 
 	for _, priority := range getOrderedPriorities(priorityToPendingTasksMap) {
 		allocationRequests := priorityToPendingTasksMap[priority]
 		log.Debugf("processing priority %d with %d pending tasks (backfilling: %v)",
 			priority, len(allocationRequests), backfilling)
 
+		if backfilling && p.backfill {
+			// Offer the free resources to the tasks that may be back-filled before the rest.
+			sort.SliceStable(allocationRequests, func(i, j int) bool {
+				return p.canBackfill(allocationRequests[i], now, reservedFrom) &&
+					!p.canBackfill(allocationRequests[j], now, reservedFrom)
+			})
+		}
+
 		successfulAllocations, unSuccessfulAllocations := trySchedulingPendingTasksInPriority(
 			allocationRequests, localAgentsState, fittingMethod)
 
@@ -113,9 +136,9 @@ func (p *priorityScheduler) prioritySchedulerWithFilter(
 					log.Debugf("scheduled task: %s", allocatedTask.Name)
 					toAllocate = append(toAllocate, allocatedTask)
 				}
-			} else if p.preemptionEnabled {
+			} else {
 				for _, allocatedTask := range successfulAllocations {
-					if !allocatedTask.Preemptible {
+					if !p.canBackfill(allocatedTask, now, reservedFrom) {
 						continue
 					}
 					log.Debugf("scheduled task via backfilling: %s", allocatedTask.Name)
@@ -127,8 +150,12 @@ func (p *priorityScheduler) prioritySchedulerWithFilter(
 
 		// Scheduling the tasks of lower priority than the current one is considered to
 		// back-filling.
-		if len(unSuccessfulAllocations) > 0 {
+		if len(unSuccessfulAllocations) > 0 && !backfilling {
 			backfilling = true
+			if p.backfill {
+				reservedFrom = earliestReservation(unSuccessfulAllocations, taskList,
+					priorityToScheduledTaskMap, localAgentsState, fittingMethod, now)
+			}
 		}
 
 		if p.preemptionEnabled {
@@ -175,6 +202,73 @@ func (p *priorityScheduler) prioritySchedulerWithFilter(
 	return toAllocate, toReleaseSlice
 }
 
+// earliestReservation estimates the earliest time that any of the blocked tasks could start,
+// assuming that running tasks release their resources once their expected runtime has elapsed.
+// It returns nil if that can't be known because some blocked task would have to wait on a task
+// without an expected runtime, or one that has already overrun it.
+func earliestReservation(
+	blocked []*sproto.AllocateRequest,
+	taskList *taskList,
+	priorityToScheduledTaskMap map[int][]*sproto.AllocateRequest,
+	agents map[*actor.Ref]*AgentState,
+	fittingMethod SoftConstraint,
+	now time.Time,
+) *time.Time {
+	type expectedEnd struct {
+		at        time.Time
+		allocated *sproto.ResourcesAllocated
+	}
+	var ends []expectedEnd
+	for _, scheduled := range priorityToScheduledTaskMap {
+		for _, req := range scheduled {
+			allocated := taskList.GetAllocations(req.AllocationRef)
+			if req.ExpectedRuntime == nil || allocated == nil {
+				continue
+			}
+			if at := allocated.StartTime.Add(*req.ExpectedRuntime); at.After(now) {
+				ends = append(ends, expectedEnd{at: at, allocated: allocated})
+			}
+		}
+	}
+	sort.Slice(ends, func(i, j int) bool { return ends[i].at.Before(ends[j].at) })
+
+	var earliest *time.Time
+	for _, req := range blocked {
+		localAgentsState := deepCopyAgents(agents)
+		var reservation *time.Time
+		for _, end := range ends {
+			removeTaskFromAgents(localAgentsState, end.allocated)
+			if fits := findFits(req, localAgentsState, fittingMethod); len(fits) > 0 {
+				at := end.at
+				reservation = &at
+				break
+			}
+		}
+		if reservation == nil {
+			return nil
+		}
+		if earliest == nil || reservation.Before(*earliest) {
+			earliest = reservation
+		}
+	}
+	return earliest
+}
+
+// canBackfill returns whether the task may start ahead of blocked higher-priority tasks, either
+// because it can be preempted to make way for them or because it is expected to finish by the
+// time they could start anyway.
+func (p *priorityScheduler) canBackfill(
+	req *sproto.AllocateRequest, now time.Time, reservedFrom *time.Time,
+) bool {
+	if p.preemptionEnabled && req.Preemptible {
+		return true
+	}
+	if !p.backfill || req.ExpectedRuntime == nil || reservedFrom == nil {
+		return false
+	}
+	return !now.Add(*req.ExpectedRuntime).After(*reservedFrom)
+}
+
 // trySchedulingTaskViaPreemption checks whether preempting lower priority tasks
 // would allow this task to be scheduled.
 func trySchedulingTaskViaPreemption(
@@ -284,6 +378,42 @@ func sortTasksByPriorityAndPositionAndTimestamp(
 	return priorityToPendingTasksMap, priorityToScheduledTaskMap
 }
 
+// agePendingTasks moves pending tasks to the priority they have aged into. Within each priority,
+// tasks that started there keep their order and come before the tasks that aged into it.
+func agePendingTasks(
+	priorityToPendingTasksMap map[int][]*sproto.AllocateRequest,
+	aging *config.PriorityAgingConfig,
+	now time.Time,
+) map[int][]*sproto.AllocateRequest {
+	aged := make(map[int][]*sproto.AllocateRequest, len(priorityToPendingTasksMap))
+	for _, priority := range getOrderedPriorities(priorityToPendingTasksMap) {
+		for _, req := range priorityToPendingTasksMap[priority] {
+			effective := agedPriority(priority, req.AllocationRef.RegisteredTime(), now, aging)
+			aged[effective] = append(aged[effective], req)
+		}
+	}
+	return aged
+}
+
+// agedPriority returns the priority of a task that has been pending since queuedSince, raised by
+// one for every aging interval it has waited. Aging never raises a task above the highest
+// priority users may set.
+func agedPriority(
+	priority int, queuedSince, now time.Time, aging *config.PriorityAgingConfig,
+) int {
+	boost := int(now.Sub(queuedSince) / time.Duration(aging.Interval))
+	if aging.MaxBoost > 0 && boost > aging.MaxBoost {
+		boost = aging.MaxBoost
+	}
+	if boost <= 0 || priority <= model.MinUserSchedulingPriority {
+		return priority
+	}
+	if priority-boost < model.MinUserSchedulingPriority {
+		return model.MinUserSchedulingPriority
+	}
+	return priority - boost
+}
+
 // comparePositions returns the following:
 // 1 if a is in front of b.
 // 0 if a is equal to b in position.
@@ -8,6 +8,7 @@ import (
 
 	"gotest.tools/assert"
 
+	"github.com/determined-ai/determined/master/internal/config"
 	"github.com/determined-ai/determined/master/internal/sproto"
 	"github.com/determined-ai/determined/master/pkg/actor"
 	"github.com/determined-ai/determined/master/pkg/cproto"
@@ -852,3 +853,83 @@ func RemoveTask(slots int, toRelease *actor.Ref, taskList *taskList, delete bool
 	}
 	return true
 }
+
+func TestPriorityAging(t *testing.T) {
+	lowerPriority := 50
+	higherPriority := 40
+	groups := []*mockGroup{
+		{id: "group1", priority: &lowerPriority},
+		{id: "group2", priority: &higherPriority},
+	}
+	tasks := []*mockTask{
+		{id: "task1", slotsNeeded: 4, group: groups[0]},
+		{id: "task2", slotsNeeded: 4, group: groups[1]},
+	}
+
+	system := actor.NewSystem(t.Name())
+	taskList, mockGroups, _ := setupSchedulerStates(t, system, tasks, groups, nil)
+	pending, _ := sortTasksByPriorityAndPositionAndTimestamp(
+		taskList, mockGroups, make(map[model.JobID]decimal.Decimal), taskFilter("", false))
+	queuedSince := pending[lowerPriority][0].AllocationRef.RegisteredTime()
+
+	aging := &config.PriorityAgingConfig{Interval: model.Duration(time.Minute)}
+	aged := agePendingTasks(pending, aging, queuedSince.Add(5*time.Minute))
+	assert.Equal(t, len(aged[lowerPriority-5]), 1)
+	assert.Equal(t, len(aged[higherPriority-5]), 1)
+
+	aged = agePendingTasks(pending, aging, queuedSince.Add(10*time.Minute))
+	assert.Equal(t, len(aged[higherPriority]), 1)
+	assert.Equal(t, aged[higherPriority][0].AllocationID, model.AllocationID("task1"))
+	assert.Equal(t, aged[higherPriority-10][0].AllocationID, model.AllocationID("task2"))
+
+	assert.Equal(t, agedPriority(50, queuedSince, queuedSince.Add(time.Hour), aging), 1)
+	aging.MaxBoost = 20
+	assert.Equal(t, agedPriority(50, queuedSince, queuedSince.Add(time.Hour), aging), 30)
+	assert.Equal(t, agedPriority(50, queuedSince, queuedSince.Add(30*time.Second), aging), 50)
+}
+
+func TestPrioritySchedulingBackfill(t *testing.T) {
+	lowerPriority := 50
+	higherPriority := 40
+	hour, halfHour, twoHours := time.Hour, 30*time.Minute, 2*time.Hour
+	submitted := time.Now().Add(-time.Minute)
+
+	agents := []*mockAgent{
+		{id: "agent1", slots: 4},
+	}
+	groups := []*mockGroup{
+		{id: "group1", priority: &lowerPriority},
+		{id: "group2", priority: &higherPriority},
+	}
+	tasks := []*mockTask{
+		{
+			id: "task1", slotsNeeded: 2, group: groups[1], expectedRuntime: &hour,
+			allocatedAgent: agents[0], containerStarted: true,
+		},
+		{id: "task2", slotsNeeded: 4, group: groups[1]},
+		{
+			id: "task3", slotsNeeded: 2, group: groups[0], expectedRuntime: &twoHours,
+			jobSubmissionTime: submitted, nonPreemptible: true,
+		},
+		{
+			id: "task4", slotsNeeded: 2, group: groups[0], expectedRuntime: &halfHour,
+			jobSubmissionTime: submitted.Add(time.Second), nonPreemptible: true,
+		},
+	}
+
+	system := actor.NewSystem(t.Name())
+	taskList, groupMap, agentMap := setupSchedulerStates(t, system, tasks, groups, agents)
+
+	// Without backfilling, nothing may start ahead of the blocked task.
+	p := &priorityScheduler{}
+	toAllocate, _ := p.prioritySchedule(taskList, groupMap,
+		make(map[model.JobID]decimal.Decimal), agentMap, BestFit)
+	assertEqualToAllocate(t, toAllocate, []*mockTask{})
+
+	// With it, only the task that will be done by the time task1 finishes may.
+	p = &priorityScheduler{backfill: true}
+	toAllocate, _ = p.prioritySchedule(taskList, groupMap,
+		make(map[model.JobID]decimal.Decimal), agentMap, BestFit)
+	assertEqualToAllocate(t, toAllocate, []*mockTask{tasks[3]})
+	assert.Equal(t, toAllocate[0].State, sproto.SchedulingStateScheduledBackfilled)
+}
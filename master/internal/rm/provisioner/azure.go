@@ -0,0 +1,418 @@
+package provisioner
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/internal/config/provconfig"
+	"github.com/determined-ai/determined/master/pkg/actor"
+)
+
+const (
+	azureManagementEndpoint = "https://management.azure.com"
+	azureADEndpoint         = "https://login.microsoftonline.com"
+	azureComputeAPIVersion  = "2022-08-01"
+	azureRequestTimeout     = time.Minute
+)
+
+// azureCluster wraps a virtual machine scale set. Determined recognizes agent VMs as the instances
+// of the scale set, and names agents after the VM names of the instances. The scale set is created
+// on the first launch, and then scaled out by raising its capacity and scaled in by deleting
+// specific instances.
+type azureCluster struct {
+	*provconfig.AzureClusterConfig
+	resourcePool string
+	scaleSetName string
+	masterURL    url.URL
+	customData   string
+
+	token  *adal.ServicePrincipalToken
+	client *http.Client
+}
+
+func newAzureCluster(
+	resourcePool string, config *provconfig.Config, cert *tls.Certificate,
+) (*azureCluster, error) {
+	if err := config.Azure.InitDefaultValues(); err != nil {
+		return nil, errors.Wrap(err, "failed to initialize auto configuration")
+	}
+
+	// The service principal or managed identity needs the "Virtual Machine Contributor" role (or
+	// at least read, write, and delete access to virtual machine scale sets) on the resource
+	// group, and "Network Contributor" or join access on the subnet.
+	resource := azureManagementEndpoint + "/"
+	var token *adal.ServicePrincipalToken
+	var err error
+	if config.Azure.ClientSecret != "" {
+		oauthConfig, oErr := adal.NewOAuthConfig(azureADEndpoint, config.Azure.TenantID)
+		if oErr != nil {
+			return nil, errors.Wrap(oErr, "failed to create Azure OAuth config")
+		}
+		token, err = adal.NewServicePrincipalToken(
+			*oauthConfig, config.Azure.ClientID, config.Azure.ClientSecret, resource)
+	} else {
+		token, err = adal.NewServicePrincipalTokenFromManagedIdentity(
+			resource, &adal.ManagedIdentityOptions{ClientID: config.Azure.ClientID})
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Azure credentials")
+	}
+
+	masterURL, err := url.Parse(config.MasterURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse master url")
+	}
+
+	startupScriptBase64 := base64.StdEncoding.EncodeToString([]byte(config.StartupScript))
+	containerScriptBase64 := base64.StdEncoding.EncodeToString([]byte(config.ContainerStartupScript))
+
+	var certBytes []byte
+	if masterURL.Scheme == secureScheme && cert != nil {
+		for _, c := range cert.Certificate {
+			b := pem.EncodeToMemory(&pem.Block{
+				Type:  "CERTIFICATE",
+				Bytes: c,
+			})
+			certBytes = append(certBytes, b...)
+		}
+	}
+	masterCertBase64 := base64.StdEncoding.EncodeToString(certBytes)
+	configFileBase64 := base64.StdEncoding.EncodeToString(config.AgentConfigFileContents)
+
+	startupScript := mustMakeAgentSetupScript(agentSetupScriptConfig{
+		MasterHost:                   masterURL.Hostname(),
+		MasterPort:                   masterURL.Port(),
+		MasterCertName:               config.MasterCertName,
+		StartupScriptBase64:          startupScriptBase64,
+		ContainerStartupScriptBase64: containerScriptBase64,
+		MasterCertBase64:             masterCertBase64,
+		ConfigFileBase64:             configFileBase64,
+		SlotType:                     config.Azure.SlotType(),
+		AgentDockerRuntime:           config.AgentDockerRuntime,
+		AgentNetwork:                 config.AgentDockerNetwork,
+		AgentDockerImage:             config.AgentDockerImage,
+		AgentFluentImage:             config.AgentFluentImage,
+		AgentReconnectAttempts:       config.AgentReconnectAttempts,
+		AgentReconnectBackoff:        config.AgentReconnectBackoff,
+		AgentID: `$(curl -s -H Metadata:true "http://169.254.169.254/metadata/instance/` +
+			`compute/name?api-version=2021-02-01&format=text")`,
+		ResourcePool: resourcePool,
+	})
+
+	scaleSetName := config.Azure.ScaleSetName
+	if scaleSetName == "" {
+		scaleSetName = fmt.Sprintf("%s-%s", config.Azure.TagValue, resourcePool)
+	}
+
+	return &azureCluster{
+		AzureClusterConfig: config.Azure,
+		resourcePool:       resourcePool,
+		scaleSetName:       scaleSetName,
+		masterURL:          *masterURL,
+		customData:         base64.StdEncoding.EncodeToString(startupScript),
+		token:              token,
+		client:             &http.Client{Timeout: azureRequestTimeout},
+	}, nil
+}
+
+func (c *azureCluster) instanceType() instanceType {
+	return c.InstanceType
+}
+
+func (c *azureCluster) slotsPerInstance() int {
+	return c.AzureClusterConfig.SlotsPerInstance()
+}
+
+func (c *azureCluster) prestart(ctx *actor.Context) {}
+
+func (c *azureCluster) list(ctx *actor.Context) ([]*Instance, error) {
+	var vms []azureScaleSetVM
+	next := c.scaleSetPath("/virtualMachines") + "&$expand=instanceView"
+	for next != "" {
+		var page struct {
+			Value    []azureScaleSetVM `json:"value"`
+			NextLink string            `json:"nextLink"`
+		}
+		switch err := c.do(http.MethodGet, next, nil, &page); {
+		case isAzureNotFound(err):
+			// The scale set is only created on the first launch.
+			return nil, nil
+		case err != nil:
+			return nil, errors.Wrap(err, "cannot list Azure scale set instances")
+		}
+		vms = append(vms, page.Value...)
+		next = page.NextLink
+	}
+
+	instances := make([]*Instance, 0, len(vms))
+	for _, vm := range vms {
+		inst := &Instance{
+			ID:        vm.InstanceID,
+			AgentName: vm.Name,
+			State:     vm.state(),
+		}
+		if t, err := time.Parse(time.RFC3339, vm.Properties.TimeCreated); err == nil {
+			inst.LaunchTime = t
+		}
+		if inst.State == Unknown {
+			ctx.Log().Errorf("unknown instance state for instance %v: %v",
+				inst.ID, vm.Properties.ProvisioningState)
+		}
+		instances = append(instances, inst)
+	}
+	return instances, nil
+}
+
+func (c *azureCluster) launch(ctx *actor.Context, instanceNum int) {
+	if instanceNum <= 0 {
+		return
+	}
+
+	var scaleSet struct {
+		SKU struct {
+			Capacity int `json:"capacity"`
+		} `json:"sku"`
+	}
+	switch err := c.do(http.MethodGet, c.scaleSetPath(""), nil, &scaleSet); {
+	case isAzureNotFound(err):
+		spec := c.scaleSetSpec(instanceNum)
+		if err := c.do(http.MethodPut, c.scaleSetPath(""), spec, nil); err != nil {
+			ctx.Log().WithError(err).Error("cannot create Azure scale set")
+			return
+		}
+		ctx.Log().Infof("created Azure scale set %s with %d instances", c.scaleSetName, instanceNum)
+		return
+	case err != nil:
+		ctx.Log().WithError(err).Error("cannot get Azure scale set")
+		return
+	}
+
+	capacity := scaleSet.SKU.Capacity + instanceNum
+	patch := map[string]interface{}{"sku": map[string]interface{}{"capacity": capacity}}
+	if err := c.do(http.MethodPatch, c.scaleSetPath(""), patch, nil); err != nil {
+		ctx.Log().WithError(err).Errorf("cannot scale Azure scale set to %d instances", capacity)
+		return
+	}
+	ctx.Log().Infof("scaled Azure scale set %s to %d instances", c.scaleSetName, capacity)
+}
+
+func (c *azureCluster) terminate(ctx *actor.Context, instances []string) {
+	if len(instances) == 0 {
+		return
+	}
+
+	body := map[string]interface{}{"instanceIds": instances}
+	if err := c.do(http.MethodPost, c.scaleSetPath("/delete"), body, nil); err != nil {
+		ctx.Log().WithError(err).Errorf("cannot delete Azure scale set instances: %s",
+			strings.Join(instances, ", "))
+		return
+	}
+	ctx.Log().Infof("deleted %d Azure scale set instances: %s",
+		len(instances), strings.Join(instances, ", "))
+}
+
+func (c *azureCluster) scaleSetPath(suffix string) string {
+	return fmt.Sprintf(
+		"%s/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/"+
+			"virtualMachineScaleSets/%s%s?api-version=%s",
+		azureManagementEndpoint, c.SubscriptionID, c.ResourceGroup, c.scaleSetName, suffix,
+		azureComputeAPIVersion,
+	)
+}
+
+// scaleSetSpec builds the scale set definition. Scaling is left entirely to Determined, so the
+// scale set is neither overprovisioned nor upgraded automatically.
+func (c *azureCluster) scaleSetSpec(capacity int) map[string]interface{} {
+	imageReference := map[string]interface{}{}
+	if c.Image.ID != "" {
+		imageReference["id"] = c.Image.ID
+	} else {
+		imageReference["publisher"] = c.Image.Publisher
+		imageReference["offer"] = c.Image.Offer
+		imageReference["sku"] = c.Image.SKU
+		imageReference["version"] = c.Image.Version
+	}
+
+	ipConfiguration := map[string]interface{}{
+		"subnet": map[string]interface{}{"id": c.SubnetID},
+	}
+	if c.PublicIP {
+		ipConfiguration["publicIPAddressConfiguration"] = map[string]interface{}{
+			"name": "determined-agent-public-ip",
+		}
+	}
+
+	profile := map[string]interface{}{
+		"osProfile": map[string]interface{}{
+			"computerNamePrefix": c.scaleSetName,
+			"adminUsername":      c.AdminUsername,
+			"customData":         c.customData,
+			"linuxConfiguration": map[string]interface{}{
+				"disablePasswordAuthentication": true,
+				"ssh": map[string]interface{}{
+					"publicKeys": []interface{}{map[string]interface{}{
+						"path":    fmt.Sprintf("/home/%s/.ssh/authorized_keys", c.AdminUsername),
+						"keyData": c.SSHPublicKey,
+					}},
+				},
+			},
+		},
+		"storageProfile": map[string]interface{}{
+			"imageReference": imageReference,
+			"osDisk": map[string]interface{}{
+				"createOption": "FromImage",
+				"diskSizeGB":   c.OSDiskSize,
+			},
+		},
+		"networkProfile": map[string]interface{}{
+			"networkInterfaceConfigurations": []interface{}{map[string]interface{}{
+				"name": "determined-agent-nic",
+				"properties": map[string]interface{}{
+					"primary": true,
+					"ipConfigurations": []interface{}{map[string]interface{}{
+						"name":       "determined-agent-ip",
+						"properties": ipConfiguration,
+					}},
+				},
+			}},
+		},
+	}
+	if c.SpotEnabled {
+		profile["priority"] = "Spot"
+		profile["evictionPolicy"] = "Delete"
+		profile["billingProfile"] = map[string]interface{}{"maxPrice": c.SpotMaxPrice}
+	}
+
+	return map[string]interface{}{
+		"location": c.Location,
+		"tags": map[string]string{
+			c.TagKey:                   c.TagValue,
+			"determined-resource-pool": c.resourcePool,
+			"determined-master-host":   c.masterURL.Hostname(),
+			"determined-master-port":   c.masterURL.Port(),
+		},
+		"sku": map[string]interface{}{
+			"name":     c.InstanceType.VMSize,
+			"tier":     "Standard",
+			"capacity": capacity,
+		},
+		"properties": map[string]interface{}{
+			"overprovision":         false,
+			"upgradePolicy":         map[string]interface{}{"mode": "Manual"},
+			"virtualMachineProfile": profile,
+		},
+	}
+}
+
+// azureError is an error response from the Azure Resource Manager API.
+type azureError struct {
+	StatusCode int
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e azureError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+func isAzureNotFound(err error) bool {
+	var azErr azureError
+	return errors.As(err, &azErr) && azErr.StatusCode == http.StatusNotFound
+}
+
+// do sends a request to the Azure Resource Manager API, decoding the response into out if it is
+// not nil. Long-running operations are not waited on; their results show up in later lists.
+func (c *azureCluster) do(method, path string, in, out interface{}) error {
+	if err := c.token.EnsureFresh(); err != nil {
+		return errors.Wrap(err, "cannot refresh Azure access token")
+	}
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token.OAuthToken())
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var errResp struct {
+			Error azureError `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&errResp)
+		errResp.Error.StatusCode = resp.StatusCode
+		return errResp.Error
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// azureScaleSetVM is the subset of a scale set VM that Determined uses.
+type azureScaleSetVM struct {
+	InstanceID string `json:"instanceId"`
+	Name       string `json:"name"`
+	Properties struct {
+		ProvisioningState string `json:"provisioningState"`
+		TimeCreated       string `json:"timeCreated"`
+		InstanceView      struct {
+			Statuses []struct {
+				Code string `json:"code"`
+			} `json:"statuses"`
+		} `json:"instanceView"`
+	} `json:"properties"`
+}
+
+// See https://learn.microsoft.com/en-us/azure/virtual-machines/states-billing.
+var azurePowerStates = map[string]InstanceState{
+	"PowerState/starting":     Starting,
+	"PowerState/running":      Running,
+	"PowerState/stopping":     Stopping,
+	"PowerState/stopped":      Stopped,
+	"PowerState/deallocating": Stopping,
+	"PowerState/deallocated":  Stopped,
+}
+
+func (vm azureScaleSetVM) state() InstanceState {
+	switch vm.Properties.ProvisioningState {
+	case "Creating":
+		return Starting
+	case "Deleting":
+		return Terminating
+	}
+	for _, status := range vm.Properties.InstanceView.Statuses {
+		if state, ok := azurePowerStates[status.Code]; ok {
+			return state
+		}
+	}
+	if vm.Properties.ProvisioningState == "Succeeded" {
+		return Starting
+	}
+	return Unknown
+}
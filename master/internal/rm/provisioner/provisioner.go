@@ -64,6 +64,11 @@ func New(
 		if cluster, err = newGCPCluster(resourcePool, config, cert); err != nil {
 			return nil, errors.Wrap(err, "cannot create a GCP cluster")
 		}
+	case config.Azure != nil:
+		var err error
+		if cluster, err = newAzureCluster(resourcePool, config, cert); err != nil {
+			return nil, errors.Wrap(err, "cannot create an Azure cluster")
+		}
 	}
 
 	return &Provisioner{
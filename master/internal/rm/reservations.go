@@ -0,0 +1,113 @@
+package rm
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// reservationsRefreshInterval is how often resource pools reload their slot reservations, which
+// bounds how long it takes for a new or deleted reservation to take effect.
+const reservationsRefreshInterval = 10 * time.Second
+
+// reservationsTick periodically triggers a resource pool to reload its slot reservations.
+type reservationsTick struct{}
+
+func (rp *ResourcePool) refreshReservations(ctx *actor.Context) {
+	reservations, err := db.SlotReservations(context.TODO(), rp.config.PoolName, time.Now())
+	if err != nil {
+		ctx.Log().WithError(err).Warn("failed to load slot reservations")
+		return
+	}
+	rp.reservations = reservations
+}
+
+// honorReservations filters the tasks chosen by the scheduler down to those that can start
+// without taking slots held by an active reservation that doesn't cover them. Tasks covered by a
+// reservation use its slots first, and any other free slots after that. Running tasks are never
+// preempted for a reservation; its slots are held as they free up.
+func honorReservations(
+	toAllocate []*sproto.AllocateRequest,
+	reservations []model.SlotReservation,
+	taskList *taskList,
+	agents map[*actor.Ref]*AgentState,
+	now time.Time,
+) []*sproto.AllocateRequest {
+	var active []model.SlotReservation
+	for _, r := range reservations {
+		if r.ActiveAt(now) {
+			active = append(active, r)
+		}
+	}
+	if len(active) == 0 {
+		return toAllocate
+	}
+
+	// unused tracks the slots of each active reservation that no task it covers is using.
+	unused := make([]int, len(active))
+	for i, r := range active {
+		unused[i] = r.Slots
+	}
+	for it := taskList.iterator(); it.next(); {
+		if req := it.value(); taskList.GetAllocations(req.AllocationRef) != nil {
+			claimReservedSlots(active, unused, req)
+		}
+	}
+
+	free := 0
+	for _, agent := range agents {
+		free += agent.NumEmptySlots()
+	}
+
+	allowed := make([]*sproto.AllocateRequest, 0, len(toAllocate))
+	for _, req := range toAllocate {
+		held := 0
+		for _, slots := range unused {
+			held += slots
+		}
+		unreserved := free - held
+		if unreserved < 0 {
+			unreserved = 0
+		}
+
+		remaining := append([]int(nil), unused...)
+		claimed := claimReservedSlots(active, remaining, req)
+		if req.SlotsNeeded-claimed > unreserved {
+			log.Debugf("not starting task %s as it would use reserved slots", req.Name)
+			continue
+		}
+		unused = remaining
+		free -= req.SlotsNeeded
+		allowed = append(allowed, req)
+	}
+	return allowed
+}
+
+// claimReservedSlots takes as many of the slots the task needs as it can from the unused slots of
+// the reservations that cover it, returning how many it took.
+func claimReservedSlots(
+	active []model.SlotReservation, unused []int, req *sproto.AllocateRequest,
+) int {
+	claimed := 0
+	for i, r := range active {
+		if claimed == req.SlotsNeeded {
+			break
+		}
+		if !r.Covers(req.UserID, req.WorkspaceID) {
+			continue
+		}
+		take := req.SlotsNeeded - claimed
+		if take > unused[i] {
+			take = unused[i]
+		}
+		unused[i] -= take
+		claimed += take
+	}
+	return claimed
+}
@@ -0,0 +1,46 @@
+package rm
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestHonorReservations(t *testing.T) {
+	system := actor.NewSystem(t.Name())
+	agents := []*mockAgent{
+		{id: "agent1", slots: 8},
+	}
+	tasks := []*mockTask{
+		{id: "running", slotsNeeded: 2, allocatedAgent: agents[0], containerStarted: true},
+	}
+	taskList, _, agentMap := setupSchedulerStates(t, system, tasks, nil, agents)
+
+	now := time.Now()
+	reserved := model.UserID(1)
+	reservations := []model.SlotReservation{{
+		Slots:     4,
+		StartTime: now.Add(-time.Hour),
+		EndTime:   now.Add(time.Hour),
+		UserIDs:   []int32{int32(reserved)},
+	}}
+	toAllocate := []*sproto.AllocateRequest{
+		{Name: "other-large", SlotsNeeded: 4},
+		{Name: "reserved", SlotsNeeded: 4, UserID: &reserved},
+		{Name: "other-small", SlotsNeeded: 2},
+	}
+
+	// Of the 6 free slots, 4 are held for the reservation, so only the task it covers and a task
+	// that fits in the remaining 2 slots may start.
+	allowed := honorReservations(toAllocate, reservations, taskList, agentMap, now)
+	assert.DeepEqual(t, allowed, []*sproto.AllocateRequest{toAllocate[1], toAllocate[2]})
+
+	// Once the reservation ends, its slots are released.
+	allowed = honorReservations(toAllocate, reservations, taskList, agentMap, now.Add(2*time.Hour))
+	assert.DeepEqual(t, allowed, toAllocate)
+}
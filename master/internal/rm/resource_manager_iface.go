@@ -27,6 +27,10 @@ type ResourceManager interface {
 		actor.Messenger,
 		sproto.ValidateCommandResourcesRequest,
 	) (sproto.ValidateCommandResourcesResponse, error)
+	ScheduleDryRun(
+		actor.Messenger,
+		sproto.ScheduleDryRunRequest,
+	) (sproto.ScheduleDryRunResponse, error)
 	DeleteJob(actor.Messenger, sproto.DeleteJob) (sproto.DeleteJobResponse, error)
 	NotifyContainerRunning(actor.Messenger, sproto.NotifyContainerRunning) error
 
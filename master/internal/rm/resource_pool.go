@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"strconv"
+	"time"
 
 	"golang.org/x/exp/maps"
 
@@ -16,6 +17,7 @@ import (
 
 	"github.com/determined-ai/determined/master/internal/config"
 	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/maintenance"
 	"github.com/determined-ai/determined/master/internal/rm/provisioner"
 	"github.com/determined-ai/determined/master/internal/sproto"
 	"github.com/determined-ai/determined/master/internal/task/taskmodel"
@@ -45,6 +47,11 @@ type ResourcePool struct {
 	groupActorToID   map[*actor.Ref]model.JobID
 	IDToGroupActor   map[model.JobID]*actor.Ref
 	scalingInfo      *sproto.ScalingInfo
+	// resizing tracks elastic allocations that have been asked to resize but haven't yet
+	// released their resources.
+	resizing map[*actor.Ref]bool
+	// reservations are the slot reservations of the pool that haven't ended yet.
+	reservations []model.SlotReservation
 
 	reschedule bool
 
@@ -59,6 +66,12 @@ type ResourcePool struct {
 // resource pool (agents, slots, cpu containers).
 type GetResourceSummary struct{}
 
+// UpdateResourcePoolConfig replaces the settings of the resource pool that can change while it
+// runs with those of Config.
+type UpdateResourcePoolConfig struct {
+	Config config.ResourcePoolConfig
+}
+
 // NewResourcePool initializes a new empty default resource provider.
 func NewResourcePool(
 	config *config.ResourcePoolConfig,
@@ -81,6 +94,7 @@ func NewResourcePool(
 		groupActorToID: make(map[*actor.Ref]model.JobID),
 		IDToGroupActor: make(map[model.JobID]*actor.Ref),
 		scalingInfo:    &sproto.ScalingInfo{},
+		resizing:       make(map[*actor.Ref]bool),
 
 		reschedule: false,
 		db:         db,
@@ -102,11 +116,28 @@ func (rp *ResourcePool) setupProvisioner(ctx *actor.Context) error {
 	return nil
 }
 
+func (rp *ResourcePool) updateConfig(ctx *actor.Context, c config.ResourcePoolConfig) {
+	rp.config.Description = c.Description
+	rp.config.MaxAuxContainersPerAgent = c.MaxAuxContainersPerAgent
+	rp.config.TaskContainerDefaults = c.TaskContainerDefaults
+	rp.config.AgentReconnectWait = c.AgentReconnectWait
+	// Pools without a scheduler of their own use the one of the resource manager, which cannot
+	// change, and jobs are queued for the type of scheduler, so only its settings can.
+	if c.Scheduler != nil && c.Scheduler.GetType() == rp.config.Scheduler.GetType() {
+		rp.config.Scheduler = c.Scheduler
+		rp.scheduler = MakeScheduler(c.Scheduler)
+		rp.fittingMethod = MakeFitFunction(c.Scheduler.FittingPolicy)
+	}
+	ctx.Log().Info("updated resource pool configuration")
+}
+
 func (rp *ResourcePool) allocateRequest(ctx *actor.Context, msg sproto.AllocateRequest) {
 	rp.notifyOnStop(ctx, msg.AllocationRef, sproto.ResourcesReleased{
 		AllocationRef: msg.AllocationRef,
 	})
-	log := ctx.Log().WithField("allocation-id", msg.AllocationID)
+	log := ctx.Log().
+		WithFields(msg.LogContext.Fields()).
+		WithField("allocation-id", msg.AllocationID)
 
 	if len(msg.AllocationID) == 0 {
 		msg.AllocationID = model.AllocationID(uuid.New().String())
@@ -204,6 +235,9 @@ func (rp *ResourcePool) restoreResources(
 		ResourcePool: rp.config.PoolName,
 		Resources:    resources,
 		Recovered:    true,
+		// The original start time isn't persisted, so assume the task is only starting now,
+		// which can only make the scheduler more cautious about backfilling.
+		StartTime: time.Now(),
 	}
 
 	rp.taskList.AddTask(req)
@@ -228,6 +262,7 @@ func (rp *ResourcePool) allocateResources(ctx *actor.Context, req *sproto.Alloca
 		return false
 	}
 
+	log := ctx.Log().WithFields(req.LogContext.Fields())
 	resources := make([]*containerResources, 0, len(fits))
 	rollback := false
 
@@ -268,7 +303,7 @@ func (rp *ResourcePool) allocateResources(ctx *actor.Context, req *sproto.Alloca
 			})
 		case error:
 			// Rollback previous allocations.
-			ctx.Log().WithError(resp).Warnf("failed to allocate request %s", req.AllocationID)
+			log.WithError(resp).Warnf("failed to allocate request %s", req.AllocationID)
 			rollback = true
 			return false
 		default:
@@ -280,12 +315,12 @@ func (rp *ResourcePool) allocateResources(ctx *actor.Context, req *sproto.Alloca
 	for _, cr := range resources {
 		rs := taskmodel.NewResourcesState(cr, -1)
 		if err := rs.Persist(); err != nil {
-			ctx.Log().WithError(err).Error("persistence failure")
+			log.WithError(err).Error("persistence failure")
 			rollback = true
 			return false
 		}
 		if err := cr.Persist(); err != nil {
-			ctx.Log().WithError(err).Error("persistence failure")
+			log.WithError(err).Error("persistence failure")
 			rollback = true
 			return false
 		}
@@ -301,6 +336,7 @@ func (rp *ResourcePool) allocateResources(ctx *actor.Context, req *sproto.Alloca
 		ResourcePool:      rp.config.PoolName,
 		Resources:         sprotoResources,
 		JobSubmissionTime: req.JobSubmissionTime,
+		StartTime:         time.Now(),
 	}
 	rp.taskList.SetAllocations(req.AllocationRef, &allocated)
 	ctx.Tell(req.AllocationRef, allocated)
@@ -313,7 +349,7 @@ func (rp *ResourcePool) allocateResources(ctx *actor.Context, req *sproto.Alloca
 
 	rp.refreshAgentStateCacheFor(ctx, allocatedAgents)
 
-	ctx.Log().Infof("allocated resources to %s", req.AllocationRef.Address())
+	log.Infof("allocated resources to %s", req.AllocationRef.Address())
 
 	return true
 }
@@ -330,6 +366,7 @@ func (rp *ResourcePool) resourcesReleased(
 	switch a := rp.taskList.GetAllocations(msg.AllocationRef); {
 	case a == nil:
 		rp.taskList.RemoveTaskByHandler(msg.AllocationRef)
+		delete(rp.resizing, msg.AllocationRef)
 	case msg.ResourcesID != nil:
 		ctx.Log().Infof(
 			"resources %v are released for %s",
@@ -351,6 +388,7 @@ func (rp *ResourcePool) resourcesReleased(
 			ctx.Tell(typed.agent.Handler, DeallocateContainer{ContainerID: typed.containerID})
 		}
 		rp.taskList.RemoveTaskByHandler(msg.AllocationRef)
+		delete(rp.resizing, msg.AllocationRef)
 	}
 }
 
@@ -421,6 +459,8 @@ func (rp *ResourcePool) Receive(ctx *actor.Context) error {
 			return err
 		}
 		actors.NotifyAfter(ctx, actionCoolDown, schedulerTick{})
+		actors.NotifyAfter(ctx, poolStatsInterval, poolStatsTick{})
+		actors.NotifyAfter(ctx, reservationsRefreshInterval, reservationsTick{})
 		return err
 
 	case
@@ -470,34 +510,61 @@ func (rp *ResourcePool) Receive(ctx *actor.Context) error {
 		}()
 		ctx.Respond(getResourceSummary(rp.agentStatesCache))
 
+	case UpdateResourcePoolConfig:
+		rp.updateConfig(ctx, msg.Config)
+
 	case aproto.GetRPConfig:
 		reschedule = false
 		ctx.Respond(aproto.GetRPResponse{
 			AgentReconnectWait:    rp.config.AgentReconnectWait,
 			AgentReattachEnabled:  rp.config.AgentReattachEnabled,
 			MaxZeroSlotContainers: rp.config.MaxAuxContainersPerAgent,
+			ContainerRuntime:      rp.config.ContainerRuntime,
+			CPUSlots:              rp.config.CPUSlots,
+
+			AgentHeartbeatInterval:      rp.config.AgentHeartbeatInterval,
+			AgentHeartbeatMissTolerance: rp.config.AgentHeartbeatMissTolerance,
 		})
 
 	case schedulerTick:
-		if rp.reschedule {
+		// In maintenance mode, the pass is put off rather than skipped, so that work submitted in
+		// the meantime is scheduled once it ends.
+		if rp.reschedule && !maintenance.Enabled() {
+			start := time.Now()
 			rp.agentStatesCache = rp.fetchAgentStates(ctx)
 			defer func() {
 				rp.agentStatesCache = nil
 			}()
 
 			toAllocate, toRelease := rp.scheduler.Schedule(rp)
+			toAllocate = honorReservations(
+				toAllocate, rp.reservations, rp.taskList, rp.agentStatesCache, time.Now())
 			for _, req := range toAllocate {
 				rp.allocateResources(ctx, req)
 			}
 			for _, taskActor := range toRelease {
 				rp.releaseResource(ctx, taskActor)
 			}
+			rp.resizeElasticAllocations(ctx, toRelease)
 			rp.sendScalingInfo(ctx)
+			observeSchedulerPass(rp.config.PoolName, rp.taskList, start)
+			rp.reschedule = false
 		}
-		rp.reschedule = false
 		reschedule = false
 		actors.NotifyAfter(ctx, actionCoolDown, schedulerTick{})
 
+	case poolStatsTick:
+		reschedule = false
+		rp.recordPoolStats(ctx)
+		actors.NotifyAfter(ctx, poolStatsInterval, poolStatsTick{})
+
+	case reservationsTick:
+		rp.refreshReservations(ctx)
+		// Reschedule while there are reservations, so that tasks that were held back can start
+		// once a reservation ends.
+		reschedule = len(rp.reservations) > 0
+		actors.NotifyAfter(ctx, reservationsRefreshInterval, reservationsTick{})
+
 	case sproto.ValidateCommandResourcesRequest:
 		fulfillable := true // Default to "true" when unknown.
 		if rp.slotsPerInstance > 0 {
@@ -505,6 +572,10 @@ func (rp *ResourcePool) Receive(ctx *actor.Context) error {
 		}
 		ctx.Respond(sproto.ValidateCommandResourcesResponse{Fulfillable: fulfillable})
 
+	case sproto.ScheduleDryRunRequest:
+		reschedule = false
+		ctx.Respond(rp.scheduleDryRun(ctx, msg))
+
 	default:
 		reschedule = false
 		return actor.ErrUnexpectedMessage(ctx)
@@ -68,6 +68,8 @@ type mockTask struct {
 	// Any test that set this to false is half wrong. It is used as a proxy to oversubscribe agents.
 	containerStarted  bool
 	jobSubmissionTime time.Time
+	elastic           *sproto.ElasticConfig
+	expectedRuntime   *time.Duration
 }
 
 func (t *mockTask) Receive(ctx *actor.Context) error {
@@ -85,6 +87,7 @@ func (t *mockTask) Receive(ctx *actor.Context) error {
 			AgentLabel:        t.label,
 			ResourcePool:      t.resourcePool,
 			AllocationRef:     ctx.Self(),
+			ExpectedRuntime:   t.expectedRuntime,
 		}
 		if t.group == nil {
 			task.Group = ctx.Self()
@@ -339,6 +342,8 @@ func mockTaskToAllocateRequest(
 		AllocationRef:     allocationRef,
 		Preemptible:       !mockTask.nonPreemptible,
 		JobSubmissionTime: jobSubmissionTime,
+		Elastic:           mockTask.elastic,
+		ExpectedRuntime:   mockTask.expectedRuntime,
 	}
 	return req
 }
@@ -439,6 +444,7 @@ func setupSchedulerStates(
 						devices:     devices,
 					},
 				},
+				StartTime: time.Now(),
 			}
 			taskList.SetAllocations(req.AllocationRef, allocated)
 		}
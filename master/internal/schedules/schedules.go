@@ -0,0 +1,318 @@
+// Package schedules stores schedules that submit an experiment whenever their cron expression
+// matches, such as for retraining a model every night, and keeps the history of their runs.
+package schedules
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/webhooks"
+	"github.com/determined-ai/determined/master/pkg/cron"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/proto/pkg/experimentv1"
+	"github.com/determined-ai/determined/proto/pkg/schedulev1"
+)
+
+// checkPeriod is how often the master looks for schedules that are due. Cron expressions are
+// precise to the minute.
+const checkPeriod = time.Minute
+
+// Schedule corresponds to a row in the "experiment_schedules" DB table.
+type Schedule struct {
+	bun.BaseModel `bun:"table:experiment_schedules"`
+
+	ID       int    `bun:"id,pk,autoincrement"`
+	Name     string `bun:"name,notnull"`
+	Cron     string `bun:"cron,notnull"`
+	TimeZone string `bun:"time_zone,notnull"`
+	// Config is the experiment config (YAML) each run submits.
+	Config string `bun:"config,notnull"`
+	// Template, if set, is the config template applied to the config of each run.
+	Template *string `bun:"template"`
+	// ModelDefinition is the gzipped tarball of the model definition each run submits.
+	ModelDefinition []byte       `bun:"model_definition,notnull"`
+	ProjectID       int          `bun:"project_id,notnull"`
+	OwnerID         model.UserID `bun:"owner_id,notnull"`
+	Paused          bool         `bun:"paused,notnull"`
+	// NextRunAt is nil while the schedule is paused.
+	NextRunAt *time.Time `bun:"next_run_at"`
+	CreatedAt time.Time  `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+// Proto returns the protobuf representation of the schedule.
+func (s Schedule) Proto() *schedulev1.ExperimentSchedule {
+	ps := &schedulev1.ExperimentSchedule{
+		Id:        int32(s.ID),
+		Name:      s.Name,
+		Cron:      s.Cron,
+		TimeZone:  s.TimeZone,
+		Config:    s.Config,
+		Template:  s.Template,
+		ProjectId: int32(s.ProjectID),
+		OwnerId:   int32(s.OwnerID),
+		Paused:    s.Paused,
+		CreatedAt: timestamppb.New(s.CreatedAt),
+	}
+	if s.NextRunAt != nil {
+		ps.NextRunAt = timestamppb.New(*s.NextRunAt)
+	}
+	return ps
+}
+
+// Validate checks that the cron expression and time zone of the schedule can be used to schedule
+// runs, and sets when it runs next.
+func (s *Schedule) Validate(now time.Time) error {
+	if s.Name == "" {
+		return errors.New("schedule name must not be empty")
+	}
+	if s.TimeZone == "" {
+		s.TimeZone = "UTC"
+	}
+	next, err := nextRun(s, now)
+	if err != nil {
+		return err
+	}
+	if next.IsZero() {
+		return errors.Errorf("cron expression %q never matches", s.Cron)
+	}
+	s.NextRunAt = &next
+	if s.Paused {
+		s.NextRunAt = nil
+	}
+	return nil
+}
+
+// nextRun returns the first time after now that the cron expression of the schedule matches in
+// its time zone.
+func nextRun(s *Schedule, now time.Time) (time.Time, error) {
+	c, err := cron.Parse(s.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "invalid time zone %q", s.TimeZone)
+	}
+	return c.Next(now.In(loc)), nil
+}
+
+// Run corresponds to a row in the "experiment_schedule_runs" DB table.
+type Run struct {
+	bun.BaseModel `bun:"table:experiment_schedule_runs,alias:run"`
+
+	ID          int       `bun:"id,pk,autoincrement"`
+	ScheduleID  int       `bun:"schedule_id,notnull"`
+	ScheduledAt time.Time `bun:"scheduled_at,notnull"`
+	SubmittedAt time.Time `bun:"submitted_at,notnull"`
+	// ExperimentID is nil if the run failed to submit an experiment, or it was deleted.
+	ExperimentID *int    `bun:"experiment_id"`
+	Error        *string `bun:"error"`
+
+	// ExperimentState is the current state of the experiment of the run.
+	ExperimentState *model.State `bun:"experiment_state,scanonly"`
+}
+
+// Proto returns the protobuf representation of the run.
+func (r Run) Proto() *schedulev1.ExperimentScheduleRun {
+	pr := &schedulev1.ExperimentScheduleRun{
+		Id:          int32(r.ID),
+		ScheduleId:  int32(r.ScheduleID),
+		ScheduledAt: timestamppb.New(r.ScheduledAt),
+		SubmittedAt: timestamppb.New(r.SubmittedAt),
+	}
+	if r.ExperimentID != nil {
+		pr.ExperimentId = ptrs.Ptr(int32(*r.ExperimentID))
+	}
+	if r.ExperimentState != nil {
+		pr.ExperimentState = experimentv1.State(
+			experimentv1.State_value["STATE_"+string(*r.ExperimentState)])
+	}
+	if r.Error != nil {
+		pr.Error = *r.Error
+	}
+	return pr
+}
+
+// Add adds a schedule to the DB. It returns db.ErrDuplicateRecord if the name is taken.
+func Add(ctx context.Context, s *Schedule) error {
+	_, err := db.Bun().NewInsert().Model(s).Returning("*").Exec(ctx)
+	return db.MatchSentinelError(err)
+}
+
+// Get returns a schedule from the DB.
+func Get(ctx context.Context, id int) (*Schedule, error) {
+	var s Schedule
+	if err := db.Bun().NewSelect().Model(&s).Where("id = ?", id).Scan(ctx); err != nil {
+		return nil, db.MatchSentinelError(err)
+	}
+	return &s, nil
+}
+
+// List returns the schedules owned by the user, or every schedule if the user is nil.
+func List(ctx context.Context, ownerID *model.UserID) ([]Schedule, error) {
+	ss := []Schedule{}
+	q := db.Bun().NewSelect().Model(&ss).Order("id")
+	if ownerID != nil {
+		q = q.Where("owner_id = ?", *ownerID)
+	}
+	if err := q.Scan(ctx); err != nil {
+		return nil, err
+	}
+	return ss, nil
+}
+
+// Update replaces the settings of a schedule in the DB with those of s.
+func Update(ctx context.Context, s *Schedule) error {
+	res, err := db.Bun().NewUpdate().Model(s).
+		Column("name", "cron", "time_zone", "config", "template", "paused", "next_run_at").
+		WherePK().
+		Exec(ctx)
+	return db.MatchSentinelError(db.MustHaveAffectedRows(res, err))
+}
+
+// Delete deletes a schedule and its runs from the DB.
+func Delete(ctx context.Context, id int) error {
+	res, err := db.Bun().NewDelete().Model((*Schedule)(nil)).Where("id = ?", id).Exec(ctx)
+	return db.MustHaveAffectedRows(res, err)
+}
+
+// Runs returns the runs of a schedule, most recent first, along with the total number of them.
+func Runs(ctx context.Context, scheduleID, offset, limit int) ([]Run, int, error) {
+	runs := []Run{}
+	q := db.Bun().NewSelect().Model(&runs).
+		ColumnExpr("run.*").
+		ColumnExpr("e.state AS experiment_state").
+		Join("LEFT JOIN experiments e ON e.id = run.experiment_id").
+		Where("run.schedule_id = ?", scheduleID).
+		Order("run.scheduled_at DESC", "run.id DESC").
+		Offset(offset)
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	total, err := q.ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return runs, total, nil
+}
+
+// Submitter submits the experiment of a run of a schedule and returns its ID.
+type Submitter func(ctx context.Context, s *Schedule) (int, error)
+
+// Loop runs the schedules that are due until the context is canceled.
+func Loop(ctx context.Context, submit Submitter) {
+	t := time.NewTicker(checkPeriod)
+	defer t.Stop()
+	for {
+		if err := RunDue(ctx, time.Now(), submit); err != nil {
+			log.WithError(err).Error("failed to run experiment schedules")
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunDue submits the experiment of each schedule that is due at the given time and records the
+// run. A schedule that was due more than once, such as while the master was down, only runs once.
+// Each schedule moves on to its next run before its experiment is submitted, so that it is not
+// submitted twice if the master stops partway through.
+func RunDue(ctx context.Context, now time.Time, submit Submitter) error {
+	var due []Schedule
+	if err := db.Bun().NewSelect().Model(&due).
+		Where("NOT paused").
+		Where("next_run_at <= ?", now).
+		Order("next_run_at").
+		Scan(ctx); err != nil {
+		return err
+	}
+
+	for i := range due {
+		s := &due[i]
+		scheduledAt := *s.NextRunAt
+		next, err := nextRun(s, now)
+		if err != nil {
+			log.WithError(err).Errorf("failed to schedule the next run of schedule %s", s.Name)
+			continue
+		}
+		// A schedule whose expression no longer matches waits to be updated rather than running.
+		nextRunAt := &next
+		if next.IsZero() {
+			nextRunAt = nil
+		}
+		// Only move on if the schedule has not been changed since it was read.
+		res, err := db.Bun().NewUpdate().Model(s).
+			Set("next_run_at = ?", nextRunAt).
+			WherePK().
+			Where("next_run_at = ?", scheduledAt).
+			Where("NOT paused").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			continue
+		}
+
+		run := Run{ScheduleID: s.ID, ScheduledAt: scheduledAt, SubmittedAt: now}
+		if id, err := submit(ctx, s); err != nil {
+			run.Error = ptrs.Ptr(err.Error())
+		} else {
+			run.ExperimentID = &id
+		}
+		if _, err := db.Bun().NewInsert().Model(&run).Exec(ctx); err != nil {
+			return err
+		}
+
+		if run.Error != nil {
+			log.Warnf("run of schedule %s at %s failed to submit an experiment: %s",
+				s.Name, scheduledAt, *run.Error)
+			reportRunFailed(ctx, s, run, *run.Error)
+			continue
+		}
+		log.Infof("run of schedule %s at %s submitted experiment %d",
+			s.Name, scheduledAt, *run.ExperimentID)
+	}
+	return nil
+}
+
+// ReportExperimentFailed notifies the webhooks that listen for failed runs if the experiment was
+// submitted by a schedule. It is called when an experiment ends in an error.
+func ReportExperimentFailed(ctx context.Context, e model.Experiment) error {
+	var runs []Run
+	if err := db.Bun().NewSelect().Model(&runs).Where("experiment_id = ?", e.ID).
+		Scan(ctx); err != nil {
+		return err
+	}
+	for _, run := range runs {
+		s, err := Get(ctx, run.ScheduleID)
+		if err != nil {
+			return err
+		}
+		reportRunFailed(ctx, s, run, "experiment ended in state "+string(e.State))
+	}
+	return nil
+}
+
+func reportRunFailed(ctx context.Context, s *Schedule, run Run, reason string) {
+	if err := webhooks.ReportScheduledRunFailed(ctx, s.ProjectID, webhooks.RunPayload{
+		ScheduleID:   s.ID,
+		ScheduleName: s.Name,
+		ScheduledAt:  run.ScheduledAt,
+		ExperimentID: run.ExperimentID,
+		Error:        reason,
+	}); err != nil {
+		log.WithError(err).Error("failed to send scheduled run failed webhook")
+	}
+}
@@ -0,0 +1,110 @@
+//go:build integration
+// +build integration
+
+package schedules
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+)
+
+func TestRunDue(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(db.RootFromDB))
+	pgDB := db.MustResolveTestPostgres(t)
+	db.MustMigrateTestPostgres(t, pgDB, db.MigrationsFromDB)
+	ctx := context.Background()
+
+	user := db.RequireMockUser(t, pgDB)
+	exp := db.RequireMockExperiment(t, pgDB, user)
+	now := time.Date(2022, 12, 18, 2, 0, 0, 0, time.UTC)
+
+	newSchedule := func(name, spec string, paused bool) *Schedule {
+		s := &Schedule{
+			Name:            name + "-" + time.Now().Format(time.RFC3339Nano),
+			Cron:            spec,
+			Config:          "name: nightly",
+			ModelDefinition: []byte{},
+			ProjectID:       1,
+			OwnerID:         user.ID,
+			Paused:          paused,
+		}
+		require.NoError(t, s.Validate(now.Add(-time.Hour)))
+		require.NoError(t, Add(ctx, s))
+		return s
+	}
+	nightly := newSchedule("nightly", "0 2 * * *", false)
+	failing := newSchedule("failing", "@hourly", false)
+	later := newSchedule("later", "0 3 * * *", false)
+	paused := newSchedule("paused", "0 2 * * *", true)
+	require.Nil(t, paused.NextRunAt)
+	require.Equal(t, now, *nightly.NextRunAt)
+	require.Equal(t, "UTC", nightly.TimeZone)
+
+	dup := *nightly
+	dup.ID = 0
+	require.ErrorIs(t, Add(ctx, &dup), db.ErrDuplicateRecord)
+
+	submitted := map[int]int{}
+	submit := func(ctx context.Context, s *Schedule) (int, error) {
+		submitted[s.ID]++
+		if s.ID == failing.ID {
+			return 0, fmt.Errorf("invalid experiment")
+		}
+		return exp.ID, nil
+	}
+	require.NoError(t, RunDue(ctx, now, submit))
+	require.Equal(t, map[int]int{nightly.ID: 1, failing.ID: 1}, submitted)
+
+	// Schedules only run once per time they are due.
+	require.NoError(t, RunDue(ctx, now.Add(30*time.Second), submit))
+	require.Equal(t, map[int]int{nightly.ID: 1, failing.ID: 1}, submitted)
+
+	s, err := Get(ctx, nightly.ID)
+	require.NoError(t, err)
+	require.Equal(t, now.AddDate(0, 0, 1), s.NextRunAt.UTC())
+	s, err = Get(ctx, later.ID)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(time.Hour), s.NextRunAt.UTC())
+
+	runs, total, err := Runs(ctx, nightly.ID, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, now, runs[0].ScheduledAt.UTC())
+	require.Equal(t, &exp.ID, runs[0].ExperimentID)
+	require.Equal(t, exp.State, *runs[0].ExperimentState)
+	require.Nil(t, runs[0].Error)
+
+	runs, _, err = Runs(ctx, failing.ID, 0, 0)
+	require.NoError(t, err)
+	require.Nil(t, runs[0].ExperimentID)
+	require.Nil(t, runs[0].ExperimentState)
+	require.Equal(t, ptrs.Ptr("invalid experiment"), runs[0].Error)
+
+	// Deleting a schedule deletes its runs.
+	require.NoError(t, Delete(ctx, nightly.ID))
+	_, err = Get(ctx, nightly.ID)
+	require.ErrorIs(t, err, db.ErrNotFound)
+	runs, total, err = Runs(ctx, nightly.ID, 0, 0)
+	require.NoError(t, err)
+	require.Empty(t, runs)
+	require.Zero(t, total)
+	require.ErrorIs(t, Delete(ctx, nightly.ID), db.ErrNotFound)
+
+	// Only the schedules of the user are listed.
+	owned, err := List(ctx, &user.ID)
+	require.NoError(t, err)
+	require.Len(t, owned, 3)
+	other := model.UserID(-1)
+	owned, err = List(ctx, &other)
+	require.NoError(t, err)
+	require.Empty(t, owned)
+}
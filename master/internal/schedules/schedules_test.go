@@ -0,0 +1,24 @@
+package schedules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	now := time.Date(2022, 12, 18, 12, 0, 0, 0, time.UTC)
+	s := Schedule{Name: "nightly", Cron: "0 2 * * *", TimeZone: "America/New_York"}
+	require.NoError(t, s.Validate(now))
+	require.Equal(t, time.Date(2022, 12, 19, 7, 0, 0, 0, time.UTC), s.NextRunAt.UTC())
+
+	for _, s := range []Schedule{
+		{Cron: "0 2 * * *"},
+		{Name: "nightly", Cron: "0 2 * *"},
+		{Name: "nightly", Cron: "0 2 * * *", TimeZone: "Nowhere/Special"},
+		{Name: "nightly", Cron: "0 0 30 2 *"},
+	} {
+		require.Error(t, s.Validate(now), s)
+	}
+}
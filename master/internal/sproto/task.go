@@ -2,6 +2,7 @@ package sproto
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"golang.org/x/exp/maps"
@@ -33,17 +34,29 @@ type (
 		// Allocation actor
 		AllocationRef *actor.Ref
 		Group         *actor.Ref
+		// The user and workspace the task belongs to, used to decide whether it may use reserved
+		// slots. Either may be nil if unknown.
+		UserID      *model.UserID
+		WorkspaceID *int
+		// LogContext holds the fields of the allocation's logs, so that the logs the scheduler
+		// writes about it can be correlated with them.
+		LogContext logger.Context
 
 		// Resource configuration.
 		SlotsNeeded         int
 		AgentLabel          string
+		LabelConstraints    map[string]string
 		ResourcePool        string
 		FittingRequirements FittingRequirements
+		// ExpectedRuntime is how long the task declared it would hold its resources, if it did.
+		ExpectedRuntime *time.Duration
 
 		// Behavioral configuration.
 		Preemptible  bool
 		IdleTimeout  *IdleTimeoutConfig
-		ProxyPort    *ProxyPortConfig
+		GangStart    *GangStartConfig
+		Elastic      *ElasticConfig
+		ProxyPorts   []*ProxyPortConfig
 		StreamEvents *EventStreamConfig
 		Restore      bool
 	}
@@ -57,12 +70,29 @@ type (
 		Debug           bool
 	}
 
+	// GangStartConfig configures how long a multi-container allocation waits for all of its
+	// resources to start before it gives up on the stragglers.
+	GangStartConfig struct {
+		TimeoutDuration time.Duration
+		// Degraded, if true, continues with the resources that did start rather than releasing
+		// everything and requeueing.
+		Degraded bool
+	}
+
+	// ElasticConfig bounds the number of slots the resource manager may resize an elastic
+	// allocation to.
+	ElasticConfig struct {
+		MinSlots int
+		MaxSlots int
+	}
+
 	// ProxyPortConfig configures a proxy the allocation should start.
 	ProxyPortConfig struct {
 		ServiceID       string
 		Port            int
 		ProxyTCP        bool
 		Unauthenticated bool
+		StripPrefix     bool
 	}
 
 	// EventStreamConfig configures an event stream.
@@ -114,6 +144,35 @@ type (
 		// - true: ok or unknown
 		Fulfillable bool
 	}
+
+	// ScheduleDryRunRequest asks the resource manager where and when it would place a
+	// hypothetical allocation under the current cluster state, without queueing anything.
+	ScheduleDryRunRequest struct {
+		ResourcePool        string
+		SlotsNeeded         int
+		AgentLabel          string
+		LabelConstraints    map[string]string
+		FittingRequirements FittingRequirements
+		// Priority is the priority of the hypothetical job; the pool's default is used if nil.
+		Priority *int
+	}
+
+	// ScheduleDryRunResponse is the response to ScheduleDryRunRequest.
+	ScheduleDryRunResponse struct {
+		// Schedulable is false if the allocation could never be placed in the pool as it is
+		// currently configured.
+		Schedulable bool
+		// Placement maps agent IDs to the number of slots the allocation would receive on them if
+		// it could be placed right now; it is empty if the allocation would have to wait.
+		Placement map[string]int
+		// QueuedAhead and SlotsAhead count the pending allocations, and the slots they need, that
+		// the scheduler would consider before this one.
+		QueuedAhead int
+		SlotsAhead  int
+		// Reason explains why the allocation would wait or would never be scheduled.
+		Reason string
+	}
+
 	// AllocationSignal is an interface for signals that can be sent to an allocation.
 	AllocationSignal string
 	// AllocationSignalWithReason is an message for signals that can be sent to an allocation
@@ -129,6 +188,9 @@ const (
 	KillAllocation AllocationSignal = "kill"
 	// TerminateAllocation is the signal to kill an allocation; analogous to in SIGTERM.
 	TerminateAllocation AllocationSignal = "terminate"
+	// PreemptAllocation is the signal to gracefully preempt an allocation, like the scheduler
+	// would, asking it to checkpoint before releasing its resources.
+	PreemptAllocation AllocationSignal = "preempt"
 )
 
 // Incoming task actor messages; task actors must accept these messages.
@@ -144,6 +206,14 @@ type (
 		Resources         ResourceList
 		JobSubmissionTime time.Time
 		Recovered         bool
+		// StartTime is when the resources were allocated, or restored after a master restart.
+		StartTime time.Time
+	}
+	// ResizeAllocation notifies an elastic task actor that the resource manager would like it to
+	// release its resources and request Slots slots instead.
+	ResizeAllocation struct {
+		AllocationID model.AllocationID
+		Slots        int
 	}
 	// PendingPreemption notifies the task actor that it should release
 	// resources due to a pending system-triggered preemption.
@@ -192,6 +262,38 @@ const (
 	ResourcesTypeSlurmJob ResourcesType = "slurm-job"
 )
 
+// ExpectedRuntimeFromSeconds converts the expected runtime declared in a task's resources
+// configuration to a duration.
+func ExpectedRuntimeFromSeconds(seconds *int) *time.Duration {
+	if seconds == nil {
+		return nil
+	}
+	runtime := time.Duration(*seconds) * time.Second
+	return &runtime
+}
+
+// ServiceProxyID returns the proxy service ID of a named service declared by a task.
+func ServiceProxyID(taskID model.TaskID, name string) string {
+	return fmt.Sprintf("%s:%s", taskID, name)
+}
+
+// ServiceProxyPorts builds the proxy configurations of the named services a task declared in the
+// proxy_ports of its environment, ordered by name. Named services are always authenticated and
+// served with their proxy prefix stripped, so they can be hosted at the root of their URL.
+func ServiceProxyPorts(taskID model.TaskID, ports map[string]int) []*ProxyPortConfig {
+	names := maps.Keys(ports)
+	sort.Strings(names)
+	configs := make([]*ProxyPortConfig, 0, len(names))
+	for _, name := range names {
+		configs = append(configs, &ProxyPortConfig{
+			ServiceID:   ServiceProxyID(taskID, name),
+			Port:        ports[name],
+			StripPrefix: true,
+		})
+	}
+	return configs
+}
+
 // Clone clones ResourcesAllocated. Used to not pass mutable refs to other actors.
 func (ra ResourcesAllocated) Clone() ResourcesAllocated {
 	return ResourcesAllocated{
@@ -200,6 +302,7 @@ func (ra ResourcesAllocated) Clone() ResourcesAllocated {
 		Resources:         maps.Clone(ra.Resources),
 		JobSubmissionTime: ra.JobSubmissionTime,
 		Recovered:         ra.Recovered,
+		StartTime:         ra.StartTime,
 	}
 }
 
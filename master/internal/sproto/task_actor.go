@@ -29,6 +29,12 @@ type (
 		Level *string
 	}
 
+	// ContainerMetrics notifies the task actor of the resource usage of one of its containers.
+	ContainerMetrics struct {
+		ContainerID cproto.ID
+		Metrics     model.SystemMetrics
+	}
+
 	// GetResourcesContainerState requests cproto.Container state for a given clump of resources.
 	// If the resources aren't a container, this request returns a failure.
 	GetResourcesContainerState struct {
@@ -0,0 +1,131 @@
+// Package stream pushes changes to experiments to the clients subscribed to them, so that they
+// don't have to poll for them.
+package stream
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/checkpointv1"
+	"github.com/determined-ai/determined/proto/pkg/experimentv1"
+	"github.com/determined-ai/determined/proto/pkg/trialv1"
+)
+
+// subscriberBufferSize is how many events may be waiting to be sent to a subscriber before it is
+// considered too slow to keep up and is dropped.
+const subscriberBufferSize = 256
+
+// ErrLagged is returned to subscribers that fell too far behind and missed events; they should
+// reload the state of their experiments and subscribe again.
+var ErrLagged = errors.New("subscriber fell too far behind and missed events")
+
+var (
+	mu          sync.Mutex
+	subscribers = make(map[*Subscription]bool)
+)
+
+// Subscription receives the events of a set of experiments.
+type Subscription struct {
+	experimentIDs map[int32]bool
+	events        chan *apiv1.StreamExperimentEventsResponse
+}
+
+// Subscribe starts receiving the events of the given experiments. The subscription must be closed
+// once it is no longer needed.
+func Subscribe(experimentIDs []int32) *Subscription {
+	s := &Subscription{
+		experimentIDs: make(map[int32]bool, len(experimentIDs)),
+		events:        make(chan *apiv1.StreamExperimentEventsResponse, subscriberBufferSize),
+	}
+	for _, id := range experimentIDs {
+		s.experimentIDs[id] = true
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	subscribers[s] = true
+	return s
+}
+
+// Events returns the events of the subscribed experiments, in the order they happened. The
+// channel is closed if the subscriber falls too far behind, in which case it missed events.
+func (s *Subscription) Events() <-chan *apiv1.StreamExperimentEventsResponse {
+	return s.events
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() {
+	mu.Lock()
+	defer mu.Unlock()
+	if subscribers[s] {
+		delete(subscribers, s)
+		close(s.events)
+	}
+}
+
+func publish(event *apiv1.StreamExperimentEventsResponse) {
+	mu.Lock()
+	defer mu.Unlock()
+	for s := range subscribers {
+		if !s.experimentIDs[event.ExperimentId] {
+			continue
+		}
+		select {
+		case s.events <- event:
+		default:
+			delete(subscribers, s)
+			close(s.events)
+		}
+	}
+}
+
+func newEvent(experimentID int) *apiv1.StreamExperimentEventsResponse {
+	return &apiv1.StreamExperimentEventsResponse{
+		ExperimentId: int32(experimentID),
+		Time:         timestamppb.New(time.Now().UTC()),
+	}
+}
+
+func stateToProto(state model.State) experimentv1.State {
+	return experimentv1.State(experimentv1.State_value["STATE_"+string(state)])
+}
+
+// ExperimentStateChanged notifies subscribers of the experiment's new state.
+func ExperimentStateChanged(e model.Experiment) {
+	event := newEvent(e.ID)
+	event.Event = &apiv1.StreamExperimentEventsResponse_ExperimentState{
+		ExperimentState: stateToProto(e.State),
+	}
+	publish(event)
+}
+
+// TrialStateChanged notifies subscribers of a trial's new state.
+func TrialStateChanged(experimentID, trialID int, state model.State) {
+	event := newEvent(experimentID)
+	event.Event = &apiv1.StreamExperimentEventsResponse_TrialState{
+		TrialState: &apiv1.TrialStateChange{
+			TrialId: int32(trialID),
+			State:   stateToProto(state),
+		},
+	}
+	publish(event)
+}
+
+// CheckpointReported notifies subscribers of a new checkpoint.
+func CheckpointReported(experimentID int, c *checkpointv1.Checkpoint) {
+	event := newEvent(experimentID)
+	event.Event = &apiv1.StreamExperimentEventsResponse_Checkpoint{Checkpoint: c}
+	publish(event)
+}
+
+// ValidationMetricsReported notifies subscribers of new validation metrics.
+func ValidationMetricsReported(experimentID int, m *trialv1.TrialMetrics) {
+	event := newEvent(experimentID)
+	event.Event = &apiv1.StreamExperimentEventsResponse_ValidationMetrics{ValidationMetrics: m}
+	publish(event)
+}
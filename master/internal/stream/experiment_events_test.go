@@ -0,0 +1,40 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/experimentv1"
+)
+
+func TestExperimentEvents(t *testing.T) {
+	sub := Subscribe([]int32{1})
+	defer sub.Close()
+
+	ExperimentStateChanged(model.Experiment{ID: 2, State: model.ActiveState})
+	TrialStateChanged(1, 10, model.CompletedState)
+
+	event := <-sub.Events()
+	require.Equal(t, int32(1), event.ExperimentId)
+	require.Equal(t, int32(10), event.GetTrialState().TrialId)
+	require.Equal(t, experimentv1.State_STATE_COMPLETED, event.GetTrialState().State)
+	require.Len(t, sub.Events(), 0)
+}
+
+func TestExperimentEventsLagged(t *testing.T) {
+	sub := Subscribe([]int32{1})
+	defer sub.Close()
+
+	for i := 0; i <= subscriberBufferSize; i++ {
+		TrialStateChanged(1, i, model.ActiveState)
+	}
+
+	// The subscriber gets the events that fit in its buffer, after which it is dropped.
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+	require.Equal(t, subscriberBufferSize, received)
+}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
 
 	"github.com/determined-ai/determined/master/internal/cluster"
 	"github.com/determined-ai/determined/master/internal/db"
@@ -18,6 +20,7 @@ import (
 	"github.com/determined-ai/determined/master/internal/sproto"
 	"github.com/determined-ai/determined/master/internal/task/taskmodel"
 	"github.com/determined-ai/determined/master/internal/telemetry"
+	"github.com/determined-ai/determined/master/internal/webhooks"
 	"github.com/determined-ai/determined/master/pkg/actor"
 	"github.com/determined-ai/determined/master/pkg/actor/actors"
 	"github.com/determined-ai/determined/master/pkg/cproto"
@@ -59,6 +62,10 @@ type (
 		killCooldown *time.Time
 		// tracks if we have finished termination.
 		exited bool
+		// When we requested resources and, until any of them start running, when we received
+		// them; used to export scheduling and startup latencies.
+		requestedAt time.Time
+		allocatedAt time.Time
 
 		// State for specific sub-behaviors of an allocation.
 		// Encapsulates the preemption state of the currently allocated task.
@@ -69,12 +76,16 @@ type (
 		rendezvous *rendezvous
 		// Encapsulates the logic of watching for idle timeouts.
 		idleTimeoutWatcher *IdleTimeoutWatcher
+		// The size an elastic allocation was asked to resize to, if any.
+		resizedSlots *int
 		// proxy state
 		proxies []string
 		// proxyAddress is provided by determined.exec.prep_container if the RM doesn't provide it.
 		proxyAddress *string
 		// active all gather state
 		allGather *allGather
+		// When the GPU power of each container was last measured, to integrate it into energy.
+		gpuPowerMeasuredAt map[cproto.ID]time.Time
 
 		logCtx   detLogger.Context
 		restored bool
@@ -93,6 +104,8 @@ type (
 		UserRequestedStop bool
 		Err               error
 		FinalState        AllocationState
+		// ResizedSlots is set when an elastic allocation exited to be resized.
+		ResizedSlots *int
 	}
 	// BuildTaskSpec is a message to request the task spec from the parent task. This
 	// is just a hack since building a task spec cant be semi-costly and we want to defer it
@@ -126,6 +139,9 @@ const (
 	killCooldown       = 30 * time.Second
 	okExitMessage      = "allocation exited successfully"
 	missingExitMessage = ""
+	// maxGPUEnergyInterval is the longest gap between measurements of GPU power that is counted
+	// towards the energy of an allocation.
+	maxGPUEnergyInterval = 5 * time.Minute
 )
 
 // NewAllocation returns a new allocation, which tracks allocation state in a fairly generic way.
@@ -192,6 +208,10 @@ func (a *Allocation) Receive(ctx *actor.Context) error {
 		a.ResourcesStateChanged(ctx, msg)
 	case sproto.ResourcesFailure:
 		a.RestoreResourceFailure(ctx, msg)
+	case gangStartTimeout:
+		a.GangStartTimeout(ctx, msg)
+	case sproto.ResizeAllocation:
+		a.Resize(ctx, msg)
 	case sproto.GetResourcesContainerState:
 		if v, ok := a.resources[msg.ResourcesID]; ok {
 			if v.Container == nil {
@@ -211,6 +231,15 @@ func (a *Allocation) Receive(ctx *actor.Context) error {
 		allocationmap.UnregisterAllocation(a.model.AllocationID)
 	case sproto.ContainerLog:
 		a.sendEvent(ctx, msg.ToEvent())
+	case sproto.ContainerMetrics:
+		if err := db.AddAllocationSystemMetrics(context.TODO(), &model.AllocationSystemMetrics{
+			AllocationID:  a.model.AllocationID,
+			ContainerID:   string(msg.ContainerID),
+			SystemMetrics: msg.Metrics,
+		}); err != nil {
+			ctx.Log().WithError(err).Warn("failed to record system metrics")
+		}
+		a.recordGPUEnergy(ctx, msg)
 
 	// These messages allow users (and sometimes an orchestrator, such as HP search)
 	// to interact with the allocation. The usually trace back to API calls.
@@ -241,8 +270,10 @@ func (a *Allocation) Receive(ctx *actor.Context) error {
 		if ctx.ExpectingResponse() {
 			ctx.Respond(a.State())
 		}
+	case GetResize:
+		a.getResize(ctx, msg)
 	case SetAllocationProxyAddress:
-		if a.req.ProxyPort == nil {
+		if len(a.req.ProxyPorts) == 0 {
 			if ctx.ExpectingResponse() {
 				ctx.Respond(ErrBehaviorUnsupported{Behavior: fmt.Sprintf("%T", msg)})
 			}
@@ -367,6 +398,8 @@ func (a *Allocation) RequestResources(ctx *actor.Context) error {
 	}
 
 	a.req.AllocationRef = ctx.Self()
+	a.req.LogContext = a.logCtx
+	a.requestedAt = time.Now()
 	if err := a.rm.Allocate(ctx, a.req); err != nil {
 		return errors.Wrap(err, "failed to request allocation")
 	}
@@ -412,6 +445,8 @@ func (a *Allocation) ResourcesAllocated(ctx *actor.Context, msg sproto.Resources
 		}
 
 		a.setModelState(model.AllocationStateAssigned)
+		a.allocatedAt = time.Now()
+		prom.ObserveAllocationScheduled(a.req.ResourcePool, a.allocatedAt.Sub(a.requestedAt))
 	} else {
 		ctx.Log().Debugf("ResourcesAllocated restored state: %s", a.getModelState())
 	}
@@ -470,10 +505,17 @@ func (a *Allocation) ResourcesAllocated(ctx *actor.Context, msg sproto.Resources
 				return fmt.Errorf("starting resources (%v): %w", r, err)
 			}
 		}
+
+		if cfg := a.req.GangStart; cfg != nil && len(a.resources) > 1 {
+			actors.NotifyAfter(ctx, cfg.TimeoutDuration, gangStartTimeout{
+				AllocationID: a.model.AllocationID,
+			})
+		}
 	} else if a.getModelState() == model.AllocationStateRunning {
 		// Restore proxies.
 		for _, r := range a.resources {
-			if a.req.ProxyPort != nil && r.Started != nil && r.Started.Addresses != nil {
+			if len(a.req.ProxyPorts) > 0 && r.Rank == 0 && r.Started != nil &&
+				r.Started.Addresses != nil {
 				a.registerProxies(ctx, r.Started.Addresses)
 			}
 		}
@@ -518,13 +560,21 @@ func (a *Allocation) SetResourcesAsDaemon(
 	return nil
 }
 
-// HandleSignal handles an external signal to kill or terminate the allocation.
+// HandleSignal handles an external signal to kill, terminate or preempt the allocation.
 func (a *Allocation) HandleSignal(ctx *actor.Context, msg sproto.AllocationSignalWithReason) {
 	switch msg.AllocationSignal {
 	case sproto.KillAllocation:
 		a.Kill(ctx, msg.InformationalReason)
 	case sproto.TerminateAllocation:
 		a.Terminate(ctx, msg.InformationalReason, false)
+	case sproto.PreemptAllocation:
+		if !a.req.Preemptible {
+			if ctx.ExpectingResponse() {
+				ctx.Respond(ErrBehaviorDisabled{preemption})
+			}
+			return
+		}
+		a.Terminate(ctx, msg.InformationalReason, true)
 	}
 }
 
@@ -573,7 +623,9 @@ func (a *Allocation) ResourcesStateChanged(
 			ctx.Log().
 				Info("all containers are connected successfully (task container state changed)")
 		}
-		if a.req.ProxyPort != nil && msg.ResourcesStarted.Addresses != nil {
+		// Services of multi-container allocations are served by their chief container.
+		if len(a.req.ProxyPorts) > 0 && a.resources[msg.ResourcesID].Rank == 0 &&
+			msg.ResourcesStarted.Addresses != nil {
 			a.registerProxies(ctx, msg.ResourcesStarted.Addresses)
 		}
 
@@ -587,6 +639,10 @@ func (a *Allocation) ResourcesStateChanged(
 			a.req.AllocationRef.Address(),
 			a.req.JobID)
 		prom.AddAllocationResources(a.resources[msg.ResourcesID].Summary(), msg.ResourcesStarted)
+		if !a.allocatedAt.IsZero() {
+			prom.ObserveAllocationStarted(a.req.ResourcePool, time.Since(a.allocatedAt))
+			a.allocatedAt = time.Time{}
+		}
 
 	case sproto.Terminated:
 		if a.resources[msg.ResourcesID].Exited != nil {
@@ -795,29 +851,21 @@ func (a *Allocation) kill(ctx *actor.Context, reason string) {
 	})
 }
 
+// registerProxies registers the proxied services of the allocation at the given addresses, which
+// belong to the container serving them: the only one, or the chief of a multi-container allocation.
 func (a *Allocation) registerProxies(ctx *actor.Context, addresses []cproto.Address) {
-	cfg := a.req.ProxyPort
-	if cfg == nil {
-		return
-	}
-	if len(a.resources) > 1 {
-		// We don't support proxying multi-reservation allocations.
-		ctx.Log().Warnf("proxy for multi-reservation allocation aborted")
+	if len(a.req.ProxyPorts) == 0 {
 		return
 	}
 
-	for _, address := range addresses {
-		// Only proxy the port we expect to proxy. If a dockerfile uses an EXPOSE command,
-		// additional addresses will appear her, but currently we only proxy one uuid to one
-		// port, so it doesn't make sense to send multiple proxy.Register messages for a
-		// single ServiceID (only the last one would work).
-		if address.ContainerPort != cfg.Port {
+	proxied := proxiedAddresses(a.req.ProxyPorts, addresses)
+	for _, cfg := range a.req.ProxyPorts {
+		address, ok := proxied[cfg.ServiceID]
+		if !ok || slices.Contains(a.proxies, cfg.ServiceID) {
 			continue
 		}
-
-		// We are keying on allocation id instead of container id. Revisit this when we need to
-		// proxy multi-container tasks or when containers are created prior to being
-		// assigned to an agent.
+		// We are keying on allocation id instead of container id. Revisit this when containers
+		// are created prior to being assigned to an agent.
 		ctx.Ask(ctx.Self().System().Get(actor.Addr("proxy")), proxy.Register{
 			ServiceID: cfg.ServiceID,
 			URL: &url.URL{
@@ -826,23 +874,19 @@ func (a *Allocation) registerProxies(ctx *actor.Context, addresses []cproto.Addr
 			},
 			ProxyTCP:        cfg.ProxyTCP,
 			Unauthenticated: cfg.Unauthenticated,
+			StripPrefix:     cfg.StripPrefix,
 		})
 		a.proxies = append(a.proxies, cfg.ServiceID)
 	}
 
-	if len(a.proxies) != 1 {
-		ctx.Log().Errorf("did not proxy as expected %v (found addrs %v)", len(a.proxies), addresses)
+	if len(a.proxies) != len(a.req.ProxyPorts) {
+		ctx.Log().Errorf("did not proxy as expected %v of %v (found addrs %v)",
+			len(a.proxies), len(a.req.ProxyPorts), addresses)
 	}
 }
 
 func (a *Allocation) unregisterProxies(ctx *actor.Context) {
-	cfg := a.req.ProxyPort
-	if cfg == nil {
-		return
-	}
-
-	if len(a.resources) > 1 {
-		// Can't proxy more than one reservation, so we never would've made them.
+	if len(a.req.ProxyPorts) == 0 {
 		return
 	}
 
@@ -853,24 +897,44 @@ func (a *Allocation) unregisterProxies(ctx *actor.Context) {
 	}
 }
 
+// proxiedAddresses matches each proxied service to the first address that exposes its port. Only
+// the ports we expect to proxy are used: if a dockerfile uses an EXPOSE command, additional
+// addresses will appear, but we only proxy one ServiceID to one port.
+func proxiedAddresses(
+	cfgs []*sproto.ProxyPortConfig, addresses []cproto.Address,
+) map[string]cproto.Address {
+	proxied := map[string]cproto.Address{}
+	for _, cfg := range cfgs {
+		for _, address := range addresses {
+			if address.ContainerPort == cfg.Port {
+				proxied[cfg.ServiceID] = address
+				break
+			}
+		}
+	}
+	return proxied
+}
+
 // containerProxyAddresses forms the container address when proxyAddress is given.
 func (a *Allocation) containerProxyAddresses() []cproto.Address {
-	if a.proxyAddress == nil || a.req.ProxyPort == nil {
+	if a.proxyAddress == nil || len(a.req.ProxyPorts) == 0 {
 		return []cproto.Address{}
 	}
-	return []cproto.Address{
-		{
+	addresses := make([]cproto.Address, 0, len(a.req.ProxyPorts))
+	for _, cfg := range a.req.ProxyPorts {
+		addresses = append(addresses, cproto.Address{
 			ContainerIP:   *a.proxyAddress,
-			ContainerPort: a.req.ProxyPort.Port,
+			ContainerPort: cfg.Port,
 			HostIP:        *a.proxyAddress,
-			HostPort:      a.req.ProxyPort.Port,
-		},
+			HostPort:      cfg.Port,
+		})
 	}
+	return addresses
 }
 
 func (a *Allocation) terminated(ctx *actor.Context, reason string) {
 	a.setMostProgressedModelState(model.AllocationStateTerminated)
-	exit := &AllocationExited{FinalState: a.State()}
+	exit := &AllocationExited{FinalState: a.State(), ResizedSlots: a.resizedSlots}
 	if a.exited {
 		// Never exit twice. If this were allowed, a trial could receive two task.AllocationExited
 		// messages. On receipt of the first message, the trial awaits our exit. Once we exit, it
@@ -1011,6 +1075,32 @@ func (a *Allocation) enrichLog(log model.TaskLog) model.TaskLog {
 	return log
 }
 
+// recordGPUEnergy adds the energy that the GPUs of a container used since their power was last
+// measured, at the power just measured. Longer gaps, such as while an agent was disconnected, are
+// only counted up to maxGPUEnergyInterval.
+func (a *Allocation) recordGPUEnergy(ctx *actor.Context, msg sproto.ContainerMetrics) {
+	if msg.Metrics.GPUPowerWatts == nil {
+		return
+	}
+	if a.gpuPowerMeasuredAt == nil {
+		a.gpuPowerMeasuredAt = map[cproto.ID]time.Time{}
+	}
+	last, ok := a.gpuPowerMeasuredAt[msg.ContainerID]
+	a.gpuPowerMeasuredAt[msg.ContainerID] = msg.Metrics.Time
+	if !ok || !msg.Metrics.Time.After(last) {
+		return
+	}
+
+	interval := msg.Metrics.Time.Sub(last)
+	if interval > maxGPUEnergyInterval {
+		interval = maxGPUEnergyInterval
+	}
+	joules := *msg.Metrics.GPUPowerWatts * interval.Seconds()
+	if err := db.AddAllocationGPUEnergy(context.TODO(), a.model.AllocationID, joules); err != nil {
+		ctx.Log().WithError(err).Warn("failed to record GPU energy")
+	}
+}
+
 func (a *Allocation) sendEvent(ctx *actor.Context, ev sproto.Event) {
 	ev = a.enrichEvent(ctx, ev)
 	a.logger.Insert(ctx, a.enrichLog(ev.ToTaskLog()))
@@ -1066,7 +1156,19 @@ func (a *Allocation) State() AllocationState {
 }
 
 func (a *Allocation) setModelState(v model.AllocationState) {
+	changed := a.model.State == nil || *a.model.State != v
 	a.model.State = &v
+	if changed {
+		if err := webhooks.ReportTaskStateChanged(context.TODO(), webhooks.TaskPayload{
+			ID:           a.req.TaskID,
+			AllocationID: a.req.AllocationID,
+			Name:         a.req.Name,
+			State:        v,
+		}, a.req.WorkspaceID); err != nil {
+			logrus.WithFields(a.logCtx.Fields()).WithError(err).
+				Error("failed to send task state change webhook")
+		}
+	}
 }
 
 func (a *Allocation) setMostProgressedModelState(v model.AllocationState) {
@@ -21,6 +21,7 @@ import (
 
 	"github.com/determined-ai/determined/master/internal/db"
 	"github.com/determined-ai/determined/master/internal/mocks"
+	"github.com/determined-ai/determined/master/internal/proxy"
 	"github.com/determined-ai/determined/master/internal/rm"
 	"github.com/determined-ai/determined/master/internal/rm/allocationmap"
 	"github.com/determined-ai/determined/master/internal/sproto"
@@ -265,6 +266,270 @@ func TestAllocationAllGather(t *testing.T) {
 	}
 }
 
+func TestAllocationProxiesMultiContainer(t *testing.T) {
+	system, _, rm, trialImpl, _, _, a, self := setup(t)
+	proxyImpl := actors.MockActor{Responses: map[string]*actors.MockResponse{}}
+	system.MustActorOf(actor.Addr("proxy"), &proxyImpl)
+	a.req.ProxyPorts = []*sproto.ProxyPortConfig{{ServiceID: "dashboard", Port: 8265}}
+
+	mockRsvn := func(rID sproto.ResourcesID, agentID string) sproto.Resources {
+		rsrv := &mocks.Resources{}
+		rsrv.On("Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).Times(1)
+		rsrv.On("Summary").Return(sproto.ResourcesSummary{
+			AllocationID:  a.req.AllocationID,
+			ResourcesID:   rID,
+			ResourcesType: sproto.ResourcesTypeDockerContainer,
+			AgentDevices:  map[aproto.ID][]device.Device{aproto.ID(agentID): nil},
+		})
+		rsrv.On("Kill", mock.Anything, mock.Anything).Return()
+		return rsrv
+	}
+	rID1, rID2 := sproto.ResourcesID(cproto.NewID()), sproto.ResourcesID(cproto.NewID())
+	resources := map[sproto.ResourcesID]sproto.Resources{
+		rID1: mockRsvn(rID1, "agent-1"),
+		rID2: mockRsvn(rID2, "agent-2"),
+	}
+	trialImpl.Expect(fmt.Sprintf("%T", BuildTaskSpec{}), actors.MockResponse{
+		Msg: tasks.TaskSpec{},
+	})
+	require.NoError(t, system.Ask(rm.Ref(), actors.ForwardThroughMock{
+		To: self,
+		Msg: sproto.ResourcesAllocated{
+			ID:           a.req.AllocationID,
+			ResourcePool: "default",
+			Resources:    resources,
+		},
+	}).Error())
+	system.Ask(rm.Ref(), actors.ForwardThroughMock{To: self, Msg: actor.Ping{}}).Get()
+
+	// Every container exposes the port, but the service is only proxied to the chief.
+	for rID := range resources {
+		hostIP := fmt.Sprintf("10.0.0.%d", a.resources[rID].Rank+1)
+		require.NoError(t, system.Ask(self, sproto.ResourcesStateChanged{
+			ResourcesID:    rID,
+			ResourcesState: sproto.Running,
+			ResourcesStarted: &sproto.ResourcesStarted{
+				Addresses: []cproto.Address{
+					{ContainerPort: 8265, HostIP: hostIP, HostPort: 30000},
+				},
+			},
+		}).Error())
+	}
+	system.Ask(self, actor.Ping{}).Get()
+	var registered []proxy.Register
+	for _, m := range proxyImpl.Messages {
+		if msg, ok := m.(proxy.Register); ok {
+			registered = append(registered, msg)
+		}
+	}
+	require.Len(t, registered, 1)
+	require.Equal(t, "dashboard", registered[0].ServiceID)
+	require.Equal(t, "10.0.0.1:30000", registered[0].URL.Host)
+
+	// The service is unregistered when the allocation exits.
+	for rID := range resources {
+		require.NoError(t, system.Ask(self, sproto.ResourcesStateChanged{
+			ResourcesID:      rID,
+			ResourcesState:   sproto.Terminated,
+			ResourcesStopped: &sproto.ResourcesStopped{},
+		}).Error())
+	}
+	system.Ask(rm.Ref(), actors.ForwardThroughMock{To: self, Msg: actor.Ping{}}).Get()
+	require.NoError(t, self.AwaitTermination())
+	system.Ask(system.Get(actor.Addr("proxy")), actor.Ping{}).Get()
+	require.Contains(t, proxyImpl.Messages, proxy.Unregister{ServiceID: "dashboard"})
+}
+
+func TestAllocationPreemptSignal(t *testing.T) {
+	system, _, rm, trialImpl, _, _, a, self := setup(t) //nolint: dogsled
+
+	rID := sproto.ResourcesID(cproto.NewID())
+	rsrv := &mocks.Resources{}
+	rsrv.On("Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+	rsrv.On("Summary").Return(sproto.ResourcesSummary{
+		AllocationID:  a.req.AllocationID,
+		ResourcesID:   rID,
+		ResourcesType: sproto.ResourcesTypeDockerContainer,
+		AgentDevices:  map[aproto.ID][]device.Device{"agent-1": nil},
+	})
+	rsrv.On("Kill", mock.Anything, mock.Anything).Return()
+	trialImpl.Expect(fmt.Sprintf("%T", BuildTaskSpec{}), actors.MockResponse{
+		Msg: tasks.TaskSpec{},
+	})
+	require.NoError(t, system.Ask(rm.Ref(), actors.ForwardThroughMock{
+		To: self,
+		Msg: sproto.ResourcesAllocated{
+			ID:           a.req.AllocationID,
+			ResourcePool: "default",
+			Resources:    map[sproto.ResourcesID]sproto.Resources{rID: rsrv},
+		},
+	}).Error())
+	system.Ask(rm.Ref(), actors.ForwardThroughMock{To: self, Msg: actor.Ping{}}).Get()
+
+	resp := system.Ask(self, WatchPreemption{AllocationID: a.req.AllocationID, ID: uuid.New()})
+	require.NoError(t, resp.Error())
+	require.IsType(t, PreemptionWatcher{}, resp.Get())
+	w := resp.Get().(PreemptionWatcher)
+
+	// Preempting an allocation that cannot be preempted is refused.
+	a.req.Preemptible = false
+	resp = system.Ask(self, sproto.AllocationSignalWithReason{
+		AllocationSignal:    sproto.PreemptAllocation,
+		InformationalReason: "preempted by an administrator",
+	})
+	require.Equal(t, ErrBehaviorDisabled{preemption}, resp.Error())
+	select {
+	case <-w.C:
+		t.Fatal("allocation was preempted but should not have been")
+	default:
+	}
+
+	// Otherwise, the allocation is asked to checkpoint and exit rather than being killed.
+	a.req.Preemptible = true
+	system.Tell(self, sproto.AllocationSignalWithReason{
+		AllocationSignal:    sproto.PreemptAllocation,
+		InformationalReason: "preempted by an administrator",
+	})
+	system.Ask(self, actor.Ping{}).Get()
+	select {
+	case <-w.C:
+	default:
+		t.Fatal("allocation was not preempted")
+	}
+	rsrv.AssertNotCalled(t, "Kill", mock.Anything, mock.Anything)
+}
+
+func TestAllocationGangStartTimeout(t *testing.T) {
+	cases := []struct {
+		name     string
+		degraded bool
+		started  bool
+		requeued bool
+	}{
+		{name: "requeue", started: true, requeued: true},
+		{name: "degraded", degraded: true, started: true},
+		{name: "degraded, nothing started", degraded: true, requeued: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			system, rmImpl, rm, trialImpl, trial, _, a, self := setup(t)
+			a.req.GangStart = &sproto.GangStartConfig{
+				TimeoutDuration: time.Hour,
+				Degraded:        tc.degraded,
+			}
+
+			mockRsvn := func(rID sproto.ResourcesID, agentID string) *mocks.Resources {
+				rsrv := &mocks.Resources{}
+				rsrv.On("Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).Times(1)
+				rsrv.On("Summary").Return(sproto.ResourcesSummary{
+					AllocationID:  a.req.AllocationID,
+					ResourcesID:   rID,
+					ResourcesType: sproto.ResourcesTypeDockerContainer,
+					AgentDevices:  map[aproto.ID][]device.Device{aproto.ID(agentID): nil},
+				})
+				rsrv.On("Kill", mock.Anything, mock.Anything).Return()
+				return rsrv
+			}
+			rID1, rID2 := sproto.ResourcesID(cproto.NewID()), sproto.ResourcesID(cproto.NewID())
+			mocked := map[sproto.ResourcesID]*mocks.Resources{
+				rID1: mockRsvn(rID1, "agent-1"),
+				rID2: mockRsvn(rID2, "agent-2"),
+			}
+			resources := map[sproto.ResourcesID]sproto.Resources{}
+			for id, r := range mocked {
+				resources[id] = r
+			}
+			trialImpl.Expect(fmt.Sprintf("%T", BuildTaskSpec{}), actors.MockResponse{
+				Msg: tasks.TaskSpec{},
+			})
+			require.NoError(t, system.Ask(rm.Ref(), actors.ForwardThroughMock{
+				To: self,
+				Msg: sproto.ResourcesAllocated{
+					ID:           a.req.AllocationID,
+					ResourcePool: "default",
+					Resources:    resources,
+				},
+			}).Error())
+			system.Ask(rm.Ref(), actors.ForwardThroughMock{To: self, Msg: actor.Ping{}}).Get()
+
+			// The resources ranked last start and wait to rendezvous, while the others never do.
+			started, stuck := rID1, rID2
+			if a.resources[rID2].Rank > a.resources[rID1].Rank {
+				started, stuck = rID2, rID1
+			}
+			var w RendezvousWatcher
+			if tc.started {
+				require.NoError(t, system.Ask(self, sproto.ResourcesStateChanged{
+					ResourcesID:    started,
+					ResourcesState: sproto.Running,
+					ResourcesStarted: &sproto.ResourcesStarted{
+						Addresses: []cproto.Address{{ContainerPort: 1734, HostIP: "10.0.0.1"}},
+					},
+				}).Error())
+				resp := system.Ask(self, WatchRendezvousInfo{ResourcesID: started})
+				require.NoError(t, resp.Error())
+				w = resp.Get().(RendezvousWatcher)
+			}
+
+			require.NoError(t, system.Ask(self, gangStartTimeout{
+				AllocationID: a.model.AllocationID,
+			}).Error())
+			system.Ask(rm.Ref(), actor.Ping{}).Get()
+
+			if !tc.requeued {
+				// The stragglers are released and the rest go on without them.
+				mocked[stuck].AssertCalled(t, "Kill", mock.Anything, mock.Anything)
+				mocked[started].AssertNotCalled(t, "Kill", mock.Anything, mock.Anything)
+				require.Contains(t, rmImpl.Messages, sproto.ResourcesReleased{
+					AllocationRef: self,
+					ResourcesID:   &stuck,
+				})
+				require.Len(t, a.resources, 1)
+				require.Equal(t, 0, a.resources[started].Rank)
+				require.Nil(t, a.killCooldown)
+
+				// The rendezvous that was waiting on the stragglers goes ahead with the rest.
+				require.True(t, a.rendezvous.ready())
+				select {
+				case info := <-w.C:
+					require.NoError(t, info.Err)
+					require.Len(t, info.Info.Addresses, 1)
+					require.Equal(t, int32(0), info.Info.Rank)
+				default:
+					t.Fatal("rendezvous did not go ahead with the started resources")
+				}
+				return
+			}
+
+			// Everything is released, so that the parent requeues the allocation.
+			for _, r := range mocked {
+				r.AssertCalled(t, "Kill", mock.Anything, mock.Anything)
+			}
+			require.NotNil(t, a.killCooldown)
+			for id := range resources {
+				require.NoError(t, system.Ask(self, sproto.ResourcesStateChanged{
+					ResourcesID:      id,
+					ResourcesState:   sproto.Terminated,
+					ResourcesStopped: &sproto.ResourcesStopped{},
+				}).Error())
+			}
+			system.Ask(rm.Ref(), actors.ForwardThroughMock{To: self, Msg: actor.Ping{}}).Get()
+			require.NoError(t, self.AwaitTermination())
+			system.Ask(trial, actor.Ping{}).Get()
+			for _, m := range trialImpl.Messages {
+				if exit, ok := m.(*AllocationExited); ok {
+					exit.FinalState = AllocationState{}
+				}
+			}
+			require.Contains(t, trialImpl.Messages, &AllocationExited{})
+		})
+	}
+}
+
 func setup(t *testing.T) (
 	*actor.System, *actors.MockActor, rm.ResourceManager, *actors.MockActor,
 	*actor.Ref, *db.PgDB, *Allocation, *actor.Ref,
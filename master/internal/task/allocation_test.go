@@ -0,0 +1,34 @@
+package task
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/cproto"
+)
+
+func TestProxiedAddresses(t *testing.T) {
+	cfgs := []*sproto.ProxyPortConfig{
+		{ServiceID: "notebook", Port: 8888},
+		{ServiceID: "dashboard", Port: 8265},
+		{ServiceID: "missing", Port: 5000},
+	}
+	notebook := cproto.Address{ContainerPort: 8888, HostIP: "10.0.0.1", HostPort: 30001}
+	dashboard := cproto.Address{ContainerPort: 8265, HostIP: "10.0.0.1", HostPort: 30002}
+	addresses := []cproto.Address{
+		// Ports exposed by the image but not proxied are ignored.
+		{ContainerPort: 22, HostIP: "10.0.0.1", HostPort: 30000},
+		notebook,
+		dashboard,
+		// Only the first address of a port is proxied.
+		{ContainerPort: 8888, HostIP: "10.0.0.1", HostPort: 30003},
+	}
+
+	assert.DeepEqual(t, proxiedAddresses(cfgs, addresses), map[string]cproto.Address{
+		"notebook":  notebook,
+		"dashboard": dashboard,
+	})
+	assert.DeepEqual(t, proxiedAddresses(cfgs, nil), map[string]cproto.Address{})
+}
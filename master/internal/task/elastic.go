@@ -0,0 +1,55 @@
+package task
+
+import (
+	"fmt"
+
+	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+)
+
+// GetResize requests the number of slots an elastic allocation has been asked to resize to. The
+// response is an *int, which is nil if no resize is pending or the allocation is not elastic.
+type GetResize struct{ AllocationID model.AllocationID }
+
+// Resize handles a request from the resource manager to change the size of an elastic
+// allocation. The allocation is gracefully preempted, so the task can checkpoint, and the new
+// size is both made available to the task through GetResize and reported to the parent on exit
+// so it can request resources accordingly. Only the agent resource manager sends resizes; under
+// Kubernetes, elastic allocations simply keep the size they were scheduled with.
+func (a *Allocation) Resize(ctx *actor.Context, msg sproto.ResizeAllocation) {
+	cfg := a.req.Elastic
+	switch {
+	case cfg == nil, !a.req.Preemptible:
+		ctx.Log().Warnf("ignoring resize of non-elastic allocation to %d slots", msg.Slots)
+		return
+	case msg.AllocationID != a.model.AllocationID, a.exited, a.resizedSlots != nil:
+		return
+	case msg.Slots == a.req.SlotsNeeded:
+		return
+	case msg.Slots < cfg.MinSlots || msg.Slots > cfg.MaxSlots:
+		ctx.Log().Warnf("ignoring resize to %d slots outside of elastic range [%d, %d]",
+			msg.Slots, cfg.MinSlots, cfg.MaxSlots)
+		return
+	}
+
+	a.resizedSlots = &msg.Slots
+	reason := fmt.Sprintf("elastic resize from %d to %d slots", a.req.SlotsNeeded, msg.Slots)
+	a.logger.Insert(ctx, a.enrichLog(model.TaskLog{
+		Level: ptrs.Ptr(model.LogLevelInfo),
+		Log:   reason,
+	}))
+	a.Terminate(ctx, reason, true)
+}
+
+func (a *Allocation) getResize(ctx *actor.Context, msg GetResize) {
+	switch {
+	case msg.AllocationID != a.model.AllocationID:
+		ctx.Respond(ErrStaleAllocation{Received: msg.AllocationID, Actual: a.model.AllocationID})
+	case a.req.Elastic == nil:
+		ctx.Respond((*int)(nil))
+	default:
+		ctx.Respond(a.resizedSlots)
+	}
+}
@@ -0,0 +1,77 @@
+package task
+
+import (
+	"fmt"
+
+	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+)
+
+// gangStartTimeout tracks the timeout of a multi-container allocation waiting for all of its
+// resources to start. On a busy cluster (most commonly Kubernetes, where pods are scheduled
+// individually) some resources may start while the rest wait indefinitely, holding slots that
+// other work could use; this lets the allocation bail out of that state.
+type gangStartTimeout struct{ AllocationID model.AllocationID }
+
+// GangStartTimeout handles the gang start timeout firing. If all resources have started, it is a
+// no-op. Otherwise, depending on the configured policy, it either kills the allocation so it can
+// be requeued by its parent or releases the resources that have not started and continues with
+// the rest.
+func (a *Allocation) GangStartTimeout(ctx *actor.Context, msg gangStartTimeout) {
+	cfg := a.req.GangStart
+	switch {
+	case cfg == nil, msg.AllocationID != a.model.AllocationID:
+		return
+	case a.exited, a.killCooldown != nil, len(a.resources.exited()) > 0:
+		// Already on the way out, nothing to do.
+		return
+	}
+
+	started := a.resources.started()
+	if len(started) == len(a.resources) {
+		return
+	}
+
+	if !cfg.Degraded || len(started) == 0 {
+		a.logger.Insert(ctx, a.enrichLog(model.TaskLog{
+			Level: ptrs.Ptr(model.LogLevelWarning),
+			Log: fmt.Sprintf(
+				"%d of %d resources did not start within %s, releasing all resources and requeueing",
+				len(a.resources)-len(started), len(a.resources), cfg.TimeoutDuration,
+			),
+		}))
+		a.Kill(ctx, "gang start timeout exceeded")
+		return
+	}
+
+	a.logger.Insert(ctx, a.enrichLog(model.TaskLog{
+		Level: ptrs.Ptr(model.LogLevelWarning),
+		Log: fmt.Sprintf(
+			"%d of %d resources did not start within %s, continuing with the started resources",
+			len(a.resources)-len(started), len(a.resources), cfg.TimeoutDuration,
+		),
+	}))
+	for id, r := range a.resources {
+		if r.Started != nil {
+			continue
+		}
+		id := id
+		r.Kill(ctx, a.logCtx)
+		a.rm.Release(ctx, sproto.ResourcesReleased{
+			AllocationRef: ctx.Self(),
+			ResourcesID:   &id,
+		})
+		delete(a.resources, id)
+	}
+
+	if err := a.resources.rerank(); err != nil {
+		a.Error(ctx, err)
+		return
+	}
+
+	if a.rendezvous != nil && a.rendezvous.try() {
+		ctx.Log().Info("all containers are connected successfully (gang start degraded)")
+	}
+}
@@ -0,0 +1,78 @@
+package task
+
+import (
+	"sync"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// LogNotifier is a log backend that tells subscribers when logs are added for a task, so that
+// followers of a task's logs can wait for new logs instead of polling the backend for them.
+// Only logs added through this master are noticed; logs that are shipped to the backend directly
+// must still be polled for.
+type LogNotifier struct {
+	LogBackend
+
+	mu          sync.Mutex
+	subscribers map[model.TaskID]map[chan struct{}]struct{}
+}
+
+// NewLogNotifier wraps a task log backend with notifications of new logs.
+func NewLogNotifier(backend LogBackend) *LogNotifier {
+	return &LogNotifier{
+		LogBackend:  backend,
+		subscribers: map[model.TaskID]map[chan struct{}]struct{}{},
+	}
+}
+
+// AddTaskLogs stores the logs in the wrapped backend and then notifies the subscribers of each
+// task that the logs were for.
+func (n *LogNotifier) AddTaskLogs(logs []*model.TaskLog) error {
+	if err := n.LogBackend.AddTaskLogs(logs); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.subscribers) == 0 {
+		return nil
+	}
+	notified := map[model.TaskID]bool{}
+	for _, l := range logs {
+		taskID := model.TaskID(l.TaskID)
+		if notified[taskID] {
+			continue
+		}
+		notified[taskID] = true
+		for ch := range n.subscribers[taskID] {
+			// Notifications are coalesced, since a subscriber reads every new log at once anyway.
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+	return nil
+}
+
+// Subscribe returns a channel that receives a value whenever logs are added for the task after
+// the call, and a function to stop receiving them that must be called when done.
+func (n *LogNotifier) Subscribe(taskID model.TaskID) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.subscribers[taskID] == nil {
+		n.subscribers[taskID] = map[chan struct{}]struct{}{}
+	}
+	n.subscribers[taskID][ch] = struct{}{}
+
+	return ch, func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		delete(n.subscribers[taskID], ch)
+		if len(n.subscribers[taskID]) == 0 {
+			delete(n.subscribers, taskID)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package task
+
+import (
+	"fmt"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// discardLogBackend is a log backend that drops logs, or fails to add them if err is set.
+type discardLogBackend struct {
+	LogBackend
+	err error
+}
+
+func (b discardLogBackend) AddTaskLogs([]*model.TaskLog) error {
+	return b.err
+}
+
+func notified(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestLogNotifier(t *testing.T) {
+	n := NewLogNotifier(discardLogBackend{})
+	a, unsubscribeA := n.Subscribe("a")
+	b, unsubscribeB := n.Subscribe("b")
+	defer unsubscribeB()
+
+	// Notifications are coalesced and only go to subscribers of the tasks the logs are for.
+	assert.NilError(t, n.AddTaskLogs([]*model.TaskLog{{TaskID: "a"}, {TaskID: "a"}}))
+	assert.NilError(t, n.AddTaskLogs([]*model.TaskLog{{TaskID: "a"}}))
+	assert.Assert(t, notified(a))
+	assert.Assert(t, !notified(a))
+	assert.Assert(t, !notified(b))
+
+	unsubscribeA()
+	assert.NilError(t, n.AddTaskLogs([]*model.TaskLog{{TaskID: "a"}, {TaskID: "b"}}))
+	assert.Assert(t, !notified(a))
+	assert.Assert(t, notified(b))
+	assert.Equal(t, len(n.subscribers), 1)
+
+	// Logs that fail to be stored are not notified.
+	n.LogBackend = discardLogBackend{err: fmt.Errorf("failed")}
+	assert.ErrorContains(t, n.AddTaskLogs([]*model.TaskLog{{TaskID: "b"}}), "failed")
+	assert.Assert(t, !notified(b))
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/determined-ai/determined/master/internal/api"
+	"github.com/determined-ai/determined/master/internal/prom"
 	"github.com/determined-ai/determined/master/pkg/actor"
 	"github.com/determined-ai/determined/master/pkg/actor/actors"
 	"github.com/determined-ai/determined/master/pkg/model"
@@ -27,6 +28,8 @@ type (
 	// NotifyAfter(), which is used to guarantee that logs are not held too
 	// long without flushing.
 	flushLogs struct{}
+	// forceFlushLogs asks the logger to flush the logs it holds right away.
+	forceFlushLogs struct{}
 )
 
 // LogBackend is an interface task log backends, such as elastic or postgres,
@@ -39,12 +42,31 @@ type LogBackend interface {
 	TaskLogsCount(taskID model.TaskID, filters []api.Filter) (int, error)
 	TaskLogsFields(taskID model.TaskID) (*apiv1.TaskLogsFieldsResponse, error)
 	DeleteTaskLogs(taskIDs []model.TaskID) error
+	// ValidateTaskLogsRegex returns an error wrapping api.ErrInvalid if the pattern is not a regular
+	// expression that logs can be searched with. Each backend has its own syntax.
+	ValidateTaskLogsRegex(pattern string) error
 	// MaxTerminationDelay is the max delay before a consumer can be sure all logs have been
 	// recevied. A better interface may be an interface for streaming, rather than helper
 	// interfaces to aid streaming, but it's not bad enough to motivate changing it.
 	MaxTerminationDelay() time.Duration
 }
 
+// meteredLogBackend is a log backend that counts the logs it stores.
+type meteredLogBackend struct {
+	LogBackend
+}
+
+// NewMeteredLogBackend wraps a log backend to export how many logs it stores as metrics.
+func NewMeteredLogBackend(backend LogBackend) LogBackend {
+	return meteredLogBackend{LogBackend: backend}
+}
+
+func (b meteredLogBackend) AddTaskLogs(logs []*model.TaskLog) error {
+	err := b.LogBackend.AddTaskLogs(logs)
+	prom.ObserveTaskLogsIngested(len(logs), err)
+	return err
+}
+
 type (
 	logger struct {
 		backend      LogBackend
@@ -84,6 +106,11 @@ func (l *Logger) Insert(ctx *actor.Context, tl model.TaskLog) {
 	ctx.Tell(l.inner, tl)
 }
 
+// Flush writes the logs that are buffered to the backend, waiting for at most the timeout.
+func (l *Logger) Flush(timeout time.Duration) {
+	l.inner.System().Ask(l.inner, forceFlushLogs{}).GetOrTimeout(timeout)
+}
+
 func (l *logger) Receive(ctx *actor.Context) error {
 	switch msg := ctx.Message().(type) {
 	case actor.PreStart:
@@ -93,6 +120,9 @@ func (l *logger) Receive(ctx *actor.Context) error {
 		l.tryFlushLogs(ctx, true)
 		actors.NotifyAfter(ctx, logFlushInterval, flushLogs{})
 
+	case forceFlushLogs:
+		l.tryFlushLogs(ctx, true)
+
 	case model.TaskLog:
 		l.pending = append(l.pending, &msg)
 		l.tryFlushLogs(ctx, false)
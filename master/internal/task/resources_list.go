@@ -1,6 +1,10 @@
 package task
 
 import (
+	"sort"
+
+	"golang.org/x/exp/maps"
+
 	"github.com/determined-ai/determined/master/internal/sproto"
 	"github.com/determined-ai/determined/master/internal/task/taskmodel"
 	"github.com/determined-ai/determined/master/pkg/device"
@@ -25,6 +29,25 @@ func (rs resourcesList) append(ars map[sproto.ResourcesID]sproto.Resources) erro
 	return nil
 }
 
+// rerank reassigns contiguous ranks, starting at zero, to the resources while preserving their
+// relative order. It is used when resources are dropped from a running allocation.
+func (rs resourcesList) rerank() error {
+	ids := maps.Keys(rs)
+	sort.Slice(ids, func(i, j int) bool {
+		return rs[ids[i]].Rank < rs[ids[j]].Rank
+	})
+	for rank, id := range ids {
+		if rs[id].Rank == rank {
+			continue
+		}
+		rs[id].Rank = rank
+		if err := rs[id].Persist(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (rs resourcesList) first() *taskmodel.ResourcesWithState {
 	for _, r := range rs {
 		return r
@@ -8,9 +8,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/determined-ai/determined/master/internal/config"
 	"github.com/determined-ai/determined/master/internal/prom"
 	"github.com/determined-ai/determined/master/internal/rm"
 	"github.com/determined-ai/determined/master/internal/task"
+	"github.com/determined-ai/determined/master/internal/user"
 
 	"github.com/determined-ai/determined/master/pkg/actor/actors"
 	"github.com/determined-ai/determined/master/pkg/logger"
@@ -21,6 +23,7 @@ import (
 
 	"github.com/determined-ai/determined/master/internal/db"
 	"github.com/determined-ai/determined/master/internal/sproto"
+	"github.com/determined-ai/determined/master/internal/stream"
 	"github.com/determined-ai/determined/master/pkg/actor"
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/master/pkg/schemas"
@@ -78,6 +81,9 @@ type trial struct {
 	// it effectively invalidates many outstanding messages associated with the previous run.
 	runID int
 
+	// elasticSlots is the size an elastic trial was last resized to, if it has been.
+	elasticSlots *int
+
 	// a ref to the current allocation
 	allocation *actor.Ref
 	// a note of the user initated exit reason, if any.
@@ -181,6 +187,10 @@ func (t *trial) Receive(ctx *actor.Context) error {
 		return t.patchState(ctx, model.StateWithReason{State: msg})
 	case model.StateWithReason:
 		return t.patchState(ctx, msg)
+	case trialWarmStart:
+		if !t.idSet {
+			t.warmStartCheckpoint = msg.checkpoint
+		}
 	case trialSearcherState:
 		t.searcher = msg
 		switch {
@@ -200,6 +210,20 @@ func (t *trial) Receive(ctx *actor.Context) error {
 		if t.allocation != nil {
 			ctx.Tell(t.allocation, msg)
 		}
+	case sproto.AllocationSignalWithReason:
+		if t.allocation == nil {
+			if ctx.ExpectingResponse() {
+				ctx.Respond(task.ErrNoAllocation{Action: string(msg.AllocationSignal)})
+			}
+			return nil
+		}
+		if !ctx.ExpectingResponse() {
+			ctx.Tell(t.allocation, msg)
+			return nil
+		}
+		if err := ctx.Ask(t.allocation, msg).Error(); err != nil {
+			ctx.Respond(err)
+		}
 	case task.BuildTaskSpec:
 		if spec, err := t.buildTaskSpec(ctx); err != nil {
 			ctx.Respond(err)
@@ -278,6 +302,9 @@ func (t *trial) maybeAllocateTask(ctx *actor.Context) error {
 	if err != nil {
 		ctx.Log().WithError(err).Warn("failed to restore trial allocation")
 	} else if restoredAllocation != nil {
+		if t.config.Resources().Elastic() != nil {
+			t.elasticSlots = &restoredAllocation.Slots
+		}
 		ar := sproto.AllocateRequest{
 			AllocationID:      restoredAllocation.AllocationID,
 			TaskID:            t.taskID,
@@ -287,14 +314,21 @@ func (t *trial) maybeAllocateTask(ctx *actor.Context) error {
 			Name:              name,
 			AllocationRef:     ctx.Self(),
 			Group:             ctx.Self().Parent(),
-			SlotsNeeded:       t.config.Resources().SlotsPerTrial(),
+			UserID:            t.taskSpec.OwnerID(),
+			WorkspaceID:       t.taskSpec.WorkspaceID,
+			SlotsNeeded:       t.slotsNeeded(),
 			AgentLabel:        t.config.Resources().AgentLabel(),
+			LabelConstraints:  t.config.Resources().LabelConstraints(),
 			ResourcePool:      t.config.Resources().ResourcePool(),
 			FittingRequirements: sproto.FittingRequirements{
 				SingleAgent: false,
 			},
+			ExpectedRuntime: sproto.ExpectedRuntimeFromSeconds(
+				t.config.Resources().ExpectedRuntimeSeconds()),
 
 			Preemptible: true,
+			Elastic:     t.elasticConfig(),
+			ProxyPorts:  sproto.ServiceProxyPorts(t.taskID, t.config.Environment().ProxyPorts()),
 			Restore:     true,
 		}
 		ctx.Log().
@@ -321,15 +355,23 @@ func (t *trial) maybeAllocateTask(ctx *actor.Context) error {
 		Name:              name,
 		AllocationRef:     ctx.Self(),
 		Group:             ctx.Self().Parent(),
+		UserID:            t.taskSpec.OwnerID(),
+		WorkspaceID:       t.taskSpec.WorkspaceID,
 
-		SlotsNeeded:  t.config.Resources().SlotsPerTrial(),
-		AgentLabel:   t.config.Resources().AgentLabel(),
-		ResourcePool: t.config.Resources().ResourcePool(),
+		SlotsNeeded:      t.slotsNeeded(),
+		AgentLabel:       t.config.Resources().AgentLabel(),
+		LabelConstraints: t.config.Resources().LabelConstraints(),
+		ResourcePool:     t.config.Resources().ResourcePool(),
 		FittingRequirements: sproto.FittingRequirements{
 			SingleAgent: false,
 		},
+		ExpectedRuntime: sproto.ExpectedRuntimeFromSeconds(
+			t.config.Resources().ExpectedRuntimeSeconds()),
 
 		Preemptible: true,
+		GangStart:   gangStartConfig(t.config.Resources().ResourcePool()),
+		Elastic:     t.elasticConfig(),
+		ProxyPorts:  sproto.ServiceProxyPorts(t.taskID, t.config.Environment().ProxyPorts()),
 	}
 
 	ctx.Log().
@@ -343,6 +385,48 @@ func (t *trial) maybeAllocateTask(ctx *actor.Context) error {
 	return nil
 }
 
+// gangStartConfig returns the gang start behavior configured for the resource pool, if any.
+func gangStartConfig(rpName string) *sproto.GangStartConfig {
+	cfg := config.ReadGangStartConfig(rpName)
+	if cfg == nil {
+		return nil
+	}
+	return &sproto.GangStartConfig{
+		TimeoutDuration: time.Duration(cfg.Timeout),
+		Degraded:        cfg.Policy == config.GangStartPolicyDegraded,
+	}
+}
+
+// slotsNeeded returns the number of slots the next allocation should request. Elastic trials
+// start at slots_per_trial, clamped to their elastic range, and then follow their resizes.
+func (t *trial) slotsNeeded() int {
+	slots := t.config.Resources().SlotsPerTrial()
+	elastic := t.config.Resources().Elastic()
+	switch {
+	case elastic == nil:
+		return slots
+	case t.elasticSlots != nil:
+		return *t.elasticSlots
+	case slots < elastic.MinSlotsPerTrial():
+		return elastic.MinSlotsPerTrial()
+	case slots > elastic.MaxSlotsPerTrial():
+		return elastic.MaxSlotsPerTrial()
+	default:
+		return slots
+	}
+}
+
+func (t *trial) elasticConfig() *sproto.ElasticConfig {
+	elastic := t.config.Resources().Elastic()
+	if elastic == nil {
+		return nil
+	}
+	return &sproto.ElasticConfig{
+		MinSlots: elastic.MinSlotsPerTrial(),
+		MaxSlots: elastic.MaxSlotsPerTrial(),
+	}
+}
+
 const (
 	// InvalidHPKillDelay the delay before we forcibly kill a trial that said it had an invalid HP.
 	InvalidHPKillDelay = 10 * time.Second
@@ -430,13 +514,46 @@ func (t *trial) buildTaskSpec(ctx *actor.Context) (tasks.TaskSpec, error) {
 		stepsCompleted = latestCheckpoint.StepsCompleted
 	}
 
+	// Elastic trials may be running with a different number of slots than they were configured
+	// with, so the harness should see the size it is actually running at.
+	conf := schemas.Copy(t.config).(expconf.ExperimentConfig)
+	if conf.Resources().Elastic() != nil {
+		resources := conf.Resources()
+		resources.SetSlotsPerTrial(t.slotsNeeded())
+		conf.SetResources(resources)
+	}
+
+	// Record what determines the order of the data this run trains on, so it can be replayed.
+	runState := &model.TrialRunState{
+		TrialID:        t.id,
+		RunID:          t.runID,
+		TrialSeed:      int64(t.searcher.Create.TrialSeed),
+		Slots:          t.slotsNeeded(),
+		StepsCompleted: stepsCompleted,
+	}
+	if latestCheckpoint != nil {
+		runState.CheckpointUUID = latestCheckpoint.UUID
+	}
+	if err := t.db.AddTrialRunState(runState); err != nil {
+		return tasks.TaskSpec{}, err
+	}
+
+	base := *t.taskSpec
+	if ownerID := t.taskSpec.OwnerID(); ownerID != nil {
+		authorizedKeys, err := user.AuthorizedSSHKeys(context.TODO(), *ownerID, true)
+		if err != nil {
+			return tasks.TaskSpec{}, errors.Wrap(err, "failed to get SSH keys for trial")
+		}
+		base.AuthorizedKeys = authorizedKeys
+	}
+
 	return tasks.TrialSpec{
-		Base: *t.taskSpec,
+		Base: base,
 
 		ExperimentID:     t.experimentID,
 		TrialID:          t.id,
 		TrialRunID:       t.runID,
-		ExperimentConfig: schemas.Copy(t.config).(expconf.ExperimentConfig),
+		ExperimentConfig: conf,
 		HParams:          t.searcher.Create.Hparams,
 		TrialSeed:        t.searcher.Create.TrialSeed,
 		StepsCompleted:   stepsCompleted,
@@ -450,6 +567,10 @@ func (t *trial) allocationExited(ctx *actor.Context, exit *task.AllocationExited
 		ctx.Log().WithError(err).Error("trial allocation failed")
 	}
 	t.allocation = nil
+	if exit.ResizedSlots != nil {
+		ctx.Log().Infof("trial resized to %d slots", *exit.ResizedSlots)
+		t.elasticSlots = exit.ResizedSlots
+	}
 
 	prom.DisassociateJobExperiment(t.jobID, strconv.Itoa(t.experimentID), t.config.Labels())
 
@@ -570,6 +691,7 @@ func (t *trial) transition(ctx *actor.Context, s model.StateWithReason) error {
 			if err := t.db.UpdateTrial(t.id, s.State); err != nil {
 				return errors.Wrap(err, "updating trial with end state")
 			}
+			stream.TrialStateChanged(t.experimentID, t.id, s.State)
 		}
 		t.state = s.State
 	}
@@ -53,6 +53,7 @@ func TestTrial(t *testing.T) {
 	db.On("AddTrial", mock.Anything).Return(nil)
 	db.On("UpdateTrialRunID", 0, 1).Return(nil)
 	db.On("LatestCheckpointForTrial", 0).Return(&model.Checkpoint{}, nil)
+	db.On("AddTrialRunState", mock.Anything).Return(nil)
 	require.NoError(t, system.Ask(tr.allocation, actors.ForwardThroughMock{
 		To:  self,
 		Msg: task.BuildTaskSpec{},
@@ -110,6 +111,7 @@ func TestTrialRestarts(t *testing.T) {
 		}
 		db.On("UpdateTrialRunID", 0, i+1).Return(nil)
 		db.On("LatestCheckpointForTrial", 0).Return(&model.Checkpoint{}, nil)
+		db.On("AddTrialRunState", mock.Anything).Return(nil)
 		require.NoError(t, system.Ask(tr.allocation, actors.ForwardThroughMock{
 			To:  self,
 			Msg: task.BuildTaskSpec{},
@@ -241,3 +243,39 @@ func setup(t *testing.T) (*actor.System, *mocks.DB, model.RequestID, *trial, *ac
 	self := system.MustActorOf(actor.Addr("trial"), tr)
 	return system, db, rID, tr, self
 }
+
+func TestTrialPreemptDisabled(t *testing.T) {
+	system, _, rID, tr, self := setup(t)
+
+	// Replace the mock allocation with one that refuses preemption.
+	allocImpl := actors.MockActor{Responses: map[string]*actors.MockResponse{}}
+	allocImpl.Expect(fmt.Sprintf("%T", sproto.AllocationSignalWithReason{}), actors.MockResponse{
+		Msg: task.ErrBehaviorDisabled{Behavior: "preemption"},
+	})
+	taskAllocator = func(
+		logCtx detLogger.Context, req sproto.AllocateRequest, db db.DB, rm rm.ResourceManager,
+		l *task.Logger,
+	) actor.Actor {
+		return &allocImpl
+	}
+
+	require.NoError(t, system.Ask(self,
+		model.StateWithReason{State: model.ActiveState}).Error())
+	require.NoError(t, system.Ask(self, trialSearcherState{
+		Create: searcher.Create{RequestID: rID},
+		Op: searcher.ValidateAfter{
+			RequestID: rID,
+			Length:    10,
+		},
+		Complete: false,
+		Closed:   true,
+	}).Error())
+	require.NotNil(t, tr.allocation)
+
+	err := system.Ask(self, sproto.AllocationSignalWithReason{
+		AllocationSignal:    sproto.PreemptAllocation,
+		InformationalReason: "preempted by an administrator",
+	}).Error()
+	require.Equal(t, task.ErrBehaviorDisabled{Behavior: "preemption"}, err)
+	require.NoError(t, allocImpl.AssertExpectations())
+}
@@ -39,7 +39,8 @@ func checkTrialFiltersEmpty(f *apiv1.TrialFilters) error {
 		len(f.UserIds) +
 		len(f.Tags) +
 		len(f.States) +
-		len(f.SearcherMetric)
+		len(f.SearcherMetric) +
+		len(f.Metadata.GetFields())
 
 	if filtersLength == 0 &&
 		f.RankWithinExp == nil &&
@@ -8,6 +8,7 @@ import (
 
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
+	"google.golang.org/protobuf/encoding/protojson"
 
 	"github.com/determined-ai/determined/master/internal/db"
 	"github.com/determined-ai/determined/master/pkg/model"
@@ -39,6 +40,7 @@ type TrialsAugmented struct {
 	SearcherMetric        string             `bun:"searcher_metric"`
 	SearcherMetricValue   float64            `bun:"searcher_metric_value"`
 	SearcherMetricLoss    float64            `bun:"searcher_metric_loss"`
+	Metadata              model.JSONObj      `bun:"metadata"`
 
 	RankWithinExp int32 `bun:"rank,scanonly"`
 }
@@ -67,6 +69,7 @@ func (t *TrialsAugmented) Proto() *apiv1.AugmentedTrial {
 		SearcherMetric:        t.SearcherMetric,
 		SearcherMetricValue:   t.SearcherMetricValue,
 		SearcherMetricLoss:    t.SearcherMetricLoss,
+		Metadata:              protoutils.ToStruct(t.Metadata),
 	}
 }
 
@@ -159,7 +162,7 @@ func TrialsColumnForNamespace(namespace apiv1.TrialSorter_Namespace,
 func BuildFilterTrialsQuery(filters *apiv1.TrialFilters, selectAll bool) (*bun.SelectQuery, error) {
 	// FilterTrials filters trials according to filters
 
-	q := db.Bun().NewSelect().Model((*TrialsAugmented)(nil))
+	q := db.ReadBun().NewSelect().Model((*TrialsAugmented)(nil))
 
 	rankFilterApplied := filters.RankWithinExp != nil && filters.RankWithinExp.Rank != 0
 
@@ -186,7 +189,7 @@ func BuildFilterTrialsQuery(filters *apiv1.TrialFilters, selectAll bool) (*bun.S
 			columnExpr,
 			QueryTrialsOrderMap[r.Sorter.OrderBy])
 
-		rankQ := db.Bun().NewSelect().
+		rankQ := db.ReadBun().NewSelect().
 			Model((*TrialsAugmented)(nil)).
 			ColumnExpr("trial_id as t_id").
 			ColumnExpr(rankExpr)
@@ -212,6 +215,14 @@ func BuildFilterTrialsQuery(filters *apiv1.TrialFilters, selectAll bool) (*bun.S
 		q.Where("tags ?| ?", bun.Safe("?"), pgdialect.Array(tagKeys))
 	}
 
+	if len(filters.Metadata.GetFields()) > 0 {
+		metadata, err := protojson.Marshal(filters.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metadata filter %w", err)
+		}
+		q.Where("metadata @> ?::jsonb", string(metadata))
+	}
+
 	if len(filters.TrialIds) > 0 {
 		q.Where("trial_id IN (?)", bun.In(filters.TrialIds))
 	}
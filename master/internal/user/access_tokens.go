@@ -0,0 +1,139 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// accessTokenBytes is the amount of randomness in an access token.
+const accessTokenBytes = 32
+
+func hashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// AddAccessToken creates an access token for a user and returns the token itself, which is not
+// stored and cannot be retrieved again.
+func AddAccessToken(ctx context.Context, t *model.AccessToken) (string, error) {
+	b := make([]byte, accessTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "error generating access token")
+	}
+	token := model.AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	t.TokenHash = hashAccessToken(token)
+	if _, err := db.Bun().NewInsert().Model(t).Returning("id, created_at").Exec(ctx); err != nil {
+		return "", errors.Wrap(err, "error adding access token")
+	}
+	return token, nil
+}
+
+// AccessTokens returns the access tokens of a user, or of all users if userID is nil. Revoked
+// and expired tokens are only included if includeInactive is set.
+func AccessTokens(
+	ctx context.Context, userID *model.UserID, includeInactive bool,
+) ([]model.AccessToken, error) {
+	tokens := []model.AccessToken{}
+	q := db.Bun().NewSelect().Model(&tokens).Order("id DESC")
+	if userID != nil {
+		q = q.Where("user_id = ?", *userID)
+	}
+	if !includeInactive {
+		q = q.Where("revoked_at IS NULL").
+			Where("expires_at IS NULL OR expires_at > now()")
+	}
+	if err := q.Scan(ctx); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// AccessTokenByID returns an access token by its ID.
+func AccessTokenByID(ctx context.Context, id int) (*model.AccessToken, error) {
+	var t model.AccessToken
+	err := db.Bun().NewSelect().Model(&t).Where("id = ?", id).Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ErrNotFound
+	}
+	return &t, err
+}
+
+// RevokeAccessToken revokes an access token, so that it can no longer be used. Revoking a token
+// twice keeps the time it was first revoked.
+func RevokeAccessToken(ctx context.Context, id int) error {
+	_, err := db.Bun().NewUpdate().Model((*model.AccessToken)(nil)).
+		Set("revoked_at = now()").
+		Where("id = ?", id).
+		Where("revoked_at IS NULL").
+		Exec(ctx)
+	return err
+}
+
+// UserByAccessToken returns the user an access token belongs to, along with a session that
+// carries the scope of the token and expires with it.
+func UserByAccessToken(token string) (*model.User, *model.UserSession, error) {
+	ctx := context.Background()
+
+	var t model.AccessToken
+	res, err := db.Bun().NewUpdate().Model(&t).
+		Set("last_used_at = now()").
+		Where("token_hash = ?", hashAccessToken(token)).
+		Where("revoked_at IS NULL").
+		Where("expires_at IS NULL OR expires_at > now()").
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, nil, err
+	} else if n == 0 {
+		return nil, nil, db.ErrNotFound
+	}
+
+	var user model.User
+	if err := db.Bun().NewSelect().Model(&user).Where("id = ?", t.UserID).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, db.ErrNotFound
+		}
+		return nil, nil, err
+	}
+
+	session := &model.UserSession{UserID: t.UserID, AccessTokenScope: t.Scope}
+	if t.ExpiresAt != nil {
+		session.Expiry = *t.ExpiresAt
+	}
+	return &user, session, nil
+}
+
+var checkpointDownloadPath = regexp.MustCompile(`^/checkpoints/[^/]+$`)
+
+// HTTPRequestAllowed returns whether a session may make an HTTP request to the given path, given
+// the scope of the access token it was authenticated with, if any.
+func HTTPRequestAllowed(session *model.UserSession, method, path string) bool {
+	if session.AccessTokenScope.AllowsAll() {
+		return true
+	}
+	switch session.AccessTokenScope {
+	case model.AccessTokenScopeReadOnly:
+		return method == http.MethodGet || method == http.MethodHead
+	case model.AccessTokenScopeCheckpointDownload:
+		return method == http.MethodGet &&
+			checkpointDownloadPath.MatchString(strings.TrimSuffix(path, "/"))
+	default:
+		return false
+	}
+}
@@ -9,7 +9,6 @@ import (
 // RegisterAPIHandler initializes and registers the API handlers for all command related features.
 func RegisterAPIHandler(echo *echo.Echo, m *Service, middleware ...echo.MiddlewareFunc) {
 	echo.POST("/logout", api.Route(m.postLogout), middleware...)
-	echo.POST("/login", api.Route(m.postLogin))
 	usersGroup := echo.Group("/users", middleware...)
 	usersGroup.GET("", api.Route(m.getUsers))
 	usersGroup.POST("", api.Route(m.postUser))
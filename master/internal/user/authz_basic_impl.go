@@ -121,6 +121,17 @@ func (a *UserAuthZBasic) CanResetUsersOwnSettings(ctx context.Context, curUser m
 	return nil
 }
 
+// CanManageUsersAccessTokens returns an error if the user is not an admin when trying to manage
+// the access tokens of another user than themselves or one of their service accounts.
+func (a *UserAuthZBasic) CanManageUsersAccessTokens(
+	ctx context.Context, curUser, targetUser model.User,
+) error {
+	if !curUser.Admin && curUser.ID != targetUser.ID && !OwnsServiceAccount(curUser, targetUser) {
+		return fmt.Errorf("only admin privileged users can manage other user's access tokens")
+	}
+	return nil
+}
+
 // CanGetActiveTasksCount always returns a nil error.
 func (a *UserAuthZBasic) CanGetActiveTasksCount(ctx context.Context, curUser model.User) error {
 	return nil
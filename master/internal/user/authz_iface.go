@@ -64,6 +64,12 @@ type UserAuthZ interface {
 	// POST /api/v1/users/setting
 	CanResetUsersOwnSettings(ctx context.Context, curUser model.User) error
 
+	// POST /api/v1/tokens
+	// GET /api/v1/tokens
+	// DELETE /api/v1/tokens/:id
+	// GET /api/v1/sessions
+	CanManageUsersAccessTokens(ctx context.Context, curUser, targetUser model.User) error
+
 	// GET /api/v1/tasks/count
 	// TODO(nick) move this when we add an AuthZ for notebooks.
 	CanGetActiveTasksCount(ctx context.Context, curUser model.User) error
@@ -0,0 +1,25 @@
+package user
+
+import (
+	"context"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// UserAuthZRoles controls access to users like UserAuthZBasic does. Notebooks, TensorBoards,
+// shells, and commands belong to no workspace, so roles cannot grant access to them, and only
+// their owners and admins can access them.
+type UserAuthZRoles struct {
+	UserAuthZBasic
+}
+
+// CanAccessNTSCTask returns whether the user is an admin or owns the task.
+func (a *UserAuthZRoles) CanAccessNTSCTask(
+	ctx context.Context, curUser model.User, ownerID model.UserID,
+) (bool, error) {
+	return curUser.Admin || curUser.ID == ownerID, nil
+}
+
+func init() {
+	AuthZProvider.Register("roles", &UserAuthZRoles{})
+}
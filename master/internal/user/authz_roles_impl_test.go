@@ -0,0 +1,26 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestRolesCanAccessNTSCTask(t *testing.T) {
+	a := &UserAuthZRoles{}
+	for _, c := range []struct {
+		user     model.User
+		expected bool
+	}{
+		{model.User{ID: 2}, true},
+		{model.User{ID: 3}, false},
+		{model.User{ID: 3, Admin: true}, true},
+	} {
+		ok, err := a.CanAccessNTSCTask(context.Background(), c.user, 2)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, ok, "user %+v", c.user)
+	}
+}
@@ -6,6 +6,6 @@ import (
 
 // IsSSOUser checks whether user has restrictions based on being
 // an SSO or SCIM user.
-func IsSSOUser(_ model.User) bool {
-	return false
+func IsSSOUser(u model.User) bool {
+	return u.Remote
 }
@@ -0,0 +1,248 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/totp"
+)
+
+const (
+	// recoveryCodeCount is how many recovery codes users get at a time.
+	recoveryCodeCount = 10
+	// recoveryCodeBytes is the amount of randomness in a recovery code.
+	recoveryCodeBytes = 10
+)
+
+var (
+	// ErrInvalidMFACode is returned when a code is neither the current one of the authenticator
+	// app of a user nor one of their unused recovery codes.
+	ErrInvalidMFACode = errors.New("invalid MFA code")
+	// ErrMFAEnrolled is returned when enrolling a user who is already enrolled.
+	ErrMFAEnrolled = errors.New("user is already enrolled in MFA")
+	// ErrMFANotEnrolled is returned when changing the enrollment of a user who has none.
+	ErrMFANotEnrolled = errors.New("user is not enrolled in MFA")
+)
+
+// UserMFA returns the multi-factor authentication settings of a user.
+func UserMFA(ctx context.Context, userID model.UserID) (*model.UserMFA, error) {
+	m := model.UserMFA{UserID: userID}
+	err := db.Bun().NewSelect().Model(&m).WherePK().Scan(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// MFARequired returns whether a user must enroll in multi-factor authentication.
+func MFARequired(m *model.UserMFA) bool {
+	return m.Required || config.GetMasterConfig().Security.MFA.Required
+}
+
+// BeginMFAEnrollment generates a secret for a user to add to their authenticator app and returns
+// it along with its otpauth URI. The enrollment takes effect once it is confirmed with a code.
+func BeginMFAEnrollment(ctx context.Context, u *model.User) (secret, uri string, err error) {
+	m, err := UserMFA(ctx, u.ID)
+	if err != nil {
+		return "", "", err
+	}
+	if m.Enrolled() {
+		return "", "", ErrMFAEnrolled
+	}
+
+	if secret, err = totp.NewSecret(); err != nil {
+		return "", "", err
+	}
+	if _, err := db.Bun().NewInsert().
+		Model(&model.UserMFA{UserID: u.ID, PendingSecret: secret}).
+		Column("user_id", "pending_secret").
+		On("CONFLICT (user_id) DO UPDATE").
+		Set("pending_secret = EXCLUDED.pending_secret").
+		Exec(ctx); err != nil {
+		return "", "", errors.Wrap(err, "error starting MFA enrollment")
+	}
+	return secret, totp.URI(config.GetMasterConfig().Security.MFA.Issuer, u.Username, secret), nil
+}
+
+// ConfirmMFAEnrollment completes the enrollment of a user with a code from their authenticator
+// app and returns their recovery codes. Their sessions are no longer limited to enrolling.
+func ConfirmMFAEnrollment(
+	ctx context.Context, userID model.UserID, code string,
+) ([]string, error) {
+	m, err := UserMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case m.Enrolled():
+		return nil, ErrMFAEnrolled
+	case m.PendingSecret == "":
+		return nil, ErrMFANotEnrolled
+	}
+	step, ok, err := totp.Validate(m.PendingSecret, code, time.Now(), 0)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = db.Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewUpdate().Model((*model.UserMFA)(nil)).
+			Set("secret = pending_secret").
+			Set("pending_secret = NULL").
+			Set("enrolled_at = now()").
+			Set("recovery_code_hashes = ?", pgdialect.Array(hashes)).
+			Set("last_used_step = ?", step).
+			Where("user_id = ?", userID).
+			Where("pending_secret = ?", m.PendingSecret).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			// The enrollment was restarted or confirmed concurrently.
+			return ErrInvalidMFACode
+		}
+		_, err = tx.NewUpdate().Table("user_sessions").
+			Set("mfa_enrollment_only = false").
+			Where("user_id = ?", userID).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// VerifyMFACode checks a code from the authenticator app of an enrolled user, or one of their
+// recovery codes, and uses it up.
+func VerifyMFACode(ctx context.Context, m *model.UserMFA, code string) error {
+	if !m.Enrolled() {
+		return ErrMFANotEnrolled
+	}
+	step, ok, err := totp.Validate(m.Secret, code, time.Now(), m.LastUsedStep)
+	if err != nil {
+		return err
+	}
+
+	q := db.Bun().NewUpdate().Model((*model.UserMFA)(nil)).Where("user_id = ?", m.UserID)
+	if ok {
+		// Guard against the same code being used by concurrent logins.
+		q = q.Set("last_used_step = ?", step).Where("last_used_step < ?", step)
+	} else {
+		hash := hashRecoveryCode(code)
+		q = q.Set("recovery_code_hashes = array_remove(recovery_code_hashes, ?)", hash).
+			Where("? = ANY(recovery_code_hashes)", hash)
+	}
+	res, err := q.Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes gives an enrolled user new recovery codes in place of their old ones.
+func ReplaceRecoveryCodes(ctx context.Context, userID model.UserID) ([]string, error) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	res, err := db.Bun().NewUpdate().Model((*model.UserMFA)(nil)).
+		Set("recovery_code_hashes = ?", pgdialect.Array(hashes)).
+		Where("user_id = ?", userID).
+		Where("enrolled_at IS NOT NULL").
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, ErrMFANotEnrolled
+	}
+	return codes, nil
+}
+
+// DeleteMFA removes the enrollment of a user, keeping whether they are required to enroll.
+func DeleteMFA(ctx context.Context, userID model.UserID) error {
+	_, err := db.Bun().NewUpdate().Model((*model.UserMFA)(nil)).
+		Set("secret = NULL").
+		Set("pending_secret = NULL").
+		Set("enrolled_at = NULL").
+		Set("recovery_code_hashes = '{}'").
+		Set("last_used_step = 0").
+		Where("user_id = ?", userID).
+		Exec(ctx)
+	return err
+}
+
+// SetMFARequired sets whether a user must enroll in multi-factor authentication. Sessions that
+// were limited to enrolling are no longer limited if the user is not required to enroll anymore.
+func SetMFARequired(ctx context.Context, userID model.UserID, required bool) error {
+	return db.Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().
+			Model(&model.UserMFA{UserID: userID, Required: required}).
+			Column("user_id", "required").
+			On("CONFLICT (user_id) DO UPDATE").
+			Set("required = EXCLUDED.required").
+			Exec(ctx); err != nil {
+			return err
+		}
+		if required || config.GetMasterConfig().Security.MFA.Required {
+			return nil
+		}
+		_, err := tx.NewUpdate().Table("user_sessions").
+			Set("mfa_enrollment_only = false").
+			Where("user_id = ?", userID).
+			Exec(ctx)
+		return err
+	})
+}
+
+// newRecoveryCodes returns random recovery codes along with their hashes.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, errors.Wrap(err, "error generating recovery codes")
+		}
+		code := strings.ToLower(encoding.EncodeToString(b))
+		code = code[:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes a recovery code, ignoring how it was typed.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
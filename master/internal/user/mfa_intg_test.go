@@ -0,0 +1,81 @@
+//go:build integration
+// +build integration
+
+package user
+
+import (
+	stdContext "context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/totp"
+)
+
+func TestMFA(t *testing.T) {
+	setup(t)
+	ctx := stdContext.TODO()
+	u := &model.User{Username: uuid.New().String(), Active: true}
+	require.NoError(t, AddUserExec(u))
+
+	m, err := UserMFA(ctx, u.ID)
+	require.NoError(t, err)
+	require.False(t, m.Enrolled())
+	require.False(t, MFARequired(m))
+
+	// Requiring enrollment leaves sessions limited to enrolling until the user does.
+	require.NoError(t, SetMFARequired(ctx, u.ID, true))
+	token, err := pgDB.StartMFAEnrollmentSession(u)
+	require.NoError(t, err)
+	_, session, err := UserByToken(token, &model.ExternalSessions{})
+	require.NoError(t, err)
+	require.True(t, session.MFAEnrollmentOnly)
+
+	_, err = ConfirmMFAEnrollment(ctx, u.ID, "000000")
+	require.ErrorIs(t, err, ErrMFANotEnrolled)
+	secret, uri, err := BeginMFAEnrollment(ctx, u)
+	require.NoError(t, err)
+	require.Contains(t, uri, secret)
+
+	_, err = ConfirmMFAEnrollment(ctx, u.ID, "not a code")
+	require.ErrorIs(t, err, ErrInvalidMFACode)
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+	recoveryCodes, err := ConfirmMFAEnrollment(ctx, u.ID, code)
+	require.NoError(t, err)
+	require.Len(t, recoveryCodes, recoveryCodeCount)
+	_, session, err = UserByToken(token, &model.ExternalSessions{})
+	require.NoError(t, err)
+	require.False(t, session.MFAEnrollmentOnly)
+	_, _, err = BeginMFAEnrollment(ctx, u)
+	require.ErrorIs(t, err, ErrMFAEnrolled)
+
+	// The code that confirmed the enrollment cannot be used to log in.
+	m, err = UserMFA(ctx, u.ID)
+	require.NoError(t, err)
+	require.True(t, m.Enrolled())
+	require.ErrorIs(t, VerifyMFACode(ctx, m, code), ErrInvalidMFACode)
+
+	// Recovery codes work once, however they are typed.
+	require.NoError(t, VerifyMFACode(ctx, m, " "+recoveryCodes[0]+" "))
+	require.ErrorIs(t, VerifyMFACode(ctx, m, recoveryCodes[0]), ErrInvalidMFACode)
+	m, err = UserMFA(ctx, u.ID)
+	require.NoError(t, err)
+	require.Len(t, m.RecoveryCodeHashes, recoveryCodeCount-1)
+
+	replaced, err := ReplaceRecoveryCodes(ctx, u.ID)
+	require.NoError(t, err)
+	require.ErrorIs(t, VerifyMFACode(ctx, m, recoveryCodes[1]), ErrInvalidMFACode)
+	require.NoError(t, VerifyMFACode(ctx, m, replaced[0]))
+
+	require.NoError(t, DeleteMFA(ctx, u.ID))
+	m, err = UserMFA(ctx, u.ID)
+	require.NoError(t, err)
+	require.False(t, m.Enrolled())
+	require.True(t, MFARequired(m))
+	_, err = ReplaceRecoveryCodes(ctx, u.ID)
+	require.ErrorIs(t, err, ErrMFANotEnrolled)
+}
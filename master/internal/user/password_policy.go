@@ -0,0 +1,104 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// CheckPasswordPolicy returns an error describing what a new password is missing to meet the
+// complexity rules of the cluster.
+func CheckPasswordPolicy(password string) error {
+	policy := config.GetMasterConfig().Security.PasswordPolicy
+	var missing []string
+	if n := len([]rune(password)); n < policy.MinLength {
+		missing = append(missing, fmt.Sprintf("at least %d characters", policy.MinLength))
+	}
+	for _, rule := range []struct {
+		required bool
+		what     string
+		is       func(rune) bool
+	}{
+		{policy.RequireUppercase, "an uppercase letter", unicode.IsUpper},
+		{policy.RequireLowercase, "a lowercase letter", unicode.IsLower},
+		{policy.RequireDigit, "a digit", unicode.IsDigit},
+		{policy.RequireSymbol, "a symbol", func(r rune) bool {
+			return unicode.IsPunct(r) || unicode.IsSymbol(r)
+		}},
+	} {
+		if rule.required && strings.IndexFunc(password, rule.is) < 0 {
+			missing = append(missing, rule.what)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("passwords must have %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// PasswordState returns what the password policies of the cluster are enforced against for a
+// user. Users whose password was never recorded as changed have a zero PasswordChangedAt.
+func PasswordState(ctx context.Context, userID model.UserID) (*model.UserPasswordState, error) {
+	s := model.UserPasswordState{UserID: userID}
+	err := db.Bun().NewSelect().Model(&s).WherePK().Scan(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// PasswordExpired returns whether the password of a user must be changed before they can log in.
+func PasswordExpired(s *model.UserPasswordState, now time.Time) bool {
+	maxAge := time.Duration(config.GetMasterConfig().Security.PasswordPolicy.MaxAge)
+	return maxAge > 0 && !s.PasswordChangedAt.IsZero() && now.After(s.PasswordChangedAt.Add(maxAge))
+}
+
+// RecordFailedLogin counts a failed login of a user, locking them out once they have failed too
+// many times in a row.
+func RecordFailedLogin(ctx context.Context, userID model.UserID) error {
+	lockout := config.GetMasterConfig().Security.Lockout
+	if lockout.MaxFailedAttempts == 0 {
+		return nil
+	}
+	return db.Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var attempts int
+		if _, err := tx.NewInsert().
+			Model(&model.UserPasswordState{UserID: userID, FailedLoginAttempts: 1}).
+			Column("user_id", "failed_login_attempts").
+			On("CONFLICT (user_id) DO UPDATE").
+			Set("failed_login_attempts = user_password_state.failed_login_attempts + 1").
+			Returning("failed_login_attempts").
+			Exec(ctx, &attempts); err != nil {
+			return errors.Wrapf(err, "error recording failed login of user %d", userID)
+		}
+		if attempts < lockout.MaxFailedAttempts {
+			return nil
+		}
+		_, err := tx.NewUpdate().Model((*model.UserPasswordState)(nil)).
+			Set("failed_login_attempts = 0").
+			Set("locked_until = ?", time.Now().Add(time.Duration(lockout.Duration))).
+			Where("user_id = ?", userID).
+			Exec(ctx)
+		return errors.Wrapf(err, "error locking out user %d", userID)
+	})
+}
+
+// RecordSuccessfulLogin resets the count of failed logins of a user.
+func RecordSuccessfulLogin(ctx context.Context, userID model.UserID) error {
+	_, err := db.Bun().NewUpdate().Model((*model.UserPasswordState)(nil)).
+		Set("failed_login_attempts = 0").
+		Where("user_id = ?", userID).
+		Where("failed_login_attempts > 0").
+		Exec(ctx)
+	return err
+}
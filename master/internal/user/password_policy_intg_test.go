@@ -0,0 +1,59 @@
+//go:build integration
+// +build integration
+
+package user
+
+import (
+	stdContext "context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	setup(t)
+	ctx := stdContext.TODO()
+	security := &config.GetMasterConfig().Security
+	defer func(policy config.PasswordPolicyConfig, lockout config.LockoutConfig) {
+		security.PasswordPolicy, security.Lockout = policy, lockout
+	}(security.PasswordPolicy, security.Lockout)
+	security.PasswordPolicy.MaxAge = model.Duration(time.Hour)
+	security.Lockout = config.LockoutConfig{
+		MaxFailedAttempts: 3,
+		Duration:          model.Duration(time.Hour),
+	}
+
+	u := &model.User{Username: uuid.New().String(), Active: true}
+	_, err := pgDB.AddUser(u, nil)
+	require.NoError(t, err)
+
+	// Passwords expire once they are older than the maximum age.
+	s, err := PasswordState(ctx, u.ID)
+	require.NoError(t, err)
+	require.False(t, PasswordExpired(s, time.Now()))
+	require.True(t, PasswordExpired(s, time.Now().Add(2*time.Hour)))
+
+	// Failed logins lock users out only if they fail too many times in a row.
+	require.NoError(t, RecordFailedLogin(ctx, u.ID))
+	require.NoError(t, RecordFailedLogin(ctx, u.ID))
+	require.NoError(t, RecordSuccessfulLogin(ctx, u.ID))
+	for i := 0; i < 2; i++ {
+		require.NoError(t, RecordFailedLogin(ctx, u.ID))
+	}
+	s, err = PasswordState(ctx, u.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, s.FailedLoginAttempts)
+	require.False(t, s.Locked(time.Now()))
+
+	require.NoError(t, RecordFailedLogin(ctx, u.ID))
+	s, err = PasswordState(ctx, u.ID)
+	require.NoError(t, err)
+	require.Zero(t, s.FailedLoginAttempts)
+	require.True(t, s.Locked(time.Now()))
+	require.False(t, s.Locked(time.Now().Add(2*time.Hour)))
+}
@@ -0,0 +1,32 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/config"
+)
+
+func TestCheckPasswordPolicy(t *testing.T) {
+	policy := &config.GetMasterConfig().Security.PasswordPolicy
+	defer func(old config.PasswordPolicyConfig) { *policy = old }(*policy)
+
+	require.NoError(t, CheckPasswordPolicy(""))
+
+	*policy = config.PasswordPolicyConfig{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+	}
+	require.NoError(t, CheckPasswordPolicy("Corr3ct-horse"))
+	require.NoError(t, CheckPasswordPolicy("Ünïcode-1"))
+	err := CheckPasswordPolicy("short")
+	require.ErrorContains(t, err, "at least 8 characters")
+	require.ErrorContains(t, err, "an uppercase letter")
+	require.ErrorContains(t, err, "a digit")
+	require.ErrorContains(t, err, "a symbol")
+	require.NotContains(t, err.Error(), "lowercase")
+}
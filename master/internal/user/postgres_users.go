@@ -25,7 +25,7 @@ func UserByID(userID model.UserID) (*model.FullUser, error) {
 	var fu model.FullUser
 	query := `
 SELECT
-	u.id, u.username, u.display_name, u.admin, u.active,
+	u.id, u.username, u.display_name, u.admin, u.active, u.remote, u.service_account, u.owner_id,
 	h.uid AS agent_uid, h.gid AS agent_gid, h.user_ AS agent_user, h.group_ AS agent_group
 FROM users u
 LEFT OUTER JOIN agent_user_groups h ON (u.id = h.user_id)
@@ -176,6 +176,10 @@ func UserByToken(token string, ext *model.ExternalSessions) (
 		return UserByExternalToken(token, ext)
 	}
 
+	if strings.HasPrefix(token, model.AccessTokenPrefix) {
+		return UserByAccessToken(token)
+	}
+
 	v2 := paseto.NewV2()
 
 	var session model.UserSession
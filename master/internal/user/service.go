@@ -13,12 +13,16 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 
 	"github.com/determined-ai/determined/master/internal/api"
+	"github.com/determined-ai/determined/master/internal/auditlog"
+	"github.com/determined-ai/determined/master/internal/config"
 	detContext "github.com/determined-ai/determined/master/internal/context"
 	"github.com/determined-ai/determined/master/internal/db"
 	"github.com/determined-ai/determined/master/internal/telemetry"
 	"github.com/determined-ai/determined/master/pkg/actor"
+	"github.com/determined-ai/determined/master/pkg/logger"
 	"github.com/determined-ai/determined/master/pkg/model"
 )
 
@@ -44,12 +48,15 @@ const (
 var unauthenticatedPointsList = []string{
 	"/",
 	"/info",
+	"/health",
 	"/task-logs",
 	"/ws/data-layer/.*",
 	"/agents",
 	"/det",
 	"/det/.*",
 	"/login",
+	"/oidc/.*",
+	"/scim/v2/.*",
 	"/api/v1/.*",
 	"/proxy/:service/.*",
 	"/agents\\?id=.*",
@@ -58,6 +65,8 @@ var unauthenticatedPointsList = []string{
 // adminAuthPointsList contains the paths that require admin authentication.
 var adminAuthPointsList = []string{
 	"/config",
+	"/audit-log/export",
+	"/audit-log/export\\?.*",
 	"/agents/.*/slots/.*",
 }
 
@@ -183,11 +192,27 @@ func (s *Service) ProcessAuthentication(next echo.HandlerFunc) echo.HandlerFunc
 			if adminOnly && !user.Admin {
 				return echo.NewHTTPError(http.StatusForbidden, "user not admin")
 			}
+			if !HTTPRequestAllowed(session, c.Request().Method, c.Request().URL.Path) {
+				return echo.NewHTTPError(http.StatusForbidden, "access token scope does not allow this")
+			}
+			if session.MFAEnrollmentOnly {
+				return echo.NewHTTPError(http.StatusForbidden, "MFA enrollment required")
+			}
+
+			// Only sessions started by logging in are kept in the database.
+			if session.AccessTokenScope == "" && s.extConfig.JwtKey == "" {
+				if err := RecordSessionUse(c.Request().Context(), session.ID,
+					auditlog.RemoteHTTPIP(c.Request())); err != nil {
+					log.WithError(err).Warnf("failed to record use of session %d", session.ID)
+				}
+			}
 
 			// Set data on the request context that might be useful to
 			// event handlers.
 			c.(*detContext.DetContext).SetUser(*user)
 			c.(*detContext.DetContext).SetUserSession(*session)
+			c.SetRequest(c.Request().WithContext(logger.WithFields(c.Request().Context(),
+				logger.Context{"user": user.Username})))
 			return next(c)
 		case db.ErrNotFound:
 			return echo.NewHTTPError(http.StatusUnauthorized)
@@ -205,19 +230,26 @@ func (s *Service) ProcessProxyAuthentication(c echo.Context) (done bool, err err
 		return true, redirectToLogin(c)
 	}
 
-	user, _, err := UserByToken(token, s.extConfig)
+	user, session, err := UserByToken(token, s.extConfig)
 	if errors.Is(err, db.ErrNotFound) {
 		return true, redirectToLogin(c)
 	} else if err != nil {
 		return true, err
 	}
-	if !user.Active {
+	// Tasks behind the proxy can do anything their owner can, so only unscoped tokens reach them.
+	if !user.Active || !session.AccessTokenScope.AllowsAll() || session.MFAEnrollmentOnly {
 		return true, redirectToLogin(c)
 	}
 
-	taskID := c.Param("service")
+	// Named services declared by a task are registered as "<task ID>:<name>".
+	taskID, _, _ := strings.Cut(c.Param("service"), ":")
 	ownerID, err := db.GetCommandOwnerID(c.Request().Context(), model.TaskID(taskID))
-	if err != nil {
+	if errors.Is(err, db.ErrNotFound) {
+		ownerID, err = db.GetTaskOwnerID(c.Request().Context(), model.TaskID(taskID))
+	}
+	if errors.Is(err, db.ErrNotFound) {
+		return true, echo.NewHTTPError(http.StatusNotFound, "service not found: "+taskID)
+	} else if err != nil {
 		return true, err
 	}
 
@@ -255,6 +287,10 @@ func (s *Service) postLogout(c echo.Context) (interface{}, error) {
 
 	// Delete the user session information from the database.
 	sess := c.(*detContext.DetContext).MustGetUserSession()
+	if sess.AccessTokenScope != "" {
+		return nil, echo.NewHTTPError(http.StatusBadRequest,
+			"cannot logout of an access token; revoke it instead")
+	}
 
 	if err := s.db.DeleteUserSessionByID(sess.ID); err != nil {
 		return nil, err
@@ -263,75 +299,13 @@ func (s *Service) postLogout(c echo.Context) (interface{}, error) {
 	return "", nil
 }
 
-func (s *Service) postLogin(c echo.Context) (interface{}, error) {
-	if s.extConfig.JwtKey != "" {
-		return nil, echo.NewHTTPError(http.StatusMisdirectedRequest,
-			"authentication is configured to be external")
-	}
-
-	type (
-		request struct {
-			Username string `json:"username"`
-			Password string `json:"password"`
-		}
-		response struct {
-			Token string `json:"token"`
-		}
-	)
-
-	body, err := ioutil.ReadAll(c.Request().Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var params request
-	if err = json.Unmarshal(body, &params); err != nil {
-		return nil, echo.NewHTTPError(http.StatusBadRequest)
-	}
-
-	// Get the user from the database.
-	user, err := UserByUsername(params.Username)
-	switch err {
-	case nil:
-	case db.ErrNotFound:
-		return nil, echo.NewHTTPError(http.StatusForbidden, "user not found")
-	default:
-		return nil, err
-	}
-
-	// The user must be active.
-	if !user.Active {
-		return nil, echo.NewHTTPError(http.StatusForbidden, "user not active")
-	}
-
-	var token string
-	if !user.ValidatePassword(params.Password) {
-		return nil, echo.NewHTTPError(http.StatusForbidden, "invalid credentials")
-	}
-
-	token, err = s.db.StartUserSession(user)
-	if err != nil {
-		return nil, err
-	}
-
-	// The caller of this REST endpoint can request that the master set a cookie.
-	// This is used by the WebUI for persistence of sessions.
-	if c.QueryParam("cookie") == "true" {
-		c.SetCookie(NewCookieFromToken(token))
-	}
-
-	return response{
-		Token: token,
-	}, nil
-}
-
 // NewCookieFromToken creates a new cookie from the given token.
 func NewCookieFromToken(token string) *http.Cookie {
 	cookie := new(http.Cookie)
 	cookie.Name = "auth"
 	cookie.Value = token
 	cookie.Path = "/"
-	cookie.Expires = time.Now().Add(db.SessionDuration)
+	cookie.Expires = time.Now().Add(db.SessionDuration())
 	return cookie
 }
 
@@ -426,6 +400,14 @@ func (s *Service) patchUser(c echo.Context) (interface{}, error) {
 				errors.Wrap(forbiddenError, err.Error()), userNotFoundErr)
 		}
 
+		if user.ServiceAccount {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, ErrServiceAccountPassword.Error())
+		}
+		// Passwords are hashed by clients for this endpoint, so they cannot be checked.
+		if config.GetMasterConfig().Security.PasswordPolicy.ComplexityRequired() {
+			return nil, echo.NewHTTPError(http.StatusBadRequest,
+				"the password policy requires new passwords to be set through /api/v1/users")
+		}
 		if err = user.UpdatePasswordHash(*params.Password); err != nil {
 			return nil, err
 		}
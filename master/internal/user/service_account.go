@@ -0,0 +1,17 @@
+package user
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// ErrServiceAccountPassword is returned when setting the password of a service account.
+var ErrServiceAccountPassword = errors.New(
+	"service accounts have no password; they authenticate with access tokens")
+
+// OwnsServiceAccount returns whether a user is the owner of a service account, which lets them
+// manage its access tokens and transfer it.
+func OwnsServiceAccount(owner model.User, u model.User) bool {
+	return u.ServiceAccount && u.OwnerID != nil && *u.OwnerID == owner.ID
+}
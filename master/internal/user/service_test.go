@@ -8,6 +8,8 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/model"
 )
 
 func TestStandardAuth(t *testing.T) {
@@ -57,3 +59,24 @@ func TestNoAuth(t *testing.T) {
 	c.SetRequest(httptest.NewRequest(http.MethodPatch, "/agents?id=1", nil))
 	require.Equal(t, authNone, service.getAuthLevel(c))
 }
+
+func TestHTTPRequestAllowed(t *testing.T) {
+	for _, tc := range []struct {
+		scope   model.AccessTokenScope
+		method  string
+		path    string
+		allowed bool
+	}{
+		{"", http.MethodPost, "/agents", true},
+		{model.AccessTokenScopeFull, http.MethodDelete, "/agents", true},
+		{model.AccessTokenScopeReadOnly, http.MethodGet, "/agents", true},
+		{model.AccessTokenScopeReadOnly, http.MethodPatch, "/agents/a/slots/0", false},
+		{model.AccessTokenScopeCheckpointDownload, http.MethodGet, "/checkpoints/abc", true},
+		{model.AccessTokenScopeCheckpointDownload, http.MethodGet, "/checkpoints/abc/", true},
+		{model.AccessTokenScopeCheckpointDownload, http.MethodGet, "/agents", false},
+		{model.AccessTokenScopeCheckpointDownload, http.MethodPost, "/checkpoints/abc", false},
+	} {
+		session := &model.UserSession{AccessTokenScope: tc.scope}
+		require.Equal(t, tc.allowed, HTTPRequestAllowed(session, tc.method, tc.path), tc)
+	}
+}
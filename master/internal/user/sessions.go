@@ -0,0 +1,115 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// sessionUseInterval is how often uses of a session from the same address are recorded, so that
+// every request does not write to the database.
+const sessionUseInterval = time.Minute
+
+type sessionUse struct {
+	ip string
+	at time.Time
+}
+
+// sessionUses remembers the last recorded use of each session, so that uses which would not be
+// recorded anyway do not query the database at all.
+type sessionUses struct {
+	mu        sync.Mutex
+	last      map[model.SessionID]sessionUse
+	lastPrune time.Time
+}
+
+var recordedSessionUses = sessionUses{last: map[model.SessionID]sessionUse{}}
+
+// shouldRecord returns whether a use of a session from an address at a time should be recorded,
+// and remembers it if so. Uses older than sessionUseInterval are forgotten once per interval.
+func (s *sessionUses) shouldRecord(id model.SessionID, ip string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastPrune) >= sessionUseInterval {
+		for sessionID, use := range s.last {
+			if now.Sub(use.at) >= sessionUseInterval {
+				delete(s.last, sessionID)
+			}
+		}
+		s.lastPrune = now
+	}
+	if use, ok := s.last[id]; ok && use.ip == ip && now.Sub(use.at) < sessionUseInterval {
+		return false
+	}
+	s.last[id] = sessionUse{ip: ip, at: now}
+	return true
+}
+
+// forget drops the last use of a session, so that the next one is recorded.
+func (s *sessionUses) forget(id model.SessionID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.last, id)
+}
+
+// Sessions returns the sessions of a user that have not expired, most recently created first.
+func Sessions(ctx context.Context, userID model.UserID) ([]model.UserSession, error) {
+	sessions := []model.UserSession{}
+	if err := db.Bun().NewSelect().Model(&sessions).
+		Where("user_id = ?", userID).
+		Where("expiry > now()").
+		Order("id DESC").
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// SessionByID returns a session by its ID.
+func SessionByID(ctx context.Context, id model.SessionID) (*model.UserSession, error) {
+	var s model.UserSession
+	err := db.Bun().NewSelect().Model(&s).Where("id = ?", id).Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ErrNotFound
+	}
+	return &s, err
+}
+
+// DeleteSessions deletes the sessions of a user, except for the one with the ID keep if it is
+// not nil, and returns how many were deleted.
+func DeleteSessions(
+	ctx context.Context, userID model.UserID, keep *model.SessionID,
+) (int64, error) {
+	q := db.Bun().NewDelete().Table("user_sessions").Where("user_id = ?", userID)
+	if keep != nil {
+		q = q.Where("id != ?", *keep)
+	}
+	res, err := q.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// RecordSessionUse records that a session was used from an address. Uses within a minute of the
+// last recorded one from the same address are not recorded.
+func RecordSessionUse(ctx context.Context, id model.SessionID, ip string) error {
+	if !recordedSessionUses.shouldRecord(id, ip, time.Now()) {
+		return nil
+	}
+	_, err := db.Bun().NewUpdate().Table("user_sessions").
+		Set("last_used_at = now()").
+		Set("last_ip = ?", ip).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		recordedSessionUses.forget(id)
+	}
+	return err
+}
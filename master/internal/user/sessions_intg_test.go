@@ -0,0 +1,58 @@
+//go:build integration
+// +build integration
+
+package user
+
+import (
+	stdContext "context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestSessions(t *testing.T) {
+	setup(t)
+	ctx := stdContext.TODO()
+	u := &model.User{Username: uuid.New().String(), Active: true}
+	require.NoError(t, AddUserExec(u))
+
+	laptopToken, err := pgDB.StartUserSession(u)
+	require.NoError(t, err)
+	desktopToken, err := pgDB.StartUserSession(u)
+	require.NoError(t, err)
+	_, desktop, err := UserByToken(desktopToken, &model.ExternalSessions{})
+	require.NoError(t, err)
+
+	sessions, err := Sessions(ctx, u.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	require.Equal(t, desktop.ID, sessions[0].ID)
+	require.Nil(t, sessions[0].LastUsedAt)
+
+	require.NoError(t, RecordSessionUse(ctx, desktop.ID, "10.0.0.1"))
+	used, err := SessionByID(ctx, desktop.ID)
+	require.NoError(t, err)
+	require.NotNil(t, used.LastUsedAt)
+	require.Equal(t, "10.0.0.1", used.LastIP)
+
+	// Uses from the same address within a minute are not recorded again, but moves are.
+	require.NoError(t, RecordSessionUse(ctx, desktop.ID, "10.0.0.1"))
+	again, err := SessionByID(ctx, desktop.ID)
+	require.NoError(t, err)
+	require.Equal(t, *used.LastUsedAt, *again.LastUsedAt)
+	require.NoError(t, RecordSessionUse(ctx, desktop.ID, "10.0.0.2"))
+	moved, err := SessionByID(ctx, desktop.ID)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.2", moved.LastIP)
+
+	deleted, err := DeleteSessions(ctx, u.ID, &desktop.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+	_, _, err = UserByToken(laptopToken, &model.ExternalSessions{})
+	require.Error(t, err)
+	_, _, err = UserByToken(desktopToken, &model.ExternalSessions{})
+	require.NoError(t, err)
+}
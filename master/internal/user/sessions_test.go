@@ -0,0 +1,29 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestSessionUsesShouldRecord(t *testing.T) {
+	uses := sessionUses{last: map[model.SessionID]sessionUse{}}
+	now := time.Now()
+
+	require.True(t, uses.shouldRecord(1, "10.0.0.1", now))
+	// Uses from the same address within the interval are not recorded again, but moves are.
+	require.False(t, uses.shouldRecord(1, "10.0.0.1", now.Add(time.Second)))
+	require.True(t, uses.shouldRecord(1, "10.0.0.2", now.Add(2*time.Second)))
+	require.True(t, uses.shouldRecord(2, "10.0.0.2", now.Add(2*time.Second)))
+	require.True(t, uses.shouldRecord(1, "10.0.0.2", now.Add(2*time.Second+sessionUseInterval)))
+
+	// Old uses are forgotten, so sessions that are no longer used do not pile up.
+	require.Len(t, uses.last, 1)
+	require.Contains(t, uses.last, model.SessionID(1))
+
+	uses.forget(1)
+	require.True(t, uses.shouldRecord(1, "10.0.0.2", now.Add(3*time.Second+sessionUseInterval)))
+}
@@ -0,0 +1,102 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/pkg/errors"
+	sshlib "golang.org/x/crypto/ssh"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// ErrInvalidSSHKey is returned when registering something that is not a single SSH public key in
+// authorized_keys format.
+var ErrInvalidSSHKey = errors.New("must be a single SSH public key in authorized_keys format")
+
+// NewSSHKey parses a public key in authorized_keys format into a key to register for a user. The
+// name defaults to the comment of the key. Keys with options are refused, since the master
+// controls the options of the keys it authorizes.
+func NewSSHKey(
+	userID model.UserID, publicKey, name string, injectIntoTrials bool,
+) (*model.UserSSHKey, error) {
+	pub, comment, options, rest, err := sshlib.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil || len(options) > 0 || len(bytes.TrimSpace(rest)) > 0 {
+		return nil, ErrInvalidSSHKey
+	}
+
+	key := strings.TrimSpace(string(sshlib.MarshalAuthorizedKey(pub)))
+	if comment != "" {
+		key += " " + comment
+	}
+	if name == "" {
+		name = comment
+	}
+	return &model.UserSSHKey{
+		UserID:           userID,
+		Name:             name,
+		PublicKey:        key,
+		Fingerprint:      sshlib.FingerprintSHA256(pub),
+		InjectIntoTrials: injectIntoTrials,
+	}, nil
+}
+
+// AddSSHKey registers an SSH public key. Returns db.ErrDuplicateRecord if the user already
+// registered the key.
+func AddSSHKey(ctx context.Context, k *model.UserSSHKey) error {
+	_, err := db.Bun().NewInsert().Model(k).Returning("id, created_at").Exec(ctx)
+	return db.MatchSentinelError(err)
+}
+
+// SSHKeys returns the SSH public keys a user registered, most recently registered first.
+func SSHKeys(ctx context.Context, userID model.UserID) ([]model.UserSSHKey, error) {
+	keys := []model.UserSSHKey{}
+	if err := db.Bun().NewSelect().Model(&keys).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// SSHKeyByID returns a registered SSH public key by its ID.
+func SSHKeyByID(ctx context.Context, id int) (*model.UserSSHKey, error) {
+	var k model.UserSSHKey
+	err := db.Bun().NewSelect().Model(&k).Where("id = ?", id).Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ErrNotFound
+	}
+	return &k, err
+}
+
+// DeleteSSHKey removes a registered SSH public key. Containers that already started keep it.
+func DeleteSSHKey(ctx context.Context, id int) error {
+	_, err := db.Bun().NewDelete().Model((*model.UserSSHKey)(nil)).Where("id = ?", id).Exec(ctx)
+	return err
+}
+
+// AuthorizedSSHKeys returns the registered SSH public keys of a user in authorized_keys format,
+// or nil if there are none. If forTrials is set, only keys to inject into trials are included.
+func AuthorizedSSHKeys(
+	ctx context.Context, userID model.UserID, forTrials bool,
+) ([]byte, error) {
+	var keys []string
+	q := db.Bun().NewSelect().Model((*model.UserSSHKey)(nil)).
+		Column("public_key").
+		Where("user_id = ?", userID).
+		Order("id")
+	if forTrials {
+		q = q.Where("inject_into_trials")
+	}
+	if err := q.Scan(ctx, &keys); err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return []byte(strings.Join(keys, "\n") + "\n"), nil
+}
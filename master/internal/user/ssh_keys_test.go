@@ -0,0 +1,41 @@
+package user
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	sshlib "golang.org/x/crypto/ssh"
+)
+
+func TestNewSSHKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := sshlib.NewPublicKey(pub)
+	require.NoError(t, err)
+	authorized := strings.TrimSpace(string(sshlib.MarshalAuthorizedKey(sshPub)))
+
+	key, err := NewSSHKey(1, authorized+" me@laptop\n", "", true)
+	require.NoError(t, err)
+	require.Equal(t, "me@laptop", key.Name)
+	require.Equal(t, authorized+" me@laptop", key.PublicKey)
+	require.Equal(t, sshlib.FingerprintSHA256(sshPub), key.Fingerprint)
+	require.True(t, key.InjectIntoTrials)
+
+	key, err = NewSSHKey(1, authorized, "work", false)
+	require.NoError(t, err)
+	require.Equal(t, "work", key.Name)
+	require.Equal(t, authorized, key.PublicKey)
+
+	for _, invalid := range []string{
+		"",
+		"not a key",
+		`command="/bin/sh" ` + authorized,
+		authorized + "\n" + authorized,
+	} {
+		_, err := NewSSHKey(1, invalid, "", false)
+		require.ErrorIs(t, err, ErrInvalidSSHKey, invalid)
+	}
+}
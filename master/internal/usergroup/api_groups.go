@@ -2,6 +2,7 @@ package usergroup
 
 import (
 	"context"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -9,6 +10,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/determined-ai/determined/master/internal/api/apiutils"
+	"github.com/determined-ai/determined/master/internal/auditlog"
 	"github.com/determined-ai/determined/master/internal/db"
 	"github.com/determined-ai/determined/master/internal/grpcutil"
 	"github.com/determined-ai/determined/master/pkg/model"
@@ -50,6 +52,13 @@ func (a *UserGroupAPIServer) CreateGroup(ctx context.Context, req *apiv1.CreateG
 	if err != nil {
 		return nil, err
 	}
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.GroupCreate,
+		TargetType: "group",
+		TargetID:   strconv.Itoa(createdGroup.ID),
+		Success:    true,
+		Details:    map[string]interface{}{"name": createdGroup.Name, "add_users": uids},
+	})
 
 	return &apiv1.CreateGroupResponse{
 		Group: &groupv1.GroupDetails{
@@ -187,6 +196,15 @@ func (a *UserGroupAPIServer) UpdateGroup(ctx context.Context, req *apiv1.UpdateG
 	if err != nil {
 		return nil, err
 	}
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.GroupUpdate,
+		TargetType: "group",
+		TargetID:   strconv.Itoa(int(req.GroupId)),
+		Success:    true,
+		Details: map[string]interface{}{
+			"name": newName, "add_users": addUsers, "remove_users": removeUsers,
+		},
+	})
 
 	resp = &apiv1.UpdateGroupResponse{
 		Group: &groupv1.GroupDetails{
@@ -221,6 +239,12 @@ func (a *UserGroupAPIServer) DeleteGroup(ctx context.Context, req *apiv1.DeleteG
 	if err != nil {
 		return nil, err
 	}
+	auditlog.Record(ctx, curUser, auditlog.Entry{
+		Action:     auditlog.GroupDelete,
+		TargetType: "group",
+		TargetID:   strconv.Itoa(int(req.GroupId)),
+		Success:    true,
+	})
 	return &apiv1.DeleteGroupResponse{}, nil
 }
 
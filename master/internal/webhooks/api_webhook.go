@@ -3,7 +3,9 @@ package webhooks
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/url"
 	"time"
@@ -13,11 +15,14 @@ import (
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 
+	"github.com/determined-ai/determined/master/internal/db"
 	"github.com/determined-ai/determined/master/internal/grpcutil"
 	"github.com/determined-ai/determined/master/pkg/ptrs"
 
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/webhookv1"
 )
 
 // WebhooksAPIServer is an embedded api server struct.
@@ -53,6 +58,22 @@ func (a *WebhooksAPIServer) GetWebhooks(
 	return &apiv1.GetWebhooksResponse{Webhooks: webhooks.Proto()}, nil
 }
 
+// GetWebhook returns a Webhook.
+func (a *WebhooksAPIServer) GetWebhook(
+	ctx context.Context, req *apiv1.GetWebhookRequest,
+) (*apiv1.GetWebhookResponse, error) {
+	if err := AuthorizeRequest(ctx); err != nil {
+		return nil, err
+	}
+	webhook, err := GetWebhook(ctx, int(req.WebhookId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "webhook %d not found", req.WebhookId)
+	} else if err != nil {
+		return nil, err
+	}
+	return &apiv1.GetWebhookResponse{Webhook: webhook.Proto()}, nil
+}
+
 // PostWebhook creates a new Webhook.
 func (a *WebhooksAPIServer) PostWebhook(
 	ctx context.Context, req *apiv1.PostWebhookRequest,
@@ -72,6 +93,14 @@ func (a *WebhooksAPIServer) PostWebhook(
 			"valid url required",
 		)
 	}
+	if err := validateTriggers(req.Webhook.Triggers); err != nil {
+		return nil, err
+	}
+	if req.Webhook.WorkspaceId != nil {
+		if err := validateWorkspace(ctx, *req.Webhook.WorkspaceId); err != nil {
+			return nil, err
+		}
+	}
 	w := WebhookFromProto(req.Webhook)
 	if err := AddWebhook(ctx, &w); err != nil {
 		return nil, err
@@ -79,6 +108,83 @@ func (a *WebhooksAPIServer) PostWebhook(
 	return &apiv1.PostWebhookResponse{Webhook: w.Proto()}, nil
 }
 
+// PatchWebhook updates a Webhook.
+func (a *WebhooksAPIServer) PatchWebhook(
+	ctx context.Context, req *apiv1.PatchWebhookRequest,
+) (*apiv1.PatchWebhookResponse, error) {
+	if err := AuthorizeRequest(ctx); err != nil {
+		return nil, err
+	}
+
+	var u WebhookUpdate
+	if req.Webhook.Url != nil {
+		if _, err := url.ParseRequestURI(req.Webhook.Url.Value); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "valid url required")
+		}
+		u.URL = &req.Webhook.Url.Value
+	}
+	if len(req.Webhook.Triggers) != 0 {
+		if err := validateTriggers(req.Webhook.Triggers); err != nil {
+			return nil, err
+		}
+		u.Triggers = TriggersFromProto(req.Webhook.Triggers)
+	}
+	if req.Webhook.Secret != nil {
+		u.Secret = &req.Webhook.Secret.Value
+	}
+	if req.Webhook.WorkspaceId != nil {
+		if id := req.Webhook.WorkspaceId.Value; id != 0 {
+			if err := validateWorkspace(ctx, id); err != nil {
+				return nil, err
+			}
+		}
+		u.WorkspaceID = &req.Webhook.WorkspaceId.Value
+	}
+
+	switch err := UpdateWebhook(ctx, WebhookID(req.Id), u); {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "webhook %d not found", req.Id)
+	case err != nil:
+		return nil, err
+	}
+	webhook, err := GetWebhook(ctx, int(req.Id))
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.PatchWebhookResponse{Webhook: webhook.Proto()}, nil
+}
+
+func validateTriggers(ts []*webhookv1.Trigger) error {
+	for _, t := range ts {
+		switch t.TriggerType {
+		case webhookv1.TriggerType_TRIGGER_TYPE_EXPERIMENT_STATE_CHANGE,
+			webhookv1.TriggerType_TRIGGER_TYPE_TASK_STATE_CHANGE:
+			if t.Condition == nil || t.Condition.Fields["state"].GetStringValue() == "" {
+				return status.Errorf(codes.InvalidArgument,
+					"%s triggers require a state condition", t.TriggerType)
+			}
+		case webhookv1.TriggerType_TRIGGER_TYPE_UNSPECIFIED:
+			return status.Error(codes.InvalidArgument, "trigger type required")
+		}
+		if t.Condition == nil {
+			t.Condition = &structpb.Struct{}
+		}
+	}
+	return nil
+}
+
+func validateWorkspace(ctx context.Context, id int32) error {
+	exists, err := db.Bun().NewSelect().Table("workspaces").Where("id = ?", id).Exists(ctx)
+	switch {
+	case err != nil:
+		return err
+	case !exists:
+		return status.Errorf(codes.InvalidArgument, "workspace %d not found", id)
+	default:
+		return nil
+	}
+}
+
 // DeleteWebhook deletes a Webhook.
 func (a *WebhooksAPIServer) DeleteWebhook(
 	ctx context.Context, req *apiv1.DeleteWebhookRequest,
@@ -127,7 +233,7 @@ func (a *WebhooksAPIServer) TestWebhook(
 			return nil, err
 		}
 
-		tr, rerr := generateWebhookRequest(ctx, webhook.URL, p, t)
+		tr, rerr := generateWebhookRequest(ctx, webhook.URL, p, t, signingKey(webhook.Secret))
 		if rerr != nil {
 			return nil, status.Errorf(codes.InvalidArgument,
 				"failed to create webhook request for event %v error : %v ", eventID, err)
@@ -160,6 +266,23 @@ func (a *WebhooksAPIServer) TestWebhook(
 				"failed to create webhook request for event %v error : %v ", eventID, err)
 		}
 		tReq = tr
+	case WebhookTypeTeams:
+		teamsMessage, terr := generateTextPayload(WebhookTypeTeams, "test")
+		if terr != nil {
+			return nil, terr
+		}
+
+		tr, rerr := http.NewRequestWithContext(
+			ctx,
+			http.MethodPost,
+			webhook.URL,
+			bytes.NewBuffer(teamsMessage),
+		)
+		if rerr != nil {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"failed to create webhook request for event %v error : %v ", eventID, rerr)
+		}
+		tReq = tr
 	default:
 		panic("Unknown webhook type")
 	}
@@ -10,7 +10,9 @@ import (
 // WebhookAuthZ describes authz methods for experiments.
 type WebhookAuthZ interface {
 	// GET /api/v1/webhooks
+	// GET /api/v1/webhooks/:webhook_id
 	// POST /api/v1/webhooks
+	// PATCH /api/v1/webhooks/:webhook_id
 	// DELETE /api/v1/webhooks/:webhook_id
 	// POST /api/v1/webhooks/test/:webhook_id
 	CanEditWebhooks(ctx context.Context, curUser *model.User) (serverError error)
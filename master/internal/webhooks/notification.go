@@ -0,0 +1,277 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	conf "github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/workspace"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// link is a piece of text that links to url, if it is set.
+type link struct {
+	text string
+	url  string
+}
+
+func (l link) slack() string {
+	if l.url == "" {
+		return l.text
+	}
+	return fmt.Sprintf("<%v | %v>", l.url, l.text)
+}
+
+func (l link) markdown() string {
+	if l.url == "" {
+		return l.text
+	}
+	return fmt.Sprintf("[%v](%v)", l.text, l.url)
+}
+
+// experimentNotification is what chat messages about an experiment reaching a terminal state
+// tell about it, independent of how each chat service formats it.
+type experimentNotification struct {
+	completed  bool
+	experiment link
+	workspace  *link
+	project    *link
+	duration   string
+	// metric is the best value of the searcher metric of the experiment, if it has one.
+	metric *metricSummary
+}
+
+type metricSummary struct {
+	name  string
+	value float64
+}
+
+func newExperimentNotification(
+	ctx context.Context, e model.Experiment,
+) (*experimentNotification, error) {
+	webUIBaseURL := conf.GetMasterConfig().Webhooks.BaseURL
+	wName := e.Config.Workspace()
+	pName := e.Config.Project()
+	n := &experimentNotification{
+		completed: e.State == model.CompletedState,
+		experiment: link{
+			text: fmt.Sprintf("%v (#%v)", e.Config.Name(), e.ID),
+		},
+	}
+	if webUIBaseURL != "" {
+		n.experiment.url = fmt.Sprintf("%v/det/experiments/%v/overview", webUIBaseURL, e.ID)
+	}
+
+	if wName != "" {
+		n.workspace = &link{text: wName}
+	}
+	if pName != "" {
+		n.project = &link{text: pName}
+	}
+	if webUIBaseURL != "" && wName != "" && pName != "" {
+		w, err := workspace.WorkspaceByName(ctx, wName)
+		if err != nil {
+			return nil, err
+		}
+		if w == nil {
+			return nil, fmt.Errorf("unable to find workspace with name: %v", wName)
+		}
+		n.workspace.url = fmt.Sprintf("%v/det/workspaces/%v/projects", webUIBaseURL, w.ID)
+
+		pID, err := workspace.ProjectIDByName(ctx, w.ID, pName)
+		if err != nil {
+			return nil, err
+		}
+		if pID != nil && *pID != 0 {
+			n.project.url = fmt.Sprintf("%v/det/projects/%v", webUIBaseURL, *pID)
+		}
+	}
+
+	if e.EndTime != nil {
+		hours := e.EndTime.Sub(e.StartTime).Hours()
+		hours, m := math.Modf(hours)
+		n.duration = fmt.Sprintf("%vh %vmin", hours, int(m*60))
+	}
+
+	metric, err := bestSearcherMetric(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+	n.metric = metric
+	return n, nil
+}
+
+// bestSearcherMetric returns the best validation value of the searcher metric of the experiment,
+// or nil if it has none.
+func bestSearcherMetric(ctx context.Context, e model.Experiment) (*metricSummary, error) {
+	name := e.Config.Searcher().Metric()
+	if name == "" {
+		return nil, nil
+	}
+	order := "DESC"
+	if e.Config.Searcher().SmallerIsBetter() {
+		order = "ASC"
+	}
+
+	var value float64
+	switch err := db.Bun().NewRaw(fmt.Sprintf(`
+SELECT (v.metrics->'validation_metrics'->>?0)::float8
+FROM validations v
+JOIN trials t ON v.trial_id = t.id
+WHERE t.experiment_id = ?1
+  AND v.state = 'COMPLETED'
+  AND jsonb_typeof(v.metrics->'validation_metrics'->?0) = 'number'
+ORDER BY 1 %s
+LIMIT 1`, order), name, e.ID).Scan(ctx, &value); {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("querying best searcher metric: %w", err)
+	}
+	return &metricSummary{name: name, value: value}, nil
+}
+
+func (n *experimentNotification) status() (message, state, color, emoji string) {
+	if n.completed {
+		return "Your experiment completed successfully 🎉", "Completed", "#13B670", "✅"
+	}
+	return "Your experiment has stopped with errors", "Errored", "#DD5040", "❌"
+}
+
+// slack formats the notification as a Slack message.
+func (n *experimentNotification) slack() ([]byte, error) {
+	message, state, color, emoji := n.status()
+	fields := []SlackField{
+		{Type: "mrkdwn", Text: fmt.Sprintf("*Status*: %v", state)},
+		{Type: "mrkdwn", Text: fmt.Sprintf("*Duration*: %v", n.duration)},
+	}
+	if n.metric != nil {
+		fields = append(fields, SlackField{
+			Type: "mrkdwn",
+			Text: fmt.Sprintf("*Best %v*: %v", n.metric.name, formatMetric(n.metric.value)),
+		})
+	}
+	if n.workspace != nil {
+		fields = append(fields, SlackField{
+			Type: "mrkdwn",
+			Text: fmt.Sprintf("*Workspace*: %v", n.workspace.slack()),
+		})
+	}
+	if n.project != nil {
+		fields = append(fields, SlackField{
+			Type: "mrkdwn",
+			Text: fmt.Sprintf("*Project*: %v", n.project.slack()),
+		})
+	}
+
+	body := SlackMessageBody{
+		Blocks: []SlackBlock{{
+			Text: SlackField{Text: message, Type: "plain_text"},
+			Type: "section",
+		}},
+		Attachments: &[]SlackAttachment{{
+			Color: color,
+			Blocks: []SlackBlock{{
+				Text: SlackField{
+					Type: "mrkdwn",
+					Text: fmt.Sprintf("%v %v", emoji, n.experiment.slack()),
+				},
+				Type:   "section",
+				Fields: &fields,
+			}},
+		}},
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating slack payload: %w", err)
+	}
+	return payload, nil
+}
+
+// teams formats the notification as a Microsoft Teams message card.
+func (n *experimentNotification) teams() ([]byte, error) {
+	message, state, color, emoji := n.status()
+	facts := []TeamsFact{
+		{Name: "Status", Value: state},
+		{Name: "Duration", Value: n.duration},
+	}
+	if n.metric != nil {
+		facts = append(facts, TeamsFact{
+			Name:  "Best " + n.metric.name,
+			Value: formatMetric(n.metric.value),
+		})
+	}
+	if n.workspace != nil {
+		facts = append(facts, TeamsFact{Name: "Workspace", Value: n.workspace.markdown()})
+	}
+	if n.project != nil {
+		facts = append(facts, TeamsFact{Name: "Project", Value: n.project.markdown()})
+	}
+
+	card := newTeamsMessageCard(message)
+	card.ThemeColor = strings.TrimPrefix(color, "#")
+	card.Sections = []TeamsSection{{
+		ActivityTitle: fmt.Sprintf("%v %v", emoji, n.experiment.markdown()),
+		Facts:         facts,
+		Markdown:      true,
+	}}
+	if n.experiment.url != "" {
+		card.PotentialAction = []TeamsAction{{
+			Type:    "OpenUri",
+			Name:    "View experiment",
+			Targets: []TeamsTarget{{OS: "default", URI: n.experiment.url}},
+		}}
+	}
+	payload, err := json.Marshal(card)
+	if err != nil {
+		return nil, fmt.Errorf("error creating teams payload: %w", err)
+	}
+	return payload, nil
+}
+
+// generateTextPayload returns a chat message consisting of a single line of markdown text, for
+// events that do not have a richer message of their own.
+func generateTextPayload(wt WebhookType, text string) ([]byte, error) {
+	var message interface{}
+	switch wt {
+	case WebhookTypeSlack:
+		message = SlackMessageBody{
+			Blocks: []SlackBlock{{
+				Type: "section",
+				Text: SlackField{Type: "mrkdwn", Text: text},
+			}},
+		}
+	case WebhookTypeTeams:
+		card := newTeamsMessageCard(text)
+		card.Text = text
+		message = card
+	default:
+		return nil, fmt.Errorf("webhook type %s does not take text messages", wt)
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("error creating %s payload: %w", strings.ToLower(string(wt)), err)
+	}
+	return payload, nil
+}
+
+func newTeamsMessageCard(summary string) TeamsMessageCard {
+	return TeamsMessageCard{
+		Type:    "MessageCard",
+		Context: "https://schema.org/extensions",
+		Summary: summary,
+		Title:   summary,
+	}
+}
+
+// formatMetric formats a metric with enough precision to tell apart values that are close.
+func formatMetric(v float64) string {
+	return fmt.Sprintf("%.6g", v)
+}
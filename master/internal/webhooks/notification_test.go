@@ -0,0 +1,85 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testNotification() *experimentNotification {
+	return &experimentNotification{
+		completed: true,
+		experiment: link{
+			text: "mnist (#7)",
+			url:  "http://det/det/experiments/7/overview",
+		},
+		workspace: &link{text: "ws", url: "http://det/det/workspaces/2/projects"},
+		project:   &link{text: "proj"},
+		duration:  "1h 30min",
+		metric:    &metricSummary{name: "loss", value: 0.0123456789},
+	}
+}
+
+func TestSlackNotification(t *testing.T) {
+	payload, err := testNotification().slack()
+	require.NoError(t, err)
+
+	var body SlackMessageBody
+	require.NoError(t, json.Unmarshal(payload, &body))
+	require.Len(t, *body.Attachments, 1)
+	a := (*body.Attachments)[0]
+	require.Equal(t, "#13B670", a.Color)
+	require.Equal(t, "✅ <http://det/det/experiments/7/overview | mnist (#7)>", a.Blocks[0].Text.Text)
+
+	var fields []string
+	for _, f := range *a.Blocks[0].Fields {
+		fields = append(fields, f.Text)
+	}
+	require.Equal(t, []string{
+		"*Status*: Completed",
+		"*Duration*: 1h 30min",
+		"*Best loss*: 0.0123457",
+		"*Workspace*: <http://det/det/workspaces/2/projects | ws>",
+		"*Project*: proj",
+	}, fields)
+}
+
+func TestTeamsNotification(t *testing.T) {
+	n := testNotification()
+	n.completed = false
+	payload, err := n.teams()
+	require.NoError(t, err)
+
+	var card TeamsMessageCard
+	require.NoError(t, json.Unmarshal(payload, &card))
+	require.Equal(t, "MessageCard", card.Type)
+	require.Equal(t, "DD5040", card.ThemeColor)
+	require.Equal(t, "Your experiment has stopped with errors", card.Summary)
+	require.Len(t, card.Sections, 1)
+	require.Equal(t, "❌ [mnist (#7)](http://det/det/experiments/7/overview)",
+		card.Sections[0].ActivityTitle)
+	require.Equal(t, []TeamsFact{
+		{Name: "Status", Value: "Errored"},
+		{Name: "Duration", Value: "1h 30min"},
+		{Name: "Best loss", Value: "0.0123457"},
+		{Name: "Workspace", Value: "[ws](http://det/det/workspaces/2/projects)"},
+		{Name: "Project", Value: "proj"},
+	}, card.Sections[0].Facts)
+	require.Equal(t, []TeamsAction{{
+		Type:    "OpenUri",
+		Name:    "View experiment",
+		Targets: []TeamsTarget{{OS: "default", URI: "http://det/det/experiments/7/overview"}},
+	}}, card.PotentialAction)
+}
+
+func TestTextPayload(t *testing.T) {
+	payload, err := generateTextPayload(WebhookTypeTeams, "Task a is now RUNNING")
+	require.NoError(t, err)
+	var card TeamsMessageCard
+	require.NoError(t, json.Unmarshal(payload, &card))
+	require.Equal(t, "Task a is now RUNNING", card.Text)
+
+	_, err = generateTextPayload(WebhookTypeDefault, "text")
+	require.Error(t, err)
+}
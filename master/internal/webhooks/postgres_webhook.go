@@ -4,16 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/uptrace/bun"
 
-	conf "github.com/determined-ai/determined/master/internal/config"
 	"github.com/determined-ai/determined/master/internal/db"
-	"github.com/determined-ai/determined/master/internal/workspace"
 	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
 
 	"github.com/google/uuid"
 )
@@ -66,6 +64,58 @@ func GetWebhooks(ctx context.Context) (Webhooks, error) {
 	return webhooks, nil
 }
 
+// WebhookUpdate is a set of changes to a Webhook. Nil fields are left as they are.
+type WebhookUpdate struct {
+	URL *string
+	// Triggers, if non-empty, replace those of the webhook.
+	Triggers Triggers
+	// Secret, if set, replaces the secret of the webhook; an empty secret removes it.
+	Secret *string
+	// WorkspaceID, if set, replaces the workspace of the webhook; zero removes it.
+	WorkspaceID *int32
+}
+
+// UpdateWebhook applies an update to a Webhook in the DB.
+func UpdateWebhook(ctx context.Context, id WebhookID, u WebhookUpdate) error {
+	return db.Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		q := tx.NewUpdate().Model((*Webhook)(nil)).Where("id = ?", id)
+		if u.URL != nil {
+			q = q.Set("url = ?", *u.URL)
+		}
+		if u.Secret != nil {
+			q = q.Set("secret = NULLIF(?, '')", *u.Secret)
+		}
+		if u.WorkspaceID != nil {
+			q = q.Set("workspace_id = NULLIF(?, 0)", *u.WorkspaceID)
+		}
+		// Always set the id, so that there is something to update and a missing webhook is
+		// reported even if nothing else changes.
+		res, err := q.Set("id = id").Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return db.ErrNotFound
+		}
+
+		if len(u.Triggers) == 0 {
+			return nil
+		}
+		if _, err := tx.NewDelete().Model((*Trigger)(nil)).
+			Where("webhook_id = ?", id).
+			Exec(ctx); err != nil {
+			return err
+		}
+		for _, t := range u.Triggers {
+			t.WebhookID = id
+		}
+		_, err = tx.NewInsert().Model(&u.Triggers).Exec(ctx)
+		return err
+	})
+}
+
 // DeleteWebhook deletes a Webhook and its Triggers from the DB.
 func DeleteWebhook(ctx context.Context, id WebhookID) error {
 	_, err := db.Bun().NewDelete().Model((*Webhook)(nil)).Where("id = ?", id).Exec(ctx)
@@ -83,24 +133,215 @@ func ReportExperimentStateChanged(ctx context.Context, e model.Experiment) error
 		}
 	}()
 
-	var ts []Trigger
-	switch err := db.Bun().NewSelect().Model(&ts).Relation("Webhook").
-		Where("trigger_type = ?", TriggerTypeStateChange).
-		Where("condition->>'state' = ?", e.State).
-		Scan(ctx); {
-	case err != nil:
+	workspaceID := db.Bun().NewSelect().Table("projects").Column("workspace_id").
+		Where("id = ?", e.ProjectID)
+	ts, err := matchingTriggers(ctx, TriggerTypeStateChange, workspaceID, func(q *bun.SelectQuery) {
+		q.Where("condition->>'state' = ?", e.State)
+	})
+	if err != nil {
+		return err
+	}
+	return enqueueEvents(ctx, ts, func(w *Webhook) ([]byte, error) {
+		return generateEventPayload(ctx, w.WebhookType, e, e.State, TriggerTypeStateChange)
+	})
+}
+
+// ReportCheckpointCreated adds webhook events to the queue for a checkpoint reported by a trial
+// of the experiment.
+func ReportCheckpointCreated(
+	ctx context.Context, e model.Experiment, c model.CheckpointV2,
+) error {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Errorf("uncaught error in webhook report: %v", rec)
+		}
+	}()
+
+	workspaceID := db.Bun().NewSelect().Table("projects").Column("workspace_id").
+		Where("id = ?", e.ProjectID)
+	ts, err := matchingTriggers(ctx, TriggerTypeCheckpointCreated, workspaceID, nil)
+	if err != nil {
+		return err
+	}
+
+	cp := &CheckpointPayload{
+		UUID:         c.UUID.String(),
+		ExperimentID: e.ID,
+		TaskID:       string(c.TaskID),
+		AllocationID: string(c.AllocationID),
+		State:        c.State,
+		ReportTime:   c.ReportTime,
+	}
+	if steps, ok := c.Metadata["steps_completed"].(float64); ok {
+		cp.StepsCompleted = ptrs.Ptr(int(steps))
+	}
+	for _, size := range c.Resources {
+		cp.Size += size
+	}
+	return enqueueEvents(ctx, ts, func(w *Webhook) ([]byte, error) {
+		switch w.WebhookType {
+		case WebhookTypeSlack, WebhookTypeTeams:
+			return generateTextPayload(w.WebhookType, fmt.Sprintf(
+				"💾 Checkpoint %s was created by experiment %d", cp.UUID, e.ID))
+		default:
+			return json.Marshal(EventPayload{
+				ID:        uuid.New(),
+				Type:      TriggerTypeCheckpointCreated,
+				Timestamp: time.Now().Unix(),
+				Data:      EventData{Checkpoint: cp},
+			})
+		}
+	})
+}
+
+// ReportTaskStateChanged adds webhook events to the queue for a task's allocation changing state.
+func ReportTaskStateChanged(ctx context.Context, t TaskPayload, workspaceID *int) error {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Errorf("uncaught error in webhook report: %v", rec)
+		}
+	}()
+
+	ts, err := matchingTriggers(ctx, TriggerTypeTaskStateChange, workspaceID,
+		func(q *bun.SelectQuery) {
+			q.Where("condition->>'state' = ?", t.State)
+		})
+	if err != nil {
+		return err
+	}
+	return enqueueEvents(ctx, ts, func(w *Webhook) ([]byte, error) {
+		switch w.WebhookType {
+		case WebhookTypeSlack, WebhookTypeTeams:
+			return generateTextPayload(w.WebhookType, fmt.Sprintf(
+				"Task %s (%s) is now %s", t.Name, t.ID, t.State))
+		default:
+			return json.Marshal(EventPayload{
+				ID:        uuid.New(),
+				Type:      TriggerTypeTaskStateChange,
+				Timestamp: time.Now().Unix(),
+				Condition: Condition{State: string(t.State)},
+				Data:      EventData{Task: &t},
+			})
+		}
+	})
+}
+
+// ReportMetricThresholdExceeded adds webhook events to the queue for an alert of the experiment
+// firing for one of its trials. Triggers with an alert condition only match the alert it names.
+func ReportMetricThresholdExceeded(
+	ctx context.Context, e model.Experiment, a AlertPayload,
+) error {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Errorf("uncaught error in webhook report: %v", rec)
+		}
+	}()
+
+	workspaceID := db.Bun().NewSelect().Table("projects").Column("workspace_id").
+		Where("id = ?", e.ProjectID)
+	ts, err := matchingTriggers(ctx, TriggerTypeMetricThresholdExceeded, workspaceID,
+		func(q *bun.SelectQuery) {
+			q.Where("coalesce(condition->>'alert', '') IN ('', ?)", a.Name)
+		})
+	if err != nil {
+		return err
+	}
+	return enqueueEvents(ctx, ts, func(w *Webhook) ([]byte, error) {
+		switch w.WebhookType {
+		case WebhookTypeSlack, WebhookTypeTeams:
+			text := fmt.Sprintf("🚨 Alert %s fired for trial %d of experiment %d: %s metric %s "+
+				"was %v after %d batches", a.Name, a.TrialID, e.ID, a.MetricGroup, a.Metric,
+				a.Value, a.TotalBatches)
+			if a.PauseExperiment {
+				text += ", so the experiment was paused"
+			}
+			return generateTextPayload(w.WebhookType, text)
+		default:
+			return json.Marshal(EventPayload{
+				ID:        uuid.New(),
+				Type:      TriggerTypeMetricThresholdExceeded,
+				Timestamp: time.Now().Unix(),
+				Condition: Condition{Alert: a.Name},
+				Data:      EventData{Experiment: experimentToWebhookPayload(e), Alert: &a},
+			})
+		}
+	})
+}
+
+// ReportScheduledRunFailed adds webhook events to the queue for a run of an experiment schedule in
+// the project failing. Triggers with a schedule condition only match the schedule it names.
+func ReportScheduledRunFailed(ctx context.Context, projectID int, r RunPayload) error {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Errorf("uncaught error in webhook report: %v", rec)
+		}
+	}()
+
+	workspaceID := db.Bun().NewSelect().Table("projects").Column("workspace_id").
+		Where("id = ?", projectID)
+	ts, err := matchingTriggers(ctx, TriggerTypeScheduledRunFailed, workspaceID,
+		func(q *bun.SelectQuery) {
+			q.Where("coalesce(condition->>'schedule', '') IN ('', ?)", r.ScheduleName)
+		})
+	if err != nil {
 		return err
-	case len(ts) == 0:
+	}
+	return enqueueEvents(ctx, ts, func(w *Webhook) ([]byte, error) {
+		switch w.WebhookType {
+		case WebhookTypeSlack, WebhookTypeTeams:
+			text := fmt.Sprintf("❌ The run of schedule %s at %s failed to submit an experiment: %s",
+				r.ScheduleName, r.ScheduledAt.Format(time.RFC3339), r.Error)
+			if r.ExperimentID != nil {
+				text = fmt.Sprintf("❌ Experiment %d of the run of schedule %s at %s failed: %s",
+					*r.ExperimentID, r.ScheduleName, r.ScheduledAt.Format(time.RFC3339), r.Error)
+			}
+			return generateTextPayload(w.WebhookType, text)
+		default:
+			return json.Marshal(EventPayload{
+				ID:        uuid.New(),
+				Type:      TriggerTypeScheduledRunFailed,
+				Timestamp: time.Now().Unix(),
+				Condition: Condition{Schedule: r.ScheduleName},
+				Data:      EventData{Run: &r},
+			})
+		}
+	})
+}
+
+// matchingTriggers returns the triggers of a type, along with their webhooks, that apply to an
+// event from the given workspace, which may be a query for it. Webhooks without a workspace apply
+// to events from every workspace.
+func matchingTriggers(
+	ctx context.Context, tt TriggerType, workspaceID interface{}, where func(*bun.SelectQuery),
+) ([]Trigger, error) {
+	var ts []Trigger
+	q := db.Bun().NewSelect().Model(&ts).Relation("Webhook").
+		Where("trigger_type = ?", tt).
+		Where("webhook.workspace_id IS NULL OR webhook.workspace_id = (?)", workspaceID)
+	if where != nil {
+		where(q)
+	}
+	if err := q.Scan(ctx); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+// enqueueEvents adds an event for each trigger to the queue, and wakes the shipper to send them.
+func enqueueEvents(
+	ctx context.Context, ts []Trigger, payload func(*Webhook) ([]byte, error),
+) error {
+	if len(ts) == 0 {
 		return nil
 	}
 
 	var es []Event
 	for _, t := range ts {
-		p, err := generateEventPayload(ctx, t.Webhook.WebhookType, e, e.State, TriggerTypeStateChange)
+		p, err := payload(t.Webhook)
 		if err != nil {
 			return fmt.Errorf("error generating event payload: %w", err)
 		}
-		es = append(es, Event{Payload: p, URL: t.Webhook.URL})
+		es = append(es, Event{Payload: p, URL: t.Webhook.URL, WebhookID: &t.Webhook.ID})
 	}
 	if _, err := db.Bun().NewInsert().Model(&es).Exec(ctx); err != nil {
 		return err
@@ -124,7 +365,7 @@ func generateEventPayload(
 			Type:      tT,
 			Timestamp: time.Now().Unix(),
 			Condition: Condition{
-				State: expState,
+				State: string(expState),
 			},
 			Data: EventData{
 				Experiment: experimentToWebhookPayload(e),
@@ -134,141 +375,20 @@ func generateEventPayload(
 			return nil, err
 		}
 		return pJSON, nil
-	case WebhookTypeSlack:
-		slackJSON, err := generateSlackPayload(ctx, e)
+	case WebhookTypeSlack, WebhookTypeTeams:
+		n, err := newExperimentNotification(ctx, e)
 		if err != nil {
 			return nil, err
 		}
-		return slackJSON, nil
+		if wt == WebhookTypeTeams {
+			return n.teams()
+		}
+		return n.slack()
 	default:
 		panic(fmt.Errorf("unknown webhook type: %+v", wt))
 	}
 }
 
-func generateSlackPayload(ctx context.Context, e model.Experiment) ([]byte, error) {
-	var status string
-	var eURL string
-	var c string
-	var mStatus string
-	var projectID int
-	var wID int
-	var w *model.Workspace
-	config := conf.GetMasterConfig()
-	wName := e.Config.Workspace()
-	pName := e.Config.Project()
-	webUIBaseURL := config.Webhooks.BaseURL
-	baseURLIsSet := webUIBaseURL != ""
-	if baseURLIsSet && wName != "" && pName != "" {
-		ws, err := workspace.WorkspaceByName(ctx, wName)
-		if err != nil {
-			return nil, err
-		}
-		w = ws
-
-		if w == nil {
-			return nil, fmt.Errorf("unable to find workspace with name: %v", wName)
-		}
-		wID = w.ID
-
-		pID, err := workspace.ProjectIDByName(ctx, wID, pName)
-		if pID != nil {
-			projectID = *pID
-		}
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if e.State == model.CompletedState {
-		status = "Your experiment completed successfully 🎉"
-		if baseURLIsSet {
-			eURL = fmt.Sprintf("✅ <%v/det/experiments/%v/overview | %v (#%v)>",
-				webUIBaseURL, e.ID, e.Config.Name(), e.ID)
-		} else {
-			eURL = fmt.Sprintf("✅ %v (#%v)", e.Config.Name(), e.ID)
-		}
-		c = "#13B670"
-		mStatus = "Completed"
-	} else {
-		status = "Your experiment has stopped with errors"
-		if baseURLIsSet {
-			eURL = fmt.Sprintf("❌ <%v/det/experiments/%v/overview | %v (#%v)>",
-				webUIBaseURL, e.ID, e.Config.Name(), e.ID)
-		} else {
-			eURL = fmt.Sprintf("❌ %v (#%v)", e.Config.Name(), e.ID)
-		}
-		c = "#DD5040"
-		mStatus = "Errored"
-	}
-	hours := e.EndTime.Sub(e.StartTime).Hours()
-	hours, m := math.Modf(hours)
-	minutes := int(m * 60)
-	duration := fmt.Sprintf("%vh %vmin", hours, minutes)
-	expBlockFields := []SlackField{
-		{
-			Type: "mrkdwn",
-			Text: fmt.Sprintf("*Status*: %v", mStatus),
-		},
-		{
-			Type: "mrkdwn",
-			Text: fmt.Sprintf("*Duration*: %v", duration),
-		},
-	}
-	if wID != 0 && wName != "" && baseURLIsSet {
-		expBlockFields = append(expBlockFields, SlackField{
-			Type: "mrkdwn",
-			Text: fmt.Sprintf("*Workspace*: <%v/det/workspaces/%v/projects | %v>",
-				webUIBaseURL, wID, wName),
-		})
-	} else if wName != "" {
-		expBlockFields = append(expBlockFields, SlackField{
-			Type: "mrkdwn",
-			Text: fmt.Sprintf("*Workspace*: %v", wName),
-		})
-	}
-	if projectID != 0 && pName != "" && baseURLIsSet {
-		expBlockFields = append(expBlockFields, SlackField{
-			Type: "mrkdwn",
-			Text: fmt.Sprintf("*Project*: <%v/det/projects/%v | %v>",
-				webUIBaseURL, projectID, pName),
-		})
-	} else if pName != "" {
-		expBlockFields = append(expBlockFields, SlackField{
-			Type: "mrkdwn",
-			Text: fmt.Sprintf("*Project*: %v", pName),
-		})
-	}
-	experimentBlock := SlackBlock{
-		Text: SlackField{
-			Type: "mrkdwn",
-			Text: eURL,
-		},
-		Type:   "section",
-		Fields: &expBlockFields,
-	}
-	messageBlock := SlackBlock{
-		Text: SlackField{
-			Text: status,
-			Type: "plain_text",
-		},
-		Type: "section",
-	}
-	attachment := SlackAttachment{
-		Color:  c,
-		Blocks: []SlackBlock{experimentBlock},
-	}
-	messageBody := SlackMessageBody{
-		Blocks:      []SlackBlock{messageBlock},
-		Attachments: &[]SlackAttachment{attachment},
-	}
-
-	message, err := json.Marshal(messageBody)
-	if err != nil {
-		return nil, fmt.Errorf("error creating slack payload: %w", err)
-	}
-	return message, nil
-}
-
 type eventBatch struct {
 	tx       *bun.Tx
 	events   []Event
@@ -299,7 +419,9 @@ func dequeueEvents(ctx context.Context, limit int) (*eventBatch, error) {
 	if err = tx.NewRaw(`
 DELETE FROM webhook_events_queue
 USING ( SELECT * FROM webhook_events_queue LIMIT ? FOR UPDATE SKIP LOCKED ) q
-WHERE q.id = webhook_events_queue.id RETURNING webhook_events_queue.*
+WHERE q.id = webhook_events_queue.id
+RETURNING webhook_events_queue.*,
+  (SELECT secret FROM webhooks w WHERE w.id = webhook_events_queue.webhook_id) AS secret
 `, limit).Scan(ctx, &events); err != nil {
 		return nil, fmt.Errorf("scanning events: %w", err)
 	}
@@ -4,12 +4,17 @@ package webhooks
 
 import (
 	"context"
+	"encoding/json"
+	"sort"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
 	"github.com/determined-ai/determined/master/internal/db"
 	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
 	"github.com/determined-ai/determined/master/pkg/schemas"
 	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
 )
@@ -168,6 +173,225 @@ func TestReportExperimentStateChanged(t *testing.T) {
 	})
 }
 
+func TestUpdateWebhook(t *testing.T) {
+	ctx := context.Background()
+	pgDB := db.MustResolveTestPostgres(t)
+	db.MustMigrateTestPostgres(t, pgDB, db.MigrationsFromDB)
+	clearWebhooksTables(ctx, t)
+
+	w := mockWebhook()
+	w.Secret = ptrs.Ptr("old")
+	w.Triggers = Triggers{{
+		TriggerType: TriggerTypeStateChange,
+		Condition:   map[string]interface{}{"state": model.CompletedState},
+	}}
+	require.NoError(t, AddWebhook(ctx, w))
+
+	require.NoError(t, UpdateWebhook(ctx, w.ID, WebhookUpdate{
+		URL:         ptrs.Ptr("http://localhost:9090"),
+		Secret:      ptrs.Ptr(""),
+		WorkspaceID: ptrs.Ptr(int32(1)),
+		Triggers: Triggers{{
+			TriggerType: TriggerTypeCheckpointCreated,
+			Condition:   map[string]interface{}{},
+		}},
+	}))
+	updated, err := GetWebhook(ctx, int(w.ID))
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:9090", updated.URL)
+	require.Nil(t, updated.Secret)
+	require.Equal(t, ptrs.Ptr(int32(1)), updated.WorkspaceID)
+	require.Len(t, updated.Triggers, 1)
+	require.Equal(t, TriggerTypeCheckpointCreated, updated.Triggers[0].TriggerType)
+
+	require.NoError(t, UpdateWebhook(ctx, w.ID, WebhookUpdate{WorkspaceID: ptrs.Ptr(int32(0))}))
+	updated, err = GetWebhook(ctx, int(w.ID))
+	require.NoError(t, err)
+	require.Nil(t, updated.WorkspaceID)
+	require.Len(t, updated.Triggers, 1, "triggers should be kept when not updated")
+
+	require.ErrorIs(t, UpdateWebhook(ctx, w.ID+1, WebhookUpdate{}), db.ErrNotFound)
+}
+
+func TestReportWebhookEvents(t *testing.T) {
+	ctx := context.Background()
+	pgDB := db.MustResolveTestPostgres(t)
+	db.MustMigrateTestPostgres(t, pgDB, db.MigrationsFromDB)
+	clearWebhooksTables(ctx, t)
+
+	singletonShipper = &shipper{wake: make(chan<- struct{})} // mock shipper
+
+	// The default workspace and project both have the id 1.
+	scoped := mockWebhook()
+	scoped.Secret = ptrs.Ptr("secret")
+	scoped.WorkspaceID = ptrs.Ptr(int32(1))
+	scoped.Triggers = Triggers{
+		{TriggerType: TriggerTypeCheckpointCreated, Condition: map[string]interface{}{}},
+		{
+			TriggerType: TriggerTypeTaskStateChange,
+			Condition:   map[string]interface{}{"state": model.AllocationStateRunning},
+		},
+	}
+	require.NoError(t, AddWebhook(ctx, scoped))
+
+	checkpoint := model.CheckpointV2{
+		UUID:      uuid.New(),
+		TaskID:    "task",
+		State:     model.CompletedState,
+		Resources: map[string]int64{"a": 1, "b": 2},
+		Metadata:  model.JSONObj{"steps_completed": float64(10)},
+	}
+	require.NoError(t, ReportCheckpointCreated(ctx, model.Experiment{ID: 1}, checkpoint))
+	count, err := CountEvents(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, count, "events outside of the workspace should not fire")
+
+	require.NoError(t,
+		ReportCheckpointCreated(ctx, model.Experiment{ID: 1, ProjectID: 1}, checkpoint))
+	require.NoError(t, ReportTaskStateChanged(ctx, TaskPayload{
+		ID:    "task",
+		State: model.AllocationStatePulling,
+	}, ptrs.Ptr(1)))
+	require.NoError(t, ReportTaskStateChanged(ctx, TaskPayload{
+		ID:    "task",
+		State: model.AllocationStateRunning,
+	}, ptrs.Ptr(1)))
+
+	batch, err := dequeueEvents(ctx, maxEventBatchSize)
+	require.NoError(t, err)
+	require.NoError(t, batch.commit())
+	require.Len(t, batch.events, 2)
+	var payloads []EventPayload
+	for _, e := range batch.events {
+		require.Equal(t, ptrs.Ptr("secret"), e.Secret)
+		var p EventPayload
+		require.NoError(t, json.Unmarshal(e.Payload, &p))
+		payloads = append(payloads, p)
+	}
+	sort.Slice(payloads, func(i, j int) bool { return payloads[i].Type < payloads[j].Type })
+	require.Equal(t, TriggerTypeCheckpointCreated, payloads[0].Type)
+	require.Equal(t, checkpoint.UUID.String(), payloads[0].Data.Checkpoint.UUID)
+	require.Equal(t, int64(3), payloads[0].Data.Checkpoint.Size)
+	require.Equal(t, ptrs.Ptr(10), payloads[0].Data.Checkpoint.StepsCompleted)
+	require.Equal(t, TriggerTypeTaskStateChange, payloads[1].Type)
+	require.Equal(t, model.AllocationStateRunning, payloads[1].Data.Task.State)
+}
+
+func TestReportMetricThresholdExceeded(t *testing.T) {
+	ctx := context.Background()
+	pgDB := db.MustResolveTestPostgres(t)
+	db.MustMigrateTestPostgres(t, pgDB, db.MigrationsFromDB)
+	clearWebhooksTables(ctx, t)
+
+	singletonShipper = &shipper{wake: make(chan<- struct{})} // mock shipper
+
+	unfiltered := mockWebhook()
+	unfiltered.Triggers = Triggers{{
+		TriggerType: TriggerTypeMetricThresholdExceeded,
+		Condition:   map[string]interface{}{},
+	}}
+	require.NoError(t, AddWebhook(ctx, unfiltered))
+	named := mockWebhook()
+	named.Triggers = Triggers{{
+		TriggerType: TriggerTypeMetricThresholdExceeded,
+		Condition:   map[string]interface{}{"alert": "diverged"},
+	}}
+	require.NoError(t, AddWebhook(ctx, named))
+
+	var config expconf.ExperimentConfig
+	e := model.Experiment{
+		ID:        1,
+		ProjectID: 1,
+		Config:    schemas.WithDefaults(config).(expconf.ExperimentConfigV0),
+	}
+	alert := AlertPayload{
+		Name:         "slow",
+		ExperimentID: e.ID,
+		TrialID:      2,
+		MetricGroup:  model.ValidationMetricGroup,
+		Metric:       "loss",
+		Value:        "NaN",
+		TotalBatches: 100,
+	}
+	require.NoError(t, ReportMetricThresholdExceeded(ctx, e, alert))
+	count, err := CountEvents(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, count, "triggers for another alert should not fire")
+
+	alert.Name = "diverged"
+	require.NoError(t, ReportMetricThresholdExceeded(ctx, e, alert))
+	batch, err := dequeueEvents(ctx, maxEventBatchSize)
+	require.NoError(t, err)
+	require.NoError(t, batch.commit())
+	require.Len(t, batch.events, 3)
+	fired := map[string]int{}
+	for _, event := range batch.events {
+		var p EventPayload
+		require.NoError(t, json.Unmarshal(event.Payload, &p))
+		require.Equal(t, TriggerTypeMetricThresholdExceeded, p.Type)
+		require.Equal(t, p.Condition.Alert, p.Data.Alert.Name)
+		require.Equal(t, e.ID, p.Data.Experiment.ID)
+		fired[p.Condition.Alert]++
+		if p.Condition.Alert == alert.Name {
+			require.Equal(t, &alert, p.Data.Alert)
+		}
+	}
+	require.Equal(t, map[string]int{"slow": 1, "diverged": 2}, fired)
+}
+
+func TestReportScheduledRunFailed(t *testing.T) {
+	ctx := context.Background()
+	pgDB := db.MustResolveTestPostgres(t)
+	db.MustMigrateTestPostgres(t, pgDB, db.MigrationsFromDB)
+	clearWebhooksTables(ctx, t)
+
+	singletonShipper = &shipper{wake: make(chan<- struct{})} // mock shipper
+
+	unfiltered := mockWebhook()
+	unfiltered.Triggers = Triggers{{
+		TriggerType: TriggerTypeScheduledRunFailed,
+		Condition:   map[string]interface{}{},
+	}}
+	require.NoError(t, AddWebhook(ctx, unfiltered))
+	named := mockWebhook()
+	named.Triggers = Triggers{{
+		TriggerType: TriggerTypeScheduledRunFailed,
+		Condition:   map[string]interface{}{"schedule": "nightly"},
+	}}
+	require.NoError(t, AddWebhook(ctx, named))
+
+	run := RunPayload{
+		ScheduleID:   1,
+		ScheduleName: "weekly",
+		ScheduledAt:  time.Date(2022, 12, 18, 2, 0, 0, 0, time.UTC),
+		Error:        "invalid experiment",
+	}
+	require.NoError(t, ReportScheduledRunFailed(ctx, 1, run))
+	count, err := CountEvents(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, count, "triggers for another schedule should not fire")
+
+	run.ScheduleName = "nightly"
+	run.ExperimentID = ptrs.Ptr(3)
+	require.NoError(t, ReportScheduledRunFailed(ctx, 1, run))
+	batch, err := dequeueEvents(ctx, maxEventBatchSize)
+	require.NoError(t, err)
+	require.NoError(t, batch.commit())
+	require.Len(t, batch.events, 3)
+	fired := map[string]int{}
+	for _, event := range batch.events {
+		var p EventPayload
+		require.NoError(t, json.Unmarshal(event.Payload, &p))
+		require.Equal(t, TriggerTypeScheduledRunFailed, p.Type)
+		require.Equal(t, p.Condition.Schedule, p.Data.Run.ScheduleName)
+		fired[p.Condition.Schedule]++
+		if p.Condition.Schedule == run.ScheduleName {
+			require.Equal(t, &run, p.Data.Run)
+		}
+	}
+	require.Equal(t, map[string]int{"weekly": 1, "nightly": 2}, fired)
+}
+
 var (
 	testWebhookOne = Webhook{
 		ID:          1000,
@@ -182,7 +182,8 @@ func backoff() back.BackOff {
 }
 
 func (w *worker) deliver(ctx context.Context, e Event) error {
-	req, err := generateWebhookRequest(ctx, e.URL, e.Payload, time.Now().Unix())
+	req, err := generateWebhookRequest(
+		ctx, e.URL, e.Payload, time.Now().Unix(), signingKey(e.Secret))
 	if err != nil {
 		return err
 	}
@@ -212,12 +213,12 @@ func generateWebhookRequest(
 	url string,
 	payload []byte,
 	t int64,
+	key []byte,
 ) (*http.Request, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(payload))
 	if err != nil {
 		return nil, fmt.Errorf("failed creating webhook request: %w", err)
 	}
-	key := []byte(conf.GetMasterConfig().Webhooks.SigningKey)
 	signedPayload := generateSignedPayload(req, t, key)
 	req.Header.Add("X-Determined-AI-Signature-Timestamp", fmt.Sprintf("%v", t))
 	req.Header.Add("X-Determined-AI-Signature", signedPayload)
@@ -225,6 +226,15 @@ func generateWebhookRequest(
 	return req, nil
 }
 
+// signingKey returns the key to sign the payloads of a webhook with: its own secret if it has one,
+// and otherwise the signing key of the master.
+func signingKey(secret *string) []byte {
+	if secret != nil {
+		return []byte(*secret)
+	}
+	return []byte(conf.GetMasterConfig().Webhooks.SigningKey)
+}
+
 func generateSignedPayload(req *http.Request, t int64, key []byte) string {
 	body := req.GetBody
 	bodyCopy, _ := body()
@@ -2,6 +2,7 @@ package webhooks
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/uptrace/bun"
 
@@ -32,17 +33,26 @@ type Webhook struct {
 	ID          WebhookID   `bun:"id,pk,autoincrement"`
 	WebhookType WebhookType `bun:"webhook_type,notnull"`
 	URL         string      `bun:"url,notnull"`
+	// Secret, if set, signs the payloads of the webhook instead of the signing key of the master.
+	Secret *string `bun:"secret"`
+	// WorkspaceID, if set, limits the webhook to events from the workspace.
+	WorkspaceID *int32 `bun:"workspace_id"`
 
 	Triggers Triggers `bun:"rel:has-many,join:id=webhook_id"`
 }
 
 // WebhookFromProto returns a model Webhook from a proto definition.
 func WebhookFromProto(w *webhookv1.Webhook) Webhook {
-	return Webhook{
+	webhook := Webhook{
 		URL:         w.Url,
 		Triggers:    TriggersFromProto(w.Triggers),
 		WebhookType: WebhookTypeFromProto(w.WebhookType),
+		WorkspaceID: w.WorkspaceId,
 	}
+	if w.Secret != "" {
+		webhook.Secret = &w.Secret
+	}
+	return webhook
 }
 
 // Proto converts a webhook to its protobuf representation.
@@ -52,6 +62,8 @@ func (w *Webhook) Proto() *webhookv1.Webhook {
 		Url:         w.URL,
 		Triggers:    w.Triggers.Proto(),
 		WebhookType: w.WebhookType.Proto(),
+		WorkspaceId: w.WorkspaceID,
+		HasSecret:   w.Secret != nil,
 	}
 }
 
@@ -124,6 +136,15 @@ const (
 
 	// TriggerTypeMetricThresholdExceeded represents a threshold for a training metric value.
 	TriggerTypeMetricThresholdExceeded TriggerType = "METRIC_THRESHOLD_EXCEEDED"
+
+	// TriggerTypeCheckpointCreated represents a checkpoint being reported.
+	TriggerTypeCheckpointCreated TriggerType = "CHECKPOINT_CREATED"
+
+	// TriggerTypeTaskStateChange represents a change in the state of a task's allocation.
+	TriggerTypeTaskStateChange TriggerType = "TASK_STATE_CHANGE"
+
+	// TriggerTypeScheduledRunFailed represents a failed run of an experiment schedule.
+	TriggerTypeScheduledRunFailed TriggerType = "SCHEDULED_RUN_FAILED"
 )
 
 const (
@@ -132,6 +153,9 @@ const (
 
 	// WebhookTypeSlack represents a slack webhook.
 	WebhookTypeSlack WebhookType = "SLACK"
+
+	// WebhookTypeTeams represents a Microsoft Teams webhook.
+	WebhookTypeTeams WebhookType = "TEAMS"
 )
 
 // WebhookTypeFromProto returns a WebhookType from a proto.
@@ -141,6 +165,8 @@ func WebhookTypeFromProto(w webhookv1.WebhookType) WebhookType {
 		return WebhookTypeDefault
 	case webhookv1.WebhookType_WEBHOOK_TYPE_SLACK:
 		return WebhookTypeSlack
+	case webhookv1.WebhookType_WEBHOOK_TYPE_TEAMS:
+		return WebhookTypeTeams
 	default:
 		// TODO(???): prob don't panic
 		panic(fmt.Errorf("missing mapping for webhook type %s to SQL", w))
@@ -154,6 +180,12 @@ func TriggerTypeFromProto(t webhookv1.TriggerType) TriggerType {
 		return TriggerTypeMetricThresholdExceeded
 	case webhookv1.TriggerType_TRIGGER_TYPE_EXPERIMENT_STATE_CHANGE:
 		return TriggerTypeStateChange
+	case webhookv1.TriggerType_TRIGGER_TYPE_CHECKPOINT_CREATED:
+		return TriggerTypeCheckpointCreated
+	case webhookv1.TriggerType_TRIGGER_TYPE_TASK_STATE_CHANGE:
+		return TriggerTypeTaskStateChange
+	case webhookv1.TriggerType_TRIGGER_TYPE_SCHEDULED_RUN_FAILED:
+		return TriggerTypeScheduledRunFailed
 	default:
 		// TODO(???): prob don't panic
 		panic(fmt.Errorf("missing mapping for trigger %s to SQL", t))
@@ -167,6 +199,8 @@ func (w WebhookType) Proto() webhookv1.WebhookType {
 		return webhookv1.WebhookType_WEBHOOK_TYPE_DEFAULT
 	case WebhookTypeSlack:
 		return webhookv1.WebhookType_WEBHOOK_TYPE_SLACK
+	case WebhookTypeTeams:
+		return webhookv1.WebhookType_WEBHOOK_TYPE_TEAMS
 	default:
 		return webhookv1.WebhookType_WEBHOOK_TYPE_UNSPECIFIED
 	}
@@ -179,6 +213,12 @@ func (t TriggerType) Proto() webhookv1.TriggerType {
 		return webhookv1.TriggerType_TRIGGER_TYPE_EXPERIMENT_STATE_CHANGE
 	case TriggerTypeMetricThresholdExceeded:
 		return webhookv1.TriggerType_TRIGGER_TYPE_METRIC_THRESHOLD_EXCEEDED
+	case TriggerTypeCheckpointCreated:
+		return webhookv1.TriggerType_TRIGGER_TYPE_CHECKPOINT_CREATED
+	case TriggerTypeTaskStateChange:
+		return webhookv1.TriggerType_TRIGGER_TYPE_TASK_STATE_CHANGE
+	case TriggerTypeScheduledRunFailed:
+		return webhookv1.TriggerType_TRIGGER_TYPE_SCHEDULED_RUN_FAILED
 	default:
 		return webhookv1.TriggerType_TRIGGER_TYPE_UNSPECIFIED
 	}
@@ -210,9 +250,13 @@ type WebhookEventID int
 type Event struct {
 	bun.BaseModel `bun:"table:webhook_events_queue"`
 
-	ID      WebhookEventID `bun:"id,pk,autoincrement"`
-	URL     string         `bun:"url,notnull"`
-	Payload []byte         `bun:"payload,notnull"`
+	ID        WebhookEventID `bun:"id,pk,autoincrement"`
+	URL       string         `bun:"url,notnull"`
+	Payload   []byte         `bun:"payload,notnull"`
+	WebhookID *WebhookID     `bun:"webhook_id"`
+
+	// Secret is the secret of the webhook when the event is dequeued.
+	Secret *string `bun:"secret,scanonly"`
 }
 
 // SlackMessageBody corresponds to an entire message as a Slack Block.
@@ -240,6 +284,44 @@ type SlackField struct {
 	Text string `json:"text"`
 }
 
+// TeamsMessageCard corresponds to an entire message as a Microsoft Teams message card.
+type TeamsMessageCard struct {
+	Type            string         `json:"@type"`
+	Context         string         `json:"@context"`
+	ThemeColor      string         `json:"themeColor,omitempty"`
+	Summary         string         `json:"summary"`
+	Title           string         `json:"title,omitempty"`
+	Text            string         `json:"text,omitempty"`
+	Sections        []TeamsSection `json:"sections,omitempty"`
+	PotentialAction []TeamsAction  `json:"potentialAction,omitempty"`
+}
+
+// TeamsSection corresponds to a section of a Microsoft Teams message card.
+type TeamsSection struct {
+	ActivityTitle string      `json:"activityTitle,omitempty"`
+	Facts         []TeamsFact `json:"facts,omitempty"`
+	Markdown      bool        `json:"markdown"`
+}
+
+// TeamsFact corresponds to a name and value pair in a Microsoft Teams message card section.
+type TeamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// TeamsAction corresponds to an action button of a Microsoft Teams message card.
+type TeamsAction struct {
+	Type    string        `json:"@type"`
+	Name    string        `json:"name"`
+	Targets []TeamsTarget `json:"targets"`
+}
+
+// TeamsTarget corresponds to a link opened by a Microsoft Teams message card action.
+type TeamsTarget struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
+}
+
 // EventPayload respresents a webhook event.
 type EventPayload struct {
 	ID        uuid.UUID   `json:"event_id"`
@@ -251,13 +333,19 @@ type EventPayload struct {
 
 // Condition represents a trigger condition.
 type Condition struct {
-	State model.State `json:"state,omitempty"`
+	State    string `json:"state,omitempty"`
+	Alert    string `json:"alert,omitempty"`
+	Schedule string `json:"schedule,omitempty"`
 }
 
 // EventData represents the event_data for a webhook event.
 type EventData struct {
 	TestData   *string            `json:"data,omitempty"`
 	Experiment *ExperimentPayload `json:"experiment,omitempty"`
+	Checkpoint *CheckpointPayload `json:"checkpoint,omitempty"`
+	Task       *TaskPayload       `json:"task,omitempty"`
+	Alert      *AlertPayload      `json:"alert,omitempty"`
+	Run        *RunPayload        `json:"run,omitempty"`
 }
 
 // ExperimentPayload is the webhook request representation of an experiment.
@@ -271,3 +359,46 @@ type ExperimentPayload struct {
 	WorkspaceName string       `json:"workspace"`
 	ProjectName   string       `json:"project"`
 }
+
+// CheckpointPayload is the webhook request representation of a checkpoint.
+type CheckpointPayload struct {
+	UUID           string      `json:"uuid"`
+	ExperimentID   int         `json:"experiment_id"`
+	TaskID         string      `json:"task_id"`
+	AllocationID   string      `json:"allocation_id"`
+	State          model.State `json:"state"`
+	ReportTime     time.Time   `json:"report_time"`
+	StepsCompleted *int        `json:"steps_completed,omitempty"`
+	Size           int64       `json:"size"`
+}
+
+// TaskPayload is the webhook request representation of a task changing state.
+type TaskPayload struct {
+	ID           model.TaskID          `json:"id"`
+	AllocationID model.AllocationID    `json:"allocation_id"`
+	Name         string                `json:"name"`
+	State        model.AllocationState `json:"state"`
+}
+
+// AlertPayload is the webhook request representation of an alert firing for a trial.
+type AlertPayload struct {
+	Name         string `json:"name"`
+	ExperimentID int    `json:"experiment_id"`
+	TrialID      int    `json:"trial_id"`
+	MetricGroup  string `json:"metric_group"`
+	Metric       string `json:"metric"`
+	// Value is a number, or "NaN", "Infinity" or "-Infinity", which JSON has no numbers for.
+	Value           interface{} `json:"value"`
+	TotalBatches    int         `json:"total_batches"`
+	PauseExperiment bool        `json:"pause_experiment"`
+}
+
+// RunPayload is the webhook request representation of a failed run of an experiment schedule.
+type RunPayload struct {
+	ScheduleID   int       `json:"schedule_id"`
+	ScheduleName string    `json:"schedule_name"`
+	ScheduledAt  time.Time `json:"scheduled_at"`
+	// ExperimentID is the experiment the run submitted, unless it failed to.
+	ExperimentID *int   `json:"experiment_id,omitempty"`
+	Error        string `json:"error"`
+}
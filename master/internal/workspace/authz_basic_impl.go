@@ -127,6 +127,17 @@ func (a *WorkspaceAuthZBasic) CanSetWorkspacesCheckpointStorageConfig(
 	return nil
 }
 
+// CanSetWorkspacesLogRetention returns an error if the user is not an admin or owner of the
+// workspace.
+func (a *WorkspaceAuthZBasic) CanSetWorkspacesLogRetention(
+	ctx context.Context, curUser model.User, workspace *workspacev1.Workspace,
+) error {
+	if !curUser.Admin && curUser.ID != model.UserID(workspace.UserId) {
+		return fmt.Errorf("only admins may set log retention on other user's workspaces")
+	}
+	return nil
+}
+
 // CanCreateWorkspaceWithCheckpointStorageConfig returns an nil error.
 func (a *WorkspaceAuthZBasic) CanCreateWorkspaceWithCheckpointStorageConfig(
 	ctx context.Context, curUser model.User,
@@ -41,6 +41,9 @@ type WorkspaceAuthZ interface {
 	CanSetWorkspacesCheckpointStorageConfig(
 		ctx context.Context, curUser model.User, workspace *workspacev1.Workspace,
 	) error
+	CanSetWorkspacesLogRetention(
+		ctx context.Context, curUser model.User, workspace *workspacev1.Workspace,
+	) error
 
 	// DELETE /api/v1/workspaces/:workspace_id
 	CanDeleteWorkspace(
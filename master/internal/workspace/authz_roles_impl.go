@@ -0,0 +1,166 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/projectv1"
+	"github.com/determined-ai/determined/proto/pkg/workspacev1"
+)
+
+// WorkspaceAuthZRoles controls access to workspaces by the roles of users in them. Users can
+// see the workspaces they have a role in or contain a project they have a role in, and admins
+// of a workspace can change it.
+type WorkspaceAuthZRoles struct{}
+
+// CanGetWorkspace returns whether the user has a role in the workspace or one of its projects.
+func (a *WorkspaceAuthZRoles) CanGetWorkspace(
+	ctx context.Context, curUser model.User, workspace *workspacev1.Workspace,
+) (canGetWorkspace bool, serverError error) {
+	if curUser.Admin {
+		return true, nil
+	}
+	return VisibleWorkspaces(curUser).Where("vw.id = ?", workspace.Id).Exists(ctx)
+}
+
+// FilterWorkspaceProjects returns the projects the user has a role in.
+func (a *WorkspaceAuthZRoles) FilterWorkspaceProjects(
+	ctx context.Context, curUser model.User, projects []*projectv1.Project,
+) ([]*projectv1.Project, error) {
+	if curUser.Admin {
+		return projects, nil
+	}
+	var ids []int32
+	if err := VisibleProjects(curUser).Scan(ctx, &ids); err != nil {
+		return nil, err
+	}
+	visible := make(map[int32]bool, len(ids))
+	for _, id := range ids {
+		visible[id] = true
+	}
+	var filtered []*projectv1.Project
+	for _, p := range projects {
+		if visible[p.Id] {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered, nil
+}
+
+// FilterWorkspaces returns the workspaces the user has a role in or contain a project they have
+// a role in.
+func (a *WorkspaceAuthZRoles) FilterWorkspaces(
+	ctx context.Context, curUser model.User, workspaces []*workspacev1.Workspace,
+) ([]*workspacev1.Workspace, error) {
+	if curUser.Admin {
+		return workspaces, nil
+	}
+	var ids []int32
+	if err := VisibleWorkspaces(curUser).Scan(ctx, &ids); err != nil {
+		return nil, err
+	}
+	visible := make(map[int32]bool, len(ids))
+	for _, id := range ids {
+		visible[id] = true
+	}
+	var filtered []*workspacev1.Workspace
+	for _, w := range workspaces {
+		if visible[w.Id] {
+			filtered = append(filtered, w)
+		}
+	}
+	return filtered, nil
+}
+
+// CanCreateWorkspace always returns a nil error, since users own the workspaces they create.
+func (a *WorkspaceAuthZRoles) CanCreateWorkspace(ctx context.Context, curUser model.User) error {
+	return nil
+}
+
+// CanCreateWorkspaceWithAgentUserGroup requires user to be an admin.
+func (a *WorkspaceAuthZRoles) CanCreateWorkspaceWithAgentUserGroup(
+	ctx context.Context, curUser model.User,
+) error {
+	if !curUser.Admin {
+		return fmt.Errorf("only admin privileged users can set workspace agent user groups")
+	}
+	return nil
+}
+
+// CanCreateWorkspaceWithCheckpointStorageConfig returns a nil error.
+func (a *WorkspaceAuthZRoles) CanCreateWorkspaceWithCheckpointStorageConfig(
+	ctx context.Context, curUser model.User,
+) error {
+	return nil
+}
+
+// CanSetWorkspacesName requires the user to be an admin of the workspace.
+func (a *WorkspaceAuthZRoles) CanSetWorkspacesName(
+	ctx context.Context, curUser model.User, workspace *workspacev1.Workspace,
+) error {
+	return RequireWorkspaceRole(ctx, curUser, int(workspace.Id), model.MemberRoleAdmin)
+}
+
+// CanSetWorkspacesAgentUserGroup can only be done by admins.
+func (a *WorkspaceAuthZRoles) CanSetWorkspacesAgentUserGroup(
+	ctx context.Context, curUser model.User, workspace *workspacev1.Workspace,
+) error {
+	if !curUser.Admin {
+		return fmt.Errorf("only admin privileged users can set workspace agent user groups")
+	}
+	return nil
+}
+
+// CanSetWorkspacesCheckpointStorageConfig requires the user to be an admin of the workspace.
+func (a *WorkspaceAuthZRoles) CanSetWorkspacesCheckpointStorageConfig(
+	ctx context.Context, curUser model.User, workspace *workspacev1.Workspace,
+) error {
+	return RequireWorkspaceRole(ctx, curUser, int(workspace.Id), model.MemberRoleAdmin)
+}
+
+// CanSetWorkspacesLogRetention requires the user to be an admin of the workspace.
+func (a *WorkspaceAuthZRoles) CanSetWorkspacesLogRetention(
+	ctx context.Context, curUser model.User, workspace *workspacev1.Workspace,
+) error {
+	return RequireWorkspaceRole(ctx, curUser, int(workspace.Id), model.MemberRoleAdmin)
+}
+
+// CanDeleteWorkspace requires the user to be an admin of the workspace.
+func (a *WorkspaceAuthZRoles) CanDeleteWorkspace(
+	ctx context.Context, curUser model.User, workspace *workspacev1.Workspace,
+) error {
+	return RequireWorkspaceRole(ctx, curUser, int(workspace.Id), model.MemberRoleAdmin)
+}
+
+// CanArchiveWorkspace requires the user to be an admin of the workspace.
+func (a *WorkspaceAuthZRoles) CanArchiveWorkspace(
+	ctx context.Context, curUser model.User, workspace *workspacev1.Workspace,
+) error {
+	return RequireWorkspaceRole(ctx, curUser, int(workspace.Id), model.MemberRoleAdmin)
+}
+
+// CanUnarchiveWorkspace requires the user to be an admin of the workspace.
+func (a *WorkspaceAuthZRoles) CanUnarchiveWorkspace(
+	ctx context.Context, curUser model.User, workspace *workspacev1.Workspace,
+) error {
+	return RequireWorkspaceRole(ctx, curUser, int(workspace.Id), model.MemberRoleAdmin)
+}
+
+// CanPinWorkspace always returns a nil error, since users can only pin workspaces they can see.
+func (a *WorkspaceAuthZRoles) CanPinWorkspace(
+	ctx context.Context, curUser model.User, workspace *workspacev1.Workspace,
+) error {
+	return nil
+}
+
+// CanUnpinWorkspace always returns a nil error.
+func (a *WorkspaceAuthZRoles) CanUnpinWorkspace(
+	ctx context.Context, curUser model.User, workspace *workspacev1.Workspace,
+) error {
+	return nil
+}
+
+func init() {
+	AuthZProvider.Register("roles", &WorkspaceAuthZRoles{})
+}
@@ -0,0 +1,309 @@
+package workspace
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// groupRole selects the greatest role a user has in the workspace w through their groups.
+const groupRole = `coalesce((
+    SELECT max(gm.role)::text FROM workspace_group_members gm
+      JOIN user_group_membership ugm ON ugm.group_id = gm.group_id
+    WHERE gm.workspace_id = w.id AND ugm.user_id = ?
+  ), '')`
+
+// projectGroupRole selects the greatest role a user has in the project p through their groups.
+const projectGroupRole = `coalesce((
+    SELECT max(gm.role)::text FROM project_group_members gm
+      JOIN user_group_membership ugm ON ugm.group_id = gm.group_id
+    WHERE gm.project_id = p.id AND ugm.user_id = ?
+  ), '')`
+
+// memberRoles is what determines the role of a user in a workspace or project.
+type memberRoles struct {
+	WorkspaceOwner model.UserID
+	ProjectOwner   model.UserID
+	Immutable      bool
+	WorkspaceRole  model.MemberRole
+	ProjectRole    model.MemberRole
+	GroupRole      model.MemberRole
+	// ProjectGroupRole is only set for projects.
+	ProjectGroupRole model.MemberRole
+}
+
+// role returns the role of the user. Cluster admins are admins everywhere, and the owners of a
+// workspace or project are admins of it. Everyone else has the greatest of their roles in the
+// workspace and the project, directly or through their groups, except that everyone can edit in
+// the default workspace.
+func (r memberRoles) role(curUser model.User) model.MemberRole {
+	if curUser.Admin || r.WorkspaceOwner == curUser.ID || r.ProjectOwner == curUser.ID {
+		return model.MemberRoleAdmin
+	}
+	role := r.WorkspaceRole.Max(r.ProjectRole).Max(r.GroupRole).Max(r.ProjectGroupRole)
+	if r.Immutable {
+		role = role.Max(model.MemberRoleEditor)
+	}
+	return role
+}
+
+// WorkspaceRole returns the role of a user in a workspace.
+func WorkspaceRole(
+	ctx context.Context, curUser model.User, workspaceID int,
+) (model.MemberRole, error) {
+	var r memberRoles
+	switch err := db.Bun().NewRaw(`
+SELECT
+  coalesce(w.user_id, 0) AS workspace_owner, coalesce(w.immutable, false) AS immutable,
+  coalesce(wm.role::text, '') AS workspace_role, `+groupRole+` AS group_role
+FROM workspaces w
+  LEFT JOIN workspace_members wm ON wm.workspace_id = w.id AND wm.user_id = ?
+WHERE w.id = ?`, curUser.ID, curUser.ID, workspaceID).Scan(ctx, &r); {
+	case errors.Is(err, sql.ErrNoRows):
+		return "", db.ErrNotFound
+	case err != nil:
+		return "", errors.Wrapf(err, "querying role in workspace %d", workspaceID)
+	}
+	return r.role(curUser), nil
+}
+
+// ProjectRole returns the role of a user in a project, which is at least their role in its
+// workspace.
+func ProjectRole(ctx context.Context, curUser model.User, projectID int) (model.MemberRole, error) {
+	var r memberRoles
+	switch err := db.Bun().NewRaw(`
+SELECT
+  coalesce(w.user_id, 0) AS workspace_owner, coalesce(p.user_id, 0) AS project_owner,
+  coalesce(w.immutable, false) AS immutable,
+  coalesce(wm.role::text, '') AS workspace_role, coalesce(pm.role::text, '') AS project_role,
+  `+groupRole+` AS group_role, `+projectGroupRole+` AS project_group_role
+FROM projects p
+  JOIN workspaces w ON w.id = p.workspace_id
+  LEFT JOIN workspace_members wm ON wm.workspace_id = w.id AND wm.user_id = ?
+  LEFT JOIN project_members pm ON pm.project_id = p.id AND pm.user_id = ?
+WHERE p.id = ?`, curUser.ID, curUser.ID, curUser.ID, curUser.ID, projectID).Scan(ctx, &r); {
+	case errors.Is(err, sql.ErrNoRows):
+		return "", db.ErrNotFound
+	case err != nil:
+		return "", errors.Wrapf(err, "querying role in project %d", projectID)
+	}
+	return r.role(curUser), nil
+}
+
+// RequireWorkspaceRole returns an error if the user does not have at least the given role in a
+// workspace.
+func RequireWorkspaceRole(
+	ctx context.Context, curUser model.User, workspaceID int, role model.MemberRole,
+) error {
+	has, err := WorkspaceRole(ctx, curUser, workspaceID)
+	if err != nil {
+		return err
+	}
+	if !has.AtLeast(role) {
+		return fmt.Errorf("the %s role in workspace %d is required", strings.ToLower(string(role)),
+			workspaceID)
+	}
+	return nil
+}
+
+// RequireProjectRole returns an error if the user does not have at least the given role in a
+// project.
+func RequireProjectRole(
+	ctx context.Context, curUser model.User, projectID int, role model.MemberRole,
+) error {
+	has, err := ProjectRole(ctx, curUser, projectID)
+	if err != nil {
+		return err
+	}
+	if !has.AtLeast(role) {
+		return fmt.Errorf("the %s role in project %d is required", strings.ToLower(string(role)),
+			projectID)
+	}
+	return nil
+}
+
+// groupMembership selects whether a user has a role in the workspace vw through their groups.
+const groupMembership = `SELECT 1 FROM workspace_group_members gm
+    JOIN user_group_membership ugm ON ugm.group_id = gm.group_id
+  WHERE gm.workspace_id = vw.id AND ugm.user_id = ?`
+
+// projectGroupMembership selects whether a user has a role in the project vp through their
+// groups.
+const projectGroupMembership = `SELECT 1 FROM project_group_members gm
+    JOIN user_group_membership ugm ON ugm.group_id = gm.group_id
+  WHERE gm.project_id = vp.id AND ugm.user_id = ?`
+
+// VisibleProjects returns a query for the IDs of the projects a user who is not a cluster admin
+// has any role in, to filter other queries with.
+func VisibleProjects(curUser model.User) *bun.SelectQuery {
+	return db.Bun().NewSelect().
+		TableExpr("projects AS vp").
+		Column("vp.id").
+		Join("JOIN workspaces vw ON vw.id = vp.workspace_id").
+		Where(`vw.immutable OR vw.user_id = ? OR vp.user_id = ?
+  OR EXISTS (SELECT 1 FROM workspace_members m WHERE m.workspace_id = vw.id AND m.user_id = ?)
+  OR EXISTS (SELECT 1 FROM project_members m WHERE m.project_id = vp.id AND m.user_id = ?)
+  OR EXISTS (`+groupMembership+`)
+  OR EXISTS (`+projectGroupMembership+`)`,
+			curUser.ID, curUser.ID, curUser.ID, curUser.ID, curUser.ID, curUser.ID)
+}
+
+// VisibleWorkspaces returns a query for the IDs of the workspaces a user who is not a cluster
+// admin has any role in, or that contain a project they have a role in.
+func VisibleWorkspaces(curUser model.User) *bun.SelectQuery {
+	return db.Bun().NewSelect().
+		TableExpr("workspaces AS vw").
+		Column("vw.id").
+		Where(`vw.immutable OR vw.user_id = ?
+  OR EXISTS (SELECT 1 FROM workspace_members m WHERE m.workspace_id = vw.id AND m.user_id = ?)
+  OR EXISTS (`+groupMembership+`)
+  OR EXISTS (
+    SELECT 1 FROM projects vp WHERE vp.workspace_id = vw.id AND (vp.user_id = ? OR EXISTS (
+      SELECT 1 FROM project_members m WHERE m.project_id = vp.id AND m.user_id = ?
+    ) OR EXISTS (`+projectGroupMembership+`))
+  )`, curUser.ID, curUser.ID, curUser.ID, curUser.ID, curUser.ID, curUser.ID)
+}
+
+// WorkspaceMembers returns the users with a role in a workspace, ordered by username.
+func WorkspaceMembers(ctx context.Context, workspaceID int) ([]model.Member, error) {
+	return members(ctx, "workspace_members", "workspace_id", workspaceID)
+}
+
+// ProjectMembers returns the users with a role in a project itself, ordered by username.
+func ProjectMembers(ctx context.Context, projectID int) ([]model.Member, error) {
+	return members(ctx, "project_members", "project_id", projectID)
+}
+
+// SetWorkspaceMember gives a user a role in a workspace, replacing any role they had.
+func SetWorkspaceMember(
+	ctx context.Context, workspaceID int, userID model.UserID, role model.MemberRole,
+) error {
+	return setMember(ctx, "workspace_members", "workspace_id", workspaceID, "user_id", int(userID),
+		role)
+}
+
+// SetProjectMember gives a user a role in a project, replacing any role they had in it.
+func SetProjectMember(
+	ctx context.Context, projectID int, userID model.UserID, role model.MemberRole,
+) error {
+	return setMember(ctx, "project_members", "project_id", projectID, "user_id", int(userID), role)
+}
+
+// RemoveWorkspaceMember removes the role of a user in a workspace. It returns ErrNotFound if
+// they had none.
+func RemoveWorkspaceMember(ctx context.Context, workspaceID int, userID model.UserID) error {
+	return removeMember(ctx, "workspace_members", "workspace_id", workspaceID, "user_id",
+		int(userID))
+}
+
+// RemoveProjectMember removes the role of a user in a project. It returns ErrNotFound if they
+// had none.
+func RemoveProjectMember(ctx context.Context, projectID int, userID model.UserID) error {
+	return removeMember(ctx, "project_members", "project_id", projectID, "user_id", int(userID))
+}
+
+// WorkspaceGroupMembers returns the groups with a role in a workspace, ordered by name.
+func WorkspaceGroupMembers(ctx context.Context, workspaceID int) ([]model.GroupMember, error) {
+	return groupMembers(ctx, "workspace_group_members", "workspace_id", workspaceID)
+}
+
+// ProjectGroupMembers returns the groups with a role in a project itself, ordered by name.
+func ProjectGroupMembers(ctx context.Context, projectID int) ([]model.GroupMember, error) {
+	return groupMembers(ctx, "project_group_members", "project_id", projectID)
+}
+
+// SetWorkspaceGroupMember gives the users in a group a role in a workspace, replacing any role
+// the group had.
+func SetWorkspaceGroupMember(
+	ctx context.Context, workspaceID int, groupID int, role model.MemberRole,
+) error {
+	return setMember(ctx, "workspace_group_members", "workspace_id", workspaceID, "group_id",
+		groupID, role)
+}
+
+// RemoveWorkspaceGroupMember removes the role of a group in a workspace. It returns ErrNotFound
+// if it had none.
+func RemoveWorkspaceGroupMember(ctx context.Context, workspaceID int, groupID int) error {
+	return removeMember(ctx, "workspace_group_members", "workspace_id", workspaceID, "group_id",
+		groupID)
+}
+
+// SetProjectGroupMember gives the users in a group a role in a project, replacing any role the
+// group had in it.
+func SetProjectGroupMember(
+	ctx context.Context, projectID int, groupID int, role model.MemberRole,
+) error {
+	return setMember(ctx, "project_group_members", "project_id", projectID, "group_id", groupID,
+		role)
+}
+
+// RemoveProjectGroupMember removes the role of a group in a project. It returns ErrNotFound if
+// it had none.
+func RemoveProjectGroupMember(ctx context.Context, projectID int, groupID int) error {
+	return removeMember(ctx, "project_group_members", "project_id", projectID, "group_id",
+		groupID)
+}
+
+func members(ctx context.Context, table, column string, id int) ([]model.Member, error) {
+	ms := []model.Member{}
+	if err := db.Bun().NewRaw(`
+SELECT m.user_id, u.username, m.role
+FROM ? m JOIN users u ON u.id = m.user_id
+WHERE m.? = ?
+ORDER BY u.username`, bun.Ident(table), bun.Ident(column), id).Scan(ctx, &ms); err != nil {
+		return nil, errors.Wrapf(err, "querying %s", table)
+	}
+	return ms, nil
+}
+
+func groupMembers(
+	ctx context.Context, table, column string, id int,
+) ([]model.GroupMember, error) {
+	ms := []model.GroupMember{}
+	if err := db.Bun().NewRaw(`
+SELECT m.group_id, g.group_name, m.role
+FROM ? m JOIN groups g ON g.id = m.group_id
+WHERE m.? = ?
+ORDER BY g.group_name`, bun.Ident(table), bun.Ident(column), id).Scan(ctx, &ms); err != nil {
+		return nil, errors.Wrapf(err, "querying %s", table)
+	}
+	return ms, nil
+}
+
+func setMember(
+	ctx context.Context, table, column string, id int, memberColumn string, memberID int,
+	role model.MemberRole,
+) error {
+	if _, err := db.Bun().ExecContext(ctx, `
+INSERT INTO ? (?, ?, role) VALUES (?, ?, ?)
+ON CONFLICT (?, ?) DO UPDATE SET role = EXCLUDED.role`,
+		bun.Ident(table), bun.Ident(column), bun.Ident(memberColumn), id, memberID, role,
+		bun.Ident(column), bun.Ident(memberColumn),
+	); err != nil {
+		return errors.Wrapf(err, "updating %s", table)
+	}
+	return nil
+}
+
+func removeMember(
+	ctx context.Context, table, column string, id int, memberColumn string, memberID int,
+) error {
+	res, err := db.Bun().ExecContext(ctx, `DELETE FROM ? WHERE ? = ? AND ? = ?`,
+		bun.Ident(table), bun.Ident(column), id, bun.Ident(memberColumn), memberID)
+	if err != nil {
+		return errors.Wrapf(err, "updating %s", table)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return db.ErrNotFound
+	}
+	return nil
+}
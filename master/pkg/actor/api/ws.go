@@ -19,12 +19,22 @@ import (
 
 // TODO: Add a write size limit.
 
-const (
-	// pingWaitDuration is the duration to wait for a pong response to a ping.
-	pingWaitDuration = 1 * time.Minute
-	// pingInterval is the duration to wait for between pinging connections.
-	pingInterval = 1 * time.Minute
-)
+// PingOptions configures how a websocket actor pings its peer to detect lost connections.
+type PingOptions struct {
+	// Interval is the duration to wait for between pinging the peer.
+	Interval time.Duration
+	// MissTolerance is the number of intervals to wait for a pong response to a ping before the
+	// connection is considered lost.
+	MissTolerance int
+}
+
+// DefaultPingOptions pings every minute and waits a minute for each pong response.
+var DefaultPingOptions = PingOptions{Interval: time.Minute, MissTolerance: 1}
+
+// pongWait is the duration to wait for a pong response to a ping.
+func (o PingOptions) pongWait() time.Duration {
+	return o.Interval * time.Duration(o.MissTolerance)
+}
 
 const (
 	// MaxWebsocketMessageSize is the maximum size of a websocket message that we send in bytes.
@@ -42,13 +52,35 @@ func (w WebSocketConnected) Accept(
 	ctx *actor.Context,
 	msgType interface{},
 	usePing bool,
+) (*actor.Ref, bool) {
+	var ping *PingOptions
+	if usePing {
+		ping = &DefaultPingOptions
+	}
+	return w.accept(ctx, msgType, ping)
+}
+
+// AcceptWithPing wraps the connecting websocket connection in an actor that pings its peer with
+// the given options.
+func (w WebSocketConnected) AcceptWithPing(
+	ctx *actor.Context,
+	msgType interface{},
+	ping PingOptions,
+) (*actor.Ref, bool) {
+	return w.accept(ctx, msgType, &ping)
+}
+
+func (w WebSocketConnected) accept(
+	ctx *actor.Context,
+	msgType interface{},
+	ping *PingOptions,
 ) (*actor.Ref, bool) {
 	conn, err := upgrader.Upgrade(w.Ctx.Response(), w.Ctx.Request(), nil)
 	if err != nil {
 		ctx.Respond(errors.Wrap(err, "websocket connection error"))
 		return nil, false
 	}
-	a, _ := ctx.ActorOf("websocket-"+uuid.New().String(), WrapSocket(conn, msgType, usePing))
+	a, _ := ctx.ActorOf("websocket-"+uuid.New().String(), wrapSocket(conn, msgType, ping))
 	ctx.Respond(a)
 	return a, true
 }
@@ -107,10 +139,24 @@ func WriteSocketRaw(ctx *actor.Context, socket *actor.Ref, msg interface{}) erro
 
 // WrapSocket wraps a websocket connection as an actor.
 func WrapSocket(conn *websocket.Conn, msgType interface{}, usePing bool) actor.Actor {
+	var ping *PingOptions
+	if usePing {
+		ping = &DefaultPingOptions
+	}
+	return wrapSocket(conn, msgType, ping)
+}
+
+// WrapSocketWithPing wraps a websocket connection as an actor that pings its peer with the given
+// options.
+func WrapSocketWithPing(conn *websocket.Conn, msgType interface{}, ping PingOptions) actor.Actor {
+	return wrapSocket(conn, msgType, &ping)
+}
+
+func wrapSocket(conn *websocket.Conn, msgType interface{}, ping *PingOptions) actor.Actor {
 	return &websocketActor{
 		conn:         conn,
 		msgType:      reflect.TypeOf(msgType),
-		usePing:      usePing,
+		pingOpts:     ping,
 		pendingPings: make(map[string]time.Time),
 	}
 }
@@ -119,7 +165,8 @@ type websocketActor struct {
 	conn    *websocket.Conn
 	msgType reflect.Type
 
-	usePing      bool
+	// pingOpts configures pinging the peer, or is nil to not ping it.
+	pingOpts     *PingOptions
 	pingLock     sync.Mutex
 	pendingPings map[string]time.Time
 }
@@ -128,7 +175,7 @@ type websocketActor struct {
 func (s *websocketActor) Receive(ctx *actor.Context) error {
 	switch msg := ctx.Message().(type) {
 	case actor.PreStart:
-		if s.usePing {
+		if s.pingOpts != nil {
 			s.setupPingLoop(ctx)
 		}
 		go s.runReadLoop(ctx)
@@ -239,7 +286,7 @@ func (s *websocketActor) ping() error {
 
 	id := uuid.New().String()
 
-	deadline := time.Now().Add(pingWaitDuration)
+	deadline := time.Now().Add(s.pingOpts.pongWait())
 	err := s.conn.WriteControl(websocket.PingMessage, []byte(id), deadline)
 	if e, ok := err.(net.Error); ok && e.Temporary() {
 		return nil
@@ -258,7 +305,7 @@ func (s *websocketActor) runPingLoop(ctx *actor.Context) {
 			return err
 		}
 
-		t := time.NewTimer(pingInterval)
+		t := time.NewTimer(s.pingOpts.Interval)
 		defer t.Stop()
 		<-t.C
 		return nil
@@ -41,6 +41,7 @@ type Ref struct {
 	log *log.Entry
 
 	address        Address
+	typeName       string
 	registeredTime time.Time
 
 	system       *System
@@ -77,6 +78,7 @@ func newRef(system *System, parent *Ref, address Address, actor Actor) *Ref {
 		}),
 
 		address:        address,
+		typeName:       typeName,
 		registeredTime: time.Now(),
 
 		system:       system,
@@ -84,6 +84,29 @@ func (s *System) Get(address Address) *Ref {
 	return s.refs[address]
 }
 
+// Backlog summarizes the actors of one type.
+type Backlog struct {
+	// Actors is how many actors of the type there are.
+	Actors int
+	// Messages is how many messages are waiting in their inboxes.
+	Messages int
+}
+
+// Backlogs returns a summary of the actors in the system by their Go type.
+func (s *System) Backlogs() map[string]Backlog {
+	s.refsLock.RLock()
+	defer s.refsLock.RUnlock()
+
+	backlogs := map[string]Backlog{}
+	for _, ref := range s.refs {
+		b := backlogs[ref.typeName]
+		b.Actors++
+		b.Messages += ref.inbox.len()
+		backlogs[ref.typeName] = b
+	}
+	return backlogs
+}
+
 // ActorOf adds the actor with the provided address.
 // The second return value denotes whether a new actor was created or not.
 func (s *System) ActorOf(address Address, actor Actor) (*Ref, bool) {
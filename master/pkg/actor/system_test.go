@@ -78,3 +78,14 @@ func TestSystem_AskAll(t *testing.T) {
 	}
 	assert.Equal(t, index, 3)
 }
+
+func TestSystem_Backlogs(t *testing.T) {
+	system := NewSystem(t.Name())
+	system.MustActorOf(Addr("mock1"), &mockActor{})
+	system.MustActorOf(Addr("mock2"), &mockActor{})
+
+	backlog := system.Backlogs()["mockActor"]
+	assert.Equal(t, backlog.Actors, 2)
+	assert.Equal(t, backlog.Messages, 0)
+	assert.NilError(t, system.StopAndAwaitTermination())
+}
@@ -21,6 +21,16 @@ type AgentMessage struct {
 	StartContainer        *StartContainer
 	SignalContainer       *SignalContainer
 	AgentShutdown         *AgentShutdown
+	MasterRestarting      *MasterRestarting
+	EvictImages           *EvictImages
+	SetImageCacheLimit    *SetImageCacheLimit
+}
+
+// MasterRestarting tells the agent that the master is shutting down and will be back, so that the
+// agent keeps trying to reconnect for longer than usual rather than exiting and losing its
+// containers.
+type MasterRestarting struct {
+	ReconnectWait model.Duration
 }
 
 // MasterSetAgentOptions is the first message sent to an agent by the master. It lets
@@ -31,6 +41,21 @@ type MasterSetAgentOptions struct {
 	MasterInfo           MasterInfo
 	LoggingOptions       model.LoggingConfig
 	ContainersToReattach []ContainerReattach
+	// ContainerRuntime is the runtime the agent should run task containers with, as configured
+	// for its resource pool. Empty means Docker.
+	ContainerRuntime string
+	// SystemMetricsPeriod is how often the agent measures the resource usage of its containers;
+	// 0 means it does not.
+	SystemMetricsPeriod model.Duration
+	// ImageCacheLimit is the size in bytes that the agent keeps its cached images under; 0 means
+	// no limit.
+	ImageCacheLimit int64
+	// CPUSlots is how the agent splits its CPUs into slots if it has no GPUs, as configured for
+	// its resource pool.
+	CPUSlots model.CPUSlotsConfig
+	// AgentReconnectWait is how long the master waits for the agent to reconnect after losing its
+	// connection before failing its containers, so the agent keeps trying to reconnect for as long.
+	AgentReconnectWait model.Duration
 }
 
 // StartContainer notifies the agent to start a container with the provided spec.
@@ -45,5 +70,17 @@ type SignalContainer struct {
 	Signal      syscall.Signal
 }
 
+// EvictImages tells the agent to remove the given images from its cache. Images are identified by
+// ID or by reference; images that are in use by containers are not removed.
+type EvictImages struct {
+	Images []string
+}
+
+// SetImageCacheLimit tells the agent to keep its cached images under LimitBytes, evicting the
+// least recently used images; 0 means no limit.
+type SetImageCacheLimit struct {
+	LimitBytes int64
+}
+
 // ErrAgentMustReconnect is the error returned by the master when the agent must exit and reconnect.
 var ErrAgentMustReconnect = errors.New("agent is past reconnect period, it must restart")
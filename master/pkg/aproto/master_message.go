@@ -39,6 +39,9 @@ type MasterMessage struct {
 	ContainerStateChanged *ContainerStateChanged
 	ContainerLog          *ContainerLog
 	ContainerStatsRecord  *ContainerStatsRecord
+	DeviceHealthReport    *DeviceHealthReport
+	ContainerMetrics      *ContainerMetrics
+	ImageCacheReport      *ImageCacheReport
 }
 
 // ContainerReattach is a struct describing containers that can be reattached.
@@ -59,10 +62,17 @@ type ID string
 type AgentStarted struct {
 	Version              string
 	Label                string
+	Labels               map[string]string
 	Devices              []device.Device
 	ContainersReattached []ContainerReattachAck
 }
 
+// DeviceHealthReport notifies the master of the health of the agent's devices. Devices missing
+// from the report are left as they were.
+type DeviceHealthReport struct {
+	Devices map[device.ID]model.DeviceHealth
+}
+
 // ContainerStateChanged notifies the master that the agent transitioned the container state.
 type ContainerStateChanged struct {
 	Container cproto.Container
@@ -85,6 +95,28 @@ type ContainerStatsRecord struct {
 	TaskType model.TaskType
 }
 
+// ContainerMetrics notifies the master of the resource usage of a container.
+type ContainerMetrics struct {
+	ContainerID cproto.ID
+	Metrics     model.SystemMetrics
+}
+
+// ImageCacheReport notifies the master of the images cached on the agent.
+type ImageCacheReport struct {
+	Images     []CachedImage
+	LimitBytes int64
+}
+
+// CachedImage describes an image cached on the agent.
+type CachedImage struct {
+	ID       string
+	Tags     []string
+	Size     int64
+	Created  time.Time
+	LastUsed time.Time
+	InUse    bool
+}
+
 // Addresses calculates the address of containers and hosts based on the container
 // started information.
 func (c ContainerStarted) Addresses() []cproto.Address {
@@ -17,6 +17,23 @@ const (
 	// it dead. The agent waits (AgentReconnectWait - AgentReconnectBackoff) before stopping
 	// attempts and AgentReconnectWait before crashing.
 	AgentReconnectWait = AgentReconnectAttempts * AgentReconnectBackoff
+	// AgentHeartbeatInterval is how often the master pings agents by default.
+	AgentHeartbeatInterval = time.Minute
+	// AgentHeartbeatMissTolerance is how many heartbeat intervals an agent may go without
+	// answering a ping by default before its connection is considered lost.
+	AgentHeartbeatMissTolerance = 1
+)
+
+// Container runtimes agents can run task containers with.
+const (
+	// ContainerRuntimeDocker runs task containers with the Docker daemon.
+	ContainerRuntimeDocker = "docker"
+	// ContainerRuntimeApptainer runs task containers with Apptainer (formerly Singularity).
+	ContainerRuntimeApptainer = "apptainer"
+	// ContainerRuntimeEnroot runs task containers with enroot.
+	ContainerRuntimeEnroot = "enroot"
+	// ContainerRuntimePodman runs task containers with Podman, which can run without root.
+	ContainerRuntimePodman = "podman"
 )
 
 // GetRPConfig is a request from agent to RP actor for some config options.
@@ -24,7 +41,11 @@ type GetRPConfig struct{}
 
 // GetRPResponse is a response to the previous request.
 type GetRPResponse struct {
-	AgentReconnectWait    model.Duration
-	AgentReattachEnabled  bool
-	MaxZeroSlotContainers int
+	AgentReconnectWait          model.Duration
+	AgentReattachEnabled        bool
+	AgentHeartbeatInterval      model.Duration
+	AgentHeartbeatMissTolerance int
+	MaxZeroSlotContainers       int
+	ContainerRuntime            string
+	CPUSlots                    model.CPUSlotsConfig
 }
@@ -0,0 +1,196 @@
+// Package cron parses cron expressions, which schedule work that recurs, such as submitting an
+// experiment every night.
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxSearch bounds how far ahead Next looks for a matching time, so that expressions that never
+// match, such as "0 0 30 2 *", do not search forever.
+const maxSearch = 5
+
+// bounds are the bounds of the values of a field of an expression.
+type bounds struct {
+	name     string
+	min, max int
+}
+
+var fields = []bounds{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	// Sunday is either 0 or 7.
+	{"day of week", 0, 7},
+}
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is a parsed cron expression. Each field is a set of the values that match it.
+type Schedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	// As in other crons, if both day fields are restricted, a day matches if either of them does.
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+// Parse parses a standard five field cron expression ("minute hour day-of-month month
+// day-of-week"), in which each field is "*", a value, a range like "1-5", or a list of them, any of
+// which may have a step like "*/15". The macros "@hourly", "@daily", "@midnight", "@weekly",
+// "@monthly", "@yearly" and "@annually" are also accepted.
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, ok := macros[spec]; ok {
+		spec = expanded
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, errors.Errorf(
+			"cron expression %q must have %d fields, not %d", spec, len(fields), len(parts))
+	}
+
+	sets := make([]uint64, len(fields))
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s field of cron expression %q",
+				fields[i].name, spec)
+		}
+		sets[i] = set
+	}
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+	return &Schedule{
+		minute:        sets[0],
+		hour:          sets[1],
+		dayOfMonth:    sets[2],
+		month:         sets[3],
+		dayOfWeek:     sets[4],
+		anyDayOfMonth: strings.HasPrefix(parts[2], "*"),
+		anyDayOfWeek:  strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+func parseField(field string, b bounds) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(field, ",") {
+		rng, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			var err error
+			if rng = item[:i]; rng == "" {
+				return 0, errors.Errorf("%q has no range to step through", item)
+			}
+			if step, err = strconv.Atoi(item[i+1:]); err != nil || step < 1 {
+				return 0, errors.Errorf("%q has an invalid step", item)
+			}
+		}
+
+		var low, high int
+		switch i := strings.Index(rng, "-"); {
+		case rng == "*":
+			low, high = b.min, b.max
+		case i >= 0:
+			var err error
+			if low, err = parseValue(rng[:i], b); err != nil {
+				return 0, err
+			}
+			if high, err = parseValue(rng[i+1:], b); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, errors.Errorf("range %q is backwards", rng)
+			}
+		default:
+			var err error
+			if low, err = parseValue(rng, b); err != nil {
+				return 0, err
+			}
+			// A single value with a step, like "5/10", steps from the value to the maximum.
+			high = low
+			if rng != item {
+				high = b.max
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+func parseValue(s string, b bounds) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.Errorf("%q is not a number", s)
+	}
+	if v < b.min || v > b.max {
+		return 0, errors.Errorf("%d is not between %d and %d", v, b.min, b.max)
+	}
+	return v, nil
+}
+
+// Next returns the first time after t that matches the schedule, in the location of t, or the
+// zero time if there is none in the next few years. Times skipped by daylight saving time changes
+// do not match; the schedule moves on to the next time that does.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearch, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case !has(s.month, int(t.Month())):
+			t = forward(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc))
+		case !s.dayMatches(t):
+			t = forward(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc))
+		case !has(s.hour, t.Hour()):
+			t = nextHour(t)
+		case !has(s.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// forward returns next, the start of a later month or day than t, unless a daylight saving time
+// change put it before t, which happens when midnight is skipped. Then it returns the next hour.
+func forward(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+	return nextHour(t)
+}
+
+// nextHour returns the start of the hour after t. It adds minutes rather than setting the hour, as
+// the hour after t may have been skipped by a daylight saving time change.
+func nextHour(t time.Time) time.Time {
+	return t.Add(time.Duration(60-t.Minute()) * time.Minute)
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dayOfMonth := has(s.dayOfMonth, t.Day())
+	dayOfWeek := has(s.dayOfWeek, int(t.Weekday()))
+	if s.anyDayOfMonth || s.anyDayOfWeek {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
@@ -0,0 +1,72 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	at := func(loc *time.Location, s string) time.Time {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", s, loc)
+		require.NoError(t, err)
+		return parsed
+	}
+
+	tests := []struct {
+		spec string
+		from time.Time
+		next time.Time
+	}{
+		{"* * * * *", at(time.UTC, "2022-12-18 10:00"), at(time.UTC, "2022-12-18 10:01")},
+		{"30 2 * * *", at(time.UTC, "2022-12-18 10:00"), at(time.UTC, "2022-12-19 02:30")},
+		{"@daily", at(time.UTC, "2022-12-31 23:59"), at(time.UTC, "2023-01-01 00:00")},
+		{"@hourly", at(time.UTC, "2022-12-18 10:00"), at(time.UTC, "2022-12-18 11:00")},
+		{"*/15 9-17 * * 1-5", at(time.UTC, "2022-12-16 17:45"), at(time.UTC, "2022-12-19 09:00")},
+		{"5/20 * * * *", at(time.UTC, "2022-12-18 10:30"), at(time.UTC, "2022-12-18 10:45")},
+		{"0 0 1,15 * *", at(time.UTC, "2022-12-02 00:00"), at(time.UTC, "2022-12-15 00:00")},
+		{"0 0 * * 7", at(time.UTC, "2022-12-18 00:00"), at(time.UTC, "2022-12-25 00:00")},
+		{"0 0 29 2 *", at(time.UTC, "2022-03-01 00:00"), at(time.UTC, "2024-02-29 00:00")},
+		// Either day field matches if both are restricted.
+		{"0 0 13 * 5", at(time.UTC, "2022-12-10 00:00"), at(time.UTC, "2022-12-13 00:00")},
+		{"0 0 13 * 5", at(time.UTC, "2022-12-13 00:00"), at(time.UTC, "2022-12-16 00:00")},
+		// Nothing runs at 2:30 on the day clocks skip from 2:00 to 3:00.
+		{"30 2 * * *", at(newYork, "2023-03-11 12:00"), at(newYork, "2023-03-13 02:30")},
+		{"0 9 * * *", at(newYork, "2023-03-11 12:00"), at(newYork, "2023-03-12 09:00")},
+		{"0 0 30 2 *", at(time.UTC, "2022-12-18 00:00"), time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		require.NoError(t, err, tt.spec)
+		next := s.Next(tt.from)
+		require.True(t, tt.next.Equal(next), "%s after %s: expected %s, got %s",
+			tt.spec, tt.from, tt.next, next)
+		if !next.IsZero() {
+			require.Equal(t, tt.from.Location(), next.Location())
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"/5 * * * *",
+		"a * * * *",
+		"@sometimes",
+	} {
+		_, err := Parse(spec)
+		require.Error(t, err, spec)
+	}
+}
@@ -1,5 +1,5 @@
 // Package etc provides configuration files for setting up common
-// system programs like ssh, sshd, bash, notebooks, and tensorboard.
+// system programs like ssh, sshd, bash, notebooks, VS Code, and tensorboard.
 package etc
 
 import (
@@ -32,6 +32,8 @@ const (
 	TaskCheckReadyLogsResource = "check_ready_logs.py"
 	// TaskEnrichLogsResource is the script to enrich logs for slurm (which doesn't run fluent).
 	TaskEnrichLogsResource = "enrich_task_logs.py"
+	// VSCodeEntrypointResource is the script to set up a VS Code server.
+	VSCodeEntrypointResource = "vscode-entrypoint.sh"
 	// TensorboardEntryScriptResource is the script to set up TensorBoard.
 	TensorboardEntryScriptResource = "tensorboard-entrypoint.sh"
 	// TrialEntrypointScriptResource is the script to set up a trial.
@@ -52,7 +52,8 @@ func makeOutputConfig(
 	tlsConfig model.TLSClientConfig,
 ) {
 	switch {
-	case loggingConfig.DefaultLoggingConfig != nil:
+	case loggingConfig.DefaultLoggingConfig != nil, loggingConfig.CloudWatchLoggingConfig != nil:
+		// Logs bound for CloudWatch are shipped to the master as well, which writes them on.
 		// HACK: If a host resolves to both IPv4 and IPv6 addresses, Fluent Bit seems to only try IPv6 and
 		// fail if that connection doesn't work. IPv6 doesn't play well with Docker and many Linux
 		// distributions ship with an `/etc/hosts` that maps "localhost" to both 127.0.0.1 (IPv4) and
@@ -79,7 +80,13 @@ func makeOutputConfig(
   storage.total_limit_size 1G
 `, masterHost, masterPort)
 
-		if c := loggingConfig.DefaultLoggingConfig.AdditionalFluentOutputs; c != nil {
+		var additionalOutputs *string
+		if loggingConfig.DefaultLoggingConfig != nil {
+			additionalOutputs = loggingConfig.DefaultLoggingConfig.AdditionalFluentOutputs
+		} else {
+			additionalOutputs = loggingConfig.CloudWatchLoggingConfig.AdditionalFluentOutputs
+		}
+		if c := additionalOutputs; c != nil {
 			fmt.Fprint(config, *c)
 		}
 
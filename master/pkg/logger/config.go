@@ -2,30 +2,44 @@ package logger
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// TextFormat formats logs as human-readable lines.
+	TextFormat = "text"
+	// JSONFormat formats logs as JSON objects, one per line, for log aggregation systems.
+	JSONFormat = "json"
+)
+
 // DefaultConfig returns the default configuration of logger.
 func DefaultConfig() *Config {
 	return &Config{
-		Level: "info",
-		Color: true,
+		Level:  "info",
+		Color:  true,
+		Format: TextFormat,
 	}
 }
 
 // Config is the configuration of logger.
 type Config struct {
-	Level string `json:"level"`
-	Color bool   `json:"color"`
+	Level  string `json:"level"`
+	Color  bool   `json:"color"`
+	Format string `json:"format"`
 }
 
 // Validate implements the check.Validatable interface.
 func (c Config) Validate() []error {
+	var errs []error
 	if _, err := logrus.ParseLevel(c.Level); err != nil {
-		return []error{err}
+		errs = append(errs, err)
 	}
-	return nil
+	if c.Format != TextFormat && c.Format != JSONFormat {
+		errs = append(errs, fmt.Errorf("invalid log format: %s", c.Format))
+	}
+	return errs
 }
 
 // SetLogrus sets logrus globally.
@@ -36,9 +50,17 @@ func SetLogrus(c Config) {
 	}
 
 	logrus.SetLevel(level)
-	logrus.SetFormatter(&logrus.TextFormatter{
+	logrus.SetFormatter(Formatter(c))
+}
+
+// Formatter returns the formatter for logs in the configured format.
+func Formatter(c Config) logrus.Formatter {
+	if c.Format == JSONFormat {
+		return &logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano}
+	}
+	return &logrus.TextFormatter{
 		FullTimestamp: true,
 		ForceColors:   true,
 		DisableColors: !c.Color,
-	})
+	}
 }
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"context"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// RequestIDHeader is the header that carries the ID of a request, both from clients that want to
+// choose it and back to every client.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the IDs that clients choose, since they end up in every log of the
+// request.
+const maxRequestIDLength = 128
+
+type fieldsKey struct{}
+
+// WithFields returns a copy of ctx that carries the given fields in addition to those ctx already
+// carries, so that logs about the work done for a request can be correlated.
+func WithFields(ctx context.Context, fields Context) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, MergeContexts(FieldsFromContext(ctx), fields))
+}
+
+// FieldsFromContext returns the fields that ctx carries.
+func FieldsFromContext(ctx context.Context) Context {
+	fields, _ := ctx.Value(fieldsKey{}).(Context)
+	return fields
+}
+
+// RequestFields returns the fields that ctx carries that identify the request and the user that
+// made it, for the logs of work that outlives the request, such as the experiments it creates.
+func RequestFields(ctx context.Context) Context {
+	fields := Context{}
+	for k, v := range FieldsFromContext(ctx) {
+		if k == "request-id" || k == "user" {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// FromContext returns a logger with the fields that ctx carries.
+func FromContext(ctx context.Context) *logrus.Entry {
+	return logrus.WithFields(FieldsFromContext(ctx).Fields())
+}
+
+// RequestID returns the ID to use for a request: the given ID, if a client chose a valid one, or a
+// new one otherwise.
+func RequestID(id string) string {
+	if id == "" || len(id) > maxRequestIDLength {
+		return uuid.New().String()
+	}
+	for _, r := range id {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return uuid.New().String()
+		}
+	}
+	return id
+}
+
+// RequestIDMiddleware is an echo middleware that gives each request an ID, returned in the
+// RequestIDHeader header, and adds the ID and route of the request to the fields that the request
+// context carries.
+func RequestIDMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		id := RequestID(req.Header.Get(RequestIDHeader))
+		// The header is kept on the request so that it is forwarded to the gRPC server.
+		req.Header.Set(RequestIDHeader, id)
+		c.Response().Header().Set(RequestIDHeader, id)
+		c.SetRequest(req.WithContext(WithFields(req.Context(), Context{
+			"request-id": id,
+			"route":      c.Path(),
+		})))
+		return next(c)
+	}
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gotest.tools/assert"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	e := echo.New()
+	var fields Context
+	e.GET("/experiments/:id", func(c echo.Context) error {
+		fields = FieldsFromContext(c.Request().Context())
+		return c.NoContent(http.StatusOK)
+	}, RequestIDMiddleware)
+
+	req := httptest.NewRequest(http.MethodGet, "/experiments/1", nil)
+	req.Header.Set(RequestIDHeader, "client-chosen-id")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, rec.Header().Get(RequestIDHeader), "client-chosen-id")
+	assert.Equal(t, fields["request-id"], "client-chosen-id")
+	assert.Equal(t, fields["route"], "/experiments/:id")
+
+	// IDs that could break up log lines are replaced.
+	req = httptest.NewRequest(http.MethodGet, "/experiments/1", nil)
+	req.Header.Set(RequestIDHeader, "id\nlevel=error")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	id := rec.Header().Get(RequestIDHeader)
+	assert.Assert(t, id != "" && !strings.Contains(id, "\n"))
+	assert.Equal(t, fields["request-id"], id)
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.Equal(t, len(DefaultConfig().Validate()), 0)
+	assert.Equal(t, len(Config{Level: "info", Format: JSONFormat}.Validate()), 0)
+	assert.Equal(t, len(Config{Level: "info", Format: "xml"}.Validate()), 1)
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/proto/pkg/userv1"
+)
+
+// AccessTokenPrefix starts every access token, which tells them apart from session tokens.
+const AccessTokenPrefix = "dtk_"
+
+// AccessTokenScope limits what an access token can be used for.
+type AccessTokenScope string
+
+const (
+	// AccessTokenScopeFull allows everything the user can do.
+	AccessTokenScopeFull AccessTokenScope = "FULL"
+	// AccessTokenScopeReadOnly allows only requests that do not change anything.
+	AccessTokenScopeReadOnly AccessTokenScope = "READ_ONLY"
+	// AccessTokenScopeCheckpointDownload allows only looking up and downloading checkpoints.
+	AccessTokenScopeCheckpointDownload AccessTokenScope = "CHECKPOINT_DOWNLOAD"
+)
+
+// AccessTokenScopeFromProto returns an AccessTokenScope from its protobuf representation, or the
+// empty scope if it is unspecified.
+func AccessTokenScopeFromProto(s userv1.AccessTokenScope) AccessTokenScope {
+	switch s {
+	case userv1.AccessTokenScope_ACCESS_TOKEN_SCOPE_FULL:
+		return AccessTokenScopeFull
+	case userv1.AccessTokenScope_ACCESS_TOKEN_SCOPE_READ_ONLY:
+		return AccessTokenScopeReadOnly
+	case userv1.AccessTokenScope_ACCESS_TOKEN_SCOPE_CHECKPOINT_DOWNLOAD:
+		return AccessTokenScopeCheckpointDownload
+	default:
+		return ""
+	}
+}
+
+// AllowsAll returns whether the scope allows everything; sessions that did not come from an
+// access token have the empty scope.
+func (s AccessTokenScope) AllowsAll() bool {
+	return s == "" || s == AccessTokenScopeFull
+}
+
+// Proto returns the protobuf representation of the scope.
+func (s AccessTokenScope) Proto() userv1.AccessTokenScope {
+	switch s {
+	case AccessTokenScopeFull:
+		return userv1.AccessTokenScope_ACCESS_TOKEN_SCOPE_FULL
+	case AccessTokenScopeReadOnly:
+		return userv1.AccessTokenScope_ACCESS_TOKEN_SCOPE_READ_ONLY
+	case AccessTokenScopeCheckpointDownload:
+		return userv1.AccessTokenScope_ACCESS_TOKEN_SCOPE_CHECKPOINT_DOWNLOAD
+	default:
+		return userv1.AccessTokenScope_ACCESS_TOKEN_SCOPE_UNSPECIFIED
+	}
+}
+
+// AccessToken corresponds to a row in the "access_tokens" DB table. Only a hash of the token
+// itself is kept.
+type AccessToken struct {
+	bun.BaseModel `bun:"table:access_tokens"`
+
+	ID          int              `bun:"id,pk,autoincrement"`
+	UserID      UserID           `bun:"user_id"`
+	TokenHash   string           `bun:"token_hash"`
+	Description string           `bun:"description"`
+	Scope       AccessTokenScope `bun:"scope"`
+	CreatedAt   time.Time        `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	ExpiresAt   *time.Time       `bun:"expires_at"`
+	RevokedAt   *time.Time       `bun:"revoked_at"`
+	LastUsedAt  *time.Time       `bun:"last_used_at"`
+}
+
+// Proto returns the protobuf representation of the token.
+func (t AccessToken) Proto() *userv1.AccessToken {
+	optionalTime := func(t *time.Time) *timestamppb.Timestamp {
+		if t == nil {
+			return nil
+		}
+		return timestamppb.New(*t)
+	}
+	return &userv1.AccessToken{
+		Id:          int32(t.ID),
+		UserId:      int32(t.UserID),
+		Description: t.Description,
+		Scope:       t.Scope.Proto(),
+		CreatedAt:   timestamppb.New(t.CreatedAt),
+		ExpiresAt:   optionalTime(t.ExpiresAt),
+		RevokedAt:   optionalTime(t.RevokedAt),
+		LastUsedAt:  optionalTime(t.LastUsedAt),
+	}
+}
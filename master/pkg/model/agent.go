@@ -63,6 +63,7 @@ type SlotSummary struct {
 	Enabled   bool              `json:"enabled"`
 	Container *cproto.Container `json:"container"`
 	Draining  bool              `json:"draining"`
+	Health    *DeviceHealth     `json:"health"`
 }
 
 // ToProto converts a SlotSummary to its protobuf representation.
@@ -73,6 +74,39 @@ func (s SlotSummary) ToProto() *agentv1.Slot {
 		Enabled:   s.Enabled,
 		Container: s.Container.ToProto(),
 		Draining:  s.Draining,
+		Health:    s.Health.ToProto(),
+	}
+}
+
+// DeviceHealth is the health of a device as last reported by its agent.
+type DeviceHealth struct {
+	Healthy bool `json:"healthy"`
+	// Reason explains why the device is unhealthy; it is empty for healthy devices.
+	Reason string `json:"reason,omitempty"`
+	// XidErrors are the critical Xid error codes the device has raised since the agent started.
+	XidErrors []int `json:"xid_errors,omitempty"`
+	// EccErrors is the count of uncorrected (volatile) ECC errors.
+	EccErrors   int       `json:"ecc_errors"`
+	Temperature int       `json:"temperature"`
+	ReportTime  time.Time `json:"report_time"`
+}
+
+// ToProto converts a DeviceHealth to its protobuf representation.
+func (h *DeviceHealth) ToProto() *agentv1.DeviceHealth {
+	if h == nil {
+		return nil
+	}
+	xids := make([]int32, 0, len(h.XidErrors))
+	for _, xid := range h.XidErrors {
+		xids = append(xids, int32(xid))
+	}
+	return &agentv1.DeviceHealth{
+		Healthy:     h.Healthy,
+		Reason:      h.Reason,
+		XidErrors:   xids,
+		EccErrors:   int32(h.EccErrors),
+		Temperature: int32(h.Temperature),
+		ReportTime:  protoutils.ToTimestamp(h.ReportTime),
 	}
 }
 
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
+	"github.com/determined-ai/determined/proto/pkg/artifactv1"
+)
+
+// Artifact is the model for artifacts in the database. An artifact is an output file of a task,
+// such as a plot or an exported model, that the task uploaded to checkpoint storage under
+// artifacts/<uuid>/<name>. Unlike checkpoints, artifacts are never garbage collected.
+type Artifact struct {
+	bun.BaseModel `bun:"table:artifacts"`
+
+	UUID         uuid.UUID                       `bun:"uuid,pk,type:uuid"`
+	TaskID       TaskID                          `bun:"task_id"`
+	AllocationID *AllocationID                   `bun:"allocation_id"`
+	Name         string                          `bun:"name"`
+	Type         string                          `bun:"type"`
+	ReportTime   time.Time                       `bun:"report_time"`
+	Resources    map[string]int64                `bun:"resources,type:jsonb"`
+	Metadata     JSONObj                         `bun:"metadata,type:jsonb"`
+	Storage      expconf.CheckpointStorageConfig `bun:"storage,type:jsonb"`
+}
+
+// StorageKey returns the key of the artifact's file in its checkpoint storage.
+func (a *Artifact) StorageKey() string {
+	return "artifacts/" + a.UUID.String() + "/" + a.Name
+}
+
+// ToProto converts a bun model of an artifact to a proto object. Secrets of its storage, such as
+// S3 access keys, are hidden.
+func (a *Artifact) ToProto() (*artifactv1.Artifact, error) {
+	metadata, err := structpb.NewStruct(a.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := a.Storage.Printable().MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	storage := &structpb.Struct{}
+	if err := storage.UnmarshalJSON(bytes); err != nil {
+		return nil, err
+	}
+
+	p := &artifactv1.Artifact{
+		Uuid:       a.UUID.String(),
+		TaskId:     string(a.TaskID),
+		Name:       a.Name,
+		Type:       a.Type,
+		ReportTime: timestamppb.New(a.ReportTime),
+		Resources:  a.Resources,
+		Metadata:   metadata,
+		Storage:    storage,
+	}
+	if a.AllocationID != nil {
+		p.AllocationId = string(*a.AllocationID)
+	}
+	return p, nil
+}
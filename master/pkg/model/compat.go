@@ -42,6 +42,9 @@ func (r ResourcesConfig) ToExpconf() expconf.ResourcesConfig {
 		RawResourcePool:   ptrs.Ptr(r.ResourcePool),
 		RawPriority:       r.Priority,
 		RawDevices:        r.Devices.ToExpconf(),
+
+		RawLabelConstraints:       r.LabelConstraints,
+		RawExpectedRuntimeSeconds: r.ExpectedRuntimeSeconds,
 	}).(expconf.ResourcesConfig)
 }
 
@@ -101,10 +104,14 @@ func (e Environment) ToExpconf() expconf.EnvironmentConfig {
 		RawImage:                &image,
 		RawEnvironmentVariables: &vars,
 		RawPorts:                e.Ports,
+		RawProxyPorts:           e.ProxyPorts,
 		RawRegistryAuth:         e.RegistryAuth,
 		RawForcePullImage:       ptrs.Ptr(e.ForcePullImage),
 		RawPodSpec:              (*expconf.PodSpec)(e.PodSpec),
 		RawAddCapabilities:      e.AddCapabilities,
 		RawDropCapabilities:     e.DropCapabilities,
+		RawPodTemplateName:      e.PodTemplateName,
+		RawInitContainers:       e.InitContainers,
+		RawSidecars:             e.Sidecars,
 	}).(expconf.EnvironmentConfig)
 }
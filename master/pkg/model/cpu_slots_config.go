@@ -0,0 +1,41 @@
+package model
+
+import (
+	"github.com/determined-ai/determined/master/pkg/check"
+)
+
+// CPUSlotsConfig configures how agents without GPUs split their CPUs into slots and how much CPU
+// the containers on those slots get.
+type CPUSlotsConfig struct {
+	// SlotsPerCPU is the number of slots each CPU of an agent provides. Ratios above 1
+	// oversubscribe the CPUs. 0 keeps a single slot for all of an agent's CPUs.
+	SlotsPerCPU float64 `json:"slots_per_cpu"`
+	// CPUSharesPerSlot is the relative CPU weight a container gets for each of its slots when
+	// CPUs are contended; 0 leaves the container runtime's default.
+	CPUSharesPerSlot int64 `json:"cpu_shares_per_slot"`
+	// CPULimitPerSlot is the number of CPUs a container may use for each of its slots; 0 means
+	// no limit.
+	CPULimitPerSlot float64 `json:"cpu_limit_per_slot"`
+}
+
+// Validate implements the check.Validatable interface.
+func (c CPUSlotsConfig) Validate() []error {
+	return []error{
+		check.True(c.SlotsPerCPU >= 0, "slots_per_cpu must be >= 0"),
+		check.True(c.CPUSharesPerSlot == 0 || c.CPUSharesPerSlot >= 2,
+			"cpu_shares_per_slot must be 0 or >= 2"),
+		check.True(c.CPULimitPerSlot >= 0, "cpu_limit_per_slot must be >= 0"),
+	}
+}
+
+// Slots returns the number of slots an agent with the given number of CPUs provides. There is
+// always at least one.
+func (c CPUSlotsConfig) Slots(cpus int) int {
+	if c.SlotsPerCPU <= 0 {
+		return 1
+	}
+	if slots := int(float64(cpus) * c.SlotsPerCPU); slots > 1 {
+		return slots
+	}
+	return 1
+}
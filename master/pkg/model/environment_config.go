@@ -24,6 +24,9 @@ const (
 	DeterminedK8ContainerName = "determined-container"
 	// DeterminedK8FluentContainerName is the name of the container running Fluent Bit in each pod.
 	DeterminedK8FluentContainerName = "determined-fluent-container"
+	// DeterminedK8InitContainerName is the name of the init container that unpacks the files of
+	// the task in each pod.
+	DeterminedK8InitContainerName = "determined-init-container"
 )
 
 // Environment configures the environment of a Determined command or experiment.
@@ -32,12 +35,20 @@ type Environment struct {
 	EnvironmentVariables RuntimeItems `json:"environment_variables,omitempty"`
 
 	Ports          map[string]int    `json:"ports"`
+	ProxyPorts     map[string]int    `json:"proxy_ports,omitempty"`
 	RegistryAuth   *types.AuthConfig `json:"registry_auth,omitempty"`
 	ForcePullImage bool              `json:"force_pull_image"`
 	PodSpec        *k8sV1.Pod        `json:"pod_spec"`
 
 	AddCapabilities  []string `json:"add_capabilities"`
 	DropCapabilities []string `json:"drop_capabilities"`
+
+	// PodTemplateName names a pod template stored in the master to merge PodSpec over.
+	PodTemplateName *string `json:"pod_template_name,omitempty"`
+
+	// InitContainers run in Kubernetes pods before the task, and Sidecars alongside it.
+	InitContainers []k8sV1.Container `json:"init_containers,omitempty"`
+	Sidecars       []k8sV1.Container `json:"sidecars,omitempty"`
 }
 
 // RuntimeItem configures the runtime image.
@@ -150,7 +161,31 @@ func (r *RuntimeItems) For(deviceType device.Type) []string {
 
 // Validate implements the check.Validatable interface.
 func (e Environment) Validate() []error {
-	return validatePodSpec(e.PodSpec)
+	errs := validatePodSpec(e.PodSpec)
+	return append(errs, validateContainers(e.InitContainers, e.Sidecars)...)
+}
+
+// validateContainers checks that init containers and sidecars can be added to the pods of tasks
+// next to the containers of Determined.
+func validateContainers(initContainers, sidecars []k8sV1.Container) []error {
+	var errs []error
+	names := map[string]bool{
+		DeterminedK8ContainerName:       true,
+		DeterminedK8FluentContainerName: true,
+		DeterminedK8InitContainerName:   true,
+	}
+	for _, containers := range [][]k8sV1.Container{initContainers, sidecars} {
+		for _, container := range containers {
+			errs = append(errs,
+				check.NotEmpty(container.Name, "container Name must be set"),
+				check.NotEmpty(container.Image, "container Image must be set"),
+				check.False(names[container.Name],
+					"container Name %q is reserved or used more than once", container.Name),
+			)
+			names[container.Name] = true
+		}
+	}
+	return errs
 }
 
 func validatePodSpec(podSpec *k8sV1.Pod) []error {
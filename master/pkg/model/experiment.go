@@ -12,6 +12,7 @@ import (
 	"github.com/determined-ai/determined/master/pkg/protoutils"
 
 	"github.com/jackc/pgtype"
+	"github.com/uptrace/bun"
 	"google.golang.org/protobuf/encoding/protojson"
 
 	"github.com/determined-ai/determined/proto/pkg/experimentv1"
@@ -766,6 +767,109 @@ const (
 	ValidationMetric MetricType = iota
 )
 
+const (
+	// TrainingMetricGroup is the name of the group of metrics reported during training.
+	TrainingMetricGroup = "training"
+	// ValidationMetricGroup is the name of the group of metrics reported during validation.
+	ValidationMetricGroup = "validation"
+)
+
+// TrialProfilerTimelineBucket aggregates the readings of a profiler series of a trial within a
+// second, which is how the series are stored to be queried by time range.
+type TrialProfilerTimelineBucket struct {
+	bun.BaseModel `bun:"table:trial_profiler_timeline,alias:t"`
+
+	TrialID    int       `bun:"trial_id,pk"`
+	Name       string    `bun:"name,pk"`
+	AgentID    string    `bun:"agent_id,pk"`
+	GPUUUID    string    `bun:"gpu_uuid,pk"`
+	MetricType string    `bun:"metric_type,pk"`
+	Bucket     time.Time `bun:"bucket,pk"`
+	Count      int       `bun:"count"`
+	Sum        float64   `bun:"sum"`
+	Min        float32   `bun:"min"`
+	Max        float32   `bun:"max"`
+}
+
+// TrialProfilerTimelinePoint summarizes the readings of a profiler series of a trial within a span
+// of time starting at Time.
+type TrialProfilerTimelinePoint struct {
+	TrialID    int       `bun:"trial_id"`
+	Name       string    `bun:"name"`
+	AgentID    string    `bun:"agent_id"`
+	GPUUUID    string    `bun:"gpu_uuid"`
+	MetricType string    `bun:"metric_type"`
+	Time       time.Time `bun:"time"`
+	Mean       float64   `bun:"mean"`
+	Min        float32   `bun:"min"`
+	Max        float32   `bun:"max"`
+}
+
+// Labels returns the labels of the series of the point.
+func (p TrialProfilerTimelinePoint) Labels() *trialv1.TrialProfilerMetricLabels {
+	return &trialv1.TrialProfilerMetricLabels{
+		TrialId: int32(p.TrialID),
+		Name:    p.Name,
+		AgentId: p.AgentID,
+		GpuUuid: p.GPUUUID,
+		MetricType: trialv1.TrialProfilerMetricLabels_ProfilerMetricType(
+			trialv1.TrialProfilerMetricLabels_ProfilerMetricType_value[p.MetricType]),
+	}
+}
+
+// Proto returns the protobuf representation of the point.
+func (p TrialProfilerTimelinePoint) Proto() *apiv1.ProfilerTimelinePoint {
+	return &apiv1.ProfilerTimelinePoint{
+		Time: timestamppb.New(p.Time),
+		Mean: p.Mean,
+		Min:  float64(p.Min),
+		Max:  float64(p.Max),
+	}
+}
+
+// TrialAlert is an alert from the config of an experiment that fired for one of its trials.
+type TrialAlert struct {
+	bun.BaseModel `bun:"table:trial_alerts"`
+
+	TrialID      int       `bun:"trial_id,pk"`
+	Name         string    `bun:"name,pk"`
+	MetricGroup  string    `bun:"metric_group"`
+	Metric       string    `bun:"metric"`
+	Value        float64   `bun:"value"`
+	TotalBatches int       `bun:"total_batches"`
+	FiredAt      time.Time `bun:"fired_at"`
+}
+
+// TrialRunState is the state that determines the order of the data that a run of a trial trains
+// on: the seed that shuffles the data, the number of slots it is sharded across, and the batches
+// that are skipped when continuing from a checkpoint.
+type TrialRunState struct {
+	bun.BaseModel `bun:"table:trial_run_states"`
+
+	TrialID        int        `bun:"trial_id,pk"`
+	RunID          int        `bun:"run_id,pk"`
+	TrialSeed      int64      `bun:"trial_seed"`
+	Slots          int        `bun:"slots"`
+	StepsCompleted int        `bun:"steps_completed"`
+	CheckpointUUID *uuid.UUID `bun:"checkpoint_uuid"`
+	StartTime      time.Time  `bun:"start_time,nullzero,default:now()"`
+}
+
+// Proto returns the protobuf representation of the run state.
+func (s TrialRunState) Proto() *apiv1.TrialRunState {
+	ps := &apiv1.TrialRunState{
+		RunId:          int32(s.RunID),
+		TrialSeed:      uint32(s.TrialSeed),
+		Slots:          int32(s.Slots),
+		StepsCompleted: int32(s.StepsCompleted),
+		StartTime:      timestamppb.New(s.StartTime),
+	}
+	if s.CheckpointUUID != nil {
+		ps.CheckpointUuid = s.CheckpointUUID.String()
+	}
+	return ps
+}
+
 // HPImportanceTrialData is the input to the hyperparameter importance algorithm.
 type HPImportanceTrialData struct {
 	TrialID int                    `db:"trial_id"`
@@ -788,6 +892,8 @@ type MetricHPImportance struct {
 	InProgress         bool               `json:"in_progress"`
 	ExperimentProgress float64            `json:"experiment_progress"`
 	HpImportance       map[string]float64 `json:"hp_importance"`
+	Trials             int                `json:"trials"`
+	DataPoints         int                `json:"data_points"`
 }
 
 // SetMetricHPImportance is a convenience function when modifying results for a specific metric.
@@ -92,6 +92,9 @@ type ResourcesConfig struct {
 	ResourcePool   string       `json:"resource_pool"`
 	Priority       *int         `json:"priority,omitempty"`
 
+	LabelConstraints       map[string]string `json:"label_constraints,omitempty"`
+	ExpectedRuntimeSeconds *int              `json:"expected_runtime_seconds,omitempty"`
+
 	Devices DevicesConfig `json:"devices"`
 }
 
@@ -36,6 +36,8 @@ const (
 	JobTypeExperiment JobType = "EXPERIMENT"
 	// JobTypeCheckpointGC is the "CheckpointGC" job type for enum.job_type in Postgres.
 	JobTypeCheckpointGC JobType = "CHECKPOINT_GC"
+	// JobTypeVSCode is the "VSCODE" job type for the enum.job_type in Postgres.
+	JobTypeVSCode JobType = "VSCODE"
 )
 
 // Proto returns the proto representation of the job type.
@@ -53,6 +55,8 @@ func (jt JobType) Proto() jobv1.Type {
 		return jobv1.Type_TYPE_TENSORBOARD
 	case JobTypeCheckpointGC:
 		return jobv1.Type_TYPE_CHECKPOINT_GC
+	case JobTypeVSCode:
+		return jobv1.Type_TYPE_VSCODE
 	default:
 		panic("unknown job type")
 	}
@@ -73,6 +77,8 @@ func JobTypeFromProto(t jobv1.Type) JobType {
 		return JobTypeTensorboard
 	case jobv1.Type_TYPE_CHECKPOINT_GC:
 		return JobTypeCheckpointGC
+	case jobv1.Type_TYPE_VSCODE:
+		return JobTypeVSCode
 	default:
 		panic("unknown job type")
 	}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestJobTypeProto(t *testing.T) {
+	for _, jobType := range []JobType{
+		JobTypeNotebook,
+		JobTypeShell,
+		JobTypeCommand,
+		JobTypeTensorboard,
+		JobTypeExperiment,
+		JobTypeCheckpointGC,
+		JobTypeVSCode,
+	} {
+		assert.Equal(t, JobTypeFromProto(jobType.Proto()), jobType)
+	}
+}
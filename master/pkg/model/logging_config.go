@@ -11,8 +11,9 @@ import (
 
 // LoggingConfig configures logging for tasks (currently only trials) in Determined.
 type LoggingConfig struct {
-	DefaultLoggingConfig *DefaultLoggingConfig `union:"type,default" json:"-"`
-	ElasticLoggingConfig *ElasticLoggingConfig `union:"type,elastic" json:"-"`
+	DefaultLoggingConfig    *DefaultLoggingConfig    `union:"type,default" json:"-"`
+	ElasticLoggingConfig    *ElasticLoggingConfig    `union:"type,elastic" json:"-"`
+	CloudWatchLoggingConfig *CloudWatchLoggingConfig `union:"type,cloudwatch" json:"-"`
 }
 
 // Resolve resolves the parts of the TaskContainerDefaultsConfig that must be evaluated on
@@ -60,6 +61,27 @@ func (o *ElasticLoggingConfig) Resolve() error {
 	return o.Security.Resolve()
 }
 
+// CloudWatchLoggingConfig configures logging for tasks using Fluent+HTTP to the master, which
+// writes the logs of each task to its own stream in an AWS CloudWatch Logs log group.
+type CloudWatchLoggingConfig struct {
+	// Region defaults to the region of the AWS environment the master runs in.
+	Region   string `json:"region"`
+	LogGroup string `json:"log_group"`
+	// RoleARN is a role to assume to access CloudWatch. Otherwise, the master uses the default AWS
+	// credentials chain, such as the instance profile or the service account role of its pod.
+	RoleARN                 *string `json:"role_arn,omitempty"`
+	AdditionalFluentOutputs *string `json:"additional_fluent_outputs,omitempty"`
+}
+
+// Validate implements the check.Validatable interface.
+func (o CloudWatchLoggingConfig) Validate() []error {
+	var errs []error
+	if o.LogGroup == "" {
+		errs = append(errs, errors.New("log_group must be specified"))
+	}
+	return errs
+}
+
 // ElasticSecurityConfig configures security-related options for the elastic logging backend.
 type ElasticSecurityConfig struct {
 	Username *string         `json:"username"`
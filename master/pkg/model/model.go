@@ -2,6 +2,11 @@ package model
 
 import (
 	"time"
+
+	"github.com/uptrace/bun"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
 )
 
 // Model represents a row from the `models` table.
@@ -32,3 +37,116 @@ type ModelVersion struct {
 	Notes           string    `db:"readme" json:"notes"`
 	Username        string    `db:"username" json:"username"`
 }
+
+// ModelVersionStage is the stage of a model version in a deployment workflow.
+type ModelVersionStage string
+
+const (
+	// ModelVersionStageNone is the stage of versions that have not been placed in a stage.
+	ModelVersionStageNone ModelVersionStage = "NONE"
+	// ModelVersionStageStaging is the stage of versions being validated for production.
+	ModelVersionStageStaging ModelVersionStage = "STAGING"
+	// ModelVersionStageProduction is the stage of versions deployed to production.
+	ModelVersionStageProduction ModelVersionStage = "PRODUCTION"
+	// ModelVersionStageArchived is the stage of versions that are no longer in use.
+	ModelVersionStageArchived ModelVersionStage = "ARCHIVED"
+)
+
+// ModelVersionStageFromProto returns a ModelVersionStage from its protobuf representation,
+// or the empty string if it is unspecified.
+func ModelVersionStageFromProto(s modelv1.ModelVersionStage) ModelVersionStage {
+	switch s {
+	case modelv1.ModelVersionStage_MODEL_VERSION_STAGE_NONE:
+		return ModelVersionStageNone
+	case modelv1.ModelVersionStage_MODEL_VERSION_STAGE_STAGING:
+		return ModelVersionStageStaging
+	case modelv1.ModelVersionStage_MODEL_VERSION_STAGE_PRODUCTION:
+		return ModelVersionStageProduction
+	case modelv1.ModelVersionStage_MODEL_VERSION_STAGE_ARCHIVED:
+		return ModelVersionStageArchived
+	default:
+		return ""
+	}
+}
+
+// Proto returns the protobuf representation of the stage.
+func (s ModelVersionStage) Proto() modelv1.ModelVersionStage {
+	switch s {
+	case ModelVersionStageNone:
+		return modelv1.ModelVersionStage_MODEL_VERSION_STAGE_NONE
+	case ModelVersionStageStaging:
+		return modelv1.ModelVersionStage_MODEL_VERSION_STAGE_STAGING
+	case ModelVersionStageProduction:
+		return modelv1.ModelVersionStage_MODEL_VERSION_STAGE_PRODUCTION
+	case ModelVersionStageArchived:
+		return modelv1.ModelVersionStage_MODEL_VERSION_STAGE_ARCHIVED
+	default:
+		return modelv1.ModelVersionStage_MODEL_VERSION_STAGE_UNSPECIFIED
+	}
+}
+
+// ModelVersionTransitionState is the state of a request to move a model version between stages.
+type ModelVersionTransitionState string
+
+const (
+	// ModelVersionTransitionPending is the state of transitions waiting for review by an approver.
+	ModelVersionTransitionPending ModelVersionTransitionState = "PENDING"
+	// ModelVersionTransitionApplied is the state of transitions that moved their version.
+	ModelVersionTransitionApplied ModelVersionTransitionState = "APPLIED"
+	// ModelVersionTransitionRejected is the state of transitions an approver rejected.
+	ModelVersionTransitionRejected ModelVersionTransitionState = "REJECTED"
+)
+
+// Proto returns the protobuf representation of the transition state.
+func (s ModelVersionTransitionState) Proto() modelv1.ModelVersionTransitionState {
+	switch s {
+	case ModelVersionTransitionPending:
+		return modelv1.ModelVersionTransitionState_MODEL_VERSION_TRANSITION_STATE_PENDING
+	case ModelVersionTransitionApplied:
+		return modelv1.ModelVersionTransitionState_MODEL_VERSION_TRANSITION_STATE_APPLIED
+	case ModelVersionTransitionRejected:
+		return modelv1.ModelVersionTransitionState_MODEL_VERSION_TRANSITION_STATE_REJECTED
+	default:
+		return modelv1.ModelVersionTransitionState_MODEL_VERSION_TRANSITION_STATE_UNSPECIFIED
+	}
+}
+
+// ModelVersionTransition represents a row from the `model_version_transitions` table.
+type ModelVersionTransition struct {
+	bun.BaseModel `bun:"table:model_version_transitions"`
+
+	ID              int                         `bun:"id,pk,autoincrement"`
+	ModelVersionID  int                         `bun:"model_version_id"`
+	FromStage       ModelVersionStage           `bun:"from_stage"`
+	ToStage         ModelVersionStage           `bun:"to_stage"`
+	ArchiveExisting bool                        `bun:"archive_existing"`
+	State           ModelVersionTransitionState `bun:"state"`
+	Comment         string                      `bun:"comment"`
+	RequestedBy     UserID                      `bun:"requested_by"`
+	RequestedTime   time.Time                   `bun:"requested_time,nullzero,default:now()"`
+	ReviewedBy      *UserID                     `bun:"reviewed_by"`
+	ReviewedTime    *time.Time                  `bun:"reviewed_time"`
+
+	RequestedByUsername string  `bun:"requested_by_username,scanonly"`
+	ReviewedByUsername  *string `bun:"reviewed_by_username,scanonly"`
+}
+
+// Proto returns the protobuf representation of the transition.
+func (t ModelVersionTransition) Proto() *modelv1.ModelVersionTransition {
+	pt := &modelv1.ModelVersionTransition{
+		Id:              int32(t.ID),
+		ModelVersionId:  int32(t.ModelVersionID),
+		FromStage:       t.FromStage.Proto(),
+		ToStage:         t.ToStage.Proto(),
+		ArchiveExisting: t.ArchiveExisting,
+		State:           t.State.Proto(),
+		Comment:         t.Comment,
+		RequestedBy:     t.RequestedByUsername,
+		RequestedTime:   timestamppb.New(t.RequestedTime),
+		ReviewedBy:      t.ReviewedByUsername,
+	}
+	if t.ReviewedTime != nil {
+		pt.ReviewedTime = timestamppb.New(*t.ReviewedTime)
+	}
+	return pt
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+)
+
+// ResourcePoolBinding is the model for resource_pool_bindings in the database. It restricts a
+// resource pool to the tasks of the bound users, the members of the bound groups, and the
+// experiments in the bound workspaces. Pools without a binding may be used by everyone.
+type ResourcePoolBinding struct {
+	bun.BaseModel `bun:"table:resource_pool_bindings"`
+
+	ResourcePool string  `bun:"resource_pool,pk"`
+	UserIDs      []int32 `bun:"user_ids,array"`
+	GroupIDs     []int32 `bun:"group_ids,array"`
+	WorkspaceIDs []int32 `bun:"workspace_ids,array"`
+}
+
+// Empty returns whether the binding binds nothing, which leaves its pool open to everyone.
+func (b ResourcePoolBinding) Empty() bool {
+	return len(b.UserIDs) == 0 && len(b.GroupIDs) == 0 && len(b.WorkspaceIDs) == 0
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// SlotReservation is the model for slot_reservations in the database. It holds a number of slots
+// in a resource pool for a window of time, during which only the tasks of the designated users
+// and workspaces may be scheduled onto them.
+type SlotReservation struct {
+	bun.BaseModel `bun:"table:slot_reservations"`
+
+	ID           int       `bun:"id,pk,autoincrement"`
+	ResourcePool string    `bun:"resource_pool"`
+	Slots        int       `bun:"slots"`
+	StartTime    time.Time `bun:"start_time"`
+	EndTime      time.Time `bun:"end_time"`
+	UserIDs      []int32   `bun:"user_ids,array"`
+	WorkspaceIDs []int32   `bun:"workspace_ids,array"`
+	Description  string    `bun:"description"`
+	CreatedBy    UserID    `bun:"created_by"`
+}
+
+// ActiveAt returns whether the reservation holds its slots at the given time.
+func (r SlotReservation) ActiveAt(t time.Time) bool {
+	return !t.Before(r.StartTime) && t.Before(r.EndTime)
+}
+
+// Covers returns whether a task owned by the given user, in the given workspace, may use the
+// reserved slots. Either may be nil if unknown.
+func (r SlotReservation) Covers(userID *UserID, workspaceID *int) bool {
+	if userID != nil {
+		for _, id := range r.UserIDs {
+			if UserID(id) == *userID {
+				return true
+			}
+		}
+	}
+	if workspaceID != nil {
+		for _, id := range r.WorkspaceIDs {
+			if int(id) == *workspaceID {
+				return true
+			}
+		}
+	}
+	return false
+}
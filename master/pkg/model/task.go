@@ -46,6 +46,8 @@ const (
 	TaskTypeTensorboard TaskType = "TENSORBOARD"
 	// TaskTypeCheckpointGC is the "CHECKPOINT_GC" job type for the enum public.job_type in Postgres.
 	TaskTypeCheckpointGC TaskType = "CHECKPOINT_GC"
+	// TaskTypeVSCode is the "VSCODE" task type for the enum.task_type in Postgres.
+	TaskTypeVSCode TaskType = "VSCODE"
 )
 
 // TaskLogVersion is the version for our log-storing scheme. Useful because changing designs
@@ -113,6 +115,55 @@ type TaskStats struct {
 	EndTime      *time.Time
 }
 
+// SlotHours is the slot hours that allocations used in a resource pool: the sum of the product of
+// the slots and the hours of each allocation.
+type SlotHours struct {
+	ResourcePool string  `bun:"resource_pool"`
+	SlotHours    float64 `bun:"slot_hours"`
+}
+
+// SystemMetrics is the usage of the resources of a container at a time. Usage that the runtime of
+// the container cannot measure is nil.
+type SystemMetrics struct {
+	Time time.Time `bun:"time"`
+	// CPUCores is the number of CPU cores in use, on average since the last measurement.
+	CPUCores       *float64 `bun:"cpu_cores"`
+	MemoryBytes    *int64   `bun:"memory_bytes"`
+	GPUUtilization *float64 `bun:"gpu_utilization"`
+	GPUMemoryBytes *int64   `bun:"gpu_memory_bytes"`
+	// GPUPowerWatts is the power drawn by the container's GPUs.
+	GPUPowerWatts *float64 `bun:"gpu_power_watts"`
+	// NetworkRxBytesPerSecond and NetworkTxBytesPerSecond are the rates of network traffic since
+	// the last measurement.
+	NetworkRxBytesPerSecond *float64 `bun:"network_rx_bytes_per_second"`
+	NetworkTxBytesPerSecond *float64 `bun:"network_tx_bytes_per_second"`
+}
+
+// AllocationSystemMetrics is the usage of the resources of a container of an allocation.
+type AllocationSystemMetrics struct {
+	bun.BaseModel `bun:"table:allocation_system_metrics"`
+
+	AllocationID AllocationID `bun:"allocation_id"`
+	ContainerID  string       `bun:"container_id"`
+	SystemMetrics
+}
+
+// Proto returns the protobuf representation of the metrics.
+func (m AllocationSystemMetrics) Proto() *taskv1.SystemMetrics {
+	return &taskv1.SystemMetrics{
+		AllocationId:            string(m.AllocationID),
+		ContainerId:             m.ContainerID,
+		Time:                    timestamppb.New(m.Time),
+		CpuCores:                m.CPUCores,
+		MemoryBytes:             m.MemoryBytes,
+		GpuUtilization:          m.GPUUtilization,
+		GpuMemoryBytes:          m.GPUMemoryBytes,
+		GpuPowerWatts:           m.GPUPowerWatts,
+		NetworkRxBytesPerSecond: m.NetworkRxBytesPerSecond,
+		NetworkTxBytesPerSecond: m.NetworkTxBytesPerSecond,
+	}
+}
+
 // ResourceAggregates is the model for resource_aggregates in the database.
 type ResourceAggregates struct {
 	Date            *time.Time
@@ -121,6 +172,23 @@ type ResourceAggregates struct {
 	Seconds         float32
 }
 
+// ResourcePoolStats is the model for resource_pool_stats in the database, a periodic snapshot of
+// the queue and utilization of a resource pool.
+type ResourcePoolStats struct {
+	bun.BaseModel `bun:"table:resource_pool_stats"`
+
+	ID           int       `bun:"id,pk,autoincrement"`
+	ResourcePool string    `bun:"resource_pool"`
+	Time         time.Time `bun:"time"`
+	QueueLength  int       `bun:"queue_length"`
+	QueuedSlots  int       `bun:"queued_slots"`
+	// MedianWaitSeconds is the median time spent queued by the allocations that started since the
+	// previous snapshot, or nil if none did.
+	MedianWaitSeconds *float64 `bun:"median_wait_seconds"`
+	SlotsTotal        int      `bun:"slots_total"`
+	SlotsUsed         int      `bun:"slots_used"`
+}
+
 const (
 	// AllocationStatePending state denotes that the command is awaiting allocation.
 	AllocationStatePending AllocationState = "PENDING"
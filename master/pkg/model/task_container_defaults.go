@@ -38,6 +38,10 @@ type TaskContainerDefaultsConfig struct {
 	WorkDir    *string          `json:"work_dir"`
 	Slurm      []string         `json:"slurm"`
 	Pbs        []string         `json:"pbs"`
+
+	// InitContainers and Sidecars are added to the Kubernetes pods of every task.
+	InitContainers []k8sV1.Container `json:"init_containers"`
+	Sidecars       []k8sV1.Container `json:"sidecars"`
 }
 
 // DefaultTaskContainerDefaults returns the default for TaskContainerDefaultsConfig.
@@ -77,6 +81,7 @@ func (c *TaskContainerDefaultsConfig) Validate() []error {
 
 	errs = append(errs, validatePodSpec(c.CPUPodSpec)...)
 	errs = append(errs, validatePodSpec(c.GPUPodSpec)...)
+	errs = append(errs, validateContainers(c.InitContainers, c.Sidecars)...)
 
 	return errs
 }
@@ -113,7 +118,7 @@ func (c *TaskContainerDefaultsConfig) MergeIntoExpConfig(config *expconf.Experim
 		podSpec = c.GPUPodSpec
 	}
 
-	//nolint:exhaustivestruct // RawPorts is not in TaskContainerDefaults.
+	//nolint:exhaustivestruct // RawPorts and RawProxyPorts are not in TaskContainerDefaults.
 	env := expconf.EnvironmentConfig{
 		RawAddCapabilities:      c.AddCapabilities,
 		RawDropCapabilities:     c.DropCapabilities,
@@ -122,6 +127,8 @@ func (c *TaskContainerDefaultsConfig) MergeIntoExpConfig(config *expconf.Experim
 		RawPodSpec:              (*expconf.PodSpec)(podSpec),
 		RawRegistryAuth:         c.RegistryAuth,
 		RawEnvironmentVariables: envVars,
+		RawInitContainers:       c.InitContainers,
+		RawSidecars:             c.Sidecars,
 	}
 	config.RawEnvironment = schemas.Merge(config.RawEnvironment, &env).(*expconf.EnvironmentConfig)
 
@@ -5,6 +5,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	k8sV1 "k8s.io/api/core/v1"
+
+	"github.com/determined-ai/determined/master/pkg/check"
 
 	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
 )
@@ -34,3 +37,46 @@ func TestEnvironmentVarsDefaultMerging(t *testing.T) {
 			RawROCM: []string{"rocm=default"},
 		})
 }
+
+func TestContainersDefaultMerging(t *testing.T) {
+	defaults := &TaskContainerDefaultsConfig{
+		InitContainers: []k8sV1.Container{{Name: "prefetch", Image: "prefetch:1"}},
+		Sidecars: []k8sV1.Container{
+			{Name: "logs", Image: "logs:1"},
+			{Name: "creds", Image: "creds:1"},
+		},
+	}
+	conf := expconf.ExperimentConfig{
+		RawEnvironment: &expconf.EnvironmentConfig{
+			RawSidecars: expconf.Containers{{Name: "creds", Image: "creds:2"}},
+		},
+	}
+	defaults.MergeIntoExpConfig(&conf)
+
+	require.Equal(t, expconf.Containers{{Name: "prefetch", Image: "prefetch:1"}},
+		conf.RawEnvironment.RawInitContainers)
+	require.Equal(t, expconf.Containers{
+		{Name: "logs", Image: "logs:1"},
+		{Name: "creds", Image: "creds:2"},
+	}, conf.RawEnvironment.RawSidecars)
+}
+
+func TestContainersValidation(t *testing.T) {
+	valid := &TaskContainerDefaultsConfig{
+		ShmSizeBytes:   1,
+		NetworkMode:    "bridge",
+		InitContainers: []k8sV1.Container{{Name: "prefetch", Image: "prefetch:1"}},
+		Sidecars:       []k8sV1.Container{{Name: "logs", Image: "logs:1"}},
+	}
+	require.NoError(t, check.Validate(valid))
+
+	for _, sidecar := range []k8sV1.Container{
+		{Name: "prefetch", Image: "logs:1"},
+		{Name: DeterminedK8FluentContainerName, Image: "logs:1"},
+		{Name: "logs"},
+	} {
+		invalid := *valid
+		invalid.Sidecars = []k8sV1.Container{sidecar}
+		require.Error(t, check.Validate(&invalid), sidecar.Name)
+	}
+}
@@ -31,6 +31,11 @@ type User struct {
 	Admin         bool        `db:"admin" json:"admin"`
 	Active        bool        `db:"active" json:"active"`
 	ModifiedAt    time.Time   `db:"modified_at" json:"modified_at"`
+	Remote        bool        `db:"remote" json:"remote"`
+	// ServiceAccount is set for users that authenticate only with access tokens, such as those of
+	// CI pipelines. OwnerID is the user responsible for a service account, if any.
+	ServiceAccount bool    `db:"service_account" json:"service_account"`
+	OwnerID        *UserID `db:"owner_id" json:"owner_id"`
 }
 
 // UserSession corresponds to a row in the "user_sessions" DB table.
@@ -39,6 +44,33 @@ type UserSession struct {
 	ID            SessionID `db:"id" json:"id"`
 	UserID        UserID    `db:"user_id" json:"user_id"`
 	Expiry        time.Time `db:"expiry" json:"expiry"`
+	// AccessTokenScope is set when the user authenticated with an access token rather than by
+	// logging in; such sessions have no ID.
+	AccessTokenScope AccessTokenScope `db:"-" bun:"-" json:"-"`
+	// The activity of the session is not part of the signed token.
+	CreatedAt  time.Time  `db:"created_at" json:"-"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"-"`
+	LastIP     string     `db:"last_ip" json:"-"`
+	// MFAEnrollmentOnly is set for sessions of users who must enroll in multi-factor
+	// authentication before they can do anything else.
+	MFAEnrollmentOnly bool `db:"mfa_enrollment_only" json:"-"`
+}
+
+// Proto returns the protobuf representation of the session, which is the one making the request
+// if current is set.
+func (s UserSession) Proto(current bool) *userv1.UserSession {
+	ps := &userv1.UserSession{
+		Id:        int32(s.ID),
+		UserId:    int32(s.UserID),
+		CreatedAt: timestamppb.New(s.CreatedAt),
+		ExpiresAt: timestamppb.New(s.Expiry),
+		LastIp:    s.LastIP,
+		Current:   current,
+	}
+	if s.LastUsedAt != nil {
+		ps.LastUsedAt = timestamppb.New(*s.LastUsedAt)
+	}
+	return ps
 }
 
 // A FullUser is a User joined with any other user relations.
@@ -49,6 +81,10 @@ type FullUser struct {
 	Admin       bool        `db:"admin" json:"admin"`
 	Active      bool        `db:"active" json:"active"`
 	ModifiedAt  time.Time   `db:"modified_at" json:"modified_at"`
+	Remote      bool        `db:"remote" json:"remote"`
+	// ServiceAccount and OwnerID are as on User.
+	ServiceAccount bool    `db:"service_account" json:"service_account"`
+	OwnerID        *UserID `db:"owner_id" json:"owner_id"`
 
 	AgentUID   null.Int    `db:"agent_uid" json:"agent_uid"`
 	AgentGID   null.Int    `db:"agent_gid" json:"agent_gid"`
@@ -59,13 +95,16 @@ type FullUser struct {
 // ToUser converts a FullUser model to just a User model.
 func (u FullUser) ToUser() User {
 	return User{
-		ID:           u.ID,
-		Username:     u.Username,
-		PasswordHash: null.String{},
-		DisplayName:  u.DisplayName,
-		Admin:        u.Admin,
-		Active:       u.Active,
-		ModifiedAt:   u.ModifiedAt,
+		ID:             u.ID,
+		Username:       u.Username,
+		PasswordHash:   null.String{},
+		DisplayName:    u.DisplayName,
+		Admin:          u.Admin,
+		Active:         u.Active,
+		ModifiedAt:     u.ModifiedAt,
+		Remote:         u.Remote,
+		ServiceAccount: u.ServiceAccount,
+		OwnerID:        u.OwnerID,
 	}
 }
 
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/proto/pkg/userv1"
+)
+
+// UserMFA is the model for user_mfa in the database. It holds the multi-factor authentication
+// settings of a user; only hashes of recovery codes are kept.
+type UserMFA struct {
+	bun.BaseModel `bun:"table:user_mfa"`
+
+	UserID UserID `bun:"user_id,pk"`
+	// Required is set when the user must enroll regardless of the cluster-wide setting.
+	Required bool   `bun:"required"`
+	Secret   string `bun:"secret,nullzero"`
+	// PendingSecret is the secret of an enrollment that has not been confirmed with a code yet.
+	PendingSecret      string     `bun:"pending_secret,nullzero"`
+	EnrolledAt         *time.Time `bun:"enrolled_at"`
+	RecoveryCodeHashes []string   `bun:"recovery_code_hashes,array"`
+	// LastUsedStep is the time step of the last code accepted, so that codes cannot be reused.
+	LastUsedStep int64 `bun:"last_used_step"`
+}
+
+// Enrolled returns whether the user has to give a code when they log in.
+func (m UserMFA) Enrolled() bool {
+	return m.EnrolledAt != nil
+}
+
+// Proto returns the protobuf representation of the settings, given whether the cluster requires
+// every user to enroll.
+func (m UserMFA) Proto(clusterRequired bool) *userv1.UserMFA {
+	pm := &userv1.UserMFA{
+		UserId:            int32(m.UserID),
+		Enrolled:          m.Enrolled(),
+		Required:          m.Required || clusterRequired,
+		RecoveryCodesLeft: int32(len(m.RecoveryCodeHashes)),
+	}
+	if m.EnrolledAt != nil {
+		pm.EnrolledAt = timestamppb.New(*m.EnrolledAt)
+	}
+	return pm
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// UserPasswordState is the model for user_password_states in the database. It tracks what the
+// password policies of the cluster are enforced against for users who log in with a password in
+// the cluster.
+type UserPasswordState struct {
+	bun.BaseModel `bun:"table:user_password_states"`
+
+	UserID            UserID    `bun:"user_id,pk"`
+	PasswordChangedAt time.Time `bun:"password_changed_at,nullzero,default:now()"`
+	// FailedLoginAttempts counts the failed logins since the last successful one or lockout.
+	FailedLoginAttempts int        `bun:"failed_login_attempts"`
+	LockedUntil         *time.Time `bun:"locked_until"`
+}
+
+// Locked returns whether the user is locked out of logging in at the given time.
+func (s UserPasswordState) Locked(now time.Time) bool {
+	return s.LockedUntil != nil && now.Before(*s.LockedUntil)
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/proto/pkg/userv1"
+)
+
+// UserSSHKey corresponds to a row in the "user_ssh_keys" DB table. It is an SSH public key a user
+// registered, which the master authorizes in their shells and, if asked to, their trials.
+type UserSSHKey struct {
+	bun.BaseModel `bun:"table:user_ssh_keys"`
+
+	ID     int    `bun:"id,pk,autoincrement"`
+	UserID UserID `bun:"user_id"`
+	Name   string `bun:"name"`
+	// PublicKey is the key in authorized_keys format, without a trailing newline.
+	PublicKey        string    `bun:"public_key"`
+	Fingerprint      string    `bun:"fingerprint"`
+	InjectIntoTrials bool      `bun:"inject_into_trials"`
+	CreatedAt        time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+// Proto returns the protobuf representation of the key.
+func (k UserSSHKey) Proto() *userv1.SSHKey {
+	return &userv1.SSHKey{
+		Id:               int32(k.ID),
+		UserId:           int32(k.UserID),
+		Name:             k.Name,
+		PublicKey:        k.PublicKey,
+		Fingerprint:      k.Fingerprint,
+		InjectIntoTrials: k.InjectIntoTrials,
+		CreatedAt:        timestamppb.New(k.CreatedAt),
+	}
+}
@@ -27,6 +27,7 @@ type Workspace struct {
 	AgentGID                *int32                           `bun:"gid"`
 	AgentGroup              *string                          `bun:"group_"`
 	CheckpointStorageConfig *expconf.CheckpointStorageConfig `bun:"checkpoint_storage_config"`
+	LogRetentionDays        *int32                           `bun:"log_retention_days"`
 }
 
 // ToProto converts a bun model of a workspace to a proto object.
@@ -65,6 +66,7 @@ func (w *Workspace) ToProto() (*workspacev1.Workspace, error) {
 		State:                   w.State.ToProto(),
 		AgentUserGroup:          aug,
 		CheckpointStorageConfig: storageConfig,
+		LogRetentionDays:        w.LogRetentionDays,
 	}, nil
 }
 
@@ -94,3 +96,96 @@ type WorkspacePin struct {
 	WorkspaceID   int    `bun:"workspace_id"`
 	UserID        UserID `bun:"user_id"`
 }
+
+// MemberRole is the role of a user in a workspace or project. Each role grants everything the
+// roles before it do; the empty role grants nothing.
+type MemberRole string
+
+const (
+	// MemberRoleViewer can view a workspace or project and the experiments in it.
+	MemberRoleViewer MemberRole = "VIEWER"
+	// MemberRoleEditor can also create and edit experiments, and create projects in a workspace.
+	MemberRoleEditor MemberRole = "EDITOR"
+	// MemberRoleAdmin can also change settings, delete others' experiments, and manage members.
+	MemberRoleAdmin MemberRole = "ADMIN"
+)
+
+var memberRoleRanks = map[MemberRole]int{
+	MemberRoleViewer: 1,
+	MemberRoleEditor: 2,
+	MemberRoleAdmin:  3,
+}
+
+// AtLeast returns whether the role grants everything the other one does.
+func (r MemberRole) AtLeast(other MemberRole) bool {
+	return memberRoleRanks[r] >= memberRoleRanks[other]
+}
+
+// Max returns whichever of the roles grants more.
+func (r MemberRole) Max(other MemberRole) MemberRole {
+	if r.AtLeast(other) {
+		return r
+	}
+	return other
+}
+
+// MemberRoleFromProto returns a MemberRole from its protobuf representation, or the empty
+// role if it is unspecified.
+func MemberRoleFromProto(r workspacev1.MemberRole) MemberRole {
+	switch r {
+	case workspacev1.MemberRole_MEMBER_ROLE_VIEWER:
+		return MemberRoleViewer
+	case workspacev1.MemberRole_MEMBER_ROLE_EDITOR:
+		return MemberRoleEditor
+	case workspacev1.MemberRole_MEMBER_ROLE_ADMIN:
+		return MemberRoleAdmin
+	default:
+		return ""
+	}
+}
+
+// Proto returns the protobuf representation of the role.
+func (r MemberRole) Proto() workspacev1.MemberRole {
+	switch r {
+	case MemberRoleViewer:
+		return workspacev1.MemberRole_MEMBER_ROLE_VIEWER
+	case MemberRoleEditor:
+		return workspacev1.MemberRole_MEMBER_ROLE_EDITOR
+	case MemberRoleAdmin:
+		return workspacev1.MemberRole_MEMBER_ROLE_ADMIN
+	default:
+		return workspacev1.MemberRole_MEMBER_ROLE_UNSPECIFIED
+	}
+}
+
+// Member is a user with a role in a workspace or project.
+type Member struct {
+	UserID   UserID     `bun:"user_id"`
+	Username string     `bun:"username,scanonly"`
+	Role     MemberRole `bun:"role"`
+}
+
+// Proto returns the protobuf representation of the member.
+func (m Member) Proto() *workspacev1.Member {
+	return &workspacev1.Member{
+		UserId:   int32(m.UserID),
+		Username: m.Username,
+		Role:     m.Role.Proto(),
+	}
+}
+
+// GroupMember is a group whose users have a role in a workspace.
+type GroupMember struct {
+	GroupID   int        `bun:"group_id"`
+	GroupName string     `bun:"group_name,scanonly"`
+	Role      MemberRole `bun:"role"`
+}
+
+// Proto returns the protobuf representation of the group member.
+func (m GroupMember) Proto() *workspacev1.GroupMember {
+	return &workspacev1.GroupMember{
+		GroupId:   int32(m.GroupID),
+		GroupName: m.GroupName,
+		Role:      m.Role.Proto(),
+	}
+}
@@ -40,6 +40,48 @@ func (p PodSpec) WithDefaults() interface{} {
 	return PodSpec(*pod.DeepCopy())
 }
 
+// Containers is a list of k8sV1.Container with custom methods, since k8sV1.Container is not
+// reflect-friendly either.
+type Containers []k8sV1.Container
+
+// Copy implements the schemas.Copyable interface.
+func (c Containers) Copy() interface{} {
+	if c == nil {
+		return c
+	}
+	out := make(Containers, 0, len(c))
+	for i := range c {
+		out = append(out, *c[i].DeepCopy())
+	}
+	return out
+}
+
+// Merge is merge-by-appending: the containers of other come first, except those replaced by a
+// container of the receiver with the same name, so that a task can both add to and override the
+// containers of its resource pool.
+func (c Containers) Merge(other interface{}) interface{} {
+	tOther := other.(Containers)
+	names := map[string]bool{}
+	for _, container := range c {
+		names[container.Name] = true
+	}
+	var out Containers
+	for i := range tOther {
+		if !names[tOther[i].Name] {
+			out = append(out, *tOther[i].DeepCopy())
+		}
+	}
+	for i := range c {
+		out = append(out, *c[i].DeepCopy())
+	}
+	return out
+}
+
+// WithDefaults implements the schemas.Defaultable interface.
+func (c Containers) WithDefaults() interface{} {
+	return c.Copy()
+}
+
 //go:generate ../gen.sh --import github.com/docker/docker/api/types
 // EnvironmentConfigV0 configures the environment of a Determined command or experiment.
 type EnvironmentConfigV0 struct {
@@ -47,12 +89,20 @@ type EnvironmentConfigV0 struct {
 	RawEnvironmentVariables *EnvironmentVariablesMapV0 `json:"environment_variables"`
 
 	RawPorts          map[string]int    `json:"ports"`
+	RawProxyPorts     map[string]int    `json:"proxy_ports"`
 	RawRegistryAuth   *types.AuthConfig `json:"registry_auth"`
 	RawForcePullImage *bool             `json:"force_pull_image"`
 	RawPodSpec        *PodSpec          `json:"pod_spec"`
 
 	RawAddCapabilities  []string `json:"add_capabilities"`
 	RawDropCapabilities []string `json:"drop_capabilities"`
+
+	// RawPodTemplateName names a pod template stored in the master to merge pod_spec over.
+	RawPodTemplateName *string `json:"pod_template_name"`
+
+	// RawInitContainers run in Kubernetes pods before the task, and RawSidecars alongside it.
+	RawInitContainers Containers `json:"init_containers"`
+	RawSidecars       Containers `json:"sidecars"`
 }
 
 //go:generate ../gen.sh
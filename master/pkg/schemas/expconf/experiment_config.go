@@ -17,6 +17,8 @@ import (
 //go:generate ../gen.sh
 // ExperimentConfigV0 is a versioned experiment config.
 type ExperimentConfigV0 struct {
+	RawAlerts                   AlertsConfigV0              `json:"alerts,omitempty"`
+	RawBestCheckpoint           *BestCheckpointConfigV0     `json:"best_checkpoint,omitempty"`
 	RawBindMounts               BindMountsConfigV0          `json:"bind_mounts"`
 	RawCheckpointPolicy         *string                     `json:"checkpoint_policy"`
 	RawCheckpointStorage        *CheckpointStorageConfigV0  `json:"checkpoint_storage"`
@@ -24,6 +26,7 @@ type ExperimentConfigV0 struct {
 	RawData                     map[string]interface{}      `json:"data"`
 	RawDebug                    *bool                       `json:"debug"`
 	RawDescription              *string                     `json:"description"`
+	RawEarlyStopping            *EarlyStoppingConfigV0      `json:"early_stopping,omitempty"`
 	RawEntrypoint               *EntrypointV0               `json:"entrypoint"`
 	RawEnvironment              *EnvironmentConfigV0        `json:"environment"`
 	RawHyperparameters          HyperparametersV0           `json:"hyperparameters"`
@@ -215,6 +218,137 @@ type ResourcesConfigV0 struct {
 	RawPriority       *int     `json:"priority"`
 
 	RawDevices DevicesConfigV0 `json:"devices"`
+
+	RawElastic *ElasticConfigV0 `json:"elastic,omitempty"`
+
+	// RawLabelConstraints restricts scheduling to agents (or nodes, on Kubernetes) with all of
+	// the given labels.
+	RawLabelConstraints map[string]string `json:"label_constraints,omitempty"`
+
+	// RawExpectedRuntimeSeconds is how long each of the tasks is expected to run, which lets the
+	// scheduler backfill them into slots held for a larger pending task.
+	RawExpectedRuntimeSeconds *int `json:"expected_runtime_seconds,omitempty"`
+}
+
+//go:generate ../gen.sh
+// ElasticConfigV0 configures the range of slots an elastic trial may be resized within.
+type ElasticConfigV0 struct {
+	RawMinSlotsPerTrial int `json:"min_slots_per_trial"`
+	RawMaxSlotsPerTrial int `json:"max_slots_per_trial"`
+}
+
+//go:generate ../gen.sh
+// BestCheckpointConfigV0 configures how the best checkpoint of an experiment is chosen. Unset
+// fields fall back to the searcher's metric.
+type BestCheckpointConfigV0 struct {
+	RawMetric          *string   `json:"metric"`
+	RawSmallerIsBetter *bool     `json:"smaller_is_better"`
+	RawSmoothingWindow *int      `json:"smoothing_window"`
+	RawMinLength       *LengthV0 `json:"min_length"`
+}
+
+// BestCheckpointPolicy is how the best checkpoint of an experiment is chosen: the completed
+// checkpoint whose validation has the best value of the metric, averaged over that validation and
+// the ones before it in its trial, up to the smoothing window, among checkpoints taken after at
+// least the minimum length of training.
+type BestCheckpointPolicy struct {
+	Metric          string
+	SmallerIsBetter bool
+	SmoothingWindow int
+	MinLength       *LengthV0
+}
+
+// BestCheckpointPolicy returns the policy for choosing the best checkpoint of the experiment.
+func (e ExperimentConfigV0) BestCheckpointPolicy() BestCheckpointPolicy {
+	p := BestCheckpointPolicy{
+		Metric:          e.Searcher().Metric(),
+		SmallerIsBetter: e.Searcher().SmallerIsBetter(),
+		SmoothingWindow: 1,
+	}
+	c := e.RawBestCheckpoint
+	if c == nil {
+		return p
+	}
+	if c.RawMetric != nil && *c.RawMetric != p.Metric {
+		// A different metric is not necessarily ordered like the searcher's.
+		p.Metric = *c.RawMetric
+		p.SmallerIsBetter = true
+	}
+	if c.RawSmallerIsBetter != nil {
+		p.SmallerIsBetter = *c.RawSmallerIsBetter
+	}
+	if c.RawSmoothingWindow != nil {
+		p.SmoothingWindow = *c.RawSmoothingWindow
+	}
+	p.MinLength = c.RawMinLength
+	return p
+}
+
+//go:generate ../gen.sh
+// AlertsConfigV0 is the configuration for alerts on the metrics of an experiment's trials.
+type AlertsConfigV0 []AlertV0
+
+//go:generate ../gen.sh
+// AlertV0 fires once for each trial whose metric in the group crosses a threshold, or is NaN or
+// infinite, after the trial has trained for at least the given number of batches.
+type AlertV0 struct {
+	RawName            string   `json:"name"`
+	RawMetric          string   `json:"metric"`
+	RawGroup           *string  `json:"group"`
+	RawAbove           *float64 `json:"above"`
+	RawBelow           *float64 `json:"below"`
+	RawNaN             *bool    `json:"nan"`
+	RawAfterBatches    *int     `json:"after_batches"`
+	RawPauseExperiment *bool    `json:"pause_experiment"`
+}
+
+//go:generate ../gen.sh
+// EarlyStoppingConfigV0 configures the rules the master uses to stop trials whose validation
+// metrics show they are not worth continuing, independently of the searcher.
+type EarlyStoppingConfigV0 struct {
+	RawMetric          *string  `json:"metric"`
+	RawSmallerIsBetter *bool    `json:"smaller_is_better"`
+	RawPatience        *int     `json:"patience"`
+	RawMinDelta        *float64 `json:"min_delta"`
+	RawStopOnNaN       *bool    `json:"stop_on_nan"`
+}
+
+// EarlyStoppingPolicy is when the master stops a trial: once the metric has not improved by more
+// than the minimum delta over the best value before the last patience validations, if patience is
+// set, or as soon as the metric is NaN or infinite, if StopOnNaN is set.
+type EarlyStoppingPolicy struct {
+	Metric          string
+	SmallerIsBetter bool
+	Patience        *int
+	MinDelta        float64
+	StopOnNaN       bool
+}
+
+// EarlyStoppingPolicy returns the early stopping rules of the experiment, or nil if there are none.
+func (e ExperimentConfigV0) EarlyStoppingPolicy() *EarlyStoppingPolicy {
+	c := e.RawEarlyStopping
+	if c == nil {
+		return nil
+	}
+	p := EarlyStoppingPolicy{
+		Metric:          e.Searcher().Metric(),
+		SmallerIsBetter: e.Searcher().SmallerIsBetter(),
+		Patience:        c.RawPatience,
+	}
+	if c.RawMetric != nil && *c.RawMetric != p.Metric {
+		p.Metric = *c.RawMetric
+		p.SmallerIsBetter = true
+	}
+	if c.RawSmallerIsBetter != nil {
+		p.SmallerIsBetter = *c.RawSmallerIsBetter
+	}
+	if c.RawMinDelta != nil {
+		p.MinDelta = *c.RawMinDelta
+	}
+	if c.RawStopOnNaN != nil {
+		p.StopOnNaN = *c.RawStopOnNaN
+	}
+	return &p
 }
 
 //go:generate ../gen.sh
@@ -341,6 +475,9 @@ func (d *DeviceV0) UnmarshalJSON(data []byte) error {
 // ReproducibilityConfigV0 configures parameters related to reproducibility.
 type ReproducibilityConfigV0 struct {
 	RawExperimentSeed *uint32 `json:"experiment_seed"`
+	// RawTrialSeed, if set, is the seed of every trial rather than one derived from the experiment
+	// seed, so that a trial can be replayed with the seed it was first run with.
+	RawTrialSeed *uint32 `json:"trial_seed"`
 }
 
 // WithDefaults implements the Defaultable interface.
@@ -351,7 +488,7 @@ func (r ReproducibilityConfigV0) WithDefaults() interface{} {
 	} else {
 		seed = uint32(time.Now().Unix())
 	}
-	return ReproducibilityConfigV0{&seed}
+	return ReproducibilityConfigV0{RawExperimentSeed: &seed, RawTrialSeed: r.RawTrialSeed}
 }
 
 //go:generate ../gen.sh
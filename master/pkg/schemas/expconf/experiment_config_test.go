@@ -59,3 +59,104 @@ func TestName(t *testing.T) {
 
 	assert.DeepEqual(t, newConfig.Name().String(), "my_name")
 }
+
+func TestConstHPs(t *testing.T) {
+	var h HyperparametersV0
+	assert.NilError(t, json.Unmarshal([]byte(`{
+		"lr": {"type": "double", "minval": 0.001, "maxval": 0.1},
+		"optimizer": {"momentum": {"type": "double", "minval": 0.1, "maxval": 0.9}},
+		"layers": {"type": "const", "val": {"a": 1}}
+	}`), &h))
+
+	consts := ConstHPs(h, map[string]interface{}{
+		"lr":        0.01,
+		"optimizer": map[string]interface{}{"momentum": 0.5},
+		"layers":    map[string]interface{}{"a": 2},
+		"extra":     "x",
+	})
+	bytes, err := json.Marshal(consts)
+	assert.NilError(t, err)
+	var actual interface{}
+	assert.NilError(t, json.Unmarshal(bytes, &actual))
+	var expected interface{}
+	assert.NilError(t, json.Unmarshal([]byte(`{
+		"lr": {"type": "const", "val": 0.01},
+		"optimizer": {"momentum": {"type": "const", "val": 0.5}},
+		"layers": {"type": "const", "val": {"a": 2}},
+		"extra": {"type": "const", "val": "x"}
+	}`), &expected))
+	assert.DeepEqual(t, actual, expected)
+	assert.Equal(t, len(FlattenHPs(consts)), 4)
+}
+
+func TestSearcherMaxLength(t *testing.T) {
+	length := LengthV0{Unit: Batches, Units: 100}
+	single := SearcherConfigV0{RawSingleConfig: &SingleConfigV0{RawMaxLength: &length}}
+	assert.DeepEqual(t, single.MaxLength(), &length)
+	custom := SearcherConfigV0{RawCustomConfig: &CustomConfigV0{}}
+	assert.Assert(t, custom.MaxLength() == nil)
+}
+
+func TestBestCheckpointPolicy(t *testing.T) {
+	config := ExperimentConfig{
+		RawSearcher: &SearcherConfig{
+			RawMetric:          ptrs.Ptr("accuracy"),
+			RawSmallerIsBetter: ptrs.Ptr(false),
+		},
+	}
+	assert.DeepEqual(t, config.BestCheckpointPolicy(), BestCheckpointPolicy{
+		Metric:          "accuracy",
+		SmallerIsBetter: false,
+		SmoothingWindow: 1,
+	})
+
+	// A different metric does not inherit the ordering of the searcher's.
+	config.RawBestCheckpoint = &BestCheckpointConfig{RawMetric: ptrs.Ptr("loss")}
+	assert.DeepEqual(t, config.BestCheckpointPolicy(), BestCheckpointPolicy{
+		Metric:          "loss",
+		SmallerIsBetter: true,
+		SmoothingWindow: 1,
+	})
+
+	minLength := LengthV0{Unit: Epochs, Units: 2}
+	config.RawBestCheckpoint = &BestCheckpointConfig{
+		RawSmallerIsBetter: ptrs.Ptr(true),
+		RawSmoothingWindow: ptrs.Ptr(3),
+		RawMinLength:       &minLength,
+	}
+	assert.DeepEqual(t, config.BestCheckpointPolicy(), BestCheckpointPolicy{
+		Metric:          "accuracy",
+		SmallerIsBetter: true,
+		SmoothingWindow: 3,
+		MinLength:       &minLength,
+	})
+}
+
+func TestEarlyStoppingPolicy(t *testing.T) {
+	config := ExperimentConfig{
+		RawSearcher: &SearcherConfig{
+			RawMetric:          ptrs.Ptr("accuracy"),
+			RawSmallerIsBetter: ptrs.Ptr(false),
+		},
+	}
+	assert.Assert(t, config.EarlyStoppingPolicy() == nil)
+
+	config.RawEarlyStopping = &EarlyStoppingConfig{RawPatience: ptrs.Ptr(3)}
+	assert.DeepEqual(t, config.EarlyStoppingPolicy(), &EarlyStoppingPolicy{
+		Metric:          "accuracy",
+		SmallerIsBetter: false,
+		Patience:        ptrs.Ptr(3),
+	})
+
+	config.RawEarlyStopping = &EarlyStoppingConfig{
+		RawMetric:    ptrs.Ptr("loss"),
+		RawMinDelta:  ptrs.Ptr(0.1),
+		RawStopOnNaN: ptrs.Ptr(true),
+	}
+	assert.DeepEqual(t, config.EarlyStoppingPolicy(), &EarlyStoppingPolicy{
+		Metric:          "loss",
+		SmallerIsBetter: true,
+		MinDelta:        0.1,
+		StopOnNaN:       true,
+	})
+}
@@ -36,6 +36,26 @@ func flattenNestedHP(h HyperparameterV0, prefix string, target *HyperparametersV
 	}
 }
 
+// ConstHPs returns hyperparameters that are fixed to the given values, such as those of a trial.
+// Values of hyperparameters that are nested in h stay nested, and values without a
+// hyperparameter in h become constants.
+func ConstHPs(h HyperparametersV0, values map[string]interface{}) HyperparametersV0 {
+	consts := make(HyperparametersV0)
+	for key, val := range values {
+		nested, isMap := val.(map[string]interface{})
+		if hp, ok := h[key]; ok && hp.RawNestedHyperparameter != nil && isMap {
+			n := map[string]HyperparameterV0(
+				ConstHPs(HyperparametersV0(*hp.RawNestedHyperparameter), nested))
+			consts[key] = HyperparameterV0{RawNestedHyperparameter: &n}
+		} else {
+			consts[key] = HyperparameterV0{RawConstHyperparameter: &ConstHyperparameterV0{
+				RawVal: val,
+			}}
+		}
+	}
+	return consts
+}
+
 //go:generate ../gen.sh
 // HyperparameterV0 is a sum type for hyperparameters.
 type HyperparameterV0 struct {
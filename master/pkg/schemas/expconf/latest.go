@@ -4,8 +4,13 @@ package expconf
 
 type (
 	AdaptiveASHAConfig        = AdaptiveASHAConfigV0
+	AdaptiveBracket           = AdaptiveBracketV0
+	AdaptiveBracketsConfig    = AdaptiveBracketsConfigV0
+	Alert                     = AlertV0
+	AlertsConfig              = AlertsConfigV0
 	AsyncHalvingConfig        = AsyncHalvingConfigV0
 	AzureConfig               = AzureConfigV0
+	BestCheckpointConfig      = BestCheckpointConfigV0
 	BindMount                 = BindMountV0
 	BindMountsConfig          = BindMountsConfigV0
 	CategoricalHyperparameter = CategoricalHyperparameterV0
@@ -16,6 +21,8 @@ type (
 	DevicesConfig             = DevicesConfigV0
 	Device                    = DeviceV0
 	DoubleHyperparameter      = DoubleHyperparameterV0
+	EarlyStoppingConfig       = EarlyStoppingConfigV0
+	ElasticConfig             = ElasticConfigV0
 	Entrypoint                = EntrypointV0
 	EnvironmentConfig         = EnvironmentConfigV0
 	EnvironmentImageMap       = EnvironmentImageMapV0
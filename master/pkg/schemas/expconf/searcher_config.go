@@ -24,10 +24,12 @@ type SearcherConfigV0 struct {
 	RawAdaptiveConfig       *AdaptiveConfigV0       `union:"name,adaptive" json:"-"`
 	RawAdaptiveSimpleConfig *AdaptiveSimpleConfigV0 `union:"name,adaptive_simple" json:"-"`
 
-	RawMetric               *string `json:"metric"`
-	RawSmallerIsBetter      *bool   `json:"smaller_is_better"`
-	RawSourceTrialID        *int    `json:"source_trial_id"`
-	RawSourceCheckpointUUID *string `json:"source_checkpoint_uuid"`
+	RawMetric                *string `json:"metric"`
+	RawSmallerIsBetter       *bool   `json:"smaller_is_better"`
+	RawWarmStartExperimentID *int    `json:"warm_start_experiment_id"`
+	RawWarmStartTrials       *int    `json:"warm_start_trials"`
+	RawSourceTrialID         *int    `json:"source_trial_id"`
+	RawSourceCheckpointUUID  *string `json:"source_checkpoint_uuid"`
 }
 
 // Merge implements schemas.Mergeable.
@@ -75,6 +77,25 @@ func (s SearcherConfigV0) Unit() Unit {
 	}
 }
 
+// MaxLength returns how long the searcher trains each trial for at most, or nil for searchers that
+// do not configure it, such as custom searchers.
+func (s SearcherConfigV0) MaxLength() *LengthV0 {
+	switch {
+	case s.RawSingleConfig != nil:
+		return s.RawSingleConfig.RawMaxLength
+	case s.RawRandomConfig != nil:
+		return s.RawRandomConfig.RawMaxLength
+	case s.RawGridConfig != nil:
+		return s.RawGridConfig.RawMaxLength
+	case s.RawAsyncHalvingConfig != nil:
+		return s.RawAsyncHalvingConfig.RawMaxLength
+	case s.RawAdaptiveASHAConfig != nil:
+		return s.RawAdaptiveASHAConfig.RawMaxLength
+	default:
+		return nil
+	}
+}
+
 //go:generate ../gen.sh
 // CustomConfigV0 configures a custom search.
 type CustomConfigV0 struct {
@@ -164,6 +185,8 @@ type AdaptiveASHAConfigV0 struct {
 	RawMaxRungs            *int          `json:"max_rungs"`
 	RawMaxConcurrentTrials *int          `json:"max_concurrent_trials"`
 	RawStopOnce            *bool         `json:"stop_once"`
+
+	RawBrackets AdaptiveBracketsConfigV0 `json:"brackets,omitempty"`
 }
 
 // Unit implements the model.InUnits interface.
@@ -171,6 +194,31 @@ func (a AdaptiveASHAConfigV0) Unit() Unit {
 	return a.RawMaxLength.Unit
 }
 
+//go:generate ../gen.sh
+// AdaptiveBracketsConfigV0 is the configuration for explicit brackets of an adaptive searcher,
+// which replace those derived from the mode, bracket_rungs, and max_rungs.
+type AdaptiveBracketsConfigV0 []AdaptiveBracketV0
+
+//go:generate ../gen.sh
+// AdaptiveBracketV0 is a bracket of an adaptive searcher that promotes trials through rungs of
+// the given lengths, in units of the searcher's max_length.
+type AdaptiveBracketV0 struct {
+	RawRungLengths         []int `json:"rung_lengths"`
+	RawMaxTrials           *int  `json:"max_trials"`
+	RawMaxConcurrentTrials *int  `json:"max_concurrent_trials"`
+}
+
+// Validate implements the check.Validatable interface.
+func (b AdaptiveBracketV0) Validate() []error {
+	for i := 1; i < len(b.RawRungLengths); i++ {
+		if b.RawRungLengths[i] <= b.RawRungLengths[i-1] {
+			return []error{errors.Errorf(
+				"'rung_lengths' must be strictly increasing, got %v", b.RawRungLengths)}
+		}
+	}
+	return nil
+}
+
 //go:generate ../gen.sh
 // SyncHalvingConfigV0 is a legacy config.
 type SyncHalvingConfigV0 struct {
@@ -93,6 +93,14 @@ func (a *AdaptiveASHAConfigV0) SetStopOnce(val bool) {
 	a.RawStopOnce = &val
 }
 
+func (a AdaptiveASHAConfigV0) Brackets() AdaptiveBracketsConfigV0 {
+	return a.RawBrackets
+}
+
+func (a *AdaptiveASHAConfigV0) SetBrackets(val AdaptiveBracketsConfigV0) {
+	a.RawBrackets = val
+}
+
 func (a AdaptiveASHAConfigV0) ParsedSchema() interface{} {
 	return schemas.ParsedAdaptiveASHAConfigV0()
 }
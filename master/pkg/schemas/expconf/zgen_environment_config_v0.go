@@ -39,6 +39,14 @@ func (e *EnvironmentConfigV0) SetPorts(val map[string]int) {
 	e.RawPorts = val
 }
 
+func (e EnvironmentConfigV0) ProxyPorts() map[string]int {
+	return e.RawProxyPorts
+}
+
+func (e *EnvironmentConfigV0) SetProxyPorts(val map[string]int) {
+	e.RawProxyPorts = val
+}
+
 func (e EnvironmentConfigV0) RegistryAuth() *types.AuthConfig {
 	return e.RawRegistryAuth
 }
@@ -82,6 +90,30 @@ func (e *EnvironmentConfigV0) SetDropCapabilities(val []string) {
 	e.RawDropCapabilities = val
 }
 
+func (e EnvironmentConfigV0) PodTemplateName() *string {
+	return e.RawPodTemplateName
+}
+
+func (e *EnvironmentConfigV0) SetPodTemplateName(val *string) {
+	e.RawPodTemplateName = val
+}
+
+func (e EnvironmentConfigV0) InitContainers() Containers {
+	return e.RawInitContainers
+}
+
+func (e *EnvironmentConfigV0) SetInitContainers(val Containers) {
+	e.RawInitContainers = val
+}
+
+func (e EnvironmentConfigV0) Sidecars() Containers {
+	return e.RawSidecars
+}
+
+func (e *EnvironmentConfigV0) SetSidecars(val Containers) {
+	e.RawSidecars = val
+}
+
 func (e EnvironmentConfigV0) ParsedSchema() interface{} {
 	return schemas.ParsedEnvironmentConfigV0()
 }
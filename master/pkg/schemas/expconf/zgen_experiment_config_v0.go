@@ -8,6 +8,22 @@ import (
 	"github.com/determined-ai/determined/master/pkg/schemas"
 )
 
+func (e ExperimentConfigV0) Alerts() AlertsConfigV0 {
+	return e.RawAlerts
+}
+
+func (e *ExperimentConfigV0) SetAlerts(val AlertsConfigV0) {
+	e.RawAlerts = val
+}
+
+func (e ExperimentConfigV0) BestCheckpoint() *BestCheckpointConfigV0 {
+	return e.RawBestCheckpoint
+}
+
+func (e *ExperimentConfigV0) SetBestCheckpoint(val *BestCheckpointConfigV0) {
+	e.RawBestCheckpoint = val
+}
+
 func (e ExperimentConfigV0) BindMounts() BindMountsConfigV0 {
 	return e.RawBindMounts
 }
@@ -76,6 +92,14 @@ func (e *ExperimentConfigV0) SetDescription(val *string) {
 	e.RawDescription = val
 }
 
+func (e ExperimentConfigV0) EarlyStopping() *EarlyStoppingConfigV0 {
+	return e.RawEarlyStopping
+}
+
+func (e *ExperimentConfigV0) SetEarlyStopping(val *EarlyStoppingConfigV0) {
+	e.RawEarlyStopping = val
+}
+
 func (e ExperimentConfigV0) Entrypoint() EntrypointV0 {
 	if e.RawEntrypoint == nil {
 		panic("You must call WithDefaults on ExperimentConfigV0 before .Entrypoint")
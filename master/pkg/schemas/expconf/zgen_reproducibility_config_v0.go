@@ -19,6 +19,14 @@ func (r *ReproducibilityConfigV0) SetExperimentSeed(val uint32) {
 	r.RawExperimentSeed = &val
 }
 
+func (r ReproducibilityConfigV0) TrialSeed() *uint32 {
+	return r.RawTrialSeed
+}
+
+func (r *ReproducibilityConfigV0) SetTrialSeed(val *uint32) {
+	r.RawTrialSeed = val
+}
+
 func (r ReproducibilityConfigV0) ParsedSchema() interface{} {
 	return schemas.ParsedReproducibilityConfigV0()
 }
@@ -103,6 +103,30 @@ func (r *ResourcesConfigV0) SetDevices(val DevicesConfigV0) {
 	r.RawDevices = val
 }
 
+func (r ResourcesConfigV0) Elastic() *ElasticConfigV0 {
+	return r.RawElastic
+}
+
+func (r *ResourcesConfigV0) SetElastic(val *ElasticConfigV0) {
+	r.RawElastic = val
+}
+
+func (r ResourcesConfigV0) LabelConstraints() map[string]string {
+	return r.RawLabelConstraints
+}
+
+func (r *ResourcesConfigV0) SetLabelConstraints(val map[string]string) {
+	r.RawLabelConstraints = val
+}
+
+func (r ResourcesConfigV0) ExpectedRuntimeSeconds() *int {
+	return r.RawExpectedRuntimeSeconds
+}
+
+func (r *ResourcesConfigV0) SetExpectedRuntimeSeconds(val *int) {
+	r.RawExpectedRuntimeSeconds = val
+}
+
 func (r ResourcesConfigV0) ParsedSchema() interface{} {
 	return schemas.ParsedResourcesConfigV0()
 }
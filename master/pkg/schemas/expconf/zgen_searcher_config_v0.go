@@ -30,6 +30,22 @@ func (s *SearcherConfigV0) SetSmallerIsBetter(val bool) {
 	s.RawSmallerIsBetter = &val
 }
 
+func (s SearcherConfigV0) WarmStartExperimentID() *int {
+	return s.RawWarmStartExperimentID
+}
+
+func (s *SearcherConfigV0) SetWarmStartExperimentID(val *int) {
+	s.RawWarmStartExperimentID = val
+}
+
+func (s SearcherConfigV0) WarmStartTrials() *int {
+	return s.RawWarmStartTrials
+}
+
+func (s *SearcherConfigV0) SetWarmStartTrials(val *int) {
+	s.RawWarmStartTrials = val
+}
+
 func (s SearcherConfigV0) SourceTrialID() *int {
 	return s.RawSourceTrialID
 }
@@ -8,6 +8,89 @@ import (
 )
 
 var (
+	textAlertV0 = []byte(`{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "$id": "http://determined.ai/schemas/expconf/v0/alert.json",
+    "title": "Alert",
+    "additionalProperties": false,
+    "required": [
+        "name",
+        "metric"
+    ],
+    "type": "object",
+    "properties": {
+        "name": {
+            "type": "string",
+            "checks": {
+                "name must not be empty": {
+                    "minLength": 1
+                }
+            }
+        },
+        "metric": {
+            "type": "string",
+            "checks": {
+                "metric must not be empty": {
+                    "minLength": 1
+                }
+            }
+        },
+        "group": {
+            "type": [
+                "string",
+                "null"
+            ],
+            "default": "validation"
+        },
+        "above": {
+            "type": [
+                "number",
+                "null"
+            ],
+            "default": null
+        },
+        "below": {
+            "type": [
+                "number",
+                "null"
+            ],
+            "default": null
+        },
+        "nan": {
+            "type": [
+                "boolean",
+                "null"
+            ],
+            "default": false
+        },
+        "after_batches": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": 0,
+            "minimum": 0
+        },
+        "pause_experiment": {
+            "type": [
+                "boolean",
+                "null"
+            ],
+            "default": false
+        }
+    }
+}
+`)
+	textAlertsConfigV0 = []byte(`{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "$id": "http://determined.ai/schemas/expconf/v0/alerts.json",
+    "title": "AlertsConfig",
+    "type": "array",
+    "items": {
+        "$ref": "http://determined.ai/schemas/expconf/v0/alert.json"
+    }
+}
+`)
 	textAzureConfigV0 = []byte(`{
     "$schema": "http://json-schema.org/draft-07/schema#",
     "$id": "http://determined.ai/schemas/expconf/v0/azure.json",
@@ -114,6 +197,47 @@ var (
         }
     }
 }
+`)
+	textBestCheckpointConfigV0 = []byte(`{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "$id": "http://determined.ai/schemas/expconf/v0/best-checkpoint.json",
+    "title": "BestCheckpointConfig",
+    "type": "object",
+    "additionalProperties": false,
+    "required": [],
+    "properties": {
+        "metric": {
+            "type": [
+                "string",
+                "null"
+            ],
+            "default": null
+        },
+        "smaller_is_better": {
+            "type": [
+                "boolean",
+                "null"
+            ],
+            "default": null
+        },
+        "smoothing_window": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": 1,
+            "minimum": 1
+        },
+        "min_length": {
+            "type": [
+                "object",
+                "null"
+            ],
+            "default": null,
+            "optionalRef": "http://determined.ai/schemas/expconf/v0/length.json"
+        }
+    }
+}
 `)
 	textBindMountV0 = []byte(`{
     "$schema": "http://json-schema.org/draft-07/schema#",
@@ -670,6 +794,81 @@ var (
         }
     }
 }
+`)
+	textEarlyStoppingConfigV0 = []byte(`{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "$id": "http://determined.ai/schemas/expconf/v0/early-stopping.json",
+    "title": "EarlyStoppingConfig",
+    "type": "object",
+    "additionalProperties": false,
+    "required": [],
+    "properties": {
+        "metric": {
+            "type": [
+                "string",
+                "null"
+            ],
+            "default": null
+        },
+        "smaller_is_better": {
+            "type": [
+                "boolean",
+                "null"
+            ],
+            "default": null
+        },
+        "patience": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
+        "min_delta": {
+            "type": [
+                "number",
+                "null"
+            ],
+            "default": 0,
+            "minimum": 0
+        },
+        "stop_on_nan": {
+            "type": [
+                "boolean",
+                "null"
+            ],
+            "default": false
+        }
+    }
+}
+`)
+	textElasticConfigV0 = []byte(`{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "$id": "http://determined.ai/schemas/expconf/v0/elastic.json",
+    "title": "ElasticConfig",
+    "type": "object",
+    "additionalProperties": false,
+    "required": [
+        "min_slots_per_trial",
+        "max_slots_per_trial"
+    ],
+    "properties": {
+        "min_slots_per_trial": {
+            "type": "integer",
+            "minimum": 1
+        },
+        "max_slots_per_trial": {
+            "type": "integer",
+            "minimum": 1
+        }
+    },
+    "compareProperties": {
+        "type": "a<=b",
+        "a": "min_slots_per_trial",
+        "b": "max_slots_per_trial"
+    }
+}
 `)
 	textEnvironmentImageMapV0 = []byte(`{
     "$schema": "http://json-schema.org/draft-07/schema#",
@@ -845,6 +1044,21 @@ var (
                 "type": "integer"
             }
         },
+        "proxy_ports": {
+            "type": [
+                "object",
+                "null"
+            ],
+            "default": {},
+            "additionalProperties": {
+                "type": "integer",
+                "minimum": 1,
+                "maximum": 65535
+            },
+            "propertyNames": {
+                "pattern": "^[a-z0-9]([a-z0-9-]*[a-z0-9])?$"
+            }
+        },
         "force_pull_image": {
             "type": [
                 "boolean",
@@ -926,6 +1140,72 @@ var (
                     }
                 }
             }
+        },
+        "pod_template_name": {
+            "type": [
+                "string",
+                "null"
+            ],
+            "default": null,
+            "minLength": 1
+        },
+        "init_containers": {
+            "type": [
+                "array",
+                "null"
+            ],
+            "default": null,
+            "items": {
+                "type": "object",
+                "required": [
+                    "name",
+                    "image"
+                ],
+                "properties": {
+                    "name": {
+                        "type": "string",
+                        "not": {
+                            "enum": [
+                                "determined-container",
+                                "determined-fluent-container",
+                                "determined-init-container"
+                            ]
+                        }
+                    },
+                    "image": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "sidecars": {
+            "type": [
+                "array",
+                "null"
+            ],
+            "default": null,
+            "items": {
+                "type": "object",
+                "required": [
+                    "name",
+                    "image"
+                ],
+                "properties": {
+                    "name": {
+                        "type": "string",
+                        "not": {
+                            "enum": [
+                                "determined-container",
+                                "determined-fluent-container",
+                                "determined-init-container"
+                            ]
+                        }
+                    },
+                    "image": {
+                        "type": "string"
+                    }
+                }
+            }
         }
     }
 }
@@ -945,6 +1225,14 @@ var (
         "searcher"
     ],
     "properties": {
+        "alerts": {
+            "type": [
+                "array",
+                "null"
+            ],
+            "default": null,
+            "optionalRef": "http://determined.ai/schemas/expconf/v0/alerts.json"
+        },
         "bind_mounts": {
             "type": [
                 "array",
@@ -953,6 +1241,14 @@ var (
             "default": [],
             "optionalRef": "http://determined.ai/schemas/expconf/v0/bind-mounts.json"
         },
+        "best_checkpoint": {
+            "type": [
+                "object",
+                "null"
+            ],
+            "default": null,
+            "optionalRef": "http://determined.ai/schemas/expconf/v0/best-checkpoint.json"
+        },
         "checkpoint_policy": {
             "enum": [
                 null,
@@ -1001,6 +1297,14 @@ var (
             ],
             "default": null
         },
+        "early_stopping": {
+            "type": [
+                "object",
+                "null"
+            ],
+            "default": null,
+            "optionalRef": "http://determined.ai/schemas/expconf/v0/early-stopping.json"
+        },
         "entrypoint": {
             "type": [
                 "string",
@@ -1222,6 +1526,9 @@ var (
             },
             "then": {
                 "properties": {
+                    "best_checkpoint": {
+                        "$ref": "http://determined.ai/schemas/expconf/v0/check-epoch-not-used.json"
+                    },
                     "min_validation_period": {
                         "$ref": "http://determined.ai/schemas/expconf/v0/check-epoch-not-used.json"
                     },
@@ -1946,6 +2253,15 @@ var (
             ],
             "default": null,
             "minimum": 0
+        },
+        "trial_seed": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 0,
+            "maximum": 2147483647
         }
     }
 }
@@ -1973,6 +2289,32 @@ var (
             "default": [],
             "optionalRef": "http://determined.ai/schemas/expconf/v0/devices.json"
         },
+        "elastic": {
+            "type": [
+                "object",
+                "null"
+            ],
+            "default": null,
+            "optionalRef": "http://determined.ai/schemas/expconf/v0/elastic.json"
+        },
+        "expected_runtime_seconds": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "minimum": 1,
+            "default": null
+        },
+        "label_constraints": {
+            "type": [
+                "object",
+                "null"
+            ],
+            "default": null,
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
         "max_slots": {
             "type": [
                 "integer",
@@ -2161,6 +2503,14 @@ var (
         "name": {
             "const": "adaptive_asha"
         },
+        "brackets": {
+            "type": [
+                "array",
+                "null"
+            ],
+            "default": null,
+            "optionalRef": "http://determined.ai/schemas/expconf/v0/searcher-adaptive-brackets.json"
+        },
         "bracket_rungs": {
             "type": [
                 "array",
@@ -2242,6 +2592,21 @@ var (
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -2258,6 +2623,57 @@ var (
         }
     }
 }
+`)
+	textAdaptiveBracketV0 = []byte(`{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "$id": "http://determined.ai/schemas/expconf/v0/searcher-adaptive-bracket.json",
+    "title": "AdaptiveBracket",
+    "additionalProperties": false,
+    "required": [
+        "rung_lengths"
+    ],
+    "type": "object",
+    "properties": {
+        "rung_lengths": {
+            "type": "array",
+            "items": {
+                "type": "integer",
+                "minimum": 1
+            },
+            "checks": {
+                "rung_lengths must not be empty": {
+                    "minItems": 1
+                }
+            }
+        },
+        "max_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
+        "max_concurrent_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        }
+    }
+}
+`)
+	textAdaptiveBracketsConfigV0 = []byte(`{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "$id": "http://determined.ai/schemas/expconf/v0/searcher-adaptive-brackets.json",
+    "title": "AdaptiveBracketsConfig",
+    "type": "array",
+    "items": {
+        "$ref": "http://determined.ai/schemas/expconf/v0/searcher-adaptive-bracket.json"
+    }
+}
 `)
 	textAdaptiveSimpleConfigV0 = []byte(`{
     "$schema": "http://json-schema.org/draft-07/schema#",
@@ -2334,6 +2750,21 @@ var (
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -2442,6 +2873,21 @@ var (
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -2539,6 +2985,21 @@ var (
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -2646,6 +3107,21 @@ var (
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -2740,6 +3216,21 @@ var (
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -2797,6 +3288,21 @@ var (
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -2887,6 +3393,21 @@ var (
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -2967,6 +3488,7 @@ var (
     ],
     "properties": {
         "bracket_rungs": true,
+        "brackets": true,
         "divisor": true,
         "max_concurrent_trials": true,
         "max_length": true,
@@ -2990,6 +3512,21 @@ var (
             ],
             "default": true
         },
+        "warm_start_experiment_id": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null
+        },
+        "warm_start_trials": {
+            "type": [
+                "integer",
+                "null"
+            ],
+            "default": null,
+            "minimum": 1
+        },
         "source_trial_id": {
             "type": [
                 "integer",
@@ -3306,8 +3843,14 @@ var (
     }
 }
 `)
+	schemaAlertV0 interface{}
+
+	schemaAlertsConfigV0 interface{}
+
 	schemaAzureConfigV0 interface{}
 
+	schemaBestCheckpointConfigV0 interface{}
+
 	schemaBindMountV0 interface{}
 
 	schemaBindMountsConfigV0 interface{}
@@ -3334,6 +3877,10 @@ var (
 
 	schemaDevicesConfigV0 interface{}
 
+	schemaEarlyStoppingConfigV0 interface{}
+
+	schemaElasticConfigV0 interface{}
+
 	schemaEnvironmentImageMapV0 interface{}
 
 	schemaEnvironmentImageV0 interface{}
@@ -3386,6 +3933,10 @@ var (
 
 	schemaAdaptiveASHAConfigV0 interface{}
 
+	schemaAdaptiveBracketV0 interface{}
+
+	schemaAdaptiveBracketsConfigV0 interface{}
+
 	schemaAdaptiveSimpleConfigV0 interface{}
 
 	schemaAdaptiveConfigV0 interface{}
@@ -3429,6 +3980,46 @@ var (
 	cachedSchemaBytesMap map[string][]byte
 )
 
+func ParsedAlertV0() interface{} {
+	cacheLock.RLock()
+	if schemaAlertV0 != nil {
+		cacheLock.RUnlock()
+		return schemaAlertV0
+	}
+	cacheLock.RUnlock()
+
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	if schemaAlertV0 != nil {
+		return schemaAlertV0
+	}
+	err := json.Unmarshal(textAlertV0, &schemaAlertV0)
+	if err != nil {
+		panic("invalid embedded json for AlertV0")
+	}
+	return schemaAlertV0
+}
+
+func ParsedAlertsConfigV0() interface{} {
+	cacheLock.RLock()
+	if schemaAlertsConfigV0 != nil {
+		cacheLock.RUnlock()
+		return schemaAlertsConfigV0
+	}
+	cacheLock.RUnlock()
+
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	if schemaAlertsConfigV0 != nil {
+		return schemaAlertsConfigV0
+	}
+	err := json.Unmarshal(textAlertsConfigV0, &schemaAlertsConfigV0)
+	if err != nil {
+		panic("invalid embedded json for AlertsConfigV0")
+	}
+	return schemaAlertsConfigV0
+}
+
 func ParsedAzureConfigV0() interface{} {
 	cacheLock.RLock()
 	if schemaAzureConfigV0 != nil {
@@ -3449,6 +4040,26 @@ func ParsedAzureConfigV0() interface{} {
 	return schemaAzureConfigV0
 }
 
+func ParsedBestCheckpointConfigV0() interface{} {
+	cacheLock.RLock()
+	if schemaBestCheckpointConfigV0 != nil {
+		cacheLock.RUnlock()
+		return schemaBestCheckpointConfigV0
+	}
+	cacheLock.RUnlock()
+
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	if schemaBestCheckpointConfigV0 != nil {
+		return schemaBestCheckpointConfigV0
+	}
+	err := json.Unmarshal(textBestCheckpointConfigV0, &schemaBestCheckpointConfigV0)
+	if err != nil {
+		panic("invalid embedded json for BestCheckpointConfigV0")
+	}
+	return schemaBestCheckpointConfigV0
+}
+
 func ParsedBindMountV0() interface{} {
 	cacheLock.RLock()
 	if schemaBindMountV0 != nil {
@@ -3709,6 +4320,46 @@ func ParsedDevicesConfigV0() interface{} {
 	return schemaDevicesConfigV0
 }
 
+func ParsedEarlyStoppingConfigV0() interface{} {
+	cacheLock.RLock()
+	if schemaEarlyStoppingConfigV0 != nil {
+		cacheLock.RUnlock()
+		return schemaEarlyStoppingConfigV0
+	}
+	cacheLock.RUnlock()
+
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	if schemaEarlyStoppingConfigV0 != nil {
+		return schemaEarlyStoppingConfigV0
+	}
+	err := json.Unmarshal(textEarlyStoppingConfigV0, &schemaEarlyStoppingConfigV0)
+	if err != nil {
+		panic("invalid embedded json for EarlyStoppingConfigV0")
+	}
+	return schemaEarlyStoppingConfigV0
+}
+
+func ParsedElasticConfigV0() interface{} {
+	cacheLock.RLock()
+	if schemaElasticConfigV0 != nil {
+		cacheLock.RUnlock()
+		return schemaElasticConfigV0
+	}
+	cacheLock.RUnlock()
+
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	if schemaElasticConfigV0 != nil {
+		return schemaElasticConfigV0
+	}
+	err := json.Unmarshal(textElasticConfigV0, &schemaElasticConfigV0)
+	if err != nil {
+		panic("invalid embedded json for ElasticConfigV0")
+	}
+	return schemaElasticConfigV0
+}
+
 func ParsedEnvironmentImageMapV0() interface{} {
 	cacheLock.RLock()
 	if schemaEnvironmentImageMapV0 != nil {
@@ -4229,6 +4880,46 @@ func ParsedAdaptiveASHAConfigV0() interface{} {
 	return schemaAdaptiveASHAConfigV0
 }
 
+func ParsedAdaptiveBracketV0() interface{} {
+	cacheLock.RLock()
+	if schemaAdaptiveBracketV0 != nil {
+		cacheLock.RUnlock()
+		return schemaAdaptiveBracketV0
+	}
+	cacheLock.RUnlock()
+
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	if schemaAdaptiveBracketV0 != nil {
+		return schemaAdaptiveBracketV0
+	}
+	err := json.Unmarshal(textAdaptiveBracketV0, &schemaAdaptiveBracketV0)
+	if err != nil {
+		panic("invalid embedded json for AdaptiveBracketV0")
+	}
+	return schemaAdaptiveBracketV0
+}
+
+func ParsedAdaptiveBracketsConfigV0() interface{} {
+	cacheLock.RLock()
+	if schemaAdaptiveBracketsConfigV0 != nil {
+		cacheLock.RUnlock()
+		return schemaAdaptiveBracketsConfigV0
+	}
+	cacheLock.RUnlock()
+
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	if schemaAdaptiveBracketsConfigV0 != nil {
+		return schemaAdaptiveBracketsConfigV0
+	}
+	err := json.Unmarshal(textAdaptiveBracketsConfigV0, &schemaAdaptiveBracketsConfigV0)
+	if err != nil {
+		panic("invalid embedded json for AdaptiveBracketsConfigV0")
+	}
+	return schemaAdaptiveBracketsConfigV0
+}
+
 func ParsedAdaptiveSimpleConfigV0() interface{} {
 	cacheLock.RLock()
 	if schemaAdaptiveSimpleConfigV0 != nil {
@@ -4604,8 +5295,14 @@ func schemaBytesMap() map[string][]byte {
 	}
 	var url string
 	cachedSchemaBytesMap = map[string][]byte{}
+	url = "http://determined.ai/schemas/expconf/v0/alert.json"
+	cachedSchemaBytesMap[url] = textAlertV0
+	url = "http://determined.ai/schemas/expconf/v0/alerts.json"
+	cachedSchemaBytesMap[url] = textAlertsConfigV0
 	url = "http://determined.ai/schemas/expconf/v0/azure.json"
 	cachedSchemaBytesMap[url] = textAzureConfigV0
+	url = "http://determined.ai/schemas/expconf/v0/best-checkpoint.json"
+	cachedSchemaBytesMap[url] = textBestCheckpointConfigV0
 	url = "http://determined.ai/schemas/expconf/v0/bind-mount.json"
 	cachedSchemaBytesMap[url] = textBindMountV0
 	url = "http://determined.ai/schemas/expconf/v0/bind-mounts.json"
@@ -4632,6 +5329,10 @@ func schemaBytesMap() map[string][]byte {
 	cachedSchemaBytesMap[url] = textDeviceV0
 	url = "http://determined.ai/schemas/expconf/v0/devices.json"
 	cachedSchemaBytesMap[url] = textDevicesConfigV0
+	url = "http://determined.ai/schemas/expconf/v0/early-stopping.json"
+	cachedSchemaBytesMap[url] = textEarlyStoppingConfigV0
+	url = "http://determined.ai/schemas/expconf/v0/elastic.json"
+	cachedSchemaBytesMap[url] = textElasticConfigV0
 	url = "http://determined.ai/schemas/expconf/v0/environment-image-map.json"
 	cachedSchemaBytesMap[url] = textEnvironmentImageMapV0
 	url = "http://determined.ai/schemas/expconf/v0/environment-image.json"
@@ -4684,6 +5385,10 @@ func schemaBytesMap() map[string][]byte {
 	cachedSchemaBytesMap[url] = textS3ConfigV0
 	url = "http://determined.ai/schemas/expconf/v0/searcher-adaptive-asha.json"
 	cachedSchemaBytesMap[url] = textAdaptiveASHAConfigV0
+	url = "http://determined.ai/schemas/expconf/v0/searcher-adaptive-bracket.json"
+	cachedSchemaBytesMap[url] = textAdaptiveBracketV0
+	url = "http://determined.ai/schemas/expconf/v0/searcher-adaptive-brackets.json"
+	cachedSchemaBytesMap[url] = textAdaptiveBracketsConfigV0
 	url = "http://determined.ai/schemas/expconf/v0/searcher-adaptive-simple.json"
 	cachedSchemaBytesMap[url] = textAdaptiveSimpleConfigV0
 	url = "http://determined.ai/schemas/expconf/v0/searcher-adaptive.json"
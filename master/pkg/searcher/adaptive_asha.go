@@ -69,6 +69,9 @@ func getBracketMaxConcurrentTrials(
 }
 
 func newAdaptiveASHASearch(config expconf.AdaptiveASHAConfig, smallerIsBetter bool) SearchMethod {
+	if len(config.Brackets()) > 0 {
+		return newCustomBracketsSearch(config, smallerIsBetter)
+	}
 	modeFunc := parseAdaptiveMode(config.Mode())
 
 	brackets := config.BracketRungs()
@@ -89,24 +92,70 @@ func newAdaptiveASHASearch(config expconf.AdaptiveASHAConfig, smallerIsBetter bo
 
 	methods := make([]SearchMethod, 0, len(brackets))
 	for i, numRungs := range brackets {
-		c := expconf.AsyncHalvingConfig{
-			RawNumRungs:            ptrs.Ptr(numRungs),
-			RawMaxLength:           ptrs.Ptr(config.MaxLength()),
-			RawMaxTrials:           &bracketMaxTrials[i],
-			RawDivisor:             ptrs.Ptr(config.Divisor()),
-			RawMaxConcurrentTrials: ptrs.Ptr(bracketMaxConcurrentTrials[i]),
-			RawStopOnce:            ptrs.Ptr(config.StopOnce()),
+		c := bracketConfig(config, numRungs, bracketMaxTrials[i], bracketMaxConcurrentTrials[i])
+		methods = append(methods, newBracketSearch(c, rungLengths(c), smallerIsBetter))
+	}
+
+	return newTournamentSearch(AdaptiveASHASearch, methods...)
+}
+
+// newCustomBracketsSearch returns an adaptive search over the brackets given in the config, in
+// the order given. Brackets that do not set max_trials or max_concurrent_trials get the share of
+// the searcher's that a bracket with as many rungs would get otherwise.
+func newCustomBracketsSearch(config expconf.AdaptiveASHAConfig, smallerIsBetter bool) SearchMethod {
+	brackets := config.Brackets()
+	numRungs := make([]int, 0, len(brackets))
+	for _, b := range brackets {
+		numRungs = append(numRungs, len(b.RungLengths()))
+	}
+	bracketMaxTrials := getBracketMaxTrials(config.MaxTrials(), config.Divisor(), numRungs)
+	for i, b := range brackets {
+		if b.MaxTrials() != nil {
+			bracketMaxTrials[i] = *b.MaxTrials()
 		}
-		if config.StopOnce() {
-			methods = append(methods, newAsyncHalvingStoppingSearch(c, smallerIsBetter))
-		} else {
-			methods = append(methods, newAsyncHalvingSearch(c, smallerIsBetter))
+	}
+	bracketMaxConcurrentTrials := getBracketMaxConcurrentTrials(
+		config.MaxConcurrentTrials(), config.Divisor(), bracketMaxTrials)
+
+	methods := make([]SearchMethod, 0, len(brackets))
+	for i, b := range brackets {
+		if b.MaxConcurrentTrials() != nil {
+			bracketMaxConcurrentTrials[i] = *b.MaxConcurrentTrials()
+		}
+		lengths := make([]uint64, 0, len(b.RungLengths()))
+		for _, l := range b.RungLengths() {
+			lengths = append(lengths, uint64(l))
 		}
+		c := bracketConfig(config, numRungs[i], bracketMaxTrials[i], bracketMaxConcurrentTrials[i])
+		methods = append(methods, newBracketSearch(c, lengths, smallerIsBetter))
 	}
 
 	return newTournamentSearch(AdaptiveASHASearch, methods...)
 }
 
+// bracketConfig returns the config of the ASHA search of one bracket of an adaptive search.
+func bracketConfig(
+	config expconf.AdaptiveASHAConfig, numRungs, maxTrials, maxConcurrentTrials int,
+) expconf.AsyncHalvingConfig {
+	return expconf.AsyncHalvingConfig{
+		RawNumRungs:            ptrs.Ptr(numRungs),
+		RawMaxLength:           ptrs.Ptr(config.MaxLength()),
+		RawMaxTrials:           ptrs.Ptr(maxTrials),
+		RawDivisor:             ptrs.Ptr(config.Divisor()),
+		RawMaxConcurrentTrials: ptrs.Ptr(maxConcurrentTrials),
+		RawStopOnce:            ptrs.Ptr(config.StopOnce()),
+	}
+}
+
+func newBracketSearch(
+	c expconf.AsyncHalvingConfig, lengths []uint64, smallerIsBetter bool,
+) SearchMethod {
+	if c.StopOnce() {
+		return newAsyncHalvingStoppingSearchWithRungs(c, lengths, smallerIsBetter)
+	}
+	return newAsyncHalvingSearchWithRungs(c, lengths, smallerIsBetter)
+}
+
 type adaptiveMode func(maxRungs int) []int
 
 func conservativeMode(maxRungs int) []int {
@@ -121,6 +121,27 @@ func TestAdaptiveASHASearchMethod(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "custom brackets",
+			expectedTrials: []predefinedTrial{
+				newConstantPredefinedTrial(toOps("200B 900B"), 0.1),
+				newConstantPredefinedTrial(toOps("200B"), 0.2),
+				newConstantPredefinedTrial(toOps("200B"), 0.3),
+				newConstantPredefinedTrial(toOps("700B"), 0.4),
+			},
+			config: expconf.SearcherConfig{
+				RawSmallerIsBetter: ptrs.Ptr(true),
+				RawAdaptiveASHAConfig: &expconf.AdaptiveASHAConfig{
+					RawMaxLength: ptrs.Ptr(expconf.NewLengthInBatches(900)),
+					RawMaxTrials: ptrs.Ptr(5),
+					RawDivisor:   ptrs.Ptr[float64](3),
+					RawBrackets: expconf.AdaptiveBracketsConfig{
+						{RawRungLengths: []int{200, 900}},
+						{RawRungLengths: []int{700}, RawMaxTrials: ptrs.Ptr(1)},
+					},
+				},
+			},
+		},
 	}
 
 	runValueSimulationTestCases(t, testCases)
@@ -53,13 +53,18 @@ type (
 const ashaExitedMetricValue = math.MaxFloat64
 
 func newAsyncHalvingSearch(config expconf.AsyncHalvingConfig, smallerIsBetter bool) SearchMethod {
-	rungs := make([]*rung, 0, config.NumRungs())
+	return newAsyncHalvingSearchWithRungs(config, rungLengths(config), smallerIsBetter)
+}
+
+// newAsyncHalvingSearchWithRungs returns an ASHA search whose rungs validate trials once they have
+// trained for the given lengths rather than for those derived from the config.
+func newAsyncHalvingSearchWithRungs(
+	config expconf.AsyncHalvingConfig, lengths []uint64, smallerIsBetter bool,
+) SearchMethod {
+	rungs := make([]*rung, 0, len(lengths))
 	var unitsNeeded uint64
-	for id := 0; id < config.NumRungs(); id++ {
-		// We divide the MaxLength by downsampling rate to get the target units
-		// for a rung.
-		downsamplingRate := math.Pow(config.Divisor(), float64(config.NumRungs()-id-1))
-		unitsNeeded += mathx.Max(uint64(float64(config.MaxLength().Units)/downsamplingRate), 1)
+	for _, length := range lengths {
+		unitsNeeded += length
 		rungs = append(rungs, &rung{UnitsNeeded: unitsNeeded})
 	}
 
@@ -76,6 +81,20 @@ func newAsyncHalvingSearch(config expconf.AsyncHalvingConfig, smallerIsBetter bo
 	}
 }
 
+// rungLengths returns how long trials have trained for when they are validated at each rung of an
+// ASHA search with the config.
+func rungLengths(config expconf.AsyncHalvingConfig) []uint64 {
+	lengths := make([]uint64, 0, config.NumRungs())
+	for id := 0; id < config.NumRungs(); id++ {
+		// We divide the MaxLength by downsampling rate to get the target units
+		// for a rung.
+		downsamplingRate := math.Pow(config.Divisor(), float64(config.NumRungs()-id-1))
+		lengths = append(lengths,
+			mathx.Max(uint64(float64(config.MaxLength().Units)/downsamplingRate), 1))
+	}
+	return lengths
+}
+
 func (s *asyncHalvingSearch) Snapshot() (json.RawMessage, error) {
 	return json.Marshal(s.asyncHalvingSearchState)
 }
@@ -27,13 +27,18 @@ type asyncHalvingStoppingSearch struct {
 func newAsyncHalvingStoppingSearch(
 	config expconf.AsyncHalvingConfig, smallerIsBetter bool,
 ) SearchMethod {
-	rungs := make([]*rung, 0, config.NumRungs())
+	return newAsyncHalvingStoppingSearchWithRungs(config, rungLengths(config), smallerIsBetter)
+}
+
+// newAsyncHalvingStoppingSearchWithRungs is like newAsyncHalvingSearchWithRungs, for searches that
+// stop trials rather than pausing them.
+func newAsyncHalvingStoppingSearchWithRungs(
+	config expconf.AsyncHalvingConfig, lengths []uint64, smallerIsBetter bool,
+) SearchMethod {
+	rungs := make([]*rung, 0, len(lengths))
 	var unitsNeeded uint64
-	for id := 0; id < config.NumRungs(); id++ {
-		// We divide the MaxLength by downsampling rate to get the target units
-		// for a rung.
-		downsamplingRate := math.Pow(config.Divisor(), float64(config.NumRungs()-id-1))
-		unitsNeeded += mathx.Max(uint64(float64(config.MaxLength().Units)/downsamplingRate), 1)
+	for _, length := range lengths {
+		unitsNeeded += length
 		rungs = append(rungs,
 			&rung{
 				UnitsNeeded:       unitsNeeded,
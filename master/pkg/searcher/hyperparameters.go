@@ -3,6 +3,7 @@ package searcher
 import (
 	"fmt"
 	"math"
+	"reflect"
 
 	"github.com/determined-ai/determined/master/pkg/nprand"
 	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
@@ -46,3 +47,42 @@ func sampleOne(h expconf.Hyperparameter, rand *nprand.State) interface{} {
 		panic(fmt.Sprintf("unexpected hyperparameter type: %+v", h))
 	}
 }
+
+// warmStartAll replaces the sampled values of the hyperparameters that vary in the search space
+// with the values a prior search observed for them. Hyperparameters that the prior search did not
+// have keep their sampled values.
+func warmStartAll(h expconf.Hyperparameters, sampled, prior HParamSample) HParamSample {
+	results := make(HParamSample)
+	h.Each(func(name string, param expconf.Hyperparameter) {
+		results[name] = warmStartOne(param, sampled[name], prior[name])
+	})
+	return results
+}
+
+func warmStartOne(h expconf.Hyperparameter, sampled, prior interface{}) interface{} {
+	switch {
+	case prior == nil, h.RawConstHyperparameter != nil:
+		return sampled
+	case h.RawNestedHyperparameter != nil:
+		sampledNested, _ := sampled.(map[string]interface{})
+		priorNested, ok := prior.(map[string]interface{})
+		if !ok {
+			return sampled
+		}
+		p := make(map[string]interface{})
+		for key, val := range *h.RawNestedHyperparameter {
+			p[key] = warmStartOne(val, sampledNested[key], priorNested[key])
+		}
+		return p
+	case h.RawCategoricalHyperparameter != nil:
+		// Categories that were removed from the search space are not tried again.
+		for _, val := range h.RawCategoricalHyperparameter.Vals() {
+			if reflect.DeepEqual(val, prior) {
+				return prior
+			}
+		}
+		return sampled
+	default:
+		return prior
+	}
+}
@@ -106,3 +106,47 @@ func TestSamplingReproducibility(t *testing.T) {
 		assert.Equal(t, rand1.Bits64(), rand2.Bits64())
 	}
 }
+
+func TestWarmStartAll(t *testing.T) {
+	nested := map[string]expconf.Hyperparameter{
+		"type": {
+			RawCategoricalHyperparameter: &expconf.CategoricalHyperparameter{
+				RawVals: []interface{}{"adam", "sgd"},
+			},
+		},
+		"momentum": {
+			RawDoubleHyperparameter: &expconf.DoubleHyperparameter{RawMinval: 0, RawMaxval: 1},
+		},
+	}
+	spec := expconf.Hyperparameters{
+		"global_batch_size": {
+			RawConstHyperparameter: &expconf.ConstHyperparameter{RawVal: 64},
+		},
+		"layers": {
+			RawIntHyperparameter: &expconf.IntHyperparameter{RawMinval: 1, RawMaxval: 8},
+		},
+		"dropout": {
+			RawDoubleHyperparameter: &expconf.DoubleHyperparameter{RawMinval: 0, RawMaxval: 0.5},
+		},
+		"optimizer": {RawNestedHyperparameter: &nested},
+	}
+	sampled := HParamSample{
+		"global_batch_size": 64,
+		"layers":            2,
+		"dropout":           0.25,
+		"optimizer":         map[string]interface{}{"type": "sgd", "momentum": 0.5},
+	}
+	prior := HParamSample{
+		"global_batch_size": 32.0,
+		"layers":            4.0,
+		"optimizer":         map[string]interface{}{"type": "rmsprop", "momentum": 0.9},
+		"removed":           true,
+	}
+
+	assert.DeepEqual(t, warmStartAll(spec, sampled, prior), HParamSample{
+		"global_batch_size": 64,
+		"layers":            4.0,
+		"dropout":           0.25,
+		"optimizer":         map[string]interface{}{"type": "sgd", "momentum": 0.9},
+	})
+}
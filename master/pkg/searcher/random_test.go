@@ -4,6 +4,8 @@ package searcher
 import (
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/determined-ai/determined/master/pkg/ptrs"
 	"github.com/determined-ai/determined/master/pkg/schemas"
 	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
@@ -118,3 +120,34 @@ func TestRandomSearcherSingleConcurrent(t *testing.T) {
 	search := newRandomSearch(actual)
 	checkSimulation(t, search, nil, ConstantValidation, expected)
 }
+
+func TestRandomSearcherWarmStart(t *testing.T) {
+	conf := expconf.RandomConfig{
+		RawMaxTrials: ptrs.Ptr(3), RawMaxLength: ptrs.Ptr(expconf.NewLengthInBatches(300)),
+	}
+	conf = schemas.WithDefaults(conf).(expconf.RandomConfig)
+	hparams := expconf.Hyperparameters{
+		"lr": {
+			RawDoubleHyperparameter: &expconf.DoubleHyperparameter{RawMinval: 0, RawMaxval: 1},
+		},
+	}
+	s := NewSearcher(0, newRandomSearch(conf), hparams)
+	require.NoError(t, s.WarmStart([]HParamSample{{"lr": 2.0}, {"lr": 3.0}}))
+
+	ops, err := s.InitialOperations()
+	require.NoError(t, err)
+	var creates []Create
+	for _, op := range ops {
+		if create, ok := op.(Create); ok {
+			creates = append(creates, create)
+		}
+	}
+	require.Len(t, creates, 3)
+	require.Equal(t, 2.0, creates[0].Hparams["lr"])
+	require.Equal(t, 3.0, creates[1].Hparams["lr"])
+	require.Less(t, creates[2].Hparams["lr"], 1.0)
+	require.Empty(t, s.WarmStartHParams)
+
+	grid := NewSearcher(0, newGridSearch(expconf.GridConfig{}), hparams)
+	require.Error(t, grid.WarmStart([]HParamSample{{"lr": 2.0}}))
+}
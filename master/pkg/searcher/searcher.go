@@ -27,6 +27,7 @@ type (
 		TrialProgress       map[model.RequestID]PartialUnits `json:"trial_progress"`
 		Shutdown            bool                             `json:"shutdown"`
 		CompletedOperations map[string]ValidateAfter         `json:"completed_operations"`
+		WarmStartHParams    []HParamSample                   `json:"warm_start_hparams"`
 
 		Rand *nprand.State `json:"rand"`
 
@@ -205,11 +206,28 @@ func (s *Searcher) SetCustomSearcherProgress(progress float64) error {
 	return unsupportedMethodError(s.method, "SetCustomSearcherProgress")
 }
 
-// Record records operations that were requested by the searcher for a specific trial.
+// WarmStart seeds the searcher with the hyperparameters of a prior search, ordered from best to
+// worst. The next trials the searcher creates try them before sampling the search space again.
+func (s *Searcher) WarmStart(hparams []HParamSample) error {
+	switch s.method.(type) {
+	case *gridSearch, *customSearch:
+		return unsupportedMethodError(s.method, "warm starting")
+	}
+	s.WarmStartHParams = hparams
+	return nil
+}
+
+// Record records operations that were requested by the searcher for a specific trial. Creates
+// consume the hyperparameters the searcher was warm started with, if any are left.
 func (s *Searcher) Record(ops []Operation) {
-	for _, op := range ops {
-		switch op.(type) {
+	for i, op := range ops {
+		switch op := op.(type) {
 		case Create:
+			if len(s.WarmStartHParams) > 0 {
+				op.Hparams = warmStartAll(s.hparams, op.Hparams, s.WarmStartHParams[0])
+				s.WarmStartHParams = s.WarmStartHParams[1:]
+				ops[i] = op
+			}
 			s.TrialsRequested++
 		case Shutdown:
 			s.Shutdown = true
@@ -28,10 +28,12 @@ func UniquePortOffset(devices []device.Device) int {
 	return int(min)
 }
 
-func toPortSet(ports map[string]int) nat.PortSet {
+func toPortSet(portMaps ...map[string]int) nat.PortSet {
 	dockerPorts := make(nat.PortSet)
-	for _, port := range ports {
-		dockerPorts[nat.Port(fmt.Sprintf("%d/tcp", port))] = struct{}{}
+	for _, ports := range portMaps {
+		for _, port := range ports {
+			dockerPorts[nat.Port(fmt.Sprintf("%d/tcp", port))] = struct{}{}
+		}
 	}
 	return dockerPorts
 }
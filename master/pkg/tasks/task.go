@@ -43,8 +43,10 @@ type TaskSpec struct {
 	// Fields that are set on the cluster level.
 	ClusterID   string
 	HarnessPath string
-	MasterCert  *tls.Certificate
-	SSHRsaSize  int
+	// MasterCert returns the certificate the master currently serves, so that tasks trust it after
+	// it is renewed or rotated. It is nil when the master does not use TLS.
+	MasterCert func() *tls.Certificate
+	SSHRsaSize int
 
 	SegmentEnabled bool
 	SegmentAPIKey  string
@@ -57,11 +59,15 @@ type TaskSpec struct {
 	ResourcesConfig       expconf.ResourcesConfig
 	WorkDir               string
 	Owner                 *model.User
+	WorkspaceID           *int
 	AgentUserGroup        *model.AgentUserGroup
 	ExtraArchives         []cproto.RunArchive
 	ExtraEnvVars          map[string]string
 	Entrypoint            []string
 	Mounts                []mount.Mount
+	// AuthorizedKeys are SSH public keys the owner registered to authorize in the container, in
+	// authorized_keys format.
+	AuthorizedKeys []byte
 	// UseHostMode is whether host mode networking would be desirable for this task.
 	// This is used by Docker only.
 	UseHostMode bool
@@ -97,6 +103,22 @@ func (t *TaskSpec) ResolveWorkDir() {
 	t.WorkDir = strings.ReplaceAll(workDir, "$DET_USER", detUser)
 }
 
+// OwnerID returns the ID of the user that owns the task, if it is known.
+func (t *TaskSpec) OwnerID() *model.UserID {
+	if t.Owner == nil {
+		return nil
+	}
+	return &t.Owner.ID
+}
+
+// masterCert returns the certificate of the master, if it uses TLS.
+func (t *TaskSpec) masterCert() *tls.Certificate {
+	if t.MasterCert == nil {
+		return nil
+	}
+	return t.MasterCert()
+}
+
 // Archives returns all the archives.
 func (t *TaskSpec) Archives() ([]cproto.RunArchive, []cproto.RunArchive) {
 	res := []cproto.RunArchive{
@@ -104,7 +126,7 @@ func (t *TaskSpec) Archives() ([]cproto.RunArchive, []cproto.RunArchive) {
 		runDirHelpersArchive(t.AgentUserGroup),
 		injectUserArchive(t.AgentUserGroup, t.WorkDir),
 		harnessArchive(t.HarnessPath, t.AgentUserGroup),
-		masterCertArchive(t.MasterCert),
+		masterCertArchive(t.masterCert()),
 	}
 	res = append(res, t.ExtraArchives...)
 
@@ -167,7 +189,7 @@ func (t TaskSpec) EnvVars() map[string]string {
 		e["DET_INTER_NODE_NETWORK_INTERFACE"] = networkInterface
 	}
 
-	if t.MasterCert != nil {
+	if t.masterCert() != nil {
 		e["DET_USE_TLS"] = "true"
 		e["DET_MASTER_CERT_FILE"] = certPath
 	} else {
@@ -238,7 +260,7 @@ func (t *TaskSpec) ToDockerSpec() cproto.Spec {
 		RunSpec: cproto.RunSpec{
 			ContainerConfig: docker.Config{
 				User:         getUser(t.AgentUserGroup),
-				ExposedPorts: toPortSet(env.Ports()),
+				ExposedPorts: toPortSet(env.Ports(), env.ProxyPorts()),
 				Env:          envVars,
 				Cmd:          t.Entrypoint,
 				Image:        env.Image().For(deviceType),
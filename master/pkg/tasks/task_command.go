@@ -4,10 +4,14 @@ import (
 	"archive/tar"
 	"encoding/json"
 
+	"github.com/docker/docker/api/types/mount"
+
 	"github.com/determined-ai/determined/master/pkg/archive"
 	"github.com/determined-ai/determined/master/pkg/cproto"
 	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/master/pkg/logger"
 	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/schemas/expconf"
 	"github.com/determined-ai/determined/master/pkg/ssh"
 )
 
@@ -49,11 +53,18 @@ type GenericCommandSpec struct {
 	Port            *int
 	ProxyTCP        bool
 	Unauthenticated bool
+	StripPrefix     bool
 
 	WatchProxyIdleTimeout  bool
 	WatchRunnerIdleTimeout bool
 
 	TaskType model.TaskType
+
+	// ArtifactStorage is the checkpoint storage the command uploads its artifacts to.
+	ArtifactStorage *expconf.CheckpointStorageConfig
+
+	// LogContext holds the fields that identify the request that created the command, for its logs.
+	LogContext logger.Context `json:"-"`
 }
 
 // ToTaskSpec generates a TaskSpec.
@@ -74,17 +85,17 @@ func (s GenericCommandSpec) ToTaskSpec(keys *ssh.PrivateAndPublicKeys) TaskSpec
 	}
 	res.ResolveWorkDir()
 
-	if keys != nil {
+	if keys != nil || len(res.AuthorizedKeys) > 0 {
+		var authorizedKeys []byte
+		if keys != nil {
+			authorizedKeys = append(authorizedKeys, keys.PublicKey...)
+		}
+		authorizedKeys = append(authorizedKeys, res.AuthorizedKeys...)
+
 		s.AdditionalFiles = append(s.AdditionalFiles, archive.Archive{
 			res.AgentUserGroup.OwnedArchiveItem(sshDir, nil, sshDirMode, tar.TypeDir),
 			res.AgentUserGroup.OwnedArchiveItem(
-				shellAuthorizedKeysFile, keys.PublicKey, 0o644, tar.TypeReg,
-			),
-			res.AgentUserGroup.OwnedArchiveItem(
-				privKeyFile, keys.PrivateKey, privKeyMode, tar.TypeReg,
-			),
-			res.AgentUserGroup.OwnedArchiveItem(
-				pubKeyFile, keys.PublicKey, pubKeyMode, tar.TypeReg,
+				shellAuthorizedKeysFile, authorizedKeys, 0o644, tar.TypeReg,
 			),
 			res.AgentUserGroup.OwnedArchiveItem(
 				sshdConfigFile,
@@ -93,6 +104,17 @@ func (s GenericCommandSpec) ToTaskSpec(keys *ssh.PrivateAndPublicKeys) TaskSpec
 				tar.TypeReg,
 			),
 		}...)
+		// Without generated keys, users connect with the keys they registered.
+		if keys != nil {
+			s.AdditionalFiles = append(s.AdditionalFiles, archive.Archive{
+				res.AgentUserGroup.OwnedArchiveItem(
+					privKeyFile, keys.PrivateKey, privKeyMode, tar.TypeReg,
+				),
+				res.AgentUserGroup.OwnedArchiveItem(
+					pubKeyFile, keys.PublicKey, pubKeyMode, tar.TypeReg,
+				),
+			}...)
+		}
 	}
 
 	res.ExtraArchives = []cproto.RunArchive{
@@ -106,6 +128,26 @@ func (s GenericCommandSpec) ToTaskSpec(keys *ssh.PrivateAndPublicKeys) TaskSpec
 
 	res.Mounts = ToDockerMounts(s.Config.BindMounts.ToExpconf(), res.WorkDir)
 
+	if s.ArtifactStorage != nil {
+		// Copy the environment variables of the base spec rather than adding to them, since the
+		// map is shared with it.
+		envVars := map[string]string{"DET_ARTIFACT_STORAGE": jsonify(*s.ArtifactStorage)}
+		for k, v := range res.ExtraEnvVars {
+			envVars[k] = v
+		}
+		res.ExtraEnvVars = envVars
+		if fs := s.ArtifactStorage.RawSharedFSConfig; fs != nil {
+			res.Mounts = append(res.Mounts, mount.Mount{
+				Type:   mount.TypeBind,
+				Source: fs.HostPath(),
+				Target: expconf.DefaultSharedFSContainerPath,
+				BindOptions: &mount.BindOptions{
+					Propagation: expconf.DefaultSharedFSPropagation,
+				},
+			})
+		}
+	}
+
 	if shm := s.Config.Resources.ShmSize; shm != nil {
 		res.ShmSize = int64(*shm)
 	}
@@ -61,7 +61,7 @@ func (s TrialSpec) ToTaskSpec(keys *ssh.PrivateAndPublicKeys) TaskSpec {
 	additionalSSHFiles := archive.Archive{
 		s.Base.AgentUserGroup.OwnedArchiveItem(sshDir, nil, sshDirMode, tar.TypeDir),
 		s.Base.AgentUserGroup.OwnedArchiveItem(trialAuthorizedKeysFile,
-			keys.PublicKey,
+			append(append([]byte{}, keys.PublicKey...), s.Base.AuthorizedKeys...),
 			trialAuthorizedKeysMode,
 			tar.TypeReg,
 		),
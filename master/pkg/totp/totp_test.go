@@ -0,0 +1,76 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA-1 key of the test vectors in RFC 6238.
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestRFCVectors(t *testing.T) {
+	// The RFC lists eight digit codes; six digit codes are their last six digits.
+	for unix, expected := range map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	} {
+		actual, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, expected, actual, "time %d", unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	now := time.Unix(1_700_000_000, 0)
+	step := Step(now)
+
+	current, err := Code(secret, step)
+	require.NoError(t, err)
+	accepted, ok, err := Validate(secret, current, now, 0)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, step, accepted)
+
+	// Codes cannot be reused.
+	_, ok, err = Validate(secret, current, now, accepted)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Codes from adjacent steps are accepted, but not older ones.
+	previous, err := Code(secret, step-1)
+	require.NoError(t, err)
+	_, ok, err = Validate(secret, previous, now, 0)
+	require.NoError(t, err)
+	require.True(t, ok)
+	stale, err := Code(secret, step-2)
+	require.NoError(t, err)
+	_, ok, err = Validate(secret, stale, now, 0)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, ok, err = Validate(secret, "12345", now, 0)
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, _, err = Validate("not base32!", current, now, 0)
+	require.Error(t, err)
+}
+
+func TestURI(t *testing.T) {
+	u, err := url.Parse(URI("Determined", "alice", "JBSWY3DPEHPK3PXP"))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", u.Scheme)
+	require.Equal(t, "totp", u.Host)
+	require.Equal(t, "/Determined:alice", u.Path)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", u.Query().Get("secret"))
+	require.Equal(t, "Determined", u.Query().Get("issuer"))
+}
@@ -0,0 +1 @@
+ALTER TABLE resourcemanagers_agent_agentstate DROP COLUMN labels;
@@ -0,0 +1 @@
+ALTER TABLE resourcemanagers_agent_agentstate ADD COLUMN labels jsonb;
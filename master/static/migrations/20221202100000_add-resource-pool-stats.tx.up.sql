@@ -0,0 +1,12 @@
+CREATE TABLE resource_pool_stats (
+    id SERIAL PRIMARY KEY,
+    resource_pool text NOT NULL,
+    time timestamptz NOT NULL,
+    queue_length integer NOT NULL,
+    queued_slots integer NOT NULL,
+    median_wait_seconds double precision,
+    slots_total integer NOT NULL,
+    slots_used integer NOT NULL
+);
+
+CREATE INDEX ix_resource_pool_stats_resource_pool_time ON resource_pool_stats (resource_pool, time);
@@ -0,0 +1,15 @@
+CREATE TABLE slot_reservations (
+    id SERIAL PRIMARY KEY,
+    resource_pool text NOT NULL,
+    slots integer NOT NULL CHECK (slots > 0),
+    start_time timestamptz NOT NULL,
+    end_time timestamptz NOT NULL,
+    user_ids integer[] NOT NULL DEFAULT '{}',
+    workspace_ids integer[] NOT NULL DEFAULT '{}',
+    description text NOT NULL DEFAULT '',
+    created_by integer NOT NULL REFERENCES users(id),
+    CHECK (start_time < end_time)
+);
+
+CREATE INDEX ix_slot_reservations_resource_pool_end_time
+    ON slot_reservations (resource_pool, end_time);
@@ -0,0 +1,3 @@
+DROP VIEW generic_metrics;
+
+DROP TABLE raw_generic_metrics;
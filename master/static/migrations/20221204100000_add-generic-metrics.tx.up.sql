@@ -0,0 +1,18 @@
+CREATE TABLE raw_generic_metrics (
+    id SERIAL PRIMARY KEY,
+    trial_id integer NOT NULL REFERENCES trials(id),
+    trial_run_id integer NOT NULL DEFAULT 0,
+    metric_group text NOT NULL,
+    total_batches integer NOT NULL,
+    end_time timestamptz NOT NULL,
+    metrics jsonb NOT NULL,
+    archived boolean NOT NULL DEFAULT false,
+    CONSTRAINT generic_metrics_trial_id_run_id_group_total_batches_unique
+        UNIQUE (trial_id, trial_run_id, metric_group, total_batches)
+);
+
+CREATE INDEX ix_raw_generic_metrics_trial_id_group_total_batches
+    ON raw_generic_metrics (trial_id, metric_group, total_batches);
+
+CREATE VIEW generic_metrics AS
+    SELECT * FROM raw_generic_metrics WHERE NOT archived;
@@ -0,0 +1,3 @@
+-- The pg_trgm extension is left in place, since it may have been created by hand or be used by
+-- something else.
+DROP INDEX CONCURRENTLY IF EXISTS ix_task_logs_log_trgm;
@@ -0,0 +1,18 @@
+-- Lets substring and regular expression searches over task logs use an index. The indexed
+-- expression must match the one the log filters search. This migration is not run in a
+-- transaction so that writing logs is not blocked while the index is built, which is why the
+-- extension is created in a statement of its own.
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm') THEN
+    CREATE EXTENSION pg_trgm;
+  END IF;
+EXCEPTION WHEN insufficient_privilege THEN
+  RAISE EXCEPTION 'the database user cannot create the pg_trgm extension to index task logs'
+    USING HINT = 'Have a superuser run "CREATE EXTENSION pg_trgm;" in this database, '
+      'then restart the master.';
+END
+$$;
+--gopg:split
+CREATE INDEX CONCURRENTLY IF NOT EXISTS ix_task_logs_log_trgm
+    ON task_logs USING gin (encode(log, 'escape') gin_trgm_ops);
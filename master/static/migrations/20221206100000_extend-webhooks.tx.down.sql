@@ -0,0 +1,16 @@
+ALTER TABLE webhook_events_queue DROP COLUMN webhook_id;
+
+DELETE FROM webhook_triggers
+  WHERE trigger_type IN ('CHECKPOINT_CREATED', 'TASK_STATE_CHANGE');
+ALTER TYPE public.trigger_type RENAME TO _trigger_type;
+CREATE TYPE public.trigger_type AS ENUM (
+  'EXPERIMENT_STATE_CHANGE',
+  'METRIC_THRESHOLD_EXCEEDED'
+);
+ALTER TABLE webhook_triggers
+  ALTER COLUMN trigger_type TYPE public.trigger_type USING (trigger_type::text::trigger_type);
+DROP TYPE _trigger_type;
+
+ALTER TABLE webhooks
+  DROP COLUMN workspace_id,
+  DROP COLUMN secret;
@@ -0,0 +1,17 @@
+ALTER TABLE webhooks
+  ADD COLUMN secret text,
+  ADD COLUMN workspace_id integer REFERENCES workspaces(id) ON DELETE CASCADE;
+
+ALTER TYPE public.trigger_type RENAME TO _trigger_type;
+CREATE TYPE public.trigger_type AS ENUM (
+  'EXPERIMENT_STATE_CHANGE',
+  'METRIC_THRESHOLD_EXCEEDED',
+  'CHECKPOINT_CREATED',
+  'TASK_STATE_CHANGE'
+);
+ALTER TABLE webhook_triggers
+  ALTER COLUMN trigger_type TYPE public.trigger_type USING (trigger_type::text::trigger_type);
+DROP TYPE _trigger_type;
+
+ALTER TABLE webhook_events_queue
+  ADD COLUMN webhook_id integer REFERENCES webhooks(id) ON DELETE CASCADE;
@@ -0,0 +1,9 @@
+DELETE FROM webhooks WHERE webhook_type = 'TEAMS';
+ALTER TYPE public.webhook_type RENAME TO _webhook_type;
+CREATE TYPE public.webhook_type AS ENUM (
+  'DEFAULT',
+  'SLACK'
+);
+ALTER TABLE webhooks
+  ALTER COLUMN webhook_type TYPE public.webhook_type USING (webhook_type::text::webhook_type);
+DROP TYPE _webhook_type;
@@ -0,0 +1,9 @@
+ALTER TYPE public.webhook_type RENAME TO _webhook_type;
+CREATE TYPE public.webhook_type AS ENUM (
+  'DEFAULT',
+  'SLACK',
+  'TEAMS'
+);
+ALTER TABLE webhooks
+  ALTER COLUMN webhook_type TYPE public.webhook_type USING (webhook_type::text::webhook_type);
+DROP TYPE _webhook_type;
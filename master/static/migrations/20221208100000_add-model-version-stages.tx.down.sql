@@ -0,0 +1,9 @@
+DROP TABLE model_version_transitions;
+DROP TYPE public.model_version_transition_state;
+
+ALTER TABLE models DROP COLUMN stage_approver_ids;
+
+ALTER TABLE model_versions
+  DROP CONSTRAINT model_versions_id_unique,
+  DROP COLUMN stage;
+DROP TYPE public.model_version_stage;
@@ -0,0 +1,35 @@
+CREATE TYPE public.model_version_stage AS ENUM (
+  'NONE',
+  'STAGING',
+  'PRODUCTION',
+  'ARCHIVED'
+);
+
+ALTER TABLE model_versions
+  ADD COLUMN stage public.model_version_stage NOT NULL DEFAULT 'NONE',
+  ADD CONSTRAINT model_versions_id_unique UNIQUE (id);
+
+ALTER TABLE models
+  ADD COLUMN stage_approver_ids integer[] NOT NULL DEFAULT '{}';
+
+CREATE TYPE public.model_version_transition_state AS ENUM (
+  'PENDING',
+  'APPLIED',
+  'REJECTED'
+);
+
+CREATE TABLE model_version_transitions (
+  id serial PRIMARY KEY,
+  model_version_id integer NOT NULL REFERENCES model_versions(id) ON DELETE CASCADE,
+  from_stage public.model_version_stage NOT NULL,
+  to_stage public.model_version_stage NOT NULL,
+  archive_existing boolean NOT NULL DEFAULT false,
+  state public.model_version_transition_state NOT NULL,
+  comment text NOT NULL DEFAULT '',
+  requested_by integer NOT NULL REFERENCES users(id),
+  requested_time timestamptz NOT NULL DEFAULT now(),
+  reviewed_by integer REFERENCES users(id),
+  reviewed_time timestamptz
+);
+CREATE INDEX ix_model_version_transitions_model_version_id
+  ON model_version_transitions(model_version_id);
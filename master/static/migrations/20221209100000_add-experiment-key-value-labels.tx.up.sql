@@ -0,0 +1,3 @@
+ALTER TABLE experiments ADD COLUMN key_value_labels jsonb NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX ix_experiments_key_value_labels ON experiments USING gin (key_value_labels);
@@ -0,0 +1,3 @@
+DROP TABLE project_members;
+DROP TABLE workspace_members;
+DROP TYPE public.member_role;
@@ -0,0 +1,17 @@
+CREATE TYPE public.member_role AS ENUM ('VIEWER', 'EDITOR', 'ADMIN');
+
+CREATE TABLE workspace_members (
+  workspace_id integer NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+  user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  role public.member_role NOT NULL,
+  PRIMARY KEY (workspace_id, user_id)
+);
+CREATE INDEX ix_workspace_members_user_id ON workspace_members USING btree (user_id);
+
+CREATE TABLE project_members (
+  project_id integer NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  role public.member_role NOT NULL,
+  PRIMARY KEY (project_id, user_id)
+);
+CREATE INDEX ix_project_members_user_id ON project_members USING btree (user_id);
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN remote boolean NOT NULL DEFAULT false;
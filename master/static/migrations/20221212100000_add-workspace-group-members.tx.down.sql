@@ -0,0 +1 @@
+DROP TABLE workspace_group_members;
@@ -0,0 +1,7 @@
+CREATE TABLE workspace_group_members (
+  workspace_id integer NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+  group_id integer NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+  role public.member_role NOT NULL,
+  PRIMARY KEY (workspace_id, group_id)
+);
+CREATE INDEX ix_workspace_group_members_group_id ON workspace_group_members USING btree (group_id);
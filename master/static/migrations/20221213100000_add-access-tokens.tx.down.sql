@@ -0,0 +1,2 @@
+DROP TABLE access_tokens;
+DROP TYPE public.access_token_scope;
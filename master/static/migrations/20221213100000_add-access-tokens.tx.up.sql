@@ -0,0 +1,14 @@
+CREATE TYPE public.access_token_scope AS ENUM ('FULL', 'READ_ONLY', 'CHECKPOINT_DOWNLOAD');
+
+CREATE TABLE access_tokens (
+  id serial PRIMARY KEY,
+  user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  token_hash text NOT NULL UNIQUE,
+  description text NOT NULL DEFAULT '',
+  scope public.access_token_scope NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  expires_at timestamptz,
+  revoked_at timestamptz,
+  last_used_at timestamptz
+);
+CREATE INDEX ix_access_tokens_user_id ON access_tokens USING btree (user_id);
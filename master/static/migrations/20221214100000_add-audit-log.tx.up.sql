@@ -0,0 +1,25 @@
+CREATE TABLE audit_log (
+  id bigserial PRIMARY KEY,
+  time timestamptz NOT NULL DEFAULT now(),
+  user_id integer,
+  username text NOT NULL DEFAULT '',
+  action text NOT NULL,
+  target_type text NOT NULL DEFAULT '',
+  target_id text NOT NULL DEFAULT '',
+  success boolean NOT NULL,
+  remote_ip text NOT NULL DEFAULT '',
+  details jsonb NOT NULL DEFAULT '{}'
+);
+CREATE INDEX ix_audit_log_time ON audit_log USING btree (time);
+CREATE INDEX ix_audit_log_user_id_time ON audit_log USING btree (user_id, time);
+
+-- The audit log is append-only: entries can be removed once they age out, but never changed.
+CREATE OR REPLACE FUNCTION audit_log_reject_update() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit log entries cannot be changed';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_reject_update
+BEFORE UPDATE ON audit_log
+FOR EACH ROW EXECUTE PROCEDURE audit_log_reject_update();
@@ -0,0 +1 @@
+DROP TABLE experiment_cold_archives;
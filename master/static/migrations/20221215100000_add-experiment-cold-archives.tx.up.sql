@@ -0,0 +1,9 @@
+CREATE TABLE experiment_cold_archives (
+  experiment_id integer PRIMARY KEY REFERENCES experiments(id) ON DELETE CASCADE,
+  archived_at timestamptz NOT NULL DEFAULT now(),
+  -- NULL while the metrics and logs of the experiment are only in storage.
+  rehydrated_at timestamptz,
+  -- The checkpoint storage config the archive was written to, so that it can be found even if
+  -- the configuration of the master changes.
+  storage jsonb NOT NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE experiments DROP COLUMN best_checkpoint_uuid;
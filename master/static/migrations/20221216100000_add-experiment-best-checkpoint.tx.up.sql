@@ -0,0 +1,3 @@
+-- The best checkpoint of the experiment by its best checkpoint policy, kept up to date as
+-- validations and checkpoints are reported.
+ALTER TABLE experiments ADD COLUMN best_checkpoint_uuid uuid;
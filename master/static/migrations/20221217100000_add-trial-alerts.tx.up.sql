@@ -0,0 +1,11 @@
+-- Alerts that fired for a trial. Each alert fires at most once per trial.
+CREATE TABLE trial_alerts (
+  trial_id integer NOT NULL REFERENCES trials(id) ON DELETE CASCADE,
+  name text NOT NULL,
+  metric_group text NOT NULL,
+  metric text NOT NULL,
+  value float8 NOT NULL,
+  total_batches integer NOT NULL,
+  fired_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (trial_id, name)
+);
@@ -0,0 +1,14 @@
+DELETE FROM webhook_triggers WHERE trigger_type = 'SCHEDULED_RUN_FAILED';
+ALTER TYPE public.trigger_type RENAME TO _trigger_type;
+CREATE TYPE public.trigger_type AS ENUM (
+  'EXPERIMENT_STATE_CHANGE',
+  'METRIC_THRESHOLD_EXCEEDED',
+  'CHECKPOINT_CREATED',
+  'TASK_STATE_CHANGE'
+);
+ALTER TABLE webhook_triggers
+  ALTER COLUMN trigger_type TYPE public.trigger_type USING (trigger_type::text::trigger_type);
+DROP TYPE _trigger_type;
+
+DROP TABLE experiment_schedule_runs;
+DROP TABLE experiment_schedules;
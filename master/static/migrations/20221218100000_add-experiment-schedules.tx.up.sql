@@ -0,0 +1,45 @@
+-- Schedules that submit an experiment from a config and model definition when their cron
+-- expression matches, such as every night.
+CREATE TABLE experiment_schedules (
+  id serial PRIMARY KEY,
+  name text NOT NULL UNIQUE,
+  cron text NOT NULL,
+  time_zone text NOT NULL DEFAULT 'UTC',
+  config text NOT NULL,
+  template text,
+  model_definition bytea NOT NULL,
+  project_id integer NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  owner_id integer NOT NULL REFERENCES users(id),
+  paused boolean NOT NULL DEFAULT false,
+  next_run_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX ix_experiment_schedules_next_run_at ON experiment_schedules (next_run_at)
+  WHERE NOT paused;
+
+-- The history of the runs of each schedule. Runs that failed to submit an experiment have an
+-- error and no experiment.
+CREATE TABLE experiment_schedule_runs (
+  id serial PRIMARY KEY,
+  schedule_id integer NOT NULL REFERENCES experiment_schedules(id) ON DELETE CASCADE,
+  scheduled_at timestamptz NOT NULL,
+  submitted_at timestamptz NOT NULL,
+  experiment_id integer REFERENCES experiments(id) ON DELETE SET NULL,
+  error text
+);
+
+CREATE INDEX ix_experiment_schedule_runs_schedule_id ON experiment_schedule_runs (schedule_id);
+CREATE INDEX ix_experiment_schedule_runs_experiment_id ON experiment_schedule_runs (experiment_id);
+
+ALTER TYPE public.trigger_type RENAME TO _trigger_type;
+CREATE TYPE public.trigger_type AS ENUM (
+  'EXPERIMENT_STATE_CHANGE',
+  'METRIC_THRESHOLD_EXCEEDED',
+  'CHECKPOINT_CREATED',
+  'TASK_STATE_CHANGE',
+  'SCHEDULED_RUN_FAILED'
+);
+ALTER TABLE webhook_triggers
+  ALTER COLUMN trigger_type TYPE public.trigger_type USING (trigger_type::text::trigger_type);
+DROP TYPE _trigger_type;
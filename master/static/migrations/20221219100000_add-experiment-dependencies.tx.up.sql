@@ -0,0 +1,8 @@
+CREATE TABLE experiment_dependencies (
+  experiment_id integer NOT NULL REFERENCES experiments(id) ON DELETE CASCADE,
+  depends_on_id integer NOT NULL REFERENCES experiments(id) ON DELETE CASCADE,
+  inherit_checkpoint boolean NOT NULL DEFAULT false,
+  met boolean NOT NULL DEFAULT false,
+  PRIMARY KEY (experiment_id, depends_on_id)
+);
+CREATE INDEX ix_experiment_dependencies_depends_on_id ON experiment_dependencies (depends_on_id);
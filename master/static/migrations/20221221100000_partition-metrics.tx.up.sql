@@ -0,0 +1,187 @@
+-- Partition the tables of metrics by ranges of trial IDs. Trial IDs only grow, so old partitions
+-- stop receiving writes, deleting old experiments can drop whole partitions instead of leaving dead
+-- rows for vacuum, and reading the metrics of a trial only scans its partition.
+ALTER TABLE raw_steps RENAME TO raw_steps_unpartitioned;
+ALTER TABLE raw_validations RENAME TO raw_validations_unpartitioned;
+ALTER TABLE raw_generic_metrics RENAME TO raw_generic_metrics_unpartitioned;
+
+CREATE TABLE raw_steps (LIKE raw_steps_unpartitioned INCLUDING DEFAULTS)
+  PARTITION BY RANGE (trial_id);
+CREATE TABLE raw_validations (LIKE raw_validations_unpartitioned INCLUDING DEFAULTS)
+  PARTITION BY RANGE (trial_id);
+CREATE TABLE raw_generic_metrics (LIKE raw_generic_metrics_unpartitioned INCLUDING DEFAULTS)
+  PARTITION BY RANGE (trial_id);
+
+-- Rows of trials that are past the last partition, which the master creates ahead of time, land in
+-- the default partitions until their partition is created.
+CREATE TABLE raw_steps_default PARTITION OF raw_steps DEFAULT;
+CREATE TABLE raw_validations_default PARTITION OF raw_validations DEFAULT;
+CREATE TABLE raw_generic_metrics_default PARTITION OF raw_generic_metrics DEFAULT;
+
+CREATE TABLE metrics_partitions (
+  table_name text NOT NULL,
+  lower_bound integer NOT NULL,
+  upper_bound integer NOT NULL,
+  PRIMARY KEY (table_name, lower_bound)
+);
+
+-- add_metrics_partitions creates the partitions of every table of metrics that are missing for
+-- trial IDs up to through_trial_id, moving rows of their trials out of the default partitions.
+CREATE FUNCTION add_metrics_partitions(through_trial_id integer, trials_per_partition integer)
+RETURNS void AS $$
+DECLARE
+  parent text;
+  lo integer;
+  hi integer;
+  part text;
+BEGIN
+  FOREACH parent IN ARRAY ARRAY['raw_steps', 'raw_validations', 'raw_generic_metrics'] LOOP
+    SELECT coalesce(max(p.upper_bound), 0) INTO lo
+      FROM metrics_partitions p WHERE p.table_name = parent;
+    WHILE lo <= through_trial_id LOOP
+      hi := lo + trials_per_partition;
+      part := format('%s_%s', parent, lo);
+      EXECUTE format('CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS)', part, parent);
+      EXECUTE format(
+        'WITH moved AS (DELETE FROM %I WHERE trial_id >= %s AND trial_id < %s RETURNING *) '
+        'INSERT INTO %I SELECT * FROM moved', parent || '_default', lo, hi, part);
+      EXECUTE format(
+        'ALTER TABLE %I ATTACH PARTITION %I FOR VALUES FROM (%s) TO (%s)', parent, part, lo, hi);
+      INSERT INTO metrics_partitions (table_name, lower_bound, upper_bound)
+        VALUES (parent, lo, hi);
+      lo := hi;
+    END LOOP;
+  END LOOP;
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT add_metrics_partitions((SELECT coalesce(max(id), 0) FROM trials) + 10000, 10000);
+
+INSERT INTO raw_steps SELECT * FROM raw_steps_unpartitioned;
+INSERT INTO raw_validations SELECT * FROM raw_validations_unpartitioned;
+INSERT INTO raw_generic_metrics SELECT * FROM raw_generic_metrics_unpartitioned;
+
+CREATE OR REPLACE VIEW steps AS
+    SELECT * FROM raw_steps WHERE NOT archived;
+
+CREATE OR REPLACE VIEW validations AS
+    SELECT * FROM raw_validations WHERE NOT archived;
+
+CREATE OR REPLACE VIEW generic_metrics AS
+    SELECT * FROM raw_generic_metrics WHERE NOT archived;
+
+CREATE OR REPLACE VIEW public.checkpoints_old_view AS
+    SELECT
+        c.id AS id,
+        c.uuid AS uuid,
+        t.task_id,
+        CASE
+        WHEN t.task_id is NULL THEN
+            NULL
+        ELSE
+            t.task_id || '.' || c.trial_run_id
+        END allocation_id,
+        c.end_time as report_time,
+        c.state,
+        c.resources,
+        -- construct a metadata json from the user's metadata plus our training-specific fields that the
+        -- TrialControllers inject when creating checkpoints.  Those values used to be "system" values,
+        -- but since the release of Core API, the TrialControllers are no longer part of the system
+        -- proper but are considered userspace tools.
+        jsonb_build_object(
+            'steps_completed', c.total_batches,
+            'framework', c.framework,
+            'format', c.format,
+            'determined_version', c.determined_version,
+            'experiment_config', e.config,
+            'hparams', t.hparams
+        ) || COALESCE(c.metadata, '{}'::jsonb) AS metadata,
+        t.id AS trial_id,
+        e.id AS experiment_id,
+        e.config AS experiment_config,
+        t.hparams AS hparams,
+        s.metrics AS training_metrics,
+        v.metrics->'validation_metrics' AS validation_metrics,
+        (v.metrics->'validation_metrics'->>(e.config->'searcher'->>'metric'))::float8 AS searcher_metric,
+        c.total_batches as steps_completed,
+        1 as checkpoint_version
+    FROM raw_checkpoints AS c
+    LEFT JOIN trials AS t on c.trial_id = t.id
+    LEFT JOIN experiments AS e on t.experiment_id = e.id
+    LEFT JOIN raw_steps AS s ON (
+        -- Hint to the query planner to use the matching index.
+        s.trial_id = t.id
+        AND s.trial_run_id = c.trial_run_id
+        AND s.total_batches = c.total_batches
+    )
+    LEFT JOIN raw_validations AS v ON (
+        -- Hint to the query planner to use the matching index.
+        v.trial_id = c.trial_id
+        AND v.trial_run_id = c.trial_run_id
+        AND v.total_batches = c.total_batches
+    )
+    -- Avoiding the steps and validation view causes Postgres to not "Materialize" in this join.
+    WHERE s.archived IS NULL OR s.archived = false
+      AND v.archived IS NULL OR v.archived = false;
+
+CREATE OR REPLACE VIEW public.checkpoints_new_view AS
+    SELECT
+        c.id AS id,
+        c.uuid AS uuid,
+        c.task_id,
+        c.allocation_id,
+        c.report_time,
+        c.state,
+        c.resources,
+        c.metadata,
+        t.id AS trial_id,
+        e.id AS experiment_id,
+        e.config AS experiment_config,
+        t.hparams AS hparams,
+        s.metrics AS training_metrics,
+        v.metrics->'validation_metrics' AS validation_metrics,
+        (v.metrics->'validation_metrics'->>(e.config->'searcher'->>'metric'))::float8 AS searcher_metric,
+        CAST(c.metadata->>'steps_completed' AS int) as steps_completed,
+        2 AS checkpoint_version
+    FROM checkpoints_v2 AS c
+    LEFT JOIN trials AS t on c.task_id = t.task_id
+    LEFT JOIN experiments AS e on t.experiment_id = e.id
+    LEFT JOIN raw_validations AS v on CAST(c.metadata->>'steps_completed' AS int) = v.total_batches and t.id = v.trial_id
+    LEFT JOIN raw_steps AS s on CAST(c.metadata->>'steps_completed' AS int) = s.total_batches and t.id = s.trial_id
+    -- avoiding the steps view causes Postgres to not "Materialize" in this join.
+    WHERE s.archived IS NULL OR s.archived = false
+      AND v.archived IS NULL OR v.archived = false;
+
+ALTER SEQUENCE raw_steps_id_seq OWNED BY raw_steps.id;
+ALTER SEQUENCE raw_generic_metrics_id_seq OWNED BY raw_generic_metrics.id;
+DROP TABLE raw_steps_unpartitioned;
+DROP TABLE raw_validations_unpartitioned;
+DROP TABLE raw_generic_metrics_unpartitioned;
+
+-- Identity columns cannot be partitioned in every version of Postgres we support, so validation
+-- IDs come from a plain sequence instead.
+CREATE SEQUENCE validations_id_seq OWNED BY raw_validations.id;
+SELECT setval('validations_id_seq', coalesce(max(id), 0) + 1, false) FROM raw_validations;
+ALTER TABLE raw_validations ALTER COLUMN id SET DEFAULT nextval('validations_id_seq');
+
+ALTER TABLE raw_steps
+  ADD CONSTRAINT steps_pkey PRIMARY KEY (trial_id, id),
+  ADD CONSTRAINT steps_trial_id_fkey FOREIGN KEY (trial_id) REFERENCES trials(id);
+CREATE INDEX steps_archived ON raw_steps(archived);
+CREATE UNIQUE INDEX steps_trial_id_total_batches_run_id_unique
+  ON raw_steps(trial_id, total_batches, trial_run_id);
+
+-- Unique indexes of partitioned tables must include the partition key.
+ALTER TABLE raw_validations ADD CONSTRAINT validations_pkey PRIMARY KEY (trial_id, id);
+CREATE INDEX ix_validations_trial_id ON raw_validations(trial_id);
+CREATE INDEX validations_archived ON raw_validations(archived);
+CREATE UNIQUE INDEX validations_trial_id_total_batches_run_id_unique
+  ON raw_validations(trial_id, total_batches, trial_run_id);
+
+ALTER TABLE raw_generic_metrics
+  ADD CONSTRAINT raw_generic_metrics_pkey PRIMARY KEY (trial_id, id),
+  ADD CONSTRAINT raw_generic_metrics_trial_id_fkey FOREIGN KEY (trial_id) REFERENCES trials(id),
+  ADD CONSTRAINT generic_metrics_trial_id_run_id_group_total_batches_unique
+    UNIQUE (trial_id, trial_run_id, metric_group, total_batches);
+CREATE INDEX ix_raw_generic_metrics_trial_id_group_total_batches
+  ON raw_generic_metrics (trial_id, metric_group, total_batches);
@@ -0,0 +1,2 @@
+DROP TABLE db_restores;
+DROP TABLE db_backups;
@@ -0,0 +1,28 @@
+-- Logical backups of the database that admins take through the API, and restores from them.
+-- Neither table is part of backups or overwritten by restores, so that they keep track of both.
+CREATE TABLE db_backups (
+  id serial PRIMARY KEY,
+  state text NOT NULL,
+  storage jsonb NOT NULL,
+  migration_version bigint NOT NULL,
+  tables_done integer NOT NULL DEFAULT 0,
+  tables_total integer NOT NULL DEFAULT 0,
+  rows_done bigint NOT NULL DEFAULT 0,
+  size_bytes bigint NOT NULL DEFAULT 0,
+  started_at timestamptz NOT NULL DEFAULT now(),
+  ended_at timestamptz,
+  error text
+);
+
+CREATE TABLE db_restores (
+  id serial PRIMARY KEY,
+  backup_id integer NOT NULL REFERENCES db_backups(id) ON DELETE CASCADE,
+  validate_only boolean NOT NULL,
+  state text NOT NULL,
+  tables_done integer NOT NULL DEFAULT 0,
+  tables_total integer NOT NULL DEFAULT 0,
+  rows_done bigint NOT NULL DEFAULT 0,
+  started_at timestamptz NOT NULL DEFAULT now(),
+  ended_at timestamptz,
+  error text
+);
@@ -0,0 +1,33 @@
+-- Tables of metrics that were converted into hypertables stay hypertables, and keep using
+-- metrics_trial_now.
+DROP FUNCTION enable_timescale_metrics;
+
+CREATE OR REPLACE FUNCTION add_metrics_partitions(
+  through_trial_id integer, trials_per_partition integer
+)
+RETURNS void AS $$
+DECLARE
+  parent text;
+  lo integer;
+  hi integer;
+  part text;
+BEGIN
+  FOREACH parent IN ARRAY ARRAY['raw_steps', 'raw_validations', 'raw_generic_metrics'] LOOP
+    SELECT coalesce(max(p.upper_bound), 0) INTO lo
+      FROM metrics_partitions p WHERE p.table_name = parent;
+    WHILE lo <= through_trial_id LOOP
+      hi := lo + trials_per_partition;
+      part := format('%s_%s', parent, lo);
+      EXECUTE format('CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS)', part, parent);
+      EXECUTE format(
+        'WITH moved AS (DELETE FROM %I WHERE trial_id >= %s AND trial_id < %s RETURNING *) '
+        'INSERT INTO %I SELECT * FROM moved', parent || '_default', lo, hi, part);
+      EXECUTE format(
+        'ALTER TABLE %I ATTACH PARTITION %I FOR VALUES FROM (%s) TO (%s)', parent, part, lo, hi);
+      INSERT INTO metrics_partitions (table_name, lower_bound, upper_bound)
+        VALUES (parent, lo, hi);
+      lo := hi;
+    END LOOP;
+  END LOOP;
+END;
+$$ LANGUAGE plpgsql;
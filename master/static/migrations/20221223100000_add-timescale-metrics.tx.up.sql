@@ -0,0 +1,161 @@
+-- add_metrics_partitions only manages the tables of metrics that are still partitioned, since
+-- enable_timescale_metrics turns the tables of training and validation metrics into TimescaleDB
+-- hypertables, which create their own chunks.
+CREATE OR REPLACE FUNCTION add_metrics_partitions(
+  through_trial_id integer, trials_per_partition integer
+)
+RETURNS void AS $$
+DECLARE
+  parent text;
+  lo integer;
+  hi integer;
+  part text;
+BEGIN
+  FOR parent IN
+    SELECT c.relname::text FROM pg_class c
+    WHERE c.relname = ANY(ARRAY['raw_steps', 'raw_validations', 'raw_generic_metrics'])
+      AND c.relnamespace = 'public'::regnamespace
+      AND c.relkind = 'p'
+  LOOP
+    SELECT coalesce(max(p.upper_bound), 0) INTO lo
+      FROM metrics_partitions p WHERE p.table_name = parent;
+    WHILE lo <= through_trial_id LOOP
+      hi := lo + trials_per_partition;
+      part := format('%s_%s', parent, lo);
+      EXECUTE format('CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS)', part, parent);
+      EXECUTE format(
+        'WITH moved AS (DELETE FROM %I WHERE trial_id >= %s AND trial_id < %s RETURNING *) '
+        'INSERT INTO %I SELECT * FROM moved', parent || '_default', lo, hi, part);
+      EXECUTE format(
+        'ALTER TABLE %I ATTACH PARTITION %I FOR VALUES FROM (%s) TO (%s)', parent, part, lo, hi);
+      INSERT INTO metrics_partitions (table_name, lower_bound, upper_bound)
+        VALUES (parent, lo, hi);
+      lo := hi;
+    END LOOP;
+  END LOOP;
+END;
+$$ LANGUAGE plpgsql;
+
+-- The time dimension of the hypertables of metrics is the ID of the trial, so "now" is the latest
+-- trial.
+CREATE OR REPLACE FUNCTION metrics_trial_now() RETURNS integer AS $$
+  SELECT coalesce(max(id), 0) FROM trials
+$$ LANGUAGE sql STABLE;
+
+-- enable_timescale_metrics converts the tables of training and validation metrics into TimescaleDB
+-- hypertables chunked by ranges of trial IDs, with compression enabled, and creates continuous
+-- aggregates that keep the last point of every 1000 batches of each series. Tables that are
+-- already hypertables are left alone. The master calls it on startup when configured to store
+-- metrics in TimescaleDB; there is no way back.
+CREATE FUNCTION enable_timescale_metrics(trials_per_chunk integer) RETURNS void AS $$
+DECLARE
+  t text;
+  old text;
+  v regclass;
+  seq text;
+  indexes text[];
+  constraints text[];
+  stmt text;
+BEGIN
+  CREATE EXTENSION IF NOT EXISTS timescaledb;
+
+  FOREACH t IN ARRAY ARRAY['raw_steps', 'raw_validations'] LOOP
+    IF EXISTS (
+      SELECT 1 FROM timescaledb_information.hypertables h
+      WHERE h.hypertable_schema = 'public' AND h.hypertable_name = t
+    ) THEN
+      CONTINUE;
+    END IF;
+    old := t || '_partitioned';
+
+    -- Recreate the indexes and constraints of the table on the hypertable once the table is gone,
+    -- so that they keep their names. Indexes of partitioned tables are printed as ON ONLY.
+    SELECT coalesce(array_agg(replace(pg_get_indexdef(i.indexrelid), ' ON ONLY ', ' ON ')), '{}')
+      INTO indexes
+      FROM pg_index i
+      WHERE i.indrelid = t::regclass
+        AND NOT EXISTS (SELECT 1 FROM pg_constraint c WHERE c.conindid = i.indexrelid);
+    SELECT coalesce(array_agg(format('ALTER TABLE %I ADD CONSTRAINT %I %s',
+        t, c.conname, pg_get_constraintdef(c.oid))), '{}')
+      INTO constraints
+      FROM pg_constraint c
+      WHERE c.conrelid = t::regclass AND c.contype <> 'n';
+
+    EXECUTE format('CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS)', t || '_hypertable', t);
+    PERFORM create_hypertable((t || '_hypertable')::regclass, 'trial_id',
+      chunk_time_interval => trials_per_chunk, create_default_indexes => false);
+    EXECUTE format('INSERT INTO %I SELECT * FROM %I', t || '_hypertable', t);
+    EXECUTE format('ALTER TABLE %I RENAME TO %I', t, old);
+    EXECUTE format('ALTER TABLE %I RENAME TO %I', t || '_hypertable', t);
+
+    -- Views keep referring to the table they were created on, so point them at the hypertable.
+    FOR v IN
+      SELECT DISTINCT r.ev_class::regclass
+      FROM pg_depend d
+      JOIN pg_rewrite r ON r.oid = d.objid
+      WHERE d.classid = 'pg_rewrite'::regclass
+        AND d.refobjid = old::regclass
+        AND r.ev_class <> old::regclass
+    LOOP
+      EXECUTE format('CREATE OR REPLACE VIEW %s AS %s',
+        v, regexp_replace(pg_get_viewdef(v), '\m' || old || '\M', t, 'g'));
+    END LOOP;
+
+    seq := pg_get_serial_sequence(old, 'id');
+    IF seq IS NOT NULL THEN
+      EXECUTE format('ALTER SEQUENCE %s OWNED BY %I.id', seq, t);
+    END IF;
+    EXECUTE format('DROP TABLE %I', old);
+    DELETE FROM metrics_partitions WHERE table_name = t;
+
+    FOREACH stmt IN ARRAY indexes || constraints LOOP
+      EXECUTE stmt;
+    END LOOP;
+
+    -- Chunks are compressed by the master once all of their trials have ended. Ordering by trial
+    -- lets reads of the metrics of one trial skip the compressed rows of the others.
+    EXECUTE format(
+      'ALTER TABLE %I SET (timescaledb.compress, timescaledb.compress_orderby = %L)',
+      t, 'trial_id, total_batches');
+    PERFORM set_integer_now_func(t::regclass, 'metrics_trial_now');
+  END LOOP;
+
+  IF NOT EXISTS (
+    SELECT 1 FROM timescaledb_information.continuous_aggregates a
+    WHERE a.view_schema = 'public' AND a.view_name = 'steps_downsampled'
+  ) THEN
+    CREATE MATERIALIZED VIEW steps_downsampled
+    WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+      SELECT
+        time_bucket(1, trial_id) AS trial_id,
+        total_batches / 1000 AS bucket,
+        max(total_batches) AS total_batches,
+        last(metrics, total_batches) AS metrics
+      FROM raw_steps
+      WHERE NOT archived AND state = 'COMPLETED'
+      GROUP BY time_bucket(1, trial_id), total_batches / 1000
+    WITH NO DATA;
+    PERFORM add_continuous_aggregate_policy('steps_downsampled',
+      start_offset => NULL, end_offset => NULL, schedule_interval => INTERVAL '1 minute');
+  END IF;
+
+  IF NOT EXISTS (
+    SELECT 1 FROM timescaledb_information.continuous_aggregates a
+    WHERE a.view_schema = 'public' AND a.view_name = 'validations_downsampled'
+  ) THEN
+    CREATE MATERIALIZED VIEW validations_downsampled
+    WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+      SELECT
+        time_bucket(1, trial_id) AS trial_id,
+        total_batches / 1000 AS bucket,
+        max(total_batches) AS total_batches,
+        last(metrics, total_batches) AS metrics
+      FROM raw_validations
+      WHERE NOT archived AND state = 'COMPLETED'
+      GROUP BY time_bucket(1, trial_id), total_batches / 1000
+    WITH NO DATA;
+    PERFORM add_continuous_aggregate_policy('validations_downsampled',
+      start_offset => NULL, end_offset => NULL, schedule_interval => INTERVAL '1 minute');
+  END IF;
+END;
+$$ LANGUAGE plpgsql;
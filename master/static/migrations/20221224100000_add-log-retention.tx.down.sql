@@ -0,0 +1,2 @@
+DROP TABLE log_retention_runs;
+ALTER TABLE workspaces DROP COLUMN log_retention_days;
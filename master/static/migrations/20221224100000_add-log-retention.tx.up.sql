@@ -0,0 +1,14 @@
+-- The number of days after a trial ends that its logs are kept; NULL uses the retention of the
+-- master, and 0 keeps them forever.
+ALTER TABLE workspaces ADD COLUMN log_retention_days integer
+  CONSTRAINT log_retention_days_not_negative CHECK (log_retention_days >= 0);
+
+CREATE TABLE log_retention_runs (
+  id serial PRIMARY KEY,
+  started_at timestamptz NOT NULL DEFAULT now(),
+  ended_at timestamptz,
+  trials integer NOT NULL DEFAULT 0,
+  rows_deleted bigint NOT NULL DEFAULT 0,
+  bytes_reclaimed bigint NOT NULL DEFAULT 0,
+  error text
+);
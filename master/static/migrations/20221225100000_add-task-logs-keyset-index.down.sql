@@ -0,0 +1 @@
+DROP INDEX CONCURRENTLY IF EXISTS ix_task_logs_task_id_timestamp_id;
@@ -0,0 +1,4 @@
+-- Lets logs ordered by timestamp be read a batch at a time from where the last batch ended. This
+-- migration is not run in a transaction so that writing logs is not blocked while it is built.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS ix_task_logs_task_id_timestamp_id
+    ON task_logs USING btree (task_id, (coalesce(timestamp, 'epoch')), id);
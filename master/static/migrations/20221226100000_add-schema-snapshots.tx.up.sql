@@ -0,0 +1,8 @@
+-- The schema of the database as the master left it after migrating it, so that changes made to it
+-- since by hand can be found.
+CREATE TABLE schema_snapshots (
+  id serial PRIMARY KEY,
+  version bigint NOT NULL,
+  taken_at timestamptz NOT NULL DEFAULT now(),
+  objects jsonb NOT NULL
+);
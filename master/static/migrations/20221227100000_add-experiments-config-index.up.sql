@@ -0,0 +1,4 @@
+-- Lets experiments be found by the values in their configurations. This migration is not run in a
+-- transaction so that experiments can still be created and changed while it is built.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS ix_experiments_config
+    ON experiments USING gin (config jsonb_path_ops);
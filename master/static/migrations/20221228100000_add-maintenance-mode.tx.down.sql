@@ -0,0 +1,2 @@
+DROP TABLE cluster_messages;
+DROP TABLE maintenance_mode;
@@ -0,0 +1,18 @@
+CREATE TABLE maintenance_mode (
+  -- There is only ever one row.
+  id boolean PRIMARY KEY DEFAULT true CHECK (id),
+  enabled boolean NOT NULL DEFAULT false,
+  drained_agents text[] NOT NULL DEFAULT '{}',
+  updated_time timestamptz NOT NULL DEFAULT now(),
+  updated_by integer REFERENCES users(id) ON DELETE SET NULL
+);
+INSERT INTO maintenance_mode (id) VALUES (true);
+
+CREATE TABLE cluster_messages (
+  id serial PRIMARY KEY,
+  message text NOT NULL,
+  start_time timestamptz NOT NULL DEFAULT now(),
+  end_time timestamptz,
+  created_time timestamptz NOT NULL DEFAULT now(),
+  created_by integer REFERENCES users(id) ON DELETE SET NULL
+);
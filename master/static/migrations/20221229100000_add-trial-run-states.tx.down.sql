@@ -0,0 +1 @@
+DROP TABLE trial_run_states;
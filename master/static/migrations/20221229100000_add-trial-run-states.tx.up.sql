@@ -0,0 +1,10 @@
+CREATE TABLE trial_run_states (
+  trial_id integer NOT NULL REFERENCES trials(id) ON DELETE CASCADE,
+  run_id integer NOT NULL,
+  trial_seed bigint NOT NULL,
+  slots integer NOT NULL,
+  steps_completed integer NOT NULL,
+  checkpoint_uuid uuid,
+  start_time timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (trial_id, run_id)
+);
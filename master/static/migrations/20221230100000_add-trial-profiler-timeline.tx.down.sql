@@ -0,0 +1 @@
+DROP TABLE trial_profiler_timeline;
@@ -0,0 +1,30 @@
+CREATE TABLE trial_profiler_timeline (
+  trial_id integer NOT NULL REFERENCES trials(id) ON DELETE CASCADE,
+  name text NOT NULL,
+  agent_id text NOT NULL,
+  gpu_uuid text NOT NULL,
+  metric_type text NOT NULL,
+  bucket timestamptz NOT NULL,
+  count integer NOT NULL,
+  sum double precision NOT NULL,
+  min real NOT NULL,
+  max real NOT NULL,
+  PRIMARY KEY (trial_id, name, agent_id, gpu_uuid, metric_type, bucket)
+);
+
+INSERT INTO trial_profiler_timeline
+  (trial_id, name, agent_id, gpu_uuid, metric_type, bucket, count, sum, min, max)
+SELECT
+  (m.labels->>'trialId')::integer,
+  m.labels->>'name',
+  coalesce(m.labels->>'agentId', ''),
+  coalesce(m.labels->>'gpuUuid', ''),
+  coalesce(m.labels->>'metricType', 'PROFILER_METRIC_TYPE_UNSPECIFIED'),
+  to_timestamp(floor(extract(epoch FROM r.ts))),
+  count(*),
+  sum(r.value),
+  min(r.value),
+  max(r.value)
+FROM trial_profiler_metrics m, unnest(m.values, m.ts) AS r(value, ts)
+WHERE EXISTS (SELECT 1 FROM trials t WHERE t.id = (m.labels->>'trialId')::integer)
+GROUP BY 1, 2, 3, 4, 5, 6;
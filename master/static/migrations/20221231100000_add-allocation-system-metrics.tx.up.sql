@@ -0,0 +1,15 @@
+CREATE TABLE allocation_system_metrics (
+  allocation_id text NOT NULL REFERENCES allocations(allocation_id) ON DELETE CASCADE,
+  container_id text NOT NULL,
+  time timestamptz NOT NULL,
+  cpu_cores double precision,
+  memory_bytes bigint,
+  gpu_utilization double precision,
+  gpu_memory_bytes bigint,
+  network_rx_bytes_per_second double precision,
+  network_tx_bytes_per_second double precision
+);
+
+CREATE INDEX ix_allocation_system_metrics_allocation_id_time
+  ON allocation_system_metrics (allocation_id, time);
+CREATE INDEX ix_allocation_system_metrics_time ON allocation_system_metrics (time);
@@ -0,0 +1,3 @@
+DROP TABLE allocation_gpu_energy;
+
+ALTER TABLE allocation_system_metrics DROP COLUMN gpu_power_watts;
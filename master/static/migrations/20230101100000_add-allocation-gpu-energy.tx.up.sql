@@ -0,0 +1,6 @@
+ALTER TABLE allocation_system_metrics ADD COLUMN gpu_power_watts double precision;
+
+CREATE TABLE allocation_gpu_energy (
+  allocation_id text PRIMARY KEY REFERENCES allocations(allocation_id) ON DELETE CASCADE,
+  joules double precision NOT NULL DEFAULT 0
+);
@@ -0,0 +1,7 @@
+-- Resource pools with a binding may only be used by the users, groups, and workspaces in it.
+CREATE TABLE resource_pool_bindings (
+    resource_pool text PRIMARY KEY,
+    user_ids integer[] NOT NULL DEFAULT '{}',
+    group_ids integer[] NOT NULL DEFAULT '{}',
+    workspace_ids integer[] NOT NULL DEFAULT '{}'
+);
@@ -0,0 +1,5 @@
+DROP INDEX ix_user_sessions_user_id;
+ALTER TABLE user_sessions
+    DROP COLUMN created_at,
+    DROP COLUMN last_used_at,
+    DROP COLUMN last_ip;
@@ -0,0 +1,5 @@
+ALTER TABLE user_sessions
+    ADD COLUMN created_at timestamptz NOT NULL DEFAULT now(),
+    ADD COLUMN last_used_at timestamptz,
+    ADD COLUMN last_ip text NOT NULL DEFAULT '';
+CREATE INDEX ix_user_sessions_user_id ON user_sessions USING btree (user_id);
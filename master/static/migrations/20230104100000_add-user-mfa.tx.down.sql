@@ -0,0 +1,4 @@
+ALTER TABLE user_sessions
+    DROP COLUMN mfa_enrollment_only;
+
+DROP TABLE user_mfa;
@@ -0,0 +1,12 @@
+CREATE TABLE user_mfa (
+    user_id integer PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    required boolean NOT NULL DEFAULT false,
+    secret text,
+    pending_secret text,
+    enrolled_at timestamptz,
+    recovery_code_hashes text[] NOT NULL DEFAULT '{}',
+    last_used_step bigint NOT NULL DEFAULT 0
+);
+
+ALTER TABLE user_sessions
+    ADD COLUMN mfa_enrollment_only boolean NOT NULL DEFAULT false;
@@ -0,0 +1,7 @@
+CREATE TABLE project_group_members (
+  project_id integer NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  group_id integer NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+  role public.member_role NOT NULL,
+  PRIMARY KEY (project_id, group_id)
+);
+CREATE INDEX ix_project_group_members_group_id ON project_group_members USING btree (group_id);
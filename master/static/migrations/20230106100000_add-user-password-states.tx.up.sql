@@ -0,0 +1,9 @@
+CREATE TABLE user_password_states (
+    user_id integer PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    password_changed_at timestamptz NOT NULL DEFAULT now(),
+    failed_login_attempts integer NOT NULL DEFAULT 0,
+    locked_until timestamptz
+);
+
+-- Existing passwords are considered to have been set now for the purpose of expiring them.
+INSERT INTO user_password_states (user_id) SELECT id FROM users;
@@ -0,0 +1,3 @@
+ALTER TABLE users
+    DROP COLUMN owner_id,
+    DROP COLUMN service_account;
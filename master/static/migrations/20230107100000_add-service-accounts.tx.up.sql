@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN service_account boolean NOT NULL DEFAULT false,
+    ADD COLUMN owner_id integer REFERENCES users(id) ON DELETE SET NULL,
+    ADD CONSTRAINT users_owner_id_service_account CHECK (owner_id IS NULL OR service_account);
@@ -0,0 +1,10 @@
+CREATE TABLE user_ssh_keys (
+  id serial PRIMARY KEY,
+  user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name text NOT NULL DEFAULT '',
+  public_key text NOT NULL,
+  fingerprint text NOT NULL,
+  inject_into_trials boolean NOT NULL DEFAULT false,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (user_id, fingerprint)
+);
@@ -0,0 +1 @@
+DROP TABLE pod_templates;
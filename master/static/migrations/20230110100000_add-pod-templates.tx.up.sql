@@ -0,0 +1,7 @@
+CREATE TABLE pod_templates (
+  name text PRIMARY KEY,
+  spec jsonb NOT NULL,
+  description text NOT NULL DEFAULT '',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1 @@
+DROP TABLE agent_image_cache_limits;
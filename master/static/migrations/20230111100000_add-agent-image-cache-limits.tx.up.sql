@@ -0,0 +1,5 @@
+CREATE TABLE agent_image_cache_limits (
+  agent_id text PRIMARY KEY,
+  limit_bytes bigint NOT NULL,
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
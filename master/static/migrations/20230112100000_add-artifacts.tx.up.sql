@@ -0,0 +1,15 @@
+CREATE TABLE artifacts (
+  uuid uuid PRIMARY KEY,
+  task_id text NOT NULL REFERENCES tasks(task_id),
+  allocation_id text REFERENCES allocations(allocation_id) NULL,
+  name text NOT NULL,
+  type text NOT NULL DEFAULT '',
+  report_time timestamptz NOT NULL DEFAULT now(),
+  resources jsonb NOT NULL DEFAULT '{}'::jsonb,
+  metadata jsonb NOT NULL DEFAULT '{}'::jsonb,
+  -- The checkpoint storage the artifact was uploaded to, so that it can still be downloaded after
+  -- the storage configuration changes.
+  storage jsonb NOT NULL
+);
+
+CREATE INDEX ix_artifacts_task_id ON artifacts USING btree (task_id);
@@ -0,0 +1 @@
+ALTER TABLE db_backups DROP COLUMN sha256;
@@ -0,0 +1,3 @@
+-- The digest of each backup archive is kept in the database, out of reach of anyone who can
+-- write to the storage of backups, so that restores can reject archives that were changed.
+ALTER TABLE db_backups ADD COLUMN sha256 text;
@@ -25,4 +25,4 @@ SELECT
     ) AS training
 FROM checkpoints_view AS c
 WHERE c.trial_id = $1
-ORDER BY c.report_time DESC
+ORDER BY c.report_time DESC, c.uuid
@@ -10,6 +10,7 @@ SELECT
     e.config->>'name' AS name,
     e.config->>'description' AS description,
     e.config->'labels' AS labels,
+    e.key_value_labels AS key_value_labels,
     e.config->'resources'->>'resource_pool' as resource_pool,
     e.config->'searcher'->'name' as searcher_type,
     e.notes AS notes,
@@ -20,6 +21,7 @@ SELECT
     e.progress AS progress,
     e.job_id AS job_id,
     e.parent_id AS forked_from,
+    e.best_checkpoint_uuid AS best_checkpoint_uuid,
     e.owner_id AS user_id,
     u.username AS username,
     (SELECT json_agg(id) FROM trial_ids) AS trial_ids,
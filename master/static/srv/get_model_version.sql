@@ -1,11 +1,12 @@
 WITH mv AS (
-  SELECT version, checkpoint_uuid, model_versions.id, creation_time, name, comment, metadata, labels, notes, username, user_id, last_updated_time
+  SELECT version, checkpoint_uuid, model_versions.id, creation_time, name, comment, metadata, labels, notes, username, user_id, last_updated_time, stage
     FROM model_versions
     LEFT JOIN users ON users.id = model_versions.user_id
     WHERE model_id = $1 AND model_versions.id = $2
 ),
 m AS (
-  SELECT m.id, m.name, m.description, m.notes, m.metadata, m.creation_time, m.last_updated_time, array_to_json(m.labels) AS labels, u.username, m.user_id, m.archived, COUNT(mv.version) as num_versions
+  SELECT m.id, m.name, m.description, m.notes, m.metadata, m.creation_time, m.last_updated_time, array_to_json(m.labels) AS labels, u.username, m.user_id, m.archived, COUNT(mv.version) as num_versions,
+    array_to_json(ARRAY(SELECT username FROM users WHERE id = ANY(m.stage_approver_ids) ORDER BY username)) AS stage_approvers
   FROM models as m
   JOIN users as u ON u.id = m.user_id
   LEFT JOIN model_versions as mv
@@ -25,5 +26,6 @@ SELECT
     mv.version, mv.id,
     mv.creation_time, mv.notes,
     mv.name, mv.comment, mv.metadata,
-    mv.username, mv.user_id, mv.last_updated_time
+    mv.username, mv.user_id, mv.last_updated_time,
+    'MODEL_VERSION_STAGE_' || mv.stage AS stage
     FROM c, m, mv;
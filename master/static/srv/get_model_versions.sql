@@ -11,7 +11,8 @@ WITH mv AS (
     notes,
     username,
     user_id,
-    last_updated_time
+    last_updated_time,
+    stage
   FROM model_versions
   LEFT JOIN users ON users.id = model_versions.user_id
   WHERE model_id = $1
@@ -37,6 +38,7 @@ SELECT
     mv.version, mv.id,
     mv.creation_time, mv.notes,
     mv.username, mv.user_id,
-    mv.name, mv.comment, mv.metadata, mv.last_updated_time
+    mv.name, mv.comment, mv.metadata, mv.last_updated_time,
+    'MODEL_VERSION_STAGE_' || mv.stage AS stage
     FROM c, mv, m
     WHERE c.uuid = mv.checkpoint_uuid::text;
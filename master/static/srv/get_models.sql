@@ -1,4 +1,6 @@
-SELECT m.id, m.name, m.description, m.notes, m.metadata, m.creation_time, m.last_updated_time, array_to_json(m.labels) AS labels, u.username, m.user_id, m.archived, COUNT(mv.version) as num_versions FROM models as m
+SELECT m.id, m.name, m.description, m.notes, m.metadata, m.creation_time, m.last_updated_time, array_to_json(m.labels) AS labels, u.username, m.user_id, m.archived, COUNT(mv.version) as num_versions,
+  array_to_json(ARRAY(SELECT username FROM users WHERE id = ANY(m.stage_approver_ids) ORDER BY username)) AS stage_approvers
+FROM models as m
 LEFT JOIN model_versions as mv ON mv.model_id = m.id
 LEFT JOIN users as u ON u.id = m.user_id
 WHERE ($1 = 0 OR m.id = $1)
@@ -52,12 +52,15 @@ WITH searcher_info AS (
     FROM trials t, searcher_info
     WHERE t.experiment_id = $1
       AND ($2 = '' OR t.state IN (SELECT unnest(string_to_array($2, ','))::trial_state))
+      AND ($7::jsonb IS NULL OR t.metadata @> $7::jsonb)
 ), page_info AS (
     SELECT public.page_info((SELECT COUNT(*) AS count FROM filtered_experiment_trials), $3, $4) AS page_info
 )
 SELECT
     (SELECT coalesce(json_agg(paginated_experiment_trials), '[]'::json) FROM (
         SELECT id FROM filtered_experiment_trials
+        -- With a cursor, the page starts after the trial it points to.
+        WHERE $5::int IS NULL OR (CASE WHEN $6 THEN id < $5 ELSE id > $5 END)
         ORDER BY %s
         OFFSET (SELECT p.page_info->>'start_index' FROM page_info p)::bigint
         LIMIT (SELECT (p.page_info->>'end_index')::bigint - (p.page_info->>'start_index')::bigint FROM page_info p)
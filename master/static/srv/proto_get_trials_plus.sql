@@ -159,6 +159,7 @@ SELECT
   t.start_time,
   t.end_time,
   t.hparams,
+  t.metadata,
   coalesce(new_ckpt.uuid, old_ckpt.uuid) AS warm_start_checkpoint_uuid,
   t.task_id,
   (
@@ -1,3 +1,5 @@
-UPDATE models SET name = $2, description = $3, notes = $4, metadata = $5, labels = string_to_array($6, ','), last_updated_time = current_timestamp
+UPDATE models SET name = $2, description = $3, notes = $4, metadata = $5, labels = string_to_array($6, ','), last_updated_time = current_timestamp,
+  stage_approver_ids = ARRAY(SELECT id FROM users WHERE username = ANY(string_to_array($7, ',')))
 WHERE id = $1
-RETURNING name, description, notes, metadata, array_to_json(labels) as labels, creation_time, last_updated_time
+RETURNING name, description, notes, metadata, array_to_json(labels) as labels, creation_time, last_updated_time,
+  array_to_json(ARRAY(SELECT username FROM users WHERE id = ANY(stage_approver_ids) ORDER BY username)) AS stage_approvers
@@ -3,7 +3,7 @@ WITH mv AS (
   SET name = $3, comment = $4, notes = $5, metadata = $6, labels = string_to_array($7, ','),
     last_updated_time = current_timestamp
   WHERE id = $1
-  RETURNING id, version, checkpoint_uuid, model_id, last_updated_time, creation_time, name, comment, notes, labels, metadata
+  RETURNING id, version, checkpoint_uuid, model_id, last_updated_time, creation_time, name, comment, notes, labels, metadata, stage
 ),
 m AS (
   SELECT m.id, m.name, m.description, m.notes, m.metadata, m.creation_time, m.last_updated_time, array_to_json(m.labels) AS labels, u.username, m.archived, COUNT(mv.version) as num_versions
@@ -23,5 +23,6 @@ SELECT
     to_json(c) AS checkpoint,
     to_json(m) AS model,
     array_to_json(mv.labels) AS labels,
-    mv.version, mv.id, mv.creation_time, mv.name, mv.comment, mv.notes, mv.metadata
+    mv.version, mv.id, mv.creation_time, mv.name, mv.comment, mv.notes, mv.metadata,
+    'MODEL_VERSION_STAGE_' || mv.stage AS stage
     FROM c, m, mv;
@@ -0,0 +1,62 @@
+#!/usr/bin/env bash
+
+source /run/determined/task-signal-handling.sh
+source /run/determined/task-logging-setup.sh
+
+set -e
+
+STARTUP_HOOK="startup-hook.sh"
+export PATH="/run/determined/pythonuserbase/bin:$PATH"
+if [ -z "$DET_PYTHON_EXECUTABLE" ]; then
+    export DET_PYTHON_EXECUTABLE="python3"
+fi
+if ! which "$DET_PYTHON_EXECUTABLE" >/dev/null 2>&1; then
+    echo "error: unable to find python3 as \"$DET_PYTHON_EXECUTABLE\"" >&2
+    echo "please install python3 or set the environment variable DET_PYTHON_EXECUTABLE=/path/to/python3" >&2
+    exit 1
+fi
+
+# See notebook-entrypoint.sh for why HOME may need to be set.
+if [ "$HOME" = "/" ]; then
+    HOME="$(
+        set -o pipefail
+        getent passwd "$(whoami)" | cut -d: -f6
+    )" || HOME="$PWD"
+    export HOME
+fi
+
+# Use user's preferred SHELL in VS Code terminals.
+SHELL="$(
+    set -o pipefail
+    getent passwd "$(whoami)" | cut -d: -f7
+)" || SHELL="/bin/bash"
+export SHELL
+
+if [ -z "$DET_SKIP_PIP_INSTALL" ]; then
+    "$DET_PYTHON_EXECUTABLE" -m pip install -q --user /opt/determined/wheels/determined*.whl
+fi
+
+"$DET_PYTHON_EXECUTABLE" -m determined.exec.prep_container --resources --proxy
+
+test -f "${STARTUP_HOOK}" && source "${STARTUP_HOOK}"
+
+# The master authenticates every request it proxies, so the server itself runs without auth.
+WORKDIR="$(pwd)"
+if which openvscode-server >/dev/null 2>&1; then
+    VSCODE_SERVER=(openvscode-server --host 0.0.0.0 --port "${VSCODE_PORT}"
+        --without-connection-token --telemetry-level off --default-folder "${WORKDIR}")
+elif which code-server >/dev/null 2>&1; then
+    VSCODE_SERVER=(code-server --bind-addr "0.0.0.0:${VSCODE_PORT}" --auth none
+        --disable-telemetry --disable-update-check "${WORKDIR}")
+else
+    echo "error: unable to find openvscode-server or code-server" >&2
+    echo "please use an image with either installed, or install one in a startup hook" >&2
+    exit 1
+fi
+
+READINESS_REGEX='(Web UI available at|HTTP server listening on)'
+
+trap_and_forward_signals
+"${VSCODE_SERVER[@]}" \
+    > >(tee -p >("$DET_PYTHON_EXECUTABLE" /run/determined/check_ready_logs.py --ready-regex "${READINESS_REGEX}")) 2>&1 &
+wait_and_handle_signals $!
@@ -76,7 +76,7 @@ func RunMaster(ctx context.Context, c *config.Config) (
 		c = dConf
 	}
 	logs := logger.NewLogBuffer(100)
-	m := internal.New(logs, c)
+	m := internal.New(logs, c, nil)
 	logrus.AddHook(logs)
 	go func() {
 		err := m.Run(ctx)
@@ -2,13 +2,13 @@ import { Button, Form, Input } from 'antd';
 import React, { useCallback, useState } from 'react';
 
 import Link from 'components/Link';
-import { StoreAction, useStoreDispatch } from 'contexts/Store';
+import { StoreAction, useStore, useStoreDispatch } from 'contexts/Store';
 import useFeature from 'hooks/useFeature';
 import { useFetchMyRoles } from 'hooks/useFetch';
 import { paths } from 'routes/utils';
 import { login } from 'services/api';
 import { updateDetApi } from 'services/apiConfig';
-import { isLoginFailure } from 'services/utils';
+import { isLoginFailure, isMfaRequiredFailure, isPasswordExpiredFailure } from 'services/utils';
 import Icon from 'shared/components/Icon/Icon';
 import useUI from 'shared/contexts/stores/UI';
 import { ErrorType } from 'shared/utils/error';
@@ -22,6 +22,8 @@ interface Props {
 }
 
 interface FromValues {
+  mfaCode?: string;
+  newPassword?: string;
   password?: string;
   username?: string;
 }
@@ -31,10 +33,13 @@ const STORAGE_KEY_LAST_USERNAME = 'lastUsername';
 
 const DeterminedAuth: React.FC<Props> = ({ canceler }: Props) => {
   const { actions: uiActions } = useUI();
+  const { info } = useStore();
   const storeDispatch = useStoreDispatch();
   const fetchMyRoles = useFetchMyRoles(canceler);
   const rbacEnabled = useFeature().isOn('rbac');
   const [isBadCredentials, setIsBadCredentials] = useState<boolean>(false);
+  const [isMfaRequired, setIsMfaRequired] = useState<boolean>(false);
+  const [isPasswordExpired, setIsPasswordExpired] = useState<boolean>(false);
   const [canSubmit, setCanSubmit] = useState<boolean>(!!storage.get(STORAGE_KEY_LAST_USERNAME));
   const [isSubmitted, setIsSubmitted] = useState<boolean>(false);
 
@@ -46,6 +51,10 @@ const DeterminedAuth: React.FC<Props> = ({ canceler }: Props) => {
       try {
         const { token, user } = await login(
           {
+            isHashed:
+              !info.ldapEnabled && !(creds.newPassword && info.passwordComplexityRequired),
+            mfaCode: creds.mfaCode,
+            newPassword: creds.newPassword,
             password: creds.password || '',
             username: creds.username || '',
           },
@@ -61,6 +70,18 @@ const DeterminedAuth: React.FC<Props> = ({ canceler }: Props) => {
         }
         storage.set(STORAGE_KEY_LAST_USERNAME, creds.username);
       } catch (e) {
+        if (isMfaRequiredFailure(e)) {
+          // The password was right; ask for a code from the authenticator app too.
+          setIsMfaRequired(true);
+          uiActions.hideSpinner();
+          return;
+        }
+        if (isPasswordExpiredFailure(e)) {
+          // The password was right but has to be replaced before signing in.
+          setIsPasswordExpired(true);
+          uiActions.hideSpinner();
+          return;
+        }
         const isBadCredentialsSync = isLoginFailure(e);
         setIsBadCredentials(isBadCredentialsSync); // this is not a sync operation
         uiActions.hideSpinner();
@@ -78,12 +99,24 @@ const DeterminedAuth: React.FC<Props> = ({ canceler }: Props) => {
         setIsSubmitted(false);
       }
     },
-    [canceler, storeDispatch, uiActions, fetchMyRoles, rbacEnabled],
+    [
+      canceler,
+      info.ldapEnabled,
+      info.passwordComplexityRequired,
+      storeDispatch,
+      uiActions,
+      fetchMyRoles,
+      rbacEnabled,
+    ],
   );
 
   const onValuesChange = useCallback((changes: FromValues, values: FromValues): void => {
     const hasUsername = !!values.username;
     setIsBadCredentials(false);
+    if (changes.username !== undefined) {
+      setIsMfaRequired(false);
+      setIsPasswordExpired(false);
+    }
     setCanSubmit(hasUsername);
   }, []);
 
@@ -107,6 +140,40 @@ const DeterminedAuth: React.FC<Props> = ({ canceler }: Props) => {
       <Form.Item name="password">
         <Input.Password placeholder="password" prefix={<Icon name="lock" size="small" />} />
       </Form.Item>
+      {isPasswordExpired && (
+        <Form.Item
+          name="newPassword"
+          rules={[
+            {
+              message: 'Your password has expired. Please type in a new one.',
+              required: true,
+            },
+          ]}>
+          <Input.Password
+            autoComplete="new-password"
+            autoFocus
+            placeholder="new password"
+            prefix={<Icon name="lock" size="small" />}
+          />
+        </Form.Item>
+      )}
+      {isMfaRequired && (
+        <Form.Item
+          name="mfaCode"
+          rules={[
+            {
+              message: 'Please type in a code from your authenticator app.',
+              required: true,
+            },
+          ]}>
+          <Input
+            autoComplete="one-time-code"
+            autoFocus
+            placeholder="authenticator or recovery code"
+            prefix={<Icon name="lock" size="small" />}
+          />
+        </Form.Item>
+      )}
       {isBadCredentials && (
         <p className={[css.errorMessage, css.message].join(' ')}>Incorrect username or password.</p>
       )}
@@ -144,7 +144,9 @@ export const initInfo: DeterminedInfo = {
   clusterName: '',
   featureSwitches: [],
   isTelemetryEnabled: false,
+  ldapEnabled: false,
   masterId: '',
+  passwordComplexityRequired: false,
   rbacEnabled: false,
   version: process.env.VERSION || '',
 };
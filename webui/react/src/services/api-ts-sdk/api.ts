@@ -155,7 +155,7 @@ export enum Determinedjobv1State {
 }
 
 /**
- * Job type.   - TYPE_UNSPECIFIED: Unspecified state.  - TYPE_EXPERIMENT: Experiement Job.  - TYPE_NOTEBOOK: Jupyter Notebook Job.  - TYPE_TENSORBOARD: TensorBoard Job.  - TYPE_SHELL: Shell Job.  - TYPE_COMMAND: Command Job.  - TYPE_CHECKPOINT_GC: CheckpointGC Job.
+ * Job type.   - TYPE_UNSPECIFIED: Unspecified state.  - TYPE_EXPERIMENT: Experiement Job.  - TYPE_NOTEBOOK: Jupyter Notebook Job.  - TYPE_TENSORBOARD: TensorBoard Job.  - TYPE_SHELL: Shell Job.  - TYPE_COMMAND: Command Job.  - TYPE_CHECKPOINT_GC: CheckpointGC Job.  - TYPE_VSCODE: VS Code Server Job.
  * @export
  * @enum {string}
  */
@@ -166,7 +166,8 @@ export enum Determinedjobv1Type {
     TENSORBOARD = <any> 'TYPE_TENSORBOARD',
     SHELL = <any> 'TYPE_SHELL',
     COMMAND = <any> 'TYPE_COMMAND',
-    CHECKPOINTGC = <any> 'TYPE_CHECKPOINT_GC'
+    CHECKPOINTGC = <any> 'TYPE_CHECKPOINT_GC',
+    VSCODE = <any> 'TYPE_VSCODE'
 }
 
 /**
@@ -3163,6 +3164,18 @@ export interface V1GetMasterResponse {
      * @memberof V1GetMasterResponse
      */
     featureSwitches?: Array<string>;
+    /**
+     * Whether users without a password in the cluster log in with their LDAP directory password, which must then be sent unhashed.
+     * @type {boolean}
+     * @memberof V1GetMasterResponse
+     */
+    ldapEnabled?: boolean;
+    /**
+     * Whether new passwords must meet complexity rules, which requires them to be sent unhashed.
+     * @type {boolean}
+     * @memberof V1GetMasterResponse
+     */
+    passwordComplexityRequired?: boolean;
 }
 
 /**
@@ -4731,6 +4744,18 @@ export interface V1LoginRequest {
      * @memberof V1LoginRequest
      */
     isHashed?: boolean;
+    /**
+     * A code from the authenticator app of the user, or one of their recovery codes, for users enrolled in multi-factor authentication.
+     * @type {string}
+     * @memberof V1LoginRequest
+     */
+    mfaCode?: string;
+    /**
+     * A new password for the user, required when their password has expired. It is hashed or not like the password.
+     * @type {string}
+     * @memberof V1LoginRequest
+     */
+    newPassword?: string;
 }
 
 /**
@@ -5631,6 +5656,12 @@ export interface V1PatchUser {
      * @memberof V1PatchUser
      */
     isHashed?: boolean;
+    /**
+     * The user to transfer the ownership of a service account to.
+     * @type {number}
+     * @memberof V1PatchUser
+     */
+    ownerId?: number;
 }
 
 /**
@@ -9059,6 +9090,18 @@ export interface V1User {
      * @memberof V1User
      */
     modifiedAt?: Date;
+    /**
+     * Whether the user is a service account, which authenticates only with access tokens.
+     * @type {boolean}
+     * @memberof V1User
+     */
+    serviceAccount?: boolean;
+    /**
+     * The user responsible for the service account, if any.
+     * @type {number}
+     * @memberof V1User
+     */
+    ownerId?: number;
 }
 
 /**
@@ -84,7 +84,15 @@ export const login: DetApi<Api.V1LoginRequest, Api.V1LoginResponse, Service.Logi
   postProcess: (resp) => ({ token: resp.token, user: decoder.mapV1User(resp.user) }),
   request: (params, options) =>
     detApi.Auth.login(
-      { ...params, isHashed: true, password: saltAndHashPassword(params.password) },
+      // Passwords checked against an LDAP directory or a password policy are sent unhashed.
+      params.isHashed === false
+        ? params
+        : {
+            ...params,
+            isHashed: true,
+            newPassword: params.newPassword && saltAndHashPassword(params.newPassword),
+            password: saltAndHashPassword(params.password),
+          },
       options,
     ),
 };
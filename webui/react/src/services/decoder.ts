@@ -102,7 +102,9 @@ export const mapV1MasterInfo = (data: Sdk.V1GetMasterResponse): types.Determined
     externalLogoutUri: data.externalLogoutUri,
     featureSwitches: data.featureSwitches || [],
     isTelemetryEnabled: data.telemetryEnabled === true,
+    ldapEnabled: !!data.ldapEnabled,
     masterId: data.masterId,
+    passwordComplexityRequired: !!data.passwordComplexityRequired,
     rbacEnabled: !!data.rbacEnabled,
     ssoProviders: data.ssoProviders,
     version: data.version,
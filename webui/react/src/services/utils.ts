@@ -19,6 +19,16 @@ export const isLoginFailure = (e: unknown): boolean => {
   return status === 401 || status === 403;
 };
 
+/* This is a failure received from a login attempt that also needs a code from an MFA app. */
+export const isMfaRequiredFailure = (e: unknown): boolean => {
+  return e instanceof DetError && e.publicMessage === 'MFA code required';
+};
+
+/* This is a failure received from a login attempt by a user whose password has expired. */
+export const isPasswordExpiredFailure = (e: unknown): boolean => {
+  return e instanceof DetError && e.publicMessage === 'password expired';
+};
+
 /* HTTP Helpers */
 
 /* gRPC Helpers */
@@ -51,7 +51,9 @@ export interface DeterminedInfo {
   externalLogoutUri?: string;
   featureSwitches: string[];
   isTelemetryEnabled: boolean;
+  ldapEnabled: boolean;
   masterId: string;
+  passwordComplexityRequired: boolean;
   rbacEnabled: boolean;
   ssoProviders?: SsoProvider[];
   version: string;
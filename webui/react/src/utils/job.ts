@@ -20,6 +20,7 @@ export const jobTypeIconName = (jobType: JobType): string => {
 };
 
 export const jobTypeLabel = (jobType: JobType): string => {
+  if (jobType === JobType.VSCODE) return 'VS Code';
   return capitalize(jobTypeIconName(jobType));
 };
 